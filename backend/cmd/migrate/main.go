@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/solobueno/erp/internal/auth"
+	"github.com/solobueno/erp/internal/auth/service"
 	"github.com/solobueno/erp/internal/shared/database"
 )
 
@@ -12,11 +14,12 @@ func main() {
 	fmt.Println("Solobueno ERP Migration Tool")
 
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: migrate [up|down|status]")
+		fmt.Println("Usage: migrate [up|down|status|hash-report]")
 		fmt.Println("Commands:")
-		fmt.Println("  up      - Run all migrations (GORM AutoMigrate)")
-		fmt.Println("  down    - Drop all tables (DANGEROUS)")
-		fmt.Println("  status  - Show migration status")
+		fmt.Println("  up           - Run all migrations (GORM AutoMigrate)")
+		fmt.Println("  down         - Drop all tables (DANGEROUS)")
+		fmt.Println("  status       - Show migration status")
+		fmt.Println("  hash-report  - Show password hash parameters in use (reads PASSWORD_PEPPERS)")
 		os.Exit(1)
 	}
 
@@ -68,7 +71,11 @@ func main() {
 		fmt.Println("Database connection: OK")
 
 		// Check if tables exist
-		tables := []string{"users", "tenants", "user_tenant_roles", "sessions", "password_reset_tokens", "auth_events"}
+		tables, err := auth.TableNames(db)
+		if err != nil {
+			fmt.Printf("Failed to list auth tables: %v\n", err)
+			os.Exit(1)
+		}
 		for _, table := range tables {
 			if db.Migrator().HasTable(table) {
 				fmt.Printf("  Table '%s': EXISTS\n", table)
@@ -77,6 +84,42 @@ func main() {
 			}
 		}
 
+	case "hash-report":
+		// Peppers as the server is configured, so current hashes are told
+		// apart from ones still to be upgraded
+		peppers, err := service.ParsePasswordPeppers(os.Getenv("PASSWORD_PEPPERS"))
+		if err != nil {
+			fmt.Printf("Invalid PASSWORD_PEPPERS: %v\n", err)
+			os.Exit(1)
+		}
+		passwords, err := service.NewPasswordServiceWithConfig(service.PasswordHashingConfig{Peppers: peppers})
+		if err != nil {
+			fmt.Printf("Invalid PASSWORD_PEPPERS: %v\n", err)
+			os.Exit(1)
+		}
+		report, err := auth.PasswordHashReport(context.Background(), db, passwords)
+		if err != nil {
+			fmt.Printf("Hash report failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Password hashes by parameters:")
+		for _, e := range report.Entries {
+			pepper := "none"
+			if e.PepperVersion != 0 {
+				pepper = fmt.Sprintf("v%d", e.PepperVersion)
+			}
+			status := "outdated"
+			if e.Current {
+				status = "current"
+			}
+			fmt.Printf("  m=%d t=%d p=%d salt=%d key=%d pepper=%s: %d users (%s)\n",
+				e.Params.Memory, e.Params.Iterations, e.Params.Parallelism, e.Params.SaltLength, e.Params.KeyLength,
+				pepper, e.Users, status)
+		}
+		if report.Unreadable > 0 {
+			fmt.Printf("  unreadable: %d users\n", report.Unreadable)
+		}
+
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		os.Exit(1)
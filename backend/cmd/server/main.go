@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -20,9 +21,16 @@ import (
 	_ "github.com/solobueno/erp/docs"
 	"github.com/solobueno/erp/internal/auth"
 	"github.com/solobueno/erp/internal/auth/handler"
+	"github.com/solobueno/erp/internal/auth/service"
 	"github.com/solobueno/erp/internal/shared/database"
 	"github.com/solobueno/erp/internal/shared/observability"
 	"github.com/solobueno/erp/pkg/jwt"
+	"github.com/solobueno/erp/pkg/webauthn"
+
+	// Modules declare their permissions at init
+	_ "github.com/solobueno/erp/internal/orders"
+	_ "github.com/solobueno/erp/internal/payments"
+	_ "github.com/solobueno/erp/internal/reporting"
 )
 
 // @title                       Solobueno ERP API
@@ -56,15 +64,84 @@ func main() {
 		generateEphemeralKeys(km)
 	}
 
+	// Scheduled in-process key rotation, for single-instance deployments
+	// (e.g. JWT_ROTATION_INTERVAL=720h). Multi-instance deployments rotate
+	// with JWT_KEY_ID and JWT_PREVIOUS_PUBLIC_KEY instead.
+	rotationCtx, stopRotation := context.WithCancel(context.Background())
+	defer stopRotation()
+	if interval := os.Getenv("JWT_ROTATION_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			log.Fatalf("invalid JWT_ROTATION_INTERVAL: %v", err)
+		}
+		rotatorCfg := jwt.DefaultKeyRotatorConfig()
+		rotatorCfg.Interval = d
+		if alg := os.Getenv("JWT_ROTATION_ALGORITHM"); alg != "" {
+			rotatorCfg.Algorithm = alg
+		}
+		go jwt.NewKeyRotator(km, rotatorCfg).Run(rotationCtx, time.Minute)
+	}
+
+	// Restrict accepted token algorithms, e.g. JWT_ALLOWED_ALGORITHMS=ES256,EdDSA
+	jwtCfg := jwt.DefaultTokenGeneratorConfig()
+	if algs := os.Getenv("JWT_ALLOWED_ALGORITHMS"); algs != "" {
+		jwtCfg.AllowedAlgorithms = strings.Split(algs, ",")
+	}
+
+	// Passkeys are off unless the relying party is configured, e.g.
+	// WEBAUTHN_RP_ID=erp.example.com WEBAUTHN_ORIGINS=https://erp.example.com
+	webauthnCfg := webauthn.Config{
+		RPID:   os.Getenv("WEBAUTHN_RP_ID"),
+		RPName: "Solobueno ERP",
+	}
+	if origins := os.Getenv("WEBAUTHN_ORIGINS"); origins != "" {
+		webauthnCfg.Origins = strings.Split(origins, ",")
+	}
+
+	// A larger breached-password list than the bundled one, one password
+	// per line, e.g. BREACHED_PASSWORDS_FILE=/etc/erp/breached-passwords.txt
+	var breachedPasswords service.BreachedPasswords
+	if path := os.Getenv("BREACHED_PASSWORDS_FILE"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("failed to open BREACHED_PASSWORDS_FILE: %v", err)
+		}
+		filter, err := service.NewBreachedPasswordFilter(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("failed to load BREACHED_PASSWORDS_FILE: %v", err)
+		}
+		breachedPasswords = filter
+	}
+
+	// Server-side peppers for password hashes, as version:base64-secret
+	// pairs, e.g. PASSWORD_PEPPERS=1:<secret>,2:<secret>. New hashes use the
+	// highest version; keep older ones until `migrate hash-report` shows no
+	// hashes still use them.
+	peppers, err := service.ParsePasswordPeppers(os.Getenv("PASSWORD_PEPPERS"))
+	if err != nil {
+		log.Fatalf("invalid PASSWORD_PEPPERS: %v", err)
+	}
+
 	authModule, err := auth.NewModule(auth.ModuleConfig{
 		DB:         db,
 		KeyManager: km,
-		JWTConfig:  jwt.DefaultTokenGeneratorConfig(),
+		JWTConfig:  jwtCfg,
+		// Frontend page identity providers return to after an SSO login
+		SSORedirectURL:    os.Getenv("SSO_REDIRECT_URL"),
+		WebAuthn:          webauthnCfg,
+		BreachedPasswords: breachedPasswords,
+		PasswordHashing:   service.PasswordHashingConfig{Peppers: peppers},
 	})
 	if err != nil {
 		log.Fatalf("failed to initialize auth module: %v", err)
 	}
 
+	// Start and expire temporary role elevations as their windows open and close
+	elevationCtx, stopElevations := context.WithCancel(context.Background())
+	defer stopElevations()
+	go authModule.UserService.RunElevationExpirer(elevationCtx, time.Minute)
+
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(handler.AccessLog)
@@ -15,14 +15,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/change-password": {
+        "/auth/approvals": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Change the authenticated user's password. Invalidates all other sessions (FR-014).",
+                "description": "Step-up authorization for a sensitive action such as a refund or a void. A manager enters their password, or their PIN on a registered terminal, on the caller's device. The approver must hold the action's permission in the caller's tenant and can't be the caller. Returns a single-use approval token, valid for 2 minutes, for the caller to perform that action on that resource; send it in the X-Approval-Token header. Approver failures return 403, never 401, so the client doesn't mistake them for its own session expiring.",
                 "consumes": [
                     "application/json"
                 ],
@@ -30,29 +30,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "approvals"
                 ],
-                "summary": "Change password",
+                "summary": "Get a manager's approval",
                 "parameters": [
                     {
-                        "description": "Current and new password",
+                        "type": "string",
+                        "description": "Terminal device token (required for PIN approvals)",
+                        "name": "X-Terminal-Token",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Action, resource and approver credentials",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.ChangePasswordRequest"
+                            "$ref": "#/definitions/internal_auth_handler.ApprovalRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.MessageResponse"
+                            "$ref": "#/definitions/internal_auth_handler.ApprovalResponse"
                         }
                     },
                     "400": {
-                        "description": "current_password_incorrect, password_weak",
+                        "description": "invalid_request, invalid_action",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
@@ -62,13 +68,36 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "invalid_credentials, invalid_pin, terminal_invalid, self_approval, password_login_disabled",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "account_locked, pin_locked",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limit_exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/auth/login": {
+        "/auth/change-password": {
             "post": {
-                "description": "Authenticate with email and password. If the user belongs to multiple tenants and none is specified, returns 400 tenant_required with the list of choices.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the authenticated user's password. The new password must meet the strictest policy of the user's tenants and not be a breached or recently used password; a password_weak error names the rule broken in its rule field. Invalidates all other sessions (FR-014).",
                 "consumes": [
                     "application/json"
                 ],
@@ -78,15 +107,15 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Log in",
+                "summary": "Change password",
                 "parameters": [
                     {
-                        "description": "Login credentials",
+                        "description": "Current and new password",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.LoginRequest"
+                            "$ref": "#/definitions/internal_auth_handler.ChangePasswordRequest"
                         }
                     }
                 ],
@@ -94,29 +123,23 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.LoginResponse"
+                            "$ref": "#/definitions/internal_auth_handler.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "tenant_required",
-                        "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.TenantRequiredResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "invalid_credentials, account_disabled",
+                        "description": "current_password_incorrect, password_weak",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
                     },
-                    "423": {
-                        "description": "account_locked",
+                    "401": {
+                        "description": "unauthorized",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
                     },
-                    "429": {
-                        "description": "rate_limit_exceeded",
+                    "403": {
+                        "description": "impersonation_forbidden",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
@@ -124,58 +147,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/logout": {
+        "/auth/email/change": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Revoke the current session's refresh token. Idempotent - always returns 204.",
+                "description": "Start moving the authenticated user to a new email address, confirmed with their password. A link is sent to the new address (1-hour TTL); nothing changes until it is followed.",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "auth"
                 ],
-                "summary": "Log out",
+                "summary": "Change email",
                 "parameters": [
                     {
-                        "description": "Refresh token to revoke",
+                        "description": "New email and current password",
                         "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.RefreshRequest"
+                            "$ref": "#/definitions/internal_auth_handler.EmailChangeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    }
-                }
-            }
-        },
-        "/auth/me": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Return the authenticated user's identity and current tenant/role, from the access token claims.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Get current user",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.MeResponse"
+                            "$ref": "#/definitions/internal_auth_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_email, email_unchanged, current_password_incorrect",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
                     },
                     "401": {
@@ -183,13 +194,31 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "impersonation_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "email_exists",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limit_exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/auth/password-reset/complete": {
+        "/auth/email/change/confirm": {
             "post": {
-                "description": "Set a new password using a valid, unexpired, unused reset token (1-hour TTL). Invalidates all sessions.",
+                "description": "Move the account to the new address a change link was sent to, using its token. The address becomes verified, all sessions are invalidated, and the previous address is sent a link that undoes the change for 7 days.",
                 "consumes": [
                     "application/json"
                 ],
@@ -199,15 +228,15 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Complete password reset",
+                "summary": "Confirm email change",
                 "parameters": [
                     {
-                        "description": "Reset token and new password",
+                        "description": "Email change token",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.PasswordResetCompleteRequest"
+                            "$ref": "#/definitions/internal_auth_handler.EmailTokenRequest"
                         }
                     }
                 ],
@@ -219,7 +248,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "token_invalid, token_expired, token_used, password_weak",
+                        "description": "token_invalid, token_expired, token_used",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "email_exists",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
@@ -227,9 +262,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/password-reset/request": {
+        "/auth/email/change/revert": {
             "post": {
-                "description": "Send a password reset token to the given email if it exists. Always returns 202 to prevent email enumeration.",
+                "description": "Move the account back to the address an undo link was sent to after an email change, using its token. Pending changes are cancelled, all sessions are invalidated, and the user is asked to set a new password.",
                 "consumes": [
                     "application/json"
                 ],
@@ -239,27 +274,33 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Request password reset",
+                "summary": "Undo email change",
                 "parameters": [
                     {
-                        "description": "Email",
+                        "description": "Email change undo token",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.PasswordResetRequest"
+                            "$ref": "#/definitions/internal_auth_handler.EmailTokenRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.MessageResponse"
                         }
                     },
-                    "429": {
-                        "description": "rate_limit_exceeded",
+                    "400": {
+                        "description": "token_invalid, token_expired, token_used",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "email_exists",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
@@ -267,9 +308,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/refresh": {
+        "/auth/email/verify": {
             "post": {
-                "description": "Exchange a valid refresh token for a new access/refresh token pair (rotates the refresh token).",
+                "description": "Verify the address a verification link was sent to, using its token. A link sent before an email change no longer works.",
                 "consumes": [
                     "application/json"
                 ],
@@ -279,15 +320,15 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Refresh access token",
+                "summary": "Verify email",
                 "parameters": [
                     {
-                        "description": "Refresh token",
+                        "description": "Verification token",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.RefreshRequest"
+                            "$ref": "#/definitions/internal_auth_handler.EmailTokenRequest"
                         }
                     }
                 ],
@@ -295,11 +336,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.TokenResponse"
+                            "$ref": "#/definitions/internal_auth_handler.MessageResponse"
                         }
                     },
-                    "401": {
-                        "description": "token_invalid, session_revoked, token_expired, account_disabled",
+                    "400": {
+                        "description": "token_invalid, token_expired, token_used",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
@@ -307,39 +348,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
-            "get": {
+        "/auth/email/verify/request": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Email the authenticated user a link verifying their current address (24-hour TTL). Only the newest link works. Password resets are only sent to verified addresses.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
-                ],
-                "summary": "List users in the current tenant",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Page number (default 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per page (default 20, max 100)",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                    "auth"
                 ],
+                "summary": "Request email verification",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.UserListResponse"
+                            "$ref": "#/definitions/internal_auth_handler.MessageResponse"
                         }
                     },
                     "401": {
@@ -347,16 +375,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
+                    },
+                    "409": {
+                        "description": "email_already_verified",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limit_exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/auth/invitations/accept": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Manager+ creates a user for their tenant. If the email already has a global account in a different tenant, links a role to it instead (200) rather than creating a duplicate (201). New accounts get a temp password emailed to them (never returned in the response).",
+                "description": "Redeem an invite token (7-day TTL, single use). If the invited email has no account, one is created with the given password. If it already has one, the password must be that account's current password, confirming the invitee wants to join the tenant.",
                 "consumes": [
                     "application/json"
                 ],
@@ -364,41 +401,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "auth"
                 ],
-                "summary": "Create a staff account",
+                "summary": "Accept an invitation",
                 "parameters": [
                     {
-                        "description": "New user details",
+                        "description": "Invite token and password",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.CreateUserRequest"
+                            "$ref": "#/definitions/internal_auth_handler.AcceptInvitationRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "linked to existing global account",
+                        "description": "existing account joined the tenant",
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.CreateUserResponse"
+                            "$ref": "#/definitions/internal_auth_handler.AcceptInvitationResponse"
                         }
                     },
                     "201": {
                         "description": "new account created",
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.CreateUserResponse"
+                            "$ref": "#/definitions/internal_auth_handler.AcceptInvitationResponse"
                         }
                     },
                     "400": {
-                        "description": "invalid_request, invalid_role, email_exists",
+                        "description": "token_invalid, token_expired, token_used, invitation_revoked, password_weak, email_exists",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "insufficient_role",
+                    "401": {
+                        "description": "invalid_credentials, account_disabled",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "account_locked",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
@@ -406,57 +449,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
+        "/auth/lockout-policy": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Admin+ views how repeated wrong passwords delay members' logins. Tenants that haven't set a policy get the default: logins from unknown devices are delayed from the 5th consecutive failure and from known devices from the 10th, for 1 minute doubling up to 15 minutes.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
-                ],
-                "summary": "Get a user",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "auth"
                 ],
+                "summary": "Get the tenant's lockout policy",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                            "$ref": "#/definitions/internal_auth_handler.LockoutPolicyResponse"
                         }
                     },
-                    "400": {
-                        "description": "invalid_id",
+                    "401": {
+                        "description": "unauthorized",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "not_found",
+                    "403": {
+                        "description": "insufficient_role",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
                     }
                 }
             },
-            "patch": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Manager+ updates a user's name or active status. Deactivating revokes all their sessions.",
+                "description": "Admin+ replaces how repeated wrong passwords delay members' logins. Once a device's threshold (1 to 100) of consecutive failures is reached, each further failure locks logins from such devices for base_delay_seconds, doubling up to max_delay_seconds (at most 86400). Devices a member has logged in from before have their own threshold, so guessing elsewhere can't lock them out of those. Members in several tenants follow the strictest combination of their tenants' policies. Locked-out members can unlock by email.",
                 "consumes": [
                     "application/json"
                 ],
@@ -464,24 +499,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "auth"
                 ],
-                "summary": "Update a user's profile",
+                "summary": "Set the tenant's lockout policy",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Fields to update",
+                        "description": "Lockout limits",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.UpdateUserRequest"
+                            "$ref": "#/definitions/internal_auth_handler.LockoutPolicyRequest"
                         }
                     }
                 ],
@@ -489,23 +517,23 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                            "$ref": "#/definitions/internal_auth_handler.LockoutPolicyResponse"
                         }
                     },
                     "400": {
-                        "description": "invalid_id, invalid_request",
+                        "description": "invalid_request, lockout_policy_invalid",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "insufficient_role",
+                    "401": {
+                        "description": "unauthorized",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "not_found",
+                    "403": {
+                        "description": "insufficient_role, impersonation_forbidden",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
@@ -513,39 +541,190 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}/role": {
-            "patch": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Manager+ changes a user's role in the current tenant. Cannot assign or manage a role equal to or higher than your own.",
-                "consumes": [
+        "/auth/login": {
+            "post": {
+                "description": "Authenticate with email and password. If the user belongs to multiple tenants and none is specified, returns 400 tenant_required with the list of choices. If the user has MFA enabled (or their role requires it), returns 401 mfa_required / mfa_enrollment_required with an mfa_token to complete via /auth/mfa/verify, or via /auth/mfa/passkey/begin when mfa_methods includes passkey. Once the password is accepted, responses carry a device_token to send on later logins from the same device, which then count failed attempts separately from unknown devices. Repeated failures lock logins from such devices (423 account_locked) for a delay that grows with each further failure, per the tenant's lockout policy.",
+                "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "auth"
                 ],
-                "summary": "Change a user's role",
+                "summary": "Log in",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Login credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "tenant_required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.TenantRequiredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid_credentials, account_disabled, mfa_required, mfa_enrollment_required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "password_login_disabled",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "account_locked",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
                     },
+                    "429": {
+                        "description": "rate_limit_exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
                     {
-                        "description": "New role",
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke the current session's refresh token and the access token used to call this endpoint. Idempotent - always returns 204.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log out",
+                "parameters": [
+                    {
+                        "description": "Refresh token to revoke",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/auth/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return the authenticated user's identity, current tenant/role and permissions, from the access token claims. impersonated_by is set while another user is impersonating this one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Whether the authenticated user has MFA enabled, whether their current role requires it, and how many recovery codes they have left.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Get MFA status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MFAStatusResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove the authenticated user's TOTP factor and recovery codes after re-verifying a current TOTP or recovery code. Users whose role requires MFA must enroll again at their next login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Disable MFA",
+                "parameters": [
+                    {
+                        "description": "TOTP or recovery code",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.UpdateRoleRequest"
+                            "$ref": "#/definitions/internal_auth_handler.MFACodeRequest"
                         }
                     }
                 ],
@@ -553,23 +732,54 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                            "$ref": "#/definitions/internal_auth_handler.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "invalid_id, invalid_role",
+                        "description": "invalid_request, mfa_code_invalid, mfa_not_enrolled",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "insufficient_role",
+                    "401": {
+                        "description": "unauthorized",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generate a new TOTP secret for the authenticated user. Add it to an authenticator app (e.g. by rendering otpauth_uri as a QR code), then confirm with /auth/mfa/enroll/confirm.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Start MFA enrollment",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MFAEnrollmentResponse"
+                        }
                     },
-                    "404": {
-                        "description": "not_found",
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "mfa_already_enrolled",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
@@ -577,292 +787,6150 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}/unlock": {
+        "/auth/mfa/enroll/confirm": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Manager+ clears a user's account lockout (FR-011a), resetting the failed-login counter.",
+                "description": "Activate the pending TOTP secret by submitting a current code. Returns recovery codes, shown only this once.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "mfa"
                 ],
-                "summary": "Clear an account lockout",
+                "summary": "Confirm MFA enrollment",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "TOTP code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MFACodeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                            "$ref": "#/definitions/internal_auth_handler.RecoveryCodesResponse"
                         }
                     },
                     "400": {
-                        "description": "invalid_id",
+                        "description": "invalid_request, mfa_code_invalid, mfa_not_enrolled",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "insufficient_role",
+                    "401": {
+                        "description": "unauthorized",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "not_found",
+                    "409": {
+                        "description": "mfa_already_enrolled",
                         "schema": {
                             "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "github_com_solobueno_erp_internal_auth_domain.Role": {
-            "type": "string",
-            "enum": [
-                "owner",
-                "admin",
-                "manager",
-                "cashier",
-                "waiter",
-                "kitchen",
-                "viewer"
-            ],
-            "x-enum-varnames": [
-                "RoleOwner",
-                "RoleAdmin",
-                "RoleManager",
-                "RoleCashier",
-                "RoleWaiter",
-                "RoleKitchen",
-                "RoleViewer"
-            ]
         },
-        "internal_auth_handler.ChangePasswordRequest": {
-            "type": "object",
-            "properties": {
-                "current_password": {
-                    "type": "string"
-                },
-                "new_password": {
-                    "type": "string"
+        "/auth/mfa/passkey/begin": {
+            "post": {
+                "description": "For a login that returned 401 mfa_required with passkey among its mfa_methods: get the options for navigator.credentials.get(), limited to the user's passkeys. Post the resulting credential to /auth/mfa/passkey/verify.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Start completing MFA with a passkey",
+                "parameters": [
+                    {
+                        "description": "Challenge token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MFASetupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasskeyRequestOptionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, mfa_not_enrolled",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "mfa_challenge_invalid, account_disabled, tenant_inactive",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "passkeys_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/passkey/verify": {
+            "post": {
+                "description": "Exchange the mfa_token from a 401 mfa_required login response plus the credential returned by navigator.credentials.get() for tokens.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Complete MFA with a passkey",
+                "parameters": [
+                    {
+                        "description": "Challenge token and credential",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasskeyMFARequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_tenant",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "mfa_challenge_invalid, passkey_invalid, passkey_challenge_invalid, account_disabled, tenant_inactive",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "passkeys_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Invalidate all existing recovery codes and issue a new set, after re-verifying a current TOTP or recovery code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Regenerate MFA recovery codes",
+                "parameters": [
+                    {
+                        "description": "TOTP or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MFACodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, mfa_code_invalid, mfa_not_enrolled",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/setup": {
+            "post": {
+                "description": "For a user whose login returned mfa_enrollment_required: generate a TOTP secret using the mfa_token as proof of the password step. Confirm it by submitting a code to /auth/mfa/verify.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Start MFA enrollment during login",
+                "parameters": [
+                    {
+                        "description": "Challenge token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MFASetupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MFAEnrollmentResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "mfa_challenge_invalid",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "mfa_already_enrolled",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/verify": {
+            "post": {
+                "description": "Exchange the mfa_token from a 401 mfa_required / mfa_enrollment_required login response plus a TOTP code (or a recovery code) for tokens. When completing a first-time enrollment, the response also carries the user's recovery codes, shown only this once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Complete MFA login",
+                "parameters": [
+                    {
+                        "description": "Challenge token and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MFAVerifyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, mfa_not_enrolled",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "mfa_challenge_invalid, mfa_code_invalid, account_disabled, tenant_inactive",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/ownership-transfer/confirm": {
+            "post": {
+                "description": "The recipient of an ownership transfer redeems the emailed token (72-hour TTL, single use) and re-authenticates with their current password. They become the tenant's owner and the previous owner takes their old role. Both are signed out of existing access tokens so the new roles take effect, and both are notified by email.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Confirm an ownership transfer",
+                "parameters": [
+                    {
+                        "description": "Transfer token and password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ConfirmOwnershipTransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OwnershipTransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "token_invalid, token_expired, token_used, transfer_cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid_credentials, account_disabled",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "account_locked",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkeys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the passkeys the authenticated user has registered, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "passkeys"
+                ],
+                "summary": "List my passkeys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasskeyListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkeys/login/begin": {
+            "post": {
+                "description": "Get the options for navigator.credentials.get() to sign in without a password. The browser offers the passkeys saved for this site; post the resulting credential to /auth/passkeys/login/finish within 5 minutes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "passkeys"
+                ],
+                "summary": "Start a passkey login",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasskeyRequestOptionsResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "passkeys_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkeys/login/finish": {
+            "post": {
+                "description": "Verify the credential returned by navigator.credentials.get() and issue tokens. The passkey verifies the user with a PIN or biometric, so no MFA step follows. Only managers, admins and owners may sign in with a passkey. If the user belongs to multiple tenants and none is specified, returns 400 tenant_required with the list of choices; start a new login with tenant_id set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "passkeys"
+                ],
+                "summary": "Finish a passkey login",
+                "parameters": [
+                    {
+                        "description": "Credential and optional tenant",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasskeyLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, tenant_required, invalid_tenant",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "passkey_invalid, passkey_challenge_invalid, account_disabled, tenant_inactive",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "passkey_not_allowed, password_login_disabled",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "account_locked",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limit_exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "passkeys_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkeys/register/begin": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the options for navigator.credentials.create() to add a passkey to the authenticated user. Only managers, admins and owners may register passkeys. Post the resulting credential to /auth/passkeys/register/finish within 5 minutes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "passkeys"
+                ],
+                "summary": "Start registering a passkey",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasskeyCreationOptionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "passkey_not_allowed, impersonation_not_allowed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "passkey_limit",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "passkeys_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkeys/register/finish": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Verify the credential returned by navigator.credentials.create() and store it as a passkey. The passkey can then sign the user in without a password, or serve as their second factor after one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "passkeys"
+                ],
+                "summary": "Finish registering a passkey",
+                "parameters": [
+                    {
+                        "description": "Credential and optional name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasskeyRegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasskeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, passkey_name",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized, passkey_invalid, passkey_challenge_invalid",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "passkey_not_allowed, impersonation_not_allowed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "passkey_limit, passkey_exists",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "passkeys_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkeys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a passkey so it can no longer sign the user in. Users whose role requires MFA and who are left with no second factor must enroll again at their next login.",
+                "tags": [
+                    "passkeys"
+                ],
+                "summary": "Remove one of my passkeys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Passkey ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "impersonation_not_allowed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "passkey_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the name a passkey is listed under. An empty name resets it to \"Passkey\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "passkeys"
+                ],
+                "summary": "Rename one of my passkeys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Passkey ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.RenamePasskeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasskeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id, invalid_request, passkey_name",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "passkey_not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password-policy": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ views the rules for members' passwords. Tenants that haven't set a policy get the default: at least 8 characters with an uppercase letter, a lowercase letter and a digit, no expiry and no reuse check.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get the tenant's password policy",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasswordPolicyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ replaces the rules for members' passwords. min_length is 8 to 128, max_age_days 0 (never expire) to 365 and history_count 0 (reuse allowed) to 24, counting the current password. Members in several tenants follow the strictest combination of their tenants' policies. New rules apply when a password is next set; a password older than max_age_days must be reset at the member's next login. Breached passwords are always refused.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Set the tenant's password policy",
+                "parameters": [
+                    {
+                        "description": "Password rules",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasswordPolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasswordPolicyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, password_policy_invalid",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, impersonation_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password-reset/complete": {
+            "post": {
+                "description": "Set a new password using a valid, unexpired, unused reset token (1-hour TTL). The new password must meet the strictest policy of the user's tenants and not be a breached or recently used password; a password_weak error names the rule broken in its rule field and leaves the token usable. Invalidates all sessions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Complete password reset",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasswordResetCompleteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "token_invalid, token_expired, token_used, password_weak, email_not_verified",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password-reset/request": {
+            "post": {
+                "description": "Send a password reset token to the given email if it belongs to an account whose email is verified. Always returns 202 to prevent email enumeration.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request password reset",
+                "parameters": [
+                    {
+                        "description": "Email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PasswordResetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MessageResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limit_exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/pin": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set or replace the authenticated user's 4-6 digit staff PIN for the current tenant, confirmed with their password. Repeated digits and straight runs are rejected. Cashier and lower roles log in with their PIN; manager and higher roles can only use it to approve actions on a terminal.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pin"
+                ],
+                "summary": "Set my PIN",
+                "parameters": [
+                    {
+                        "description": "Current password and new PIN",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.SetPINRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_request, current_password_incorrect, pin_invalid_format, pin_too_simple",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove the authenticated user's staff PIN for the current tenant, turning off PIN login for them.",
+                "tags": [
+                    "pin"
+                ],
+                "summary": "Remove my PIN",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/pin-login": {
+            "post": {
+                "description": "Fast user switching on a shared POS terminal. The terminal authenticates with its device token; the staff member picks themselves from /auth/terminal/staff and enters their PIN. Returns a short-lived access token scoped to the terminal's tenant, with no refresh token. Only cashier and lower roles can use a PIN.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pin"
+                ],
+                "summary": "Log in with a staff PIN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Terminal device token",
+                        "name": "X-Terminal-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Staff member and PIN",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PINLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PINLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "terminal_invalid, invalid_pin, account_disabled, tenant_inactive",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "pin_not_allowed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "pin_locked",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limit_exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchange a valid refresh token for a new access/refresh token pair (rotates the refresh token).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.TokenResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "token_invalid, session_revoked, token_expired, account_disabled, mfa_sign_in_required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/scim/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ lists the tenant's SCIM tokens that have not been revoked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scim"
+                ],
+                "summary": "List SCIM tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.SCIMTokenListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ creates a bearer token for the tenant's HR system or identity provider to provision users over SCIM 2.0 at /scim/v2. The token is shown only this once. SCIM clients act with admin rights: they can create users, move them between the roles below admin and deprovision them, but never touch admins or owners.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scim"
+                ],
+                "summary": "Create a SCIM token",
+                "parameters": [
+                    {
+                        "description": "Token name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CreateSCIMTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CreateSCIMTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/scim/tokens/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ revokes one of the tenant's SCIM tokens so it stops working. Users it provisioned are kept.",
+                "tags": [
+                    "scim"
+                ],
+                "summary": "Revoke a SCIM token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCIM token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the devices the authenticated user is signed in on, across all tenants. The session the request was made from is flagged as current.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "List my sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.SessionListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions/revoke-others": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke every session of the authenticated user except the one the request was made from.",
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Log out everywhere else",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "session_unknown",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sign the authenticated user out of one of their devices. Access tokens already issued to that device remain valid until they expire.",
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Revoke one of my sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sso/callback": {
+            "post": {
+                "description": "Finish an SSO login with the state and code the identity provider sent back. The provider account is linked to an existing member of the tenant by verified email in one of the tenant's allowed domains; accounts are never created here. MFA is left to the identity provider, so it only signs in roles below that of the member who last saved the SSO configuration, and never Owners; others get sso_role_not_allowed and sign in with a password.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sso"
+                ],
+                "summary": "Complete an SSO login",
+                "parameters": [
+                    {
+                        "description": "State and code from the identity provider",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.SSOCallbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, sso_state_invalid",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "sso_failed, account_disabled, tenant_inactive",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "sso_email_not_allowed, sso_account_not_found, sso_role_not_allowed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "sso_not_configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "sso_provider_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sso/config": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ views the tenant's identity provider settings. The client secret is never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sso"
+                ],
+                "summary": "Get the tenant's SSO configuration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.SSOConfigResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "sso_not_configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ sets the tenant's OpenID Connect identity provider. The issuer must use https and is discovered before saving. The client secret is required the first time and kept when omitted afterwards. SSO signs in only roles below the caller's, never owners. With password_login_disabled, the members it signs in can only sign in to the tenant through SSO; everyone else keeps their password.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sso"
+                ],
+                "summary": "Set up the tenant's SSO",
+                "parameters": [
+                    {
+                        "description": "Identity provider settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.SSOConfigRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.SSOConfigResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, sso_config_invalid",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "sso_provider_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ removes the tenant's identity provider. Password login is allowed again for everyone; existing identity links are kept for if SSO is set up again with the same provider.",
+                "tags": [
+                    "sso"
+                ],
+                "summary": "Remove the tenant's SSO",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "sso_not_configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sso/start": {
+            "post": {
+                "description": "Begin signing in to a tenant through its identity provider. Send the user's browser to the returned authorization URL; the provider redirects back to the configured redirect URI with state and code, which the client posts to /auth/sso/callback within 10 minutes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sso"
+                ],
+                "summary": "Start an SSO login",
+                "parameters": [
+                    {
+                        "description": "Tenant to sign in to",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.SSOStartRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.SSOStartResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "tenant_inactive",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "sso_not_configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "sso_provider_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/switch-tenant": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move the current session to another tenant the user belongs to, without re-entering credentials. Returns a new token pair scoped to the target tenant; the current session and the access token used to call this endpoint are revoked. A user without MFA whose role in the target tenant requires it gets 401 mfa_enrollment_required with an mfa_token, and keeps the current session. A session signed in through SSO only switches freely to tenants whose SSO would accept the same login; for other tenants, users with MFA get 401 mfa_required with an mfa_token to verify, and others 403 sign_in_required. Either way they keep the current session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Switch tenant",
+                "parameters": [
+                    {
+                        "description": "Target tenant",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.SwitchTenantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_tenant, same_tenant",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized, session_required, session_revoked, account_disabled, tenant_inactive, mfa_required, mfa_enrollment_required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "password_login_disabled, sign_in_required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/terminal/staff": {
+            "get": {
+                "description": "List the staff a terminal can offer for PIN login: active members of its tenant, at cashier level or below, who have set a PIN.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pin"
+                ],
+                "summary": "List staff for PIN login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Terminal device token",
+                        "name": "X-Terminal-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.TerminalStaffResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "terminal_invalid",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/terminals": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the current tenant's registered terminals. Requires Manager+.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pin"
+                ],
+                "summary": "List POS terminals",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.TerminalListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register a shared device for staff PIN login in the current tenant. The response carries the terminal's device token, shown only this once; configure it on the device as the X-Terminal-Token header. Requires Manager+.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pin"
+                ],
+                "summary": "Register a POS terminal",
+                "parameters": [
+                    {
+                        "description": "Terminal name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.RegisterTerminalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.RegisterTerminalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/terminals/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke one of the current tenant's terminals so its device token stops working. Access tokens already issued on it remain valid until they expire. Requires Manager+.",
+                "tags": [
+                    "pin"
+                ],
+                "summary": "Revoke a POS terminal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Terminal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/unlock": {
+            "post": {
+                "description": "Lift a lockout after failed logins using the token from an unlock link, on every device.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Unlock account",
+                "parameters": [
+                    {
+                        "description": "Unlock token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.EmailTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "token_invalid, token_expired, token_used",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/unlock/request": {
+            "post": {
+                "description": "Send an unlock link (1-hour TTL) to the given email if it belongs to an account that is locked out after failed logins and whose email is verified. Always returns 202 to prevent email enumeration.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request account unlock",
+                "parameters": [
+                    {
+                        "description": "Email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.AccountUnlockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.MessageResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limit_exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/authorize": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Validates an OAuth authorization request (RFC 6749 section 4.1.1) for the consent screen, with the client, the scopes it asks for and any it was already granted. The owner of the current tenant must approve it with POST /oauth/authorize. A request that is invalid in a way the client must hear of returns only redirect_to, carrying the error back to the client. PKCE with S256 is required; scope defaults to all of the client's scopes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Check an authorization request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Must be code",
+                        "name": "response_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "One of the client's redirect URIs; optional if it has only one",
+                        "name": "redirect_uri",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Space-separated permissions",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque value returned to the client",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code challenge",
+                        "name": "code_challenge",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Must be S256",
+                        "name": "code_challenge_method",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OAuthAuthorizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_client, invalid_redirect_uri",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The owner of the current tenant approves or denies an OAuth authorization request, with the same parameters as GET /oauth/authorize. Send the user to redirect_to: on approval it carries an authorization code, valid for 10 minutes, and the tenant's consent for the client is recorded, replacing any earlier one; on denial it carries access_denied.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Approve or deny an authorization request",
+                "parameters": [
+                    {
+                        "description": "Authorization decision",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OAuthAuthorizeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OAuthRedirectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_client, invalid_redirect_uri",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/clients": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ lists the OAuth clients the current tenant has registered, ordered by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "List OAuth clients",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OAuthClientListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ registers a third-party app, such as a reservation or delivery app, that the owner of any tenant can then authorize. Its scopes are the most any tenant can grant it, and must be ones you hold yourself. A confidential client gets a secret, shown only this once; a public client (a mobile or browser app) gets none and relies on PKCE.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Register an OAuth client",
+                "parameters": [
+                    {
+                        "description": "Client",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CreateOAuthClientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CreateOAuthClientResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_name, invalid_redirect_uri, unknown_permission",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, impersonation_not_allowed, permission_not_held, service_account_forbidden, oauth_client_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/clients/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ gets one of the OAuth clients the current tenant has registered.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Get an OAuth client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OAuthClientResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ deletes an OAuth client, withdrawing every tenant's authorization of it. Its tokens stop working at once.",
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Delete an OAuth client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ renames an OAuth client or replaces its redirect URIs or scopes. Narrowed scopes apply to the client's next tokens in every tenant.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Update an OAuth client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.UpdateOAuthClientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OAuthClientResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id, invalid_request, invalid_name, invalid_redirect_uri, unknown_permission",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, impersonation_not_allowed, permission_not_held, service_account_forbidden, oauth_client_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/clients/{id}/secret": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ replaces a confidential client's secret with a new one, shown only this once. The old secret stops working at once; tokens already issued are unaffected.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Rotate a client secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OAuthClientSecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id, public_client",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/consents": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The owner lists the OAuth clients the current tenant has authorized, with the scopes each was granted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "List authorized apps",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OAuthConsentListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/consents/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The owner withdraws the current tenant's authorization of an OAuth client. The client's tokens for the tenant stop working at once.",
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Revoke an app's access",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Consent ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/revoke": {
+            "post": {
+                "description": "OAuth 2.0 token revocation (RFC 7009). A client revokes one of its refresh tokens, ending the whole grant, or one of its access tokens. Clients authenticate as at the token endpoint. Unknown tokens are ignored, so the response is the same whether or not anything was revoked.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Revoke a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token to revoke",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "access_token or refresh_token",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID, if not sent with HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret, if not sent with HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "invalid_request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OAuthErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid_client",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OAuthErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/token": {
+            "post": {
+                "description": "OAuth 2.0 token endpoint (RFC 6749 section 3.2) for the authorization_code (with code_verifier), refresh_token and client_credentials grants. Clients authenticate with HTTP Basic or client_id and client_secret in the form; public clients send only client_id. Refresh tokens are rotated on every use, and replaying a used code or refresh token revokes the grant. Access tokens are JWTs verifiable at /.well-known/jwks.json, carrying the client in the client_id claim.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Token endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "authorization_code, refresh_token or client_credentials",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Redirect URI the code was issued to",
+                        "name": "redirect_uri",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code verifier",
+                        "name": "code_verifier",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refresh token",
+                        "name": "refresh_token",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Space-separated permissions, narrowing the grant",
+                        "name": "scope",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID, if not sent with HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret, if not sent with HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_solobueno_erp_pkg_oauth.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_grant, unauthorized_client, unsupported_grant_type, invalid_scope",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OAuthErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid_client",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OAuthErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ lists the current tenant's custom roles, ordered by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List custom roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CustomRoleListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ creates a custom role in the current tenant. It ranks as its base role (which must be lower than your own and can't be changed later) and grants exactly the listed permissions, each of which you must hold yourself.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Create a custom role",
+                "parameters": [
+                    {
+                        "description": "Custom role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CreateCustomRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CustomRoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_name, invalid_role, unknown_permission",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, permission_not_held",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "role_exists",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List every permission the modules declare, with the built-in roles granted it by default. Custom roles are built from these.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List permissions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.PermissionListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ gets one of the current tenant's custom roles.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Get a custom role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Custom role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CustomRoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ deletes a custom role. A role still assigned to someone can't be deleted; give them another role first.",
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Delete a custom role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Custom role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "role_in_use",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ renames a custom role or replaces its permissions. Everyone holding the role has their access tokens revoked, so they pick up the new permissions on refresh.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Update a custom role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Custom role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.UpdateCustomRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CustomRoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id, invalid_request, invalid_name, unknown_permission",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, permission_not_held",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "role_exists",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ lists the current tenant's service accounts, ordered by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "List service accounts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ServiceAccountListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, service_account_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ creates a service account for an integration such as an accounting sync or a delivery platform. It can do exactly what its scopes allow, each of which you must hold yourself, and never reaches the user, role or auth endpoints meant for people. Create an API key for it to authenticate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "Create a service account",
+                "parameters": [
+                    {
+                        "description": "Service account",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CreateServiceAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ServiceAccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_name, unknown_permission",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, permission_not_held, service_account_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ gets one of the current tenant's service accounts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "Get a service account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ServiceAccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, service_account_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ deletes a service account. Its API keys stop working at once.",
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "Delete a service account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, service_account_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ renames a service account, changes its description or replaces its scopes. New scopes apply to the account's next request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "Update a service account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.UpdateServiceAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ServiceAccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id, invalid_request, invalid_name, unknown_permission",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, permission_not_held, service_account_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts/{id}/keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ lists a service account's API keys that have not been revoked, including expired ones. Keys are identified by their prefix; the keys themselves are never shown again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "List API keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.APIKeyListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, service_account_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ creates an API key for a service account. The key is shown only this once; send it as a bearer token. Keys expire after expires_in_days (default 90, at most 365).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "Create an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "API key",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CreateAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id, invalid_request, invalid_expiry",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, service_account_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts/{id}/keys/{keyId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ revokes an API key. It stops working at once.",
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, service_account_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts/{id}/keys/{keyId}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin+ replaces an API key with a new one, shown only this once. The old key keeps working for grace_period_hours (at most 168) so the integration can switch over, or stops at once if it is 0.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "Rotate an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rotation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.RotateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.CreateAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id, invalid_request, invalid_expiry",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role, service_account_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List users in the current tenant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.UserListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/invitations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ lists the tenant's invitations that have not been accepted or revoked, including expired ones that can be resent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List pending invitations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.InvitationListResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ invites a person by email. The invitee receives a single-use link (valid 7 days) to set their own password, or, if the email already has an account, to confirm it and join this tenant. The invite token is never returned in the response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Invite someone to the tenant",
+                "parameters": [
+                    {
+                        "description": "Invitee details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.InviteUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.InvitationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_role, email_exists",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "invitation_pending",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/invitations/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ cancels a pending invitation so its link can no longer be used.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invitation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Invitation revoked"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/invitations/{id}/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ emails a fresh invite link and restarts the 7-day expiry. The previous link stops working.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Resend an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invitation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.InvitationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/ownership-transfer": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Owner offers the owner role to another active member of the current tenant. The recipient is emailed a confirmation link (72-hour TTL, single use); nothing changes until they confirm it with their password, at which point the two members' roles are swapped. Only one transfer can be pending per tenant.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Start an ownership transfer",
+                "parameters": [
+                    {
+                        "description": "Recipient",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.StartOwnershipTransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.OwnershipTransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_target",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "transfer_pending",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Owner withdraws the current tenant's pending ownership transfer; its confirmation link stops working.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Cancel the pending ownership transfer",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ updates a user's name or active status. Deactivating revokes all their sessions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update a user's profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id, invalid_request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/impersonate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sign in as a lower-ranked user of the current tenant to see exactly what they see, without their password. Requires the users.impersonate permission (Admin+ by default). Returns a 15-minute access token for the user, with no refresh token, that carries the caller in its act claim. Requests made with it can't change passwords, MFA or PINs, or start another impersonation; GET /auth/me reports impersonated_by; and every audit event and access log entry names the caller as actor. To stop, log out with the token (POST /auth/logout) or discard it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Impersonate a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the audit trail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ImpersonateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ImpersonationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id, invalid_request, cannot_impersonate_self",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_permission, insufficient_role, account_disabled, impersonation_forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/role": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ changes a user's role in the current tenant, to a built-in role or one of the tenant's custom roles (custom_role_id, which takes precedence over role). A custom role ranks as its base role. Cannot assign or manage a role equal to or higher than your own.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change a user's role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.UpdateRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id, invalid_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/role/elevation": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ gives a user a higher role (or a custom role, via custom_role_id) in the current tenant from valid_from (default now) until valid_until, at most 7 days. The user's standing role is kept and applies again when the window ends, and the sessions issued under the elevated role are then revoked. A new elevation replaces any existing one. The same role hierarchy rules apply as for changing a role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Temporarily elevate a user's role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Elevated role and window",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ElevateRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id, invalid_request, invalid_role, invalid_window, not_an_elevation",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ ends a user's temporary role elevation early, or cancels one that hasn't started. The user reverts to their standing role and the sessions issued under the elevated role are revoked.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "End a role elevation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found, no_elevation",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ lists the devices a user is signed in on in the current tenant. Cannot view users with a role equal to or higher than your own.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List a user's sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.SessionListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ signs a user out of all their devices in the current tenant. Cannot manage users with a role equal to or higher than your own.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke a user's sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/sessions/{sessionId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ signs a user out of a specific device in the current tenant. Cannot manage users with a role equal to or higher than your own.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke one of a user's sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/tenants/{tenantId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ removes a user from the current tenant and signs them out of it. The account and the user's other tenants are not affected. Users may remove themselves; removing anyone else requires a role higher than theirs. The last owner of a tenant cannot be removed.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Remove a user from a tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (must be the current tenant)",
+                        "name": "tenantId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden or insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "last_owner",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Manager+ clears a user's account lockout (FR-011a), resetting the failed-login counter.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Clear an account lockout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_id",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient_role",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_auth_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "github_com_solobueno_erp_internal_auth_domain.Permission": {
+            "type": "string",
+            "enum": [
+                "users.manage",
+                "users.impersonate",
+                "terminals.manage"
+            ],
+            "x-enum-varnames": [
+                "PermUsersManage",
+                "PermUsersImpersonate",
+                "PermTerminalsManage"
+            ]
+        },
+        "github_com_solobueno_erp_internal_auth_domain.Role": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "admin",
+                "manager",
+                "cashier",
+                "waiter",
+                "kitchen",
+                "viewer",
+                "admin",
+                "viewer",
+                "viewer"
+            ],
+            "x-enum-varnames": [
+                "RoleOwner",
+                "RoleAdmin",
+                "RoleManager",
+                "RoleCashier",
+                "RoleWaiter",
+                "RoleKitchen",
+                "RoleViewer",
+                "SCIMRole",
+                "ServiceAccountRole",
+                "OAuthClientRole"
+            ]
+        },
+        "github_com_solobueno_erp_pkg_oauth.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_solobueno_erp_pkg_webauthn.AssertionResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "rawId": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "response": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.AssertionResponseData"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_solobueno_erp_pkg_webauthn.AssertionResponseData": {
+            "type": "object",
+            "properties": {
+                "authenticatorData": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "clientDataJSON": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "signature": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "userHandle": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "github_com_solobueno_erp_pkg_webauthn.AttestationResponse": {
+            "type": "object",
+            "properties": {
+                "attestationObject": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "clientDataJSON": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "transports": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_solobueno_erp_pkg_webauthn.AuthenticatorSelection": {
+            "type": "object",
+            "properties": {
+                "residentKey": {
+                    "type": "string"
+                },
+                "userVerification": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_solobueno_erp_pkg_webauthn.CreationOptions": {
+            "type": "object",
+            "properties": {
+                "attestation": {
+                    "type": "string"
+                },
+                "authenticatorSelection": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.AuthenticatorSelection"
+                },
+                "challenge": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "excludeCredentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.CredentialDescriptor"
+                    }
+                },
+                "pubKeyCredParams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.CredentialParameter"
+                    }
+                },
+                "rp": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.RelyingParty"
+                },
+                "timeout": {
+                    "description": "Milliseconds",
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.User"
+                }
+            }
+        },
+        "github_com_solobueno_erp_pkg_webauthn.CredentialDescriptor": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "transports": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_solobueno_erp_pkg_webauthn.CredentialParameter": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_solobueno_erp_pkg_webauthn.RegistrationResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "rawId": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "response": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.AttestationResponse"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_solobueno_erp_pkg_webauthn.RelyingParty": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_solobueno_erp_pkg_webauthn.RequestOptions": {
+            "type": "object",
+            "properties": {
+                "allowCredentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.CredentialDescriptor"
+                    }
+                },
+                "challenge": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "rpId": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "description": "Milliseconds",
+                    "type": "integer"
+                },
+                "userVerification": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_solobueno_erp_pkg_webauthn.User": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.APIKeyListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.APIKeyResponse"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "rotated_from_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.AcceptInvitationRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.AcceptInvitationResponse": {
+            "type": "object",
+            "properties": {
+                "existing_account": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                }
+            }
+        },
+        "internal_auth_handler.AccountUnlockRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.ApprovalRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_internal_auth_domain.Permission"
+                },
+                "approver_email": {
+                    "type": "string"
+                },
+                "approver_id": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "pin": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.ApprovalResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "approval_token": {
+                    "type": "string"
+                },
+                "approved_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.ChangePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.ConfirmOwnershipTransferRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.CreateAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in_days": {
+                    "description": "ExpiresInDays defaults to 90 and may be at most 365.",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "rotated_from_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.CreateCustomRoleRequest": {
+            "type": "object",
+            "properties": {
+                "base_role": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_internal_auth_domain.Role"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_solobueno_erp_internal_auth_domain.Permission"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.CreateOAuthClientRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "public": {
+                    "description": "Public registers a client that can't keep a secret, such as a mobile\nor browser app. It gets no secret and relies on PKCE.",
+                    "type": "boolean"
+                },
+                "redirect_uris": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_solobueno_erp_internal_auth_domain.Permission"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.CreateOAuthClientResponse": {
+            "type": "object",
+            "properties": {
+                "client_secret": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "redirect_uris": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.CreateSCIMTokenRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.CreateSCIMTokenResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.CreateServiceAccountRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_solobueno_erp_internal_auth_domain.Permission"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.CustomRoleListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.CustomRoleResponse"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.CustomRoleResponse": {
+            "type": "object",
+            "properties": {
+                "base_role": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.ElevateRoleRequest": {
+            "type": "object",
+            "properties": {
+                "custom_role_id": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_internal_auth_domain.Role"
+                },
+                "valid_from": {
+                    "description": "ValidFrom defaults to now.",
+                    "type": "string"
+                },
+                "valid_until": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.EmailChangeRequest": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.EmailTokenRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.ErrorDetail": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "device_token": {
+                    "description": "Set on login challenges once the password is accepted",
+                    "type": "string"
+                },
+                "locked_until": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "mfa_methods": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "mfa_token": {
+                    "type": "string"
+                },
+                "retry_after": {
+                    "type": "integer"
+                },
+                "rule": {
+                    "description": "Password policy rule a new password broke",
+                    "type": "string"
+                },
+                "tenants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.TenantOption"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/internal_auth_handler.ErrorDetail"
+                }
+            }
+        },
+        "internal_auth_handler.ImpersonateRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "Reason is recorded on the audit trail, e.g. a support ticket.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.ImpersonationResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "impersonated_by": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                }
+            }
+        },
+        "internal_auth_handler.InvitationListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.InvitationResponse"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.InvitationResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "existing_account": {
+                    "description": "ExistingAccount is set when sending or resending, if the email already\nhas an account that the invitee will confirm rather than create.",
+                    "type": "boolean"
+                },
+                "expired": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invited_by": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.InviteUserRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_internal_auth_domain.Role"
+                }
+            }
+        },
+        "internal_auth_handler.LockoutPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "base_delay_seconds": {
+                    "type": "integer"
+                },
+                "known_device_threshold": {
+                    "type": "integer"
+                },
+                "max_delay_seconds": {
+                    "type": "integer"
+                },
+                "unknown_device_threshold": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_auth_handler.LockoutPolicyResponse": {
+            "type": "object",
+            "properties": {
+                "base_delay_seconds": {
+                    "type": "integer"
+                },
+                "known_device_threshold": {
+                    "type": "integer"
+                },
+                "max_delay_seconds": {
+                    "type": "integer"
+                },
+                "unknown_device_threshold": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "device_token": {
+                    "description": "DeviceToken is the device_token an earlier login returned, marking\nthis as a known device with its own failed-login threshold.",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "device_token": {
+                    "description": "DeviceToken identifies this device on later logins; send it back as\nthe login request's device_token.",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "recovery_codes": {
+                    "description": "RecoveryCodes is only set on the login that completes a first-time MFA enrollment.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                }
+            }
+        },
+        "internal_auth_handler.MFACodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.MFAEnrollmentResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.MFASetupRequest": {
+            "type": "object",
+            "properties": {
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.MFAStatusResponse": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "recovery_codes_remaining": {
+                    "type": "integer"
+                },
+                "required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_auth_handler.MFAVerifyRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "TOTP code or recovery code",
+                    "type": "string"
+                },
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.MeResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "impersonated_by": {
+                    "description": "ImpersonatedBy is set while another user is impersonating this one;\nclients should show it prominently.",
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "must_reset_password": {
+                    "type": "boolean"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "tenant_name": {
+                    "type": "string"
+                },
+                "tenants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.TenantRoleInfo"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.OAuthAuthorizationResponse": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "$ref": "#/definitions/internal_auth_handler.OAuthClientSummary"
+                },
+                "consented_scopes": {
+                    "description": "ConsentedScopes are the scopes the tenant has already granted the\nclient, if any.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "redirect_to": {
+                    "type": "string"
+                },
+                "redirect_uri": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.OAuthAuthorizeRequest": {
+            "type": "object",
+            "properties": {
+                "approve": {
+                    "type": "boolean"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "code_challenge": {
+                    "type": "string"
+                },
+                "code_challenge_method": {
+                    "type": "string"
+                },
+                "redirect_uri": {
+                    "type": "string"
+                },
+                "response_type": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.OAuthClientListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.OAuthClientResponse"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.OAuthClientResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "redirect_uris": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.OAuthClientSecretResponse": {
+            "type": "object",
+            "properties": {
+                "client_secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.OAuthClientSummary": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.OAuthConsentListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.OAuthConsentResponse"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.OAuthConsentResponse": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "$ref": "#/definitions/internal_auth_handler.OAuthClientSummary"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "granted_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.OAuthErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "error_description": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.OAuthRedirectResponse": {
+            "type": "object",
+            "properties": {
+                "redirect_to": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.OwnershipTransferResponse": {
+            "type": "object",
+            "properties": {
+                "confirmed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "from_user_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "previous_owner_role": {
+                    "description": "PreviousOwnerRole is set once confirmed: the role the previous owner\nnow holds (the recipient's role before the swap).",
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "to_user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.PINLoginRequest": {
+            "type": "object",
+            "properties": {
+                "pin": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.PINLoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "token_type": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                }
+            }
+        },
+        "internal_auth_handler.Pagination": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_auth_handler.PasskeyCreationOptionsResponse": {
+            "type": "object",
+            "properties": {
+                "publicKey": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.CreationOptions"
+                }
+            }
+        },
+        "internal_auth_handler.PasskeyListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.PasskeyResponse"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.PasskeyLoginRequest": {
+            "type": "object",
+            "properties": {
+                "credential": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.AssertionResponse"
+                },
+                "tenant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.PasskeyMFARequest": {
+            "type": "object",
+            "properties": {
+                "credential": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.AssertionResponse"
+                },
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.PasskeyRegisterRequest": {
+            "type": "object",
+            "properties": {
+                "credential": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.RegistrationResponse"
+                },
+                "name": {
+                    "description": "Defaults to \"Passkey\"",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.PasskeyRequestOptionsResponse": {
+            "type": "object",
+            "properties": {
+                "publicKey": {
+                    "$ref": "#/definitions/github_com_solobueno_erp_pkg_webauthn.RequestOptions"
+                }
+            }
+        },
+        "internal_auth_handler.PasskeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "synced": {
+                    "description": "Synced is true for passkeys that sync between the user's devices.",
+                    "type": "boolean"
+                },
+                "transports": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.PasswordPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "history_count": {
+                    "description": "0 = reuse allowed",
+                    "type": "integer"
+                },
+                "max_age_days": {
+                    "description": "0 = passwords never expire",
+                    "type": "integer"
+                },
+                "min_length": {
+                    "type": "integer"
+                },
+                "require_digit": {
+                    "type": "boolean"
+                },
+                "require_lowercase": {
+                    "type": "boolean"
+                },
+                "require_symbol": {
+                    "type": "boolean"
+                },
+                "require_uppercase": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_auth_handler.PasswordPolicyResponse": {
+            "type": "object",
+            "properties": {
+                "history_count": {
+                    "type": "integer"
+                },
+                "max_age_days": {
+                    "type": "integer"
+                },
+                "min_length": {
+                    "type": "integer"
+                },
+                "require_digit": {
+                    "type": "boolean"
+                },
+                "require_lowercase": {
+                    "type": "boolean"
+                },
+                "require_symbol": {
+                    "type": "boolean"
+                },
+                "require_uppercase": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.PasswordResetCompleteRequest": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.PasswordResetRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.PermissionListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.PermissionResponse"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.PermissionResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "permission": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.RegisterTerminalRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.RegisterTerminalResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "terminal_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.RenamePasskeyRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.RoleElevationResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active is true while the elevated role applies.",
+                    "type": "boolean"
+                },
+                "custom_role": {
+                    "type": "string"
+                },
+                "custom_role_id": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "valid_until": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.RotateAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in_days": {
+                    "description": "ExpiresInDays is the new key's lifetime, as for CreateAPIKeyRequest.",
+                    "type": "integer"
+                },
+                "grace_period_hours": {
+                    "description": "GracePeriodHours is how long the old key keeps working, at most 168\n(7 days). Zero revokes it right away.",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_auth_handler.SCIMTokenListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.SCIMTokenResponse"
+                    }
                 }
             }
         },
-        "internal_auth_handler.CreateUserRequest": {
+        "internal_auth_handler.SCIMTokenResponse": {
             "type": "object",
             "properties": {
-                "email": {
+                "created_at": {
                     "type": "string"
                 },
-                "first_name": {
+                "created_by": {
                     "type": "string"
                 },
-                "last_name": {
+                "id": {
                     "type": "string"
                 },
-                "role": {
-                    "$ref": "#/definitions/github_com_solobueno_erp_internal_auth_domain.Role"
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
                 }
             }
         },
-        "internal_auth_handler.CreateUserResponse": {
+        "internal_auth_handler.SSOCallbackRequest": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "email": {
+                "code": {
                     "type": "string"
                 },
-                "first_name": {
+                "state": {
                     "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.SSOConfigRequest": {
+            "type": "object",
+            "properties": {
+                "allowed_domains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "id": {
+                "client_id": {
                     "type": "string"
                 },
-                "last_name": {
+                "client_secret": {
+                    "description": "ClientSecret may be omitted on update to keep the current one.",
                     "type": "string"
                 },
-                "linked_existing_account": {
-                    "type": "boolean"
-                },
-                "message": {
+                "issuer": {
                     "type": "string"
                 },
-                "must_reset_password": {
+                "password_login_disabled": {
                     "type": "boolean"
-                },
-                "role": {
-                    "type": "string"
                 }
             }
         },
-        "internal_auth_handler.ErrorDetail": {
+        "internal_auth_handler.SSOConfigResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "string"
+                "allowed_domains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "locked_until": {
+                "client_id": {
                     "type": "string"
                 },
-                "message": {
+                "client_secret_set": {
+                    "type": "boolean"
+                },
+                "issuer": {
                     "type": "string"
                 },
-                "retry_after": {
-                    "type": "integer"
+                "password_login_disabled": {
+                    "type": "boolean"
                 },
-                "tenants": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/internal_auth_handler.TenantOption"
-                    }
+                "redirect_uri": {
+                    "description": "Register this with the identity provider",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "internal_auth_handler.ErrorResponse": {
+        "internal_auth_handler.SSOStartRequest": {
             "type": "object",
             "properties": {
-                "error": {
-                    "$ref": "#/definitions/internal_auth_handler.ErrorDetail"
+                "tenant_slug": {
+                    "type": "string"
                 }
             }
         },
-        "internal_auth_handler.LoginRequest": {
+        "internal_auth_handler.SSOStartResponse": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string"
-                },
-                "tenant_id": {
+                "authorization_url": {
                     "type": "string"
                 }
             }
         },
-        "internal_auth_handler.LoginResponse": {
+        "internal_auth_handler.ServiceAccountListResponse": {
             "type": "object",
             "properties": {
-                "access_token": {
-                    "type": "string"
-                },
-                "expires_at": {
-                    "type": "string"
-                },
-                "expires_in": {
-                    "type": "integer"
-                },
-                "refresh_token": {
-                    "type": "string"
-                },
-                "token_type": {
-                    "type": "string"
-                },
-                "user": {
-                    "$ref": "#/definitions/internal_auth_handler.UserResponse"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.ServiceAccountResponse"
+                    }
                 }
             }
         },
-        "internal_auth_handler.MeResponse": {
+        "internal_auth_handler.ServiceAccountResponse": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "first_name": {
-                    "type": "string"
-                },
-                "id": {
+                "created_at": {
                     "type": "string"
                 },
-                "last_name": {
+                "created_by": {
                     "type": "string"
                 },
-                "must_reset_password": {
-                    "type": "boolean"
-                },
-                "role": {
+                "description": {
                     "type": "string"
                 },
-                "tenant_id": {
+                "id": {
                     "type": "string"
                 },
-                "tenant_name": {
+                "name": {
                     "type": "string"
                 },
-                "tenants": {
+                "scopes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/internal_auth_handler.TenantRoleInfo"
+                        "type": "string"
                     }
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "internal_auth_handler.MessageResponse": {
+        "internal_auth_handler.SessionListResponse": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.SessionResponse"
+                    }
                 }
             }
         },
-        "internal_auth_handler.Pagination": {
+        "internal_auth_handler.SessionResponse": {
             "type": "object",
             "properties": {
-                "limit": {
-                    "type": "integer"
+                "created_at": {
+                    "type": "string"
                 },
-                "page": {
-                    "type": "integer"
+                "current": {
+                    "description": "Current is true for the session the request was made from.",
+                    "type": "boolean"
                 },
-                "total": {
-                    "type": "integer"
+                "device_name": {
+                    "type": "string"
                 },
-                "total_pages": {
-                    "type": "integer"
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
                 }
             }
         },
-        "internal_auth_handler.PasswordResetCompleteRequest": {
+        "internal_auth_handler.SetPINRequest": {
             "type": "object",
             "properties": {
-                "new_password": {
+                "current_password": {
                     "type": "string"
                 },
-                "token": {
+                "pin": {
                     "type": "string"
                 }
             }
         },
-        "internal_auth_handler.PasswordResetRequest": {
+        "internal_auth_handler.StartOwnershipTransferRequest": {
             "type": "object",
             "properties": {
-                "email": {
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "internal_auth_handler.RefreshRequest": {
+        "internal_auth_handler.SwitchTenantRequest": {
             "type": "object",
             "properties": {
-                "refresh_token": {
+                "tenant_id": {
                     "type": "string"
                 }
             }
@@ -903,6 +6971,62 @@ const docTemplate = `{
                 }
             }
         },
+        "internal_auth_handler.TerminalListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.TerminalResponse"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.TerminalResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.TerminalStaffMember": {
+            "type": "object",
+            "properties": {
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_auth_handler.TerminalStaffResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_auth_handler.TerminalStaffMember"
+                    }
+                }
+            }
+        },
         "internal_auth_handler.TokenResponse": {
             "type": "object",
             "properties": {
@@ -923,14 +7047,68 @@ const docTemplate = `{
                 }
             }
         },
+        "internal_auth_handler.UpdateCustomRoleRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_solobueno_erp_internal_auth_domain.Permission"
+                    }
+                }
+            }
+        },
+        "internal_auth_handler.UpdateOAuthClientRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "redirect_uris": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_solobueno_erp_internal_auth_domain.Permission"
+                    }
+                }
+            }
+        },
         "internal_auth_handler.UpdateRoleRequest": {
             "type": "object",
             "properties": {
+                "custom_role_id": {
+                    "type": "string"
+                },
                 "role": {
                     "$ref": "#/definitions/github_com_solobueno_erp_internal_auth_domain.Role"
                 }
             }
         },
+        "internal_auth_handler.UpdateServiceAccountRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_solobueno_erp_internal_auth_domain.Permission"
+                    }
+                }
+            }
+        },
         "internal_auth_handler.UpdateUserRequest": {
             "type": "object",
             "properties": {
@@ -965,9 +7143,27 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "custom_role": {
+                    "type": "string"
+                },
+                "custom_role_id": {
+                    "description": "CustomRoleID and CustomRole identify the tenant custom role the user\nholds, if any; Role is then its base role.",
+                    "type": "string"
+                },
+                "elevation": {
+                    "description": "Elevation is the user's scheduled or running temporary elevation;\nRole and CustomRole are the standing role it reverts to.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_auth_handler.RoleElevationResponse"
+                        }
+                    ]
+                },
                 "email": {
                     "type": "string"
                 },
+                "email_verified": {
+                    "type": "boolean"
+                },
                 "first_name": {
                     "type": "string"
                 },
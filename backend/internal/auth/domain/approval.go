@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApprovalTTL is how long a step-up approval token stays usable. It only has
+// to outlive handing the device back to the cashier who asked for it.
+const ApprovalTTL = 2 * time.Minute
+
+// Ways an approver can confirm a step-up approval.
+const (
+	ApprovalMethodPassword = "password"
+	ApprovalMethodPIN      = "pin"
+)
+
+// Approval is a manager's step-up authorization of one sensitive action
+// (a refund, a void) by another user. The approver enters their password or
+// PIN on the requester's device; the requester gets back a short-lived,
+// single-use token (stored hashed) bound to the action, the resource and
+// themselves, which the module performing the action consumes.
+type Approval struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	Action      Permission `gorm:"type:varchar(100);not null" json:"action"`
+	ResourceID  string     `gorm:"size:255;not null" json:"resource_id"`
+	RequestedBy uuid.UUID  `gorm:"type:uuid;not null;index" json:"requested_by"`
+	ApprovedBy  uuid.UUID  `gorm:"type:uuid;not null;index" json:"approved_by"`
+	Method      string     `gorm:"size:20;not null" json:"method"`
+	TerminalID  *uuid.UUID `gorm:"type:uuid" json:"terminal_id,omitempty"`
+	TokenHash   string     `gorm:"uniqueIndex;size:255;not null" json:"-"` // Hashed token
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt      *time.Time `json:"used_at,omitempty"`
+}
+
+// TableName specifies the table name for GORM.
+func (Approval) TableName() string {
+	return "approvals"
+}
+
+// IsValid checks if the approval can still be used (not used and not expired).
+func (a *Approval) IsValid() bool {
+	return !a.IsUsed() && !a.IsExpired()
+}
+
+// IsExpired checks if the approval has expired.
+func (a *Approval) IsExpired() bool {
+	return time.Now().After(a.ExpiresAt)
+}
+
+// IsUsed checks if the approval has already been consumed.
+func (a *Approval) IsUsed() bool {
+	return a.UsedAt != nil
+}
+
+// Covers checks if the approval was granted to requesterID for the given
+// action on the given resource in the given tenant.
+func (a *Approval) Covers(tenantID, requesterID uuid.UUID, action Permission, resourceID string) bool {
+	return a.TenantID == tenantID && a.RequestedBy == requesterID && a.Action == action && a.ResourceID == resourceID
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestApproval_IsValid(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		approval Approval
+		want     bool
+	}{
+		{
+			name:     "valid approval",
+			approval: Approval{ExpiresAt: now.Add(time.Minute)},
+			want:     true,
+		},
+		{
+			name:     "expired approval",
+			approval: Approval{ExpiresAt: now.Add(-time.Second)},
+			want:     false,
+		},
+		{
+			name:     "used approval",
+			approval: Approval{ExpiresAt: now.Add(time.Minute), UsedAt: &now},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.approval.IsValid(); got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApproval_Covers(t *testing.T) {
+	tenantID, requesterID := uuid.New(), uuid.New()
+	approval := Approval{TenantID: tenantID, RequestedBy: requesterID, Action: "payments.refund", ResourceID: "pay-1"}
+
+	tests := []struct {
+		name        string
+		tenantID    uuid.UUID
+		requesterID uuid.UUID
+		action      Permission
+		resourceID  string
+		want        bool
+	}{
+		{"match", tenantID, requesterID, "payments.refund", "pay-1", true},
+		{"other tenant", uuid.New(), requesterID, "payments.refund", "pay-1", false},
+		{"other requester", tenantID, uuid.New(), "payments.refund", "pay-1", false},
+		{"other action", tenantID, requesterID, "orders.void", "pay-1", false},
+		{"other resource", tenantID, requesterID, "payments.refund", "pay-2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := approval.Covers(tt.tenantID, tt.requesterID, tt.action, tt.resourceID); got != tt.want {
+				t.Errorf("Covers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
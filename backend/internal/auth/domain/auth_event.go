@@ -27,9 +27,70 @@ const (
 	EventAccountLocked          AuthEventType = "account_locked"
 	EventAccountUnlocked        AuthEventType = "account_unlocked"
 	EventTenantRoleAdded        AuthEventType = "tenant_role_added"
+	EventTenantRoleRemoved      AuthEventType = "tenant_role_removed"
 	EventRoleChanged            AuthEventType = "role_changed"
 	EventSessionRevoked         AuthEventType = "session_revoked"
 	EventEmailDeliveryFailed    AuthEventType = "email_delivery_failed"
+	EventMFAEnrolled            AuthEventType = "mfa_enrolled"
+	EventMFADisabled            AuthEventType = "mfa_disabled"
+	EventMFASuccess             AuthEventType = "mfa_success"
+	EventMFAFailed              AuthEventType = "mfa_failed"
+	EventMFARecoveryCodesReset  AuthEventType = "mfa_recovery_codes_reset"
+	EventPINSet                 AuthEventType = "pin_set"
+	EventPINRemoved             AuthEventType = "pin_removed"
+	EventPINLocked              AuthEventType = "pin_locked"
+	EventTerminalRegistered     AuthEventType = "terminal_registered"
+	EventTerminalRevoked        AuthEventType = "terminal_revoked"
+	EventTenantSwitched         AuthEventType = "tenant_switched"
+	EventInvitationSent         AuthEventType = "invitation_sent"
+	EventInvitationResent       AuthEventType = "invitation_resent"
+	EventInvitationRevoked      AuthEventType = "invitation_revoked"
+	EventInvitationAccepted     AuthEventType = "invitation_accepted"
+	EventOwnerTransferStarted   AuthEventType = "ownership_transfer_started"
+	EventOwnerTransferCancelled AuthEventType = "ownership_transfer_cancelled"
+	EventOwnerTransferred       AuthEventType = "ownership_transferred"
+	EventCustomRoleCreated      AuthEventType = "custom_role_created"
+	EventCustomRoleUpdated      AuthEventType = "custom_role_updated"
+	EventCustomRoleDeleted      AuthEventType = "custom_role_deleted"
+	EventRoleElevated           AuthEventType = "role_elevated"
+	EventRoleElevationStarted   AuthEventType = "role_elevation_started"
+	EventRoleElevationEnded     AuthEventType = "role_elevation_ended"
+	EventRoleElevationExpired   AuthEventType = "role_elevation_expired"
+	EventApprovalGranted        AuthEventType = "approval_granted"
+	EventApprovalDenied         AuthEventType = "approval_denied"
+	EventApprovalUsed           AuthEventType = "approval_used"
+	EventImpersonationStarted   AuthEventType = "impersonation_started"
+	EventSSOConfigUpdated       AuthEventType = "sso_config_updated"
+	EventSSOConfigDeleted       AuthEventType = "sso_config_deleted"
+	EventSSOIdentityLinked      AuthEventType = "sso_identity_linked"
+	EventSCIMTokenCreated       AuthEventType = "scim_token_created"
+	EventSCIMTokenRevoked       AuthEventType = "scim_token_revoked"
+	EventServiceAccountCreated  AuthEventType = "service_account_created"
+	EventServiceAccountUpdated  AuthEventType = "service_account_updated"
+	EventServiceAccountDeleted  AuthEventType = "service_account_deleted"
+	EventAPIKeyCreated          AuthEventType = "api_key_created"
+	EventAPIKeyRotated          AuthEventType = "api_key_rotated"
+	EventAPIKeyRevoked          AuthEventType = "api_key_revoked"
+	EventOAuthClientCreated     AuthEventType = "oauth_client_created"
+	EventOAuthClientUpdated     AuthEventType = "oauth_client_updated"
+	EventOAuthClientDeleted     AuthEventType = "oauth_client_deleted"
+	EventOAuthSecretRotated     AuthEventType = "oauth_secret_rotated"
+	EventOAuthConsentGranted    AuthEventType = "oauth_consent_granted"
+	EventOAuthConsentRevoked    AuthEventType = "oauth_consent_revoked"
+	EventOAuthTokenReused       AuthEventType = "oauth_token_reused"
+	EventPasskeyRegistered      AuthEventType = "passkey_registered"
+	EventPasskeyRenamed         AuthEventType = "passkey_renamed"
+	EventPasskeyRemoved         AuthEventType = "passkey_removed"
+	EventPasskeyFailed          AuthEventType = "passkey_failed"
+	EventEmailVerificationSent  AuthEventType = "email_verification_sent"
+	EventEmailVerified          AuthEventType = "email_verified"
+	EventEmailChangeRequested   AuthEventType = "email_change_requested"
+	EventEmailChanged           AuthEventType = "email_changed"
+	EventEmailChangeReverted    AuthEventType = "email_change_reverted"
+	EventPasswordPolicyUpdated  AuthEventType = "password_policy_updated"
+	EventPasswordExpired        AuthEventType = "password_expired"
+	EventLockoutPolicyUpdated   AuthEventType = "lockout_policy_updated"
+	EventAccountUnlockRequested AuthEventType = "account_unlock_requested"
 )
 
 // String returns the string representation of the event type.
@@ -82,6 +143,7 @@ func (m Metadata) GormDataType() string {
 type AuthEvent struct {
 	ID        uuid.UUID     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	UserID    *uuid.UUID    `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	ActorID   *uuid.UUID    `gorm:"type:uuid;index" json:"actor_id,omitempty"` // Impersonator, when UserID was being impersonated
 	TenantID  *uuid.UUID    `gorm:"type:uuid;index" json:"tenant_id,omitempty"`
 	EventType AuthEventType `gorm:"size:50;not null;index" json:"event_type"`
 	IPAddress string        `gorm:"size:45" json:"ip_address,omitempty"`
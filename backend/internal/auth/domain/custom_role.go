@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxCustomRoleNameLength is the longest name a custom role may have.
+const MaxCustomRoleNameLength = 50
+
+// CustomRole is a tenant-defined role such as "bartender" or "shift lead".
+// It derives from a built-in base role, which decides where it sits in the
+// role hierarchy (who may manage it, whether it needs MFA, whether it may
+// PIN-login), and carries its own permission set in place of the base
+// role's defaults. The base role can't change once the custom role exists.
+type CustomRole struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	Name        string         `gorm:"size:50;not null" json:"name"`
+	BaseRole    Role           `gorm:"size:20;not null" json:"base_role"`
+	Permissions PermissionList `gorm:"type:jsonb;not null" json:"permissions"`
+	CreatedBy   uuid.UUID      `gorm:"type:uuid" json:"created_by"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Associations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (CustomRole) TableName() string {
+	return "custom_roles"
+}
+
+// HasPermission returns true if the custom role grants the permission.
+func (c *CustomRole) HasPermission(p Permission) bool {
+	return slices.Contains(c.Permissions, p)
+}
+
+// NormalizeCustomRoleName trims a custom role name and checks it is 1-50
+// characters and doesn't shadow a built-in role name.
+func NormalizeCustomRoleName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxCustomRoleNameLength {
+		return "", ErrCustomRoleName
+	}
+	for _, r := range AllRoles() {
+		if strings.EqualFold(name, string(r)) {
+			return "", ErrCustomRoleName
+		}
+	}
+	return name, nil
+}
+
+// PermissionList is a set of permissions stored as a JSON array.
+type PermissionList []Permission
+
+// Scan implements sql.Scanner interface for database reads.
+func (l *PermissionList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan type %T into PermissionList", value)
+	}
+
+	if len(bytes) == 0 {
+		*l = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
+// Value implements driver.Valuer interface for database writes. A nil list
+// is stored as an empty array, since the column is NOT NULL.
+func (l PermissionList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+// GormDataType implements GORM's custom type interface.
+func (l PermissionList) GormDataType() string {
+	return "jsonb"
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNormalizeCustomRoleName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"plain", "Bartender", "Bartender", false},
+		{"trimmed", "  Shift lead ", "Shift lead", false},
+		{"max length", strings.Repeat("a", MaxCustomRoleNameLength), strings.Repeat("a", MaxCustomRoleNameLength), false},
+		{"multibyte counts runes", strings.Repeat("ñ", MaxCustomRoleNameLength), strings.Repeat("ñ", MaxCustomRoleNameLength), false},
+		{"empty", "   ", "", true},
+		{"too long", strings.Repeat("a", MaxCustomRoleNameLength+1), "", true},
+		{"built-in role", "Manager", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeCustomRoleName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeCustomRoleName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err != nil && err != ErrCustomRoleName {
+				t.Errorf("error = %v, want ErrCustomRoleName", err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeCustomRoleName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionList_ScanValue(t *testing.T) {
+	list := PermissionList{PermUsersManage, PermTerminalsManage}
+	v, err := list.Value()
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+
+	var scanned PermissionList
+	if err := scanned.Scan(v); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if !slices.Equal(scanned, list) {
+		t.Errorf("Scan(Value()) = %v, want %v", scanned, list)
+	}
+
+	if err := scanned.Scan(`["users.manage"]`); err != nil || len(scanned) != 1 {
+		t.Errorf("Scan(string) = %v, %v", scanned, err)
+	}
+	if err := scanned.Scan(nil); err != nil || scanned != nil {
+		t.Errorf("Scan(nil) = %v, %v; want nil", scanned, err)
+	}
+	if err := scanned.Scan(42); err == nil {
+		t.Error("Scan(int) should fail")
+	}
+
+	// The column is NOT NULL, so an unset list is stored as []
+	empty, _ := PermissionList(nil).Value()
+	if string(empty.([]byte)) != "[]" {
+		t.Errorf("nil Value() = %s, want []", empty)
+	}
+}
+
+func TestUserTenantRole_CustomRole(t *testing.T) {
+	builtIn := UserTenantRole{Role: RoleManager}
+	if builtIn.RoleName() != "manager" {
+		t.Errorf("RoleName() = %q, want manager", builtIn.RoleName())
+	}
+	if !slices.Equal(builtIn.Permissions(), RoleManager.Permissions()) {
+		t.Errorf("Permissions() = %v, want the manager defaults", builtIn.Permissions())
+	}
+
+	id := uuid.New()
+	custom := UserTenantRole{
+		Role:         RoleWaiter,
+		CustomRoleID: &id,
+		CustomRole: &CustomRole{
+			ID: id, Name: "Floor lead", BaseRole: RoleWaiter,
+			Permissions: PermissionList{PermUsersManage},
+		},
+	}
+	if custom.RoleName() != "Floor lead" {
+		t.Errorf("RoleName() = %q, want Floor lead", custom.RoleName())
+	}
+	if got := custom.Permissions(); !slices.Equal(got, []Permission{PermUsersManage}) {
+		t.Errorf("Permissions() = %v, want only the custom role's", got)
+	}
+
+	user := User{TenantRoles: []UserTenantRole{custom}}
+	tenantID := uuid.New()
+	user.TenantRoles[0].TenantID = tenantID
+	if got := user.GetPermissionsForTenant(tenantID); !slices.Equal(got, []Permission{PermUsersManage}) {
+		t.Errorf("GetPermissionsForTenant() = %v, want the custom role's", got)
+	}
+	if got := user.GetPermissionsForTenant(uuid.New()); got != nil {
+		t.Errorf("GetPermissionsForTenant(other tenant) = %v, want nil", got)
+	}
+}
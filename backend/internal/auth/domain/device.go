@@ -0,0 +1,72 @@
+package domain
+
+import "strings"
+
+// UnknownDevice is the device name shown when a user agent can't be recognised.
+const UnknownDevice = "Unknown device"
+
+// userAgentToken maps a substring of a User-Agent header to a friendly name.
+// Lists are checked in order, so more specific tokens must come first
+// (e.g. Edge and Opera also advertise Chrome, and Chrome advertises Safari).
+type userAgentToken struct {
+	token string
+	name  string
+}
+
+var deviceBrowsers = []userAgentToken{
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"okhttp/", "Android app"},
+	{"CFNetwork/", "iOS app"},
+	{"curl/", "curl"},
+}
+
+var deviceOperatingSystems = []userAgentToken{
+	{"iPhone", "iPhone"},
+	{"iPad", "iPad"},
+	{"Android", "Android"},
+	{"CrOS", "ChromeOS"},
+	{"Windows", "Windows"},
+	{"Macintosh", "macOS"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// ParseDeviceName turns a User-Agent header into a short, human-friendly
+// device name such as "Chrome on Windows", for display in session lists.
+func ParseDeviceName(userAgent string) string {
+	browser := matchUserAgent(userAgent, deviceBrowsers)
+	os := matchUserAgent(userAgent, deviceOperatingSystems)
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return UnknownDevice
+	}
+}
+
+func matchUserAgent(userAgent string, tokens []userAgentToken) string {
+	for _, t := range tokens {
+		if strings.Contains(userAgent, t.token) {
+			return t.name
+		}
+	}
+	return ""
+}
+
+// DeviceName returns a friendly name for the device the session was created on.
+func (s *Session) DeviceName() string {
+	return ParseDeviceName(s.DeviceInfo)
+}
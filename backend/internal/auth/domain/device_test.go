@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestParseDeviceName(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      string
+	}{
+		{
+			name:      "chrome on windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want:      "Chrome on Windows",
+		},
+		{
+			name:      "edge on windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
+			want:      "Edge on Windows",
+		},
+		{
+			name:      "safari on macos",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_2) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+			want:      "Safari on macOS",
+		},
+		{
+			name:      "safari on iphone",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1",
+			want:      "Safari on iPhone",
+		},
+		{
+			name:      "chrome on ipad",
+			userAgent: "Mozilla/5.0 (iPad; CPU OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1",
+			want:      "Chrome on iPad",
+		},
+		{
+			name:      "firefox on linux",
+			userAgent: "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			want:      "Firefox on Linux",
+		},
+		{
+			name:      "samsung internet on android",
+			userAgent: "Mozilla/5.0 (Linux; Android 13; SM-S901B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Mobile Safari/537.36",
+			want:      "Samsung Internet on Android",
+		},
+		{
+			name:      "native android app",
+			userAgent: "okhttp/4.12.0",
+			want:      "Android app",
+		},
+		{
+			name:      "os only",
+			userAgent: "SomeKiosk/1.0 (Windows NT 10.0)",
+			want:      "Windows",
+		},
+		{
+			name:      "unrecognised",
+			userAgent: "Go-http-client/1.1",
+			want:      UnknownDevice,
+		},
+		{
+			name:      "empty",
+			userAgent: "",
+			want:      UnknownDevice,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseDeviceName(tt.userAgent); got != tt.want {
+				t.Errorf("ParseDeviceName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_DeviceName(t *testing.T) {
+	s := Session{DeviceInfo: "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"}
+	if got := s.DeviceName(); got != "Firefox on Linux" {
+		t.Errorf("Session.DeviceName() = %q, want %q", got, "Firefox on Linux")
+	}
+}
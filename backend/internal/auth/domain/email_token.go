@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Email token lifetimes.
+const (
+	// EmailVerificationTTL is how long a link verifying the current email works.
+	EmailVerificationTTL = 24 * time.Hour
+
+	// EmailChangeTTL is how long a link confirming a new email works.
+	EmailChangeTTL = time.Hour
+
+	// EmailChangeRevertTTL is how long the old address can undo a change.
+	EmailChangeRevertTTL = 7 * 24 * time.Hour
+
+	// AccountUnlockTTL is how long a link unlocking a locked account works.
+	AccountUnlockTTL = time.Hour
+)
+
+// EmailTokenPurpose is what following an emailed link does.
+type EmailTokenPurpose string
+
+// Email token purposes.
+const (
+	// EmailTokenVerify verifies the user's current email.
+	EmailTokenVerify EmailTokenPurpose = "verify"
+
+	// EmailTokenChange moves the user to a new email, sent to that address.
+	EmailTokenChange EmailTokenPurpose = "change"
+
+	// EmailTokenRevert undoes a change, sent to the address changed from.
+	EmailTokenRevert EmailTokenPurpose = "revert"
+
+	// EmailTokenUnlock clears the lockout of the user's account.
+	EmailTokenUnlock EmailTokenPurpose = "unlock"
+)
+
+// EmailToken is a single-use, time-limited link proving its recipient
+// controls Email. Like password reset tokens, only the hash is stored.
+type EmailToken struct {
+	ID        uuid.UUID         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID         `gorm:"type:uuid;not null;index" json:"user_id"`
+	Purpose   EmailTokenPurpose `gorm:"size:20;not null" json:"purpose"`
+	Email     string            `gorm:"size:255;not null" json:"email"` // Address the link was sent to
+	TokenHash string            `gorm:"uniqueIndex;size:255;not null" json:"-"`
+	CreatedAt time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	ExpiresAt time.Time         `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time        `json:"used_at,omitempty"`
+
+	// Associations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (EmailToken) TableName() string {
+	return "email_tokens"
+}
+
+// IsValid checks if the token is still valid (not used and not expired).
+func (t *EmailToken) IsValid() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
+
+// IsExpired checks if the token has expired.
+func (t *EmailToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// IsUsed checks if the token has been used.
+func (t *EmailToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEmailToken_IsValid(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		token EmailToken
+		want  bool
+	}{
+		{"valid token", EmailToken{ExpiresAt: now.Add(time.Hour)}, true},
+		{"expired token", EmailToken{ExpiresAt: now.Add(-time.Hour)}, false},
+		{"used token", EmailToken{ExpiresAt: now.Add(time.Hour), UsedAt: &now}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.token.IsValid(); got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+			if got := tt.token.IsExpired(); got != (tt.name == "expired token") {
+				t.Errorf("IsExpired() = %v", got)
+			}
+			if got := tt.token.IsUsed(); got != (tt.name == "used token") {
+				t.Errorf("IsUsed() = %v", got)
+			}
+		})
+	}
+}
+
+func TestEmailToken_TableName(t *testing.T) {
+	if got := (EmailToken{}).TableName(); got != "email_tokens" {
+		t.Errorf("TableName() = %q, want %q", got, "email_tokens")
+	}
+}
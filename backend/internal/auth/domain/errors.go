@@ -15,6 +15,7 @@ var (
 	ErrTokenExpired        = errors.New("token has expired")
 	ErrTokenInvalid        = errors.New("token is invalid")
 	ErrTokenMalformed      = errors.New("token is malformed")
+	ErrTokenRevoked        = errors.New("token has been revoked")
 	ErrSessionRevoked      = errors.New("session has been revoked")
 	ErrSessionNotFound     = errors.New("session not found")
 	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
@@ -29,12 +30,15 @@ var (
 	ErrTenantNotFound  = errors.New("tenant not found")
 	ErrTenantInactive  = errors.New("tenant is inactive")
 	ErrUserNotInTenant = errors.New("user does not belong to this tenant")
+	ErrSameTenant      = errors.New("already signed in to this tenant")
+	ErrSignInRequired  = errors.New("sign in to this tenant to switch to it")
 
 	// User management errors
 	ErrEmailExists      = errors.New("email already registered")
 	ErrUserNotFound     = errors.New("user not found")
 	ErrCannotManageRole = errors.New("cannot manage users with this role")
 	ErrCannotAssignRole = errors.New("cannot assign this role")
+	ErrLastOwner        = errors.New("cannot remove the last owner of a tenant")
 
 	// Password errors
 	ErrPasswordWeak         = errors.New("password does not meet requirements")
@@ -43,6 +47,127 @@ var (
 	ErrPasswordResetUsed    = errors.New("password reset token has already been used")
 	ErrPasswordResetInvalid = errors.New("password reset token is invalid")
 
+	// Password policy errors
+	ErrPasswordPolicyInvalid  = errors.New("password policy limits are out of range")
+	ErrPasswordPolicyNotFound = errors.New("tenant has no password policy")
+
+	// Account lockout errors
+	ErrLockoutPolicyInvalid  = errors.New("lockout policy limits are out of range")
+	ErrLockoutPolicyNotFound = errors.New("tenant has no lockout policy")
+	ErrKnownDeviceNotFound   = errors.New("device is not known")
+
+	// Email verification errors
+	ErrEmailTokenInvalid    = errors.New("email token is invalid")
+	ErrEmailTokenExpired    = errors.New("email token has expired")
+	ErrEmailTokenUsed       = errors.New("email token has already been used")
+	ErrEmailInvalid         = errors.New("email address is invalid")
+	ErrEmailUnchanged       = errors.New("new email is the current email")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrEmailNotVerified     = errors.New("email is not verified")
+
+	// Invitation errors
+	ErrInvitationInvalid  = errors.New("invitation is invalid")
+	ErrInvitationExpired  = errors.New("invitation has expired")
+	ErrInvitationAccepted = errors.New("invitation has already been accepted")
+	ErrInvitationRevoked  = errors.New("invitation has been revoked")
+	ErrInvitationPending  = errors.New("an invitation is already pending for this email")
+	ErrInvitationNotFound = errors.New("invitation not found")
+
+	// Ownership transfer errors
+	ErrOwnershipTransferInvalid   = errors.New("ownership transfer is invalid")
+	ErrOwnershipTransferExpired   = errors.New("ownership transfer has expired")
+	ErrOwnershipTransferUsed      = errors.New("ownership transfer has already been confirmed")
+	ErrOwnershipTransferCancelled = errors.New("ownership transfer has been cancelled")
+	ErrOwnershipTransferPending   = errors.New("an ownership transfer is already pending for this tenant")
+	ErrOwnershipTransferNotFound  = errors.New("ownership transfer not found")
+	ErrOwnershipTransferTarget    = errors.New("ownership can only be transferred to another active member of the tenant")
+
+	// Custom role errors
+	ErrCustomRoleNotFound = errors.New("custom role not found")
+	ErrCustomRoleExists   = errors.New("a role with this name already exists in the tenant")
+	ErrCustomRoleName     = errors.New("custom role name must be 1-50 characters and not a built-in role name")
+	ErrCustomRoleInUse    = errors.New("custom role is still assigned to users")
+	ErrUnknownPermission  = errors.New("unknown permission")
+	ErrPermissionNotHeld  = errors.New("cannot grant a permission you do not hold")
+
+	// Role elevation errors
+	ErrElevationWindow   = errors.New("elevation must end in the future, after it starts, and last at most 7 days")
+	ErrElevationNotAbove = errors.New("elevated role must differ from and not rank below the current role")
+	ErrNoElevation       = errors.New("user has no role elevation in this tenant")
+
+	// Step-up approval errors
+	ErrApprovalRequired = errors.New("manager approval is required for this action")
+	ErrApprovalInvalid  = errors.New("approval token is invalid")
+	ErrApprovalExpired  = errors.New("approval token has expired")
+	ErrApprovalUsed     = errors.New("approval token has already been used")
+	ErrSelfApproval     = errors.New("cannot approve your own request")
+
+	// Impersonation errors
+	ErrImpersonateSelf         = errors.New("cannot impersonate yourself")
+	ErrImpersonationNotAllowed = errors.New("not allowed while impersonating another user")
+
+	// Single sign-on errors
+	ErrSSONotConfigured       = errors.New("single sign-on is not configured for this tenant")
+	ErrSSOConfigInvalid       = errors.New("sso configuration needs an https issuer, a client id and at least one allowed email domain")
+	ErrSSOProviderUnavailable = errors.New("identity provider could not be reached")
+	ErrSSOStateInvalid        = errors.New("sso login is invalid or expired")
+	ErrSSOTokenInvalid        = errors.New("identity provider response could not be verified")
+	ErrSSOEmailNotAllowed     = errors.New("identity provider did not return a verified email in an allowed domain")
+	ErrSSOAccountNotFound     = errors.New("no account in this tenant for the signed-in identity")
+	ErrSSOIdentityNotFound    = errors.New("linked identity not found")
+	ErrPasswordLoginDisabled  = errors.New("password login is disabled for this tenant, sign in with sso")
+	ErrSSORoleNotAllowed      = errors.New("sso login is not allowed for this role, sign in with a password")
+
+	// SCIM provisioning errors
+	ErrSCIMTokenNotFound = errors.New("scim token not found")
+	ErrSCIMTokenInvalid  = errors.New("scim token is invalid or has been revoked")
+	ErrSCIMLinkNotFound  = errors.New("user was not provisioned over scim in this tenant")
+
+	// Service account errors
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	ErrServiceAccountName     = errors.New("service account name must be 1-100 characters")
+	ErrAPIKeyNotFound         = errors.New("api key not found")
+	ErrAPIKeyInvalid          = errors.New("api key is invalid or has been revoked")
+	ErrAPIKeyExpired          = errors.New("api key has expired")
+	ErrAPIKeyLifetime         = errors.New("api keys must expire within 1 to 365 days, with at most 7 days of rotation overlap")
+
+	// OAuth errors
+	ErrOAuthClientNotFound  = errors.New("oauth client not found")
+	ErrOAuthClientName      = errors.New("oauth client name must be 1-100 characters")
+	ErrOAuthRedirectURI     = errors.New("a client needs 1-10 redirect uris, each an https url (or http on localhost) without a fragment")
+	ErrOAuthConsentNotFound = errors.New("oauth consent not found")
+	ErrOAuthGrantInvalid    = errors.New("oauth grant is invalid, expired or already used")
+	ErrOAuthClientPublic    = errors.New("public oauth clients have no secret")
+
+	// Passkey errors
+	ErrPasskeyNotFound         = errors.New("passkey not found")
+	ErrPasskeyName             = errors.New("passkey name must be at most 100 characters")
+	ErrPasskeyLimit            = errors.New("too many passkeys registered")
+	ErrPasskeyExists           = errors.New("this passkey is already registered")
+	ErrPasskeyInvalid          = errors.New("passkey verification failed")
+	ErrPasskeyChallengeInvalid = errors.New("passkey challenge is invalid or expired")
+	ErrPasskeyNotAllowed       = errors.New("passkey login is not allowed for this role")
+	ErrPasskeysUnavailable     = errors.New("passkeys are not configured on this server")
+
+	// MFA errors
+	ErrMFARequired           = errors.New("multi-factor authentication required")
+	ErrMFAEnrollmentRequired = errors.New("multi-factor enrollment required for this role")
+	ErrMFASignInRequired     = errors.New("sign in again with multi-factor authentication to use this role")
+	ErrMFANotEnrolled        = errors.New("multi-factor authentication is not enrolled")
+	ErrMFAAlreadyEnrolled    = errors.New("multi-factor authentication is already enrolled")
+	ErrMFACodeInvalid        = errors.New("verification code is invalid")
+	ErrMFAChallengeInvalid   = errors.New("mfa challenge is invalid or expired")
+
+	// Staff PIN errors
+	ErrPINInvalid       = errors.New("invalid PIN")
+	ErrPINNotSet        = errors.New("no PIN has been set")
+	ErrPINFormat        = errors.New("PIN must be 4 to 6 digits")
+	ErrPINTooSimple     = errors.New("PIN is too easy to guess")
+	ErrPINLocked        = errors.New("PIN is locked due to too many failed attempts")
+	ErrPINNotAllowed    = errors.New("PIN login is not allowed for this role")
+	ErrTerminalNotFound = errors.New("terminal not found")
+	ErrTerminalInvalid  = errors.New("terminal is not registered or has been revoked")
+
 	// Rate limiting errors
 	ErrRateLimitExceeded = errors.New("rate limit exceeded")
 )
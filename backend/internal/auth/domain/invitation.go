@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InvitationTTL is how long an invitation link stays valid after it is sent.
+const InvitationTTL = 7 * 24 * time.Hour
+
+// Invitation is a single-use, time-limited invite for someone to join a
+// tenant with a given role. Only the hash of the emailed token is stored.
+// Invitees without an account set their own password when accepting;
+// invitees who already have an account confirm it to join the tenant.
+type Invitation struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	Email      string     `gorm:"size:255;not null;index" json:"email"`
+	FirstName  string     `gorm:"size:100;not null" json:"first_name"`
+	LastName   string     `gorm:"size:100;not null" json:"last_name"`
+	Role       Role       `gorm:"size:20;not null" json:"role"`
+	TokenHash  string     `gorm:"uniqueIndex;size:255;not null" json:"-"` // Hashed token
+	InvitedBy  uuid.UUID  `gorm:"type:uuid" json:"invited_by"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+
+	// Associations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (Invitation) TableName() string {
+	return "user_invitations"
+}
+
+// IsValid checks if the invitation can still be accepted (not accepted,
+// not revoked and not expired).
+func (i *Invitation) IsValid() bool {
+	return !i.IsAccepted() && !i.IsRevoked() && !i.IsExpired()
+}
+
+// IsPending checks if the invitation is still outstanding, i.e. neither
+// accepted nor revoked. A pending invitation may have expired.
+func (i *Invitation) IsPending() bool {
+	return !i.IsAccepted() && !i.IsRevoked()
+}
+
+// IsExpired checks if the invitation has expired.
+func (i *Invitation) IsExpired() bool {
+	return time.Now().After(i.ExpiresAt)
+}
+
+// IsAccepted checks if the invitation has been accepted.
+func (i *Invitation) IsAccepted() bool {
+	return i.AcceptedAt != nil
+}
+
+// IsRevoked checks if the invitation has been revoked.
+func (i *Invitation) IsRevoked() bool {
+	return i.RevokedAt != nil
+}
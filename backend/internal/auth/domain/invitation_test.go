@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInvitation_IsValid(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		invitation Invitation
+		want       bool
+	}{
+		{
+			name:       "valid invitation",
+			invitation: Invitation{ExpiresAt: now.Add(time.Hour)},
+			want:       true,
+		},
+		{
+			name:       "expired invitation",
+			invitation: Invitation{ExpiresAt: now.Add(-time.Hour)},
+			want:       false,
+		},
+		{
+			name:       "accepted invitation",
+			invitation: Invitation{ExpiresAt: now.Add(time.Hour), AcceptedAt: &now},
+			want:       false,
+		},
+		{
+			name:       "revoked invitation",
+			invitation: Invitation{ExpiresAt: now.Add(time.Hour), RevokedAt: &now},
+			want:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.invitation.IsValid(); got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvitation_IsPending(t *testing.T) {
+	now := time.Now()
+
+	expired := Invitation{ExpiresAt: now.Add(-time.Hour)}
+	if !expired.IsPending() {
+		t.Error("Expired invitation should still be pending until accepted or revoked")
+	}
+
+	accepted := Invitation{ExpiresAt: now.Add(time.Hour), AcceptedAt: &now}
+	if accepted.IsPending() {
+		t.Error("Accepted invitation should not be pending")
+	}
+
+	revoked := Invitation{ExpiresAt: now.Add(time.Hour), RevokedAt: &now}
+	if revoked.IsPending() {
+		t.Error("Revoked invitation should not be pending")
+	}
+}
+
+func TestInvitation_TableName(t *testing.T) {
+	if got := (Invitation{}).TableName(); got != "user_invitations" {
+		t.Errorf("TableName() = %q, want user_invitations", got)
+	}
+}
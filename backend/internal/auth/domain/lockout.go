@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lockout policy limits.
+const (
+	MaxLockoutThreshold    = 100
+	MaxLockoutDelaySeconds = 24 * 60 * 60
+)
+
+// LockoutPolicy is a tenant's response to repeated wrong passwords. Once a
+// device's threshold of consecutive failures is reached, each further
+// failure locks logins from such devices for a delay that starts at
+// BaseDelaySeconds and doubles up to MaxDelaySeconds. Devices the user has
+// logged in from before get their own, usually higher, threshold, so
+// guessing from elsewhere can't lock staff out of the devices they use. A
+// user in several tenants follows the strictest combination of their
+// policies (Strictest); tenants without one use DefaultLockoutPolicy.
+type LockoutPolicy struct {
+	ID                     uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID               uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"tenant_id"`
+	UnknownDeviceThreshold int        `gorm:"not null;default:5" json:"unknown_device_threshold"`
+	KnownDeviceThreshold   int        `gorm:"not null;default:10" json:"known_device_threshold"`
+	BaseDelaySeconds       int        `gorm:"not null;default:60" json:"base_delay_seconds"`
+	MaxDelaySeconds        int        `gorm:"not null;default:900" json:"max_delay_seconds"`
+	UpdatedBy              *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	CreatedAt              time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt              time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Associations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (LockoutPolicy) TableName() string {
+	return "tenant_lockout_policies"
+}
+
+// DefaultLockoutPolicy returns the policy of tenants that haven't set one:
+// unknown devices are delayed from the 5th consecutive failure and known
+// devices from the 10th, for 1 minute doubling up to 15 minutes.
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		UnknownDeviceThreshold: 5,
+		KnownDeviceThreshold:   10,
+		BaseDelaySeconds:       60,
+		MaxDelaySeconds:        15 * 60,
+	}
+}
+
+// Validate checks the policy's limits are in range.
+func (p *LockoutPolicy) Validate() error {
+	switch {
+	case p.UnknownDeviceThreshold < 1 || p.UnknownDeviceThreshold > MaxLockoutThreshold:
+		return ErrLockoutPolicyInvalid
+	case p.KnownDeviceThreshold < 1 || p.KnownDeviceThreshold > MaxLockoutThreshold:
+		return ErrLockoutPolicyInvalid
+	case p.BaseDelaySeconds < 1 || p.BaseDelaySeconds > MaxLockoutDelaySeconds:
+		return ErrLockoutPolicyInvalid
+	case p.MaxDelaySeconds < p.BaseDelaySeconds || p.MaxDelaySeconds > MaxLockoutDelaySeconds:
+		return ErrLockoutPolicyInvalid
+	}
+	return nil
+}
+
+// Strictest combines two policies into one that satisfies both: the lower
+// thresholds and the longer delays.
+func (p LockoutPolicy) Strictest(other LockoutPolicy) LockoutPolicy {
+	combined := p
+	combined.UnknownDeviceThreshold = min(p.UnknownDeviceThreshold, other.UnknownDeviceThreshold)
+	combined.KnownDeviceThreshold = min(p.KnownDeviceThreshold, other.KnownDeviceThreshold)
+	combined.BaseDelaySeconds = max(p.BaseDelaySeconds, other.BaseDelaySeconds)
+	combined.MaxDelaySeconds = max(p.MaxDelaySeconds, other.MaxDelaySeconds)
+	return combined
+}
+
+// Delay returns how long logins are locked after the given number of
+// consecutive failures from a known or unknown device, or 0 below the
+// device's threshold.
+func (p *LockoutPolicy) Delay(failures int, knownDevice bool) time.Duration {
+	threshold := p.UnknownDeviceThreshold
+	if knownDevice {
+		threshold = p.KnownDeviceThreshold
+	}
+	if failures < threshold {
+		return 0
+	}
+	maxDelay := time.Duration(p.MaxDelaySeconds) * time.Second
+	delay := time.Duration(p.BaseDelaySeconds) * time.Second
+	for i := threshold; i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+// KnownDevice is a browser or app a user has logged in from. Login
+// responses carry a device token the client presents on later logins, so
+// failures from it count against the known-device threshold. Only the
+// token's hash is stored.
+type KnownDevice struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	TokenHash  string    `gorm:"uniqueIndex;size:255;not null" json:"-"`
+	DeviceInfo string    `gorm:"size:500" json:"device_info,omitempty"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	LastSeenAt time.Time `gorm:"not null" json:"last_seen_at"`
+
+	// Associations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (KnownDevice) TableName() string {
+	return "known_devices"
+}
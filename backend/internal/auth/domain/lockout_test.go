@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  LockoutPolicy
+		wantErr bool
+	}{
+		{"default", DefaultLockoutPolicy(), false},
+		{"limits", LockoutPolicy{UnknownDeviceThreshold: 100, KnownDeviceThreshold: 1, BaseDelaySeconds: 86400, MaxDelaySeconds: 86400}, false},
+		{"zero threshold", LockoutPolicy{UnknownDeviceThreshold: 0, KnownDeviceThreshold: 10, BaseDelaySeconds: 60, MaxDelaySeconds: 900}, true},
+		{"threshold too high", LockoutPolicy{UnknownDeviceThreshold: 5, KnownDeviceThreshold: 101, BaseDelaySeconds: 60, MaxDelaySeconds: 900}, true},
+		{"no base delay", LockoutPolicy{UnknownDeviceThreshold: 5, KnownDeviceThreshold: 10, BaseDelaySeconds: 0, MaxDelaySeconds: 900}, true},
+		{"max below base", LockoutPolicy{UnknownDeviceThreshold: 5, KnownDeviceThreshold: 10, BaseDelaySeconds: 60, MaxDelaySeconds: 30}, true},
+		{"max over a day", LockoutPolicy{UnknownDeviceThreshold: 5, KnownDeviceThreshold: 10, BaseDelaySeconds: 60, MaxDelaySeconds: 86401}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && err != ErrLockoutPolicyInvalid {
+				t.Errorf("error = %v, want ErrLockoutPolicyInvalid", err)
+			}
+		})
+	}
+}
+
+func TestLockoutPolicy_Strictest(t *testing.T) {
+	a := LockoutPolicy{UnknownDeviceThreshold: 3, KnownDeviceThreshold: 20, BaseDelaySeconds: 30, MaxDelaySeconds: 3600}
+	b := LockoutPolicy{UnknownDeviceThreshold: 5, KnownDeviceThreshold: 8, BaseDelaySeconds: 120, MaxDelaySeconds: 600}
+
+	got := a.Strictest(b)
+	if got.UnknownDeviceThreshold != 3 || got.KnownDeviceThreshold != 8 || got.BaseDelaySeconds != 120 || got.MaxDelaySeconds != 3600 {
+		t.Errorf("Strictest = %+v", got)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("combined policy should be valid: %v", err)
+	}
+}
+
+func TestLockoutPolicy_Delay(t *testing.T) {
+	policy := DefaultLockoutPolicy()
+
+	tests := []struct {
+		failures    int
+		knownDevice bool
+		want        time.Duration
+	}{
+		{4, false, 0},
+		{5, false, time.Minute},
+		{6, false, 2 * time.Minute},
+		{8, false, 8 * time.Minute},
+		{9, false, 15 * time.Minute},
+		{50, false, 15 * time.Minute},
+		{9, true, 0},
+		{10, true, time.Minute},
+		{11, true, 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := policy.Delay(tt.failures, tt.knownDevice); got != tt.want {
+			t.Errorf("Delay(%d, %v) = %v, want %v", tt.failures, tt.knownDevice, got, tt.want)
+		}
+	}
+}
+
+func TestUser_RecordLoginFailure(t *testing.T) {
+	policy := LockoutPolicy{UnknownDeviceThreshold: 2, KnownDeviceThreshold: 3, BaseDelaySeconds: 60, MaxDelaySeconds: 900}
+	user := &User{}
+
+	if until := user.RecordLoginFailure(policy, false); until != nil {
+		t.Fatalf("first failure should not lock, got %v", until)
+	}
+	until := user.RecordLoginFailure(policy, false)
+	if until == nil || !user.IsLocked() {
+		t.Fatal("second failure from an unknown device should lock")
+	}
+	if locked, _ := user.IsLockedOn(true); locked {
+		t.Error("an unknown-device lock should not lock known devices")
+	}
+
+	for i := 0; i < 2; i++ {
+		user.RecordLoginFailure(policy, true)
+	}
+	if locked, _ := user.IsLockedOn(true); locked {
+		t.Fatal("known devices should not lock before their threshold")
+	}
+	user.RecordLoginFailure(policy, true)
+	if locked, lockedUntil := user.IsLockedOn(true); !locked || lockedUntil == nil {
+		t.Fatal("third failure from a known device should lock")
+	}
+
+	if !user.ResetLoginFailures() {
+		t.Fatal("ResetLoginFailures should report clearing the locks")
+	}
+	if user.IsLocked() || user.FailedLoginCount != 0 || user.KnownDeviceFailedCount != 0 || user.KnownDeviceLockedUntil != nil {
+		t.Errorf("ResetLoginFailures left %+v", user)
+	}
+	if user.ResetLoginFailures() {
+		t.Error("ResetLoginFailures should report nothing to clear")
+	}
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MFAFactor represents a user's TOTP authenticator enrollment (RFC 6238).
+// A factor is pending until the user proves possession of the secret by
+// submitting a valid code; only confirmed factors are challenged at login.
+type MFAFactor struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Secret       string     `gorm:"size:64;not null" json:"-"`   // Base32 shared secret
+	LastUsedStep int64      `gorm:"default:0;not null" json:"-"` // Last accepted TOTP time step (replay protection)
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Associations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (MFAFactor) TableName() string {
+	return "mfa_factors"
+}
+
+// IsConfirmed checks if the factor has completed enrollment.
+func (f *MFAFactor) IsConfirmed() bool {
+	return f.ConfirmedAt != nil
+}
+
+// Confirm marks the factor as enrolled.
+func (f *MFAFactor) Confirm() {
+	now := time.Now()
+	f.ConfirmedAt = &now
+}
+
+// MFARecoveryCode represents a single-use backup code for a user who has lost
+// access to their authenticator.
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:255;not null" json:"-"` // Hashed code
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+
+	// Associations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// IsUsed checks if the recovery code has been used.
+func (c *MFARecoveryCode) IsUsed() bool {
+	return c.UsedAt != nil
+}
+
+// MarkUsed marks the recovery code as used.
+func (c *MFARecoveryCode) MarkUsed() {
+	now := time.Now()
+	c.UsedAt = &now
+}
+
+// MFAChallenge is the short-lived, single-use state between a successful
+// password check and the second factor. It is bound to the tenant selected
+// at login so /mfa/verify issues tokens for exactly that tenant.
+type MFAChallenge struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	TenantID  uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
+	TokenHash string     `gorm:"uniqueIndex;size:255;not null" json:"-"` // Hashed challenge token
+	Attempts  int        `gorm:"default:0;not null" json:"attempts"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+
+	// Associations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (MFAChallenge) TableName() string {
+	return "mfa_challenges"
+}
+
+// IsValid checks if the challenge can still be answered (not used and not expired).
+func (c *MFAChallenge) IsValid() bool {
+	return c.UsedAt == nil && time.Now().Before(c.ExpiresAt)
+}
+
+// IsExpired checks if the challenge has expired.
+func (c *MFAChallenge) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
+
+// IsUsed checks if the challenge has been used.
+func (c *MFAChallenge) IsUsed() bool {
+	return c.UsedAt != nil
+}
+
+// MarkUsed marks the challenge as used.
+func (c *MFAChallenge) MarkUsed() {
+	now := time.Now()
+	c.UsedAt = &now
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMFAFactor_Confirm(t *testing.T) {
+	f := MFAFactor{}
+	if f.IsConfirmed() {
+		t.Error("new factor should not be confirmed")
+	}
+
+	f.Confirm()
+
+	if !f.IsConfirmed() {
+		t.Error("factor should be confirmed after Confirm()")
+	}
+}
+
+func TestMFARecoveryCode_MarkUsed(t *testing.T) {
+	c := MFARecoveryCode{}
+	if c.IsUsed() {
+		t.Error("new recovery code should not be used")
+	}
+
+	c.MarkUsed()
+
+	if !c.IsUsed() {
+		t.Error("recovery code should be used after MarkUsed()")
+	}
+}
+
+func TestMFAChallenge_IsValid(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		challenge MFAChallenge
+		want      bool
+	}{
+		{
+			name:      "valid challenge",
+			challenge: MFAChallenge{ExpiresAt: now.Add(5 * time.Minute)},
+			want:      true,
+		},
+		{
+			name:      "expired challenge",
+			challenge: MFAChallenge{ExpiresAt: now.Add(-time.Minute)},
+			want:      false,
+		},
+		{
+			name:      "used challenge",
+			challenge: MFAChallenge{ExpiresAt: now.Add(5 * time.Minute), UsedAt: &now},
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.challenge.IsValid(); got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMFAChallenge_MarkUsed(t *testing.T) {
+	c := MFAChallenge{ExpiresAt: time.Now().Add(5 * time.Minute)}
+	if c.IsUsed() || c.IsExpired() {
+		t.Error("new challenge should be neither used nor expired")
+	}
+
+	c.MarkUsed()
+
+	if !c.IsUsed() {
+		t.Error("challenge should be used after MarkUsed()")
+	}
+	if c.IsValid() {
+		t.Error("used challenge should not be valid")
+	}
+}
+
+func TestMFA_TableNames(t *testing.T) {
+	if (MFAFactor{}).TableName() != "mfa_factors" {
+		t.Errorf("MFAFactor.TableName() = %q", (MFAFactor{}).TableName())
+	}
+	if (MFARecoveryCode{}).TableName() != "mfa_recovery_codes" {
+		t.Errorf("MFARecoveryCode.TableName() = %q", (MFARecoveryCode{}).TableName())
+	}
+	if (MFAChallenge{}).TableName() != "mfa_challenges" {
+		t.Errorf("MFAChallenge.TableName() = %q", (MFAChallenge{}).TableName())
+	}
+}
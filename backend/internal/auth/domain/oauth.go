@@ -0,0 +1,226 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// OAuthClientRole is the rank tokens issued to OAuth clients hold for
+// role-level checks. Like ServiceAccountRole it is the lowest, so only the
+// permissions granted to the client open routes to it, whatever the role of
+// the user who authorized it.
+const OAuthClientRole = RoleViewer
+
+// MaxOAuthClientNameLength is the longest name an OAuth client may have.
+const MaxOAuthClientNameLength = 100
+
+// MaxOAuthRedirectURIs is the most redirect URIs a client may register.
+const MaxOAuthRedirectURIs = 10
+
+// OAuthCodeTTL is how long an authorization code can be exchanged for
+// tokens.
+const OAuthCodeTTL = 10 * time.Minute
+
+// OAuthClient is a third-party application, such as a reservation or
+// delivery app, registered to get delegated access to tenants. It is
+// registered by an admin of one tenant, and may then be authorized by the
+// owner of any tenant. A confidential client authenticates with a secret,
+// shown once and stored hashed, and may also act on its own behalf in its
+// registering tenant with the client credentials grant; a public client
+// (a mobile or browser app) can't keep a secret and relies on PKCE alone.
+type OAuthClient struct {
+	ID           uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"` // The client_id
+	TenantID     uuid.UUID       `gorm:"type:uuid;not null;index" json:"tenant_id"`                // Registering tenant
+	Name         string          `gorm:"size:100;not null" json:"name"`
+	RedirectURIs RedirectURIList `gorm:"type:jsonb;not null" json:"redirect_uris"`
+	Scopes       PermissionList  `gorm:"type:jsonb;not null" json:"scopes"` // Most the client may be granted
+	Public       bool            `gorm:"default:false;not null" json:"public"`
+	SecretHash   string          `gorm:"size:255;not null;default:''" json:"-"` // Hashed secret; empty for public clients
+	CreatedBy    uuid.UUID       `gorm:"type:uuid" json:"created_by"`
+	CreatedAt    time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Associations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// HasRedirectURI reports whether uri is one of the client's registered
+// redirect URIs. URIs are compared exactly.
+func (c *OAuthClient) HasRedirectURI(uri string) bool {
+	return slices.Contains(c.RedirectURIs, uri)
+}
+
+// HasScope returns true if the client may be granted the permission.
+func (c *OAuthClient) HasScope(p Permission) bool {
+	return slices.Contains(c.Scopes, p)
+}
+
+// NormalizeOAuthClientName trims an OAuth client name and checks it is
+// 1-100 characters.
+func NormalizeOAuthClientName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxOAuthClientNameLength {
+		return "", ErrOAuthClientName
+	}
+	return name, nil
+}
+
+// OAuthConsent records a tenant's grant of delegated access to an OAuth
+// client: which scopes the client may use in the tenant, and who approved
+// them. A tenant has at most one active consent per client; approving the
+// client again replaces its scopes. Revoking the consent ends every token
+// issued under it.
+type OAuthConsent struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	ClientID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"client_id"`
+	GrantedBy uuid.UUID      `gorm:"type:uuid;not null" json:"granted_by"`
+	Scopes    PermissionList `gorm:"type:jsonb;not null" json:"scopes"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	RevokedAt *time.Time     `json:"revoked_at,omitempty"`
+
+	// Associations
+	Client OAuthClient `gorm:"foreignKey:ClientID" json:"-"`
+	Tenant Tenant      `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (OAuthConsent) TableName() string {
+	return "oauth_consents"
+}
+
+// IsRevoked checks if the consent has been revoked.
+func (c *OAuthConsent) IsRevoked() bool {
+	return c.RevokedAt != nil
+}
+
+// Covers returns true if the consent grants every one of scopes.
+func (c *OAuthConsent) Covers(scopes []Permission) bool {
+	for _, s := range scopes {
+		if !slices.Contains(c.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// OAuthAuthorizationCode is the single-use code a client exchanges for
+// tokens after a user approves it. The code is stored hashed and bound to
+// the redirect URI and PKCE challenge of the authorization request.
+type OAuthAuthorizationCode struct {
+	ID            uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	CodeHash      string         `gorm:"uniqueIndex;size:255;not null" json:"-"`
+	ClientID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"client_id"`
+	TenantID      uuid.UUID      `gorm:"type:uuid;not null" json:"tenant_id"`
+	UserID        uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
+	RedirectURI   string         `gorm:"size:2000;not null" json:"redirect_uri"`
+	Scopes        PermissionList `gorm:"type:jsonb;not null" json:"scopes"`
+	CodeChallenge string         `gorm:"size:128;not null" json:"-"`
+	ExpiresAt     time.Time      `gorm:"not null;index" json:"expires_at"`
+	UsedAt        *time.Time     `json:"used_at,omitempty"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// IsExpired checks if the code can no longer be exchanged.
+func (c *OAuthAuthorizationCode) IsExpired() bool {
+	return !time.Now().Before(c.ExpiresAt)
+}
+
+// OAuthRefreshToken is a refresh token issued to an OAuth client on a
+// user's behalf. Like a session's refresh token it is rotated on every use:
+// all tokens descending from one authorization code share a FamilyID (the
+// code's ID), and ReplacedByID links a rotated token to its successor, so
+// replaying a rotated token revokes the whole family.
+type OAuthRefreshToken struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TokenHash    string         `gorm:"uniqueIndex;size:255;not null" json:"-"`
+	ClientID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"client_id"`
+	TenantID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	UserID       uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
+	FamilyID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"family_id"`
+	Scopes       PermissionList `gorm:"type:jsonb;not null" json:"scopes"`
+	ExpiresAt    time.Time      `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time     `json:"revoked_at,omitempty"`
+	ReplacedByID *uuid.UUID     `gorm:"type:uuid" json:"replaced_by_id,omitempty"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (OAuthRefreshToken) TableName() string {
+	return "oauth_refresh_tokens"
+}
+
+// IsRevoked checks if the token has been revoked.
+func (t *OAuthRefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// IsRotated checks if the token was revoked by being exchanged for a new one.
+func (t *OAuthRefreshToken) IsRotated() bool {
+	return t.ReplacedByID != nil
+}
+
+// IsExpired checks if the token has expired.
+func (t *OAuthRefreshToken) IsExpired() bool {
+	return !time.Now().Before(t.ExpiresAt)
+}
+
+// RedirectURIList is a list of redirect URIs stored as a JSON array.
+type RedirectURIList []string
+
+// Scan implements sql.Scanner interface for database reads.
+func (l *RedirectURIList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan type %T into RedirectURIList", value)
+	}
+
+	if len(bytes) == 0 {
+		*l = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
+// Value implements driver.Valuer interface for database writes. A nil list
+// is stored as an empty array, since the column is NOT NULL.
+func (l RedirectURIList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+// GormDataType implements GORM's custom type interface.
+func (l RedirectURIList) GormDataType() string {
+	return "jsonb"
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestOAuthClient_HasRedirectURI(t *testing.T) {
+	client := &OAuthClient{RedirectURIs: RedirectURIList{"https://reservas.example.com/cb"}}
+
+	if !client.HasRedirectURI("https://reservas.example.com/cb") {
+		t.Error("registered redirect URI should match")
+	}
+	for _, uri := range []string{"https://reservas.example.com/cb/", "https://reservas.example.com/cb?x=1", "https://RESERVAS.example.com/cb", ""} {
+		if client.HasRedirectURI(uri) {
+			t.Errorf("HasRedirectURI(%q) = true, redirect URIs must match exactly", uri)
+		}
+	}
+}
+
+func TestNormalizeOAuthClientName(t *testing.T) {
+	if name, err := NormalizeOAuthClientName("  Reservas Online "); err != nil || name != "Reservas Online" {
+		t.Errorf("NormalizeOAuthClientName = %q, %v", name, err)
+	}
+	for _, name := range []string{"", "   ", strings.Repeat("a", MaxOAuthClientNameLength+1)} {
+		if _, err := NormalizeOAuthClientName(name); !errors.Is(err, ErrOAuthClientName) {
+			t.Errorf("NormalizeOAuthClientName(%q) error = %v, want ErrOAuthClientName", name, err)
+		}
+	}
+}
+
+func TestOAuthConsent_Covers(t *testing.T) {
+	consent := &OAuthConsent{Scopes: PermissionList{PermUsersManage, PermTerminalsManage}}
+
+	if !consent.Covers([]Permission{PermTerminalsManage}) || !consent.Covers(nil) {
+		t.Error("consent should cover a subset of its scopes")
+	}
+	if consent.Covers([]Permission{PermTerminalsManage, PermUsersImpersonate}) {
+		t.Error("consent should not cover a scope it doesn't grant")
+	}
+}
+
+func TestOAuthRefreshToken_State(t *testing.T) {
+	token := &OAuthRefreshToken{ExpiresAt: time.Now().Add(time.Hour)}
+	if token.IsRevoked() || token.IsRotated() || token.IsExpired() {
+		t.Error("new token should be usable")
+	}
+
+	now := time.Now()
+	token.RevokedAt = &now
+	if !token.IsRevoked() || token.IsRotated() {
+		t.Error("revoked token should not count as rotated")
+	}
+
+	next := uuid.New()
+	token.ReplacedByID = &next
+	if !token.IsRotated() {
+		t.Error("replaced token should count as rotated")
+	}
+
+	token = &OAuthRefreshToken{ExpiresAt: time.Now().Add(-time.Second)}
+	if !token.IsExpired() {
+		t.Error("token past its expiry should be expired")
+	}
+	code := &OAuthAuthorizationCode{ExpiresAt: time.Now().Add(-time.Second)}
+	if !code.IsExpired() {
+		t.Error("code past its expiry should be expired")
+	}
+}
+
+func TestRedirectURIList_Value(t *testing.T) {
+	v, err := RedirectURIList(nil).Value()
+	if err != nil || string(v.([]byte)) != "[]" {
+		t.Errorf("nil list Value() = %v, %v, want []", v, err)
+	}
+
+	var l RedirectURIList
+	if err := l.Scan(`["https://reservas.example.com/cb"]`); err != nil || len(l) != 1 {
+		t.Errorf("Scan = %v, %v", l, err)
+	}
+	if err := l.Scan(42); err == nil {
+		t.Error("Scan of an int should fail")
+	}
+}
+
+func TestOAuth_TableNames(t *testing.T) {
+	tests := map[string]string{
+		(OAuthClient{}).TableName():            "oauth_clients",
+		(OAuthConsent{}).TableName():           "oauth_consents",
+		(OAuthAuthorizationCode{}).TableName(): "oauth_authorization_codes",
+		(OAuthRefreshToken{}).TableName():      "oauth_refresh_tokens",
+	}
+	for got, want := range tests {
+		if got != want {
+			t.Errorf("TableName() = %q, want %q", got, want)
+		}
+	}
+}
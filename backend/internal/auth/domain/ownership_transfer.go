@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OwnershipTransferTTL is how long the recipient has to confirm a transfer.
+const OwnershipTransferTTL = 72 * time.Hour
+
+// OwnershipTransfer is a tenant owner's request to hand the owner role to
+// another member of the tenant. Nobody outranks an owner, so this is the only
+// way the role changes hands: the owner starts it, and the recipient
+// confirms with the emailed single-use token (stored hashed) and their
+// password. On confirmation the two members' roles are swapped.
+type OwnershipTransfer struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	FromUserID  uuid.UUID  `gorm:"type:uuid;not null" json:"from_user_id"`
+	ToUserID    uuid.UUID  `gorm:"type:uuid;not null" json:"to_user_id"`
+	TokenHash   string     `gorm:"uniqueIndex;size:255;not null" json:"-"` // Hashed token
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+
+	// Associations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (OwnershipTransfer) TableName() string {
+	return "ownership_transfers"
+}
+
+// IsValid checks if the transfer can still be confirmed (not confirmed,
+// not cancelled and not expired).
+func (t *OwnershipTransfer) IsValid() bool {
+	return t.IsPending() && !t.IsExpired()
+}
+
+// IsPending checks if the transfer is still outstanding, i.e. neither
+// confirmed nor cancelled. A pending transfer may have expired.
+func (t *OwnershipTransfer) IsPending() bool {
+	return !t.IsConfirmed() && !t.IsCancelled()
+}
+
+// IsExpired checks if the transfer has expired.
+func (t *OwnershipTransfer) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// IsConfirmed checks if the recipient has confirmed the transfer.
+func (t *OwnershipTransfer) IsConfirmed() bool {
+	return t.ConfirmedAt != nil
+}
+
+// IsCancelled checks if the transfer has been cancelled.
+func (t *OwnershipTransfer) IsCancelled() bool {
+	return t.CancelledAt != nil
+}
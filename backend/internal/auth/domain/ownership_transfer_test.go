@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOwnershipTransfer_IsValid(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		transfer OwnershipTransfer
+		want     bool
+	}{
+		{
+			name:     "valid transfer",
+			transfer: OwnershipTransfer{ExpiresAt: now.Add(time.Hour)},
+			want:     true,
+		},
+		{
+			name:     "expired transfer",
+			transfer: OwnershipTransfer{ExpiresAt: now.Add(-time.Hour)},
+			want:     false,
+		},
+		{
+			name:     "confirmed transfer",
+			transfer: OwnershipTransfer{ExpiresAt: now.Add(time.Hour), ConfirmedAt: &now},
+			want:     false,
+		},
+		{
+			name:     "cancelled transfer",
+			transfer: OwnershipTransfer{ExpiresAt: now.Add(time.Hour), CancelledAt: &now},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.transfer.IsValid(); got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOwnershipTransfer_IsPending(t *testing.T) {
+	now := time.Now()
+
+	expired := OwnershipTransfer{ExpiresAt: now.Add(-time.Hour)}
+	if !expired.IsPending() {
+		t.Error("Expired transfer should still be pending until confirmed or cancelled")
+	}
+
+	confirmed := OwnershipTransfer{ExpiresAt: now.Add(time.Hour), ConfirmedAt: &now}
+	if confirmed.IsPending() {
+		t.Error("Confirmed transfer should not be pending")
+	}
+
+	cancelled := OwnershipTransfer{ExpiresAt: now.Add(time.Hour), CancelledAt: &now}
+	if cancelled.IsPending() {
+		t.Error("Cancelled transfer should not be pending")
+	}
+}
+
+func TestOwnershipTransfer_TableName(t *testing.T) {
+	if got := (OwnershipTransfer{}).TableName(); got != "ownership_transfers" {
+		t.Errorf("TableName() = %q, want ownership_transfers", got)
+	}
+}
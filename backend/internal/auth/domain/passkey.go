@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// PasskeyChallengeTTL is how long a user has to complete a WebAuthn
+// ceremony once it has been started.
+const PasskeyChallengeTTL = 5 * time.Minute
+
+// MaxPasskeysPerUser caps how many passkeys one user may register.
+const MaxPasskeysPerUser = 10
+
+// MaxPasskeyNameLength is the longest name a passkey may have.
+const MaxPasskeyNameLength = 100
+
+// DefaultPasskeyName names a passkey registered without a name.
+const DefaultPasskeyName = "Passkey"
+
+// Passkey is a WebAuthn (FIDO2) credential registered by a user: a key pair
+// held by their phone, laptop or security key, unlocked with a fingerprint,
+// face or device PIN. It signs the user in without a password, or serves as
+// their second factor after one. Passkeys belong to the user, not a tenant.
+type Passkey struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID         uuid.UUID     `gorm:"type:uuid;not null;index" json:"user_id"`
+	CredentialID   []byte        `gorm:"type:bytea;not null;uniqueIndex" json:"-"`
+	PublicKey      []byte        `gorm:"type:bytea;not null" json:"-"` // CBOR-encoded COSE key
+	SignCount      uint32        `gorm:"type:bigint;default:0;not null" json:"-"`
+	AAGUID         []byte        `gorm:"column:aaguid;type:bytea" json:"-"` // Authenticator model
+	Name           string        `gorm:"size:100;not null" json:"name"`
+	Transports     TransportList `gorm:"type:jsonb;not null" json:"transports"`
+	BackupEligible bool          `gorm:"default:false;not null" json:"backup_eligible"` // Synced passkey
+	BackedUp       bool          `gorm:"default:false;not null" json:"backed_up"`
+	LastUsedAt     *time.Time    `json:"last_used_at,omitempty"`
+	CreatedAt      time.Time     `gorm:"autoCreateTime" json:"created_at"`
+
+	// Associations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (Passkey) TableName() string {
+	return "passkeys"
+}
+
+// RecordUse stores the counter and backup state reported by a successful
+// assertion.
+func (p *Passkey) RecordUse(signCount uint32, backedUp bool) {
+	now := time.Now()
+	p.SignCount = signCount
+	p.BackedUp = backedUp
+	p.LastUsedAt = &now
+}
+
+// NormalizePasskeyName trims a passkey name and checks it is at most 100
+// characters. An empty name becomes DefaultPasskeyName.
+func NormalizePasskeyName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return DefaultPasskeyName, nil
+	}
+	if utf8.RuneCountInString(name) > MaxPasskeyNameLength {
+		return "", ErrPasskeyName
+	}
+	return name, nil
+}
+
+// PasskeyPurpose is the ceremony a passkey challenge was issued for.
+type PasskeyPurpose string
+
+// PasskeyPurpose constants.
+const (
+	PasskeyPurposeRegister PasskeyPurpose = "register" // Adding a passkey to a signed-in user
+	PasskeyPurposeLogin    PasskeyPurpose = "login"    // Passwordless login
+	PasskeyPurposeMFA      PasskeyPurpose = "mfa"      // Second factor after a password
+)
+
+// PasskeyChallenge is the single-use state of a WebAuthn ceremony between
+// issuing its options and receiving the authenticator's response. The
+// random challenge is stored hashed. A passwordless login challenge has no
+// user, since the user is only known from the credential that answers it;
+// an MFA challenge is bound to the login challenge it completes.
+type PasskeyChallenge struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ChallengeHash  string         `gorm:"uniqueIndex;size:255;not null" json:"-"`
+	Purpose        PasskeyPurpose `gorm:"size:20;not null" json:"purpose"`
+	UserID         *uuid.UUID     `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	MFAChallengeID *uuid.UUID     `gorm:"type:uuid" json:"mfa_challenge_id,omitempty"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	ExpiresAt      time.Time      `gorm:"not null;index" json:"expires_at"`
+	UsedAt         *time.Time     `json:"used_at,omitempty"`
+}
+
+// TableName specifies the table name for GORM.
+func (PasskeyChallenge) TableName() string {
+	return "passkey_challenges"
+}
+
+// IsValid checks if the challenge can still be answered (not used and not expired).
+func (c *PasskeyChallenge) IsValid() bool {
+	return c.UsedAt == nil && time.Now().Before(c.ExpiresAt)
+}
+
+// TransportList is the list of WebAuthn transports ("internal", "usb",
+// "nfc", "ble", "hybrid") a credential is reachable over, stored as a JSON
+// array.
+type TransportList []string
+
+// Scan implements sql.Scanner interface for database reads.
+func (l *TransportList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan type %T into TransportList", value)
+	}
+
+	if len(bytes) == 0 {
+		*l = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
+// Value implements driver.Valuer interface for database writes. A nil list
+// is stored as an empty array, since the column is NOT NULL.
+func (l TransportList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+// GormDataType implements GORM's custom type interface.
+func (l TransportList) GormDataType() string {
+	return "jsonb"
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPasskey_RecordUse(t *testing.T) {
+	p := Passkey{SignCount: 3}
+	p.RecordUse(4, true)
+
+	if p.SignCount != 4 || !p.BackedUp {
+		t.Errorf("after RecordUse: sign count %d, backed up %v", p.SignCount, p.BackedUp)
+	}
+	if p.LastUsedAt == nil {
+		t.Error("RecordUse should set LastUsedAt")
+	}
+}
+
+func TestNormalizePasskeyName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr error
+	}{
+		{"  Ana's iPhone ", "Ana's iPhone", nil},
+		{"   ", DefaultPasskeyName, nil},
+		{strings.Repeat("ñ", MaxPasskeyNameLength), strings.Repeat("ñ", MaxPasskeyNameLength), nil},
+		{strings.Repeat("a", MaxPasskeyNameLength+1), "", ErrPasskeyName},
+	}
+	for _, tt := range tests {
+		got, err := NormalizePasskeyName(tt.name)
+		if got != tt.want || err != tt.wantErr {
+			t.Errorf("NormalizePasskeyName(%q) = %q, %v, want %q, %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestPasskeyChallenge_IsValid(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		challenge PasskeyChallenge
+		want      bool
+	}{
+		{"valid challenge", PasskeyChallenge{ExpiresAt: now.Add(PasskeyChallengeTTL)}, true},
+		{"expired challenge", PasskeyChallenge{ExpiresAt: now.Add(-time.Minute)}, false},
+		{"used challenge", PasskeyChallenge{ExpiresAt: now.Add(PasskeyChallengeTTL), UsedAt: &now}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.challenge.IsValid(); got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransportList_Value(t *testing.T) {
+	v, err := TransportList(nil).Value()
+	if err != nil || string(v.([]byte)) != "[]" {
+		t.Errorf("nil list Value() = %v, %v, want []", v, err)
+	}
+
+	var l TransportList
+	if err := l.Scan([]byte(`["internal","hybrid"]`)); err != nil || len(l) != 2 {
+		t.Errorf("Scan = %v, %v", l, err)
+	}
+	if err := l.Scan(42); err == nil {
+		t.Error("Scan of an int should fail")
+	}
+}
+
+func TestPasskey_TableNames(t *testing.T) {
+	if got := (Passkey{}).TableName(); got != "passkeys" {
+		t.Errorf("TableName() = %q, want %q", got, "passkeys")
+	}
+	if got := (PasskeyChallenge{}).TableName(); got != "passkey_challenges" {
+		t.Errorf("TableName() = %q, want %q", got, "passkey_challenges")
+	}
+}
@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Password policy limits. No tenant can allow passwords shorter than
+// MinPasswordLength, and every tenant's passwords are checked against the
+// breached-password list.
+const (
+	MinPasswordLength  = 8
+	MaxPasswordLength  = 128
+	MaxPasswordHistory = 24
+	MaxPasswordAgeDays = 365
+)
+
+// PasswordRule names a password policy rule a new password can break.
+type PasswordRule string
+
+// Password rules.
+const (
+	PasswordRuleLength    PasswordRule = "length"
+	PasswordRuleUppercase PasswordRule = "uppercase"
+	PasswordRuleLowercase PasswordRule = "lowercase"
+	PasswordRuleDigit     PasswordRule = "digit"
+	PasswordRuleSymbol    PasswordRule = "symbol"
+	PasswordRuleReused    PasswordRule = "reused"
+	PasswordRuleBreached  PasswordRule = "breached"
+)
+
+// PasswordRuleError reports which password policy rule a new password
+// broke. It matches ErrPasswordWeak.
+type PasswordRuleError struct {
+	Rule PasswordRule
+	// Limit is the policy's minimum length for PasswordRuleLength, and how
+	// many recent passwords can't be reused for PasswordRuleReused.
+	Limit int
+}
+
+// Error implements the error interface.
+func (e *PasswordRuleError) Error() string {
+	switch e.Rule {
+	case PasswordRuleLength:
+		return fmt.Sprintf("password must be %d to %d characters", e.Limit, MaxPasswordLength)
+	case PasswordRuleUppercase:
+		return "password must contain an uppercase letter"
+	case PasswordRuleLowercase:
+		return "password must contain a lowercase letter"
+	case PasswordRuleDigit:
+		return "password must contain a digit"
+	case PasswordRuleSymbol:
+		return "password must contain a symbol"
+	case PasswordRuleReused:
+		return fmt.Sprintf("password must differ from the last %d passwords", e.Limit)
+	case PasswordRuleBreached:
+		return "password appears in a list of breached passwords"
+	}
+	return ErrPasswordWeak.Error()
+}
+
+// Is makes errors.Is(err, ErrPasswordWeak) hold for rule errors.
+func (e *PasswordRuleError) Is(target error) bool {
+	return target == ErrPasswordWeak
+}
+
+// PasswordPolicy is a tenant's rules for its members' passwords. A user in
+// several tenants follows the strictest combination of their policies
+// (Strictest); tenants without one use DefaultPasswordPolicy.
+type PasswordPolicy struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"tenant_id"`
+	MinLength        int        `gorm:"not null;default:8" json:"min_length"`
+	RequireUppercase bool       `gorm:"not null" json:"require_uppercase"`
+	RequireLowercase bool       `gorm:"not null" json:"require_lowercase"`
+	RequireDigit     bool       `gorm:"not null" json:"require_digit"`
+	RequireSymbol    bool       `gorm:"not null" json:"require_symbol"`
+	MaxAgeDays       int        `gorm:"not null;default:0" json:"max_age_days"`  // 0 = passwords never expire
+	HistoryCount     int        `gorm:"not null;default:0" json:"history_count"` // 0 = reuse allowed
+	UpdatedBy        *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Associations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (PasswordPolicy) TableName() string {
+	return "tenant_password_policies"
+}
+
+// DefaultPasswordPolicy returns the policy of tenants that haven't set
+// one: 8+ characters with an uppercase letter, a lowercase letter and a
+// digit, no expiry and no reuse check.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:        MinPasswordLength,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+	}
+}
+
+// Validate checks the policy's limits are in range.
+func (p *PasswordPolicy) Validate() error {
+	switch {
+	case p.MinLength < MinPasswordLength || p.MinLength > MaxPasswordLength:
+		return ErrPasswordPolicyInvalid
+	case p.MaxAgeDays < 0 || p.MaxAgeDays > MaxPasswordAgeDays:
+		return ErrPasswordPolicyInvalid
+	case p.HistoryCount < 0 || p.HistoryCount > MaxPasswordHistory:
+		return ErrPasswordPolicyInvalid
+	}
+	return nil
+}
+
+// Strictest combines two policies into one that satisfies both: the longer
+// minimum length and history, every required character class, and the
+// shorter maximum age.
+func (p PasswordPolicy) Strictest(other PasswordPolicy) PasswordPolicy {
+	combined := p
+	combined.MinLength = max(p.MinLength, other.MinLength)
+	combined.RequireUppercase = p.RequireUppercase || other.RequireUppercase
+	combined.RequireLowercase = p.RequireLowercase || other.RequireLowercase
+	combined.RequireDigit = p.RequireDigit || other.RequireDigit
+	combined.RequireSymbol = p.RequireSymbol || other.RequireSymbol
+	combined.HistoryCount = max(p.HistoryCount, other.HistoryCount)
+	if other.MaxAgeDays > 0 && (p.MaxAgeDays == 0 || other.MaxAgeDays < p.MaxAgeDays) {
+		combined.MaxAgeDays = other.MaxAgeDays
+	}
+	return combined
+}
+
+// CheckComposition checks a new password's length and character classes,
+// returning a *PasswordRuleError for the first rule it breaks. Reuse and
+// breached passwords need the user's history and the breached list, so
+// they are checked by the caller.
+func (p *PasswordPolicy) CheckComposition(password string) error {
+	if n := utf8.RuneCountInString(password); n < p.MinLength || n > MaxPasswordLength {
+		return &PasswordRuleError{Rule: PasswordRuleLength, Limit: p.MinLength}
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char) || unicode.IsSpace(char):
+			hasSymbol = true
+		}
+	}
+
+	switch {
+	case p.RequireUppercase && !hasUpper:
+		return &PasswordRuleError{Rule: PasswordRuleUppercase}
+	case p.RequireLowercase && !hasLower:
+		return &PasswordRuleError{Rule: PasswordRuleLowercase}
+	case p.RequireDigit && !hasDigit:
+		return &PasswordRuleError{Rule: PasswordRuleDigit}
+	case p.RequireSymbol && !hasSymbol:
+		return &PasswordRuleError{Rule: PasswordRuleSymbol}
+	}
+	return nil
+}
+
+// IsExpired reports whether a password set at changedAt is older than the
+// policy's maximum age. A password of unknown age never expires.
+func (p *PasswordPolicy) IsExpired(changedAt *time.Time) bool {
+	if p.MaxAgeDays == 0 || changedAt == nil {
+		return false
+	}
+	return time.Since(*changedAt) > time.Duration(p.MaxAgeDays)*24*time.Hour
+}
+
+// PasswordHistoryEntry is a password hash a user had before changing it,
+// kept so the password can't be reused.
+type PasswordHistoryEntry struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	PasswordHash string    `gorm:"size:255;not null" json:"-"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;index" json:"created_at"` // When the password was replaced
+
+	// Associations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (PasswordHistoryEntry) TableName() string {
+	return "password_history"
+}
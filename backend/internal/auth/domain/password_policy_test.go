@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  PasswordPolicy
+		wantErr bool
+	}{
+		{"default", DefaultPasswordPolicy(), false},
+		{"strict", PasswordPolicy{MinLength: 128, MaxAgeDays: 365, HistoryCount: 24}, false},
+		{"too short", PasswordPolicy{MinLength: 7}, true},
+		{"too long", PasswordPolicy{MinLength: 129}, true},
+		{"negative age", PasswordPolicy{MinLength: 8, MaxAgeDays: -1}, true},
+		{"age over a year", PasswordPolicy{MinLength: 8, MaxAgeDays: 366}, true},
+		{"history too long", PasswordPolicy{MinLength: 8, HistoryCount: 25}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && err != ErrPasswordPolicyInvalid {
+				t.Errorf("error = %v, want ErrPasswordPolicyInvalid", err)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_Strictest(t *testing.T) {
+	a := PasswordPolicy{MinLength: 12, RequireUppercase: true, MaxAgeDays: 90, HistoryCount: 2}
+	b := PasswordPolicy{MinLength: 10, RequireSymbol: true, MaxAgeDays: 0, HistoryCount: 5}
+
+	got := a.Strictest(b)
+	if got.MinLength != 12 || !got.RequireUppercase || !got.RequireSymbol || got.RequireDigit || got.MaxAgeDays != 90 || got.HistoryCount != 5 {
+		t.Errorf("Strictest = %+v", got)
+	}
+	if got := b.Strictest(a); got.MaxAgeDays != 90 {
+		t.Errorf("a policy without expiry should take the other's maximum age, got %d", got.MaxAgeDays)
+	}
+	if got := a.Strictest(PasswordPolicy{MinLength: 8, MaxAgeDays: 30}); got.MaxAgeDays != 30 {
+		t.Errorf("the shorter maximum age should win, got %d", got.MaxAgeDays)
+	}
+}
+
+func TestPasswordPolicy_CheckComposition(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 10, RequireUppercase: true, RequireLowercase: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		password string
+		want     PasswordRule
+	}{
+		{"Aa1!", PasswordRuleLength},
+		{strings.Repeat("Aa1!", 33), PasswordRuleLength},
+		{"aaaaaaaa1!", PasswordRuleUppercase},
+		{"AAAAAAAA1!", PasswordRuleLowercase},
+		{"Aaaaaaaaa!", PasswordRuleDigit},
+		{"Aaaaaaaaa1", PasswordRuleSymbol},
+		{"Aaaaaaaa1 ", ""},  // A space counts as a symbol
+		{"Ñandú-pie-9", ""}, // Letters outside ASCII count
+		{"Ññññññññ1!", ""},  // Length is counted in characters, not bytes
+	}
+	for _, tt := range tests {
+		err := policy.CheckComposition(tt.password)
+		var got PasswordRule
+		var ruleErr *PasswordRuleError
+		if errors.As(err, &ruleErr) {
+			got = ruleErr.Rule
+		} else if err != nil {
+			t.Fatalf("CheckComposition(%q) = %v, want a *PasswordRuleError", tt.password, err)
+		}
+		if got != tt.want {
+			t.Errorf("CheckComposition(%q) rule = %q, want %q", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestPasswordRuleError(t *testing.T) {
+	err := error(&PasswordRuleError{Rule: PasswordRuleLength, Limit: 12})
+	if !errors.Is(err, ErrPasswordWeak) {
+		t.Error("a rule error should match ErrPasswordWeak")
+	}
+	if errors.Is(err, ErrPasswordIncorrect) {
+		t.Error("a rule error should only match ErrPasswordWeak")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "12") {
+		t.Errorf("length message %q should name the minimum", msg)
+	}
+	if msg := (&PasswordRuleError{Rule: PasswordRuleReused, Limit: 5}).Error(); !strings.Contains(msg, "5") {
+		t.Errorf("reuse message %q should name the history count", msg)
+	}
+}
+
+func TestPasswordPolicy_IsExpired(t *testing.T) {
+	old := time.Now().Add(-31 * 24 * time.Hour)
+	recent := time.Now().Add(-29 * 24 * time.Hour)
+
+	policy := PasswordPolicy{MaxAgeDays: 30}
+	if !policy.IsExpired(&old) {
+		t.Error("a 31-day-old password should be expired under a 30-day policy")
+	}
+	if policy.IsExpired(&recent) {
+		t.Error("a 29-day-old password should not be expired under a 30-day policy")
+	}
+	if policy.IsExpired(nil) {
+		t.Error("a password of unknown age should not be expired")
+	}
+	if (&PasswordPolicy{}).IsExpired(&old) {
+		t.Error("a policy without a maximum age should never expire passwords")
+	}
+}
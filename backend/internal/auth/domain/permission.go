@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Permission names a single action a user may perform, namespaced by the
+// module that declares it (e.g. "orders.void", "payments.refund").
+type Permission string
+
+// Auth module permissions.
+const (
+	PermUsersManage      Permission = "users.manage"
+	PermUsersImpersonate Permission = "users.impersonate"
+	PermTerminalsManage  Permission = "terminals.manage"
+)
+
+// PermissionDefinition declares a permission and the built-in roles that are
+// granted it by default. Together, the registered definitions form the
+// default role → permission matrix.
+type PermissionDefinition struct {
+	Permission  Permission
+	Description string
+	Roles       []Role
+}
+
+// permissionPattern is "<module>.<action>", lowercase, with optional further
+// dot-separated segments (e.g. "reporting.revenue.view").
+var permissionPattern = regexp.MustCompile(`^[a-z][a-z_]*(\.[a-z][a-z_]*)+$`)
+
+var (
+	permissionsMu sync.RWMutex
+	permissions   = map[Permission]PermissionDefinition{}
+)
+
+func init() {
+	RegisterPermissions(
+		PermissionDefinition{
+			Permission:  PermUsersManage,
+			Description: "Invite, update and remove users and manage their sessions",
+			Roles:       RolesAtLeast(RoleManager),
+		},
+		PermissionDefinition{
+			Permission:  PermUsersImpersonate,
+			Description: "Sign in as a lower-ranked user to see what they see",
+			Roles:       RolesAtLeast(RoleAdmin),
+		},
+		PermissionDefinition{
+			Permission:  PermTerminalsManage,
+			Description: "Register and revoke POS terminals",
+			Roles:       RolesAtLeast(RoleManager),
+		},
+	)
+}
+
+// RegisterPermissions adds permissions to the registry. Modules call it from
+// an init function. Like sql.Register it panics on a duplicate or malformed
+// permission, or an unknown role, since those are programming errors.
+func RegisterPermissions(defs ...PermissionDefinition) {
+	permissionsMu.Lock()
+	defer permissionsMu.Unlock()
+
+	for _, def := range defs {
+		if !permissionPattern.MatchString(string(def.Permission)) {
+			panic(fmt.Sprintf("auth: malformed permission %q", def.Permission))
+		}
+		if _, dup := permissions[def.Permission]; dup {
+			panic(fmt.Sprintf("auth: permission %q registered twice", def.Permission))
+		}
+		for _, role := range def.Roles {
+			if !role.IsValid() {
+				panic(fmt.Sprintf("auth: permission %q granted to unknown role %q", def.Permission, role))
+			}
+		}
+		permissions[def.Permission] = def
+	}
+}
+
+// AllPermissions returns every registered permission, sorted by name.
+func AllPermissions() []PermissionDefinition {
+	permissionsMu.RLock()
+	defer permissionsMu.RUnlock()
+
+	defs := make([]PermissionDefinition, 0, len(permissions))
+	for _, def := range permissions {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Permission < defs[j].Permission })
+	return defs
+}
+
+// IsRegistered returns true if the permission has been declared by a module.
+func (p Permission) IsRegistered() bool {
+	permissionsMu.RLock()
+	defer permissionsMu.RUnlock()
+
+	_, ok := permissions[p]
+	return ok
+}
+
+// String returns the string representation of the permission.
+func (p Permission) String() string {
+	return string(p)
+}
+
+// Permissions returns the permissions granted to the role by default,
+// sorted by name.
+func (r Role) Permissions() []Permission {
+	permissionsMu.RLock()
+	defer permissionsMu.RUnlock()
+
+	var perms []Permission
+	for p, def := range permissions {
+		if slices.Contains(def.Roles, r) {
+			perms = append(perms, p)
+		}
+	}
+	slices.Sort(perms)
+	return perms
+}
+
+// HasPermission returns true if the role is granted the permission by default.
+func (r Role) HasPermission(p Permission) bool {
+	permissionsMu.RLock()
+	defer permissionsMu.RUnlock()
+
+	def, ok := permissions[p]
+	return ok && slices.Contains(def.Roles, r)
+}
+
+// RolesAtLeast returns the built-in roles at or above min's level, for
+// permissions that follow the role hierarchy.
+func RolesAtLeast(min Role) []Role {
+	var roles []Role
+	for _, r := range AllRoles() {
+		if r.Level() >= min.Level() {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
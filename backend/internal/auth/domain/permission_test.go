@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRole_Permissions(t *testing.T) {
+	manager := RoleManager.Permissions()
+	if !slices.Contains(manager, PermUsersManage) || !slices.Contains(manager, PermTerminalsManage) {
+		t.Errorf("manager permissions = %v, want users.manage and terminals.manage", manager)
+	}
+	if !slices.IsSorted(manager) {
+		t.Errorf("manager permissions = %v, want sorted", manager)
+	}
+
+	if perms := RoleWaiter.Permissions(); slices.Contains(perms, PermUsersManage) {
+		t.Errorf("waiter permissions = %v, should not include users.manage", perms)
+	}
+}
+
+func TestRole_HasPermission(t *testing.T) {
+	tests := []struct {
+		role Role
+		perm Permission
+		want bool
+	}{
+		{RoleOwner, PermUsersManage, true},
+		{RoleManager, PermUsersManage, true},
+		{RoleCashier, PermUsersManage, false},
+		{RoleAdmin, PermUsersImpersonate, true},
+		{RoleManager, PermUsersImpersonate, false},
+		{RoleKitchen, PermTerminalsManage, false},
+		{RoleOwner, Permission("nonexistent.permission"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" "+string(tt.perm), func(t *testing.T) {
+			if got := tt.role.HasPermission(tt.perm); got != tt.want {
+				t.Errorf("Role(%q).HasPermission(%q) = %v, want %v", tt.role, tt.perm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterPermissions(t *testing.T) {
+	perm := Permission("test.widgets.count")
+	// Registration is process-wide, so -count=N reruns must not register twice
+	if !perm.IsRegistered() {
+		RegisterPermissions(PermissionDefinition{Permission: perm, Roles: []Role{RoleKitchen, RoleViewer}})
+	}
+
+	if !perm.IsRegistered() {
+		t.Fatal("expected permission to be registered")
+	}
+	if !RoleKitchen.HasPermission(perm) || !RoleViewer.HasPermission(perm) {
+		t.Error("expected kitchen and viewer to be granted the permission")
+	}
+	if RoleOwner.HasPermission(perm) {
+		t.Error("owner should only get permissions that list it")
+	}
+
+	all := AllPermissions()
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Permission >= all[i].Permission {
+			t.Errorf("AllPermissions not sorted by name: %q before %q", all[i-1].Permission, all[i].Permission)
+		}
+	}
+}
+
+func TestRegisterPermissions_Panics(t *testing.T) {
+	tests := []struct {
+		name string
+		def  PermissionDefinition
+	}{
+		{"duplicate", PermissionDefinition{Permission: PermUsersManage}},
+		{"no module", PermissionDefinition{Permission: "void"}},
+		{"uppercase", PermissionDefinition{Permission: "Orders.Void"}},
+		{"empty segment", PermissionDefinition{Permission: "orders..void"}},
+		{"unknown role", PermissionDefinition{Permission: "test.unknown_role", Roles: []Role{"superadmin"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterPermissions(%q) did not panic", tt.def.Permission)
+				}
+			}()
+			RegisterPermissions(tt.def)
+		})
+	}
+}
+
+func TestRolesAtLeast(t *testing.T) {
+	got := RolesAtLeast(RoleManager)
+	want := []Role{RoleOwner, RoleAdmin, RoleManager}
+	if !slices.Equal(got, want) {
+		t.Errorf("RolesAtLeast(manager) = %v, want %v", got, want)
+	}
+}
@@ -69,6 +69,28 @@ func (r Role) CanAssign(other Role) bool {
 	return r.CanManage(other)
 }
 
+// RequiresMFA returns true if logins with this role must pass a TOTP
+// challenge. Roles that can manage staff (manager and above) require it,
+// since a leaked password for them gives control over the whole tenant.
+func (r Role) RequiresMFA() bool {
+	return r.Level() >= RoleManager.Level()
+}
+
+// AllowsPINLogin returns true if this role may log in with a staff PIN on a
+// shared POS terminal. A 4-6 digit PIN is only strong enough for front-of-
+// house roles, so it is limited to cashier and below.
+func (r Role) AllowsPINLogin() bool {
+	return r.Level() <= RoleCashier.Level()
+}
+
+// AllowsPasskeyLogin returns true if this role may register passkeys and
+// sign in with one instead of a password. Passkeys are meant for the
+// manager and owner accounts used on shared devices, where passwords end up
+// written down; they are limited to the roles that also require MFA.
+func (r Role) AllowsPasskeyLogin() bool {
+	return r.RequiresMFA()
+}
+
 // String returns the string representation of the role.
 func (r Role) String() string {
 	return string(r)
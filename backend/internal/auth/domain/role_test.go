@@ -100,6 +100,76 @@ func TestRole_CanAssign(t *testing.T) {
 	}
 }
 
+func TestRole_RequiresMFA(t *testing.T) {
+	tests := []struct {
+		role     Role
+		expected bool
+	}{
+		{RoleOwner, true},
+		{RoleAdmin, true},
+		{RoleManager, true},
+		{RoleCashier, false},
+		{RoleWaiter, false},
+		{RoleKitchen, false},
+		{RoleViewer, false},
+		{Role("invalid"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			if got := tt.role.RequiresMFA(); got != tt.expected {
+				t.Errorf("Role(%q).RequiresMFA() = %v, want %v", tt.role, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRole_AllowsPINLogin(t *testing.T) {
+	tests := []struct {
+		role     Role
+		expected bool
+	}{
+		{RoleOwner, false},
+		{RoleAdmin, false},
+		{RoleManager, false},
+		{RoleCashier, true},
+		{RoleWaiter, true},
+		{RoleKitchen, true},
+		{RoleViewer, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			if got := tt.role.AllowsPINLogin(); got != tt.expected {
+				t.Errorf("Role(%q).AllowsPINLogin() = %v, want %v", tt.role, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRole_AllowsPasskeyLogin(t *testing.T) {
+	tests := []struct {
+		role     Role
+		expected bool
+	}{
+		{RoleOwner, true},
+		{RoleAdmin, true},
+		{RoleManager, true},
+		{RoleCashier, false},
+		{RoleWaiter, false},
+		{RoleKitchen, false},
+		{RoleViewer, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			if got := tt.role.AllowsPasskeyLogin(); got != tt.expected {
+				t.Errorf("Role(%q).AllowsPasskeyLogin() = %v, want %v", tt.role, got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestRole_String(t *testing.T) {
 	if RoleManager.String() != "manager" {
 		t.Errorf("RoleManager.String() = %q, want %q", RoleManager.String(), "manager")
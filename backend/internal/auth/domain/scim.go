@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SCIMRole is the authority a SCIM client has in its tenant. It provisions
+// staff like an admin would: it may manage and assign any role an admin
+// may, and sees (but can't change) admins and owners.
+const SCIMRole = RoleAdmin
+
+// SCIMToken is the bearer token a tenant's HR system or identity provider
+// uses to provision staff through the SCIM API. Like a terminal's device
+// token, it is shown once when created and stored hashed.
+type SCIMToken struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	TokenHash  string     `gorm:"uniqueIndex;size:255;not null" json:"-"` // Hashed bearer token
+	CreatedBy  uuid.UUID  `gorm:"type:uuid" json:"created_by"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// Associations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (SCIMToken) TableName() string {
+	return "scim_tokens"
+}
+
+// IsRevoked checks if the token has been revoked.
+func (t *SCIMToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// SCIMUserLink records that a user was provisioned into a tenant over SCIM,
+// with the id the provisioning client knows them by (the SCIM externalId).
+// It outlives the user's role in the tenant, so someone deprovisioned and
+// later rehired can be provisioned again without a new invitation.
+type SCIMUserLink struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_scim_user_link" json:"tenant_id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_scim_user_link;index" json:"user_id"`
+	ExternalID string    `gorm:"size:255;not null;default:''" json:"external_id,omitempty"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Associations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+	User   User   `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (SCIMUserLink) TableName() string {
+	return "scim_user_links"
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSCIMToken_IsRevoked(t *testing.T) {
+	token := &SCIMToken{}
+	if token.IsRevoked() {
+		t.Error("new token should not be revoked")
+	}
+
+	now := time.Now()
+	token.RevokedAt = &now
+	if !token.IsRevoked() {
+		t.Error("token with RevokedAt should be revoked")
+	}
+}
+
+func TestSCIMRole(t *testing.T) {
+	for _, role := range []Role{RoleManager, RoleCashier, RoleWaiter, RoleKitchen, RoleViewer} {
+		if !SCIMRole.CanManage(role) {
+			t.Errorf("SCIM should manage %s", role)
+		}
+	}
+	for _, role := range []Role{RoleOwner, RoleAdmin} {
+		if SCIMRole.CanManage(role) || SCIMRole.CanAssign(role) {
+			t.Errorf("SCIM should not manage or assign %s", role)
+		}
+	}
+}
+
+func TestSCIM_TableNames(t *testing.T) {
+	if (SCIMToken{}).TableName() != "scim_tokens" {
+		t.Errorf("SCIMToken.TableName() = %q", (SCIMToken{}).TableName())
+	}
+	if (SCIMUserLink{}).TableName() != "scim_user_links" {
+		t.Errorf("SCIMUserLink.TableName() = %q", (SCIMUserLink{}).TableName())
+	}
+}
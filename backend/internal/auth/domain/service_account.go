@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix starts every API key, so the auth middleware can tell keys
+// from access tokens and secret scanners can spot leaked ones.
+const APIKeyPrefix = "sbk_"
+
+// ServiceAccountRole is the rank a service account holds for role-level
+// checks. It is the lowest, so only the permissions in a service account's
+// scopes open routes to it.
+const ServiceAccountRole = RoleViewer
+
+// MaxServiceAccountNameLength is the longest name a service account may have.
+const MaxServiceAccountNameLength = 100
+
+// API key lifetimes.
+const (
+	// DefaultAPIKeyLifetime is how long a key is valid when no expiry is given.
+	DefaultAPIKeyLifetime = 90 * 24 * time.Hour
+	// MaxAPIKeyLifetime is the longest a key may be valid.
+	MaxAPIKeyLifetime = 365 * 24 * time.Hour
+	// MaxAPIKeyRotationGrace is the longest a rotated key keeps working
+	// alongside its replacement.
+	MaxAPIKeyRotationGrace = 7 * 24 * time.Hour
+)
+
+// ServiceAccount is a tenant's non-human identity for an integration such
+// as an accounting sync or a delivery platform. It authenticates with API
+// keys and may do exactly what its scopes allow.
+type ServiceAccount struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	Name        string         `gorm:"size:100;not null" json:"name"`
+	Description string         `gorm:"size:500;not null;default:''" json:"description,omitempty"`
+	Scopes      PermissionList `gorm:"type:jsonb;not null" json:"scopes"`
+	CreatedBy   uuid.UUID      `gorm:"type:uuid" json:"created_by"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Associations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (ServiceAccount) TableName() string {
+	return "service_accounts"
+}
+
+// HasScope returns true if the service account's scopes grant the permission.
+func (a *ServiceAccount) HasScope(p Permission) bool {
+	return slices.Contains(a.Scopes, p)
+}
+
+// Claims returns the claims a request authenticated with one of the
+// account's keys carries: the account as subject and the key as token ID,
+// with the account's scopes as permissions.
+func (a *ServiceAccount) Claims(key *APIKey) *Claims {
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   a.ID.String(),
+			ID:        key.ID.String(),
+			ExpiresAt: jwt.NewNumericDate(key.ExpiresAt),
+		},
+		TenantID:    a.TenantID,
+		Role:        ServiceAccountRole,
+		Permissions: slices.Clone(a.Scopes),
+	}
+}
+
+// NormalizeServiceAccountName trims a service account name and checks it
+// is 1-100 characters.
+func NormalizeServiceAccountName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxServiceAccountNameLength {
+		return "", ErrServiceAccountName
+	}
+	return name, nil
+}
+
+// APIKey is a credential of a service account. Like a terminal's device
+// token it is shown once when created and stored hashed; the prefix is
+// kept in the clear so admins can tell keys apart. Keys always expire, and
+// rotating one issues a replacement while the old key keeps working for a
+// grace period.
+type APIKey struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ServiceAccountID uuid.UUID  `gorm:"type:uuid;not null;index" json:"service_account_id"`
+	TenantID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	Name             string     `gorm:"size:100;not null" json:"name"`
+	Prefix           string     `gorm:"size:20;not null" json:"prefix"`
+	KeyHash          string     `gorm:"uniqueIndex;size:255;not null" json:"-"` // Hashed key
+	ExpiresAt        time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	RotatedFromID    *uuid.UUID `gorm:"type:uuid" json:"rotated_from_id,omitempty"` // Key this one replaced
+	CreatedBy        uuid.UUID  `gorm:"type:uuid" json:"created_by"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// Associations
+	ServiceAccount ServiceAccount `gorm:"foreignKey:ServiceAccountID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsRevoked checks if the key has been revoked.
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// IsExpired checks if the key has expired.
+func (k *APIKey) IsExpired() bool {
+	return !time.Now().Before(k.ExpiresAt)
+}
+
+// IsValid checks if the key can still be used.
+func (k *APIKey) IsValid() bool {
+	return !k.IsRevoked() && !k.IsExpired()
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestServiceAccount_Claims(t *testing.T) {
+	account := &ServiceAccount{ID: uuid.New(), TenantID: uuid.New(), Scopes: PermissionList{PermUsersManage}}
+	key := &APIKey{ID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)}
+
+	claims := account.Claims(key)
+
+	if userID, err := claims.GetUserID(); err != nil || userID != account.ID {
+		t.Errorf("subject = %q, want the service account", claims.Subject)
+	}
+	if claims.ID != key.ID.String() || claims.TenantID != account.TenantID {
+		t.Errorf("claims = %+v", claims)
+	}
+	if claims.Role != ServiceAccountRole || len(claims.Permissions) != 1 || claims.Permissions[0] != PermUsersManage {
+		t.Errorf("role %s, permissions %v, want %s with the scopes", claims.Role, claims.Permissions, ServiceAccountRole)
+	}
+
+	claims.Permissions[0] = PermTerminalsManage
+	if !account.HasScope(PermUsersManage) {
+		t.Error("changing the claims should not change the account's scopes")
+	}
+}
+
+func TestNormalizeServiceAccountName(t *testing.T) {
+	if name, err := NormalizeServiceAccountName("  Accounting sync "); err != nil || name != "Accounting sync" {
+		t.Errorf("NormalizeServiceAccountName = %q, %v", name, err)
+	}
+	for _, name := range []string{"", "   ", strings.Repeat("a", MaxServiceAccountNameLength+1)} {
+		if _, err := NormalizeServiceAccountName(name); !errors.Is(err, ErrServiceAccountName) {
+			t.Errorf("NormalizeServiceAccountName(%q) error = %v, want ErrServiceAccountName", name, err)
+		}
+	}
+}
+
+func TestAPIKey_IsValid(t *testing.T) {
+	key := &APIKey{ExpiresAt: time.Now().Add(time.Hour)}
+	if !key.IsValid() {
+		t.Error("new key should be valid")
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	if key.IsValid() || !key.IsRevoked() {
+		t.Error("revoked key should not be valid")
+	}
+
+	key = &APIKey{ExpiresAt: time.Now().Add(-time.Second)}
+	if key.IsValid() || !key.IsExpired() {
+		t.Error("expired key should not be valid")
+	}
+}
+
+func TestServiceAccount_TableNames(t *testing.T) {
+	if (ServiceAccount{}).TableName() != "service_accounts" {
+		t.Errorf("ServiceAccount.TableName() = %q", (ServiceAccount{}).TableName())
+	}
+	if (APIKey{}).TableName() != "api_keys" {
+		t.Errorf("APIKey.TableName() = %q", (APIKey{}).TableName())
+	}
+}
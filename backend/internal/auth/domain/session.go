@@ -8,10 +8,21 @@ import (
 
 // Session represents an active authentication session.
 // Refresh tokens are stored here for revocation support.
+//
+// Every refresh rotates the token into a new session. All sessions rotated
+// from the same login share a FamilyID, and ParentID links each one to the
+// session it replaced, so replaying an already-rotated token can be traced
+// back to (and revoke) the whole chain.
+//
+// SignInMethod records how the family's login was authenticated, so moving
+// the session to another tenant can ask for more than the login proved.
 type Session struct {
 	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	UserID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
 	TenantID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	FamilyID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"family_id"`
+	ParentID     *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	SignInMethod string     `gorm:"size:20;not null" json:"sign_in_method"`
 	RefreshToken string     `gorm:"uniqueIndex;size:255;not null" json:"-"` // Hashed token
 	DeviceInfo   string     `gorm:"size:500" json:"device_info,omitempty"`
 	IPAddress    string     `gorm:"size:45" json:"ip_address,omitempty"` // IPv6 max length
@@ -29,6 +40,45 @@ func (Session) TableName() string {
 	return "sessions"
 }
 
+// Ways a session can be signed in, recorded as Session.SignInMethod.
+const (
+	SignInPassword = "password"
+	// SignInMFA is a password login, or a tenant switch, completed with a
+	// second factor.
+	SignInMFA     = "mfa"
+	SignInPasskey = "passkey"
+	// SignInSSO is vouched for by the tenant's identity provider rather
+	// than credentials checked here.
+	SignInSSO = "sso"
+	// SignInPIN is vouched for by a shared terminal. PIN logins only issue
+	// short-lived access tokens today, so no session records it yet.
+	SignInPIN = "pin"
+)
+
+// IsDelegated reports whether the session's login was vouched for by an
+// identity provider or a terminal rather than the user's own credentials.
+// Such a session only proves who the user is to the tenant that trusted
+// that provider. Sessions whose method wasn't recorded count as delegated.
+func (s *Session) IsDelegated() bool {
+	switch s.SignInMethod {
+	case SignInPassword, SignInMFA, SignInPasskey:
+		return false
+	}
+	return true
+}
+
+// SatisfiesMFA reports whether the session's login is good enough for a role
+// that requires MFA: it was completed with a second factor or a passkey, or
+// vouched for by an identity provider, which login already trusts with such
+// roles.
+func (s *Session) SatisfiesMFA() bool {
+	switch s.SignInMethod {
+	case SignInMFA, SignInPasskey, SignInSSO:
+		return true
+	}
+	return false
+}
+
 // IsValid checks if the session is still valid (not revoked and not expired).
 func (s *Session) IsValid() bool {
 	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
@@ -50,6 +100,22 @@ func (s *Session) Revoke() {
 	s.RevokedAt = &now
 }
 
+// Rotate returns the session that replaces s when its refresh token is
+// exchanged, in the same family with s as its parent. The caller sets the
+// new RefreshToken.
+func (s *Session) Rotate(expiresAt time.Time) *Session {
+	parentID := s.ID
+	return &Session{
+		ID:           uuid.New(),
+		UserID:       s.UserID,
+		TenantID:     s.TenantID,
+		FamilyID:     s.FamilyID,
+		ParentID:     &parentID,
+		SignInMethod: s.SignInMethod,
+		ExpiresAt:    expiresAt,
+	}
+}
+
 // TimeUntilExpiry returns the duration until the session expires.
 func (s *Session) TimeUntilExpiry() time.Duration {
 	return time.Until(s.ExpiresAt)
@@ -107,6 +107,38 @@ func TestSession_Revoke(t *testing.T) {
 	}
 }
 
+func TestSession_Rotate(t *testing.T) {
+	parent := Session{
+		ID:           uuid.New(),
+		UserID:       uuid.New(),
+		TenantID:     uuid.New(),
+		FamilyID:     uuid.New(),
+		SignInMethod: SignInSSO,
+	}
+	expiresAt := time.Now().Add(time.Hour)
+
+	child := parent.Rotate(expiresAt)
+
+	if child.ID == uuid.Nil || child.ID == parent.ID {
+		t.Error("Rotated session should have a new ID")
+	}
+	if child.FamilyID != parent.FamilyID {
+		t.Error("Rotated session should stay in the same family")
+	}
+	if child.ParentID == nil || *child.ParentID != parent.ID {
+		t.Error("Rotated session should point at its parent")
+	}
+	if child.UserID != parent.UserID || child.TenantID != parent.TenantID {
+		t.Error("Rotated session should keep the user and tenant")
+	}
+	if child.SignInMethod != SignInSSO {
+		t.Error("Rotated session should keep how the login was signed in")
+	}
+	if !child.ExpiresAt.Equal(expiresAt) {
+		t.Error("Rotated session should carry the new expiry")
+	}
+}
+
 func TestSession_TimeUntilExpiry(t *testing.T) {
 	s := Session{
 		ExpiresAt: time.Now().Add(time.Hour),
@@ -124,3 +156,43 @@ func TestSession_TableName(t *testing.T) {
 		t.Errorf("TableName() = %q, want %q", s.TableName(), "sessions")
 	}
 }
+
+func TestSession_IsDelegated(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{SignInPassword, false},
+		{SignInMFA, false},
+		{SignInPasskey, false},
+		{SignInSSO, true},
+		{SignInPIN, true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		s := Session{SignInMethod: tt.method}
+		if got := s.IsDelegated(); got != tt.want {
+			t.Errorf("IsDelegated() with method %q = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestSession_SatisfiesMFA(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{SignInPassword, false},
+		{SignInMFA, true},
+		{SignInPasskey, true},
+		{SignInSSO, true},
+		{SignInPIN, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		s := Session{SignInMethod: tt.method}
+		if got := s.SatisfiesMFA(); got != tt.want {
+			t.Errorf("SatisfiesMFA() with method %q = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
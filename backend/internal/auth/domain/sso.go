@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SSOLoginStateTTL is how long a user has to sign in at the identity
+// provider and come back before the SSO login must be started again.
+const SSOLoginStateTTL = 10 * time.Minute
+
+// TenantSSOConfig is a tenant's OpenID Connect identity provider. Staff sign
+// in at the provider and are linked to their existing account by verified
+// email, provided it is in one of the allowed domains. With
+// PasswordLoginDisabled set, SSO is the only way into the tenant for
+// everyone but its owners, who keep password login as a break-glass path.
+type TenantSSOConfig struct {
+	ID                    uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID              uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"tenant_id"`
+	Issuer                string     `gorm:"size:500;not null" json:"issuer"`
+	ClientID              string     `gorm:"size:255;not null" json:"client_id"`
+	ClientSecret          string     `gorm:"size:500;not null" json:"-"`
+	AllowedDomains        DomainList `gorm:"type:jsonb;not null" json:"allowed_domains"`
+	PasswordLoginDisabled bool       `gorm:"default:false;not null" json:"password_login_disabled"`
+	UpdatedBy             *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	CreatedAt             time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt             time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Associations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (TenantSSOConfig) TableName() string {
+	return "tenant_sso_configs"
+}
+
+// AllowsEmail reports whether email is in one of the allowed domains.
+// Subdomains must be listed separately.
+func (c *TenantSSOConfig) AllowsEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return false
+	}
+	return slices.Contains(c.AllowedDomains, strings.ToLower(email[at+1:]))
+}
+
+// AllowsPasswordLogin reports whether a member with role may sign in to the
+// tenant with a password.
+func (c *TenantSSOConfig) AllowsPasswordLogin(role Role) bool {
+	return !c.PasswordLoginDisabled || role == RoleOwner
+}
+
+// NormalizeEmailDomains lowercases, trims and de-duplicates a list of email
+// domains ("@" prefixes are dropped). The list must not be empty.
+func NormalizeEmailDomains(domains []string) (DomainList, error) {
+	normalized := make(DomainList, 0, len(domains))
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if !isEmailDomain(d) {
+			return nil, ErrSSOConfigInvalid
+		}
+		if !slices.Contains(normalized, d) {
+			normalized = append(normalized, d)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, ErrSSOConfigInvalid
+	}
+	return normalized, nil
+}
+
+// isEmailDomain checks d looks like a DNS name with at least two labels.
+func isEmailDomain(d string) bool {
+	if len(d) > 253 || !strings.Contains(d, ".") {
+		return false
+	}
+	for _, label := range strings.Split(d, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// DomainList is a list of email domains stored as a JSON array.
+type DomainList []string
+
+// Scan implements sql.Scanner interface for database reads.
+func (l *DomainList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan type %T into DomainList", value)
+	}
+
+	if len(bytes) == 0 {
+		*l = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
+// Value implements driver.Valuer interface for database writes. A nil list
+// is stored as an empty array, since the column is NOT NULL.
+func (l DomainList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+// GormDataType implements GORM's custom type interface.
+func (l DomainList) GormDataType() string {
+	return "jsonb"
+}
+
+// SSOLoginState is the single-use state between sending a user to the
+// identity provider and their return. The state parameter is stored hashed;
+// the nonce and PKCE code verifier are kept in full, since the callback has
+// to present them to the provider.
+type SSOLoginState struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	StateHash    string     `gorm:"uniqueIndex;size:255;not null" json:"-"`
+	Nonce        string     `gorm:"size:255;not null" json:"-"`
+	CodeVerifier string     `gorm:"size:255;not null" json:"-"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt       *time.Time `json:"used_at,omitempty"`
+}
+
+// TableName specifies the table name for GORM.
+func (SSOLoginState) TableName() string {
+	return "sso_login_states"
+}
+
+// IsValid checks if the state can still be redeemed (not used and not expired).
+func (s *SSOLoginState) IsValid() bool {
+	return s.UsedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// UserIdentity links an account at an identity provider (issuer and
+// subject) to a user. Links are made on the first SSO login, by verified
+// email, and from then on the subject alone identifies the user.
+type UserIdentity struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Issuer      string     `gorm:"size:500;not null;uniqueIndex:idx_user_identities_subject" json:"issuer"`
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:idx_user_identities_subject" json:"subject"`
+	Email       string     `gorm:"size:255" json:"email"` // Email the link was made with
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+
+	// Associations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
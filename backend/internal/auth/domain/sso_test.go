@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNormalizeEmailDomains(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		want    DomainList
+		wantErr bool
+	}{
+		{"plain", []string{"example.com"}, DomainList{"example.com"}, false},
+		{"normalized", []string{" @Example.COM ", "staff.example.com"}, DomainList{"example.com", "staff.example.com"}, false},
+		{"deduplicated", []string{"example.com", "EXAMPLE.com"}, DomainList{"example.com"}, false},
+		{"empty list", nil, nil, true},
+		{"blank entry", []string{"example.com", " "}, nil, true},
+		{"single label", []string{"localhost"}, nil, true},
+		{"email address", []string{"ana@example.com"}, nil, true},
+		{"wildcard", []string{"*.example.com"}, nil, true},
+		{"leading hyphen", []string{"-example.com"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeEmailDomains(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeEmailDomains(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err != nil && err != ErrSSOConfigInvalid {
+				t.Errorf("error = %v, want ErrSSOConfigInvalid", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("NormalizeEmailDomains(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantSSOConfig_AllowsEmail(t *testing.T) {
+	cfg := &TenantSSOConfig{AllowedDomains: DomainList{"example.com"}}
+
+	tests := map[string]bool{
+		"ana@example.com":         true,
+		"Ana@EXAMPLE.com":         true,
+		"ana@staff.example.com":   false,
+		"ana@example.com.evil.io": false,
+		"ana@notexample.com":      false,
+		"@example.com":            false,
+		"example.com":             false,
+		"":                        false,
+	}
+	for email, want := range tests {
+		if got := cfg.AllowsEmail(email); got != want {
+			t.Errorf("AllowsEmail(%q) = %v, want %v", email, got, want)
+		}
+	}
+}
+
+func TestTenantSSOConfig_AllowsPasswordLogin(t *testing.T) {
+	cfg := &TenantSSOConfig{}
+	if !cfg.AllowsPasswordLogin(RoleWaiter) {
+		t.Error("password login should be allowed by default")
+	}
+
+	cfg.PasswordLoginDisabled = true
+	if cfg.AllowsPasswordLogin(RoleAdmin) || cfg.AllowsPasswordLogin(RoleWaiter) {
+		t.Error("password login should be disabled for non-owners")
+	}
+	if !cfg.AllowsPasswordLogin(RoleOwner) {
+		t.Error("owners should keep password login as a break-glass path")
+	}
+}
+
+func TestDomainList_ScanValue(t *testing.T) {
+	list := DomainList{"example.com", "example.org"}
+	v, err := list.Value()
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+
+	var scanned DomainList
+	if err := scanned.Scan(v); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if !slices.Equal(scanned, list) {
+		t.Errorf("Scan(Value()) = %v, want %v", scanned, list)
+	}
+
+	if v, _ := DomainList(nil).Value(); string(v.([]byte)) != "[]" {
+		t.Errorf("nil list Value() = %s, want []", v)
+	}
+	if err := scanned.Scan(42); err == nil {
+		t.Error("Scan(int) should fail")
+	}
+}
+
+func TestSSOLoginState_IsValid(t *testing.T) {
+	state := &SSOLoginState{ExpiresAt: time.Now().Add(SSOLoginStateTTL)}
+	if !state.IsValid() {
+		t.Error("fresh state should be valid")
+	}
+
+	now := time.Now()
+	state.UsedAt = &now
+	if state.IsValid() {
+		t.Error("used state should not be valid")
+	}
+
+	expired := &SSOLoginState{ExpiresAt: time.Now().Add(-time.Second)}
+	if expired.IsValid() {
+		t.Error("expired state should not be valid")
+	}
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Staff PIN length limits.
+const (
+	MinPINLength = 4
+	MaxPINLength = 6
+)
+
+// StaffPIN is a user's short numeric PIN for fast login on a tenant's
+// registered POS terminals. Roles that may not log in with a PIN (manager
+// and above) can still set one to approve sensitive actions on a terminal.
+// PINs are set per tenant, and failed attempts lock the PIN independently of
+// the account's password lockout.
+type StaffPIN struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_staff_pin_user_tenant" json:"user_id"`
+	TenantID       uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_staff_pin_user_tenant" json:"tenant_id"`
+	PINHash        string     `gorm:"column:pin_hash;size:255;not null" json:"-"`
+	FailedAttempts int        `gorm:"default:0;not null" json:"-"`
+	LockedUntil    *time.Time `json:"-"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Associations
+	User   User   `gorm:"foreignKey:UserID" json:"-"`
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (StaffPIN) TableName() string {
+	return "staff_pins"
+}
+
+// IsLocked checks if the PIN is currently locked out due to failed attempts.
+func (p *StaffPIN) IsLocked() bool {
+	return p.LockedUntil != nil && time.Now().Before(*p.LockedUntil)
+}
+
+// RecordFailure counts a wrong PIN, locking the PIN for lockDuration once
+// maxAttempts is reached. Returns true if this failure locked it.
+func (p *StaffPIN) RecordFailure(maxAttempts int, lockDuration time.Duration) bool {
+	p.FailedAttempts++
+	if p.FailedAttempts < maxAttempts {
+		return false
+	}
+	until := time.Now().Add(lockDuration)
+	p.LockedUntil = &until
+	return true
+}
+
+// ResetFailures clears the failed-attempt counter and any lockout.
+func (p *StaffPIN) ResetFailures() {
+	p.FailedAttempts = 0
+	p.LockedUntil = nil
+}
+
+// ValidatePIN checks that a PIN is MinPINLength to MaxPINLength digits and
+// not one of the guesses tried first: a repeated digit ("1111") or a
+// straight run ("1234", "9876").
+func ValidatePIN(pin string) error {
+	if len(pin) < MinPINLength || len(pin) > MaxPINLength {
+		return ErrPINFormat
+	}
+	for _, c := range pin {
+		if c < '0' || c > '9' {
+			return ErrPINFormat
+		}
+	}
+
+	step := int(pin[1]) - int(pin[0])
+	if step < -1 || step > 1 {
+		return nil
+	}
+	for i := 2; i < len(pin); i++ {
+		if int(pin[i])-int(pin[i-1]) != step {
+			return nil
+		}
+	}
+	return ErrPINTooSimple
+}
+
+// Terminal is a shared POS device registered to a tenant. Staff PIN logins
+// are only accepted from registered terminals, which authenticate with an
+// opaque device token issued at registration.
+type Terminal struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TenantID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	TokenHash  string     `gorm:"uniqueIndex;size:255;not null" json:"-"` // Hashed device token
+	CreatedBy  uuid.UUID  `gorm:"type:uuid" json:"created_by"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// Associations
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// TableName specifies the table name for GORM.
+func (Terminal) TableName() string {
+	return "pos_terminals"
+}
+
+// IsRevoked checks if the terminal has been revoked.
+func (t *Terminal) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidatePIN(t *testing.T) {
+	tests := []struct {
+		pin     string
+		wantErr error
+	}{
+		{"2580", nil},
+		{"73914", nil},
+		{"104729", nil},
+		{"123", ErrPINFormat},
+		{"1234567", ErrPINFormat},
+		{"12a4", ErrPINFormat},
+		{"", ErrPINFormat},
+		{"1111", ErrPINTooSimple},
+		{"1234", ErrPINTooSimple},
+		{"987654", ErrPINTooSimple},
+		{"1235", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pin, func(t *testing.T) {
+			if err := ValidatePIN(tt.pin); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidatePIN(%q) = %v, want %v", tt.pin, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStaffPIN_RecordFailure(t *testing.T) {
+	pin := &StaffPIN{}
+
+	for i := 1; i < 3; i++ {
+		if pin.RecordFailure(3, time.Minute) {
+			t.Fatalf("failure %d should not lock the PIN", i)
+		}
+	}
+	if pin.IsLocked() {
+		t.Fatal("PIN should not be locked before the limit")
+	}
+
+	if !pin.RecordFailure(3, time.Minute) {
+		t.Fatal("failure at the limit should lock the PIN")
+	}
+	if !pin.IsLocked() {
+		t.Error("PIN should be locked at the limit")
+	}
+
+	pin.ResetFailures()
+	if pin.IsLocked() || pin.FailedAttempts != 0 {
+		t.Error("ResetFailures should clear the lockout")
+	}
+}
+
+func TestTerminal_IsRevoked(t *testing.T) {
+	terminal := &Terminal{}
+	if terminal.IsRevoked() {
+		t.Error("new terminal should not be revoked")
+	}
+
+	now := time.Now()
+	terminal.RevokedAt = &now
+	if !terminal.IsRevoked() {
+		t.Error("terminal with RevokedAt should be revoked")
+	}
+}
+
+func TestStaffPIN_TableNames(t *testing.T) {
+	if (StaffPIN{}).TableName() != "staff_pins" {
+		t.Errorf("StaffPIN.TableName() = %q", (StaffPIN{}).TableName())
+	}
+	if (Terminal{}).TableName() != "pos_terminals" {
+		t.Errorf("Terminal.TableName() = %q", (Terminal{}).TableName())
+	}
+}
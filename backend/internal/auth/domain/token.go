@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -28,12 +29,26 @@ func NewTokenPair(accessToken, refreshToken string, expiresAt time.Time) *TokenP
 	}
 }
 
+// ImpersonationTTL is how long an impersonation access token is valid.
+// There is no refresh token; the actor starts a new impersonation to go on.
+const ImpersonationTTL = 15 * time.Minute
+
 // Claims represents the JWT payload for access tokens.
 type Claims struct {
 	jwt.RegisteredClaims
-	TenantID uuid.UUID `json:"tenant_id"`
-	Role     Role      `json:"role"`
-	Email    string    `json:"email"`
+	TenantID    uuid.UUID    `json:"tenant_id"`
+	Role        Role         `json:"role"`
+	Email       string       `json:"email"`
+	SessionID   string       `json:"sid,omitempty"`       // Session the token was issued for
+	Permissions []Permission `json:"perms,omitempty"`     // Permissions granted for the tenant
+	Actor       *Actor       `json:"act,omitempty"`       // User impersonating the subject
+	ClientID    string       `json:"client_id,omitempty"` // OAuth client the token was issued to
+}
+
+// Actor is the act claim: the user actually making requests with a token
+// issued for someone else.
+type Actor struct {
+	Subject string `json:"sub"`
 }
 
 // NewClaims creates new JWT claims for a user session.
@@ -58,6 +73,64 @@ func (c *Claims) GetUserID() (uuid.UUID, error) {
 	return uuid.Parse(c.Subject)
 }
 
+// GetSessionID parses and returns the session ID from the sid claim.
+// Tokens issued without a session return an error.
+func (c *Claims) GetSessionID() (uuid.UUID, error) {
+	return uuid.Parse(c.SessionID)
+}
+
+// GetActorID returns the ID of the user impersonating the subject. It
+// reports false for ordinary tokens.
+func (c *Claims) GetActorID() (uuid.UUID, bool) {
+	if c.Actor == nil {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Actor.Subject)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// IsImpersonation returns true if the token was issued for impersonation.
+func (c *Claims) IsImpersonation() bool {
+	return c.Actor != nil
+}
+
+// GetClientID returns the ID of the OAuth client the token was issued to.
+// It reports false for tokens issued to users.
+func (c *Claims) GetClientID() (uuid.UUID, bool) {
+	if c.ClientID == "" {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.ClientID)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// IsClientToken returns true if the token was issued to an OAuth client,
+// either on a user's behalf or for the client itself.
+func (c *Claims) IsClientToken() bool {
+	return c.ClientID != ""
+}
+
+// GetPermissions returns the permissions the token grants. Tokens issued
+// before permissions were added to the claims fall back to the role's
+// default permissions.
+func (c *Claims) GetPermissions() []Permission {
+	if c.Permissions == nil {
+		return c.Role.Permissions()
+	}
+	return c.Permissions
+}
+
+// HasPermission returns true if the token grants the permission.
+func (c *Claims) HasPermission(p Permission) bool {
+	return slices.Contains(c.GetPermissions(), p)
+}
+
 // IsExpired checks if the claims have expired.
 func (c *Claims) IsExpired() bool {
 	if c.ExpiresAt == nil {
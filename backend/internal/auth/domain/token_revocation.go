@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokedAccessToken revokes a single access token, by jti, before it expires.
+type RevokedAccessToken struct {
+	JTI       string    `gorm:"column:jti;primaryKey;size:64" json:"jti"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	RevokedAt time.Time `gorm:"autoCreateTime" json:"revoked_at"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"` // The token's own expiry
+}
+
+// TableName specifies the table name for GORM.
+func (RevokedAccessToken) TableName() string {
+	return "revoked_access_tokens"
+}
+
+// UserTokenRevocation revokes every access token issued to a user before
+// RevokedBefore. It is kept until ExpiresAt, by which time every token it
+// covers has expired on its own.
+type UserTokenRevocation struct {
+	UserID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	RevokedBefore time.Time `gorm:"not null" json:"revoked_before"`
+	ExpiresAt     time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+// TableName specifies the table name for GORM.
+func (UserTokenRevocation) TableName() string {
+	return "user_token_revocations"
+}
+
+// Covers reports whether an access token issued at issuedAt is revoked.
+// JWT iat has one-second precision, so a token issued in the same second as
+// the revocation cannot be told apart from one issued just before it and is
+// revoked too.
+func (r *UserTokenRevocation) Covers(issuedAt time.Time) bool {
+	return !issuedAt.After(r.RevokedBefore.Truncate(time.Second))
+}
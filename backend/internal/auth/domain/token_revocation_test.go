@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestUserTokenRevocation_Covers(t *testing.T) {
+	cutoff := time.Date(2026, 3, 1, 12, 0, 0, 600*int(time.Millisecond), time.UTC)
+	r := &UserTokenRevocation{UserID: uuid.New(), RevokedBefore: cutoff, ExpiresAt: cutoff.Add(time.Hour)}
+
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		want     bool
+	}{
+		{"previous second", cutoff.Truncate(time.Second).Add(-time.Second), true},
+		{"same second (iat precision)", cutoff.Truncate(time.Second), true},
+		{"next second", cutoff.Truncate(time.Second).Add(time.Second), false},
+	}
+	for _, tt := range tests {
+		if got := r.Covers(tt.issuedAt); got != tt.want {
+			t.Errorf("Covers(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -88,6 +88,62 @@ func TestClaims_GetUserID(t *testing.T) {
 	}
 }
 
+func TestClaims_GetActorID(t *testing.T) {
+	actorID := uuid.New()
+
+	tests := []struct {
+		name   string
+		actor  *Actor
+		wantID uuid.UUID
+		wantOK bool
+	}{
+		{"impersonation", &Actor{Subject: actorID.String()}, actorID, true},
+		{"ordinary token", nil, uuid.Nil, false},
+		{"invalid actor", &Actor{Subject: "invalid-uuid"}, uuid.Nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &Claims{Actor: tt.actor}
+			got, ok := claims.GetActorID()
+			if got != tt.wantID || ok != tt.wantOK {
+				t.Errorf("GetActorID() = %v, %v, want %v, %v", got, ok, tt.wantID, tt.wantOK)
+			}
+			if claims.IsImpersonation() != (tt.actor != nil) {
+				t.Errorf("IsImpersonation() = %v, want %v", claims.IsImpersonation(), tt.actor != nil)
+			}
+		})
+	}
+}
+
+func TestClaims_GetClientID(t *testing.T) {
+	clientID := uuid.New()
+
+	tests := []struct {
+		name     string
+		clientID string
+		wantID   uuid.UUID
+		wantOK   bool
+	}{
+		{"client token", clientID.String(), clientID, true},
+		{"user token", "", uuid.Nil, false},
+		{"invalid client", "invalid-uuid", uuid.Nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &Claims{ClientID: tt.clientID}
+			got, ok := claims.GetClientID()
+			if got != tt.wantID || ok != tt.wantOK {
+				t.Errorf("GetClientID() = %v, %v, want %v, %v", got, ok, tt.wantID, tt.wantOK)
+			}
+			if claims.IsClientToken() != (tt.clientID != "") {
+				t.Errorf("IsClientToken() = %v, want %v", claims.IsClientToken(), tt.clientID != "")
+			}
+		})
+	}
+}
+
 func TestClaims_IsExpired(t *testing.T) {
 	// Not expired
 	claims1 := &Claims{
@@ -115,3 +171,20 @@ func TestClaims_IsExpired(t *testing.T) {
 		t.Error("No expiry should be considered expired")
 	}
 }
+
+func TestClaims_GetPermissions(t *testing.T) {
+	claims := &Claims{Role: RoleWaiter, Permissions: []Permission{PermUsersManage}}
+	if !claims.HasPermission(PermUsersManage) {
+		t.Error("expected the perms claim to be used over the role defaults")
+	}
+
+	// Tokens issued before the perms claim fall back to the role's defaults
+	legacy := &Claims{Role: RoleManager}
+	if !legacy.HasPermission(PermUsersManage) {
+		t.Error("expected manager defaults for a token without a perms claim")
+	}
+	legacy.Role = RoleWaiter
+	if legacy.HasPermission(PermUsersManage) {
+		t.Error("waiter defaults should not include users.manage")
+	}
+}
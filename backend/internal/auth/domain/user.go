@@ -9,17 +9,23 @@ import (
 // User represents a person with access to the system.
 // Users have globally unique emails and can have roles in multiple tenants.
 type User struct {
-	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	Email            string     `gorm:"uniqueIndex;size:255;not null" json:"email"`
-	PasswordHash     string     `gorm:"size:255;not null" json:"-"` // Never serialize
-	FirstName        string     `gorm:"size:100;not null" json:"first_name"`
-	LastName         string     `gorm:"size:100;not null" json:"last_name"`
-	IsActive         bool       `gorm:"default:true;not null;index" json:"is_active"`
-	MustResetPwd     bool       `gorm:"column:must_reset_pwd;default:false;not null" json:"must_reset_password"`
-	FailedLoginCount int        `gorm:"default:0;not null" json:"-"`
-	LockedUntil      *time.Time `gorm:"index" json:"-"`
-	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt        time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	ID                uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Email             string     `gorm:"uniqueIndex;size:255;not null" json:"email"`
+	EmailVerifiedAt   *time.Time `json:"email_verified_at,omitempty"`
+	PasswordHash      string     `gorm:"size:255;not null" json:"-"` // Never serialize
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+	FirstName         string     `gorm:"size:100;not null" json:"first_name"`
+	LastName          string     `gorm:"size:100;not null" json:"last_name"`
+	IsActive          bool       `gorm:"default:true;not null;index" json:"is_active"`
+	MustResetPwd      bool       `gorm:"column:must_reset_pwd;default:false;not null" json:"must_reset_password"`
+	// Failed logins from devices the user hasn't logged in from before
+	// (see KnownDevice), and from known ones, are counted and locked apart
+	FailedLoginCount       int        `gorm:"default:0;not null" json:"-"`
+	LockedUntil            *time.Time `gorm:"index" json:"-"`
+	KnownDeviceFailedCount int        `gorm:"default:0;not null" json:"-"`
+	KnownDeviceLockedUntil *time.Time `json:"-"`
+	CreatedAt              time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt              time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
 
 	// Associations
 	TenantRoles []UserTenantRole `gorm:"foreignKey:UserID" json:"tenant_roles,omitempty"`
@@ -41,11 +47,58 @@ func (u *User) CanLogin() bool {
 	return u.IsActive
 }
 
-// IsLocked checks if the user's account is currently locked out due to failed login attempts.
+// IsEmailVerified checks if the user has confirmed they receive mail at
+// their email address.
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsLocked checks if the user's account is currently locked out due to
+// failed login attempts from unknown devices. This is the lock that applies
+// wherever the device isn't identified.
 func (u *User) IsLocked() bool {
 	return u.LockedUntil != nil && time.Now().Before(*u.LockedUntil)
 }
 
+// IsLockedOn checks if logins from a known or unknown device are currently
+// locked out, returning when the lock ends.
+func (u *User) IsLockedOn(knownDevice bool) (bool, *time.Time) {
+	until := u.LockedUntil
+	if knownDevice {
+		until = u.KnownDeviceLockedUntil
+	}
+	return until != nil && time.Now().Before(*until), until
+}
+
+// RecordLoginFailure counts a wrong password from a known or unknown device
+// and, once the policy's threshold for it is reached, locks such logins for
+// the policy's delay. Returns the end of the lock if this failure set one.
+func (u *User) RecordLoginFailure(policy LockoutPolicy, knownDevice bool) *time.Time {
+	count, lockedUntil := &u.FailedLoginCount, &u.LockedUntil
+	if knownDevice {
+		count, lockedUntil = &u.KnownDeviceFailedCount, &u.KnownDeviceLockedUntil
+	}
+	*count++
+	delay := policy.Delay(*count, knownDevice)
+	if delay == 0 {
+		return nil
+	}
+	until := time.Now().Add(delay)
+	*lockedUntil = &until
+	return &until
+}
+
+// ResetLoginFailures clears the failed-login counters and lockouts of both
+// known and unknown devices. Returns false if there was nothing to clear.
+func (u *User) ResetLoginFailures() bool {
+	if u.FailedLoginCount == 0 && u.LockedUntil == nil && u.KnownDeviceFailedCount == 0 && u.KnownDeviceLockedUntil == nil {
+		return false
+	}
+	u.FailedLoginCount, u.LockedUntil = 0, nil
+	u.KnownDeviceFailedCount, u.KnownDeviceLockedUntil = 0, nil
+	return true
+}
+
 // HasTenant checks if the user has access to the specified tenant.
 func (u *User) HasTenant(tenantID uuid.UUID) bool {
 	for _, tr := range u.TenantRoles {
@@ -56,17 +109,38 @@ func (u *User) HasTenant(tenantID uuid.UUID) bool {
 	return false
 }
 
-// GetRoleForTenant returns the user's role in the specified tenant.
+// GetRoleForTenant returns the user's role in the specified tenant right
+// now: the elevated role during an elevation, otherwise the standing role.
 // Returns empty Role if user doesn't belong to the tenant.
 func (u *User) GetRoleForTenant(tenantID uuid.UUID) Role {
 	for _, tr := range u.TenantRoles {
 		if tr.TenantID == tenantID {
-			return tr.Role
+			return tr.EffectiveRole()
 		}
 	}
 	return ""
 }
 
+// GetTenantRole returns the user's role assignment in the specified tenant,
+// or nil if the user doesn't belong to the tenant.
+func (u *User) GetTenantRole(tenantID uuid.UUID) *UserTenantRole {
+	for i := range u.TenantRoles {
+		if u.TenantRoles[i].TenantID == tenantID {
+			return &u.TenantRoles[i]
+		}
+	}
+	return nil
+}
+
+// GetPermissionsForTenant returns the user's permissions in the specified
+// tenant. Returns nil if the user doesn't belong to the tenant.
+func (u *User) GetPermissionsForTenant(tenantID uuid.UUID) []Permission {
+	if tr := u.GetTenantRole(tenantID); tr != nil {
+		return tr.Permissions()
+	}
+	return nil
+}
+
 // TenantCount returns the number of tenants the user belongs to.
 func (u *User) TenantCount() int {
 	return len(u.TenantRoles)
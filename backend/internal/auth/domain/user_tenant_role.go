@@ -1,27 +1,137 @@
 package domain
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// MaxRoleElevation is the longest a temporary role elevation may last.
+const MaxRoleElevation = 7 * 24 * time.Hour
+
 // UserTenantRole represents the junction between users and tenants,
-// defining what role a user has within a specific tenant.
+// defining what role a user has within a specific tenant. When a custom
+// role is assigned, Role holds its base role so the role hierarchy applies
+// unchanged, and the custom role's permissions replace the base role's.
+//
+// A temporary elevation (a waiter covering as shift lead for a night) is
+// kept alongside the standing role: from ValidFrom until ValidUntil,
+// ElevatedRole (and ElevatedCustomRoleID) apply instead of Role, and after
+// that the standing role applies again without anything having to change.
 type UserTenantRole struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	UserID    uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_user_tenant" json:"user_id"`
-	TenantID  uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_user_tenant" json:"tenant_id"`
-	Role      Role      `gorm:"size:20;not null;index" json:"role"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_user_tenant" json:"user_id"`
+	TenantID     uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_user_tenant" json:"tenant_id"`
+	Role         Role       `gorm:"size:20;not null;index" json:"role"`
+	CustomRoleID *uuid.UUID `gorm:"type:uuid;index" json:"custom_role_id,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Temporary elevation
+	ElevatedRole         Role       `gorm:"size:20;not null;default:''" json:"elevated_role,omitempty"`
+	ElevatedCustomRoleID *uuid.UUID `gorm:"type:uuid" json:"elevated_custom_role_id,omitempty"`
+	ValidFrom            *time.Time `json:"valid_from,omitempty"`
+	ValidUntil           *time.Time `gorm:"index" json:"valid_until,omitempty"`
+	ElevatedBy           *uuid.UUID `gorm:"type:uuid" json:"elevated_by,omitempty"`
+	// ElevationStartedAt is set once the start of the elevation has been
+	// processed (audited and the user's old-role tokens revoked).
+	ElevationStartedAt *time.Time `json:"elevation_started_at,omitempty"`
 
 	// Associations
-	User   User   `gorm:"foreignKey:UserID" json:"-"`
-	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+	User               User        `gorm:"foreignKey:UserID" json:"-"`
+	Tenant             Tenant      `gorm:"foreignKey:TenantID" json:"-"`
+	CustomRole         *CustomRole `gorm:"foreignKey:CustomRoleID" json:"custom_role,omitempty"`
+	ElevatedCustomRole *CustomRole `gorm:"foreignKey:ElevatedCustomRoleID" json:"elevated_custom_role,omitempty"`
 }
 
 // TableName specifies the table name for GORM.
 func (UserTenantRole) TableName() string {
 	return "user_tenant_roles"
 }
+
+// HasElevation returns true if a temporary elevation is scheduled, running
+// or ended but not yet cleared.
+func (utr *UserTenantRole) HasElevation() bool {
+	return utr.ElevatedRole != "" && utr.ValidFrom != nil && utr.ValidUntil != nil
+}
+
+// IsElevated returns true if a temporary elevation applies right now.
+func (utr *UserTenantRole) IsElevated() bool {
+	if !utr.HasElevation() {
+		return false
+	}
+	now := time.Now()
+	return !now.Before(*utr.ValidFrom) && now.Before(*utr.ValidUntil)
+}
+
+// ElevationEnded returns true if the elevation's window has passed.
+func (utr *UserTenantRole) ElevationEnded() bool {
+	return utr.HasElevation() && !time.Now().Before(*utr.ValidUntil)
+}
+
+// ClearElevation removes any temporary elevation, leaving the standing role.
+func (utr *UserTenantRole) ClearElevation() {
+	utr.ElevatedRole = ""
+	utr.ElevatedCustomRoleID = nil
+	utr.ElevatedCustomRole = nil
+	utr.ValidFrom = nil
+	utr.ValidUntil = nil
+	utr.ElevatedBy = nil
+	utr.ElevationStartedAt = nil
+}
+
+// EffectiveRole returns the role that applies right now: the elevated role
+// during an elevation, otherwise the standing role.
+func (utr *UserTenantRole) EffectiveRole() Role {
+	if utr.IsElevated() {
+		return utr.ElevatedRole
+	}
+	return utr.Role
+}
+
+// effectiveCustomRole returns the custom role that applies right now, if any.
+func (utr *UserTenantRole) effectiveCustomRole() *CustomRole {
+	if utr.IsElevated() {
+		return utr.ElevatedCustomRole
+	}
+	return utr.CustomRole
+}
+
+// Permissions returns the permissions the assignment grants right now: the
+// custom role's own set if one applies, otherwise the role's defaults.
+func (utr *UserTenantRole) Permissions() []Permission {
+	if custom := utr.effectiveCustomRole(); custom != nil {
+		perms := slices.Clone([]Permission(custom.Permissions))
+		slices.Sort(perms)
+		return perms
+	}
+	return utr.EffectiveRole().Permissions()
+}
+
+// RoleName returns the name of the role that applies right now: the custom
+// role's if one applies, otherwise the built-in role's.
+func (utr *UserTenantRole) RoleName() string {
+	if custom := utr.effectiveCustomRole(); custom != nil {
+		return custom.Name
+	}
+	return string(utr.EffectiveRole())
+}
+
+// StandingRoleName returns the name of the standing role, ignoring any
+// elevation.
+func (utr *UserTenantRole) StandingRoleName() string {
+	if utr.CustomRole != nil {
+		return utr.CustomRole.Name
+	}
+	return string(utr.Role)
+}
+
+// ElevatedRoleName returns the name of the elevated role, or "" if there is
+// no elevation.
+func (utr *UserTenantRole) ElevatedRoleName() string {
+	if utr.ElevatedCustomRole != nil {
+		return utr.ElevatedCustomRole.Name
+	}
+	return string(utr.ElevatedRole)
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestUserTenantRole_Elevation(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+		return &v
+	}
+
+	tests := []struct {
+		name          string
+		validFrom     *time.Time
+		validUntil    *time.Time
+		wantElevated  bool
+		wantEnded     bool
+		wantEffective Role
+	}{
+		{"no elevation", nil, nil, false, false, RoleWaiter},
+		{"scheduled", at(time.Hour), at(2 * time.Hour), false, false, RoleWaiter},
+		{"running", at(-time.Hour), at(time.Hour), true, false, RoleManager},
+		{"ended", at(-2 * time.Hour), at(-time.Hour), false, true, RoleWaiter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			utr := UserTenantRole{Role: RoleWaiter, ValidFrom: tt.validFrom, ValidUntil: tt.validUntil}
+			if tt.validFrom != nil {
+				utr.ElevatedRole = RoleManager
+			}
+
+			if got := utr.IsElevated(); got != tt.wantElevated {
+				t.Errorf("IsElevated() = %v, want %v", got, tt.wantElevated)
+			}
+			if got := utr.ElevationEnded(); got != tt.wantEnded {
+				t.Errorf("ElevationEnded() = %v, want %v", got, tt.wantEnded)
+			}
+			if got := utr.EffectiveRole(); got != tt.wantEffective {
+				t.Errorf("EffectiveRole() = %q, want %q", got, tt.wantEffective)
+			}
+			if got := utr.Permissions(); !slices.Equal(got, tt.wantEffective.Permissions()) {
+				t.Errorf("Permissions() = %v, want the %s defaults", got, tt.wantEffective)
+			}
+		})
+	}
+}
+
+func TestUserTenantRole_ElevatedCustomRole(t *testing.T) {
+	id := uuid.New()
+	validFrom, validUntil := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	utr := UserTenantRole{
+		Role:                 RoleWaiter,
+		ElevatedRole:         RoleWaiter,
+		ElevatedCustomRoleID: &id,
+		ElevatedCustomRole: &CustomRole{
+			ID: id, Name: "Shift lead", BaseRole: RoleWaiter,
+			Permissions: PermissionList{PermUsersManage},
+		},
+		ValidFrom:  &validFrom,
+		ValidUntil: &validUntil,
+	}
+
+	if utr.RoleName() != "Shift lead" {
+		t.Errorf("RoleName() = %q, want Shift lead", utr.RoleName())
+	}
+	if utr.StandingRoleName() != "waiter" || utr.ElevatedRoleName() != "Shift lead" {
+		t.Errorf("StandingRoleName(), ElevatedRoleName() = %q, %q, want waiter, Shift lead", utr.StandingRoleName(), utr.ElevatedRoleName())
+	}
+	if got := utr.Permissions(); !slices.Equal(got, []Permission{PermUsersManage}) {
+		t.Errorf("Permissions() = %v, want the elevated custom role's", got)
+	}
+
+	utr.ClearElevation()
+	if utr.HasElevation() || utr.ElevatedRoleName() != "" {
+		t.Error("ClearElevation() should remove the elevation")
+	}
+	if utr.RoleName() != "waiter" {
+		t.Errorf("RoleName() after ClearElevation() = %q, want waiter", utr.RoleName())
+	}
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -85,6 +86,19 @@ func TestUser_GetRoleForTenant(t *testing.T) {
 	if got := u.GetRoleForTenant(tenantID3); got != "" {
 		t.Errorf("GetRoleForTenant(unknown) = %q, want empty", got)
 	}
+
+	// An elevation applies only within its window
+	validFrom, validUntil := time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)
+	u.TenantRoles[1].ElevatedRole = RoleManager
+	u.TenantRoles[1].ValidFrom, u.TenantRoles[1].ValidUntil = &validFrom, &validUntil
+	if got := u.GetRoleForTenant(tenantID2); got != RoleWaiter {
+		t.Errorf("GetRoleForTenant(tenant2) after elevation expired = %q, want %q", got, RoleWaiter)
+	}
+
+	validUntil = time.Now().Add(time.Hour)
+	if got := u.GetRoleForTenant(tenantID2); got != RoleManager {
+		t.Errorf("GetRoleForTenant(tenant2) while elevated = %q, want %q", got, RoleManager)
+	}
 }
 
 func TestUser_TenantCount(t *testing.T) {
@@ -116,6 +130,17 @@ func TestUser_NeedsPasswordReset(t *testing.T) {
 	}
 }
 
+func TestUser_IsEmailVerified(t *testing.T) {
+	if (&User{}).IsEmailVerified() {
+		t.Error("Should not be verified without email_verified_at")
+	}
+
+	now := time.Now()
+	if !(&User{EmailVerifiedAt: &now}).IsEmailVerified() {
+		t.Error("Should be verified")
+	}
+}
+
 func TestUser_TableName(t *testing.T) {
 	u := User{}
 	if u.TableName() != "users" {
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+)
+
+// e2eRefund stands in for payments.refund on the e2e server's
+// /payments/{id}/refund route.
+const e2eRefund domain.Permission = "approvals_test.refund"
+
+func init() {
+	domain.RegisterPermissions(domain.PermissionDefinition{
+		Permission:  e2eRefund,
+		Description: "Refund a payment (e2e tests)",
+		Roles:       domain.RolesAtLeast(domain.RoleManager),
+	})
+}
+
+// doWithHeader is do with one extra request header.
+func (e *e2eEnv) doWithHeader(method, path, token, header, value string, body interface{}) *http.Response {
+	e.t.Helper()
+	b, err := json.Marshal(body)
+	if err != nil {
+		e.t.Fatalf("failed to marshal request body: %v", err)
+	}
+	req, err := http.NewRequest(method, e.server.URL+path, bytes.NewReader(b))
+	if err != nil {
+		e.t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if value != "" {
+		req.Header.Set(header, value)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		e.t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	return resp
+}
+
+// TestE2E_StepUpApproval covers a manager approving a cashier's refund on
+// the cashier's device, by password and then by PIN on a terminal, and the
+// approval token being spent exactly once on the route it was issued for.
+func TestE2E_StepUpApproval(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	manager := env.seedUser("manager@example.com", "ManagerPass123!", tenant.ID, domain.RoleManager)
+	cashier := env.seedUser("cashier@example.com", "CashierPass123!", tenant.ID, domain.RoleCashier)
+
+	managerToken, _, resp := env.login("manager@example.com", "ManagerPass123!")
+	resp.Body.Close()
+	cashierToken, _, resp := env.login("cashier@example.com", "CashierPass123!")
+	resp.Body.Close()
+
+	refund := func(paymentID, approvalToken string) *http.Response {
+		return env.doWithHeader(http.MethodPost, "/payments/"+paymentID+"/refund", cashierToken, handler.ApprovalTokenHeader, approvalToken, nil)
+	}
+	wantError := func(resp *http.Response, status int, code string) {
+		t.Helper()
+		if resp.StatusCode != status {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, status)
+		}
+		var body handler.ErrorResponse
+		decodeBody(t, resp, &body)
+		if body.Error.Code != code {
+			t.Errorf("error code = %q, want %q", body.Error.Code, code)
+		}
+	}
+
+	// Without an approval the refund is refused
+	wantError(refund("payment-1", ""), http.StatusForbidden, "approval_required")
+
+	// A cashier can't approve a refund for someone else
+	wantError(env.do(http.MethodPost, "/approvals", managerToken, handler.ApprovalRequest{
+		Action: e2eRefund, ResourceID: "payment-1", ApproverEmail: "cashier@example.com", Password: "CashierPass123!",
+	}), http.StatusForbidden, "invalid_credentials")
+
+	// The manager approves with their password on the cashier's device
+	approveResp := env.do(http.MethodPost, "/approvals", cashierToken, handler.ApprovalRequest{
+		Action: e2eRefund, ResourceID: "payment-1", ApproverEmail: "manager@example.com", Password: "ManagerPass123!",
+	})
+	if approveResp.StatusCode != http.StatusCreated {
+		t.Fatalf("approve status = %d, want %d", approveResp.StatusCode, http.StatusCreated)
+	}
+	var approval handler.ApprovalResponse
+	decodeBody(t, approveResp, &approval)
+	if approval.ApprovedBy != manager.ID || approval.ApprovalToken == "" {
+		t.Fatalf("approval = %+v, want a token approved by the manager", approval)
+	}
+
+	// The approval is bound to its resource and spent once
+	wantError(refund("payment-2", approval.ApprovalToken), http.StatusForbidden, "approval_invalid")
+	refundResp := refund("payment-1", approval.ApprovalToken)
+	refundResp.Body.Close()
+	if refundResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("refund status = %d, want %d", refundResp.StatusCode, http.StatusNoContent)
+	}
+	if got := refundResp.Header.Get("X-Approved-By"); got != manager.ID.String() {
+		t.Errorf("approved by = %q, want the manager", got)
+	}
+	wantError(refund("payment-1", approval.ApprovalToken), http.StatusForbidden, "approval_used")
+
+	// On a terminal the manager can approve with their PIN instead
+	regResp := env.do(http.MethodPost, "/terminals", managerToken, handler.RegisterTerminalRequest{Name: "Front counter"})
+	var terminal handler.RegisterTerminalResponse
+	decodeBody(t, regResp, &terminal)
+	pinResp := env.do(http.MethodPut, "/pin", managerToken, handler.SetPINRequest{CurrentPassword: "ManagerPass123!", PIN: "3691"})
+	if pinResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("manager set PIN status = %d, want %d", pinResp.StatusCode, http.StatusNoContent)
+	}
+	pinResp.Body.Close()
+
+	pinApproval := handler.ApprovalRequest{Action: e2eRefund, ResourceID: "payment-2", ApproverID: &manager.ID, PIN: "3691"}
+	wantError(env.do(http.MethodPost, "/approvals", cashierToken, pinApproval), http.StatusForbidden, "terminal_invalid")
+	approveResp = env.doWithHeader(http.MethodPost, "/approvals", cashierToken, handler.TerminalTokenHeader, terminal.TerminalToken, pinApproval)
+	if approveResp.StatusCode != http.StatusCreated {
+		t.Fatalf("PIN approve status = %d, want %d", approveResp.StatusCode, http.StatusCreated)
+	}
+	decodeBody(t, approveResp, &approval)
+	refundResp = refund("payment-2", approval.ApprovalToken)
+	refundResp.Body.Close()
+	if refundResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("PIN-approved refund status = %d, want %d", refundResp.StatusCode, http.StatusNoContent)
+	}
+
+	// Both the approver and the requester are on the audit trail
+	var granted, used int
+	for _, e := range env.eventRepo.GetEvents() {
+		switch {
+		case e.EventType == domain.EventApprovalGranted && *e.UserID == manager.ID && e.Metadata["requested_by"] == cashier.ID.String():
+			granted++
+		case e.EventType == domain.EventApprovalUsed && *e.UserID == cashier.ID && e.Metadata["approved_by"] == manager.ID.String():
+			used++
+		}
+	}
+	if granted != 2 || used != 2 {
+		t.Errorf("approval_granted, approval_used events = %d, %d, want 2, 2", granted, used)
+	}
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+)
+
+// TestE2E_EmailVerification_GatesPasswordReset covers the reset gate over
+// real HTTP: an unverified address is never sent a reset link (while the
+// response stays the same as for any other address), and verifying it via
+// the emailed link lets the reset through.
+func TestE2E_EmailVerification_GatesPasswordReset(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("staff@example.com", "Password123!", tenant.ID, domain.RoleWaiter)
+
+	reqResp := env.do(http.MethodPost, "/password-reset/request", "", handler.PasswordResetRequest{Email: "staff@example.com"})
+	if reqResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("reset request status = %d, want %d", reqResp.StatusCode, http.StatusAccepted)
+	}
+	reqResp.Body.Close()
+	if env.emailer.hasResetToken("staff@example.com") {
+		t.Fatal("a reset link was sent to an unverified address")
+	}
+
+	access, _, loginResp := env.login("staff@example.com", "Password123!")
+	loginResp.Body.Close()
+	verifyReq := env.do(http.MethodPost, "/email/verify/request", access, nil)
+	if verifyReq.StatusCode != http.StatusAccepted {
+		t.Fatalf("verification request status = %d, want %d", verifyReq.StatusCode, http.StatusAccepted)
+	}
+	verifyReq.Body.Close()
+
+	verifyResp := env.do(http.MethodPost, "/email/verify", "", handler.EmailTokenRequest{
+		Token: env.emailer.emailTokenFor(t, "staff@example.com"),
+	})
+	if verifyResp.StatusCode != http.StatusOK {
+		t.Fatalf("verify status = %d, want %d", verifyResp.StatusCode, http.StatusOK)
+	}
+	verifyResp.Body.Close()
+
+	reqResp = env.do(http.MethodPost, "/password-reset/request", "", handler.PasswordResetRequest{Email: "staff@example.com"})
+	reqResp.Body.Close()
+	env.emailer.resetTokenFor(t, "staff@example.com")
+}
+
+// TestE2E_EmailChangeFlow covers an email change end to end: request ->
+// confirm via the link sent to the new address -> every session is revoked
+// and the old address gets an undo link -> undoing moves the account back.
+func TestE2E_EmailChangeFlow(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	staff := env.seedUser("staff@example.com", "Password123!", tenant.ID, domain.RoleWaiter)
+
+	access, refresh, loginResp := env.login("staff@example.com", "Password123!")
+	loginResp.Body.Close()
+
+	changeResp := env.do(http.MethodPost, "/email/change", access, handler.EmailChangeRequest{
+		NewEmail: "staff.new@example.com", CurrentPassword: "Password123!",
+	})
+	if changeResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("change request status = %d, want %d", changeResp.StatusCode, http.StatusAccepted)
+	}
+	changeResp.Body.Close()
+
+	confirmResp := env.do(http.MethodPost, "/email/change/confirm", "", handler.EmailTokenRequest{
+		Token: env.emailer.emailTokenFor(t, "staff.new@example.com"),
+	})
+	if confirmResp.StatusCode != http.StatusOK {
+		t.Fatalf("confirm status = %d, want %d", confirmResp.StatusCode, http.StatusOK)
+	}
+	confirmResp.Body.Close()
+
+	refreshResp := env.do(http.MethodPost, "/refresh", "", map[string]string{"refresh_token": refresh})
+	if refreshResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("refresh after email change status = %d, want %d", refreshResp.StatusCode, http.StatusUnauthorized)
+	}
+	refreshResp.Body.Close()
+
+	_, _, newLogin := env.login("staff.new@example.com", "Password123!")
+	if newLogin.StatusCode != http.StatusOK {
+		t.Errorf("login with new email status = %d, want %d", newLogin.StatusCode, http.StatusOK)
+	}
+	newLogin.Body.Close()
+
+	revertResp := env.do(http.MethodPost, "/email/change/revert", "", handler.EmailTokenRequest{
+		Token: env.emailer.emailTokenFor(t, "staff@example.com"),
+	})
+	if revertResp.StatusCode != http.StatusOK {
+		t.Fatalf("revert status = %d, want %d", revertResp.StatusCode, http.StatusOK)
+	}
+	revertResp.Body.Close()
+
+	if staff.Email != "staff@example.com" || !staff.MustResetPwd {
+		t.Errorf("after undoing, email = %q (must reset %v), want the old address and a forced reset", staff.Email, staff.MustResetPwd)
+	}
+}
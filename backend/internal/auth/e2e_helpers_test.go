@@ -12,13 +12,17 @@ import (
 	"net/http/httptest"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+	"github.com/solobueno/erp/internal/auth/repository"
 	"github.com/solobueno/erp/internal/auth/repository/mock"
 	"github.com/solobueno/erp/internal/auth/service"
 	"github.com/solobueno/erp/pkg/jwt"
+	"github.com/solobueno/erp/pkg/webauthn"
 )
 
 // e2eEnv bundles a real HTTP server (real chi router, real services) backed
@@ -31,13 +35,34 @@ type e2eEnv struct {
 	userRepo    *mock.MockUserRepository
 	tenantRepo  *mock.MockTenantRepository
 	roleRepo    *mock.MockUserTenantRoleRepository
+	customRoles *mock.MockCustomRoleRepository
 	sessionRepo *mock.MockSessionRepository
 	resetRepo   *mock.MockPasswordResetRepository
+	mfaRepo     *mock.MockMFARepository
+	eventRepo   *mock.MockAuthEventRepository
+	revocations *repository.MemoryTokenRevocationStore
 	emailer     *capturingEmailer
+	userService *service.UserService
+	ssoConfigs  *mock.MockSSOConfigRepository
+	identities  *mock.MockUserIdentityRepository
+	ssoClient   *http.Client // Pointed at a stub IdP by startIdP
+	apiKeys     *mock.MockAPIKeyRepository
 }
 
 func setupE2E(t *testing.T) *e2eEnv {
 	t.Helper()
+	return newE2EEnv(t, false)
+}
+
+// setupMFAE2E is setupE2E with the TOTP second step enabled on login, so
+// manager+ logins are challenged the way they are in production.
+func setupMFAE2E(t *testing.T) *e2eEnv {
+	t.Helper()
+	return newE2EEnv(t, true)
+}
+
+func newE2EEnv(t *testing.T, withMFA bool) *e2eEnv {
+	t.Helper()
 
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
@@ -63,11 +88,20 @@ func setupE2E(t *testing.T) *e2eEnv {
 	sessionRepo := mock.NewMockSessionRepository()
 	tenantRepo := mock.NewMockTenantRepository()
 	roleRepo := mock.NewMockUserTenantRoleRepository()
+	customRoles := mock.NewMockCustomRoleRepository()
 	resetRepo := mock.NewMockPasswordResetRepository()
+	mfaRepo := mock.NewMockMFARepository()
+	eventRepo := mock.NewMockAuthEventRepository()
+	revocations := repository.NewMemoryTokenRevocationStore(time.Minute)
 	emailer := newCapturingEmailer()
+	ssoConfigs := mock.NewMockSSOConfigRepository()
+	identities := mock.NewMockUserIdentityRepository()
+	ssoClient := &http.Client{}
+	apiKeys := mock.NewMockAPIKeyRepository()
+	passkeys := mock.NewMockPasskeyRepository()
 
 	// Cross-reference the two mock stores the way a real Postgres FK join
-	// would: after UserService.Create/UpdateRole writes to roleRepo, reads
+	// would: after UserService.AcceptInvitation/UpdateRole writes to roleRepo, reads
 	// through userRepo's *WithTenants methods (and ListByTenant) see it.
 	userRepo.RolesLookup = func(userID uuid.UUID) []domain.UserTenantRole {
 		ptrs, _ := roleRepo.ListByUser(context.Background(), userID)
@@ -78,33 +112,168 @@ func setupE2E(t *testing.T) *e2eEnv {
 		return roles
 	}
 
-	authSvc := service.NewAuthService(service.AuthServiceConfig{
+	mfaSvc := service.NewMFAService(service.MFAServiceConfig{
+		MFARepo:       mfaRepo,
+		ChallengeRepo: mock.NewMockMFAChallengeRepository(),
+		EventRepo:     eventRepo,
+		Passkeys:      passkeys,
+	})
+	serviceAccountSvc := service.NewServiceAccountService(service.ServiceAccountServiceConfig{
+		Accounts:  mock.NewMockServiceAccountRepository(),
+		APIKeys:   apiKeys,
+		EventRepo: eventRepo,
+	})
+	oauthSvc := service.NewOAuthService(service.OAuthServiceConfig{
+		Clients:         mock.NewMockOAuthClientRepository(),
+		Consents:        mock.NewMockOAuthConsentRepository(),
+		Codes:           mock.NewMockOAuthCodeRepository(),
+		RefreshTokens:   mock.NewMockOAuthRefreshTokenRepository(),
+		UserRepo:        userRepo,
+		EventRepo:       eventRepo,
+		TokenService:    tokenSvc,
+		RevocationStore: revocations,
+	})
+	pwdPolicySvc := service.NewPasswordPolicyService(service.PasswordPolicyServiceConfig{
+		Policies:  mock.NewMockPasswordPolicyRepository(),
+		History:   mock.NewMockPasswordHistoryRepository(),
+		RoleRepo:  roleRepo,
+		EventRepo: eventRepo,
+	})
+	lockoutSvc := service.NewLockoutService(service.LockoutServiceConfig{
+		Policies:  mock.NewMockLockoutPolicyRepository(),
+		Devices:   mock.NewMockKnownDeviceRepository(),
+		RoleRepo:  roleRepo,
+		EventRepo: eventRepo,
+	})
+	authCfg := service.AuthServiceConfig{
+		UserRepo:         userRepo,
+		SessionRepo:      sessionRepo,
+		EventRepo:        eventRepo,
+		TenantRepo:       tenantRepo,
+		RoleRepo:         roleRepo,
+		TokenService:     tokenSvc,
+		RevocationStore:  revocations,
+		Emailer:          emailer,
+		SSOConfigs:       ssoConfigs,
+		ServiceAccounts:  serviceAccountSvc,
+		OAuth:            oauthSvc,
+		PasswordPolicies: pwdPolicySvc,
+		Lockout:          lockoutSvc,
+	}
+	if withMFA {
+		authCfg.MFAService = mfaSvc
+	}
+	authSvc := service.NewAuthService(authCfg)
+	userSvc := service.NewUserService(service.UserServiceConfig{
+		UserRepo:         userRepo,
+		RoleRepo:         roleRepo,
+		SessionRepo:      sessionRepo,
+		EventRepo:        mock.NewMockAuthEventRepository(),
+		PasswordReset:    resetRepo,
+		Invitations:      mock.NewMockInvitationRepository(),
+		Transfers:        mock.NewMockOwnershipTransferRepository(),
+		RevocationStore:  revocations,
+		Emailer:          emailer,
+		CustomRoles:      customRoles,
+		EmailTokens:      mock.NewMockEmailTokenRepository(),
+		PasswordPolicies: pwdPolicySvc,
+	})
+	roleSvc := service.NewRoleService(service.RoleServiceConfig{
+		CustomRoles:     customRoles,
+		RoleRepo:        roleRepo,
+		EventRepo:       eventRepo,
+		RevocationStore: revocations,
+	})
+
+	pinSvc := service.NewPINService(service.PINServiceConfig{
+		PINRepo:      mock.NewMockStaffPINRepository(),
+		TerminalRepo: mock.NewMockTerminalRepository(),
 		UserRepo:     userRepo,
-		SessionRepo:  sessionRepo,
-		EventRepo:    mock.NewMockAuthEventRepository(),
 		TenantRepo:   tenantRepo,
-		RoleRepo:     roleRepo,
+		EventRepo:    eventRepo,
 		TokenService: tokenSvc,
 	})
-	userSvc := service.NewUserService(service.UserServiceConfig{
-		UserRepo:      userRepo,
-		RoleRepo:      roleRepo,
-		SessionRepo:   sessionRepo,
-		EventRepo:     mock.NewMockAuthEventRepository(),
-		PasswordReset: resetRepo,
-		Emailer:       emailer,
+
+	approvalSvc := service.NewApprovalService(service.ApprovalServiceConfig{
+		Approvals:   mock.NewMockApprovalRepository(),
+		UserRepo:    userRepo,
+		EventRepo:   eventRepo,
+		PINService:  pinSvc,
+		Lockout:     lockoutSvc,
+		AuthService: authSvc,
+	})
+
+	ssoSvc := service.NewSSOService(service.SSOServiceConfig{
+		Configs:     ssoConfigs,
+		States:      mock.NewMockSSOLoginStateRepository(),
+		Identities:  identities,
+		TenantRepo:  tenantRepo,
+		UserRepo:    userRepo,
+		EventRepo:   eventRepo,
+		AuthService: authSvc,
+		RedirectURL: e2eSSORedirectURL,
+		HTTPClient:  ssoClient,
+	})
+
+	scimSvc := service.NewSCIMService(service.SCIMServiceConfig{
+		Tokens:      mock.NewMockSCIMTokenRepository(),
+		Links:       mock.NewMockSCIMUserLinkRepository(),
+		UserRepo:    userRepo,
+		RoleRepo:    roleRepo,
+		EventRepo:   eventRepo,
+		UserService: userSvc,
+		CustomRoles: customRoles,
+	})
+
+	passkeySvc := service.NewPasskeyService(service.PasskeyServiceConfig{
+		Passkeys:    passkeys,
+		Challenges:  mock.NewMockPasskeyChallengeRepository(),
+		UserRepo:    userRepo,
+		TenantRepo:  tenantRepo,
+		EventRepo:   eventRepo,
+		AuthService: authSvc,
+		MFAService:  mfaSvc,
+		WebAuthn: webauthn.Config{
+			RPID:    e2ePasskeyRPID,
+			RPName:  "Solobueno ERP",
+			Origins: []string{e2ePasskeyOrigin},
+		},
 	})
 
 	mux := chi.NewRouter()
-	mux.Mount("/", Router(authSvc, userSvc))
+	mux.Mount("/", Router(authSvc, userSvc, mfaSvc, pinSvc, approvalSvc, ssoSvc, scimSvc, passkeySvc, pwdPolicySvc, lockoutSvc))
 	mux.Mount("/users", UserRouter(authSvc, userSvc))
+	mux.Mount("/roles", RoleRouter(authSvc, roleSvc))
+	mux.Mount("/scim/v2", SCIMRouter(scimSvc))
+	mux.Mount("/service-accounts", ServiceAccountRouter(authSvc, serviceAccountSvc))
+	mux.Mount("/oauth", OAuthRouter(authSvc, oauthSvc))
+
+	// Stands in for a module route that needs a manager's approval
+	mux.With(handler.NewAuthMiddleware(authSvc).RequireAuth, handler.RequireApproval(approvalSvc, e2eRefund, "id")).
+		Post("/payments/{id}/refund", func(w http.ResponseWriter, r *http.Request) {
+			approval, _ := handler.GetApproval(r.Context())
+			w.Header().Set("X-Approved-By", approval.ApprovedBy.String())
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+	// Stands in for a module route guarded by a permission, which a service
+	// account or OAuth client reaches with the matching scope
+	mux.With(handler.NewAuthMiddleware(authSvc).RequireAuth, handler.NewAuthMiddleware(authSvc).RequirePermission(e2eReportsRead)).
+		Get("/reports/sales", func(w http.ResponseWriter, r *http.Request) {
+			userID, _ := handler.GetUserID(r.Context())
+			w.Header().Set("X-User-ID", userID.String())
+			w.WriteHeader(http.StatusNoContent)
+		})
 	srv := httptest.NewServer(mux)
 	t.Cleanup(srv.Close)
 
 	return &e2eEnv{
 		t: t, server: srv, client: srv.Client(),
-		userRepo: userRepo, tenantRepo: tenantRepo, roleRepo: roleRepo,
-		sessionRepo: sessionRepo, resetRepo: resetRepo, emailer: emailer,
+		userRepo: userRepo, tenantRepo: tenantRepo, roleRepo: roleRepo, customRoles: customRoles,
+		sessionRepo: sessionRepo, resetRepo: resetRepo, mfaRepo: mfaRepo,
+		eventRepo: eventRepo, revocations: revocations, emailer: emailer,
+		userService: userSvc, ssoConfigs: ssoConfigs, identities: identities, ssoClient: ssoClient,
+		apiKeys: apiKeys,
 	}
 }
 
@@ -175,6 +344,14 @@ func (e *e2eEnv) do(method, path, token string, body interface{}) *http.Response
 	return resp
 }
 
+// waitForNextSecond sleeps until the next whole second. iat has one-second
+// precision, so a user revocation also covers tokens issued in the second it
+// was made; a test that signs in again afterwards waits this out first.
+func waitForNextSecond() {
+	now := time.Now()
+	time.Sleep(now.Truncate(time.Second).Add(time.Second).Sub(now))
+}
+
 // decodeBody JSON-decodes and closes the response body.
 func decodeBody(t *testing.T, resp *http.Response, v interface{}) {
 	t.Helper()
@@ -185,30 +362,51 @@ func decodeBody(t *testing.T, resp *http.Response, v interface{}) {
 }
 
 // capturingEmailer is a test double for service.Emailer that records what
-// would have been sent, so e2e tests can retrieve tokens/passwords that
-// only ever leave the system via email (never in an API response).
+// would have been sent, so e2e tests can retrieve tokens that only ever
+// leave the system via email (never in an API response).
 type capturingEmailer struct {
-	mu            sync.Mutex
-	tempPasswords map[string]string
-	resetTokens   map[string]string
-	tenantLinks   []string
+	mu          sync.Mutex
+	invites     map[string]capturedInvite
+	resetTokens map[string]string
+	reuseAlerts []string
+	// transferTokens holds the latest ownership transfer token per recipient;
+	// transferNotices records who was told a transfer completed.
+	transferTokens  map[string]string
+	transferNotices []string
+	// emailTokens holds the latest verification, change, revert or unlock
+	// token per recipient.
+	emailTokens map[string]string
+}
+
+// capturedInvite is the latest invitation emailed to an address.
+type capturedInvite struct {
+	tenantID        uuid.UUID
+	token           string
+	existingAccount bool
 }
 
 func newCapturingEmailer() *capturingEmailer {
-	return &capturingEmailer{tempPasswords: map[string]string{}, resetTokens: map[string]string{}}
+	return &capturingEmailer{invites: map[string]capturedInvite{}, resetTokens: map[string]string{}, transferTokens: map[string]string{}, emailTokens: map[string]string{}}
+}
+
+func (e *capturingEmailer) SendInvitation(ctx context.Context, toEmail string, tenantID uuid.UUID, inviteToken string, existingAccount bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.invites[toEmail] = capturedInvite{tenantID: tenantID, token: inviteToken, existingAccount: existingAccount}
+	return nil
 }
 
-func (e *capturingEmailer) SendTemporaryPassword(ctx context.Context, toEmail, tempPassword string) error {
+func (e *capturingEmailer) SendOwnershipTransferRequest(ctx context.Context, toEmail string, tenantID uuid.UUID, transferToken string) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	e.tempPasswords[toEmail] = tempPassword
+	e.transferTokens[toEmail] = transferToken
 	return nil
 }
 
-func (e *capturingEmailer) SendTenantLinked(ctx context.Context, toEmail string, tenantID uuid.UUID) error {
+func (e *capturingEmailer) SendOwnershipTransferred(ctx context.Context, toEmail string, tenantID uuid.UUID, newOwner bool) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	e.tenantLinks = append(e.tenantLinks, toEmail)
+	e.transferNotices = append(e.transferNotices, toEmail)
 	return nil
 }
 
@@ -219,15 +417,50 @@ func (e *capturingEmailer) SendPasswordReset(ctx context.Context, toEmail, reset
 	return nil
 }
 
-func (e *capturingEmailer) tempPasswordFor(t *testing.T, email string) string {
+func (e *capturingEmailer) SendEmailVerification(ctx context.Context, toEmail, verifyToken string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.emailTokens[toEmail] = verifyToken
+	return nil
+}
+
+func (e *capturingEmailer) SendEmailChangeConfirmation(ctx context.Context, toEmail, changeToken string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.emailTokens[toEmail] = changeToken
+	return nil
+}
+
+func (e *capturingEmailer) SendEmailChanged(ctx context.Context, toEmail, newEmail, revertToken string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.emailTokens[toEmail] = revertToken
+	return nil
+}
+
+func (e *capturingEmailer) SendRefreshTokenReuse(ctx context.Context, toEmail string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.reuseAlerts = append(e.reuseAlerts, toEmail)
+	return nil
+}
+
+func (e *capturingEmailer) SendAccountUnlock(ctx context.Context, toEmail, unlockToken string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.emailTokens[toEmail] = unlockToken
+	return nil
+}
+
+func (e *capturingEmailer) inviteFor(t *testing.T, email string) capturedInvite {
 	t.Helper()
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	p, ok := e.tempPasswords[email]
+	inv, ok := e.invites[email]
 	if !ok {
-		t.Fatalf("no temporary password captured for %s", email)
+		t.Fatalf("no invitation captured for %s", email)
 	}
-	return p
+	return inv
 }
 
 func (e *capturingEmailer) resetTokenFor(t *testing.T, email string) string {
@@ -241,10 +474,50 @@ func (e *capturingEmailer) resetTokenFor(t *testing.T, email string) string {
 	return tok
 }
 
-func (e *capturingEmailer) hasTenantLinkNotification(email string) bool {
+func (e *capturingEmailer) hasResetToken(email string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.resetTokens[email]
+	return ok
+}
+
+func (e *capturingEmailer) emailTokenFor(t *testing.T, email string) string {
+	t.Helper()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	tok, ok := e.emailTokens[email]
+	if !ok {
+		t.Fatalf("no email token captured for %s", email)
+	}
+	return tok
+}
+
+func (e *capturingEmailer) transferTokenFor(t *testing.T, email string) string {
+	t.Helper()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	tok, ok := e.transferTokens[email]
+	if !ok {
+		t.Fatalf("no ownership transfer token captured for %s", email)
+	}
+	return tok
+}
+
+func (e *capturingEmailer) hasTransferNotice(email string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, addr := range e.transferNotices {
+		if addr == email {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *capturingEmailer) hasReuseAlert(email string) bool {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	for _, addr := range e.tenantLinks {
+	for _, addr := range e.reuseAlerts {
 		if addr == email {
 			return true
 		}
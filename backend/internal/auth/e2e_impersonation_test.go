@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+)
+
+// TestE2E_Impersonation covers an admin impersonating a manager: the token
+// acts as the manager but can't change their credentials, /me shows who is
+// really acting, the audit trail names the admin on everything done under
+// it, and logging out ends it.
+func TestE2E_Impersonation(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	admin := env.seedUser("admin@example.com", "AdminPass123!", tenant.ID, domain.RoleAdmin)
+	manager := env.seedUser("manager@example.com", "ManagerPass123!", tenant.ID, domain.RoleManager)
+	otherAdmin := env.seedUser("admin2@example.com", "AdminPass123!", tenant.ID, domain.RoleAdmin)
+
+	adminToken, _, resp := env.login("admin@example.com", "AdminPass123!")
+	resp.Body.Close()
+	managerToken, _, resp := env.login("manager@example.com", "ManagerPass123!")
+	resp.Body.Close()
+
+	wantError := func(resp *http.Response, status int, code string) {
+		t.Helper()
+		if resp.StatusCode != status {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, status)
+		}
+		var body handler.ErrorResponse
+		decodeBody(t, resp, &body)
+		if body.Error.Code != code {
+			t.Errorf("error code = %q, want %q", body.Error.Code, code)
+		}
+	}
+	reason := handler.ImpersonateRequest{Reason: "Ticket 4521: can't see the terminal list"}
+
+	// Managers can't impersonate, and admins can't impersonate their peers
+	wantError(env.do(http.MethodPost, "/users/"+admin.ID.String()+"/impersonate", managerToken, reason), http.StatusForbidden, "insufficient_permission")
+	wantError(env.do(http.MethodPost, "/users/"+otherAdmin.ID.String()+"/impersonate", adminToken, reason), http.StatusForbidden, "insufficient_role")
+
+	impResp := env.do(http.MethodPost, "/users/"+manager.ID.String()+"/impersonate", adminToken, reason)
+	if impResp.StatusCode != http.StatusOK {
+		t.Fatalf("impersonate status = %d, want %d", impResp.StatusCode, http.StatusOK)
+	}
+	var imp handler.ImpersonationResponse
+	decodeBody(t, impResp, &imp)
+	if imp.User.ID != manager.ID || imp.ImpersonatedBy != admin.ID {
+		t.Fatalf("impersonation = %+v, want the manager impersonated by the admin", imp)
+	}
+
+	// /me is the manager's, with the admin shown as the one acting
+	meResp := env.do(http.MethodGet, "/me", imp.AccessToken, nil)
+	var me handler.MeResponse
+	decodeBody(t, meResp, &me)
+	if me.ID != manager.ID || me.ImpersonatedBy == nil || *me.ImpersonatedBy != admin.ID {
+		t.Errorf("/me = %+v, want the manager with impersonated_by the admin", me)
+	}
+
+	// The manager's credentials are off limits, and impersonations don't nest
+	wantError(env.do(http.MethodPost, "/change-password", imp.AccessToken, handler.ChangePasswordRequest{
+		CurrentPassword: "ManagerPass123!", NewPassword: "Hijacked123!",
+	}), http.StatusForbidden, "impersonation_forbidden")
+	wantError(env.do(http.MethodPut, "/pin", imp.AccessToken, handler.SetPINRequest{CurrentPassword: "ManagerPass123!", PIN: "3691"}), http.StatusForbidden, "impersonation_forbidden")
+
+	// Actions taken under impersonation are the manager's, attributed to the admin
+	regResp := env.do(http.MethodPost, "/terminals", imp.AccessToken, handler.RegisterTerminalRequest{Name: "Front counter"})
+	regResp.Body.Close()
+	if regResp.StatusCode != http.StatusCreated {
+		t.Fatalf("register terminal status = %d, want %d", regResp.StatusCode, http.StatusCreated)
+	}
+	var started, registered *domain.AuthEvent
+	for _, e := range env.eventRepo.GetEvents() {
+		switch e.EventType {
+		case domain.EventImpersonationStarted:
+			started = e
+		case domain.EventTerminalRegistered:
+			registered = e
+		}
+	}
+	for name, e := range map[string]*domain.AuthEvent{"impersonation_started": started, "terminal_registered": registered} {
+		if e == nil || e.UserID == nil || *e.UserID != manager.ID || e.ActorID == nil || *e.ActorID != admin.ID {
+			t.Errorf("%s event = %+v, want the manager with the admin as actor", name, e)
+		}
+	}
+
+	// Logging out ends the impersonation
+	logoutResp := env.do(http.MethodPost, "/logout", imp.AccessToken, nil)
+	logoutResp.Body.Close()
+	wantError(env.do(http.MethodGet, "/me", imp.AccessToken, nil), http.StatusUnauthorized, "token_revoked")
+}
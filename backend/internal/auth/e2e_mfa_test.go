@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+)
+
+// authenticatorCode computes the code an authenticator app would show for
+// secret, offset by the given number of 30s steps. Implemented
+// independently of the service so the e2e tests check interoperability.
+func authenticatorCode(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("invalid TOTP secret: %v", err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(time.Now().Unix()/30+offset))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	o := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[o:o+4])&0x7fffffff)%1000000)
+}
+
+// mfaLogin posts credentials and returns the error code and mfa_token from
+// the 401 challenge response.
+func (e *e2eEnv) mfaLogin(email, password string) (code, mfaToken string) {
+	e.t.Helper()
+	resp := e.do(http.MethodPost, "/login", "", map[string]string{"email": email, "password": password})
+	if resp.StatusCode != http.StatusUnauthorized {
+		e.t.Fatalf("login status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+	var out handler.ErrorResponse
+	decodeBody(e.t, resp, &out)
+	return out.Error.Code, out.Error.MFAToken
+}
+
+// TestE2E_MFA_ManagerEnrollsDuringLogin walks a manager with no factor
+// through the forced enrollment flow, then logs in again with a TOTP code
+// and finally with a recovery code.
+func TestE2E_MFA_ManagerEnrollsDuringLogin(t *testing.T) {
+	env := setupMFAE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("manager@example.com", "Password123!", tenant.ID, domain.RoleManager)
+
+	code, mfaToken := env.mfaLogin("manager@example.com", "Password123!")
+	if code != "mfa_enrollment_required" || mfaToken == "" {
+		t.Fatalf("login = %q (token %q), want mfa_enrollment_required with a token", code, mfaToken)
+	}
+
+	setupResp := env.do(http.MethodPost, "/mfa/setup", "", handler.MFASetupRequest{MFAToken: mfaToken})
+	if setupResp.StatusCode != http.StatusOK {
+		t.Fatalf("setup status = %d, want %d", setupResp.StatusCode, http.StatusOK)
+	}
+	var enrollment handler.MFAEnrollmentResponse
+	decodeBody(t, setupResp, &enrollment)
+
+	verifyResp := env.do(http.MethodPost, "/mfa/verify", "", handler.MFAVerifyRequest{
+		MFAToken: mfaToken, Code: authenticatorCode(t, enrollment.Secret, 0),
+	})
+	if verifyResp.StatusCode != http.StatusOK {
+		t.Fatalf("verify status = %d, want %d", verifyResp.StatusCode, http.StatusOK)
+	}
+	var first handler.LoginResponse
+	decodeBody(t, verifyResp, &first)
+	if first.AccessToken == "" || len(first.RecoveryCodes) == 0 {
+		t.Fatalf("enrollment verify should return tokens and recovery codes: %+v", first)
+	}
+
+	statusResp := env.do(http.MethodGet, "/mfa", first.AccessToken, nil)
+	var status handler.MFAStatusResponse
+	decodeBody(t, statusResp, &status)
+	if !status.Enabled || !status.Required || status.RecoveryCodesRemaining != int64(len(first.RecoveryCodes)) {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	// Second login: now enrolled, so a plain challenge
+	code, mfaToken = env.mfaLogin("manager@example.com", "Password123!")
+	if code != "mfa_required" {
+		t.Fatalf("second login code = %q, want mfa_required", code)
+	}
+	verifyResp = env.do(http.MethodPost, "/mfa/verify", "", handler.MFAVerifyRequest{
+		MFAToken: mfaToken, Code: authenticatorCode(t, enrollment.Secret, 1),
+	})
+	if verifyResp.StatusCode != http.StatusOK {
+		t.Fatalf("TOTP verify status = %d, want %d", verifyResp.StatusCode, http.StatusOK)
+	}
+	verifyResp.Body.Close()
+
+	// Third login with a recovery code
+	_, mfaToken = env.mfaLogin("manager@example.com", "Password123!")
+	verifyResp = env.do(http.MethodPost, "/mfa/verify", "", handler.MFAVerifyRequest{
+		MFAToken: mfaToken, Code: first.RecoveryCodes[0],
+	})
+	if verifyResp.StatusCode != http.StatusOK {
+		t.Fatalf("recovery code verify status = %d, want %d", verifyResp.StatusCode, http.StatusOK)
+	}
+	verifyResp.Body.Close()
+
+	// The recovery code is spent
+	_, mfaToken = env.mfaLogin("manager@example.com", "Password123!")
+	verifyResp = env.do(http.MethodPost, "/mfa/verify", "", handler.MFAVerifyRequest{
+		MFAToken: mfaToken, Code: first.RecoveryCodes[0],
+	})
+	if verifyResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("reused recovery code status = %d, want %d", verifyResp.StatusCode, http.StatusUnauthorized)
+	}
+	verifyResp.Body.Close()
+}
+
+// TestE2E_MFA_ChallengeLocksAfterMaxAttempts verifies a challenge stops
+// accepting codes after repeated wrong guesses, forcing a fresh password login.
+func TestE2E_MFA_ChallengeLocksAfterMaxAttempts(t *testing.T) {
+	env := setupMFAE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("owner@example.com", "Password123!", tenant.ID, domain.RoleOwner)
+
+	_, mfaToken := env.mfaLogin("owner@example.com", "Password123!")
+	setupResp := env.do(http.MethodPost, "/mfa/setup", "", handler.MFASetupRequest{MFAToken: mfaToken})
+	var enrollment handler.MFAEnrollmentResponse
+	decodeBody(t, setupResp, &enrollment)
+
+	for i := 0; i < 5; i++ {
+		resp := env.do(http.MethodPost, "/mfa/verify", "", handler.MFAVerifyRequest{MFAToken: mfaToken, Code: "000000"})
+		resp.Body.Close()
+	}
+
+	resp := env.do(http.MethodPost, "/mfa/verify", "", handler.MFAVerifyRequest{
+		MFAToken: mfaToken, Code: authenticatorCode(t, enrollment.Secret, 0),
+	})
+	var out handler.ErrorResponse
+	decodeBody(t, resp, &out)
+	if resp.StatusCode != http.StatusUnauthorized || out.Error.Code != "mfa_challenge_invalid" {
+		t.Errorf("verify after max attempts = %d %q, want 401 mfa_challenge_invalid", resp.StatusCode, out.Error.Code)
+	}
+}
+
+// TestE2E_MFA_StaffOptInAndDisable covers a role that isn't required to use
+// MFA opting in from an authenticated session, then turning it off again.
+func TestE2E_MFA_StaffOptInAndDisable(t *testing.T) {
+	env := setupMFAE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("waiter@example.com", "Password123!", tenant.ID, domain.RoleWaiter)
+
+	accessToken, _, loginResp := env.login("waiter@example.com", "Password123!")
+	if loginResp.StatusCode != http.StatusOK {
+		t.Fatalf("login status = %d, want %d", loginResp.StatusCode, http.StatusOK)
+	}
+	loginResp.Body.Close()
+
+	enrollResp := env.do(http.MethodPost, "/mfa/enroll", accessToken, nil)
+	if enrollResp.StatusCode != http.StatusOK {
+		t.Fatalf("enroll status = %d, want %d", enrollResp.StatusCode, http.StatusOK)
+	}
+	var enrollment handler.MFAEnrollmentResponse
+	decodeBody(t, enrollResp, &enrollment)
+
+	confirmResp := env.do(http.MethodPost, "/mfa/enroll/confirm", accessToken, handler.MFACodeRequest{
+		Code: authenticatorCode(t, enrollment.Secret, 0),
+	})
+	if confirmResp.StatusCode != http.StatusOK {
+		t.Fatalf("confirm status = %d, want %d", confirmResp.StatusCode, http.StatusOK)
+	}
+	confirmResp.Body.Close()
+
+	// Opted in, so the next login is challenged
+	if code, _ := env.mfaLogin("waiter@example.com", "Password123!"); code != "mfa_required" {
+		t.Fatalf("login after opt-in code = %q, want mfa_required", code)
+	}
+
+	disableResp := env.do(http.MethodPost, "/mfa/disable", accessToken, handler.MFACodeRequest{
+		Code: authenticatorCode(t, enrollment.Secret, 1),
+	})
+	if disableResp.StatusCode != http.StatusOK {
+		t.Fatalf("disable status = %d, want %d", disableResp.StatusCode, http.StatusOK)
+	}
+	disableResp.Body.Close()
+
+	_, _, loginResp = env.login("waiter@example.com", "Password123!")
+	if loginResp.StatusCode != http.StatusOK {
+		t.Errorf("login after disable status = %d, want %d", loginResp.StatusCode, http.StatusOK)
+	}
+	loginResp.Body.Close()
+}
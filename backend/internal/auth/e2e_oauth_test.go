@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+	"github.com/solobueno/erp/pkg/oauth"
+)
+
+// TestE2E_OAuth covers a reservation platform integrating with a
+// restaurant: the platform registers its app, the restaurant's owner
+// authorizes it with PKCE, and the app's tokens open exactly the routes its
+// scopes allow until the owner revokes its access. Replaying a rotated
+// refresh token ends the grant.
+func TestE2E_OAuth(t *testing.T) {
+	env := setupE2E(t)
+	partner := env.seedTenant("Reservas Online", "reservas-online")
+	restaurant := env.seedTenant("Casa Ana", "casa-ana")
+	env.seedUser("dev@reservas.com", "PartnerPass123!", partner.ID, domain.RoleAdmin)
+	owner := env.seedUser("ana@casa-ana.com", "OwnerPass123!", restaurant.ID, domain.RoleOwner)
+	env.seedUser("luis@casa-ana.com", "ManagerPass123!", restaurant.ID, domain.RoleManager)
+
+	partnerToken, _, resp := env.login("dev@reservas.com", "PartnerPass123!")
+	resp.Body.Close()
+	ownerToken, _, resp := env.login("ana@casa-ana.com", "OwnerPass123!")
+	resp.Body.Close()
+	managerToken, _, resp := env.login("luis@casa-ana.com", "ManagerPass123!")
+	resp.Body.Close()
+
+	wantStatus := func(resp *http.Response, status int) {
+		t.Helper()
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, status)
+		}
+	}
+	wantError := func(resp *http.Response, status int, code string) {
+		t.Helper()
+		if resp.StatusCode != status {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, status)
+		}
+		var body handler.ErrorResponse
+		decodeBody(t, resp, &body)
+		if body.Error.Code != code {
+			t.Errorf("error code = %q, want %q", body.Error.Code, code)
+		}
+	}
+
+	// The partner registers its app
+	resp = env.do(http.MethodPost, "/oauth/clients", partnerToken, handler.CreateOAuthClientRequest{
+		Name:         "Reservas Online",
+		RedirectURIs: []string{"https://reservas.example.com/cb"},
+		Scopes:       []domain.Permission{e2eReportsRead},
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("register status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	var client handler.CreateOAuthClientResponse
+	decodeBody(t, resp, &client)
+
+	// token posts to the token endpoint as the app, with HTTP Basic
+	token := func(form url.Values) (*oauth.TokenResponse, *handler.OAuthErrorResponse) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, env.server.URL+"/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(client.ID.String(), client.ClientSecret)
+		resp, err := env.client.Do(req)
+		if err != nil {
+			t.Fatalf("POST /oauth/token failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			var oerr handler.OAuthErrorResponse
+			decodeBody(t, resp, &oerr)
+			return nil, &oerr
+		}
+		var tokens oauth.TokenResponse
+		decodeBody(t, resp, &tokens)
+		return &tokens, nil
+	}
+
+	// Only the restaurant's owner can authorize it
+	verifier := "dBjftJeZ4CVP-mJ92K9ZBuTxmfPmOgS1mWXGmAu8VkV"
+	sum := sha256.Sum256([]byte(verifier))
+	authorization := handler.OAuthAuthorizeRequest{
+		ResponseType:        oauth.ResponseTypeCode,
+		ClientID:            client.ID.String(),
+		Scope:               string(e2eReportsRead),
+		State:               "table-for-two",
+		CodeChallenge:       base64.RawURLEncoding.EncodeToString(sum[:]),
+		CodeChallengeMethod: oauth.CodeChallengeMethodS256,
+		Approve:             true,
+	}
+	query := url.Values{
+		"response_type":         {authorization.ResponseType},
+		"client_id":             {authorization.ClientID},
+		"scope":                 {authorization.Scope},
+		"state":                 {authorization.State},
+		"code_challenge":        {authorization.CodeChallenge},
+		"code_challenge_method": {authorization.CodeChallengeMethod},
+	}
+	wantError(env.do(http.MethodGet, "/oauth/authorize?"+query.Encode(), managerToken, nil), http.StatusForbidden, "insufficient_role")
+
+	resp = env.do(http.MethodGet, "/oauth/authorize?"+query.Encode(), ownerToken, nil)
+	var screen handler.OAuthAuthorizationResponse
+	decodeBody(t, resp, &screen)
+	if screen.Client == nil || screen.Client.Name != "Reservas Online" || len(screen.Scopes) != 1 {
+		t.Fatalf("consent screen = %+v", screen)
+	}
+
+	resp = env.do(http.MethodPost, "/oauth/authorize", ownerToken, authorization)
+	var redirect handler.OAuthRedirectResponse
+	decodeBody(t, resp, &redirect)
+	redirectTo, _ := url.Parse(redirect.RedirectTo)
+	code := redirectTo.Query().Get("code")
+	if code == "" || redirectTo.Query().Get("state") != "table-for-two" {
+		t.Fatalf("redirected to %q, want a code and the state", redirect.RedirectTo)
+	}
+
+	// A wrong verifier is refused; the right one gets tokens
+	exchange := url.Values{
+		"grant_type":   {oauth.GrantAuthorizationCode},
+		"code":         {code},
+		"redirect_uri": {"https://reservas.example.com/cb"},
+	}
+	exchange.Set("code_verifier", strings.Repeat("x", 43))
+	if _, oerr := token(exchange); oerr == nil || oerr.Error != oauth.ErrorInvalidGrant {
+		t.Fatalf("exchange with a wrong verifier = %+v, want %s", oerr, oauth.ErrorInvalidGrant)
+	}
+	exchange.Set("code_verifier", verifier)
+	tokens, oerr := token(exchange)
+	if oerr != nil {
+		t.Fatalf("exchange failed: %+v", oerr)
+	}
+
+	// The app acts as the owner, with only the granted scope
+	resp = env.do(http.MethodGet, "/reports/sales", tokens.AccessToken, nil)
+	wantStatus(resp, http.StatusNoContent)
+	if got := resp.Header.Get("X-User-ID"); got != owner.ID.String() {
+		t.Errorf("request made as %s, want the owner %s", got, owner.ID)
+	}
+	for _, path := range []string{"/me", "/sessions", "/users", "/oauth/consents"} {
+		wantError(env.do(http.MethodGet, path, tokens.AccessToken, nil), http.StatusForbidden, "oauth_client_forbidden")
+	}
+
+	// Refresh tokens rotate; replaying a rotated one ends the grant
+	refreshed, oerr := token(url.Values{"grant_type": {oauth.GrantRefreshToken}, "refresh_token": {tokens.RefreshToken}})
+	if oerr != nil || refreshed.RefreshToken == tokens.RefreshToken {
+		t.Fatalf("refresh = %+v %+v, want a new refresh token", refreshed, oerr)
+	}
+	if _, oerr := token(url.Values{"grant_type": {oauth.GrantRefreshToken}, "refresh_token": {tokens.RefreshToken}}); oerr == nil || oerr.Error != oauth.ErrorInvalidGrant {
+		t.Fatalf("replayed refresh token = %+v, want %s", oerr, oauth.ErrorInvalidGrant)
+	}
+	if _, oerr := token(url.Values{"grant_type": {oauth.GrantRefreshToken}, "refresh_token": {refreshed.RefreshToken}}); oerr == nil {
+		t.Fatal("refresh after a replay should fail, the family is revoked")
+	}
+
+	// A fresh authorization; then the owner revokes the app's access
+	resp = env.do(http.MethodPost, "/oauth/authorize", ownerToken, authorization)
+	decodeBody(t, resp, &redirect)
+	redirectTo, _ = url.Parse(redirect.RedirectTo)
+	exchange.Set("code", redirectTo.Query().Get("code"))
+	if tokens, oerr = token(exchange); oerr != nil {
+		t.Fatalf("second exchange failed: %+v", oerr)
+	}
+	wantStatus(env.do(http.MethodGet, "/reports/sales", tokens.AccessToken, nil), http.StatusNoContent)
+
+	resp = env.do(http.MethodGet, "/oauth/consents", ownerToken, nil)
+	var consents handler.OAuthConsentListResponse
+	decodeBody(t, resp, &consents)
+	if len(consents.Data) != 1 {
+		t.Fatalf("listed %d consents, want 1", len(consents.Data))
+	}
+	wantStatus(env.do(http.MethodDelete, "/oauth/consents/"+consents.Data[0].ID.String(), ownerToken, nil), http.StatusNoContent)
+	wantError(env.do(http.MethodGet, "/reports/sales", tokens.AccessToken, nil), http.StatusUnauthorized, "token_revoked")
+	if _, oerr := token(url.Values{"grant_type": {oauth.GrantRefreshToken}, "refresh_token": {tokens.RefreshToken}}); oerr == nil || oerr.Error != oauth.ErrorInvalidGrant {
+		t.Errorf("refresh after revoking consent = %+v, want %s", oerr, oauth.ErrorInvalidGrant)
+	}
+
+	// The app can still act as itself in the partner's own tenant
+	own, oerr := token(url.Values{"grant_type": {oauth.GrantClientCredentials}})
+	if oerr != nil {
+		t.Fatalf("client credentials failed: %+v", oerr)
+	}
+	resp = env.do(http.MethodGet, "/reports/sales", own.AccessToken, nil)
+	wantStatus(resp, http.StatusNoContent)
+	if got := resp.Header.Get("X-User-ID"); got != client.ID.String() {
+		t.Errorf("request made as %s, want the client %s", got, client.ID)
+	}
+
+	// Deleting the client cuts off its own tokens too
+	wantStatus(env.do(http.MethodDelete, "/oauth/clients/"+client.ID.String(), partnerToken, nil), http.StatusNoContent)
+	wantError(env.do(http.MethodGet, "/reports/sales", own.AccessToken, nil), http.StatusUnauthorized, "token_revoked")
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+	"github.com/solobueno/erp/pkg/webauthn/webauthntest"
+)
+
+// The relying party the e2e passkey service is configured as.
+const (
+	e2ePasskeyRPID   = "erp.example.com"
+	e2ePasskeyOrigin = "https://erp.example.com"
+)
+
+// passkeyCeremony starts a ceremony at beginPath, passes its options
+// through the software authenticator step (Register or Login) and returns
+// the credential JSON a browser would post back.
+func (e *e2eEnv) passkeyCeremony(beginPath, token string, body interface{}, step func([]byte) ([]byte, error)) json.RawMessage {
+	e.t.Helper()
+	resp := e.do(http.MethodPost, beginPath, token, body)
+	if resp.StatusCode != http.StatusOK {
+		e.t.Fatalf("%s status = %d, want %d", beginPath, resp.StatusCode, http.StatusOK)
+	}
+	var options struct {
+		PublicKey json.RawMessage `json:"publicKey"`
+	}
+	decodeBody(e.t, resp, &options)
+
+	credential, err := step(options.PublicKey)
+	if err != nil {
+		e.t.Fatalf("authenticator failed: %v", err)
+	}
+	return credential
+}
+
+// passkeyLogin runs a passwordless login with authenticator and returns
+// the finish response.
+func (e *e2eEnv) passkeyLogin(authenticator *webauthntest.Authenticator) *http.Response {
+	e.t.Helper()
+	credential := e.passkeyCeremony("/passkeys/login/begin", "", nil, authenticator.Login)
+	return e.do(http.MethodPost, "/passkeys/login/finish", "", map[string]interface{}{"credential": credential})
+}
+
+// TestE2E_Passkeys covers an owner adding a passkey from a signed-in
+// session, signing in with it instead of a password, using it as the second
+// factor after a password, and managing it alongside their sessions.
+func TestE2E_Passkeys(t *testing.T) {
+	env := setupMFAE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("owner@example.com", "Password123!", tenant.ID, domain.RoleOwner)
+	env.seedUser("waiter@example.com", "Password123!", tenant.ID, domain.RoleWaiter)
+
+	// The owner enrolls TOTP to get their first session
+	_, mfaToken := env.mfaLogin("owner@example.com", "Password123!")
+	setupResp := env.do(http.MethodPost, "/mfa/setup", "", handler.MFASetupRequest{MFAToken: mfaToken})
+	var enrollment handler.MFAEnrollmentResponse
+	decodeBody(t, setupResp, &enrollment)
+	verifyResp := env.do(http.MethodPost, "/mfa/verify", "", handler.MFAVerifyRequest{
+		MFAToken: mfaToken, Code: authenticatorCode(t, enrollment.Secret, 0),
+	})
+	var session handler.LoginResponse
+	decodeBody(t, verifyResp, &session)
+
+	// Register the laptop's passkey
+	laptop := webauthntest.New(e2ePasskeyOrigin)
+	credential := env.passkeyCeremony("/passkeys/register/begin", session.AccessToken, nil, laptop.Register)
+	registerResp := env.do(http.MethodPost, "/passkeys/register/finish", session.AccessToken, map[string]interface{}{
+		"name":       "Back office laptop",
+		"credential": credential,
+	})
+	if registerResp.StatusCode != http.StatusCreated {
+		t.Fatalf("register status = %d, want %d", registerResp.StatusCode, http.StatusCreated)
+	}
+	var passkey handler.PasskeyResponse
+	decodeBody(t, registerResp, &passkey)
+	if passkey.Name != "Back office laptop" {
+		t.Errorf("name = %q, want %q", passkey.Name, "Back office laptop")
+	}
+
+	// Passwordless login, with no MFA step
+	loginResp := env.passkeyLogin(laptop)
+	if loginResp.StatusCode != http.StatusOK {
+		t.Fatalf("passkey login status = %d, want %d", loginResp.StatusCode, http.StatusOK)
+	}
+	var passkeySession handler.LoginResponse
+	decodeBody(t, loginResp, &passkeySession)
+	if passkeySession.AccessToken == "" || passkeySession.User.Role != string(domain.RoleOwner) {
+		t.Errorf("unexpected passkey login response: %+v", passkeySession)
+	}
+
+	// After a password, the passkey can stand in for the TOTP code
+	resp := env.do(http.MethodPost, "/login", "", map[string]string{"email": "owner@example.com", "password": "Password123!"})
+	var challenge handler.ErrorResponse
+	decodeBody(t, resp, &challenge)
+	if challenge.Error.Code != "mfa_required" || !slices.Equal(challenge.Error.MFAMethods, []string{"totp", "passkey"}) {
+		t.Fatalf("login = %q with methods %v, want mfa_required with [totp passkey]", challenge.Error.Code, challenge.Error.MFAMethods)
+	}
+	credential = env.passkeyCeremony("/mfa/passkey/begin", "", handler.MFASetupRequest{MFAToken: challenge.Error.MFAToken}, laptop.Login)
+	resp = env.do(http.MethodPost, "/mfa/passkey/verify", "", map[string]interface{}{
+		"mfa_token":  challenge.Error.MFAToken,
+		"credential": credential,
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("passkey MFA status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	resp.Body.Close()
+
+	// Manage it next to the sessions
+	listResp := env.do(http.MethodGet, "/passkeys", session.AccessToken, nil)
+	var list handler.PasskeyListResponse
+	decodeBody(t, listResp, &list)
+	if len(list.Data) != 1 || list.Data[0].LastUsedAt == nil {
+		t.Fatalf("passkeys = %+v, want one used passkey", list.Data)
+	}
+	renameResp := env.do(http.MethodPatch, "/passkeys/"+passkey.ID.String(), session.AccessToken, handler.RenamePasskeyRequest{Name: "Office laptop"})
+	if renameResp.StatusCode != http.StatusOK {
+		t.Fatalf("rename status = %d, want %d", renameResp.StatusCode, http.StatusOK)
+	}
+	renameResp.Body.Close()
+	deleteResp := env.do(http.MethodDelete, "/passkeys/"+passkey.ID.String(), session.AccessToken, nil)
+	if deleteResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete status = %d, want %d", deleteResp.StatusCode, http.StatusNoContent)
+	}
+	deleteResp.Body.Close()
+
+	// A removed passkey no longer signs in
+	resp = env.passkeyLogin(laptop)
+	var failure handler.ErrorResponse
+	decodeBody(t, resp, &failure)
+	if resp.StatusCode != http.StatusUnauthorized || failure.Error.Code != "passkey_invalid" {
+		t.Errorf("removed passkey login = %d %q, want 401 passkey_invalid", resp.StatusCode, failure.Error.Code)
+	}
+
+	seen := make(map[domain.AuthEventType]bool)
+	for _, e := range env.eventRepo.GetEvents() {
+		seen[e.EventType] = true
+	}
+	for _, eventType := range []domain.AuthEventType{domain.EventPasskeyRegistered, domain.EventPasskeyRenamed, domain.EventPasskeyRemoved} {
+		if !seen[eventType] {
+			t.Errorf("expected a %s event", eventType)
+		}
+	}
+
+	// Passkeys are for managers and above
+	waiterToken, _, waiterResp := env.login("waiter@example.com", "Password123!")
+	waiterResp.Body.Close()
+	resp = env.do(http.MethodPost, "/passkeys/register/begin", waiterToken, nil)
+	decodeBody(t, resp, &failure)
+	if resp.StatusCode != http.StatusForbidden || failure.Error.Code != "passkey_not_allowed" {
+		t.Errorf("waiter register = %d %q, want 403 passkey_not_allowed", resp.StatusCode, failure.Error.Code)
+	}
+}
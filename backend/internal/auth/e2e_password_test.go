@@ -3,6 +3,7 @@ package auth
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/solobueno/erp/internal/auth/domain"
 	"github.com/solobueno/erp/internal/auth/handler"
@@ -98,7 +99,9 @@ func TestE2E_ChangePassword_InvalidatesOtherSessions(t *testing.T) {
 func TestE2E_PasswordResetFlow(t *testing.T) {
 	env := setupE2E(t)
 	tenant := env.seedTenant("Acme Diner", "acme-diner")
-	env.seedUser("staff@example.com", "OldPass123!", tenant.ID, domain.RoleWaiter)
+	staff := env.seedUser("staff@example.com", "OldPass123!", tenant.ID, domain.RoleWaiter)
+	verifiedAt := time.Now()
+	staff.EmailVerifiedAt = &verifiedAt // Resets only go to verified addresses
 
 	reqResp := env.do(http.MethodPost, "/password-reset/request", "", handler.PasswordResetRequest{Email: "staff@example.com"})
 	if reqResp.StatusCode != http.StatusAccepted {
@@ -145,3 +148,94 @@ func TestE2E_PasswordResetFlow(t *testing.T) {
 	}
 	unknownResp.Body.Close()
 }
+
+// TestE2E_Refresh_ReuseRevokesFamily covers refresh-token reuse detection:
+// replaying a rotated refresh token over real HTTP signs out every session
+// descended from that login and alerts the user by email.
+func TestE2E_Refresh_ReuseRevokesFamily(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("staff@example.com", "Password123!", tenant.ID, domain.RoleWaiter)
+
+	_, original, loginResp := env.login("staff@example.com", "Password123!")
+	loginResp.Body.Close()
+
+	rotateResp := env.do(http.MethodPost, "/refresh", "", map[string]string{"refresh_token": original})
+	if rotateResp.StatusCode != http.StatusOK {
+		t.Fatalf("refresh status = %d, want %d", rotateResp.StatusCode, http.StatusOK)
+	}
+	var rotated handler.TokenResponse
+	decodeBody(t, rotateResp, &rotated)
+
+	replayResp := env.do(http.MethodPost, "/refresh", "", map[string]string{"refresh_token": original})
+	if replayResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("replayed refresh status = %d, want %d", replayResp.StatusCode, http.StatusUnauthorized)
+	}
+	replayResp.Body.Close()
+
+	latestResp := env.do(http.MethodPost, "/refresh", "", map[string]string{"refresh_token": rotated.RefreshToken})
+	if latestResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("latest token after reuse status = %d, want %d", latestResp.StatusCode, http.StatusUnauthorized)
+	}
+	latestResp.Body.Close()
+
+	if !env.emailer.hasReuseAlert("staff@example.com") {
+		t.Error("expected a refresh token reuse alert email")
+	}
+}
+
+// TestE2E_PasswordPolicy covers a tenant's password policy over real HTTP:
+// an admin tightens it, a member's password change is refused naming the
+// rule broken (including reuse of the current password), and a password
+// older than the maximum age must be reset at the next login.
+func TestE2E_PasswordPolicy(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("admin@example.com", "AdminPass123!", tenant.ID, domain.RoleAdmin)
+	staff := env.seedUser("staff@example.com", "OldPass123!", tenant.ID, domain.RoleWaiter)
+
+	adminAccess, _, adminLogin := env.login("admin@example.com", "AdminPass123!")
+	adminLogin.Body.Close()
+	putResp := env.do(http.MethodPut, "/password-policy", adminAccess, handler.PasswordPolicyRequest{
+		MinLength: 10, RequireLowercase: true, RequireDigit: true, RequireSymbol: true, MaxAgeDays: 30, HistoryCount: 3,
+	})
+	if putResp.StatusCode != http.StatusOK {
+		t.Fatalf("put policy status = %d, want %d", putResp.StatusCode, http.StatusOK)
+	}
+	putResp.Body.Close()
+
+	staffAccess, _, staffLogin := env.login("staff@example.com", "OldPass123!")
+	staffLogin.Body.Close()
+	for _, tt := range []struct {
+		password string
+		wantRule string
+	}{
+		{"nosymbols12345", "symbol"},
+		{"OldPass123!", "reused"},
+	} {
+		resp := env.do(http.MethodPost, "/change-password", staffAccess, handler.ChangePasswordRequest{
+			CurrentPassword: "OldPass123!", NewPassword: tt.password,
+		})
+		var errResp handler.ErrorResponse
+		decodeBody(t, resp, &errResp)
+		if resp.StatusCode != http.StatusBadRequest || errResp.Error.Code != "password_weak" || errResp.Error.Rule != tt.wantRule {
+			t.Errorf("change to %q = %d %+v, want password_weak for rule %s", tt.password, resp.StatusCode, errResp.Error, tt.wantRule)
+		}
+	}
+
+	// The waiter can't manage the policy
+	forbidden := env.do(http.MethodGet, "/password-policy", staffAccess, nil)
+	if forbidden.StatusCode != http.StatusForbidden {
+		t.Errorf("waiter get policy status = %d, want %d", forbidden.StatusCode, http.StatusForbidden)
+	}
+	forbidden.Body.Close()
+
+	changedAt := time.Now().Add(-31 * 24 * time.Hour)
+	staff.PasswordChangedAt = &changedAt
+	expiredLogin := env.do(http.MethodPost, "/login", "", handler.LoginRequest{Email: "staff@example.com", Password: "OldPass123!"})
+	var loginResp handler.LoginResponse
+	decodeBody(t, expiredLogin, &loginResp)
+	if !loginResp.User.MustResetPassword {
+		t.Error("a password older than the policy's maximum age should require a reset at login")
+	}
+}
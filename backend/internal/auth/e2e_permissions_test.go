@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+	"github.com/solobueno/erp/pkg/jwt"
+)
+
+// TestE2E_Permissions covers the default role → permission matrix reaching
+// the access token, /me, and the routes guarded by RequirePermission.
+func TestE2E_Permissions(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("manager@example.com", "ManagerPass123!", tenant.ID, domain.RoleManager)
+	env.seedUser("kitchen@example.com", "KitchenPass123!", tenant.ID, domain.RoleKitchen)
+
+	managerToken, _, resp := env.login("manager@example.com", "ManagerPass123!")
+	resp.Body.Close()
+	kitchenToken, _, resp := env.login("kitchen@example.com", "KitchenPass123!")
+	resp.Body.Close()
+
+	claims, err := jwt.ParseUnverified(managerToken)
+	if err != nil {
+		t.Fatalf("failed to parse access token: %v", err)
+	}
+	if !slices.Contains(claims.Permissions, string(domain.PermUsersManage)) {
+		t.Errorf("manager perms claim = %v, want to include %q", claims.Permissions, domain.PermUsersManage)
+	}
+
+	meResp := env.do(http.MethodGet, "/me", managerToken, nil)
+	var me handler.MeResponse
+	decodeBody(t, meResp, &me)
+	if !slices.Equal(me.Permissions, claims.Permissions) {
+		t.Errorf("/me permissions = %v, want the token's %v", me.Permissions, claims.Permissions)
+	}
+
+	meResp = env.do(http.MethodGet, "/me", kitchenToken, nil)
+	decodeBody(t, meResp, &me)
+	if slices.Contains(me.Permissions, string(domain.PermUsersManage)) {
+		t.Errorf("kitchen /me permissions = %v, should not include %q", me.Permissions, domain.PermUsersManage)
+	}
+
+	listResp := env.do(http.MethodGet, "/users", managerToken, nil)
+	if listResp.StatusCode != http.StatusOK {
+		t.Errorf("manager list users status = %d, want %d", listResp.StatusCode, http.StatusOK)
+	}
+	listResp.Body.Close()
+
+	forbiddenResp := env.do(http.MethodGet, "/users", kitchenToken, nil)
+	if forbiddenResp.StatusCode != http.StatusForbidden {
+		t.Fatalf("kitchen list users status = %d, want %d", forbiddenResp.StatusCode, http.StatusForbidden)
+	}
+	var errResp handler.ErrorResponse
+	decodeBody(t, forbiddenResp, &errResp)
+	if errResp.Error.Code != "insufficient_permission" {
+		t.Errorf("error code = %q, want %q", errResp.Error.Code, "insufficient_permission")
+	}
+}
+
+// TestE2E_CustomRoles covers an admin defining a custom role, a manager
+// assigning it, and its permission set (not the base role's) reaching the
+// assignee's token and the routes guarded by RequirePermission.
+func TestE2E_CustomRoles(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("admin@example.com", "AdminPass123!", tenant.ID, domain.RoleAdmin)
+	env.seedUser("manager@example.com", "ManagerPass123!", tenant.ID, domain.RoleManager)
+	waiter := env.seedUser("waiter@example.com", "WaiterPass123!", tenant.ID, domain.RoleWaiter)
+
+	adminToken, _, resp := env.login("admin@example.com", "AdminPass123!")
+	resp.Body.Close()
+	managerToken, _, resp := env.login("manager@example.com", "ManagerPass123!")
+	resp.Body.Close()
+
+	// Managers can't define roles
+	forbidden := env.do(http.MethodGet, "/roles", managerToken, nil)
+	forbidden.Body.Close()
+	if forbidden.StatusCode != http.StatusForbidden {
+		t.Errorf("manager list roles status = %d, want %d", forbidden.StatusCode, http.StatusForbidden)
+	}
+
+	createResp := env.do(http.MethodPost, "/roles", adminToken, map[string]interface{}{
+		"name":        "Floor lead",
+		"base_role":   "waiter",
+		"permissions": []string{string(domain.PermTerminalsManage)},
+	})
+	if createResp.StatusCode != http.StatusCreated {
+		t.Fatalf("create role status = %d, want %d", createResp.StatusCode, http.StatusCreated)
+	}
+	var floorLead handler.CustomRoleResponse
+	decodeBody(t, createResp, &floorLead)
+
+	assignResp := env.do(http.MethodPatch, "/users/"+waiter.ID.String()+"/role", managerToken, map[string]interface{}{
+		"custom_role_id": floorLead.ID,
+	})
+	if assignResp.StatusCode != http.StatusOK {
+		t.Fatalf("assign custom role status = %d, want %d", assignResp.StatusCode, http.StatusOK)
+	}
+	var assigned handler.UserResponse
+	decodeBody(t, assignResp, &assigned)
+	if assigned.Role != "waiter" || assigned.CustomRole != "Floor lead" {
+		t.Errorf("assigned role = %s/%q, want waiter/Floor lead", assigned.Role, assigned.CustomRole)
+	}
+
+	waitForNextSecond()
+	waiterToken, _, resp := env.login("waiter@example.com", "WaiterPass123!")
+	resp.Body.Close()
+	claims, err := jwt.ParseUnverified(waiterToken)
+	if err != nil {
+		t.Fatalf("failed to parse access token: %v", err)
+	}
+	if claims.Role != "waiter" || !slices.Equal(claims.Permissions, []string{string(domain.PermTerminalsManage)}) {
+		t.Errorf("token role/perms = %s/%v, want waiter with only %q", claims.Role, claims.Permissions, domain.PermTerminalsManage)
+	}
+	terminals := env.do(http.MethodGet, "/terminals", waiterToken, nil)
+	terminals.Body.Close()
+	if terminals.StatusCode != http.StatusOK {
+		t.Errorf("floor lead list terminals status = %d, want %d", terminals.StatusCode, http.StatusOK)
+	}
+
+	inUse := env.do(http.MethodDelete, "/roles/"+floorLead.ID.String(), adminToken, nil)
+	inUse.Body.Close()
+	if inUse.StatusCode != http.StatusConflict {
+		t.Errorf("delete assigned role status = %d, want %d", inUse.StatusCode, http.StatusConflict)
+	}
+
+	updateResp := env.do(http.MethodPatch, "/roles/"+floorLead.ID.String(), adminToken, map[string]interface{}{
+		"permissions": []string{},
+	})
+	updateResp.Body.Close()
+	if updateResp.StatusCode != http.StatusOK {
+		t.Fatalf("update role status = %d, want %d", updateResp.StatusCode, http.StatusOK)
+	}
+
+	revoked := env.do(http.MethodGet, "/me", waiterToken, nil)
+	revoked.Body.Close()
+	if revoked.StatusCode != http.StatusUnauthorized {
+		t.Errorf("token after permission change status = %d, want %d", revoked.StatusCode, http.StatusUnauthorized)
+	}
+
+	waitForNextSecond()
+	waiterToken, _, resp = env.login("waiter@example.com", "WaiterPass123!")
+	resp.Body.Close()
+	terminals = env.do(http.MethodGet, "/terminals", waiterToken, nil)
+	terminals.Body.Close()
+	if terminals.StatusCode != http.StatusForbidden {
+		t.Errorf("list terminals after losing permission status = %d, want %d", terminals.StatusCode, http.StatusForbidden)
+	}
+}
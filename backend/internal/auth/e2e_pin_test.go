@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+)
+
+// doTerminal performs a JSON request authenticated by a terminal device token
+// instead of a bearer token.
+func (e *e2eEnv) doTerminal(method, path, terminalToken string, body interface{}) *http.Response {
+	e.t.Helper()
+	b, err := json.Marshal(body)
+	if err != nil {
+		e.t.Fatalf("failed to marshal request body: %v", err)
+	}
+	req, err := http.NewRequest(method, e.server.URL+path, bytes.NewReader(b))
+	if err != nil {
+		e.t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handler.TerminalTokenHeader, terminalToken)
+	resp, err := e.client.Do(req)
+	if err != nil {
+		e.t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	return resp
+}
+
+// TestE2E_PINLoginOnTerminal covers the shared-terminal flow end to end: a
+// manager registers a terminal, a cashier sets a PIN, and the terminal
+// offers the cashier for PIN login and gets a token scoped to its tenant.
+func TestE2E_PINLoginOnTerminal(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	manager := env.seedUser("manager@example.com", "Password123!", tenant.ID, domain.RoleManager)
+	cashier := env.seedUser("cashier@example.com", "Password123!", tenant.ID, domain.RoleCashier)
+
+	managerToken, _, resp := env.login("manager@example.com", "Password123!")
+	resp.Body.Close()
+
+	regResp := env.do(http.MethodPost, "/terminals", managerToken, handler.RegisterTerminalRequest{Name: "Front counter"})
+	if regResp.StatusCode != http.StatusCreated {
+		t.Fatalf("register terminal status = %d, want %d", regResp.StatusCode, http.StatusCreated)
+	}
+	var terminal handler.RegisterTerminalResponse
+	decodeBody(t, regResp, &terminal)
+
+	// Managers can set a PIN for approvals, but log in with their password
+	pinResp := env.do(http.MethodPut, "/pin", managerToken, handler.SetPINRequest{CurrentPassword: "Password123!", PIN: "3691"})
+	if pinResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("manager set PIN status = %d, want %d", pinResp.StatusCode, http.StatusNoContent)
+	}
+	pinResp.Body.Close()
+	managerLogin := env.doTerminal(http.MethodPost, "/pin-login", terminal.TerminalToken, handler.PINLoginRequest{UserID: manager.ID, PIN: "3691"})
+	if managerLogin.StatusCode != http.StatusForbidden {
+		t.Fatalf("manager PIN login status = %d, want %d", managerLogin.StatusCode, http.StatusForbidden)
+	}
+	managerLogin.Body.Close()
+
+	cashierToken, _, resp := env.login("cashier@example.com", "Password123!")
+	resp.Body.Close()
+	pinResp = env.do(http.MethodPut, "/pin", cashierToken, handler.SetPINRequest{CurrentPassword: "Password123!", PIN: "2580"})
+	if pinResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("cashier set PIN status = %d, want %d", pinResp.StatusCode, http.StatusNoContent)
+	}
+	pinResp.Body.Close()
+
+	staffResp := env.doTerminal(http.MethodGet, "/terminal/staff", terminal.TerminalToken, nil)
+	if staffResp.StatusCode != http.StatusOK {
+		t.Fatalf("terminal staff status = %d, want %d", staffResp.StatusCode, http.StatusOK)
+	}
+	var staff handler.TerminalStaffResponse
+	decodeBody(t, staffResp, &staff)
+	if len(staff.Data) != 1 || staff.Data[0].ID != cashier.ID {
+		t.Fatalf("terminal staff = %+v, want only the cashier", staff.Data)
+	}
+
+	loginResp := env.doTerminal(http.MethodPost, "/pin-login", terminal.TerminalToken, handler.PINLoginRequest{UserID: cashier.ID, PIN: "2580"})
+	if loginResp.StatusCode != http.StatusOK {
+		t.Fatalf("PIN login status = %d, want %d", loginResp.StatusCode, http.StatusOK)
+	}
+	var pinLogin handler.PINLoginResponse
+	decodeBody(t, loginResp, &pinLogin)
+
+	meResp := env.do(http.MethodGet, "/me", pinLogin.AccessToken, nil)
+	if meResp.StatusCode != http.StatusOK {
+		t.Fatalf("/me with PIN token status = %d, want %d", meResp.StatusCode, http.StatusOK)
+	}
+	var me handler.MeResponse
+	decodeBody(t, meResp, &me)
+	if me.ID != cashier.ID || me.TenantID != tenant.ID || me.Role != string(domain.RoleCashier) {
+		t.Errorf("/me = %+v, want the cashier in %s", me, tenant.ID)
+	}
+
+	// Once revoked, the terminal can no longer log anyone in
+	revokeResp := env.do(http.MethodDelete, "/terminals/"+terminal.ID.String(), managerToken, nil)
+	if revokeResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("revoke terminal status = %d, want %d", revokeResp.StatusCode, http.StatusNoContent)
+	}
+	revokeResp.Body.Close()
+
+	loginResp = env.doTerminal(http.MethodPost, "/pin-login", terminal.TerminalToken, handler.PINLoginRequest{UserID: cashier.ID, PIN: "2580"})
+	if loginResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("PIN login on revoked terminal status = %d, want %d", loginResp.StatusCode, http.StatusUnauthorized)
+	}
+	var errResp handler.ErrorResponse
+	decodeBody(t, loginResp, &errResp)
+	if errResp.Error.Code != "terminal_invalid" {
+		t.Errorf("error code = %q, want terminal_invalid", errResp.Error.Code)
+	}
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+)
+
+// TestE2E_LogoutRevokesAccessToken covers an access token being refused
+// straight after logout, rather than staying usable until it expires.
+func TestE2E_LogoutRevokesAccessToken(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("staff@example.com", "Password123!", tenant.ID, domain.RoleWaiter)
+
+	token, refresh, resp := env.login("staff@example.com", "Password123!")
+	resp.Body.Close()
+
+	logoutResp := env.do(http.MethodPost, "/logout", token, map[string]string{"refresh_token": refresh})
+	if logoutResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("logout status = %d, want %d", logoutResp.StatusCode, http.StatusNoContent)
+	}
+	logoutResp.Body.Close()
+
+	meResp := env.do(http.MethodGet, "/me", token, nil)
+	if meResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("/me after logout status = %d, want %d", meResp.StatusCode, http.StatusUnauthorized)
+	}
+	var errResp handler.ErrorResponse
+	decodeBody(t, meResp, &errResp)
+	if errResp.Error.Code != "token_revoked" {
+		t.Errorf("error code = %q, want token_revoked", errResp.Error.Code)
+	}
+}
+
+// TestE2E_RoleChangeRevokesAccessTokens covers a demoted employee's existing
+// access token being refused, so the old role can't be used until expiry.
+func TestE2E_RoleChangeRevokesAccessTokens(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("owner@example.com", "Password123!", tenant.ID, domain.RoleOwner)
+	staff := env.seedUser("staff@example.com", "Password123!", tenant.ID, domain.RoleManager)
+
+	ownerToken, _, resp := env.login("owner@example.com", "Password123!")
+	resp.Body.Close()
+	staffToken, _, resp := env.login("staff@example.com", "Password123!")
+	resp.Body.Close()
+
+	roleResp := env.do(http.MethodPatch, "/users/"+staff.ID.String()+"/role", ownerToken, handler.UpdateRoleRequest{Role: domain.RoleWaiter})
+	if roleResp.StatusCode != http.StatusOK {
+		t.Fatalf("role update status = %d, want %d", roleResp.StatusCode, http.StatusOK)
+	}
+	roleResp.Body.Close()
+
+	meResp := env.do(http.MethodGet, "/me", staffToken, nil)
+	if meResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("/me with pre-demotion token status = %d, want %d", meResp.StatusCode, http.StatusUnauthorized)
+	}
+	meResp.Body.Close()
+
+	// Signing in again issues a token carrying the new role.
+	waitForNextSecond()
+	newToken, _, resp := env.login("staff@example.com", "Password123!")
+	resp.Body.Close()
+	meResp = env.do(http.MethodGet, "/me", newToken, nil)
+	if meResp.StatusCode != http.StatusOK {
+		t.Fatalf("/me with new token status = %d, want %d", meResp.StatusCode, http.StatusOK)
+	}
+	var me handler.MeResponse
+	decodeBody(t, meResp, &me)
+	if me.Role != string(domain.RoleWaiter) {
+		t.Errorf("Role after re-login = %q, want %q", me.Role, domain.RoleWaiter)
+	}
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+	"github.com/solobueno/erp/pkg/scim"
+)
+
+// TestE2E_SCIM covers an HR system provisioning a new hire over SCIM,
+// moving them into a role group, and terminating them: the termination
+// removes them from the tenant and ends the sessions they already had.
+func TestE2E_SCIM(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Grupo Sabor", "grupo-sabor")
+	env.seedUser("admin@gruposabor.com", "AdminPass123!", tenant.ID, domain.RoleAdmin)
+	waiter := env.seedUser("waiter@gruposabor.com", "WaiterPass123!", tenant.ID, domain.RoleWaiter)
+
+	adminToken, _, resp := env.login("admin@gruposabor.com", "AdminPass123!")
+	resp.Body.Close()
+	waiterToken, waiterRefresh, resp := env.login("waiter@gruposabor.com", "WaiterPass123!")
+	resp.Body.Close()
+
+	wantStatus := func(resp *http.Response, status int) {
+		t.Helper()
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, status)
+		}
+	}
+
+	// Only admins create SCIM tokens
+	wantStatus(env.do(http.MethodPost, "/scim/tokens", waiterToken, handler.CreateSCIMTokenRequest{Name: "Workday"}), http.StatusForbidden)
+	resp = env.do(http.MethodPost, "/scim/tokens", adminToken, handler.CreateSCIMTokenRequest{Name: "Workday"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create token status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	var created handler.CreateSCIMTokenResponse
+	decodeBody(t, resp, &created)
+	scimToken := created.Token
+
+	// Access tokens don't work on the SCIM API, and SCIM tokens don't work elsewhere
+	wantStatus(env.do(http.MethodGet, "/scim/v2/Users", adminToken, nil), http.StatusUnauthorized)
+	wantStatus(env.do(http.MethodGet, "/me", scimToken, nil), http.StatusUnauthorized)
+
+	// A new hire is created as a viewer who must set a password
+	resp = env.do(http.MethodPost, "/scim/v2/Users", scimToken, scim.User{
+		Schemas:    []string{scim.UserSchema},
+		UserName:   "maria@gruposabor.com",
+		ExternalID: "HR-1042",
+		Name:       &scim.Name{GivenName: "María", FamilyName: "Pérez"},
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create user status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != scim.ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, scim.ContentType)
+	}
+	var hire scim.User
+	decodeBody(t, resp, &hire)
+	if !hire.IsActive() || len(hire.Groups) != 1 || hire.Groups[0].Value != string(domain.RoleViewer) {
+		t.Fatalf("new hire = %+v", hire)
+	}
+	wantStatus(env.do(http.MethodPost, "/scim/v2/Users", scimToken, scim.User{UserName: "maria@gruposabor.com"}), http.StatusConflict)
+
+	// The HR system looks them up by userName and puts them in the cashier group
+	resp = env.do(http.MethodGet, `/scim/v2/Users?filter=userName%20eq%20%22maria@gruposabor.com%22`, scimToken, nil)
+	var found scim.ListResponse
+	decodeBody(t, resp, &found)
+	if found.TotalResults != 1 {
+		t.Fatalf("filter found %d users, want 1", found.TotalResults)
+	}
+	wantStatus(env.do(http.MethodPatch, "/scim/v2/Groups/cashier", scimToken, scim.PatchRequest{
+		Schemas:    []string{scim.PatchOpSchema},
+		Operations: []scim.PatchOperation{{Op: "add", Path: "members", Value: []byte(`[{"value":"` + hire.ID + `"}]`)}},
+	}), http.StatusOK)
+	resp = env.do(http.MethodGet, "/scim/v2/Users/"+hire.ID, scimToken, nil)
+	decodeBody(t, resp, &hire)
+	if len(hire.Groups) != 1 || hire.Groups[0].Value != string(domain.RoleCashier) {
+		t.Errorf("groups = %+v, want cashier", hire.Groups)
+	}
+
+	// SCIM can't reach admins
+	wantStatus(env.do(http.MethodPatch, "/scim/v2/Groups/admin", scimToken, scim.PatchRequest{
+		Operations: []scim.PatchOperation{{Op: "add", Path: "members", Value: []byte(`[{"value":"` + hire.ID + `"}]`)}},
+	}), http.StatusForbidden)
+
+	// Terminating the waiter ends their access right away.
+	wantStatus(env.do(http.MethodPatch, "/scim/v2/Users/"+waiter.ID.String(), scimToken, scim.PatchRequest{
+		Schemas:    []string{scim.PatchOpSchema},
+		Operations: []scim.PatchOperation{{Op: "replace", Value: []byte(`{"active":false}`)}},
+	}), http.StatusOK)
+	wantStatus(env.do(http.MethodGet, "/me", waiterToken, nil), http.StatusUnauthorized)
+	wantStatus(env.do(http.MethodPost, "/refresh", "", map[string]string{"refresh_token": waiterRefresh}), http.StatusUnauthorized)
+	_, _, resp = env.login("waiter@gruposabor.com", "WaiterPass123!")
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		t.Error("a deprovisioned user should not be able to log in to the tenant")
+	}
+
+	// They are still listed, as inactive
+	resp = env.do(http.MethodGet, "/scim/v2/Users/"+waiter.ID.String(), scimToken, nil)
+	var terminated scim.User
+	decodeBody(t, resp, &terminated)
+	if terminated.IsActive() {
+		t.Error("terminated user should be inactive")
+	}
+	wantStatus(env.do(http.MethodDelete, "/scim/v2/Users/"+hire.ID, scimToken, nil), http.StatusNoContent)
+
+	// Revoking the token cuts the HR system off
+	wantStatus(env.do(http.MethodDelete, "/scim/tokens/"+created.ID.String(), adminToken, nil), http.StatusNoContent)
+	wantStatus(env.do(http.MethodGet, "/scim/v2/Users", scimToken, nil), http.StatusUnauthorized)
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+)
+
+// e2eReportsRead stands in for a module permission on the e2e server's
+// /reports/sales route.
+const e2eReportsRead domain.Permission = "service_accounts_test.reports_read"
+
+func init() {
+	domain.RegisterPermissions(domain.PermissionDefinition{
+		Permission:  e2eReportsRead,
+		Description: "Read sales reports (e2e tests)",
+		Roles:       domain.RolesAtLeast(domain.RoleManager),
+	})
+}
+
+// TestE2E_ServiceAccounts covers an admin setting up an accounting sync:
+// its API key opens exactly the routes its scopes allow and none meant for
+// people, rotation keeps the old key working for the grace period, and
+// revoked or expired keys are refused.
+func TestE2E_ServiceAccounts(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Grupo Sabor", "grupo-sabor")
+	env.seedUser("admin@gruposabor.com", "AdminPass123!", tenant.ID, domain.RoleAdmin)
+	env.seedUser("manager@gruposabor.com", "ManagerPass123!", tenant.ID, domain.RoleManager)
+
+	adminToken, _, resp := env.login("admin@gruposabor.com", "AdminPass123!")
+	resp.Body.Close()
+	managerToken, _, resp := env.login("manager@gruposabor.com", "ManagerPass123!")
+	resp.Body.Close()
+
+	wantStatus := func(resp *http.Response, status int) {
+		t.Helper()
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, status)
+		}
+	}
+	wantError := func(resp *http.Response, status int, code string) {
+		t.Helper()
+		if resp.StatusCode != status {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, status)
+		}
+		var body handler.ErrorResponse
+		decodeBody(t, resp, &body)
+		if body.Error.Code != code {
+			t.Errorf("error code = %q, want %q", body.Error.Code, code)
+		}
+	}
+	createKey := func(path string, body interface{}) handler.CreateAPIKeyResponse {
+		t.Helper()
+		resp := env.do(http.MethodPost, path, adminToken, body)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("POST %s status = %d, want %d", path, resp.StatusCode, http.StatusCreated)
+		}
+		var created handler.CreateAPIKeyResponse
+		decodeBody(t, resp, &created)
+		return created
+	}
+
+	// Only admins manage service accounts
+	account := handler.CreateServiceAccountRequest{Name: "Accounting sync", Scopes: []domain.Permission{e2eReportsRead}}
+	wantError(env.do(http.MethodPost, "/service-accounts", managerToken, account), http.StatusForbidden, "insufficient_role")
+	resp = env.do(http.MethodPost, "/service-accounts", adminToken, account)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	var sync handler.ServiceAccountResponse
+	decodeBody(t, resp, &sync)
+	accountPath := "/service-accounts/" + sync.ID.String()
+
+	key := createKey(accountPath+"/keys", handler.CreateAPIKeyRequest{Name: "production"})
+	if !strings.HasPrefix(key.Key, key.Prefix+"_") || !strings.HasPrefix(key.Prefix, domain.APIKeyPrefix) {
+		t.Fatalf("key %q doesn't start with its prefix %q", key.Key, key.Prefix)
+	}
+
+	// The key opens the routes its scopes allow, as the service account
+	resp = env.do(http.MethodGet, "/reports/sales", key.Key, nil)
+	wantStatus(resp, http.StatusNoContent)
+	if got := resp.Header.Get("X-User-ID"); got != sync.ID.String() {
+		t.Errorf("request made as %s, want the service account %s", got, sync.ID)
+	}
+
+	// ...and none of the routes meant for people
+	for _, path := range []string{"/me", "/sessions", "/users", "/roles", "/service-accounts"} {
+		wantError(env.do(http.MethodGet, path, key.Key, nil), http.StatusForbidden, "service_account_forbidden")
+	}
+
+	// Scope changes apply to the next request
+	wantStatus(env.do(http.MethodPatch, accountPath, adminToken, map[string][]string{"scopes": {}}), http.StatusOK)
+	wantError(env.do(http.MethodGet, "/reports/sales", key.Key, nil), http.StatusForbidden, "insufficient_permission")
+	wantStatus(env.do(http.MethodPatch, accountPath, adminToken, handler.UpdateServiceAccountRequest{Scopes: []domain.Permission{e2eReportsRead}}), http.StatusOK)
+
+	// Rotating with a grace period keeps the old key working alongside the new one
+	rotated := createKey(accountPath+"/keys/"+key.ID.String()+"/rotate", handler.RotateAPIKeyRequest{GracePeriodHours: 24})
+	if rotated.RotatedFromID == nil || *rotated.RotatedFromID != key.ID {
+		t.Errorf("rotated_from_id = %v, want %s", rotated.RotatedFromID, key.ID)
+	}
+	wantStatus(env.do(http.MethodGet, "/reports/sales", key.Key, nil), http.StatusNoContent)
+	wantStatus(env.do(http.MethodGet, "/reports/sales", rotated.Key, nil), http.StatusNoContent)
+
+	// Without one the old key stops at once
+	replaced := createKey(accountPath+"/keys/"+rotated.ID.String()+"/rotate", handler.RotateAPIKeyRequest{})
+	wantError(env.do(http.MethodGet, "/reports/sales", rotated.Key, nil), http.StatusUnauthorized, "api_key_invalid")
+	wantStatus(env.do(http.MethodGet, "/reports/sales", replaced.Key, nil), http.StatusNoContent)
+
+	// The key list shows prefixes and last use, never the keys
+	resp = env.do(http.MethodGet, accountPath+"/keys", adminToken, nil)
+	var keys handler.APIKeyListResponse
+	decodeBody(t, resp, &keys)
+	if len(keys.Data) != 2 {
+		t.Fatalf("listed %d keys, want the graced and the latest", len(keys.Data))
+	}
+	for _, k := range keys.Data {
+		if k.LastUsedAt == nil {
+			t.Errorf("key %s has no last use", k.Prefix)
+		}
+	}
+
+	// Revoked and expired keys are refused
+	wantStatus(env.do(http.MethodDelete, accountPath+"/keys/"+replaced.ID.String(), adminToken, nil), http.StatusNoContent)
+	wantError(env.do(http.MethodGet, "/reports/sales", replaced.Key, nil), http.StatusUnauthorized, "api_key_invalid")
+	env.apiKeys.SetExpiry(context.Background(), key.ID, time.Now().Add(-time.Second))
+	wantError(env.do(http.MethodGet, "/reports/sales", key.Key, nil), http.StatusUnauthorized, "api_key_expired")
+
+	// Deleting the account cuts off every key
+	fresh := createKey(accountPath+"/keys", handler.CreateAPIKeyRequest{Name: "staging", ExpiresInDays: 7})
+	wantStatus(env.do(http.MethodDelete, accountPath, adminToken, nil), http.StatusNoContent)
+	wantError(env.do(http.MethodGet, "/reports/sales", fresh.Key, nil), http.StatusUnauthorized, "api_key_invalid")
+}
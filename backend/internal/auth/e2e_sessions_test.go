@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+)
+
+// TestE2E_SelfServiceSessions covers a user reviewing their signed-in
+// devices, signing one out, then logging out everywhere except here.
+func TestE2E_SelfServiceSessions(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("staff@example.com", "Password123!", tenant.ID, domain.RoleWaiter)
+
+	token, _, resp := env.login("staff@example.com", "Password123!")
+	resp.Body.Close()
+	_, tabletRefresh, resp := env.login("staff@example.com", "Password123!")
+	resp.Body.Close()
+	_, phoneRefresh, resp := env.login("staff@example.com", "Password123!")
+	resp.Body.Close()
+
+	listResp := env.do(http.MethodGet, "/sessions", token, nil)
+	if listResp.StatusCode != http.StatusOK {
+		t.Fatalf("list sessions status = %d, want %d", listResp.StatusCode, http.StatusOK)
+	}
+	var list handler.SessionListResponse
+	decodeBody(t, listResp, &list)
+	if len(list.Data) != 3 {
+		t.Fatalf("expected 3 sessions, got %d", len(list.Data))
+	}
+	var current, other *handler.SessionResponse
+	for i := range list.Data {
+		if list.Data[i].Current {
+			current = &list.Data[i]
+		} else if other == nil {
+			other = &list.Data[i]
+		}
+	}
+	if current == nil {
+		t.Fatal("expected the calling session to be flagged as current")
+	}
+
+	revokeResp := env.do(http.MethodDelete, "/sessions/"+other.ID.String(), token, nil)
+	if revokeResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("revoke session status = %d, want %d", revokeResp.StatusCode, http.StatusNoContent)
+	}
+	revokeResp.Body.Close()
+
+	othersResp := env.do(http.MethodPost, "/sessions/revoke-others", token, nil)
+	if othersResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("revoke others status = %d, want %d", othersResp.StatusCode, http.StatusNoContent)
+	}
+	othersResp.Body.Close()
+
+	for name, refresh := range map[string]string{"tablet": tabletRefresh, "phone": phoneRefresh} {
+		r := env.do(http.MethodPost, "/refresh", "", map[string]string{"refresh_token": refresh})
+		if r.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s refresh after revoke status = %d, want %d", name, r.StatusCode, http.StatusUnauthorized)
+		}
+		r.Body.Close()
+	}
+
+	listResp = env.do(http.MethodGet, "/sessions", token, nil)
+	decodeBody(t, listResp, &list)
+	if len(list.Data) != 1 || list.Data[0].ID != current.ID {
+		t.Errorf("expected only the current session to remain, got %+v", list.Data)
+	}
+}
+
+// TestE2E_ManagerRevokesEmployeeSessions covers a manager signing an
+// employee out of every device, and being refused for a peer.
+func TestE2E_ManagerRevokesEmployeeSessions(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("manager@example.com", "Password123!", tenant.ID, domain.RoleManager)
+	peer := env.seedUser("manager2@example.com", "Password123!", tenant.ID, domain.RoleManager)
+	staff := env.seedUser("staff@example.com", "Password123!", tenant.ID, domain.RoleWaiter)
+
+	managerToken, _, resp := env.login("manager@example.com", "Password123!")
+	resp.Body.Close()
+	_, staffRefresh, resp := env.login("staff@example.com", "Password123!")
+	resp.Body.Close()
+
+	listResp := env.do(http.MethodGet, "/users/"+staff.ID.String()+"/sessions", managerToken, nil)
+	if listResp.StatusCode != http.StatusOK {
+		t.Fatalf("list user sessions status = %d, want %d", listResp.StatusCode, http.StatusOK)
+	}
+	var list handler.SessionListResponse
+	decodeBody(t, listResp, &list)
+	if len(list.Data) != 1 {
+		t.Fatalf("expected 1 staff session, got %d", len(list.Data))
+	}
+
+	revokeResp := env.do(http.MethodDelete, "/users/"+staff.ID.String()+"/sessions/"+list.Data[0].ID.String(), managerToken, nil)
+	if revokeResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("revoke user session status = %d, want %d", revokeResp.StatusCode, http.StatusNoContent)
+	}
+	revokeResp.Body.Close()
+
+	refreshResp := env.do(http.MethodPost, "/refresh", "", map[string]string{"refresh_token": staffRefresh})
+	if refreshResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("staff refresh after revoke status = %d, want %d", refreshResp.StatusCode, http.StatusUnauthorized)
+	}
+	refreshResp.Body.Close()
+
+	peerResp := env.do(http.MethodDelete, "/users/"+peer.ID.String()+"/sessions", managerToken, nil)
+	if peerResp.StatusCode != http.StatusForbidden {
+		t.Errorf("revoke peer sessions status = %d, want %d", peerResp.StatusCode, http.StatusForbidden)
+	}
+	peerResp.Body.Close()
+
+	staffToken, _, resp := env.login("staff@example.com", "Password123!")
+	resp.Body.Close()
+	forbiddenResp := env.do(http.MethodGet, "/users/"+staff.ID.String()+"/sessions", staffToken, nil)
+	if forbiddenResp.StatusCode != http.StatusForbidden {
+		t.Errorf("waiter listing user sessions status = %d, want %d", forbiddenResp.StatusCode, http.StatusForbidden)
+	}
+	forbiddenResp.Body.Close()
+}
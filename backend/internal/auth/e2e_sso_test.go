@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+	"github.com/solobueno/erp/pkg/oidc/oidctest"
+)
+
+// e2eSSORedirectURL is the frontend page identity providers send users
+// back to; it posts the state and code on to /sso/callback.
+const e2eSSORedirectURL = "https://app.example.com/sso/callback"
+
+// startIdP starts a stub identity provider and points the env's SSO
+// service at it.
+func (e *e2eEnv) startIdP() *oidctest.Server {
+	e.t.Helper()
+	idp := oidctest.NewServer("erp-client", "erp-secret")
+	e.t.Cleanup(idp.Close)
+	e.ssoClient.Transport = idp.Client().Transport
+	return idp
+}
+
+// ssoLogin signs in to tenantSlug through the stub IdP as identity, the way
+// a browser would, and returns the callback response.
+func (e *e2eEnv) ssoLogin(idp *oidctest.Server, tenantSlug string, identity oidctest.Identity) *http.Response {
+	e.t.Helper()
+	startResp := e.do(http.MethodPost, "/sso/start", "", handler.SSOStartRequest{TenantSlug: tenantSlug})
+	if startResp.StatusCode != http.StatusOK {
+		e.t.Fatalf("sso start status = %d, want %d", startResp.StatusCode, http.StatusOK)
+	}
+	var start handler.SSOStartResponse
+	decodeBody(e.t, startResp, &start)
+
+	idp.SetIdentity(identity)
+	callback, err := idp.Authorize(start.AuthorizationURL)
+	if err != nil {
+		e.t.Fatalf("authorize at IdP failed: %v", err)
+	}
+	return e.do(http.MethodPost, "/sso/callback", "", handler.SSOCallbackRequest{
+		State: callback.Query().Get("state"),
+		Code:  callback.Query().Get("code"),
+	})
+}
+
+// TestE2E_SSO covers an admin setting up their company IdP and turning
+// password login off, staff signing in through it with their existing
+// accounts, the owner's break-glass password login, and removing SSO.
+func TestE2E_SSO(t *testing.T) {
+	env := setupE2E(t)
+	idp := env.startIdP()
+	tenant := env.seedTenant("Grupo Sabor", "grupo-sabor")
+	env.seedUser("owner@gruposabor.com", "OwnerPass123!", tenant.ID, domain.RoleOwner)
+	env.seedUser("admin@gruposabor.com", "AdminPass123!", tenant.ID, domain.RoleAdmin)
+	waiter := env.seedUser("waiter@gruposabor.com", "WaiterPass123!", tenant.ID, domain.RoleWaiter)
+
+	adminToken, _, resp := env.login("admin@gruposabor.com", "AdminPass123!")
+	resp.Body.Close()
+	waiterToken, _, resp := env.login("waiter@gruposabor.com", "WaiterPass123!")
+	resp.Body.Close()
+
+	wantError := func(resp *http.Response, status int, code string) {
+		t.Helper()
+		if resp.StatusCode != status {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, status)
+		}
+		var body handler.ErrorResponse
+		decodeBody(t, resp, &body)
+		if body.Error.Code != code {
+			t.Errorf("error code = %q, want %q", body.Error.Code, code)
+		}
+	}
+
+	wantError(env.do(http.MethodPost, "/sso/start", "", handler.SSOStartRequest{TenantSlug: "grupo-sabor"}), http.StatusNotFound, "sso_not_configured")
+	wantError(env.do(http.MethodGet, "/sso/config", adminToken, nil), http.StatusNotFound, "sso_not_configured")
+
+	// Only admins configure SSO, and only against a reachable https issuer
+	config := handler.SSOConfigRequest{
+		Issuer:                idp.Issuer(),
+		ClientID:              "erp-client",
+		ClientSecret:          "erp-secret",
+		AllowedDomains:        []string{"gruposabor.com"},
+		PasswordLoginDisabled: true,
+	}
+	wantError(env.do(http.MethodPut, "/sso/config", waiterToken, config), http.StatusForbidden, "insufficient_role")
+	badIssuer := config
+	badIssuer.Issuer = "http://idp.gruposabor.com"
+	wantError(env.do(http.MethodPut, "/sso/config", adminToken, badIssuer), http.StatusBadRequest, "sso_config_invalid")
+
+	putResp := env.do(http.MethodPut, "/sso/config", adminToken, config)
+	if putResp.StatusCode != http.StatusOK {
+		t.Fatalf("put config status = %d, want %d", putResp.StatusCode, http.StatusOK)
+	}
+	var saved handler.SSOConfigResponse
+	decodeBody(t, putResp, &saved)
+	if saved.Issuer != idp.Issuer() || !saved.ClientSecretSet || !saved.PasswordLoginDisabled || saved.RedirectURI != e2eSSORedirectURL {
+		t.Errorf("saved config = %+v", saved)
+	}
+
+	// Password login is off for staff, but the owner keeps it
+	wantError(env.do(http.MethodPost, "/login", "", map[string]string{"email": "waiter@gruposabor.com", "password": "WaiterPass123!"}),
+		http.StatusForbidden, "password_login_disabled")
+	ownerToken, _, resp := env.login("owner@gruposabor.com", "OwnerPass123!")
+	resp.Body.Close()
+	if ownerToken == "" {
+		t.Fatalf("owner password login status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	// The waiter signs in through the IdP and is linked to their account
+	ssoResp := env.ssoLogin(idp, "grupo-sabor", oidctest.Identity{Subject: "sabor-1042", Email: "waiter@gruposabor.com", EmailVerified: true, Name: "Luis"})
+	if ssoResp.StatusCode != http.StatusOK {
+		t.Fatalf("sso callback status = %d, want %d", ssoResp.StatusCode, http.StatusOK)
+	}
+	var login handler.LoginResponse
+	decodeBody(t, ssoResp, &login)
+	if login.User.ID != waiter.ID || login.AccessToken == "" {
+		t.Fatalf("sso login = %+v, want the waiter signed in", login.User)
+	}
+	meResp := env.do(http.MethodGet, "/me", login.AccessToken, nil)
+	var me handler.MeResponse
+	decodeBody(t, meResp, &me)
+	if me.ID != waiter.ID || me.TenantID != tenant.ID {
+		t.Errorf("/me = %+v, want the waiter in the tenant", me)
+	}
+	if ids := env.identities.Identities(); len(ids) != 1 || ids[0].UserID != waiter.ID || ids[0].Subject != "sabor-1042" {
+		t.Errorf("identities = %+v, want the waiter linked", ids)
+	}
+
+	// No account is created for strangers, even from an allowed domain
+	wantError(env.ssoLogin(idp, "grupo-sabor", oidctest.Identity{Subject: "sabor-2001", Email: "new@gruposabor.com", EmailVerified: true}),
+		http.StatusForbidden, "sso_account_not_found")
+	wantError(env.ssoLogin(idp, "grupo-sabor", oidctest.Identity{Subject: "sabor-2002", Email: "waiter@gruposabor.com"}),
+		http.StatusForbidden, "sso_email_not_allowed")
+	wantError(env.do(http.MethodPost, "/sso/callback", "", handler.SSOCallbackRequest{State: "forged", Code: "forged"}),
+		http.StatusBadRequest, "sso_state_invalid")
+
+	// The secret isn't returned, and updates can leave it out
+	config.ClientSecret = ""
+	config.PasswordLoginDisabled = false
+	putResp = env.do(http.MethodPut, "/sso/config", adminToken, config)
+	decodeBody(t, putResp, &saved)
+	if !saved.ClientSecretSet || saved.PasswordLoginDisabled {
+		t.Errorf("updated config = %+v, want the secret kept and password login on", saved)
+	}
+
+	// Removing SSO ends the SSO sign-in but leaves the links in place
+	delResp := env.do(http.MethodDelete, "/sso/config", ownerToken, nil)
+	delResp.Body.Close()
+	if delResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete config status = %d, want %d", delResp.StatusCode, http.StatusNoContent)
+	}
+	wantError(env.do(http.MethodPost, "/sso/start", "", handler.SSOStartRequest{TenantSlug: "grupo-sabor"}), http.StatusNotFound, "sso_not_configured")
+	if len(env.identities.Identities()) != 1 {
+		t.Error("identity links should outlive the configuration")
+	}
+
+	var configEvents int
+	for _, e := range env.eventRepo.GetEvents() {
+		switch e.EventType {
+		case domain.EventSSOConfigUpdated, domain.EventSSOConfigDeleted, domain.EventSSOIdentityLinked:
+			configEvents++
+		}
+	}
+	if configEvents != 4 {
+		t.Errorf("sso audit events = %d, want 2 updates, 1 link and 1 delete", configEvents)
+	}
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
+)
+
+// TestE2E_SwitchTenant covers a user who works at two restaurants moving
+// from one to the other without logging in again.
+func TestE2E_SwitchTenant(t *testing.T) {
+	env := setupE2E(t)
+	diner := env.seedTenant("Acme Diner", "acme-diner")
+	bistro := env.seedTenant("Acme Bistro", "acme-bistro")
+	user := env.seedUser("staff@example.com", "Password123!", diner.ID, domain.RoleWaiter)
+	env.roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: user.ID, TenantID: bistro.ID, Role: domain.RoleCashier})
+
+	loginResp := env.do(http.MethodPost, "/login", "", handler.LoginRequest{Email: "staff@example.com", Password: "Password123!", TenantID: &diner.ID})
+	if loginResp.StatusCode != http.StatusOK {
+		t.Fatalf("login status = %d, want %d", loginResp.StatusCode, http.StatusOK)
+	}
+	var login handler.LoginResponse
+	decodeBody(t, loginResp, &login)
+
+	switchResp := env.do(http.MethodPost, "/switch-tenant", login.AccessToken, handler.SwitchTenantRequest{TenantID: bistro.ID})
+	if switchResp.StatusCode != http.StatusOK {
+		t.Fatalf("switch-tenant status = %d, want %d", switchResp.StatusCode, http.StatusOK)
+	}
+	var switched handler.LoginResponse
+	decodeBody(t, switchResp, &switched)
+
+	meResp := env.do(http.MethodGet, "/me", switched.AccessToken, nil)
+	if meResp.StatusCode != http.StatusOK {
+		t.Fatalf("/me status = %d, want %d", meResp.StatusCode, http.StatusOK)
+	}
+	var me handler.MeResponse
+	decodeBody(t, meResp, &me)
+	if me.TenantID != bistro.ID || me.Role != string(domain.RoleCashier) {
+		t.Errorf("/me tenant/role = %s/%s, want %s/cashier", me.TenantID, me.Role, bistro.ID)
+	}
+
+	// The diner token and refresh token no longer work
+	oldMe := env.do(http.MethodGet, "/me", login.AccessToken, nil)
+	if oldMe.StatusCode != http.StatusUnauthorized {
+		t.Errorf("/me with pre-switch token status = %d, want %d", oldMe.StatusCode, http.StatusUnauthorized)
+	}
+	oldMe.Body.Close()
+	refreshResp := env.do(http.MethodPost, "/refresh", "", handler.RefreshRequest{RefreshToken: login.RefreshToken})
+	if refreshResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("refresh with pre-switch token status = %d, want %d", refreshResp.StatusCode, http.StatusUnauthorized)
+	}
+	refreshResp.Body.Close()
+}
@@ -78,7 +78,7 @@ func TestE2E_LoginUseRefreshLogout(t *testing.T) {
 	tenantRepo.AddTenant(&domain.Tenant{ID: tenantID, Name: "Acme", Slug: "acme", IsActive: true})
 
 	mux := chi.NewRouter()
-	mux.Mount("/", Router(authSvc, userSvc))
+	mux.Mount("/", Router(authSvc, userSvc, nil, nil, nil, nil, nil, nil, nil, nil))
 	mux.Mount("/users", UserRouter(authSvc, userSvc))
 
 	srv := httptest.NewServer(mux)
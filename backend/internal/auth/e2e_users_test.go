@@ -1,17 +1,22 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 	"testing"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/solobueno/erp/internal/auth/domain"
 	"github.com/solobueno/erp/internal/auth/handler"
+	"github.com/solobueno/erp/pkg/jwt"
 )
 
 // TestE2E_ManagerCreatesAndManagesStaff drives the full staff lifecycle over
-// real HTTP: an owner creates a waiter, the new hire logs in with the
-// emailed temp password and sets their own, then the owner lists, reads,
-// updates, and promotes them.
+// real HTTP: an owner invites a waiter, the new hire accepts the emailed
+// invitation with a password of their own and logs in, then the owner
+// lists, reads, updates, and promotes them.
 func TestE2E_ManagerCreatesAndManagesStaff(t *testing.T) {
 	env := setupE2E(t)
 	tenant := env.seedTenant("Acme Diner", "acme-diner")
@@ -23,25 +28,50 @@ func TestE2E_ManagerCreatesAndManagesStaff(t *testing.T) {
 		t.Fatal("owner login did not return an access token")
 	}
 
-	// 1. Create a new staff account.
-	createResp := env.do(http.MethodPost, "/users", ownerToken, handler.CreateUserRequest{
+	// 1. Invite a new staff member.
+	inviteResp := env.do(http.MethodPost, "/users/invitations", ownerToken, handler.InviteUserRequest{
 		Email: "waiter@example.com", FirstName: "New", LastName: "Hire", Role: domain.RoleWaiter,
 	})
-	if createResp.StatusCode != http.StatusCreated {
-		t.Fatalf("create status = %d, want %d", createResp.StatusCode, http.StatusCreated)
+	if inviteResp.StatusCode != http.StatusCreated {
+		t.Fatalf("invite status = %d, want %d", inviteResp.StatusCode, http.StatusCreated)
 	}
-	var created handler.CreateUserResponse
-	decodeBody(t, createResp, &created)
-	if created.MustResetPassword != true || created.LinkedExistingAccount {
-		t.Errorf("unexpected create response: %+v", created)
+	var invited handler.InvitationResponse
+	decodeBody(t, inviteResp, &invited)
+	if invited.ExistingAccount || invited.Expired {
+		t.Errorf("unexpected invite response: %+v", invited)
 	}
 
-	// 2. The temp password only ever reached the user via email, never the
+	// 2. The invite token only ever reached the invitee via email, never the
 	// API response - retrieve it from the capturing emailer.
-	tempPassword := env.emailer.tempPasswordFor(t, "waiter@example.com")
+	invite := env.emailer.inviteFor(t, "waiter@example.com")
+	if invite.tenantID != tenant.ID || invite.existingAccount {
+		t.Errorf("unexpected invitation email: %+v", invite)
+	}
+
+	// 3. The new hire accepts, choosing their own password.
+	acceptResp := env.do(http.MethodPost, "/invitations/accept", "", handler.AcceptInvitationRequest{
+		Token: invite.token, Password: "StaffChosen123!",
+	})
+	if acceptResp.StatusCode != http.StatusCreated {
+		t.Fatalf("accept status = %d, want %d", acceptResp.StatusCode, http.StatusCreated)
+	}
+	var accepted handler.AcceptInvitationResponse
+	decodeBody(t, acceptResp, &accepted)
+	if accepted.User.MustResetPassword {
+		t.Error("an invited user chose their own password and must not be forced to reset it")
+	}
 
-	// 3. New hire logs in with the temp password.
-	staffToken, _, loginResp := env.login("waiter@example.com", tempPassword)
+	// 4. The invitation is single use.
+	reuseResp := env.do(http.MethodPost, "/invitations/accept", "", handler.AcceptInvitationRequest{
+		Token: invite.token, Password: "Another123!",
+	})
+	if reuseResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("reused invite status = %d, want %d", reuseResp.StatusCode, http.StatusBadRequest)
+	}
+	reuseResp.Body.Close()
+
+	// 5. New hire logs in with the password they chose.
+	staffToken, _, loginResp := env.login("waiter@example.com", "StaffChosen123!")
 	if loginResp.StatusCode != http.StatusOK {
 		t.Fatalf("staff login status = %d, want %d", loginResp.StatusCode, http.StatusOK)
 	}
@@ -50,16 +80,8 @@ func TestE2E_ManagerCreatesAndManagesStaff(t *testing.T) {
 		t.Fatal("staff login did not return an access token")
 	}
 
-	// 4. New hire sets their own password (required before must_reset_password clears).
-	changeResp := env.do(http.MethodPost, "/change-password", staffToken, handler.ChangePasswordRequest{
-		CurrentPassword: tempPassword, NewPassword: "StaffChosen123!",
-	})
-	if changeResp.StatusCode != http.StatusOK {
-		t.Fatalf("change-password status = %d, want %d", changeResp.StatusCode, http.StatusOK)
-	}
-	changeResp.Body.Close()
-
-	// 5. Owner lists tenant users and finds the new hire with the right role.
+	// 6. Owner lists tenant users and finds the new hire with the right role,
+	// and the invitation is no longer pending.
 	listResp := env.do(http.MethodGet, "/users", ownerToken, nil)
 	if listResp.StatusCode != http.StatusOK {
 		t.Fatalf("list status = %d, want %d", listResp.StatusCode, http.StatusOK)
@@ -78,8 +100,14 @@ func TestE2E_ManagerCreatesAndManagesStaff(t *testing.T) {
 	if staffID == "" {
 		t.Fatalf("new hire not found in tenant user list: %+v", list.Data)
 	}
+	pendingResp := env.do(http.MethodGet, "/users/invitations", ownerToken, nil)
+	var pending handler.InvitationListResponse
+	decodeBody(t, pendingResp, &pending)
+	if len(pending.Data) != 0 {
+		t.Errorf("accepted invitation should not be pending: %+v", pending.Data)
+	}
 
-	// 6. Owner reads the user directly.
+	// 7. Owner reads the user directly.
 	getResp := env.do(http.MethodGet, "/users/"+staffID, ownerToken, nil)
 	if getResp.StatusCode != http.StatusOK {
 		t.Fatalf("get status = %d, want %d", getResp.StatusCode, http.StatusOK)
@@ -90,7 +118,7 @@ func TestE2E_ManagerCreatesAndManagesStaff(t *testing.T) {
 		t.Errorf("unexpected get response: %+v", got)
 	}
 
-	// 7. Owner updates the new hire's name.
+	// 8. Owner updates the new hire's name.
 	newName := "Updated"
 	updateResp := env.do(http.MethodPatch, "/users/"+staffID, ownerToken, handler.UpdateUserRequest{FirstName: &newName})
 	if updateResp.StatusCode != http.StatusOK {
@@ -102,7 +130,7 @@ func TestE2E_ManagerCreatesAndManagesStaff(t *testing.T) {
 		t.Errorf("FirstName = %q, want %q", updated.FirstName, "Updated")
 	}
 
-	// 8. Owner promotes the new hire to cashier.
+	// 9. Owner promotes the new hire to cashier.
 	roleResp := env.do(http.MethodPatch, "/users/"+staffID+"/role", ownerToken, handler.UpdateRoleRequest{Role: domain.RoleCashier})
 	if roleResp.StatusCode != http.StatusOK {
 		t.Fatalf("role update status = %d, want %d", roleResp.StatusCode, http.StatusOK)
@@ -113,7 +141,7 @@ func TestE2E_ManagerCreatesAndManagesStaff(t *testing.T) {
 		t.Errorf("Role after promotion = %q, want %q", promoted.Role, domain.RoleCashier)
 	}
 
-	// 9. The promotion is durable - re-reading shows cashier, not waiter.
+	// 10. The promotion is durable - re-reading shows cashier, not waiter.
 	getResp2 := env.do(http.MethodGet, "/users/"+staffID, ownerToken, nil)
 	var got2 handler.UserResponse
 	decodeBody(t, getResp2, &got2)
@@ -122,55 +150,73 @@ func TestE2E_ManagerCreatesAndManagesStaff(t *testing.T) {
 	}
 }
 
-// TestE2E_CreateUser_LinksExistingAccountAcrossTenants covers FR-012: an
-// email that already has a global account in a different tenant gets a new
-// role linked to it instead of a duplicate account/password, and the same
-// email colliding within the SAME tenant is still rejected.
-func TestE2E_CreateUser_LinksExistingAccountAcrossTenants(t *testing.T) {
+// TestE2E_Invitation_ExistingAccountJoinsTenant covers FR-012: an email that
+// already has a global account in a different tenant is invited to join
+// rather than getting a duplicate account/password, and is only added once
+// they confirm with that account's password. The same email colliding
+// within the SAME tenant is still rejected.
+func TestE2E_Invitation_ExistingAccountJoinsTenant(t *testing.T) {
 	env := setupE2E(t)
 	tenantA := env.seedTenant("Tenant A", "tenant-a")
 	tenantB := env.seedTenant("Tenant B", "tenant-b")
 	env.seedUser("admin-a@example.com", "AdminPass123!", tenantA.ID, domain.RoleOwner)
 	env.seedUser("admin-b@example.com", "AdminPass123!", tenantB.ID, domain.RoleOwner)
+	shared := env.seedUser("shared@example.com", "SharedPass123!", tenantA.ID, domain.RoleWaiter)
 
 	adminAToken, _, r1 := env.login("admin-a@example.com", "AdminPass123!")
 	r1.Body.Close()
 	adminBToken, _, r2 := env.login("admin-b@example.com", "AdminPass123!")
 	r2.Body.Close()
 
-	// Admin A creates a shared-email user in tenant A.
-	createResp := env.do(http.MethodPost, "/users", adminAToken, handler.CreateUserRequest{
-		Email: "shared@example.com", FirstName: "Shared", LastName: "Person", Role: domain.RoleWaiter,
+	// Admin B invites the SAME email to tenant B - it is an invitation to
+	// join, not a silent link.
+	inviteResp := env.do(http.MethodPost, "/users/invitations", adminBToken, handler.InviteUserRequest{
+		Email: "shared@example.com", FirstName: "Shared", LastName: "Person", Role: domain.RoleManager,
 	})
-	if createResp.StatusCode != http.StatusCreated {
-		t.Fatalf("initial create status = %d, want %d", createResp.StatusCode, http.StatusCreated)
+	if inviteResp.StatusCode != http.StatusCreated {
+		t.Fatalf("invite status = %d, want %d", inviteResp.StatusCode, http.StatusCreated)
+	}
+	var invited handler.InvitationResponse
+	decodeBody(t, inviteResp, &invited)
+	if !invited.ExistingAccount {
+		t.Errorf("expected existing_account=true, got %+v", invited)
+	}
+	invite := env.emailer.inviteFor(t, "shared@example.com")
+	if !invite.existingAccount {
+		t.Error("invitation email should ask the invitee to confirm their existing account")
 	}
-	var created handler.CreateUserResponse
-	decodeBody(t, createResp, &created)
 
-	// Admin B creates a user with the SAME email for tenant B - must link,
-	// not duplicate.
-	linkResp := env.do(http.MethodPost, "/users", adminBToken, handler.CreateUserRequest{
-		Email: "shared@example.com", FirstName: "Shared", LastName: "Person", Role: domain.RoleManager,
-	})
-	if linkResp.StatusCode != http.StatusOK {
-		t.Fatalf("linking create status = %d, want %d", linkResp.StatusCode, http.StatusOK)
+	// Nothing is linked until the invitee accepts: still a single-tenant login.
+	_, _, loginOneTenant := env.login("shared@example.com", "SharedPass123!")
+	if loginOneTenant.StatusCode != http.StatusOK {
+		t.Fatalf("login before accepting status = %d, want %d", loginOneTenant.StatusCode, http.StatusOK)
 	}
-	var linked handler.CreateUserResponse
-	decodeBody(t, linkResp, &linked)
-	if !linked.LinkedExistingAccount {
-		t.Errorf("expected linked_existing_account=true, got %+v", linked)
+	loginOneTenant.Body.Close()
+
+	// Accepting requires the existing account's password, not a new one.
+	wrongResp := env.do(http.MethodPost, "/invitations/accept", "", handler.AcceptInvitationRequest{
+		Token: invite.token, Password: "BrandNew123!",
+	})
+	if wrongResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("accept with a new password status = %d, want %d", wrongResp.StatusCode, http.StatusUnauthorized)
 	}
-	if linked.ID != created.ID {
-		t.Errorf("linked user ID = %s, want the original user's ID %s", linked.ID, created.ID)
+	wrongResp.Body.Close()
+
+	acceptResp := env.do(http.MethodPost, "/invitations/accept", "", handler.AcceptInvitationRequest{
+		Token: invite.token, Password: "SharedPass123!",
+	})
+	if acceptResp.StatusCode != http.StatusOK {
+		t.Fatalf("accept status = %d, want %d", acceptResp.StatusCode, http.StatusOK)
 	}
-	if !env.emailer.hasTenantLinkNotification("shared@example.com") {
-		t.Error("expected the existing user to be notified of the new tenant link")
+	var accepted handler.AcceptInvitationResponse
+	decodeBody(t, acceptResp, &accepted)
+	if !accepted.ExistingAccount || accepted.User.ID != shared.ID {
+		t.Errorf("expected the existing account to join tenant B, got %+v", accepted)
 	}
 
-	// Admin A tries to add the SAME email to tenant A again - real conflict,
+	// Admin A tries to invite the SAME email to tenant A - real conflict,
 	// must still be rejected.
-	dupResp := env.do(http.MethodPost, "/users", adminAToken, handler.CreateUserRequest{
+	dupResp := env.do(http.MethodPost, "/users/invitations", adminAToken, handler.InviteUserRequest{
 		Email: "shared@example.com", FirstName: "Shared", LastName: "Person", Role: domain.RoleCashier,
 	})
 	if dupResp.StatusCode != http.StatusBadRequest {
@@ -178,14 +224,74 @@ func TestE2E_CreateUser_LinksExistingAccountAcrossTenants(t *testing.T) {
 	}
 	dupResp.Body.Close()
 
-	// The linked user can log in and see both tenants when they select one.
-	_, _, loginNoTenant := env.login("shared@example.com", env.emailer.tempPasswordFor(t, "shared@example.com"))
+	// The user now belongs to both tenants and must pick one at login.
+	_, _, loginNoTenant := env.login("shared@example.com", "SharedPass123!")
 	if loginNoTenant.StatusCode != http.StatusBadRequest {
 		t.Errorf("multi-tenant login without tenant_id status = %d, want %d (tenant_required)", loginNoTenant.StatusCode, http.StatusBadRequest)
 	}
 	loginNoTenant.Body.Close()
 }
 
+// TestE2E_Invitation_ResendAndRevoke covers managing a pending invitation:
+// resending invalidates the previous link, and a revoked invitation can no
+// longer be accepted.
+func TestE2E_Invitation_ResendAndRevoke(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("manager@example.com", "Password123!", tenant.ID, domain.RoleManager)
+
+	managerToken, _, resp := env.login("manager@example.com", "Password123!")
+	resp.Body.Close()
+
+	inviteResp := env.do(http.MethodPost, "/users/invitations", managerToken, handler.InviteUserRequest{
+		Email: "cook@example.com", FirstName: "Line", LastName: "Cook", Role: domain.RoleKitchen,
+	})
+	var invited handler.InvitationResponse
+	decodeBody(t, inviteResp, &invited)
+	firstToken := env.emailer.inviteFor(t, "cook@example.com").token
+
+	// A second invite for the same email is refused while one is pending
+	dupResp := env.do(http.MethodPost, "/users/invitations", managerToken, handler.InviteUserRequest{
+		Email: "cook@example.com", FirstName: "Line", LastName: "Cook", Role: domain.RoleKitchen,
+	})
+	if dupResp.StatusCode != http.StatusConflict {
+		t.Fatalf("duplicate invite status = %d, want %d", dupResp.StatusCode, http.StatusConflict)
+	}
+	dupResp.Body.Close()
+
+	resendResp := env.do(http.MethodPost, "/users/invitations/"+invited.ID.String()+"/resend", managerToken, nil)
+	if resendResp.StatusCode != http.StatusOK {
+		t.Fatalf("resend status = %d, want %d", resendResp.StatusCode, http.StatusOK)
+	}
+	resendResp.Body.Close()
+	secondToken := env.emailer.inviteFor(t, "cook@example.com").token
+	if secondToken == firstToken {
+		t.Fatal("resend should email a new token")
+	}
+
+	oldResp := env.do(http.MethodPost, "/invitations/accept", "", handler.AcceptInvitationRequest{Token: firstToken, Password: "CookPass123!"})
+	var errResp handler.ErrorResponse
+	decodeBody(t, oldResp, &errResp)
+	if oldResp.StatusCode != http.StatusBadRequest || errResp.Error.Code != "token_invalid" {
+		t.Errorf("old token = %d %q, want 400 token_invalid", oldResp.StatusCode, errResp.Error.Code)
+	}
+
+	revokeResp := env.do(http.MethodDelete, "/users/invitations/"+invited.ID.String(), managerToken, nil)
+	if revokeResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("revoke status = %d, want %d", revokeResp.StatusCode, http.StatusNoContent)
+	}
+	revokeResp.Body.Close()
+
+	revokedResp := env.do(http.MethodPost, "/invitations/accept", "", handler.AcceptInvitationRequest{Token: secondToken, Password: "CookPass123!"})
+	decodeBody(t, revokedResp, &errResp)
+	if revokedResp.StatusCode != http.StatusBadRequest || errResp.Error.Code != "invitation_revoked" {
+		t.Errorf("revoked token = %d %q, want 400 invitation_revoked", revokedResp.StatusCode, errResp.Error.Code)
+	}
+	if _, _, loginResp := env.login("cook@example.com", "CookPass123!"); loginResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("login after revoked invite status = %d, want %d", loginResp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
 // TestE2E_AccountLockoutAndUnlock covers FR-011a end to end: 5 failed
 // attempts locks the account (423) independent of the correct password,
 // and a Manager+ can clear the lockout via POST /users/{id}/unlock.
@@ -225,3 +331,284 @@ func TestE2E_AccountLockoutAndUnlock(t *testing.T) {
 	}
 	afterUnlock.Body.Close()
 }
+
+// TestE2E_LockoutPolicyAndUnlockByEmail covers the tenant lockout policy
+// over real HTTP: an admin lowers the threshold, guessing from an unknown
+// device locks those logins but not the device the member logged in from
+// before, and the member lifts the lock with an emailed unlock link.
+func TestE2E_LockoutPolicyAndUnlockByEmail(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("admin@example.com", "AdminPass123!", tenant.ID, domain.RoleAdmin)
+	staff := env.seedUser("staff@example.com", "StaffPass123!", tenant.ID, domain.RoleWaiter)
+	verifiedAt := time.Now()
+	staff.EmailVerifiedAt = &verifiedAt
+
+	adminAccess, _, adminLogin := env.login("admin@example.com", "AdminPass123!")
+	adminLogin.Body.Close()
+	putResp := env.do(http.MethodPut, "/lockout-policy", adminAccess, handler.LockoutPolicyRequest{
+		UnknownDeviceThreshold: 3, KnownDeviceThreshold: 10, BaseDelaySeconds: 300, MaxDelaySeconds: 3600,
+	})
+	if putResp.StatusCode != http.StatusOK {
+		t.Fatalf("put policy status = %d, want %d", putResp.StatusCode, http.StatusOK)
+	}
+	putResp.Body.Close()
+
+	staffLogin := env.do(http.MethodPost, "/login", "", handler.LoginRequest{Email: "staff@example.com", Password: "StaffPass123!"})
+	var staffSession handler.LoginResponse
+	decodeBody(t, staffLogin, &staffSession)
+	if staffSession.DeviceToken == "" {
+		t.Fatal("expected a device token on login")
+	}
+
+	for i := 0; i < 3; i++ {
+		resp := env.do(http.MethodPost, "/login", "", handler.LoginRequest{Email: "staff@example.com", Password: "WrongPassword!"})
+		resp.Body.Close()
+	}
+	lockedResp := env.do(http.MethodPost, "/login", "", handler.LoginRequest{Email: "staff@example.com", Password: "StaffPass123!"})
+	if lockedResp.StatusCode != http.StatusLocked {
+		t.Fatalf("login from an unknown device status = %d, want %d", lockedResp.StatusCode, http.StatusLocked)
+	}
+	lockedResp.Body.Close()
+
+	knownResp := env.do(http.MethodPost, "/login", "", handler.LoginRequest{Email: "staff@example.com", Password: "StaffPass123!", DeviceToken: staffSession.DeviceToken})
+	if knownResp.StatusCode != http.StatusOK {
+		t.Fatalf("login from the known device status = %d, want %d", knownResp.StatusCode, http.StatusOK)
+	}
+	knownResp.Body.Close()
+
+	// Lock again, then unlock by email
+	for i := 0; i < 3; i++ {
+		resp := env.do(http.MethodPost, "/login", "", handler.LoginRequest{Email: "staff@example.com", Password: "WrongPassword!"})
+		resp.Body.Close()
+	}
+	requestResp := env.do(http.MethodPost, "/unlock/request", "", handler.AccountUnlockRequest{Email: "staff@example.com"})
+	if requestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("unlock request status = %d, want %d", requestResp.StatusCode, http.StatusAccepted)
+	}
+	requestResp.Body.Close()
+
+	unlockResp := env.do(http.MethodPost, "/unlock", "", handler.EmailTokenRequest{Token: env.emailer.emailTokenFor(t, "staff@example.com")})
+	if unlockResp.StatusCode != http.StatusOK {
+		t.Fatalf("unlock status = %d, want %d", unlockResp.StatusCode, http.StatusOK)
+	}
+	unlockResp.Body.Close()
+
+	_, _, afterUnlock := env.login("staff@example.com", "StaffPass123!")
+	if afterUnlock.StatusCode != http.StatusOK {
+		t.Fatalf("post-unlock login status = %d, want %d", afterUnlock.StatusCode, http.StatusOK)
+	}
+	afterUnlock.Body.Close()
+}
+
+// TestE2E_RemoveFromTenant covers offboarding an employee from one
+// restaurant while they keep working at another.
+func TestE2E_RemoveFromTenant(t *testing.T) {
+	env := setupE2E(t)
+	diner := env.seedTenant("Acme Diner", "acme-diner")
+	bistro := env.seedTenant("Acme Bistro", "acme-bistro")
+	owner := env.seedUser("owner@example.com", "OwnerPass123!", diner.ID, domain.RoleOwner)
+	staff := env.seedUser("staff@example.com", "StaffPass123!", diner.ID, domain.RoleWaiter)
+	env.roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: staff.ID, TenantID: bistro.ID, Role: domain.RoleCashier})
+
+	ownerToken, _, ownerLogin := env.login("owner@example.com", "OwnerPass123!")
+	ownerLogin.Body.Close()
+
+	staffLogin := env.do(http.MethodPost, "/login", "", handler.LoginRequest{Email: "staff@example.com", Password: "StaffPass123!", TenantID: &diner.ID})
+	if staffLogin.StatusCode != http.StatusOK {
+		t.Fatalf("staff login status = %d, want %d", staffLogin.StatusCode, http.StatusOK)
+	}
+	var staffSession handler.LoginResponse
+	decodeBody(t, staffLogin, &staffSession)
+
+	removeResp := env.do(http.MethodDelete, "/users/"+staff.ID.String()+"/tenants/"+diner.ID.String(), ownerToken, nil)
+	if removeResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("remove status = %d, want %d", removeResp.StatusCode, http.StatusNoContent)
+	}
+	removeResp.Body.Close()
+
+	// The diner session is gone
+	meResp := env.do(http.MethodGet, "/me", staffSession.AccessToken, nil)
+	if meResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("/me after removal status = %d, want %d", meResp.StatusCode, http.StatusUnauthorized)
+	}
+	meResp.Body.Close()
+	refreshResp := env.do(http.MethodPost, "/refresh", "", handler.RefreshRequest{RefreshToken: staffSession.RefreshToken})
+	if refreshResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("refresh after removal status = %d, want %d", refreshResp.StatusCode, http.StatusUnauthorized)
+	}
+	refreshResp.Body.Close()
+
+	// The account still works at the bistro, now the only tenant
+	bistroLogin := env.do(http.MethodPost, "/login", "", map[string]string{"email": "staff@example.com", "password": "StaffPass123!"})
+	if bistroLogin.StatusCode != http.StatusOK {
+		t.Fatalf("post-removal login status = %d, want %d", bistroLogin.StatusCode, http.StatusOK)
+	}
+	var bistroSession handler.LoginResponse
+	decodeBody(t, bistroLogin, &bistroSession)
+	if bistroSession.User.TenantID != bistro.ID {
+		t.Errorf("post-removal login tenant = %s, want %s", bistroSession.User.TenantID, bistro.ID)
+	}
+
+	// The owner can't leave a tenant they are the only owner of
+	lastOwnerResp := env.do(http.MethodDelete, "/users/"+owner.ID.String()+"/tenants/"+diner.ID.String(), ownerToken, nil)
+	if lastOwnerResp.StatusCode != http.StatusConflict {
+		t.Errorf("last owner removal status = %d, want %d", lastOwnerResp.StatusCode, http.StatusConflict)
+	}
+	lastOwnerResp.Body.Close()
+}
+
+func TestE2E_OwnershipTransfer(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	owner := env.seedUser("owner@example.com", "OwnerPass123!", tenant.ID, domain.RoleOwner)
+	manager := env.seedUser("manager@example.com", "ManagerPass123!", tenant.ID, domain.RoleManager)
+
+	ownerToken, _, ownerLogin := env.login("owner@example.com", "OwnerPass123!")
+	ownerLogin.Body.Close()
+
+	startResp := env.do(http.MethodPost, "/users/ownership-transfer", ownerToken, handler.StartOwnershipTransferRequest{UserID: manager.ID})
+	if startResp.StatusCode != http.StatusCreated {
+		t.Fatalf("start transfer status = %d, want %d", startResp.StatusCode, http.StatusCreated)
+	}
+	startResp.Body.Close()
+
+	// Roles don't change until the recipient confirms
+	managerToken, _, managerLogin := env.login("manager@example.com", "ManagerPass123!")
+	managerLogin.Body.Close()
+	demoteResp := env.do(http.MethodPatch, "/users/"+owner.ID.String()+"/role", managerToken, handler.UpdateRoleRequest{Role: "manager"})
+	if demoteResp.StatusCode != http.StatusForbidden {
+		t.Errorf("manager demoting owner status = %d, want %d", demoteResp.StatusCode, http.StatusForbidden)
+	}
+	demoteResp.Body.Close()
+
+	token := env.emailer.transferTokenFor(t, "manager@example.com")
+	badResp := env.do(http.MethodPost, "/ownership-transfer/confirm", "", handler.ConfirmOwnershipTransferRequest{Token: token, Password: "OwnerPass123!"})
+	if badResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("confirm with wrong password status = %d, want %d", badResp.StatusCode, http.StatusUnauthorized)
+	}
+	badResp.Body.Close()
+
+	confirmResp := env.do(http.MethodPost, "/ownership-transfer/confirm", "", handler.ConfirmOwnershipTransferRequest{Token: token, Password: "ManagerPass123!"})
+	if confirmResp.StatusCode != http.StatusOK {
+		t.Fatalf("confirm status = %d, want %d", confirmResp.StatusCode, http.StatusOK)
+	}
+	var transfer handler.OwnershipTransferResponse
+	decodeBody(t, confirmResp, &transfer)
+	if transfer.PreviousOwnerRole != string(domain.RoleManager) {
+		t.Errorf("previous owner role = %q, want %q", transfer.PreviousOwnerRole, domain.RoleManager)
+	}
+
+	if !env.emailer.hasTransferNotice("owner@example.com") || !env.emailer.hasTransferNotice("manager@example.com") {
+		t.Error("expected both parties to be notified of the transfer")
+	}
+
+	// Both parties' tokens carried the old roles and are revoked
+	for name, tok := range map[string]string{"owner": ownerToken, "manager": managerToken} {
+		resp := env.do(http.MethodGet, "/me", tok, nil)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s /me with pre-transfer token status = %d, want %d", name, resp.StatusCode, http.StatusUnauthorized)
+		}
+		resp.Body.Close()
+	}
+
+	waitForNextSecond()
+	newOwnerToken, _, newOwnerLogin := env.login("manager@example.com", "ManagerPass123!")
+	newOwnerLogin.Body.Close()
+	meResp := env.do(http.MethodGet, "/me", newOwnerToken, nil)
+	if meResp.StatusCode != http.StatusOK {
+		t.Fatalf("/me status = %d, want %d", meResp.StatusCode, http.StatusOK)
+	}
+	var me handler.MeResponse
+	decodeBody(t, meResp, &me)
+	if me.Role != string(domain.RoleOwner) {
+		t.Errorf("new owner role = %q, want %q", me.Role, domain.RoleOwner)
+	}
+
+	// The new owner is now the only owner and can't step away, but can
+	// remove the previous owner, who is a manager now
+	lastOwnerResp := env.do(http.MethodDelete, "/users/"+manager.ID.String()+"/tenants/"+tenant.ID.String(), newOwnerToken, nil)
+	if lastOwnerResp.StatusCode != http.StatusConflict {
+		t.Errorf("last owner removal status = %d, want %d", lastOwnerResp.StatusCode, http.StatusConflict)
+	}
+	lastOwnerResp.Body.Close()
+	removeResp := env.do(http.MethodDelete, "/users/"+owner.ID.String()+"/tenants/"+tenant.ID.String(), newOwnerToken, nil)
+	if removeResp.StatusCode != http.StatusNoContent {
+		t.Errorf("remove previous owner status = %d, want %d", removeResp.StatusCode, http.StatusNoContent)
+	}
+	removeResp.Body.Close()
+}
+
+// TestE2E_RoleElevation covers a waiter covering as cashier for a shift: the
+// elevation takes effect on their next refresh, and once it has run its
+// course the expirer reverts them and signs out the sessions issued under it.
+func TestE2E_RoleElevation(t *testing.T) {
+	env := setupE2E(t)
+	tenant := env.seedTenant("Acme Diner", "acme-diner")
+	env.seedUser("manager@example.com", "ManagerPass123!", tenant.ID, domain.RoleManager)
+	waiter := env.seedUser("waiter@example.com", "WaiterPass123!", tenant.ID, domain.RoleWaiter)
+
+	managerToken, _, resp := env.login("manager@example.com", "ManagerPass123!")
+	resp.Body.Close()
+	waiterToken, waiterRefresh, resp := env.login("waiter@example.com", "WaiterPass123!")
+	resp.Body.Close()
+
+	elevateResp := env.do(http.MethodPost, "/users/"+waiter.ID.String()+"/role/elevation", managerToken, handler.ElevateRoleRequest{
+		Role:       domain.RoleCashier,
+		ValidUntil: time.Now().Add(8 * time.Hour),
+	})
+	if elevateResp.StatusCode != http.StatusOK {
+		t.Fatalf("elevate status = %d, want %d", elevateResp.StatusCode, http.StatusOK)
+	}
+	var elevated handler.UserResponse
+	decodeBody(t, elevateResp, &elevated)
+	if elevated.Role != "waiter" || elevated.Elevation == nil || elevated.Elevation.Role != "cashier" || !elevated.Elevation.Active {
+		t.Errorf("elevated user = %s with %+v, want waiter with an active cashier elevation", elevated.Role, elevated.Elevation)
+	}
+
+	// The waiter-role token is revoked; refreshing picks up the cashier role
+	meResp := env.do(http.MethodGet, "/me", waiterToken, nil)
+	meResp.Body.Close()
+	if meResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("/me with the pre-elevation token status = %d, want %d", meResp.StatusCode, http.StatusUnauthorized)
+	}
+	refreshResp := env.do(http.MethodPost, "/refresh", "", handler.RefreshRequest{RefreshToken: waiterRefresh})
+	if refreshResp.StatusCode != http.StatusOK {
+		t.Fatalf("refresh status = %d, want %d", refreshResp.StatusCode, http.StatusOK)
+	}
+	var refreshed handler.TokenResponse
+	decodeBody(t, refreshResp, &refreshed)
+	claims, err := jwt.ParseUnverified(refreshed.AccessToken)
+	if err != nil {
+		t.Fatalf("failed to parse access token: %v", err)
+	}
+	if claims.Role != "cashier" {
+		t.Errorf("refreshed token role = %s, want cashier", claims.Role)
+	}
+
+	// The shift is over
+	if _, err := env.userService.ProcessRoleElevations(context.Background(), time.Now().Add(9*time.Hour)); err != nil {
+		t.Fatalf("ProcessRoleElevations failed: %v", err)
+	}
+
+	meResp = env.do(http.MethodGet, "/me", refreshed.AccessToken, nil)
+	meResp.Body.Close()
+	if meResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("/me with the elevated token after expiry status = %d, want %d", meResp.StatusCode, http.StatusUnauthorized)
+	}
+	refreshResp = env.do(http.MethodPost, "/refresh", "", handler.RefreshRequest{RefreshToken: refreshed.RefreshToken})
+	refreshResp.Body.Close()
+	if refreshResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("refresh of the elevated session after expiry status = %d, want %d", refreshResp.StatusCode, http.StatusUnauthorized)
+	}
+
+	waiterToken, _, resp = env.login("waiter@example.com", "WaiterPass123!")
+	resp.Body.Close()
+	claims, err = jwt.ParseUnverified(waiterToken)
+	if err != nil {
+		t.Fatalf("failed to parse access token: %v", err)
+	}
+	if claims.Role != "waiter" {
+		t.Errorf("token role after expiry = %s, want waiter", claims.Role)
+	}
+}
@@ -18,6 +18,7 @@ import (
 type accessLogFields struct {
 	UserID   string
 	TenantID string
+	ActorID  string
 }
 
 type accessLogContextKey struct{}
@@ -34,6 +35,12 @@ func setAccessLogTenantID(ctx context.Context, tenantID string) {
 	}
 }
 
+func setAccessLogActorID(ctx context.Context, actorID string) {
+	if f, ok := ctx.Value(accessLogContextKey{}).(*accessLogFields); ok {
+		f.ActorID = actorID
+	}
+}
+
 // AccessLog logs one structured "request completed" entry per request,
 // regardless of outcome - including expected auth rejections (revoked/
 // expired token, wrong password, insufficient role) that previously
@@ -62,6 +69,9 @@ func AccessLog(next http.Handler) http.Handler {
 		if fields.TenantID != "" {
 			logFields = append(logFields, observability.Field{Key: "tenant_id", Value: fields.TenantID})
 		}
+		if fields.ActorID != "" {
+			logFields = append(logFields, observability.Field{Key: "actor_id", Value: fields.ActorID})
+		}
 
 		if ww.Status() >= 500 {
 			logger.Error("request completed", logFields...)
@@ -82,7 +82,7 @@ func TestAccessLog_RevokedToken_LogsCompletionWithNoPII(t *testing.T) {
 	if status, _ := cl.entryField("request completed", "status"); status != http.StatusUnauthorized {
 		t.Errorf("status field = %v, want %d", status, http.StatusUnauthorized)
 	}
-	for _, key := range []string{"user_id", "tenant_id"} {
+	for _, key := range []string{"user_id", "tenant_id", "actor_id"} {
 		if _, ok := cl.entryField("request completed", key); ok {
 			t.Errorf("unauthenticated request must not log %q", key)
 		}
@@ -125,3 +125,36 @@ func TestAccessLog_AuthenticatedRequest_LogsUserAndTenant(t *testing.T) {
 		t.Errorf("tenant_id = %v, want %s", v, tenantID.String())
 	}
 }
+
+// TestAccessLog_Impersonation_LogsActor checks requests made with an
+// impersonation token log the real actor next to the impersonated user.
+func TestAccessLog_Impersonation_LogsActor(t *testing.T) {
+	mw, tokenSvc := setupAuthMiddleware(t)
+	cl := &capturingLogger{}
+	SetLogger(cl)
+	t.Cleanup(func() { SetLogger(observability.New("test")) })
+
+	user := &domain.User{ID: uuid.New(), Email: "waiter@example.com"}
+	actorID := uuid.New()
+	pair, err := tokenSvc.GenerateImpersonationToken(user, uuid.New(), actorID, domain.RoleWaiter, domain.RoleWaiter.Permissions(), domain.ImpersonationTTL)
+	if err != nil {
+		t.Fatalf("GenerateImpersonationToken failed: %v", err)
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	w := httptest.NewRecorder()
+
+	AccessLog(mw.RequireAuth(next)).ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+	if v, _ := cl.entryField("request completed", "user_id"); v != user.ID.String() {
+		t.Errorf("user_id = %v, want the impersonated user %s", v, user.ID)
+	}
+	if v, _ := cl.entryField("request completed", "actor_id"); v != actorID.String() {
+		t.Errorf("actor_id = %v, want %s", v, actorID)
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// maxApprovalResourceIDLength matches the approvals.resource_id column.
+const maxApprovalResourceIDLength = 255
+
+// ApprovalHandler handles step-up manager approval endpoints.
+type ApprovalHandler struct {
+	approvals *service.ApprovalService
+}
+
+// NewApprovalHandler creates a new ApprovalHandler.
+func NewApprovalHandler(approvals *service.ApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{approvals: approvals}
+}
+
+// Approve handles POST /approvals.
+//
+// @Summary      Get a manager's approval
+// @Description  Step-up authorization for a sensitive action such as a refund or a void. A manager enters their password, or their PIN on a registered terminal, on the caller's device. The approver must hold the action's permission in the caller's tenant and can't be the caller. Returns a single-use approval token, valid for 2 minutes, for the caller to perform that action on that resource; send it in the X-Approval-Token header. Approver failures return 403, never 401, so the client doesn't mistake them for its own session expiring.
+// @Tags         approvals
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        X-Terminal-Token  header    string           false  "Terminal device token (required for PIN approvals)"
+// @Param        request           body      ApprovalRequest  true   "Action, resource and approver credentials"
+// @Success      201               {object}  ApprovalResponse
+// @Failure      400               {object}  ErrorResponse "invalid_request, invalid_action"
+// @Failure      401               {object}  ErrorResponse "unauthorized"
+// @Failure      403               {object}  ErrorResponse "invalid_credentials, invalid_pin, terminal_invalid, self_approval, password_login_disabled"
+// @Failure      423               {object}  ErrorResponse "account_locked, pin_locked"
+// @Failure      429               {object}  ErrorResponse "rate_limit_exceeded"
+// @Router       /auth/approvals [post]
+func (h *ApprovalHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	var req ApprovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.Action == "" || req.ResourceID == "" || len(req.ResourceID) > maxApprovalResourceIDLength {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Action and a resource ID of at most 255 characters are required")
+		return
+	}
+	if (req.ApproverID == nil || *req.ApproverID == uuid.Nil) && req.ApproverEmail == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Approver ID or email is required")
+		return
+	}
+	if (req.Password == "") == (req.PIN == "") {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Exactly one of password or PIN is required")
+		return
+	}
+
+	svcReq := service.ApproveRequest{
+		TenantID:      tenantID,
+		RequesterID:   userID,
+		Action:        req.Action,
+		ResourceID:    req.ResourceID,
+		ApproverEmail: req.ApproverEmail,
+		Password:      req.Password,
+		PIN:           req.PIN,
+		TerminalToken: r.Header.Get(TerminalTokenHeader),
+		IPAddress:     GetClientIP(r),
+		UserAgent:     r.UserAgent(),
+	}
+	if req.ApproverID != nil {
+		svcReq.ApproverID = *req.ApproverID
+	}
+
+	approval, token, err := h.approvals.Approve(r.Context(), svcReq)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnknownPermission):
+			writeError(w, http.StatusBadRequest, "invalid_action", "Unknown action "+string(req.Action))
+			return
+		case errors.Is(err, domain.ErrInvalidCredentials):
+			writeError(w, http.StatusForbidden, "invalid_credentials", "Invalid approver email or password")
+			return
+		case errors.Is(err, domain.ErrPINInvalid):
+			writeError(w, http.StatusForbidden, "invalid_pin", "Invalid PIN")
+			return
+		case errors.Is(err, domain.ErrTerminalInvalid):
+			writeError(w, http.StatusForbidden, "terminal_invalid", "PIN approvals need a terminal registered to this tenant")
+			return
+		case errors.Is(err, domain.ErrSelfApproval):
+			writeError(w, http.StatusForbidden, "self_approval", "You cannot approve your own request")
+			return
+		case errors.Is(err, domain.ErrPasswordLoginDisabled):
+			writeError(w, http.StatusForbidden, "password_login_disabled", "This organization requires single sign-on, so the approver must use their PIN")
+			return
+		case errors.Is(err, domain.ErrAccountLocked):
+			writeError(w, http.StatusLocked, "account_locked", "Approver account is locked after too many failed attempts")
+			return
+		case errors.Is(err, domain.ErrPINLocked):
+			writeError(w, http.StatusLocked, "pin_locked", "Approver PIN is locked after too many failed attempts. Use the password instead.")
+			return
+		case errors.Is(err, domain.ErrRateLimitExceeded):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error: ErrorDetail{
+					Code:       "rate_limit_exceeded",
+					Message:    "Too many approval attempts. Please try again later.",
+					RetryAfter: 60,
+				},
+			})
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusCreated, ToApprovalResponse(approval, token))
+}
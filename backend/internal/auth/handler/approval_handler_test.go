@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// approvalHandlerEnv is an ApprovalHandler for one tenant with a registered
+// terminal, a cashier asking for approval and a manager (PIN 2580) who can
+// give it. The tests approve users.manage, which managers hold by default.
+type approvalHandlerEnv struct {
+	h             *ApprovalHandler
+	approvals     *service.ApprovalService
+	tenantID      uuid.UUID
+	cashierID     uuid.UUID
+	managerID     uuid.UUID
+	terminalToken string
+}
+
+func setupApprovalHandler(t *testing.T) *approvalHandlerEnv {
+	t.Helper()
+
+	userRepo, tenantRepo := mock.NewMockUserRepository(), mock.NewMockTenantRepository()
+	pinSvc := service.NewPINService(service.PINServiceConfig{
+		PINRepo:      mock.NewMockStaffPINRepository(),
+		TerminalRepo: mock.NewMockTerminalRepository(),
+		UserRepo:     userRepo,
+		TenantRepo:   tenantRepo,
+		EventRepo:    mock.NewMockAuthEventRepository(),
+	})
+	env := &approvalHandlerEnv{
+		approvals: service.NewApprovalService(service.ApprovalServiceConfig{
+			Approvals:  mock.NewMockApprovalRepository(),
+			UserRepo:   userRepo,
+			EventRepo:  mock.NewMockAuthEventRepository(),
+			PINService: pinSvc,
+		}),
+		tenantID:  uuid.New(),
+		cashierID: uuid.New(),
+		managerID: uuid.New(),
+	}
+	env.h = NewApprovalHandler(env.approvals)
+
+	passwordHash, _ := service.NewPasswordService().Hash("Password123!")
+	for id, role := range map[uuid.UUID]domain.Role{env.cashierID: domain.RoleCashier, env.managerID: domain.RoleManager} {
+		userRepo.AddUser(&domain.User{
+			ID: id, Email: string(role) + "@example.com", PasswordHash: passwordHash, IsActive: true,
+			TenantRoles: []domain.UserTenantRole{{UserID: id, TenantID: env.tenantID, Role: role}},
+		})
+	}
+
+	ctx := context.Background()
+	_, token, err := pinSvc.RegisterTerminal(ctx, env.tenantID, env.managerID, "Front counter", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RegisterTerminal failed: %v", err)
+	}
+	env.terminalToken = token
+	if err := pinSvc.SetPIN(ctx, service.SetPINRequest{UserID: env.managerID, TenantID: env.tenantID, CurrentPassword: "Password123!", PIN: "2580"}); err != nil {
+		t.Fatalf("SetPIN failed: %v", err)
+	}
+	return env
+}
+
+// approvalRequest builds a POST /approvals request from the env's cashier.
+func (e *approvalHandlerEnv) approvalRequest(body ApprovalRequest, terminalToken string) *http.Request {
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/approvals", bytes.NewReader(b)).WithContext(authedContext(e.cashierID, e.tenantID, domain.RoleCashier))
+	if terminalToken != "" {
+		req.Header.Set(TerminalTokenHeader, terminalToken)
+	}
+	return req
+}
+
+func TestApprovalHandler_Approve(t *testing.T) {
+	env := setupApprovalHandler(t)
+
+	tests := []struct {
+		name          string
+		body          ApprovalRequest
+		terminalToken string
+	}{
+		{"password", ApprovalRequest{Action: domain.PermUsersManage, ResourceID: "user-1", ApproverEmail: "manager@example.com", Password: "Password123!"}, ""},
+		{"PIN on a terminal", ApprovalRequest{Action: domain.PermUsersManage, ResourceID: "user-1", ApproverID: &env.managerID, PIN: "2580"}, env.terminalToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			env.h.Approve(w, env.approvalRequest(tt.body, tt.terminalToken))
+
+			if w.Code != http.StatusCreated {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+			}
+			var resp ApprovalResponse
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp.ApprovalToken == "" || resp.ApprovedBy != env.managerID || resp.Action != string(domain.PermUsersManage) || resp.ResourceID != "user-1" {
+				t.Errorf("response = %+v, want a users.manage approval of user-1 by the manager", resp)
+			}
+		})
+	}
+}
+
+func TestApprovalHandler_Approve_Errors(t *testing.T) {
+	env := setupApprovalHandler(t)
+	byPassword := func(email, password string) ApprovalRequest {
+		return ApprovalRequest{Action: domain.PermUsersManage, ResourceID: "user-1", ApproverEmail: email, Password: password}
+	}
+
+	tests := []struct {
+		name          string
+		body          ApprovalRequest
+		terminalToken string
+		wantStatus    int
+		wantCode      string
+	}{
+		{"missing resource", ApprovalRequest{Action: domain.PermUsersManage, ApproverEmail: "manager@example.com", Password: "Password123!"}, "", http.StatusBadRequest, "invalid_request"},
+		{"missing approver", ApprovalRequest{Action: domain.PermUsersManage, ResourceID: "user-1", Password: "Password123!"}, "", http.StatusBadRequest, "invalid_request"},
+		{"password and PIN", ApprovalRequest{Action: domain.PermUsersManage, ResourceID: "user-1", ApproverEmail: "manager@example.com", Password: "Password123!", PIN: "2580"}, "", http.StatusBadRequest, "invalid_request"},
+		{"unknown action", ApprovalRequest{Action: "payments.teleport", ResourceID: "user-1", ApproverEmail: "manager@example.com", Password: "Password123!"}, "", http.StatusBadRequest, "invalid_action"},
+		{"wrong password", byPassword("manager@example.com", "WrongPassword1!"), "", http.StatusForbidden, "invalid_credentials"},
+		{"self approval", byPassword("cashier@example.com", "Password123!"), "", http.StatusForbidden, "self_approval"},
+		{"wrong PIN", ApprovalRequest{Action: domain.PermUsersManage, ResourceID: "user-1", ApproverID: &env.managerID, PIN: "9731"}, env.terminalToken, http.StatusForbidden, "invalid_pin"},
+		{"PIN without a terminal", ApprovalRequest{Action: domain.PermUsersManage, ResourceID: "user-1", ApproverID: &env.managerID, PIN: "2580"}, "", http.StatusForbidden, "terminal_invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			env.h.Approve(w, env.approvalRequest(tt.body, tt.terminalToken))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var resp ErrorResponse
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", resp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestApprovalHandler_Approve_ApproverNotPermitted(t *testing.T) {
+	env := setupApprovalHandler(t)
+
+	// The manager asks; the cashier can't approve what they can't do themselves
+	b, _ := json.Marshal(ApprovalRequest{Action: domain.PermUsersManage, ResourceID: "user-1", ApproverEmail: "cashier@example.com", Password: "Password123!"})
+	req := httptest.NewRequest("POST", "/approvals", bytes.NewReader(b)).WithContext(authedContext(env.managerID, env.tenantID, domain.RoleManager))
+	w := httptest.NewRecorder()
+	env.h.Approve(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	var resp ErrorResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Error.Code != "invalid_credentials" {
+		t.Errorf("Code = %q, want invalid_credentials", resp.Error.Code)
+	}
+}
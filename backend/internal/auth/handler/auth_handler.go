@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
 	"github.com/solobueno/erp/internal/auth/domain"
 	"github.com/solobueno/erp/internal/auth/service"
 	"github.com/solobueno/erp/internal/shared/observability"
@@ -24,14 +26,15 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 // Login handles POST /login.
 //
 // @Summary      Log in
-// @Description  Authenticate with email and password. If the user belongs to multiple tenants and none is specified, returns 400 tenant_required with the list of choices.
+// @Description  Authenticate with email and password. If the user belongs to multiple tenants and none is specified, returns 400 tenant_required with the list of choices. If the user has MFA enabled (or their role requires it), returns 401 mfa_required / mfa_enrollment_required with an mfa_token to complete via /auth/mfa/verify, or via /auth/mfa/passkey/begin when mfa_methods includes passkey. Once the password is accepted, responses carry a device_token to send on later logins from the same device, which then count failed attempts separately from unknown devices. Repeated failures lock logins from such devices (423 account_locked) for a delay that grows with each further failure, per the tenant's lockout policy.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        request  body      LoginRequest  true  "Login credentials"
 // @Success      200      {object}  LoginResponse
 // @Failure      400      {object}  TenantRequiredResponse "tenant_required"
-// @Failure      401      {object}  ErrorResponse "invalid_credentials, account_disabled"
+// @Failure      401      {object}  ErrorResponse "invalid_credentials, account_disabled, mfa_required, mfa_enrollment_required"
+// @Failure      403      {object}  ErrorResponse "password_login_disabled"
 // @Failure      423      {object}  ErrorResponse "account_locked"
 // @Failure      429      {object}  ErrorResponse "rate_limit_exceeded"
 // @Router       /auth/login [post]
@@ -49,11 +52,12 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	loginReq := service.LoginRequest{
-		Email:     req.Email,
-		Password:  req.Password,
-		TenantID:  req.TenantID,
-		IPAddress: GetClientIP(r),
-		UserAgent: r.UserAgent(),
+		Email:       req.Email,
+		Password:    req.Password,
+		TenantID:    req.TenantID,
+		IPAddress:   GetClientIP(r),
+		UserAgent:   r.UserAgent(),
+		DeviceToken: req.DeviceToken,
 	}
 
 	resp, err := h.authService.Login(r.Context(), loginReq)
@@ -65,12 +69,19 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(TenantRequiredResponse{
 				Error: ErrorDetail{
-					Code:    "tenant_required",
-					Message: "User belongs to multiple tenants. Please specify tenant_id.",
-					Tenants: ToTenantOptions(resp.Tenants),
+					Code:        "tenant_required",
+					Message:     "User belongs to multiple tenants. Please specify tenant_id.",
+					Tenants:     ToTenantOptions(resp.Tenants),
+					DeviceToken: resp.DeviceToken,
 				},
 			})
 			return
+		case errors.Is(err, domain.ErrMFARequired):
+			writeMFAChallenge(w, "mfa_required", "Verify with your authenticator app or passkey.", resp.MFAToken, resp.MFAMethods, resp.DeviceToken)
+			return
+		case errors.Is(err, domain.ErrMFAEnrollmentRequired):
+			writeMFAChallenge(w, "mfa_enrollment_required", "Your role requires multi-factor authentication. Set up an authenticator app to continue.", resp.MFAToken, nil, resp.DeviceToken)
+			return
 		case errors.Is(err, domain.ErrInvalidCredentials):
 			writeError(w, http.StatusUnauthorized, "invalid_credentials", "Invalid email or password")
 			return
@@ -83,7 +94,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(ErrorResponse{
 				Error: ErrorDetail{
 					Code:        "account_locked",
-					Message:     "Account locked after 5 failed login attempts. Try again later.",
+					Message:     "Account temporarily locked after repeated failed login attempts. Try again later or request an unlock link.",
 					LockedUntil: resp.LockedUntil,
 				},
 			})
@@ -105,6 +116,9 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		case errors.Is(err, domain.ErrUserNotInTenant):
 			writeError(w, http.StatusBadRequest, "invalid_tenant", "User does not belong to this tenant")
 			return
+		case errors.Is(err, domain.ErrPasswordLoginDisabled):
+			writeError(w, http.StatusForbidden, "password_login_disabled", "This organization requires signing in with single sign-on")
+			return
 		default:
 			writeInternalError(w, r, err)
 			return
@@ -123,7 +137,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Param        request  body      RefreshRequest  true  "Refresh token"
 // @Success      200      {object}  TokenResponse
-// @Failure      401      {object}  ErrorResponse "token_invalid, session_revoked, token_expired, account_disabled"
+// @Failure      401      {object}  ErrorResponse "token_invalid, session_revoked, token_expired, account_disabled, mfa_sign_in_required"
 // @Router       /auth/refresh [post]
 func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	var req RefreshRequest
@@ -158,6 +172,9 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		case errors.Is(err, domain.ErrAccountDisabled):
 			writeError(w, http.StatusUnauthorized, "account_disabled", "Account is disabled")
 			return
+		case errors.Is(err, domain.ErrMFASignInRequired):
+			writeError(w, http.StatusUnauthorized, "mfa_sign_in_required", "Your elevated role requires multi-factor authentication. Sign in again to continue.")
+			return
 		default:
 			writeInternalError(w, r, err)
 			return
@@ -170,7 +187,7 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 // Logout handles POST /logout.
 //
 // @Summary      Log out
-// @Description  Revoke the current session's refresh token. Idempotent - always returns 204.
+// @Description  Revoke the current session's refresh token and the access token used to call this endpoint. Idempotent - always returns 204.
 // @Tags         auth
 // @Security     BearerAuth
 // @Accept       json
@@ -184,6 +201,12 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		req.RefreshToken = ""
 	}
 
+	// The calling access token is revoked either way, so it can't be
+	// replayed for the rest of its lifetime
+	if claims, ok := GetClaims(r.Context()); ok {
+		_ = h.authService.RevokeAccessToken(r.Context(), claims)
+	}
+
 	// If no refresh token in body, try to get session from token and revoke
 	if req.RefreshToken == "" {
 		// Just return success - nothing to revoke
@@ -200,10 +223,96 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// SwitchTenant handles POST /switch-tenant.
+//
+// @Summary      Switch tenant
+// @Description  Move the current session to another tenant the user belongs to, without re-entering credentials. Returns a new token pair scoped to the target tenant; the current session and the access token used to call this endpoint are revoked. A user without MFA whose role in the target tenant requires it gets 401 mfa_enrollment_required with an mfa_token, and keeps the current session. A session signed in through SSO only switches freely to tenants whose SSO would accept the same login; for other tenants, users with MFA get 401 mfa_required with an mfa_token to verify, and others 403 sign_in_required. Either way they keep the current session.
+// @Tags         auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      SwitchTenantRequest  true  "Target tenant"
+// @Success      200      {object}  LoginResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, invalid_tenant, same_tenant"
+// @Failure      401      {object}  ErrorResponse "unauthorized, session_required, session_revoked, account_disabled, tenant_inactive, mfa_required, mfa_enrollment_required"
+// @Failure      403      {object}  ErrorResponse "password_login_disabled, sign_in_required"
+// @Router       /auth/switch-tenant [post]
+func (h *AuthHandler) SwitchTenant(w http.ResponseWriter, r *http.Request) {
+	claims, ok := GetClaims(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	userID, _ := GetUserID(r.Context())
+
+	sessionID, ok := GetSessionID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "session_required", "Switching tenants requires a signed-in session")
+		return
+	}
+
+	var req SwitchTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.TenantID == uuid.Nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Tenant ID is required")
+		return
+	}
+
+	resp, err := h.authService.SwitchTenant(r.Context(), service.SwitchTenantRequest{
+		UserID:    userID,
+		SessionID: sessionID,
+		TenantID:  req.TenantID,
+		IPAddress: GetClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMFAEnrollmentRequired):
+			writeMFAChallenge(w, "mfa_enrollment_required", "Your role in this tenant requires multi-factor authentication. Set up an authenticator app to continue.", resp.MFAToken, nil, "")
+			return
+		case errors.Is(err, domain.ErrMFARequired):
+			writeMFAChallenge(w, "mfa_required", "Verify with your authenticator app or passkey to switch to this tenant.", resp.MFAToken, resp.MFAMethods, "")
+			return
+		case errors.Is(err, domain.ErrSignInRequired):
+			writeError(w, http.StatusForbidden, "sign_in_required", "Your single sign-on doesn't cover this tenant. Sign in to it directly.")
+			return
+		case errors.Is(err, domain.ErrSessionRevoked):
+			writeError(w, http.StatusUnauthorized, "session_revoked", "Session has been revoked")
+			return
+		case errors.Is(err, domain.ErrAccountDisabled):
+			writeError(w, http.StatusUnauthorized, "account_disabled", "Account is disabled")
+			return
+		case errors.Is(err, domain.ErrTenantInactive):
+			writeError(w, http.StatusUnauthorized, "tenant_inactive", "Tenant is inactive")
+			return
+		case errors.Is(err, domain.ErrUserNotInTenant):
+			writeError(w, http.StatusBadRequest, "invalid_tenant", "User does not belong to this tenant")
+			return
+		case errors.Is(err, domain.ErrSameTenant):
+			writeError(w, http.StatusBadRequest, "same_tenant", "Already signed in to this tenant")
+			return
+		case errors.Is(err, domain.ErrPasswordLoginDisabled):
+			writeError(w, http.StatusForbidden, "password_login_disabled", "This organization requires signing in with single sign-on")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	// The old token is still scoped to the previous tenant
+	_ = h.authService.RevokeAccessToken(r.Context(), claims)
+
+	writeJSON(w, http.StatusOK, ToLoginResponse(resp))
+}
+
 // Me handles GET /me.
 //
 // @Summary      Get current user
-// @Description  Return the authenticated user's identity and current tenant/role, from the access token claims.
+// @Description  Return the authenticated user's identity, current tenant/role and permissions, from the access token claims. impersonated_by is set while another user is impersonating this one.
 // @Tags         auth
 // @Security     BearerAuth
 // @Produce      json
@@ -231,6 +340,13 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 		TenantName:        "", // Would need to fetch from DB
 		MustResetPassword: false,
 		Tenants:           []TenantRoleInfo{},
+		Permissions:       []string{},
+	}
+	for _, p := range claims.GetPermissions() {
+		resp.Permissions = append(resp.Permissions, string(p))
+	}
+	if actorID, ok := claims.GetActorID(); ok {
+		resp.ImpersonatedBy = &actorID
 	}
 
 	writeJSON(w, http.StatusOK, resp)
@@ -239,7 +355,7 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 // ChangePassword handles POST /change-password.
 //
 // @Summary      Change password
-// @Description  Change the authenticated user's password. Invalidates all other sessions (FR-014).
+// @Description  Change the authenticated user's password. The new password must meet the strictest policy of the user's tenants and not be a breached or recently used password; a password_weak error names the rule broken in its rule field. Invalidates all other sessions (FR-014).
 // @Tags         auth
 // @Security     BearerAuth
 // @Accept       json
@@ -248,6 +364,7 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 // @Success      200      {object}  MessageResponse
 // @Failure      400      {object}  ErrorResponse "current_password_incorrect, password_weak"
 // @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "impersonation_forbidden"
 // @Router       /auth/change-password [post]
 func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request, userService *service.UserService) {
 	userID, ok := GetUserID(r.Context())
@@ -281,7 +398,7 @@ func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request, use
 			writeError(w, http.StatusBadRequest, "current_password_incorrect", "Current password is incorrect")
 			return
 		case errors.Is(err, domain.ErrPasswordWeak):
-			writeError(w, http.StatusBadRequest, "password_weak", "Password does not meet requirements")
+			writePasswordWeak(w, err)
 			return
 		default:
 			writeInternalError(w, r, err)
@@ -297,7 +414,7 @@ func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request, use
 // RequestPasswordReset handles POST /password-reset/request.
 //
 // @Summary      Request password reset
-// @Description  Send a password reset token to the given email if it exists. Always returns 202 to prevent email enumeration.
+// @Description  Send a password reset token to the given email if it belongs to an account whose email is verified. Always returns 202 to prevent email enumeration.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -343,13 +460,13 @@ func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Reques
 // CompletePasswordReset handles POST /password-reset/complete.
 //
 // @Summary      Complete password reset
-// @Description  Set a new password using a valid, unexpired, unused reset token (1-hour TTL). Invalidates all sessions.
+// @Description  Set a new password using a valid, unexpired, unused reset token (1-hour TTL). The new password must meet the strictest policy of the user's tenants and not be a breached or recently used password; a password_weak error names the rule broken in its rule field and leaves the token usable. Invalidates all sessions.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        request  body      PasswordResetCompleteRequest  true  "Reset token and new password"
 // @Success      200      {object}  MessageResponse
-// @Failure      400      {object}  ErrorResponse "token_invalid, token_expired, token_used, password_weak"
+// @Failure      400      {object}  ErrorResponse "token_invalid, token_expired, token_used, password_weak, email_not_verified"
 // @Router       /auth/password-reset/complete [post]
 func (h *AuthHandler) CompletePasswordReset(w http.ResponseWriter, r *http.Request, userService *service.UserService) {
 	var req PasswordResetCompleteRequest
@@ -376,7 +493,10 @@ func (h *AuthHandler) CompletePasswordReset(w http.ResponseWriter, r *http.Reque
 			writeError(w, http.StatusBadRequest, "token_used", "Password reset token has already been used")
 			return
 		case errors.Is(err, domain.ErrPasswordWeak):
-			writeError(w, http.StatusBadRequest, "password_weak", "Password does not meet requirements")
+			writePasswordWeak(w, err)
+			return
+		case errors.Is(err, domain.ErrEmailNotVerified):
+			writeError(w, http.StatusBadRequest, "email_not_verified", "Email address is not verified")
 			return
 		default:
 			writeInternalError(w, r, err)
@@ -389,6 +509,444 @@ func (h *AuthHandler) CompletePasswordReset(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// RequestEmailVerification handles POST /email/verify/request.
+//
+// @Summary      Request email verification
+// @Description  Email the authenticated user a link verifying their current address (24-hour TTL). Only the newest link works. Password resets are only sent to verified addresses.
+// @Tags         auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      202      {object}  MessageResponse
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      409      {object}  ErrorResponse "email_already_verified"
+// @Failure      429      {object}  ErrorResponse "rate_limit_exceeded"
+// @Router       /auth/email/verify/request [post]
+func (h *AuthHandler) RequestEmailVerification(w http.ResponseWriter, r *http.Request, userService *service.UserService) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	err := userService.RequestEmailVerification(r.Context(), userID, GetClientIP(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrEmailAlreadyVerified):
+			writeError(w, http.StatusConflict, "email_already_verified", "Email address is already verified")
+			return
+		case errors.Is(err, domain.ErrRateLimitExceeded):
+			writeError(w, http.StatusTooManyRequests, "rate_limit_exceeded", "Too many verification emails. Please try again later.")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusAccepted, MessageResponse{
+		Message: "A verification link has been sent.",
+	})
+}
+
+// VerifyEmail handles POST /email/verify.
+//
+// @Summary      Verify email
+// @Description  Verify the address a verification link was sent to, using its token. A link sent before an email change no longer works.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      EmailTokenRequest  true  "Verification token"
+// @Success      200      {object}  MessageResponse
+// @Failure      400      {object}  ErrorResponse "token_invalid, token_expired, token_used"
+// @Router       /auth/email/verify [post]
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request, userService *service.UserService) {
+	token, ok := decodeEmailToken(w, r)
+	if !ok {
+		return
+	}
+
+	if err := userService.VerifyEmail(r.Context(), token, GetClientIP(r)); err != nil {
+		writeEmailTokenError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, MessageResponse{
+		Message: "Email address verified.",
+	})
+}
+
+// RequestEmailChange handles POST /email/change.
+//
+// @Summary      Change email
+// @Description  Start moving the authenticated user to a new email address, confirmed with their password. A link is sent to the new address (1-hour TTL); nothing changes until it is followed.
+// @Tags         auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      EmailChangeRequest  true  "New email and current password"
+// @Success      202      {object}  MessageResponse
+// @Failure      400      {object}  ErrorResponse "invalid_email, email_unchanged, current_password_incorrect"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "impersonation_forbidden"
+// @Failure      409      {object}  ErrorResponse "email_exists"
+// @Failure      429      {object}  ErrorResponse "rate_limit_exceeded"
+// @Router       /auth/email/change [post]
+func (h *AuthHandler) RequestEmailChange(w http.ResponseWriter, r *http.Request, userService *service.UserService) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	var req EmailChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.NewEmail == "" || req.CurrentPassword == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "New email and current password are required")
+		return
+	}
+
+	err := userService.RequestEmailChange(r.Context(), service.RequestEmailChangeRequest{
+		UserID:          userID,
+		NewEmail:        req.NewEmail,
+		CurrentPassword: req.CurrentPassword,
+		IPAddress:       GetClientIP(r),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrEmailInvalid):
+			writeError(w, http.StatusBadRequest, "invalid_email", "New email is not a valid address")
+			return
+		case errors.Is(err, domain.ErrEmailUnchanged):
+			writeError(w, http.StatusBadRequest, "email_unchanged", "New email is the current email")
+			return
+		case errors.Is(err, domain.ErrPasswordIncorrect):
+			writeError(w, http.StatusBadRequest, "current_password_incorrect", "Current password is incorrect")
+			return
+		case errors.Is(err, domain.ErrEmailExists):
+			writeError(w, http.StatusConflict, "email_exists", "Email already registered")
+			return
+		case errors.Is(err, domain.ErrRateLimitExceeded):
+			writeError(w, http.StatusTooManyRequests, "rate_limit_exceeded", "Too many email change requests. Please try again later.")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusAccepted, MessageResponse{
+		Message: "A confirmation link has been sent to the new address.",
+	})
+}
+
+// ConfirmEmailChange handles POST /email/change/confirm.
+//
+// @Summary      Confirm email change
+// @Description  Move the account to the new address a change link was sent to, using its token. The address becomes verified, all sessions are invalidated, and the previous address is sent a link that undoes the change for 7 days.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      EmailTokenRequest  true  "Email change token"
+// @Success      200      {object}  MessageResponse
+// @Failure      400      {object}  ErrorResponse "token_invalid, token_expired, token_used"
+// @Failure      409      {object}  ErrorResponse "email_exists"
+// @Router       /auth/email/change/confirm [post]
+func (h *AuthHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request, userService *service.UserService) {
+	token, ok := decodeEmailToken(w, r)
+	if !ok {
+		return
+	}
+
+	if err := userService.ConfirmEmailChange(r.Context(), token, GetClientIP(r)); err != nil {
+		writeEmailTokenError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, MessageResponse{
+		Message: "Email address changed. All sessions have been invalidated.",
+	})
+}
+
+// RevertEmailChange handles POST /email/change/revert.
+//
+// @Summary      Undo email change
+// @Description  Move the account back to the address an undo link was sent to after an email change, using its token. Pending changes are cancelled, all sessions are invalidated, and the user is asked to set a new password.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      EmailTokenRequest  true  "Email change undo token"
+// @Success      200      {object}  MessageResponse
+// @Failure      400      {object}  ErrorResponse "token_invalid, token_expired, token_used"
+// @Failure      409      {object}  ErrorResponse "email_exists"
+// @Router       /auth/email/change/revert [post]
+func (h *AuthHandler) RevertEmailChange(w http.ResponseWriter, r *http.Request, userService *service.UserService) {
+	token, ok := decodeEmailToken(w, r)
+	if !ok {
+		return
+	}
+
+	if err := userService.RevertEmailChange(r.Context(), token, GetClientIP(r)); err != nil {
+		writeEmailTokenError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, MessageResponse{
+		Message: "Email change undone. All sessions have been invalidated; please reset your password.",
+	})
+}
+
+// RequestAccountUnlock handles POST /unlock/request.
+//
+// @Summary      Request account unlock
+// @Description  Send an unlock link (1-hour TTL) to the given email if it belongs to an account that is locked out after failed logins and whose email is verified. Always returns 202 to prevent email enumeration.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      AccountUnlockRequest  true  "Email"
+// @Success      202      {object}  MessageResponse
+// @Failure      429      {object}  ErrorResponse "rate_limit_exceeded"
+// @Router       /auth/unlock/request [post]
+func (h *AuthHandler) RequestAccountUnlock(w http.ResponseWriter, r *http.Request, userService *service.UserService) {
+	var req AccountUnlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.Email == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Email is required")
+		return
+	}
+
+	err := userService.RequestAccountUnlock(r.Context(), req.Email, GetClientIP(r))
+	if err != nil {
+		if errors.Is(err, domain.ErrRateLimitExceeded) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error: ErrorDetail{
+					Code:       "rate_limit_exceeded",
+					Message:    "Please wait before requesting another unlock link.",
+					RetryAfter: 300,
+				},
+			})
+			return
+		}
+		// Don't reveal other errors - could expose whether email exists
+	}
+
+	writeJSON(w, http.StatusAccepted, MessageResponse{
+		Message: "If the account is locked, an unlock link has been sent.",
+	})
+}
+
+// UnlockAccount handles POST /unlock.
+//
+// @Summary      Unlock account
+// @Description  Lift a lockout after failed logins using the token from an unlock link, on every device.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      EmailTokenRequest  true  "Unlock token"
+// @Success      200      {object}  MessageResponse
+// @Failure      400      {object}  ErrorResponse "token_invalid, token_expired, token_used"
+// @Router       /auth/unlock [post]
+func (h *AuthHandler) UnlockAccount(w http.ResponseWriter, r *http.Request, userService *service.UserService) {
+	token, ok := decodeEmailToken(w, r)
+	if !ok {
+		return
+	}
+
+	if err := userService.UnlockAccount(r.Context(), token, GetClientIP(r)); err != nil {
+		writeEmailTokenError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, MessageResponse{
+		Message: "Account unlocked. You can log in again.",
+	})
+}
+
+// decodeEmailToken reads an EmailTokenRequest, writing a 400 if it has no token.
+func decodeEmailToken(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req EmailTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return "", false
+	}
+	if req.Token == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Token is required")
+		return "", false
+	}
+	return req.Token, true
+}
+
+// writeEmailTokenError maps errors from following an emailed link to responses.
+func writeEmailTokenError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrEmailTokenInvalid):
+		writeError(w, http.StatusBadRequest, "token_invalid", "Email token is invalid")
+	case errors.Is(err, domain.ErrEmailTokenExpired):
+		writeError(w, http.StatusBadRequest, "token_expired", "Email token has expired")
+	case errors.Is(err, domain.ErrEmailTokenUsed):
+		writeError(w, http.StatusBadRequest, "token_used", "Email token has already been used")
+	case errors.Is(err, domain.ErrEmailExists):
+		writeError(w, http.StatusConflict, "email_exists", "Email already registered")
+	default:
+		writeInternalError(w, r, err)
+	}
+}
+
+// AcceptInvitation handles POST /invitations/accept.
+//
+// @Summary      Accept an invitation
+// @Description  Redeem an invite token (7-day TTL, single use). If the invited email has no account, one is created with the given password. If it already has one, the password must be that account's current password, confirming the invitee wants to join the tenant.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      AcceptInvitationRequest  true  "Invite token and password"
+// @Success      201      {object}  AcceptInvitationResponse "new account created"
+// @Success      200      {object}  AcceptInvitationResponse "existing account joined the tenant"
+// @Failure      400      {object}  ErrorResponse "token_invalid, token_expired, token_used, invitation_revoked, password_weak, email_exists"
+// @Failure      401      {object}  ErrorResponse "invalid_credentials, account_disabled"
+// @Failure      423      {object}  ErrorResponse "account_locked"
+// @Router       /auth/invitations/accept [post]
+func (h *AuthHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request, userService *service.UserService) {
+	var req AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.Token == "" || req.Password == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Token and password are required")
+		return
+	}
+
+	resp, err := userService.AcceptInvitation(r.Context(), service.AcceptInvitationRequest{
+		Token:     req.Token,
+		Password:  req.Password,
+		IPAddress: GetClientIP(r),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvitationInvalid):
+			writeError(w, http.StatusBadRequest, "token_invalid", "Invitation is invalid")
+			return
+		case errors.Is(err, domain.ErrInvitationExpired):
+			writeError(w, http.StatusBadRequest, "token_expired", "Invitation has expired")
+			return
+		case errors.Is(err, domain.ErrInvitationAccepted):
+			writeError(w, http.StatusBadRequest, "token_used", "Invitation has already been accepted")
+			return
+		case errors.Is(err, domain.ErrInvitationRevoked):
+			writeError(w, http.StatusBadRequest, "invitation_revoked", "Invitation has been revoked")
+			return
+		case errors.Is(err, domain.ErrPasswordWeak):
+			writePasswordWeak(w, err)
+			return
+		case errors.Is(err, domain.ErrEmailExists):
+			writeError(w, http.StatusBadRequest, "email_exists", "You are already a member of this tenant")
+			return
+		case errors.Is(err, domain.ErrInvalidCredentials):
+			writeError(w, http.StatusUnauthorized, "invalid_credentials", "Password is incorrect for the existing account")
+			return
+		case errors.Is(err, domain.ErrAccountDisabled):
+			writeError(w, http.StatusUnauthorized, "account_disabled", "Account is disabled")
+			return
+		case errors.Is(err, domain.ErrAccountLocked):
+			writeError(w, http.StatusLocked, "account_locked", "Account is temporarily locked")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	// A just-created user has no tenant roles loaded yet
+	user := ToUserResponse(resp.User, resp.TenantID)
+	user.Role = string(resp.Role)
+
+	status := http.StatusCreated
+	if resp.ExistingAccount {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, AcceptInvitationResponse{
+		User:            *user,
+		TenantID:        resp.TenantID,
+		Role:            string(resp.Role),
+		ExistingAccount: resp.ExistingAccount,
+	})
+}
+
+// ConfirmOwnershipTransfer handles POST /ownership-transfer/confirm.
+//
+// @Summary      Confirm an ownership transfer
+// @Description  The recipient of an ownership transfer redeems the emailed token (72-hour TTL, single use) and re-authenticates with their current password. They become the tenant's owner and the previous owner takes their old role. Both are signed out of existing access tokens so the new roles take effect, and both are notified by email.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      ConfirmOwnershipTransferRequest  true  "Transfer token and password"
+// @Success      200      {object}  OwnershipTransferResponse
+// @Failure      400      {object}  ErrorResponse "token_invalid, token_expired, token_used, transfer_cancelled"
+// @Failure      401      {object}  ErrorResponse "invalid_credentials, account_disabled"
+// @Failure      423      {object}  ErrorResponse "account_locked"
+// @Router       /auth/ownership-transfer/confirm [post]
+func (h *AuthHandler) ConfirmOwnershipTransfer(w http.ResponseWriter, r *http.Request, userService *service.UserService) {
+	var req ConfirmOwnershipTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.Token == "" || req.Password == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Token and password are required")
+		return
+	}
+
+	resp, err := userService.ConfirmOwnershipTransfer(r.Context(), service.ConfirmOwnershipTransferRequest{
+		Token:     req.Token,
+		Password:  req.Password,
+		IPAddress: GetClientIP(r),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrOwnershipTransferInvalid):
+			writeError(w, http.StatusBadRequest, "token_invalid", "Ownership transfer is invalid")
+			return
+		case errors.Is(err, domain.ErrOwnershipTransferExpired):
+			writeError(w, http.StatusBadRequest, "token_expired", "Ownership transfer has expired")
+			return
+		case errors.Is(err, domain.ErrOwnershipTransferUsed):
+			writeError(w, http.StatusBadRequest, "token_used", "Ownership transfer has already been confirmed")
+			return
+		case errors.Is(err, domain.ErrOwnershipTransferCancelled):
+			writeError(w, http.StatusBadRequest, "transfer_cancelled", "Ownership transfer has been cancelled")
+			return
+		case errors.Is(err, domain.ErrInvalidCredentials):
+			writeError(w, http.StatusUnauthorized, "invalid_credentials", "Password is incorrect")
+			return
+		case errors.Is(err, domain.ErrAccountDisabled):
+			writeError(w, http.StatusUnauthorized, "account_disabled", "Account is disabled")
+			return
+		case errors.Is(err, domain.ErrAccountLocked):
+			writeError(w, http.StatusLocked, "account_locked", "Account is temporarily locked")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	transfer := ToOwnershipTransferResponse(resp.Transfer)
+	transfer.PreviousOwnerRole = string(resp.PreviousOwnerRole)
+	writeJSON(w, http.StatusOK, transfer)
+}
+
 // --- Helper Functions ---
 
 // writeJSON writes a JSON response.
@@ -410,14 +968,32 @@ func writeError(w http.ResponseWriter, status int, code, message string) {
 	})
 }
 
+// writePasswordWeak writes the 400 for a new password that breaks the
+// password policy, naming the rule it broke when known.
+func writePasswordWeak(w http.ResponseWriter, err error) {
+	detail := ErrorDetail{Code: "password_weak", Message: "Password does not meet requirements"}
+	var ruleErr *domain.PasswordRuleError
+	if errors.As(err, &ruleErr) {
+		msg := ruleErr.Error()
+		detail.Message = strings.ToUpper(msg[:1]) + msg[1:]
+		detail.Rule = string(ruleErr.Rule)
+	}
+	writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: detail})
+}
+
 // writeInternalError logs an unexpected error at error level (with the
 // request-correlation ID for troubleshooting) and returns a generic 500 to
 // the client - the real error detail never reaches the response body, per FR-017.
 func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	logUnhandledError(r, err)
+	writeError(w, http.StatusInternalServerError, "internal_error", "An unexpected error occurred")
+}
+
+// logUnhandledError logs an unexpected error with the request-correlation ID.
+func logUnhandledError(r *http.Request, err error) {
 	logger.Error("unhandled error",
 		observability.Field{Key: "error", Value: err.Error()},
 		observability.Field{Key: "request_id", Value: middleware.GetReqID(r.Context())},
 		observability.Field{Key: "path", Value: r.URL.Path},
 	)
-	writeError(w, http.StatusInternalServerError, "internal_error", "An unexpected error occurred")
 }
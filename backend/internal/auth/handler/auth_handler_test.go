@@ -10,6 +10,8 @@ import (
 	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -68,6 +70,9 @@ func setupWiredAuthHandler(t *testing.T) (*AuthHandler, *service.UserService, *s
 		SessionRepo:   sessionRepo,
 		EventRepo:     eventRepo,
 		PasswordReset: passwordResetRepo,
+		Invitations:   mock.NewMockInvitationRepository(),
+		Transfers:     mock.NewMockOwnershipTransferRepository(),
+		EmailTokens:   mock.NewMockEmailTokenRepository(),
 	})
 
 	return NewAuthHandler(authSvc), userSvc, tokenSvc, userRepo, tenantRepo, roleRepo, sessionRepo
@@ -306,6 +311,294 @@ func TestAuthHandler_CompletePasswordReset_InvalidToken(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_AcceptInvitation(t *testing.T) {
+	_, userSvc, _, userRepo, _, _, _ := setupWiredAuthHandler(t)
+	h := NewAuthHandler(nil)
+	tenantID := uuid.New()
+
+	invite, err := userSvc.Invite(context.Background(), service.InviteRequest{
+		Email: "new@example.com", FirstName: "New", LastName: "Hire", TenantID: tenantID, Role: domain.RoleWaiter,
+	}, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("Invite failed: %v", err)
+	}
+
+	accept := func(body interface{}) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		h.AcceptInvitation(w, httptest.NewRequest("POST", "/invitations/accept", bytes.NewReader(b)), userSvc)
+		return w
+	}
+
+	w := accept(AcceptInvitationRequest{Token: invite.Token, Password: "NewPassword123!"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var resp AcceptInvitationResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.ExistingAccount || resp.TenantID != tenantID || resp.User.Role != string(domain.RoleWaiter) || resp.User.MustResetPassword {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if _, err := userRepo.FindByEmail(context.Background(), "new@example.com"); err != nil {
+		t.Errorf("account should be created: %v", err)
+	}
+
+	w = accept(AcceptInvitationRequest{Token: invite.Token, Password: "NewPassword123!"})
+	var errResp ErrorResponse
+	json.NewDecoder(w.Body).Decode(&errResp)
+	if w.Code != http.StatusBadRequest || errResp.Error.Code != "token_used" {
+		t.Errorf("second accept = %d %q, want 400 token_used", w.Code, errResp.Error.Code)
+	}
+}
+
+func TestAuthHandler_AcceptInvitation_ExistingAccount(t *testing.T) {
+	_, userSvc, _, userRepo, _, _, _ := setupWiredAuthHandler(t)
+	h := NewAuthHandler(nil)
+
+	hash, _ := service.NewPasswordService().Hash("Existing123!")
+	existingID := uuid.New()
+	userRepo.AddUser(&domain.User{
+		ID: existingID, Email: "existing@example.com", PasswordHash: hash, IsActive: true,
+		TenantRoles: []domain.UserTenantRole{{UserID: existingID, TenantID: uuid.New(), Role: domain.RoleWaiter}},
+	})
+	invite, err := userSvc.Invite(context.Background(), service.InviteRequest{
+		Email: "existing@example.com", FirstName: "X", LastName: "Y", TenantID: uuid.New(), Role: domain.RoleCashier,
+	}, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("Invite failed: %v", err)
+	}
+
+	body, _ := json.Marshal(AcceptInvitationRequest{Token: invite.Token, Password: "WrongPassword123!"})
+	w := httptest.NewRecorder()
+	h.AcceptInvitation(w, httptest.NewRequest("POST", "/invitations/accept", bytes.NewReader(body)), userSvc)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password status = %d, want %d, body=%s", w.Code, http.StatusUnauthorized, w.Body.String())
+	}
+
+	body, _ = json.Marshal(AcceptInvitationRequest{Token: invite.Token, Password: "Existing123!"})
+	w = httptest.NewRecorder()
+	h.AcceptInvitation(w, httptest.NewRequest("POST", "/invitations/accept", bytes.NewReader(body)), userSvc)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp AcceptInvitationResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if !resp.ExistingAccount || resp.User.ID != existingID {
+		t.Errorf("expected the existing account to join, got %+v", resp)
+	}
+}
+
+func TestAuthHandler_AcceptInvitation_Errors(t *testing.T) {
+	_, userSvc, _, _, _, _, _ := setupWiredAuthHandler(t)
+	h := NewAuthHandler(nil)
+
+	invite, err := userSvc.Invite(context.Background(), service.InviteRequest{
+		Email: "new@example.com", FirstName: "New", LastName: "Hire", TenantID: uuid.New(), Role: domain.RoleWaiter,
+	}, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("Invite failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+	}{
+		{name: "invalid body", body: "invalid json", wantCode: "invalid_request"},
+		{name: "missing token", body: `{"password":"NewPassword123!"}`, wantCode: "invalid_request"},
+		{name: "missing password", body: `{"token":"` + invite.Token + `"}`, wantCode: "invalid_request"},
+		{name: "unknown token", body: `{"token":"nonexistent","password":"NewPassword123!"}`, wantCode: "token_invalid"},
+		{name: "weak password", body: `{"token":"` + invite.Token + `","password":"weak"}`, wantCode: "password_weak"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.AcceptInvitation(w, httptest.NewRequest("POST", "/invitations/accept", strings.NewReader(tt.body)), userSvc)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+			}
+			var errResp ErrorResponse
+			json.NewDecoder(w.Body).Decode(&errResp)
+			if errResp.Error.Code != tt.wantCode {
+				t.Errorf("error code = %q, want %q", errResp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_ConfirmOwnershipTransfer(t *testing.T) {
+	_, userSvc, _, userRepo, _, roleRepo, _ := setupWiredAuthHandler(t)
+	h := NewAuthHandler(nil)
+
+	hash, _ := service.NewPasswordService().Hash("Password123!")
+	tenantID, ownerID, managerID := uuid.New(), uuid.New(), uuid.New()
+	userRepo.AddUser(&domain.User{ID: ownerID, Email: "owner@example.com", PasswordHash: hash, IsActive: true})
+	userRepo.AddUser(&domain.User{ID: managerID, Email: "manager@example.com", PasswordHash: hash, IsActive: true})
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: ownerID, TenantID: tenantID, Role: domain.RoleOwner})
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: managerID, TenantID: tenantID, Role: domain.RoleManager})
+
+	started, err := userSvc.StartOwnershipTransfer(context.Background(), service.StartOwnershipTransferRequest{
+		TenantID: tenantID, FromUserID: ownerID, ToUserID: managerID,
+	})
+	if err != nil {
+		t.Fatalf("StartOwnershipTransfer failed: %v", err)
+	}
+
+	confirm := func(body interface{}) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		h.ConfirmOwnershipTransfer(w, httptest.NewRequest("POST", "/ownership-transfer/confirm", bytes.NewReader(b)), userSvc)
+		return w
+	}
+
+	w := confirm(ConfirmOwnershipTransferRequest{Token: started.Token, Password: "WrongPassword123!"})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password status = %d, want %d, body=%s", w.Code, http.StatusUnauthorized, w.Body.String())
+	}
+
+	w = confirm(ConfirmOwnershipTransferRequest{Token: started.Token, Password: "Password123!"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp OwnershipTransferResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.ToUserID != managerID || resp.ConfirmedAt == nil || resp.PreviousOwnerRole != string(domain.RoleManager) {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	w = confirm(ConfirmOwnershipTransferRequest{Token: started.Token, Password: "Password123!"})
+	var errResp ErrorResponse
+	json.NewDecoder(w.Body).Decode(&errResp)
+	if w.Code != http.StatusBadRequest || errResp.Error.Code != "token_used" {
+		t.Errorf("second confirm = %d %q, want 400 token_used", w.Code, errResp.Error.Code)
+	}
+}
+
+func TestAuthHandler_ConfirmOwnershipTransfer_Errors(t *testing.T) {
+	_, userSvc, _, _, _, _, _ := setupWiredAuthHandler(t)
+	h := NewAuthHandler(nil)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+	}{
+		{name: "invalid body", body: "invalid json", wantCode: "invalid_request"},
+		{name: "missing token", body: `{"password":"Password123!"}`, wantCode: "invalid_request"},
+		{name: "missing password", body: `{"token":"abc"}`, wantCode: "invalid_request"},
+		{name: "unknown token", body: `{"token":"nonexistent","password":"Password123!"}`, wantCode: "token_invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ConfirmOwnershipTransfer(w, httptest.NewRequest("POST", "/ownership-transfer/confirm", strings.NewReader(tt.body)), userSvc)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+			}
+			var errResp ErrorResponse
+			json.NewDecoder(w.Body).Decode(&errResp)
+			if errResp.Error.Code != tt.wantCode {
+				t.Errorf("error code = %q, want %q", errResp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_RequestEmailVerification(t *testing.T) {
+	_, userSvc, _, userRepo, _, _, _ := setupWiredAuthHandler(t)
+	h := NewAuthHandler(nil)
+
+	user := &domain.User{ID: uuid.New(), Email: "verify@example.com", IsActive: true}
+	userRepo.AddUser(user)
+	ctx := context.WithValue(context.Background(), UserIDContextKey, user.ID)
+
+	w := httptest.NewRecorder()
+	h.RequestEmailVerification(w, httptest.NewRequest("POST", "/email/verify/request", nil).WithContext(ctx), userSvc)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusAccepted, w.Body.String())
+	}
+
+	verifiedAt := time.Now()
+	user.EmailVerifiedAt = &verifiedAt
+	w = httptest.NewRecorder()
+	h.RequestEmailVerification(w, httptest.NewRequest("POST", "/email/verify/request", nil).WithContext(ctx), userSvc)
+	assertErrorCode(t, w, http.StatusConflict, "email_already_verified")
+
+	w = httptest.NewRecorder()
+	h.RequestEmailVerification(w, httptest.NewRequest("POST", "/email/verify/request", nil), userSvc)
+	assertErrorCode(t, w, http.StatusUnauthorized, "unauthorized")
+}
+
+func TestAuthHandler_RequestEmailChange_Errors(t *testing.T) {
+	_, userSvc, _, userRepo, _, _, _ := setupWiredAuthHandler(t)
+	h := NewAuthHandler(nil)
+
+	passwordHash, _ := service.NewPasswordService().Hash("OldPassword123!")
+	user := &domain.User{ID: uuid.New(), Email: "change@example.com", PasswordHash: passwordHash, IsActive: true}
+	userRepo.AddUser(user)
+	userRepo.AddUser(&domain.User{ID: uuid.New(), Email: "taken@example.com", IsActive: true})
+	ctx := context.WithValue(context.Background(), UserIDContextKey, user.ID)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"invalid body", "invalid json", http.StatusBadRequest, "invalid_request"},
+		{"missing password", `{"new_email":"new@example.com"}`, http.StatusBadRequest, "invalid_request"},
+		{"malformed address", `{"new_email":"nope","current_password":"OldPassword123!"}`, http.StatusBadRequest, "invalid_email"},
+		{"same address", `{"new_email":"change@example.com","current_password":"OldPassword123!"}`, http.StatusBadRequest, "email_unchanged"},
+		{"wrong password", `{"new_email":"new@example.com","current_password":"WrongOld!"}`, http.StatusBadRequest, "current_password_incorrect"},
+		{"address taken", `{"new_email":"taken@example.com","current_password":"OldPassword123!"}`, http.StatusConflict, "email_exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.RequestEmailChange(w, httptest.NewRequest("POST", "/email/change", strings.NewReader(tt.body)).WithContext(ctx), userSvc)
+			assertErrorCode(t, w, tt.wantStatus, tt.wantCode)
+		})
+	}
+}
+
+func TestAuthHandler_EmailLinks_Errors(t *testing.T) {
+	_, userSvc, _, _, _, _, _ := setupWiredAuthHandler(t)
+	h := NewAuthHandler(nil)
+
+	endpoints := []struct {
+		path   string
+		handle func(http.ResponseWriter, *http.Request, *service.UserService)
+	}{
+		{"/email/verify", h.VerifyEmail},
+		{"/email/change/confirm", h.ConfirmEmailChange},
+		{"/email/change/revert", h.RevertEmailChange},
+	}
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+	}{
+		{"invalid body", "invalid json", "invalid_request"},
+		{"missing token", `{}`, "invalid_request"},
+		{"unknown token", `{"token":"nonexistent"}`, "token_invalid"},
+	}
+
+	for _, e := range endpoints {
+		for _, tt := range tests {
+			t.Run(e.path+" "+tt.name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				e.handle(w, httptest.NewRequest("POST", e.path, strings.NewReader(tt.body)), userSvc)
+				assertErrorCode(t, w, http.StatusBadRequest, tt.wantCode)
+			})
+		}
+	}
+}
+
 func TestAuthHandler_Login_MissingFields(t *testing.T) {
 	handler := NewAuthHandler(nil)
 
@@ -463,6 +756,29 @@ func TestAuthHandler_Me_WithClaims(t *testing.T) {
 	if resp.Role != "manager" {
 		t.Errorf("Role = %q, want %q", resp.Role, "manager")
 	}
+	if !slices.Contains(resp.Permissions, string(domain.PermUsersManage)) {
+		t.Errorf("Permissions = %v, want to include %q", resp.Permissions, domain.PermUsersManage)
+	}
+}
+
+func TestAuthHandler_Me_Impersonation(t *testing.T) {
+	handler := NewAuthHandler(nil)
+
+	actorID := uuid.New()
+	claims := &domain.Claims{Role: domain.RoleWaiter, Actor: &domain.Actor{Subject: actorID.String()}}
+	claims.Subject = uuid.NewString()
+
+	ctx := context.WithValue(context.Background(), UserContextKey, claims)
+	req := httptest.NewRequest("GET", "/me", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Me(w, req)
+
+	var resp MeResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.ImpersonatedBy == nil || *resp.ImpersonatedBy != actorID {
+		t.Errorf("ImpersonatedBy = %v, want %s", resp.ImpersonatedBy, actorID)
+	}
 }
 
 func TestAuthHandler_ChangePassword_Unauthorized(t *testing.T) {
@@ -588,3 +904,83 @@ func TestAuthHandler_CompletePasswordReset_InvalidBody(t *testing.T) {
 		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
 	}
 }
+
+func TestAuthHandler_SwitchTenant(t *testing.T) {
+	h, _, _, userRepo, tenantRepo, _, sessionRepo := setupWiredAuthHandler(t)
+
+	fromID, toID, userID := uuid.New(), uuid.New(), uuid.New()
+	tenantRepo.AddTenant(&domain.Tenant{ID: fromID, Name: "Acme", Slug: "acme", IsActive: true})
+	tenantRepo.AddTenant(&domain.Tenant{ID: toID, Name: "Beta", Slug: "beta", IsActive: true})
+	userRepo.AddUser(&domain.User{
+		ID: userID, Email: "multi@example.com", IsActive: true,
+		TenantRoles: []domain.UserTenantRole{
+			{ID: uuid.New(), UserID: userID, TenantID: fromID, Role: domain.RoleWaiter},
+			{ID: uuid.New(), UserID: userID, TenantID: toID, Role: domain.RoleCashier},
+		},
+	})
+	session := addSession(t, sessionRepo, userID, fromID, "Mozilla/5.0")
+
+	body, _ := json.Marshal(SwitchTenantRequest{TenantID: toID})
+	req := httptest.NewRequest("POST", "/switch-tenant", bytes.NewReader(body)).WithContext(sessionContext(userID, fromID, session.ID))
+	w := httptest.NewRecorder()
+	h.SwitchTenant(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp LoginResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.AccessToken == "" || resp.RefreshToken == "" {
+		t.Errorf("expected a new token pair, got %+v", resp)
+	}
+	if resp.User.TenantID != toID || resp.User.Role != string(domain.RoleCashier) {
+		t.Errorf("User = %+v, want cashier in %s", resp.User, toID)
+	}
+}
+
+func TestAuthHandler_SwitchTenant_Errors(t *testing.T) {
+	h, _, _, userRepo, tenantRepo, _, sessionRepo := setupWiredAuthHandler(t)
+
+	tenantID, userID := uuid.New(), uuid.New()
+	tenantRepo.AddTenant(&domain.Tenant{ID: tenantID, Name: "Acme", Slug: "acme", IsActive: true})
+	userRepo.AddUser(&domain.User{
+		ID: userID, Email: "single@example.com", IsActive: true,
+		TenantRoles: []domain.UserTenantRole{{ID: uuid.New(), UserID: userID, TenantID: tenantID, Role: domain.RoleWaiter}},
+	})
+	session := addSession(t, sessionRepo, userID, tenantID, "Mozilla/5.0")
+	ctx := sessionContext(userID, tenantID, session.ID)
+
+	// PIN logins carry no session
+	noSession := context.WithValue(authedContext(userID, tenantID, domain.RoleWaiter), UserContextKey,
+		domain.NewClaims(userID, tenantID, "single@example.com", domain.RoleWaiter, time.Now().Add(time.Hour)))
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"no session", noSession, `{"tenant_id":"` + uuid.NewString() + `"}`, http.StatusUnauthorized, "session_required"},
+		{"missing tenant", ctx, `{}`, http.StatusBadRequest, "invalid_request"},
+		{"same tenant", ctx, `{"tenant_id":"` + tenantID.String() + `"}`, http.StatusBadRequest, "same_tenant"},
+		{"not a member", ctx, `{"tenant_id":"` + uuid.NewString() + `"}`, http.StatusBadRequest, "invalid_tenant"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/switch-tenant", strings.NewReader(tt.body)).WithContext(tt.ctx)
+			w := httptest.NewRecorder()
+			h.SwitchTenant(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var resp ErrorResponse
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", resp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/solobueno/erp/internal/auth/domain"
 	"github.com/solobueno/erp/internal/auth/service"
+	"github.com/solobueno/erp/pkg/webauthn"
 )
 
 // --- Request DTOs ---
@@ -16,6 +17,9 @@ type LoginRequest struct {
 	Email    string     `json:"email"`
 	Password string     `json:"password"`
 	TenantID *uuid.UUID `json:"tenant_id,omitempty"`
+	// DeviceToken is the device_token an earlier login returned, marking
+	// this as a known device with its own failed-login threshold.
+	DeviceToken string `json:"device_token,omitempty"`
 }
 
 // RefreshRequest is the request body for POST /refresh.
@@ -23,6 +27,11 @@ type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+// SwitchTenantRequest is the request body for POST /switch-tenant.
+type SwitchTenantRequest struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+}
+
 // ChangePasswordRequest is the request body for POST /change-password.
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password"`
@@ -40,8 +49,75 @@ type PasswordResetCompleteRequest struct {
 	NewPassword string `json:"new_password"`
 }
 
-// CreateUserRequest is the request body for POST /users.
-type CreateUserRequest struct {
+// AccountUnlockRequest is the request body for POST /unlock/request.
+type AccountUnlockRequest struct {
+	Email string `json:"email"`
+}
+
+// EmailTokenRequest is the request body for following an emailed link:
+// POST /email/verify, /email/change/confirm, /email/change/revert and
+// /unlock.
+type EmailTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// EmailChangeRequest is the request body for POST /email/change.
+type EmailChangeRequest struct {
+	NewEmail        string `json:"new_email"`
+	CurrentPassword string `json:"current_password"`
+}
+
+// AcceptInvitationRequest is the request body for POST /invitations/accept.
+// Password is the new account's password, or the current password of the
+// invitee's existing account.
+type AcceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// ConfirmOwnershipTransferRequest is the request body for POST
+// /ownership-transfer/confirm. Password is the recipient's current password.
+type ConfirmOwnershipTransferRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// MFAVerifyRequest is the request body for POST /mfa/verify.
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"` // TOTP code or recovery code
+}
+
+// MFASetupRequest is the request body for POST /mfa/setup and POST
+// /mfa/passkey/begin.
+type MFASetupRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+// MFACodeRequest is the request body for endpoints that re-verify a TOTP or recovery code.
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// PINLoginRequest is the request body for POST /pin-login.
+type PINLoginRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+	PIN    string    `json:"pin"`
+}
+
+// SetPINRequest is the request body for PUT /pin.
+type SetPINRequest struct {
+	CurrentPassword string `json:"current_password"`
+	PIN             string `json:"pin"`
+}
+
+// RegisterTerminalRequest is the request body for POST /terminals.
+type RegisterTerminalRequest struct {
+	Name string `json:"name"`
+}
+
+// InviteUserRequest is the request body for POST /users/invitations.
+type InviteUserRequest struct {
 	Email     string      `json:"email"`
 	FirstName string      `json:"first_name"`
 	LastName  string      `json:"last_name"`
@@ -55,9 +131,185 @@ type UpdateUserRequest struct {
 	IsActive  *bool   `json:"is_active,omitempty"`
 }
 
-// UpdateRoleRequest is the request body for PATCH /users/{id}/role.
+// UpdateRoleRequest is the request body for PATCH /users/{id}/role. Set
+// either a built-in role or one of the tenant's custom roles.
 type UpdateRoleRequest struct {
-	Role domain.Role `json:"role"`
+	Role         domain.Role `json:"role,omitempty"`
+	CustomRoleID *uuid.UUID  `json:"custom_role_id,omitempty"`
+}
+
+// ElevateRoleRequest is the request body for POST /users/{id}/role/elevation.
+type ElevateRoleRequest struct {
+	Role         domain.Role `json:"role,omitempty"`
+	CustomRoleID *uuid.UUID  `json:"custom_role_id,omitempty"`
+	// ValidFrom defaults to now.
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil time.Time  `json:"valid_until"`
+}
+
+// ImpersonateRequest is the request body for POST /users/{id}/impersonate.
+type ImpersonateRequest struct {
+	// Reason is recorded on the audit trail, e.g. a support ticket.
+	Reason string `json:"reason"`
+}
+
+// StartOwnershipTransferRequest is the request body for POST /users/ownership-transfer.
+type StartOwnershipTransferRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// ApprovalRequest is the request body for POST /approvals. The approver is
+// identified by ID or email and confirms with exactly one of a password or
+// a PIN.
+type ApprovalRequest struct {
+	Action        domain.Permission `json:"action"`
+	ResourceID    string            `json:"resource_id"`
+	ApproverID    *uuid.UUID        `json:"approver_id,omitempty"`
+	ApproverEmail string            `json:"approver_email,omitempty"`
+	Password      string            `json:"password,omitempty"`
+	PIN           string            `json:"pin,omitempty"`
+}
+
+// CreateCustomRoleRequest is the request body for POST /roles.
+type CreateCustomRoleRequest struct {
+	Name        string              `json:"name"`
+	BaseRole    domain.Role         `json:"base_role"`
+	Permissions []domain.Permission `json:"permissions"`
+}
+
+// UpdateCustomRoleRequest is the request body for PATCH /roles/{id}.
+type UpdateCustomRoleRequest struct {
+	Name        *string             `json:"name,omitempty"`
+	Permissions []domain.Permission `json:"permissions,omitempty"`
+}
+
+// SSOStartRequest is the request body for POST /sso/start.
+type SSOStartRequest struct {
+	TenantSlug string `json:"tenant_slug"`
+}
+
+// SSOCallbackRequest is the request body for POST /sso/callback, with the
+// parameters the identity provider added to the redirect URI.
+type SSOCallbackRequest struct {
+	State string `json:"state"`
+	Code  string `json:"code"`
+}
+
+// SSOConfigRequest is the request body for PUT /sso/config.
+type SSOConfigRequest struct {
+	Issuer   string `json:"issuer"`
+	ClientID string `json:"client_id"`
+	// ClientSecret may be omitted on update to keep the current one.
+	ClientSecret          string   `json:"client_secret,omitempty"`
+	AllowedDomains        []string `json:"allowed_domains"`
+	PasswordLoginDisabled bool     `json:"password_login_disabled"`
+}
+
+// PasswordPolicyRequest is the request body for PUT /password-policy.
+type PasswordPolicyRequest struct {
+	MinLength        int  `json:"min_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSymbol    bool `json:"require_symbol"`
+	MaxAgeDays       int  `json:"max_age_days"`  // 0 = passwords never expire
+	HistoryCount     int  `json:"history_count"` // 0 = reuse allowed
+}
+
+// LockoutPolicyRequest is the request body for PUT /lockout-policy.
+type LockoutPolicyRequest struct {
+	UnknownDeviceThreshold int `json:"unknown_device_threshold"`
+	KnownDeviceThreshold   int `json:"known_device_threshold"`
+	BaseDelaySeconds       int `json:"base_delay_seconds"`
+	MaxDelaySeconds        int `json:"max_delay_seconds"`
+}
+
+// CreateSCIMTokenRequest is the request body for POST /scim/tokens.
+type CreateSCIMTokenRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateServiceAccountRequest is the request body for POST /service-accounts.
+type CreateServiceAccountRequest struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Scopes      []domain.Permission `json:"scopes"`
+}
+
+// UpdateServiceAccountRequest is the request body for PATCH /service-accounts/{id}.
+type UpdateServiceAccountRequest struct {
+	Name        *string             `json:"name,omitempty"`
+	Description *string             `json:"description,omitempty"`
+	Scopes      []domain.Permission `json:"scopes,omitempty"`
+}
+
+// CreateAPIKeyRequest is the request body for POST /service-accounts/{id}/keys.
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+	// ExpiresInDays defaults to 90 and may be at most 365.
+	ExpiresInDays int `json:"expires_in_days,omitempty"`
+}
+
+// RotateAPIKeyRequest is the request body for POST /service-accounts/{id}/keys/{keyId}/rotate.
+type RotateAPIKeyRequest struct {
+	// ExpiresInDays is the new key's lifetime, as for CreateAPIKeyRequest.
+	ExpiresInDays int `json:"expires_in_days,omitempty"`
+	// GracePeriodHours is how long the old key keeps working, at most 168
+	// (7 days). Zero revokes it right away.
+	GracePeriodHours int `json:"grace_period_hours"`
+}
+
+// CreateOAuthClientRequest is the request body for POST /oauth/clients.
+type CreateOAuthClientRequest struct {
+	Name         string              `json:"name"`
+	RedirectURIs []string            `json:"redirect_uris"`
+	Scopes       []domain.Permission `json:"scopes"`
+	// Public registers a client that can't keep a secret, such as a mobile
+	// or browser app. It gets no secret and relies on PKCE.
+	Public bool `json:"public,omitempty"`
+}
+
+// UpdateOAuthClientRequest is the request body for PATCH /oauth/clients/{id}.
+type UpdateOAuthClientRequest struct {
+	Name         *string             `json:"name,omitempty"`
+	RedirectURIs []string            `json:"redirect_uris,omitempty"`
+	Scopes       []domain.Permission `json:"scopes,omitempty"`
+}
+
+// OAuthAuthorizeRequest is the request body for POST /oauth/authorize: the
+// authorization request's parameters and the user's decision.
+type OAuthAuthorizeRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri,omitempty"`
+	Scope               string `json:"scope,omitempty"`
+	State               string `json:"state,omitempty"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	Approve             bool   `json:"approve"`
+}
+
+// PasskeyRegisterRequest is the request body for POST /passkeys/register/finish.
+type PasskeyRegisterRequest struct {
+	Name       string                        `json:"name,omitempty"` // Defaults to "Passkey"
+	Credential webauthn.RegistrationResponse `json:"credential"`
+}
+
+// RenamePasskeyRequest is the request body for PATCH /passkeys/{id}.
+type RenamePasskeyRequest struct {
+	Name string `json:"name"`
+}
+
+// PasskeyLoginRequest is the request body for POST /passkeys/login/finish.
+type PasskeyLoginRequest struct {
+	Credential webauthn.AssertionResponse `json:"credential"`
+	TenantID   *uuid.UUID                 `json:"tenant_id,omitempty"`
+}
+
+// PasskeyMFARequest is the request body for POST /mfa/passkey/verify.
+type PasskeyMFARequest struct {
+	MFAToken   string                     `json:"mfa_token"`
+	Credential webauthn.AssertionResponse `json:"credential"`
 }
 
 // --- Response DTOs ---
@@ -70,6 +322,11 @@ type LoginResponse struct {
 	ExpiresIn    int          `json:"expires_in"`
 	ExpiresAt    time.Time    `json:"expires_at"`
 	User         UserResponse `json:"user"`
+	// RecoveryCodes is only set on the login that completes a first-time MFA enrollment.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+	// DeviceToken identifies this device on later logins; send it back as
+	// the login request's device_token.
+	DeviceToken string `json:"device_token,omitempty"`
 }
 
 // TenantRequiredResponse is returned when user must select a tenant.
@@ -97,6 +354,7 @@ type TokenResponse struct {
 type UserResponse struct {
 	ID                uuid.UUID `json:"id"`
 	Email             string    `json:"email"`
+	EmailVerified     bool      `json:"email_verified"`
 	FirstName         string    `json:"first_name"`
 	LastName          string    `json:"last_name"`
 	Role              string    `json:"role,omitempty"`
@@ -105,6 +363,24 @@ type UserResponse struct {
 	MustResetPassword bool      `json:"must_reset_password"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at,omitempty"`
+	// CustomRoleID and CustomRole identify the tenant custom role the user
+	// holds, if any; Role is then its base role.
+	CustomRoleID *uuid.UUID `json:"custom_role_id,omitempty"`
+	CustomRole   string     `json:"custom_role,omitempty"`
+	// Elevation is the user's scheduled or running temporary elevation;
+	// Role and CustomRole are the standing role it reverts to.
+	Elevation *RoleElevationResponse `json:"elevation,omitempty"`
+}
+
+// RoleElevationResponse describes a temporary role elevation.
+type RoleElevationResponse struct {
+	Role         string     `json:"role"`
+	CustomRoleID *uuid.UUID `json:"custom_role_id,omitempty"`
+	CustomRole   string     `json:"custom_role,omitempty"`
+	ValidFrom    time.Time  `json:"valid_from"`
+	ValidUntil   time.Time  `json:"valid_until"`
+	// Active is true while the elevated role applies.
+	Active bool `json:"active"`
 }
 
 // MeResponse is the response for GET /me.
@@ -118,6 +394,10 @@ type MeResponse struct {
 	TenantName        string           `json:"tenant_name"`
 	MustResetPassword bool             `json:"must_reset_password"`
 	Tenants           []TenantRoleInfo `json:"tenants"`
+	Permissions       []string         `json:"permissions"`
+	// ImpersonatedBy is set while another user is impersonating this one;
+	// clients should show it prominently.
+	ImpersonatedBy *uuid.UUID `json:"impersonated_by,omitempty"`
 }
 
 // TenantRoleInfo represents a user's role in a tenant.
@@ -127,19 +407,50 @@ type TenantRoleInfo struct {
 	Role string    `json:"role"`
 }
 
-// CreateUserResponse is the response for POST /users. It never includes the
-// temporary password (or, for a linked existing user, no password at all) -
-// that is emailed directly to the user, never returned in the API response.
-type CreateUserResponse struct {
-	ID                    uuid.UUID `json:"id"`
-	Email                 string    `json:"email"`
-	FirstName             string    `json:"first_name"`
-	LastName              string    `json:"last_name"`
-	Role                  string    `json:"role"`
-	MustResetPassword     bool      `json:"must_reset_password"`
-	LinkedExistingAccount bool      `json:"linked_existing_account,omitempty"`
-	Message               string    `json:"message"`
-	CreatedAt             time.Time `json:"created_at"`
+// InvitationResponse represents a pending invitation in API responses. It
+// never includes the invite token; that is emailed directly to the invitee.
+type InvitationResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Role      string    `json:"role"`
+	InvitedBy uuid.UUID `json:"invited_by"`
+	Expired   bool      `json:"expired"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExistingAccount is set when sending or resending, if the email already
+	// has an account that the invitee will confirm rather than create.
+	ExistingAccount bool `json:"existing_account,omitempty"`
+}
+
+// InvitationListResponse is the response for GET /users/invitations.
+type InvitationListResponse struct {
+	Data []InvitationResponse `json:"data"`
+}
+
+// AcceptInvitationResponse is the response for POST /invitations/accept.
+type AcceptInvitationResponse struct {
+	User            UserResponse `json:"user"`
+	TenantID        uuid.UUID    `json:"tenant_id"`
+	Role            string       `json:"role"`
+	ExistingAccount bool         `json:"existing_account"`
+}
+
+// OwnershipTransferResponse represents an ownership transfer in API
+// responses. It never includes the confirmation token; that is emailed
+// directly to the recipient.
+type OwnershipTransferResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	FromUserID  uuid.UUID  `json:"from_user_id"`
+	ToUserID    uuid.UUID  `json:"to_user_id"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	// PreviousOwnerRole is set once confirmed: the role the previous owner
+	// now holds (the recipient's role before the swap).
+	PreviousOwnerRole string `json:"previous_owner_role,omitempty"`
 }
 
 // UserListResponse is the response for GET /users.
@@ -156,6 +467,342 @@ type Pagination struct {
 	TotalPages int   `json:"total_pages"`
 }
 
+// MFAEnrollmentResponse is the response for starting TOTP enrollment.
+type MFAEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFAStatusResponse is the response for GET /mfa.
+type MFAStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	Required               bool  `json:"required"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// RecoveryCodesResponse returns freshly issued MFA recovery codes. They are
+// shown once; only their hashes are stored.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// SessionResponse represents a signed-in device in API responses.
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	TenantID   uuid.UUID `json:"tenant_id"`
+	DeviceName string    `json:"device_name"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Current is true for the session the request was made from.
+	Current bool `json:"current"`
+}
+
+// SessionListResponse is the response for session listings.
+type SessionListResponse struct {
+	Data []SessionResponse `json:"data"`
+}
+
+// PasskeyResponse represents a registered passkey in API responses.
+type PasskeyResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	Transports []string  `json:"transports"`
+	// Synced is true for passkeys that sync between the user's devices.
+	Synced     bool       `json:"synced"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// PasskeyListResponse is the response for GET /passkeys.
+type PasskeyListResponse struct {
+	Data []PasskeyResponse `json:"data"`
+}
+
+// PasskeyCreationOptionsResponse is the response that starts a passkey
+// registration. Pass it to navigator.credentials.create() after decoding
+// the base64url fields.
+type PasskeyCreationOptionsResponse struct {
+	PublicKey *webauthn.CreationOptions `json:"publicKey"`
+}
+
+// PasskeyRequestOptionsResponse is the response that starts a passkey
+// login. Pass it to navigator.credentials.get() after decoding the
+// base64url fields.
+type PasskeyRequestOptionsResponse struct {
+	PublicKey *webauthn.RequestOptions `json:"publicKey"`
+}
+
+// PINLoginResponse is the response for a successful PIN login. There is no
+// refresh token: the token is short-lived and the next user logs in over it.
+type PINLoginResponse struct {
+	AccessToken string       `json:"access_token"`
+	TokenType   string       `json:"token_type"`
+	ExpiresIn   int          `json:"expires_in"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	User        UserResponse `json:"user"`
+}
+
+// ImpersonationResponse is the response for a successful impersonation. Like
+// a PIN login there is no refresh token; the token is short-lived.
+type ImpersonationResponse struct {
+	AccessToken    string       `json:"access_token"`
+	TokenType      string       `json:"token_type"`
+	ExpiresIn      int          `json:"expires_in"`
+	ExpiresAt      time.Time    `json:"expires_at"`
+	User           UserResponse `json:"user"`
+	ImpersonatedBy uuid.UUID    `json:"impersonated_by"`
+}
+
+// TerminalResponse represents a registered POS terminal in API responses.
+type TerminalResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// RegisterTerminalResponse is the response for POST /terminals. The
+// terminal token is shown once; only its hash is stored.
+type RegisterTerminalResponse struct {
+	TerminalResponse
+	TerminalToken string `json:"terminal_token"`
+}
+
+// TerminalListResponse is the response for GET /terminals.
+type TerminalListResponse struct {
+	Data []TerminalResponse `json:"data"`
+}
+
+// TerminalStaffMember is a user offered for PIN login on a terminal.
+type TerminalStaffMember struct {
+	ID        uuid.UUID `json:"id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Role      string    `json:"role"`
+}
+
+// TerminalStaffResponse is the response for GET /terminal/staff.
+type TerminalStaffResponse struct {
+	Data []TerminalStaffMember `json:"data"`
+}
+
+// ApprovalResponse is the response for POST /approvals. The approval token
+// is shown once; only its hash is stored.
+type ApprovalResponse struct {
+	ID            uuid.UUID `json:"id"`
+	ApprovalToken string    `json:"approval_token"`
+	Action        string    `json:"action"`
+	ResourceID    string    `json:"resource_id"`
+	ApprovedBy    uuid.UUID `json:"approved_by"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// CustomRoleResponse represents a tenant custom role in API responses.
+type CustomRoleResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	BaseRole    string    `json:"base_role"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CustomRoleListResponse is the response for GET /roles.
+type CustomRoleListResponse struct {
+	Data []CustomRoleResponse `json:"data"`
+}
+
+// PermissionResponse describes a grantable permission in API responses.
+type PermissionResponse struct {
+	Permission  string   `json:"permission"`
+	Description string   `json:"description"`
+	Roles       []string `json:"roles"`
+}
+
+// PermissionListResponse is the response for GET /roles/permissions.
+type PermissionListResponse struct {
+	Data []PermissionResponse `json:"data"`
+}
+
+// SSOStartResponse is the response for POST /sso/start.
+type SSOStartResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// SSOConfigResponse represents a tenant's SSO configuration in API
+// responses. The client secret is never returned.
+type SSOConfigResponse struct {
+	Issuer                string    `json:"issuer"`
+	ClientID              string    `json:"client_id"`
+	ClientSecretSet       bool      `json:"client_secret_set"`
+	AllowedDomains        []string  `json:"allowed_domains"`
+	PasswordLoginDisabled bool      `json:"password_login_disabled"`
+	RedirectURI           string    `json:"redirect_uri"` // Register this with the identity provider
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// PasswordPolicyResponse represents a tenant's password policy in API
+// responses. UpdatedAt is omitted while the tenant uses the default policy.
+type PasswordPolicyResponse struct {
+	MinLength        int        `json:"min_length"`
+	RequireUppercase bool       `json:"require_uppercase"`
+	RequireLowercase bool       `json:"require_lowercase"`
+	RequireDigit     bool       `json:"require_digit"`
+	RequireSymbol    bool       `json:"require_symbol"`
+	MaxAgeDays       int        `json:"max_age_days"`
+	HistoryCount     int        `json:"history_count"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+}
+
+// LockoutPolicyResponse represents a tenant's lockout policy in API
+// responses. UpdatedAt is omitted while the tenant uses the default policy.
+type LockoutPolicyResponse struct {
+	UnknownDeviceThreshold int        `json:"unknown_device_threshold"`
+	KnownDeviceThreshold   int        `json:"known_device_threshold"`
+	BaseDelaySeconds       int        `json:"base_delay_seconds"`
+	MaxDelaySeconds        int        `json:"max_delay_seconds"`
+	UpdatedAt              *time.Time `json:"updated_at,omitempty"`
+}
+
+// SCIMTokenResponse represents a tenant's SCIM token in API responses.
+type SCIMTokenResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	CreatedBy  uuid.UUID  `json:"created_by"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateSCIMTokenResponse is the response for POST /scim/tokens. The token
+// is shown once; only its hash is stored.
+type CreateSCIMTokenResponse struct {
+	SCIMTokenResponse
+	Token string `json:"token"`
+}
+
+// SCIMTokenListResponse is the response for GET /scim/tokens.
+type SCIMTokenListResponse struct {
+	Data []SCIMTokenResponse `json:"data"`
+}
+
+// ServiceAccountResponse represents a tenant service account in API responses.
+type ServiceAccountResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Scopes      []string  `json:"scopes"`
+	CreatedBy   uuid.UUID `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ServiceAccountListResponse is the response for GET /service-accounts.
+type ServiceAccountListResponse struct {
+	Data []ServiceAccountResponse `json:"data"`
+}
+
+// APIKeyResponse represents a service account API key in API responses.
+type APIKeyResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	Prefix        string     `json:"prefix"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
+	RotatedFromID *uuid.UUID `json:"rotated_from_id,omitempty"`
+	CreatedBy     uuid.UUID  `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse is the response for creating or rotating an API key.
+// The key is shown once; only its hash is stored.
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// APIKeyListResponse is the response for GET /service-accounts/{id}/keys.
+type APIKeyListResponse struct {
+	Data []APIKeyResponse `json:"data"`
+}
+
+// OAuthClientResponse represents an OAuth client in API responses.
+type OAuthClientResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	Public       bool      `json:"public"`
+	CreatedBy    uuid.UUID `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CreateOAuthClientResponse is the response for POST /oauth/clients. A
+// confidential client's secret is shown once; only its hash is stored.
+type CreateOAuthClientResponse struct {
+	OAuthClientResponse
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// OAuthClientSecretResponse is the response for POST /oauth/clients/{id}/secret.
+type OAuthClientSecretResponse struct {
+	ClientSecret string `json:"client_secret"`
+}
+
+// OAuthClientListResponse is the response for GET /oauth/clients.
+type OAuthClientListResponse struct {
+	Data []OAuthClientResponse `json:"data"`
+}
+
+// OAuthClientSummary names an OAuth client to the users asked to authorize
+// it.
+type OAuthClientSummary struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// OAuthAuthorizationResponse is the response for GET /oauth/authorize: what
+// the consent screen shows, or only RedirectTo if the request must be sent
+// back to the client with an error.
+type OAuthAuthorizationResponse struct {
+	Client      *OAuthClientSummary `json:"client,omitempty"`
+	RedirectURI string              `json:"redirect_uri,omitempty"`
+	Scopes      []string            `json:"scopes,omitempty"`
+	// ConsentedScopes are the scopes the tenant has already granted the
+	// client, if any.
+	ConsentedScopes []string `json:"consented_scopes,omitempty"`
+	RedirectTo      string   `json:"redirect_to,omitempty"`
+}
+
+// OAuthRedirectResponse is the response for POST /oauth/authorize.
+type OAuthRedirectResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// OAuthConsentResponse represents a tenant's authorization of an OAuth
+// client in API responses.
+type OAuthConsentResponse struct {
+	ID        uuid.UUID          `json:"id"`
+	Client    OAuthClientSummary `json:"client"`
+	Scopes    []string           `json:"scopes"`
+	GrantedBy uuid.UUID          `json:"granted_by"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// OAuthConsentListResponse is the response for GET /oauth/consents.
+type OAuthConsentListResponse struct {
+	Data []OAuthConsentResponse `json:"data"`
+}
+
+// OAuthErrorResponse is the error format of the OAuth token and revocation
+// endpoints (RFC 6749 section 5.2).
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
 // MessageResponse is a simple message response.
 type MessageResponse struct {
 	Message string `json:"message"`
@@ -175,6 +822,10 @@ type ErrorDetail struct {
 	RetryAfter  int            `json:"retry_after,omitempty"`
 	Tenants     []TenantOption `json:"tenants,omitempty"`
 	LockedUntil *time.Time     `json:"locked_until,omitempty"`
+	MFAToken    string         `json:"mfa_token,omitempty"`
+	MFAMethods  []string       `json:"mfa_methods,omitempty"`
+	Rule        string         `json:"rule,omitempty"`         // Password policy rule a new password broke
+	DeviceToken string         `json:"device_token,omitempty"` // Set on login challenges once the password is accepted
 }
 
 // --- Conversion Functions ---
@@ -190,6 +841,7 @@ func ToLoginResponse(resp *service.LoginResponse) *LoginResponse {
 		User: UserResponse{
 			ID:                resp.User.ID,
 			Email:             resp.User.Email,
+			EmailVerified:     resp.User.IsEmailVerified(),
 			FirstName:         resp.User.FirstName,
 			LastName:          resp.User.LastName,
 			Role:              string(resp.Role),
@@ -198,6 +850,8 @@ func ToLoginResponse(resp *service.LoginResponse) *LoginResponse {
 			MustResetPassword: resp.User.MustResetPwd,
 			CreatedAt:         resp.User.CreatedAt,
 		},
+		RecoveryCodes: resp.RecoveryCodes,
+		DeviceToken:   resp.DeviceToken,
 	}
 }
 
@@ -215,18 +869,305 @@ func ToTokenResponse(tp *domain.TokenPair) *TokenResponse {
 // ToUserResponse converts a domain user to an API response, scoped to the
 // given tenant so Role/TenantID reflect the caller's tenant context.
 func ToUserResponse(user *domain.User, tenantID uuid.UUID) *UserResponse {
-	return &UserResponse{
+	resp := &UserResponse{
 		ID:                user.ID,
 		Email:             user.Email,
+		EmailVerified:     user.IsEmailVerified(),
 		FirstName:         user.FirstName,
 		LastName:          user.LastName,
-		Role:              string(user.GetRoleForTenant(tenantID)),
 		TenantID:          tenantID,
 		IsActive:          user.IsActive,
 		MustResetPassword: user.MustResetPwd,
 		CreatedAt:         user.CreatedAt,
 		UpdatedAt:         user.UpdatedAt,
 	}
+	tr := user.GetTenantRole(tenantID)
+	if tr == nil {
+		return resp
+	}
+	resp.Role = string(tr.Role)
+	if tr.CustomRole != nil {
+		resp.CustomRoleID = tr.CustomRoleID
+		resp.CustomRole = tr.CustomRole.Name
+	}
+	if tr.HasElevation() && !tr.ElevationEnded() {
+		resp.Elevation = &RoleElevationResponse{
+			Role:         string(tr.ElevatedRole),
+			CustomRoleID: tr.ElevatedCustomRoleID,
+			ValidFrom:    *tr.ValidFrom,
+			ValidUntil:   *tr.ValidUntil,
+			Active:       tr.IsElevated(),
+		}
+		if tr.ElevatedCustomRole != nil {
+			resp.Elevation.CustomRole = tr.ElevatedCustomRole.Name
+		}
+	}
+	return resp
+}
+
+// ToMFAEnrollmentResponse converts a service enrollment to API response.
+func ToMFAEnrollmentResponse(e *service.MFAEnrollment) *MFAEnrollmentResponse {
+	return &MFAEnrollmentResponse{
+		Secret:     e.Secret,
+		OTPAuthURI: e.URI,
+	}
+}
+
+// ToSessionListResponse converts domain sessions to API response, flagging
+// currentSessionID (uuid.Nil if unknown) as the caller's own session.
+func ToSessionListResponse(sessions []*domain.Session, currentSessionID uuid.UUID) *SessionListResponse {
+	data := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		data[i] = SessionResponse{
+			ID:         s.ID,
+			TenantID:   s.TenantID,
+			DeviceName: s.DeviceName(),
+			IPAddress:  s.IPAddress,
+			CreatedAt:  s.CreatedAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    currentSessionID != uuid.Nil && s.ID == currentSessionID,
+		}
+	}
+	return &SessionListResponse{Data: data}
+}
+
+// ToPasskeyResponse converts a domain passkey to API response.
+func ToPasskeyResponse(p *domain.Passkey) PasskeyResponse {
+	transports := []string(p.Transports)
+	if transports == nil {
+		transports = []string{}
+	}
+	return PasskeyResponse{
+		ID:         p.ID,
+		Name:       p.Name,
+		Transports: transports,
+		Synced:     p.BackupEligible,
+		LastUsedAt: p.LastUsedAt,
+		CreatedAt:  p.CreatedAt,
+	}
+}
+
+// ToPasskeyListResponse converts domain passkeys to API response.
+func ToPasskeyListResponse(passkeys []*domain.Passkey) *PasskeyListResponse {
+	data := make([]PasskeyResponse, len(passkeys))
+	for i, p := range passkeys {
+		data[i] = ToPasskeyResponse(p)
+	}
+	return &PasskeyListResponse{Data: data}
+}
+
+// ToPINLoginResponse converts a service PIN login response to API response.
+func ToPINLoginResponse(resp *service.LoginResponse) *PINLoginResponse {
+	return &PINLoginResponse{
+		AccessToken: resp.TokenPair.AccessToken,
+		TokenType:   resp.TokenPair.TokenType,
+		ExpiresIn:   resp.TokenPair.ExpiresIn,
+		ExpiresAt:   resp.TokenPair.ExpiresAt,
+		User:        *ToUserResponse(resp.User, resp.TenantID),
+	}
+}
+
+// ToImpersonationResponse converts a service impersonation response to API
+// response.
+func ToImpersonationResponse(resp *service.LoginResponse, actorID uuid.UUID) *ImpersonationResponse {
+	return &ImpersonationResponse{
+		AccessToken:    resp.TokenPair.AccessToken,
+		TokenType:      resp.TokenPair.TokenType,
+		ExpiresIn:      resp.TokenPair.ExpiresIn,
+		ExpiresAt:      resp.TokenPair.ExpiresAt,
+		User:           *ToUserResponse(resp.User, resp.TenantID),
+		ImpersonatedBy: actorID,
+	}
+}
+
+// ToTerminalResponse converts a domain terminal to API response.
+func ToTerminalResponse(t *domain.Terminal) TerminalResponse {
+	return TerminalResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// ToTerminalListResponse converts domain terminals to API response.
+func ToTerminalListResponse(terminals []*domain.Terminal) *TerminalListResponse {
+	data := make([]TerminalResponse, len(terminals))
+	for i, t := range terminals {
+		data[i] = ToTerminalResponse(t)
+	}
+	return &TerminalListResponse{Data: data}
+}
+
+// ToTerminalStaffResponse converts the users offered on a terminal to API
+// response, with their role in the terminal's tenant.
+func ToTerminalStaffResponse(users []*domain.User, tenantID uuid.UUID) *TerminalStaffResponse {
+	data := make([]TerminalStaffMember, len(users))
+	for i, u := range users {
+		data[i] = TerminalStaffMember{
+			ID:        u.ID,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			Role:      string(u.GetRoleForTenant(tenantID)),
+		}
+	}
+	return &TerminalStaffResponse{Data: data}
+}
+
+// ToApprovalResponse converts a domain approval and its plain token to API response.
+func ToApprovalResponse(a *domain.Approval, token string) *ApprovalResponse {
+	return &ApprovalResponse{
+		ID:            a.ID,
+		ApprovalToken: token,
+		Action:        string(a.Action),
+		ResourceID:    a.ResourceID,
+		ApprovedBy:    a.ApprovedBy,
+		ExpiresAt:     a.ExpiresAt,
+	}
+}
+
+// ToSSOConfigResponse converts a domain SSO configuration to API response.
+func ToSSOConfigResponse(c *domain.TenantSSOConfig, redirectURI string) *SSOConfigResponse {
+	return &SSOConfigResponse{
+		Issuer:                c.Issuer,
+		ClientID:              c.ClientID,
+		ClientSecretSet:       c.ClientSecret != "",
+		AllowedDomains:        c.AllowedDomains,
+		PasswordLoginDisabled: c.PasswordLoginDisabled,
+		RedirectURI:           redirectURI,
+		UpdatedAt:             c.UpdatedAt,
+	}
+}
+
+// ToPasswordPolicyResponse converts a domain password policy to API response.
+func ToPasswordPolicyResponse(p *domain.PasswordPolicy) *PasswordPolicyResponse {
+	resp := &PasswordPolicyResponse{
+		MinLength:        p.MinLength,
+		RequireUppercase: p.RequireUppercase,
+		RequireLowercase: p.RequireLowercase,
+		RequireDigit:     p.RequireDigit,
+		RequireSymbol:    p.RequireSymbol,
+		MaxAgeDays:       p.MaxAgeDays,
+		HistoryCount:     p.HistoryCount,
+	}
+	if !p.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &p.UpdatedAt
+	}
+	return resp
+}
+
+// ToLockoutPolicyResponse converts a domain lockout policy to API response.
+func ToLockoutPolicyResponse(p *domain.LockoutPolicy) *LockoutPolicyResponse {
+	resp := &LockoutPolicyResponse{
+		UnknownDeviceThreshold: p.UnknownDeviceThreshold,
+		KnownDeviceThreshold:   p.KnownDeviceThreshold,
+		BaseDelaySeconds:       p.BaseDelaySeconds,
+		MaxDelaySeconds:        p.MaxDelaySeconds,
+	}
+	if !p.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &p.UpdatedAt
+	}
+	return resp
+}
+
+// ToSCIMTokenResponse converts a domain SCIM token to API response.
+func ToSCIMTokenResponse(t *domain.SCIMToken) SCIMTokenResponse {
+	return SCIMTokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		CreatedBy:  t.CreatedBy,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// ToSCIMTokenListResponse converts domain SCIM tokens to API response.
+func ToSCIMTokenListResponse(tokens []*domain.SCIMToken) *SCIMTokenListResponse {
+	data := make([]SCIMTokenResponse, len(tokens))
+	for i, t := range tokens {
+		data[i] = ToSCIMTokenResponse(t)
+	}
+	return &SCIMTokenListResponse{Data: data}
+}
+
+// ToInvitationResponse converts a domain invitation to API response.
+func ToInvitationResponse(inv *domain.Invitation, existingAccount bool) InvitationResponse {
+	return InvitationResponse{
+		ID:              inv.ID,
+		Email:           inv.Email,
+		FirstName:       inv.FirstName,
+		LastName:        inv.LastName,
+		Role:            string(inv.Role),
+		InvitedBy:       inv.InvitedBy,
+		Expired:         inv.IsExpired(),
+		ExpiresAt:       inv.ExpiresAt,
+		CreatedAt:       inv.CreatedAt,
+		ExistingAccount: existingAccount,
+	}
+}
+
+// ToInvitationListResponse converts domain invitations to API response.
+func ToInvitationListResponse(invitations []*domain.Invitation) *InvitationListResponse {
+	data := make([]InvitationResponse, len(invitations))
+	for i, inv := range invitations {
+		data[i] = ToInvitationResponse(inv, false)
+	}
+	return &InvitationListResponse{Data: data}
+}
+
+// ToOwnershipTransferResponse converts a domain ownership transfer to API response.
+func ToOwnershipTransferResponse(t *domain.OwnershipTransfer) OwnershipTransferResponse {
+	return OwnershipTransferResponse{
+		ID:          t.ID,
+		TenantID:    t.TenantID,
+		FromUserID:  t.FromUserID,
+		ToUserID:    t.ToUserID,
+		ExpiresAt:   t.ExpiresAt,
+		CreatedAt:   t.CreatedAt,
+		ConfirmedAt: t.ConfirmedAt,
+	}
+}
+
+// ToCustomRoleResponse converts a domain custom role to API response.
+func ToCustomRoleResponse(c *domain.CustomRole) CustomRoleResponse {
+	perms := make([]string, len(c.Permissions))
+	for i, p := range c.Permissions {
+		perms[i] = string(p)
+	}
+	return CustomRoleResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		BaseRole:    string(c.BaseRole),
+		Permissions: perms,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+// ToCustomRoleListResponse converts domain custom roles to API response.
+func ToCustomRoleListResponse(roles []*domain.CustomRole) *CustomRoleListResponse {
+	data := make([]CustomRoleResponse, len(roles))
+	for i, c := range roles {
+		data[i] = ToCustomRoleResponse(c)
+	}
+	return &CustomRoleListResponse{Data: data}
+}
+
+// ToPermissionListResponse converts permission definitions to API response.
+func ToPermissionListResponse(defs []domain.PermissionDefinition) *PermissionListResponse {
+	data := make([]PermissionResponse, len(defs))
+	for i, def := range defs {
+		roles := make([]string, len(def.Roles))
+		for j, r := range def.Roles {
+			roles[j] = string(r)
+		}
+		data[i] = PermissionResponse{
+			Permission:  string(def.Permission),
+			Description: def.Description,
+			Roles:       roles,
+		}
+	}
+	return &PermissionListResponse{Data: data}
 }
 
 // ToTenantOptions converts service tenant info to API format.
@@ -241,3 +1182,116 @@ func ToTenantOptions(tenants []service.TenantInfo) []TenantOption {
 	}
 	return options
 }
+
+// ToServiceAccountResponse converts a domain service account to API response.
+func ToServiceAccountResponse(a *domain.ServiceAccount) ServiceAccountResponse {
+	scopes := make([]string, len(a.Scopes))
+	for i, p := range a.Scopes {
+		scopes[i] = string(p)
+	}
+	return ServiceAccountResponse{
+		ID:          a.ID,
+		Name:        a.Name,
+		Description: a.Description,
+		Scopes:      scopes,
+		CreatedBy:   a.CreatedBy,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+// ToServiceAccountListResponse converts domain service accounts to API response.
+func ToServiceAccountListResponse(accounts []*domain.ServiceAccount) *ServiceAccountListResponse {
+	data := make([]ServiceAccountResponse, len(accounts))
+	for i, a := range accounts {
+		data[i] = ToServiceAccountResponse(a)
+	}
+	return &ServiceAccountListResponse{Data: data}
+}
+
+// ToAPIKeyResponse converts a domain API key to API response.
+func ToAPIKeyResponse(k *domain.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:            k.ID,
+		Name:          k.Name,
+		Prefix:        k.Prefix,
+		ExpiresAt:     k.ExpiresAt,
+		LastUsedAt:    k.LastUsedAt,
+		RotatedFromID: k.RotatedFromID,
+		CreatedBy:     k.CreatedBy,
+		CreatedAt:     k.CreatedAt,
+	}
+}
+
+// ToAPIKeyListResponse converts domain API keys to API response.
+func ToAPIKeyListResponse(keys []*domain.APIKey) *APIKeyListResponse {
+	data := make([]APIKeyResponse, len(keys))
+	for i, k := range keys {
+		data[i] = ToAPIKeyResponse(k)
+	}
+	return &APIKeyListResponse{Data: data}
+}
+
+// ToOAuthClientResponse converts a domain OAuth client to API response.
+func ToOAuthClientResponse(c *domain.OAuthClient) OAuthClientResponse {
+	redirectURIs := make([]string, len(c.RedirectURIs))
+	copy(redirectURIs, c.RedirectURIs)
+	return OAuthClientResponse{
+		ID:           c.ID,
+		Name:         c.Name,
+		RedirectURIs: redirectURIs,
+		Scopes:       permissionStrings(c.Scopes),
+		Public:       c.Public,
+		CreatedBy:    c.CreatedBy,
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+	}
+}
+
+// ToOAuthClientListResponse converts domain OAuth clients to API response.
+func ToOAuthClientListResponse(clients []*domain.OAuthClient) *OAuthClientListResponse {
+	data := make([]OAuthClientResponse, len(clients))
+	for i, c := range clients {
+		data[i] = ToOAuthClientResponse(c)
+	}
+	return &OAuthClientListResponse{Data: data}
+}
+
+// ToOAuthAuthorizationResponse converts a validated authorization request
+// to API response.
+func ToOAuthAuthorizationResponse(a *service.OAuthAuthorization) *OAuthAuthorizationResponse {
+	if a.RedirectTo != "" {
+		return &OAuthAuthorizationResponse{RedirectTo: a.RedirectTo}
+	}
+	return &OAuthAuthorizationResponse{
+		Client:          &OAuthClientSummary{ID: a.Client.ID, Name: a.Client.Name},
+		RedirectURI:     a.RedirectURI,
+		Scopes:          permissionStrings(a.Scopes),
+		ConsentedScopes: permissionStrings(a.Consented),
+	}
+}
+
+// ToOAuthConsentListResponse converts domain OAuth consents to API response.
+func ToOAuthConsentListResponse(consents []*domain.OAuthConsent) *OAuthConsentListResponse {
+	data := make([]OAuthConsentResponse, len(consents))
+	for i, c := range consents {
+		data[i] = OAuthConsentResponse{
+			ID:        c.ID,
+			Client:    OAuthClientSummary{ID: c.ClientID, Name: c.Client.Name},
+			Scopes:    permissionStrings(c.Scopes),
+			GrantedBy: c.GrantedBy,
+			CreatedAt: c.CreatedAt,
+			UpdatedAt: c.UpdatedAt,
+		}
+	}
+	return &OAuthConsentListResponse{Data: data}
+}
+
+// permissionStrings converts permissions to the strings API responses carry.
+func permissionStrings(perms []domain.Permission) []string {
+	out := make([]string, len(perms))
+	for i, p := range perms {
+		out[i] = string(p)
+	}
+	return out
+}
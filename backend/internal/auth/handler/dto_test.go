@@ -125,6 +125,37 @@ func TestToUserResponse(t *testing.T) {
 	if result.TenantID != tenantID {
 		t.Error("TenantID mismatch")
 	}
+	if result.CustomRoleID != nil || result.CustomRole != "" {
+		t.Errorf("custom role = %v/%q, want none", result.CustomRoleID, result.CustomRole)
+	}
+
+	customRoleID := uuid.New()
+	user.TenantRoles[0].CustomRoleID = &customRoleID
+	user.TenantRoles[0].CustomRole = &domain.CustomRole{ID: customRoleID, Name: "Bartender", BaseRole: domain.RoleWaiter}
+	result = ToUserResponse(user, tenantID)
+	if result.Role != string(domain.RoleWaiter) || result.CustomRole != "Bartender" || result.CustomRoleID == nil || *result.CustomRoleID != customRoleID {
+		t.Errorf("custom role response = %s/%v/%q, want waiter with Bartender", result.Role, result.CustomRoleID, result.CustomRole)
+	}
+	if result.Elevation != nil {
+		t.Errorf("Elevation = %+v, want none", result.Elevation)
+	}
+
+	// Role stays the standing role; the elevation is reported separately
+	validFrom, validUntil := now.Add(-time.Hour), now.Add(time.Hour)
+	user.TenantRoles[0].ElevatedRole = domain.RoleManager
+	user.TenantRoles[0].ValidFrom, user.TenantRoles[0].ValidUntil = &validFrom, &validUntil
+	result = ToUserResponse(user, tenantID)
+	if result.Role != string(domain.RoleWaiter) {
+		t.Errorf("Role while elevated = %q, want the standing %q", result.Role, domain.RoleWaiter)
+	}
+	if e := result.Elevation; e == nil || e.Role != string(domain.RoleManager) || !e.Active || !e.ValidUntil.Equal(validUntil) {
+		t.Errorf("Elevation = %+v, want an active manager elevation until %v", e, validUntil)
+	}
+
+	validUntil = now.Add(-time.Minute)
+	if result = ToUserResponse(user, tenantID); result.Elevation != nil {
+		t.Errorf("Elevation after it ended = %+v, want none", result.Elevation)
+	}
 }
 
 func TestToTenantOptions(t *testing.T) {
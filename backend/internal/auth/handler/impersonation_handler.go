@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// maxImpersonationReasonLength bounds the reason kept in the audit event.
+const maxImpersonationReasonLength = 500
+
+// ImpersonationHandler handles admin impersonation endpoints.
+type ImpersonationHandler struct {
+	authService *service.AuthService
+}
+
+// NewImpersonationHandler creates a new ImpersonationHandler.
+func NewImpersonationHandler(authService *service.AuthService) *ImpersonationHandler {
+	return &ImpersonationHandler{authService: authService}
+}
+
+// Impersonate handles POST /users/{id}/impersonate.
+//
+// @Summary      Impersonate a user
+// @Description  Sign in as a lower-ranked user of the current tenant to see exactly what they see, without their password. Requires the users.impersonate permission (Admin+ by default). Returns a 15-minute access token for the user, with no refresh token, that carries the caller in its act claim. Requests made with it can't change passwords, MFA or PINs, or start another impersonation; GET /auth/me reports impersonated_by; and every audit event and access log entry names the caller as actor. To stop, log out with the token (POST /auth/logout) or discard it.
+// @Tags         users
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string              true  "User ID"
+// @Param        request  body      ImpersonateRequest  true  "Reason for the audit trail"
+// @Success      200      {object}  ImpersonationResponse
+// @Failure      400      {object}  ErrorResponse "invalid_id, invalid_request, cannot_impersonate_self"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_permission, insufficient_role, account_disabled, impersonation_forbidden"
+// @Failure      404      {object}  ErrorResponse "not_found"
+// @Router       /users/{id}/impersonate [post]
+func (h *ImpersonationHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	callerRole, ok := GetRole(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	callerID, _ := GetUserID(r.Context())
+	tenantID, _ := GetTenantID(r.Context())
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid user ID format")
+		return
+	}
+
+	var req ImpersonateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.Reason == "" || len(req.Reason) > maxImpersonationReasonLength {
+		writeError(w, http.StatusBadRequest, "invalid_request", "A reason of at most 500 characters is required")
+		return
+	}
+
+	resp, err := h.authService.Impersonate(r.Context(), service.ImpersonateRequest{
+		ActorID:   callerID,
+		ActorRole: callerRole,
+		UserID:    userID,
+		TenantID:  tenantID,
+		Reason:    req.Reason,
+		IPAddress: GetClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrImpersonateSelf):
+			writeError(w, http.StatusBadRequest, "cannot_impersonate_self", "You cannot impersonate yourself")
+		case errors.Is(err, domain.ErrUserNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "User not found")
+		case errors.Is(err, domain.ErrCannotManageRole):
+			writeError(w, http.StatusForbidden, "insufficient_role", "You can only impersonate users with a lower role")
+		case errors.Is(err, domain.ErrAccountDisabled):
+			writeError(w, http.StatusForbidden, "account_disabled", "User's account is disabled")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToImpersonationResponse(resp, callerID))
+}
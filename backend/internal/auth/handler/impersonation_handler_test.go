@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+)
+
+func TestImpersonationHandler_Impersonate(t *testing.T) {
+	h, _, tokenSvc, userRepo, _, _, _ := setupWiredAuthHandler(t)
+	handler := NewImpersonationHandler(h.authService)
+
+	tenantID, adminID, waiterID := uuid.New(), uuid.New(), uuid.New()
+	userRepo.AddUser(&domain.User{
+		ID: waiterID, Email: "waiter@example.com", IsActive: true,
+		TenantRoles: []domain.UserTenantRole{{UserID: waiterID, TenantID: tenantID, Role: domain.RoleWaiter}},
+	})
+
+	body, _ := json.Marshal(ImpersonateRequest{Reason: "Ticket 4521"})
+	req := httptest.NewRequest("POST", "/users/"+waiterID.String()+"/impersonate", bytes.NewReader(body)).WithContext(authedContext(adminID, tenantID, domain.RoleAdmin))
+	req = withChiURLParam(req, "id", waiterID.String())
+	w := httptest.NewRecorder()
+
+	handler.Impersonate(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp ImpersonationResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.User.ID != waiterID || resp.ImpersonatedBy != adminID {
+		t.Errorf("response = %+v, want the waiter impersonated by the admin", resp)
+	}
+
+	claims, err := tokenSvc.ValidateAccessToken(resp.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+	if actorID, _ := claims.GetActorID(); actorID != adminID {
+		t.Errorf("act claim = %s, want the admin", actorID)
+	}
+}
+
+func TestImpersonationHandler_Impersonate_Errors(t *testing.T) {
+	h, _, _, userRepo, _, _, _ := setupWiredAuthHandler(t)
+	handler := NewImpersonationHandler(h.authService)
+
+	tenantID, adminID := uuid.New(), uuid.New()
+	addUser := func(role domain.Role) uuid.UUID {
+		id := uuid.New()
+		userRepo.AddUser(&domain.User{
+			ID: id, Email: id.String() + "@example.com", IsActive: true,
+			TenantRoles: []domain.UserTenantRole{{UserID: id, TenantID: tenantID, Role: role}},
+		})
+		return id
+	}
+	waiter, otherAdmin := addUser(domain.RoleWaiter), addUser(domain.RoleAdmin)
+
+	tests := []struct {
+		name       string
+		id         string
+		reason     string
+		wantStatus int
+		wantCode   string
+	}{
+		{"invalid id", "not-a-uuid", "Ticket 4521", http.StatusBadRequest, "invalid_id"},
+		{"missing reason", waiter.String(), "", http.StatusBadRequest, "invalid_request"},
+		{"self", adminID.String(), "Ticket 4521", http.StatusBadRequest, "cannot_impersonate_self"},
+		{"unknown user", uuid.NewString(), "Ticket 4521", http.StatusNotFound, "not_found"},
+		{"same rank", otherAdmin.String(), "Ticket 4521", http.StatusForbidden, "insufficient_role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(ImpersonateRequest{Reason: tt.reason})
+			req := httptest.NewRequest("POST", "/users/"+tt.id+"/impersonate", bytes.NewReader(body)).WithContext(authedContext(adminID, tenantID, domain.RoleAdmin))
+			req = withChiURLParam(req, "id", tt.id)
+			w := httptest.NewRecorder()
+
+			handler.Impersonate(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var resp ErrorResponse
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", resp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// JWKSHandler serves the public keys that verify access tokens, so other
+// services and the kitchen-display and mobile apps can validate tokens
+// offline. It is mounted at /.well-known/jwks.json, outside the /api/v1
+// base path, so it is not part of the OpenAPI spec.
+type JWKSHandler struct {
+	tokenService *service.TokenService
+}
+
+// NewJWKSHandler creates a new JWKSHandler.
+func NewJWKSHandler(tokenService *service.TokenService) *JWKSHandler {
+	return &JWKSHandler{tokenService: tokenService}
+}
+
+// ServeHTTP handles GET /.well-known/jwks.json.
+func (h *JWKSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Short cache: a rotated key is pre-published for longer than this,
+	// so clients always see it before it starts signing.
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, http.StatusOK, h.tokenService.JWKS())
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/pkg/jwt"
+)
+
+func TestJWKSHandler_PublishesSigningKey(t *testing.T) {
+	_, _, tokenSvc, _, _, _, _ := setupWiredAuthHandler(t)
+	h := NewJWKSHandler(tokenSvc)
+
+	user := &domain.User{ID: uuid.New(), Email: "user@example.com"}
+	pair, _, err := tokenSvc.GenerateTokenPair(user, uuid.New(), uuid.New(), domain.RoleWaiter, domain.RoleWaiter.Permissions())
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(strings.Split(pair.AccessToken, ".")[0])
+	if err != nil {
+		t.Fatalf("failed to decode token header: %v", err)
+	}
+	var header struct {
+		KeyID string `json:"kid"`
+	}
+	json.Unmarshal(headerJSON, &header)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if cc := rr.Header().Get("Cache-Control"); !strings.Contains(cc, "max-age") {
+		t.Errorf("Expected a cacheable response, got Cache-Control %q", cc)
+	}
+
+	var set jwt.JWKS
+	if err := json.NewDecoder(rr.Body).Decode(&set); err != nil {
+		t.Fatalf("failed to decode JWKS: %v", err)
+	}
+	if len(set.Keys) != 1 {
+		t.Fatalf("Expected 1 key, got %d", len(set.Keys))
+	}
+	if set.Keys[0].KeyID != header.KeyID {
+		t.Errorf("JWKS kid = %q, token kid = %q", set.Keys[0].KeyID, header.KeyID)
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// LockoutPolicyHandler handles the tenant account lockout policy endpoints.
+type LockoutPolicyHandler struct {
+	lockout *service.LockoutService
+}
+
+// NewLockoutPolicyHandler creates a new LockoutPolicyHandler.
+func NewLockoutPolicyHandler(lockout *service.LockoutService) *LockoutPolicyHandler {
+	return &LockoutPolicyHandler{lockout: lockout}
+}
+
+// GetPolicy handles GET /lockout-policy.
+//
+// @Summary      Get the tenant's lockout policy
+// @Description  Admin+ views how repeated wrong passwords delay members' logins. Tenants that haven't set a policy get the default: logins from unknown devices are delayed from the 5th consecutive failure and from known devices from the 10th, for 1 minute doubling up to 15 minutes.
+// @Tags         auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  LockoutPolicyResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Router       /auth/lockout-policy [get]
+func (h *LockoutPolicyHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	policy, err := h.lockout.GetPolicy(r.Context(), tenantID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToLockoutPolicyResponse(policy))
+}
+
+// PutPolicy handles PUT /lockout-policy.
+//
+// @Summary      Set the tenant's lockout policy
+// @Description  Admin+ replaces how repeated wrong passwords delay members' logins. Once a device's threshold (1 to 100) of consecutive failures is reached, each further failure locks logins from such devices for base_delay_seconds, doubling up to max_delay_seconds (at most 86400). Devices a member has logged in from before have their own threshold, so guessing elsewhere can't lock them out of those. Members in several tenants follow the strictest combination of their tenants' policies. Locked-out members can unlock by email.
+// @Tags         auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      LockoutPolicyRequest  true  "Lockout limits"
+// @Success      200      {object}  LockoutPolicyResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, lockout_policy_invalid"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role, impersonation_forbidden"
+// @Router       /auth/lockout-policy [put]
+func (h *LockoutPolicyHandler) PutPolicy(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	var req LockoutPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	policy, err := h.lockout.SavePolicy(r.Context(), service.SaveLockoutPolicyRequest{
+		TenantID:               tenantID,
+		UpdatedBy:              userID,
+		UnknownDeviceThreshold: req.UnknownDeviceThreshold,
+		KnownDeviceThreshold:   req.KnownDeviceThreshold,
+		BaseDelaySeconds:       req.BaseDelaySeconds,
+		MaxDelaySeconds:        req.MaxDelaySeconds,
+		IPAddress:              GetClientIP(r),
+		UserAgent:              r.UserAgent(),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrLockoutPolicyInvalid) {
+			writeError(w, http.StatusBadRequest, "lockout_policy_invalid", "Thresholds must be 1 to 100, base_delay_seconds 1 to 86400 and max_delay_seconds between base_delay_seconds and 86400")
+			return
+		}
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToLockoutPolicyResponse(policy))
+}
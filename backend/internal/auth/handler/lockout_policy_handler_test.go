@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+func setupLockoutPolicyHandler(t *testing.T) *LockoutPolicyHandler {
+	t.Helper()
+
+	return NewLockoutPolicyHandler(service.NewLockoutService(service.LockoutServiceConfig{
+		Policies:  mock.NewMockLockoutPolicyRepository(),
+		Devices:   mock.NewMockKnownDeviceRepository(),
+		RoleRepo:  mock.NewMockUserTenantRoleRepository(),
+		EventRepo: mock.NewMockAuthEventRepository(),
+	}))
+}
+
+func TestLockoutPolicyHandler_GetAndPut(t *testing.T) {
+	h := setupLockoutPolicyHandler(t)
+	ctx := authedContext(uuid.New(), uuid.New(), domain.RoleAdmin)
+
+	req := httptest.NewRequest("GET", "/lockout-policy", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	h.GetPolicy(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp LockoutPolicyResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.UnknownDeviceThreshold != 5 || resp.KnownDeviceThreshold != 10 || resp.UpdatedAt != nil {
+		t.Errorf("default policy response = %+v", resp)
+	}
+
+	body, _ := json.Marshal(LockoutPolicyRequest{UnknownDeviceThreshold: 3, KnownDeviceThreshold: 15, BaseDelaySeconds: 30, MaxDelaySeconds: 3600})
+	req = httptest.NewRequest("PUT", "/lockout-policy", bytes.NewReader(body)).WithContext(ctx)
+	w = httptest.NewRecorder()
+	h.PutPolicy(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/lockout-policy", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	h.GetPolicy(w, req)
+	resp = LockoutPolicyResponse{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.UnknownDeviceThreshold != 3 || resp.KnownDeviceThreshold != 15 || resp.BaseDelaySeconds != 30 || resp.MaxDelaySeconds != 3600 {
+		t.Errorf("saved policy response = %+v", resp)
+	}
+}
+
+func TestLockoutPolicyHandler_PutPolicy_Invalid(t *testing.T) {
+	h := setupLockoutPolicyHandler(t)
+	ctx := authedContext(uuid.New(), uuid.New(), domain.RoleAdmin)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+	}{
+		{"invalid body", "{", "invalid_request"},
+		{"zero threshold", `{"unknown_device_threshold":0,"known_device_threshold":10,"base_delay_seconds":60,"max_delay_seconds":900}`, "lockout_policy_invalid"},
+		{"max below base", `{"unknown_device_threshold":5,"known_device_threshold":10,"base_delay_seconds":600,"max_delay_seconds":60}`, "lockout_policy_invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/lockout-policy", bytes.NewReader([]byte(tt.body))).WithContext(ctx)
+			w := httptest.NewRecorder()
+			h.PutPolicy(w, req)
+			assertErrorCode(t, w, http.StatusBadRequest, tt.wantCode)
+		})
+	}
+}
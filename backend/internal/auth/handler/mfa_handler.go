@@ -0,0 +1,366 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// MFAHandler handles TOTP multi-factor authentication endpoints.
+type MFAHandler struct {
+	authService *service.AuthService
+	mfaService  *service.MFAService
+}
+
+// NewMFAHandler creates a new MFAHandler.
+func NewMFAHandler(authService *service.AuthService, mfaService *service.MFAService) *MFAHandler {
+	return &MFAHandler{authService: authService, mfaService: mfaService}
+}
+
+// Verify handles POST /mfa/verify.
+//
+// @Summary      Complete MFA login
+// @Description  Exchange the mfa_token from a 401 mfa_required / mfa_enrollment_required login response plus a TOTP code (or a recovery code) for tokens. When completing a first-time enrollment, the response also carries the user's recovery codes, shown only this once.
+// @Tags         mfa
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFAVerifyRequest  true  "Challenge token and code"
+// @Success      200      {object}  LoginResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, mfa_not_enrolled"
+// @Failure      401      {object}  ErrorResponse "mfa_challenge_invalid, mfa_code_invalid, account_disabled, tenant_inactive"
+// @Router       /auth/mfa/verify [post]
+func (h *MFAHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var req MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "MFA token and code are required")
+		return
+	}
+
+	resp, err := h.authService.VerifyMFA(r.Context(), service.MFAVerifyRequest{
+		MFAToken:  req.MFAToken,
+		Code:      req.Code,
+		IPAddress: GetClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMFAChallengeInvalid):
+			writeError(w, http.StatusUnauthorized, "mfa_challenge_invalid", "MFA challenge is invalid or expired. Please log in again.")
+			return
+		case errors.Is(err, domain.ErrMFACodeInvalid):
+			writeError(w, http.StatusUnauthorized, "mfa_code_invalid", "Verification code is invalid")
+			return
+		case errors.Is(err, domain.ErrMFANotEnrolled):
+			writeError(w, http.StatusBadRequest, "mfa_not_enrolled", "Set up an authenticator app via /mfa/setup first")
+			return
+		case errors.Is(err, domain.ErrAccountDisabled):
+			writeError(w, http.StatusUnauthorized, "account_disabled", "Account is disabled")
+			return
+		case errors.Is(err, domain.ErrTenantInactive):
+			writeError(w, http.StatusUnauthorized, "tenant_inactive", "Tenant is inactive")
+			return
+		case errors.Is(err, domain.ErrUserNotInTenant):
+			writeError(w, http.StatusBadRequest, "invalid_tenant", "User does not belong to this tenant")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, ToLoginResponse(resp))
+}
+
+// Setup handles POST /mfa/setup.
+//
+// @Summary      Start MFA enrollment during login
+// @Description  For a user whose login returned mfa_enrollment_required: generate a TOTP secret using the mfa_token as proof of the password step. Confirm it by submitting a code to /auth/mfa/verify.
+// @Tags         mfa
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFASetupRequest  true  "Challenge token"
+// @Success      200      {object}  MFAEnrollmentResponse
+// @Failure      401      {object}  ErrorResponse "mfa_challenge_invalid"
+// @Failure      409      {object}  ErrorResponse "mfa_already_enrolled"
+// @Router       /auth/mfa/setup [post]
+func (h *MFAHandler) Setup(w http.ResponseWriter, r *http.Request) {
+	var req MFASetupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.MFAToken == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "MFA token is required")
+		return
+	}
+
+	enrollment, err := h.authService.BeginMFAEnrollment(r.Context(), req.MFAToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMFAChallengeInvalid):
+			writeError(w, http.StatusUnauthorized, "mfa_challenge_invalid", "MFA challenge is invalid or expired. Please log in again.")
+			return
+		case errors.Is(err, domain.ErrMFAAlreadyEnrolled):
+			writeError(w, http.StatusConflict, "mfa_already_enrolled", "Multi-factor authentication is already enrolled")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, ToMFAEnrollmentResponse(enrollment))
+}
+
+// Status handles GET /mfa.
+//
+// @Summary      Get MFA status
+// @Description  Whether the authenticated user has MFA enabled, whether their current role requires it, and how many recovery codes they have left.
+// @Tags         mfa
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  MFAStatusResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Router       /auth/mfa [get]
+func (h *MFAHandler) Status(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	role, _ := GetRole(r.Context())
+
+	enabled, err := h.mfaService.IsEnrolled(r.Context(), userID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	var remaining int64
+	if enabled {
+		remaining, err = h.mfaService.RemainingRecoveryCodes(r.Context(), userID)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, MFAStatusResponse{
+		Enabled:                enabled,
+		Required:               role.RequiresMFA(),
+		RecoveryCodesRemaining: remaining,
+	})
+}
+
+// Enroll handles POST /mfa/enroll.
+//
+// @Summary      Start MFA enrollment
+// @Description  Generate a new TOTP secret for the authenticated user. Add it to an authenticator app (e.g. by rendering otpauth_uri as a QR code), then confirm with /auth/mfa/enroll/confirm.
+// @Tags         mfa
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  MFAEnrollmentResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      409  {object}  ErrorResponse "mfa_already_enrolled"
+// @Router       /auth/mfa/enroll [post]
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	claims, ok := GetClaims(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	userID, err := claims.GetUserID()
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	enrollment, err := h.mfaService.BeginEnrollment(r.Context(), userID, claims.Email)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFAAlreadyEnrolled) {
+			writeError(w, http.StatusConflict, "mfa_already_enrolled", "Multi-factor authentication is already enrolled")
+			return
+		}
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToMFAEnrollmentResponse(enrollment))
+}
+
+// ConfirmEnrollment handles POST /mfa/enroll/confirm.
+//
+// @Summary      Confirm MFA enrollment
+// @Description  Activate the pending TOTP secret by submitting a current code. Returns recovery codes, shown only this once.
+// @Tags         mfa
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFACodeRequest  true  "TOTP code"
+// @Success      200      {object}  RecoveryCodesResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, mfa_code_invalid, mfa_not_enrolled"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      409      {object}  ErrorResponse "mfa_already_enrolled"
+// @Router       /auth/mfa/enroll/confirm [post]
+func (h *MFAHandler) ConfirmEnrollment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.Code == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Code is required")
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmEnrollment(r.Context(), userID, req.Code, GetClientIP(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMFACodeInvalid):
+			writeError(w, http.StatusBadRequest, "mfa_code_invalid", "Verification code is invalid")
+			return
+		case errors.Is(err, domain.ErrMFANotEnrolled):
+			writeError(w, http.StatusBadRequest, "mfa_not_enrolled", "Start enrollment via /mfa/enroll first")
+			return
+		case errors.Is(err, domain.ErrMFAAlreadyEnrolled):
+			writeError(w, http.StatusConflict, "mfa_already_enrolled", "Multi-factor authentication is already enrolled")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable handles POST /mfa/disable.
+//
+// @Summary      Disable MFA
+// @Description  Remove the authenticated user's TOTP factor and recovery codes after re-verifying a current TOTP or recovery code. Users whose role requires MFA must enroll again at their next login.
+// @Tags         mfa
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFACodeRequest  true  "TOTP or recovery code"
+// @Success      200      {object}  MessageResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, mfa_code_invalid, mfa_not_enrolled"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Router       /auth/mfa/disable [post]
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.Code == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Code is required")
+		return
+	}
+
+	err := h.mfaService.Disable(r.Context(), userID, req.Code, GetClientIP(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMFACodeInvalid):
+			writeError(w, http.StatusBadRequest, "mfa_code_invalid", "Verification code is invalid")
+			return
+		case errors.Is(err, domain.ErrMFANotEnrolled):
+			writeError(w, http.StatusBadRequest, "mfa_not_enrolled", "Multi-factor authentication is not enrolled")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, MessageResponse{
+		Message: "Multi-factor authentication has been disabled.",
+	})
+}
+
+// RegenerateRecoveryCodes handles POST /mfa/recovery-codes.
+//
+// @Summary      Regenerate MFA recovery codes
+// @Description  Invalidate all existing recovery codes and issue a new set, after re-verifying a current TOTP or recovery code.
+// @Tags         mfa
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFACodeRequest  true  "TOTP or recovery code"
+// @Success      200      {object}  RecoveryCodesResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, mfa_code_invalid, mfa_not_enrolled"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Router       /auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.Code == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Code is required")
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(r.Context(), userID, req.Code, GetClientIP(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMFACodeInvalid):
+			writeError(w, http.StatusBadRequest, "mfa_code_invalid", "Verification code is invalid")
+			return
+		case errors.Is(err, domain.ErrMFANotEnrolled):
+			writeError(w, http.StatusBadRequest, "mfa_not_enrolled", "Multi-factor authentication is not enrolled")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// writeMFAChallenge writes the 401 returned when a login needs a second
+// factor, carrying the challenge token the client must send to /mfa/verify
+// (for a TOTP or recovery code) or /mfa/passkey/verify, and the methods the
+// user can complete it with.
+func writeMFAChallenge(w http.ResponseWriter, code, message, mfaToken string, methods []string, deviceToken string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error: ErrorDetail{
+			Code:        code,
+			Message:     message,
+			MFAToken:    mfaToken,
+			MFAMethods:  methods,
+			DeviceToken: deviceToken,
+		},
+	})
+}
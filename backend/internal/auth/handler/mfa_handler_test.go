@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// setupMFAHandler builds an MFAHandler (and an AuthHandler sharing the same
+// services) with MFA enabled on login.
+func setupMFAHandler(t *testing.T) (*MFAHandler, *AuthHandler, *mock.MockUserRepository, *mock.MockTenantRepository) {
+	t.Helper()
+
+	// Reuse the wired setup for its token service and repos
+	_, _, tokenSvc, userRepo, tenantRepo, roleRepo, sessionRepo := setupWiredAuthHandler(t)
+	eventRepo := mock.NewMockAuthEventRepository()
+
+	mfaSvc := service.NewMFAService(service.MFAServiceConfig{
+		MFARepo:       mock.NewMockMFARepository(),
+		ChallengeRepo: mock.NewMockMFAChallengeRepository(),
+		EventRepo:     eventRepo,
+	})
+	authSvc := service.NewAuthService(service.AuthServiceConfig{
+		UserRepo:     userRepo,
+		SessionRepo:  sessionRepo,
+		EventRepo:    eventRepo,
+		TenantRepo:   tenantRepo,
+		RoleRepo:     roleRepo,
+		TokenService: tokenSvc,
+		MFAService:   mfaSvc,
+	})
+
+	return NewMFAHandler(authSvc, mfaSvc), NewAuthHandler(authSvc), userRepo, tenantRepo
+}
+
+// loginForMFAToken logs a manager in and returns the mfa_token from the 401 challenge.
+func loginForMFAToken(t *testing.T, auth *AuthHandler, userRepo *mock.MockUserRepository, tenantRepo *mock.MockTenantRepository) string {
+	t.Helper()
+
+	passwordHash, _ := service.NewPasswordService().Hash("Password123!")
+	tenantID := uuid.New()
+	userID := uuid.New()
+	userRepo.AddUser(&domain.User{
+		ID: userID, Email: "manager@example.com", PasswordHash: passwordHash, IsActive: true,
+		TenantRoles: []domain.UserTenantRole{{ID: uuid.New(), UserID: userID, TenantID: tenantID, Role: domain.RoleManager}},
+	})
+	tenantRepo.AddTenant(&domain.Tenant{ID: tenantID, Name: "Acme", Slug: "acme", IsActive: true})
+
+	body, _ := json.Marshal(LoginRequest{Email: "manager@example.com", Password: "Password123!"})
+	w := httptest.NewRecorder()
+	auth.Login(w, httptest.NewRequest("POST", "/login", bytes.NewReader(body)))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Login status = %d, want %d, body=%s", w.Code, http.StatusUnauthorized, w.Body.String())
+	}
+	var resp ErrorResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Error.Code != "mfa_enrollment_required" || resp.Error.MFAToken == "" {
+		t.Fatalf("unexpected login response: %+v", resp)
+	}
+	return resp.Error.MFAToken
+}
+
+func TestMFAHandler_Setup_Success(t *testing.T) {
+	h, auth, userRepo, tenantRepo := setupMFAHandler(t)
+	token := loginForMFAToken(t, auth, userRepo, tenantRepo)
+
+	body, _ := json.Marshal(MFASetupRequest{MFAToken: token})
+	w := httptest.NewRecorder()
+	h.Setup(w, httptest.NewRequest("POST", "/mfa/setup", bytes.NewReader(body)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp MFAEnrollmentResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Secret == "" || !strings.HasPrefix(resp.OTPAuthURI, "otpauth://totp/") {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestMFAHandler_Setup_InvalidChallenge(t *testing.T) {
+	h, _, _, _ := setupMFAHandler(t)
+
+	body, _ := json.Marshal(MFASetupRequest{MFAToken: "bogus"})
+	w := httptest.NewRecorder()
+	h.Setup(w, httptest.NewRequest("POST", "/mfa/setup", bytes.NewReader(body)))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestMFAHandler_Setup_MissingToken(t *testing.T) {
+	h, _, _, _ := setupMFAHandler(t)
+
+	w := httptest.NewRecorder()
+	h.Setup(w, httptest.NewRequest("POST", "/mfa/setup", strings.NewReader(`{}`)))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMFAHandler_Verify_InvalidCode(t *testing.T) {
+	h, auth, userRepo, tenantRepo := setupMFAHandler(t)
+	token := loginForMFAToken(t, auth, userRepo, tenantRepo)
+
+	setupBody, _ := json.Marshal(MFASetupRequest{MFAToken: token})
+	h.Setup(httptest.NewRecorder(), httptest.NewRequest("POST", "/mfa/setup", bytes.NewReader(setupBody)))
+
+	body, _ := json.Marshal(MFAVerifyRequest{MFAToken: token, Code: "000000"})
+	w := httptest.NewRecorder()
+	h.Verify(w, httptest.NewRequest("POST", "/mfa/verify", bytes.NewReader(body)))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	var resp ErrorResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Error.Code != "mfa_code_invalid" {
+		t.Errorf("Code = %q, want mfa_code_invalid", resp.Error.Code)
+	}
+}
+
+func TestMFAHandler_Verify_NotEnrolled(t *testing.T) {
+	h, auth, userRepo, tenantRepo := setupMFAHandler(t)
+	token := loginForMFAToken(t, auth, userRepo, tenantRepo)
+
+	// Verifying before /mfa/setup has generated a secret
+	body, _ := json.Marshal(MFAVerifyRequest{MFAToken: token, Code: "123456"})
+	w := httptest.NewRecorder()
+	h.Verify(w, httptest.NewRequest("POST", "/mfa/verify", bytes.NewReader(body)))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMFAHandler_Verify_InvalidChallenge(t *testing.T) {
+	h, _, _, _ := setupMFAHandler(t)
+
+	body, _ := json.Marshal(MFAVerifyRequest{MFAToken: "bogus", Code: "123456"})
+	w := httptest.NewRecorder()
+	h.Verify(w, httptest.NewRequest("POST", "/mfa/verify", bytes.NewReader(body)))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestMFAHandler_Verify_MissingFields(t *testing.T) {
+	h, _, _, _ := setupMFAHandler(t)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing code", `{"mfa_token":"abc"}`},
+		{"missing token", `{"code":"123456"}`},
+		{"invalid body", `not json`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.Verify(w, httptest.NewRequest("POST", "/mfa/verify", strings.NewReader(tt.body)))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestMFAHandler_Status(t *testing.T) {
+	h, _, _, _ := setupMFAHandler(t)
+
+	ctx := authedContext(uuid.New(), uuid.New(), domain.RoleOwner)
+	w := httptest.NewRecorder()
+	h.Status(w, httptest.NewRequest("GET", "/mfa", nil).WithContext(ctx))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp MFAStatusResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Enabled || !resp.Required {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestMFAHandler_Enroll(t *testing.T) {
+	h, _, _, _ := setupMFAHandler(t)
+
+	claims := &domain.Claims{TenantID: uuid.New(), Role: domain.RoleCashier, Email: "cashier@example.com"}
+	claims.Subject = uuid.New().String()
+	ctx := context.WithValue(context.Background(), UserContextKey, claims)
+
+	w := httptest.NewRecorder()
+	h.Enroll(w, httptest.NewRequest("POST", "/mfa/enroll", nil).WithContext(ctx))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp MFAEnrollmentResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if !strings.Contains(resp.OTPAuthURI, "cashier@example.com") {
+		t.Errorf("URI %q should contain the account email", resp.OTPAuthURI)
+	}
+}
+
+func TestMFAHandler_ConfirmEnrollment_NotStarted(t *testing.T) {
+	h, _, _, _ := setupMFAHandler(t)
+
+	ctx := authedContext(uuid.New(), uuid.New(), domain.RoleOwner)
+	w := httptest.NewRecorder()
+	h.ConfirmEnrollment(w, httptest.NewRequest("POST", "/mfa/enroll/confirm", strings.NewReader(`{"code":"123456"}`)).WithContext(ctx))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMFAHandler_CodeEndpoints_NotEnrolled(t *testing.T) {
+	h, _, _, _ := setupMFAHandler(t)
+
+	handlers := map[string]http.HandlerFunc{
+		"disable":        h.Disable,
+		"recovery-codes": h.RegenerateRecoveryCodes,
+	}
+	for name, fn := range handlers {
+		t.Run(name, func(t *testing.T) {
+			ctx := authedContext(uuid.New(), uuid.New(), domain.RoleOwner)
+			w := httptest.NewRecorder()
+			fn(w, httptest.NewRequest("POST", "/mfa/"+name, strings.NewReader(`{"code":"123456"}`)).WithContext(ctx))
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			var resp ErrorResponse
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp.Error.Code != "mfa_not_enrolled" {
+				t.Errorf("Code = %q, want mfa_not_enrolled", resp.Error.Code)
+			}
+		})
+	}
+}
+
+func TestMFAHandler_Unauthorized(t *testing.T) {
+	h := NewMFAHandler(nil, nil)
+
+	handlers := map[string]http.HandlerFunc{
+		"status":         h.Status,
+		"enroll":         h.Enroll,
+		"confirm":        h.ConfirmEnrollment,
+		"disable":        h.Disable,
+		"recovery-codes": h.RegenerateRecoveryCodes,
+	}
+	for name, fn := range handlers {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			fn(w, httptest.NewRequest("POST", "/mfa", strings.NewReader(`{"code":"123456"}`)))
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("Status = %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestMFAHandler_CodeEndpoints_MissingCode(t *testing.T) {
+	h := NewMFAHandler(nil, nil)
+
+	handlers := map[string]http.HandlerFunc{
+		"confirm":        h.ConfirmEnrollment,
+		"disable":        h.Disable,
+		"recovery-codes": h.RegenerateRecoveryCodes,
+	}
+	for name, fn := range handlers {
+		t.Run(name, func(t *testing.T) {
+			ctx := authedContext(uuid.New(), uuid.New(), domain.RoleOwner)
+			w := httptest.NewRecorder()
+			fn(w, httptest.NewRequest("POST", "/mfa", strings.NewReader(`{}`)).WithContext(ctx))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
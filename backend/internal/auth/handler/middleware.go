@@ -5,8 +5,10 @@ import (
 	"errors"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/solobueno/erp/internal/auth/domain"
 	"github.com/solobueno/erp/internal/auth/service"
@@ -24,8 +26,28 @@ const (
 	TenantIDContextKey ContextKey = "tenant_id"
 	// RoleContextKey is the context key for the user's role.
 	RoleContextKey ContextKey = "role"
+	// PermissionsContextKey is the context key for the user's permissions.
+	PermissionsContextKey ContextKey = "permissions"
+	// ApprovalContextKey is the context key for the step-up approval spent
+	// by RequireApproval.
+	ApprovalContextKey ContextKey = "approval"
+	// ActorIDContextKey is the context key for the ID of the user
+	// impersonating the authenticated user, if any.
+	ActorIDContextKey ContextKey = "actor_id"
+	// SCIMTokenContextKey is the context key for the SCIM token
+	// authenticating a SCIM request.
+	SCIMTokenContextKey ContextKey = "scim_token"
+	// ServiceAccountContextKey is the context key for the ID of the service
+	// account authenticating a request with an API key, if any.
+	ServiceAccountContextKey ContextKey = "service_account_id"
+	// OAuthClientContextKey is the context key for the ID of the OAuth
+	// client a request's access token was issued to, if any.
+	OAuthClientContextKey ContextKey = "oauth_client_id"
 )
 
+// ApprovalTokenHeader carries a step-up approval token from POST /auth/approvals.
+const ApprovalTokenHeader = "X-Approval-Token"
+
 // AuthMiddleware provides authentication middleware.
 type AuthMiddleware struct {
 	authService *service.AuthService
@@ -36,7 +58,8 @@ func NewAuthMiddleware(authService *service.AuthService) *AuthMiddleware {
 	return &AuthMiddleware{authService: authService}
 }
 
-// RequireAuth is middleware that requires a valid JWT token.
+// RequireAuth is middleware that requires a valid JWT token, or a service
+// account API key in its place.
 func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Extract token from Authorization header
@@ -46,6 +69,11 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 			return
 		}
 
+		if strings.HasPrefix(token, domain.APIKeyPrefix) {
+			m.authenticateAPIKey(w, r, token, next)
+			return
+		}
+
 		// Validate token
 		claims, err := m.authService.ValidateToken(r.Context(), token)
 		if err != nil {
@@ -54,8 +82,13 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 				writeError(w, http.StatusUnauthorized, "token_expired", "Token has expired")
 			case errors.Is(err, domain.ErrTokenMalformed):
 				writeError(w, http.StatusUnauthorized, "token_invalid", "Token is malformed")
-			default:
+			case errors.Is(err, domain.ErrTokenRevoked):
+				writeError(w, http.StatusUnauthorized, "token_revoked", "Token has been revoked")
+			case errors.Is(err, domain.ErrTokenInvalid):
 				writeError(w, http.StatusUnauthorized, "token_invalid", "Token is invalid")
+			default:
+				// Fail closed if the revocation list can't be checked
+				writeInternalError(w, r, err)
 			}
 			return
 		}
@@ -73,14 +106,92 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 		ctx = context.WithValue(ctx, UserIDContextKey, userID)
 		ctx = context.WithValue(ctx, TenantIDContextKey, claims.TenantID)
 		ctx = context.WithValue(ctx, RoleContextKey, claims.Role)
+		ctx = context.WithValue(ctx, PermissionsContextKey, claims.GetPermissions())
 
 		setAccessLogUserID(ctx, userID.String())
 		setAccessLogTenantID(ctx, claims.TenantID.String())
 
+		// Under impersonation, audit events and the access log name the
+		// real actor alongside the impersonated user
+		if claims.IsImpersonation() {
+			actorID, ok := claims.GetActorID()
+			if !ok {
+				writeError(w, http.StatusUnauthorized, "token_invalid", "Invalid actor ID in token")
+				return
+			}
+			ctx = context.WithValue(ctx, ActorIDContextKey, actorID)
+			ctx = service.WithActor(ctx, actorID)
+			setAccessLogActorID(ctx, actorID.String())
+		}
+
+		if claims.IsClientToken() {
+			clientID, ok := claims.GetClientID()
+			if !ok {
+				writeError(w, http.StatusUnauthorized, "token_invalid", "Invalid client ID in token")
+				return
+			}
+			ctx = context.WithValue(ctx, OAuthClientContextKey, clientID)
+		}
+
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// authenticateAPIKey authenticates a request made with a service account API
+// key. The account stands in for the user: its ID is the request's user ID
+// and its scopes are the request's permissions.
+func (m *AuthMiddleware) authenticateAPIKey(w http.ResponseWriter, r *http.Request, key string, next http.Handler) {
+	claims, err := m.authService.ValidateAPIKey(r.Context(), key)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrAPIKeyExpired):
+			writeError(w, http.StatusUnauthorized, "api_key_expired", "API key has expired")
+		case errors.Is(err, domain.ErrAPIKeyInvalid):
+			writeError(w, http.StatusUnauthorized, "api_key_invalid", "API key is invalid or has been revoked")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	accountID, err := claims.GetUserID()
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "api_key_invalid", "API key is invalid or has been revoked")
+		return
+	}
+
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, UserContextKey, claims)
+	ctx = context.WithValue(ctx, UserIDContextKey, accountID)
+	ctx = context.WithValue(ctx, ServiceAccountContextKey, accountID)
+	ctx = context.WithValue(ctx, TenantIDContextKey, claims.TenantID)
+	ctx = context.WithValue(ctx, RoleContextKey, claims.Role)
+	ctx = context.WithValue(ctx, PermissionsContextKey, claims.GetPermissions())
+
+	setAccessLogUserID(ctx, accountID.String())
+	setAccessLogTenantID(ctx, claims.TenantID.String())
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RequireUser is middleware that rejects requests made with a service
+// account API key or an OAuth client's access token, for routes that only
+// make sense for a person, such as their own profile and sessions, or that
+// manage identities. It must run after RequireAuth.
+func (m *AuthMiddleware) RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := GetServiceAccountID(r.Context()); ok {
+			writeError(w, http.StatusForbidden, "service_account_forbidden", "Not available to service accounts")
+			return
+		}
+		if _, ok := GetOAuthClientID(r.Context()); ok {
+			writeError(w, http.StatusForbidden, "oauth_client_forbidden", "Not available to OAuth clients")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // RequireRole is middleware that requires a minimum role level.
 func (m *AuthMiddleware) RequireRole(minRole domain.Role) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -125,6 +236,97 @@ func (m *AuthMiddleware) RequireAnyRole(roles ...domain.Role) func(http.Handler)
 	}
 }
 
+// RequirePermission is middleware that requires the given permission. It
+// panics if the permission was never registered, so a typo fails at startup
+// instead of locking everyone out of the route.
+func (m *AuthMiddleware) RequirePermission(perm domain.Permission) func(http.Handler) http.Handler {
+	if !perm.IsRegistered() {
+		panic("auth: RequirePermission with unregistered permission " + string(perm))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			perms, ok := GetPermissions(r.Context())
+			if !ok {
+				writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+				return
+			}
+
+			if !slices.Contains(perms, perm) {
+				writeError(w, http.StatusForbidden, "insufficient_permission", "Missing permission "+string(perm))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DenyImpersonation is middleware that rejects requests made under
+// impersonation, for routes that change the user's credentials or that an
+// impersonator should never reach. It must run after RequireAuth.
+func (m *AuthMiddleware) DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := GetActorID(r.Context()); ok {
+			writeError(w, http.StatusForbidden, "impersonation_forbidden", "Not allowed while impersonating another user")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireApproval returns middleware that requires a manager's step-up
+// approval (see ApprovalHandler.Approve) for the action on the resource
+// named by the resourceParam URL parameter. The approval token comes in
+// the X-Approval-Token header and is spent before the handler runs, so the
+// handler should perform the action; GetApproval names the approver. Like
+// RequirePermission it panics on an unregistered action, and it must run
+// after RequireAuth.
+func RequireApproval(approvals *service.ApprovalService, action domain.Permission, resourceParam string) func(http.Handler) http.Handler {
+	if !action.IsRegistered() {
+		panic("auth: RequireApproval with unregistered permission " + string(action))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserID(r.Context())
+			if !ok {
+				writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+				return
+			}
+			tenantID, _ := GetTenantID(r.Context())
+
+			approval, err := approvals.Consume(r.Context(), service.ConsumeApprovalRequest{
+				Token:       r.Header.Get(ApprovalTokenHeader),
+				TenantID:    tenantID,
+				RequesterID: userID,
+				Action:      action,
+				ResourceID:  chi.URLParam(r, resourceParam),
+				IPAddress:   GetClientIP(r),
+				UserAgent:   r.UserAgent(),
+			})
+			if err != nil {
+				switch {
+				case errors.Is(err, domain.ErrApprovalRequired):
+					writeError(w, http.StatusForbidden, "approval_required", "Manager approval is required for "+string(action))
+				case errors.Is(err, domain.ErrApprovalInvalid):
+					writeError(w, http.StatusForbidden, "approval_invalid", "Approval token is not valid for this action")
+				case errors.Is(err, domain.ErrApprovalExpired):
+					writeError(w, http.StatusForbidden, "approval_expired", "Approval has expired. Ask a manager to approve again.")
+				case errors.Is(err, domain.ErrApprovalUsed):
+					writeError(w, http.StatusForbidden, "approval_used", "Approval has already been used")
+				default:
+					writeInternalError(w, r, err)
+				}
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ApprovalContextKey, approval)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // extractBearerToken extracts the JWT token from the Authorization header.
 func extractBearerToken(r *http.Request) (string, error) {
 	authHeader := r.Header.Get("Authorization")
@@ -158,12 +360,62 @@ func GetRole(ctx context.Context) (domain.Role, bool) {
 	return role, ok
 }
 
+// GetPermissions extracts the user's permissions from the request context.
+func GetPermissions(ctx context.Context) ([]domain.Permission, bool) {
+	perms, ok := ctx.Value(PermissionsContextKey).([]domain.Permission)
+	return perms, ok
+}
+
 // GetClaims extracts the full claims from the request context.
 func GetClaims(ctx context.Context) (*domain.Claims, bool) {
 	claims, ok := ctx.Value(UserContextKey).(*domain.Claims)
 	return claims, ok
 }
 
+// GetActorID extracts the ID of the user impersonating the authenticated
+// user from the request context. It reports false outside impersonation.
+func GetActorID(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(ActorIDContextKey).(uuid.UUID)
+	return id, ok
+}
+
+// GetServiceAccountID extracts the ID of the service account authenticating
+// the request from the request context. It reports false for requests made
+// by a user.
+func GetServiceAccountID(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(ServiceAccountContextKey).(uuid.UUID)
+	return id, ok
+}
+
+// GetOAuthClientID extracts the ID of the OAuth client the request's access
+// token was issued to from the request context. It reports false for
+// requests made by a user directly.
+func GetOAuthClientID(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(OAuthClientContextKey).(uuid.UUID)
+	return id, ok
+}
+
+// GetApproval extracts the step-up approval spent by RequireApproval from
+// the request context.
+func GetApproval(ctx context.Context) (*domain.Approval, bool) {
+	approval, ok := ctx.Value(ApprovalContextKey).(*domain.Approval)
+	return approval, ok
+}
+
+// GetSessionID extracts the caller's session ID from the access token claims.
+// It reports false for tokens issued without a session.
+func GetSessionID(ctx context.Context) (uuid.UUID, bool) {
+	claims, ok := GetClaims(ctx)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, err := claims.GetSessionID()
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
 // GetClientIP extracts the client IP address from the request. Forwarded
 // headers (X-Forwarded-For, X-Real-IP) are client-controlled and are only
 // trusted when the server sits behind a known reverse proxy, since IP is
@@ -5,13 +5,18 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
 	"github.com/solobueno/erp/internal/auth/repository/mock"
 	"github.com/solobueno/erp/internal/auth/service"
 	"github.com/solobueno/erp/pkg/jwt"
@@ -21,6 +26,13 @@ import (
 // keypair) so RequireAuth exercises actual token validation instead of nils.
 func setupAuthMiddleware(t *testing.T) (*AuthMiddleware, *service.TokenService) {
 	t.Helper()
+	return setupAuthMiddlewareWithRevocations(t, nil)
+}
+
+// setupAuthMiddlewareWithRevocations is setupAuthMiddleware with an
+// access-token revocation list.
+func setupAuthMiddlewareWithRevocations(t *testing.T, store repository.TokenRevocationStore) (*AuthMiddleware, *service.TokenService) {
+	t.Helper()
 
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
@@ -43,12 +55,13 @@ func setupAuthMiddleware(t *testing.T) (*AuthMiddleware, *service.TokenService)
 	tokenSvc := service.NewTokenService(km, jwt.DefaultTokenGeneratorConfig())
 
 	authSvc := service.NewAuthService(service.AuthServiceConfig{
-		UserRepo:     mock.NewMockUserRepository(),
-		SessionRepo:  mock.NewMockSessionRepository(),
-		EventRepo:    mock.NewMockAuthEventRepository(),
-		TenantRepo:   mock.NewMockTenantRepository(),
-		RoleRepo:     mock.NewMockUserTenantRoleRepository(),
-		TokenService: tokenSvc,
+		UserRepo:        mock.NewMockUserRepository(),
+		SessionRepo:     mock.NewMockSessionRepository(),
+		EventRepo:       mock.NewMockAuthEventRepository(),
+		TenantRepo:      mock.NewMockTenantRepository(),
+		RoleRepo:        mock.NewMockUserTenantRoleRepository(),
+		TokenService:    tokenSvc,
+		RevocationStore: store,
 	})
 
 	return NewAuthMiddleware(authSvc), tokenSvc
@@ -59,7 +72,7 @@ func TestAuthMiddleware_RequireAuth(t *testing.T) {
 
 	user := &domain.User{ID: uuid.New(), Email: "user@example.com"}
 	tenantID := uuid.New()
-	pair, _, err := tokenSvc.GenerateTokenPair(user, tenantID, domain.RoleManager)
+	pair, _, err := tokenSvc.GenerateTokenPair(user, uuid.New(), tenantID, domain.RoleManager, domain.RoleManager.Permissions())
 	if err != nil {
 		t.Fatalf("failed to generate token pair: %v", err)
 	}
@@ -121,6 +134,79 @@ func TestAuthMiddleware_RequireAuth_InvalidToken(t *testing.T) {
 	}
 }
 
+func TestAuthMiddleware_RequireAuth_RevokedToken(t *testing.T) {
+	store := repository.NewMemoryTokenRevocationStore(time.Minute)
+	mw, tokenSvc := setupAuthMiddlewareWithRevocations(t, store)
+
+	user := &domain.User{ID: uuid.New(), Email: "user@example.com"}
+	pair, _, err := tokenSvc.GenerateTokenPair(user, uuid.New(), uuid.New(), domain.RoleManager, domain.RoleManager.Permissions())
+	if err != nil {
+		t.Fatalf("failed to generate token pair: %v", err)
+	}
+	claims, err := tokenSvc.ValidateAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("failed to validate token: %v", err)
+	}
+	store.RevokeToken(context.Background(), &domain.RevokedAccessToken{JTI: claims.ID, UserID: user.ID, ExpiresAt: claims.ExpiresAt.Time})
+
+	called := false
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	w := httptest.NewRecorder()
+
+	mw.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })).ServeHTTP(w, req)
+
+	if called {
+		t.Error("next handler should not be called for a revoked token")
+	}
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if !strings.Contains(w.Body.String(), "token_revoked") {
+		t.Errorf("Expected token_revoked error, got %s", w.Body.String())
+	}
+}
+
+func TestAuthMiddleware_RequireAuth_RevocationCheckFails(t *testing.T) {
+	mw, tokenSvc := setupAuthMiddlewareWithRevocations(t, failingRevocationStore{})
+
+	user := &domain.User{ID: uuid.New(), Email: "user@example.com"}
+	pair, _, err := tokenSvc.GenerateTokenPair(user, uuid.New(), uuid.New(), domain.RoleManager, domain.RoleManager.Permissions())
+	if err != nil {
+		t.Fatalf("failed to generate token pair: %v", err)
+	}
+
+	called := false
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	w := httptest.NewRecorder()
+
+	mw.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })).ServeHTTP(w, req)
+
+	if called {
+		t.Error("next handler should not be called when the revocation list is unavailable")
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+// failingRevocationStore fails every call, for testing that RequireAuth fails closed.
+type failingRevocationStore struct{}
+
+func (failingRevocationStore) RevokeToken(ctx context.Context, token *domain.RevokedAccessToken) error {
+	return errors.New("store unavailable")
+}
+func (failingRevocationStore) RevokeUser(ctx context.Context, revocation *domain.UserTokenRevocation) error {
+	return errors.New("store unavailable")
+}
+func (failingRevocationStore) IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+func (failingRevocationStore) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, errors.New("store unavailable")
+}
+
 func TestAuthMiddleware_RequireRole(t *testing.T) {
 	mw, _ := setupAuthMiddleware(t)
 
@@ -216,6 +302,75 @@ func TestAuthMiddleware_RequireAnyRole_Unauthenticated(t *testing.T) {
 	}
 }
 
+func TestAuthMiddleware_RequirePermission(t *testing.T) {
+	mw, tokenSvc := setupAuthMiddleware(t)
+
+	tests := []struct {
+		name     string
+		role     domain.Role
+		wantCode int
+	}{
+		{"granted by default", domain.RoleManager, http.StatusOK},
+		{"not granted", domain.RoleKitchen, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair, _, err := tokenSvc.GenerateTokenPair(&domain.User{ID: uuid.New(), Email: "user@example.com"}, uuid.New(), uuid.New(), tt.role, tt.role.Permissions())
+			if err != nil {
+				t.Fatalf("failed to generate token pair: %v", err)
+			}
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			handler := mw.RequireAuth(mw.RequirePermission(domain.PermTerminalsManage)(next))
+
+			req := httptest.NewRequest("GET", "/terminals", nil)
+			req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("Status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if tt.wantCode == http.StatusForbidden {
+				var errResp ErrorResponse
+				json.NewDecoder(w.Body).Decode(&errResp)
+				if errResp.Error.Code != "insufficient_permission" {
+					t.Errorf("error code = %q, want %q", errResp.Error.Code, "insufficient_permission")
+				}
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_RequirePermission_Unauthenticated(t *testing.T) {
+	mw, _ := setupAuthMiddleware(t)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := mw.RequirePermission(domain.PermUsersManage)(next)
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuthMiddleware_RequirePermission_Unregistered(t *testing.T) {
+	mw, _ := setupAuthMiddleware(t)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RequirePermission with an unregistered permission should panic")
+		}
+	}()
+	mw.RequirePermission(domain.Permission("orders.vodi"))
+}
+
 func TestExtractBearerToken(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -455,3 +610,333 @@ func TestWriteError(t *testing.T) {
 		t.Error("Body should not be empty")
 	}
 }
+
+// TestAuthMiddleware_Impersonation checks RequireAuth exposes the actor of
+// an impersonation token and DenyImpersonation turns it away.
+func TestAuthMiddleware_Impersonation(t *testing.T) {
+	mw, tokenSvc := setupAuthMiddleware(t)
+
+	user := &domain.User{ID: uuid.New(), Email: "waiter@example.com"}
+	actorID := uuid.New()
+	impersonation, err := tokenSvc.GenerateImpersonationToken(user, uuid.New(), actorID, domain.RoleWaiter, domain.RoleWaiter.Permissions(), domain.ImpersonationTTL)
+	if err != nil {
+		t.Fatalf("GenerateImpersonationToken failed: %v", err)
+	}
+	own, _, err := tokenSvc.GenerateTokenPair(user, uuid.New(), uuid.New(), domain.RoleWaiter, domain.RoleWaiter.Permissions())
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+
+	var gotActor uuid.UUID
+	var gotActorOK, gotServiceActor bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotActor, gotActorOK = GetActorID(r.Context())
+		_, gotServiceActor = service.ActorFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		token      string
+		deny       bool
+		wantStatus int
+		wantActor  bool
+	}{
+		{"impersonation", impersonation.AccessToken, false, http.StatusOK, true},
+		{"own token", own.AccessToken, false, http.StatusOK, false},
+		{"impersonation denied", impersonation.AccessToken, true, http.StatusForbidden, false},
+		{"own token on a denied route", own.AccessToken, true, http.StatusOK, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotActor, gotActorOK, gotServiceActor = uuid.Nil, false, false
+			var h http.Handler = next
+			if tt.deny {
+				h = mw.DenyImpersonation(h)
+			}
+			req := httptest.NewRequest("POST", "/change-password", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			mw.RequireAuth(h).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if gotActorOK != tt.wantActor || gotServiceActor != tt.wantActor || (tt.wantActor && gotActor != actorID) {
+				t.Errorf("actor = %v, %v (service context %v), want present = %v", gotActor, gotActorOK, gotServiceActor, tt.wantActor)
+			}
+			if tt.wantStatus == http.StatusForbidden {
+				var resp ErrorResponse
+				json.NewDecoder(w.Body).Decode(&resp)
+				if resp.Error.Code != "impersonation_forbidden" {
+					t.Errorf("Code = %q, want impersonation_forbidden", resp.Error.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestRequireApproval(t *testing.T) {
+	env := setupApprovalHandler(t)
+	approval, token, err := env.approvals.Approve(context.Background(), service.ApproveRequest{
+		TenantID: env.tenantID, RequesterID: env.cashierID, Action: domain.PermUsersManage, ResourceID: "user-1",
+		ApproverEmail: "manager@example.com", Password: "Password123!",
+	})
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+
+	var approvedBy uuid.UUID
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a, ok := GetApproval(r.Context()); ok {
+			approvedBy = a.ApprovedBy
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := RequireApproval(env.approvals, domain.PermUsersManage, "id")(next)
+
+	tests := []struct {
+		name       string
+		resourceID string
+		token      string
+		wantStatus int
+		wantCode   string
+	}{
+		{"no token", "user-1", "", http.StatusForbidden, "approval_required"},
+		{"other resource", "user-2", token, http.StatusForbidden, "approval_invalid"},
+		{"approved", "user-1", token, http.StatusOK, ""},
+		{"replayed", "user-1", token, http.StatusForbidden, "approval_used"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/users/"+tt.resourceID+"/something", nil).WithContext(authedContext(env.cashierID, env.tenantID, domain.RoleCashier))
+			req = withChiURLParam(req, "id", tt.resourceID)
+			if tt.token != "" {
+				req.Header.Set(ApprovalTokenHeader, tt.token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var errResp ErrorResponse
+			json.NewDecoder(w.Body).Decode(&errResp)
+			if errResp.Error.Code != tt.wantCode {
+				t.Errorf("error code = %q, want %q", errResp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+
+	if approvedBy != approval.ApprovedBy {
+		t.Errorf("GetApproval() approver = %s, want %s", approvedBy, approval.ApprovedBy)
+	}
+}
+
+func TestRequireApproval_Unauthenticated(t *testing.T) {
+	env := setupApprovalHandler(t)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	req := httptest.NewRequest("POST", "/users/user-1/something", nil)
+	w := httptest.NewRecorder()
+	RequireApproval(env.approvals, domain.PermUsersManage, "id")(next).ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRequireApproval_Unregistered(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RequireApproval with an unregistered permission should panic")
+		}
+	}()
+	RequireApproval(nil, "payments.teleport", "id")
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	apiKeys := mock.NewMockAPIKeyRepository()
+	serviceAccounts := service.NewServiceAccountService(service.ServiceAccountServiceConfig{
+		Accounts:  mock.NewMockServiceAccountRepository(),
+		APIKeys:   apiKeys,
+		EventRepo: mock.NewMockAuthEventRepository(),
+	})
+	mw := NewAuthMiddleware(service.NewAuthService(service.AuthServiceConfig{ServiceAccounts: serviceAccounts}))
+	ctx := context.Background()
+
+	tenantID := uuid.New()
+	account, err := serviceAccounts.Create(ctx, service.CreateServiceAccountRequest{
+		TenantID: tenantID, Name: "Accounting sync", Scopes: []domain.Permission{domain.PermUsersManage},
+	}, domain.RoleAdmin.Permissions())
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	newKey := func() (*domain.APIKey, string) {
+		key, plain, err := serviceAccounts.CreateAPIKey(ctx, service.CreateAPIKeyRequest{TenantID: tenantID, ServiceAccountID: account.ID, Name: "production"})
+		if err != nil {
+			t.Fatalf("CreateAPIKey failed: %v", err)
+		}
+		return key, plain
+	}
+	_, valid := newKey()
+	revoked, revokedPlain := newKey()
+	apiKeys.Revoke(ctx, revoked.ID)
+	expired, expiredPlain := newKey()
+	apiKeys.SetExpiry(ctx, expired.ID, time.Now().Add(-time.Second))
+
+	var gotAccount, gotUser, gotTenant uuid.UUID
+	var gotPerms []domain.Permission
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAccount, _ = GetServiceAccountID(r.Context())
+		gotUser, _ = GetUserID(r.Context())
+		gotTenant, _ = GetTenantID(r.Context())
+		gotPerms, _ = GetPermissions(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name        string
+		key         string
+		requireUser bool
+		wantStatus  int
+		wantCode    string
+	}{
+		{"valid key", valid, false, http.StatusOK, ""},
+		{"people-only route", valid, true, http.StatusForbidden, "service_account_forbidden"},
+		{"revoked key", revokedPlain, false, http.StatusUnauthorized, "api_key_invalid"},
+		{"expired key", expiredPlain, false, http.StatusUnauthorized, "api_key_expired"},
+		{"unknown key", domain.APIKeyPrefix + "nope", false, http.StatusUnauthorized, "api_key_invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h http.Handler = next
+			if tt.requireUser {
+				h = mw.RequireUser(h)
+			}
+			req := httptest.NewRequest("GET", "/reports", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.key)
+			w := httptest.NewRecorder()
+
+			mw.RequireAuth(h).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantCode != "" {
+				var resp ErrorResponse
+				json.NewDecoder(w.Body).Decode(&resp)
+				if resp.Error.Code != tt.wantCode {
+					t.Errorf("Code = %q, want %q", resp.Error.Code, tt.wantCode)
+				}
+			}
+		})
+	}
+
+	if gotAccount != account.ID || gotUser != account.ID || gotTenant != tenantID {
+		t.Errorf("context = account %s, user %s, tenant %s, want the service account in %s", gotAccount, gotUser, gotTenant, tenantID)
+	}
+	if len(gotPerms) != 1 || gotPerms[0] != domain.PermUsersManage {
+		t.Errorf("permissions = %v, want the account's scopes", gotPerms)
+	}
+
+	// Without service accounts configured, keys are just invalid
+	noKeys, _ := setupAuthMiddleware(t)
+	req := httptest.NewRequest("GET", "/reports", nil)
+	req.Header.Set("Authorization", "Bearer "+valid)
+	w := httptest.NewRecorder()
+	noKeys.RequireAuth(next).ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d without service accounts", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuthMiddleware_OAuthClientToken(t *testing.T) {
+	noOAuth, tokenSvc := setupAuthMiddleware(t)
+	oauthSvc := service.NewOAuthService(service.OAuthServiceConfig{
+		Clients:       mock.NewMockOAuthClientRepository(),
+		Consents:      mock.NewMockOAuthConsentRepository(),
+		Codes:         mock.NewMockOAuthCodeRepository(),
+		RefreshTokens: mock.NewMockOAuthRefreshTokenRepository(),
+		UserRepo:      mock.NewMockUserRepository(),
+		EventRepo:     mock.NewMockAuthEventRepository(),
+		TokenService:  tokenSvc,
+	})
+	mw := NewAuthMiddleware(service.NewAuthService(service.AuthServiceConfig{
+		TokenService: tokenSvc,
+		OAuth:        oauthSvc,
+	}))
+	ctx := context.Background()
+
+	tenantID := uuid.New()
+	client, secret, err := oauthSvc.CreateClient(ctx, service.CreateOAuthClientRequest{
+		TenantID:     tenantID,
+		Name:         "Reservas Online",
+		RedirectURIs: []string{"https://reservas.example.com/cb"},
+		Scopes:       []domain.Permission{domain.PermTerminalsManage},
+		CreatedBy:    uuid.New(),
+	}, domain.RoleAdmin.Permissions())
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	tokens, err := oauthSvc.Token(ctx, service.TokenRequest{
+		GrantType:    "client_credentials",
+		ClientID:     client.ID.String(),
+		ClientSecret: secret,
+	})
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+
+	var gotClient, gotTenant uuid.UUID
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotClient, _ = GetOAuthClientID(r.Context())
+		gotTenant, _ = GetTenantID(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name        string
+		mw          *AuthMiddleware
+		requireUser bool
+		wantStatus  int
+		wantCode    string
+	}{
+		{"client token", mw, false, http.StatusOK, ""},
+		{"people-only route", mw, true, http.StatusForbidden, "oauth_client_forbidden"},
+		{"without oauth configured", noOAuth, false, http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h http.Handler = next
+			if tt.requireUser {
+				h = tt.mw.RequireUser(h)
+			}
+			req := httptest.NewRequest("GET", "/reports", nil)
+			req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+			w := httptest.NewRecorder()
+
+			tt.mw.RequireAuth(h).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantCode != "" {
+				var resp ErrorResponse
+				json.NewDecoder(w.Body).Decode(&resp)
+				if resp.Error.Code != tt.wantCode {
+					t.Errorf("Code = %q, want %q", resp.Error.Code, tt.wantCode)
+				}
+			}
+		})
+	}
+
+	if gotClient != client.ID || gotTenant != tenantID {
+		t.Errorf("context = client %s, tenant %s, want %s in %s", gotClient, gotTenant, client.ID, tenantID)
+	}
+}
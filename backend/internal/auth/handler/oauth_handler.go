@@ -0,0 +1,570 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+	"github.com/solobueno/erp/pkg/oauth"
+)
+
+// OAuthHandler handles the OAuth 2.0 authorization server's endpoints:
+// the authorization, token and revocation endpoints used by third-party
+// clients, and the management of clients and consents.
+type OAuthHandler struct {
+	oauth *service.OAuthService
+}
+
+// NewOAuthHandler creates a new OAuthHandler.
+func NewOAuthHandler(oauthService *service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{oauth: oauthService}
+}
+
+// GetAuthorization handles GET /oauth/authorize.
+//
+// @Summary      Check an authorization request
+// @Description  Validates an OAuth authorization request (RFC 6749 section 4.1.1) for the consent screen, with the client, the scopes it asks for and any it was already granted. The owner of the current tenant must approve it with POST /oauth/authorize. A request that is invalid in a way the client must hear of returns only redirect_to, carrying the error back to the client. PKCE with S256 is required; scope defaults to all of the client's scopes.
+// @Tags         oauth
+// @Security     BearerAuth
+// @Produce      json
+// @Param        response_type          query     string  true   "Must be code"
+// @Param        client_id              query     string  true   "Client ID"
+// @Param        redirect_uri           query     string  false  "One of the client's redirect URIs; optional if it has only one"
+// @Param        scope                  query     string  false  "Space-separated permissions"
+// @Param        state                  query     string  false  "Opaque value returned to the client"
+// @Param        code_challenge         query     string  true   "PKCE code challenge"
+// @Param        code_challenge_method  query     string  true   "Must be S256"
+// @Success      200  {object}  OAuthAuthorizationResponse
+// @Failure      400  {object}  ErrorResponse "invalid_client, invalid_redirect_uri"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden"
+// @Router       /oauth/authorize [get]
+func (h *OAuthHandler) GetAuthorization(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+	perms, _ := GetPermissions(r.Context())
+
+	q := r.URL.Query()
+	auth, err := h.oauth.ValidateAuthorization(r.Context(), service.AuthorizeRequest{
+		TenantID:            tenantID,
+		UserID:              userID,
+		ClientID:            q.Get("client_id"),
+		RedirectURI:         q.Get("redirect_uri"),
+		ResponseType:        q.Get("response_type"),
+		Scope:               q.Get("scope"),
+		State:               q.Get("state"),
+		CodeChallenge:       q.Get("code_challenge"),
+		CodeChallengeMethod: q.Get("code_challenge_method"),
+	}, perms)
+	if err != nil {
+		writeAuthorizationError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToOAuthAuthorizationResponse(auth))
+}
+
+// Authorize handles POST /oauth/authorize.
+//
+// @Summary      Approve or deny an authorization request
+// @Description  The owner of the current tenant approves or denies an OAuth authorization request, with the same parameters as GET /oauth/authorize. Send the user to redirect_to: on approval it carries an authorization code, valid for 10 minutes, and the tenant's consent for the client is recorded, replacing any earlier one; on denial it carries access_denied.
+// @Tags         oauth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      OAuthAuthorizeRequest  true  "Authorization decision"
+// @Success      200      {object}  OAuthRedirectResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, invalid_client, invalid_redirect_uri"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden"
+// @Router       /oauth/authorize [post]
+func (h *OAuthHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+	perms, _ := GetPermissions(r.Context())
+
+	var req OAuthAuthorizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	redirectTo, err := h.oauth.Authorize(r.Context(), service.AuthorizeRequest{
+		TenantID:            tenantID,
+		UserID:              userID,
+		ClientID:            req.ClientID,
+		RedirectURI:         req.RedirectURI,
+		ResponseType:        req.ResponseType,
+		Scope:               req.Scope,
+		State:               req.State,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		IPAddress:           GetClientIP(r),
+	}, req.Approve, perms)
+	if err != nil {
+		writeAuthorizationError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, OAuthRedirectResponse{RedirectTo: redirectTo})
+}
+
+// Token handles POST /oauth/token.
+//
+// @Summary      Token endpoint
+// @Description  OAuth 2.0 token endpoint (RFC 6749 section 3.2) for the authorization_code (with code_verifier), refresh_token and client_credentials grants. Clients authenticate with HTTP Basic or client_id and client_secret in the form; public clients send only client_id. Refresh tokens are rotated on every use, and replaying a used code or refresh token revokes the grant. Access tokens are JWTs verifiable at /.well-known/jwks.json, carrying the client in the client_id claim.
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        grant_type     formData  string  true   "authorization_code, refresh_token or client_credentials"
+// @Param        code           formData  string  false  "Authorization code"
+// @Param        redirect_uri   formData  string  false  "Redirect URI the code was issued to"
+// @Param        code_verifier  formData  string  false  "PKCE code verifier"
+// @Param        refresh_token  formData  string  false  "Refresh token"
+// @Param        scope          formData  string  false  "Space-separated permissions, narrowing the grant"
+// @Param        client_id      formData  string  false  "Client ID, if not sent with HTTP Basic"
+// @Param        client_secret  formData  string  false  "Client secret, if not sent with HTTP Basic"
+// @Success      200  {object}  oauth.TokenResponse
+// @Failure      400  {object}  OAuthErrorResponse "invalid_request, invalid_grant, unauthorized_client, unsupported_grant_type, invalid_scope"
+// @Failure      401  {object}  OAuthErrorResponse "invalid_client"
+// @Router       /oauth/token [post]
+func (h *OAuthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuthProtocolError(w, r, oauth.NewError(oauth.ErrorInvalidRequest, "invalid form body"))
+		return
+	}
+	clientID, clientSecret, err := oauth.ClientCredentials(r)
+	if err != nil {
+		writeOAuthProtocolError(w, r, err)
+		return
+	}
+
+	resp, err := h.oauth.Token(r.Context(), service.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		Scope:        r.PostForm.Get("scope"),
+		IPAddress:    GetClientIP(r),
+	})
+	if err != nil {
+		writeOAuthProtocolError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Revoke handles POST /oauth/revoke.
+//
+// @Summary      Revoke a token
+// @Description  OAuth 2.0 token revocation (RFC 7009). A client revokes one of its refresh tokens, ending the whole grant, or one of its access tokens. Clients authenticate as at the token endpoint. Unknown tokens are ignored, so the response is the same whether or not anything was revoked.
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Param        token            formData  string  true   "Token to revoke"
+// @Param        token_type_hint  formData  string  false  "access_token or refresh_token"
+// @Param        client_id        formData  string  false  "Client ID, if not sent with HTTP Basic"
+// @Param        client_secret    formData  string  false  "Client secret, if not sent with HTTP Basic"
+// @Success      200  "OK"
+// @Failure      400  {object}  OAuthErrorResponse "invalid_request"
+// @Failure      401  {object}  OAuthErrorResponse "invalid_client"
+// @Router       /oauth/revoke [post]
+func (h *OAuthHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthProtocolError(w, r, oauth.NewError(oauth.ErrorInvalidRequest, "invalid form body"))
+		return
+	}
+	clientID, clientSecret, err := oauth.ClientCredentials(r)
+	if err != nil {
+		writeOAuthProtocolError(w, r, err)
+		return
+	}
+
+	if err := h.oauth.RevokeToken(r.Context(), service.RevokeTokenRequest{
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		Token:         r.PostForm.Get("token"),
+		TokenTypeHint: r.PostForm.Get("token_type_hint"),
+		IPAddress:     GetClientIP(r),
+	}); err != nil {
+		writeOAuthProtocolError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ListConsents handles GET /oauth/consents.
+//
+// @Summary      List authorized apps
+// @Description  The owner lists the OAuth clients the current tenant has authorized, with the scopes each was granted.
+// @Tags         oauth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  OAuthConsentListResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden"
+// @Router       /oauth/consents [get]
+func (h *OAuthHandler) ListConsents(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	consents, err := h.oauth.ListConsents(r.Context(), tenantID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToOAuthConsentListResponse(consents))
+}
+
+// RevokeConsent handles DELETE /oauth/consents/{id}.
+//
+// @Summary      Revoke an app's access
+// @Description  The owner withdraws the current tenant's authorization of an OAuth client. The client's tokens for the tenant stop working at once.
+// @Tags         oauth
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Consent ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /oauth/consents/{id} [delete]
+func (h *OAuthHandler) RevokeConsent(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid consent ID format")
+		return
+	}
+
+	if err := h.oauth.RevokeConsent(r.Context(), tenantID, id, callerID, GetClientIP(r)); err != nil {
+		writeOAuthError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListClients handles GET /oauth/clients.
+//
+// @Summary      List OAuth clients
+// @Description  Admin+ lists the OAuth clients the current tenant has registered, ordered by name.
+// @Tags         oauth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  OAuthClientListResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden"
+// @Router       /oauth/clients [get]
+func (h *OAuthHandler) ListClients(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	clients, err := h.oauth.ListClients(r.Context(), tenantID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToOAuthClientListResponse(clients))
+}
+
+// GetClient handles GET /oauth/clients/{id}.
+//
+// @Summary      Get an OAuth client
+// @Description  Admin+ gets one of the OAuth clients the current tenant has registered.
+// @Tags         oauth
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Client ID"
+// @Success      200  {object}  OAuthClientResponse
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /oauth/clients/{id} [get]
+func (h *OAuthHandler) GetClient(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid client ID format")
+		return
+	}
+
+	client, err := h.oauth.GetClient(r.Context(), tenantID, id)
+	if err != nil {
+		writeOAuthError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToOAuthClientResponse(client))
+}
+
+// CreateClient handles POST /oauth/clients.
+//
+// @Summary      Register an OAuth client
+// @Description  Admin+ registers a third-party app, such as a reservation or delivery app, that the owner of any tenant can then authorize. Its scopes are the most any tenant can grant it, and must be ones you hold yourself. A confidential client gets a secret, shown only this once; a public client (a mobile or browser app) gets none and relies on PKCE.
+// @Tags         oauth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CreateOAuthClientRequest  true  "Client"
+// @Success      201      {object}  CreateOAuthClientResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, invalid_name, invalid_redirect_uri, unknown_permission"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role, impersonation_not_allowed, permission_not_held, service_account_forbidden, oauth_client_forbidden"
+// @Router       /oauth/clients [post]
+func (h *OAuthHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+	callerPerms, _ := GetPermissions(r.Context())
+
+	var req CreateOAuthClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	client, secret, err := h.oauth.CreateClient(r.Context(), service.CreateOAuthClientRequest{
+		TenantID:     tenantID,
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       req.Scopes,
+		Public:       req.Public,
+		CreatedBy:    callerID,
+		IPAddress:    GetClientIP(r),
+	}, callerPerms)
+	if err != nil {
+		writeOAuthError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, CreateOAuthClientResponse{
+		OAuthClientResponse: ToOAuthClientResponse(client),
+		ClientSecret:        secret,
+	})
+}
+
+// UpdateClient handles PATCH /oauth/clients/{id}.
+//
+// @Summary      Update an OAuth client
+// @Description  Admin+ renames an OAuth client or replaces its redirect URIs or scopes. Narrowed scopes apply to the client's next tokens in every tenant.
+// @Tags         oauth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                    true  "Client ID"
+// @Param        request  body      UpdateOAuthClientRequest  true  "Fields to update"
+// @Success      200      {object}  OAuthClientResponse
+// @Failure      400      {object}  ErrorResponse "invalid_id, invalid_request, invalid_name, invalid_redirect_uri, unknown_permission"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role, impersonation_not_allowed, permission_not_held, service_account_forbidden, oauth_client_forbidden"
+// @Failure      404      {object}  ErrorResponse "not_found"
+// @Router       /oauth/clients/{id} [patch]
+func (h *OAuthHandler) UpdateClient(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+	callerPerms, _ := GetPermissions(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid client ID format")
+		return
+	}
+
+	var req UpdateOAuthClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	client, err := h.oauth.UpdateClient(r.Context(), service.UpdateOAuthClientRequest{
+		TenantID:     tenantID,
+		ID:           id,
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       req.Scopes,
+		UpdatedBy:    callerID,
+		IPAddress:    GetClientIP(r),
+	}, callerPerms)
+	if err != nil {
+		writeOAuthError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToOAuthClientResponse(client))
+}
+
+// DeleteClient handles DELETE /oauth/clients/{id}.
+//
+// @Summary      Delete an OAuth client
+// @Description  Admin+ deletes an OAuth client, withdrawing every tenant's authorization of it. Its tokens stop working at once.
+// @Tags         oauth
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Client ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /oauth/clients/{id} [delete]
+func (h *OAuthHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid client ID format")
+		return
+	}
+
+	if err := h.oauth.DeleteClient(r.Context(), tenantID, id, callerID, GetClientIP(r)); err != nil {
+		writeOAuthError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RotateClientSecret handles POST /oauth/clients/{id}/secret.
+//
+// @Summary      Rotate a client secret
+// @Description  Admin+ replaces a confidential client's secret with a new one, shown only this once. The old secret stops working at once; tokens already issued are unaffected.
+// @Tags         oauth
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Client ID"
+// @Success      201  {object}  OAuthClientSecretResponse
+// @Failure      400  {object}  ErrorResponse "invalid_id, public_client"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role, impersonation_not_allowed, service_account_forbidden, oauth_client_forbidden"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /oauth/clients/{id}/secret [post]
+func (h *OAuthHandler) RotateClientSecret(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid client ID format")
+		return
+	}
+
+	secret, err := h.oauth.RotateClientSecret(r.Context(), tenantID, id, callerID, GetClientIP(r))
+	if err != nil {
+		writeOAuthError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, OAuthClientSecretResponse{ClientSecret: secret})
+}
+
+// writeOAuthError maps OAuth client and consent errors to responses.
+func writeOAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrOAuthClientNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "OAuth client not found")
+	case errors.Is(err, domain.ErrOAuthConsentNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "Consent not found")
+	case errors.Is(err, domain.ErrOAuthRedirectURI):
+		writeError(w, http.StatusBadRequest, "invalid_redirect_uri", err.Error())
+	case errors.Is(err, domain.ErrOAuthClientName):
+		writeError(w, http.StatusBadRequest, "invalid_name", "Name must be 1-100 characters")
+	case errors.Is(err, domain.ErrOAuthClientPublic):
+		writeError(w, http.StatusBadRequest, "public_client", "Public clients have no secret")
+	case errors.Is(err, domain.ErrUnknownPermission):
+		writeError(w, http.StatusBadRequest, "unknown_permission", err.Error())
+	case errors.Is(err, domain.ErrPermissionNotHeld):
+		writeError(w, http.StatusForbidden, "permission_not_held", err.Error())
+	default:
+		writeInternalError(w, r, err)
+	}
+}
+
+// writeAuthorizationError maps errors of an authorization request that
+// can't be redirected back to the client to responses.
+func writeAuthorizationError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrOAuthClientNotFound):
+		writeError(w, http.StatusBadRequest, "invalid_client", "Unknown OAuth client")
+	case errors.Is(err, domain.ErrOAuthRedirectURI):
+		writeError(w, http.StatusBadRequest, "invalid_redirect_uri", "Redirect URI is not registered for this client")
+	default:
+		writeInternalError(w, r, err)
+	}
+}
+
+// writeOAuthProtocolError writes an error from the token or revocation
+// endpoint in the OAuth error format (RFC 6749 section 5.2). Errors other
+// than *oauth.Error are logged and written as server_error.
+func writeOAuthProtocolError(w http.ResponseWriter, r *http.Request, err error) {
+	var oerr *oauth.Error
+	if !errors.As(err, &oerr) {
+		logUnhandledError(r, err)
+		oerr = oauth.NewError(oauth.ErrorServerError, "an unexpected error occurred")
+	}
+	if oerr.Status() == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	writeJSON(w, oerr.Status(), oerr)
+}
@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/internal/auth/service"
+	"github.com/solobueno/erp/pkg/oauth"
+)
+
+const (
+	oauthTestRedirectURI = "https://reservas.example.com/cb"
+	oauthTestVerifier    = "dBjftJeZ4CVP-mJ92K9ZBuTxmfPmOgS1mWXGmAu8VkV"
+)
+
+// setupOAuthHandler returns an OAuthHandler, its service and the context of
+// the owner of a tenant. Client tokens reaching other endpoints are covered
+// by the middleware and e2e tests.
+func setupOAuthHandler(t *testing.T) (*OAuthHandler, *service.OAuthService, context.Context) {
+	t.Helper()
+	_, tokenSvc := setupAuthMiddleware(t)
+
+	userRepo := mock.NewMockUserRepository()
+	tenantID := uuid.New()
+	owner := &domain.User{
+		ID:          uuid.New(),
+		Email:       "ana@casa-ana.com",
+		IsActive:    true,
+		TenantRoles: []domain.UserTenantRole{{TenantID: tenantID, Role: domain.RoleOwner}},
+	}
+	userRepo.AddUser(owner)
+
+	svc := service.NewOAuthService(service.OAuthServiceConfig{
+		Clients:       mock.NewMockOAuthClientRepository(),
+		Consents:      mock.NewMockOAuthConsentRepository(),
+		Codes:         mock.NewMockOAuthCodeRepository(),
+		RefreshTokens: mock.NewMockOAuthRefreshTokenRepository(),
+		UserRepo:      userRepo,
+		EventRepo:     mock.NewMockAuthEventRepository(),
+		TokenService:  tokenSvc,
+	})
+	ctx := authedContext(owner.ID, tenantID, domain.RoleOwner)
+	ctx = context.WithValue(ctx, PermissionsContextKey, domain.RoleOwner.Permissions())
+	return NewOAuthHandler(svc), svc, ctx
+}
+
+// oauthRequest builds a JSON request as the context's user with the given
+// chi route params, as key/value pairs.
+func oauthRequest(ctx context.Context, method, target, body string, params ...string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	for i := 0; i+1 < len(params); i += 2 {
+		rctx.URLParams.Add(params[i], params[i+1])
+	}
+	return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+}
+
+// oauthFormRequest builds a form request to the token or revocation
+// endpoint.
+func oauthFormRequest(target string, form url.Values) *http.Request {
+	req := httptest.NewRequest("POST", target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// createOAuthClient registers a confidential client through the handler.
+func createOAuthClient(t *testing.T, h *OAuthHandler, ctx context.Context) CreateOAuthClientResponse {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.CreateClient(w, oauthRequest(ctx, "POST", "/oauth/clients",
+		`{"name":"Reservas Online","redirect_uris":["`+oauthTestRedirectURI+`"],"scopes":["terminals.manage"]}`))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("CreateClient status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var client CreateOAuthClientResponse
+	json.NewDecoder(w.Body).Decode(&client)
+	return client
+}
+
+func TestOAuthHandler_ClientLifecycle(t *testing.T) {
+	h, _, ctx := setupOAuthHandler(t)
+
+	client := createOAuthClient(t, h, ctx)
+	id := client.ID.String()
+	if client.ClientSecret == "" || client.Public {
+		t.Fatalf("CreateClient = %+v, want a confidential client with a secret", client)
+	}
+
+	w := httptest.NewRecorder()
+	h.UpdateClient(w, oauthRequest(ctx, "PATCH", "/oauth/clients/"+id, `{"name":"Reservas"}`, "id", id))
+	var updated OAuthClientResponse
+	json.NewDecoder(w.Body).Decode(&updated)
+	if w.Code != http.StatusOK || updated.Name != "Reservas" || len(updated.RedirectURIs) != 1 {
+		t.Fatalf("UpdateClient = %d %+v", w.Code, updated)
+	}
+
+	w = httptest.NewRecorder()
+	h.RotateClientSecret(w, oauthRequest(ctx, "POST", "/oauth/clients/"+id+"/secret", "", "id", id))
+	var rotated OAuthClientSecretResponse
+	json.NewDecoder(w.Body).Decode(&rotated)
+	if w.Code != http.StatusCreated || rotated.ClientSecret == "" || rotated.ClientSecret == client.ClientSecret {
+		t.Fatalf("RotateClientSecret = %d %+v, want a new secret", w.Code, rotated)
+	}
+
+	w = httptest.NewRecorder()
+	h.ListClients(w, oauthRequest(ctx, "GET", "/oauth/clients", ""))
+	var list OAuthClientListResponse
+	json.NewDecoder(w.Body).Decode(&list)
+	if len(list.Data) != 1 {
+		t.Fatalf("ListClients = %+v, want the client", list.Data)
+	}
+	if strings.Contains(w.Body.String(), rotated.ClientSecret) {
+		t.Error("client list must not expose secrets")
+	}
+
+	w = httptest.NewRecorder()
+	h.DeleteClient(w, oauthRequest(ctx, "DELETE", "/oauth/clients/"+id, "", "id", id))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("DeleteClient status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	w = httptest.NewRecorder()
+	h.GetClient(w, oauthRequest(ctx, "GET", "/oauth/clients/"+id, "", "id", id))
+	assertErrorCode(t, w, http.StatusNotFound, "not_found")
+}
+
+func TestOAuthHandler_BadRequests(t *testing.T) {
+	h, _, ctx := setupOAuthHandler(t)
+
+	w := httptest.NewRecorder()
+	h.CreateClient(w, oauthRequest(ctx, "POST", "/oauth/clients",
+		`{"name":"Reservas App","redirect_uris":["http://localhost:8080/cb"],"public":true}`))
+	var public CreateOAuthClientResponse
+	json.NewDecoder(w.Body).Decode(&public)
+	if w.Code != http.StatusCreated || !public.Public || public.ClientSecret != "" {
+		t.Fatalf("CreateClient public = %d %+v, want no secret", w.Code, public)
+	}
+	publicID := public.ID.String()
+	id := uuid.New().String()
+
+	tests := []struct {
+		name       string
+		handle     http.HandlerFunc
+		req        *http.Request
+		wantStatus int
+		wantCode   string
+	}{
+		{"create invalid body", h.CreateClient, oauthRequest(ctx, "POST", "/oauth/clients", "{"), http.StatusBadRequest, "invalid_request"},
+		{"create blank name", h.CreateClient, oauthRequest(ctx, "POST", "/oauth/clients", `{"name":" ","redirect_uris":["`+oauthTestRedirectURI+`"]}`), http.StatusBadRequest, "invalid_name"},
+		{"create http redirect", h.CreateClient, oauthRequest(ctx, "POST", "/oauth/clients", `{"name":"Reservas","redirect_uris":["http://reservas.example.com/cb"]}`), http.StatusBadRequest, "invalid_redirect_uri"},
+		{"create unknown scope", h.CreateClient, oauthRequest(ctx, "POST", "/oauth/clients", `{"name":"Reservas","redirect_uris":["`+oauthTestRedirectURI+`"],"scopes":["payments.teleport"]}`), http.StatusBadRequest, "unknown_permission"},
+		{"get invalid id", h.GetClient, oauthRequest(ctx, "GET", "/oauth/clients/x", "", "id", "x"), http.StatusBadRequest, "invalid_id"},
+		{"update missing", h.UpdateClient, oauthRequest(ctx, "PATCH", "/oauth/clients/"+id, `{"name":"Reservas"}`, "id", id), http.StatusNotFound, "not_found"},
+		{"rotate public", h.RotateClientSecret, oauthRequest(ctx, "POST", "/oauth/clients/"+publicID+"/secret", "", "id", publicID), http.StatusBadRequest, "public_client"},
+		{"revoke consent missing", h.RevokeConsent, oauthRequest(ctx, "DELETE", "/oauth/consents/"+id, "", "id", id), http.StatusNotFound, "not_found"},
+		{"authorize unknown client", h.GetAuthorization, oauthRequest(ctx, "GET", "/oauth/authorize?response_type=code&client_id="+id, ""), http.StatusBadRequest, "invalid_client"},
+		{"authorize unregistered redirect", h.GetAuthorization, oauthRequest(ctx, "GET", "/oauth/authorize?response_type=code&client_id="+publicID+"&redirect_uri=https://evil.example.com/cb", ""), http.StatusBadRequest, "invalid_redirect_uri"},
+		{"authorize invalid body", h.Authorize, oauthRequest(ctx, "POST", "/oauth/authorize", "{"), http.StatusBadRequest, "invalid_request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handle(w, tt.req)
+			assertErrorCode(t, w, tt.wantStatus, tt.wantCode)
+		})
+	}
+}
+
+func TestOAuthHandler_AuthorizationCodeFlow(t *testing.T) {
+	h, _, ctx := setupOAuthHandler(t)
+	client := createOAuthClient(t, h, ctx)
+
+	sum := sha256.Sum256([]byte(oauthTestVerifier))
+	params := url.Values{
+		"response_type":         {oauth.ResponseTypeCode},
+		"client_id":             {client.ID.String()},
+		"scope":                 {"terminals.manage"},
+		"state":                 {"xyz"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {oauth.CodeChallengeMethodS256},
+	}
+
+	// The consent screen
+	w := httptest.NewRecorder()
+	h.GetAuthorization(w, oauthRequest(ctx, "GET", "/oauth/authorize?"+params.Encode(), ""))
+	var screen OAuthAuthorizationResponse
+	json.NewDecoder(w.Body).Decode(&screen)
+	if w.Code != http.StatusOK || screen.Client == nil || screen.Client.Name != "Reservas Online" {
+		t.Fatalf("GetAuthorization = %d %+v", w.Code, screen)
+	}
+	if screen.RedirectURI != oauthTestRedirectURI || len(screen.Scopes) != 1 || len(screen.ConsentedScopes) != 0 {
+		t.Errorf("GetAuthorization = %+v, want the only redirect URI and the scope, nothing consented yet", screen)
+	}
+
+	// The owner approves
+	body, _ := json.Marshal(OAuthAuthorizeRequest{
+		ResponseType:        params.Get("response_type"),
+		ClientID:            params.Get("client_id"),
+		Scope:               params.Get("scope"),
+		State:               params.Get("state"),
+		CodeChallenge:       params.Get("code_challenge"),
+		CodeChallengeMethod: params.Get("code_challenge_method"),
+		Approve:             true,
+	})
+	w = httptest.NewRecorder()
+	h.Authorize(w, oauthRequest(ctx, "POST", "/oauth/authorize", string(body)))
+	var redirect OAuthRedirectResponse
+	json.NewDecoder(w.Body).Decode(&redirect)
+	redirectTo, err := url.Parse(redirect.RedirectTo)
+	if w.Code != http.StatusOK || err != nil {
+		t.Fatalf("Authorize = %d %+v", w.Code, redirect)
+	}
+	code := redirectTo.Query().Get("code")
+	if code == "" || redirectTo.Query().Get("state") != "xyz" {
+		t.Fatalf("Authorize redirected to %s, want a code and the state", redirect.RedirectTo)
+	}
+
+	// The client exchanges the code, authenticating with HTTP Basic
+	exchange := func() *httptest.ResponseRecorder {
+		req := oauthFormRequest("/oauth/token", url.Values{
+			"grant_type":    {oauth.GrantAuthorizationCode},
+			"code":          {code},
+			"redirect_uri":  {oauthTestRedirectURI},
+			"code_verifier": {oauthTestVerifier},
+		})
+		req.SetBasicAuth(client.ID.String(), client.ClientSecret)
+		w := httptest.NewRecorder()
+		h.Token(w, req)
+		return w
+	}
+	w = exchange()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Token status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", w.Header().Get("Cache-Control"))
+	}
+	var tokens oauth.TokenResponse
+	json.NewDecoder(w.Body).Decode(&tokens)
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" || tokens.TokenType != oauth.TokenTypeBearer || tokens.Scope != "terminals.manage" {
+		t.Errorf("Token = %+v", tokens)
+	}
+
+	// Replaying the code fails in the OAuth error format
+	w = exchange()
+	var oerr OAuthErrorResponse
+	json.NewDecoder(w.Body).Decode(&oerr)
+	if w.Code != http.StatusBadRequest || oerr.Error != oauth.ErrorInvalidGrant {
+		t.Errorf("replayed code = %d %+v, want %d %s", w.Code, oerr, http.StatusBadRequest, oauth.ErrorInvalidGrant)
+	}
+
+	// The owner sees and revokes the app's access
+	w = httptest.NewRecorder()
+	h.ListConsents(w, oauthRequest(ctx, "GET", "/oauth/consents", ""))
+	var consents OAuthConsentListResponse
+	json.NewDecoder(w.Body).Decode(&consents)
+	if len(consents.Data) != 1 || consents.Data[0].Client.ID != client.ID {
+		t.Fatalf("ListConsents = %+v, want the client's consent", consents.Data)
+	}
+	consentID := consents.Data[0].ID.String()
+	w = httptest.NewRecorder()
+	h.RevokeConsent(w, oauthRequest(ctx, "DELETE", "/oauth/consents/"+consentID, "", "id", consentID))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("RevokeConsent status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestOAuthHandler_ClientAuthentication(t *testing.T) {
+	h, _, ctx := setupOAuthHandler(t)
+	client := createOAuthClient(t, h, ctx)
+	clientID := client.ID.String()
+
+	tests := []struct {
+		name       string
+		endpoint   string
+		form       url.Values
+		basic      []string
+		wantStatus int
+		wantError  string
+	}{
+		{"client credentials in form", "/oauth/token", url.Values{"grant_type": {oauth.GrantClientCredentials}, "client_id": {clientID}, "client_secret": {client.ClientSecret}}, nil, http.StatusOK, ""},
+		{"client credentials with basic", "/oauth/token", url.Values{"grant_type": {oauth.GrantClientCredentials}}, []string{clientID, client.ClientSecret}, http.StatusOK, ""},
+		{"no client authentication", "/oauth/token", url.Values{"grant_type": {oauth.GrantClientCredentials}}, nil, http.StatusUnauthorized, oauth.ErrorInvalidClient},
+		{"wrong secret", "/oauth/token", url.Values{"grant_type": {oauth.GrantClientCredentials}}, []string{clientID, "nope"}, http.StatusUnauthorized, oauth.ErrorInvalidClient},
+		{"two authentication methods", "/oauth/token", url.Values{"grant_type": {oauth.GrantClientCredentials}, "client_secret": {client.ClientSecret}}, []string{clientID, client.ClientSecret}, http.StatusBadRequest, oauth.ErrorInvalidRequest},
+		{"unsupported grant", "/oauth/token", url.Values{"grant_type": {"password"}}, []string{clientID, client.ClientSecret}, http.StatusBadRequest, oauth.ErrorUnsupportedGrantType},
+		{"unknown scope", "/oauth/token", url.Values{"grant_type": {oauth.GrantClientCredentials}, "scope": {"users.manage"}}, []string{clientID, client.ClientSecret}, http.StatusBadRequest, oauth.ErrorInvalidScope},
+		{"revoke unknown token", "/oauth/revoke", url.Values{"token": {"nope"}}, []string{clientID, client.ClientSecret}, http.StatusOK, ""},
+		{"revoke wrong secret", "/oauth/revoke", url.Values{"token": {"nope"}}, []string{clientID, "nope"}, http.StatusUnauthorized, oauth.ErrorInvalidClient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := oauthFormRequest(tt.endpoint, tt.form)
+			if tt.basic != nil {
+				req.SetBasicAuth(tt.basic[0], tt.basic[1])
+			}
+			w := httptest.NewRecorder()
+			if tt.endpoint == "/oauth/revoke" {
+				h.Revoke(w, req)
+			} else {
+				h.Token(w, req)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantError == "" {
+				return
+			}
+			var resp OAuthErrorResponse
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("invalid_client responses must carry WWW-Authenticate")
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// PasskeyHandler handles WebAuthn passkey endpoints: managing a signed-in
+// user's passkeys, passwordless login, and passkeys as a second factor.
+type PasskeyHandler struct {
+	passkeys *service.PasskeyService
+}
+
+// NewPasskeyHandler creates a new PasskeyHandler.
+func NewPasskeyHandler(passkeys *service.PasskeyService) *PasskeyHandler {
+	return &PasskeyHandler{passkeys: passkeys}
+}
+
+// List handles GET /passkeys.
+//
+// @Summary      List my passkeys
+// @Description  List the passkeys the authenticated user has registered, oldest first.
+// @Tags         passkeys
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  PasskeyListResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Router       /auth/passkeys [get]
+func (h *PasskeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	passkeys, err := h.passkeys.List(r.Context(), userID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToPasskeyListResponse(passkeys))
+}
+
+// BeginRegistration handles POST /passkeys/register/begin.
+//
+// @Summary      Start registering a passkey
+// @Description  Get the options for navigator.credentials.create() to add a passkey to the authenticated user. Only managers, admins and owners may register passkeys. Post the resulting credential to /auth/passkeys/register/finish within 5 minutes.
+// @Tags         passkeys
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  PasskeyCreationOptionsResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "passkey_not_allowed, impersonation_not_allowed"
+// @Failure      409  {object}  ErrorResponse "passkey_limit"
+// @Failure      503  {object}  ErrorResponse "passkeys_unavailable"
+// @Router       /auth/passkeys/register/begin [post]
+func (h *PasskeyHandler) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	role, _ := GetRole(r.Context())
+
+	options, err := h.passkeys.BeginRegistration(r.Context(), userID, role)
+	if err != nil {
+		writePasskeyError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, PasskeyCreationOptionsResponse{PublicKey: options})
+}
+
+// FinishRegistration handles POST /passkeys/register/finish.
+//
+// @Summary      Finish registering a passkey
+// @Description  Verify the credential returned by navigator.credentials.create() and store it as a passkey. The passkey can then sign the user in without a password, or serve as their second factor after one.
+// @Tags         passkeys
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      PasskeyRegisterRequest  true  "Credential and optional name"
+// @Success      201      {object}  PasskeyResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, passkey_name"
+// @Failure      401      {object}  ErrorResponse "unauthorized, passkey_invalid, passkey_challenge_invalid"
+// @Failure      403      {object}  ErrorResponse "passkey_not_allowed, impersonation_not_allowed"
+// @Failure      409      {object}  ErrorResponse "passkey_limit, passkey_exists"
+// @Failure      503      {object}  ErrorResponse "passkeys_unavailable"
+// @Router       /auth/passkeys/register/finish [post]
+func (h *PasskeyHandler) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+	role, _ := GetRole(r.Context())
+
+	var req PasskeyRegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	passkey, err := h.passkeys.FinishRegistration(r.Context(), service.FinishPasskeyRegistrationRequest{
+		UserID:     userID,
+		TenantID:   tenantID,
+		Role:       role,
+		Name:       req.Name,
+		Credential: &req.Credential,
+		IPAddress:  GetClientIP(r),
+		UserAgent:  r.UserAgent(),
+	})
+	if err != nil {
+		writePasskeyError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, ToPasskeyResponse(passkey))
+}
+
+// Rename handles PATCH /passkeys/{id}.
+//
+// @Summary      Rename one of my passkeys
+// @Description  Change the name a passkey is listed under. An empty name resets it to "Passkey".
+// @Tags         passkeys
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                true  "Passkey ID"
+// @Param        request  body      RenamePasskeyRequest  true  "New name"
+// @Success      200      {object}  PasskeyResponse
+// @Failure      400      {object}  ErrorResponse "invalid_id, invalid_request, passkey_name"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      404      {object}  ErrorResponse "passkey_not_found"
+// @Router       /auth/passkeys/{id} [patch]
+func (h *PasskeyHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	passkeyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid passkey ID format")
+		return
+	}
+
+	var req RenamePasskeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	passkey, err := h.passkeys.Rename(r.Context(), service.RenamePasskeyRequest{
+		UserID:    userID,
+		TenantID:  tenantID,
+		PasskeyID: passkeyID,
+		Name:      req.Name,
+		IPAddress: GetClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		writePasskeyError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToPasskeyResponse(passkey))
+}
+
+// Remove handles DELETE /passkeys/{id}.
+//
+// @Summary      Remove one of my passkeys
+// @Description  Delete a passkey so it can no longer sign the user in. Users whose role requires MFA and who are left with no second factor must enroll again at their next login.
+// @Tags         passkeys
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Passkey ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "impersonation_not_allowed"
+// @Failure      404  {object}  ErrorResponse "passkey_not_found"
+// @Router       /auth/passkeys/{id} [delete]
+func (h *PasskeyHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	passkeyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid passkey ID format")
+		return
+	}
+
+	err = h.passkeys.Remove(r.Context(), service.RemovePasskeyRequest{
+		UserID:    userID,
+		TenantID:  tenantID,
+		PasskeyID: passkeyID,
+		IPAddress: GetClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		writePasskeyError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BeginLogin handles POST /passkeys/login/begin.
+//
+// @Summary      Start a passkey login
+// @Description  Get the options for navigator.credentials.get() to sign in without a password. The browser offers the passkeys saved for this site; post the resulting credential to /auth/passkeys/login/finish within 5 minutes.
+// @Tags         passkeys
+// @Produce      json
+// @Success      200  {object}  PasskeyRequestOptionsResponse
+// @Failure      503  {object}  ErrorResponse "passkeys_unavailable"
+// @Router       /auth/passkeys/login/begin [post]
+func (h *PasskeyHandler) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	options, err := h.passkeys.BeginLogin(r.Context())
+	if err != nil {
+		writePasskeyError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, PasskeyRequestOptionsResponse{PublicKey: options})
+}
+
+// FinishLogin handles POST /passkeys/login/finish.
+//
+// @Summary      Finish a passkey login
+// @Description  Verify the credential returned by navigator.credentials.get() and issue tokens. The passkey verifies the user with a PIN or biometric, so no MFA step follows. Only managers, admins and owners may sign in with a passkey. If the user belongs to multiple tenants and none is specified, returns 400 tenant_required with the list of choices; start a new login with tenant_id set.
+// @Tags         passkeys
+// @Accept       json
+// @Produce      json
+// @Param        request  body      PasskeyLoginRequest  true  "Credential and optional tenant"
+// @Success      200      {object}  LoginResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, tenant_required, invalid_tenant"
+// @Failure      401      {object}  ErrorResponse "passkey_invalid, passkey_challenge_invalid, account_disabled, tenant_inactive"
+// @Failure      403      {object}  ErrorResponse "passkey_not_allowed, password_login_disabled"
+// @Failure      423      {object}  ErrorResponse "account_locked"
+// @Failure      429      {object}  ErrorResponse "rate_limit_exceeded"
+// @Failure      503      {object}  ErrorResponse "passkeys_unavailable"
+// @Router       /auth/passkeys/login/finish [post]
+func (h *PasskeyHandler) FinishLogin(w http.ResponseWriter, r *http.Request) {
+	var req PasskeyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	resp, err := h.passkeys.FinishLogin(r.Context(), service.FinishPasskeyLoginRequest{
+		Credential: &req.Credential,
+		TenantID:   req.TenantID,
+		IPAddress:  GetClientIP(r),
+		UserAgent:  r.UserAgent(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTenantRequired):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TenantRequiredResponse{
+				Error: ErrorDetail{
+					Code:    "tenant_required",
+					Message: "User belongs to multiple tenants. Please sign in again with tenant_id.",
+					Tenants: ToTenantOptions(resp.Tenants),
+				},
+			})
+			return
+		case errors.Is(err, domain.ErrAccountLocked):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusLocked)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error: ErrorDetail{
+					Code:        "account_locked",
+					Message:     "Account temporarily locked after repeated failed login attempts. Try again later or request an unlock link.",
+					LockedUntil: resp.LockedUntil,
+				},
+			})
+			return
+		case errors.Is(err, domain.ErrRateLimitExceeded):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error: ErrorDetail{
+					Code:       "rate_limit_exceeded",
+					Message:    "Too many login attempts. Please try again later.",
+					RetryAfter: 60,
+				},
+			})
+			return
+		case errors.Is(err, domain.ErrUserNotInTenant):
+			writeError(w, http.StatusBadRequest, "invalid_tenant", "User does not belong to this tenant")
+			return
+		case errors.Is(err, domain.ErrPasswordLoginDisabled):
+			writeError(w, http.StatusForbidden, "password_login_disabled", "This organization requires signing in with single sign-on")
+			return
+		default:
+			writePasskeyError(w, r, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, ToLoginResponse(resp))
+}
+
+// BeginMFA handles POST /mfa/passkey/begin.
+//
+// @Summary      Start completing MFA with a passkey
+// @Description  For a login that returned 401 mfa_required with passkey among its mfa_methods: get the options for navigator.credentials.get(), limited to the user's passkeys. Post the resulting credential to /auth/mfa/passkey/verify.
+// @Tags         mfa
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFASetupRequest  true  "Challenge token"
+// @Success      200      {object}  PasskeyRequestOptionsResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, mfa_not_enrolled"
+// @Failure      401      {object}  ErrorResponse "mfa_challenge_invalid, account_disabled, tenant_inactive"
+// @Failure      503      {object}  ErrorResponse "passkeys_unavailable"
+// @Router       /auth/mfa/passkey/begin [post]
+func (h *PasskeyHandler) BeginMFA(w http.ResponseWriter, r *http.Request) {
+	var req MFASetupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.MFAToken == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "MFA token is required")
+		return
+	}
+
+	options, err := h.passkeys.BeginMFA(r.Context(), req.MFAToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFANotEnrolled) {
+			writeError(w, http.StatusBadRequest, "mfa_not_enrolled", "No passkey is registered. Use your authenticator app instead.")
+			return
+		}
+		writePasskeyError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, PasskeyRequestOptionsResponse{PublicKey: options})
+}
+
+// VerifyMFA handles POST /mfa/passkey/verify.
+//
+// @Summary      Complete MFA with a passkey
+// @Description  Exchange the mfa_token from a 401 mfa_required login response plus the credential returned by navigator.credentials.get() for tokens.
+// @Tags         mfa
+// @Accept       json
+// @Produce      json
+// @Param        request  body      PasskeyMFARequest  true  "Challenge token and credential"
+// @Success      200      {object}  LoginResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, invalid_tenant"
+// @Failure      401      {object}  ErrorResponse "mfa_challenge_invalid, passkey_invalid, passkey_challenge_invalid, account_disabled, tenant_inactive"
+// @Failure      503      {object}  ErrorResponse "passkeys_unavailable"
+// @Router       /auth/mfa/passkey/verify [post]
+func (h *PasskeyHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req PasskeyMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.MFAToken == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "MFA token is required")
+		return
+	}
+
+	resp, err := h.passkeys.FinishMFA(r.Context(), service.FinishPasskeyMFARequest{
+		MFAToken:   req.MFAToken,
+		Credential: &req.Credential,
+		IPAddress:  GetClientIP(r),
+		UserAgent:  r.UserAgent(),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotInTenant) {
+			writeError(w, http.StatusBadRequest, "invalid_tenant", "User does not belong to this tenant")
+			return
+		}
+		writePasskeyError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToLoginResponse(resp))
+}
+
+// writePasskeyError maps passkey errors, and the account and challenge
+// errors shared with the other login paths, to responses.
+func writePasskeyError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrPasskeysUnavailable):
+		writeError(w, http.StatusServiceUnavailable, "passkeys_unavailable", "Passkeys are not available on this server")
+	case errors.Is(err, domain.ErrPasskeyNotAllowed):
+		writeError(w, http.StatusForbidden, "passkey_not_allowed", "Passkeys are only available to managers, admins and owners")
+	case errors.Is(err, domain.ErrPasskeyLimit):
+		writeError(w, http.StatusConflict, "passkey_limit", "You have registered the maximum of 10 passkeys. Remove one first.")
+	case errors.Is(err, domain.ErrPasskeyExists):
+		writeError(w, http.StatusConflict, "passkey_exists", "This passkey is already registered")
+	case errors.Is(err, domain.ErrPasskeyName):
+		writeError(w, http.StatusBadRequest, "passkey_name", "Passkey name must be at most 100 characters")
+	case errors.Is(err, domain.ErrPasskeyNotFound):
+		writeError(w, http.StatusNotFound, "passkey_not_found", "Passkey not found")
+	case errors.Is(err, domain.ErrPasskeyChallengeInvalid):
+		writeError(w, http.StatusUnauthorized, "passkey_challenge_invalid", "This passkey request is invalid or has expired. Please start again.")
+	case errors.Is(err, domain.ErrPasskeyInvalid):
+		writeError(w, http.StatusUnauthorized, "passkey_invalid", "The passkey could not be verified")
+	case errors.Is(err, domain.ErrMFAChallengeInvalid):
+		writeError(w, http.StatusUnauthorized, "mfa_challenge_invalid", "MFA challenge is invalid or expired. Please log in again.")
+	case errors.Is(err, domain.ErrAccountDisabled):
+		writeError(w, http.StatusUnauthorized, "account_disabled", "Account is disabled")
+	case errors.Is(err, domain.ErrTenantInactive):
+		writeError(w, http.StatusUnauthorized, "tenant_inactive", "Tenant is inactive")
+	default:
+		writeInternalError(w, r, err)
+	}
+}
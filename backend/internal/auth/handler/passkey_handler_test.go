@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/internal/auth/service"
+	"github.com/solobueno/erp/pkg/webauthn"
+	"github.com/solobueno/erp/pkg/webauthn/webauthntest"
+)
+
+const testPasskeyOrigin = "https://erp.example.com"
+
+// setupPasskeyHandler returns a PasskeyHandler for the relying party rpID
+// (empty leaves passkeys unconfigured) and seeds a tenant with a manager
+// and a waiter.
+func setupPasskeyHandler(t *testing.T, rpID string) (h *PasskeyHandler, tenantID, managerID, waiterID uuid.UUID) {
+	t.Helper()
+
+	_, _, tokenSvc, userRepo, tenantRepo, roleRepo, sessionRepo := setupWiredAuthHandler(t)
+	eventRepo := mock.NewMockAuthEventRepository()
+	passkeys := mock.NewMockPasskeyRepository()
+
+	mfaSvc := service.NewMFAService(service.MFAServiceConfig{
+		MFARepo:       mock.NewMockMFARepository(),
+		ChallengeRepo: mock.NewMockMFAChallengeRepository(),
+		EventRepo:     eventRepo,
+		Passkeys:      passkeys,
+	})
+	authSvc := service.NewAuthService(service.AuthServiceConfig{
+		UserRepo:     userRepo,
+		SessionRepo:  sessionRepo,
+		EventRepo:    eventRepo,
+		TenantRepo:   tenantRepo,
+		RoleRepo:     roleRepo,
+		TokenService: tokenSvc,
+		MFAService:   mfaSvc,
+	})
+	passkeySvc := service.NewPasskeyService(service.PasskeyServiceConfig{
+		Passkeys:    passkeys,
+		Challenges:  mock.NewMockPasskeyChallengeRepository(),
+		UserRepo:    userRepo,
+		TenantRepo:  tenantRepo,
+		EventRepo:   eventRepo,
+		AuthService: authSvc,
+		MFAService:  mfaSvc,
+		WebAuthn:    webauthn.Config{RPID: rpID, RPName: "Solobueno ERP", Origins: []string{testPasskeyOrigin}},
+	})
+
+	tenantID, managerID, waiterID = uuid.New(), uuid.New(), uuid.New()
+	tenantRepo.AddTenant(&domain.Tenant{ID: tenantID, Name: "Acme", Slug: "acme", IsActive: true})
+	for id, role := range map[uuid.UUID]domain.Role{managerID: domain.RoleManager, waiterID: domain.RoleWaiter} {
+		userRepo.AddUser(&domain.User{
+			ID: id, Email: string(role) + "@example.com", IsActive: true,
+			TenantRoles: []domain.UserTenantRole{{ID: uuid.New(), UserID: id, TenantID: tenantID, Role: role}},
+		})
+	}
+	return NewPasskeyHandler(passkeySvc), tenantID, managerID, waiterID
+}
+
+// publicKeyOptions extracts the options a browser would pass to
+// navigator.credentials from a begin response.
+func publicKeyOptions(t *testing.T, w *httptest.ResponseRecorder) []byte {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("begin status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp struct {
+		PublicKey json.RawMessage `json:"publicKey"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	return resp.PublicKey
+}
+
+func TestPasskeyHandler_RegisterAndLogin(t *testing.T) {
+	h, tenantID, managerID, _ := setupPasskeyHandler(t, "erp.example.com")
+	ctx := authedContext(managerID, tenantID, domain.RoleManager)
+	authenticator := webauthntest.New(testPasskeyOrigin)
+
+	w := httptest.NewRecorder()
+	h.BeginRegistration(w, httptest.NewRequest("POST", "/passkeys/register/begin", nil).WithContext(ctx))
+	credential, err := authenticator.Register(publicKeyOptions(t, w))
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "Office laptop", "credential": json.RawMessage(credential)})
+	w = httptest.NewRecorder()
+	h.FinishRegistration(w, httptest.NewRequest("POST", "/passkeys/register/finish", bytes.NewReader(body)).WithContext(ctx))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("FinishRegistration status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "public_key") {
+		t.Error("response must not contain the public key")
+	}
+
+	w = httptest.NewRecorder()
+	h.List(w, httptest.NewRequest("GET", "/passkeys", nil).WithContext(ctx))
+	var list PasskeyListResponse
+	json.NewDecoder(w.Body).Decode(&list)
+	if len(list.Data) != 1 || list.Data[0].Name != "Office laptop" {
+		t.Fatalf("List() = %+v, want the registered passkey", list.Data)
+	}
+
+	w = httptest.NewRecorder()
+	h.BeginLogin(w, httptest.NewRequest("POST", "/passkeys/login/begin", nil))
+	assertion, err := authenticator.Login(publicKeyOptions(t, w))
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	body, _ = json.Marshal(map[string]interface{}{"credential": json.RawMessage(assertion)})
+	w = httptest.NewRecorder()
+	h.FinishLogin(w, httptest.NewRequest("POST", "/passkeys/login/finish", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("FinishLogin status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var login LoginResponse
+	json.NewDecoder(w.Body).Decode(&login)
+	if login.AccessToken == "" || login.User.ID != managerID {
+		t.Errorf("FinishLogin response = %+v", login)
+	}
+}
+
+func TestPasskeyHandler_Errors(t *testing.T) {
+	h, tenantID, managerID, waiterID := setupPasskeyHandler(t, "erp.example.com")
+	manager := authedContext(managerID, tenantID, domain.RoleManager)
+
+	tests := []struct {
+		name       string
+		handle     http.HandlerFunc
+		req        *http.Request
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			"waiter registers", h.BeginRegistration,
+			httptest.NewRequest("POST", "/passkeys/register/begin", nil).WithContext(authedContext(waiterID, tenantID, domain.RoleWaiter)),
+			http.StatusForbidden, "passkey_not_allowed",
+		},
+		{
+			"finish registration without a challenge", h.FinishRegistration,
+			httptest.NewRequest("POST", "/passkeys/register/finish", strings.NewReader(`{"name":"Laptop","credential":{}}`)).WithContext(manager),
+			http.StatusUnauthorized, "passkey_challenge_invalid",
+		},
+		{
+			"rename with invalid ID", h.Rename,
+			withChiURLParam(httptest.NewRequest("PATCH", "/passkeys/x", strings.NewReader(`{"name":"Laptop"}`)).WithContext(manager), "id", "x"),
+			http.StatusBadRequest, "invalid_id",
+		},
+		{
+			"remove unknown passkey", h.Remove,
+			withChiURLParam(httptest.NewRequest("DELETE", "/passkeys/x", nil).WithContext(manager), "id", uuid.NewString()),
+			http.StatusNotFound, "passkey_not_found",
+		},
+		{
+			"login with invalid body", h.FinishLogin,
+			httptest.NewRequest("POST", "/passkeys/login/finish", strings.NewReader("{")),
+			http.StatusBadRequest, "invalid_request",
+		},
+		{
+			"MFA without token", h.BeginMFA,
+			httptest.NewRequest("POST", "/mfa/passkey/begin", strings.NewReader(`{}`)),
+			http.StatusBadRequest, "invalid_request",
+		},
+		{
+			"MFA with unknown token", h.BeginMFA,
+			httptest.NewRequest("POST", "/mfa/passkey/begin", strings.NewReader(`{"mfa_token":"nope"}`)),
+			http.StatusUnauthorized, "mfa_challenge_invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handle(w, tt.req)
+			assertErrorCode(t, w, tt.wantStatus, tt.wantCode)
+		})
+	}
+}
+
+func TestPasskeyHandler_Unavailable(t *testing.T) {
+	h, _, _, _ := setupPasskeyHandler(t, "")
+
+	w := httptest.NewRecorder()
+	h.BeginLogin(w, httptest.NewRequest("POST", "/passkeys/login/begin", nil))
+
+	assertErrorCode(t, w, http.StatusServiceUnavailable, "passkeys_unavailable")
+}
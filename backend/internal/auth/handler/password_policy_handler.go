@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// PasswordPolicyHandler handles the tenant password policy endpoints.
+type PasswordPolicyHandler struct {
+	policies *service.PasswordPolicyService
+}
+
+// NewPasswordPolicyHandler creates a new PasswordPolicyHandler.
+func NewPasswordPolicyHandler(policies *service.PasswordPolicyService) *PasswordPolicyHandler {
+	return &PasswordPolicyHandler{policies: policies}
+}
+
+// GetPolicy handles GET /password-policy.
+//
+// @Summary      Get the tenant's password policy
+// @Description  Admin+ views the rules for members' passwords. Tenants that haven't set a policy get the default: at least 8 characters with an uppercase letter, a lowercase letter and a digit, no expiry and no reuse check.
+// @Tags         auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  PasswordPolicyResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Router       /auth/password-policy [get]
+func (h *PasswordPolicyHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	policy, err := h.policies.GetPolicy(r.Context(), tenantID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToPasswordPolicyResponse(policy))
+}
+
+// PutPolicy handles PUT /password-policy.
+//
+// @Summary      Set the tenant's password policy
+// @Description  Admin+ replaces the rules for members' passwords. min_length is 8 to 128, max_age_days 0 (never expire) to 365 and history_count 0 (reuse allowed) to 24, counting the current password. Members in several tenants follow the strictest combination of their tenants' policies. New rules apply when a password is next set; a password older than max_age_days must be reset at the member's next login. Breached passwords are always refused.
+// @Tags         auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      PasswordPolicyRequest  true  "Password rules"
+// @Success      200      {object}  PasswordPolicyResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, password_policy_invalid"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role, impersonation_forbidden"
+// @Router       /auth/password-policy [put]
+func (h *PasswordPolicyHandler) PutPolicy(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	var req PasswordPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	policy, err := h.policies.SavePolicy(r.Context(), service.SavePasswordPolicyRequest{
+		TenantID:         tenantID,
+		UpdatedBy:        userID,
+		MinLength:        req.MinLength,
+		RequireUppercase: req.RequireUppercase,
+		RequireLowercase: req.RequireLowercase,
+		RequireDigit:     req.RequireDigit,
+		RequireSymbol:    req.RequireSymbol,
+		MaxAgeDays:       req.MaxAgeDays,
+		HistoryCount:     req.HistoryCount,
+		IPAddress:        GetClientIP(r),
+		UserAgent:        r.UserAgent(),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrPasswordPolicyInvalid) {
+			writeError(w, http.StatusBadRequest, "password_policy_invalid", "min_length must be 8 to 128, max_age_days 0 to 365 and history_count 0 to 24")
+			return
+		}
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToPasswordPolicyResponse(policy))
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+func setupPasswordPolicyHandler(t *testing.T) *PasswordPolicyHandler {
+	t.Helper()
+
+	return NewPasswordPolicyHandler(service.NewPasswordPolicyService(service.PasswordPolicyServiceConfig{
+		Policies:  mock.NewMockPasswordPolicyRepository(),
+		History:   mock.NewMockPasswordHistoryRepository(),
+		RoleRepo:  mock.NewMockUserTenantRoleRepository(),
+		EventRepo: mock.NewMockAuthEventRepository(),
+	}))
+}
+
+func TestPasswordPolicyHandler_GetAndPut(t *testing.T) {
+	h := setupPasswordPolicyHandler(t)
+	ctx := authedContext(uuid.New(), uuid.New(), domain.RoleAdmin)
+
+	req := httptest.NewRequest("GET", "/password-policy", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	h.GetPolicy(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp PasswordPolicyResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.MinLength != domain.MinPasswordLength || !resp.RequireUppercase || resp.UpdatedAt != nil {
+		t.Errorf("default policy response = %+v", resp)
+	}
+
+	body, _ := json.Marshal(PasswordPolicyRequest{MinLength: 14, RequireSymbol: true, MaxAgeDays: 90, HistoryCount: 5})
+	req = httptest.NewRequest("PUT", "/password-policy", bytes.NewReader(body)).WithContext(ctx)
+	w = httptest.NewRecorder()
+	h.PutPolicy(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/password-policy", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	h.GetPolicy(w, req)
+	resp = PasswordPolicyResponse{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.MinLength != 14 || !resp.RequireSymbol || resp.RequireUppercase || resp.MaxAgeDays != 90 || resp.HistoryCount != 5 {
+		t.Errorf("saved policy response = %+v", resp)
+	}
+}
+
+func TestPasswordPolicyHandler_PutPolicy_Invalid(t *testing.T) {
+	h := setupPasswordPolicyHandler(t)
+	ctx := authedContext(uuid.New(), uuid.New(), domain.RoleAdmin)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+	}{
+		{"invalid body", "{", "invalid_request"},
+		{"too short", `{"min_length":6}`, "password_policy_invalid"},
+		{"history too long", `{"min_length":8,"history_count":30}`, "password_policy_invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/password-policy", bytes.NewReader([]byte(tt.body))).WithContext(ctx)
+			w := httptest.NewRecorder()
+			h.PutPolicy(w, req)
+			assertErrorCode(t, w, http.StatusBadRequest, tt.wantCode)
+		})
+	}
+}
+
+func TestWritePasswordWeak(t *testing.T) {
+	w := httptest.NewRecorder()
+	writePasswordWeak(w, &domain.PasswordRuleError{Rule: domain.PasswordRuleReused, Limit: 5})
+
+	var resp ErrorResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if w.Code != http.StatusBadRequest || resp.Error.Code != "password_weak" || resp.Error.Rule != "reused" {
+		t.Errorf("response = %d %+v", w.Code, resp.Error)
+	}
+	if resp.Error.Message != "Password must differ from the last 5 passwords" {
+		t.Errorf("message = %q", resp.Error.Message)
+	}
+
+	w = httptest.NewRecorder()
+	writePasswordWeak(w, domain.ErrPasswordWeak)
+	resp = ErrorResponse{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Error.Code != "password_weak" || resp.Error.Rule != "" {
+		t.Errorf("response without a rule = %+v", resp.Error)
+	}
+}
@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// TerminalTokenHeader carries a registered POS terminal's device token.
+const TerminalTokenHeader = "X-Terminal-Token"
+
+// PINHandler handles staff PIN login and POS terminal endpoints.
+type PINHandler struct {
+	pinService *service.PINService
+}
+
+// NewPINHandler creates a new PINHandler.
+func NewPINHandler(pinService *service.PINService) *PINHandler {
+	return &PINHandler{pinService: pinService}
+}
+
+// Login handles POST /pin-login.
+//
+// @Summary      Log in with a staff PIN
+// @Description  Fast user switching on a shared POS terminal. The terminal authenticates with its device token; the staff member picks themselves from /auth/terminal/staff and enters their PIN. Returns a short-lived access token scoped to the terminal's tenant, with no refresh token. Only cashier and lower roles can use a PIN.
+// @Tags         pin
+// @Accept       json
+// @Produce      json
+// @Param        X-Terminal-Token  header    string           true  "Terminal device token"
+// @Param        request           body      PINLoginRequest  true  "Staff member and PIN"
+// @Success      200               {object}  PINLoginResponse
+// @Failure      400               {object}  ErrorResponse "invalid_request"
+// @Failure      401               {object}  ErrorResponse "terminal_invalid, invalid_pin, account_disabled, tenant_inactive"
+// @Failure      403               {object}  ErrorResponse "pin_not_allowed"
+// @Failure      423               {object}  ErrorResponse "pin_locked"
+// @Failure      429               {object}  ErrorResponse "rate_limit_exceeded"
+// @Router       /auth/pin-login [post]
+func (h *PINHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req PINLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.UserID == uuid.Nil || req.PIN == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "User ID and PIN are required")
+		return
+	}
+
+	resp, err := h.pinService.Login(r.Context(), service.PINLoginRequest{
+		TerminalToken: r.Header.Get(TerminalTokenHeader),
+		UserID:        req.UserID,
+		PIN:           req.PIN,
+		IPAddress:     GetClientIP(r),
+		UserAgent:     r.UserAgent(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTerminalInvalid):
+			writeError(w, http.StatusUnauthorized, "terminal_invalid", "Terminal is not registered or has been revoked")
+			return
+		case errors.Is(err, domain.ErrPINInvalid):
+			writeError(w, http.StatusUnauthorized, "invalid_pin", "Invalid PIN")
+			return
+		case errors.Is(err, domain.ErrPINNotAllowed):
+			writeError(w, http.StatusForbidden, "pin_not_allowed", "PIN login is not allowed for your role. Log in with your password.")
+			return
+		case errors.Is(err, domain.ErrPINLocked):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusLocked)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error: ErrorDetail{
+					Code:        "pin_locked",
+					Message:     "PIN locked after 5 failed attempts. Try again later or log in with your password.",
+					LockedUntil: resp.LockedUntil,
+				},
+			})
+			return
+		case errors.Is(err, domain.ErrRateLimitExceeded):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error: ErrorDetail{
+					Code:       "rate_limit_exceeded",
+					Message:    "Too many PIN attempts on this terminal. Please try again later.",
+					RetryAfter: 60,
+				},
+			})
+			return
+		case errors.Is(err, domain.ErrAccountDisabled):
+			writeError(w, http.StatusUnauthorized, "account_disabled", "Account is disabled")
+			return
+		case errors.Is(err, domain.ErrTenantInactive):
+			writeError(w, http.StatusUnauthorized, "tenant_inactive", "Tenant is inactive")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, ToPINLoginResponse(resp))
+}
+
+// Staff handles GET /terminal/staff.
+//
+// @Summary      List staff for PIN login
+// @Description  List the staff a terminal can offer for PIN login: active members of its tenant, at cashier level or below, who have set a PIN.
+// @Tags         pin
+// @Produce      json
+// @Param        X-Terminal-Token  header    string  true  "Terminal device token"
+// @Success      200               {object}  TerminalStaffResponse
+// @Failure      401               {object}  ErrorResponse "terminal_invalid"
+// @Router       /auth/terminal/staff [get]
+func (h *PINHandler) Staff(w http.ResponseWriter, r *http.Request) {
+	terminal, err := h.pinService.AuthenticateTerminal(r.Context(), r.Header.Get(TerminalTokenHeader))
+	if err != nil {
+		if errors.Is(err, domain.ErrTerminalInvalid) {
+			writeError(w, http.StatusUnauthorized, "terminal_invalid", "Terminal is not registered or has been revoked")
+			return
+		}
+		writeInternalError(w, r, err)
+		return
+	}
+
+	users, err := h.pinService.ListStaff(r.Context(), terminal)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToTerminalStaffResponse(users, terminal.TenantID))
+}
+
+// SetPIN handles PUT /pin.
+//
+// @Summary      Set my PIN
+// @Description  Set or replace the authenticated user's 4-6 digit staff PIN for the current tenant, confirmed with their password. Repeated digits and straight runs are rejected. Cashier and lower roles log in with their PIN; manager and higher roles can only use it to approve actions on a terminal.
+// @Tags         pin
+// @Security     BearerAuth
+// @Accept       json
+// @Param        request  body  SetPINRequest  true  "Current password and new PIN"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_request, current_password_incorrect, pin_invalid_format, pin_too_simple"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "forbidden"
+// @Router       /auth/pin [put]
+func (h *PINHandler) SetPIN(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	var req SetPINRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.CurrentPassword == "" || req.PIN == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Current password and PIN are required")
+		return
+	}
+
+	err := h.pinService.SetPIN(r.Context(), service.SetPINRequest{
+		UserID:          userID,
+		TenantID:        tenantID,
+		CurrentPassword: req.CurrentPassword,
+		PIN:             req.PIN,
+		IPAddress:       GetClientIP(r),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrPasswordIncorrect):
+			writeError(w, http.StatusBadRequest, "current_password_incorrect", "Current password is incorrect")
+			return
+		case errors.Is(err, domain.ErrPINFormat):
+			writeError(w, http.StatusBadRequest, "pin_invalid_format", "PIN must be 4 to 6 digits")
+			return
+		case errors.Is(err, domain.ErrPINTooSimple):
+			writeError(w, http.StatusBadRequest, "pin_too_simple", "PIN is too easy to guess")
+			return
+		case errors.Is(err, domain.ErrUserNotInTenant):
+			writeError(w, http.StatusForbidden, "forbidden", "User does not belong to this tenant")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemovePIN handles DELETE /pin.
+//
+// @Summary      Remove my PIN
+// @Description  Remove the authenticated user's staff PIN for the current tenant, turning off PIN login for them.
+// @Tags         pin
+// @Security     BearerAuth
+// @Success      204  "No Content"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Router       /auth/pin [delete]
+func (h *PINHandler) RemovePIN(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	if err := h.pinService.RemovePIN(r.Context(), userID, tenantID, GetClientIP(r)); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegisterTerminal handles POST /terminals.
+//
+// @Summary      Register a POS terminal
+// @Description  Register a shared device for staff PIN login in the current tenant. The response carries the terminal's device token, shown only this once; configure it on the device as the X-Terminal-Token header. Requires Manager+.
+// @Tags         pin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      RegisterTerminalRequest  true  "Terminal name"
+// @Success      201      {object}  RegisterTerminalResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "forbidden"
+// @Router       /auth/terminals [post]
+func (h *PINHandler) RegisterTerminal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	var req RegisterTerminalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Name is required and must be at most 100 characters")
+		return
+	}
+
+	terminal, token, err := h.pinService.RegisterTerminal(r.Context(), tenantID, userID, name, GetClientIP(r))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, RegisterTerminalResponse{
+		TerminalResponse: ToTerminalResponse(terminal),
+		TerminalToken:    token,
+	})
+}
+
+// ListTerminals handles GET /terminals.
+//
+// @Summary      List POS terminals
+// @Description  List the current tenant's registered terminals. Requires Manager+.
+// @Tags         pin
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  TerminalListResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "forbidden"
+// @Router       /auth/terminals [get]
+func (h *PINHandler) ListTerminals(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	terminals, err := h.pinService.ListTerminals(r.Context(), tenantID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToTerminalListResponse(terminals))
+}
+
+// RevokeTerminal handles DELETE /terminals/{id}.
+//
+// @Summary      Revoke a POS terminal
+// @Description  Revoke one of the current tenant's terminals so its device token stops working. Access tokens already issued on it remain valid until they expire. Requires Manager+.
+// @Tags         pin
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Terminal ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "forbidden"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /auth/terminals/{id} [delete]
+func (h *PINHandler) RevokeTerminal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	terminalID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid terminal ID format")
+		return
+	}
+
+	if err := h.pinService.RevokeTerminal(r.Context(), tenantID, terminalID, userID, GetClientIP(r)); err != nil {
+		if errors.Is(err, domain.ErrTerminalNotFound) {
+			writeError(w, http.StatusNotFound, "not_found", "Terminal not found")
+			return
+		}
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// setupPINHandler builds a PINHandler for one active tenant with a
+// registered terminal, returning the terminal's device token.
+func setupPINHandler(t *testing.T) (*PINHandler, *mock.MockUserRepository, uuid.UUID, string) {
+	t.Helper()
+
+	_, _, tokenSvc, userRepo, tenantRepo, _, _ := setupWiredAuthHandler(t)
+	pinSvc := service.NewPINService(service.PINServiceConfig{
+		PINRepo:      mock.NewMockStaffPINRepository(),
+		TerminalRepo: mock.NewMockTerminalRepository(),
+		UserRepo:     userRepo,
+		TenantRepo:   tenantRepo,
+		EventRepo:    mock.NewMockAuthEventRepository(),
+		TokenService: tokenSvc,
+	})
+
+	tenantID := uuid.New()
+	tenantRepo.AddTenant(&domain.Tenant{ID: tenantID, Name: "Acme", Slug: "acme", IsActive: true})
+
+	_, token, err := pinSvc.RegisterTerminal(context.Background(), tenantID, uuid.New(), "Front counter", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RegisterTerminal failed: %v", err)
+	}
+
+	return NewPINHandler(pinSvc), userRepo, tenantID, token
+}
+
+// addPINUser creates an active user with the given role in tenantID and sets
+// their PIN through the handler.
+func addPINUser(t *testing.T, h *PINHandler, userRepo *mock.MockUserRepository, tenantID uuid.UUID, role domain.Role, pin string) uuid.UUID {
+	t.Helper()
+
+	passwordHash, _ := service.NewPasswordService().Hash("Password123!")
+	userID := uuid.New()
+	userRepo.AddUser(&domain.User{
+		ID: userID, Email: "cashier@example.com", PasswordHash: passwordHash, FirstName: "Ana", LastName: "Diaz", IsActive: true,
+		TenantRoles: []domain.UserTenantRole{{ID: uuid.New(), UserID: userID, TenantID: tenantID, Role: role}},
+	})
+
+	body, _ := json.Marshal(SetPINRequest{CurrentPassword: "Password123!", PIN: pin})
+	w := httptest.NewRecorder()
+	h.SetPIN(w, httptest.NewRequest("PUT", "/pin", bytes.NewReader(body)).WithContext(authedContext(userID, tenantID, role)))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("SetPIN status = %d, want %d, body=%s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+	return userID
+}
+
+func pinLoginRequest(terminalToken string, userID uuid.UUID, pin string) *http.Request {
+	body, _ := json.Marshal(PINLoginRequest{UserID: userID, PIN: pin})
+	req := httptest.NewRequest("POST", "/pin-login", bytes.NewReader(body))
+	if terminalToken != "" {
+		req.Header.Set(TerminalTokenHeader, terminalToken)
+	}
+	return req
+}
+
+func TestPINHandler_Login_Success(t *testing.T) {
+	h, userRepo, tenantID, terminalToken := setupPINHandler(t)
+	userID := addPINUser(t, h, userRepo, tenantID, domain.RoleCashier, "2580")
+
+	w := httptest.NewRecorder()
+	h.Login(w, pinLoginRequest(terminalToken, userID, "2580"))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp PINLoginResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.AccessToken == "" || resp.TokenType != "Bearer" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if resp.User.ID != userID || resp.User.Role != string(domain.RoleCashier) {
+		t.Errorf("User = %+v", resp.User)
+	}
+	if strings.Contains(w.Body.String(), "refresh_token") {
+		t.Error("PIN login must not issue a refresh token")
+	}
+}
+
+func TestPINHandler_Login_Errors(t *testing.T) {
+	h, userRepo, tenantID, terminalToken := setupPINHandler(t)
+	cashierID := addPINUser(t, h, userRepo, tenantID, domain.RoleCashier, "2580")
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing terminal token", pinLoginRequest("", cashierID, "2580"), http.StatusUnauthorized, "terminal_invalid"},
+		{"unknown terminal token", pinLoginRequest("bogus", cashierID, "2580"), http.StatusUnauthorized, "terminal_invalid"},
+		{"wrong PIN", pinLoginRequest(terminalToken, cashierID, "9999"), http.StatusUnauthorized, "invalid_pin"},
+		{"unknown user", pinLoginRequest(terminalToken, uuid.New(), "2580"), http.StatusUnauthorized, "invalid_pin"},
+		{"missing PIN", pinLoginRequest(terminalToken, cashierID, ""), http.StatusBadRequest, "invalid_request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.Login(w, tt.req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var resp ErrorResponse
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", resp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestPINHandler_Login_Locked(t *testing.T) {
+	h, userRepo, tenantID, terminalToken := setupPINHandler(t)
+	userID := addPINUser(t, h, userRepo, tenantID, domain.RoleCashier, "2580")
+
+	for i := 0; i < 5; i++ {
+		h.Login(httptest.NewRecorder(), pinLoginRequest(terminalToken, userID, "9999"))
+	}
+
+	w := httptest.NewRecorder()
+	h.Login(w, pinLoginRequest(terminalToken, userID, "2580"))
+
+	if w.Code != http.StatusLocked {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusLocked)
+	}
+	var resp ErrorResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Error.Code != "pin_locked" || resp.Error.LockedUntil == nil {
+		t.Errorf("unexpected response: %+v", resp.Error)
+	}
+}
+
+func TestPINHandler_SetPIN_Errors(t *testing.T) {
+	h, userRepo, tenantID, _ := setupPINHandler(t)
+
+	passwordHash, _ := service.NewPasswordService().Hash("Password123!")
+	cashierID, managerID := uuid.New(), uuid.New()
+	userRepo.AddUser(&domain.User{
+		ID: cashierID, Email: "cashier@example.com", PasswordHash: passwordHash, IsActive: true,
+		TenantRoles: []domain.UserTenantRole{{UserID: cashierID, TenantID: tenantID, Role: domain.RoleCashier}},
+	})
+	userRepo.AddUser(&domain.User{
+		ID: managerID, Email: "manager@example.com", PasswordHash: passwordHash, IsActive: true,
+		TenantRoles: []domain.UserTenantRole{{UserID: managerID, TenantID: tenantID, Role: domain.RoleManager}},
+	})
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		role       domain.Role
+		body       SetPINRequest
+		wantStatus int
+		wantCode   string
+	}{
+		{"wrong password", cashierID, domain.RoleCashier, SetPINRequest{CurrentPassword: "wrong", PIN: "2580"}, http.StatusBadRequest, "current_password_incorrect"},
+		{"bad format", cashierID, domain.RoleCashier, SetPINRequest{CurrentPassword: "Password123!", PIN: "12a4"}, http.StatusBadRequest, "pin_invalid_format"},
+		{"too simple", cashierID, domain.RoleCashier, SetPINRequest{CurrentPassword: "Password123!", PIN: "1234"}, http.StatusBadRequest, "pin_too_simple"},
+		{"missing fields", cashierID, domain.RoleCashier, SetPINRequest{PIN: "2580"}, http.StatusBadRequest, "invalid_request"},
+		{"manager sets an approval PIN", managerID, domain.RoleManager, SetPINRequest{CurrentPassword: "Password123!", PIN: "2580"}, http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			w := httptest.NewRecorder()
+			h.SetPIN(w, httptest.NewRequest("PUT", "/pin", bytes.NewReader(body)).WithContext(authedContext(tt.userID, tenantID, tt.role)))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var resp ErrorResponse
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", resp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestPINHandler_Staff(t *testing.T) {
+	h, userRepo, tenantID, terminalToken := setupPINHandler(t)
+	userID := addPINUser(t, h, userRepo, tenantID, domain.RoleCashier, "2580")
+
+	req := httptest.NewRequest("GET", "/terminal/staff", nil)
+	req.Header.Set(TerminalTokenHeader, terminalToken)
+	w := httptest.NewRecorder()
+	h.Staff(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp TerminalStaffResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Data) != 1 || resp.Data[0].ID != userID || resp.Data[0].FirstName != "Ana" {
+		t.Errorf("unexpected staff: %+v", resp.Data)
+	}
+	if strings.Contains(w.Body.String(), "@example.com") {
+		t.Error("staff roster must not expose email addresses")
+	}
+}
+
+func TestPINHandler_Terminals(t *testing.T) {
+	h, _, tenantID, _ := setupPINHandler(t)
+	ctx := authedContext(uuid.New(), tenantID, domain.RoleManager)
+
+	w := httptest.NewRecorder()
+	h.RegisterTerminal(w, httptest.NewRequest("POST", "/terminals", strings.NewReader(`{"name":"Patio"}`)).WithContext(ctx))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Register status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var created RegisterTerminalResponse
+	json.NewDecoder(w.Body).Decode(&created)
+	if created.TerminalToken == "" || created.Name != "Patio" {
+		t.Fatalf("unexpected response: %+v", created)
+	}
+
+	w = httptest.NewRecorder()
+	h.ListTerminals(w, httptest.NewRequest("GET", "/terminals", nil).WithContext(ctx))
+	var list TerminalListResponse
+	json.NewDecoder(w.Body).Decode(&list)
+	if len(list.Data) != 2 {
+		t.Fatalf("Expected 2 terminals, got %d", len(list.Data))
+	}
+	if strings.Contains(w.Body.String(), "token") {
+		t.Error("terminal list must not expose device tokens")
+	}
+
+	revoke := func(id string) int {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		req := httptest.NewRequest("DELETE", "/terminals/"+id, nil).
+			WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+		h.RevokeTerminal(w, req)
+		return w.Code
+	}
+
+	if code := revoke(created.ID.String()); code != http.StatusNoContent {
+		t.Errorf("Revoke status = %d, want %d", code, http.StatusNoContent)
+	}
+	if code := revoke(created.ID.String()); code != http.StatusNotFound {
+		t.Errorf("second Revoke status = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := revoke("not-a-uuid"); code != http.StatusBadRequest {
+		t.Errorf("Revoke with bad ID status = %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestPINHandler_Terminals_EmptyName(t *testing.T) {
+	h, _, tenantID, _ := setupPINHandler(t)
+	ctx := authedContext(uuid.New(), tenantID, domain.RoleManager)
+
+	w := httptest.NewRecorder()
+	h.RegisterTerminal(w, httptest.NewRequest("POST", "/terminals", strings.NewReader(`{"name":"  "}`)).WithContext(ctx))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// RoleHandler handles tenant custom role endpoints.
+type RoleHandler struct {
+	roleService *service.RoleService
+}
+
+// NewRoleHandler creates a new RoleHandler.
+func NewRoleHandler(roleService *service.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+// ListPermissions handles GET /roles/permissions.
+//
+// @Summary      List permissions
+// @Description  List every permission the modules declare, with the built-in roles granted it by default. Custom roles are built from these.
+// @Tags         roles
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  PermissionListResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Router       /roles/permissions [get]
+func (h *RoleHandler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, ToPermissionListResponse(domain.AllPermissions()))
+}
+
+// List handles GET /roles.
+//
+// @Summary      List custom roles
+// @Description  Admin+ lists the current tenant's custom roles, ordered by name.
+// @Tags         roles
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  CustomRoleListResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Router       /roles [get]
+func (h *RoleHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	roles, err := h.roleService.ListCustomRoles(r.Context(), tenantID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToCustomRoleListResponse(roles))
+}
+
+// Get handles GET /roles/{id}.
+//
+// @Summary      Get a custom role
+// @Description  Admin+ gets one of the current tenant's custom roles.
+// @Tags         roles
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Custom role ID"
+// @Success      200  {object}  CustomRoleResponse
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /roles/{id} [get]
+func (h *RoleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid custom role ID format")
+		return
+	}
+
+	role, err := h.roleService.GetCustomRole(r.Context(), tenantID, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrCustomRoleNotFound) {
+			writeError(w, http.StatusNotFound, "not_found", "Custom role not found")
+			return
+		}
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToCustomRoleResponse(role))
+}
+
+// Create handles POST /roles.
+//
+// @Summary      Create a custom role
+// @Description  Admin+ creates a custom role in the current tenant. It ranks as its base role (which must be lower than your own and can't be changed later) and grants exactly the listed permissions, each of which you must hold yourself.
+// @Tags         roles
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CreateCustomRoleRequest  true  "Custom role"
+// @Success      201      {object}  CustomRoleResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, invalid_name, invalid_role, unknown_permission"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role, permission_not_held"
+// @Failure      409      {object}  ErrorResponse "role_exists"
+// @Router       /roles [post]
+func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	callerRole, ok := GetRole(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	callerID, _ := GetUserID(r.Context())
+	tenantID, _ := GetTenantID(r.Context())
+	callerPerms, _ := GetPermissions(r.Context())
+
+	var req CreateCustomRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if !req.BaseRole.IsValid() {
+		writeError(w, http.StatusBadRequest, "invalid_role", "Invalid base role specified")
+		return
+	}
+
+	role, err := h.roleService.CreateCustomRole(r.Context(), service.CreateCustomRoleRequest{
+		TenantID:    tenantID,
+		Name:        req.Name,
+		BaseRole:    req.BaseRole,
+		Permissions: req.Permissions,
+		CreatedBy:   callerID,
+		IPAddress:   GetClientIP(r),
+	}, callerRole, callerPerms)
+	if err != nil {
+		writeCustomRoleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, ToCustomRoleResponse(role))
+}
+
+// Update handles PATCH /roles/{id}.
+//
+// @Summary      Update a custom role
+// @Description  Admin+ renames a custom role or replaces its permissions. Everyone holding the role has their access tokens revoked, so they pick up the new permissions on refresh.
+// @Tags         roles
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                   true  "Custom role ID"
+// @Param        request  body      UpdateCustomRoleRequest  true  "Fields to update"
+// @Success      200      {object}  CustomRoleResponse
+// @Failure      400      {object}  ErrorResponse "invalid_id, invalid_request, invalid_name, unknown_permission"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role, permission_not_held"
+// @Failure      404      {object}  ErrorResponse "not_found"
+// @Failure      409      {object}  ErrorResponse "role_exists"
+// @Router       /roles/{id} [patch]
+func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	callerRole, ok := GetRole(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	callerID, _ := GetUserID(r.Context())
+	tenantID, _ := GetTenantID(r.Context())
+	callerPerms, _ := GetPermissions(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid custom role ID format")
+		return
+	}
+
+	var req UpdateCustomRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	role, err := h.roleService.UpdateCustomRole(r.Context(), service.UpdateCustomRoleRequest{
+		TenantID:    tenantID,
+		ID:          id,
+		Name:        req.Name,
+		Permissions: req.Permissions,
+		UpdatedBy:   callerID,
+		IPAddress:   GetClientIP(r),
+	}, callerRole, callerPerms)
+	if err != nil {
+		writeCustomRoleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToCustomRoleResponse(role))
+}
+
+// Delete handles DELETE /roles/{id}.
+//
+// @Summary      Delete a custom role
+// @Description  Admin+ deletes a custom role. A role still assigned to someone can't be deleted; give them another role first.
+// @Tags         roles
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Custom role ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Failure      409  {object}  ErrorResponse "role_in_use"
+// @Router       /roles/{id} [delete]
+func (h *RoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	callerRole, ok := GetRole(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	callerID, _ := GetUserID(r.Context())
+	tenantID, _ := GetTenantID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid custom role ID format")
+		return
+	}
+
+	err = h.roleService.DeleteCustomRole(r.Context(), service.DeleteCustomRoleRequest{
+		TenantID:  tenantID,
+		ID:        id,
+		DeletedBy: callerID,
+		IPAddress: GetClientIP(r),
+	}, callerRole)
+	if err != nil {
+		writeCustomRoleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeCustomRoleError maps custom role create/update/delete errors to responses.
+func writeCustomRoleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCustomRoleNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "Custom role not found")
+	case errors.Is(err, domain.ErrCustomRoleExists):
+		writeError(w, http.StatusConflict, "role_exists", "A custom role with this name already exists")
+	case errors.Is(err, domain.ErrCustomRoleInUse):
+		writeError(w, http.StatusConflict, "role_in_use", "Custom role is still assigned to users")
+	case errors.Is(err, domain.ErrCustomRoleName):
+		writeError(w, http.StatusBadRequest, "invalid_name", "Name must be 1-50 characters and not a built-in role name")
+	case errors.Is(err, domain.ErrUnknownPermission):
+		writeError(w, http.StatusBadRequest, "unknown_permission", err.Error())
+	case errors.Is(err, domain.ErrPermissionNotHeld):
+		writeError(w, http.StatusForbidden, "permission_not_held", err.Error())
+	case errors.Is(err, domain.ErrCannotAssignRole):
+		writeError(w, http.StatusForbidden, "insufficient_role", "Cannot use a base role equal or higher than your own")
+	case errors.Is(err, domain.ErrCannotManageRole):
+		writeError(w, http.StatusForbidden, "insufficient_role", "Cannot manage custom roles with this base role")
+	default:
+		writeInternalError(w, r, err)
+	}
+}
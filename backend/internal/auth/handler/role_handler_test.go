@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+func setupRoleHandler(t *testing.T) (*RoleHandler, *mock.MockCustomRoleRepository, *mock.MockUserTenantRoleRepository) {
+	t.Helper()
+
+	customRoles := mock.NewMockCustomRoleRepository()
+	roleRepo := mock.NewMockUserTenantRoleRepository()
+	roleSvc := service.NewRoleService(service.RoleServiceConfig{
+		CustomRoles: customRoles,
+		RoleRepo:    roleRepo,
+		EventRepo:   mock.NewMockAuthEventRepository(),
+	})
+
+	return NewRoleHandler(roleSvc), customRoles, roleRepo
+}
+
+// roleContext builds a request context as RequireAuth would populate it,
+// including the role's default permissions.
+func roleContext(tenantID uuid.UUID, role domain.Role) context.Context {
+	ctx := authedContext(uuid.New(), tenantID, role)
+	return context.WithValue(ctx, PermissionsContextKey, role.Permissions())
+}
+
+func roleRequest(method, target string, body interface{}, ctx context.Context) *http.Request {
+	b, _ := json.Marshal(body)
+	return httptest.NewRequest(method, target, bytes.NewReader(b)).WithContext(ctx)
+}
+
+func TestRoleHandler_Create(t *testing.T) {
+	h, customRoles, _ := setupRoleHandler(t)
+	tenantID := uuid.New()
+
+	w := httptest.NewRecorder()
+	h.Create(w, roleRequest("POST", "/roles", CreateCustomRoleRequest{
+		Name:        "Bartender",
+		BaseRole:    domain.RoleWaiter,
+		Permissions: []domain.Permission{domain.PermUsersManage},
+	}, roleContext(tenantID, domain.RoleAdmin)))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var resp CustomRoleResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Name != "Bartender" || resp.BaseRole != "waiter" || len(resp.Permissions) != 1 || resp.Permissions[0] != "users.manage" {
+		t.Errorf("response = %+v, want Bartender/waiter with users.manage", resp)
+	}
+	if _, err := customRoles.FindByID(context.Background(), tenantID, resp.ID); err != nil {
+		t.Errorf("custom role not stored: %v", err)
+	}
+}
+
+func TestRoleHandler_Create_Errors(t *testing.T) {
+	tenantID := uuid.New()
+	admin := roleContext(tenantID, domain.RoleAdmin)
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "unauthenticated",
+			req:        httptest.NewRequest("POST", "/roles", strings.NewReader(`{}`)),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "unauthorized",
+		},
+		{
+			name:       "invalid body",
+			req:        httptest.NewRequest("POST", "/roles", strings.NewReader("invalid json")).WithContext(admin),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+		{
+			name:       "invalid base role",
+			req:        roleRequest("POST", "/roles", CreateCustomRoleRequest{Name: "Host", BaseRole: "bogus"}, admin),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_role",
+		},
+		{
+			name:       "invalid name",
+			req:        roleRequest("POST", "/roles", CreateCustomRoleRequest{Name: "Waiter", BaseRole: domain.RoleWaiter}, admin),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_name",
+		},
+		{
+			name:       "base role not below caller",
+			req:        roleRequest("POST", "/roles", CreateCustomRoleRequest{Name: "Deputy", BaseRole: domain.RoleAdmin}, admin),
+			wantStatus: http.StatusForbidden,
+			wantCode:   "insufficient_role",
+		},
+		{
+			name:       "unknown permission",
+			req:        roleRequest("POST", "/roles", CreateCustomRoleRequest{Name: "Host", BaseRole: domain.RoleViewer, Permissions: []domain.Permission{"tables.seat"}}, admin),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "unknown_permission",
+		},
+		{
+			name:       "permission not held",
+			req:        roleRequest("POST", "/roles", CreateCustomRoleRequest{Name: "Host", BaseRole: domain.RoleViewer, Permissions: []domain.Permission{domain.PermUsersManage}}, authedContext(uuid.New(), tenantID, domain.RoleAdmin)),
+			wantStatus: http.StatusForbidden,
+			wantCode:   "permission_not_held",
+		},
+		{
+			name:       "name taken",
+			req:        roleRequest("POST", "/roles", CreateCustomRoleRequest{Name: "bartender", BaseRole: domain.RoleCashier}, admin),
+			wantStatus: http.StatusConflict,
+			wantCode:   "role_exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, customRoles, _ := setupRoleHandler(t)
+			customRoles.AddCustomRole(&domain.CustomRole{ID: uuid.New(), TenantID: tenantID, Name: "Bartender", BaseRole: domain.RoleWaiter})
+
+			w := httptest.NewRecorder()
+			h.Create(w, tt.req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var errResp ErrorResponse
+			json.NewDecoder(w.Body).Decode(&errResp)
+			if errResp.Error.Code != tt.wantCode {
+				t.Errorf("error code = %q, want %q", errResp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRoleHandler_Lifecycle(t *testing.T) {
+	h, customRoles, roleRepo := setupRoleHandler(t)
+	tenantID := uuid.New()
+	admin := roleContext(tenantID, domain.RoleAdmin)
+
+	role := &domain.CustomRole{ID: uuid.New(), TenantID: tenantID, Name: "Bartender", BaseRole: domain.RoleWaiter}
+	customRoles.AddCustomRole(role)
+	customRoles.AddCustomRole(&domain.CustomRole{ID: uuid.New(), TenantID: uuid.New(), Name: "Elsewhere", BaseRole: domain.RoleWaiter})
+
+	w := httptest.NewRecorder()
+	h.List(w, httptest.NewRequest("GET", "/roles", nil).WithContext(admin))
+	var list CustomRoleListResponse
+	json.NewDecoder(w.Body).Decode(&list)
+	if w.Code != http.StatusOK || len(list.Data) != 1 {
+		t.Fatalf("List = %d with %d roles, want 200 with 1", w.Code, len(list.Data))
+	}
+
+	w = httptest.NewRecorder()
+	h.Get(w, withChiURLParam(httptest.NewRequest("GET", "/roles/x", nil).WithContext(admin), "id", role.ID.String()))
+	if w.Code != http.StatusOK {
+		t.Errorf("Get status = %d, want %d", w.Code, http.StatusOK)
+	}
+	w = httptest.NewRecorder()
+	h.Get(w, withChiURLParam(httptest.NewRequest("GET", "/roles/x", nil).WithContext(admin), "id", uuid.New().String()))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Get unknown status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	w = httptest.NewRecorder()
+	h.Get(w, withChiURLParam(httptest.NewRequest("GET", "/roles/x", nil).WithContext(admin), "id", "not-a-uuid"))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Get invalid ID status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w = httptest.NewRecorder()
+	h.Update(w, withChiURLParam(roleRequest("PATCH", "/roles/x", UpdateCustomRoleRequest{
+		Permissions: []domain.Permission{domain.PermUsersManage},
+	}, admin), "id", role.ID.String()))
+	var updated CustomRoleResponse
+	json.NewDecoder(w.Body).Decode(&updated)
+	if w.Code != http.StatusOK || len(updated.Permissions) != 1 {
+		t.Fatalf("Update = %d %+v, want 200 with users.manage", w.Code, updated)
+	}
+
+	assignment := &domain.UserTenantRole{ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, Role: domain.RoleWaiter, CustomRoleID: &role.ID}
+	roleRepo.AddRole(assignment)
+
+	w = httptest.NewRecorder()
+	h.Delete(w, withChiURLParam(httptest.NewRequest("DELETE", "/roles/x", nil).WithContext(admin), "id", role.ID.String()))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Delete while assigned status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	roleRepo.Delete(context.Background(), assignment.ID)
+	w = httptest.NewRecorder()
+	h.Delete(w, withChiURLParam(httptest.NewRequest("DELETE", "/roles/x", nil).WithContext(admin), "id", role.ID.String()))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Delete status = %d, want %d, body=%s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+}
+
+func TestRoleHandler_ListPermissions(t *testing.T) {
+	h, _, _ := setupRoleHandler(t)
+
+	w := httptest.NewRecorder()
+	h.ListPermissions(w, httptest.NewRequest("GET", "/roles/permissions", nil).WithContext(roleContext(uuid.New(), domain.RoleWaiter)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp PermissionListResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	found := false
+	for _, p := range resp.Data {
+		if p.Permission == string(domain.PermUsersManage) {
+			found = len(p.Roles) > 0 && p.Description != ""
+		}
+	}
+	if !found {
+		t.Errorf("permissions = %+v, want users.manage with its roles and description", resp.Data)
+	}
+}
@@ -0,0 +1,423 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+	"github.com/solobueno/erp/pkg/scim"
+)
+
+// SCIMHandler handles SCIM provisioning: managing a tenant's SCIM tokens
+// under /auth, and the SCIM 2.0 protocol endpoints the tenant's HR system
+// or identity provider calls with one of them. The protocol endpoints are
+// mounted at /scim/v2, outside the /api/v1 base path, so they are not part
+// of the OpenAPI spec.
+type SCIMHandler struct {
+	scim *service.SCIMService
+}
+
+// NewSCIMHandler creates a new SCIMHandler.
+func NewSCIMHandler(scimService *service.SCIMService) *SCIMHandler {
+	return &SCIMHandler{scim: scimService}
+}
+
+// CreateToken handles POST /scim/tokens.
+//
+// @Summary      Create a SCIM token
+// @Description  Admin+ creates a bearer token for the tenant's HR system or identity provider to provision users over SCIM 2.0 at /scim/v2. The token is shown only this once. SCIM clients act with admin rights: they can create users, move them between the roles below admin and deprovision them, but never touch admins or owners.
+// @Tags         scim
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CreateSCIMTokenRequest  true  "Token name"
+// @Success      201      {object}  CreateSCIMTokenResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role"
+// @Router       /auth/scim/tokens [post]
+func (h *SCIMHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	var req CreateSCIMTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Name is required and must be at most 100 characters")
+		return
+	}
+
+	token, plain, err := h.scim.CreateToken(r.Context(), tenantID, userID, name, GetClientIP(r))
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, CreateSCIMTokenResponse{
+		SCIMTokenResponse: ToSCIMTokenResponse(token),
+		Token:             plain,
+	})
+}
+
+// ListTokens handles GET /scim/tokens.
+//
+// @Summary      List SCIM tokens
+// @Description  Admin+ lists the tenant's SCIM tokens that have not been revoked.
+// @Tags         scim
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  SCIMTokenListResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Router       /auth/scim/tokens [get]
+func (h *SCIMHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tokens, err := h.scim.ListTokens(r.Context(), tenantID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToSCIMTokenListResponse(tokens))
+}
+
+// RevokeToken handles DELETE /scim/tokens/{id}.
+//
+// @Summary      Revoke a SCIM token
+// @Description  Admin+ revokes one of the tenant's SCIM tokens so it stops working. Users it provisioned are kept.
+// @Tags         scim
+// @Security     BearerAuth
+// @Param        id   path  string  true  "SCIM token ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /auth/scim/tokens/{id} [delete]
+func (h *SCIMHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	tokenID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid token ID format")
+		return
+	}
+
+	if err := h.scim.RevokeToken(r.Context(), tenantID, tokenID, userID, GetClientIP(r)); err != nil {
+		if errors.Is(err, domain.ErrSCIMTokenNotFound) {
+			writeError(w, http.StatusNotFound, "not_found", "SCIM token not found")
+			return
+		}
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequireToken is middleware that authenticates SCIM requests by the
+// bearer token in the Authorization header.
+func (h *SCIMHandler) RequireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plain, err := extractBearerToken(r)
+		if err != nil {
+			writeSCIMError(w, r, scim.NewError(http.StatusUnauthorized, "", "Authorization header missing or invalid"))
+			return
+		}
+
+		token, err := h.scim.AuthenticateToken(r.Context(), plain)
+		if err != nil {
+			if errors.Is(err, domain.ErrSCIMTokenInvalid) {
+				writeSCIMError(w, r, scim.NewError(http.StatusUnauthorized, "", "Token is invalid or has been revoked"))
+				return
+			}
+			writeSCIMError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), SCIMTokenContextKey, token)
+		setAccessLogTenantID(ctx, token.TenantID.String())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ListUsers handles GET /Users.
+func (h *SCIMHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	req, err := parseSCIMListRequest(r)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	resp, err := h.scim.ListUsers(r.Context(), getSCIMToken(r.Context()), req)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, resp)
+}
+
+// GetUser handles GET /Users/{id}.
+func (h *SCIMHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.scim.GetUser(r.Context(), getSCIMToken(r.Context()), chi.URLParam(r, "id"))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, user)
+}
+
+// CreateUser handles POST /Users.
+func (h *SCIMHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req scim.User
+	if err := decodeSCIM(r, &req); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	user, err := h.scim.CreateUser(r.Context(), getSCIMToken(r.Context()), &req, GetClientIP(r))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusCreated, user)
+}
+
+// ReplaceUser handles PUT /Users/{id}.
+func (h *SCIMHandler) ReplaceUser(w http.ResponseWriter, r *http.Request) {
+	var req scim.User
+	if err := decodeSCIM(r, &req); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	user, err := h.scim.ReplaceUser(r.Context(), getSCIMToken(r.Context()), chi.URLParam(r, "id"), &req, GetClientIP(r))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, user)
+}
+
+// PatchUser handles PATCH /Users/{id}.
+func (h *SCIMHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	var req scim.PatchRequest
+	if err := decodeSCIM(r, &req); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	user, err := h.scim.PatchUser(r.Context(), getSCIMToken(r.Context()), chi.URLParam(r, "id"), req.Operations, GetClientIP(r))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, user)
+}
+
+// DeleteUser handles DELETE /Users/{id}. The user is deprovisioned from
+// the tenant; their account is kept.
+func (h *SCIMHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	if err := h.scim.DeleteUser(r.Context(), getSCIMToken(r.Context()), chi.URLParam(r, "id"), GetClientIP(r)); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListGroups handles GET /Groups.
+func (h *SCIMHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	req, err := parseSCIMListRequest(r)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	resp, err := h.scim.ListGroups(r.Context(), getSCIMToken(r.Context()), req)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, resp)
+}
+
+// GetGroup handles GET /Groups/{id}.
+func (h *SCIMHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	group, err := h.scim.GetGroup(r.Context(), getSCIMToken(r.Context()), chi.URLParam(r, "id"), excludesMembers(r))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, group)
+}
+
+// ReplaceGroup handles PUT /Groups/{id}.
+func (h *SCIMHandler) ReplaceGroup(w http.ResponseWriter, r *http.Request) {
+	var req scim.Group
+	if err := decodeSCIM(r, &req); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	group, err := h.scim.ReplaceGroup(r.Context(), getSCIMToken(r.Context()), chi.URLParam(r, "id"), &req, GetClientIP(r))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, group)
+}
+
+// PatchGroup handles PATCH /Groups/{id}.
+func (h *SCIMHandler) PatchGroup(w http.ResponseWriter, r *http.Request) {
+	var req scim.PatchRequest
+	if err := decodeSCIM(r, &req); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	group, err := h.scim.PatchGroup(r.Context(), getSCIMToken(r.Context()), chi.URLParam(r, "id"), req.Operations, GetClientIP(r))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, group)
+}
+
+// GroupsReadOnly handles POST /Groups and DELETE /Groups/{id}. Groups are
+// the tenant's roles, which are managed through /api/v1/roles.
+func (h *SCIMHandler) GroupsReadOnly(w http.ResponseWriter, r *http.Request) {
+	writeSCIMError(w, r, scim.NewError(http.StatusNotImplemented, "", "Groups are the organization's roles and are managed in the application"))
+}
+
+// ServiceProviderConfig handles GET /ServiceProviderConfig.
+func (h *SCIMHandler) ServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	writeSCIM(w, http.StatusOK, scim.NewServiceProviderConfig(service.MaxSCIMCount))
+}
+
+// ResourceTypes handles GET /ResourceTypes.
+func (h *SCIMHandler) ResourceTypes(w http.ResponseWriter, r *http.Request) {
+	types := scim.ResourceTypes()
+	resources := make([]interface{}, len(types))
+	for i := range types {
+		resources[i] = types[i]
+	}
+	writeSCIM(w, http.StatusOK, scim.NewListResponse(resources, 1, len(resources)))
+}
+
+// getSCIMToken returns the SCIM token set by RequireToken.
+func getSCIMToken(ctx context.Context) *domain.SCIMToken {
+	token, _ := ctx.Value(SCIMTokenContextKey).(*domain.SCIMToken)
+	return token
+}
+
+// parseSCIMListRequest reads the filter, paging and excludedAttributes
+// query parameters of a list request.
+func parseSCIMListRequest(r *http.Request) (service.SCIMListRequest, error) {
+	query := r.URL.Query()
+	req := service.SCIMListRequest{
+		Filter:         query.Get("filter"),
+		StartIndex:     1,
+		Count:          service.DefaultSCIMCount,
+		ExcludeMembers: excludesMembers(r),
+	}
+
+	if v := query.Get("startIndex"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return req, scim.BadRequest(scim.ErrorInvalidValue, "startIndex must be an integer")
+		}
+		req.StartIndex = n
+	}
+	if v := query.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return req, scim.BadRequest(scim.ErrorInvalidValue, "count must be an integer")
+		}
+		req.Count = n
+	}
+	return req, nil
+}
+
+// excludesMembers reports whether a request asks to leave out group
+// members, which identity providers do to avoid loading large groups.
+func excludesMembers(r *http.Request) bool {
+	for _, attr := range strings.Split(r.URL.Query().Get("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(attr), "members") {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeSCIM decodes a SCIM request body.
+func decodeSCIM(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return scim.BadRequest(scim.ErrorInvalidSyntax, "Invalid request body")
+	}
+	return nil
+}
+
+// writeSCIM writes a SCIM response.
+func writeSCIM(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", scim.ContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// writeSCIMError maps SCIM provisioning errors to SCIM error responses.
+func writeSCIMError(w http.ResponseWriter, r *http.Request, err error) {
+	var scimErr *scim.Error
+	switch {
+	case errors.As(err, &scimErr):
+		writeSCIM(w, scimErr.Status, scimErr)
+	case errors.Is(err, domain.ErrUserNotFound):
+		writeSCIM(w, http.StatusNotFound, scim.NewError(http.StatusNotFound, "", "User not found"))
+	case errors.Is(err, domain.ErrCustomRoleNotFound):
+		writeSCIM(w, http.StatusNotFound, scim.NewError(http.StatusNotFound, "", "Group not found"))
+	case errors.Is(err, domain.ErrCannotManageRole):
+		writeSCIM(w, http.StatusForbidden, scim.NewError(http.StatusForbidden, "", "Admins and owners can't be managed over SCIM"))
+	case errors.Is(err, domain.ErrCannotAssignRole):
+		writeSCIM(w, http.StatusForbidden, scim.NewError(http.StatusForbidden, "", "This role can't be assigned over SCIM"))
+	case errors.Is(err, domain.ErrEmailExists):
+		writeSCIM(w, http.StatusConflict, scim.NewError(http.StatusConflict, scim.ErrorUniqueness, "A user with this userName already exists"))
+	default:
+		logUnhandledError(r, err)
+		writeSCIM(w, http.StatusInternalServerError, scim.NewError(http.StatusInternalServerError, "", "An unexpected error occurred"))
+	}
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/internal/auth/service"
+	"github.com/solobueno/erp/pkg/scim"
+)
+
+// setupSCIMHandler returns a SCIMHandler for a tenant with a waiter in it.
+// Provisioning itself is covered by the service and e2e tests.
+func setupSCIMHandler(t *testing.T) (*SCIMHandler, uuid.UUID) {
+	t.Helper()
+
+	userRepo := mock.NewMockUserRepository()
+	roleRepo := mock.NewMockUserTenantRoleRepository()
+	userRepo.RolesLookup = func(userID uuid.UUID) []domain.UserTenantRole {
+		ptrs, _ := roleRepo.ListByUser(context.Background(), userID)
+		roles := make([]domain.UserTenantRole, len(ptrs))
+		for i, p := range ptrs {
+			roles[i] = *p
+		}
+		return roles
+	}
+	tenantID := uuid.New()
+	waiter := &domain.User{ID: uuid.New(), Email: "luis@casa-ana.com", IsActive: true}
+	userRepo.AddUser(waiter)
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: waiter.ID, TenantID: tenantID, Role: domain.RoleWaiter})
+
+	eventRepo := mock.NewMockAuthEventRepository()
+	scimService := service.NewSCIMService(service.SCIMServiceConfig{
+		Tokens:    mock.NewMockSCIMTokenRepository(),
+		Links:     mock.NewMockSCIMUserLinkRepository(),
+		UserRepo:  userRepo,
+		RoleRepo:  roleRepo,
+		EventRepo: eventRepo,
+		UserService: service.NewUserService(service.UserServiceConfig{
+			UserRepo:    userRepo,
+			RoleRepo:    roleRepo,
+			SessionRepo: mock.NewMockSessionRepository(),
+			EventRepo:   eventRepo,
+			EmailTokens: mock.NewMockEmailTokenRepository(),
+		}),
+		CustomRoles: mock.NewMockCustomRoleRepository(),
+	})
+	return NewSCIMHandler(scimService), tenantID
+}
+
+// createSCIMToken creates a SCIM token through the handler and returns it.
+func createSCIMToken(t *testing.T, h *SCIMHandler, tenantID uuid.UUID) CreateSCIMTokenResponse {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/scim/tokens", strings.NewReader(`{"name":"Workday"}`)).
+		WithContext(authedContext(uuid.New(), tenantID, domain.RoleAdmin))
+	w := httptest.NewRecorder()
+	h.CreateToken(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("CreateToken status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var resp CreateSCIMTokenResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	return resp
+}
+
+func TestSCIMHandler_Tokens(t *testing.T) {
+	h, tenantID := setupSCIMHandler(t)
+	ctx := authedContext(uuid.New(), tenantID, domain.RoleAdmin)
+
+	created := createSCIMToken(t, h, tenantID)
+	if created.Token == "" || created.Name != "Workday" {
+		t.Fatalf("unexpected response: %+v", created)
+	}
+
+	w := httptest.NewRecorder()
+	h.ListTokens(w, httptest.NewRequest("GET", "/scim/tokens", nil).WithContext(ctx))
+	var list SCIMTokenListResponse
+	json.NewDecoder(w.Body).Decode(&list)
+	if len(list.Data) != 1 || list.Data[0].ID != created.ID {
+		t.Fatalf("list = %+v, want the created token", list.Data)
+	}
+	if strings.Contains(w.Body.String(), created.Token) {
+		t.Error("token list must not expose tokens")
+	}
+
+	revoke := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("DELETE", "/scim/tokens/"+id, nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		h.RevokeToken(w, withChiURLParam(req, "id", id))
+		return w
+	}
+	if w := revoke(created.ID.String()); w.Code != http.StatusNoContent {
+		t.Errorf("Revoke status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	assertErrorCode(t, revoke(created.ID.String()), http.StatusNotFound, "not_found")
+	assertErrorCode(t, revoke("not-a-uuid"), http.StatusBadRequest, "invalid_id")
+
+	w = httptest.NewRecorder()
+	h.CreateToken(w, httptest.NewRequest("POST", "/scim/tokens", strings.NewReader(`{"name":" "}`)).WithContext(ctx))
+	assertErrorCode(t, w, http.StatusBadRequest, "invalid_request")
+}
+
+func TestSCIMHandler_RequireToken(t *testing.T) {
+	h, tenantID := setupSCIMHandler(t)
+	created := createSCIMToken(t, h, tenantID)
+
+	var gotTenant uuid.UUID
+	next := h.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant = getSCIMToken(r.Context()).TenantID
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{"valid token", "Bearer " + created.Token, http.StatusOK},
+		{"missing header", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer nope", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/Users", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			next.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("Content-Type") != scim.ContentType {
+				t.Errorf("Content-Type = %q, want %q", w.Header().Get("Content-Type"), scim.ContentType)
+			}
+		})
+	}
+	if gotTenant != tenantID {
+		t.Errorf("token tenant = %s, want %s", gotTenant, tenantID)
+	}
+}
+
+func TestSCIMHandler_ListUsers(t *testing.T) {
+	h, tenantID := setupSCIMHandler(t)
+	created := createSCIMToken(t, h, tenantID)
+	list := h.RequireToken(http.HandlerFunc(h.ListUsers))
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/Users"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+created.Token)
+		w := httptest.NewRecorder()
+		list.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(`?filter=userName+eq+"luis@casa-ana.com"`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		TotalResults int         `json:"totalResults"`
+		Resources    []scim.User `json:"Resources"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.TotalResults != 1 || resp.Resources[0].UserName != "luis@casa-ana.com" {
+		t.Errorf("response = %+v", resp)
+	}
+
+	for _, query := range []string{"?count=ten", "?startIndex=x", "?filter=userName+eq"} {
+		if w := get(query); w.Code != http.StatusBadRequest {
+			t.Errorf("GET /Users%s status = %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestSCIMHandler_PatchUser_InvalidBody(t *testing.T) {
+	h, tenantID := setupSCIMHandler(t)
+	created := createSCIMToken(t, h, tenantID)
+
+	req := httptest.NewRequest("PATCH", "/Users/x", strings.NewReader("{"))
+	req.Header.Set("Authorization", "Bearer "+created.Token)
+	w := httptest.NewRecorder()
+	h.RequireToken(http.HandlerFunc(h.PatchUser)).ServeHTTP(w, req)
+
+	assertSCIMError(t, w, http.StatusBadRequest, scim.ErrorInvalidSyntax)
+}
+
+func TestParseSCIMListRequest(t *testing.T) {
+	req, err := parseSCIMListRequest(httptest.NewRequest("GET", "/Groups?excludedAttributes=meta,Members", nil))
+	if err != nil {
+		t.Fatalf("parseSCIMListRequest failed: %v", err)
+	}
+	if req.StartIndex != 1 || req.Count != service.DefaultSCIMCount || !req.ExcludeMembers {
+		t.Errorf("defaults = %+v", req)
+	}
+
+	req, _ = parseSCIMListRequest(httptest.NewRequest("GET", `/Users?startIndex=3&count=0`, nil))
+	if req.StartIndex != 3 || req.Count != 0 || req.ExcludeMembers {
+		t.Errorf("request = %+v", req)
+	}
+}
+
+func TestWriteSCIMError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantStatus   int
+		wantScimType string
+	}{
+		{"scim error", scim.BadRequest(scim.ErrorInvalidFilter, "bad"), http.StatusBadRequest, scim.ErrorInvalidFilter},
+		{"user not found", domain.ErrUserNotFound, http.StatusNotFound, ""},
+		{"group not found", domain.ErrCustomRoleNotFound, http.StatusNotFound, ""},
+		{"cannot manage", domain.ErrCannotManageRole, http.StatusForbidden, ""},
+		{"cannot assign", domain.ErrCannotAssignRole, http.StatusForbidden, ""},
+		{"email exists", domain.ErrEmailExists, http.StatusConflict, scim.ErrorUniqueness},
+		{"unexpected", errors.New("connection refused"), http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeSCIMError(w, httptest.NewRequest("GET", "/Users", nil), tt.err)
+			assertSCIMError(t, w, tt.wantStatus, tt.wantScimType)
+		})
+	}
+}
+
+// assertSCIMError checks a recorded SCIM error response's status and type.
+func assertSCIMError(t *testing.T, w *httptest.ResponseRecorder, wantStatus int, wantScimType string) {
+	t.Helper()
+	if w.Code != wantStatus {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, wantStatus, w.Body.String())
+	}
+	var resp struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		ScimType string   `json:"scimType"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Schemas) != 1 || resp.Schemas[0] != scim.ErrorSchema || resp.ScimType != wantScimType {
+		t.Errorf("error = %+v, want scimType %q", resp, wantScimType)
+	}
+}
@@ -0,0 +1,474 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// maxServiceAccountDescriptionLength is the longest description a service
+// account may have.
+const maxServiceAccountDescriptionLength = 500
+
+// ServiceAccountHandler handles tenant service account and API key endpoints.
+type ServiceAccountHandler struct {
+	serviceAccounts *service.ServiceAccountService
+}
+
+// NewServiceAccountHandler creates a new ServiceAccountHandler.
+func NewServiceAccountHandler(serviceAccounts *service.ServiceAccountService) *ServiceAccountHandler {
+	return &ServiceAccountHandler{serviceAccounts: serviceAccounts}
+}
+
+// List handles GET /service-accounts.
+//
+// @Summary      List service accounts
+// @Description  Admin+ lists the current tenant's service accounts, ordered by name.
+// @Tags         service-accounts
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  ServiceAccountListResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role, service_account_forbidden"
+// @Router       /service-accounts [get]
+func (h *ServiceAccountHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	accounts, err := h.serviceAccounts.List(r.Context(), tenantID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToServiceAccountListResponse(accounts))
+}
+
+// Get handles GET /service-accounts/{id}.
+//
+// @Summary      Get a service account
+// @Description  Admin+ gets one of the current tenant's service accounts.
+// @Tags         service-accounts
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Service account ID"
+// @Success      200  {object}  ServiceAccountResponse
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role, service_account_forbidden"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /service-accounts/{id} [get]
+func (h *ServiceAccountHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid service account ID format")
+		return
+	}
+
+	account, err := h.serviceAccounts.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeServiceAccountError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToServiceAccountResponse(account))
+}
+
+// Create handles POST /service-accounts.
+//
+// @Summary      Create a service account
+// @Description  Admin+ creates a service account for an integration such as an accounting sync or a delivery platform. It can do exactly what its scopes allow, each of which you must hold yourself, and never reaches the user, role or auth endpoints meant for people. Create an API key for it to authenticate.
+// @Tags         service-accounts
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CreateServiceAccountRequest  true  "Service account"
+// @Success      201      {object}  ServiceAccountResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, invalid_name, unknown_permission"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role, permission_not_held, service_account_forbidden"
+// @Router       /service-accounts [post]
+func (h *ServiceAccountHandler) Create(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+	callerPerms, _ := GetPermissions(r.Context())
+
+	var req CreateServiceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if !validServiceAccountDescription(req.Description) {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Description must be at most 500 characters")
+		return
+	}
+
+	account, err := h.serviceAccounts.Create(r.Context(), service.CreateServiceAccountRequest{
+		TenantID:    tenantID,
+		Name:        req.Name,
+		Description: strings.TrimSpace(req.Description),
+		Scopes:      req.Scopes,
+		CreatedBy:   callerID,
+		IPAddress:   GetClientIP(r),
+	}, callerPerms)
+	if err != nil {
+		writeServiceAccountError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, ToServiceAccountResponse(account))
+}
+
+// Update handles PATCH /service-accounts/{id}.
+//
+// @Summary      Update a service account
+// @Description  Admin+ renames a service account, changes its description or replaces its scopes. New scopes apply to the account's next request.
+// @Tags         service-accounts
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                       true  "Service account ID"
+// @Param        request  body      UpdateServiceAccountRequest  true  "Fields to update"
+// @Success      200      {object}  ServiceAccountResponse
+// @Failure      400      {object}  ErrorResponse "invalid_id, invalid_request, invalid_name, unknown_permission"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role, permission_not_held, service_account_forbidden"
+// @Failure      404      {object}  ErrorResponse "not_found"
+// @Router       /service-accounts/{id} [patch]
+func (h *ServiceAccountHandler) Update(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+	callerPerms, _ := GetPermissions(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid service account ID format")
+		return
+	}
+
+	var req UpdateServiceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.Description != nil {
+		if !validServiceAccountDescription(*req.Description) {
+			writeError(w, http.StatusBadRequest, "invalid_request", "Description must be at most 500 characters")
+			return
+		}
+		description := strings.TrimSpace(*req.Description)
+		req.Description = &description
+	}
+
+	account, err := h.serviceAccounts.Update(r.Context(), service.UpdateServiceAccountRequest{
+		TenantID:    tenantID,
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		Scopes:      req.Scopes,
+		UpdatedBy:   callerID,
+		IPAddress:   GetClientIP(r),
+	}, callerPerms)
+	if err != nil {
+		writeServiceAccountError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToServiceAccountResponse(account))
+}
+
+// Delete handles DELETE /service-accounts/{id}.
+//
+// @Summary      Delete a service account
+// @Description  Admin+ deletes a service account. Its API keys stop working at once.
+// @Tags         service-accounts
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Service account ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role, service_account_forbidden"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /service-accounts/{id} [delete]
+func (h *ServiceAccountHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid service account ID format")
+		return
+	}
+
+	if err := h.serviceAccounts.Delete(r.Context(), tenantID, id, callerID, GetClientIP(r)); err != nil {
+		writeServiceAccountError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListKeys handles GET /service-accounts/{id}/keys.
+//
+// @Summary      List API keys
+// @Description  Admin+ lists a service account's API keys that have not been revoked, including expired ones. Keys are identified by their prefix; the keys themselves are never shown again.
+// @Tags         service-accounts
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Service account ID"
+// @Success      200  {object}  APIKeyListResponse
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role, service_account_forbidden"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /service-accounts/{id}/keys [get]
+func (h *ServiceAccountHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid service account ID format")
+		return
+	}
+
+	keys, err := h.serviceAccounts.ListAPIKeys(r.Context(), tenantID, id)
+	if err != nil {
+		writeServiceAccountError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToAPIKeyListResponse(keys))
+}
+
+// CreateKey handles POST /service-accounts/{id}/keys.
+//
+// @Summary      Create an API key
+// @Description  Admin+ creates an API key for a service account. The key is shown only this once; send it as a bearer token. Keys expire after expires_in_days (default 90, at most 365).
+// @Tags         service-accounts
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string               true  "Service account ID"
+// @Param        request  body      CreateAPIKeyRequest  true  "API key"
+// @Success      201      {object}  CreateAPIKeyResponse
+// @Failure      400      {object}  ErrorResponse "invalid_id, invalid_request, invalid_expiry"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role, service_account_forbidden"
+// @Failure      404      {object}  ErrorResponse "not_found"
+// @Router       /service-accounts/{id}/keys [post]
+func (h *ServiceAccountHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid service account ID format")
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Name is required and must be at most 100 characters")
+		return
+	}
+
+	key, plain, err := h.serviceAccounts.CreateAPIKey(r.Context(), service.CreateAPIKeyRequest{
+		TenantID:         tenantID,
+		ServiceAccountID: id,
+		Name:             name,
+		ExpiresIn:        time.Duration(req.ExpiresInDays) * 24 * time.Hour,
+		CreatedBy:        callerID,
+		IPAddress:        GetClientIP(r),
+	})
+	if err != nil {
+		writeServiceAccountError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, CreateAPIKeyResponse{
+		APIKeyResponse: ToAPIKeyResponse(key),
+		Key:            plain,
+	})
+}
+
+// RotateKey handles POST /service-accounts/{id}/keys/{keyId}/rotate.
+//
+// @Summary      Rotate an API key
+// @Description  Admin+ replaces an API key with a new one, shown only this once. The old key keeps working for grace_period_hours (at most 168) so the integration can switch over, or stops at once if it is 0.
+// @Tags         service-accounts
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string               true  "Service account ID"
+// @Param        keyId    path      string               true  "API key ID"
+// @Param        request  body      RotateAPIKeyRequest  true  "Rotation"
+// @Success      201      {object}  CreateAPIKeyResponse
+// @Failure      400      {object}  ErrorResponse "invalid_id, invalid_request, invalid_expiry"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role, service_account_forbidden"
+// @Failure      404      {object}  ErrorResponse "not_found"
+// @Router       /service-accounts/{id}/keys/{keyId}/rotate [post]
+func (h *ServiceAccountHandler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+
+	id, keyID, ok := parseAPIKeyPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req RotateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	key, plain, err := h.serviceAccounts.RotateAPIKey(r.Context(), service.RotateAPIKeyRequest{
+		TenantID:         tenantID,
+		ServiceAccountID: id,
+		KeyID:            keyID,
+		ExpiresIn:        time.Duration(req.ExpiresInDays) * 24 * time.Hour,
+		GracePeriod:      time.Duration(req.GracePeriodHours) * time.Hour,
+		RotatedBy:        callerID,
+		IPAddress:        GetClientIP(r),
+	})
+	if err != nil {
+		writeServiceAccountError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, CreateAPIKeyResponse{
+		APIKeyResponse: ToAPIKeyResponse(key),
+		Key:            plain,
+	})
+}
+
+// RevokeKey handles DELETE /service-accounts/{id}/keys/{keyId}.
+//
+// @Summary      Revoke an API key
+// @Description  Admin+ revokes an API key. It stops working at once.
+// @Tags         service-accounts
+// @Security     BearerAuth
+// @Param        id     path  string  true  "Service account ID"
+// @Param        keyId  path  string  true  "API key ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role, service_account_forbidden"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /service-accounts/{id}/keys/{keyId} [delete]
+func (h *ServiceAccountHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+
+	id, keyID, ok := parseAPIKeyPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.serviceAccounts.RevokeAPIKey(r.Context(), tenantID, id, keyID, callerID, GetClientIP(r)); err != nil {
+		writeServiceAccountError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseAPIKeyPath parses the service account and API key IDs of a key's
+// path, writing the error response if either is malformed.
+func parseAPIKeyPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid service account ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+	keyID, err := uuid.Parse(chi.URLParam(r, "keyId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid API key ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return id, keyID, true
+}
+
+// validServiceAccountDescription reports whether a description is short
+// enough to store.
+func validServiceAccountDescription(description string) bool {
+	return utf8.RuneCountInString(strings.TrimSpace(description)) <= maxServiceAccountDescriptionLength
+}
+
+// writeServiceAccountError maps service account and API key errors to responses.
+func writeServiceAccountError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrServiceAccountNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "Service account not found")
+	case errors.Is(err, domain.ErrAPIKeyNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "API key not found")
+	case errors.Is(err, domain.ErrServiceAccountName):
+		writeError(w, http.StatusBadRequest, "invalid_name", "Name must be 1-100 characters")
+	case errors.Is(err, domain.ErrAPIKeyLifetime):
+		writeError(w, http.StatusBadRequest, "invalid_expiry", err.Error())
+	case errors.Is(err, domain.ErrUnknownPermission):
+		writeError(w, http.StatusBadRequest, "unknown_permission", err.Error())
+	case errors.Is(err, domain.ErrPermissionNotHeld):
+		writeError(w, http.StatusForbidden, "permission_not_held", err.Error())
+	default:
+		writeInternalError(w, r, err)
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// setupServiceAccountHandler returns a ServiceAccountHandler and the context
+// of an admin of a tenant. Keys authenticating requests are covered by the
+// middleware and e2e tests.
+func setupServiceAccountHandler(t *testing.T) (*ServiceAccountHandler, context.Context) {
+	t.Helper()
+	svc := service.NewServiceAccountService(service.ServiceAccountServiceConfig{
+		Accounts:  mock.NewMockServiceAccountRepository(),
+		APIKeys:   mock.NewMockAPIKeyRepository(),
+		EventRepo: mock.NewMockAuthEventRepository(),
+	})
+	ctx := authedContext(uuid.New(), uuid.New(), domain.RoleAdmin)
+	ctx = context.WithValue(ctx, PermissionsContextKey, domain.RoleAdmin.Permissions())
+	return NewServiceAccountHandler(svc), ctx
+}
+
+// serviceAccountRequest builds a request as the admin with the given chi
+// route params, as key/value pairs.
+func serviceAccountRequest(ctx context.Context, method, body string, params ...string) *http.Request {
+	req := httptest.NewRequest(method, "/service-accounts", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	for i := 0; i+1 < len(params); i += 2 {
+		rctx.URLParams.Add(params[i], params[i+1])
+	}
+	return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+}
+
+func TestServiceAccountHandler_Lifecycle(t *testing.T) {
+	h, ctx := setupServiceAccountHandler(t)
+
+	w := httptest.NewRecorder()
+	h.Create(w, serviceAccountRequest(ctx, "POST", `{"name":"Accounting sync","scopes":["users.manage"]}`))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Create status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var account ServiceAccountResponse
+	json.NewDecoder(w.Body).Decode(&account)
+	id := account.ID.String()
+
+	w = httptest.NewRecorder()
+	h.Update(w, serviceAccountRequest(ctx, "PATCH", `{"description":"Nightly ledger export"}`, "id", id))
+	json.NewDecoder(w.Body).Decode(&account)
+	if w.Code != http.StatusOK || account.Description != "Nightly ledger export" || len(account.Scopes) != 1 {
+		t.Fatalf("Update = %d %+v", w.Code, account)
+	}
+
+	w = httptest.NewRecorder()
+	h.CreateKey(w, serviceAccountRequest(ctx, "POST", `{"name":"production","expires_in_days":30}`, "id", id))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("CreateKey status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var key CreateAPIKeyResponse
+	json.NewDecoder(w.Body).Decode(&key)
+	if !strings.HasPrefix(key.Key, key.Prefix) {
+		t.Errorf("key %q doesn't start with its prefix %q", key.Key, key.Prefix)
+	}
+
+	w = httptest.NewRecorder()
+	h.RotateKey(w, serviceAccountRequest(ctx, "POST", `{"grace_period_hours":24}`, "id", id, "keyId", key.ID.String()))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("RotateKey status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ListKeys(w, serviceAccountRequest(ctx, "GET", "", "id", id))
+	var keys APIKeyListResponse
+	json.NewDecoder(w.Body).Decode(&keys)
+	if len(keys.Data) != 2 {
+		t.Fatalf("ListKeys = %+v, want the old and new keys", keys.Data)
+	}
+	if strings.Contains(w.Body.String(), key.Key) {
+		t.Error("key list must not expose keys")
+	}
+
+	w = httptest.NewRecorder()
+	h.RevokeKey(w, serviceAccountRequest(ctx, "DELETE", "", "id", id, "keyId", key.ID.String()))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("RevokeKey status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+
+	w = httptest.NewRecorder()
+	h.Delete(w, serviceAccountRequest(ctx, "DELETE", "", "id", id))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Delete status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	w = httptest.NewRecorder()
+	h.Get(w, serviceAccountRequest(ctx, "GET", "", "id", id))
+	assertErrorCode(t, w, http.StatusNotFound, "not_found")
+}
+
+func TestServiceAccountHandler_BadRequests(t *testing.T) {
+	h, ctx := setupServiceAccountHandler(t)
+	id := uuid.New().String()
+
+	tests := []struct {
+		name     string
+		handle   http.HandlerFunc
+		req      *http.Request
+		wantCode string
+	}{
+		{"create invalid body", h.Create, serviceAccountRequest(ctx, "POST", "{"), "invalid_request"},
+		{"create long description", h.Create, serviceAccountRequest(ctx, "POST", `{"name":"Sync","description":"`+strings.Repeat("a", 501)+`"}`), "invalid_request"},
+		{"create blank name", h.Create, serviceAccountRequest(ctx, "POST", `{"name":" "}`), "invalid_name"},
+		{"create unknown scope", h.Create, serviceAccountRequest(ctx, "POST", `{"name":"Sync","scopes":["nope.nope"]}`), "unknown_permission"},
+		{"get invalid id", h.Get, serviceAccountRequest(ctx, "GET", "", "id", "x"), "invalid_id"},
+		{"key without name", h.CreateKey, serviceAccountRequest(ctx, "POST", `{}`, "id", id), "invalid_request"},
+		{"rotate invalid key id", h.RotateKey, serviceAccountRequest(ctx, "POST", `{}`, "id", id, "keyId", "x"), "invalid_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handle(w, tt.req)
+			assertErrorCode(t, w, http.StatusBadRequest, tt.wantCode)
+		})
+	}
+}
+
+func TestWriteServiceAccountError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"account not found", domain.ErrServiceAccountNotFound, http.StatusNotFound, "not_found"},
+		{"key not found", domain.ErrAPIKeyNotFound, http.StatusNotFound, "not_found"},
+		{"lifetime", domain.ErrAPIKeyLifetime, http.StatusBadRequest, "invalid_expiry"},
+		{"not held", domain.ErrPermissionNotHeld, http.StatusForbidden, "permission_not_held"},
+		{"unexpected", errors.New("connection refused"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeServiceAccountError(w, httptest.NewRequest("GET", "/service-accounts", nil), tt.err)
+			assertErrorCode(t, w, tt.wantStatus, tt.wantCode)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// SessionHandler handles self-service session (signed-in device) endpoints.
+type SessionHandler struct {
+	authService *service.AuthService
+}
+
+// NewSessionHandler creates a new SessionHandler.
+func NewSessionHandler(authService *service.AuthService) *SessionHandler {
+	return &SessionHandler{authService: authService}
+}
+
+// List handles GET /sessions.
+//
+// @Summary      List my sessions
+// @Description  List the devices the authenticated user is signed in on, across all tenants. The session the request was made from is flagged as current.
+// @Tags         sessions
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  SessionListResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Router       /auth/sessions [get]
+func (h *SessionHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(r.Context(), userID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	currentID, _ := GetSessionID(r.Context())
+	writeJSON(w, http.StatusOK, ToSessionListResponse(sessions, currentID))
+}
+
+// Revoke handles DELETE /sessions/{id}.
+//
+// @Summary      Revoke one of my sessions
+// @Description  Sign the authenticated user out of one of their devices. Access tokens already issued to that device remain valid until they expire.
+// @Tags         sessions
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Session ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /auth/sessions/{id} [delete]
+func (h *SessionHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid session ID format")
+		return
+	}
+
+	if err := h.authService.RevokeSession(r.Context(), userID, sessionID, GetClientIP(r)); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSessionNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "Session not found")
+			return
+		default:
+			writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeOthers handles POST /sessions/revoke-others.
+//
+// @Summary      Log out everywhere else
+// @Description  Revoke every session of the authenticated user except the one the request was made from.
+// @Tags         sessions
+// @Security     BearerAuth
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "session_unknown"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Router       /auth/sessions/revoke-others [post]
+func (h *SessionHandler) RevokeOthers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	currentID, ok := GetSessionID(r.Context())
+	if !ok {
+		writeError(w, http.StatusBadRequest, "session_unknown", "Access token is not bound to a session. Please log in again.")
+		return
+	}
+
+	if err := h.authService.LogoutOthers(r.Context(), userID, currentID, GetClientIP(r)); err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+)
+
+func setupSessionHandler(t *testing.T) (*SessionHandler, *mock.MockSessionRepository) {
+	t.Helper()
+
+	auth, _, _, _, _, _, sessionRepo := setupWiredAuthHandler(t)
+	return NewSessionHandler(auth.authService), sessionRepo
+}
+
+// sessionContext builds a request context as RequireAuth would populate it
+// for an access token bound to sessionID.
+func sessionContext(userID, tenantID, sessionID uuid.UUID) context.Context {
+	claims := domain.NewClaims(userID, tenantID, "user@example.com", domain.RoleWaiter, time.Now().Add(time.Hour))
+	claims.SessionID = sessionID.String()
+	ctx := authedContext(userID, tenantID, domain.RoleWaiter)
+	return context.WithValue(ctx, UserContextKey, claims)
+}
+
+func addSession(t *testing.T, sessionRepo *mock.MockSessionRepository, userID, tenantID uuid.UUID, userAgent string) *domain.Session {
+	t.Helper()
+
+	session := &domain.Session{
+		ID:           uuid.New(),
+		UserID:       userID,
+		TenantID:     tenantID,
+		RefreshToken: uuid.New().String(),
+		SignInMethod: domain.SignInPassword,
+		DeviceInfo:   userAgent,
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	if err := sessionRepo.Create(context.Background(), session); err != nil {
+		t.Fatalf("Create session failed: %v", err)
+	}
+	return session
+}
+
+func TestSessionHandler_List(t *testing.T) {
+	h, sessionRepo := setupSessionHandler(t)
+
+	userID, tenantID := uuid.New(), uuid.New()
+	current := addSession(t, sessionRepo, userID, tenantID, "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0.0.0 Safari/537.36")
+	addSession(t, sessionRepo, userID, tenantID, "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) Version/17.2 Safari/604.1")
+	addSession(t, sessionRepo, uuid.New(), tenantID, "someone else")
+
+	req := httptest.NewRequest("GET", "/sessions", nil).WithContext(sessionContext(userID, tenantID, current.ID))
+	w := httptest.NewRecorder()
+	h.List(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp SessionListResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Data) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(resp.Data))
+	}
+	for _, s := range resp.Data {
+		if s.ID == current.ID {
+			if !s.Current || s.DeviceName != "Chrome on Windows" {
+				t.Errorf("unexpected current session: %+v", s)
+			}
+		} else if s.Current || s.DeviceName != "Safari on iPhone" {
+			t.Errorf("unexpected other session: %+v", s)
+		}
+	}
+}
+
+func TestSessionHandler_Revoke(t *testing.T) {
+	h, sessionRepo := setupSessionHandler(t)
+
+	userID, tenantID := uuid.New(), uuid.New()
+	current := addSession(t, sessionRepo, userID, tenantID, "")
+	other := addSession(t, sessionRepo, userID, tenantID, "")
+
+	req := httptest.NewRequest("DELETE", "/sessions/"+other.ID.String(), nil).WithContext(sessionContext(userID, tenantID, current.ID))
+	req = withChiURLParam(req, "id", other.ID.String())
+	w := httptest.NewRecorder()
+	h.Revoke(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+	found, _ := sessionRepo.FindByID(context.Background(), other.ID)
+	if !found.IsRevoked() {
+		t.Error("Session should be revoked")
+	}
+}
+
+func TestSessionHandler_Revoke_Errors(t *testing.T) {
+	h, sessionRepo := setupSessionHandler(t)
+
+	userID, tenantID := uuid.New(), uuid.New()
+	someoneElses := addSession(t, sessionRepo, uuid.New(), tenantID, "")
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{"invalid id", "not-a-uuid", http.StatusBadRequest},
+		{"unknown session", uuid.New().String(), http.StatusNotFound},
+		{"another user's session", someoneElses.ID.String(), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", "/sessions/"+tt.id, nil).WithContext(authedContext(userID, tenantID, domain.RoleWaiter))
+			req = withChiURLParam(req, "id", tt.id)
+			w := httptest.NewRecorder()
+			h.Revoke(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("Status = %d, want %d, body=%s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+
+	found, _ := sessionRepo.FindByID(context.Background(), someoneElses.ID)
+	if found.IsRevoked() {
+		t.Error("Another user's session must not be revoked")
+	}
+}
+
+func TestSessionHandler_RevokeOthers(t *testing.T) {
+	h, sessionRepo := setupSessionHandler(t)
+
+	userID, tenantID := uuid.New(), uuid.New()
+	current := addSession(t, sessionRepo, userID, tenantID, "")
+	other := addSession(t, sessionRepo, userID, tenantID, "")
+
+	req := httptest.NewRequest("POST", "/sessions/revoke-others", nil).WithContext(sessionContext(userID, tenantID, current.ID))
+	w := httptest.NewRecorder()
+	h.RevokeOthers(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+	found, _ := sessionRepo.FindByID(context.Background(), current.ID)
+	if found.IsRevoked() {
+		t.Error("Current session should not be revoked")
+	}
+	found, _ = sessionRepo.FindByID(context.Background(), other.ID)
+	if !found.IsRevoked() {
+		t.Error("Other session should be revoked")
+	}
+}
+
+func TestSessionHandler_RevokeOthers_NoSessionInToken(t *testing.T) {
+	h, _ := setupSessionHandler(t)
+
+	req := httptest.NewRequest("POST", "/sessions/revoke-others", nil).WithContext(authedContext(uuid.New(), uuid.New(), domain.RoleWaiter))
+	w := httptest.NewRecorder()
+	h.RevokeOthers(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	var resp ErrorResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Error.Code != "session_unknown" {
+		t.Errorf("Error code = %q, want session_unknown", resp.Error.Code)
+	}
+}
+
+func TestSessionHandler_Unauthorized(t *testing.T) {
+	h, _ := setupSessionHandler(t)
+
+	for name, fn := range map[string]http.HandlerFunc{
+		"list":          h.List,
+		"revoke":        h.Revoke,
+		"revoke-others": h.RevokeOthers,
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			fn(w, httptest.NewRequest("GET", "/sessions", nil))
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("Status = %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
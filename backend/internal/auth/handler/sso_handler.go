@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+// SSOHandler handles single sign-on endpoints.
+type SSOHandler struct {
+	sso *service.SSOService
+}
+
+// NewSSOHandler creates a new SSOHandler.
+func NewSSOHandler(sso *service.SSOService) *SSOHandler {
+	return &SSOHandler{sso: sso}
+}
+
+// Start handles POST /sso/start.
+//
+// @Summary      Start an SSO login
+// @Description  Begin signing in to a tenant through its identity provider. Send the user's browser to the returned authorization URL; the provider redirects back to the configured redirect URI with state and code, which the client posts to /auth/sso/callback within 10 minutes.
+// @Tags         sso
+// @Accept       json
+// @Produce      json
+// @Param        request  body      SSOStartRequest  true  "Tenant to sign in to"
+// @Success      200      {object}  SSOStartResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request"
+// @Failure      401      {object}  ErrorResponse "tenant_inactive"
+// @Failure      404      {object}  ErrorResponse "sso_not_configured"
+// @Failure      502      {object}  ErrorResponse "sso_provider_unavailable"
+// @Router       /auth/sso/start [post]
+func (h *SSOHandler) Start(w http.ResponseWriter, r *http.Request) {
+	var req SSOStartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.TenantSlug == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Tenant slug is required")
+		return
+	}
+
+	authURL, err := h.sso.StartLogin(r.Context(), req.TenantSlug)
+	if err != nil {
+		writeSSOError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SSOStartResponse{AuthorizationURL: authURL})
+}
+
+// Callback handles POST /sso/callback.
+//
+// @Summary      Complete an SSO login
+// @Description  Finish an SSO login with the state and code the identity provider sent back. The provider account is linked to an existing member of the tenant by verified email in one of the tenant's allowed domains; accounts are never created here. MFA is left to the identity provider, so it only signs in roles below that of the member who last saved the SSO configuration, and never Owners; others get sso_role_not_allowed and sign in with a password.
+// @Tags         sso
+// @Accept       json
+// @Produce      json
+// @Param        request  body      SSOCallbackRequest  true  "State and code from the identity provider"
+// @Success      200      {object}  LoginResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, sso_state_invalid"
+// @Failure      401      {object}  ErrorResponse "sso_failed, account_disabled, tenant_inactive"
+// @Failure      403      {object}  ErrorResponse "sso_email_not_allowed, sso_account_not_found, sso_role_not_allowed"
+// @Failure      404      {object}  ErrorResponse "sso_not_configured"
+// @Failure      502      {object}  ErrorResponse "sso_provider_unavailable"
+// @Router       /auth/sso/callback [post]
+func (h *SSOHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	var req SSOCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.State == "" || req.Code == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "State and code are required")
+		return
+	}
+
+	resp, err := h.sso.CompleteLogin(r.Context(), service.CompleteSSOLoginRequest{
+		State:     req.State,
+		Code:      req.Code,
+		IPAddress: GetClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		writeSSOError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToLoginResponse(resp))
+}
+
+// GetConfig handles GET /sso/config.
+//
+// @Summary      Get the tenant's SSO configuration
+// @Description  Admin+ views the tenant's identity provider settings. The client secret is never returned.
+// @Tags         sso
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  SSOConfigResponse
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Failure      404  {object}  ErrorResponse "sso_not_configured"
+// @Router       /auth/sso/config [get]
+func (h *SSOHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	cfg, err := h.sso.GetConfig(r.Context(), tenantID)
+	if err != nil {
+		writeSSOError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToSSOConfigResponse(cfg, h.sso.RedirectURL()))
+}
+
+// PutConfig handles PUT /sso/config.
+//
+// @Summary      Set up the tenant's SSO
+// @Description  Admin+ sets the tenant's OpenID Connect identity provider. The issuer must use https and is discovered before saving. The client secret is required the first time and kept when omitted afterwards. SSO signs in only roles below the caller's, never owners. With password_login_disabled, the members it signs in can only sign in to the tenant through SSO; everyone else keeps their password.
+// @Tags         sso
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      SSOConfigRequest  true  "Identity provider settings"
+// @Success      200      {object}  SSOConfigResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, sso_config_invalid"
+// @Failure      401      {object}  ErrorResponse "unauthorized"
+// @Failure      403      {object}  ErrorResponse "insufficient_role"
+// @Failure      502      {object}  ErrorResponse "sso_provider_unavailable"
+// @Router       /auth/sso/config [put]
+func (h *SSOHandler) PutConfig(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	var req SSOConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	cfg, err := h.sso.SaveConfig(r.Context(), service.SaveSSOConfigRequest{
+		TenantID:              tenantID,
+		UpdatedBy:             userID,
+		Issuer:                req.Issuer,
+		ClientID:              req.ClientID,
+		ClientSecret:          req.ClientSecret,
+		AllowedDomains:        req.AllowedDomains,
+		PasswordLoginDisabled: req.PasswordLoginDisabled,
+		IPAddress:             GetClientIP(r),
+		UserAgent:             r.UserAgent(),
+	})
+	if err != nil {
+		writeSSOError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToSSOConfigResponse(cfg, h.sso.RedirectURL()))
+}
+
+// DeleteConfig handles DELETE /sso/config.
+//
+// @Summary      Remove the tenant's SSO
+// @Description  Admin+ removes the tenant's identity provider. Password login is allowed again for everyone; existing identity links are kept for if SSO is set up again with the same provider.
+// @Tags         sso
+// @Security     BearerAuth
+// @Success      204  "No Content"
+// @Failure      401  {object}  ErrorResponse "unauthorized"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Failure      404  {object}  ErrorResponse "sso_not_configured"
+// @Router       /auth/sso/config [delete]
+func (h *SSOHandler) DeleteConfig(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+	tenantID, _ := GetTenantID(r.Context())
+
+	if err := h.sso.DeleteConfig(r.Context(), tenantID, userID, GetClientIP(r), r.UserAgent()); err != nil {
+		writeSSOError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeSSOError maps SSO login and configuration errors to responses.
+func writeSSOError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSSONotConfigured):
+		writeError(w, http.StatusNotFound, "sso_not_configured", "Single sign-on is not set up for this organization")
+	case errors.Is(err, domain.ErrSSOConfigInvalid):
+		writeError(w, http.StatusBadRequest, "sso_config_invalid", "An https issuer, a client ID, a client secret and at least one valid email domain are required")
+	case errors.Is(err, domain.ErrSSOProviderUnavailable):
+		writeError(w, http.StatusBadGateway, "sso_provider_unavailable", "The identity provider could not be reached")
+	case errors.Is(err, domain.ErrSSOStateInvalid):
+		writeError(w, http.StatusBadRequest, "sso_state_invalid", "This sign-in link is invalid or has expired. Please start again.")
+	case errors.Is(err, domain.ErrSSOTokenInvalid):
+		writeError(w, http.StatusUnauthorized, "sso_failed", "The identity provider's response could not be verified")
+	case errors.Is(err, domain.ErrSSOEmailNotAllowed):
+		writeError(w, http.StatusForbidden, "sso_email_not_allowed", "Your identity provider account needs a verified email in one of this organization's domains")
+	case errors.Is(err, domain.ErrSSOAccountNotFound):
+		writeError(w, http.StatusForbidden, "sso_account_not_found", "No account in this organization matches your email. Ask an administrator for an invitation.")
+	case errors.Is(err, domain.ErrSSORoleNotAllowed):
+		writeError(w, http.StatusForbidden, "sso_role_not_allowed", "Your role in this organization signs in with a password")
+	case errors.Is(err, domain.ErrAccountDisabled):
+		writeError(w, http.StatusUnauthorized, "account_disabled", "Account is disabled")
+	case errors.Is(err, domain.ErrTenantInactive):
+		writeError(w, http.StatusUnauthorized, "tenant_inactive", "Tenant is inactive")
+	default:
+		writeInternalError(w, r, err)
+	}
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/internal/auth/service"
+)
+
+const testSSORedirectURL = "https://app.example.com/sso/callback"
+
+// setupSSOHandler returns an SSOHandler for a tenant with SSO configured.
+// Nothing here reaches an identity provider; the full flow is covered by
+// the service and e2e tests.
+func setupSSOHandler(t *testing.T) (*SSOHandler, uuid.UUID) {
+	t.Helper()
+
+	tenantRepo := mock.NewMockTenantRepository()
+	configs := mock.NewMockSSOConfigRepository()
+	tenantID := uuid.New()
+	tenantRepo.AddTenant(&domain.Tenant{ID: tenantID, Name: "Casa Ana", Slug: "casa-ana", IsActive: true})
+	configs.AddConfig(&domain.TenantSSOConfig{
+		TenantID:       tenantID,
+		Issuer:         "https://idp.casa-ana.com",
+		ClientID:       "erp-client",
+		ClientSecret:   "erp-secret",
+		AllowedDomains: domain.DomainList{"casa-ana.com"},
+	})
+
+	sso := service.NewSSOService(service.SSOServiceConfig{
+		Configs:     configs,
+		States:      mock.NewMockSSOLoginStateRepository(),
+		Identities:  mock.NewMockUserIdentityRepository(),
+		TenantRepo:  tenantRepo,
+		UserRepo:    mock.NewMockUserRepository(),
+		EventRepo:   mock.NewMockAuthEventRepository(),
+		RedirectURL: testSSORedirectURL,
+	})
+	return NewSSOHandler(sso), tenantID
+}
+
+func TestSSOHandler_Start_Validation(t *testing.T) {
+	h, _ := setupSSOHandler(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"invalid body", "{", http.StatusBadRequest, "invalid_request"},
+		{"missing slug", `{}`, http.StatusBadRequest, "invalid_request"},
+		{"unknown tenant", `{"tenant_slug":"nowhere"}`, http.StatusNotFound, "sso_not_configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/sso/start", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			h.Start(w, req)
+
+			assertErrorCode(t, w, tt.wantStatus, tt.wantCode)
+		})
+	}
+}
+
+func TestSSOHandler_Callback_Validation(t *testing.T) {
+	h, _ := setupSSOHandler(t)
+
+	tests := []struct {
+		name       string
+		body       SSOCallbackRequest
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing state", SSOCallbackRequest{Code: "code"}, http.StatusBadRequest, "invalid_request"},
+		{"missing code", SSOCallbackRequest{State: "state"}, http.StatusBadRequest, "invalid_request"},
+		{"unknown state", SSOCallbackRequest{State: "state", Code: "code"}, http.StatusBadRequest, "sso_state_invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest("POST", "/sso/callback", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			h.Callback(w, req)
+
+			assertErrorCode(t, w, tt.wantStatus, tt.wantCode)
+		})
+	}
+}
+
+func TestSSOHandler_GetConfig(t *testing.T) {
+	h, tenantID := setupSSOHandler(t)
+
+	req := httptest.NewRequest("GET", "/sso/config", nil).WithContext(authedContext(uuid.New(), tenantID, domain.RoleAdmin))
+	w := httptest.NewRecorder()
+	h.GetConfig(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "erp-secret") {
+		t.Error("response must not contain the client secret")
+	}
+	var resp SSOConfigResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Issuer != "https://idp.casa-ana.com" || !resp.ClientSecretSet || resp.RedirectURI != testSSORedirectURL {
+		t.Errorf("response = %+v", resp)
+	}
+
+	req = httptest.NewRequest("GET", "/sso/config", nil).WithContext(authedContext(uuid.New(), uuid.New(), domain.RoleAdmin))
+	w = httptest.NewRecorder()
+	h.GetConfig(w, req)
+	assertErrorCode(t, w, http.StatusNotFound, "sso_not_configured")
+}
+
+func TestSSOHandler_PutConfig_Invalid(t *testing.T) {
+	h, tenantID := setupSSOHandler(t)
+
+	body, _ := json.Marshal(SSOConfigRequest{Issuer: "http://idp.casa-ana.com", ClientID: "erp-client", AllowedDomains: []string{"casa-ana.com"}})
+	req := httptest.NewRequest("PUT", "/sso/config", bytes.NewReader(body)).WithContext(authedContext(uuid.New(), tenantID, domain.RoleAdmin))
+	w := httptest.NewRecorder()
+
+	h.PutConfig(w, req)
+
+	assertErrorCode(t, w, http.StatusBadRequest, "sso_config_invalid")
+}
+
+func TestSSOHandler_DeleteConfig(t *testing.T) {
+	h, tenantID := setupSSOHandler(t)
+	adminID := uuid.New()
+
+	req := httptest.NewRequest("DELETE", "/sso/config", nil).WithContext(authedContext(adminID, tenantID, domain.RoleAdmin))
+	w := httptest.NewRecorder()
+	h.DeleteConfig(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.DeleteConfig(w, req)
+	assertErrorCode(t, w, http.StatusNotFound, "sso_not_configured")
+}
+
+func TestWriteSSOError(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{domain.ErrSSONotConfigured, http.StatusNotFound, "sso_not_configured"},
+		{domain.ErrSSOConfigInvalid, http.StatusBadRequest, "sso_config_invalid"},
+		{fmt.Errorf("%w: dial tcp: timeout", domain.ErrSSOProviderUnavailable), http.StatusBadGateway, "sso_provider_unavailable"},
+		{domain.ErrSSOStateInvalid, http.StatusBadRequest, "sso_state_invalid"},
+		{domain.ErrSSOTokenInvalid, http.StatusUnauthorized, "sso_failed"},
+		{domain.ErrSSOEmailNotAllowed, http.StatusForbidden, "sso_email_not_allowed"},
+		{domain.ErrSSOAccountNotFound, http.StatusForbidden, "sso_account_not_found"},
+		{domain.ErrAccountDisabled, http.StatusUnauthorized, "account_disabled"},
+		{domain.ErrTenantInactive, http.StatusUnauthorized, "tenant_inactive"},
+		{errors.New("connection refused"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantCode, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeSSOError(w, httptest.NewRequest("POST", "/sso/callback", nil), tt.err)
+			assertErrorCode(t, w, tt.wantStatus, tt.wantCode)
+		})
+	}
+}
+
+// assertErrorCode checks a recorded error response's status and code.
+func assertErrorCode(t *testing.T, w *httptest.ResponseRecorder, wantStatus int, wantCode string) {
+	t.Helper()
+	if w.Code != wantStatus {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, wantStatus, w.Body.String())
+	}
+	var resp ErrorResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Error.Code != wantCode {
+		t.Errorf("error code = %q, want %q", resp.Error.Code, wantCode)
+	}
+}
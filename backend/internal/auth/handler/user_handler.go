@@ -22,21 +22,21 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 	return &UserHandler{userService: userService}
 }
 
-// Create handles POST /users.
+// Invite handles POST /users/invitations.
 //
-// @Summary      Create a staff account
-// @Description  Manager+ creates a user for their tenant. If the email already has a global account in a different tenant, links a role to it instead (200) rather than creating a duplicate (201). New accounts get a temp password emailed to them (never returned in the response).
+// @Summary      Invite someone to the tenant
+// @Description  Manager+ invites a person by email. The invitee receives a single-use link (valid 7 days) to set their own password, or, if the email already has an account, to confirm it and join this tenant. The invite token is never returned in the response.
 // @Tags         users
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        request  body      CreateUserRequest  true  "New user details"
-// @Success      201      {object}  CreateUserResponse "new account created"
-// @Success      200      {object}  CreateUserResponse "linked to existing global account"
+// @Param        request  body      InviteUserRequest  true  "Invitee details"
+// @Success      201      {object}  InvitationResponse
 // @Failure      400      {object}  ErrorResponse "invalid_request, invalid_role, email_exists"
 // @Failure      403      {object}  ErrorResponse "insufficient_role"
-// @Router       /users [post]
-func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
+// @Failure      409      {object}  ErrorResponse "invitation_pending"
+// @Router       /users/invitations [post]
+func (h *UserHandler) Invite(w http.ResponseWriter, r *http.Request) {
 	callerRole, ok := GetRole(r.Context())
 	if !ok {
 		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
@@ -46,7 +46,7 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	callerID, _ := GetUserID(r.Context())
 	tenantID, _ := GetTenantID(r.Context())
 
-	var req CreateUserRequest
+	var req InviteUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
 		return
@@ -63,24 +63,27 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	createReq := service.CreateUserRequest{
+	inviteReq := service.InviteRequest{
 		Email:     req.Email,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		TenantID:  tenantID,
 		Role:      req.Role,
-		CreatedBy: callerID,
+		InvitedBy: callerID,
 		IPAddress: GetClientIP(r),
 	}
 
-	resp, err := h.userService.Create(r.Context(), createReq, callerRole)
+	resp, err := h.userService.Invite(r.Context(), inviteReq, callerRole)
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrCannotAssignRole):
-			writeError(w, http.StatusForbidden, "insufficient_role", "Cannot create users with role equal or higher than your own")
+			writeError(w, http.StatusForbidden, "insufficient_role", "Cannot invite users with role equal or higher than your own")
 			return
 		case errors.Is(err, domain.ErrEmailExists):
-			writeError(w, http.StatusBadRequest, "email_exists", "Email already registered")
+			writeError(w, http.StatusBadRequest, "email_exists", "This person is already a member of the tenant")
+			return
+		case errors.Is(err, domain.ErrInvitationPending):
+			writeError(w, http.StatusConflict, "invitation_pending", "An invitation is already pending for this email; resend it instead")
 			return
 		default:
 			writeInternalError(w, r, err)
@@ -88,31 +91,125 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if resp.LinkedExistingAccount {
-		writeJSON(w, http.StatusOK, CreateUserResponse{
-			ID:                    resp.User.ID,
-			Email:                 resp.User.Email,
-			FirstName:             resp.User.FirstName,
-			LastName:              resp.User.LastName,
-			Role:                  string(createReq.Role),
-			MustResetPassword:     false,
-			LinkedExistingAccount: true,
-			Message:               "Existing account linked to this tenant. User notified by email.",
-			CreatedAt:             resp.User.CreatedAt,
-		})
-		return
-	}
-
-	writeJSON(w, http.StatusCreated, CreateUserResponse{
-		ID:                resp.User.ID,
-		Email:             resp.User.Email,
-		FirstName:         resp.User.FirstName,
-		LastName:          resp.User.LastName,
-		Role:              string(createReq.Role),
-		MustResetPassword: true,
-		Message:           "Account created. Temporary password sent to user's email.",
-		CreatedAt:         resp.User.CreatedAt,
-	})
+	writeJSON(w, http.StatusCreated, ToInvitationResponse(resp.Invitation, resp.ExistingAccount))
+}
+
+// ListInvitations handles GET /users/invitations.
+//
+// @Summary      List pending invitations
+// @Description  Manager+ lists the tenant's invitations that have not been accepted or revoked, including expired ones that can be resent.
+// @Tags         users
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  InvitationListResponse
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Router       /users/invitations [get]
+func (h *UserHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := GetTenantID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	invitations, err := h.userService.ListInvitations(r.Context(), tenantID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToInvitationListResponse(invitations))
+}
+
+// ResendInvitation handles POST /users/invitations/{id}/resend.
+//
+// @Summary      Resend an invitation
+// @Description  Manager+ emails a fresh invite link and restarts the 7-day expiry. The previous link stops working.
+// @Tags         users
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Invitation ID"
+// @Success      200  {object}  InvitationResponse
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /users/invitations/{id}/resend [post]
+func (h *UserHandler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	actionReq, callerRole, ok := invitationActionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.userService.ResendInvitation(r.Context(), actionReq, callerRole)
+	if err != nil {
+		writeInvitationActionError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToInvitationResponse(resp.Invitation, resp.ExistingAccount))
+}
+
+// RevokeInvitation handles DELETE /users/invitations/{id}.
+//
+// @Summary      Revoke an invitation
+// @Description  Manager+ cancels a pending invitation so its link can no longer be used.
+// @Tags         users
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Invitation ID"
+// @Success      204  "Invitation revoked"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /users/invitations/{id} [delete]
+func (h *UserHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	actionReq, callerRole, ok := invitationActionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.RevokeInvitation(r.Context(), actionReq, callerRole); err != nil {
+		writeInvitationActionError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// invitationActionRequest builds the service request for resending or
+// revoking the invitation in the URL, writing an error response if it can't.
+func invitationActionRequest(w http.ResponseWriter, r *http.Request) (service.InvitationActionRequest, domain.Role, bool) {
+	callerRole, ok := GetRole(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return service.InvitationActionRequest{}, "", false
+	}
+
+	callerID, _ := GetUserID(r.Context())
+	tenantID, _ := GetTenantID(r.Context())
+
+	invitationID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid invitation ID format")
+		return service.InvitationActionRequest{}, "", false
+	}
+
+	return service.InvitationActionRequest{
+		InvitationID: invitationID,
+		TenantID:     tenantID,
+		PerformedBy:  callerID,
+		IPAddress:    GetClientIP(r),
+	}, callerRole, true
+}
+
+// writeInvitationActionError maps resend/revoke errors to responses.
+func writeInvitationActionError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvitationNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "Invitation not found")
+	case errors.Is(err, domain.ErrCannotAssignRole):
+		writeError(w, http.StatusForbidden, "insufficient_role", "Cannot manage invitations for a role equal or higher than your own")
+	default:
+		writeInternalError(w, r, err)
+	}
 }
 
 // List handles GET /users.
@@ -330,7 +427,7 @@ func (h *UserHandler) Unlock(w http.ResponseWriter, r *http.Request) {
 // UpdateRole handles PATCH /users/{id}/role.
 //
 // @Summary      Change a user's role
-// @Description  Manager+ changes a user's role in the current tenant. Cannot assign or manage a role equal to or higher than your own.
+// @Description  Manager+ changes a user's role in the current tenant, to a built-in role or one of the tenant's custom roles (custom_role_id, which takes precedence over role). A custom role ranks as its base role. Cannot assign or manage a role equal to or higher than your own.
 // @Tags         users
 // @Security     BearerAuth
 // @Accept       json
@@ -365,17 +462,18 @@ func (h *UserHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !req.Role.IsValid() {
+	if req.CustomRoleID == nil && !req.Role.IsValid() {
 		writeError(w, http.StatusBadRequest, "invalid_role", "Invalid role specified")
 		return
 	}
 
 	updateReq := service.UpdateRoleRequest{
-		UserID:    userID,
-		TenantID:  tenantID,
-		NewRole:   req.Role,
-		UpdatedBy: callerID,
-		IPAddress: GetClientIP(r),
+		UserID:       userID,
+		TenantID:     tenantID,
+		NewRole:      req.Role,
+		CustomRoleID: req.CustomRoleID,
+		UpdatedBy:    callerID,
+		IPAddress:    GetClientIP(r),
 	}
 
 	err = h.userService.UpdateRole(r.Context(), updateReq, callerRole)
@@ -384,6 +482,9 @@ func (h *UserHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
 		case errors.Is(err, domain.ErrUserNotInTenant):
 			writeError(w, http.StatusNotFound, "not_found", "User not found in this tenant")
 			return
+		case errors.Is(err, domain.ErrCustomRoleNotFound):
+			writeError(w, http.StatusBadRequest, "invalid_role", "Custom role not found in this tenant")
+			return
 		case errors.Is(err, domain.ErrCannotAssignRole):
 			writeError(w, http.StatusForbidden, "insufficient_role", "Cannot assign role equal or higher than your own")
 			return
@@ -405,3 +506,416 @@ func (h *UserHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
 
 	writeJSON(w, http.StatusOK, ToUserResponse(user, tenantID))
 }
+
+// ElevateRole handles POST /users/{id}/role/elevation.
+//
+// @Summary      Temporarily elevate a user's role
+// @Description  Manager+ gives a user a higher role (or a custom role, via custom_role_id) in the current tenant from valid_from (default now) until valid_until, at most 7 days. The user's standing role is kept and applies again when the window ends, and the sessions issued under the elevated role are then revoked. A new elevation replaces any existing one. The same role hierarchy rules apply as for changing a role.
+// @Tags         users
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string              true  "User ID"
+// @Param        request  body      ElevateRoleRequest  true  "Elevated role and window"
+// @Success      200      {object}  UserResponse
+// @Failure      400      {object}  ErrorResponse "invalid_id, invalid_request, invalid_role, invalid_window, not_an_elevation"
+// @Failure      403      {object}  ErrorResponse "insufficient_role"
+// @Failure      404      {object}  ErrorResponse "not_found"
+// @Router       /users/{id}/role/elevation [post]
+func (h *UserHandler) ElevateRole(w http.ResponseWriter, r *http.Request) {
+	callerRole, ok := GetRole(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	callerID, _ := GetUserID(r.Context())
+	tenantID, _ := GetTenantID(r.Context())
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid user ID format")
+		return
+	}
+
+	var req ElevateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.CustomRoleID == nil && !req.Role.IsValid() {
+		writeError(w, http.StatusBadRequest, "invalid_role", "Invalid role specified")
+		return
+	}
+
+	_, err = h.userService.ElevateRole(r.Context(), service.ElevateRoleRequest{
+		UserID:       userID,
+		TenantID:     tenantID,
+		NewRole:      req.Role,
+		CustomRoleID: req.CustomRoleID,
+		ValidFrom:    req.ValidFrom,
+		ValidUntil:   req.ValidUntil,
+		ElevatedBy:   callerID,
+		IPAddress:    GetClientIP(r),
+	}, callerRole)
+	if err != nil {
+		writeElevationError(w, r, err)
+		return
+	}
+
+	user, err := h.userService.GetByID(r.Context(), userID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToUserResponse(user, tenantID))
+}
+
+// EndElevation handles DELETE /users/{id}/role/elevation.
+//
+// @Summary      End a role elevation
+// @Description  Manager+ ends a user's temporary role elevation early, or cancels one that hasn't started. The user reverts to their standing role and the sessions issued under the elevated role are revoked.
+// @Tags         users
+// @Security     BearerAuth
+// @Param        id   path  string  true  "User ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Failure      404  {object}  ErrorResponse "not_found, no_elevation"
+// @Router       /users/{id}/role/elevation [delete]
+func (h *UserHandler) EndElevation(w http.ResponseWriter, r *http.Request) {
+	callerRole, ok := GetRole(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	callerID, _ := GetUserID(r.Context())
+	tenantID, _ := GetTenantID(r.Context())
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid user ID format")
+		return
+	}
+
+	err = h.userService.EndElevation(r.Context(), service.EndElevationRequest{
+		UserID:    userID,
+		TenantID:  tenantID,
+		EndedBy:   callerID,
+		IPAddress: GetClientIP(r),
+	}, callerRole)
+	if err != nil {
+		writeElevationError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeElevationError maps role elevation errors to responses.
+func writeElevationError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotInTenant):
+		writeError(w, http.StatusNotFound, "not_found", "User not found in this tenant")
+	case errors.Is(err, domain.ErrNoElevation):
+		writeError(w, http.StatusNotFound, "no_elevation", "User has no role elevation")
+	case errors.Is(err, domain.ErrCustomRoleNotFound):
+		writeError(w, http.StatusBadRequest, "invalid_role", "Custom role not found in this tenant")
+	case errors.Is(err, domain.ErrElevationWindow):
+		writeError(w, http.StatusBadRequest, "invalid_window", "valid_until must be in the future, after valid_from, and at most 7 days after it")
+	case errors.Is(err, domain.ErrElevationNotAbove):
+		writeError(w, http.StatusBadRequest, "not_an_elevation", "Elevated role must differ from and not rank below the user's current role")
+	case errors.Is(err, domain.ErrCannotAssignRole):
+		writeError(w, http.StatusForbidden, "insufficient_role", "Cannot assign role equal or higher than your own")
+	case errors.Is(err, domain.ErrCannotManageRole):
+		writeError(w, http.StatusForbidden, "insufficient_role", "Cannot manage users with this role")
+	default:
+		writeInternalError(w, r, err)
+	}
+}
+
+// ListSessions handles GET /users/{id}/sessions.
+//
+// @Summary      List a user's sessions
+// @Description  Manager+ lists the devices a user is signed in on in the current tenant. Cannot view users with a role equal to or higher than your own.
+// @Tags         users
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  SessionListResponse
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /users/{id}/sessions [get]
+func (h *UserHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	callerRole, ok := GetRole(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid user ID format")
+		return
+	}
+
+	sessions, err := h.userService.ListUserSessions(r.Context(), userID, tenantID, callerRole)
+	if err != nil {
+		writeUserSessionsError(w, r, err)
+		return
+	}
+
+	currentID, _ := GetSessionID(r.Context())
+	writeJSON(w, http.StatusOK, ToSessionListResponse(sessions, currentID))
+}
+
+// RevokeSessions handles DELETE /users/{id}/sessions.
+//
+// @Summary      Revoke a user's sessions
+// @Description  Manager+ signs a user out of all their devices in the current tenant. Cannot manage users with a role equal to or higher than your own.
+// @Tags         users
+// @Security     BearerAuth
+// @Param        id   path  string  true  "User ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /users/{id}/sessions [delete]
+func (h *UserHandler) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	h.revokeSessions(w, r, uuid.Nil)
+}
+
+// RevokeSession handles DELETE /users/{id}/sessions/{sessionId}.
+//
+// @Summary      Revoke one of a user's sessions
+// @Description  Manager+ signs a user out of a specific device in the current tenant. Cannot manage users with a role equal to or higher than your own.
+// @Tags         users
+// @Security     BearerAuth
+// @Param        id         path  string  true  "User ID"
+// @Param        sessionId  path  string  true  "Session ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /users/{id}/sessions/{sessionId} [delete]
+func (h *UserHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(chi.URLParam(r, "sessionId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid session ID format")
+		return
+	}
+	h.revokeSessions(w, r, sessionID)
+}
+
+// revokeSessions revokes one session, or all of the user's sessions in the
+// tenant when sessionID is uuid.Nil.
+func (h *UserHandler) revokeSessions(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	callerRole, ok := GetRole(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	callerID, _ := GetUserID(r.Context())
+	tenantID, _ := GetTenantID(r.Context())
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid user ID format")
+		return
+	}
+
+	revokeReq := service.RevokeUserSessionsRequest{
+		UserID:    userID,
+		TenantID:  tenantID,
+		SessionID: sessionID,
+		RevokedBy: callerID,
+		IPAddress: GetClientIP(r),
+	}
+
+	if err := h.userService.RevokeUserSessions(r.Context(), revokeReq, callerRole); err != nil {
+		writeUserSessionsError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeUserSessionsError maps errors from the admin session endpoints.
+func writeUserSessionsError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotInTenant):
+		writeError(w, http.StatusNotFound, "not_found", "User not found in this tenant")
+	case errors.Is(err, domain.ErrSessionNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "Session not found")
+	case errors.Is(err, domain.ErrCannotManageRole):
+		writeError(w, http.StatusForbidden, "insufficient_role", "Cannot manage users with this role")
+	default:
+		writeInternalError(w, r, err)
+	}
+}
+
+// RemoveFromTenant handles DELETE /users/{id}/tenants/{tenantId}.
+//
+// @Summary      Remove a user from a tenant
+// @Description  Manager+ removes a user from the current tenant and signs them out of it. The account and the user's other tenants are not affected. Users may remove themselves; removing anyone else requires a role higher than theirs. The last owner of a tenant cannot be removed.
+// @Tags         users
+// @Security     BearerAuth
+// @Param        id        path  string  true  "User ID"
+// @Param        tenantId  path  string  true  "Tenant ID (must be the current tenant)"
+// @Success      204  "No Content"
+// @Failure      400  {object}  ErrorResponse "invalid_id"
+// @Failure      403  {object}  ErrorResponse "forbidden or insufficient_role"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Failure      409  {object}  ErrorResponse "last_owner"
+// @Router       /users/{id}/tenants/{tenantId} [delete]
+func (h *UserHandler) RemoveFromTenant(w http.ResponseWriter, r *http.Request) {
+	callerRole, ok := GetRole(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	callerID, _ := GetUserID(r.Context())
+	callerTenantID, _ := GetTenantID(r.Context())
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid user ID format")
+		return
+	}
+
+	tenantID, err := uuid.Parse(chi.URLParam(r, "tenantId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "Invalid tenant ID format")
+		return
+	}
+
+	// The caller's role only applies to the tenant they are signed in to
+	if tenantID != callerTenantID {
+		writeError(w, http.StatusForbidden, "forbidden", "Users can only be removed from the current tenant")
+		return
+	}
+
+	removeReq := service.RemoveFromTenantRequest{
+		UserID:    userID,
+		TenantID:  tenantID,
+		RemovedBy: callerID,
+		IPAddress: GetClientIP(r),
+	}
+
+	if err := h.userService.RemoveFromTenant(r.Context(), removeReq, callerRole); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUserNotInTenant):
+			writeError(w, http.StatusNotFound, "not_found", "User not found in this tenant")
+		case errors.Is(err, domain.ErrCannotManageRole):
+			writeError(w, http.StatusForbidden, "insufficient_role", "Cannot manage users with this role")
+		case errors.Is(err, domain.ErrLastOwner):
+			writeError(w, http.StatusConflict, "last_owner", "Cannot remove the last owner of a tenant")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// StartOwnershipTransfer handles POST /users/ownership-transfer.
+//
+// @Summary      Start an ownership transfer
+// @Description  Owner offers the owner role to another active member of the current tenant. The recipient is emailed a confirmation link (72-hour TTL, single use); nothing changes until they confirm it with their password, at which point the two members' roles are swapped. Only one transfer can be pending per tenant.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      StartOwnershipTransferRequest  true  "Recipient"
+// @Success      201      {object}  OwnershipTransferResponse
+// @Failure      400      {object}  ErrorResponse "invalid_request, invalid_target"
+// @Failure      403      {object}  ErrorResponse "insufficient_role"
+// @Failure      409      {object}  ErrorResponse "transfer_pending"
+// @Router       /users/ownership-transfer [post]
+func (h *UserHandler) StartOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+
+	var req StartOwnershipTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.UserID == uuid.Nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "User ID is required")
+		return
+	}
+
+	resp, err := h.userService.StartOwnershipTransfer(r.Context(), service.StartOwnershipTransferRequest{
+		TenantID:   tenantID,
+		FromUserID: callerID,
+		ToUserID:   req.UserID,
+		IPAddress:  GetClientIP(r),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInsufficientRole), errors.Is(err, domain.ErrUserNotInTenant):
+			writeError(w, http.StatusForbidden, "insufficient_role", "Only the owner can transfer ownership")
+		case errors.Is(err, domain.ErrOwnershipTransferTarget):
+			writeError(w, http.StatusBadRequest, "invalid_target", "Ownership can only be transferred to another active member of this tenant")
+		case errors.Is(err, domain.ErrOwnershipTransferPending):
+			writeError(w, http.StatusConflict, "transfer_pending", "An ownership transfer is already pending; cancel it first")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, ToOwnershipTransferResponse(resp.Transfer))
+}
+
+// CancelOwnershipTransfer handles DELETE /users/ownership-transfer.
+//
+// @Summary      Cancel the pending ownership transfer
+// @Description  Owner withdraws the current tenant's pending ownership transfer; its confirmation link stops working.
+// @Tags         users
+// @Security     BearerAuth
+// @Success      204  "No Content"
+// @Failure      403  {object}  ErrorResponse "insufficient_role"
+// @Failure      404  {object}  ErrorResponse "not_found"
+// @Router       /users/ownership-transfer [delete]
+func (h *UserHandler) CancelOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	tenantID, _ := GetTenantID(r.Context())
+
+	err := h.userService.CancelOwnershipTransfer(r.Context(), service.CancelOwnershipTransferRequest{
+		TenantID:    tenantID,
+		CancelledBy: callerID,
+		IPAddress:   GetClientIP(r),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrOwnershipTransferNotFound) {
+			writeError(w, http.StatusNotFound, "not_found", "No ownership transfer is pending")
+			return
+		}
+		writeInternalError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
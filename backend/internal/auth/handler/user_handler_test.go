@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -28,6 +29,9 @@ func setupUserHandler(t *testing.T) (*UserHandler, *mock.MockUserRepository, *mo
 		SessionRepo:   mock.NewMockSessionRepository(),
 		EventRepo:     mock.NewMockAuthEventRepository(),
 		PasswordReset: mock.NewMockPasswordResetRepository(),
+		Invitations:   mock.NewMockInvitationRepository(),
+		Transfers:     mock.NewMockOwnershipTransferRepository(),
+		EmailTokens:   mock.NewMockEmailTokenRepository(),
 	})
 
 	return NewUserHandler(userSvc), userRepo, roleRepo
@@ -49,145 +53,182 @@ func withChiURLParam(req *http.Request, key, value string) *http.Request {
 	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
 }
 
-func TestUserHandler_Create_Success(t *testing.T) {
+// inviteRequest builds a POST /users/invitations request as the given caller.
+func inviteRequest(body interface{}, tenantID uuid.UUID, role domain.Role) *http.Request {
+	b, _ := json.Marshal(body)
+	return httptest.NewRequest("POST", "/users/invitations", bytes.NewReader(b)).WithContext(authedContext(uuid.New(), tenantID, role))
+}
+
+func TestUserHandler_Invite_Success(t *testing.T) {
 	h, _, _ := setupUserHandler(t)
 
-	tenantID := uuid.New()
-	body, _ := json.Marshal(CreateUserRequest{Email: "new@example.com", FirstName: "New", LastName: "Hire", Role: domain.RoleWaiter})
-	req := httptest.NewRequest("POST", "/users", bytes.NewReader(body)).WithContext(authedContext(uuid.New(), tenantID, domain.RoleManager))
 	w := httptest.NewRecorder()
-
-	h.Create(w, req)
+	h.Invite(w, inviteRequest(InviteUserRequest{Email: "new@example.com", FirstName: "New", LastName: "Hire", Role: domain.RoleWaiter}, uuid.New(), domain.RoleManager))
 
 	if w.Code != http.StatusCreated {
 		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
 	}
-	var resp CreateUserResponse
+	if strings.Contains(w.Body.String(), "token") {
+		t.Errorf("response must never include the invite token: %s", w.Body.String())
+	}
+	var resp InvitationResponse
 	json.NewDecoder(w.Body).Decode(&resp)
-	if resp.Email != "new@example.com" {
+	if resp.Email != "new@example.com" || resp.Role != string(domain.RoleWaiter) || resp.ExistingAccount {
 		t.Errorf("unexpected response: %+v", resp)
 	}
-	if strings.Contains(w.Body.String(), "temporary_password") {
-		t.Errorf("response must never include the temporary password: %s", w.Body.String())
-	}
 }
 
-func TestUserHandler_Create_Unauthorized(t *testing.T) {
-	h, _, _ := setupUserHandler(t)
+func TestUserHandler_Invite_ExistingAccount(t *testing.T) {
+	h, userRepo, _ := setupUserHandler(t)
+	existingID := uuid.New()
+	userRepo.AddUser(&domain.User{
+		ID: existingID, Email: "existing@example.com",
+		TenantRoles: []domain.UserTenantRole{{UserID: existingID, TenantID: uuid.New(), Role: domain.RoleWaiter}},
+	})
 
-	body, _ := json.Marshal(CreateUserRequest{Email: "x@example.com", FirstName: "X", LastName: "Y", Role: domain.RoleWaiter})
-	req := httptest.NewRequest("POST", "/users", bytes.NewReader(body))
 	w := httptest.NewRecorder()
+	h.Invite(w, inviteRequest(InviteUserRequest{Email: "existing@example.com", FirstName: "X", LastName: "Y", Role: domain.RoleManager}, uuid.New(), domain.RoleAdmin))
 
-	h.Create(w, req)
-
-	if w.Code != http.StatusUnauthorized {
-		t.Errorf("Status = %d, want %d", w.Code, http.StatusUnauthorized)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
 	}
-}
-
-func TestUserHandler_Create_MissingFields(t *testing.T) {
-	h, _, _ := setupUserHandler(t)
-
-	body, _ := json.Marshal(CreateUserRequest{Role: domain.RoleWaiter})
-	req := httptest.NewRequest("POST", "/users", bytes.NewReader(body)).WithContext(authedContext(uuid.New(), uuid.New(), domain.RoleManager))
-	w := httptest.NewRecorder()
-
-	h.Create(w, req)
-
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+	var resp InvitationResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if !resp.ExistingAccount {
+		t.Errorf("expected existing_account=true, got %+v", resp)
 	}
 }
 
-func TestUserHandler_Create_InvalidRole(t *testing.T) {
-	h, _, _ := setupUserHandler(t)
-
-	body, _ := json.Marshal(CreateUserRequest{Email: "x@example.com", FirstName: "X", LastName: "Y", Role: domain.Role("not-a-role")})
-	req := httptest.NewRequest("POST", "/users", bytes.NewReader(body)).WithContext(authedContext(uuid.New(), uuid.New(), domain.RoleManager))
-	w := httptest.NewRecorder()
-
-	h.Create(w, req)
-
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+func TestUserHandler_Invite_Errors(t *testing.T) {
+	tenantID := uuid.New()
+	valid := InviteUserRequest{Email: "x@example.com", FirstName: "X", LastName: "Y", Role: domain.RoleWaiter}
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "unauthenticated",
+			req:        httptest.NewRequest("POST", "/users/invitations", strings.NewReader(`{}`)),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "unauthorized",
+		},
+		{
+			name:       "invalid body",
+			req:        httptest.NewRequest("POST", "/users/invitations", strings.NewReader("invalid json")).WithContext(authedContext(uuid.New(), tenantID, domain.RoleManager)),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+		{
+			name:       "missing fields",
+			req:        inviteRequest(InviteUserRequest{Role: domain.RoleWaiter}, tenantID, domain.RoleManager),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+		{
+			name:       "invalid role",
+			req:        inviteRequest(InviteUserRequest{Email: "x@example.com", FirstName: "X", LastName: "Y", Role: domain.Role("not-a-role")}, tenantID, domain.RoleManager),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_role",
+		},
+		{
+			// A waiter cannot invite another waiter (must outrank the assigned role).
+			name:       "cannot assign role",
+			req:        inviteRequest(valid, tenantID, domain.RoleWaiter),
+			wantStatus: http.StatusForbidden,
+			wantCode:   "insufficient_role",
+		},
+		{
+			name:       "already a member",
+			req:        inviteRequest(InviteUserRequest{Email: "dup@example.com", FirstName: "X", LastName: "Y", Role: domain.RoleWaiter}, tenantID, domain.RoleManager),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "email_exists",
+		},
+		{
+			name:       "already invited",
+			req:        inviteRequest(InviteUserRequest{Email: "pending@example.com", FirstName: "X", LastName: "Y", Role: domain.RoleWaiter}, tenantID, domain.RoleManager),
+			wantStatus: http.StatusConflict,
+			wantCode:   "invitation_pending",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, userRepo, _ := setupUserHandler(t)
+			existingID := uuid.New()
+			userRepo.AddUser(&domain.User{
+				ID: existingID, Email: "dup@example.com",
+				TenantRoles: []domain.UserTenantRole{{UserID: existingID, TenantID: tenantID, Role: domain.RoleWaiter}},
+			})
+			h.Invite(httptest.NewRecorder(), inviteRequest(InviteUserRequest{Email: "pending@example.com", FirstName: "X", LastName: "Y", Role: domain.RoleWaiter}, tenantID, domain.RoleManager))
+
+			w := httptest.NewRecorder()
+			h.Invite(w, tt.req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var errResp ErrorResponse
+			json.NewDecoder(w.Body).Decode(&errResp)
+			if errResp.Error.Code != tt.wantCode {
+				t.Errorf("error code = %q, want %q", errResp.Error.Code, tt.wantCode)
+			}
+		})
 	}
 }
 
-func TestUserHandler_Create_InvalidBody(t *testing.T) {
+func TestUserHandler_Invitations_Lifecycle(t *testing.T) {
 	h, _, _ := setupUserHandler(t)
+	tenantID := uuid.New()
+	ctx := authedContext(uuid.New(), tenantID, domain.RoleManager)
 
-	req := httptest.NewRequest("POST", "/users", bytes.NewReader([]byte("invalid json"))).WithContext(authedContext(uuid.New(), uuid.New(), domain.RoleManager))
 	w := httptest.NewRecorder()
-
-	h.Create(w, req)
-
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+	h.Invite(w, inviteRequest(InviteUserRequest{Email: "new@example.com", FirstName: "New", LastName: "Hire", Role: domain.RoleCashier}, tenantID, domain.RoleManager))
+	var invited InvitationResponse
+	json.NewDecoder(w.Body).Decode(&invited)
+
+	list := func() InvitationListResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ListInvitations(w, httptest.NewRequest("GET", "/users/invitations", nil).WithContext(ctx))
+		if w.Code != http.StatusOK {
+			t.Fatalf("list status = %d, want %d", w.Code, http.StatusOK)
+		}
+		var resp InvitationListResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp
+	}
+	if got := list(); len(got.Data) != 1 || got.Data[0].ID != invited.ID {
+		t.Fatalf("list = %+v, want the new invitation", got.Data)
+	}
+
+	w = httptest.NewRecorder()
+	h.ResendInvitation(w, withChiURLParam(httptest.NewRequest("POST", "/users/invitations/"+invited.ID.String()+"/resend", nil).WithContext(ctx), "id", invited.ID.String()))
+	if w.Code != http.StatusOK {
+		t.Fatalf("resend status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
 	}
-}
 
-func TestUserHandler_Create_CannotAssignRole(t *testing.T) {
-	h, _, _ := setupUserHandler(t)
-
-	// A waiter cannot create another waiter (must outrank the assigned role).
-	body, _ := json.Marshal(CreateUserRequest{Email: "x@example.com", FirstName: "X", LastName: "Y", Role: domain.RoleWaiter})
-	req := httptest.NewRequest("POST", "/users", bytes.NewReader(body)).WithContext(authedContext(uuid.New(), uuid.New(), domain.RoleWaiter))
-	w := httptest.NewRecorder()
-
-	h.Create(w, req)
-
-	if w.Code != http.StatusForbidden {
-		t.Errorf("Status = %d, want %d, body=%s", w.Code, http.StatusForbidden, w.Body.String())
+	w = httptest.NewRecorder()
+	h.RevokeInvitation(w, withChiURLParam(httptest.NewRequest("DELETE", "/users/invitations/"+invited.ID.String(), nil).WithContext(ctx), "id", invited.ID.String()))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("revoke status = %d, want %d, body=%s", w.Code, http.StatusNoContent, w.Body.String())
 	}
-}
-
-func TestUserHandler_Create_EmailExistsInSameTenant(t *testing.T) {
-	h, userRepo, _ := setupUserHandler(t)
-	tenantID := uuid.New()
-	existingID := uuid.New()
-	userRepo.AddUser(&domain.User{
-		ID: existingID, Email: "dup@example.com",
-		TenantRoles: []domain.UserTenantRole{{UserID: existingID, TenantID: tenantID, Role: domain.RoleWaiter}},
-	})
-
-	body, _ := json.Marshal(CreateUserRequest{Email: "dup@example.com", FirstName: "X", LastName: "Y", Role: domain.RoleWaiter})
-	req := httptest.NewRequest("POST", "/users", bytes.NewReader(body)).WithContext(authedContext(uuid.New(), tenantID, domain.RoleManager))
-	w := httptest.NewRecorder()
-
-	h.Create(w, req)
-
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+	if got := list(); len(got.Data) != 0 {
+		t.Errorf("revoked invitation should not be listed, got %+v", got.Data)
 	}
-}
-
-func TestUserHandler_Create_LinksExistingUserToNewTenant(t *testing.T) {
-	h, userRepo, _ := setupUserHandler(t)
-	existingID := uuid.New()
-	otherTenantID := uuid.New()
-	userRepo.AddUser(&domain.User{
-		ID: existingID, Email: "existing@example.com",
-		TenantRoles: []domain.UserTenantRole{{UserID: existingID, TenantID: otherTenantID, Role: domain.RoleWaiter}},
-	})
 
-	newTenantID := uuid.New()
-	body, _ := json.Marshal(CreateUserRequest{Email: "existing@example.com", FirstName: "X", LastName: "Y", Role: domain.RoleManager})
-	req := httptest.NewRequest("POST", "/users", bytes.NewReader(body)).WithContext(authedContext(uuid.New(), newTenantID, domain.RoleAdmin))
-	w := httptest.NewRecorder()
-
-	h.Create(w, req)
-
-	if w.Code != http.StatusOK {
-		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
-	}
-	var resp CreateUserResponse
-	json.NewDecoder(w.Body).Decode(&resp)
-	if !resp.LinkedExistingAccount {
-		t.Errorf("expected linked_existing_account=true, got %+v", resp)
+	// Already revoked, or not an ID at all
+	w = httptest.NewRecorder()
+	h.RevokeInvitation(w, withChiURLParam(httptest.NewRequest("DELETE", "/users/invitations/"+invited.ID.String(), nil).WithContext(ctx), "id", invited.ID.String()))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second revoke status = %d, want %d", w.Code, http.StatusNotFound)
 	}
-	if strings.Contains(w.Body.String(), "temporary_password") {
-		t.Errorf("linked response must never include a temporary password: %s", w.Body.String())
+	w = httptest.NewRecorder()
+	h.ResendInvitation(w, withChiURLParam(httptest.NewRequest("POST", "/users/invitations/not-a-uuid/resend", nil).WithContext(ctx), "id", "not-a-uuid"))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid id status = %d, want %d", w.Code, http.StatusBadRequest)
 	}
 }
 
@@ -472,6 +513,27 @@ func TestUserHandler_UpdateRole_InvalidBody(t *testing.T) {
 	}
 }
 
+func TestUserHandler_UpdateRole_UnknownCustomRole(t *testing.T) {
+	h, userRepo, roleRepo := setupUserHandler(t)
+
+	tenantID := uuid.New()
+	user := &domain.User{ID: uuid.New(), Email: "role@example.com"}
+	userRepo.AddUser(user)
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: user.ID, TenantID: tenantID, Role: domain.RoleWaiter})
+
+	customRoleID := uuid.New()
+	body, _ := json.Marshal(UpdateRoleRequest{CustomRoleID: &customRoleID})
+	req := httptest.NewRequest("PATCH", "/users/"+user.ID.String()+"/role", bytes.NewReader(body)).WithContext(authedContext(uuid.New(), tenantID, domain.RoleManager))
+	req = withChiURLParam(req, "id", user.ID.String())
+	w := httptest.NewRecorder()
+
+	h.UpdateRole(w, req)
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_role") {
+		t.Errorf("Status = %d, body=%s; want 400 invalid_role", w.Code, w.Body.String())
+	}
+}
+
 func TestUserHandler_UpdateRole_InvalidRole(t *testing.T) {
 	h, _, _ := setupUserHandler(t)
 
@@ -504,3 +566,328 @@ func TestUserHandler_UpdateRole_NotInTenant(t *testing.T) {
 		t.Errorf("Status = %d, want %d, body=%s", w.Code, http.StatusNotFound, w.Body.String())
 	}
 }
+
+func elevationRequest(method, userID string, body interface{}, tenantID uuid.UUID, role domain.Role) *http.Request {
+	var raw []byte
+	switch b := body.(type) {
+	case string:
+		raw = []byte(b)
+	case nil:
+	default:
+		raw, _ = json.Marshal(b)
+	}
+	req := httptest.NewRequest(method, "/users/"+userID+"/role/elevation", bytes.NewReader(raw)).WithContext(authedContext(uuid.New(), tenantID, role))
+	return withChiURLParam(req, "id", userID)
+}
+
+func TestUserHandler_ElevateRole(t *testing.T) {
+	h, userRepo, roleRepo := setupUserHandler(t)
+
+	tenantID := uuid.New()
+	user := &domain.User{ID: uuid.New(), Email: "waiter@example.com"}
+	userRepo.AddUser(user)
+	assignment := &domain.UserTenantRole{ID: uuid.New(), UserID: user.ID, TenantID: tenantID, Role: domain.RoleWaiter}
+	roleRepo.AddRole(assignment)
+
+	w := httptest.NewRecorder()
+	h.ElevateRole(w, elevationRequest("POST", user.ID.String(), ElevateRoleRequest{
+		Role:       domain.RoleCashier,
+		ValidUntil: time.Now().Add(8 * time.Hour),
+	}, tenantID, domain.RoleManager))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if assignment.Role != domain.RoleWaiter || assignment.EffectiveRole() != domain.RoleCashier {
+		t.Errorf("assignment = %s elevated to %s, want waiter elevated to cashier", assignment.Role, assignment.EffectiveRole())
+	}
+
+	w = httptest.NewRecorder()
+	h.EndElevation(w, elevationRequest("DELETE", user.ID.String(), nil, tenantID, domain.RoleManager))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("EndElevation status = %d, want %d, body=%s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+	if assignment.HasElevation() {
+		t.Error("EndElevation should clear the elevation")
+	}
+
+	w = httptest.NewRecorder()
+	h.EndElevation(w, elevationRequest("DELETE", user.ID.String(), nil, tenantID, domain.RoleManager))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "no_elevation") {
+		t.Errorf("EndElevation without an elevation: status = %d, body=%s; want 404 no_elevation", w.Code, w.Body.String())
+	}
+}
+
+func TestUserHandler_ElevateRole_Errors(t *testing.T) {
+	h, userRepo, roleRepo := setupUserHandler(t)
+
+	tenantID := uuid.New()
+	user := &domain.User{ID: uuid.New(), Email: "waiter@example.com"}
+	userRepo.AddUser(user)
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: user.ID, TenantID: tenantID, Role: domain.RoleWaiter})
+	later := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		userID     string
+		body       interface{}
+		role       domain.Role
+		wantStatus int
+		wantCode   string
+	}{
+		{"invalid id", "not-a-uuid", ElevateRoleRequest{Role: domain.RoleCashier, ValidUntil: later}, domain.RoleManager, http.StatusBadRequest, "invalid_id"},
+		{"invalid body", user.ID.String(), "invalid json", domain.RoleManager, http.StatusBadRequest, "invalid_request"},
+		{"invalid role", user.ID.String(), ElevateRoleRequest{Role: "bogus", ValidUntil: later}, domain.RoleManager, http.StatusBadRequest, "invalid_role"},
+		{"no end", user.ID.String(), ElevateRoleRequest{Role: domain.RoleCashier}, domain.RoleManager, http.StatusBadRequest, "invalid_window"},
+		{"demotion", user.ID.String(), ElevateRoleRequest{Role: domain.RoleViewer, ValidUntil: later}, domain.RoleManager, http.StatusBadRequest, "not_an_elevation"},
+		{"above caller", user.ID.String(), ElevateRoleRequest{Role: domain.RoleManager, ValidUntil: later}, domain.RoleManager, http.StatusForbidden, "insufficient_role"},
+		{"not in tenant", uuid.New().String(), ElevateRoleRequest{Role: domain.RoleCashier, ValidUntil: later}, domain.RoleManager, http.StatusNotFound, "not_found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ElevateRole(w, elevationRequest("POST", tt.userID, tt.body, tenantID, tt.role))
+
+			if w.Code != tt.wantStatus || !strings.Contains(w.Body.String(), tt.wantCode) {
+				t.Errorf("Status = %d, body=%s; want %d %s", w.Code, w.Body.String(), tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("POST", "/users/"+user.ID.String()+"/role/elevation", bytes.NewReader([]byte("{}")))
+	w := httptest.NewRecorder()
+	h.ElevateRole(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Unauthenticated status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestUserHandler_ListSessions(t *testing.T) {
+	h, _, roleRepo := setupUserHandler(t)
+
+	tenantID := uuid.New()
+	waiterID := uuid.New()
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: waiterID, TenantID: tenantID, Role: domain.RoleWaiter})
+
+	req := httptest.NewRequest("GET", "/users/"+waiterID.String()+"/sessions", nil).WithContext(authedContext(uuid.New(), tenantID, domain.RoleManager))
+	req = withChiURLParam(req, "id", waiterID.String())
+	w := httptest.NewRecorder()
+
+	h.ListSessions(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp SessionListResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Data == nil {
+		t.Error("Data should be an empty list, not null")
+	}
+}
+
+func TestUserHandler_SessionEndpoints_Errors(t *testing.T) {
+	h, _, roleRepo := setupUserHandler(t)
+
+	tenantID := uuid.New()
+	peerID := uuid.New()
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: peerID, TenantID: tenantID, Role: domain.RoleManager})
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{"invalid id", "not-a-uuid", http.StatusBadRequest},
+		{"not in tenant", uuid.New().String(), http.StatusNotFound},
+		{"cannot manage peer", peerID.String(), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		for name, fn := range map[string]http.HandlerFunc{"list": h.ListSessions, "revoke": h.RevokeSessions} {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				req := httptest.NewRequest("GET", "/users/"+tt.id+"/sessions", nil).WithContext(authedContext(uuid.New(), tenantID, domain.RoleManager))
+				req = withChiURLParam(req, "id", tt.id)
+				w := httptest.NewRecorder()
+
+				fn(w, req)
+
+				if w.Code != tt.wantCode {
+					t.Errorf("Status = %d, want %d, body=%s", w.Code, tt.wantCode, w.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func TestUserHandler_RevokeSessions(t *testing.T) {
+	h, _, roleRepo := setupUserHandler(t)
+
+	tenantID := uuid.New()
+	waiterID := uuid.New()
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: waiterID, TenantID: tenantID, Role: domain.RoleWaiter})
+
+	req := httptest.NewRequest("DELETE", "/users/"+waiterID.String()+"/sessions", nil).WithContext(authedContext(uuid.New(), tenantID, domain.RoleManager))
+	req = withChiURLParam(req, "id", waiterID.String())
+	w := httptest.NewRecorder()
+
+	h.RevokeSessions(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Status = %d, want %d, body=%s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+}
+
+func TestUserHandler_RevokeSession(t *testing.T) {
+	h, _, roleRepo := setupUserHandler(t)
+
+	tenantID := uuid.New()
+	waiterID := uuid.New()
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: waiterID, TenantID: tenantID, Role: domain.RoleWaiter})
+
+	tests := []struct {
+		name      string
+		sessionID string
+		wantCode  int
+	}{
+		{"invalid session id", "not-a-uuid", http.StatusBadRequest},
+		{"unknown session", uuid.New().String(), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", "/users/"+waiterID.String()+"/sessions/"+tt.sessionID, nil).WithContext(authedContext(uuid.New(), tenantID, domain.RoleManager))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", waiterID.String())
+			rctx.URLParams.Add("sessionId", tt.sessionID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			h.RevokeSession(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("Status = %d, want %d, body=%s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUserHandler_RemoveFromTenant(t *testing.T) {
+	h, _, roleRepo := setupUserHandler(t)
+
+	tenantID := uuid.New()
+	ownerID := uuid.New()
+	waiterID := uuid.New()
+	peerID := uuid.New()
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: ownerID, TenantID: tenantID, Role: domain.RoleOwner})
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: waiterID, TenantID: tenantID, Role: domain.RoleWaiter})
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: peerID, TenantID: tenantID, Role: domain.RoleManager})
+
+	tests := []struct {
+		name       string
+		callerID   uuid.UUID
+		callerRole domain.Role
+		id         string
+		tenantID   string
+		wantCode   int
+	}{
+		{"invalid id", uuid.New(), domain.RoleManager, "not-a-uuid", tenantID.String(), http.StatusBadRequest},
+		{"invalid tenant id", uuid.New(), domain.RoleManager, waiterID.String(), "not-a-uuid", http.StatusBadRequest},
+		{"other tenant", uuid.New(), domain.RoleManager, waiterID.String(), uuid.New().String(), http.StatusForbidden},
+		{"not in tenant", uuid.New(), domain.RoleManager, uuid.New().String(), tenantID.String(), http.StatusNotFound},
+		{"cannot manage peer", uuid.New(), domain.RoleManager, peerID.String(), tenantID.String(), http.StatusForbidden},
+		{"last owner", ownerID, domain.RoleOwner, ownerID.String(), tenantID.String(), http.StatusConflict},
+		{"success", uuid.New(), domain.RoleManager, waiterID.String(), tenantID.String(), http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", "/users/"+tt.id+"/tenants/"+tt.tenantID, nil).WithContext(authedContext(tt.callerID, tenantID, tt.callerRole))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			rctx.URLParams.Add("tenantId", tt.tenantID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			h.RemoveFromTenant(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("Status = %d, want %d, body=%s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUserHandler_StartOwnershipTransfer(t *testing.T) {
+	h, userRepo, roleRepo := setupUserHandler(t)
+
+	tenantID, ownerID, managerID := uuid.New(), uuid.New(), uuid.New()
+	userRepo.AddUser(&domain.User{ID: managerID, Email: "manager@example.com", IsActive: true})
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: ownerID, TenantID: tenantID, Role: domain.RoleOwner})
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: managerID, TenantID: tenantID, Role: domain.RoleManager})
+
+	tests := []struct {
+		name     string
+		callerID uuid.UUID
+		body     string
+		wantCode int
+		wantErr  string
+	}{
+		{"invalid body", ownerID, "invalid json", http.StatusBadRequest, "invalid_request"},
+		{"missing user", ownerID, `{}`, http.StatusBadRequest, "invalid_request"},
+		{"not a member", ownerID, `{"user_id":"` + uuid.New().String() + `"}`, http.StatusBadRequest, "invalid_target"},
+		{"caller not owner", managerID, `{"user_id":"` + ownerID.String() + `"}`, http.StatusForbidden, "insufficient_role"},
+		{"success", ownerID, `{"user_id":"` + managerID.String() + `"}`, http.StatusCreated, ""},
+		{"already pending", ownerID, `{"user_id":"` + managerID.String() + `"}`, http.StatusConflict, "transfer_pending"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/users/ownership-transfer", strings.NewReader(tt.body)).WithContext(authedContext(tt.callerID, tenantID, domain.RoleOwner))
+			w := httptest.NewRecorder()
+
+			h.StartOwnershipTransfer(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("Status = %d, want %d, body=%s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantErr != "" {
+				var errResp ErrorResponse
+				json.NewDecoder(w.Body).Decode(&errResp)
+				if errResp.Error.Code != tt.wantErr {
+					t.Errorf("error code = %q, want %q", errResp.Error.Code, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestUserHandler_CancelOwnershipTransfer(t *testing.T) {
+	h, userRepo, roleRepo := setupUserHandler(t)
+
+	tenantID, ownerID, managerID := uuid.New(), uuid.New(), uuid.New()
+	userRepo.AddUser(&domain.User{ID: managerID, Email: "manager@example.com", IsActive: true})
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: ownerID, TenantID: tenantID, Role: domain.RoleOwner})
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: managerID, TenantID: tenantID, Role: domain.RoleManager})
+
+	cancel := func() int {
+		req := httptest.NewRequest("DELETE", "/users/ownership-transfer", nil).WithContext(authedContext(ownerID, tenantID, domain.RoleOwner))
+		w := httptest.NewRecorder()
+		h.CancelOwnershipTransfer(w, req)
+		return w.Code
+	}
+
+	if code := cancel(); code != http.StatusNotFound {
+		t.Errorf("cancel with nothing pending = %d, want %d", code, http.StatusNotFound)
+	}
+
+	req := httptest.NewRequest("POST", "/users/ownership-transfer", strings.NewReader(`{"user_id":"`+managerID.String()+`"}`)).WithContext(authedContext(ownerID, tenantID, domain.RoleOwner))
+	w := httptest.NewRecorder()
+	h.StartOwnershipTransfer(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("start status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	if code := cancel(); code != http.StatusNoContent {
+		t.Errorf("cancel = %d, want %d", code, http.StatusNoContent)
+	}
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"sort"
+
+	"github.com/alexedwards/argon2id"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/service"
+	"gorm.io/gorm"
+)
+
+// HashReportEntry counts the users whose password hashes share parameters
+// and pepper version.
+type HashReportEntry struct {
+	Params        argon2id.Params
+	PepperVersion int
+	// Current is true if these hashes match the configured parameters and
+	// pepper. Others are upgraded as their users log in.
+	Current bool
+	Users   int
+}
+
+// HashReport is the distribution of password hash parameters across users,
+// used to tell when outdated parameters or retired peppers are no longer in
+// use.
+type HashReport struct {
+	Entries []HashReportEntry
+	// Unreadable counts hashes that couldn't be parsed.
+	Unreadable int
+}
+
+// PasswordHashReport reads every user's password hash and groups them by
+// how they were made, most common first.
+func PasswordHashReport(ctx context.Context, db *gorm.DB, passwords *service.PasswordService) (*HashReport, error) {
+	type key struct {
+		params        argon2id.Params
+		pepperVersion int
+	}
+	counts := make(map[key]*HashReportEntry)
+	report := &HashReport{}
+
+	var users []domain.User
+	result := db.WithContext(ctx).Select("id", "password_hash").FindInBatches(&users, 500, func(tx *gorm.DB, batch int) error {
+		for _, u := range users {
+			info, err := passwords.Inspect(u.PasswordHash)
+			if err != nil {
+				report.Unreadable++
+				continue
+			}
+			k := key{params: info.Params, pepperVersion: info.PepperVersion}
+			entry, ok := counts[k]
+			if !ok {
+				entry = &HashReportEntry{Params: info.Params, PepperVersion: info.PepperVersion, Current: info.Current}
+				counts[k] = entry
+			}
+			entry.Users++
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, entry := range counts {
+		report.Entries = append(report.Entries, *entry)
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		if report.Entries[i].Users != report.Entries[j].Users {
+			return report.Entries[i].Users > report.Entries[j].Users
+		}
+		return report.Entries[i].PepperVersion > report.Entries[j].PepperVersion
+	})
+	return report, nil
+}
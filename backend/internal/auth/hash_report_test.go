@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/service"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestPasswordHashReport(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE users (id TEXT PRIMARY KEY, password_hash TEXT NOT NULL)`).Error; err != nil {
+		t.Fatalf("failed to create users table: %v", err)
+	}
+
+	pepper := service.PasswordPepper{Version: 1, Secret: bytes.Repeat([]byte{1}, 32)}
+	passwords, err := service.NewPasswordServiceWithConfig(service.PasswordHashingConfig{Peppers: []service.PasswordPepper{pepper}})
+	if err != nil {
+		t.Fatalf("NewPasswordServiceWithConfig: %v", err)
+	}
+	current, _ := passwords.Hash("Password123!")
+	outdated, _ := service.NewPasswordService().Hash("Password123!")
+	for _, hash := range []string{current, current, outdated, "garbage"} {
+		if err := db.Exec(`INSERT INTO users (id, password_hash) VALUES (?, ?)`, uuid.New().String(), hash).Error; err != nil {
+			t.Fatalf("failed to insert user: %v", err)
+		}
+	}
+
+	report, err := PasswordHashReport(context.Background(), db, passwords)
+	if err != nil {
+		t.Fatalf("PasswordHashReport: %v", err)
+	}
+	if len(report.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", report.Entries)
+	}
+	if e := report.Entries[0]; e.Users != 2 || e.PepperVersion != 1 || !e.Current {
+		t.Errorf("expected 2 current pepper-1 hashes first, got %+v", e)
+	}
+	if e := report.Entries[1]; e.Users != 1 || e.PepperVersion != 0 || e.Current {
+		t.Errorf("expected 1 outdated unpeppered hash, got %+v", e)
+	}
+	if e := report.Entries[1]; e.Params != *service.DefaultPasswordHashParams() {
+		t.Errorf("expected default params, got %+v", e.Params)
+	}
+	if report.Unreadable != 1 {
+		t.Errorf("expected 1 unreadable hash, got %d", report.Unreadable)
+	}
+}
@@ -1,32 +1,78 @@
 package auth
 
 import (
+	"slices"
+
 	"github.com/solobueno/erp/internal/auth/domain"
 	"gorm.io/gorm"
 )
 
-// AutoMigrate runs GORM auto-migration for all auth domain models.
-// This is intended for development use. For production, use explicit SQL migrations.
-func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+// models lists every auth domain model, each after the models it has
+// foreign keys to.
+func models() []interface{} {
+	return []interface{}{
 		&domain.User{},
 		&domain.Tenant{},
+		&domain.CustomRole{},
 		&domain.UserTenantRole{},
 		&domain.Session{},
 		&domain.PasswordResetToken{},
+		&domain.EmailToken{},
+		&domain.PasswordHistoryEntry{},
+		&domain.PasswordPolicy{},
+		&domain.LockoutPolicy{},
+		&domain.KnownDevice{},
 		&domain.AuthEvent{},
-	)
+		&domain.MFAFactor{},
+		&domain.MFARecoveryCode{},
+		&domain.MFAChallenge{},
+		&domain.RevokedAccessToken{},
+		&domain.UserTokenRevocation{},
+		&domain.Terminal{},
+		&domain.StaffPIN{},
+		&domain.Invitation{},
+		&domain.OwnershipTransfer{},
+		&domain.Approval{},
+		&domain.TenantSSOConfig{},
+		&domain.SSOLoginState{},
+		&domain.UserIdentity{},
+		&domain.SCIMToken{},
+		&domain.SCIMUserLink{},
+		&domain.ServiceAccount{},
+		&domain.APIKey{},
+		&domain.OAuthClient{},
+		&domain.OAuthConsent{},
+		&domain.OAuthAuthorizationCode{},
+		&domain.OAuthRefreshToken{},
+		&domain.Passkey{},
+		&domain.PasskeyChallenge{},
+	}
+}
+
+// AutoMigrate runs GORM auto-migration for all auth domain models.
+// This is intended for development use. For production, use explicit SQL migrations.
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(models()...)
 }
 
 // DropAll drops all auth-related tables.
 // WARNING: This is destructive and should only be used in development/testing.
 func DropAll(db *gorm.DB) error {
-	return db.Migrator().DropTable(
-		&domain.AuthEvent{},
-		&domain.PasswordResetToken{},
-		&domain.Session{},
-		&domain.UserTenantRole{},
-		&domain.Tenant{},
-		&domain.User{},
-	)
+	all := models()
+	slices.Reverse(all)
+	return db.Migrator().DropTable(all...)
+}
+
+// TableNames returns the names of the auth tables, in the order
+// AutoMigrate creates them.
+func TableNames(db *gorm.DB) ([]string, error) {
+	names := make([]string, 0, len(models()))
+	for _, model := range models() {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		names = append(names, stmt.Schema.Table)
+	}
+	return names, nil
 }
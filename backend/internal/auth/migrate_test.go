@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestTableNames(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	names, err := TableNames(db)
+	if err != nil {
+		t.Fatalf("TableNames: %v", err)
+	}
+	if len(names) != len(models()) {
+		t.Fatalf("got %d names for %d models", len(names), len(models()))
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			t.Errorf("table %q listed twice", name)
+		}
+		seen[name] = true
+	}
+	for _, want := range []string{"users", "tenants", "known_devices", "tenant_sso_configs", "oauth_refresh_tokens", "passkeys"} {
+		if !seen[want] {
+			t.Errorf("missing table %q", want)
+		}
+	}
+	if names[0] != "users" {
+		t.Errorf("first table = %q, want users", names[0])
+	}
+}
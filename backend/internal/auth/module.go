@@ -1,19 +1,41 @@
 package auth
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/handler"
 	"github.com/solobueno/erp/internal/auth/repository"
 	"github.com/solobueno/erp/internal/auth/service"
 	"github.com/solobueno/erp/pkg/jwt"
+	"github.com/solobueno/erp/pkg/webauthn"
 	"gorm.io/gorm"
 )
 
 // Module represents the auth module with all its components.
 type Module struct {
-	AuthService *service.AuthService
-	UserService *service.UserService
-	AuthRouter  chi.Router
-	UserRouter  chi.Router
+	AuthService           *service.AuthService
+	UserService           *service.UserService
+	MFAService            *service.MFAService
+	PINService            *service.PINService
+	RoleService           *service.RoleService
+	ApprovalService       *service.ApprovalService
+	SSOService            *service.SSOService
+	SCIMService           *service.SCIMService
+	ServiceAccountService *service.ServiceAccountService
+	OAuthService          *service.OAuthService
+	PasskeyService        *service.PasskeyService
+	PasswordPolicyService *service.PasswordPolicyService
+	LockoutService        *service.LockoutService
+	AuthRouter            chi.Router
+	UserRouter            chi.Router
+	RoleRouter            chi.Router
+	SCIMRouter            chi.Router
+	ServiceAccountRouter  chi.Router
+	OAuthRouter           chi.Router
+	JWKSHandler           *handler.JWKSHandler
 }
 
 // ModuleConfig holds configuration for the auth module.
@@ -21,6 +43,23 @@ type ModuleConfig struct {
 	DB         *gorm.DB
 	KeyManager *jwt.KeyManager
 	JWTConfig  jwt.TokenGeneratorConfig
+
+	// SSORedirectURL is the frontend page identity providers send users back
+	// to after an SSO login. Tenant admins register it with their provider.
+	SSORedirectURL string
+	// SSOHTTPClient is used to talk to identity providers. Defaults to a
+	// client with a short timeout.
+	SSOHTTPClient *http.Client
+	// WebAuthn identifies the server to passkey authenticators: the domain
+	// passkeys are scoped to and the origins the frontend is served from.
+	// Without an RPID, passkey endpoints return 503.
+	WebAuthn webauthn.Config
+	// BreachedPasswords lists passwords no tenant's members can use.
+	// Defaults to the list bundled with the module.
+	BreachedPasswords service.BreachedPasswords
+	// PasswordHashing sets the Argon2id parameters and peppers for password
+	// hashes. Hashes made with other settings are upgraded at login.
+	PasswordHashing service.PasswordHashingConfig
 }
 
 // NewModule creates and initializes the auth module.
@@ -32,6 +71,39 @@ func NewModule(cfg ModuleConfig) (*Module, error) {
 	tenantRepo := repository.NewGormTenantRepository(cfg.DB)
 	roleRepo := repository.NewGormUserTenantRoleRepository(cfg.DB)
 	passwordResetRepo := repository.NewGormPasswordResetRepository(cfg.DB)
+	invitationRepo := repository.NewGormInvitationRepository(cfg.DB)
+	transferRepo := repository.NewGormOwnershipTransferRepository(cfg.DB)
+	customRoleRepo := repository.NewGormCustomRoleRepository(cfg.DB)
+	mfaRepo := repository.NewGormMFARepository(cfg.DB)
+	mfaChallengeRepo := repository.NewGormMFAChallengeRepository(cfg.DB)
+	revocationStore := repository.NewGormTokenRevocationStore(cfg.DB)
+	staffPINRepo := repository.NewGormStaffPINRepository(cfg.DB)
+	terminalRepo := repository.NewGormTerminalRepository(cfg.DB)
+	approvalRepo := repository.NewGormApprovalRepository(cfg.DB)
+	ssoConfigRepo := repository.NewGormSSOConfigRepository(cfg.DB)
+	ssoStateRepo := repository.NewGormSSOLoginStateRepository(cfg.DB)
+	identityRepo := repository.NewGormUserIdentityRepository(cfg.DB)
+	scimTokenRepo := repository.NewGormSCIMTokenRepository(cfg.DB)
+	scimLinkRepo := repository.NewGormSCIMUserLinkRepository(cfg.DB)
+	serviceAccountRepo := repository.NewGormServiceAccountRepository(cfg.DB)
+	apiKeyRepo := repository.NewGormAPIKeyRepository(cfg.DB)
+	oauthClientRepo := repository.NewGormOAuthClientRepository(cfg.DB)
+	oauthConsentRepo := repository.NewGormOAuthConsentRepository(cfg.DB)
+	oauthCodeRepo := repository.NewGormOAuthCodeRepository(cfg.DB)
+	oauthRefreshTokenRepo := repository.NewGormOAuthRefreshTokenRepository(cfg.DB)
+	passkeyRepo := repository.NewGormPasskeyRepository(cfg.DB)
+	passkeyChallengeRepo := repository.NewGormPasskeyChallengeRepository(cfg.DB)
+	emailTokenRepo := repository.NewGormEmailTokenRepository(cfg.DB)
+	passwordPolicyRepo := repository.NewGormPasswordPolicyRepository(cfg.DB)
+	passwordHistoryRepo := repository.NewGormPasswordHistoryRepository(cfg.DB)
+	lockoutPolicyRepo := repository.NewGormLockoutPolicyRepository(cfg.DB)
+	knownDeviceRepo := repository.NewGormKnownDeviceRepository(cfg.DB)
+
+	// Create password hashing, shared by every service that handles passwords
+	passwords, err := service.NewPasswordServiceWithConfig(cfg.PasswordHashing)
+	if err != nil {
+		return nil, fmt.Errorf("auth module: password hashing: %w", err)
+	}
 
 	// Create token service
 	tokenService := service.NewTokenService(cfg.KeyManager, cfg.JWTConfig)
@@ -39,16 +111,70 @@ func NewModule(cfg ModuleConfig) (*Module, error) {
 	// Create rate limiters
 	loginRateLimiter := service.NewMemoryRateLimiter(service.DefaultLoginRateLimiterConfig())
 	resetRateLimiter := service.NewMemoryRateLimiter(service.DefaultPasswordResetRateLimiterConfig())
+	pinLoginRateLimiter := service.NewMemoryRateLimiter(service.DefaultPINLoginRateLimiterConfig())
+	approvalRateLimiter := service.NewMemoryRateLimiter(service.DefaultApprovalRateLimiterConfig())
+
+	// Email delivery (logging stub until a real provider is wired in)
+	emailer := service.NewLogEmailer()
 
 	// Create services
+	mfaService := service.NewMFAService(service.MFAServiceConfig{
+		MFARepo:       mfaRepo,
+		ChallengeRepo: mfaChallengeRepo,
+		EventRepo:     eventRepo,
+		Passkeys:      passkeyRepo,
+	})
+
+	serviceAccountService := service.NewServiceAccountService(service.ServiceAccountServiceConfig{
+		Accounts:  serviceAccountRepo,
+		APIKeys:   apiKeyRepo,
+		EventRepo: eventRepo,
+	})
+
+	oauthService := service.NewOAuthService(service.OAuthServiceConfig{
+		Clients:         oauthClientRepo,
+		Consents:        oauthConsentRepo,
+		Codes:           oauthCodeRepo,
+		RefreshTokens:   oauthRefreshTokenRepo,
+		UserRepo:        userRepo,
+		EventRepo:       eventRepo,
+		TokenService:    tokenService,
+		RevocationStore: revocationStore,
+	})
+
+	passwordPolicyService := service.NewPasswordPolicyService(service.PasswordPolicyServiceConfig{
+		Policies:  passwordPolicyRepo,
+		History:   passwordHistoryRepo,
+		RoleRepo:  roleRepo,
+		EventRepo: eventRepo,
+		Breached:  cfg.BreachedPasswords,
+		Passwords: passwords,
+	})
+
+	lockoutService := service.NewLockoutService(service.LockoutServiceConfig{
+		Policies:  lockoutPolicyRepo,
+		Devices:   knownDeviceRepo,
+		RoleRepo:  roleRepo,
+		EventRepo: eventRepo,
+	})
+
 	authService := service.NewAuthService(service.AuthServiceConfig{
-		UserRepo:     userRepo,
-		SessionRepo:  sessionRepo,
-		EventRepo:    eventRepo,
-		TenantRepo:   tenantRepo,
-		RoleRepo:     roleRepo,
-		TokenService: tokenService,
-		RateLimiter:  loginRateLimiter,
+		UserRepo:         userRepo,
+		SessionRepo:      sessionRepo,
+		EventRepo:        eventRepo,
+		TenantRepo:       tenantRepo,
+		RoleRepo:         roleRepo,
+		TokenService:     tokenService,
+		RateLimiter:      loginRateLimiter,
+		MFAService:       mfaService,
+		Emailer:          emailer,
+		RevocationStore:  revocationStore,
+		SSOConfigs:       ssoConfigRepo,
+		ServiceAccounts:  serviceAccountService,
+		OAuth:            oauthService,
+		PasswordPolicies: passwordPolicyService,
+		Lockout:          lockoutService,
+		Passwords:        passwords,
 	})
 
 	userService := service.NewUserService(service.UserServiceConfig{
@@ -57,18 +183,112 @@ func NewModule(cfg ModuleConfig) (*Module, error) {
 		SessionRepo:      sessionRepo,
 		EventRepo:        eventRepo,
 		PasswordReset:    passwordResetRepo,
+		Invitations:      invitationRepo,
+		Transfers:        transferRepo,
 		ResetRateLimiter: resetRateLimiter,
+		Emailer:          emailer,
+		RevocationStore:  revocationStore,
+		AccessTokenTTL:   cfg.JWTConfig.AccessTokenTTL,
+		CustomRoles:      customRoleRepo,
+		EmailTokens:      emailTokenRepo,
+		PasswordPolicies: passwordPolicyService,
+		Passwords:        passwords,
+	})
+
+	roleService := service.NewRoleService(service.RoleServiceConfig{
+		CustomRoles:     customRoleRepo,
+		RoleRepo:        roleRepo,
+		EventRepo:       eventRepo,
+		RevocationStore: revocationStore,
+		AccessTokenTTL:  cfg.JWTConfig.AccessTokenTTL,
+	})
+
+	pinService := service.NewPINService(service.PINServiceConfig{
+		PINRepo:      staffPINRepo,
+		TerminalRepo: terminalRepo,
+		UserRepo:     userRepo,
+		TenantRepo:   tenantRepo,
+		EventRepo:    eventRepo,
+		TokenService: tokenService,
+		RateLimiter:  pinLoginRateLimiter,
+		Passwords:    passwords,
+	})
+
+	approvalService := service.NewApprovalService(service.ApprovalServiceConfig{
+		Approvals:   approvalRepo,
+		UserRepo:    userRepo,
+		EventRepo:   eventRepo,
+		PINService:  pinService,
+		RateLimiter: approvalRateLimiter,
+		Lockout:     lockoutService,
+		Passwords:   passwords,
+		AuthService: authService,
+	})
+
+	ssoService := service.NewSSOService(service.SSOServiceConfig{
+		Configs:     ssoConfigRepo,
+		States:      ssoStateRepo,
+		Identities:  identityRepo,
+		TenantRepo:  tenantRepo,
+		UserRepo:    userRepo,
+		EventRepo:   eventRepo,
+		AuthService: authService,
+		RedirectURL: cfg.SSORedirectURL,
+		HTTPClient:  cfg.SSOHTTPClient,
+	})
+
+	scimService := service.NewSCIMService(service.SCIMServiceConfig{
+		Tokens:      scimTokenRepo,
+		Links:       scimLinkRepo,
+		UserRepo:    userRepo,
+		RoleRepo:    roleRepo,
+		EventRepo:   eventRepo,
+		UserService: userService,
+		CustomRoles: customRoleRepo,
+		Passwords:   passwords,
+	})
+
+	passkeyService := service.NewPasskeyService(service.PasskeyServiceConfig{
+		Passkeys:    passkeyRepo,
+		Challenges:  passkeyChallengeRepo,
+		UserRepo:    userRepo,
+		TenantRepo:  tenantRepo,
+		EventRepo:   eventRepo,
+		AuthService: authService,
+		MFAService:  mfaService,
+		RateLimiter: loginRateLimiter,
+		WebAuthn:    cfg.WebAuthn,
 	})
 
 	// Create routers
-	authRouter := Router(authService, userService)
+	authRouter := Router(authService, userService, mfaService, pinService, approvalService, ssoService, scimService, passkeyService, passwordPolicyService, lockoutService)
 	userRouter := UserRouter(authService, userService)
+	roleRouter := RoleRouter(authService, roleService)
+	scimRouter := SCIMRouter(scimService)
+	serviceAccountRouter := ServiceAccountRouter(authService, serviceAccountService)
+	oauthRouter := OAuthRouter(authService, oauthService)
 
 	return &Module{
-		AuthService: authService,
-		UserService: userService,
-		AuthRouter:  authRouter,
-		UserRouter:  userRouter,
+		AuthService:           authService,
+		UserService:           userService,
+		MFAService:            mfaService,
+		PINService:            pinService,
+		RoleService:           roleService,
+		ApprovalService:       approvalService,
+		SSOService:            ssoService,
+		SCIMService:           scimService,
+		ServiceAccountService: serviceAccountService,
+		OAuthService:          oauthService,
+		PasskeyService:        passkeyService,
+		PasswordPolicyService: passwordPolicyService,
+		LockoutService:        lockoutService,
+		AuthRouter:            authRouter,
+		UserRouter:            userRouter,
+		RoleRouter:            roleRouter,
+		SCIMRouter:            scimRouter,
+		ServiceAccountRouter:  serviceAccountRouter,
+		OAuthRouter:           oauthRouter,
+		JWKSHandler:           handler.NewJWKSHandler(tokenService),
 	}, nil
 }
 
@@ -76,4 +296,17 @@ func NewModule(cfg ModuleConfig) (*Module, error) {
 func (m *Module) RegisterRoutes(r chi.Router) {
 	r.Mount("/api/v1/auth", m.AuthRouter)
 	r.Mount("/api/v1/users", m.UserRouter)
+	r.Mount("/api/v1/roles", m.RoleRouter)
+	r.Mount("/api/v1/service-accounts", m.ServiceAccountRouter)
+	r.Mount("/api/v1/oauth", m.OAuthRouter)
+	r.Mount("/scim/v2", m.SCIMRouter)
+	r.Get("/.well-known/jwks.json", m.JWKSHandler.ServeHTTP)
+}
+
+// RequireApproval returns middleware that requires a manager's step-up
+// approval for action on the resource named by the resourceParam URL
+// parameter, e.g. r.With(authModule.RequireApproval(payments.PermRefund,
+// "id")).Post("/payments/{id}/refund", ...). It must run after RequireAuth.
+func (m *Module) RequireApproval(action domain.Permission, resourceParam string) func(http.Handler) http.Handler {
+	return handler.RequireApproval(m.ApprovalService, action, resourceParam)
 }
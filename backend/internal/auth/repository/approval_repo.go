@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// ApprovalRepository defines the interface for step-up approval data access.
+type ApprovalRepository interface {
+	// Create creates a new approval.
+	Create(ctx context.Context, approval *domain.Approval) error
+
+	// FindByToken retrieves an approval by its token hash.
+	FindByToken(ctx context.Context, tokenHash string) (*domain.Approval, error)
+
+	// MarkUsed marks an unused approval as used. It returns
+	// ErrApprovalUsed if the approval was already used, so two concurrent
+	// requests can't both spend the same approval.
+	MarkUsed(ctx context.Context, id uuid.UUID) error
+}
+
+// GormApprovalRepository is a GORM implementation of ApprovalRepository.
+type GormApprovalRepository struct {
+	db *gorm.DB
+}
+
+// NewGormApprovalRepository creates a new GormApprovalRepository.
+func NewGormApprovalRepository(db *gorm.DB) *GormApprovalRepository {
+	return &GormApprovalRepository{db: db}
+}
+
+// Create creates a new approval.
+func (r *GormApprovalRepository) Create(ctx context.Context, approval *domain.Approval) error {
+	if approval.ID == uuid.Nil {
+		approval.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(approval).Error
+}
+
+// FindByToken retrieves an approval by its token hash.
+func (r *GormApprovalRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.Approval, error) {
+	var approval domain.Approval
+	if err := r.db.WithContext(ctx).First(&approval, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrApprovalInvalid
+		}
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// MarkUsed marks an unused approval as used.
+func (r *GormApprovalRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Approval{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrApprovalUsed
+	}
+	return nil
+}
+
+// Ensure GormApprovalRepository implements ApprovalRepository
+var _ ApprovalRepository = (*GormApprovalRepository)(nil)
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// CustomRoleRepository defines the interface for tenant custom role data access.
+type CustomRoleRepository interface {
+	// Create creates a new custom role.
+	Create(ctx context.Context, role *domain.CustomRole) error
+
+	// FindByID retrieves a tenant's custom role by ID.
+	FindByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.CustomRole, error)
+
+	// FindByName retrieves a tenant's custom role by name, ignoring case.
+	FindByName(ctx context.Context, tenantID uuid.UUID, name string) (*domain.CustomRole, error)
+
+	// ListByTenant lists a tenant's custom roles, ordered by name.
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.CustomRole, error)
+
+	// Update updates a custom role.
+	Update(ctx context.Context, role *domain.CustomRole) error
+
+	// Delete deletes a tenant's custom role.
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+}
+
+// GormCustomRoleRepository is a GORM implementation of CustomRoleRepository.
+type GormCustomRoleRepository struct {
+	db *gorm.DB
+}
+
+// NewGormCustomRoleRepository creates a new GormCustomRoleRepository.
+func NewGormCustomRoleRepository(db *gorm.DB) *GormCustomRoleRepository {
+	return &GormCustomRoleRepository{db: db}
+}
+
+// Create creates a new custom role.
+func (r *GormCustomRoleRepository) Create(ctx context.Context, role *domain.CustomRole) error {
+	if role.ID == uuid.Nil {
+		role.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(role).Error
+}
+
+// FindByID retrieves a tenant's custom role by ID.
+func (r *GormCustomRoleRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.CustomRole, error) {
+	var role domain.CustomRole
+	if err := r.db.WithContext(ctx).First(&role, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrCustomRoleNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// FindByName retrieves a tenant's custom role by name, ignoring case.
+func (r *GormCustomRoleRepository) FindByName(ctx context.Context, tenantID uuid.UUID, name string) (*domain.CustomRole, error) {
+	var role domain.CustomRole
+	if err := r.db.WithContext(ctx).First(&role, "tenant_id = ? AND LOWER(name) = LOWER(?)", tenantID, name).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrCustomRoleNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// ListByTenant lists a tenant's custom roles, ordered by name.
+func (r *GormCustomRoleRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.CustomRole, error) {
+	var roles []*domain.CustomRole
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("name ASC").
+		Find(&roles).Error
+	return roles, err
+}
+
+// Update updates a custom role.
+func (r *GormCustomRoleRepository) Update(ctx context.Context, role *domain.CustomRole) error {
+	return r.db.WithContext(ctx).Save(role).Error
+}
+
+// Delete deletes a tenant's custom role.
+func (r *GormCustomRoleRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&domain.CustomRole{}, "id = ? AND tenant_id = ?", id, tenantID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrCustomRoleNotFound
+	}
+	return nil
+}
+
+// Ensure GormCustomRoleRepository implements CustomRoleRepository
+var _ CustomRoleRepository = (*GormCustomRoleRepository)(nil)
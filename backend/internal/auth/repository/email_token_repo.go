@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// EmailTokenRepository defines the interface for email verification, change
+// and revert token data access.
+type EmailTokenRepository interface {
+	// Create creates a new email token.
+	Create(ctx context.Context, token *domain.EmailToken) error
+
+	// FindByToken retrieves an email token by its hash.
+	FindByToken(ctx context.Context, tokenHash string) (*domain.EmailToken, error)
+
+	// MarkUsed marks an email token as used. Returns ErrEmailTokenUsed if it
+	// already was, so a link raced by two requests only works once.
+	MarkUsed(ctx context.Context, id uuid.UUID) error
+
+	// DeleteForUser removes a user's tokens with the given purpose, so only
+	// the newest link works.
+	DeleteForUser(ctx context.Context, userID uuid.UUID, purpose domain.EmailTokenPurpose) error
+
+	// DeleteExpired removes all expired tokens.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// GormEmailTokenRepository is a GORM implementation of EmailTokenRepository.
+type GormEmailTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGormEmailTokenRepository creates a new GormEmailTokenRepository.
+func NewGormEmailTokenRepository(db *gorm.DB) *GormEmailTokenRepository {
+	return &GormEmailTokenRepository{db: db}
+}
+
+// Create creates a new email token.
+func (r *GormEmailTokenRepository) Create(ctx context.Context, token *domain.EmailToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// FindByToken retrieves an email token by its hash.
+func (r *GormEmailTokenRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.EmailToken, error) {
+	var token domain.EmailToken
+	if err := r.db.WithContext(ctx).First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrEmailTokenInvalid
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marks an email token as used.
+func (r *GormEmailTokenRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.EmailToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrEmailTokenUsed
+	}
+	return nil
+}
+
+// DeleteForUser removes a user's tokens with the given purpose.
+func (r *GormEmailTokenRepository) DeleteForUser(ctx context.Context, userID uuid.UUID, purpose domain.EmailTokenPurpose) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND purpose = ?", userID, purpose).
+		Delete(&domain.EmailToken{}).Error
+}
+
+// DeleteExpired removes all expired tokens.
+func (r *GormEmailTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now()).
+		Delete(&domain.EmailToken{})
+	return result.RowsAffected, result.Error
+}
+
+// Ensure GormEmailTokenRepository implements EmailTokenRepository
+var _ EmailTokenRepository = (*GormEmailTokenRepository)(nil)
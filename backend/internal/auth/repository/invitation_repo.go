@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// InvitationRepository defines the interface for user invitation data access.
+type InvitationRepository interface {
+	// Create creates a new invitation.
+	Create(ctx context.Context, invitation *domain.Invitation) error
+
+	// FindByID retrieves an invitation by ID.
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.Invitation, error)
+
+	// FindByToken retrieves an invitation by its token hash.
+	FindByToken(ctx context.Context, tokenHash string) (*domain.Invitation, error)
+
+	// FindPending retrieves the outstanding (not accepted, not revoked)
+	// invitation for an email in a tenant, if any.
+	FindPending(ctx context.Context, tenantID uuid.UUID, email string) (*domain.Invitation, error)
+
+	// ListPendingByTenant lists a tenant's outstanding invitations, including
+	// expired ones that can still be resent.
+	ListPendingByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.Invitation, error)
+
+	// Update updates an invitation (e.g. a new token and expiry on resend).
+	Update(ctx context.Context, invitation *domain.Invitation) error
+
+	// MarkAccepted marks an outstanding invitation as accepted.
+	MarkAccepted(ctx context.Context, id uuid.UUID) error
+
+	// Revoke revokes an outstanding invitation so its token stops working.
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+// GormInvitationRepository is a GORM implementation of InvitationRepository.
+type GormInvitationRepository struct {
+	db *gorm.DB
+}
+
+// NewGormInvitationRepository creates a new GormInvitationRepository.
+func NewGormInvitationRepository(db *gorm.DB) *GormInvitationRepository {
+	return &GormInvitationRepository{db: db}
+}
+
+// Create creates a new invitation.
+func (r *GormInvitationRepository) Create(ctx context.Context, invitation *domain.Invitation) error {
+	if invitation.ID == uuid.Nil {
+		invitation.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(invitation).Error
+}
+
+// FindByID retrieves an invitation by ID.
+func (r *GormInvitationRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	if err := r.db.WithContext(ctx).First(&invitation, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// FindByToken retrieves an invitation by its token hash.
+func (r *GormInvitationRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	if err := r.db.WithContext(ctx).First(&invitation, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInvitationInvalid
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// FindPending retrieves the outstanding invitation for an email in a tenant.
+func (r *GormInvitationRepository) FindPending(ctx context.Context, tenantID uuid.UUID, email string) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND email = ? AND accepted_at IS NULL AND revoked_at IS NULL", tenantID, email).
+		First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// ListPendingByTenant lists a tenant's outstanding invitations.
+func (r *GormInvitationRepository) ListPendingByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.Invitation, error) {
+	var invitations []*domain.Invitation
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND accepted_at IS NULL AND revoked_at IS NULL", tenantID).
+		Order("created_at ASC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// Update updates an invitation.
+func (r *GormInvitationRepository) Update(ctx context.Context, invitation *domain.Invitation) error {
+	return r.db.WithContext(ctx).Save(invitation).Error
+}
+
+// MarkAccepted marks an outstanding invitation as accepted.
+func (r *GormInvitationRepository) MarkAccepted(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("accepted_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInvitationAccepted
+	}
+	return nil
+}
+
+// Revoke revokes an outstanding invitation.
+func (r *GormInvitationRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInvitationNotFound
+	}
+	return nil
+}
+
+// Ensure GormInvitationRepository implements InvitationRepository
+var _ InvitationRepository = (*GormInvitationRepository)(nil)
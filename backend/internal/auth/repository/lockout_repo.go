@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// LockoutPolicyRepository defines the interface for tenant account lockout
+// policy data access.
+type LockoutPolicyRepository interface {
+	// Create creates a tenant's lockout policy.
+	Create(ctx context.Context, policy *domain.LockoutPolicy) error
+
+	// FindByTenant retrieves a tenant's lockout policy.
+	FindByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.LockoutPolicy, error)
+
+	// ListByTenants retrieves the lockout policies of the given tenants.
+	// Tenants without one are left out.
+	ListByTenants(ctx context.Context, tenantIDs []uuid.UUID) ([]*domain.LockoutPolicy, error)
+
+	// Update updates a tenant's lockout policy.
+	Update(ctx context.Context, policy *domain.LockoutPolicy) error
+}
+
+// GormLockoutPolicyRepository is a GORM implementation of LockoutPolicyRepository.
+type GormLockoutPolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewGormLockoutPolicyRepository creates a new GormLockoutPolicyRepository.
+func NewGormLockoutPolicyRepository(db *gorm.DB) *GormLockoutPolicyRepository {
+	return &GormLockoutPolicyRepository{db: db}
+}
+
+// Create creates a tenant's lockout policy.
+func (r *GormLockoutPolicyRepository) Create(ctx context.Context, policy *domain.LockoutPolicy) error {
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(policy).Error
+}
+
+// FindByTenant retrieves a tenant's lockout policy.
+func (r *GormLockoutPolicyRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.LockoutPolicy, error) {
+	var policy domain.LockoutPolicy
+	if err := r.db.WithContext(ctx).First(&policy, "tenant_id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrLockoutPolicyNotFound
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ListByTenants retrieves the lockout policies of the given tenants.
+func (r *GormLockoutPolicyRepository) ListByTenants(ctx context.Context, tenantIDs []uuid.UUID) ([]*domain.LockoutPolicy, error) {
+	var policies []*domain.LockoutPolicy
+	if len(tenantIDs) == 0 {
+		return policies, nil
+	}
+	err := r.db.WithContext(ctx).Where("tenant_id IN ?", tenantIDs).Find(&policies).Error
+	return policies, err
+}
+
+// Update updates a tenant's lockout policy.
+func (r *GormLockoutPolicyRepository) Update(ctx context.Context, policy *domain.LockoutPolicy) error {
+	return r.db.WithContext(ctx).Save(policy).Error
+}
+
+// Ensure GormLockoutPolicyRepository implements LockoutPolicyRepository
+var _ LockoutPolicyRepository = (*GormLockoutPolicyRepository)(nil)
+
+// KnownDeviceRepository defines the interface for the devices users have
+// logged in from.
+type KnownDeviceRepository interface {
+	// Create records a device a user has logged in from.
+	Create(ctx context.Context, device *domain.KnownDevice) error
+
+	// FindByToken retrieves a device by the hash of its device token.
+	FindByToken(ctx context.Context, tokenHash string) (*domain.KnownDevice, error)
+
+	// Touch records that a device was used to log in again.
+	Touch(ctx context.Context, id uuid.UUID, seenAt time.Time) error
+}
+
+// GormKnownDeviceRepository is a GORM implementation of KnownDeviceRepository.
+type GormKnownDeviceRepository struct {
+	db *gorm.DB
+}
+
+// NewGormKnownDeviceRepository creates a new GormKnownDeviceRepository.
+func NewGormKnownDeviceRepository(db *gorm.DB) *GormKnownDeviceRepository {
+	return &GormKnownDeviceRepository{db: db}
+}
+
+// Create records a device a user has logged in from.
+func (r *GormKnownDeviceRepository) Create(ctx context.Context, device *domain.KnownDevice) error {
+	if device.ID == uuid.Nil {
+		device.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(device).Error
+}
+
+// FindByToken retrieves a device by the hash of its device token.
+func (r *GormKnownDeviceRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.KnownDevice, error) {
+	var device domain.KnownDevice
+	if err := r.db.WithContext(ctx).First(&device, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrKnownDeviceNotFound
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+// Touch records that a device was used to log in again.
+func (r *GormKnownDeviceRepository) Touch(ctx context.Context, id uuid.UUID, seenAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.KnownDevice{}).Where("id = ?", id).Update("last_seen_at", seenAt).Error
+}
+
+// Ensure GormKnownDeviceRepository implements KnownDeviceRepository
+var _ KnownDeviceRepository = (*GormKnownDeviceRepository)(nil)
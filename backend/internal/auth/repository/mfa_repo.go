@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// MFARepository defines the interface for TOTP factor and recovery code data access.
+type MFARepository interface {
+	// FindFactorByUser retrieves a user's TOTP factor (pending or confirmed).
+	FindFactorByUser(ctx context.Context, userID uuid.UUID) (*domain.MFAFactor, error)
+
+	// SaveFactor stores a new factor for a user, replacing any existing one.
+	SaveFactor(ctx context.Context, factor *domain.MFAFactor) error
+
+	// UpdateFactor updates an existing factor.
+	UpdateFactor(ctx context.Context, factor *domain.MFAFactor) error
+
+	// AdvanceStep records step as the factor's last accepted TOTP time step
+	// if it is later than the one stored, returning ErrMFACodeInvalid if it
+	// isn't (the code was already used, possibly by a concurrent request).
+	AdvanceStep(ctx context.Context, factorID uuid.UUID, step int64) error
+
+	// DeleteForUser removes a user's factor and all of their recovery codes.
+	DeleteForUser(ctx context.Context, userID uuid.UUID) error
+
+	// ReplaceRecoveryCodes atomically swaps a user's recovery codes for a new set.
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*domain.MFARecoveryCode) error
+
+	// UseRecoveryCode marks an unused recovery code as used.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error
+
+	// CountUnusedRecoveryCodes counts the recovery codes a user has left.
+	CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+// GormMFARepository is a GORM implementation of MFARepository.
+type GormMFARepository struct {
+	db *gorm.DB
+}
+
+// NewGormMFARepository creates a new GormMFARepository.
+func NewGormMFARepository(db *gorm.DB) *GormMFARepository {
+	return &GormMFARepository{db: db}
+}
+
+// FindFactorByUser retrieves a user's TOTP factor.
+func (r *GormMFARepository) FindFactorByUser(ctx context.Context, userID uuid.UUID) (*domain.MFAFactor, error) {
+	var factor domain.MFAFactor
+	if err := r.db.WithContext(ctx).First(&factor, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+	return &factor, nil
+}
+
+// SaveFactor stores a new factor for a user, replacing any existing one.
+func (r *GormMFARepository) SaveFactor(ctx context.Context, factor *domain.MFAFactor) error {
+	if factor.ID == uuid.Nil {
+		factor.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", factor.UserID).Delete(&domain.MFAFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(factor).Error
+	})
+}
+
+// UpdateFactor updates an existing factor.
+func (r *GormMFARepository) UpdateFactor(ctx context.Context, factor *domain.MFAFactor) error {
+	return r.db.WithContext(ctx).Save(factor).Error
+}
+
+// AdvanceStep records step as the factor's last accepted TOTP time step if
+// it is later than the one stored.
+func (r *GormMFARepository) AdvanceStep(ctx context.Context, factorID uuid.UUID, step int64) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.MFAFactor{}).
+		Where("id = ? AND last_used_step < ?", factorID, step).
+		Update("last_used_step", step)
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrMFACodeInvalid
+	}
+	return nil
+}
+
+// DeleteForUser removes a user's factor and all of their recovery codes.
+func (r *GormMFARepository) DeleteForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&domain.MFAFactor{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes atomically swaps a user's recovery codes for a new set.
+func (r *GormMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*domain.MFARecoveryCode) error {
+	for _, c := range codes {
+		if c.ID == uuid.Nil {
+			c.ID = uuid.New()
+		}
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks an unused recovery code as used.
+func (r *GormMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrMFACodeInvalid
+	}
+	return nil
+}
+
+// CountUnusedRecoveryCodes counts the recovery codes a user has left.
+func (r *GormMFARepository) CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// Ensure GormMFARepository implements MFARepository
+var _ MFARepository = (*GormMFARepository)(nil)
+
+// MFAChallengeRepository defines the interface for pending login MFA challenges.
+type MFAChallengeRepository interface {
+	// Create creates a new MFA challenge.
+	Create(ctx context.Context, challenge *domain.MFAChallenge) error
+
+	// FindByToken retrieves a challenge by its token hash.
+	FindByToken(ctx context.Context, tokenHash string) (*domain.MFAChallenge, error)
+
+	// IncrementAttempts records a failed verification attempt against a challenge.
+	IncrementAttempts(ctx context.Context, id uuid.UUID) error
+
+	// MarkUsed marks a challenge as used.
+	MarkUsed(ctx context.Context, id uuid.UUID) error
+
+	// DeleteExpired removes all expired challenges.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// GormMFAChallengeRepository is a GORM implementation of MFAChallengeRepository.
+type GormMFAChallengeRepository struct {
+	db *gorm.DB
+}
+
+// NewGormMFAChallengeRepository creates a new GormMFAChallengeRepository.
+func NewGormMFAChallengeRepository(db *gorm.DB) *GormMFAChallengeRepository {
+	return &GormMFAChallengeRepository{db: db}
+}
+
+// Create creates a new MFA challenge.
+func (r *GormMFAChallengeRepository) Create(ctx context.Context, challenge *domain.MFAChallenge) error {
+	if challenge.ID == uuid.Nil {
+		challenge.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(challenge).Error
+}
+
+// FindByToken retrieves a challenge by its token hash.
+func (r *GormMFAChallengeRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.MFAChallenge, error) {
+	var challenge domain.MFAChallenge
+	if err := r.db.WithContext(ctx).First(&challenge, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrMFAChallengeInvalid
+		}
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// IncrementAttempts records a failed verification attempt against a challenge.
+func (r *GormMFAChallengeRepository) IncrementAttempts(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.MFAChallenge{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// MarkUsed marks a challenge as used.
+func (r *GormMFAChallengeRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrMFAChallengeInvalid
+	}
+	return nil
+}
+
+// DeleteExpired removes all expired challenges.
+func (r *GormMFAChallengeRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now()).
+		Delete(&domain.MFAChallenge{})
+	return result.RowsAffected, result.Error
+}
+
+// Ensure GormMFAChallengeRepository implements MFAChallengeRepository
+var _ MFAChallengeRepository = (*GormMFAChallengeRepository)(nil)
@@ -5,7 +5,11 @@
 package mock
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -190,8 +194,12 @@ type MockSessionRepository struct {
 	RevokeByTokenFunc            func(ctx context.Context, tokenHash string) error
 	RevokeAllForUserFunc         func(ctx context.Context, userID uuid.UUID) error
 	RevokeAllForUserInTenantFunc func(ctx context.Context, userID, tenantID uuid.UUID) error
+	RevokeAllForUserExceptFunc   func(ctx context.Context, userID, keepID uuid.UUID) error
+	ListActiveForUserFunc        func(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error)
 	DeleteExpiredFunc            func(ctx context.Context) (int64, error)
 	CountActiveForUserFunc       func(ctx context.Context, userID uuid.UUID) (int64, error)
+	ExistsByParentFunc           func(ctx context.Context, parentID uuid.UUID) (bool, error)
+	RevokeFamilyFunc             func(ctx context.Context, familyID uuid.UUID) (int64, error)
 }
 
 func NewMockSessionRepository() *MockSessionRepository {
@@ -206,6 +214,12 @@ func (m *MockSessionRepository) Create(ctx context.Context, session *domain.Sess
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if session.FamilyID == uuid.Nil {
+		session.FamilyID = session.ID
+	}
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
 	m.sessions[session.ID] = session
 	return nil
 }
@@ -291,6 +305,57 @@ func (m *MockSessionRepository) RevokeAllForUserInTenant(ctx context.Context, us
 	return nil
 }
 
+func (m *MockSessionRepository) RevokeForUserInTenantSince(ctx context.Context, userID, tenantID uuid.UUID, since time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for _, s := range m.sessions {
+		if s.UserID == userID && s.TenantID == tenantID && !s.CreatedAt.Before(since) && !s.IsRevoked() {
+			s.Revoke()
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MockSessionRepository) RevokeAllForUserExcept(ctx context.Context, userID, keepID uuid.UUID) error {
+	if m.RevokeAllForUserExceptFunc != nil {
+		return m.RevokeAllForUserExceptFunc(ctx, userID, keepID)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.sessions {
+		if s.UserID == userID && s.ID != keepID && s.RevokedAt == nil {
+			s.Revoke()
+		}
+	}
+	return nil
+}
+
+func (m *MockSessionRepository) ListActiveForUser(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+	if m.ListActiveForUserFunc != nil {
+		return m.ListActiveForUserFunc(ctx, userID)
+	}
+	return m.listActive(func(s *domain.Session) bool { return s.UserID == userID }), nil
+}
+
+func (m *MockSessionRepository) ListActiveForUserInTenant(ctx context.Context, userID, tenantID uuid.UUID) ([]*domain.Session, error) {
+	return m.listActive(func(s *domain.Session) bool { return s.UserID == userID && s.TenantID == tenantID }), nil
+}
+
+func (m *MockSessionRepository) listActive(match func(*domain.Session) bool) []*domain.Session {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.Session
+	for _, s := range m.sessions {
+		if match(s) && s.IsValid() {
+			result = append(result, s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result
+}
+
 func (m *MockSessionRepository) DeleteExpired(ctx context.Context) (int64, error) {
 	if m.DeleteExpiredFunc != nil {
 		return m.DeleteExpiredFunc(ctx)
@@ -314,6 +379,36 @@ func (m *MockSessionRepository) CountActiveForUser(ctx context.Context, userID u
 	return count, nil
 }
 
+func (m *MockSessionRepository) ExistsByParent(ctx context.Context, parentID uuid.UUID) (bool, error) {
+	if m.ExistsByParentFunc != nil {
+		return m.ExistsByParentFunc(ctx, parentID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.sessions {
+		if s.ParentID != nil && *s.ParentID == parentID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockSessionRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	if m.RevokeFamilyFunc != nil {
+		return m.RevokeFamilyFunc(ctx, familyID)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var count int64
+	for _, s := range m.sessions {
+		if s.FamilyID == familyID && s.RevokedAt == nil {
+			s.Revoke()
+			count++
+		}
+	}
+	return count, nil
+}
+
 var _ repository.SessionRepository = (*MockSessionRepository)(nil)
 
 // MockAuthEventRepository is a mock implementation of AuthEventRepository.
@@ -524,6 +619,13 @@ type MockUserTenantRoleRepository struct {
 	FindByUserAndTenantFunc func(ctx context.Context, userID, tenantID uuid.UUID) (*domain.UserTenantRole, error)
 	CreateFunc              func(ctx context.Context, role *domain.UserTenantRole) error
 	UpdateFunc              func(ctx context.Context, role *domain.UserTenantRole) error
+
+	// UserLookup, if set, resolves the accounts behind role assignments for
+	// the *UnlessLastOwner methods, the way a join on users would: owners
+	// whose account is disabled don't count, and DeactivateUnlessLastOwner
+	// disables the account it returns. Without it every owner counts as
+	// active.
+	UserLookup func(userID uuid.UUID) *domain.User
 }
 
 func NewMockUserTenantRoleRepository() *MockUserTenantRoleRepository {
@@ -612,6 +714,122 @@ func (m *MockUserTenantRoleRepository) ListByTenant(ctx context.Context, tenantI
 	return result, nil
 }
 
+func (m *MockUserTenantRoleRepository) ListByCustomRole(ctx context.Context, customRoleID uuid.UUID) ([]*domain.UserTenantRole, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.UserTenantRole
+	for _, r := range m.roles {
+		if (r.CustomRoleID != nil && *r.CustomRoleID == customRoleID) ||
+			(r.ElevatedCustomRoleID != nil && *r.ElevatedCustomRoleID == customRoleID) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockUserTenantRoleRepository) ListDueElevations(ctx context.Context, now time.Time) ([]*domain.UserTenantRole, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.UserTenantRole
+	for _, r := range m.roles {
+		if !r.HasElevation() {
+			continue
+		}
+		ended := !now.Before(*r.ValidUntil)
+		started := r.ElevationStartedAt == nil && !now.Before(*r.ValidFrom)
+		if ended || started {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockUserTenantRoleRepository) TransferOwnership(ctx context.Context, tenantID, fromUserID, toUserID uuid.UUID) (domain.Role, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var from, to *domain.UserTenantRole
+	for _, r := range m.roles {
+		if r.TenantID != tenantID {
+			continue
+		}
+		switch r.UserID {
+		case fromUserID:
+			from = r
+		case toUserID:
+			to = r
+		}
+	}
+	if to == nil {
+		return "", domain.ErrUserNotInTenant
+	}
+	if from == nil || from.Role != domain.RoleOwner || to.Role == domain.RoleOwner {
+		return "", domain.ErrOwnershipTransferInvalid
+	}
+	previousRole := to.Role
+	from.Role, from.CustomRoleID, from.CustomRole = previousRole, to.CustomRoleID, to.CustomRole
+	to.Role, to.CustomRoleID, to.CustomRole = domain.RoleOwner, nil, nil
+	to.ClearElevation()
+	return previousRole, nil
+}
+
+func (m *MockUserTenantRoleRepository) UpdateUnlessLastOwner(ctx context.Context, role *domain.UserTenantRole) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.hasOtherActiveOwner(role.TenantID, role.UserID) {
+		return domain.ErrLastOwner
+	}
+	m.roles[role.ID] = role
+	return nil
+}
+
+func (m *MockUserTenantRoleRepository) DeleteUnlessLastOwner(ctx context.Context, userID, tenantID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.hasOtherActiveOwner(tenantID, userID) {
+		return domain.ErrLastOwner
+	}
+	for id, r := range m.roles {
+		if r.UserID == userID && r.TenantID == tenantID {
+			delete(m.roles, id)
+			return nil
+		}
+	}
+	return domain.ErrUserNotInTenant
+}
+
+func (m *MockUserTenantRoleRepository) DeactivateUnlessLastOwner(ctx context.Context, userID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range m.roles {
+		if r.UserID == userID && r.Role == domain.RoleOwner && !m.hasOtherActiveOwner(r.TenantID, userID) {
+			return domain.ErrLastOwner
+		}
+	}
+	if m.UserLookup != nil {
+		if u := m.UserLookup(userID); u != nil {
+			u.IsActive = false
+		}
+	}
+	return nil
+}
+
+// hasOtherActiveOwner reports whether an active user other than userID owns
+// tenantID. The caller must hold m.mu.
+func (m *MockUserTenantRoleRepository) hasOtherActiveOwner(tenantID, userID uuid.UUID) bool {
+	for _, r := range m.roles {
+		if r.TenantID != tenantID || r.UserID == userID || r.Role != domain.RoleOwner {
+			continue
+		}
+		if m.UserLookup == nil {
+			return true
+		}
+		if u := m.UserLookup(r.UserID); u == nil || u.IsActive {
+			return true
+		}
+	}
+	return false
+}
+
 // AddRole adds a role assignment to the mock repository.
 func (m *MockUserTenantRoleRepository) AddRole(role *domain.UserTenantRole) {
 	m.mu.Lock()
@@ -713,3 +931,1961 @@ func (m *MockPasswordResetRepository) AddToken(token *domain.PasswordResetToken)
 }
 
 var _ repository.PasswordResetRepository = (*MockPasswordResetRepository)(nil)
+
+// MockEmailTokenRepository is a mock implementation of EmailTokenRepository.
+type MockEmailTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[uuid.UUID]*domain.EmailToken
+}
+
+func NewMockEmailTokenRepository() *MockEmailTokenRepository {
+	return &MockEmailTokenRepository{
+		tokens: make(map[uuid.UUID]*domain.EmailToken),
+	}
+}
+
+func (m *MockEmailTokenRepository) Create(ctx context.Context, token *domain.EmailToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	m.tokens[token.ID] = token
+	return nil
+}
+
+func (m *MockEmailTokenRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.EmailToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrEmailTokenInvalid
+}
+
+func (m *MockEmailTokenRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tokens[id]
+	if !ok || t.UsedAt != nil {
+		return domain.ErrEmailTokenUsed
+	}
+	now := time.Now()
+	t.UsedAt = &now
+	return nil
+}
+
+func (m *MockEmailTokenRepository) DeleteForUser(ctx context.Context, userID uuid.UUID, purpose domain.EmailTokenPurpose) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, t := range m.tokens {
+		if t.UserID == userID && t.Purpose == purpose {
+			delete(m.tokens, id)
+		}
+	}
+	return nil
+}
+
+func (m *MockEmailTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	now := time.Now()
+	for id, t := range m.tokens {
+		if t.ExpiresAt.Before(now) {
+			delete(m.tokens, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// GetTokens returns a copy of all tokens, for assertions.
+func (m *MockEmailTokenRepository) GetTokens() []*domain.EmailToken {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tokens := make([]*domain.EmailToken, 0, len(m.tokens))
+	for _, t := range m.tokens {
+		copied := *t
+		tokens = append(tokens, &copied)
+	}
+	return tokens
+}
+
+var _ repository.EmailTokenRepository = (*MockEmailTokenRepository)(nil)
+
+// MockPasswordPolicyRepository is a mock implementation of PasswordPolicyRepository.
+type MockPasswordPolicyRepository struct {
+	mu       sync.RWMutex
+	policies map[uuid.UUID]*domain.PasswordPolicy // by tenant
+}
+
+func NewMockPasswordPolicyRepository() *MockPasswordPolicyRepository {
+	return &MockPasswordPolicyRepository{
+		policies: make(map[uuid.UUID]*domain.PasswordPolicy),
+	}
+}
+
+func (m *MockPasswordPolicyRepository) Create(ctx context.Context, policy *domain.PasswordPolicy) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+	m.policies[policy.TenantID] = policy
+	return nil
+}
+
+func (m *MockPasswordPolicyRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.PasswordPolicy, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if policy, ok := m.policies[tenantID]; ok {
+		copied := *policy
+		return &copied, nil
+	}
+	return nil, domain.ErrPasswordPolicyNotFound
+}
+
+func (m *MockPasswordPolicyRepository) ListByTenants(ctx context.Context, tenantIDs []uuid.UUID) ([]*domain.PasswordPolicy, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var policies []*domain.PasswordPolicy
+	for _, id := range tenantIDs {
+		if policy, ok := m.policies[id]; ok {
+			copied := *policy
+			policies = append(policies, &copied)
+		}
+	}
+	return policies, nil
+}
+
+func (m *MockPasswordPolicyRepository) Update(ctx context.Context, policy *domain.PasswordPolicy) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.policies[policy.TenantID] = policy
+	return nil
+}
+
+// AddPolicy adds a password policy to the mock repository.
+func (m *MockPasswordPolicyRepository) AddPolicy(policy *domain.PasswordPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+	m.policies[policy.TenantID] = policy
+}
+
+var _ repository.PasswordPolicyRepository = (*MockPasswordPolicyRepository)(nil)
+
+// MockPasswordHistoryRepository is a mock implementation of PasswordHistoryRepository.
+type MockPasswordHistoryRepository struct {
+	mu      sync.RWMutex
+	entries []*domain.PasswordHistoryEntry // Oldest first
+}
+
+func NewMockPasswordHistoryRepository() *MockPasswordHistoryRepository {
+	return &MockPasswordHistoryRepository{}
+}
+
+func (m *MockPasswordHistoryRepository) Create(ctx context.Context, entry *domain.PasswordHistoryEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *MockPasswordHistoryRepository) ListRecent(ctx context.Context, userID uuid.UUID, limit int) ([]*domain.PasswordHistoryEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var entries []*domain.PasswordHistoryEntry
+	for i := len(m.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		if m.entries[i].UserID == userID {
+			entries = append(entries, m.entries[i])
+		}
+	}
+	return entries, nil
+}
+
+func (m *MockPasswordHistoryRepository) Prune(ctx context.Context, userID uuid.UUID, keep int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := 0
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		if m.entries[i].UserID != userID {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+		m.entries = append(m.entries[:i], m.entries[i+1:]...)
+	}
+	return nil
+}
+
+// CountForUser returns how many history entries a user has, for assertions.
+func (m *MockPasswordHistoryRepository) CountForUser(userID uuid.UUID) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	count := 0
+	for _, e := range m.entries {
+		if e.UserID == userID {
+			count++
+		}
+	}
+	return count
+}
+
+var _ repository.PasswordHistoryRepository = (*MockPasswordHistoryRepository)(nil)
+
+// MockMFARepository is a mock implementation of MFARepository.
+type MockMFARepository struct {
+	mu            sync.RWMutex
+	factors       map[uuid.UUID]*domain.MFAFactor
+	recoveryCodes map[uuid.UUID][]*domain.MFARecoveryCode
+
+	FindFactorByUserFunc func(ctx context.Context, userID uuid.UUID) (*domain.MFAFactor, error)
+}
+
+func NewMockMFARepository() *MockMFARepository {
+	return &MockMFARepository{
+		factors:       make(map[uuid.UUID]*domain.MFAFactor),
+		recoveryCodes: make(map[uuid.UUID][]*domain.MFARecoveryCode),
+	}
+}
+
+func (m *MockMFARepository) FindFactorByUser(ctx context.Context, userID uuid.UUID) (*domain.MFAFactor, error) {
+	if m.FindFactorByUserFunc != nil {
+		return m.FindFactorByUserFunc(ctx, userID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if f, ok := m.factors[userID]; ok {
+		return f, nil
+	}
+	return nil, domain.ErrMFANotEnrolled
+}
+
+func (m *MockMFARepository) SaveFactor(ctx context.Context, factor *domain.MFAFactor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if factor.ID == uuid.Nil {
+		factor.ID = uuid.New()
+	}
+	m.factors[factor.UserID] = factor
+	return nil
+}
+
+func (m *MockMFARepository) UpdateFactor(ctx context.Context, factor *domain.MFAFactor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.factors[factor.UserID] = factor
+	return nil
+}
+
+func (m *MockMFARepository) AdvanceStep(ctx context.Context, factorID uuid.UUID, step int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, f := range m.factors {
+		if f.ID == factorID {
+			if f.LastUsedStep >= step {
+				return domain.ErrMFACodeInvalid
+			}
+			f.LastUsedStep = step
+			return nil
+		}
+	}
+	return domain.ErrMFACodeInvalid
+}
+
+func (m *MockMFARepository) DeleteForUser(ctx context.Context, userID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.factors, userID)
+	delete(m.recoveryCodes, userID)
+	return nil
+}
+
+func (m *MockMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*domain.MFARecoveryCode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range codes {
+		if c.ID == uuid.Nil {
+			c.ID = uuid.New()
+		}
+	}
+	m.recoveryCodes[userID] = codes
+	return nil
+}
+
+func (m *MockMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.recoveryCodes[userID] {
+		if c.CodeHash == codeHash && !c.IsUsed() {
+			c.MarkUsed()
+			return nil
+		}
+	}
+	return domain.ErrMFACodeInvalid
+}
+
+func (m *MockMFARepository) CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var count int64
+	for _, c := range m.recoveryCodes[userID] {
+		if !c.IsUsed() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// AddFactor adds a factor to the mock repository.
+func (m *MockMFARepository) AddFactor(factor *domain.MFAFactor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.factors[factor.UserID] = factor
+}
+
+var _ repository.MFARepository = (*MockMFARepository)(nil)
+
+// MockMFAChallengeRepository is a mock implementation of MFAChallengeRepository.
+type MockMFAChallengeRepository struct {
+	mu         sync.RWMutex
+	challenges map[uuid.UUID]*domain.MFAChallenge
+}
+
+func NewMockMFAChallengeRepository() *MockMFAChallengeRepository {
+	return &MockMFAChallengeRepository{
+		challenges: make(map[uuid.UUID]*domain.MFAChallenge),
+	}
+}
+
+func (m *MockMFAChallengeRepository) Create(ctx context.Context, challenge *domain.MFAChallenge) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if challenge.ID == uuid.Nil {
+		challenge.ID = uuid.New()
+	}
+	m.challenges[challenge.ID] = challenge
+	return nil
+}
+
+func (m *MockMFAChallengeRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.MFAChallenge, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, c := range m.challenges {
+		if c.TokenHash == tokenHash {
+			return c, nil
+		}
+	}
+	return nil, domain.ErrMFAChallengeInvalid
+}
+
+func (m *MockMFAChallengeRepository) IncrementAttempts(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.challenges[id]; ok {
+		c.Attempts++
+	}
+	return nil
+}
+
+func (m *MockMFAChallengeRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.challenges[id]
+	if !ok || c.IsUsed() {
+		return domain.ErrMFAChallengeInvalid
+	}
+	c.MarkUsed()
+	return nil
+}
+
+func (m *MockMFAChallengeRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	now := time.Now()
+	for id, c := range m.challenges {
+		if c.ExpiresAt.Before(now) {
+			delete(m.challenges, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+var _ repository.MFAChallengeRepository = (*MockMFAChallengeRepository)(nil)
+
+// MockStaffPINRepository is a mock implementation of StaffPINRepository.
+type MockStaffPINRepository struct {
+	mu   sync.RWMutex
+	pins map[uuid.UUID]*domain.StaffPIN
+}
+
+func NewMockStaffPINRepository() *MockStaffPINRepository {
+	return &MockStaffPINRepository{
+		pins: make(map[uuid.UUID]*domain.StaffPIN),
+	}
+}
+
+func (m *MockStaffPINRepository) FindByUserAndTenant(ctx context.Context, userID, tenantID uuid.UUID) (*domain.StaffPIN, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, p := range m.pins {
+		if p.UserID == userID && p.TenantID == tenantID {
+			return p, nil
+		}
+	}
+	return nil, domain.ErrPINNotSet
+}
+
+func (m *MockStaffPINRepository) Save(ctx context.Context, pin *domain.StaffPIN) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, p := range m.pins {
+		if p.UserID == pin.UserID && p.TenantID == pin.TenantID {
+			delete(m.pins, id)
+		}
+	}
+	if pin.ID == uuid.Nil {
+		pin.ID = uuid.New()
+	}
+	if pin.CreatedAt.IsZero() {
+		pin.CreatedAt = time.Now()
+	}
+	m.pins[pin.ID] = pin
+	return nil
+}
+
+func (m *MockStaffPINRepository) Update(ctx context.Context, pin *domain.StaffPIN) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pins[pin.ID] = pin
+	return nil
+}
+
+func (m *MockStaffPINRepository) Delete(ctx context.Context, userID, tenantID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, p := range m.pins {
+		if p.UserID == userID && p.TenantID == tenantID {
+			delete(m.pins, id)
+		}
+	}
+	return nil
+}
+
+func (m *MockStaffPINRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.StaffPIN, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var pins []*domain.StaffPIN
+	for _, p := range m.pins {
+		if p.TenantID == tenantID {
+			pins = append(pins, p)
+		}
+	}
+	sort.Slice(pins, func(i, j int) bool { return pins[i].CreatedAt.Before(pins[j].CreatedAt) })
+	return pins, nil
+}
+
+var _ repository.StaffPINRepository = (*MockStaffPINRepository)(nil)
+
+// MockTerminalRepository is a mock implementation of TerminalRepository.
+type MockTerminalRepository struct {
+	mu        sync.RWMutex
+	terminals map[uuid.UUID]*domain.Terminal
+}
+
+func NewMockTerminalRepository() *MockTerminalRepository {
+	return &MockTerminalRepository{
+		terminals: make(map[uuid.UUID]*domain.Terminal),
+	}
+}
+
+func (m *MockTerminalRepository) Create(ctx context.Context, terminal *domain.Terminal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if terminal.ID == uuid.Nil {
+		terminal.ID = uuid.New()
+	}
+	if terminal.CreatedAt.IsZero() {
+		terminal.CreatedAt = time.Now()
+	}
+	m.terminals[terminal.ID] = terminal
+	return nil
+}
+
+func (m *MockTerminalRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Terminal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if t, ok := m.terminals[id]; ok {
+		return t, nil
+	}
+	return nil, domain.ErrTerminalNotFound
+}
+
+func (m *MockTerminalRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.Terminal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, t := range m.terminals {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, domain.ErrTerminalNotFound
+}
+
+func (m *MockTerminalRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.Terminal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var terminals []*domain.Terminal
+	for _, t := range m.terminals {
+		if t.TenantID == tenantID && !t.IsRevoked() {
+			terminals = append(terminals, t)
+		}
+	}
+	sort.Slice(terminals, func(i, j int) bool { return terminals[i].CreatedAt.Before(terminals[j].CreatedAt) })
+	return terminals, nil
+}
+
+func (m *MockTerminalRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.terminals[id]
+	if !ok || t.IsRevoked() {
+		return domain.ErrTerminalNotFound
+	}
+	now := time.Now()
+	t.RevokedAt = &now
+	return nil
+}
+
+func (m *MockTerminalRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.terminals[id]; ok {
+		t.LastUsedAt = &at
+	}
+	return nil
+}
+
+var _ repository.TerminalRepository = (*MockTerminalRepository)(nil)
+
+// MockInvitationRepository is a mock implementation of InvitationRepository.
+type MockInvitationRepository struct {
+	mu          sync.RWMutex
+	invitations map[uuid.UUID]*domain.Invitation
+}
+
+func NewMockInvitationRepository() *MockInvitationRepository {
+	return &MockInvitationRepository{
+		invitations: make(map[uuid.UUID]*domain.Invitation),
+	}
+}
+
+func (m *MockInvitationRepository) Create(ctx context.Context, invitation *domain.Invitation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if invitation.ID == uuid.Nil {
+		invitation.ID = uuid.New()
+	}
+	if invitation.CreatedAt.IsZero() {
+		invitation.CreatedAt = time.Now()
+	}
+	m.invitations[invitation.ID] = invitation
+	return nil
+}
+
+func (m *MockInvitationRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Invitation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if inv, ok := m.invitations[id]; ok {
+		return inv, nil
+	}
+	return nil, domain.ErrInvitationNotFound
+}
+
+func (m *MockInvitationRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.Invitation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, inv := range m.invitations {
+		if inv.TokenHash == tokenHash {
+			return inv, nil
+		}
+	}
+	return nil, domain.ErrInvitationInvalid
+}
+
+func (m *MockInvitationRepository) FindPending(ctx context.Context, tenantID uuid.UUID, email string) (*domain.Invitation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, inv := range m.invitations {
+		if inv.TenantID == tenantID && inv.Email == email && inv.IsPending() {
+			return inv, nil
+		}
+	}
+	return nil, domain.ErrInvitationNotFound
+}
+
+func (m *MockInvitationRepository) ListPendingByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.Invitation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var invitations []*domain.Invitation
+	for _, inv := range m.invitations {
+		if inv.TenantID == tenantID && inv.IsPending() {
+			invitations = append(invitations, inv)
+		}
+	}
+	sort.Slice(invitations, func(i, j int) bool { return invitations[i].CreatedAt.Before(invitations[j].CreatedAt) })
+	return invitations, nil
+}
+
+func (m *MockInvitationRepository) Update(ctx context.Context, invitation *domain.Invitation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.invitations[invitation.ID] = invitation
+	return nil
+}
+
+func (m *MockInvitationRepository) MarkAccepted(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inv, ok := m.invitations[id]
+	if !ok || !inv.IsPending() {
+		return domain.ErrInvitationAccepted
+	}
+	now := time.Now()
+	inv.AcceptedAt = &now
+	return nil
+}
+
+func (m *MockInvitationRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inv, ok := m.invitations[id]
+	if !ok || !inv.IsPending() {
+		return domain.ErrInvitationNotFound
+	}
+	now := time.Now()
+	inv.RevokedAt = &now
+	return nil
+}
+
+// AddInvitation adds an invitation to the mock repository.
+func (m *MockInvitationRepository) AddInvitation(invitation *domain.Invitation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.invitations[invitation.ID] = invitation
+}
+
+var _ repository.InvitationRepository = (*MockInvitationRepository)(nil)
+
+// MockOwnershipTransferRepository is a mock implementation of OwnershipTransferRepository.
+type MockOwnershipTransferRepository struct {
+	mu        sync.RWMutex
+	transfers map[uuid.UUID]*domain.OwnershipTransfer
+}
+
+func NewMockOwnershipTransferRepository() *MockOwnershipTransferRepository {
+	return &MockOwnershipTransferRepository{
+		transfers: make(map[uuid.UUID]*domain.OwnershipTransfer),
+	}
+}
+
+func (m *MockOwnershipTransferRepository) Create(ctx context.Context, transfer *domain.OwnershipTransfer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if transfer.ID == uuid.Nil {
+		transfer.ID = uuid.New()
+	}
+	if transfer.CreatedAt.IsZero() {
+		transfer.CreatedAt = time.Now()
+	}
+	m.transfers[transfer.ID] = transfer
+	return nil
+}
+
+func (m *MockOwnershipTransferRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.OwnershipTransfer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, t := range m.transfers {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, domain.ErrOwnershipTransferInvalid
+}
+
+func (m *MockOwnershipTransferRepository) FindPendingByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.OwnershipTransfer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, t := range m.transfers {
+		if t.TenantID == tenantID && t.IsPending() {
+			return t, nil
+		}
+	}
+	return nil, domain.ErrOwnershipTransferNotFound
+}
+
+func (m *MockOwnershipTransferRepository) MarkConfirmed(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.transfers[id]
+	if !ok || !t.IsPending() {
+		return domain.ErrOwnershipTransferUsed
+	}
+	now := time.Now()
+	t.ConfirmedAt = &now
+	return nil
+}
+
+func (m *MockOwnershipTransferRepository) Cancel(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.transfers[id]
+	if !ok || !t.IsPending() {
+		return domain.ErrOwnershipTransferNotFound
+	}
+	now := time.Now()
+	t.CancelledAt = &now
+	return nil
+}
+
+// AddTransfer adds an ownership transfer to the mock repository.
+func (m *MockOwnershipTransferRepository) AddTransfer(transfer *domain.OwnershipTransfer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transfers[transfer.ID] = transfer
+}
+
+var _ repository.OwnershipTransferRepository = (*MockOwnershipTransferRepository)(nil)
+
+// MockCustomRoleRepository is a mock implementation of CustomRoleRepository.
+type MockCustomRoleRepository struct {
+	mu    sync.RWMutex
+	roles map[uuid.UUID]*domain.CustomRole
+}
+
+func NewMockCustomRoleRepository() *MockCustomRoleRepository {
+	return &MockCustomRoleRepository{
+		roles: make(map[uuid.UUID]*domain.CustomRole),
+	}
+}
+
+func (m *MockCustomRoleRepository) Create(ctx context.Context, role *domain.CustomRole) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if role.ID == uuid.Nil {
+		role.ID = uuid.New()
+	}
+	m.roles[role.ID] = role
+	return nil
+}
+
+func (m *MockCustomRoleRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.CustomRole, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if r, ok := m.roles[id]; ok && r.TenantID == tenantID {
+		return r, nil
+	}
+	return nil, domain.ErrCustomRoleNotFound
+}
+
+func (m *MockCustomRoleRepository) FindByName(ctx context.Context, tenantID uuid.UUID, name string) (*domain.CustomRole, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, r := range m.roles {
+		if r.TenantID == tenantID && strings.EqualFold(r.Name, name) {
+			return r, nil
+		}
+	}
+	return nil, domain.ErrCustomRoleNotFound
+}
+
+func (m *MockCustomRoleRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.CustomRole, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.CustomRole
+	for _, r := range m.roles {
+		if r.TenantID == tenantID {
+			result = append(result, r)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (m *MockCustomRoleRepository) Update(ctx context.Context, role *domain.CustomRole) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.roles[role.ID] = role
+	return nil
+}
+
+func (m *MockCustomRoleRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r, ok := m.roles[id]; !ok || r.TenantID != tenantID {
+		return domain.ErrCustomRoleNotFound
+	}
+	delete(m.roles, id)
+	return nil
+}
+
+// AddCustomRole adds a custom role to the mock repository.
+func (m *MockCustomRoleRepository) AddCustomRole(role *domain.CustomRole) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.roles[role.ID] = role
+}
+
+var _ repository.CustomRoleRepository = (*MockCustomRoleRepository)(nil)
+
+// MockApprovalRepository is a mock implementation of ApprovalRepository.
+type MockApprovalRepository struct {
+	mu        sync.RWMutex
+	approvals map[uuid.UUID]*domain.Approval
+}
+
+func NewMockApprovalRepository() *MockApprovalRepository {
+	return &MockApprovalRepository{
+		approvals: make(map[uuid.UUID]*domain.Approval),
+	}
+}
+
+func (m *MockApprovalRepository) Create(ctx context.Context, approval *domain.Approval) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if approval.ID == uuid.Nil {
+		approval.ID = uuid.New()
+	}
+	if approval.CreatedAt.IsZero() {
+		approval.CreatedAt = time.Now()
+	}
+	m.approvals[approval.ID] = approval
+	return nil
+}
+
+func (m *MockApprovalRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.Approval, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, a := range m.approvals {
+		if a.TokenHash == tokenHash {
+			return a, nil
+		}
+	}
+	return nil, domain.ErrApprovalInvalid
+}
+
+func (m *MockApprovalRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.approvals[id]
+	if !ok || a.IsUsed() {
+		return domain.ErrApprovalUsed
+	}
+	now := time.Now()
+	a.UsedAt = &now
+	return nil
+}
+
+// AddApproval adds an approval to the mock repository.
+func (m *MockApprovalRepository) AddApproval(approval *domain.Approval) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.approvals[approval.ID] = approval
+}
+
+// Approvals returns all approvals in the mock repository.
+func (m *MockApprovalRepository) Approvals() []*domain.Approval {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	approvals := make([]*domain.Approval, 0, len(m.approvals))
+	for _, a := range m.approvals {
+		approvals = append(approvals, a)
+	}
+	return approvals
+}
+
+var _ repository.ApprovalRepository = (*MockApprovalRepository)(nil)
+
+// MockSSOConfigRepository is a mock implementation of SSOConfigRepository.
+type MockSSOConfigRepository struct {
+	mu      sync.RWMutex
+	configs map[uuid.UUID]*domain.TenantSSOConfig // by tenant
+}
+
+func NewMockSSOConfigRepository() *MockSSOConfigRepository {
+	return &MockSSOConfigRepository{
+		configs: make(map[uuid.UUID]*domain.TenantSSOConfig),
+	}
+}
+
+func (m *MockSSOConfigRepository) Create(ctx context.Context, cfg *domain.TenantSSOConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cfg.ID == uuid.Nil {
+		cfg.ID = uuid.New()
+	}
+	m.configs[cfg.TenantID] = cfg
+	return nil
+}
+
+func (m *MockSSOConfigRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.TenantSSOConfig, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if cfg, ok := m.configs[tenantID]; ok {
+		return cfg, nil
+	}
+	return nil, domain.ErrSSONotConfigured
+}
+
+func (m *MockSSOConfigRepository) Update(ctx context.Context, cfg *domain.TenantSSOConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.configs[cfg.TenantID] = cfg
+	return nil
+}
+
+func (m *MockSSOConfigRepository) Delete(ctx context.Context, tenantID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.configs[tenantID]; !ok {
+		return domain.ErrSSONotConfigured
+	}
+	delete(m.configs, tenantID)
+	return nil
+}
+
+// AddConfig adds an SSO configuration to the mock repository.
+func (m *MockSSOConfigRepository) AddConfig(cfg *domain.TenantSSOConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cfg.ID == uuid.Nil {
+		cfg.ID = uuid.New()
+	}
+	m.configs[cfg.TenantID] = cfg
+}
+
+var _ repository.SSOConfigRepository = (*MockSSOConfigRepository)(nil)
+
+// MockSSOLoginStateRepository is a mock implementation of SSOLoginStateRepository.
+type MockSSOLoginStateRepository struct {
+	mu     sync.RWMutex
+	states map[uuid.UUID]*domain.SSOLoginState
+}
+
+func NewMockSSOLoginStateRepository() *MockSSOLoginStateRepository {
+	return &MockSSOLoginStateRepository{
+		states: make(map[uuid.UUID]*domain.SSOLoginState),
+	}
+}
+
+func (m *MockSSOLoginStateRepository) Create(ctx context.Context, state *domain.SSOLoginState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if state.ID == uuid.Nil {
+		state.ID = uuid.New()
+	}
+	m.states[state.ID] = state
+	return nil
+}
+
+func (m *MockSSOLoginStateRepository) FindByState(ctx context.Context, stateHash string) (*domain.SSOLoginState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.states {
+		if s.StateHash == stateHash {
+			return s, nil
+		}
+	}
+	return nil, domain.ErrSSOStateInvalid
+}
+
+func (m *MockSSOLoginStateRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.states[id]
+	if !ok || s.UsedAt != nil {
+		return domain.ErrSSOStateInvalid
+	}
+	now := time.Now()
+	s.UsedAt = &now
+	return nil
+}
+
+func (m *MockSSOLoginStateRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	now := time.Now()
+	for id, s := range m.states {
+		if s.ExpiresAt.Before(now) {
+			delete(m.states, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// States returns all login states in the mock repository.
+func (m *MockSSOLoginStateRepository) States() []*domain.SSOLoginState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	states := make([]*domain.SSOLoginState, 0, len(m.states))
+	for _, s := range m.states {
+		states = append(states, s)
+	}
+	return states
+}
+
+var _ repository.SSOLoginStateRepository = (*MockSSOLoginStateRepository)(nil)
+
+// MockUserIdentityRepository is a mock implementation of UserIdentityRepository.
+type MockUserIdentityRepository struct {
+	mu         sync.RWMutex
+	identities map[uuid.UUID]*domain.UserIdentity
+}
+
+func NewMockUserIdentityRepository() *MockUserIdentityRepository {
+	return &MockUserIdentityRepository{
+		identities: make(map[uuid.UUID]*domain.UserIdentity),
+	}
+}
+
+func (m *MockUserIdentityRepository) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, i := range m.identities {
+		if i.Issuer == identity.Issuer && i.Subject == identity.Subject {
+			return errors.New("duplicate identity")
+		}
+	}
+	if identity.ID == uuid.Nil {
+		identity.ID = uuid.New()
+	}
+	m.identities[identity.ID] = identity
+	return nil
+}
+
+func (m *MockUserIdentityRepository) FindBySubject(ctx context.Context, issuer, subject string) (*domain.UserIdentity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, i := range m.identities {
+		if i.Issuer == issuer && i.Subject == subject {
+			return i, nil
+		}
+	}
+	return nil, domain.ErrSSOIdentityNotFound
+}
+
+func (m *MockUserIdentityRepository) TouchLastLogin(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i, ok := m.identities[id]; ok {
+		now := time.Now()
+		i.LastLoginAt = &now
+	}
+	return nil
+}
+
+// AddIdentity adds an identity link to the mock repository.
+func (m *MockUserIdentityRepository) AddIdentity(identity *domain.UserIdentity) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if identity.ID == uuid.Nil {
+		identity.ID = uuid.New()
+	}
+	m.identities[identity.ID] = identity
+}
+
+// Identities returns all identity links in the mock repository.
+func (m *MockUserIdentityRepository) Identities() []*domain.UserIdentity {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	identities := make([]*domain.UserIdentity, 0, len(m.identities))
+	for _, i := range m.identities {
+		identities = append(identities, i)
+	}
+	return identities
+}
+
+var _ repository.UserIdentityRepository = (*MockUserIdentityRepository)(nil)
+
+// MockSCIMTokenRepository is a mock implementation of SCIMTokenRepository.
+type MockSCIMTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[uuid.UUID]*domain.SCIMToken
+}
+
+func NewMockSCIMTokenRepository() *MockSCIMTokenRepository {
+	return &MockSCIMTokenRepository{
+		tokens: make(map[uuid.UUID]*domain.SCIMToken),
+	}
+}
+
+func (m *MockSCIMTokenRepository) Create(ctx context.Context, token *domain.SCIMToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	m.tokens[token.ID] = token
+	return nil
+}
+
+func (m *MockSCIMTokenRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.SCIMToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if t, ok := m.tokens[id]; ok {
+		return t, nil
+	}
+	return nil, domain.ErrSCIMTokenNotFound
+}
+
+func (m *MockSCIMTokenRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.SCIMToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, domain.ErrSCIMTokenNotFound
+}
+
+func (m *MockSCIMTokenRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.SCIMToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var tokens []*domain.SCIMToken
+	for _, t := range m.tokens {
+		if t.TenantID == tenantID && !t.IsRevoked() {
+			tokens = append(tokens, t)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].CreatedAt.Before(tokens[j].CreatedAt) })
+	return tokens, nil
+}
+
+func (m *MockSCIMTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tokens[id]
+	if !ok || t.IsRevoked() {
+		return domain.ErrSCIMTokenNotFound
+	}
+	now := time.Now()
+	t.RevokedAt = &now
+	return nil
+}
+
+func (m *MockSCIMTokenRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.tokens[id]; ok {
+		t.LastUsedAt = &at
+	}
+	return nil
+}
+
+var _ repository.SCIMTokenRepository = (*MockSCIMTokenRepository)(nil)
+
+// MockSCIMUserLinkRepository is a mock implementation of SCIMUserLinkRepository.
+type MockSCIMUserLinkRepository struct {
+	mu    sync.RWMutex
+	links map[uuid.UUID]*domain.SCIMUserLink
+}
+
+func NewMockSCIMUserLinkRepository() *MockSCIMUserLinkRepository {
+	return &MockSCIMUserLinkRepository{
+		links: make(map[uuid.UUID]*domain.SCIMUserLink),
+	}
+}
+
+func (m *MockSCIMUserLinkRepository) Upsert(ctx context.Context, link *domain.SCIMUserLink) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, l := range m.links {
+		if l.TenantID == link.TenantID && l.UserID == link.UserID {
+			l.ExternalID = link.ExternalID
+			l.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	if link.ID == uuid.Nil {
+		link.ID = uuid.New()
+	}
+	m.links[link.ID] = link
+	return nil
+}
+
+func (m *MockSCIMUserLinkRepository) FindByUser(ctx context.Context, tenantID, userID uuid.UUID) (*domain.SCIMUserLink, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, l := range m.links {
+		if l.TenantID == tenantID && l.UserID == userID {
+			return l, nil
+		}
+	}
+	return nil, domain.ErrSCIMLinkNotFound
+}
+
+func (m *MockSCIMUserLinkRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.SCIMUserLink, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var links []*domain.SCIMUserLink
+	for _, l := range m.links {
+		if l.TenantID == tenantID {
+			links = append(links, l)
+		}
+	}
+	return links, nil
+}
+
+// Links returns all links in the mock repository.
+func (m *MockSCIMUserLinkRepository) Links() []*domain.SCIMUserLink {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	links := make([]*domain.SCIMUserLink, 0, len(m.links))
+	for _, l := range m.links {
+		links = append(links, l)
+	}
+	return links
+}
+
+var _ repository.SCIMUserLinkRepository = (*MockSCIMUserLinkRepository)(nil)
+
+// MockServiceAccountRepository is a mock implementation of ServiceAccountRepository.
+type MockServiceAccountRepository struct {
+	mu       sync.RWMutex
+	accounts map[uuid.UUID]*domain.ServiceAccount
+}
+
+func NewMockServiceAccountRepository() *MockServiceAccountRepository {
+	return &MockServiceAccountRepository{
+		accounts: make(map[uuid.UUID]*domain.ServiceAccount),
+	}
+}
+
+func (m *MockServiceAccountRepository) Create(ctx context.Context, account *domain.ServiceAccount) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if account.ID == uuid.Nil {
+		account.ID = uuid.New()
+	}
+	m.accounts[account.ID] = account
+	return nil
+}
+
+func (m *MockServiceAccountRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.ServiceAccount, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if a, ok := m.accounts[id]; ok && a.TenantID == tenantID {
+		return a, nil
+	}
+	return nil, domain.ErrServiceAccountNotFound
+}
+
+func (m *MockServiceAccountRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.ServiceAccount, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.ServiceAccount
+	for _, a := range m.accounts {
+		if a.TenantID == tenantID {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (m *MockServiceAccountRepository) Update(ctx context.Context, account *domain.ServiceAccount) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.accounts[account.ID] = account
+	return nil
+}
+
+func (m *MockServiceAccountRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if a, ok := m.accounts[id]; !ok || a.TenantID != tenantID {
+		return domain.ErrServiceAccountNotFound
+	}
+	delete(m.accounts, id)
+	return nil
+}
+
+var _ repository.ServiceAccountRepository = (*MockServiceAccountRepository)(nil)
+
+// MockAPIKeyRepository is a mock implementation of APIKeyRepository.
+type MockAPIKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[uuid.UUID]*domain.APIKey
+}
+
+func NewMockAPIKeyRepository() *MockAPIKeyRepository {
+	return &MockAPIKeyRepository{
+		keys: make(map[uuid.UUID]*domain.APIKey),
+	}
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m *MockAPIKeyRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if k, ok := m.keys[id]; ok {
+		return k, nil
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (m *MockAPIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, k := range m.keys {
+		if k.KeyHash == keyHash {
+			return k, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (m *MockAPIKeyRepository) ListByServiceAccount(ctx context.Context, serviceAccountID uuid.UUID) ([]*domain.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var keys []*domain.APIKey
+	for _, k := range m.keys {
+		if k.ServiceAccountID == serviceAccountID && !k.IsRevoked() {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+func (m *MockAPIKeyRepository) SetExpiry(ctx context.Context, id uuid.UUID, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k, ok := m.keys[id]
+	if !ok || k.IsRevoked() {
+		return domain.ErrAPIKeyNotFound
+	}
+	k.ExpiresAt = expiresAt
+	return nil
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k, ok := m.keys[id]
+	if !ok || k.IsRevoked() {
+		return domain.ErrAPIKeyNotFound
+	}
+	now := time.Now()
+	k.RevokedAt = &now
+	return nil
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if k, ok := m.keys[id]; ok {
+		k.LastUsedAt = &at
+	}
+	return nil
+}
+
+var _ repository.APIKeyRepository = (*MockAPIKeyRepository)(nil)
+
+// MockOAuthClientRepository is a mock implementation of OAuthClientRepository.
+type MockOAuthClientRepository struct {
+	mu      sync.RWMutex
+	clients map[uuid.UUID]*domain.OAuthClient
+}
+
+func NewMockOAuthClientRepository() *MockOAuthClientRepository {
+	return &MockOAuthClientRepository{
+		clients: make(map[uuid.UUID]*domain.OAuthClient),
+	}
+}
+
+func (m *MockOAuthClientRepository) Create(ctx context.Context, client *domain.OAuthClient) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if client.ID == uuid.Nil {
+		client.ID = uuid.New()
+	}
+	m.clients[client.ID] = client
+	return nil
+}
+
+func (m *MockOAuthClientRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.OAuthClient, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if c, ok := m.clients[id]; ok {
+		return c, nil
+	}
+	return nil, domain.ErrOAuthClientNotFound
+}
+
+func (m *MockOAuthClientRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.OAuthClient, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.OAuthClient
+	for _, c := range m.clients {
+		if c.TenantID == tenantID {
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (m *MockOAuthClientRepository) Update(ctx context.Context, client *domain.OAuthClient) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clients[client.ID] = client
+	return nil
+}
+
+func (m *MockOAuthClientRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.clients[id]; !ok || c.TenantID != tenantID {
+		return domain.ErrOAuthClientNotFound
+	}
+	delete(m.clients, id)
+	return nil
+}
+
+var _ repository.OAuthClientRepository = (*MockOAuthClientRepository)(nil)
+
+// MockOAuthConsentRepository is a mock implementation of OAuthConsentRepository.
+type MockOAuthConsentRepository struct {
+	mu       sync.RWMutex
+	consents map[uuid.UUID]*domain.OAuthConsent
+}
+
+func NewMockOAuthConsentRepository() *MockOAuthConsentRepository {
+	return &MockOAuthConsentRepository{
+		consents: make(map[uuid.UUID]*domain.OAuthConsent),
+	}
+}
+
+func (m *MockOAuthConsentRepository) Create(ctx context.Context, consent *domain.OAuthConsent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if consent.ID == uuid.Nil {
+		consent.ID = uuid.New()
+	}
+	if consent.CreatedAt.IsZero() {
+		consent.CreatedAt = time.Now()
+	}
+	m.consents[consent.ID] = consent
+	return nil
+}
+
+func (m *MockOAuthConsentRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.OAuthConsent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if c, ok := m.consents[id]; ok && c.TenantID == tenantID {
+		return c, nil
+	}
+	return nil, domain.ErrOAuthConsentNotFound
+}
+
+func (m *MockOAuthConsentRepository) FindActive(ctx context.Context, tenantID, clientID uuid.UUID) (*domain.OAuthConsent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, c := range m.consents {
+		if c.TenantID == tenantID && c.ClientID == clientID && !c.IsRevoked() {
+			return c, nil
+		}
+	}
+	return nil, domain.ErrOAuthConsentNotFound
+}
+
+func (m *MockOAuthConsentRepository) ListActiveByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.OAuthConsent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.OAuthConsent
+	for _, c := range m.consents {
+		if c.TenantID == tenantID && !c.IsRevoked() {
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (m *MockOAuthConsentRepository) Update(ctx context.Context, consent *domain.OAuthConsent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.consents[consent.ID] = consent
+	return nil
+}
+
+func (m *MockOAuthConsentRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.consents[id]
+	if !ok || c.IsRevoked() {
+		return domain.ErrOAuthConsentNotFound
+	}
+	now := time.Now()
+	c.RevokedAt = &now
+	return nil
+}
+
+var _ repository.OAuthConsentRepository = (*MockOAuthConsentRepository)(nil)
+
+// MockOAuthCodeRepository is a mock implementation of OAuthCodeRepository.
+type MockOAuthCodeRepository struct {
+	mu    sync.RWMutex
+	codes map[uuid.UUID]*domain.OAuthAuthorizationCode
+}
+
+func NewMockOAuthCodeRepository() *MockOAuthCodeRepository {
+	return &MockOAuthCodeRepository{
+		codes: make(map[uuid.UUID]*domain.OAuthAuthorizationCode),
+	}
+}
+
+func (m *MockOAuthCodeRepository) Create(ctx context.Context, code *domain.OAuthAuthorizationCode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if code.ID == uuid.Nil {
+		code.ID = uuid.New()
+	}
+	m.codes[code.ID] = code
+	return nil
+}
+
+func (m *MockOAuthCodeRepository) FindByHash(ctx context.Context, codeHash string) (*domain.OAuthAuthorizationCode, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, c := range m.codes {
+		if c.CodeHash == codeHash {
+			return c, nil
+		}
+	}
+	return nil, domain.ErrOAuthGrantInvalid
+}
+
+func (m *MockOAuthCodeRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.codes[id]
+	if !ok || c.UsedAt != nil {
+		return domain.ErrOAuthGrantInvalid
+	}
+	now := time.Now()
+	c.UsedAt = &now
+	return nil
+}
+
+func (m *MockOAuthCodeRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var count int64
+	for id, c := range m.codes {
+		if c.IsExpired() {
+			delete(m.codes, id)
+			count++
+		}
+	}
+	return count, nil
+}
+
+var _ repository.OAuthCodeRepository = (*MockOAuthCodeRepository)(nil)
+
+// MockOAuthRefreshTokenRepository is a mock implementation of OAuthRefreshTokenRepository.
+type MockOAuthRefreshTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[uuid.UUID]*domain.OAuthRefreshToken
+}
+
+func NewMockOAuthRefreshTokenRepository() *MockOAuthRefreshTokenRepository {
+	return &MockOAuthRefreshTokenRepository{
+		tokens: make(map[uuid.UUID]*domain.OAuthRefreshToken),
+	}
+}
+
+func (m *MockOAuthRefreshTokenRepository) Create(ctx context.Context, token *domain.OAuthRefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	m.tokens[token.ID] = token
+	return nil
+}
+
+func (m *MockOAuthRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.OAuthRefreshToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, domain.ErrOAuthGrantInvalid
+}
+
+func (m *MockOAuthRefreshTokenRepository) Rotate(ctx context.Context, id uuid.UUID, next *domain.OAuthRefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tokens[id]
+	if !ok || t.IsRevoked() {
+		return domain.ErrOAuthGrantInvalid
+	}
+	if next.ID == uuid.Nil {
+		next.ID = uuid.New()
+	}
+	now := time.Now()
+	t.RevokedAt = &now
+	t.ReplacedByID = &next.ID
+	m.tokens[next.ID] = next
+	return nil
+}
+
+func (m *MockOAuthRefreshTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.tokens[id]; ok && !t.IsRevoked() {
+		now := time.Now()
+		t.RevokedAt = &now
+	}
+	return nil
+}
+
+func (m *MockOAuthRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	return m.revokeWhere(func(t *domain.OAuthRefreshToken) bool { return t.FamilyID == familyID }), nil
+}
+
+func (m *MockOAuthRefreshTokenRepository) RevokeByClient(ctx context.Context, tenantID, clientID uuid.UUID) (int64, error) {
+	return m.revokeWhere(func(t *domain.OAuthRefreshToken) bool { return t.TenantID == tenantID && t.ClientID == clientID }), nil
+}
+
+func (m *MockOAuthRefreshTokenRepository) revokeWhere(match func(*domain.OAuthRefreshToken) bool) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	var count int64
+	for _, t := range m.tokens {
+		if match(t) && !t.IsRevoked() {
+			t.RevokedAt = &now
+			count++
+		}
+	}
+	return count
+}
+
+var _ repository.OAuthRefreshTokenRepository = (*MockOAuthRefreshTokenRepository)(nil)
+
+// MockPasskeyRepository is a mock implementation of PasskeyRepository.
+type MockPasskeyRepository struct {
+	mu       sync.RWMutex
+	passkeys map[uuid.UUID]*domain.Passkey
+}
+
+func NewMockPasskeyRepository() *MockPasskeyRepository {
+	return &MockPasskeyRepository{
+		passkeys: make(map[uuid.UUID]*domain.Passkey),
+	}
+}
+
+func (m *MockPasskeyRepository) Create(ctx context.Context, passkey *domain.Passkey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.passkeys {
+		if bytes.Equal(p.CredentialID, passkey.CredentialID) {
+			return errors.New("duplicate credential id")
+		}
+	}
+	if passkey.ID == uuid.Nil {
+		passkey.ID = uuid.New()
+	}
+	if passkey.CreatedAt.IsZero() {
+		passkey.CreatedAt = time.Now()
+	}
+	m.passkeys[passkey.ID] = passkey
+	return nil
+}
+
+func (m *MockPasskeyRepository) FindByID(ctx context.Context, userID, id uuid.UUID) (*domain.Passkey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if p, ok := m.passkeys[id]; ok && p.UserID == userID {
+		return p, nil
+	}
+	return nil, domain.ErrPasskeyNotFound
+}
+
+func (m *MockPasskeyRepository) FindByCredentialID(ctx context.Context, credentialID []byte) (*domain.Passkey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, p := range m.passkeys {
+		if bytes.Equal(p.CredentialID, credentialID) {
+			return p, nil
+		}
+	}
+	return nil, domain.ErrPasskeyNotFound
+}
+
+func (m *MockPasskeyRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Passkey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.Passkey
+	for _, p := range m.passkeys {
+		if p.UserID == userID {
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (m *MockPasskeyRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var count int64
+	for _, p := range m.passkeys {
+		if p.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockPasskeyRepository) Update(ctx context.Context, passkey *domain.Passkey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.passkeys[passkey.ID] = passkey
+	return nil
+}
+
+func (m *MockPasskeyRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.passkeys[id]; !ok || p.UserID != userID {
+		return domain.ErrPasskeyNotFound
+	}
+	delete(m.passkeys, id)
+	return nil
+}
+
+var _ repository.PasskeyRepository = (*MockPasskeyRepository)(nil)
+
+// MockPasskeyChallengeRepository is a mock implementation of PasskeyChallengeRepository.
+type MockPasskeyChallengeRepository struct {
+	mu         sync.RWMutex
+	challenges map[uuid.UUID]*domain.PasskeyChallenge
+}
+
+func NewMockPasskeyChallengeRepository() *MockPasskeyChallengeRepository {
+	return &MockPasskeyChallengeRepository{
+		challenges: make(map[uuid.UUID]*domain.PasskeyChallenge),
+	}
+}
+
+func (m *MockPasskeyChallengeRepository) Create(ctx context.Context, challenge *domain.PasskeyChallenge) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if challenge.ID == uuid.Nil {
+		challenge.ID = uuid.New()
+	}
+	m.challenges[challenge.ID] = challenge
+	return nil
+}
+
+func (m *MockPasskeyChallengeRepository) FindByChallenge(ctx context.Context, challengeHash string) (*domain.PasskeyChallenge, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, c := range m.challenges {
+		if c.ChallengeHash == challengeHash {
+			return c, nil
+		}
+	}
+	return nil, domain.ErrPasskeyChallengeInvalid
+}
+
+func (m *MockPasskeyChallengeRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.challenges[id]
+	if !ok || c.UsedAt != nil {
+		return domain.ErrPasskeyChallengeInvalid
+	}
+	now := time.Now()
+	c.UsedAt = &now
+	return nil
+}
+
+func (m *MockPasskeyChallengeRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	now := time.Now()
+	for id, c := range m.challenges {
+		if c.ExpiresAt.Before(now) {
+			delete(m.challenges, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+var _ repository.PasskeyChallengeRepository = (*MockPasskeyChallengeRepository)(nil)
+
+// MockLockoutPolicyRepository is a mock implementation of LockoutPolicyRepository.
+type MockLockoutPolicyRepository struct {
+	mu       sync.RWMutex
+	policies map[uuid.UUID]*domain.LockoutPolicy // by tenant
+}
+
+func NewMockLockoutPolicyRepository() *MockLockoutPolicyRepository {
+	return &MockLockoutPolicyRepository{
+		policies: make(map[uuid.UUID]*domain.LockoutPolicy),
+	}
+}
+
+func (m *MockLockoutPolicyRepository) Create(ctx context.Context, policy *domain.LockoutPolicy) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+	m.policies[policy.TenantID] = policy
+	return nil
+}
+
+func (m *MockLockoutPolicyRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.LockoutPolicy, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if policy, ok := m.policies[tenantID]; ok {
+		copied := *policy
+		return &copied, nil
+	}
+	return nil, domain.ErrLockoutPolicyNotFound
+}
+
+func (m *MockLockoutPolicyRepository) ListByTenants(ctx context.Context, tenantIDs []uuid.UUID) ([]*domain.LockoutPolicy, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var policies []*domain.LockoutPolicy
+	for _, id := range tenantIDs {
+		if policy, ok := m.policies[id]; ok {
+			copied := *policy
+			policies = append(policies, &copied)
+		}
+	}
+	return policies, nil
+}
+
+func (m *MockLockoutPolicyRepository) Update(ctx context.Context, policy *domain.LockoutPolicy) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.policies[policy.TenantID] = policy
+	return nil
+}
+
+// AddPolicy adds a lockout policy to the mock repository.
+func (m *MockLockoutPolicyRepository) AddPolicy(policy *domain.LockoutPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+	m.policies[policy.TenantID] = policy
+}
+
+var _ repository.LockoutPolicyRepository = (*MockLockoutPolicyRepository)(nil)
+
+// MockKnownDeviceRepository is a mock implementation of KnownDeviceRepository.
+type MockKnownDeviceRepository struct {
+	mu      sync.RWMutex
+	devices map[uuid.UUID]*domain.KnownDevice
+}
+
+func NewMockKnownDeviceRepository() *MockKnownDeviceRepository {
+	return &MockKnownDeviceRepository{
+		devices: make(map[uuid.UUID]*domain.KnownDevice),
+	}
+}
+
+func (m *MockKnownDeviceRepository) Create(ctx context.Context, device *domain.KnownDevice) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if device.ID == uuid.Nil {
+		device.ID = uuid.New()
+	}
+	m.devices[device.ID] = device
+	return nil
+}
+
+func (m *MockKnownDeviceRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.KnownDevice, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, d := range m.devices {
+		if d.TokenHash == tokenHash {
+			copied := *d
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrKnownDeviceNotFound
+}
+
+func (m *MockKnownDeviceRepository) Touch(ctx context.Context, id uuid.UUID, seenAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.devices[id]; ok {
+		d.LastSeenAt = seenAt
+	}
+	return nil
+}
+
+// CountForUser returns how many devices a user has, for assertions.
+func (m *MockKnownDeviceRepository) CountForUser(userID uuid.UUID) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	count := 0
+	for _, d := range m.devices {
+		if d.UserID == userID {
+			count++
+		}
+	}
+	return count
+}
+
+var _ repository.KnownDeviceRepository = (*MockKnownDeviceRepository)(nil)
@@ -0,0 +1,375 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// OAuthClientRepository defines the interface for OAuth client data access.
+type OAuthClientRepository interface {
+	// Create registers a new OAuth client.
+	Create(ctx context.Context, client *domain.OAuthClient) error
+
+	// FindByID retrieves an OAuth client by ID, whatever tenant registered it.
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.OAuthClient, error)
+
+	// ListByTenant lists the OAuth clients a tenant registered, ordered by name.
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.OAuthClient, error)
+
+	// Update updates an OAuth client.
+	Update(ctx context.Context, client *domain.OAuthClient) error
+
+	// Delete deletes a tenant's OAuth client along with its consents, codes
+	// and refresh tokens in every tenant.
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+}
+
+// GormOAuthClientRepository is a GORM implementation of OAuthClientRepository.
+type GormOAuthClientRepository struct {
+	db *gorm.DB
+}
+
+// NewGormOAuthClientRepository creates a new GormOAuthClientRepository.
+func NewGormOAuthClientRepository(db *gorm.DB) *GormOAuthClientRepository {
+	return &GormOAuthClientRepository{db: db}
+}
+
+// Create registers a new OAuth client.
+func (r *GormOAuthClientRepository) Create(ctx context.Context, client *domain.OAuthClient) error {
+	if client.ID == uuid.Nil {
+		client.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(client).Error
+}
+
+// FindByID retrieves an OAuth client by ID.
+func (r *GormOAuthClientRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.OAuthClient, error) {
+	var client domain.OAuthClient
+	if err := r.db.WithContext(ctx).First(&client, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrOAuthClientNotFound
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+// ListByTenant lists the OAuth clients a tenant registered, ordered by name.
+func (r *GormOAuthClientRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.OAuthClient, error) {
+	var clients []*domain.OAuthClient
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("name ASC").
+		Find(&clients).Error
+	return clients, err
+}
+
+// Update updates an OAuth client.
+func (r *GormOAuthClientRepository) Update(ctx context.Context, client *domain.OAuthClient) error {
+	return r.db.WithContext(ctx).Save(client).Error
+}
+
+// Delete deletes a tenant's OAuth client. Everything issued to it goes with it.
+func (r *GormOAuthClientRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&domain.OAuthClient{}, "id = ? AND tenant_id = ?", id, tenantID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrOAuthClientNotFound
+		}
+		for _, model := range []interface{}{&domain.OAuthRefreshToken{}, &domain.OAuthAuthorizationCode{}, &domain.OAuthConsent{}} {
+			if err := tx.Delete(model, "client_id = ?", id).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Ensure GormOAuthClientRepository implements OAuthClientRepository
+var _ OAuthClientRepository = (*GormOAuthClientRepository)(nil)
+
+// OAuthConsentRepository defines the interface for OAuth consent data access.
+type OAuthConsentRepository interface {
+	// Create records a new consent.
+	Create(ctx context.Context, consent *domain.OAuthConsent) error
+
+	// FindByID retrieves a tenant's consent by ID.
+	FindByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.OAuthConsent, error)
+
+	// FindActive retrieves a tenant's consent to a client that has not been
+	// revoked.
+	FindActive(ctx context.Context, tenantID, clientID uuid.UUID) (*domain.OAuthConsent, error)
+
+	// ListActiveByTenant lists a tenant's consents that have not been
+	// revoked, oldest first, with their clients.
+	ListActiveByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.OAuthConsent, error)
+
+	// Update updates a consent.
+	Update(ctx context.Context, consent *domain.OAuthConsent) error
+
+	// Revoke revokes a consent.
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+// GormOAuthConsentRepository is a GORM implementation of OAuthConsentRepository.
+type GormOAuthConsentRepository struct {
+	db *gorm.DB
+}
+
+// NewGormOAuthConsentRepository creates a new GormOAuthConsentRepository.
+func NewGormOAuthConsentRepository(db *gorm.DB) *GormOAuthConsentRepository {
+	return &GormOAuthConsentRepository{db: db}
+}
+
+// Create records a new consent.
+func (r *GormOAuthConsentRepository) Create(ctx context.Context, consent *domain.OAuthConsent) error {
+	if consent.ID == uuid.Nil {
+		consent.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(consent).Error
+}
+
+// FindByID retrieves a tenant's consent by ID.
+func (r *GormOAuthConsentRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.OAuthConsent, error) {
+	var consent domain.OAuthConsent
+	if err := r.db.WithContext(ctx).First(&consent, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrOAuthConsentNotFound
+		}
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// FindActive retrieves a tenant's unrevoked consent to a client.
+func (r *GormOAuthConsentRepository) FindActive(ctx context.Context, tenantID, clientID uuid.UUID) (*domain.OAuthConsent, error) {
+	var consent domain.OAuthConsent
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND client_id = ? AND revoked_at IS NULL", tenantID, clientID).
+		First(&consent).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrOAuthConsentNotFound
+		}
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// ListActiveByTenant lists a tenant's unrevoked consents with their clients.
+func (r *GormOAuthConsentRepository) ListActiveByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.OAuthConsent, error) {
+	var consents []*domain.OAuthConsent
+	err := r.db.WithContext(ctx).
+		Preload("Client").
+		Where("tenant_id = ? AND revoked_at IS NULL", tenantID).
+		Order("created_at ASC").
+		Find(&consents).Error
+	return consents, err
+}
+
+// Update updates a consent.
+func (r *GormOAuthConsentRepository) Update(ctx context.Context, consent *domain.OAuthConsent) error {
+	return r.db.WithContext(ctx).Omit("Client", "Tenant").Save(consent).Error
+}
+
+// Revoke revokes a consent.
+func (r *GormOAuthConsentRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.OAuthConsent{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrOAuthConsentNotFound
+	}
+	return nil
+}
+
+// Ensure GormOAuthConsentRepository implements OAuthConsentRepository
+var _ OAuthConsentRepository = (*GormOAuthConsentRepository)(nil)
+
+// OAuthCodeRepository defines the interface for OAuth authorization code data access.
+type OAuthCodeRepository interface {
+	// Create stores a new authorization code.
+	Create(ctx context.Context, code *domain.OAuthAuthorizationCode) error
+
+	// FindByHash retrieves an authorization code by its hash.
+	FindByHash(ctx context.Context, codeHash string) (*domain.OAuthAuthorizationCode, error)
+
+	// MarkUsed marks an unused authorization code as used. It returns
+	// ErrOAuthGrantInvalid if the code was already used.
+	MarkUsed(ctx context.Context, id uuid.UUID) error
+
+	// DeleteExpired removes all expired authorization codes.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// GormOAuthCodeRepository is a GORM implementation of OAuthCodeRepository.
+type GormOAuthCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewGormOAuthCodeRepository creates a new GormOAuthCodeRepository.
+func NewGormOAuthCodeRepository(db *gorm.DB) *GormOAuthCodeRepository {
+	return &GormOAuthCodeRepository{db: db}
+}
+
+// Create stores a new authorization code.
+func (r *GormOAuthCodeRepository) Create(ctx context.Context, code *domain.OAuthAuthorizationCode) error {
+	if code.ID == uuid.Nil {
+		code.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(code).Error
+}
+
+// FindByHash retrieves an authorization code by its hash.
+func (r *GormOAuthCodeRepository) FindByHash(ctx context.Context, codeHash string) (*domain.OAuthAuthorizationCode, error) {
+	var code domain.OAuthAuthorizationCode
+	if err := r.db.WithContext(ctx).First(&code, "code_hash = ?", codeHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrOAuthGrantInvalid
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// MarkUsed marks an unused authorization code as used.
+func (r *GormOAuthCodeRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.OAuthAuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrOAuthGrantInvalid
+	}
+	return nil
+}
+
+// DeleteExpired removes all expired authorization codes.
+func (r *GormOAuthCodeRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now()).
+		Delete(&domain.OAuthAuthorizationCode{})
+	return result.RowsAffected, result.Error
+}
+
+// Ensure GormOAuthCodeRepository implements OAuthCodeRepository
+var _ OAuthCodeRepository = (*GormOAuthCodeRepository)(nil)
+
+// OAuthRefreshTokenRepository defines the interface for OAuth refresh token data access.
+type OAuthRefreshTokenRepository interface {
+	// Create stores a new refresh token.
+	Create(ctx context.Context, token *domain.OAuthRefreshToken) error
+
+	// FindByHash retrieves a refresh token by its hash.
+	FindByHash(ctx context.Context, tokenHash string) (*domain.OAuthRefreshToken, error)
+
+	// Rotate revokes a refresh token as replaced by next and stores next,
+	// atomically. It returns ErrOAuthGrantInvalid if the token was already
+	// revoked, so a token can only be rotated once.
+	Rotate(ctx context.Context, id uuid.UUID, next *domain.OAuthRefreshToken) error
+
+	// Revoke revokes a refresh token.
+	Revoke(ctx context.Context, id uuid.UUID) error
+
+	// RevokeFamily revokes every active refresh token in a family.
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) (int64, error)
+
+	// RevokeByClient revokes every active refresh token issued to a client
+	// in a tenant.
+	RevokeByClient(ctx context.Context, tenantID, clientID uuid.UUID) (int64, error)
+}
+
+// GormOAuthRefreshTokenRepository is a GORM implementation of OAuthRefreshTokenRepository.
+type GormOAuthRefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGormOAuthRefreshTokenRepository creates a new GormOAuthRefreshTokenRepository.
+func NewGormOAuthRefreshTokenRepository(db *gorm.DB) *GormOAuthRefreshTokenRepository {
+	return &GormOAuthRefreshTokenRepository{db: db}
+}
+
+// Create stores a new refresh token.
+func (r *GormOAuthRefreshTokenRepository) Create(ctx context.Context, token *domain.OAuthRefreshToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// FindByHash retrieves a refresh token by its hash.
+func (r *GormOAuthRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.OAuthRefreshToken, error) {
+	var token domain.OAuthRefreshToken
+	if err := r.db.WithContext(ctx).First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrOAuthGrantInvalid
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate revokes a refresh token as replaced by next and stores next.
+func (r *GormOAuthRefreshTokenRepository) Rotate(ctx context.Context, id uuid.UUID, next *domain.OAuthRefreshToken) error {
+	if next.ID == uuid.Nil {
+		next.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.OAuthRefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", id).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by_id": next.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrOAuthGrantInvalid
+		}
+		return tx.Create(next).Error
+	})
+}
+
+// Revoke revokes a refresh token.
+func (r *GormOAuthRefreshTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.OAuthRefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeFamily revokes every active refresh token in a family.
+func (r *GormOAuthRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.OAuthRefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// RevokeByClient revokes every active refresh token issued to a client in a tenant.
+func (r *GormOAuthRefreshTokenRepository) RevokeByClient(ctx context.Context, tenantID, clientID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.OAuthRefreshToken{}).
+		Where("tenant_id = ? AND client_id = ? AND revoked_at IS NULL", tenantID, clientID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// Ensure GormOAuthRefreshTokenRepository implements OAuthRefreshTokenRepository
+var _ OAuthRefreshTokenRepository = (*GormOAuthRefreshTokenRepository)(nil)
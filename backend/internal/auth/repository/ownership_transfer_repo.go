@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// OwnershipTransferRepository defines the interface for ownership transfer data access.
+type OwnershipTransferRepository interface {
+	// Create creates a new ownership transfer.
+	Create(ctx context.Context, transfer *domain.OwnershipTransfer) error
+
+	// FindByToken retrieves a transfer by its token hash.
+	FindByToken(ctx context.Context, tokenHash string) (*domain.OwnershipTransfer, error)
+
+	// FindPendingByTenant retrieves a tenant's outstanding (not confirmed,
+	// not cancelled) transfer, if any.
+	FindPendingByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.OwnershipTransfer, error)
+
+	// MarkConfirmed marks an outstanding transfer as confirmed.
+	MarkConfirmed(ctx context.Context, id uuid.UUID) error
+
+	// Cancel cancels an outstanding transfer so its token stops working.
+	Cancel(ctx context.Context, id uuid.UUID) error
+}
+
+// GormOwnershipTransferRepository is a GORM implementation of OwnershipTransferRepository.
+type GormOwnershipTransferRepository struct {
+	db *gorm.DB
+}
+
+// NewGormOwnershipTransferRepository creates a new GormOwnershipTransferRepository.
+func NewGormOwnershipTransferRepository(db *gorm.DB) *GormOwnershipTransferRepository {
+	return &GormOwnershipTransferRepository{db: db}
+}
+
+// Create creates a new ownership transfer.
+func (r *GormOwnershipTransferRepository) Create(ctx context.Context, transfer *domain.OwnershipTransfer) error {
+	if transfer.ID == uuid.Nil {
+		transfer.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(transfer).Error
+}
+
+// FindByToken retrieves a transfer by its token hash.
+func (r *GormOwnershipTransferRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.OwnershipTransfer, error) {
+	var transfer domain.OwnershipTransfer
+	if err := r.db.WithContext(ctx).First(&transfer, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrOwnershipTransferInvalid
+		}
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// FindPendingByTenant retrieves a tenant's outstanding transfer.
+func (r *GormOwnershipTransferRepository) FindPendingByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.OwnershipTransfer, error) {
+	var transfer domain.OwnershipTransfer
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND confirmed_at IS NULL AND cancelled_at IS NULL", tenantID).
+		First(&transfer).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrOwnershipTransferNotFound
+		}
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// MarkConfirmed marks an outstanding transfer as confirmed.
+func (r *GormOwnershipTransferRepository) MarkConfirmed(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.OwnershipTransfer{}).
+		Where("id = ? AND confirmed_at IS NULL AND cancelled_at IS NULL", id).
+		Update("confirmed_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrOwnershipTransferUsed
+	}
+	return nil
+}
+
+// Cancel cancels an outstanding transfer.
+func (r *GormOwnershipTransferRepository) Cancel(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.OwnershipTransfer{}).
+		Where("id = ? AND confirmed_at IS NULL AND cancelled_at IS NULL", id).
+		Update("cancelled_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrOwnershipTransferNotFound
+	}
+	return nil
+}
+
+// Ensure GormOwnershipTransferRepository implements OwnershipTransferRepository
+var _ OwnershipTransferRepository = (*GormOwnershipTransferRepository)(nil)
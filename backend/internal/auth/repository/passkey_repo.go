@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// PasskeyRepository defines the interface for WebAuthn credential data access.
+type PasskeyRepository interface {
+	// Create creates a new passkey.
+	Create(ctx context.Context, passkey *domain.Passkey) error
+
+	// FindByID retrieves one of a user's passkeys by ID.
+	FindByID(ctx context.Context, userID, id uuid.UUID) (*domain.Passkey, error)
+
+	// FindByCredentialID retrieves a passkey by its WebAuthn credential ID.
+	FindByCredentialID(ctx context.Context, credentialID []byte) (*domain.Passkey, error)
+
+	// ListByUser lists a user's passkeys, oldest first.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Passkey, error)
+
+	// CountByUser counts a user's passkeys.
+	CountByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// Update updates a passkey.
+	Update(ctx context.Context, passkey *domain.Passkey) error
+
+	// Delete deletes one of a user's passkeys.
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+}
+
+// GormPasskeyRepository is a GORM implementation of PasskeyRepository.
+type GormPasskeyRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPasskeyRepository creates a new GormPasskeyRepository.
+func NewGormPasskeyRepository(db *gorm.DB) *GormPasskeyRepository {
+	return &GormPasskeyRepository{db: db}
+}
+
+// Create creates a new passkey.
+func (r *GormPasskeyRepository) Create(ctx context.Context, passkey *domain.Passkey) error {
+	if passkey.ID == uuid.Nil {
+		passkey.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(passkey).Error
+}
+
+// FindByID retrieves one of a user's passkeys by ID.
+func (r *GormPasskeyRepository) FindByID(ctx context.Context, userID, id uuid.UUID) (*domain.Passkey, error) {
+	var passkey domain.Passkey
+	if err := r.db.WithContext(ctx).First(&passkey, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPasskeyNotFound
+		}
+		return nil, err
+	}
+	return &passkey, nil
+}
+
+// FindByCredentialID retrieves a passkey by its WebAuthn credential ID.
+func (r *GormPasskeyRepository) FindByCredentialID(ctx context.Context, credentialID []byte) (*domain.Passkey, error) {
+	var passkey domain.Passkey
+	if err := r.db.WithContext(ctx).First(&passkey, "credential_id = ?", credentialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPasskeyNotFound
+		}
+		return nil, err
+	}
+	return &passkey, nil
+}
+
+// ListByUser lists a user's passkeys, oldest first.
+func (r *GormPasskeyRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Passkey, error) {
+	var passkeys []*domain.Passkey
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&passkeys).Error
+	return passkeys, err
+}
+
+// CountByUser counts a user's passkeys.
+func (r *GormPasskeyRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Passkey{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// Update updates a passkey.
+func (r *GormPasskeyRepository) Update(ctx context.Context, passkey *domain.Passkey) error {
+	return r.db.WithContext(ctx).Save(passkey).Error
+}
+
+// Delete deletes one of a user's passkeys.
+func (r *GormPasskeyRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&domain.Passkey{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+// Ensure GormPasskeyRepository implements PasskeyRepository
+var _ PasskeyRepository = (*GormPasskeyRepository)(nil)
+
+// PasskeyChallengeRepository defines the interface for pending WebAuthn ceremonies.
+type PasskeyChallengeRepository interface {
+	// Create creates a new passkey challenge.
+	Create(ctx context.Context, challenge *domain.PasskeyChallenge) error
+
+	// FindByChallenge retrieves a challenge by its hash.
+	FindByChallenge(ctx context.Context, challengeHash string) (*domain.PasskeyChallenge, error)
+
+	// MarkUsed marks a challenge as used.
+	MarkUsed(ctx context.Context, id uuid.UUID) error
+
+	// DeleteExpired removes all expired challenges.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// GormPasskeyChallengeRepository is a GORM implementation of PasskeyChallengeRepository.
+type GormPasskeyChallengeRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPasskeyChallengeRepository creates a new GormPasskeyChallengeRepository.
+func NewGormPasskeyChallengeRepository(db *gorm.DB) *GormPasskeyChallengeRepository {
+	return &GormPasskeyChallengeRepository{db: db}
+}
+
+// Create creates a new passkey challenge.
+func (r *GormPasskeyChallengeRepository) Create(ctx context.Context, challenge *domain.PasskeyChallenge) error {
+	if challenge.ID == uuid.Nil {
+		challenge.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(challenge).Error
+}
+
+// FindByChallenge retrieves a challenge by its hash.
+func (r *GormPasskeyChallengeRepository) FindByChallenge(ctx context.Context, challengeHash string) (*domain.PasskeyChallenge, error) {
+	var challenge domain.PasskeyChallenge
+	if err := r.db.WithContext(ctx).First(&challenge, "challenge_hash = ?", challengeHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPasskeyChallengeInvalid
+		}
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// MarkUsed marks a challenge as used.
+func (r *GormPasskeyChallengeRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.PasskeyChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPasskeyChallengeInvalid
+	}
+	return nil
+}
+
+// DeleteExpired removes all expired challenges.
+func (r *GormPasskeyChallengeRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now()).
+		Delete(&domain.PasskeyChallenge{})
+	return result.RowsAffected, result.Error
+}
+
+// Ensure GormPasskeyChallengeRepository implements PasskeyChallengeRepository
+var _ PasskeyChallengeRepository = (*GormPasskeyChallengeRepository)(nil)
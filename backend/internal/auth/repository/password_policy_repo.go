@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// PasswordPolicyRepository defines the interface for tenant password
+// policy data access.
+type PasswordPolicyRepository interface {
+	// Create creates a tenant's password policy.
+	Create(ctx context.Context, policy *domain.PasswordPolicy) error
+
+	// FindByTenant retrieves a tenant's password policy.
+	FindByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.PasswordPolicy, error)
+
+	// ListByTenants retrieves the password policies of the given tenants.
+	// Tenants without one are left out.
+	ListByTenants(ctx context.Context, tenantIDs []uuid.UUID) ([]*domain.PasswordPolicy, error)
+
+	// Update updates a tenant's password policy.
+	Update(ctx context.Context, policy *domain.PasswordPolicy) error
+}
+
+// GormPasswordPolicyRepository is a GORM implementation of PasswordPolicyRepository.
+type GormPasswordPolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPasswordPolicyRepository creates a new GormPasswordPolicyRepository.
+func NewGormPasswordPolicyRepository(db *gorm.DB) *GormPasswordPolicyRepository {
+	return &GormPasswordPolicyRepository{db: db}
+}
+
+// Create creates a tenant's password policy.
+func (r *GormPasswordPolicyRepository) Create(ctx context.Context, policy *domain.PasswordPolicy) error {
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(policy).Error
+}
+
+// FindByTenant retrieves a tenant's password policy.
+func (r *GormPasswordPolicyRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.PasswordPolicy, error) {
+	var policy domain.PasswordPolicy
+	if err := r.db.WithContext(ctx).First(&policy, "tenant_id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPasswordPolicyNotFound
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ListByTenants retrieves the password policies of the given tenants.
+func (r *GormPasswordPolicyRepository) ListByTenants(ctx context.Context, tenantIDs []uuid.UUID) ([]*domain.PasswordPolicy, error) {
+	var policies []*domain.PasswordPolicy
+	if len(tenantIDs) == 0 {
+		return policies, nil
+	}
+	err := r.db.WithContext(ctx).Where("tenant_id IN ?", tenantIDs).Find(&policies).Error
+	return policies, err
+}
+
+// Update updates a tenant's password policy.
+func (r *GormPasswordPolicyRepository) Update(ctx context.Context, policy *domain.PasswordPolicy) error {
+	return r.db.WithContext(ctx).Save(policy).Error
+}
+
+// Ensure GormPasswordPolicyRepository implements PasswordPolicyRepository
+var _ PasswordPolicyRepository = (*GormPasswordPolicyRepository)(nil)
+
+// PasswordHistoryRepository defines the interface for the previous
+// password hashes kept to prevent reuse.
+type PasswordHistoryRepository interface {
+	// Create records a password hash a user has replaced.
+	Create(ctx context.Context, entry *domain.PasswordHistoryEntry) error
+
+	// ListRecent retrieves a user's most recently replaced password hashes,
+	// newest first.
+	ListRecent(ctx context.Context, userID uuid.UUID, limit int) ([]*domain.PasswordHistoryEntry, error)
+
+	// Prune removes all but a user's keep most recent entries.
+	Prune(ctx context.Context, userID uuid.UUID, keep int) error
+}
+
+// GormPasswordHistoryRepository is a GORM implementation of PasswordHistoryRepository.
+type GormPasswordHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPasswordHistoryRepository creates a new GormPasswordHistoryRepository.
+func NewGormPasswordHistoryRepository(db *gorm.DB) *GormPasswordHistoryRepository {
+	return &GormPasswordHistoryRepository{db: db}
+}
+
+// Create records a password hash a user has replaced.
+func (r *GormPasswordHistoryRepository) Create(ctx context.Context, entry *domain.PasswordHistoryEntry) error {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// ListRecent retrieves a user's most recently replaced password hashes.
+func (r *GormPasswordHistoryRepository) ListRecent(ctx context.Context, userID uuid.UUID, limit int) ([]*domain.PasswordHistoryEntry, error) {
+	var entries []*domain.PasswordHistoryEntry
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+// Prune removes all but a user's keep most recent entries.
+func (r *GormPasswordHistoryRepository) Prune(ctx context.Context, userID uuid.UUID, keep int) error {
+	recent := r.db.Model(&domain.PasswordHistoryEntry{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(keep)
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND id NOT IN (?)", userID, recent).
+		Delete(&domain.PasswordHistoryEntry{}).Error
+}
+
+// Ensure GormPasswordHistoryRepository implements PasswordHistoryRepository
+var _ PasswordHistoryRepository = (*GormPasswordHistoryRepository)(nil)
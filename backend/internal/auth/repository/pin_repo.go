@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// StaffPINRepository defines the interface for staff PIN data access.
+type StaffPINRepository interface {
+	// FindByUserAndTenant retrieves a user's PIN for a tenant.
+	FindByUserAndTenant(ctx context.Context, userID, tenantID uuid.UUID) (*domain.StaffPIN, error)
+
+	// Save stores a user's PIN for a tenant, replacing any existing one.
+	Save(ctx context.Context, pin *domain.StaffPIN) error
+
+	// Update updates an existing PIN (failed attempts and lockout).
+	Update(ctx context.Context, pin *domain.StaffPIN) error
+
+	// Delete removes a user's PIN for a tenant.
+	Delete(ctx context.Context, userID, tenantID uuid.UUID) error
+
+	// ListByTenant lists every PIN set in a tenant.
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.StaffPIN, error)
+}
+
+// GormStaffPINRepository is a GORM implementation of StaffPINRepository.
+type GormStaffPINRepository struct {
+	db *gorm.DB
+}
+
+// NewGormStaffPINRepository creates a new GormStaffPINRepository.
+func NewGormStaffPINRepository(db *gorm.DB) *GormStaffPINRepository {
+	return &GormStaffPINRepository{db: db}
+}
+
+// FindByUserAndTenant retrieves a user's PIN for a tenant.
+func (r *GormStaffPINRepository) FindByUserAndTenant(ctx context.Context, userID, tenantID uuid.UUID) (*domain.StaffPIN, error) {
+	var pin domain.StaffPIN
+	if err := r.db.WithContext(ctx).First(&pin, "user_id = ? AND tenant_id = ?", userID, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPINNotSet
+		}
+		return nil, err
+	}
+	return &pin, nil
+}
+
+// Save stores a user's PIN for a tenant, replacing any existing one.
+func (r *GormStaffPINRepository) Save(ctx context.Context, pin *domain.StaffPIN) error {
+	if pin.ID == uuid.Nil {
+		pin.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND tenant_id = ?", pin.UserID, pin.TenantID).Delete(&domain.StaffPIN{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(pin).Error
+	})
+}
+
+// Update updates an existing PIN.
+func (r *GormStaffPINRepository) Update(ctx context.Context, pin *domain.StaffPIN) error {
+	return r.db.WithContext(ctx).Save(pin).Error
+}
+
+// Delete removes a user's PIN for a tenant.
+func (r *GormStaffPINRepository) Delete(ctx context.Context, userID, tenantID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND tenant_id = ?", userID, tenantID).
+		Delete(&domain.StaffPIN{}).Error
+}
+
+// ListByTenant lists every PIN set in a tenant.
+func (r *GormStaffPINRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.StaffPIN, error) {
+	var pins []*domain.StaffPIN
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at ASC").
+		Find(&pins).Error
+	return pins, err
+}
+
+// Ensure GormStaffPINRepository implements StaffPINRepository
+var _ StaffPINRepository = (*GormStaffPINRepository)(nil)
+
+// TerminalRepository defines the interface for registered POS terminal data access.
+type TerminalRepository interface {
+	// Create registers a new terminal.
+	Create(ctx context.Context, terminal *domain.Terminal) error
+
+	// FindByID retrieves a terminal by ID.
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.Terminal, error)
+
+	// FindByToken retrieves a terminal by its device token hash.
+	FindByToken(ctx context.Context, tokenHash string) (*domain.Terminal, error)
+
+	// ListByTenant lists a tenant's terminals that have not been revoked.
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.Terminal, error)
+
+	// Revoke revokes a terminal so its device token stops working.
+	Revoke(ctx context.Context, id uuid.UUID) error
+
+	// TouchLastUsed records when a terminal was last used to log in.
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// GormTerminalRepository is a GORM implementation of TerminalRepository.
+type GormTerminalRepository struct {
+	db *gorm.DB
+}
+
+// NewGormTerminalRepository creates a new GormTerminalRepository.
+func NewGormTerminalRepository(db *gorm.DB) *GormTerminalRepository {
+	return &GormTerminalRepository{db: db}
+}
+
+// Create registers a new terminal.
+func (r *GormTerminalRepository) Create(ctx context.Context, terminal *domain.Terminal) error {
+	if terminal.ID == uuid.Nil {
+		terminal.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(terminal).Error
+}
+
+// FindByID retrieves a terminal by ID.
+func (r *GormTerminalRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Terminal, error) {
+	var terminal domain.Terminal
+	if err := r.db.WithContext(ctx).First(&terminal, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrTerminalNotFound
+		}
+		return nil, err
+	}
+	return &terminal, nil
+}
+
+// FindByToken retrieves a terminal by its device token hash.
+func (r *GormTerminalRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.Terminal, error) {
+	var terminal domain.Terminal
+	if err := r.db.WithContext(ctx).First(&terminal, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrTerminalNotFound
+		}
+		return nil, err
+	}
+	return &terminal, nil
+}
+
+// ListByTenant lists a tenant's terminals that have not been revoked.
+func (r *GormTerminalRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.Terminal, error) {
+	var terminals []*domain.Terminal
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND revoked_at IS NULL", tenantID).
+		Order("created_at ASC").
+		Find(&terminals).Error
+	return terminals, err
+}
+
+// Revoke revokes a terminal.
+func (r *GormTerminalRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Terminal{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrTerminalNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records when a terminal was last used to log in.
+func (r *GormTerminalRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Terminal{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
+
+// Ensure GormTerminalRepository implements TerminalRepository
+var _ TerminalRepository = (*GormTerminalRepository)(nil)
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strconv"
 	"testing"
 	"time"
 
@@ -43,6 +44,10 @@ func setupTestDB(t *testing.T) *gorm.DB {
 			must_reset_pwd INTEGER DEFAULT 0,
 			failed_login_count INTEGER DEFAULT 0,
 			locked_until DATETIME,
+			known_device_failed_count INTEGER DEFAULT 0,
+			known_device_locked_until DATETIME,
+			email_verified_at DATETIME,
+			password_changed_at DATETIME,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		);
@@ -62,15 +67,36 @@ func setupTestDB(t *testing.T) *gorm.DB {
 			user_id TEXT NOT NULL,
 			tenant_id TEXT NOT NULL,
 			role TEXT NOT NULL,
+			custom_role_id TEXT,
+			elevated_role TEXT NOT NULL DEFAULT '',
+			elevated_custom_role_id TEXT,
+			valid_from DATETIME,
+			valid_until DATETIME,
+			elevated_by TEXT,
+			elevation_started_at DATETIME,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			UNIQUE(user_id, tenant_id)
 		);
 
+		CREATE TABLE IF NOT EXISTS custom_roles (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			base_role TEXT NOT NULL,
+			permissions TEXT NOT NULL DEFAULT '[]',
+			created_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
 		CREATE TABLE IF NOT EXISTS sessions (
 			id TEXT PRIMARY KEY,
 			user_id TEXT NOT NULL,
 			tenant_id TEXT NOT NULL,
+			family_id TEXT NOT NULL,
+			parent_id TEXT,
+			sign_in_method TEXT NOT NULL,
 			refresh_token TEXT UNIQUE NOT NULL,
 			device_info TEXT,
 			ip_address TEXT,
@@ -88,9 +114,64 @@ func setupTestDB(t *testing.T) *gorm.DB {
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		);
 
+		CREATE TABLE IF NOT EXISTS email_tokens (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			purpose TEXT NOT NULL,
+			email TEXT NOT NULL,
+			token_hash TEXT UNIQUE NOT NULL,
+			expires_at DATETIME NOT NULL,
+			used_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS tenant_password_policies (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT UNIQUE NOT NULL,
+			min_length INTEGER NOT NULL DEFAULT 8,
+			require_uppercase INTEGER NOT NULL DEFAULT 0,
+			require_lowercase INTEGER NOT NULL DEFAULT 0,
+			require_digit INTEGER NOT NULL DEFAULT 0,
+			require_symbol INTEGER NOT NULL DEFAULT 0,
+			max_age_days INTEGER NOT NULL DEFAULT 0,
+			history_count INTEGER NOT NULL DEFAULT 0,
+			updated_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS tenant_lockout_policies (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT UNIQUE NOT NULL,
+			unknown_device_threshold INTEGER NOT NULL DEFAULT 5,
+			known_device_threshold INTEGER NOT NULL DEFAULT 10,
+			base_delay_seconds INTEGER NOT NULL DEFAULT 60,
+			max_delay_seconds INTEGER NOT NULL DEFAULT 900,
+			updated_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS known_devices (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			token_hash TEXT UNIQUE NOT NULL,
+			device_info TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			last_seen_at DATETIME NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS password_history (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			password_hash TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
 		CREATE TABLE IF NOT EXISTS auth_events (
 			id TEXT PRIMARY KEY,
 			user_id TEXT,
+			actor_id TEXT,
 			tenant_id TEXT,
 			event_type TEXT NOT NULL,
 			ip_address TEXT,
@@ -98,6 +179,274 @@ func setupTestDB(t *testing.T) *gorm.DB {
 			metadata TEXT,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		);
+
+		CREATE TABLE IF NOT EXISTS mfa_factors (
+			id TEXT PRIMARY KEY,
+			user_id TEXT UNIQUE NOT NULL,
+			secret TEXT NOT NULL,
+			last_used_step INTEGER NOT NULL DEFAULT 0,
+			confirmed_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			code_hash TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			used_at DATETIME
+		);
+
+		CREATE TABLE IF NOT EXISTS mfa_challenges (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			tenant_id TEXT NOT NULL,
+			token_hash TEXT UNIQUE NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			expires_at DATETIME NOT NULL,
+			used_at DATETIME
+		);
+
+		CREATE TABLE IF NOT EXISTS revoked_access_tokens (
+			jti TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			revoked_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			expires_at DATETIME NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS user_token_revocations (
+			user_id TEXT PRIMARY KEY,
+			revoked_before DATETIME NOT NULL,
+			expires_at DATETIME NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS staff_pins (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			tenant_id TEXT NOT NULL,
+			pin_hash TEXT NOT NULL,
+			failed_attempts INTEGER NOT NULL DEFAULT 0,
+			locked_until DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(user_id, tenant_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS pos_terminals (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			token_hash TEXT UNIQUE NOT NULL,
+			created_by TEXT,
+			last_used_at DATETIME,
+			revoked_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS user_invitations (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			email TEXT NOT NULL,
+			first_name TEXT NOT NULL,
+			last_name TEXT NOT NULL,
+			role TEXT NOT NULL,
+			token_hash TEXT UNIQUE NOT NULL,
+			invited_by TEXT,
+			expires_at DATETIME NOT NULL,
+			accepted_at DATETIME,
+			revoked_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS ownership_transfers (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			from_user_id TEXT NOT NULL,
+			to_user_id TEXT NOT NULL,
+			token_hash TEXT UNIQUE NOT NULL,
+			expires_at DATETIME NOT NULL,
+			confirmed_at DATETIME,
+			cancelled_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS approvals (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			action TEXT NOT NULL,
+			resource_id TEXT NOT NULL,
+			requested_by TEXT NOT NULL,
+			approved_by TEXT NOT NULL,
+			method TEXT NOT NULL,
+			terminal_id TEXT,
+			token_hash TEXT UNIQUE NOT NULL,
+			expires_at DATETIME NOT NULL,
+			used_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS tenant_sso_configs (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT UNIQUE NOT NULL,
+			issuer TEXT NOT NULL,
+			client_id TEXT NOT NULL,
+			client_secret TEXT NOT NULL,
+			allowed_domains TEXT NOT NULL DEFAULT '[]',
+			password_login_disabled INTEGER NOT NULL DEFAULT 0,
+			updated_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS sso_login_states (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			state_hash TEXT UNIQUE NOT NULL,
+			nonce TEXT NOT NULL,
+			code_verifier TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			expires_at DATETIME NOT NULL,
+			used_at DATETIME
+		);
+
+		CREATE TABLE IF NOT EXISTS user_identities (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			issuer TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			email TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			last_login_at DATETIME,
+			UNIQUE(issuer, subject)
+		);
+
+		CREATE TABLE IF NOT EXISTS scim_tokens (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			token_hash TEXT UNIQUE NOT NULL,
+			created_by TEXT,
+			last_used_at DATETIME,
+			revoked_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS scim_user_links (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			external_id TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(tenant_id, user_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS service_accounts (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			scopes TEXT NOT NULL DEFAULT '[]',
+			created_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id TEXT PRIMARY KEY,
+			service_account_id TEXT NOT NULL,
+			tenant_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			prefix TEXT NOT NULL,
+			key_hash TEXT UNIQUE NOT NULL,
+			expires_at DATETIME NOT NULL,
+			last_used_at DATETIME,
+			revoked_at DATETIME,
+			rotated_from_id TEXT,
+			created_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS oauth_clients (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			redirect_uris TEXT NOT NULL DEFAULT '[]',
+			scopes TEXT NOT NULL DEFAULT '[]',
+			public INTEGER NOT NULL DEFAULT 0,
+			secret_hash TEXT NOT NULL DEFAULT '',
+			created_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS oauth_consents (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			client_id TEXT NOT NULL,
+			granted_by TEXT NOT NULL,
+			scopes TEXT NOT NULL DEFAULT '[]',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			revoked_at DATETIME
+		);
+
+		CREATE TABLE IF NOT EXISTS oauth_authorization_codes (
+			id TEXT PRIMARY KEY,
+			code_hash TEXT UNIQUE NOT NULL,
+			client_id TEXT NOT NULL,
+			tenant_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			redirect_uri TEXT NOT NULL,
+			scopes TEXT NOT NULL DEFAULT '[]',
+			code_challenge TEXT NOT NULL,
+			expires_at DATETIME NOT NULL,
+			used_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS oauth_refresh_tokens (
+			id TEXT PRIMARY KEY,
+			token_hash TEXT UNIQUE NOT NULL,
+			client_id TEXT NOT NULL,
+			tenant_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			family_id TEXT NOT NULL,
+			scopes TEXT NOT NULL DEFAULT '[]',
+			expires_at DATETIME NOT NULL,
+			revoked_at DATETIME,
+			replaced_by_id TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS passkeys (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			credential_id BLOB UNIQUE NOT NULL,
+			public_key BLOB NOT NULL,
+			sign_count INTEGER NOT NULL DEFAULT 0,
+			aaguid BLOB,
+			name TEXT NOT NULL,
+			transports TEXT NOT NULL DEFAULT '[]',
+			backup_eligible INTEGER NOT NULL DEFAULT 0,
+			backed_up INTEGER NOT NULL DEFAULT 0,
+			last_used_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS passkey_challenges (
+			id TEXT PRIMARY KEY,
+			challenge_hash TEXT UNIQUE NOT NULL,
+			purpose TEXT NOT NULL,
+			user_id TEXT,
+			mfa_challenge_id TEXT,
+			expires_at DATETIME NOT NULL,
+			used_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
 	`
 
 	_, err = sqlDB.Exec(schema)
@@ -229,6 +578,7 @@ func TestGormSessionRepository_Create(t *testing.T) {
 		UserID:       uuid.New(),
 		TenantID:     uuid.New(),
 		RefreshToken: "token_hash",
+		SignInMethod: domain.SignInSSO,
 		ExpiresAt:    time.Now().Add(time.Hour),
 	}
 
@@ -241,7 +591,7 @@ func TestGormSessionRepository_Create(t *testing.T) {
 	if err != nil {
 		t.Fatalf("FindByID failed: %v", err)
 	}
-	if found.RefreshToken != session.RefreshToken {
+	if found.RefreshToken != session.RefreshToken || found.SignInMethod != domain.SignInSSO {
 		t.Error("Session not saved correctly")
 	}
 }
@@ -392,8 +742,9 @@ func TestGormAuthEventRepository_Create(t *testing.T) {
 	repo := NewGormAuthEventRepository(db)
 	ctx := context.Background()
 
-	userID := uuid.New()
+	userID, actorID := uuid.New(), uuid.New()
 	event := domain.NewAuthEvent(domain.EventLoginSuccess, &userID, nil, "127.0.0.1", "TestAgent")
+	event.ActorID = &actorID
 
 	err := repo.Create(ctx, event)
 	if err != nil {
@@ -408,7 +759,10 @@ func TestGormAuthEventRepository_Create(t *testing.T) {
 		t.Errorf("Total = %d, want 1", total)
 	}
 	if len(events) != 1 {
-		t.Errorf("len(events) = %d, want 1", len(events))
+		t.Fatalf("len(events) = %d, want 1", len(events))
+	}
+	if events[0].ActorID == nil || *events[0].ActorID != actorID {
+		t.Errorf("ActorID = %v, want %v", events[0].ActorID, actorID)
 	}
 }
 
@@ -464,6 +818,54 @@ func TestGormPasswordResetRepository_MarkUsed(t *testing.T) {
 	}
 }
 
+func TestGormEmailTokenRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormEmailTokenRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	token := &domain.EmailToken{
+		UserID:    userID,
+		Purpose:   domain.EmailTokenChange,
+		Email:     "new@example.com",
+		TokenHash: "email_change_test",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := repo.Create(ctx, token); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	repo.Create(ctx, &domain.EmailToken{UserID: userID, Purpose: domain.EmailTokenVerify, Email: "old@example.com", TokenHash: "email_verify_test", ExpiresAt: time.Now().Add(time.Hour)})
+	repo.Create(ctx, &domain.EmailToken{UserID: userID, Purpose: domain.EmailTokenVerify, Email: "old@example.com", TokenHash: "email_expired_test", ExpiresAt: time.Now().Add(-time.Hour)})
+
+	found, err := repo.FindByToken(ctx, "email_change_test")
+	if err != nil || found.Email != "new@example.com" || found.Purpose != domain.EmailTokenChange {
+		t.Fatalf("FindByToken = %+v, %v", found, err)
+	}
+	if _, err := repo.FindByToken(ctx, "missing"); err != domain.ErrEmailTokenInvalid {
+		t.Errorf("FindByToken error = %v, want ErrEmailTokenInvalid", err)
+	}
+
+	if err := repo.MarkUsed(ctx, token.ID); err != nil {
+		t.Fatalf("MarkUsed failed: %v", err)
+	}
+	if err := repo.MarkUsed(ctx, token.ID); err != domain.ErrEmailTokenUsed {
+		t.Errorf("second MarkUsed error = %v, want ErrEmailTokenUsed", err)
+	}
+
+	if deleted, _ := repo.DeleteExpired(ctx); deleted != 1 {
+		t.Errorf("DeleteExpired = %d, want 1", deleted)
+	}
+	if err := repo.DeleteForUser(ctx, userID, domain.EmailTokenVerify); err != nil {
+		t.Fatalf("DeleteForUser failed: %v", err)
+	}
+	if _, err := repo.FindByToken(ctx, "email_verify_test"); err != domain.ErrEmailTokenInvalid {
+		t.Error("DeleteForUser should remove tokens with the purpose")
+	}
+	if _, err := repo.FindByToken(ctx, "email_change_test"); err != nil {
+		t.Error("DeleteForUser should keep tokens with other purposes")
+	}
+}
+
 // ============ UserTenantRole Repository Tests ============
 
 func TestGormUserTenantRoleRepository_Create(t *testing.T) {
@@ -526,49 +928,139 @@ func TestGormUserTenantRoleRepository_Update(t *testing.T) {
 	}
 }
 
-func TestGormUserTenantRoleRepository_Delete(t *testing.T) {
+func TestGormUserTenantRoleRepository_TransferOwnership(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewGormUserTenantRoleRepository(db)
 	ctx := context.Background()
 
-	userID, tenantID := uuid.New(), uuid.New()
-	role := &domain.UserTenantRole{ID: uuid.New(), UserID: userID, TenantID: tenantID, Role: domain.RoleWaiter}
-	repo.Create(ctx, role)
+	tenantID := uuid.New()
+	ownerID, managerID := uuid.New(), uuid.New()
+	repo.Create(ctx, &domain.UserTenantRole{UserID: ownerID, TenantID: tenantID, Role: domain.RoleOwner})
+	repo.Create(ctx, &domain.UserTenantRole{UserID: managerID, TenantID: tenantID, Role: domain.RoleManager})
 
-	if err := repo.Delete(ctx, role.ID); err != nil {
-		t.Fatalf("Delete failed: %v", err)
+	if _, err := repo.TransferOwnership(ctx, tenantID, ownerID, uuid.New()); err != domain.ErrUserNotInTenant {
+		t.Errorf("TransferOwnership to non-member error = %v, want ErrUserNotInTenant", err)
+	}
+	if _, err := repo.TransferOwnership(ctx, tenantID, managerID, ownerID); err != domain.ErrOwnershipTransferInvalid {
+		t.Errorf("TransferOwnership from non-owner error = %v, want ErrOwnershipTransferInvalid", err)
 	}
 
-	if _, err := repo.FindByUserAndTenant(ctx, userID, tenantID); err != domain.ErrUserNotInTenant {
-		t.Errorf("Expected ErrUserNotInTenant after delete, got %v", err)
+	previousRole, err := repo.TransferOwnership(ctx, tenantID, ownerID, managerID)
+	if err != nil {
+		t.Fatalf("TransferOwnership failed: %v", err)
+	}
+	if previousRole != domain.RoleManager {
+		t.Errorf("previous role = %v, want %v", previousRole, domain.RoleManager)
+	}
+
+	newOwner, _ := repo.FindByUserAndTenant(ctx, managerID, tenantID)
+	if newOwner.Role != domain.RoleOwner {
+		t.Errorf("recipient role = %v, want %v", newOwner.Role, domain.RoleOwner)
+	}
+	oldOwner, _ := repo.FindByUserAndTenant(ctx, ownerID, tenantID)
+	if oldOwner.Role != domain.RoleManager {
+		t.Errorf("previous owner role = %v, want %v", oldOwner.Role, domain.RoleManager)
 	}
 }
 
-func TestGormUserTenantRoleRepository_DeleteByUserAndTenant(t *testing.T) {
+func TestGormUserTenantRoleRepository_UnlessLastOwner(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewGormUserTenantRoleRepository(db)
+	userRepo := NewGormUserRepository(db)
 	ctx := context.Background()
 
-	userID, tenantID := uuid.New(), uuid.New()
-	role := &domain.UserTenantRole{ID: uuid.New(), UserID: userID, TenantID: tenantID, Role: domain.RoleWaiter}
-	repo.Create(ctx, role)
+	tenantID := uuid.New()
+	owner := &domain.User{ID: uuid.New(), Email: "owner@test.com", IsActive: true}
+	disabled := &domain.User{ID: uuid.New(), Email: "disabled@test.com", IsActive: true}
+	userRepo.Create(ctx, owner)
+	userRepo.Create(ctx, disabled)
+	db.Model(&domain.User{}).Where("id = ?", disabled.ID).Update("is_active", false)
+	ownerRole := &domain.UserTenantRole{ID: uuid.New(), UserID: owner.ID, TenantID: tenantID, Role: domain.RoleOwner}
+	repo.Create(ctx, ownerRole)
+	repo.Create(ctx, &domain.UserTenantRole{UserID: disabled.ID, TenantID: tenantID, Role: domain.RoleOwner})
 
-	if err := repo.DeleteByUserAndTenant(ctx, userID, tenantID); err != nil {
-		t.Fatalf("DeleteByUserAndTenant failed: %v", err)
+	// A disabled second owner doesn't count
+	ownerRole.Role = domain.RoleManager
+	if err := repo.UpdateUnlessLastOwner(ctx, ownerRole); err != domain.ErrLastOwner {
+		t.Errorf("UpdateUnlessLastOwner error = %v, want ErrLastOwner", err)
+	}
+	if err := repo.DeleteUnlessLastOwner(ctx, owner.ID, tenantID); err != domain.ErrLastOwner {
+		t.Errorf("DeleteUnlessLastOwner error = %v, want ErrLastOwner", err)
+	}
+	if err := repo.DeactivateUnlessLastOwner(ctx, owner.ID); err != domain.ErrLastOwner {
+		t.Errorf("DeactivateUnlessLastOwner error = %v, want ErrLastOwner", err)
+	}
+	found, _ := repo.FindByUserAndTenant(ctx, owner.ID, tenantID)
+	if found.Role != domain.RoleOwner {
+		t.Errorf("Role = %v, want %v", found.Role, domain.RoleOwner)
+	}
+	if user, _ := userRepo.FindByID(ctx, owner.ID); !user.IsActive {
+		t.Error("Owner should stay active")
 	}
 
-	if err := repo.DeleteByUserAndTenant(ctx, userID, tenantID); err != domain.ErrUserNotInTenant {
-		t.Errorf("Expected ErrUserNotInTenant on repeat delete, got %v", err)
+	// Once the other owner is active again the first one can step down
+	db.Model(&domain.User{}).Where("id = ?", disabled.ID).Update("is_active", true)
+	if err := repo.UpdateUnlessLastOwner(ctx, ownerRole); err != nil {
+		t.Fatalf("UpdateUnlessLastOwner failed: %v", err)
+	}
+	found, _ = repo.FindByUserAndTenant(ctx, owner.ID, tenantID)
+	if found.Role != domain.RoleManager {
+		t.Errorf("Role = %v, want %v", found.Role, domain.RoleManager)
+	}
+	if err := repo.DeactivateUnlessLastOwner(ctx, disabled.ID); err != domain.ErrLastOwner {
+		t.Errorf("DeactivateUnlessLastOwner of the remaining owner error = %v, want ErrLastOwner", err)
+	}
+	if err := repo.DeactivateUnlessLastOwner(ctx, owner.ID); err != nil {
+		t.Errorf("DeactivateUnlessLastOwner of a non-owner failed: %v", err)
+	}
+	if user, _ := userRepo.FindByID(ctx, owner.ID); user.IsActive {
+		t.Error("DeactivateUnlessLastOwner should disable the account")
 	}
 }
 
-func TestGormUserTenantRoleRepository_ListByUserAndListByTenant(t *testing.T) {
+func TestGormUserTenantRoleRepository_Delete(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewGormUserTenantRoleRepository(db)
 	ctx := context.Background()
 
 	userID, tenantID := uuid.New(), uuid.New()
-	repo.Create(ctx, &domain.UserTenantRole{ID: uuid.New(), UserID: userID, TenantID: tenantID, Role: domain.RoleWaiter})
+	role := &domain.UserTenantRole{ID: uuid.New(), UserID: userID, TenantID: tenantID, Role: domain.RoleWaiter}
+	repo.Create(ctx, role)
+
+	if err := repo.Delete(ctx, role.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	if _, err := repo.FindByUserAndTenant(ctx, userID, tenantID); err != domain.ErrUserNotInTenant {
+		t.Errorf("Expected ErrUserNotInTenant after delete, got %v", err)
+	}
+}
+
+func TestGormUserTenantRoleRepository_DeleteByUserAndTenant(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormUserTenantRoleRepository(db)
+	ctx := context.Background()
+
+	userID, tenantID := uuid.New(), uuid.New()
+	role := &domain.UserTenantRole{ID: uuid.New(), UserID: userID, TenantID: tenantID, Role: domain.RoleWaiter}
+	repo.Create(ctx, role)
+
+	if err := repo.DeleteByUserAndTenant(ctx, userID, tenantID); err != nil {
+		t.Fatalf("DeleteByUserAndTenant failed: %v", err)
+	}
+
+	if err := repo.DeleteByUserAndTenant(ctx, userID, tenantID); err != domain.ErrUserNotInTenant {
+		t.Errorf("Expected ErrUserNotInTenant on repeat delete, got %v", err)
+	}
+}
+
+func TestGormUserTenantRoleRepository_ListByUserAndListByTenant(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormUserTenantRoleRepository(db)
+	ctx := context.Background()
+
+	userID, tenantID := uuid.New(), uuid.New()
+	repo.Create(ctx, &domain.UserTenantRole{ID: uuid.New(), UserID: userID, TenantID: tenantID, Role: domain.RoleWaiter})
 	repo.Create(ctx, &domain.UserTenantRole{ID: uuid.New(), UserID: userID, TenantID: uuid.New(), Role: domain.RoleCashier})
 
 	byUser, err := repo.ListByUser(ctx, userID)
@@ -703,6 +1195,161 @@ func TestGormSessionRepository_RevokeAllForUserInTenant(t *testing.T) {
 	}
 }
 
+func TestGormSessionRepository_RevokeForUserInTenantSince(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormSessionRepository(db)
+	ctx := context.Background()
+
+	userID, tenantA, tenantB := uuid.New(), uuid.New(), uuid.New()
+	since := time.Now().Add(-time.Hour)
+	newSession := func(tenantID uuid.UUID, createdAt time.Time) {
+		repo.Create(ctx, &domain.Session{ID: uuid.New(), UserID: userID, TenantID: tenantID, RefreshToken: uuid.New().String(), ExpiresAt: time.Now().Add(time.Hour), CreatedAt: createdAt})
+	}
+	newSession(tenantA, since.Add(-time.Minute)) // before: kept
+	newSession(tenantA, since)                   // at: revoked
+	newSession(tenantA, since.Add(time.Minute))  // after: revoked
+	newSession(tenantB, since.Add(time.Minute))  // other tenant: kept
+
+	revoked, err := repo.RevokeForUserInTenantSince(ctx, userID, tenantA, since)
+	if err != nil {
+		t.Fatalf("RevokeForUserInTenantSince failed: %v", err)
+	}
+	if revoked != 2 {
+		t.Errorf("revoked = %d, want 2", revoked)
+	}
+
+	if count, _ := repo.CountActiveForUser(ctx, userID); count != 2 {
+		t.Errorf("Active sessions = %d, want 2 (the older one and tenantB's)", count)
+	}
+}
+
+func TestGormSessionRepository_Family(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormSessionRepository(db)
+	ctx := context.Background()
+
+	root := &domain.Session{
+		ID:           uuid.New(),
+		UserID:       uuid.New(),
+		TenantID:     uuid.New(),
+		RefreshToken: "family_root",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	if err := repo.Create(ctx, root); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if root.FamilyID != root.ID {
+		t.Errorf("FamilyID = %v, want the session's own ID %v", root.FamilyID, root.ID)
+	}
+
+	child := root.Rotate(time.Now().Add(time.Hour))
+	child.RefreshToken = "family_child"
+	repo.Create(ctx, child)
+	other := &domain.Session{ID: uuid.New(), UserID: root.UserID, TenantID: root.TenantID, RefreshToken: "other_family", ExpiresAt: time.Now().Add(time.Hour)}
+	repo.Create(ctx, other)
+
+	rotated, err := repo.ExistsByParent(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("ExistsByParent failed: %v", err)
+	}
+	if !rotated {
+		t.Error("Root session should have a child")
+	}
+	if rotated, _ := repo.ExistsByParent(ctx, child.ID); rotated {
+		t.Error("Newest session should have no child")
+	}
+
+	revoked, err := repo.RevokeFamily(ctx, root.FamilyID)
+	if err != nil {
+		t.Fatalf("RevokeFamily failed: %v", err)
+	}
+	if revoked != 2 {
+		t.Errorf("RevokeFamily = %d, want 2", revoked)
+	}
+
+	found, _ := repo.FindByID(ctx, child.ID)
+	if !found.IsRevoked() {
+		t.Error("Child session should be revoked")
+	}
+	found, _ = repo.FindByID(ctx, other.ID)
+	if found.IsRevoked() {
+		t.Error("Session in another family should not be revoked")
+	}
+}
+
+func TestGormSessionRepository_ListActive(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormSessionRepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	tenantA := uuid.New()
+	tenantB := uuid.New()
+	now := time.Now()
+
+	older := &domain.Session{ID: uuid.New(), UserID: userID, TenantID: tenantA, RefreshToken: "older", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}
+	newer := &domain.Session{ID: uuid.New(), UserID: userID, TenantID: tenantB, RefreshToken: "newer", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	revoked := &domain.Session{ID: uuid.New(), UserID: userID, TenantID: tenantA, RefreshToken: "revoked", ExpiresAt: now.Add(time.Hour)}
+	expired := &domain.Session{ID: uuid.New(), UserID: userID, TenantID: tenantA, RefreshToken: "expired", ExpiresAt: now.Add(-time.Hour)}
+	otherUser := &domain.Session{ID: uuid.New(), UserID: uuid.New(), TenantID: tenantA, RefreshToken: "other_user", ExpiresAt: now.Add(time.Hour)}
+	for _, s := range []*domain.Session{older, newer, revoked, expired, otherUser} {
+		if err := repo.Create(ctx, s); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	repo.Revoke(ctx, revoked.ID)
+
+	sessions, err := repo.ListActiveForUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListActiveForUser failed: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("ListActiveForUser returned %d sessions, want 2", len(sessions))
+	}
+	if sessions[0].ID != newer.ID || sessions[1].ID != older.ID {
+		t.Error("Sessions should be ordered newest first")
+	}
+
+	sessions, err = repo.ListActiveForUserInTenant(ctx, userID, tenantA)
+	if err != nil {
+		t.Fatalf("ListActiveForUserInTenant failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != older.ID {
+		t.Errorf("ListActiveForUserInTenant = %v, want only the active tenant A session", sessions)
+	}
+}
+
+func TestGormSessionRepository_RevokeAllForUserExcept(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormSessionRepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	keep := &domain.Session{ID: uuid.New(), UserID: userID, TenantID: uuid.New(), RefreshToken: "keep", ExpiresAt: time.Now().Add(time.Hour)}
+	drop := &domain.Session{ID: uuid.New(), UserID: userID, TenantID: uuid.New(), RefreshToken: "drop", ExpiresAt: time.Now().Add(time.Hour)}
+	otherUser := &domain.Session{ID: uuid.New(), UserID: uuid.New(), TenantID: uuid.New(), RefreshToken: "other_user", ExpiresAt: time.Now().Add(time.Hour)}
+	repo.Create(ctx, keep)
+	repo.Create(ctx, drop)
+	repo.Create(ctx, otherUser)
+
+	if err := repo.RevokeAllForUserExcept(ctx, userID, keep.ID); err != nil {
+		t.Fatalf("RevokeAllForUserExcept failed: %v", err)
+	}
+
+	found, _ := repo.FindByID(ctx, keep.ID)
+	if found.IsRevoked() {
+		t.Error("Kept session should not be revoked")
+	}
+	found, _ = repo.FindByID(ctx, drop.ID)
+	if !found.IsRevoked() {
+		t.Error("Other session should be revoked")
+	}
+	found, _ = repo.FindByID(ctx, otherUser.ID)
+	if found.IsRevoked() {
+		t.Error("Another user's session should not be revoked")
+	}
+}
+
 func TestGormSessionRepository_DeleteExpired(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewGormSessionRepository(db)
@@ -1009,3 +1656,1645 @@ func TestGormPasswordResetRepository_CountRecentForUser(t *testing.T) {
 		t.Errorf("CountRecentForUser = %d, want 2", count)
 	}
 }
+
+// ============ MFA Repository Tests ============
+
+func TestGormMFARepository_SaveAndFindFactor(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormMFARepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	if _, err := repo.FindFactorByUser(ctx, userID); err != domain.ErrMFANotEnrolled {
+		t.Fatalf("FindFactorByUser error = %v, want ErrMFANotEnrolled", err)
+	}
+
+	first := &domain.MFAFactor{UserID: userID, Secret: "FIRSTSECRET"}
+	if err := repo.SaveFactor(ctx, first); err != nil {
+		t.Fatalf("SaveFactor failed: %v", err)
+	}
+	if first.ID == uuid.Nil {
+		t.Error("SaveFactor should generate an ID")
+	}
+
+	// Saving again replaces the pending factor rather than violating the unique user index
+	second := &domain.MFAFactor{UserID: userID, Secret: "SECONDSECRET"}
+	if err := repo.SaveFactor(ctx, second); err != nil {
+		t.Fatalf("SaveFactor (replace) failed: %v", err)
+	}
+
+	found, err := repo.FindFactorByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("FindFactorByUser failed: %v", err)
+	}
+	if found.Secret != "SECONDSECRET" {
+		t.Errorf("Secret = %s, want SECONDSECRET", found.Secret)
+	}
+	if found.IsConfirmed() {
+		t.Error("New factor should not be confirmed")
+	}
+}
+
+func TestGormMFARepository_UpdateFactor(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormMFARepository(db)
+	ctx := context.Background()
+
+	factor := &domain.MFAFactor{UserID: uuid.New(), Secret: "SECRET"}
+	repo.SaveFactor(ctx, factor)
+
+	factor.Confirm()
+	factor.LastUsedStep = 42
+	if err := repo.UpdateFactor(ctx, factor); err != nil {
+		t.Fatalf("UpdateFactor failed: %v", err)
+	}
+
+	found, _ := repo.FindFactorByUser(ctx, factor.UserID)
+	if !found.IsConfirmed() {
+		t.Error("Factor should be confirmed")
+	}
+	if found.LastUsedStep != 42 {
+		t.Errorf("LastUsedStep = %d, want 42", found.LastUsedStep)
+	}
+}
+
+func TestGormMFARepository_AdvanceStep(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormMFARepository(db)
+	ctx := context.Background()
+
+	factor := &domain.MFAFactor{UserID: uuid.New(), Secret: "SECRET", LastUsedStep: 10}
+	repo.SaveFactor(ctx, factor)
+
+	if err := repo.AdvanceStep(ctx, factor.ID, 11); err != nil {
+		t.Fatalf("AdvanceStep failed: %v", err)
+	}
+	// The same or an earlier step is a replay
+	for _, step := range []int64{11, 5} {
+		if err := repo.AdvanceStep(ctx, factor.ID, step); err != domain.ErrMFACodeInvalid {
+			t.Errorf("AdvanceStep(%d) error = %v, want ErrMFACodeInvalid", step, err)
+		}
+	}
+
+	found, _ := repo.FindFactorByUser(ctx, factor.UserID)
+	if found.LastUsedStep != 11 {
+		t.Errorf("LastUsedStep = %d, want 11", found.LastUsedStep)
+	}
+}
+
+func TestGormMFARepository_RecoveryCodes(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormMFARepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	codes := []*domain.MFARecoveryCode{
+		{UserID: userID, CodeHash: "hash1"},
+		{UserID: userID, CodeHash: "hash2"},
+	}
+	if err := repo.ReplaceRecoveryCodes(ctx, userID, codes); err != nil {
+		t.Fatalf("ReplaceRecoveryCodes failed: %v", err)
+	}
+
+	count, err := repo.CountUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		t.Fatalf("CountUnusedRecoveryCodes failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("CountUnusedRecoveryCodes = %d, want 2", count)
+	}
+
+	if err := repo.UseRecoveryCode(ctx, userID, "hash1"); err != nil {
+		t.Fatalf("UseRecoveryCode failed: %v", err)
+	}
+	if err := repo.UseRecoveryCode(ctx, userID, "hash1"); err != domain.ErrMFACodeInvalid {
+		t.Errorf("Reusing a code error = %v, want ErrMFACodeInvalid", err)
+	}
+	if err := repo.UseRecoveryCode(ctx, uuid.New(), "hash2"); err != domain.ErrMFACodeInvalid {
+		t.Errorf("Another user's code error = %v, want ErrMFACodeInvalid", err)
+	}
+
+	count, _ = repo.CountUnusedRecoveryCodes(ctx, userID)
+	if count != 1 {
+		t.Errorf("CountUnusedRecoveryCodes after use = %d, want 1", count)
+	}
+
+	// Replacing discards the old set entirely
+	repo.ReplaceRecoveryCodes(ctx, userID, []*domain.MFARecoveryCode{{UserID: userID, CodeHash: "hash3"}})
+	if err := repo.UseRecoveryCode(ctx, userID, "hash2"); err != domain.ErrMFACodeInvalid {
+		t.Errorf("Replaced code error = %v, want ErrMFACodeInvalid", err)
+	}
+	count, _ = repo.CountUnusedRecoveryCodes(ctx, userID)
+	if count != 1 {
+		t.Errorf("CountUnusedRecoveryCodes after replace = %d, want 1", count)
+	}
+}
+
+func TestGormMFARepository_DeleteForUser(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormMFARepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	repo.SaveFactor(ctx, &domain.MFAFactor{UserID: userID, Secret: "SECRET"})
+	repo.ReplaceRecoveryCodes(ctx, userID, []*domain.MFARecoveryCode{{UserID: userID, CodeHash: "hash"}})
+
+	if err := repo.DeleteForUser(ctx, userID); err != nil {
+		t.Fatalf("DeleteForUser failed: %v", err)
+	}
+
+	if _, err := repo.FindFactorByUser(ctx, userID); err != domain.ErrMFANotEnrolled {
+		t.Errorf("FindFactorByUser error = %v, want ErrMFANotEnrolled", err)
+	}
+	count, _ := repo.CountUnusedRecoveryCodes(ctx, userID)
+	if count != 0 {
+		t.Errorf("CountUnusedRecoveryCodes = %d, want 0", count)
+	}
+}
+
+func TestGormMFAChallengeRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormMFAChallengeRepository(db)
+	ctx := context.Background()
+
+	challenge := &domain.MFAChallenge{
+		UserID:    uuid.New(),
+		TenantID:  uuid.New(),
+		TokenHash: "challenge_hash",
+		ExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+	if err := repo.Create(ctx, challenge); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if challenge.ID == uuid.Nil {
+		t.Error("Create should generate an ID")
+	}
+
+	if _, err := repo.FindByToken(ctx, "missing"); err != domain.ErrMFAChallengeInvalid {
+		t.Errorf("FindByToken error = %v, want ErrMFAChallengeInvalid", err)
+	}
+
+	if err := repo.IncrementAttempts(ctx, challenge.ID); err != nil {
+		t.Fatalf("IncrementAttempts failed: %v", err)
+	}
+	repo.IncrementAttempts(ctx, challenge.ID)
+
+	found, err := repo.FindByToken(ctx, "challenge_hash")
+	if err != nil {
+		t.Fatalf("FindByToken failed: %v", err)
+	}
+	if found.Attempts != 2 {
+		t.Errorf("Attempts = %d, want 2", found.Attempts)
+	}
+
+	if err := repo.MarkUsed(ctx, challenge.ID); err != nil {
+		t.Fatalf("MarkUsed failed: %v", err)
+	}
+	if err := repo.MarkUsed(ctx, challenge.ID); err != domain.ErrMFAChallengeInvalid {
+		t.Errorf("Second MarkUsed error = %v, want ErrMFAChallengeInvalid", err)
+	}
+
+	found, _ = repo.FindByToken(ctx, "challenge_hash")
+	if !found.IsUsed() {
+		t.Error("Challenge should be marked as used")
+	}
+}
+
+func TestGormMFAChallengeRepository_DeleteExpired(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormMFAChallengeRepository(db)
+	ctx := context.Background()
+
+	repo.Create(ctx, &domain.MFAChallenge{UserID: uuid.New(), TenantID: uuid.New(), TokenHash: "expired", ExpiresAt: time.Now().Add(-time.Minute)})
+	repo.Create(ctx, &domain.MFAChallenge{UserID: uuid.New(), TenantID: uuid.New(), TokenHash: "valid", ExpiresAt: time.Now().Add(time.Minute)})
+
+	deleted, err := repo.DeleteExpired(ctx)
+	if err != nil {
+		t.Fatalf("DeleteExpired failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteExpired = %d, want 1", deleted)
+	}
+	if _, err := repo.FindByToken(ctx, "valid"); err != nil {
+		t.Errorf("Valid challenge should remain, got %v", err)
+	}
+}
+
+// ============ Staff PIN Repository Tests ============
+
+func TestGormStaffPINRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormStaffPINRepository(db)
+	ctx := context.Background()
+	userID, tenantID := uuid.New(), uuid.New()
+
+	if _, err := repo.FindByUserAndTenant(ctx, userID, tenantID); err != domain.ErrPINNotSet {
+		t.Errorf("FindByUserAndTenant error = %v, want ErrPINNotSet", err)
+	}
+
+	pin := &domain.StaffPIN{UserID: userID, TenantID: tenantID, PINHash: "hash1"}
+	if err := repo.Save(ctx, pin); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if pin.ID == uuid.Nil {
+		t.Error("Save should generate an ID")
+	}
+
+	// Saving again replaces the PIN rather than violating the unique index
+	if err := repo.Save(ctx, &domain.StaffPIN{UserID: userID, TenantID: tenantID, PINHash: "hash2"}); err != nil {
+		t.Fatalf("second Save failed: %v", err)
+	}
+	found, err := repo.FindByUserAndTenant(ctx, userID, tenantID)
+	if err != nil {
+		t.Fatalf("FindByUserAndTenant failed: %v", err)
+	}
+	if found.PINHash != "hash2" {
+		t.Errorf("PINHash = %q, want hash2", found.PINHash)
+	}
+
+	found.RecordFailure(5, time.Minute)
+	if err := repo.Update(ctx, found); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	found, _ = repo.FindByUserAndTenant(ctx, userID, tenantID)
+	if found.FailedAttempts != 1 {
+		t.Errorf("FailedAttempts = %d, want 1", found.FailedAttempts)
+	}
+
+	repo.Save(ctx, &domain.StaffPIN{UserID: uuid.New(), TenantID: tenantID, PINHash: "other"})
+	repo.Save(ctx, &domain.StaffPIN{UserID: userID, TenantID: uuid.New(), PINHash: "elsewhere"})
+	pins, err := repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("ListByTenant failed: %v", err)
+	}
+	if len(pins) != 2 {
+		t.Errorf("ListByTenant returned %d PINs, want 2", len(pins))
+	}
+
+	if err := repo.Delete(ctx, userID, tenantID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := repo.FindByUserAndTenant(ctx, userID, tenantID); err != domain.ErrPINNotSet {
+		t.Errorf("FindByUserAndTenant after Delete error = %v, want ErrPINNotSet", err)
+	}
+}
+
+func TestGormTerminalRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormTerminalRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	terminal := &domain.Terminal{TenantID: tenantID, Name: "Bar tablet", TokenHash: "device_hash", CreatedBy: uuid.New()}
+	if err := repo.Create(ctx, terminal); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if terminal.ID == uuid.Nil {
+		t.Error("Create should generate an ID")
+	}
+	repo.Create(ctx, &domain.Terminal{TenantID: uuid.New(), Name: "Elsewhere", TokenHash: "other_hash"})
+
+	found, err := repo.FindByToken(ctx, "device_hash")
+	if err != nil {
+		t.Fatalf("FindByToken failed: %v", err)
+	}
+	if found.ID != terminal.ID {
+		t.Errorf("FindByToken returned %s, want %s", found.ID, terminal.ID)
+	}
+	if _, err := repo.FindByToken(ctx, "missing"); err != domain.ErrTerminalNotFound {
+		t.Errorf("FindByToken error = %v, want ErrTerminalNotFound", err)
+	}
+	if _, err := repo.FindByID(ctx, uuid.New()); err != domain.ErrTerminalNotFound {
+		t.Errorf("FindByID error = %v, want ErrTerminalNotFound", err)
+	}
+
+	usedAt := time.Now().Truncate(time.Second)
+	if err := repo.TouchLastUsed(ctx, terminal.ID, usedAt); err != nil {
+		t.Fatalf("TouchLastUsed failed: %v", err)
+	}
+	found, _ = repo.FindByID(ctx, terminal.ID)
+	if found.LastUsedAt == nil || !found.LastUsedAt.Equal(usedAt) {
+		t.Errorf("LastUsedAt = %v, want %v", found.LastUsedAt, usedAt)
+	}
+
+	terminals, err := repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("ListByTenant failed: %v", err)
+	}
+	if len(terminals) != 1 {
+		t.Fatalf("ListByTenant returned %d terminals, want 1", len(terminals))
+	}
+
+	if err := repo.Revoke(ctx, terminal.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if err := repo.Revoke(ctx, terminal.ID); err != domain.ErrTerminalNotFound {
+		t.Errorf("second Revoke error = %v, want ErrTerminalNotFound", err)
+	}
+	found, _ = repo.FindByID(ctx, terminal.ID)
+	if !found.IsRevoked() {
+		t.Error("Terminal should be revoked")
+	}
+	terminals, _ = repo.ListByTenant(ctx, tenantID)
+	if len(terminals) != 0 {
+		t.Errorf("ListByTenant should omit revoked terminals, got %d", len(terminals))
+	}
+}
+
+// ============ Invitation Repository Tests ============
+
+func TestGormInvitationRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormInvitationRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	invitation := &domain.Invitation{
+		TenantID:  tenantID,
+		Email:     "new.hire@example.com",
+		FirstName: "New",
+		LastName:  "Hire",
+		Role:      domain.RoleCashier,
+		TokenHash: "invite_hash",
+		InvitedBy: uuid.New(),
+		ExpiresAt: time.Now().Add(domain.InvitationTTL),
+	}
+	if err := repo.Create(ctx, invitation); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if invitation.ID == uuid.Nil {
+		t.Error("Create should generate an ID")
+	}
+	repo.Create(ctx, &domain.Invitation{
+		TenantID: uuid.New(), Email: "new.hire@example.com", Role: domain.RoleWaiter,
+		TokenHash: "other_hash", ExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	found, err := repo.FindByToken(ctx, "invite_hash")
+	if err != nil {
+		t.Fatalf("FindByToken failed: %v", err)
+	}
+	if found.ID != invitation.ID {
+		t.Errorf("FindByToken returned %s, want %s", found.ID, invitation.ID)
+	}
+	if _, err := repo.FindByToken(ctx, "missing"); err != domain.ErrInvitationInvalid {
+		t.Errorf("FindByToken error = %v, want ErrInvitationInvalid", err)
+	}
+	if _, err := repo.FindByID(ctx, uuid.New()); err != domain.ErrInvitationNotFound {
+		t.Errorf("FindByID error = %v, want ErrInvitationNotFound", err)
+	}
+
+	pending, err := repo.FindPending(ctx, tenantID, "new.hire@example.com")
+	if err != nil {
+		t.Fatalf("FindPending failed: %v", err)
+	}
+	if pending.ID != invitation.ID {
+		t.Errorf("FindPending returned %s, want %s", pending.ID, invitation.ID)
+	}
+
+	// Resend swaps in a new token
+	found.TokenHash = "resent_hash"
+	if err := repo.Update(ctx, found); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := repo.FindByToken(ctx, "invite_hash"); err != domain.ErrInvitationInvalid {
+		t.Errorf("old token should no longer resolve, got %v", err)
+	}
+
+	invitations, err := repo.ListPendingByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("ListPendingByTenant failed: %v", err)
+	}
+	if len(invitations) != 1 {
+		t.Fatalf("ListPendingByTenant returned %d invitations, want 1", len(invitations))
+	}
+
+	if err := repo.MarkAccepted(ctx, invitation.ID); err != nil {
+		t.Fatalf("MarkAccepted failed: %v", err)
+	}
+	if err := repo.MarkAccepted(ctx, invitation.ID); err != domain.ErrInvitationAccepted {
+		t.Errorf("second MarkAccepted error = %v, want ErrInvitationAccepted", err)
+	}
+	if err := repo.Revoke(ctx, invitation.ID); err != domain.ErrInvitationNotFound {
+		t.Errorf("Revoke of accepted invitation error = %v, want ErrInvitationNotFound", err)
+	}
+	if _, err := repo.FindPending(ctx, tenantID, "new.hire@example.com"); err != domain.ErrInvitationNotFound {
+		t.Errorf("FindPending after accept error = %v, want ErrInvitationNotFound", err)
+	}
+	invitations, _ = repo.ListPendingByTenant(ctx, tenantID)
+	if len(invitations) != 0 {
+		t.Errorf("ListPendingByTenant should omit accepted invitations, got %d", len(invitations))
+	}
+}
+
+func TestGormInvitationRepository_Revoke(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormInvitationRepository(db)
+	ctx := context.Background()
+
+	invitation := &domain.Invitation{
+		TenantID: uuid.New(), Email: "revoked@example.com", Role: domain.RoleWaiter,
+		TokenHash: "revoke_hash", ExpiresAt: time.Now().Add(time.Hour),
+	}
+	repo.Create(ctx, invitation)
+
+	if err := repo.Revoke(ctx, invitation.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if err := repo.Revoke(ctx, invitation.ID); err != domain.ErrInvitationNotFound {
+		t.Errorf("second Revoke error = %v, want ErrInvitationNotFound", err)
+	}
+	if err := repo.MarkAccepted(ctx, invitation.ID); err != domain.ErrInvitationAccepted {
+		t.Errorf("MarkAccepted of revoked invitation error = %v, want ErrInvitationAccepted", err)
+	}
+	found, _ := repo.FindByID(ctx, invitation.ID)
+	if !found.IsRevoked() {
+		t.Error("Invitation should be revoked")
+	}
+}
+
+func TestGormOwnershipTransferRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormOwnershipTransferRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	transfer := &domain.OwnershipTransfer{
+		TenantID:   tenantID,
+		FromUserID: uuid.New(),
+		ToUserID:   uuid.New(),
+		TokenHash:  "transfer_hash",
+		ExpiresAt:  time.Now().Add(domain.OwnershipTransferTTL),
+	}
+	if err := repo.Create(ctx, transfer); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if transfer.ID == uuid.Nil {
+		t.Error("Create should generate an ID")
+	}
+
+	found, err := repo.FindByToken(ctx, "transfer_hash")
+	if err != nil {
+		t.Fatalf("FindByToken failed: %v", err)
+	}
+	if found.ID != transfer.ID {
+		t.Errorf("FindByToken returned %s, want %s", found.ID, transfer.ID)
+	}
+	if _, err := repo.FindByToken(ctx, "missing"); err != domain.ErrOwnershipTransferInvalid {
+		t.Errorf("FindByToken error = %v, want ErrOwnershipTransferInvalid", err)
+	}
+
+	pending, err := repo.FindPendingByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("FindPendingByTenant failed: %v", err)
+	}
+	if pending.ID != transfer.ID {
+		t.Errorf("FindPendingByTenant returned %s, want %s", pending.ID, transfer.ID)
+	}
+
+	if err := repo.MarkConfirmed(ctx, transfer.ID); err != nil {
+		t.Fatalf("MarkConfirmed failed: %v", err)
+	}
+	if err := repo.MarkConfirmed(ctx, transfer.ID); err != domain.ErrOwnershipTransferUsed {
+		t.Errorf("second MarkConfirmed error = %v, want ErrOwnershipTransferUsed", err)
+	}
+	if err := repo.Cancel(ctx, transfer.ID); err != domain.ErrOwnershipTransferNotFound {
+		t.Errorf("Cancel of confirmed transfer error = %v, want ErrOwnershipTransferNotFound", err)
+	}
+	if _, err := repo.FindPendingByTenant(ctx, tenantID); err != domain.ErrOwnershipTransferNotFound {
+		t.Errorf("FindPendingByTenant after confirm error = %v, want ErrOwnershipTransferNotFound", err)
+	}
+}
+
+func TestGormOwnershipTransferRepository_Cancel(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormOwnershipTransferRepository(db)
+	ctx := context.Background()
+
+	transfer := &domain.OwnershipTransfer{
+		TenantID: uuid.New(), FromUserID: uuid.New(), ToUserID: uuid.New(),
+		TokenHash: "cancel_hash", ExpiresAt: time.Now().Add(time.Hour),
+	}
+	repo.Create(ctx, transfer)
+
+	if err := repo.Cancel(ctx, transfer.ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if err := repo.Cancel(ctx, transfer.ID); err != domain.ErrOwnershipTransferNotFound {
+		t.Errorf("second Cancel error = %v, want ErrOwnershipTransferNotFound", err)
+	}
+	if err := repo.MarkConfirmed(ctx, transfer.ID); err != domain.ErrOwnershipTransferUsed {
+		t.Errorf("MarkConfirmed of cancelled transfer error = %v, want ErrOwnershipTransferUsed", err)
+	}
+	found, _ := repo.FindByToken(ctx, "cancel_hash")
+	if !found.IsCancelled() {
+		t.Error("Transfer should be cancelled")
+	}
+}
+
+func TestGormApprovalRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormApprovalRepository(db)
+	ctx := context.Background()
+
+	approval := &domain.Approval{
+		TenantID:    uuid.New(),
+		Action:      "payments.refund",
+		ResourceID:  "payment-1",
+		RequestedBy: uuid.New(),
+		ApprovedBy:  uuid.New(),
+		Method:      domain.ApprovalMethodPassword,
+		TokenHash:   "approval_hash",
+		ExpiresAt:   time.Now().Add(domain.ApprovalTTL),
+	}
+	if err := repo.Create(ctx, approval); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if approval.ID == uuid.Nil {
+		t.Error("Create should generate an ID")
+	}
+
+	found, err := repo.FindByToken(ctx, "approval_hash")
+	if err != nil {
+		t.Fatalf("FindByToken failed: %v", err)
+	}
+	if found.ID != approval.ID || found.Action != "payments.refund" || found.ResourceID != "payment-1" {
+		t.Errorf("FindByToken returned %+v, want the created approval", found)
+	}
+	if _, err := repo.FindByToken(ctx, "missing"); err != domain.ErrApprovalInvalid {
+		t.Errorf("FindByToken error = %v, want ErrApprovalInvalid", err)
+	}
+
+	if err := repo.MarkUsed(ctx, approval.ID); err != nil {
+		t.Fatalf("MarkUsed failed: %v", err)
+	}
+	if err := repo.MarkUsed(ctx, approval.ID); err != domain.ErrApprovalUsed {
+		t.Errorf("second MarkUsed error = %v, want ErrApprovalUsed", err)
+	}
+	found, _ = repo.FindByToken(ctx, "approval_hash")
+	if !found.IsUsed() {
+		t.Error("Approval should be used")
+	}
+}
+
+func TestGormCustomRoleRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormCustomRoleRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	role := &domain.CustomRole{
+		TenantID:    tenantID,
+		Name:        "Bartender",
+		BaseRole:    domain.RoleWaiter,
+		Permissions: domain.PermissionList{"orders.create", "payments.collect"},
+	}
+	if err := repo.Create(ctx, role); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if role.ID == uuid.Nil {
+		t.Error("Create should generate an ID")
+	}
+	repo.Create(ctx, &domain.CustomRole{TenantID: tenantID, Name: "Host", BaseRole: domain.RoleViewer})
+	repo.Create(ctx, &domain.CustomRole{TenantID: uuid.New(), Name: "Bartender", BaseRole: domain.RoleWaiter})
+
+	found, err := repo.FindByID(ctx, tenantID, role.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.BaseRole != domain.RoleWaiter || len(found.Permissions) != 2 || !found.HasPermission("payments.collect") {
+		t.Errorf("FindByID = %+v, want waiter with 2 permissions", found)
+	}
+	if _, err := repo.FindByID(ctx, uuid.New(), role.ID); err != domain.ErrCustomRoleNotFound {
+		t.Errorf("FindByID in another tenant error = %v, want ErrCustomRoleNotFound", err)
+	}
+
+	byName, err := repo.FindByName(ctx, tenantID, "BARTENDER")
+	if err != nil {
+		t.Fatalf("FindByName failed: %v", err)
+	}
+	if byName.ID != role.ID {
+		t.Errorf("FindByName returned %s, want %s", byName.ID, role.ID)
+	}
+	if _, err := repo.FindByName(ctx, tenantID, "Sommelier"); err != domain.ErrCustomRoleNotFound {
+		t.Errorf("FindByName error = %v, want ErrCustomRoleNotFound", err)
+	}
+
+	list, err := repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("ListByTenant failed: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "Bartender" || list[1].Name != "Host" {
+		t.Errorf("ListByTenant = %d roles, want Bartender and Host in order", len(list))
+	}
+
+	found.Permissions = domain.PermissionList{"orders.create"}
+	if err := repo.Update(ctx, found); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	updated, _ := repo.FindByID(ctx, tenantID, role.ID)
+	if len(updated.Permissions) != 1 {
+		t.Errorf("Permissions after update = %v, want [orders.create]", updated.Permissions)
+	}
+
+	if err := repo.Delete(ctx, tenantID, role.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := repo.Delete(ctx, tenantID, role.ID); err != domain.ErrCustomRoleNotFound {
+		t.Errorf("second Delete error = %v, want ErrCustomRoleNotFound", err)
+	}
+}
+
+func TestGormSSOConfigRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormSSOConfigRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	if _, err := repo.FindByTenant(ctx, tenantID); err != domain.ErrSSONotConfigured {
+		t.Errorf("FindByTenant error = %v, want ErrSSONotConfigured", err)
+	}
+
+	cfg := &domain.TenantSSOConfig{
+		TenantID:       tenantID,
+		Issuer:         "https://idp.example.com",
+		ClientID:       "erp",
+		ClientSecret:   "secret",
+		AllowedDomains: domain.DomainList{"example.com"},
+	}
+	if err := repo.Create(ctx, cfg); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if cfg.ID == uuid.Nil {
+		t.Error("Create should generate an ID")
+	}
+
+	found, err := repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("FindByTenant failed: %v", err)
+	}
+	if found.Issuer != cfg.Issuer || found.ClientSecret != "secret" || !found.AllowsEmail("ana@example.com") || found.PasswordLoginDisabled {
+		t.Errorf("FindByTenant = %+v, want the created config", found)
+	}
+
+	found.PasswordLoginDisabled = true
+	found.AllowedDomains = domain.DomainList{"example.com", "example.org"}
+	if err := repo.Update(ctx, found); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	updated, _ := repo.FindByTenant(ctx, tenantID)
+	if !updated.PasswordLoginDisabled || len(updated.AllowedDomains) != 2 {
+		t.Errorf("after Update = %+v, want password login disabled and 2 domains", updated)
+	}
+
+	if err := repo.Delete(ctx, tenantID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := repo.Delete(ctx, tenantID); err != domain.ErrSSONotConfigured {
+		t.Errorf("second Delete error = %v, want ErrSSONotConfigured", err)
+	}
+}
+
+func TestGormSSOLoginStateRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormSSOLoginStateRepository(db)
+	ctx := context.Background()
+
+	state := &domain.SSOLoginState{
+		TenantID:     uuid.New(),
+		StateHash:    "state_hash",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		ExpiresAt:    time.Now().Add(domain.SSOLoginStateTTL),
+	}
+	if err := repo.Create(ctx, state); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	repo.Create(ctx, &domain.SSOLoginState{TenantID: uuid.New(), StateHash: "expired_hash", Nonce: "n", CodeVerifier: "v", ExpiresAt: time.Now().Add(-time.Minute)})
+
+	found, err := repo.FindByState(ctx, "state_hash")
+	if err != nil {
+		t.Fatalf("FindByState failed: %v", err)
+	}
+	if found.ID != state.ID || found.Nonce != "nonce" || found.CodeVerifier != "verifier" || !found.IsValid() {
+		t.Errorf("FindByState = %+v, want the created state", found)
+	}
+	if _, err := repo.FindByState(ctx, "missing"); err != domain.ErrSSOStateInvalid {
+		t.Errorf("FindByState error = %v, want ErrSSOStateInvalid", err)
+	}
+
+	if err := repo.MarkUsed(ctx, state.ID); err != nil {
+		t.Fatalf("MarkUsed failed: %v", err)
+	}
+	if err := repo.MarkUsed(ctx, state.ID); err != domain.ErrSSOStateInvalid {
+		t.Errorf("second MarkUsed error = %v, want ErrSSOStateInvalid", err)
+	}
+
+	deleted, err := repo.DeleteExpired(ctx)
+	if err != nil {
+		t.Fatalf("DeleteExpired failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteExpired removed %d states, want 1", deleted)
+	}
+}
+
+func TestGormUserIdentityRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormUserIdentityRepository(db)
+	ctx := context.Background()
+
+	identity := &domain.UserIdentity{
+		UserID:  uuid.New(),
+		Issuer:  "https://idp.example.com",
+		Subject: "idp-user-1",
+		Email:   "ana@example.com",
+	}
+	if err := repo.Create(ctx, identity); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := repo.Create(ctx, &domain.UserIdentity{UserID: uuid.New(), Issuer: identity.Issuer, Subject: identity.Subject}); err == nil {
+		t.Error("Create should reject a second link for the same issuer and subject")
+	}
+
+	found, err := repo.FindBySubject(ctx, "https://idp.example.com", "idp-user-1")
+	if err != nil {
+		t.Fatalf("FindBySubject failed: %v", err)
+	}
+	if found.UserID != identity.UserID || found.LastLoginAt != nil {
+		t.Errorf("FindBySubject = %+v, want the created identity", found)
+	}
+	if _, err := repo.FindBySubject(ctx, "https://other.example.com", "idp-user-1"); err != domain.ErrSSOIdentityNotFound {
+		t.Errorf("FindBySubject for another issuer error = %v, want ErrSSOIdentityNotFound", err)
+	}
+
+	if err := repo.TouchLastLogin(ctx, identity.ID); err != nil {
+		t.Fatalf("TouchLastLogin failed: %v", err)
+	}
+	found, _ = repo.FindBySubject(ctx, "https://idp.example.com", "idp-user-1")
+	if found.LastLoginAt == nil {
+		t.Error("TouchLastLogin should set LastLoginAt")
+	}
+}
+
+func TestGormSCIMTokenRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormSCIMTokenRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	token := &domain.SCIMToken{TenantID: tenantID, Name: "Workday", TokenHash: "scim_hash", CreatedBy: uuid.New()}
+	if err := repo.Create(ctx, token); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if token.ID == uuid.Nil {
+		t.Error("Create should generate an ID")
+	}
+	repo.Create(ctx, &domain.SCIMToken{TenantID: uuid.New(), Name: "Elsewhere", TokenHash: "other_hash"})
+
+	found, err := repo.FindByToken(ctx, "scim_hash")
+	if err != nil {
+		t.Fatalf("FindByToken failed: %v", err)
+	}
+	if found.ID != token.ID {
+		t.Errorf("FindByToken returned %s, want %s", found.ID, token.ID)
+	}
+	if _, err := repo.FindByToken(ctx, "missing"); err != domain.ErrSCIMTokenNotFound {
+		t.Errorf("FindByToken error = %v, want ErrSCIMTokenNotFound", err)
+	}
+	if _, err := repo.FindByID(ctx, uuid.New()); err != domain.ErrSCIMTokenNotFound {
+		t.Errorf("FindByID error = %v, want ErrSCIMTokenNotFound", err)
+	}
+
+	usedAt := time.Now().Truncate(time.Second)
+	if err := repo.TouchLastUsed(ctx, token.ID, usedAt); err != nil {
+		t.Fatalf("TouchLastUsed failed: %v", err)
+	}
+	found, _ = repo.FindByID(ctx, token.ID)
+	if found.LastUsedAt == nil || !found.LastUsedAt.Equal(usedAt) {
+		t.Errorf("LastUsedAt = %v, want %v", found.LastUsedAt, usedAt)
+	}
+
+	tokens, err := repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("ListByTenant failed: %v", err)
+	}
+	if len(tokens) != 1 {
+		t.Fatalf("ListByTenant returned %d tokens, want 1", len(tokens))
+	}
+
+	if err := repo.Revoke(ctx, token.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if err := repo.Revoke(ctx, token.ID); err != domain.ErrSCIMTokenNotFound {
+		t.Errorf("second Revoke error = %v, want ErrSCIMTokenNotFound", err)
+	}
+	found, _ = repo.FindByToken(ctx, "scim_hash")
+	if !found.IsRevoked() {
+		t.Error("revoked token should be marked revoked")
+	}
+	if tokens, _ := repo.ListByTenant(ctx, tenantID); len(tokens) != 0 {
+		t.Errorf("ListByTenant returned %d tokens after revoke, want 0", len(tokens))
+	}
+}
+
+func TestGormSCIMUserLinkRepository_Upsert(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormSCIMUserLinkRepository(db)
+	ctx := context.Background()
+	tenantID, userID := uuid.New(), uuid.New()
+
+	if _, err := repo.FindByUser(ctx, tenantID, userID); err != domain.ErrSCIMLinkNotFound {
+		t.Errorf("FindByUser error = %v, want ErrSCIMLinkNotFound", err)
+	}
+
+	if err := repo.Upsert(ctx, &domain.SCIMUserLink{TenantID: tenantID, UserID: userID, ExternalID: "HR-1042"}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if err := repo.Upsert(ctx, &domain.SCIMUserLink{TenantID: tenantID, UserID: userID, ExternalID: "HR-2000"}); err != nil {
+		t.Fatalf("second Upsert failed: %v", err)
+	}
+	repo.Upsert(ctx, &domain.SCIMUserLink{TenantID: uuid.New(), UserID: userID, ExternalID: "OTHER-1"})
+
+	found, err := repo.FindByUser(ctx, tenantID, userID)
+	if err != nil {
+		t.Fatalf("FindByUser failed: %v", err)
+	}
+	if found.ExternalID != "HR-2000" {
+		t.Errorf("ExternalID = %q, want the updated HR-2000", found.ExternalID)
+	}
+
+	links, err := repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("ListByTenant failed: %v", err)
+	}
+	if len(links) != 1 {
+		t.Errorf("ListByTenant returned %d links, want 1", len(links))
+	}
+}
+
+func TestGormServiceAccountRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormServiceAccountRepository(db)
+	keys := NewGormAPIKeyRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	account := &domain.ServiceAccount{TenantID: tenantID, Name: "Accounting sync", Scopes: domain.PermissionList{domain.PermUsersManage}}
+	if err := repo.Create(ctx, account); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	repo.Create(ctx, &domain.ServiceAccount{TenantID: tenantID, Name: "Delivery", Scopes: domain.PermissionList{}})
+	repo.Create(ctx, &domain.ServiceAccount{TenantID: uuid.New(), Name: "Elsewhere", Scopes: domain.PermissionList{}})
+
+	if _, err := repo.FindByID(ctx, uuid.New(), account.ID); err != domain.ErrServiceAccountNotFound {
+		t.Errorf("FindByID in another tenant error = %v, want ErrServiceAccountNotFound", err)
+	}
+
+	account.Description = "Nightly ledger export"
+	if err := repo.Update(ctx, account); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	found, err := repo.FindByID(ctx, tenantID, account.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.Description != "Nightly ledger export" || !found.HasScope(domain.PermUsersManage) {
+		t.Errorf("found = %+v", found)
+	}
+
+	accounts, err := repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("ListByTenant failed: %v", err)
+	}
+	if len(accounts) != 2 || accounts[0].Name != "Accounting sync" {
+		t.Fatalf("ListByTenant = %d accounts, want 2 ordered by name", len(accounts))
+	}
+
+	keys.Create(ctx, &domain.APIKey{ServiceAccountID: account.ID, TenantID: tenantID, Name: "prod", Prefix: "sbk_1", KeyHash: "hash_1", ExpiresAt: time.Now().Add(time.Hour)})
+	if err := repo.Delete(ctx, tenantID, account.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := repo.Delete(ctx, tenantID, account.ID); err != domain.ErrServiceAccountNotFound {
+		t.Errorf("second Delete error = %v, want ErrServiceAccountNotFound", err)
+	}
+	if _, err := keys.FindByHash(ctx, "hash_1"); err != domain.ErrAPIKeyNotFound {
+		t.Errorf("FindByHash after Delete error = %v, want the key deleted with its account", err)
+	}
+}
+
+func TestGormAPIKeyRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormAPIKeyRepository(db)
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	key := &domain.APIKey{ServiceAccountID: accountID, TenantID: uuid.New(), Name: "prod", Prefix: "sbk_1", KeyHash: "hash_1", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.Create(ctx, key); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if key.ID == uuid.Nil {
+		t.Error("Create should generate an ID")
+	}
+	repo.Create(ctx, &domain.APIKey{ServiceAccountID: uuid.New(), TenantID: key.TenantID, Name: "other", Prefix: "sbk_2", KeyHash: "hash_2", ExpiresAt: time.Now().Add(time.Hour)})
+
+	found, err := repo.FindByHash(ctx, "hash_1")
+	if err != nil {
+		t.Fatalf("FindByHash failed: %v", err)
+	}
+	if found.ID != key.ID {
+		t.Errorf("FindByHash returned %s, want %s", found.ID, key.ID)
+	}
+	if _, err := repo.FindByHash(ctx, "missing"); err != domain.ErrAPIKeyNotFound {
+		t.Errorf("FindByHash error = %v, want ErrAPIKeyNotFound", err)
+	}
+	if _, err := repo.FindByID(ctx, uuid.New()); err != domain.ErrAPIKeyNotFound {
+		t.Errorf("FindByID error = %v, want ErrAPIKeyNotFound", err)
+	}
+
+	usedAt := time.Now().Truncate(time.Second)
+	if err := repo.TouchLastUsed(ctx, key.ID, usedAt); err != nil {
+		t.Fatalf("TouchLastUsed failed: %v", err)
+	}
+	expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+	if err := repo.SetExpiry(ctx, key.ID, expiresAt); err != nil {
+		t.Fatalf("SetExpiry failed: %v", err)
+	}
+	found, _ = repo.FindByID(ctx, key.ID)
+	if found.LastUsedAt == nil || !found.LastUsedAt.Equal(usedAt) {
+		t.Errorf("LastUsedAt = %v, want %v", found.LastUsedAt, usedAt)
+	}
+	if !found.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("ExpiresAt = %v, want %v", found.ExpiresAt, expiresAt)
+	}
+
+	if keys, err := repo.ListByServiceAccount(ctx, accountID); err != nil || len(keys) != 1 {
+		t.Fatalf("ListByServiceAccount = %d keys, %v, want 1", len(keys), err)
+	}
+
+	if err := repo.Revoke(ctx, key.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if err := repo.Revoke(ctx, key.ID); err != domain.ErrAPIKeyNotFound {
+		t.Errorf("second Revoke error = %v, want ErrAPIKeyNotFound", err)
+	}
+	if err := repo.SetExpiry(ctx, key.ID, time.Now()); err != domain.ErrAPIKeyNotFound {
+		t.Errorf("SetExpiry on a revoked key error = %v, want ErrAPIKeyNotFound", err)
+	}
+	found, _ = repo.FindByHash(ctx, "hash_1")
+	if !found.IsRevoked() {
+		t.Error("revoked key should be marked revoked")
+	}
+	if keys, _ := repo.ListByServiceAccount(ctx, accountID); len(keys) != 0 {
+		t.Errorf("ListByServiceAccount returned %d keys after revoke, want 0", len(keys))
+	}
+}
+
+func TestGormOAuthClientRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormOAuthClientRepository(db)
+	consents := NewGormOAuthConsentRepository(db)
+	tokens := NewGormOAuthRefreshTokenRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	client := &domain.OAuthClient{
+		TenantID:     tenantID,
+		Name:         "Reservas Online",
+		RedirectURIs: domain.RedirectURIList{"https://reservas.example.com/cb"},
+		Scopes:       domain.PermissionList{domain.PermTerminalsManage},
+		SecretHash:   "secret_hash",
+	}
+	if err := repo.Create(ctx, client); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	repo.Create(ctx, &domain.OAuthClient{TenantID: tenantID, Name: "Delivery", Public: true})
+	repo.Create(ctx, &domain.OAuthClient{TenantID: uuid.New(), Name: "Elsewhere"})
+
+	found, err := repo.FindByID(ctx, client.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if !found.HasRedirectURI("https://reservas.example.com/cb") || !found.HasScope(domain.PermTerminalsManage) || found.Public {
+		t.Errorf("found = %+v", found)
+	}
+	if _, err := repo.FindByID(ctx, uuid.New()); err != domain.ErrOAuthClientNotFound {
+		t.Errorf("FindByID error = %v, want ErrOAuthClientNotFound", err)
+	}
+
+	clients, err := repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("ListByTenant failed: %v", err)
+	}
+	if len(clients) != 2 || clients[0].Name != "Delivery" {
+		t.Fatalf("ListByTenant = %d clients, want 2 ordered by name", len(clients))
+	}
+
+	// Deleting the client takes what was granted to it in other tenants too
+	otherTenant := uuid.New()
+	consents.Create(ctx, &domain.OAuthConsent{TenantID: otherTenant, ClientID: client.ID, GrantedBy: uuid.New()})
+	tokens.Create(ctx, &domain.OAuthRefreshToken{TokenHash: "rt_1", ClientID: client.ID, TenantID: otherTenant, UserID: uuid.New(), FamilyID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)})
+
+	if err := repo.Delete(ctx, uuid.New(), client.ID); err != domain.ErrOAuthClientNotFound {
+		t.Errorf("Delete from another tenant error = %v, want ErrOAuthClientNotFound", err)
+	}
+	if err := repo.Delete(ctx, tenantID, client.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := consents.FindActive(ctx, otherTenant, client.ID); err != domain.ErrOAuthConsentNotFound {
+		t.Errorf("FindActive after Delete error = %v, want the consent deleted with its client", err)
+	}
+	if _, err := tokens.FindByHash(ctx, "rt_1"); err != domain.ErrOAuthGrantInvalid {
+		t.Errorf("FindByHash after Delete error = %v, want the token deleted with its client", err)
+	}
+}
+
+func TestGormOAuthConsentRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormOAuthConsentRepository(db)
+	clients := NewGormOAuthClientRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	client := &domain.OAuthClient{TenantID: uuid.New(), Name: "Reservas Online"}
+	clients.Create(ctx, client)
+
+	consent := &domain.OAuthConsent{TenantID: tenantID, ClientID: client.ID, GrantedBy: uuid.New(), Scopes: domain.PermissionList{domain.PermTerminalsManage}}
+	if err := repo.Create(ctx, consent); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	found, err := repo.FindActive(ctx, tenantID, client.ID)
+	if err != nil {
+		t.Fatalf("FindActive failed: %v", err)
+	}
+	if found.ID != consent.ID || !found.Covers([]domain.Permission{domain.PermTerminalsManage}) {
+		t.Errorf("found = %+v", found)
+	}
+	if _, err := repo.FindByID(ctx, uuid.New(), consent.ID); err != domain.ErrOAuthConsentNotFound {
+		t.Errorf("FindByID in another tenant error = %v, want ErrOAuthConsentNotFound", err)
+	}
+
+	consent.Scopes = domain.PermissionList{domain.PermTerminalsManage, domain.PermUsersManage}
+	if err := repo.Update(ctx, consent); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	list, err := repo.ListActiveByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("ListActiveByTenant failed: %v", err)
+	}
+	if len(list) != 1 || len(list[0].Scopes) != 2 || list[0].Client.Name != "Reservas Online" {
+		t.Fatalf("ListActiveByTenant = %+v, want the updated consent with its client", list)
+	}
+
+	if err := repo.Revoke(ctx, consent.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if err := repo.Revoke(ctx, consent.ID); err != domain.ErrOAuthConsentNotFound {
+		t.Errorf("second Revoke error = %v, want ErrOAuthConsentNotFound", err)
+	}
+	if _, err := repo.FindActive(ctx, tenantID, client.ID); err != domain.ErrOAuthConsentNotFound {
+		t.Errorf("FindActive after Revoke error = %v, want ErrOAuthConsentNotFound", err)
+	}
+	if list, _ := repo.ListActiveByTenant(ctx, tenantID); len(list) != 0 {
+		t.Errorf("ListActiveByTenant returned %d consents after revoke, want 0", len(list))
+	}
+}
+
+func TestGormOAuthCodeRepository_MarkUsed(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormOAuthCodeRepository(db)
+	ctx := context.Background()
+
+	code := &domain.OAuthAuthorizationCode{
+		CodeHash: "code_1", ClientID: uuid.New(), TenantID: uuid.New(), UserID: uuid.New(),
+		RedirectURI: "https://reservas.example.com/cb", CodeChallenge: "challenge", ExpiresAt: time.Now().Add(time.Minute),
+	}
+	if err := repo.Create(ctx, code); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	repo.Create(ctx, &domain.OAuthAuthorizationCode{
+		CodeHash: "code_2", ClientID: uuid.New(), TenantID: uuid.New(), UserID: uuid.New(),
+		RedirectURI: "https://reservas.example.com/cb", CodeChallenge: "challenge", ExpiresAt: time.Now().Add(-time.Minute),
+	})
+
+	if found, err := repo.FindByHash(ctx, "code_1"); err != nil || found.ID != code.ID {
+		t.Fatalf("FindByHash = %v, %v", found, err)
+	}
+	if err := repo.MarkUsed(ctx, code.ID); err != nil {
+		t.Fatalf("MarkUsed failed: %v", err)
+	}
+	if err := repo.MarkUsed(ctx, code.ID); err != domain.ErrOAuthGrantInvalid {
+		t.Errorf("second MarkUsed error = %v, want ErrOAuthGrantInvalid", err)
+	}
+
+	if n, err := repo.DeleteExpired(ctx); err != nil || n != 1 {
+		t.Errorf("DeleteExpired = %d, %v, want 1", n, err)
+	}
+	if _, err := repo.FindByHash(ctx, "code_2"); err != domain.ErrOAuthGrantInvalid {
+		t.Errorf("FindByHash of an expired code error = %v, want ErrOAuthGrantInvalid", err)
+	}
+}
+
+func TestGormOAuthRefreshTokenRepository_Rotate(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormOAuthRefreshTokenRepository(db)
+	ctx := context.Background()
+
+	newToken := func(hash string, familyID, clientID uuid.UUID) *domain.OAuthRefreshToken {
+		return &domain.OAuthRefreshToken{
+			TokenHash: hash, ClientID: clientID, TenantID: uuid.Nil, UserID: uuid.New(),
+			FamilyID: familyID, ExpiresAt: time.Now().Add(time.Hour),
+		}
+	}
+	familyID, clientID := uuid.New(), uuid.New()
+	first := newToken("rt_1", familyID, clientID)
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	second := newToken("rt_2", familyID, clientID)
+	if err := repo.Rotate(ctx, first.ID, second); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	if err := repo.Rotate(ctx, first.ID, newToken("rt_3", familyID, clientID)); err != domain.ErrOAuthGrantInvalid {
+		t.Errorf("second Rotate error = %v, want ErrOAuthGrantInvalid", err)
+	}
+	found, _ := repo.FindByHash(ctx, "rt_1")
+	if !found.IsRotated() || *found.ReplacedByID != second.ID {
+		t.Errorf("rotated token = %+v, want it replaced by %s", found, second.ID)
+	}
+	if _, err := repo.FindByHash(ctx, "rt_3"); err != domain.ErrOAuthGrantInvalid {
+		t.Error("a failed rotation should not store the new token")
+	}
+
+	repo.Create(ctx, newToken("rt_other", uuid.New(), clientID))
+	if n, err := repo.RevokeFamily(ctx, familyID); err != nil || n != 1 {
+		t.Errorf("RevokeFamily = %d, %v, want 1", n, err)
+	}
+	if n, err := repo.RevokeByClient(ctx, uuid.Nil, clientID); err != nil || n != 1 {
+		t.Errorf("RevokeByClient = %d, %v, want the one token outside the family", n, err)
+	}
+	found, _ = repo.FindByHash(ctx, "rt_other")
+	if !found.IsRevoked() || found.IsRotated() {
+		t.Errorf("token revoked by client = %+v", found)
+	}
+}
+
+func TestGormPasskeyRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormPasskeyRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	passkey := &domain.Passkey{
+		UserID:       userID,
+		CredentialID: []byte{0x01, 0x02, 0x03},
+		PublicKey:    []byte{0xa5},
+		Name:         "Ana's iPhone",
+		Transports:   domain.TransportList{"internal", "hybrid"},
+	}
+	if err := repo.Create(ctx, passkey); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if passkey.ID == uuid.Nil {
+		t.Error("Create should generate an ID")
+	}
+	time.Sleep(10 * time.Millisecond)
+	repo.Create(ctx, &domain.Passkey{UserID: userID, CredentialID: []byte{0x04}, PublicKey: []byte{0xa5}, Name: "YubiKey"})
+	repo.Create(ctx, &domain.Passkey{UserID: uuid.New(), CredentialID: []byte{0x05}, PublicKey: []byte{0xa5}, Name: "Elsewhere"})
+
+	if err := repo.Create(ctx, &domain.Passkey{UserID: userID, CredentialID: []byte{0x01, 0x02, 0x03}, PublicKey: []byte{0xa5}, Name: "Dup"}); err == nil {
+		t.Error("Create with a duplicate credential ID should fail")
+	}
+
+	found, err := repo.FindByCredentialID(ctx, []byte{0x01, 0x02, 0x03})
+	if err != nil {
+		t.Fatalf("FindByCredentialID failed: %v", err)
+	}
+	if found.ID != passkey.ID || len(found.Transports) != 2 {
+		t.Errorf("FindByCredentialID = %+v, want the created passkey", found)
+	}
+	if _, err := repo.FindByCredentialID(ctx, []byte{0x09}); err != domain.ErrPasskeyNotFound {
+		t.Errorf("FindByCredentialID error = %v, want ErrPasskeyNotFound", err)
+	}
+	if _, err := repo.FindByID(ctx, uuid.New(), passkey.ID); err != domain.ErrPasskeyNotFound {
+		t.Errorf("FindByID for another user error = %v, want ErrPasskeyNotFound", err)
+	}
+
+	found.RecordUse(7, true)
+	if err := repo.Update(ctx, found); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	updated, _ := repo.FindByID(ctx, userID, passkey.ID)
+	if updated.SignCount != 7 || !updated.BackedUp || updated.LastUsedAt == nil {
+		t.Errorf("after Update = %+v, want sign count 7, backed up and last used", updated)
+	}
+
+	list, err := repo.ListByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListByUser failed: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "Ana's iPhone" || list[1].Name != "YubiKey" {
+		t.Errorf("ListByUser = %d passkeys, want the user's 2 oldest first", len(list))
+	}
+	if count, _ := repo.CountByUser(ctx, userID); count != 2 {
+		t.Errorf("CountByUser = %d, want 2", count)
+	}
+
+	if err := repo.Delete(ctx, uuid.New(), passkey.ID); err != domain.ErrPasskeyNotFound {
+		t.Errorf("Delete for another user error = %v, want ErrPasskeyNotFound", err)
+	}
+	if err := repo.Delete(ctx, userID, passkey.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if count, _ := repo.CountByUser(ctx, userID); count != 1 {
+		t.Errorf("CountByUser after Delete = %d, want 1", count)
+	}
+}
+
+func TestGormPasskeyChallengeRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormPasskeyChallengeRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	challenge := &domain.PasskeyChallenge{
+		ChallengeHash: "challenge_hash",
+		Purpose:       domain.PasskeyPurposeRegister,
+		UserID:        &userID,
+		ExpiresAt:     time.Now().Add(domain.PasskeyChallengeTTL),
+	}
+	if err := repo.Create(ctx, challenge); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	repo.Create(ctx, &domain.PasskeyChallenge{ChallengeHash: "expired_hash", Purpose: domain.PasskeyPurposeLogin, ExpiresAt: time.Now().Add(-time.Minute)})
+
+	found, err := repo.FindByChallenge(ctx, "challenge_hash")
+	if err != nil {
+		t.Fatalf("FindByChallenge failed: %v", err)
+	}
+	if found.ID != challenge.ID || found.Purpose != domain.PasskeyPurposeRegister || found.UserID == nil || *found.UserID != userID || !found.IsValid() {
+		t.Errorf("FindByChallenge = %+v, want the created challenge", found)
+	}
+	if _, err := repo.FindByChallenge(ctx, "missing"); err != domain.ErrPasskeyChallengeInvalid {
+		t.Errorf("FindByChallenge error = %v, want ErrPasskeyChallengeInvalid", err)
+	}
+
+	if err := repo.MarkUsed(ctx, challenge.ID); err != nil {
+		t.Fatalf("MarkUsed failed: %v", err)
+	}
+	if err := repo.MarkUsed(ctx, challenge.ID); err != domain.ErrPasskeyChallengeInvalid {
+		t.Errorf("second MarkUsed error = %v, want ErrPasskeyChallengeInvalid", err)
+	}
+
+	deleted, err := repo.DeleteExpired(ctx)
+	if err != nil {
+		t.Fatalf("DeleteExpired failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteExpired removed %d challenges, want 1", deleted)
+	}
+}
+
+func TestGormUserTenantRoleRepository_CustomRole(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormUserTenantRoleRepository(db)
+	customRoles := NewGormCustomRoleRepository(db)
+	ctx := context.Background()
+
+	tenantID, userID := uuid.New(), uuid.New()
+	custom := &domain.CustomRole{
+		TenantID: tenantID, Name: "Bartender", BaseRole: domain.RoleWaiter,
+		Permissions: domain.PermissionList{"payments.collect"},
+	}
+	customRoles.Create(ctx, custom)
+	repo.Create(ctx, &domain.UserTenantRole{UserID: userID, TenantID: tenantID, Role: domain.RoleWaiter, CustomRoleID: &custom.ID})
+	repo.Create(ctx, &domain.UserTenantRole{UserID: uuid.New(), TenantID: tenantID, Role: domain.RoleWaiter})
+
+	found, err := repo.FindByUserAndTenant(ctx, userID, tenantID)
+	if err != nil {
+		t.Fatalf("FindByUserAndTenant failed: %v", err)
+	}
+	if found.CustomRole == nil || found.CustomRole.Name != "Bartender" {
+		t.Fatalf("CustomRole = %+v, want Bartender preloaded", found.CustomRole)
+	}
+	if perms := found.Permissions(); len(perms) != 1 || perms[0] != "payments.collect" {
+		t.Errorf("Permissions() = %v, want [payments.collect]", perms)
+	}
+
+	assigned, err := repo.ListByCustomRole(ctx, custom.ID)
+	if err != nil {
+		t.Fatalf("ListByCustomRole failed: %v", err)
+	}
+	if len(assigned) != 1 || assigned[0].UserID != userID {
+		t.Errorf("ListByCustomRole = %d assignments, want only the bartender", len(assigned))
+	}
+}
+
+func TestGormUserTenantRoleRepository_Elevations(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormUserTenantRoleRepository(db)
+	ctx := context.Background()
+
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+		return &v
+	}
+	tenantID := uuid.New()
+	newAssignment := func(validFrom, validUntil, startedAt *time.Time) uuid.UUID {
+		userID := uuid.New()
+		utr := &domain.UserTenantRole{UserID: userID, TenantID: tenantID, Role: domain.RoleWaiter}
+		if validFrom != nil {
+			utr.ElevatedRole = domain.RoleManager
+			utr.ValidFrom, utr.ValidUntil, utr.ElevationStartedAt = validFrom, validUntil, startedAt
+		}
+		if err := repo.Create(ctx, utr); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		return userID
+	}
+
+	newAssignment(nil, nil, nil)                                   // no elevation
+	newAssignment(at(time.Hour), at(2*time.Hour), nil)             // scheduled
+	newAssignment(at(-time.Hour), at(time.Hour), at(-time.Hour))   // running, already started
+	toStart := newAssignment(at(-time.Minute), at(time.Hour), nil) // due to start
+	toExpire := newAssignment(at(-2*time.Hour), at(-time.Minute), at(-2*time.Hour))
+
+	due, err := repo.ListDueElevations(ctx, now)
+	if err != nil {
+		t.Fatalf("ListDueElevations failed: %v", err)
+	}
+	got := map[uuid.UUID]bool{}
+	for _, utr := range due {
+		got[utr.UserID] = true
+	}
+	if len(due) != 2 || !got[toStart] || !got[toExpire] {
+		t.Errorf("ListDueElevations = %d assignments, want the one to start and the one to expire", len(due))
+	}
+
+	// The elevated role applies within the window
+	found, err := repo.FindByUserAndTenant(ctx, toStart, tenantID)
+	if err != nil {
+		t.Fatalf("FindByUserAndTenant failed: %v", err)
+	}
+	if found.EffectiveRole() != domain.RoleManager {
+		t.Errorf("EffectiveRole() = %q, want manager", found.EffectiveRole())
+	}
+
+	// An ownership transfer clears the recipient's elevation
+	ownerID := uuid.New()
+	repo.Create(ctx, &domain.UserTenantRole{UserID: ownerID, TenantID: tenantID, Role: domain.RoleOwner})
+	if _, err := repo.TransferOwnership(ctx, tenantID, ownerID, toStart); err != nil {
+		t.Fatalf("TransferOwnership failed: %v", err)
+	}
+	found, _ = repo.FindByUserAndTenant(ctx, toStart, tenantID)
+	if found.HasElevation() || found.ElevatedRole != "" {
+		t.Errorf("elevation after transfer = %q until %v, want cleared", found.ElevatedRole, found.ValidUntil)
+	}
+}
+
+// ============ Token Revocation Store Tests ============
+
+// testTokenRevocationStore checks the behaviour every TokenRevocationStore
+// implementation must share.
+func testTokenRevocationStore(t *testing.T, store TokenRevocationStore) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+	userID := uuid.New()
+	otherUserID := uuid.New()
+
+	// By jti
+	store.RevokeToken(ctx, &domain.RevokedAccessToken{JTI: "revoked-jti", UserID: userID, ExpiresAt: now.Add(time.Hour)})
+	store.RevokeToken(ctx, &domain.RevokedAccessToken{JTI: "expired-jti", UserID: userID, ExpiresAt: now.Add(-time.Minute)})
+	if err := store.RevokeToken(ctx, &domain.RevokedAccessToken{JTI: "revoked-jti", UserID: userID, ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Errorf("Revoking the same jti twice should succeed, got %v", err)
+	}
+
+	for jti, want := range map[string]bool{"revoked-jti": true, "expired-jti": false, "other-jti": false} {
+		revoked, err := store.IsRevoked(ctx, jti, otherUserID, now)
+		if err != nil {
+			t.Fatalf("IsRevoked(%s) failed: %v", jti, err)
+		}
+		if revoked != want {
+			t.Errorf("IsRevoked(%s) = %v, want %v", jti, revoked, want)
+		}
+	}
+
+	// By user, issued before the cutoff
+	cutoff := now.Truncate(time.Second)
+	if err := store.RevokeUser(ctx, &domain.UserTokenRevocation{UserID: userID, RevokedBefore: cutoff, ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("RevokeUser failed: %v", err)
+	}
+	// An earlier cutoff never un-revokes tokens
+	store.RevokeUser(ctx, &domain.UserTokenRevocation{UserID: userID, RevokedBefore: cutoff.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)})
+
+	tests := []struct {
+		name     string
+		userID   uuid.UUID
+		issuedAt time.Time
+		want     bool
+	}{
+		{"issued before cutoff", userID, cutoff.Add(-time.Minute), true},
+		{"issued in the cutoff second", userID, cutoff, true},
+		{"issued in the next second", userID, cutoff.Add(time.Second), false},
+		{"other user", otherUserID, cutoff.Add(-time.Minute), false},
+	}
+	for _, tt := range tests {
+		revoked, err := store.IsRevoked(ctx, "", tt.userID, tt.issuedAt)
+		if err != nil {
+			t.Fatalf("IsRevoked(%s) failed: %v", tt.name, err)
+		}
+		if revoked != tt.want {
+			t.Errorf("IsRevoked(%s) = %v, want %v", tt.name, revoked, tt.want)
+		}
+	}
+
+	// Moving the cutoff later revokes more
+	store.RevokeUser(ctx, &domain.UserTokenRevocation{UserID: userID, RevokedBefore: cutoff.Add(2 * time.Minute), ExpiresAt: now.Add(time.Hour)})
+	if revoked, _ := store.IsRevoked(ctx, "", userID, cutoff.Add(time.Minute)); !revoked {
+		t.Error("A later cutoff should revoke tokens issued before it")
+	}
+
+	// Expired entries are ignored and removed
+	store.RevokeUser(ctx, &domain.UserTokenRevocation{UserID: otherUserID, RevokedBefore: now, ExpiresAt: now.Add(-time.Minute)})
+	if revoked, _ := store.IsRevoked(ctx, "", otherUserID, now.Add(-time.Hour)); revoked {
+		t.Error("Expired user revocation should be ignored")
+	}
+	deleted, err := store.DeleteExpired(ctx)
+	if err != nil {
+		t.Fatalf("DeleteExpired failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("DeleteExpired = %d, want 2", deleted)
+	}
+	if revoked, _ := store.IsRevoked(ctx, "revoked-jti", userID, now); !revoked {
+		t.Error("Unexpired revocation should remain after DeleteExpired")
+	}
+}
+
+func TestGormTokenRevocationStore(t *testing.T) {
+	testTokenRevocationStore(t, NewGormTokenRevocationStore(setupTestDB(t)))
+}
+
+func TestMemoryTokenRevocationStore(t *testing.T) {
+	testTokenRevocationStore(t, NewMemoryTokenRevocationStore(time.Hour))
+}
+
+func TestMemoryTokenRevocationStore_ExpiresAutomatically(t *testing.T) {
+	store := NewMemoryTokenRevocationStore(10 * time.Millisecond)
+	ctx := context.Background()
+
+	store.RevokeToken(ctx, &domain.RevokedAccessToken{JTI: "short-lived", UserID: uuid.New(), ExpiresAt: time.Now().Add(20 * time.Millisecond)})
+	store.RevokeUser(ctx, &domain.UserTokenRevocation{UserID: uuid.New(), RevokedBefore: time.Now(), ExpiresAt: time.Now().Add(20 * time.Millisecond)})
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		store.mu.RLock()
+		remaining := len(store.tokens) + len(store.users)
+		store.mu.RUnlock()
+		if remaining == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("Expired entries should be swept without calling DeleteExpired")
+}
+
+func TestGormPasswordPolicyRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormPasswordPolicyRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	if _, err := repo.FindByTenant(ctx, tenantID); err != domain.ErrPasswordPolicyNotFound {
+		t.Fatalf("FindByTenant error = %v, want ErrPasswordPolicyNotFound", err)
+	}
+
+	policy := domain.DefaultPasswordPolicy()
+	policy.TenantID = tenantID
+	policy.HistoryCount = 5
+	if err := repo.Create(ctx, &policy); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// false must be stored as false, not replaced by a column default
+	policy.RequireUppercase = false
+	policy.MinLength = 12
+	if err := repo.Update(ctx, &policy); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	found, err := repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("FindByTenant failed: %v", err)
+	}
+	if found.MinLength != 12 || found.RequireUppercase || !found.RequireDigit || found.HistoryCount != 5 {
+		t.Errorf("FindByTenant = %+v", found)
+	}
+
+	policies, err := repo.ListByTenants(ctx, []uuid.UUID{tenantID, uuid.New()})
+	if err != nil || len(policies) != 1 || policies[0].TenantID != tenantID {
+		t.Errorf("ListByTenants = %v, %v; want the one policy", policies, err)
+	}
+}
+
+func TestGormPasswordHistoryRepository_ListAndPrune(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormPasswordHistoryRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+	otherID := uuid.New()
+
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 5; i++ {
+		entry := &domain.PasswordHistoryEntry{UserID: userID, PasswordHash: "hash-" + strconv.Itoa(i), CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+		if err := repo.Create(ctx, entry); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	repo.Create(ctx, &domain.PasswordHistoryEntry{UserID: otherID, PasswordHash: "other"})
+
+	recent, err := repo.ListRecent(ctx, userID, 2)
+	if err != nil {
+		t.Fatalf("ListRecent failed: %v", err)
+	}
+	if len(recent) != 2 || recent[0].PasswordHash != "hash-4" || recent[1].PasswordHash != "hash-3" {
+		t.Errorf("ListRecent = %v, want hash-4 then hash-3", recent)
+	}
+
+	if err := repo.Prune(ctx, userID, 3); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	all, _ := repo.ListRecent(ctx, userID, 10)
+	if len(all) != 3 || all[2].PasswordHash != "hash-2" {
+		t.Errorf("after Prune(3) = %v, want hash-4..hash-2", all)
+	}
+	if others, _ := repo.ListRecent(ctx, otherID, 10); len(others) != 1 {
+		t.Error("Prune should keep other users' history")
+	}
+}
+
+func TestGormLockoutPolicyRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormLockoutPolicyRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	if _, err := repo.FindByTenant(ctx, tenantID); err != domain.ErrLockoutPolicyNotFound {
+		t.Fatalf("FindByTenant error = %v, want ErrLockoutPolicyNotFound", err)
+	}
+
+	policy := domain.DefaultLockoutPolicy()
+	policy.TenantID = tenantID
+	if err := repo.Create(ctx, &policy); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	policy.KnownDeviceThreshold = 20
+	policy.MaxDelaySeconds = 3600
+	if err := repo.Update(ctx, &policy); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	found, err := repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("FindByTenant failed: %v", err)
+	}
+	if found.KnownDeviceThreshold != 20 || found.MaxDelaySeconds != 3600 || found.UnknownDeviceThreshold != 5 {
+		t.Errorf("FindByTenant = %+v", found)
+	}
+
+	policies, err := repo.ListByTenants(ctx, []uuid.UUID{tenantID, uuid.New()})
+	if err != nil || len(policies) != 1 || policies[0].TenantID != tenantID {
+		t.Errorf("ListByTenants = %v, %v; want the one policy", policies, err)
+	}
+}
+
+func TestGormKnownDeviceRepository_FindAndTouch(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewGormKnownDeviceRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	if _, err := repo.FindByToken(ctx, "missing"); err != domain.ErrKnownDeviceNotFound {
+		t.Fatalf("FindByToken error = %v, want ErrKnownDeviceNotFound", err)
+	}
+
+	device := &domain.KnownDevice{UserID: userID, TokenHash: "device-hash", DeviceInfo: "TestAgent", LastSeenAt: time.Now().Add(-time.Hour)}
+	if err := repo.Create(ctx, device); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	seenAt := time.Now()
+	if err := repo.Touch(ctx, device.ID, seenAt); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+	found, err := repo.FindByToken(ctx, "device-hash")
+	if err != nil {
+		t.Fatalf("FindByToken failed: %v", err)
+	}
+	if found.UserID != userID || found.LastSeenAt.Before(seenAt.Add(-time.Second)) {
+		t.Errorf("FindByToken = %+v, want the device seen at %v", found, seenAt)
+	}
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SCIMTokenRepository defines the interface for SCIM bearer token data access.
+type SCIMTokenRepository interface {
+	// Create creates a new SCIM token.
+	Create(ctx context.Context, token *domain.SCIMToken) error
+
+	// FindByID retrieves a SCIM token by ID.
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.SCIMToken, error)
+
+	// FindByToken retrieves a SCIM token by its hash.
+	FindByToken(ctx context.Context, tokenHash string) (*domain.SCIMToken, error)
+
+	// ListByTenant lists a tenant's SCIM tokens that have not been revoked.
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.SCIMToken, error)
+
+	// Revoke revokes a SCIM token so it stops working.
+	Revoke(ctx context.Context, id uuid.UUID) error
+
+	// TouchLastUsed records when a SCIM token was last used.
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// GormSCIMTokenRepository is a GORM implementation of SCIMTokenRepository.
+type GormSCIMTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSCIMTokenRepository creates a new GormSCIMTokenRepository.
+func NewGormSCIMTokenRepository(db *gorm.DB) *GormSCIMTokenRepository {
+	return &GormSCIMTokenRepository{db: db}
+}
+
+// Create creates a new SCIM token.
+func (r *GormSCIMTokenRepository) Create(ctx context.Context, token *domain.SCIMToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// FindByID retrieves a SCIM token by ID.
+func (r *GormSCIMTokenRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.SCIMToken, error) {
+	var token domain.SCIMToken
+	if err := r.db.WithContext(ctx).First(&token, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSCIMTokenNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindByToken retrieves a SCIM token by its hash.
+func (r *GormSCIMTokenRepository) FindByToken(ctx context.Context, tokenHash string) (*domain.SCIMToken, error) {
+	var token domain.SCIMToken
+	if err := r.db.WithContext(ctx).First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSCIMTokenNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListByTenant lists a tenant's SCIM tokens that have not been revoked.
+func (r *GormSCIMTokenRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.SCIMToken, error) {
+	var tokens []*domain.SCIMToken
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND revoked_at IS NULL", tenantID).
+		Order("created_at ASC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// Revoke revokes a SCIM token.
+func (r *GormSCIMTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.SCIMToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSCIMTokenNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records when a SCIM token was last used.
+func (r *GormSCIMTokenRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.SCIMToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
+
+// Ensure GormSCIMTokenRepository implements SCIMTokenRepository
+var _ SCIMTokenRepository = (*GormSCIMTokenRepository)(nil)
+
+// SCIMUserLinkRepository defines the interface for SCIM-provisioned user data access.
+type SCIMUserLinkRepository interface {
+	// Upsert creates a user's link in a tenant, or updates its external ID
+	// if there already is one.
+	Upsert(ctx context.Context, link *domain.SCIMUserLink) error
+
+	// FindByUser retrieves a user's link in a tenant.
+	FindByUser(ctx context.Context, tenantID, userID uuid.UUID) (*domain.SCIMUserLink, error)
+
+	// ListByTenant retrieves all of a tenant's links.
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.SCIMUserLink, error)
+}
+
+// GormSCIMUserLinkRepository is a GORM implementation of SCIMUserLinkRepository.
+type GormSCIMUserLinkRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSCIMUserLinkRepository creates a new GormSCIMUserLinkRepository.
+func NewGormSCIMUserLinkRepository(db *gorm.DB) *GormSCIMUserLinkRepository {
+	return &GormSCIMUserLinkRepository{db: db}
+}
+
+// Upsert creates or updates a user's link in a tenant.
+func (r *GormSCIMUserLinkRepository) Upsert(ctx context.Context, link *domain.SCIMUserLink) error {
+	if link.ID == uuid.Nil {
+		link.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"external_id", "updated_at"}),
+		}).
+		Create(link).Error
+}
+
+// FindByUser retrieves a user's link in a tenant.
+func (r *GormSCIMUserLinkRepository) FindByUser(ctx context.Context, tenantID, userID uuid.UUID) (*domain.SCIMUserLink, error) {
+	var link domain.SCIMUserLink
+	if err := r.db.WithContext(ctx).First(&link, "tenant_id = ? AND user_id = ?", tenantID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSCIMLinkNotFound
+		}
+		return nil, err
+	}
+	return &link, nil
+}
+
+// ListByTenant retrieves all of a tenant's links.
+func (r *GormSCIMUserLinkRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.SCIMUserLink, error) {
+	var links []*domain.SCIMUserLink
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Find(&links).Error
+	return links, err
+}
+
+// Ensure GormSCIMUserLinkRepository implements SCIMUserLinkRepository
+var _ SCIMUserLinkRepository = (*GormSCIMUserLinkRepository)(nil)
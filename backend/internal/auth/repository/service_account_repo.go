@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// ServiceAccountRepository defines the interface for service account data access.
+type ServiceAccountRepository interface {
+	// Create creates a new service account.
+	Create(ctx context.Context, account *domain.ServiceAccount) error
+
+	// FindByID retrieves a tenant's service account by ID.
+	FindByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.ServiceAccount, error)
+
+	// ListByTenant lists a tenant's service accounts, ordered by name.
+	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.ServiceAccount, error)
+
+	// Update updates a service account.
+	Update(ctx context.Context, account *domain.ServiceAccount) error
+
+	// Delete deletes a tenant's service account along with its API keys.
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+}
+
+// GormServiceAccountRepository is a GORM implementation of ServiceAccountRepository.
+type GormServiceAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewGormServiceAccountRepository creates a new GormServiceAccountRepository.
+func NewGormServiceAccountRepository(db *gorm.DB) *GormServiceAccountRepository {
+	return &GormServiceAccountRepository{db: db}
+}
+
+// Create creates a new service account.
+func (r *GormServiceAccountRepository) Create(ctx context.Context, account *domain.ServiceAccount) error {
+	if account.ID == uuid.Nil {
+		account.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(account).Error
+}
+
+// FindByID retrieves a tenant's service account by ID.
+func (r *GormServiceAccountRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.ServiceAccount, error) {
+	var account domain.ServiceAccount
+	if err := r.db.WithContext(ctx).First(&account, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrServiceAccountNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ListByTenant lists a tenant's service accounts, ordered by name.
+func (r *GormServiceAccountRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.ServiceAccount, error) {
+	var accounts []*domain.ServiceAccount
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("name ASC").
+		Find(&accounts).Error
+	return accounts, err
+}
+
+// Update updates a service account.
+func (r *GormServiceAccountRepository) Update(ctx context.Context, account *domain.ServiceAccount) error {
+	return r.db.WithContext(ctx).Save(account).Error
+}
+
+// Delete deletes a tenant's service account. Its API keys go with it.
+func (r *GormServiceAccountRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&domain.ServiceAccount{}, "id = ? AND tenant_id = ?", id, tenantID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrServiceAccountNotFound
+		}
+		return tx.Delete(&domain.APIKey{}, "service_account_id = ?", id).Error
+	})
+}
+
+// Ensure GormServiceAccountRepository implements ServiceAccountRepository
+var _ ServiceAccountRepository = (*GormServiceAccountRepository)(nil)
+
+// APIKeyRepository defines the interface for service account API key data access.
+type APIKeyRepository interface {
+	// Create creates a new API key.
+	Create(ctx context.Context, key *domain.APIKey) error
+
+	// FindByID retrieves an API key by ID.
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+
+	// FindByHash retrieves an API key by its hash.
+	FindByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+
+	// ListByServiceAccount lists a service account's API keys that have not
+	// been revoked, oldest first.
+	ListByServiceAccount(ctx context.Context, serviceAccountID uuid.UUID) ([]*domain.APIKey, error)
+
+	// SetExpiry moves an API key's expiry, as when it is rotated out.
+	SetExpiry(ctx context.Context, id uuid.UUID, expiresAt time.Time) error
+
+	// Revoke revokes an API key so it stops working.
+	Revoke(ctx context.Context, id uuid.UUID) error
+
+	// TouchLastUsed records when an API key was last used.
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// GormAPIKeyRepository is a GORM implementation of APIKeyRepository.
+type GormAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewGormAPIKeyRepository creates a new GormAPIKeyRepository.
+func NewGormAPIKeyRepository(db *gorm.DB) *GormAPIKeyRepository {
+	return &GormAPIKeyRepository{db: db}
+}
+
+// Create creates a new API key.
+func (r *GormAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// FindByID retrieves an API key by ID.
+func (r *GormAPIKeyRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.db.WithContext(ctx).First(&key, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindByHash retrieves an API key by its hash.
+func (r *GormAPIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.db.WithContext(ctx).First(&key, "key_hash = ?", keyHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListByServiceAccount lists a service account's API keys that have not been revoked.
+func (r *GormAPIKeyRepository) ListByServiceAccount(ctx context.Context, serviceAccountID uuid.UUID) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	err := r.db.WithContext(ctx).
+		Where("service_account_id = ? AND revoked_at IS NULL", serviceAccountID).
+		Order("created_at ASC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// SetExpiry moves an API key's expiry.
+func (r *GormAPIKeyRepository) SetExpiry(ctx context.Context, id uuid.UUID, expiresAt time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("expires_at", expiresAt)
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// Revoke revokes an API key.
+func (r *GormAPIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records when an API key was last used.
+func (r *GormAPIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
+
+// Ensure GormAPIKeyRepository implements APIKeyRepository
+var _ APIKeyRepository = (*GormAPIKeyRepository)(nil)
@@ -33,11 +33,31 @@ type SessionRepository interface {
 	// RevokeAllForUserInTenant revokes all sessions for a user in a specific tenant.
 	RevokeAllForUserInTenant(ctx context.Context, userID, tenantID uuid.UUID) error
 
+	// RevokeForUserInTenantSince revokes a user's sessions in a tenant that
+	// were created at or after since, returning how many were revoked.
+	RevokeForUserInTenantSince(ctx context.Context, userID, tenantID uuid.UUID, since time.Time) (int64, error)
+
+	// RevokeAllForUserExcept revokes all sessions for a user other than keepID.
+	RevokeAllForUserExcept(ctx context.Context, userID, keepID uuid.UUID) error
+
+	// ListActiveForUser returns a user's active sessions, newest first.
+	ListActiveForUser(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error)
+
+	// ListActiveForUserInTenant returns a user's active sessions in a specific tenant, newest first.
+	ListActiveForUserInTenant(ctx context.Context, userID, tenantID uuid.UUID) ([]*domain.Session, error)
+
 	// DeleteExpired removes all expired sessions.
 	DeleteExpired(ctx context.Context) (int64, error)
 
 	// CountActiveForUser returns the number of active sessions for a user.
 	CountActiveForUser(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// ExistsByParent checks if a session was rotated, i.e. another session
+	// was issued in exchange for its refresh token.
+	ExistsByParent(ctx context.Context, parentID uuid.UUID) (bool, error)
+
+	// RevokeFamily revokes every active session in a refresh-token family.
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) (int64, error)
 }
 
 // GormSessionRepository is a GORM implementation of SessionRepository.
@@ -55,6 +75,10 @@ func (r *GormSessionRepository) Create(ctx context.Context, session *domain.Sess
 	if session.ID == uuid.Nil {
 		session.ID = uuid.New()
 	}
+	// A session without a family starts a new one
+	if session.FamilyID == uuid.Nil {
+		session.FamilyID = session.ID
+	}
 	return r.db.WithContext(ctx).Create(session).Error
 }
 
@@ -130,6 +154,44 @@ func (r *GormSessionRepository) RevokeAllForUserInTenant(ctx context.Context, us
 		Update("revoked_at", time.Now()).Error
 }
 
+// RevokeForUserInTenantSince revokes a user's sessions in a tenant that were
+// created at or after since, returning how many were revoked.
+func (r *GormSessionRepository) RevokeForUserInTenantSince(ctx context.Context, userID, tenantID uuid.UUID, since time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Session{}).
+		Where("user_id = ? AND tenant_id = ? AND created_at >= ? AND revoked_at IS NULL", userID, tenantID, since).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// RevokeAllForUserExcept revokes all sessions for a user other than keepID.
+func (r *GormSessionRepository) RevokeAllForUserExcept(ctx context.Context, userID, keepID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// ListActiveForUser returns a user's active sessions, newest first.
+func (r *GormSessionRepository) ListActiveForUser(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+	var sessions []*domain.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// ListActiveForUserInTenant returns a user's active sessions in a specific tenant, newest first.
+func (r *GormSessionRepository) ListActiveForUserInTenant(ctx context.Context, userID, tenantID uuid.UUID) ([]*domain.Session, error) {
+	var sessions []*domain.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND tenant_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, tenantID, time.Now()).
+		Order("created_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
 // DeleteExpired removes all expired sessions.
 func (r *GormSessionRepository) DeleteExpired(ctx context.Context) (int64, error) {
 	result := r.db.WithContext(ctx).
@@ -148,5 +210,24 @@ func (r *GormSessionRepository) CountActiveForUser(ctx context.Context, userID u
 	return count, err
 }
 
+// ExistsByParent checks if a session was rotated into a newer one.
+func (r *GormSessionRepository) ExistsByParent(ctx context.Context, parentID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Session{}).
+		Where("parent_id = ?", parentID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RevokeFamily revokes every active session in a refresh-token family.
+func (r *GormSessionRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Session{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
 // Ensure GormSessionRepository implements SessionRepository
 var _ SessionRepository = (*GormSessionRepository)(nil)
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+)
+
+// SSOConfigRepository defines the interface for tenant SSO configuration data access.
+type SSOConfigRepository interface {
+	// Create creates a tenant's SSO configuration.
+	Create(ctx context.Context, cfg *domain.TenantSSOConfig) error
+
+	// FindByTenant retrieves a tenant's SSO configuration.
+	FindByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.TenantSSOConfig, error)
+
+	// Update updates a tenant's SSO configuration.
+	Update(ctx context.Context, cfg *domain.TenantSSOConfig) error
+
+	// Delete deletes a tenant's SSO configuration.
+	Delete(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// GormSSOConfigRepository is a GORM implementation of SSOConfigRepository.
+type GormSSOConfigRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSSOConfigRepository creates a new GormSSOConfigRepository.
+func NewGormSSOConfigRepository(db *gorm.DB) *GormSSOConfigRepository {
+	return &GormSSOConfigRepository{db: db}
+}
+
+// Create creates a tenant's SSO configuration.
+func (r *GormSSOConfigRepository) Create(ctx context.Context, cfg *domain.TenantSSOConfig) error {
+	if cfg.ID == uuid.Nil {
+		cfg.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(cfg).Error
+}
+
+// FindByTenant retrieves a tenant's SSO configuration.
+func (r *GormSSOConfigRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID) (*domain.TenantSSOConfig, error) {
+	var cfg domain.TenantSSOConfig
+	if err := r.db.WithContext(ctx).First(&cfg, "tenant_id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSSONotConfigured
+		}
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Update updates a tenant's SSO configuration.
+func (r *GormSSOConfigRepository) Update(ctx context.Context, cfg *domain.TenantSSOConfig) error {
+	return r.db.WithContext(ctx).Save(cfg).Error
+}
+
+// Delete deletes a tenant's SSO configuration.
+func (r *GormSSOConfigRepository) Delete(ctx context.Context, tenantID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&domain.TenantSSOConfig{}, "tenant_id = ?", tenantID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSSONotConfigured
+	}
+	return nil
+}
+
+// Ensure GormSSOConfigRepository implements SSOConfigRepository
+var _ SSOConfigRepository = (*GormSSOConfigRepository)(nil)
+
+// SSOLoginStateRepository defines the interface for pending SSO logins.
+type SSOLoginStateRepository interface {
+	// Create creates a new SSO login state.
+	Create(ctx context.Context, state *domain.SSOLoginState) error
+
+	// FindByState retrieves a login state by its state hash.
+	FindByState(ctx context.Context, stateHash string) (*domain.SSOLoginState, error)
+
+	// MarkUsed marks an unused login state as used. It returns
+	// ErrSSOStateInvalid if it was already used, so a state can't be
+	// redeemed twice.
+	MarkUsed(ctx context.Context, id uuid.UUID) error
+
+	// DeleteExpired removes all expired login states.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// GormSSOLoginStateRepository is a GORM implementation of SSOLoginStateRepository.
+type GormSSOLoginStateRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSSOLoginStateRepository creates a new GormSSOLoginStateRepository.
+func NewGormSSOLoginStateRepository(db *gorm.DB) *GormSSOLoginStateRepository {
+	return &GormSSOLoginStateRepository{db: db}
+}
+
+// Create creates a new SSO login state.
+func (r *GormSSOLoginStateRepository) Create(ctx context.Context, state *domain.SSOLoginState) error {
+	if state.ID == uuid.Nil {
+		state.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+// FindByState retrieves a login state by its state hash.
+func (r *GormSSOLoginStateRepository) FindByState(ctx context.Context, stateHash string) (*domain.SSOLoginState, error) {
+	var state domain.SSOLoginState
+	if err := r.db.WithContext(ctx).First(&state, "state_hash = ?", stateHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSSOStateInvalid
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+// MarkUsed marks an unused login state as used.
+func (r *GormSSOLoginStateRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.SSOLoginState{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSSOStateInvalid
+	}
+	return nil
+}
+
+// DeleteExpired removes all expired login states.
+func (r *GormSSOLoginStateRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now()).
+		Delete(&domain.SSOLoginState{})
+	return result.RowsAffected, result.Error
+}
+
+// Ensure GormSSOLoginStateRepository implements SSOLoginStateRepository
+var _ SSOLoginStateRepository = (*GormSSOLoginStateRepository)(nil)
+
+// UserIdentityRepository defines the interface for identity provider links.
+type UserIdentityRepository interface {
+	// Create links an identity provider account to a user.
+	Create(ctx context.Context, identity *domain.UserIdentity) error
+
+	// FindBySubject retrieves the link for an issuer and subject.
+	FindBySubject(ctx context.Context, issuer, subject string) (*domain.UserIdentity, error)
+
+	// TouchLastLogin records a login through a linked identity.
+	TouchLastLogin(ctx context.Context, id uuid.UUID) error
+}
+
+// GormUserIdentityRepository is a GORM implementation of UserIdentityRepository.
+type GormUserIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewGormUserIdentityRepository creates a new GormUserIdentityRepository.
+func NewGormUserIdentityRepository(db *gorm.DB) *GormUserIdentityRepository {
+	return &GormUserIdentityRepository{db: db}
+}
+
+// Create links an identity provider account to a user.
+func (r *GormUserIdentityRepository) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	if identity.ID == uuid.Nil {
+		identity.ID = uuid.New()
+	}
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+// FindBySubject retrieves the link for an issuer and subject.
+func (r *GormUserIdentityRepository) FindBySubject(ctx context.Context, issuer, subject string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	if err := r.db.WithContext(ctx).First(&identity, "issuer = ? AND subject = ?", issuer, subject).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSSOIdentityNotFound
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// TouchLastLogin records a login through a linked identity.
+func (r *GormUserIdentityRepository) TouchLastLogin(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.UserIdentity{}).
+		Where("id = ?", id).
+		Update("last_login_at", time.Now()).Error
+}
+
+// Ensure GormUserIdentityRepository implements UserIdentityRepository
+var _ UserIdentityRepository = (*GormUserIdentityRepository)(nil)
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+)
+
+// MemoryTokenRevocationStore is an in-memory implementation of
+// TokenRevocationStore for single-instance deployments and tests. Entries
+// are ignored once expired and removed by a background sweep.
+type MemoryTokenRevocationStore struct {
+	tokens map[string]domain.RevokedAccessToken
+	users  map[uuid.UUID]domain.UserTokenRevocation
+	mu     sync.RWMutex
+}
+
+// NewMemoryTokenRevocationStore creates a new in-memory revocation store that
+// sweeps expired entries every cleanupInterval.
+func NewMemoryTokenRevocationStore(cleanupInterval time.Duration) *MemoryTokenRevocationStore {
+	s := &MemoryTokenRevocationStore{
+		tokens: make(map[string]domain.RevokedAccessToken),
+		users:  make(map[uuid.UUID]domain.UserTokenRevocation),
+	}
+
+	// Start background cleanup goroutine
+	go s.cleanup(cleanupInterval)
+
+	return s
+}
+
+// RevokeToken revokes a single access token by its jti.
+func (s *MemoryTokenRevocationStore) RevokeToken(ctx context.Context, token *domain.RevokedAccessToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tokens[token.JTI]; !exists {
+		entry := *token
+		if entry.RevokedAt.IsZero() {
+			entry.RevokedAt = time.Now()
+		}
+		s.tokens[token.JTI] = entry
+	}
+	return nil
+}
+
+// RevokeUser revokes every access token issued to a user before RevokedBefore.
+func (s *MemoryTokenRevocationStore) RevokeUser(ctx context.Context, revocation *domain.UserTokenRevocation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, exists := s.users[revocation.UserID]; exists && !existing.RevokedBefore.Before(revocation.RevokedBefore) {
+		return nil
+	}
+	s.users[revocation.UserID] = *revocation
+	return nil
+}
+
+// IsRevoked reports whether an access token has been revoked.
+func (s *MemoryTokenRevocationStore) IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	now := time.Now()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if token, exists := s.tokens[jti]; exists && token.ExpiresAt.After(now) {
+		return true, nil
+	}
+	if revocation, exists := s.users[userID]; exists && revocation.ExpiresAt.After(now) {
+		return revocation.Covers(issuedAt), nil
+	}
+	return false, nil
+}
+
+// DeleteExpired removes entries whose tokens have all expired.
+func (s *MemoryTokenRevocationStore) DeleteExpired(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for jti, token := range s.tokens {
+		if !token.ExpiresAt.After(now) {
+			delete(s.tokens, jti)
+			deleted++
+		}
+	}
+	for userID, revocation := range s.users {
+		if !revocation.ExpiresAt.After(now) {
+			delete(s.users, userID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// cleanup periodically removes expired entries to prevent memory leaks.
+func (s *MemoryTokenRevocationStore) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, _ = s.DeleteExpired(context.Background())
+	}
+}
+
+// Ensure MemoryTokenRevocationStore implements TokenRevocationStore
+var _ TokenRevocationStore = (*MemoryTokenRevocationStore)(nil)
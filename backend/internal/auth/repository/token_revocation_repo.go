@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenRevocationStore defines the interface for the access-token revocation
+// list, checked on every authenticated request so a token can be cut off
+// before it expires.
+type TokenRevocationStore interface {
+	// RevokeToken revokes a single access token by its jti.
+	RevokeToken(ctx context.Context, token *domain.RevokedAccessToken) error
+
+	// RevokeUser revokes every access token issued to a user before
+	// RevokedBefore. An existing entry is only ever moved later.
+	RevokeUser(ctx context.Context, revocation *domain.UserTokenRevocation) error
+
+	// IsRevoked reports whether the access token jti, issued to userID at
+	// issuedAt, has been revoked by either key.
+	IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+
+	// DeleteExpired removes entries whose tokens have all expired.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// GormTokenRevocationStore is a GORM (Postgres) implementation of
+// TokenRevocationStore, shared by every API instance.
+type GormTokenRevocationStore struct {
+	db *gorm.DB
+}
+
+// NewGormTokenRevocationStore creates a new GormTokenRevocationStore.
+func NewGormTokenRevocationStore(db *gorm.DB) *GormTokenRevocationStore {
+	return &GormTokenRevocationStore{db: db}
+}
+
+// RevokeToken revokes a single access token by its jti.
+func (r *GormTokenRevocationStore) RevokeToken(ctx context.Context, token *domain.RevokedAccessToken) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(token).Error
+}
+
+// RevokeUser revokes every access token issued to a user before RevokedBefore.
+func (r *GormTokenRevocationStore) RevokeUser(ctx context.Context, revocation *domain.UserTokenRevocation) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"revoked_before", "expires_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "user_token_revocations.revoked_before < excluded.revoked_before"},
+			}},
+		}).
+		Create(revocation).Error
+}
+
+// IsRevoked reports whether an access token has been revoked.
+func (r *GormTokenRevocationStore) IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	now := time.Now()
+	db := r.db.WithContext(ctx)
+
+	if jti != "" {
+		var count int64
+		if err := db.Model(&domain.RevokedAccessToken{}).
+			Where("jti = ? AND expires_at > ?", jti, now).
+			Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	var revocation domain.UserTokenRevocation
+	if err := db.First(&revocation, "user_id = ? AND expires_at > ?", userID, now).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return revocation.Covers(issuedAt), nil
+}
+
+// DeleteExpired removes entries whose tokens have all expired.
+func (r *GormTokenRevocationStore) DeleteExpired(ctx context.Context) (int64, error) {
+	now := time.Now()
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("expires_at < ?", now).Delete(&domain.RevokedAccessToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+
+		result = tx.Where("expires_at < ?", now).Delete(&domain.UserTokenRevocation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted += result.RowsAffected
+		return nil
+	})
+	return deleted, err
+}
+
+// Ensure GormTokenRevocationStore implements TokenRevocationStore
+var _ TokenRevocationStore = (*GormTokenRevocationStore)(nil)
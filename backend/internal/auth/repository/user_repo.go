@@ -76,6 +76,8 @@ func (r *GormUserRepository) FindByEmailWithTenants(ctx context.Context, email s
 	var user domain.User
 	if err := r.db.WithContext(ctx).
 		Preload("TenantRoles").
+		Preload("TenantRoles.CustomRole").
+		Preload("TenantRoles.ElevatedCustomRole").
 		Preload("TenantRoles.Tenant").
 		First(&user, "email = ?", email).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -91,6 +93,8 @@ func (r *GormUserRepository) FindByIDWithTenants(ctx context.Context, id uuid.UU
 	var user domain.User
 	if err := r.db.WithContext(ctx).
 		Preload("TenantRoles").
+		Preload("TenantRoles.CustomRole").
+		Preload("TenantRoles.ElevatedCustomRole").
 		Preload("TenantRoles.Tenant").
 		First(&user, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -131,8 +135,11 @@ func (r *GormUserRepository) ListByTenant(ctx context.Context, tenantID uuid.UUI
 	// Get paginated results
 	if err := r.db.WithContext(ctx).
 		Preload("TenantRoles").
+		Preload("TenantRoles.CustomRole").
+		Preload("TenantRoles.ElevatedCustomRole").
 		Joins("JOIN user_tenant_roles ON user_tenant_roles.user_id = users.id").
 		Where("user_tenant_roles.tenant_id = ?", tenantID).
+		Order("users.created_at ASC, users.id ASC").
 		Offset(offset).
 		Limit(limit).
 		Find(&users).Error; err != nil {
@@ -3,10 +3,12 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/solobueno/erp/internal/auth/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserTenantRoleRepository defines the interface for user-tenant-role data access.
@@ -31,6 +33,35 @@ type UserTenantRoleRepository interface {
 
 	// ListByTenant retrieves all role assignments for a tenant.
 	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*domain.UserTenantRole, error)
+
+	// ListByCustomRole retrieves all role assignments of a custom role,
+	// standing or elevated.
+	ListByCustomRole(ctx context.Context, customRoleID uuid.UUID) ([]*domain.UserTenantRole, error)
+
+	// ListDueElevations retrieves the role assignments whose elevation has
+	// ended by now, or has started by now without having been processed.
+	ListDueElevations(ctx context.Context, now time.Time) ([]*domain.UserTenantRole, error)
+
+	// TransferOwnership atomically swaps the roles of a tenant's owner
+	// (fromUserID) and another member (toUserID): toUserID becomes owner and
+	// fromUserID takes toUserID's previous role (and custom role, if any),
+	// which is returned. Any elevation toUserID had is cleared.
+	TransferOwnership(ctx context.Context, tenantID, fromUserID, toUserID uuid.UUID) (domain.Role, error)
+
+	// UpdateUnlessLastOwner updates a role assignment that takes the owner
+	// role away from its user, failing with ErrLastOwner unless another
+	// active user still owns the tenant. The check and the write are atomic.
+	UpdateUnlessLastOwner(ctx context.Context, role *domain.UserTenantRole) error
+
+	// DeleteUnlessLastOwner removes an owner's role in a tenant, failing with
+	// ErrLastOwner unless another active user still owns the tenant. The
+	// check and the write are atomic.
+	DeleteUnlessLastOwner(ctx context.Context, userID, tenantID uuid.UUID) error
+
+	// DeactivateUnlessLastOwner disables a user's account, failing with
+	// ErrLastOwner if they are the last active owner of any tenant. The
+	// check and the write are atomic.
+	DeactivateUnlessLastOwner(ctx context.Context, userID uuid.UUID) error
 }
 
 // GormUserTenantRoleRepository is a GORM implementation of UserTenantRoleRepository.
@@ -49,6 +80,8 @@ func (r *GormUserTenantRoleRepository) FindByUserAndTenant(ctx context.Context,
 	if err := r.db.WithContext(ctx).
 		Preload("User").
 		Preload("Tenant").
+		Preload("CustomRole").
+		Preload("ElevatedCustomRole").
 		First(&role, "user_id = ? AND tenant_id = ?", userID, tenantID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrUserNotInTenant
@@ -100,6 +133,8 @@ func (r *GormUserTenantRoleRepository) ListByUser(ctx context.Context, userID uu
 	var roles []*domain.UserTenantRole
 	if err := r.db.WithContext(ctx).
 		Preload("Tenant").
+		Preload("CustomRole").
+		Preload("ElevatedCustomRole").
 		Where("user_id = ?", userID).
 		Find(&roles).Error; err != nil {
 		return nil, err
@@ -112,6 +147,8 @@ func (r *GormUserTenantRoleRepository) ListByTenant(ctx context.Context, tenantI
 	var roles []*domain.UserTenantRole
 	if err := r.db.WithContext(ctx).
 		Preload("User").
+		Preload("CustomRole").
+		Preload("ElevatedCustomRole").
 		Where("tenant_id = ?", tenantID).
 		Find(&roles).Error; err != nil {
 		return nil, err
@@ -119,5 +156,173 @@ func (r *GormUserTenantRoleRepository) ListByTenant(ctx context.Context, tenantI
 	return roles, nil
 }
 
+// ListByCustomRole retrieves all role assignments of a custom role,
+// standing or elevated.
+func (r *GormUserTenantRoleRepository) ListByCustomRole(ctx context.Context, customRoleID uuid.UUID) ([]*domain.UserTenantRole, error) {
+	var roles []*domain.UserTenantRole
+	if err := r.db.WithContext(ctx).
+		Where("custom_role_id = ? OR elevated_custom_role_id = ?", customRoleID, customRoleID).
+		Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// ListDueElevations retrieves the role assignments whose elevation has ended
+// by now, or has started by now without having been processed.
+func (r *GormUserTenantRoleRepository) ListDueElevations(ctx context.Context, now time.Time) ([]*domain.UserTenantRole, error) {
+	var roles []*domain.UserTenantRole
+	if err := r.db.WithContext(ctx).
+		Preload("CustomRole").
+		Preload("ElevatedCustomRole").
+		Where("elevated_role <> ''").
+		Where("valid_until <= ? OR (elevation_started_at IS NULL AND valid_from <= ?)", now, now).
+		Order("valid_until ASC").
+		Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// TransferOwnership atomically swaps the roles of a tenant's owner and another member.
+// Both rows are updated conditionally on their current roles, so a concurrent
+// role change makes the transfer fail with ErrOwnershipTransferInvalid
+// instead of leaving the tenant with zero or two owners.
+func (r *GormUserTenantRoleRepository) TransferOwnership(ctx context.Context, tenantID, fromUserID, toUserID uuid.UUID) (domain.Role, error) {
+	var previousRole domain.Role
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var to domain.UserTenantRole
+		if err := tx.First(&to, "user_id = ? AND tenant_id = ?", toUserID, tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrUserNotInTenant
+			}
+			return err
+		}
+		if to.Role == domain.RoleOwner {
+			return domain.ErrOwnershipTransferInvalid
+		}
+		previousRole = to.Role
+
+		result := tx.Model(&domain.UserTenantRole{}).
+			Where("user_id = ? AND tenant_id = ? AND role = ?", fromUserID, tenantID, domain.RoleOwner).
+			Updates(map[string]interface{}{"role": previousRole, "custom_role_id": to.CustomRoleID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrOwnershipTransferInvalid
+		}
+
+		result = tx.Model(&domain.UserTenantRole{}).
+			Where("user_id = ? AND tenant_id = ? AND role = ?", toUserID, tenantID, previousRole).
+			Updates(map[string]interface{}{
+				"role":                    domain.RoleOwner,
+				"custom_role_id":          nil,
+				"elevated_role":           "",
+				"elevated_custom_role_id": nil,
+				"valid_from":              nil,
+				"valid_until":             nil,
+				"elevated_by":             nil,
+				"elevation_started_at":    nil,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrOwnershipTransferInvalid
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return previousRole, nil
+}
+
+// UpdateUnlessLastOwner updates a role assignment that takes the owner role
+// away from its user, unless they are the tenant's last active owner.
+func (r *GormUserTenantRoleRepository) UpdateUnlessLastOwner(ctx context.Context, role *domain.UserTenantRole) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureOtherActiveOwner(tx, role.TenantID, role.UserID); err != nil {
+			return err
+		}
+		return tx.Save(role).Error
+	})
+}
+
+// DeleteUnlessLastOwner removes an owner's role in a tenant, unless they are
+// the tenant's last active owner.
+func (r *GormUserTenantRoleRepository) DeleteUnlessLastOwner(ctx context.Context, userID, tenantID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureOtherActiveOwner(tx, tenantID, userID); err != nil {
+			return err
+		}
+		result := tx.Delete(&domain.UserTenantRole{}, "user_id = ? AND tenant_id = ?", userID, tenantID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrUserNotInTenant
+		}
+		return nil
+	})
+}
+
+// DeactivateUnlessLastOwner disables a user's account, unless they are the
+// last active owner of any tenant. Tenants are locked in ID order so that
+// concurrent calls can't deadlock.
+func (r *GormUserTenantRoleRepository) DeactivateUnlessLastOwner(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tenantIDs []uuid.UUID
+		if err := tx.Model(&domain.UserTenantRole{}).
+			Where("user_id = ? AND role = ?", userID, domain.RoleOwner).
+			Order("tenant_id").
+			Pluck("tenant_id", &tenantIDs).Error; err != nil {
+			return err
+		}
+		for _, tenantID := range tenantIDs {
+			if err := ensureOtherActiveOwner(tx, tenantID, userID); err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(&domain.User{}).Where("id = ?", userID).Update("is_active", false)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrUserNotFound
+		}
+		return nil
+	})
+}
+
+// ensureOtherActiveOwner locks a tenant's owner assignments until tx ends and
+// returns ErrLastOwner unless an active user other than userID holds one.
+// Holding the lock serializes every write that can take an owner away, so
+// two of them can't each count the other as the remaining owner.
+func ensureOtherActiveOwner(tx *gorm.DB, tenantID, userID uuid.UUID) error {
+	var ownerIDs []uuid.UUID
+	if err := tx.Model(&domain.UserTenantRole{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND role = ?", tenantID, domain.RoleOwner).
+		Pluck("id", &ownerIDs).Error; err != nil {
+		return err
+	}
+
+	var others int64
+	if err := tx.Model(&domain.UserTenantRole{}).
+		Joins("JOIN users ON users.id = user_tenant_roles.user_id").
+		Where("user_tenant_roles.tenant_id = ? AND user_tenant_roles.role = ?", tenantID, domain.RoleOwner).
+		Where("user_tenant_roles.user_id <> ? AND users.is_active = ?", userID, true).
+		Count(&others).Error; err != nil {
+		return err
+	}
+	if others == 0 {
+		return domain.ErrLastOwner
+	}
+	return nil
+}
+
 // Ensure GormUserTenantRoleRepository implements UserTenantRoleRepository
 var _ UserTenantRoleRepository = (*GormUserTenantRoleRepository)(nil)
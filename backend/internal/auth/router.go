@@ -10,10 +10,19 @@ import (
 )
 
 // Router creates and configures the auth router.
-func Router(authService *service.AuthService, userService *service.UserService) chi.Router {
+func Router(authService *service.AuthService, userService *service.UserService, mfaService *service.MFAService, pinService *service.PINService, approvalService *service.ApprovalService, ssoService *service.SSOService, scimService *service.SCIMService, passkeyService *service.PasskeyService, passwordPolicyService *service.PasswordPolicyService, lockoutService *service.LockoutService) chi.Router {
 	r := chi.NewRouter()
 
 	authHandler := handler.NewAuthHandler(authService)
+	mfaHandler := handler.NewMFAHandler(authService, mfaService)
+	sessionHandler := handler.NewSessionHandler(authService)
+	pinHandler := handler.NewPINHandler(pinService)
+	approvalHandler := handler.NewApprovalHandler(approvalService)
+	ssoHandler := handler.NewSSOHandler(ssoService)
+	scimHandler := handler.NewSCIMHandler(scimService)
+	passkeyHandler := handler.NewPasskeyHandler(passkeyService)
+	passwordPolicyHandler := handler.NewPasswordPolicyHandler(passwordPolicyService)
+	lockoutPolicyHandler := handler.NewLockoutPolicyHandler(lockoutService)
 	middleware := handler.NewAuthMiddleware(authService)
 
 	// Public routes (no auth required)
@@ -26,18 +35,145 @@ func Router(authService *service.AuthService, userService *service.UserService)
 		r.Post("/password-reset/complete", func(w http.ResponseWriter, req *http.Request) {
 			authHandler.CompletePasswordReset(w, req, userService)
 		})
+		r.Post("/email/verify", func(w http.ResponseWriter, req *http.Request) {
+			authHandler.VerifyEmail(w, req, userService)
+		})
+		r.Post("/email/change/confirm", func(w http.ResponseWriter, req *http.Request) {
+			authHandler.ConfirmEmailChange(w, req, userService)
+		})
+		r.Post("/email/change/revert", func(w http.ResponseWriter, req *http.Request) {
+			authHandler.RevertEmailChange(w, req, userService)
+		})
+		r.Post("/unlock/request", func(w http.ResponseWriter, req *http.Request) {
+			authHandler.RequestAccountUnlock(w, req, userService)
+		})
+		r.Post("/unlock", func(w http.ResponseWriter, req *http.Request) {
+			authHandler.UnlockAccount(w, req, userService)
+		})
+		r.Post("/invitations/accept", func(w http.ResponseWriter, req *http.Request) {
+			authHandler.AcceptInvitation(w, req, userService)
+		})
+		r.Post("/ownership-transfer/confirm", func(w http.ResponseWriter, req *http.Request) {
+			authHandler.ConfirmOwnershipTransfer(w, req, userService)
+		})
+
+		// MFA login step (authenticated by the login challenge token)
+		r.Post("/mfa/verify", mfaHandler.Verify)
+		r.Post("/mfa/setup", mfaHandler.Setup)
+		r.Post("/mfa/passkey/begin", passkeyHandler.BeginMFA)
+		r.Post("/mfa/passkey/verify", passkeyHandler.VerifyMFA)
+
+		// Passwordless passkey login
+		r.Post("/passkeys/login/begin", passkeyHandler.BeginLogin)
+		r.Post("/passkeys/login/finish", passkeyHandler.FinishLogin)
+
+		// Staff PIN login (authenticated by the terminal device token)
+		r.Post("/pin-login", pinHandler.Login)
+		r.Get("/terminal/staff", pinHandler.Staff)
+
+		// Single sign-on through the tenant's identity provider
+		r.Post("/sso/start", ssoHandler.Start)
+		r.Post("/sso/callback", ssoHandler.Callback)
 	})
 
-	// Protected routes (auth required)
+	// Protected routes (auth required, people only)
 	r.Group(func(r chi.Router) {
 		r.Use(middleware.RequireAuth)
+		r.Use(middleware.RequireUser)
 
 		// Auth endpoints
 		r.Post("/logout", authHandler.Logout)
 		r.Get("/me", authHandler.Me)
-		r.Post("/change-password", func(w http.ResponseWriter, req *http.Request) {
+		r.Post("/switch-tenant", authHandler.SwitchTenant)
+		r.With(middleware.DenyImpersonation).Post("/change-password", func(w http.ResponseWriter, req *http.Request) {
 			authHandler.ChangePassword(w, req, userService)
 		})
+		r.Post("/email/verify/request", func(w http.ResponseWriter, req *http.Request) {
+			authHandler.RequestEmailVerification(w, req, userService)
+		})
+		r.With(middleware.DenyImpersonation).Post("/email/change", func(w http.ResponseWriter, req *http.Request) {
+			authHandler.RequestEmailChange(w, req, userService)
+		})
+
+		// Session (device) management
+		r.Get("/sessions", sessionHandler.List)
+		r.Delete("/sessions/{id}", sessionHandler.Revoke)
+		r.Post("/sessions/revoke-others", sessionHandler.RevokeOthers)
+
+		// Passkeys (the devices that can sign the user in)
+		r.Get("/passkeys", passkeyHandler.List)
+		r.Patch("/passkeys/{id}", passkeyHandler.Rename)
+
+		// MFA management
+		r.Get("/mfa", mfaHandler.Status)
+
+		// Credential changes are the user's own to make, never an impersonator's
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.DenyImpersonation)
+
+			r.Post("/mfa/enroll", mfaHandler.Enroll)
+			r.Post("/mfa/enroll/confirm", mfaHandler.ConfirmEnrollment)
+			r.Post("/mfa/disable", mfaHandler.Disable)
+			r.Post("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+
+			r.Post("/passkeys/register/begin", passkeyHandler.BeginRegistration)
+			r.Post("/passkeys/register/finish", passkeyHandler.FinishRegistration)
+			r.Delete("/passkeys/{id}", passkeyHandler.Remove)
+
+			// Staff PIN management
+			r.Put("/pin", pinHandler.SetPIN)
+			r.Delete("/pin", pinHandler.RemovePIN)
+		})
+
+		// Step-up manager approval for sensitive actions
+		r.Post("/approvals", approvalHandler.Approve)
+
+		// POS terminal management
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequirePermission(domain.PermTerminalsManage))
+
+			r.Post("/terminals", pinHandler.RegisterTerminal)
+			r.Get("/terminals", pinHandler.ListTerminals)
+			r.Delete("/terminals/{id}", pinHandler.RevokeTerminal)
+		})
+
+		// SSO configuration (Admin+, never while impersonating)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole(domain.RoleAdmin))
+			r.Use(middleware.DenyImpersonation)
+
+			r.Get("/sso/config", ssoHandler.GetConfig)
+			r.Put("/sso/config", ssoHandler.PutConfig)
+			r.Delete("/sso/config", ssoHandler.DeleteConfig)
+		})
+
+		// Password policy (Admin+, never while impersonating)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole(domain.RoleAdmin))
+			r.Use(middleware.DenyImpersonation)
+
+			r.Get("/password-policy", passwordPolicyHandler.GetPolicy)
+			r.Put("/password-policy", passwordPolicyHandler.PutPolicy)
+		})
+
+		// Lockout policy (Admin+, never while impersonating)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole(domain.RoleAdmin))
+			r.Use(middleware.DenyImpersonation)
+
+			r.Get("/lockout-policy", lockoutPolicyHandler.GetPolicy)
+			r.Put("/lockout-policy", lockoutPolicyHandler.PutPolicy)
+		})
+
+		// SCIM token management (Admin+, never while impersonating)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole(domain.RoleAdmin))
+			r.Use(middleware.DenyImpersonation)
+
+			r.Post("/scim/tokens", scimHandler.CreateToken)
+			r.Get("/scim/tokens", scimHandler.ListTokens)
+			r.Delete("/scim/tokens/{id}", scimHandler.RevokeToken)
+		})
 	})
 
 	return r
@@ -48,22 +184,187 @@ func UserRouter(authService *service.AuthService, userService *service.UserServi
 	r := chi.NewRouter()
 
 	userHandler := handler.NewUserHandler(userService)
+	impersonationHandler := handler.NewImpersonationHandler(authService)
 	middleware := handler.NewAuthMiddleware(authService)
 
-	// All user routes require authentication
+	// All user routes require a person's authentication
 	r.Use(middleware.RequireAuth)
+	r.Use(middleware.RequireUser)
 
-	// Routes requiring Manager+ role
+	// Ownership transfer (Owner only)
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.RequireRole(domain.RoleManager))
+		r.Use(middleware.RequireRole(domain.RoleOwner))
 
-		r.Post("/", userHandler.Create)
+		r.Post("/ownership-transfer", userHandler.StartOwnershipTransfer)
+		r.Delete("/ownership-transfer", userHandler.CancelOwnershipTransfer)
+	})
+
+	// User management
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequirePermission(domain.PermUsersManage))
+
+		// Invitations
+		r.Post("/invitations", userHandler.Invite)
+		r.Get("/invitations", userHandler.ListInvitations)
+		r.Post("/invitations/{id}/resend", userHandler.ResendInvitation)
+		r.Delete("/invitations/{id}", userHandler.RevokeInvitation)
+
+		// Users
 		r.Get("/", userHandler.List)
 		r.Get("/{id}", userHandler.Get)
 		r.Patch("/{id}", userHandler.Update)
 		r.Patch("/{id}/role", userHandler.UpdateRole)
+		r.Post("/{id}/role/elevation", userHandler.ElevateRole)
+		r.Delete("/{id}/role/elevation", userHandler.EndElevation)
 		r.Post("/{id}/unlock", userHandler.Unlock)
+		r.Get("/{id}/sessions", userHandler.ListSessions)
+		r.Delete("/{id}/sessions", userHandler.RevokeSessions)
+		r.Delete("/{id}/sessions/{sessionId}", userHandler.RevokeSession)
+		r.Delete("/{id}/tenants/{tenantId}", userHandler.RemoveFromTenant)
+	})
+
+	// Impersonation (no impersonating from an impersonation)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequirePermission(domain.PermUsersImpersonate))
+		r.Use(middleware.DenyImpersonation)
+
+		r.Post("/{id}/impersonate", impersonationHandler.Impersonate)
 	})
 
 	return r
 }
+
+// RoleRouter creates and configures the custom role router.
+func RoleRouter(authService *service.AuthService, roleService *service.RoleService) chi.Router {
+	r := chi.NewRouter()
+
+	roleHandler := handler.NewRoleHandler(roleService)
+	middleware := handler.NewAuthMiddleware(authService)
+
+	// All role routes require a person's authentication
+	r.Use(middleware.RequireAuth)
+	r.Use(middleware.RequireUser)
+
+	// Permission catalog, for building role editors
+	r.Get("/permissions", roleHandler.ListPermissions)
+
+	// Custom role management (Admin+)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireRole(domain.RoleAdmin))
+
+		r.Get("/", roleHandler.List)
+		r.Post("/", roleHandler.Create)
+		r.Get("/{id}", roleHandler.Get)
+		r.Patch("/{id}", roleHandler.Update)
+		r.Delete("/{id}", roleHandler.Delete)
+	})
+
+	return r
+}
+
+// ServiceAccountRouter creates and configures the service account router.
+func ServiceAccountRouter(authService *service.AuthService, serviceAccountService *service.ServiceAccountService) chi.Router {
+	r := chi.NewRouter()
+
+	serviceAccountHandler := handler.NewServiceAccountHandler(serviceAccountService)
+	middleware := handler.NewAuthMiddleware(authService)
+
+	// Service accounts and their keys are managed by admins, never by a
+	// service account or while impersonating
+	r.Use(middleware.RequireAuth)
+	r.Use(middleware.RequireUser)
+	r.Use(middleware.RequireRole(domain.RoleAdmin))
+	r.Use(middleware.DenyImpersonation)
+
+	r.Get("/", serviceAccountHandler.List)
+	r.Post("/", serviceAccountHandler.Create)
+	r.Get("/{id}", serviceAccountHandler.Get)
+	r.Patch("/{id}", serviceAccountHandler.Update)
+	r.Delete("/{id}", serviceAccountHandler.Delete)
+
+	// API keys
+	r.Get("/{id}/keys", serviceAccountHandler.ListKeys)
+	r.Post("/{id}/keys", serviceAccountHandler.CreateKey)
+	r.Post("/{id}/keys/{keyId}/rotate", serviceAccountHandler.RotateKey)
+	r.Delete("/{id}/keys/{keyId}", serviceAccountHandler.RevokeKey)
+
+	return r
+}
+
+// OAuthRouter creates and configures the OAuth 2.0 authorization server
+// router. The token and revocation endpoints are called by OAuth clients,
+// which authenticate with their own credentials; every other route is for a
+// signed-in person.
+func OAuthRouter(authService *service.AuthService, oauthService *service.OAuthService) chi.Router {
+	r := chi.NewRouter()
+
+	oauthHandler := handler.NewOAuthHandler(oauthService)
+	middleware := handler.NewAuthMiddleware(authService)
+
+	// Client endpoints
+	r.Post("/token", oauthHandler.Token)
+	r.Post("/revoke", oauthHandler.Revoke)
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireAuth)
+		r.Use(middleware.RequireUser)
+		r.Use(middleware.DenyImpersonation)
+
+		// Authorizing clients for the tenant, and withdrawing that (Owner)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole(domain.RoleOwner))
+
+			r.Get("/authorize", oauthHandler.GetAuthorization)
+			r.Post("/authorize", oauthHandler.Authorize)
+			r.Get("/consents", oauthHandler.ListConsents)
+			r.Delete("/consents/{id}", oauthHandler.RevokeConsent)
+		})
+
+		// Client registration (Admin+)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole(domain.RoleAdmin))
+
+			r.Get("/clients", oauthHandler.ListClients)
+			r.Post("/clients", oauthHandler.CreateClient)
+			r.Get("/clients/{id}", oauthHandler.GetClient)
+			r.Patch("/clients/{id}", oauthHandler.UpdateClient)
+			r.Delete("/clients/{id}", oauthHandler.DeleteClient)
+			r.Post("/clients/{id}/secret", oauthHandler.RotateClientSecret)
+		})
+	})
+
+	return r
+}
+
+// SCIMRouter creates and configures the SCIM 2.0 provisioning router. Every
+// route is authenticated by a tenant's SCIM token rather than a user's
+// access token.
+func SCIMRouter(scimService *service.SCIMService) chi.Router {
+	r := chi.NewRouter()
+
+	scimHandler := handler.NewSCIMHandler(scimService)
+
+	r.Use(scimHandler.RequireToken)
+
+	// Discovery
+	r.Get("/ServiceProviderConfig", scimHandler.ServiceProviderConfig)
+	r.Get("/ResourceTypes", scimHandler.ResourceTypes)
+
+	// Users
+	r.Get("/Users", scimHandler.ListUsers)
+	r.Post("/Users", scimHandler.CreateUser)
+	r.Get("/Users/{id}", scimHandler.GetUser)
+	r.Put("/Users/{id}", scimHandler.ReplaceUser)
+	r.Patch("/Users/{id}", scimHandler.PatchUser)
+	r.Delete("/Users/{id}", scimHandler.DeleteUser)
+
+	// Groups (the tenant's roles, so they can't be created or deleted here)
+	r.Get("/Groups", scimHandler.ListGroups)
+	r.Post("/Groups", scimHandler.GroupsReadOnly)
+	r.Get("/Groups/{id}", scimHandler.GetGroup)
+	r.Put("/Groups/{id}", scimHandler.ReplaceGroup)
+	r.Patch("/Groups/{id}", scimHandler.PatchGroup)
+	r.Delete("/Groups/{id}", scimHandler.GroupsReadOnly)
+
+	return r
+}
@@ -16,17 +16,44 @@ import (
 )
 
 // publicRoutes lists the only endpoints allowed to skip authentication:
-// login/refresh (that's how you get a token) and password reset (used by
-// someone who, by definition, can't log in yet). SC-003 requires 100% of
+// login/refresh (that's how you get a token), password reset (used by
+// someone who, by definition, can't log in yet), account unlock (used by
+// someone locked out of logging in), following an emailed verification,
+// email change or unlock link (authenticated by its token, and may be
+// opened on a device that isn't signed in), accepting an invitation
+// or an ownership transfer (authenticated by the emailed token plus, for a
+// transfer, the recipient's password), the MFA login step (authenticated
+// by the login challenge token instead), passkey login (authenticated by
+// the passkey itself), SSO login (authenticated by the identity provider) and the OAuth token and revocation endpoints
+// (authenticated by the client's credentials). SC-003 requires 100% of
 // every other endpoint to enforce auth.
 var publicRoutes = map[string]bool{
-	"POST /login":                   true,
-	"POST /refresh":                 true,
-	"POST /password-reset/request":  true,
-	"POST /password-reset/complete": true,
+	"POST /login":                      true,
+	"POST /refresh":                    true,
+	"POST /password-reset/request":     true,
+	"POST /password-reset/complete":    true,
+	"POST /unlock/request":             true,
+	"POST /unlock":                     true,
+	"POST /email/verify":               true,
+	"POST /email/change/confirm":       true,
+	"POST /email/change/revert":        true,
+	"POST /invitations/accept":         true,
+	"POST /ownership-transfer/confirm": true,
+	"POST /mfa/verify":                 true,
+	"POST /mfa/setup":                  true,
+	"POST /mfa/passkey/begin":          true,
+	"POST /mfa/passkey/verify":         true,
+	"POST /passkeys/login/begin":       true,
+	"POST /passkeys/login/finish":      true,
+	"POST /pin-login":                  true,
+	"GET /terminal/staff":              true,
+	"POST /sso/start":                  true,
+	"POST /sso/callback":               true,
+	"POST /token":                      true,
+	"POST /revoke":                     true,
 }
 
-func testServices(t *testing.T) (*service.AuthService, *service.UserService) {
+func testServices(t *testing.T) (*service.AuthService, *service.UserService, *service.MFAService, *service.PINService, *service.RoleService) {
 	t.Helper()
 
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
@@ -49,6 +76,11 @@ func testServices(t *testing.T) (*service.AuthService, *service.UserService) {
 	}
 	tokenSvc := service.NewTokenService(km, jwt.DefaultTokenGeneratorConfig())
 
+	mfaSvc := service.NewMFAService(service.MFAServiceConfig{
+		MFARepo:       mock.NewMockMFARepository(),
+		ChallengeRepo: mock.NewMockMFAChallengeRepository(),
+		EventRepo:     mock.NewMockAuthEventRepository(),
+	})
 	authSvc := service.NewAuthService(service.AuthServiceConfig{
 		UserRepo:     mock.NewMockUserRepository(),
 		SessionRepo:  mock.NewMockSessionRepository(),
@@ -56,6 +88,7 @@ func testServices(t *testing.T) (*service.AuthService, *service.UserService) {
 		TenantRepo:   mock.NewMockTenantRepository(),
 		RoleRepo:     mock.NewMockUserTenantRoleRepository(),
 		TokenService: tokenSvc,
+		MFAService:   mfaSvc,
 	})
 	userSvc := service.NewUserService(service.UserServiceConfig{
 		UserRepo:      mock.NewMockUserRepository(),
@@ -65,20 +98,39 @@ func testServices(t *testing.T) (*service.AuthService, *service.UserService) {
 		PasswordReset: mock.NewMockPasswordResetRepository(),
 	})
 
-	return authSvc, userSvc
+	pinSvc := service.NewPINService(service.PINServiceConfig{
+		PINRepo:      mock.NewMockStaffPINRepository(),
+		TerminalRepo: mock.NewMockTerminalRepository(),
+		UserRepo:     mock.NewMockUserRepository(),
+		TenantRepo:   mock.NewMockTenantRepository(),
+		EventRepo:    mock.NewMockAuthEventRepository(),
+		TokenService: tokenSvc,
+	})
+
+	roleSvc := service.NewRoleService(service.RoleServiceConfig{
+		CustomRoles: mock.NewMockCustomRoleRepository(),
+		RoleRepo:    mock.NewMockUserTenantRoleRepository(),
+		EventRepo:   mock.NewMockAuthEventRepository(),
+	})
+
+	return authSvc, userSvc, mfaSvc, pinSvc, roleSvc
 }
 
-// TestRouteAuthCoverage walks every registered route in both the auth and
-// user routers and, for anything not explicitly public, fires a request
-// with no Authorization header. Each must come back 401 — proving the
-// route actually goes through RequireAuth rather than just trusting that a
-// r.Use() call was added correctly (SC-003, SC-004).
+// TestRouteAuthCoverage walks every registered route in the auth, user,
+// role, service account, OAuth and SCIM routers and, for anything not explicitly
+// public, fires a request with no Authorization header. Each must come back
+// 401 — proving the route actually goes through RequireAuth rather than just
+// trusting that a r.Use() call was added correctly (SC-003, SC-004).
 func TestRouteAuthCoverage(t *testing.T) {
-	authSvc, userSvc := testServices(t)
+	authSvc, userSvc, mfaSvc, pinSvc, roleSvc := testServices(t)
 
 	routers := map[string]chi.Router{
-		"auth": Router(authSvc, userSvc),
-		"user": UserRouter(authSvc, userSvc),
+		"auth":             Router(authSvc, userSvc, mfaSvc, pinSvc, nil, nil, nil, nil, nil, nil),
+		"user":             UserRouter(authSvc, userSvc),
+		"role":             RoleRouter(authSvc, roleSvc),
+		"scim":             SCIMRouter(nil),
+		"service-accounts": ServiceAccountRouter(authSvc, nil),
+		"oauth":            OAuthRouter(authSvc, nil),
 	}
 
 	checked := 0
@@ -2,10 +2,17 @@
 //
 // This package is the main entry point for the auth module and provides:
 //   - User authentication (login, logout, token refresh)
-//   - User management (CRUD operations)
+//   - User management (CRUD operations, onboarding by email invitation)
 //   - Role-based access control (RBAC)
-//   - Password management (change, reset)
+//   - Password management (change, reset) under per-tenant password policies
+//   - Email verification and confirmed email changes
+//   - TOTP multi-factor authentication (required for manager and above)
+//   - WebAuthn passkeys for passwordless login or as the second factor
 //   - Session management
+//   - Staff PIN login on registered POS terminals
+//   - Single sign-on through each tenant's OpenID Connect provider
+//   - Service accounts with scoped API keys for integrations
+//   - An OAuth 2.0 authorization server for third-party apps
 //
 // # Quick Start
 //
@@ -35,16 +42,120 @@
 //   - POST /refresh        - Refresh access token
 //   - POST /logout         - Invalidate session
 //   - GET  /me             - Get current user info
+//   - POST /switch-tenant  - Move the session to another of my tenants
 //   - POST /change-password - Change password
 //   - POST /password-reset/request  - Request password reset
 //   - POST /password-reset/complete - Complete password reset
+//   - POST /email/verify/request - Email me a link verifying my address
+//   - POST /email/verify   - Verify an address (verification token)
+//   - POST /email/change   - Start changing my email (current password)
+//   - POST /email/change/confirm - Move to the new address (change token)
+//   - POST /email/change/revert  - Undo an email change (undo token)
+//   - POST /unlock/request - Email me a link lifting a lockout
+//   - POST /unlock         - Lift a lockout (unlock token)
+//   - POST /invitations/accept - Accept an invitation (invite token)
+//   - POST /ownership-transfer/confirm - Accept tenant ownership (transfer token)
+//   - GET  /sessions       - List my signed-in devices
+//   - DELETE /sessions/{id} - Sign out one of my devices
+//   - POST /sessions/revoke-others - Sign out everywhere except this device
+//   - POST /mfa/verify     - Complete an MFA login challenge
+//   - POST /mfa/setup      - Start enrollment during an MFA login challenge
+//   - GET  /mfa            - Get MFA status
+//   - POST /mfa/enroll     - Start TOTP enrollment
+//   - POST /mfa/enroll/confirm - Confirm enrollment, get recovery codes
+//   - POST /mfa/disable    - Disable MFA
+//   - POST /mfa/recovery-codes - Regenerate recovery codes
+//   - POST /mfa/passkey/begin  - Get passkey options for an MFA login challenge
+//   - POST /mfa/passkey/verify - Complete an MFA login challenge with a passkey
+//   - POST /passkeys/login/begin  - Get options for a passwordless login
+//   - POST /passkeys/login/finish - Log in with a passkey
+//   - GET  /passkeys       - List my passkeys
+//   - POST /passkeys/register/begin  - Start registering a passkey (Manager+)
+//   - POST /passkeys/register/finish - Finish registering a passkey (Manager+)
+//   - PATCH /passkeys/{id} - Rename one of my passkeys
+//   - DELETE /passkeys/{id} - Remove one of my passkeys
+//   - POST /pin-login      - Log in with a staff PIN (terminal token)
+//   - GET  /terminal/staff - List staff who can PIN-login (terminal token)
+//   - PUT  /pin            - Set my PIN
+//   - DELETE /pin          - Remove my PIN
+//   - POST /terminals      - Register a POS terminal (Manager+)
+//   - GET  /terminals      - List POS terminals (Manager+)
+//   - DELETE /terminals/{id} - Revoke a POS terminal (Manager+)
+//   - POST /approvals      - Get a manager's approval for a sensitive action
+//   - POST /sso/start      - Start an SSO login to a tenant
+//   - POST /sso/callback   - Complete an SSO login (state and code)
+//   - GET  /sso/config     - Get the tenant's SSO configuration (Admin+)
+//   - PUT  /sso/config     - Set up the tenant's identity provider (Admin+)
+//   - DELETE /sso/config   - Remove the tenant's SSO (Admin+)
+//   - GET  /password-policy - Get the tenant's password policy (Admin+)
+//   - PUT  /password-policy - Set the tenant's password policy (Admin+)
+//   - GET  /lockout-policy - Get the tenant's lockout policy (Admin+)
+//   - PUT  /lockout-policy - Set the tenant's lockout policy (Admin+)
+//   - POST /scim/tokens    - Create a SCIM token (Admin+)
+//   - GET  /scim/tokens    - List SCIM tokens (Admin+)
+//   - DELETE /scim/tokens/{id} - Revoke a SCIM token (Admin+)
 //
 // User endpoints (base: /api/v1/users):
-//   - POST   /           - Create user (Manager+)
+//   - POST   /ownership-transfer - Offer tenant ownership to a member (Owner)
+//   - DELETE /ownership-transfer - Cancel the pending ownership transfer (Owner)
+//   - POST   /invitations - Invite someone to the tenant (Manager+)
+//   - GET    /invitations - List pending invitations (Manager+)
+//   - POST   /invitations/{id}/resend - Resend an invitation (Manager+)
+//   - DELETE /invitations/{id} - Revoke an invitation (Manager+)
 //   - GET    /           - List users (Manager+)
 //   - GET    /{id}       - Get user (Manager+)
 //   - PATCH  /{id}       - Update user (Manager+)
 //   - PATCH  /{id}/role  - Change user role (Manager+)
+//   - POST   /{id}/role/elevation - Temporarily elevate user role (Manager+)
+//   - DELETE /{id}/role/elevation - End a role elevation early (Manager+)
+//   - GET    /{id}/sessions - List user's sessions in this tenant (Manager+)
+//   - DELETE /{id}/sessions - Revoke all of user's sessions in this tenant (Manager+)
+//   - DELETE /{id}/sessions/{sessionId} - Revoke one session (Manager+)
+//   - DELETE /{id}/tenants/{tenantId} - Remove user from this tenant (Manager+)
+//   - POST   /{id}/impersonate - Act as a lower-ranked user (Admin+)
+//
+// Role endpoints (base: /api/v1/roles):
+//   - GET    /permissions - List the permission catalog
+//   - GET    /           - List custom roles (Admin+)
+//   - POST   /           - Create a custom role (Admin+)
+//   - GET    /{id}       - Get a custom role (Admin+)
+//   - PATCH  /{id}       - Rename a custom role or change its permissions (Admin+)
+//   - DELETE /{id}       - Delete an unassigned custom role (Admin+)
+//
+// Service account endpoints (base: /api/v1/service-accounts, Admin+):
+//   - GET    /           - List service accounts
+//   - POST   /           - Create a service account
+//   - GET    /{id}       - Get a service account
+//   - PATCH  /{id}       - Rename a service account or change its scopes
+//   - DELETE /{id}       - Delete a service account and its keys
+//   - GET    /{id}/keys  - List API keys
+//   - POST   /{id}/keys  - Create an API key
+//   - POST   /{id}/keys/{keyId}/rotate - Replace an API key, with a grace period
+//   - DELETE /{id}/keys/{keyId} - Revoke an API key
+//
+// OAuth endpoints (base: /api/v1/oauth):
+//   - POST   /token      - Token endpoint (client authentication)
+//   - POST   /revoke     - Revoke a token (client authentication)
+//   - GET    /authorize  - Check an authorization request (Owner)
+//   - POST   /authorize  - Approve or deny an authorization request (Owner)
+//   - GET    /consents   - List authorized apps (Owner)
+//   - DELETE /consents/{id} - Revoke an app's access (Owner)
+//   - GET    /clients    - List registered clients (Admin+)
+//   - POST   /clients    - Register a client (Admin+)
+//   - GET    /clients/{id} - Get a client (Admin+)
+//   - PATCH  /clients/{id} - Rename a client or change its redirect URIs or scopes (Admin+)
+//   - DELETE /clients/{id} - Delete a client (Admin+)
+//   - POST   /clients/{id}/secret - Rotate a client secret (Admin+)
+//
+// SCIM 2.0 provisioning (base: /scim/v2, SCIM token):
+//   - GET    /ServiceProviderConfig, /ResourceTypes - Discovery
+//   - GET    /Users, POST /Users - Query or provision users
+//   - GET, PUT, PATCH, DELETE /Users/{id} - Read, update or deprovision a user
+//   - GET    /Groups     - Query role groups
+//   - GET, PUT, PATCH /Groups/{id} - Read a role group or change its members
+//
+// Key discovery (unversioned, public):
+//   - GET /.well-known/jwks.json - Public keys for verifying access tokens
 //
 // # Roles
 //
@@ -57,14 +168,233 @@
 //   - kitchen (30)  - Can view/update order status
 //   - viewer  (10)  - Read-only access
 //
+// Tenants can also define custom roles ("bartender", "shift lead"). A
+// custom role derives from a built-in base role, which decides where it
+// ranks, and grants its own permission set instead of the base role's.
+// It is assigned through PATCH /users/{id}/role with custom_role_id.
+//
+// A role can also be raised for a time window (a waiter covering as shift
+// lead for a night) through POST /users/{id}/role/elevation. The standing
+// role is kept and applies again once the window ends; run
+// UserService.RunElevationExpirer to audit each elevation as it starts and
+// ends, and revoke the sessions issued under it when it does.
+//
+// # Permissions
+//
+// Route access is checked against permissions rather than role levels.
+// Each module declares its permissions ("<module>.<action>", e.g.
+// "orders.void") with domain.RegisterPermissions from an init function,
+// listing the roles granted each one by default. Access tokens carry the
+// resulting set (or the custom role's) in the perms claim, GET /me lists
+// it, and routes guard on it with AuthMiddleware.RequirePermission:
+//
+//	r.With(mw.RequirePermission(orders.PermVoid)).Post("/orders/{id}/void", h.Void)
+//
+// # Step-up Approvals
+//
+// Some actions need a manager's sign-off on top of the caller's own
+// permission, such as a cashier refunding a payment. The manager enters
+// their password, or their PIN on a registered terminal, on the cashier's
+// device through POST /approvals, and the cashier gets a single-use token
+// for that action on that resource, valid for 2 minutes. Routes require it
+// in the X-Approval-Token header with Module.RequireApproval, naming the
+// URL parameter that identifies the resource:
+//
+//	r.With(authModule.RequireApproval(payments.PermRefund, "id")).Post("/payments/{id}/refund", h.Refund)
+//
+// # Impersonation
+//
+// Support can see exactly what a user sees without asking for their
+// password: POST /users/{id}/impersonate (users.impersonate, Admin+ by
+// default) issues a 15-minute access token for a user ranked below the
+// caller, with no refresh token. The caller rides along in the token's act
+// claim. GET /me reports them as impersonated_by, every audit event written
+// under the token records them in actor_id and the access log in actor_id,
+// and routes that change credentials (password, MFA, PIN) reject the token.
+// Guard other such routes with AuthMiddleware.DenyImpersonation.
+//
+// # Passkeys
+//
+// Managers, admins and owners can register WebAuthn passkeys (platform
+// authenticators such as Touch ID or Windows Hello, security keys, or
+// passkeys synced by a password manager) and then sign in with one instead
+// of a password, or use one in place of the TOTP code after a password. A
+// passkey counts as their second factor, so a manager with only passkeys is
+// enrolled in MFA; mfa_required challenges list the methods the user has in
+// mfa_methods. Passkeys are tied to the relying party in ModuleConfig's
+// WebAuthn setting (the site's domain and the origins the frontend is
+// served from); without an RP ID the passkey endpoints answer 503. Logins
+// require user verification, and a passwordless login still honors the
+// tenant's SSO policy.
+//
+// # Password Policy
+//
+// Each tenant sets the rules for its members' passwords through PUT
+// /password-policy: a minimum length (8 to 128), required character
+// classes, a maximum age after which the password must be reset at the
+// next login, and how many recent passwords (counting the current one)
+// can't be reused. Tenants that haven't set one require 8 characters with
+// an uppercase letter, a lowercase letter and a digit. A password is
+// shared by all of a user's tenants, so it must meet the strictest
+// combination of their policies. Every new password is also checked,
+// ignoring case, against a list of breached passwords held offline in a
+// Bloom filter: a list of common ones is bundled, and ModuleConfig's
+// BreachedPasswords can supply a larger one. Refused passwords get a
+// password_weak error whose rule field names the rule broken.
+//
+// # Password Hashing
+//
+// Passwords are hashed with Argon2id, optionally after an HMAC-SHA256 with
+// a server-side pepper set through ModuleConfig's PasswordHashing (and
+// PASSWORD_PEPPERS for the server). Each hash records its parameters and
+// pepper version, so both can change without forcing password resets: a
+// hash made with other parameters or an older pepper still verifies, and
+// is replaced at the user's next successful login. Retired peppers must
+// stay configured until `migrate hash-report`, which counts users' hashes
+// by parameters and pepper version, shows none still use them.
+//
+// # Account Lockout
+//
+// Repeated wrong passwords delay further logins instead of locking the
+// account outright: once a device reaches its tenant's threshold of
+// consecutive failures, each further failure locks logins from such
+// devices for a delay that doubles from a base up to a cap (by default the
+// 5th failure, 1 minute doubling to 15). Login responses carry a
+// device_token for the client to send on later logins; devices presenting
+// one are counted separately with their own, higher threshold (10 by
+// default), so someone guessing from elsewhere can't lock staff out of the
+// devices they use. Admins tune both through PUT /lockout-policy, and a
+// user in several tenants follows the strictest combination. A locked-out
+// user can have a link (1-hour expiry) sent to their verified address
+// through POST /unlock/request that lifts every lock, and managers can
+// still unlock staff from the user endpoints.
+//
+// # Email Verification
+//
+// Password reset links are only sent to verified addresses, so a typo'd or
+// stale email can never be used to take over an account; requests for an
+// unverified one get the usual 202 and nothing is sent. Accepting an
+// invitation verifies the address it went to, and anyone else can have a
+// verification link (24-hour expiry) emailed through POST
+// /email/verify/request. Changing email takes the current password and
+// only happens once the new address confirms it (1-hour expiry). The
+// change signs the user out everywhere and sends the old address a link
+// that undoes it for 7 days; undoing it signs everyone out again and makes
+// the user set a new password, since whoever made the change knew it.
+//
+// # Single Sign-On
+//
+// A tenant can let staff sign in through its company identity provider
+// with OpenID Connect. An admin registers the provider's issuer, a client
+// ID and secret, and the email domains staff accounts use, through PUT
+// /sso/config; the provider must send users back to the module's
+// SSORedirectURL, a frontend page that posts the state and code it
+// receives to /sso/callback. Logins use the authorization code flow with
+// PKCE and a nonce, and the ID token is checked against the provider's
+// JWKS. The first SSO login links the provider account to the existing
+// member with the same email, which the provider must have verified and
+// which must be in an allowed domain; SSO never creates accounts. The
+// tenant can also turn password login off, leaving SSO the only way in
+// for everyone but its owners.
+//
+// # SCIM Provisioning
+//
+// A tenant's HR system or identity provider can keep its staff in sync
+// over SCIM 2.0 with a bearer token an admin creates through POST
+// /scim/tokens. Users are the tenant's members, matched by email as
+// userName; groups are its roles, built-in ones by name ("cashier") and
+// custom ones by ID, and adding a member to a group assigns them that
+// role. New hires join as viewers with a password they must reset, and are
+// emailed a link verifying their address so they can reset it; existing
+// accounts from other tenants must be invited instead. Setting
+// active to false, or deleting the user, deprovisions them: they are
+// removed from the tenant and their sessions and access tokens revoked,
+// but the account is kept and they stay listed as inactive so they can be
+// provisioned again. SCIM clients act as an admin, so they never manage
+// admins or owners.
+//
+// # Service Accounts
+//
+// Integrations such as an accounting sync or a delivery platform run as a
+// tenant service account rather than a staff member's login. An admin
+// creates the account with scopes, permissions they hold themselves, and
+// API keys for it; a key goes in the Authorization header in place of an
+// access token ("Bearer sbk_..."), and RequireAuth accepts it with the
+// account's ID as the user ID and its scopes as the permissions, ranked as
+// a viewer. Routes meant for people (the auth, user, role and service
+// account endpoints) refuse keys; guard other such routes with
+// AuthMiddleware.RequireUser. Keys expire after at most a year, and
+// rotating one keeps the old key working for up to 7 days so the
+// integration can switch over.
+//
+// # OAuth
+//
+// Third-party apps such as a reservation or delivery platform act on a
+// tenant's behalf through OAuth 2.0. An admin of the platform's own tenant
+// registers the app as a client with redirect URIs and scopes; the owner of
+// any tenant can then authorize it with the authorization code flow, which
+// requires PKCE (S256). The owner's frontend shows the consent screen from
+// GET /oauth/authorize and sends the decision to POST /oauth/authorize,
+// which returns where to redirect the browser. Access tokens are ordinary
+// JWTs carrying the client in the client_id claim: RequireAuth accepts them
+// with the owner as the user and the granted scopes, narrowed to what the
+// owner still holds, as the permissions. Confidential clients can also act
+// as themselves in their own tenant with the client credentials grant.
+// Client tokens are refused by routes meant for people, like API keys, and
+// stop working as soon as the consent is revoked or the client deleted.
+//
 // # Security
 //
 // The module implements several security measures:
-//   - Argon2id password hashing with OWASP-recommended parameters
-//   - RS256 JWT signing for access tokens
-//   - Refresh token rotation on each use
+//   - Argon2id password hashing with OWASP-recommended parameters and an
+//     optional versioned pepper, upgraded transparently at login
+//   - RS256, ES256 or EdDSA JWT signing for access tokens (picked from the
+//     key type), with an allow-list of algorithms enforced on validation
+//   - Signing keys held in a ring selected by kid, so keys can rotate
+//     without invalidating issued tokens
+//   - TOTP second factor (RFC 6238) with single-use recovery codes
+//   - Passkeys verified against the relying party ID and origin, with
+//     single-use challenges (5-minute expiry), user verification
+//     required to log in, and sign counter regressions rejected
+//   - Refresh token rotation on each use, with reuse detection that revokes
+//     the whole token family
 //   - Rate limiting on login attempts (5/min/IP)
+//   - Progressive lockout after failed logins, per tenant policy, counted
+//     separately for known devices and liftable by an emailed unlock link
 //   - All sessions invalidated on password change
+//   - New passwords checked against the tenants' policies, the user's
+//     password history (kept hashed) and a list of breached passwords
+//   - Password resets only sent to verified email addresses; email changes
+//     confirmed by the new address, undoable from the old one for 7 days,
+//     and invalidating all sessions
+//   - New staff are onboarded by single-use invitation links (stored
+//     hashed, 7-day expiry) and choose their own password; an existing
+//     account must confirm its password to join another tenant
+//   - Access tokens revocable before expiry: by jti on logout, and per user
+//     on logout-all, password change, deactivation, role change and role
+//     elevation, and for everyone holding a custom role when its
+//     permissions change
+//   - Staff PIN login only for cashier and below (managers and up may set
+//     a PIN for approvals only), PINs hashed like passwords, locked
+//     after 5 wrong attempts, and only accepted from registered terminals
+//     (20 attempts/min/terminal); PIN logins get a 15-minute access token
+//     and no refresh token
+//   - Impersonation only of lower-ranked users, short-lived, unable to
+//     change credentials, and attributed to the real actor in the audit
+//     trail; it ends when the actor's own tokens are revoked
+//   - Approvals given by someone other than the requester who holds the
+//     action's permission, with both recorded on the audit trail
+//   - SSO logins bound to single-use state (stored hashed, 10-minute
+//     expiry), PKCE and a nonce, linked only by verified email in the
+//     tenant's allowed domains, with the domain rule re-checked every login
+//   - SCIM tokens stored hashed, scoped to one tenant, unable to reach
+//     admins or owners, and attributed to the admin who created them
+//   - API keys stored hashed with a recognizable prefix, always expiring,
+//     limited to their account's scopes, and kept off the endpoints that
+//     manage people and credentials
+//   - OAuth client secrets, codes and refresh tokens stored hashed; codes
+//     single use and bound to a PKCE challenge; refresh tokens rotated on
+//     every use, with replay revoking the whole grant
 //   - Audit logging for all auth events
 package auth
 
@@ -78,6 +408,9 @@ import (
 // Role represents a user's role within a tenant.
 type Role = domain.Role
 
+// Permission names a single action a user may perform.
+type Permission = domain.Permission
+
 // Role constants
 const (
 	RoleOwner   = domain.RoleOwner
@@ -101,6 +434,36 @@ type TokenService = service.TokenService
 // PasswordService handles password hashing and verification.
 type PasswordService = service.PasswordService
 
+// MFAService handles TOTP multi-factor authentication.
+type MFAService = service.MFAService
+
+// RoleService handles tenant-defined custom roles.
+type RoleService = service.RoleService
+
+// ApprovalService handles step-up manager approvals.
+type ApprovalService = service.ApprovalService
+
+// PasskeyService handles WebAuthn passkeys.
+type PasskeyService = service.PasskeyService
+
+// SSOService handles per-tenant OpenID Connect single sign-on.
+type SSOService = service.SSOService
+
+// SCIMService handles SCIM 2.0 user provisioning.
+type SCIMService = service.SCIMService
+
+// ServiceAccountService handles tenant service accounts and their API keys.
+type ServiceAccountService = service.ServiceAccountService
+
+// OAuthService handles the OAuth 2.0 authorization server.
+type OAuthService = service.OAuthService
+
+// PasswordPolicyService handles tenant password policies.
+type PasswordPolicyService = service.PasswordPolicyService
+
+// LockoutService handles tenant account lockout policies and known devices.
+type LockoutService = service.LockoutService
+
 // Error types
 var (
 	ErrInvalidCredentials = domain.ErrInvalidCredentials
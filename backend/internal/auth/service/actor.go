@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+)
+
+type actorContextKey struct{}
+
+// WithActor returns a context for a request made by actorID while
+// impersonating another user. Auth events logged with it record actorID
+// next to the impersonated user.
+func WithActor(ctx context.Context, actorID uuid.UUID) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actorID)
+}
+
+// ActorFromContext returns the impersonating user set by WithActor.
+func ActorFromContext(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(actorContextKey{}).(uuid.UUID)
+	return id, ok
+}
+
+// newAuthEvent creates an auth event, recording the impersonating user if
+// the request is made under impersonation.
+func newAuthEvent(ctx context.Context, eventType domain.AuthEventType, userID, tenantID *uuid.UUID, ipAddress, userAgent string) *domain.AuthEvent {
+	event := domain.NewAuthEvent(eventType, userID, tenantID, ipAddress, userAgent)
+	if actorID, ok := ActorFromContext(ctx); ok {
+		event.ActorID = &actorID
+	}
+	return event
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+)
+
+// ApprovalService handles step-up approvals: a manager authorizing one
+// sensitive action (a refund, a void) on another user's device, and the
+// module performing the action consuming that authorization.
+type ApprovalService struct {
+	approvals   repository.ApprovalRepository
+	userRepo    repository.UserRepository
+	eventRepo   repository.AuthEventRepository
+	pinService  *PINService
+	passwordSvc *PasswordService
+	rateLimiter RateLimiter
+	lockout     *LockoutService
+	authService *AuthService
+	ttl         time.Duration
+}
+
+// ApprovalServiceConfig holds configuration for ApprovalService.
+type ApprovalServiceConfig struct {
+	Approvals repository.ApprovalRepository
+	UserRepo  repository.UserRepository
+	EventRepo repository.AuthEventRepository
+	// PINService authenticates the terminal and checks the approver's PIN
+	// for PIN approvals.
+	PINService *PINService
+	// RateLimiter limits approval attempts per requesting user. If nil, only
+	// the approver's password or PIN lockout applies.
+	RateLimiter RateLimiter
+	// TTL is how long an approval token stays usable. Defaults to domain.ApprovalTTL.
+	TTL time.Duration
+	// Passwords hashes and verifies passwords. Defaults to
+	// NewPasswordService(), without a pepper.
+	Passwords *PasswordService
+	// Lockout decides how wrong approver passwords lock the account, like
+	// failed logins. Defaults to domain.DefaultLockoutPolicy for every user.
+	Lockout *LockoutService
+	// AuthService applies the tenant's SSO policy to password approvals, as
+	// it does to password logins. If nil, password approvals aren't checked
+	// against it.
+	AuthService *AuthService
+}
+
+// NewApprovalService creates a new ApprovalService.
+func NewApprovalService(cfg ApprovalServiceConfig) *ApprovalService {
+	passwordSvc := cfg.Passwords
+	if passwordSvc == nil {
+		passwordSvc = NewPasswordService()
+	}
+	lockout := cfg.Lockout
+	if lockout == nil {
+		lockout = NewLockoutService(LockoutServiceConfig{EventRepo: cfg.EventRepo})
+	}
+	ttl := cfg.TTL
+	if ttl == 0 {
+		ttl = domain.ApprovalTTL
+	}
+	return &ApprovalService{
+		approvals:   cfg.Approvals,
+		userRepo:    cfg.UserRepo,
+		eventRepo:   cfg.EventRepo,
+		pinService:  cfg.PINService,
+		passwordSvc: passwordSvc,
+		rateLimiter: cfg.RateLimiter,
+		lockout:     lockout,
+		authService: cfg.AuthService,
+		ttl:         ttl,
+	}
+}
+
+// ApproveRequest contains the data needed to grant a step-up approval.
+type ApproveRequest struct {
+	TenantID    uuid.UUID
+	RequesterID uuid.UUID
+	Action      domain.Permission
+	ResourceID  string
+	// The approver is identified by ID (picked from a list on a terminal)
+	// or by email.
+	ApproverID    uuid.UUID
+	ApproverEmail string
+	// Exactly one of Password or PIN. A PIN is only accepted on a
+	// registered terminal of the tenant.
+	Password      string
+	PIN           string
+	TerminalToken string
+	IPAddress     string
+	UserAgent     string
+}
+
+// Approve checks that the approver holds the action's permission in the
+// tenant and, if their password or PIN is right, issues the requester a single-use
+// approval token for that action on that resource. The plain token is
+// returned once; only its hash is stored.
+func (s *ApprovalService) Approve(ctx context.Context, req ApproveRequest) (*domain.Approval, string, error) {
+	if !req.Action.IsRegistered() {
+		return nil, "", fmt.Errorf("%w: %s", domain.ErrUnknownPermission, req.Action)
+	}
+	method := domain.ApprovalMethodPassword
+	if req.PIN != "" {
+		method = domain.ApprovalMethodPIN
+	}
+	deny := func(approverID *uuid.UUID, reason string) {
+		s.logEvent(ctx, domain.EventApprovalDenied, approverID, &req.TenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+			"requested_by": req.RequesterID.String(),
+			"action":       req.Action.String(),
+			"resource_id":  req.ResourceID,
+			"method":       method,
+			"reason":       reason,
+		})
+	}
+
+	// Check rate limit (per requester, so switching approvers doesn't help)
+	if s.rateLimiter != nil {
+		allowed, err := s.rateLimiter.Allow(ctx, req.RequesterID.String())
+		if err != nil {
+			return nil, "", fmt.Errorf("approve: rate limit check: %w", err)
+		}
+		if !allowed {
+			deny(nil, "rate_limit_exceeded")
+			return nil, "", domain.ErrRateLimitExceeded
+		}
+	}
+
+	var terminal *domain.Terminal
+	if method == domain.ApprovalMethodPIN {
+		var err error
+		terminal, err = s.pinService.AuthenticateTerminal(ctx, req.TerminalToken)
+		if err != nil {
+			if errors.Is(err, domain.ErrTerminalInvalid) {
+				deny(nil, "terminal_invalid")
+				return nil, "", err
+			}
+			return nil, "", fmt.Errorf("approve: %w", err)
+		}
+		if terminal.TenantID != req.TenantID {
+			deny(nil, "terminal_invalid")
+			return nil, "", domain.ErrTerminalInvalid
+		}
+	}
+	credentialErr := domain.ErrInvalidCredentials
+	if method == domain.ApprovalMethodPIN {
+		credentialErr = domain.ErrPINInvalid
+	}
+
+	approver, err := s.findApprover(ctx, req)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			deny(nil, "approver_not_found")
+			return nil, "", credentialErr
+		}
+		return nil, "", fmt.Errorf("approve: approver lookup: %w", err)
+	}
+	if approver.ID == req.RequesterID {
+		deny(&approver.ID, "self_approval")
+		return nil, "", domain.ErrSelfApproval
+	}
+
+	// Only check the credential of someone who may approve this action here,
+	// so approvals can't be used to guess other users' passwords (and lock
+	// them out). Every refusal looks like a wrong credential.
+	switch {
+	case !approver.CanLogin():
+		deny(&approver.ID, "account_disabled")
+		return nil, "", credentialErr
+	case !approver.HasTenant(req.TenantID):
+		deny(&approver.ID, "not_in_tenant")
+		return nil, "", credentialErr
+	case !slices.Contains(approver.GetPermissionsForTenant(req.TenantID), req.Action):
+		deny(&approver.ID, "missing_permission")
+		return nil, "", credentialErr
+	}
+
+	if method == domain.ApprovalMethodPIN {
+		if _, err := s.pinService.checkPIN(ctx, approver.ID, req.TenantID, req.PIN, terminal.ID, req.IPAddress, req.UserAgent); err != nil {
+			switch {
+			case errors.Is(err, domain.ErrPINNotSet):
+				deny(&approver.ID, "pin_not_set")
+				return nil, "", domain.ErrPINInvalid
+			case errors.Is(err, domain.ErrPINLocked):
+				deny(&approver.ID, "pin_locked")
+				return nil, "", err
+			case errors.Is(err, domain.ErrPINInvalid):
+				deny(&approver.ID, "invalid_pin")
+				return nil, "", err
+			default:
+				return nil, "", fmt.Errorf("approve: %w", err)
+			}
+		}
+	} else if err := s.checkPassword(ctx, approver, req); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrPasswordLoginDisabled):
+			deny(&approver.ID, "password_login_disabled")
+			return nil, "", err
+		case errors.Is(err, domain.ErrAccountLocked):
+			deny(&approver.ID, "account_locked")
+			return nil, "", err
+		case errors.Is(err, domain.ErrInvalidCredentials):
+			deny(&approver.ID, "invalid_password")
+			return nil, "", err
+		default:
+			return nil, "", fmt.Errorf("approve: %w", err)
+		}
+	}
+
+	plainToken, tokenHash, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("approve: generate token: %w", err)
+	}
+	approval := &domain.Approval{
+		ID:          uuid.New(),
+		TenantID:    req.TenantID,
+		Action:      req.Action,
+		ResourceID:  req.ResourceID,
+		RequestedBy: req.RequesterID,
+		ApprovedBy:  approver.ID,
+		Method:      method,
+		TokenHash:   tokenHash,
+		ExpiresAt:   time.Now().Add(s.ttl),
+	}
+	if terminal != nil {
+		approval.TerminalID = &terminal.ID
+	}
+	if err := s.approvals.Create(ctx, approval); err != nil {
+		return nil, "", fmt.Errorf("approve: save: %w", err)
+	}
+
+	metadata := map[string]interface{}{
+		"approval_id":  approval.ID.String(),
+		"requested_by": req.RequesterID.String(),
+		"action":       req.Action.String(),
+		"resource_id":  req.ResourceID,
+		"method":       method,
+	}
+	if terminal != nil {
+		metadata["terminal_id"] = terminal.ID.String()
+	}
+	s.logEvent(ctx, domain.EventApprovalGranted, &approver.ID, &req.TenantID, req.IPAddress, req.UserAgent, metadata)
+
+	return approval, plainToken, nil
+}
+
+// ConsumeApprovalRequest contains the data needed to spend an approval.
+type ConsumeApprovalRequest struct {
+	Token       string
+	TenantID    uuid.UUID
+	RequesterID uuid.UUID
+	Action      domain.Permission
+	ResourceID  string
+	IPAddress   string
+	UserAgent   string
+}
+
+// Consume verifies that the token approves this requester performing the
+// action on the resource, and marks it used so it can't be spent twice.
+// Modules call it (or the RequireApproval middleware) right before
+// performing the approved action; the returned approval names the approver.
+func (s *ApprovalService) Consume(ctx context.Context, req ConsumeApprovalRequest) (*domain.Approval, error) {
+	if req.Token == "" {
+		return nil, domain.ErrApprovalRequired
+	}
+
+	approval, err := s.approvals.FindByToken(ctx, s.passwordSvc.HashResetToken(req.Token))
+	if err != nil {
+		if errors.Is(err, domain.ErrApprovalInvalid) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("consume approval: lookup: %w", err)
+	}
+	// A token for another action, resource or requester is as good as no
+	// token, and is left unspent for the request it was issued for.
+	if !approval.Covers(req.TenantID, req.RequesterID, req.Action, req.ResourceID) {
+		return nil, domain.ErrApprovalInvalid
+	}
+	if approval.IsUsed() {
+		return nil, domain.ErrApprovalUsed
+	}
+	if approval.IsExpired() {
+		return nil, domain.ErrApprovalExpired
+	}
+
+	if err := s.approvals.MarkUsed(ctx, approval.ID); err != nil {
+		if errors.Is(err, domain.ErrApprovalUsed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("consume approval: %w", err)
+	}
+	now := time.Now()
+	approval.UsedAt = &now
+
+	s.logEvent(ctx, domain.EventApprovalUsed, &req.RequesterID, &req.TenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+		"approval_id": approval.ID.String(),
+		"approved_by": approval.ApprovedBy.String(),
+		"action":      approval.Action.String(),
+		"resource_id": approval.ResourceID,
+	})
+
+	return approval, nil
+}
+
+// findApprover looks up the approver by ID, or by email if no ID was given.
+func (s *ApprovalService) findApprover(ctx context.Context, req ApproveRequest) (*domain.User, error) {
+	if req.ApproverID != uuid.Nil {
+		return s.userRepo.FindByIDWithTenants(ctx, req.ApproverID)
+	}
+	return s.userRepo.FindByEmailWithTenants(ctx, req.ApproverEmail)
+}
+
+// checkPassword verifies the approver's password. Wrong passwords count
+// towards the same account lockout as failed logins, so approvals can't be
+// used to guess a password around it.
+func (s *ApprovalService) checkPassword(ctx context.Context, approver *domain.User, req ApproveRequest) error {
+	// A tenant that requires SSO for the approver's role doesn't take their
+	// password here either; they can still approve with their PIN
+	if s.authService != nil {
+		allowed, err := s.authService.passwordLoginAllowed(ctx, req.TenantID, approver.GetRoleForTenant(req.TenantID))
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return domain.ErrPasswordLoginDisabled
+		}
+	}
+
+	if approver.IsLocked() {
+		return domain.ErrAccountLocked
+	}
+
+	match, err := s.passwordSvc.Verify(req.Password, approver.PasswordHash)
+	if err != nil {
+		return fmt.Errorf("password verify: %w", err)
+	}
+	if !match {
+		// Terminals aren't known devices, so this counts as an unknown one
+		lockedUntil, err := s.lockout.RecordFailure(ctx, approver, false)
+		if err != nil {
+			return err
+		}
+		if err := s.userRepo.Update(ctx, approver); err != nil {
+			return fmt.Errorf("update failed-login count: %w", err)
+		}
+		if lockedUntil != nil {
+			s.logEvent(ctx, domain.EventAccountLocked, &approver.ID, &req.TenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+				"failed_login_count": approver.FailedLoginCount,
+				"known_device":       false,
+				"locked_until":       lockedUntil,
+			})
+		}
+		return domain.ErrInvalidCredentials
+	}
+
+	// Successful credential check - reset lockout counters
+	if approver.ResetLoginFailures() {
+		if err := s.userRepo.Update(ctx, approver); err != nil {
+			return fmt.Errorf("reset failed-login count: %w", err)
+		}
+	}
+	return nil
+}
+
+// logEvent logs a step-up approval event.
+func (s *ApprovalService) logEvent(ctx context.Context, eventType domain.AuthEventType, userID, tenantID *uuid.UUID, ipAddress, userAgent string, metadata map[string]interface{}) {
+	event := newAuthEvent(ctx, eventType, userID, tenantID, ipAddress, userAgent)
+	if metadata != nil {
+		event.Metadata = metadata
+	}
+	// Fire and forget - don't fail the request if logging fails
+	_ = s.eventRepo.Create(ctx, event)
+}
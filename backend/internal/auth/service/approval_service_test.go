@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+)
+
+// testRefund stands in for another module's sensitive action, such as
+// payments.refund.
+const testRefund domain.Permission = "approvals_test.refund"
+
+func init() {
+	domain.RegisterPermissions(domain.PermissionDefinition{
+		Permission:  testRefund,
+		Description: "Refund a payment (approval tests)",
+		Roles:       domain.RolesAtLeast(domain.RoleManager),
+	})
+}
+
+// approvalTestEnv bundles an ApprovalService with the PIN test env it
+// builds on, a cashier asking for approval and a manager who can give it.
+type approvalTestEnv struct {
+	*pinTestEnv
+	approvals    *ApprovalService
+	approvalRepo *mock.MockApprovalRepository
+	cashier      *domain.User
+	manager      *domain.User
+}
+
+func setupApprovalService(t *testing.T, rateLimiter RateLimiter) *approvalTestEnv {
+	t.Helper()
+
+	env := &approvalTestEnv{
+		pinTestEnv:   setupPINService(t, nil),
+		approvalRepo: mock.NewMockApprovalRepository(),
+	}
+	env.approvals = NewApprovalService(ApprovalServiceConfig{
+		Approvals:   env.approvalRepo,
+		UserRepo:    env.userRepo,
+		EventRepo:   env.eventRepo,
+		PINService:  env.svc,
+		RateLimiter: rateLimiter,
+	})
+	env.cashier = env.addStaff(t, domain.RoleCashier, "")
+	env.manager = env.addStaff(t, domain.RoleManager, "2580")
+	return env
+}
+
+// passwordApproval returns a request for the env's manager to approve a
+// refund of payment-1 for the env's cashier with their password.
+func (e *approvalTestEnv) passwordApproval() ApproveRequest {
+	return ApproveRequest{
+		TenantID:      e.tenantID,
+		RequesterID:   e.cashier.ID,
+		Action:        testRefund,
+		ResourceID:    "payment-1",
+		ApproverEmail: e.manager.Email,
+		Password:      "Password123!",
+	}
+}
+
+// consumeRequest returns a request for the env's cashier to spend token on
+// a refund of resourceID.
+func (e *approvalTestEnv) consumeRequest(token, resourceID string) ConsumeApprovalRequest {
+	return ConsumeApprovalRequest{
+		Token:       token,
+		TenantID:    e.tenantID,
+		RequesterID: e.cashier.ID,
+		Action:      testRefund,
+		ResourceID:  resourceID,
+	}
+}
+
+func TestApprovalService_Password(t *testing.T) {
+	env := setupApprovalService(t, nil)
+	ctx := context.Background()
+
+	approval, token, err := env.approvals.Approve(ctx, env.passwordApproval())
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if token == "" || approval.TokenHash == token {
+		t.Error("Approve should return a plain token and store only its hash")
+	}
+	if approval.ApprovedBy != env.manager.ID || approval.RequestedBy != env.cashier.ID || approval.Method != domain.ApprovalMethodPassword {
+		t.Errorf("approval = %+v, want the manager approving the cashier by password", approval)
+	}
+	if d := time.Until(approval.ExpiresAt); d <= 0 || d > domain.ApprovalTTL {
+		t.Errorf("approval expires in %v, want within %v", d, domain.ApprovalTTL)
+	}
+
+	used, err := env.approvals.Consume(ctx, env.consumeRequest(token, "payment-1"))
+	if err != nil {
+		t.Fatalf("Consume failed: %v", err)
+	}
+	if used.ApprovedBy != env.manager.ID || !used.IsUsed() {
+		t.Errorf("consumed approval = %+v, want a used approval by the manager", used)
+	}
+	if _, err := env.approvals.Consume(ctx, env.consumeRequest(token, "payment-1")); !errors.Is(err, domain.ErrApprovalUsed) {
+		t.Errorf("second Consume = %v, want ErrApprovalUsed", err)
+	}
+
+	// The approver and the requester are both on the audit trail
+	var granted, usedEvent *domain.AuthEvent
+	for _, e := range env.eventRepo.GetEvents() {
+		switch e.EventType {
+		case domain.EventApprovalGranted:
+			granted = e
+		case domain.EventApprovalUsed:
+			usedEvent = e
+		}
+	}
+	if granted == nil || *granted.UserID != env.manager.ID || granted.Metadata["requested_by"] != env.cashier.ID.String() {
+		t.Errorf("approval_granted event = %+v, want the manager approving for the cashier", granted)
+	}
+	if usedEvent == nil || *usedEvent.UserID != env.cashier.ID || usedEvent.Metadata["approved_by"] != env.manager.ID.String() {
+		t.Errorf("approval_used event = %+v, want the cashier using the manager's approval", usedEvent)
+	}
+}
+
+func TestApprovalService_PIN(t *testing.T) {
+	env := setupApprovalService(t, nil)
+	ctx := context.Background()
+
+	req := ApproveRequest{
+		TenantID:      env.tenantID,
+		RequesterID:   env.cashier.ID,
+		Action:        testRefund,
+		ResourceID:    "payment-1",
+		ApproverID:    env.manager.ID,
+		PIN:           "2580",
+		TerminalToken: env.terminalToken,
+	}
+	approval, token, err := env.approvals.Approve(ctx, req)
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if approval.Method != domain.ApprovalMethodPIN || approval.TerminalID == nil || *approval.TerminalID != env.terminal.ID {
+		t.Errorf("approval = %+v, want a PIN approval on the env's terminal", approval)
+	}
+	if _, err := env.approvals.Consume(ctx, env.consumeRequest(token, "payment-1")); err != nil {
+		t.Errorf("Consume failed: %v", err)
+	}
+
+	// A PIN is only accepted on a registered terminal
+	req.TerminalToken = ""
+	if _, _, err := env.approvals.Approve(ctx, req); !errors.Is(err, domain.ErrTerminalInvalid) {
+		t.Errorf("Approve without a terminal = %v, want ErrTerminalInvalid", err)
+	}
+
+	// A manager's PIN still doesn't log them in
+	if _, err := env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: env.manager.ID, PIN: "2580"}); !errors.Is(err, domain.ErrPINNotAllowed) {
+		t.Errorf("manager PIN login = %v, want ErrPINNotAllowed", err)
+	}
+}
+
+func TestApprovalService_Approve_PasswordLoginDisabled(t *testing.T) {
+	env := setupApprovalService(t, nil)
+	authSvc, _, _, _, _ := setupAuthService(t)
+	authSvc.userRepo = env.userRepo
+	configs := mock.NewMockSSOConfigRepository()
+	authSvc.ssoConfigs = configs
+	env.approvals.authService = authSvc
+	owner := env.addStaff(t, domain.RoleOwner, "")
+	configs.AddConfig(&domain.TenantSSOConfig{TenantID: env.tenantID, PasswordLoginDisabled: true, UpdatedBy: &owner.ID})
+	ctx := context.Background()
+
+	if _, _, err := env.approvals.Approve(ctx, env.passwordApproval()); err != domain.ErrPasswordLoginDisabled {
+		t.Fatalf("password Approve under SSO = %v, want ErrPasswordLoginDisabled", err)
+	}
+	if !hasEventType(env.eventRepo, domain.EventApprovalDenied) {
+		t.Error("expected an approval_denied event")
+	}
+
+	// The manager can still approve with their PIN
+	_, _, err := env.approvals.Approve(ctx, ApproveRequest{
+		TenantID:      env.tenantID,
+		RequesterID:   env.cashier.ID,
+		Action:        testRefund,
+		ResourceID:    "payment-1",
+		ApproverID:    env.manager.ID,
+		PIN:           "2580",
+		TerminalToken: env.terminalToken,
+	})
+	if err != nil {
+		t.Errorf("PIN Approve under SSO failed: %v", err)
+	}
+}
+
+func TestApprovalService_Approve_Errors(t *testing.T) {
+	env := setupApprovalService(t, nil)
+	ctx := context.Background()
+	otherManager := env.addStaff(t, domain.RoleManager, "")
+	otherManager.TenantRoles[0].TenantID = uuid.New()
+	noPIN := env.addStaff(t, domain.RoleAdmin, "")
+
+	tests := []struct {
+		name    string
+		modify  func(*ApproveRequest)
+		wantErr error
+	}{
+		{"unregistered action", func(r *ApproveRequest) { r.Action = "payments.teleport" }, domain.ErrUnknownPermission},
+		{"wrong password", func(r *ApproveRequest) { r.Password = "WrongPassword1!" }, domain.ErrInvalidCredentials},
+		{"unknown approver", func(r *ApproveRequest) { r.ApproverEmail = "nobody@example.com" }, domain.ErrInvalidCredentials},
+		{"self approval", func(r *ApproveRequest) { r.ApproverEmail = env.cashier.Email }, domain.ErrSelfApproval},
+		{"approver lacks permission", func(r *ApproveRequest) {
+			r.RequesterID = env.manager.ID
+			r.ApproverEmail = env.cashier.Email
+		}, domain.ErrInvalidCredentials},
+		{"approver in another tenant", func(r *ApproveRequest) { r.ApproverEmail = otherManager.Email }, domain.ErrInvalidCredentials},
+		{"wrong PIN", func(r *ApproveRequest) {
+			r.ApproverID, r.PIN, r.Password, r.TerminalToken = env.manager.ID, "9731", "", env.terminalToken
+		}, domain.ErrPINInvalid},
+		{"PIN not set", func(r *ApproveRequest) {
+			r.ApproverID, r.PIN, r.Password, r.TerminalToken = noPIN.ID, "2580", "", env.terminalToken
+		}, domain.ErrPINInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := env.passwordApproval()
+			tt.modify(&req)
+			if _, _, err := env.approvals.Approve(ctx, req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Approve() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if len(env.approvalRepo.Approvals()) != 0 {
+		t.Errorf("%d approvals were issued, want none", len(env.approvalRepo.Approvals()))
+	}
+	if !hasEventType(env.eventRepo, domain.EventApprovalDenied) {
+		t.Error("expected approval_denied events")
+	}
+}
+
+func TestApprovalService_Approve_PasswordLockout(t *testing.T) {
+	env := setupApprovalService(t, nil)
+	ctx := context.Background()
+
+	req := env.passwordApproval()
+	req.Password = "WrongPassword1!"
+	threshold := domain.DefaultLockoutPolicy().UnknownDeviceThreshold
+	for range threshold {
+		env.approvals.Approve(ctx, req)
+	}
+
+	// Guessing through approvals locks the account just like failed logins
+	if _, _, err := env.approvals.Approve(ctx, env.passwordApproval()); !errors.Is(err, domain.ErrAccountLocked) {
+		t.Errorf("Approve after %d wrong passwords = %v, want ErrAccountLocked", threshold, err)
+	}
+	if !hasEventType(env.eventRepo, domain.EventAccountLocked) {
+		t.Error("expected an account_locked event")
+	}
+}
+
+func TestApprovalService_Approve_IneligibleApproverPasswordNotChecked(t *testing.T) {
+	env := setupApprovalService(t, nil)
+	ctx := context.Background()
+	outsider := env.addStaff(t, domain.RoleManager, "")
+	outsider.TenantRoles[0].TenantID = uuid.New()
+
+	// Guessing the password of someone who can't approve here gives nothing
+	// away and doesn't count towards their lockout
+	req := env.passwordApproval()
+	req.ApproverEmail = outsider.Email
+	req.Password = "WrongPassword1!"
+	threshold := domain.DefaultLockoutPolicy().UnknownDeviceThreshold
+	for i := 0; i < threshold+1; i++ {
+		if _, _, err := env.approvals.Approve(ctx, req); err != domain.ErrInvalidCredentials {
+			t.Fatalf("Approve by an outsider = %v, want ErrInvalidCredentials", err)
+		}
+	}
+	req.Password = "Password123!"
+	if _, _, err := env.approvals.Approve(ctx, req); err != domain.ErrInvalidCredentials {
+		t.Errorf("Approve by an outsider with the right password = %v, want ErrInvalidCredentials", err)
+	}
+
+	if outsider.FailedLoginCount != 0 || outsider.IsLocked() {
+		t.Errorf("outsider failed logins = %d, locked = %v; want 0, false", outsider.FailedLoginCount, outsider.IsLocked())
+	}
+	if hasEventType(env.eventRepo, domain.EventAccountLocked) {
+		t.Error("expected no account_locked event")
+	}
+}
+
+func TestApprovalService_Approve_RateLimit(t *testing.T) {
+	limiter := NewMemoryRateLimiter(RateLimiterConfig{MaxRequests: 2, Window: time.Minute, KeyPrefix: "approval:"})
+	env := setupApprovalService(t, limiter)
+	ctx := context.Background()
+
+	req := env.passwordApproval()
+	req.Password = "WrongPassword1!"
+	env.approvals.Approve(ctx, req)
+	env.approvals.Approve(ctx, req)
+
+	if _, _, err := env.approvals.Approve(ctx, env.passwordApproval()); !errors.Is(err, domain.ErrRateLimitExceeded) {
+		t.Errorf("Approve over the limit = %v, want ErrRateLimitExceeded", err)
+	}
+}
+
+func TestApprovalService_Consume_Errors(t *testing.T) {
+	env := setupApprovalService(t, nil)
+	ctx := context.Background()
+
+	approval, token, err := env.approvals.Approve(ctx, env.passwordApproval())
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		req     ConsumeApprovalRequest
+		wantErr error
+	}{
+		{"no token", env.consumeRequest("", "payment-1"), domain.ErrApprovalRequired},
+		{"unknown token", env.consumeRequest("not-a-token", "payment-1"), domain.ErrApprovalInvalid},
+		{"other resource", env.consumeRequest(token, "payment-2"), domain.ErrApprovalInvalid},
+		{"other requester", func() ConsumeApprovalRequest {
+			r := env.consumeRequest(token, "payment-1")
+			r.RequesterID = uuid.New()
+			return r
+		}(), domain.ErrApprovalInvalid},
+		{"other action", func() ConsumeApprovalRequest {
+			r := env.consumeRequest(token, "payment-1")
+			r.Action = domain.PermUsersManage
+			return r
+		}(), domain.ErrApprovalInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := env.approvals.Consume(ctx, tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Consume() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Misdirected attempts don't spend the approval, but expiry does end it
+	approval.ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := env.approvals.Consume(ctx, env.consumeRequest(token, "payment-1")); !errors.Is(err, domain.ErrApprovalExpired) {
+		t.Errorf("Consume after expiry = %v, want ErrApprovalExpired", err)
+	}
+	if approval.IsUsed() {
+		t.Error("Failed Consume calls should not mark the approval used")
+	}
+}
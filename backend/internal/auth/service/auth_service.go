@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,12 +13,6 @@ import (
 	"github.com/solobueno/erp/internal/auth/repository"
 )
 
-// maxFailedLoginAttempts is the number of consecutive failed logins that locks an account.
-const maxFailedLoginAttempts = 5
-
-// accountLockDuration is how long an account stays locked after hitting maxFailedLoginAttempts.
-const accountLockDuration = 15 * time.Minute
-
 // AuthService handles authentication operations.
 type AuthService struct {
 	userRepo     repository.UserRepository
@@ -27,6 +23,14 @@ type AuthService struct {
 	tokenService *TokenService
 	passwordSvc  *PasswordService
 	rateLimiter  RateLimiter
+	mfaService   *MFAService
+	emailer      Emailer
+	revocations  repository.TokenRevocationStore
+	ssoConfigs   repository.SSOConfigRepository
+	serviceAccts *ServiceAccountService
+	oauth        *OAuthService
+	pwdPolicies  *PasswordPolicyService
+	lockout      *LockoutService
 }
 
 // AuthServiceConfig holds configuration for AuthService.
@@ -38,10 +42,47 @@ type AuthServiceConfig struct {
 	RoleRepo     repository.UserTenantRoleRepository
 	TokenService *TokenService
 	RateLimiter  RateLimiter
+	// MFAService enables the TOTP second step at login. If nil, logins
+	// complete after the password check alone.
+	MFAService *MFAService
+	// Emailer notifies users when refresh token reuse is detected. If nil,
+	// reuse is still handled and audited but no email is sent.
+	Emailer Emailer
+	// RevocationStore lets access tokens be revoked before they expire. If
+	// nil, access tokens stay valid until they expire.
+	RevocationStore repository.TokenRevocationStore
+	// SSOConfigs enforces tenants' policy on password login. If nil,
+	// password login is always allowed.
+	SSOConfigs repository.SSOConfigRepository
+	// ServiceAccounts accepts service account API keys in place of access
+	// tokens. If nil, API keys are rejected.
+	ServiceAccounts *ServiceAccountService
+	// OAuth checks that access tokens issued to OAuth clients are still
+	// backed by a grant. If nil, such tokens are rejected.
+	OAuth *OAuthService
+	// PasswordPolicies expires passwords older than the maximum age of the
+	// user's tenants' policies, forcing a reset at login. If nil, passwords
+	// never expire.
+	PasswordPolicies *PasswordPolicyService
+	// Lockout decides how failed logins delay further ones, from known and
+	// unknown devices. Defaults to domain.DefaultLockoutPolicy for every
+	// user, with every device treated as unknown.
+	Lockout *LockoutService
+	// Passwords hashes and verifies passwords. Defaults to
+	// NewPasswordService(), without a pepper.
+	Passwords *PasswordService
 }
 
 // NewAuthService creates a new AuthService.
 func NewAuthService(cfg AuthServiceConfig) *AuthService {
+	passwordSvc := cfg.Passwords
+	if passwordSvc == nil {
+		passwordSvc = NewPasswordService()
+	}
+	lockout := cfg.Lockout
+	if lockout == nil {
+		lockout = NewLockoutService(LockoutServiceConfig{EventRepo: cfg.EventRepo})
+	}
 	return &AuthService{
 		userRepo:     cfg.UserRepo,
 		sessionRepo:  cfg.SessionRepo,
@@ -49,8 +90,16 @@ func NewAuthService(cfg AuthServiceConfig) *AuthService {
 		tenantRepo:   cfg.TenantRepo,
 		roleRepo:     cfg.RoleRepo,
 		tokenService: cfg.TokenService,
-		passwordSvc:  NewPasswordService(),
+		passwordSvc:  passwordSvc,
 		rateLimiter:  cfg.RateLimiter,
+		mfaService:   cfg.MFAService,
+		emailer:      cfg.Emailer,
+		revocations:  cfg.RevocationStore,
+		ssoConfigs:   cfg.SSOConfigs,
+		serviceAccts: cfg.ServiceAccounts,
+		oauth:        cfg.OAuth,
+		pwdPolicies:  cfg.PasswordPolicies,
+		lockout:      lockout,
 	}
 }
 
@@ -61,6 +110,10 @@ type LoginRequest struct {
 	TenantID  *uuid.UUID // Optional, required if user belongs to multiple tenants
 	IPAddress string
 	UserAgent string
+	// DeviceToken is the token an earlier login returned to this device,
+	// if any. Failed logins from a known device count towards its own,
+	// usually higher, lockout threshold.
+	DeviceToken string
 }
 
 // LoginResponse contains the login result.
@@ -73,6 +126,18 @@ type LoginResponse struct {
 	Tenants []TenantInfo
 	// LockedUntil is set when the account is currently locked out
 	LockedUntil *time.Time
+	// MFAToken is set when the user must complete a second factor via
+	// VerifyMFA before tokens are issued
+	MFAToken string
+	// MFAMethods lists the second factors that can answer MFAToken; empty
+	// when the user must enroll
+	MFAMethods []string
+	// RecoveryCodes is set when VerifyMFA completed a first-time enrollment;
+	// they are shown to the user once and never retrievable again
+	RecoveryCodes []string
+	// DeviceToken is set once the password is accepted, for the client to
+	// present as LoginRequest.DeviceToken on later logins
+	DeviceToken string
 }
 
 // TenantInfo contains basic tenant information.
@@ -113,12 +178,20 @@ func (s *AuthService) Login(ctx context.Context, req LoginRequest) (*LoginRespon
 		return nil, fmt.Errorf("login: user lookup: %w", err)
 	}
 
+	// Failures from a device the user has logged in from before are counted
+	// apart from others, so guessing elsewhere can't lock them out of it
+	knownDevice, err := s.lockout.IsKnownDevice(ctx, user.ID, req.DeviceToken)
+	if err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+
 	// Check account lockout (independent of the IP rate limit above)
-	if user.IsLocked() {
+	if locked, lockedUntil := user.IsLockedOn(knownDevice); locked {
 		s.logEvent(ctx, domain.EventLoginFailed, &user.ID, nil, req.IPAddress, req.UserAgent, map[string]interface{}{
-			"reason": "account_locked",
+			"reason":       "account_locked",
+			"known_device": knownDevice,
 		})
-		return &LoginResponse{LockedUntil: user.LockedUntil}, domain.ErrAccountLocked
+		return &LoginResponse{LockedUntil: lockedUntil}, domain.ErrAccountLocked
 	}
 
 	// Verify password
@@ -127,19 +200,22 @@ func (s *AuthService) Login(ctx context.Context, req LoginRequest) (*LoginRespon
 		return nil, fmt.Errorf("login: password verify: %w", err)
 	}
 	if !match {
-		user.FailedLoginCount++
-		var lockedUntil *time.Time
-		if user.FailedLoginCount >= maxFailedLoginAttempts {
-			until := time.Now().Add(accountLockDuration)
-			user.LockedUntil = &until
-			lockedUntil = &until
+		lockedUntil, err := s.lockout.RecordFailure(ctx, user, knownDevice)
+		if err != nil {
+			return nil, fmt.Errorf("login: %w", err)
 		}
 		if err := s.userRepo.Update(ctx, user); err != nil {
 			return nil, fmt.Errorf("login: update failed-login count: %w", err)
 		}
 		if lockedUntil != nil {
+			failedCount := user.FailedLoginCount
+			if knownDevice {
+				failedCount = user.KnownDeviceFailedCount
+			}
 			s.logEvent(ctx, domain.EventAccountLocked, &user.ID, nil, req.IPAddress, req.UserAgent, map[string]interface{}{
-				"failed_login_count": user.FailedLoginCount,
+				"failed_login_count": failedCount,
+				"known_device":       knownDevice,
+				"locked_until":       lockedUntil,
 			})
 		}
 		s.logEvent(ctx, domain.EventLoginFailed, &user.ID, nil, req.IPAddress, req.UserAgent, map[string]interface{}{
@@ -156,96 +232,624 @@ func (s *AuthService) Login(ctx context.Context, req LoginRequest) (*LoginRespon
 		return nil, domain.ErrAccountDisabled
 	}
 
-	// Successful credential check - reset lockout counter
-	if user.FailedLoginCount != 0 || user.LockedUntil != nil {
-		user.FailedLoginCount = 0
-		user.LockedUntil = nil
+	// Successful credential check - reset lockout counters
+	if user.ResetLoginFailures() {
 		if err := s.userRepo.Update(ctx, user); err != nil {
 			return nil, fmt.Errorf("login: reset failed-login count: %w", err)
 		}
 	}
 
+	// The device is known from now on
+	deviceToken, err := s.lockout.RememberDevice(ctx, user.ID, req.DeviceToken, req.UserAgent)
+	if err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+
+	// Upgrade a hash made with outdated parameters or pepper while the
+	// plain password is at hand
+	if s.passwordSvc.NeedsRehash(user.PasswordHash) {
+		hash, err := s.passwordSvc.Hash(req.Password)
+		if err != nil {
+			return nil, fmt.Errorf("login: rehash password: %w", err)
+		}
+		user.PasswordHash = hash
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, fmt.Errorf("login: rehash password: %w", err)
+		}
+	}
+
+	// Force a reset of a password older than its policy allows
+	if s.pwdPolicies != nil && !user.MustResetPwd {
+		expired, maxAgeDays, err := s.pwdPolicies.IsExpired(ctx, user)
+		if err != nil {
+			return nil, fmt.Errorf("login: %w", err)
+		}
+		if expired {
+			user.MustResetPwd = true
+			if err := s.userRepo.Update(ctx, user); err != nil {
+				return nil, fmt.Errorf("login: expire password: %w", err)
+			}
+			s.logEvent(ctx, domain.EventPasswordExpired, &user.ID, nil, req.IPAddress, req.UserAgent, map[string]interface{}{
+				"max_age_days": maxAgeDays,
+			})
+		}
+	}
+
 	// Handle tenant selection
-	var selectedTenantID uuid.UUID
-	var selectedRole domain.Role
+	selectedTenantID, selectedRole, tenants, err := selectTenant(user, req.TenantID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTenantRequired) {
+			return &LoginResponse{User: user, Tenants: tenants, DeviceToken: deviceToken}, err
+		}
+		return nil, err
+	}
+
+	// Verify tenant is active
+	tenant, err := s.tenantRepo.FindByID(ctx, selectedTenantID)
+	if err != nil {
+		return nil, fmt.Errorf("login: tenant lookup: %w", err)
+	}
+	if !tenant.IsOperational() {
+		return nil, domain.ErrTenantInactive
+	}
 
+	// Tenants that sign in through their identity provider can turn
+	// password login off
+	allowed, err := s.passwordLoginAllowed(ctx, selectedTenantID, selectedRole)
+	if err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	if !allowed {
+		s.logEvent(ctx, domain.EventLoginFailed, &user.ID, &selectedTenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+			"reason": "password_login_disabled",
+		})
+		return nil, domain.ErrPasswordLoginDisabled
+	}
+
+	// Second factor: anyone who has enrolled is challenged, and roles that
+	// require MFA are challenged to enroll before any token is issued. The
+	// challenge is bound to the tenant chosen above, so multi-tenant users
+	// resolve ErrTenantRequired first and then complete MFA once.
+	if s.mfaService != nil {
+		methods, err := s.mfaService.Methods(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("login: %w", err)
+		}
+		if len(methods) > 0 || selectedRole.RequiresMFA() {
+			mfaToken, err := s.mfaService.CreateChallenge(ctx, user.ID, selectedTenantID)
+			if err != nil {
+				return nil, fmt.Errorf("login: %w", err)
+			}
+			resp := &LoginResponse{User: user, TenantID: selectedTenantID, Role: selectedRole, MFAToken: mfaToken, MFAMethods: methods, DeviceToken: deviceToken}
+			if len(methods) == 0 {
+				return resp, domain.ErrMFAEnrollmentRequired
+			}
+			return resp, domain.ErrMFARequired
+		}
+	}
+
+	resp, err := s.issueSession(ctx, user, selectedTenantID, selectedRole, domain.SignInPassword, req.IPAddress, req.UserAgent, nil)
+	if err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	resp.DeviceToken = deviceToken
+	return resp, nil
+}
+
+// selectTenant picks the tenant a login is for: the user's only tenant, or
+// the requested one. A member of several tenants who requested none gets
+// ErrTenantRequired along with the tenants to choose from.
+func selectTenant(user *domain.User, requested *uuid.UUID) (uuid.UUID, domain.Role, []TenantInfo, error) {
 	if len(user.TenantRoles) == 0 {
-		return nil, domain.ErrUserNotInTenant
+		return uuid.Nil, "", nil, domain.ErrUserNotInTenant
 	}
 
+	// Single tenant - auto-select
 	if len(user.TenantRoles) == 1 {
-		// Single tenant - auto-select
-		selectedTenantID = user.TenantRoles[0].TenantID
-		selectedRole = user.TenantRoles[0].Role
-	} else if req.TenantID != nil {
-		// Multiple tenants - use provided tenant ID
-		found := false
+		return user.TenantRoles[0].TenantID, user.TenantRoles[0].EffectiveRole(), nil, nil
+	}
+
+	// Multiple tenants - use provided tenant ID
+	if requested != nil {
 		for _, tr := range user.TenantRoles {
-			if tr.TenantID == *req.TenantID {
-				selectedTenantID = tr.TenantID
-				selectedRole = tr.Role
-				found = true
-				break
+			if tr.TenantID == *requested {
+				return tr.TenantID, tr.EffectiveRole(), nil, nil
 			}
 		}
-		if !found {
-			return nil, domain.ErrUserNotInTenant
-		}
-	} else {
-		// Multiple tenants - require selection
-		tenants := make([]TenantInfo, 0, len(user.TenantRoles))
-		for _, tr := range user.TenantRoles {
-			tenants = append(tenants, TenantInfo{
-				ID:   tr.TenantID,
-				Name: tr.Tenant.Name,
-				Slug: tr.Tenant.Slug,
-				Role: tr.Role,
+		return uuid.Nil, "", nil, domain.ErrUserNotInTenant
+	}
+
+	// Multiple tenants - require selection
+	tenants := make([]TenantInfo, 0, len(user.TenantRoles))
+	for _, tr := range user.TenantRoles {
+		tenants = append(tenants, TenantInfo{
+			ID:   tr.TenantID,
+			Name: tr.Tenant.Name,
+			Slug: tr.Tenant.Slug,
+			Role: tr.EffectiveRole(),
+		})
+	}
+	return uuid.Nil, "", tenants, domain.ErrTenantRequired
+}
+
+// MFAVerifyRequest contains the data needed to complete an MFA login challenge.
+type MFAVerifyRequest struct {
+	MFAToken  string
+	Code      string // TOTP code, or a recovery code
+	IPAddress string
+	UserAgent string
+}
+
+// VerifyMFA completes a login that was paused by ErrMFARequired or
+// ErrMFAEnrollmentRequired. For a pending enrollment, the code confirms the
+// new factor and the response carries the user's recovery codes.
+func (s *AuthService) VerifyMFA(ctx context.Context, req MFAVerifyRequest) (*LoginResponse, error) {
+	challenge, user, role, err := s.loadMFAChallenge(ctx, req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	methods, err := s.mfaService.Methods(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("mfa verify: %w", err)
+	}
+
+	var method string
+	var recoveryCodes []string
+	switch {
+	case slices.Contains(methods, MFAMethodTOTP):
+		method, err = s.mfaService.Verify(ctx, user.ID, req.Code)
+	case len(methods) > 0:
+		// Enrolled with passkeys only: a code can't stand in for them
+		err = domain.ErrMFACodeInvalid
+	default:
+		method = MFAMethodTOTP
+		recoveryCodes, err = s.mfaService.ConfirmEnrollment(ctx, user.ID, req.Code, req.IPAddress)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMFACodeInvalid):
+			if err := s.mfaService.RecordFailedAttempt(ctx, challenge.ID); err != nil {
+				return nil, fmt.Errorf("mfa verify: %w", err)
+			}
+			s.logEvent(ctx, domain.EventMFAFailed, &user.ID, &challenge.TenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+				"reason": "invalid_code",
 			})
+			return nil, domain.ErrMFACodeInvalid
+		case errors.Is(err, domain.ErrMFANotEnrolled):
+			return nil, err
+		default:
+			return nil, fmt.Errorf("mfa verify: %w", err)
 		}
-		return &LoginResponse{
-			User:    user,
-			Tenants: tenants,
-		}, domain.ErrTenantRequired
 	}
 
-	// Verify tenant is active
-	tenant, err := s.tenantRepo.FindByID(ctx, selectedTenantID)
+	resp, err := s.completeMFA(ctx, challenge, user, role, method, req.IPAddress, req.UserAgent)
 	if err != nil {
-		return nil, fmt.Errorf("login: tenant lookup: %w", err)
+		return nil, err
+	}
+	resp.RecoveryCodes = recoveryCodes
+	return resp, nil
+}
+
+// loadMFAChallenge finds a pending login challenge and re-checks everything
+// the password step checked: the account or tenant may have changed in the
+// minutes since the challenge was issued. It returns the challenge with the
+// user and their role in the challenge's tenant.
+func (s *AuthService) loadMFAChallenge(ctx context.Context, mfaToken string) (*domain.MFAChallenge, *domain.User, domain.Role, error) {
+	if s.mfaService == nil {
+		return nil, nil, "", domain.ErrMFAChallengeInvalid
+	}
+
+	challenge, err := s.mfaService.FindChallenge(ctx, mfaToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFAChallengeInvalid) {
+			return nil, nil, "", err
+		}
+		return nil, nil, "", fmt.Errorf("mfa verify: %w", err)
+	}
+
+	user, err := s.userRepo.FindByIDWithTenants(ctx, challenge.UserID)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("mfa verify: user lookup: %w", err)
+	}
+	if !user.CanLogin() {
+		return nil, nil, "", domain.ErrAccountDisabled
+	}
+	role := user.GetRoleForTenant(challenge.TenantID)
+	if role == "" {
+		return nil, nil, "", domain.ErrUserNotInTenant
+	}
+	tenant, err := s.tenantRepo.FindByID(ctx, challenge.TenantID)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("mfa verify: tenant lookup: %w", err)
 	}
 	if !tenant.IsOperational() {
-		return nil, domain.ErrTenantInactive
+		return nil, nil, "", domain.ErrTenantInactive
 	}
+	return challenge, user, role, nil
+}
 
-	// Generate tokens
-	tokenPair, refreshTokenHash, err := s.tokenService.GenerateTokenPair(user, selectedTenantID, selectedRole)
+// completeMFA spends a login challenge answered with method, audits the
+// second factor and issues the session.
+func (s *AuthService) completeMFA(ctx context.Context, challenge *domain.MFAChallenge, user *domain.User, role domain.Role, method, ipAddress, userAgent string) (*LoginResponse, error) {
+	if err := s.mfaService.CompleteChallenge(ctx, challenge.ID); err != nil {
+		if errors.Is(err, domain.ErrMFAChallengeInvalid) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("mfa verify: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventMFASuccess, &user.ID, &challenge.TenantID, ipAddress, userAgent, map[string]interface{}{
+		"method": method,
+	})
+
+	resp, err := s.issueSession(ctx, user, challenge.TenantID, role, domain.SignInMFA, ipAddress, userAgent, map[string]interface{}{
+		"mfa_method": method,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mfa verify: %w", err)
+	}
+	return resp, nil
+}
+
+// BeginMFAEnrollment starts TOTP enrollment for a user whose role requires
+// MFA but who has not enrolled yet. It is authenticated by the login
+// challenge rather than an access token, since they can't get one until
+// MFA is set up. Confirmed factors can't be replaced this way.
+func (s *AuthService) BeginMFAEnrollment(ctx context.Context, mfaToken string) (*MFAEnrollment, error) {
+	if s.mfaService == nil {
+		return nil, domain.ErrMFAChallengeInvalid
+	}
+
+	challenge, err := s.mfaService.FindChallenge(ctx, mfaToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFAChallengeInvalid) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("mfa setup: %w", err)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("mfa setup: user lookup: %w", err)
+	}
+
+	// A user with passkeys is already enrolled: letting them add TOTP here,
+	// on the strength of the password alone, would bypass their passkeys
+	methods, err := s.mfaService.Methods(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("mfa setup: %w", err)
+	}
+	if len(methods) > 0 {
+		return nil, domain.ErrMFAAlreadyEnrolled
+	}
+
+	enrollment, err := s.mfaService.BeginEnrollment(ctx, user.ID, user.Email)
 	if err != nil {
-		return nil, fmt.Errorf("login: token generation: %w", err)
+		if errors.Is(err, domain.ErrMFAAlreadyEnrolled) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("mfa setup: %w", err)
 	}
+	return enrollment, nil
+}
 
-	// Create session
+// passwordLoginAllowed reports whether a member with role may sign in to
+// the tenant with a password, under the tenant's SSO policy.
+func (s *AuthService) passwordLoginAllowed(ctx context.Context, tenantID uuid.UUID, role domain.Role) (bool, error) {
+	if s.ssoConfigs == nil {
+		return true, nil
+	}
+	cfg, err := s.ssoConfigs.FindByTenant(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSSONotConfigured) {
+			return true, nil
+		}
+		return false, fmt.Errorf("sso config lookup: %w", err)
+	}
+	if cfg.AllowsPasswordLogin(role) {
+		return true, nil
+	}
+	// Roles the identity provider can't sign in keep their password
+	ssoAllowed, err := s.ssoAllowsRole(ctx, cfg, role)
+	if err != nil {
+		return false, err
+	}
+	return !ssoAllowed, nil
+}
+
+// ssoAllowsRole reports whether the tenant's identity provider may sign in
+// a member with role. It is trusted only as far as whoever last saved the
+// config: roles they can't manage, Owners always among them, sign in with
+// a password, so an admin's provider can't vouch for a fellow admin.
+func (s *AuthService) ssoAllowsRole(ctx context.Context, cfg *domain.TenantSSOConfig, role domain.Role) (bool, error) {
+	if role == domain.RoleOwner || cfg.UpdatedBy == nil {
+		return false, nil
+	}
+	updater, err := s.userRepo.FindByIDWithTenants(ctx, *cfg.UpdatedBy)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("sso config updater lookup: %w", err)
+	}
+	// The standing role, so an elevation doesn't outlast itself here
+	assignment := updater.GetTenantRole(cfg.TenantID)
+	if assignment == nil {
+		return false, nil
+	}
+	return assignment.Role.CanManage(role), nil
+}
+
+// ssoLoginCarriesOver reports whether an SSO login to the from tenant would
+// also be accepted by the to tenant for a member with role there: both sign
+// in through the same identity provider, and to allows the user's email
+// and role.
+func (s *AuthService) ssoLoginCarriesOver(ctx context.Context, from, to uuid.UUID, email string, role domain.Role) (bool, error) {
+	if s.ssoConfigs == nil {
+		return false, nil
+	}
+	fromCfg, err := s.ssoConfigs.FindByTenant(ctx, from)
+	if err != nil {
+		if errors.Is(err, domain.ErrSSONotConfigured) {
+			return false, nil
+		}
+		return false, fmt.Errorf("sso config lookup: %w", err)
+	}
+	toCfg, err := s.ssoConfigs.FindByTenant(ctx, to)
+	if err != nil {
+		if errors.Is(err, domain.ErrSSONotConfigured) {
+			return false, nil
+		}
+		return false, fmt.Errorf("sso config lookup: %w", err)
+	}
+	if fromCfg.Issuer != toCfg.Issuer || !toCfg.AllowsEmail(email) {
+		return false, nil
+	}
+	return s.ssoAllowsRole(ctx, toCfg, role)
+}
+
+// issueSession generates a token pair, persists the backing session and
+// logs the successful login. Shared by every path that ends in a login;
+// method is how the login was authenticated (domain.SignInPassword etc).
+func (s *AuthService) issueSession(ctx context.Context, user *domain.User, tenantID uuid.UUID, role domain.Role, method, ipAddress, userAgent string, metadata map[string]interface{}) (*LoginResponse, error) {
+	// Each login starts a new refresh-token family
+	sessionID := uuid.New()
 	session := &domain.Session{
-		ID:           uuid.New(),
+		ID:           sessionID,
 		UserID:       user.ID,
-		TenantID:     selectedTenantID,
-		RefreshToken: refreshTokenHash,
-		DeviceInfo:   req.UserAgent,
-		IPAddress:    req.IPAddress,
+		TenantID:     tenantID,
+		FamilyID:     sessionID,
+		SignInMethod: method,
 		ExpiresAt:    s.tokenService.GetRefreshTokenExpiry(),
 	}
+	resp, err := s.createSession(ctx, session, user, role, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Log successful login
+	s.logEvent(ctx, domain.EventLoginSuccess, &user.ID, &tenantID, ipAddress, userAgent, metadata)
+
+	return resp, nil
+}
+
+// createSession generates a token pair for session's tenant and persists
+// session with it.
+func (s *AuthService) createSession(ctx context.Context, session *domain.Session, user *domain.User, role domain.Role, ipAddress, userAgent string) (*LoginResponse, error) {
+	session.DeviceInfo = userAgent
+	session.IPAddress = ipAddress
+
+	// Generate tokens
+	tokenPair, refreshTokenHash, err := s.tokenService.GenerateTokenPair(user, session.ID, session.TenantID, role, user.GetPermissionsForTenant(session.TenantID))
+	if err != nil {
+		return nil, fmt.Errorf("token generation: %w", err)
+	}
+	session.RefreshToken = refreshTokenHash
 
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
-		return nil, fmt.Errorf("login: session create: %w", err)
+		return nil, fmt.Errorf("session create: %w", err)
 	}
 
-	// Log successful login
-	s.logEvent(ctx, domain.EventLoginSuccess, &user.ID, &selectedTenantID, req.IPAddress, req.UserAgent, nil)
+	return &LoginResponse{
+		TokenPair: tokenPair,
+		User:      user,
+		TenantID:  session.TenantID,
+		Role:      role,
+	}, nil
+}
+
+// SwitchTenantRequest contains the data needed to switch tenants.
+type SwitchTenantRequest struct {
+	UserID    uuid.UUID
+	SessionID uuid.UUID // Session behind the calling access token
+	TenantID  uuid.UUID // Tenant to switch to
+	IPAddress string
+	UserAgent string
+}
+
+// SwitchTenant moves a signed-in user to another of their tenants without
+// re-entering credentials. The current session is replaced by its rotated
+// child, scoped to the target tenant. A user who has not enrolled in MFA
+// and whose role in the target tenant requires it gets
+// ErrMFAEnrollmentRequired with an MFA challenge for that tenant, and keeps
+// their current session.
+//
+// A session signed in through SSO only switches freely to tenants whose SSO
+// would accept the same login. For any other tenant, enrolled users get
+// ErrMFARequired with an MFA challenge for it, and others ErrSignInRequired;
+// either way they keep their current session.
+func (s *AuthService) SwitchTenant(ctx context.Context, req SwitchTenantRequest) (*LoginResponse, error) {
+	session, err := s.sessionRepo.FindByID(ctx, req.SessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, domain.ErrSessionRevoked
+		}
+		return nil, fmt.Errorf("switch tenant: session lookup: %w", err)
+	}
+	if session.UserID != req.UserID || !session.IsValid() {
+		return nil, domain.ErrSessionRevoked
+	}
+	if session.TenantID == req.TenantID {
+		return nil, domain.ErrSameTenant
+	}
+
+	user, err := s.userRepo.FindByIDWithTenants(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("switch tenant: user lookup: %w", err)
+	}
+	if !user.CanLogin() {
+		return nil, domain.ErrAccountDisabled
+	}
+
+	role := user.GetRoleForTenant(req.TenantID)
+	if role == "" {
+		return nil, domain.ErrUserNotInTenant
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, req.TenantID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTenantNotFound) {
+			return nil, domain.ErrUserNotInTenant
+		}
+		return nil, fmt.Errorf("switch tenant: tenant lookup: %w", err)
+	}
+	if !tenant.IsOperational() {
+		return nil, domain.ErrTenantInactive
+	}
+
+	// An SSO login only vouches for the user to tenants that would accept
+	// the same login from the same identity provider
+	carriesOver := false
+	if session.IsDelegated() {
+		carriesOver, err = s.ssoLoginCarriesOver(ctx, session.TenantID, req.TenantID, user.Email, role)
+		if err != nil {
+			return nil, fmt.Errorf("switch tenant: %w", err)
+		}
+	}
+
+	// Tenants that have turned password login off are only entered through
+	// their identity provider
+	if session.SignInMethod != domain.SignInSSO || !carriesOver {
+		allowed, err := s.passwordLoginAllowed(ctx, req.TenantID, role)
+		if err != nil {
+			return nil, fmt.Errorf("switch tenant: %w", err)
+		}
+		if !allowed {
+			return nil, domain.ErrPasswordLoginDisabled
+		}
+	}
+
+	// Otherwise a session vouched for by an identity provider or terminal
+	// proves nothing here: enrolled users answer a fresh MFA challenge for
+	// the target tenant, and everyone else signs in to it directly.
+	if session.IsDelegated() && !carriesOver {
+		var methods []string
+		if s.mfaService != nil {
+			methods, err = s.mfaService.Methods(ctx, user.ID)
+			if err != nil {
+				return nil, fmt.Errorf("switch tenant: %w", err)
+			}
+		}
+		if len(methods) == 0 {
+			return nil, domain.ErrSignInRequired
+		}
+		mfaToken, err := s.mfaService.CreateChallenge(ctx, user.ID, req.TenantID)
+		if err != nil {
+			return nil, fmt.Errorf("switch tenant: %w", err)
+		}
+		return &LoginResponse{User: user, TenantID: req.TenantID, Role: role, MFAToken: mfaToken, MFAMethods: methods}, domain.ErrMFARequired
+	}
+
+	// Enrolled users already completed MFA for this password session.
+	// Anyone else moving into a role that requires it must enroll first.
+	if s.mfaService != nil && role.RequiresMFA() {
+		methods, err := s.mfaService.Methods(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("switch tenant: %w", err)
+		}
+		if len(methods) == 0 {
+			mfaToken, err := s.mfaService.CreateChallenge(ctx, user.ID, req.TenantID)
+			if err != nil {
+				return nil, fmt.Errorf("switch tenant: %w", err)
+			}
+			return &LoginResponse{User: user, TenantID: req.TenantID, Role: role, MFAToken: mfaToken}, domain.ErrMFAEnrollmentRequired
+		}
+	}
+
+	// The new session continues the old one's refresh-token family, so
+	// replaying the old refresh token revokes it as in Refresh
+	next := session.Rotate(s.tokenService.GetRefreshTokenExpiry())
+	next.TenantID = req.TenantID
+	resp, err := s.createSession(ctx, next, user, role, req.IPAddress, req.UserAgent)
+	if err != nil {
+		return nil, fmt.Errorf("switch tenant: %w", err)
+	}
+
+	if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+		return nil, fmt.Errorf("switch tenant: session revoke: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventTenantSwitched, &user.ID, &req.TenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+		"from_tenant_id":  session.TenantID.String(),
+		"from_session_id": session.ID.String(),
+	})
+
+	return resp, nil
+}
+
+// ImpersonateRequest contains the data needed to impersonate a user.
+type ImpersonateRequest struct {
+	ActorID   uuid.UUID
+	ActorRole domain.Role // Actor's role in the tenant
+	UserID    uuid.UUID   // User to impersonate
+	TenantID  uuid.UUID
+	Reason    string
+	IPAddress string
+	UserAgent string
+}
+
+// Impersonate issues the actor an access token for another user in the
+// tenant, so support can see exactly what that user sees. The user must
+// rank below the actor. The token lasts domain.ImpersonationTTL, has no
+// refresh token or session, and carries the actor in its act claim; every
+// auth event recorded under it names the actor.
+func (s *AuthService) Impersonate(ctx context.Context, req ImpersonateRequest) (*LoginResponse, error) {
+	if req.UserID == req.ActorID {
+		return nil, domain.ErrImpersonateSelf
+	}
+
+	user, err := s.userRepo.FindByIDWithTenants(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("impersonate: user lookup: %w", err)
+	}
+	role := user.GetRoleForTenant(req.TenantID)
+	if role == "" {
+		return nil, domain.ErrUserNotFound
+	}
+	if !req.ActorRole.CanManage(role) {
+		return nil, domain.ErrCannotManageRole
+	}
+	if !user.CanLogin() {
+		return nil, domain.ErrAccountDisabled
+	}
+
+	tokenPair, err := s.tokenService.GenerateImpersonationToken(user, req.TenantID, req.ActorID, role, user.GetPermissionsForTenant(req.TenantID), domain.ImpersonationTTL)
+	if err != nil {
+		return nil, fmt.Errorf("impersonate: token generation: %w", err)
+	}
+
+	s.logEvent(WithActor(ctx, req.ActorID), domain.EventImpersonationStarted, &user.ID, &req.TenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+		"actor_role": string(req.ActorRole),
+		"reason":     req.Reason,
+		"expires_at": tokenPair.ExpiresAt,
+	})
 
 	return &LoginResponse{
 		TokenPair: tokenPair,
 		User:      user,
-		TenantID:  selectedTenantID,
-		Role:      selectedRole,
+		TenantID:  req.TenantID,
+		Role:      role,
 	}, nil
 }
 
@@ -273,6 +877,9 @@ func (s *AuthService) Refresh(ctx context.Context, req RefreshRequest) (*domain.
 	// Check if session is valid
 	if !session.IsValid() {
 		if session.IsRevoked() {
+			if err := s.handleRefreshTokenReuse(ctx, session, req.IPAddress, req.UserAgent); err != nil {
+				return nil, err
+			}
 			return nil, domain.ErrSessionRevoked
 		}
 		return nil, domain.ErrTokenExpired
@@ -297,11 +904,26 @@ func (s *AuthService) Refresh(ctx context.Context, req RefreshRequest) (*domain.
 		return nil, domain.ErrUserNotInTenant
 	}
 
-	// Generate new token pair
-	tokenPair, newRefreshTokenHash, err := s.tokenService.GenerateTokenPair(user, session.TenantID, role)
+	// Logins in a role that requires MFA are challenged for it, but an
+	// elevation into one reaches sessions that weren't: those have to sign
+	// in again and answer the challenge before they get the elevated role
+	if s.mfaService != nil && role.RequiresMFA() && !session.SatisfiesMFA() {
+		if tr := user.GetTenantRole(session.TenantID); tr.IsElevated() && !tr.Role.RequiresMFA() {
+			return nil, domain.ErrMFASignInRequired
+		}
+	}
+
+	// Generate new token pair for the session that will replace this one,
+	// in the same family
+	newSession := session.Rotate(s.tokenService.GetRefreshTokenExpiry())
+	newSession.DeviceInfo = req.UserAgent
+	newSession.IPAddress = req.IPAddress
+
+	tokenPair, newRefreshTokenHash, err := s.tokenService.GenerateTokenPair(user, newSession.ID, session.TenantID, role, user.GetPermissionsForTenant(session.TenantID))
 	if err != nil {
 		return nil, fmt.Errorf("refresh: token generation: %w", err)
 	}
+	newSession.RefreshToken = newRefreshTokenHash
 
 	// Revoke old session
 	if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
@@ -309,16 +931,6 @@ func (s *AuthService) Refresh(ctx context.Context, req RefreshRequest) (*domain.
 	}
 
 	// Create new session with rotated refresh token
-	newSession := &domain.Session{
-		ID:           uuid.New(),
-		UserID:       user.ID,
-		TenantID:     session.TenantID,
-		RefreshToken: newRefreshTokenHash,
-		DeviceInfo:   req.UserAgent,
-		IPAddress:    req.IPAddress,
-		ExpiresAt:    s.tokenService.GetRefreshTokenExpiry(),
-	}
-
 	if err := s.sessionRepo.Create(ctx, newSession); err != nil {
 		return nil, fmt.Errorf("refresh: session create: %w", err)
 	}
@@ -329,6 +941,54 @@ func (s *AuthService) Refresh(ctx context.Context, req RefreshRequest) (*domain.
 	return tokenPair, nil
 }
 
+// handleRefreshTokenReuse checks whether a revoked session was revoked by
+// rotation rather than logout. A rotated token should never be presented
+// again, so its reuse means either the legitimate client or an attacker holds
+// a stolen copy; since we can't tell which, the whole family is revoked and
+// both must log in again.
+func (s *AuthService) handleRefreshTokenReuse(ctx context.Context, session *domain.Session, ipAddress, userAgent string) error {
+	rotated, err := s.sessionRepo.ExistsByParent(ctx, session.ID)
+	if err != nil {
+		return fmt.Errorf("refresh: reuse check: %w", err)
+	}
+	if !rotated {
+		return nil
+	}
+
+	revoked, err := s.sessionRepo.RevokeFamily(ctx, session.FamilyID)
+	if err != nil {
+		return fmt.Errorf("refresh: revoke family: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventSessionRevoked, &session.UserID, &session.TenantID, ipAddress, userAgent, map[string]interface{}{
+		"reason":           "refresh_token_reuse",
+		"family_id":        session.FamilyID.String(),
+		"sessions_revoked": revoked,
+	})
+
+	if s.emailer != nil {
+		s.notifyRefreshTokenReuse(ctx, session, ipAddress)
+	}
+
+	return nil
+}
+
+// notifyRefreshTokenReuse emails the user about a detected reuse. Failures
+// are logged and audited but never change the outcome of the refresh.
+func (s *AuthService) notifyRefreshTokenReuse(ctx context.Context, session *domain.Session, ipAddress string) {
+	user, err := s.userRepo.FindByID(ctx, session.UserID)
+	if err == nil {
+		err = s.emailer.SendRefreshTokenReuse(ctx, user.Email)
+	}
+	if err != nil {
+		log.Printf("ERROR: failed to send refresh_token_reuse email to user %s: %v", session.UserID, err)
+		s.logEvent(ctx, domain.EventEmailDeliveryFailed, &session.UserID, &session.TenantID, ipAddress, "", map[string]interface{}{
+			"email_type": "refresh_token_reuse",
+			"error":      err.Error(),
+		})
+	}
+}
+
 // Logout invalidates a user's session.
 func (s *AuthService) Logout(ctx context.Context, refreshToken, ipAddress string) error {
 	// Hash the provided refresh token
@@ -355,11 +1015,14 @@ func (s *AuthService) Logout(ctx context.Context, refreshToken, ipAddress string
 	return nil
 }
 
-// LogoutAll invalidates all sessions for a user.
+// LogoutAll invalidates all sessions and access tokens for a user.
 func (s *AuthService) LogoutAll(ctx context.Context, userID uuid.UUID, ipAddress string) error {
 	if err := s.sessionRepo.RevokeAllForUser(ctx, userID); err != nil {
 		return fmt.Errorf("logout all: revoke sessions: %w", err)
 	}
+	if err := revokeUserAccessTokens(ctx, s.revocations, userID, s.tokenService.GetAccessTokenTTL()); err != nil {
+		return fmt.Errorf("logout all: revoke access tokens: %w", err)
+	}
 
 	s.logEvent(ctx, domain.EventSessionRevoked, &userID, nil, ipAddress, "", map[string]interface{}{
 		"scope": "all_sessions",
@@ -368,14 +1031,166 @@ func (s *AuthService) LogoutAll(ctx context.Context, userID uuid.UUID, ipAddress
 	return nil
 }
 
-// ValidateToken validates an access token and returns the claims.
+// LogoutOthers invalidates every session for a user except the one they are
+// currently using ("log out everywhere else").
+func (s *AuthService) LogoutOthers(ctx context.Context, userID, currentSessionID uuid.UUID, ipAddress string) error {
+	if err := s.sessionRepo.RevokeAllForUserExcept(ctx, userID, currentSessionID); err != nil {
+		return fmt.Errorf("logout others: revoke sessions: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventSessionRevoked, &userID, nil, ipAddress, "", map[string]interface{}{
+		"scope":        "other_sessions",
+		"kept_session": currentSessionID.String(),
+	})
+
+	return nil
+}
+
+// ListSessions returns the active sessions (signed-in devices) for a user.
+func (s *AuthService) ListSessions(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+	sessions, err := s.sessionRepo.ListActiveForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs a user out of one of their own sessions.
+// Sessions belonging to someone else are reported as not found.
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID, ipAddress string) error {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return domain.ErrSessionNotFound
+		}
+		return fmt.Errorf("revoke session: session lookup: %w", err)
+	}
+
+	if session.UserID != userID || session.IsRevoked() {
+		return domain.ErrSessionNotFound
+	}
+
+	if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventSessionRevoked, &userID, &session.TenantID, ipAddress, "", map[string]interface{}{
+		"scope":      "session",
+		"session_id": session.ID.String(),
+	})
+
+	return nil
+}
+
+// ValidateToken validates an access token and returns the claims. Tokens on
+// the revocation list, and OAuth client tokens whose grant has been revoked,
+// are rejected with ErrTokenRevoked.
 func (s *AuthService) ValidateToken(ctx context.Context, token string) (*domain.Claims, error) {
-	return s.tokenService.ValidateAccessToken(token)
+	claims, err := s.tokenService.ValidateAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.IsClientToken() {
+		if s.oauth == nil {
+			return nil, domain.ErrTokenInvalid
+		}
+		if err := s.oauth.ValidateClientToken(ctx, claims); err != nil {
+			return nil, err
+		}
+	}
+	if s.revocations == nil {
+		return claims, nil
+	}
+
+	userID, err := claims.GetUserID()
+	if err != nil {
+		return nil, domain.ErrTokenInvalid
+	}
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := s.revocations.IsRevoked(ctx, claims.ID, userID, issuedAt)
+	if err != nil {
+		return nil, fmt.Errorf("validate token: revocation check: %w", err)
+	}
+	if revoked {
+		return nil, domain.ErrTokenRevoked
+	}
+
+	// An impersonation also ends when the actor's own tokens are revoked,
+	// e.g. on deactivation or logout everywhere
+	if claims.IsImpersonation() {
+		actorID, ok := claims.GetActorID()
+		if !ok {
+			return nil, domain.ErrTokenInvalid
+		}
+		revoked, err := s.revocations.IsRevoked(ctx, "", actorID, issuedAt)
+		if err != nil {
+			return nil, fmt.Errorf("validate token: actor revocation check: %w", err)
+		}
+		if revoked {
+			return nil, domain.ErrTokenRevoked
+		}
+	}
+
+	return claims, nil
+}
+
+// ValidateAPIKey validates a service account API key and returns the claims
+// it carries, as ValidateToken does for an access token. It returns
+// ErrAPIKeyExpired for an expired key and ErrAPIKeyInvalid for any other key
+// that doesn't work.
+func (s *AuthService) ValidateAPIKey(ctx context.Context, key string) (*domain.Claims, error) {
+	if s.serviceAccts == nil {
+		return nil, domain.ErrAPIKeyInvalid
+	}
+	account, apiKey, err := s.serviceAccts.Authenticate(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return account.Claims(apiKey), nil
+}
+
+// RevokeAccessToken revokes a single access token, by its jti, until it
+// expires.
+func (s *AuthService) RevokeAccessToken(ctx context.Context, claims *domain.Claims) error {
+	if s.revocations == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	userID, err := claims.GetUserID()
+	if err != nil {
+		return domain.ErrTokenInvalid
+	}
+
+	if err := s.revocations.RevokeToken(ctx, &domain.RevokedAccessToken{
+		JTI:       claims.ID,
+		UserID:    userID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}); err != nil {
+		return fmt.Errorf("revoke access token: %w", err)
+	}
+	return nil
+}
+
+// revokeUserAccessTokens revokes every access token issued to a user so far.
+// The entry is kept for one access token TTL, after which every token it
+// covers has expired anyway. A nil store revokes nothing.
+func revokeUserAccessTokens(ctx context.Context, store repository.TokenRevocationStore, userID uuid.UUID, accessTokenTTL time.Duration) error {
+	if store == nil {
+		return nil
+	}
+	now := time.Now()
+	return store.RevokeUser(ctx, &domain.UserTokenRevocation{
+		UserID:        userID,
+		RevokedBefore: now,
+		ExpiresAt:     now.Add(accessTokenTTL),
+	})
 }
 
 // logEvent logs an authentication event.
 func (s *AuthService) logEvent(ctx context.Context, eventType domain.AuthEventType, userID, tenantID *uuid.UUID, ipAddress, userAgent string, metadata map[string]interface{}) {
-	event := domain.NewAuthEvent(eventType, userID, tenantID, ipAddress, userAgent)
+	event := newAuthEvent(ctx, eventType, userID, tenantID, ipAddress, userAgent)
 	if metadata != nil {
 		event.Metadata = metadata
 	}
@@ -6,11 +6,14 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
 	"github.com/solobueno/erp/internal/auth/repository/mock"
 	"github.com/solobueno/erp/pkg/jwt"
 )
@@ -263,6 +266,54 @@ func TestAuthService_Login_ResetsFailedCountOnSuccess(t *testing.T) {
 	}
 }
 
+func TestAuthService_Login_RehashesOutdatedHash(t *testing.T) {
+	authSvc, userRepo, _, tenantRepo, _ := setupAuthService(t)
+	ctx := context.Background()
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	passwordHash, _ := NewPasswordService().Hash("Password123!")
+	changedAt := time.Now().Add(-24 * time.Hour)
+	userRepo.AddUser(&domain.User{
+		ID:                userID,
+		Email:             "test@example.com",
+		PasswordHash:      passwordHash,
+		PasswordChangedAt: &changedAt,
+		IsActive:          true,
+		TenantRoles: []domain.UserTenantRole{
+			{TenantID: tenantID, Role: domain.RoleManager},
+		},
+	})
+	tenantRepo.AddTenant(&domain.Tenant{ID: tenantID, IsActive: true})
+
+	// A pepper is introduced after the hash was made
+	peppered, err := NewPasswordServiceWithConfig(PasswordHashingConfig{Peppers: []PasswordPepper{testPepper(1)}})
+	if err != nil {
+		t.Fatalf("NewPasswordServiceWithConfig: %v", err)
+	}
+	authSvc.passwordSvc = peppered
+
+	if _, err := authSvc.Login(ctx, LoginRequest{Email: "test@example.com", Password: "Password123!"}); err != nil {
+		t.Fatalf("expected successful login, got %v", err)
+	}
+	user, _ := userRepo.FindByID(ctx, userID)
+	if user.PasswordHash == passwordHash || peppered.NeedsRehash(user.PasswordHash) {
+		t.Fatalf("expected the hash to be upgraded, got %s", user.PasswordHash)
+	}
+	if !user.PasswordChangedAt.Equal(changedAt) {
+		t.Error("a rehash must not count as a password change")
+	}
+
+	// The upgraded hash keeps working, and isn't rehashed again
+	upgraded := user.PasswordHash
+	if _, err := authSvc.Login(ctx, LoginRequest{Email: "test@example.com", Password: "Password123!"}); err != nil {
+		t.Fatalf("expected successful login with the upgraded hash, got %v", err)
+	}
+	if user, _ := userRepo.FindByID(ctx, userID); user.PasswordHash != upgraded {
+		t.Error("expected a current hash to be kept")
+	}
+}
+
 func TestAuthService_Login_UserNotFound(t *testing.T) {
 	authSvc, _, _, _, _ := setupAuthService(t)
 	ctx := context.Background()
@@ -521,6 +572,51 @@ func TestAuthService_Refresh_UsesPreloadedTenantRoles(t *testing.T) {
 	}
 }
 
+// TestAuthService_Refresh_ExpiredElevation checks a token refreshed after a
+// role elevation has ended carries the standing role, even before the
+// expirer has cleared the elevation.
+func TestAuthService_Refresh_ExpiredElevation(t *testing.T) {
+	authSvc, userRepo, _, tenantRepo, _ := setupAuthService(t)
+	ctx := context.Background()
+
+	tenantID := uuid.New()
+	passwordHash, _ := NewPasswordService().Hash("Password123!")
+	validFrom, validUntil := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	user := &domain.User{
+		ID:           uuid.New(),
+		Email:        "test@example.com",
+		PasswordHash: passwordHash,
+		IsActive:     true,
+		TenantRoles: []domain.UserTenantRole{{
+			TenantID: tenantID, Role: domain.RoleWaiter,
+			ElevatedRole: domain.RoleCashier, ValidFrom: &validFrom, ValidUntil: &validUntil,
+		}},
+	}
+	userRepo.AddUser(user)
+	tenantRepo.AddTenant(&domain.Tenant{ID: tenantID, IsActive: true})
+
+	loginResp, err := authSvc.Login(ctx, LoginRequest{Email: "test@example.com", Password: "Password123!"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	claims, _ := authSvc.ValidateToken(ctx, loginResp.TokenPair.AccessToken)
+	if claims.Role != domain.RoleCashier {
+		t.Errorf("Role while elevated = %v, want %v", claims.Role, domain.RoleCashier)
+	}
+
+	// The elevation ends
+	validUntil = time.Now().Add(-time.Second)
+
+	pair, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: loginResp.TokenPair.RefreshToken})
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	claims, _ = authSvc.ValidateToken(ctx, pair.AccessToken)
+	if claims.Role != domain.RoleWaiter {
+		t.Errorf("Role after the elevation ended = %v, want %v", claims.Role, domain.RoleWaiter)
+	}
+}
+
 func TestAuthService_Refresh_InvalidToken(t *testing.T) {
 	authSvc, _, _, _, _ := setupAuthService(t)
 	ctx := context.Background()
@@ -534,6 +630,146 @@ func TestAuthService_Refresh_InvalidToken(t *testing.T) {
 	}
 }
 
+// loginForRefresh seeds a user, logs in and returns the initial refresh token.
+func loginForRefresh(t *testing.T, authSvc *AuthService, userRepo *mock.MockUserRepository, tenantRepo *mock.MockTenantRepository) string {
+	t.Helper()
+
+	tenantID := uuid.New()
+	passwordHash, _ := NewPasswordService().Hash("Password123!")
+	userRepo.AddUser(&domain.User{
+		ID:           uuid.New(),
+		Email:        "test@example.com",
+		PasswordHash: passwordHash,
+		IsActive:     true,
+		TenantRoles:  []domain.UserTenantRole{{TenantID: tenantID, Role: domain.RoleWaiter}},
+	})
+	tenantRepo.AddTenant(&domain.Tenant{ID: tenantID, IsActive: true})
+
+	loginResp, err := authSvc.Login(context.Background(), LoginRequest{Email: "test@example.com", Password: "Password123!"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	return loginResp.TokenPair.RefreshToken
+}
+
+func reuseEvents(events []*domain.AuthEvent) []*domain.AuthEvent {
+	var result []*domain.AuthEvent
+	for _, e := range events {
+		if e.EventType == domain.EventSessionRevoked && e.Metadata["reason"] == "refresh_token_reuse" {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func TestAuthService_Refresh_RotatesWithinFamily(t *testing.T) {
+	authSvc, userRepo, sessionRepo, tenantRepo, _ := setupAuthService(t)
+	ctx := context.Background()
+	token := loginForRefresh(t, authSvc, userRepo, tenantRepo)
+
+	first, _ := sessionRepo.FindByToken(ctx, authSvc.tokenService.HashRefreshToken(token))
+	pair, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: token})
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	second, _ := sessionRepo.FindByToken(ctx, authSvc.tokenService.HashRefreshToken(pair.RefreshToken))
+
+	if first.FamilyID != first.ID {
+		t.Error("Login session should start its own family")
+	}
+	if second.FamilyID != first.FamilyID {
+		t.Error("Rotated session should stay in the login's family")
+	}
+	if second.ParentID == nil || *second.ParentID != first.ID {
+		t.Error("Rotated session should point at the session it replaced")
+	}
+}
+
+func TestAuthService_Refresh_ReuseRevokesFamily(t *testing.T) {
+	authSvc, userRepo, sessionRepo, tenantRepo, eventRepo := setupAuthService(t)
+	ctx := context.Background()
+	stolen := loginForRefresh(t, authSvc, userRepo, tenantRepo)
+
+	// The legitimate client rotates twice
+	pair, _ := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: stolen})
+	pair, _ = authSvc.Refresh(ctx, RefreshRequest{RefreshToken: pair.RefreshToken})
+
+	// An unrelated login stays untouched
+	other, _ := authSvc.Login(ctx, LoginRequest{Email: "test@example.com", Password: "Password123!"})
+
+	// Replaying the first token is reuse
+	_, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: stolen, IPAddress: "10.0.0.9"})
+	if err != domain.ErrSessionRevoked {
+		t.Fatalf("Refresh error = %v, want ErrSessionRevoked", err)
+	}
+
+	if _, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: pair.RefreshToken}); err != domain.ErrSessionRevoked {
+		t.Errorf("Latest token in the family error = %v, want ErrSessionRevoked", err)
+	}
+	otherSession, _ := sessionRepo.FindByToken(ctx, authSvc.tokenService.HashRefreshToken(other.TokenPair.RefreshToken))
+	if otherSession.IsRevoked() {
+		t.Error("Session from a separate login should not be revoked")
+	}
+
+	events := reuseEvents(eventRepo.GetEvents())
+	if len(events) != 1 {
+		t.Fatalf("len(reuse events) = %d, want 1", len(events))
+	}
+	if events[0].Metadata["sessions_revoked"] != int64(1) {
+		t.Errorf("sessions_revoked = %v, want 1", events[0].Metadata["sessions_revoked"])
+	}
+	if events[0].IPAddress != "10.0.0.9" {
+		t.Errorf("IPAddress = %q, want the replaying client's", events[0].IPAddress)
+	}
+}
+
+func TestAuthService_Refresh_LoggedOutTokenIsNotReuse(t *testing.T) {
+	authSvc, userRepo, _, tenantRepo, eventRepo := setupAuthService(t)
+	ctx := context.Background()
+	token := loginForRefresh(t, authSvc, userRepo, tenantRepo)
+
+	authSvc.Logout(ctx, token, "")
+
+	if _, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: token}); err != domain.ErrSessionRevoked {
+		t.Fatalf("Refresh error = %v, want ErrSessionRevoked", err)
+	}
+	if len(reuseEvents(eventRepo.GetEvents())) != 0 {
+		t.Error("A logged-out token was never rotated, so it shouldn't be flagged as reuse")
+	}
+}
+
+func TestAuthService_Refresh_ReuseNotifiesUser(t *testing.T) {
+	base, userRepo, sessionRepo, tenantRepo, eventRepo := setupAuthService(t)
+	authSvc := NewAuthService(AuthServiceConfig{
+		UserRepo:     userRepo,
+		SessionRepo:  sessionRepo,
+		EventRepo:    eventRepo,
+		TenantRepo:   tenantRepo,
+		RoleRepo:     mock.NewMockUserTenantRoleRepository(),
+		TokenService: base.tokenService,
+		Emailer:      &failingEmailer{err: errors.New("smtp timeout")},
+	})
+	ctx := context.Background()
+	token := loginForRefresh(t, authSvc, userRepo, tenantRepo)
+
+	authSvc.Refresh(ctx, RefreshRequest{RefreshToken: token})
+
+	// A failed notification is audited but doesn't change the outcome
+	if _, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: token}); err != domain.ErrSessionRevoked {
+		t.Fatalf("Refresh error = %v, want ErrSessionRevoked", err)
+	}
+
+	var found bool
+	for _, e := range eventRepo.GetEvents() {
+		if e.EventType == domain.EventEmailDeliveryFailed && e.Metadata["email_type"] == "refresh_token_reuse" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected email_delivery_failed event for the reuse notification")
+	}
+}
+
 func TestAuthService_Logout_Success(t *testing.T) {
 	authSvc, userRepo, _, tenantRepo, _ := setupAuthService(t)
 	ctx := context.Background()
@@ -635,6 +871,187 @@ func TestAuthService_LogoutAll(t *testing.T) {
 	}
 }
 
+// loginTwice logs the same user in from two devices and returns the
+// responses plus the user's ID.
+func loginTwice(t *testing.T, authSvc *AuthService, userRepo *mock.MockUserRepository, tenantRepo *mock.MockTenantRepository) (*LoginResponse, *LoginResponse, uuid.UUID) {
+	t.Helper()
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	passwordHash, _ := NewPasswordService().Hash("Password123!")
+	userRepo.AddUser(&domain.User{
+		ID:           userID,
+		Email:        "test@example.com",
+		PasswordHash: passwordHash,
+		IsActive:     true,
+		TenantRoles:  []domain.UserTenantRole{{TenantID: tenantID, Role: domain.RoleWaiter}},
+	})
+	tenantRepo.AddTenant(&domain.Tenant{ID: tenantID, IsActive: true})
+
+	ctx := context.Background()
+	first, err := authSvc.Login(ctx, LoginRequest{Email: "test@example.com", Password: "Password123!", UserAgent: "Mozilla/5.0 (Windows NT 10.0) Chrome/120.0 Safari/537.36"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	second, err := authSvc.Login(ctx, LoginRequest{Email: "test@example.com", Password: "Password123!", UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) Safari/604.1"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	return first, second, userID
+}
+
+func sessionIDFromToken(t *testing.T, authSvc *AuthService, accessToken string) uuid.UUID {
+	t.Helper()
+
+	claims, err := authSvc.ValidateToken(context.Background(), accessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	id, err := claims.GetSessionID()
+	if err != nil {
+		t.Fatalf("access token has no session: %v", err)
+	}
+	return id
+}
+
+func TestAuthService_ListSessions(t *testing.T) {
+	authSvc, userRepo, _, tenantRepo, _ := setupAuthService(t)
+	ctx := context.Background()
+	first, second, userID := loginTwice(t, authSvc, userRepo, tenantRepo)
+
+	sessions, err := authSvc.ListSessions(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+	}
+
+	ids := map[uuid.UUID]bool{}
+	for _, s := range sessions {
+		ids[s.ID] = true
+	}
+	if !ids[sessionIDFromToken(t, authSvc, first.TokenPair.AccessToken)] || !ids[sessionIDFromToken(t, authSvc, second.TokenPair.AccessToken)] {
+		t.Error("Access tokens should carry the IDs of the listed sessions")
+	}
+}
+
+func TestAuthService_Refresh_KeepsSessionIDInAccessToken(t *testing.T) {
+	authSvc, userRepo, sessionRepo, tenantRepo, _ := setupAuthService(t)
+	ctx := context.Background()
+	token := loginForRefresh(t, authSvc, userRepo, tenantRepo)
+
+	pair, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: token})
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	rotated, _ := sessionRepo.FindByToken(ctx, authSvc.tokenService.HashRefreshToken(pair.RefreshToken))
+	if got := sessionIDFromToken(t, authSvc, pair.AccessToken); got != rotated.ID {
+		t.Errorf("Access token sid = %v, want rotated session %v", got, rotated.ID)
+	}
+}
+
+func TestAuthService_RevokeSession(t *testing.T) {
+	authSvc, userRepo, _, tenantRepo, eventRepo := setupAuthService(t)
+	ctx := context.Background()
+	first, second, userID := loginTwice(t, authSvc, userRepo, tenantRepo)
+
+	secondID := sessionIDFromToken(t, authSvc, second.TokenPair.AccessToken)
+	if err := authSvc.RevokeSession(ctx, userID, secondID, "127.0.0.1"); err != nil {
+		t.Fatalf("RevokeSession failed: %v", err)
+	}
+
+	if _, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: second.TokenPair.RefreshToken}); err == nil {
+		t.Error("Revoked session should not refresh")
+	}
+	if _, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: first.TokenPair.RefreshToken}); err != nil {
+		t.Errorf("Other session should still refresh: %v", err)
+	}
+
+	found := false
+	for _, e := range eventRepo.GetEvents() {
+		if e.EventType == domain.EventSessionRevoked && e.Metadata["session_id"] == secondID.String() {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected a session_revoked event for the session")
+	}
+
+	// Already revoked
+	if err := authSvc.RevokeSession(ctx, userID, secondID, "127.0.0.1"); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound for a revoked session, got %v", err)
+	}
+}
+
+func TestAuthService_RevokeSession_OtherUsersSession(t *testing.T) {
+	authSvc, userRepo, _, tenantRepo, _ := setupAuthService(t)
+	ctx := context.Background()
+	first, _, _ := loginTwice(t, authSvc, userRepo, tenantRepo)
+
+	err := authSvc.RevokeSession(ctx, uuid.New(), sessionIDFromToken(t, authSvc, first.TokenPair.AccessToken), "127.0.0.1")
+	if !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+	if _, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: first.TokenPair.RefreshToken}); err != nil {
+		t.Errorf("Session should not have been revoked: %v", err)
+	}
+}
+
+func TestAuthService_RevokeSession_NotFound(t *testing.T) {
+	authSvc, _, _, _, _ := setupAuthService(t)
+
+	err := authSvc.RevokeSession(context.Background(), uuid.New(), uuid.New(), "127.0.0.1")
+	if !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestAuthService_LogoutOthers(t *testing.T) {
+	authSvc, userRepo, _, tenantRepo, _ := setupAuthService(t)
+	ctx := context.Background()
+	first, second, userID := loginTwice(t, authSvc, userRepo, tenantRepo)
+
+	current := sessionIDFromToken(t, authSvc, first.TokenPair.AccessToken)
+	if err := authSvc.LogoutOthers(ctx, userID, current, "127.0.0.1"); err != nil {
+		t.Fatalf("LogoutOthers failed: %v", err)
+	}
+
+	if _, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: second.TokenPair.RefreshToken}); err == nil {
+		t.Error("Other session should be revoked")
+	}
+	if _, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: first.TokenPair.RefreshToken}); err != nil {
+		t.Errorf("Current session should still refresh: %v", err)
+	}
+}
+
+func TestAuthService_SessionRepositoryErrors(t *testing.T) {
+	authSvc, _, sessionRepo, _, _ := setupAuthService(t)
+	ctx := context.Background()
+	dbErr := errors.New("db down")
+
+	sessionRepo.ListActiveForUserFunc = func(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+		return nil, dbErr
+	}
+	sessionRepo.RevokeAllForUserExceptFunc = func(ctx context.Context, userID, keepID uuid.UUID) error {
+		return dbErr
+	}
+	sessionRepo.FindByIDFunc = func(ctx context.Context, id uuid.UUID) (*domain.Session, error) {
+		return nil, dbErr
+	}
+
+	if _, err := authSvc.ListSessions(ctx, uuid.New()); !errors.Is(err, dbErr) {
+		t.Errorf("ListSessions: expected wrapped db error, got %v", err)
+	}
+	if err := authSvc.LogoutOthers(ctx, uuid.New(), uuid.New(), ""); !errors.Is(err, dbErr) {
+		t.Errorf("LogoutOthers: expected wrapped db error, got %v", err)
+	}
+	if err := authSvc.RevokeSession(ctx, uuid.New(), uuid.New(), ""); !errors.Is(err, dbErr) {
+		t.Errorf("RevokeSession: expected wrapped db error, got %v", err)
+	}
+}
+
 func TestAuthService_ValidateToken(t *testing.T) {
 	authSvc, userRepo, _, tenantRepo, _ := setupAuthService(t)
 	ctx := context.Background()
@@ -684,3 +1101,420 @@ func TestAuthService_ValidateToken_Invalid(t *testing.T) {
 		t.Error("Expected error for invalid token")
 	}
 }
+
+// setupAuthServiceWithRevocations is setupAuthService with an in-memory
+// access-token revocation list.
+func setupAuthServiceWithRevocations(t *testing.T) (*AuthService, *repository.MemoryTokenRevocationStore, *mock.MockUserRepository, *mock.MockTenantRepository) {
+	t.Helper()
+
+	authSvc, userRepo, sessionRepo, tenantRepo, eventRepo := setupAuthService(t)
+	store := repository.NewMemoryTokenRevocationStore(time.Minute)
+	authSvc = NewAuthService(AuthServiceConfig{
+		UserRepo:        userRepo,
+		SessionRepo:     sessionRepo,
+		EventRepo:       eventRepo,
+		TenantRepo:      tenantRepo,
+		RoleRepo:        mock.NewMockUserTenantRoleRepository(),
+		TokenService:    authSvc.tokenService,
+		RevocationStore: store,
+	})
+
+	return authSvc, store, userRepo, tenantRepo
+}
+
+func TestAuthService_ValidateToken_Revoked(t *testing.T) {
+	authSvc, store, userRepo, tenantRepo := setupAuthServiceWithRevocations(t)
+	ctx := context.Background()
+
+	tenantID := uuid.New()
+	passwordHash, _ := NewPasswordService().Hash("Password123!")
+	user := &domain.User{
+		ID:           uuid.New(),
+		Email:        "test@example.com",
+		PasswordHash: passwordHash,
+		IsActive:     true,
+		TenantRoles:  []domain.UserTenantRole{{TenantID: tenantID, Role: domain.RoleWaiter}},
+	}
+	userRepo.AddUser(user)
+	tenantRepo.AddTenant(&domain.Tenant{ID: tenantID, IsActive: true})
+
+	login := func() string {
+		t.Helper()
+		resp, err := authSvc.Login(ctx, LoginRequest{Email: "test@example.com", Password: "Password123!"})
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		return resp.TokenPair.AccessToken
+	}
+
+	// By jti
+	token := login()
+	claims, err := authSvc.ValidateToken(ctx, token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if err := authSvc.RevokeAccessToken(ctx, claims); err != nil {
+		t.Fatalf("RevokeAccessToken failed: %v", err)
+	}
+	if _, err := authSvc.ValidateToken(ctx, token); !errors.Is(err, domain.ErrTokenRevoked) {
+		t.Errorf("Expected ErrTokenRevoked for a revoked jti, got %v", err)
+	}
+
+	// By user: tokens issued before the cutoff are rejected, a new login is not
+	other := login()
+	store.RevokeUser(ctx, &domain.UserTokenRevocation{UserID: user.ID, RevokedBefore: time.Now().Add(2 * time.Second), ExpiresAt: time.Now().Add(time.Hour)})
+	if _, err := authSvc.ValidateToken(ctx, other); !errors.Is(err, domain.ErrTokenRevoked) {
+		t.Errorf("Expected ErrTokenRevoked for a token issued before the cutoff, got %v", err)
+	}
+}
+
+func TestAuthService_LogoutAll_RevokesAccessTokens(t *testing.T) {
+	authSvc, store, _, _ := setupAuthServiceWithRevocations(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	if err := authSvc.LogoutAll(ctx, userID, "127.0.0.1"); err != nil {
+		t.Fatalf("LogoutAll failed: %v", err)
+	}
+
+	revoked, _ := store.IsRevoked(ctx, "", userID, time.Now().Add(-2*time.Second))
+	if !revoked {
+		t.Error("Access tokens issued before LogoutAll should be revoked")
+	}
+}
+
+func TestAuthService_ValidateToken_RevocationStoreError(t *testing.T) {
+	authSvc, userRepo, sessionRepo, tenantRepo, eventRepo := setupAuthService(t)
+	ctx := context.Background()
+	authSvc = NewAuthService(AuthServiceConfig{
+		UserRepo:        userRepo,
+		SessionRepo:     sessionRepo,
+		EventRepo:       eventRepo,
+		TenantRepo:      tenantRepo,
+		TokenService:    authSvc.tokenService,
+		RevocationStore: failingRevocationStore{},
+	})
+
+	pair, _, err := authSvc.tokenService.GenerateTokenPair(&domain.User{ID: uuid.New(), Email: "test@example.com"}, uuid.New(), uuid.New(), domain.RoleWaiter, domain.RoleWaiter.Permissions())
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+
+	_, err = authSvc.ValidateToken(ctx, pair.AccessToken)
+	if err == nil || errors.Is(err, domain.ErrTokenInvalid) || errors.Is(err, domain.ErrTokenRevoked) {
+		t.Errorf("Expected an internal error when the revocation list is unavailable, got %v", err)
+	}
+}
+
+// failingRevocationStore fails every call, for testing that token checks fail closed.
+type failingRevocationStore struct{}
+
+func (failingRevocationStore) RevokeToken(ctx context.Context, token *domain.RevokedAccessToken) error {
+	return errors.New("store unavailable")
+}
+func (failingRevocationStore) RevokeUser(ctx context.Context, revocation *domain.UserTokenRevocation) error {
+	return errors.New("store unavailable")
+}
+func (failingRevocationStore) IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+func (failingRevocationStore) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, errors.New("store unavailable")
+}
+
+// loginMultiTenant logs in a user who is a waiter in one tenant and a
+// cashier in another, selecting the first.
+func loginMultiTenant(t *testing.T, authSvc *AuthService, userRepo *mock.MockUserRepository, tenantRepo *mock.MockTenantRepository) (*LoginResponse, *domain.Tenant, *domain.Tenant) {
+	t.Helper()
+
+	passwordHash, _ := NewPasswordService().Hash("Password123!")
+	from := &domain.Tenant{ID: uuid.New(), Name: "Restaurant 1", Slug: "r1", IsActive: true}
+	to := &domain.Tenant{ID: uuid.New(), Name: "Restaurant 2", Slug: "r2", IsActive: true}
+	userID := uuid.New()
+	userRepo.AddUser(&domain.User{
+		ID:           userID,
+		Email:        "test@example.com",
+		PasswordHash: passwordHash,
+		IsActive:     true,
+		TenantRoles: []domain.UserTenantRole{
+			{UserID: userID, TenantID: from.ID, Role: domain.RoleWaiter, Tenant: *from},
+			{UserID: userID, TenantID: to.ID, Role: domain.RoleCashier, Tenant: *to},
+		},
+	})
+	tenantRepo.AddTenant(from)
+	tenantRepo.AddTenant(to)
+
+	resp, err := authSvc.Login(context.Background(), LoginRequest{Email: "test@example.com", Password: "Password123!", TenantID: &from.ID})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	return resp, from, to
+}
+
+func TestAuthService_SwitchTenant_Success(t *testing.T) {
+	authSvc, userRepo, sessionRepo, tenantRepo, eventRepo := setupAuthService(t)
+	ctx := context.Background()
+	login, from, to := loginMultiTenant(t, authSvc, userRepo, tenantRepo)
+	oldSessionID := sessionIDFromToken(t, authSvc, login.TokenPair.AccessToken)
+
+	resp, err := authSvc.SwitchTenant(ctx, SwitchTenantRequest{
+		UserID:    login.User.ID,
+		SessionID: oldSessionID,
+		TenantID:  to.ID,
+		IPAddress: "127.0.0.1",
+	})
+	if err != nil {
+		t.Fatalf("SwitchTenant failed: %v", err)
+	}
+	if resp.TenantID != to.ID || resp.Role != domain.RoleCashier {
+		t.Errorf("switched to %s as %s, want %s as cashier", resp.TenantID, resp.Role, to.ID)
+	}
+
+	claims, err := authSvc.ValidateToken(ctx, resp.TokenPair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if claims.TenantID != to.ID || claims.Role != domain.RoleCashier {
+		t.Errorf("claims tenant/role = %s/%s, want %s/cashier", claims.TenantID, claims.Role, to.ID)
+	}
+
+	oldSession, _ := sessionRepo.FindByID(ctx, oldSessionID)
+	if !oldSession.IsRevoked() {
+		t.Error("the session switched away from should be revoked")
+	}
+	newSession, err := sessionRepo.FindByID(ctx, sessionIDFromToken(t, authSvc, resp.TokenPair.AccessToken))
+	if err != nil {
+		t.Fatalf("new session lookup failed: %v", err)
+	}
+	if newSession.TenantID != to.ID || !newSession.IsValid() {
+		t.Errorf("new session = %+v, want a valid session in %s", newSession, to.ID)
+	}
+
+	// The new refresh token stays in the target tenant
+	refreshed, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: resp.TokenPair.RefreshToken})
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if claims, _ := authSvc.ValidateToken(ctx, refreshed.AccessToken); claims.TenantID != to.ID {
+		t.Errorf("refreshed tenant = %s, want %s", claims.TenantID, to.ID)
+	}
+
+	var switched *domain.AuthEvent
+	for _, e := range eventRepo.GetEvents() {
+		if e.EventType == domain.EventTenantSwitched {
+			switched = e
+		}
+	}
+	if switched == nil {
+		t.Fatal("expected a tenant_switched event")
+	}
+	if switched.TenantID == nil || *switched.TenantID != to.ID || switched.Metadata["from_tenant_id"] != from.ID.String() {
+		t.Errorf("unexpected tenant_switched event: %+v", switched)
+	}
+}
+
+func TestAuthService_SwitchTenant_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(req *SwitchTenantRequest, from, to *domain.Tenant, tenantRepo *mock.MockTenantRepository)
+		wantErr error
+	}{
+		{"same tenant", func(req *SwitchTenantRequest, from, to *domain.Tenant, _ *mock.MockTenantRepository) {
+			req.TenantID = from.ID
+		}, domain.ErrSameTenant},
+		{"not a member", func(req *SwitchTenantRequest, _, _ *domain.Tenant, _ *mock.MockTenantRepository) {
+			req.TenantID = uuid.New()
+		}, domain.ErrUserNotInTenant},
+		{"inactive tenant", func(_ *SwitchTenantRequest, _, to *domain.Tenant, tenantRepo *mock.MockTenantRepository) {
+			to.IsActive = false
+			tenantRepo.AddTenant(to)
+		}, domain.ErrTenantInactive},
+		{"someone else's session", func(req *SwitchTenantRequest, _, _ *domain.Tenant, _ *mock.MockTenantRepository) {
+			req.UserID = uuid.New()
+		}, domain.ErrSessionRevoked},
+		{"unknown session", func(req *SwitchTenantRequest, _, _ *domain.Tenant, _ *mock.MockTenantRepository) {
+			req.SessionID = uuid.New()
+		}, domain.ErrSessionRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authSvc, userRepo, _, tenantRepo, _ := setupAuthService(t)
+			login, from, to := loginMultiTenant(t, authSvc, userRepo, tenantRepo)
+
+			req := SwitchTenantRequest{
+				UserID:    login.User.ID,
+				SessionID: sessionIDFromToken(t, authSvc, login.TokenPair.AccessToken),
+				TenantID:  to.ID,
+			}
+			tt.modify(&req, from, to, tenantRepo)
+
+			if _, err := authSvc.SwitchTenant(context.Background(), req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SwitchTenant error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthService_SwitchTenant_RevokedSession(t *testing.T) {
+	authSvc, userRepo, _, tenantRepo, _ := setupAuthService(t)
+	ctx := context.Background()
+	login, _, to := loginMultiTenant(t, authSvc, userRepo, tenantRepo)
+	sessionID := sessionIDFromToken(t, authSvc, login.TokenPair.AccessToken)
+
+	if err := authSvc.Logout(ctx, login.TokenPair.RefreshToken, "127.0.0.1"); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+
+	_, err := authSvc.SwitchTenant(ctx, SwitchTenantRequest{UserID: login.User.ID, SessionID: sessionID, TenantID: to.ID})
+	if !errors.Is(err, domain.ErrSessionRevoked) {
+		t.Errorf("SwitchTenant error = %v, want ErrSessionRevoked", err)
+	}
+}
+
+func TestAuthService_SwitchTenant_OldRefreshTokenIsReuse(t *testing.T) {
+	authSvc, userRepo, sessionRepo, tenantRepo, eventRepo := setupAuthService(t)
+	ctx := context.Background()
+	login, _, to := loginMultiTenant(t, authSvc, userRepo, tenantRepo)
+	oldSessionID := sessionIDFromToken(t, authSvc, login.TokenPair.AccessToken)
+
+	resp, err := authSvc.SwitchTenant(ctx, SwitchTenantRequest{UserID: login.User.ID, SessionID: oldSessionID, TenantID: to.ID})
+	if err != nil {
+		t.Fatalf("SwitchTenant failed: %v", err)
+	}
+	newSession, _ := sessionRepo.FindByID(ctx, sessionIDFromToken(t, authSvc, resp.TokenPair.AccessToken))
+	if newSession.FamilyID != oldSessionID || newSession.ParentID == nil || *newSession.ParentID != oldSessionID {
+		t.Errorf("new session family/parent = %s/%v, want the old session %s", newSession.FamilyID, newSession.ParentID, oldSessionID)
+	}
+
+	// The refresh token from before the switch was rotated, so replaying it
+	// revokes the switched session too
+	if _, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: login.TokenPair.RefreshToken}); err != domain.ErrSessionRevoked {
+		t.Fatalf("Refresh with the pre-switch token error = %v, want ErrSessionRevoked", err)
+	}
+	if _, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: resp.TokenPair.RefreshToken}); err != domain.ErrSessionRevoked {
+		t.Errorf("Refresh with the switched token error = %v, want ErrSessionRevoked", err)
+	}
+	if len(reuseEvents(eventRepo.GetEvents())) != 1 {
+		t.Error("expected a refresh token reuse event")
+	}
+}
+
+func TestAuthService_Impersonate(t *testing.T) {
+	authSvc, userRepo, _, _, eventRepo := setupAuthService(t)
+	ctx := context.Background()
+
+	tenantID, adminID := uuid.New(), uuid.New()
+	waiter := &domain.User{
+		ID:          uuid.New(),
+		Email:       "waiter@example.com",
+		IsActive:    true,
+		TenantRoles: []domain.UserTenantRole{{TenantID: tenantID, Role: domain.RoleWaiter}},
+	}
+	userRepo.AddUser(waiter)
+
+	resp, err := authSvc.Impersonate(ctx, ImpersonateRequest{
+		ActorID:   adminID,
+		ActorRole: domain.RoleAdmin,
+		UserID:    waiter.ID,
+		TenantID:  tenantID,
+		Reason:    "Ticket 4521: waiter can't see table 12",
+		IPAddress: "127.0.0.1",
+	})
+	if err != nil {
+		t.Fatalf("Impersonate failed: %v", err)
+	}
+	if resp.TokenPair.RefreshToken != "" {
+		t.Error("Impersonation should not issue a refresh token")
+	}
+	if d := time.Until(resp.TokenPair.ExpiresAt); d <= 0 || d > domain.ImpersonationTTL {
+		t.Errorf("token expires in %v, want within %v", d, domain.ImpersonationTTL)
+	}
+
+	claims, err := authSvc.ValidateToken(ctx, resp.TokenPair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if userID, _ := claims.GetUserID(); userID != waiter.ID || claims.Role != domain.RoleWaiter {
+		t.Errorf("token is for %s as %s, want the waiter", userID, claims.Role)
+	}
+	if actorID, ok := claims.GetActorID(); !ok || actorID != adminID {
+		t.Errorf("GetActorID() = %v, %v, want the admin", actorID, ok)
+	}
+	if !slices.Equal(claims.GetPermissions(), domain.RoleWaiter.Permissions()) {
+		t.Errorf("perms = %v, want the waiter's", claims.GetPermissions())
+	}
+
+	var started *domain.AuthEvent
+	for _, e := range eventRepo.GetEvents() {
+		if e.EventType == domain.EventImpersonationStarted {
+			started = e
+		}
+	}
+	if started == nil || *started.UserID != waiter.ID || started.ActorID == nil || *started.ActorID != adminID {
+		t.Errorf("impersonation_started event = %+v, want the waiter impersonated by the admin", started)
+	}
+}
+
+func TestAuthService_Impersonate_Errors(t *testing.T) {
+	authSvc, userRepo, _, _, _ := setupAuthService(t)
+	ctx := context.Background()
+
+	tenantID, managerID := uuid.New(), uuid.New()
+	addUser := func(role domain.Role, active bool) uuid.UUID {
+		user := &domain.User{
+			ID:          uuid.New(),
+			Email:       uuid.NewString() + "@example.com",
+			IsActive:    active,
+			TenantRoles: []domain.UserTenantRole{{TenantID: tenantID, Role: role}},
+		}
+		userRepo.AddUser(user)
+		return user.ID
+	}
+	waiter := addUser(domain.RoleWaiter, true)
+	otherManager := addUser(domain.RoleManager, true)
+	disabled := addUser(domain.RoleWaiter, false)
+
+	tests := []struct {
+		name     string
+		userID   uuid.UUID
+		tenantID uuid.UUID
+		wantErr  error
+	}{
+		{"self", managerID, tenantID, domain.ErrImpersonateSelf},
+		{"unknown user", uuid.New(), tenantID, domain.ErrUserNotFound},
+		{"user in another tenant", waiter, uuid.New(), domain.ErrUserNotFound},
+		{"same rank", otherManager, tenantID, domain.ErrCannotManageRole},
+		{"disabled user", disabled, tenantID, domain.ErrAccountDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authSvc.Impersonate(ctx, ImpersonateRequest{ActorID: managerID, ActorRole: domain.RoleManager, UserID: tt.userID, TenantID: tt.tenantID})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Impersonate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthService_ValidateToken_ImpersonationEndsWithActor(t *testing.T) {
+	authSvc, store, userRepo, _ := setupAuthServiceWithRevocations(t)
+	ctx := context.Background()
+
+	tenantID, adminID := uuid.New(), uuid.New()
+	waiter := &domain.User{ID: uuid.New(), Email: "waiter@example.com", IsActive: true, TenantRoles: []domain.UserTenantRole{{TenantID: tenantID, Role: domain.RoleWaiter}}}
+	userRepo.AddUser(waiter)
+
+	resp, err := authSvc.Impersonate(ctx, ImpersonateRequest{ActorID: adminID, ActorRole: domain.RoleAdmin, UserID: waiter.ID, TenantID: tenantID})
+	if err != nil {
+		t.Fatalf("Impersonate failed: %v", err)
+	}
+
+	// Revoking the admin's tokens (logout everywhere, deactivation) ends
+	// their impersonations too
+	store.RevokeUser(ctx, &domain.UserTokenRevocation{UserID: adminID, RevokedBefore: time.Now().Add(2 * time.Second), ExpiresAt: time.Now().Add(time.Hour)})
+	if _, err := authSvc.ValidateToken(ctx, resp.TokenPair.AccessToken); !errors.Is(err, domain.ErrTokenRevoked) {
+		t.Errorf("ValidateToken after the actor's revocation = %v, want ErrTokenRevoked", err)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/solobueno/erp/pkg/bloom"
+)
+
+// breachedPasswordFalsePositiveRate is how often a password that isn't in
+// the list is reported as breached anyway. Rejecting one good password in
+// a thousand costs the user a retry; it never lets a breached one through.
+const breachedPasswordFalsePositiveRate = 0.001
+
+// bundledBreachedPasswords is the list checked when no other is configured:
+// the most common passwords from public breach corpora, lowercased, with
+// their usual numeric and year suffixes.
+//
+//go:embed data/breached_passwords.txt
+var bundledBreachedPasswords []byte
+
+// BreachedPasswords reports whether a password is known from breaches and
+// so must not be used.
+type BreachedPasswords interface {
+	Contains(password string) bool
+}
+
+// BreachedPasswordFilter is a BreachedPasswords held in a Bloom filter, so
+// a large list can be checked offline in little memory. Matching ignores
+// case, so "Dragon2024" is caught by "dragon2024".
+type BreachedPasswordFilter struct {
+	filter *bloom.Filter
+}
+
+// NewBreachedPasswordFilter builds a filter from a newline-separated list
+// of passwords. Blank lines are skipped.
+func NewBreachedPasswordFilter(r io.Reader) (*BreachedPasswordFilter, error) {
+	var passwords []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if password := strings.TrimSpace(scanner.Text()); password != "" {
+			passwords = append(passwords, strings.ToLower(password))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read breached password list: %w", err)
+	}
+
+	filter := bloom.New(len(passwords), breachedPasswordFalsePositiveRate)
+	for _, password := range passwords {
+		filter.Add([]byte(password))
+	}
+	return &BreachedPasswordFilter{filter: filter}, nil
+}
+
+// Contains reports whether password is (probably) in the list.
+func (f *BreachedPasswordFilter) Contains(password string) bool {
+	return f.filter.Test([]byte(strings.ToLower(password)))
+}
+
+// Len returns the number of passwords in the list.
+func (f *BreachedPasswordFilter) Len() int {
+	return f.filter.Len()
+}
+
+// BundledBreachedPasswords returns the filter of the list bundled with the
+// module. It is built once, on first use.
+var BundledBreachedPasswords = sync.OnceValue(func() *BreachedPasswordFilter {
+	filter, err := NewBreachedPasswordFilter(bytes.NewReader(bundledBreachedPasswords))
+	if err != nil {
+		panic(err) // Reading from memory can't fail
+	}
+	return filter
+})
@@ -8,18 +8,46 @@ import (
 )
 
 // Emailer defines the interface for sending transactional auth emails
-// (temporary passwords, tenant-link notifications, password resets).
+// (invitations, ownership transfers, password resets, email verification
+// and changes, security alerts).
 // Real delivery (AWS SES per the project's stack) is a future integration;
 // LogEmailer is the dev-safe default until that adapter is wired in.
 type Emailer interface {
-	// SendTemporaryPassword notifies a newly created user of their temporary password.
-	SendTemporaryPassword(ctx context.Context, toEmail, tempPassword string) error
+	// SendInvitation sends the plaintext invite token for joining a tenant.
+	// existingAccount tells the template whether to ask the invitee to set a
+	// password or to confirm the account they already have.
+	SendInvitation(ctx context.Context, toEmail string, tenantID uuid.UUID, inviteToken string, existingAccount bool) error
 
-	// SendTenantLinked notifies an existing user that a new tenant role was added to their account.
-	SendTenantLinked(ctx context.Context, toEmail string, tenantID uuid.UUID) error
+	// SendOwnershipTransferRequest sends the plaintext confirmation token to
+	// the member a tenant owner wants to hand ownership to.
+	SendOwnershipTransferRequest(ctx context.Context, toEmail string, tenantID uuid.UUID, transferToken string) error
+
+	// SendOwnershipTransferred tells both parties that a tenant's ownership
+	// changed hands. newOwner distinguishes the recipient from the previous owner.
+	SendOwnershipTransferred(ctx context.Context, toEmail string, tenantID uuid.UUID, newOwner bool) error
 
 	// SendPasswordReset sends the plaintext reset token to a user who requested a password reset.
 	SendPasswordReset(ctx context.Context, toEmail, resetToken string) error
+
+	// SendEmailVerification sends the plaintext token confirming the user
+	// receives mail at their current address.
+	SendEmailVerification(ctx context.Context, toEmail, verifyToken string) error
+
+	// SendEmailChangeConfirmation sends the plaintext token to the new
+	// address a user asked to move their account to.
+	SendEmailChangeConfirmation(ctx context.Context, toEmail, changeToken string) error
+
+	// SendEmailChanged tells the previous address that the account moved to
+	// newEmail, with the plaintext token that undoes the change.
+	SendEmailChanged(ctx context.Context, toEmail, newEmail, revertToken string) error
+
+	// SendRefreshTokenReuse warns a user that an already-used refresh token was
+	// replayed and the affected sessions were signed out.
+	SendRefreshTokenReuse(ctx context.Context, toEmail string) error
+
+	// SendAccountUnlock sends the plaintext token that lifts the lockout of
+	// a user's account after repeated failed logins.
+	SendAccountUnlock(ctx context.Context, toEmail, unlockToken string) error
 }
 
 // LogEmailer is a stub Emailer that logs instead of sending real email.
@@ -31,13 +59,18 @@ func NewLogEmailer() *LogEmailer {
 	return &LogEmailer{}
 }
 
-func (e *LogEmailer) SendTemporaryPassword(ctx context.Context, toEmail, tempPassword string) error {
-	log.Printf("[email stub] temporary password for %s: %s", toEmail, tempPassword)
+func (e *LogEmailer) SendInvitation(ctx context.Context, toEmail string, tenantID uuid.UUID, inviteToken string, existingAccount bool) error {
+	log.Printf("[email stub] invitation to tenant %s for %s (existing account: %t): %s", tenantID, toEmail, existingAccount, inviteToken)
 	return nil
 }
 
-func (e *LogEmailer) SendTenantLinked(ctx context.Context, toEmail string, tenantID uuid.UUID) error {
-	log.Printf("[email stub] %s was added to tenant %s", toEmail, tenantID)
+func (e *LogEmailer) SendOwnershipTransferRequest(ctx context.Context, toEmail string, tenantID uuid.UUID, transferToken string) error {
+	log.Printf("[email stub] ownership transfer of tenant %s to %s: %s", tenantID, toEmail, transferToken)
+	return nil
+}
+
+func (e *LogEmailer) SendOwnershipTransferred(ctx context.Context, toEmail string, tenantID uuid.UUID, newOwner bool) error {
+	log.Printf("[email stub] ownership of tenant %s transferred, notifying %s (new owner: %t)", tenantID, toEmail, newOwner)
 	return nil
 }
 
@@ -46,4 +79,29 @@ func (e *LogEmailer) SendPasswordReset(ctx context.Context, toEmail, resetToken
 	return nil
 }
 
+func (e *LogEmailer) SendEmailVerification(ctx context.Context, toEmail, verifyToken string) error {
+	log.Printf("[email stub] email verification token for %s: %s", toEmail, verifyToken)
+	return nil
+}
+
+func (e *LogEmailer) SendEmailChangeConfirmation(ctx context.Context, toEmail, changeToken string) error {
+	log.Printf("[email stub] email change confirmation token for %s: %s", toEmail, changeToken)
+	return nil
+}
+
+func (e *LogEmailer) SendEmailChanged(ctx context.Context, toEmail, newEmail, revertToken string) error {
+	log.Printf("[email stub] account email changed from %s to %s; undo token: %s", toEmail, newEmail, revertToken)
+	return nil
+}
+
+func (e *LogEmailer) SendRefreshTokenReuse(ctx context.Context, toEmail string) error {
+	log.Printf("[email stub] refresh token reuse detected for %s; sessions signed out", toEmail)
+	return nil
+}
+
+func (e *LogEmailer) SendAccountUnlock(ctx context.Context, toEmail, unlockToken string) error {
+	log.Printf("[email stub] account unlock token for %s: %s", toEmail, unlockToken)
+	return nil
+}
+
 var _ Emailer = (*LogEmailer)(nil)
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+)
+
+// LockoutService manages tenants' account lockout policies and the devices
+// users have logged in from, which decide how quickly repeated wrong
+// passwords delay further logins. A user in several tenants follows the
+// strictest combination of their policies.
+type LockoutService struct {
+	policies    repository.LockoutPolicyRepository
+	devices     repository.KnownDeviceRepository
+	roleRepo    repository.UserTenantRoleRepository
+	eventRepo   repository.AuthEventRepository
+	passwordSvc *PasswordService
+}
+
+// LockoutServiceConfig holds configuration for LockoutService.
+type LockoutServiceConfig struct {
+	// Policies stores tenants' policies. If nil, every tenant uses
+	// domain.DefaultLockoutPolicy.
+	Policies repository.LockoutPolicyRepository
+	// Devices remembers the devices users have logged in from. If nil,
+	// every device is treated as unknown.
+	Devices repository.KnownDeviceRepository
+	// RoleRepo finds the tenants whose policies apply to a user.
+	RoleRepo  repository.UserTenantRoleRepository
+	EventRepo repository.AuthEventRepository
+}
+
+// NewLockoutService creates a new LockoutService.
+func NewLockoutService(cfg LockoutServiceConfig) *LockoutService {
+	return &LockoutService{
+		policies:    cfg.Policies,
+		devices:     cfg.Devices,
+		roleRepo:    cfg.RoleRepo,
+		eventRepo:   cfg.EventRepo,
+		passwordSvc: NewPasswordService(),
+	}
+}
+
+// GetPolicy returns a tenant's lockout policy, or the default policy if it
+// hasn't set one.
+func (s *LockoutService) GetPolicy(ctx context.Context, tenantID uuid.UUID) (*domain.LockoutPolicy, error) {
+	if s.policies == nil {
+		policy := domain.DefaultLockoutPolicy()
+		policy.TenantID = tenantID
+		return &policy, nil
+	}
+	policy, err := s.policies.FindByTenant(ctx, tenantID)
+	if errors.Is(err, domain.ErrLockoutPolicyNotFound) {
+		defaults := domain.DefaultLockoutPolicy()
+		defaults.TenantID = tenantID
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get lockout policy: %w", err)
+	}
+	return policy, nil
+}
+
+// SaveLockoutPolicyRequest contains a tenant's new lockout policy.
+type SaveLockoutPolicyRequest struct {
+	TenantID               uuid.UUID
+	UpdatedBy              uuid.UUID
+	UnknownDeviceThreshold int
+	KnownDeviceThreshold   int
+	BaseDelaySeconds       int
+	MaxDelaySeconds        int
+	IPAddress              string
+	UserAgent              string
+}
+
+// SavePolicy creates or replaces a tenant's lockout policy. It applies from
+// the members' next failed login; current lockouts run their course.
+func (s *LockoutService) SavePolicy(ctx context.Context, req SaveLockoutPolicyRequest) (*domain.LockoutPolicy, error) {
+	if s.policies == nil {
+		return nil, errors.New("save lockout policy: no policy repository configured")
+	}
+
+	policy, err := s.policies.FindByTenant(ctx, req.TenantID)
+	creating := errors.Is(err, domain.ErrLockoutPolicyNotFound)
+	if err != nil && !creating {
+		return nil, fmt.Errorf("save lockout policy: lookup: %w", err)
+	}
+	if creating {
+		policy = &domain.LockoutPolicy{ID: uuid.New(), TenantID: req.TenantID}
+	}
+
+	policy.UnknownDeviceThreshold = req.UnknownDeviceThreshold
+	policy.KnownDeviceThreshold = req.KnownDeviceThreshold
+	policy.BaseDelaySeconds = req.BaseDelaySeconds
+	policy.MaxDelaySeconds = req.MaxDelaySeconds
+	policy.UpdatedBy = &req.UpdatedBy
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	if creating {
+		err = s.policies.Create(ctx, policy)
+	} else {
+		err = s.policies.Update(ctx, policy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("save lockout policy: %w", err)
+	}
+
+	event := newAuthEvent(ctx, domain.EventLockoutPolicyUpdated, &req.UpdatedBy, &req.TenantID, req.IPAddress, req.UserAgent)
+	event.Metadata = map[string]interface{}{
+		"unknown_device_threshold": policy.UnknownDeviceThreshold,
+		"known_device_threshold":   policy.KnownDeviceThreshold,
+		"base_delay_seconds":       policy.BaseDelaySeconds,
+		"max_delay_seconds":        policy.MaxDelaySeconds,
+	}
+	_ = s.eventRepo.Create(ctx, event)
+
+	return policy, nil
+}
+
+// PolicyForUser returns the strictest combination of the policies of every
+// tenant the user belongs to, or the default policy for a user in none.
+func (s *LockoutService) PolicyForUser(ctx context.Context, userID uuid.UUID) (domain.LockoutPolicy, error) {
+	if s.roleRepo == nil || s.policies == nil {
+		return domain.DefaultLockoutPolicy(), nil
+	}
+	roles, err := s.roleRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return domain.LockoutPolicy{}, fmt.Errorf("lockout policy: list tenants: %w", err)
+	}
+	if len(roles) == 0 {
+		return domain.DefaultLockoutPolicy(), nil
+	}
+	tenantIDs := make([]uuid.UUID, 0, len(roles))
+	for _, role := range roles {
+		tenantIDs = append(tenantIDs, role.TenantID)
+	}
+
+	policies, err := s.policies.ListByTenants(ctx, tenantIDs)
+	if err != nil {
+		return domain.LockoutPolicy{}, fmt.Errorf("lockout policy: lookup: %w", err)
+	}
+	byTenant := make(map[uuid.UUID]domain.LockoutPolicy, len(policies))
+	for _, p := range policies {
+		byTenant[p.TenantID] = *p
+	}
+
+	var combined domain.LockoutPolicy
+	for i, tenantID := range tenantIDs {
+		policy, ok := byTenant[tenantID]
+		if !ok {
+			policy = domain.DefaultLockoutPolicy()
+		}
+		if i == 0 {
+			combined = policy
+		} else {
+			combined = combined.Strictest(policy)
+		}
+	}
+	combined.ID, combined.TenantID, combined.UpdatedBy = uuid.Nil, uuid.Nil, nil
+	return combined, nil
+}
+
+// RecordFailure counts a wrong password for the user under their policy,
+// locking further logins from the same kind of device once its threshold
+// is reached. The caller saves the user. Returns the end of the lock if
+// this failure set one.
+func (s *LockoutService) RecordFailure(ctx context.Context, user *domain.User, knownDevice bool) (*time.Time, error) {
+	policy, err := s.PolicyForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return user.RecordLoginFailure(policy, knownDevice), nil
+}
+
+// IsKnownDevice reports whether a device token was handed to this user at
+// an earlier login.
+func (s *LockoutService) IsKnownDevice(ctx context.Context, userID uuid.UUID, deviceToken string) (bool, error) {
+	if s.devices == nil || deviceToken == "" {
+		return false, nil
+	}
+	device, err := s.devices.FindByToken(ctx, s.passwordSvc.HashResetToken(deviceToken))
+	if errors.Is(err, domain.ErrKnownDeviceNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("known device lookup: %w", err)
+	}
+	return device.UserID == userID, nil
+}
+
+// RememberDevice marks the device a user just logged in from as known and
+// returns the device token it should present on later logins: the one it
+// already has, or a new one. Returns "" if devices aren't remembered.
+func (s *LockoutService) RememberDevice(ctx context.Context, userID uuid.UUID, deviceToken, userAgent string) (string, error) {
+	if s.devices == nil {
+		return "", nil
+	}
+	if deviceToken != "" {
+		device, err := s.devices.FindByToken(ctx, s.passwordSvc.HashResetToken(deviceToken))
+		if err != nil && !errors.Is(err, domain.ErrKnownDeviceNotFound) {
+			return "", fmt.Errorf("remember device: lookup: %w", err)
+		}
+		if err == nil && device.UserID == userID {
+			if err := s.devices.Touch(ctx, device.ID, time.Now()); err != nil {
+				return "", fmt.Errorf("remember device: %w", err)
+			}
+			return deviceToken, nil
+		}
+	}
+
+	plainToken, tokenHash, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return "", fmt.Errorf("remember device: generate token: %w", err)
+	}
+	device := &domain.KnownDevice{
+		ID:         uuid.New(),
+		UserID:     userID,
+		TokenHash:  tokenHash,
+		DeviceInfo: userAgent,
+		LastSeenAt: time.Now(),
+	}
+	if err := s.devices.Create(ctx, device); err != nil {
+		return "", fmt.Errorf("remember device: %w", err)
+	}
+	return plainToken, nil
+}
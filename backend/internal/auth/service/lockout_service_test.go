@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+)
+
+// lockoutTestEnv bundles a LockoutService over mock repositories, with a
+// user who is a member of two tenants.
+type lockoutTestEnv struct {
+	svc       *LockoutService
+	policies  *mock.MockLockoutPolicyRepository
+	devices   *mock.MockKnownDeviceRepository
+	roleRepo  *mock.MockUserTenantRoleRepository
+	eventRepo *mock.MockAuthEventRepository
+	user      *domain.User
+	tenantA   uuid.UUID
+	tenantB   uuid.UUID
+}
+
+func setupLockoutService(t *testing.T) *lockoutTestEnv {
+	t.Helper()
+
+	env := &lockoutTestEnv{
+		policies:  mock.NewMockLockoutPolicyRepository(),
+		devices:   mock.NewMockKnownDeviceRepository(),
+		roleRepo:  mock.NewMockUserTenantRoleRepository(),
+		eventRepo: mock.NewMockAuthEventRepository(),
+		tenantA:   uuid.New(),
+		tenantB:   uuid.New(),
+	}
+	env.svc = NewLockoutService(LockoutServiceConfig{
+		Policies:  env.policies,
+		Devices:   env.devices,
+		RoleRepo:  env.roleRepo,
+		EventRepo: env.eventRepo,
+	})
+
+	passwordHash, _ := NewPasswordService().Hash("Current123!")
+	env.user = &domain.User{ID: uuid.New(), Email: "ana@example.com", PasswordHash: passwordHash, IsActive: true,
+		TenantRoles: []domain.UserTenantRole{{TenantID: env.tenantA, Role: domain.RoleWaiter}}}
+	env.roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: env.user.ID, TenantID: env.tenantA, Role: domain.RoleWaiter})
+	env.roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: env.user.ID, TenantID: env.tenantB, Role: domain.RoleWaiter})
+	return env
+}
+
+// loginAuthService returns the test AuthService logging users in under the
+// env's lockout service, with env.user and tenant A registered.
+func (env *lockoutTestEnv) loginAuthService(t *testing.T) *AuthService {
+	t.Helper()
+	authSvc, userRepo, _, tenantRepo, _ := setupAuthService(t)
+	authSvc.lockout = env.svc
+	tenantRepo.AddTenant(&domain.Tenant{ID: env.tenantA, Name: "Casa Ana", Slug: "casa-ana", IsActive: true})
+	userRepo.AddUser(env.user)
+	return authSvc
+}
+
+func TestLockoutService_GetPolicy_DefaultsWhenUnset(t *testing.T) {
+	env := setupLockoutService(t)
+
+	policy, err := env.svc.GetPolicy(context.Background(), env.tenantA)
+	if err != nil {
+		t.Fatalf("GetPolicy failed: %v", err)
+	}
+	want := domain.DefaultLockoutPolicy()
+	if policy.UnknownDeviceThreshold != want.UnknownDeviceThreshold || policy.MaxDelaySeconds != want.MaxDelaySeconds || policy.TenantID != env.tenantA {
+		t.Errorf("GetPolicy = %+v, want the default policy", policy)
+	}
+}
+
+func TestLockoutService_SavePolicy(t *testing.T) {
+	env := setupLockoutService(t)
+	ctx := context.Background()
+
+	req := SaveLockoutPolicyRequest{
+		TenantID:               env.tenantA,
+		UpdatedBy:              uuid.New(),
+		UnknownDeviceThreshold: 3,
+		KnownDeviceThreshold:   8,
+		BaseDelaySeconds:       30,
+		MaxDelaySeconds:        600,
+	}
+	if _, err := env.svc.SavePolicy(ctx, req); err != nil {
+		t.Fatalf("SavePolicy (create) failed: %v", err)
+	}
+
+	req.MaxDelaySeconds = 3600
+	saved, err := env.svc.SavePolicy(ctx, req)
+	if err != nil {
+		t.Fatalf("SavePolicy (update) failed: %v", err)
+	}
+
+	found, _ := env.svc.GetPolicy(ctx, env.tenantA)
+	if found.ID != saved.ID || found.UnknownDeviceThreshold != 3 || found.MaxDelaySeconds != 3600 {
+		t.Errorf("GetPolicy after update = %+v", found)
+	}
+	if !hasEventType(env.eventRepo, domain.EventLockoutPolicyUpdated) {
+		t.Error("expected a lockout_policy_updated event")
+	}
+
+	req.MaxDelaySeconds = 10
+	if _, err := env.svc.SavePolicy(ctx, req); !errors.Is(err, domain.ErrLockoutPolicyInvalid) {
+		t.Errorf("SavePolicy with max below base error = %v, want ErrLockoutPolicyInvalid", err)
+	}
+}
+
+func TestLockoutService_PolicyForUser_Strictest(t *testing.T) {
+	env := setupLockoutService(t)
+	env.policies.AddPolicy(&domain.LockoutPolicy{TenantID: env.tenantA, UnknownDeviceThreshold: 3, KnownDeviceThreshold: 20, BaseDelaySeconds: 30, MaxDelaySeconds: 3600})
+
+	policy, err := env.svc.PolicyForUser(context.Background(), env.user.ID)
+	if err != nil {
+		t.Fatalf("PolicyForUser failed: %v", err)
+	}
+	// Tenant B has no policy, so the default applies there
+	if policy.UnknownDeviceThreshold != 3 || policy.KnownDeviceThreshold != 10 || policy.BaseDelaySeconds != 60 || policy.MaxDelaySeconds != 3600 {
+		t.Errorf("PolicyForUser = %+v, want thresholds 3/10 and delays 60s to 1h", policy)
+	}
+}
+
+func TestLockoutService_RememberDevice(t *testing.T) {
+	env := setupLockoutService(t)
+	ctx := context.Background()
+
+	token, err := env.svc.RememberDevice(ctx, env.user.ID, "", "Firefox")
+	if err != nil || token == "" {
+		t.Fatalf("RememberDevice = %q, %v; want a new token", token, err)
+	}
+	if known, _ := env.svc.IsKnownDevice(ctx, env.user.ID, token); !known {
+		t.Error("a remembered device should be known")
+	}
+	if known, _ := env.svc.IsKnownDevice(ctx, uuid.New(), token); known {
+		t.Error("a device should only be known to the user who logged in from it")
+	}
+
+	again, err := env.svc.RememberDevice(ctx, env.user.ID, token, "Firefox")
+	if err != nil || again != token {
+		t.Errorf("RememberDevice with a known token = %q, %v; want the same token", again, err)
+	}
+	if n := env.devices.CountForUser(env.user.ID); n != 1 {
+		t.Errorf("devices = %d, want 1", n)
+	}
+
+	if known, _ := env.svc.IsKnownDevice(ctx, env.user.ID, "forged"); known {
+		t.Error("an unknown token should not be a known device")
+	}
+}
+
+func TestAuthService_Login_KnownDeviceNotLockedByUnknownFailures(t *testing.T) {
+	env := setupLockoutService(t)
+	authSvc := env.loginAuthService(t)
+	ctx := context.Background()
+
+	resp, err := authSvc.Login(ctx, LoginRequest{Email: env.user.Email, Password: "Current123!"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	deviceToken := resp.DeviceToken
+	if deviceToken == "" {
+		t.Fatal("expected a device token on login")
+	}
+
+	// Someone else guesses until unknown devices are locked out
+	for i := 0; i < domain.DefaultLockoutPolicy().UnknownDeviceThreshold; i++ {
+		_, _ = authSvc.Login(ctx, LoginRequest{Email: env.user.Email, Password: "Wrong123!"})
+	}
+	if _, err := authSvc.Login(ctx, LoginRequest{Email: env.user.Email, Password: "Current123!"}); !errors.Is(err, domain.ErrAccountLocked) {
+		t.Fatalf("Login from an unknown device error = %v, want ErrAccountLocked", err)
+	}
+
+	resp, err = authSvc.Login(ctx, LoginRequest{Email: env.user.Email, Password: "Current123!", DeviceToken: deviceToken})
+	if err != nil {
+		t.Fatalf("Login from the known device failed: %v", err)
+	}
+	if resp.DeviceToken != deviceToken {
+		t.Errorf("DeviceToken = %q, want the device's existing token", resp.DeviceToken)
+	}
+	if env.user.IsLocked() {
+		t.Error("a successful login should clear the lockout")
+	}
+}
+
+func TestAuthService_Login_ProgressiveDelay(t *testing.T) {
+	env := setupLockoutService(t)
+	env.policies.AddPolicy(&domain.LockoutPolicy{TenantID: env.tenantA, UnknownDeviceThreshold: 2, KnownDeviceThreshold: 10, BaseDelaySeconds: 60, MaxDelaySeconds: 3600})
+	authSvc := env.loginAuthService(t)
+	ctx := context.Background()
+
+	var delays []time.Duration
+	for i := 0; i < 3; i++ {
+		_, _ = authSvc.Login(ctx, LoginRequest{Email: env.user.Email, Password: "Wrong123!"})
+		if env.user.LockedUntil != nil {
+			delays = append(delays, time.Until(*env.user.LockedUntil).Round(time.Minute))
+			// Let the next guess through to see how long it locks for
+			env.user.LockedUntil = nil
+		}
+	}
+	if len(delays) != 2 || delays[0] != time.Minute || delays[1] != 2*time.Minute {
+		t.Errorf("lock delays = %v, want [1m 2m] from the 2nd failure", delays)
+	}
+}
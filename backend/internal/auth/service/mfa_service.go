@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+)
+
+// mfaChallengeTTL is how long a user has to enter their second factor after a successful password check.
+const mfaChallengeTTL = 5 * time.Minute
+
+// maxMFAChallengeAttempts is the number of wrong codes a single challenge tolerates before the user must log in again.
+const maxMFAChallengeAttempts = 5
+
+// recoveryCodeCount is how many single-use recovery codes are issued per enrollment.
+const recoveryCodeCount = 10
+
+// defaultMFAIssuer labels the account in authenticator apps when no issuer is configured.
+const defaultMFAIssuer = "Solobueno ERP"
+
+// MFA verification methods, recorded in auth event metadata.
+const (
+	MFAMethodTOTP         = "totp"
+	MFAMethodRecoveryCode = "recovery_code"
+	MFAMethodPasskey      = "passkey"
+)
+
+// MFAService handles TOTP enrollment, verification, recovery codes and
+// the short-lived login challenges that sit between the password step and
+// token issuance.
+type MFAService struct {
+	mfaRepo       repository.MFARepository
+	challengeRepo repository.MFAChallengeRepository
+	eventRepo     repository.AuthEventRepository
+	passkeys      repository.PasskeyRepository
+	passwordSvc   *PasswordService
+	issuer        string
+}
+
+// MFAServiceConfig holds configuration for MFAService.
+type MFAServiceConfig struct {
+	MFARepo       repository.MFARepository
+	ChallengeRepo repository.MFAChallengeRepository
+	EventRepo     repository.AuthEventRepository
+	// Passkeys lets registered passkeys serve as a second factor. If nil,
+	// only TOTP is offered.
+	Passkeys repository.PasskeyRepository
+	// Issuer labels the account in authenticator apps. Defaults to "Solobueno ERP".
+	Issuer string
+}
+
+// NewMFAService creates a new MFAService.
+func NewMFAService(cfg MFAServiceConfig) *MFAService {
+	issuer := cfg.Issuer
+	if issuer == "" {
+		issuer = defaultMFAIssuer
+	}
+	return &MFAService{
+		mfaRepo:       cfg.MFARepo,
+		challengeRepo: cfg.ChallengeRepo,
+		eventRepo:     cfg.EventRepo,
+		passkeys:      cfg.Passkeys,
+		passwordSvc:   NewPasswordService(),
+		issuer:        issuer,
+	}
+}
+
+// MFAEnrollment contains what a client needs to add the account to an authenticator app.
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// IsEnrolled reports whether the user has a confirmed TOTP factor.
+func (s *MFAService) IsEnrolled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	factor, err := s.mfaRepo.FindFactorByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFANotEnrolled) {
+			return false, nil
+		}
+		return false, fmt.Errorf("mfa: factor lookup: %w", err)
+	}
+	return factor.IsConfirmed(), nil
+}
+
+// Methods returns the second factors the user can complete a login
+// challenge with: MFAMethodTOTP for a confirmed TOTP factor, and
+// MFAMethodPasskey if they have registered a passkey. A user with any
+// method is enrolled in MFA.
+func (s *MFAService) Methods(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var methods []string
+	enrolled, err := s.IsEnrolled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrolled {
+		methods = append(methods, MFAMethodTOTP)
+	}
+	if s.passkeys != nil {
+		count, err := s.passkeys.CountByUser(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("mfa: passkey count: %w", err)
+		}
+		if count > 0 {
+			methods = append(methods, MFAMethodPasskey)
+		}
+	}
+	return methods, nil
+}
+
+// BeginEnrollment generates a new pending TOTP secret for the user. Any
+// earlier pending secret is replaced; a confirmed factor must be disabled
+// before a new one can be enrolled.
+func (s *MFAService) BeginEnrollment(ctx context.Context, userID uuid.UUID, accountName string) (*MFAEnrollment, error) {
+	enrolled, err := s.IsEnrolled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrolled {
+		return nil, domain.ErrMFAAlreadyEnrolled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("mfa enroll: generate secret: %w", err)
+	}
+
+	factor := &domain.MFAFactor{
+		ID:     uuid.New(),
+		UserID: userID,
+		Secret: secret,
+	}
+	if err := s.mfaRepo.SaveFactor(ctx, factor); err != nil {
+		return nil, fmt.Errorf("mfa enroll: save factor: %w", err)
+	}
+
+	return &MFAEnrollment{
+		Secret: secret,
+		URI:    totpURI(s.issuer, accountName, secret),
+	}, nil
+}
+
+// ConfirmEnrollment activates a pending factor once the user proves they can
+// generate codes for it, and returns a fresh set of plaintext recovery codes.
+// The recovery codes are shown once and only their hashes are stored.
+func (s *MFAService) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code, ipAddress string) ([]string, error) {
+	factor, err := s.mfaRepo.FindFactorByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFANotEnrolled) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("mfa confirm: factor lookup: %w", err)
+	}
+	if factor.IsConfirmed() {
+		return nil, domain.ErrMFAAlreadyEnrolled
+	}
+
+	step, ok := validateTOTP(factor.Secret, code, time.Now(), factor.LastUsedStep)
+	if !ok {
+		return nil, domain.ErrMFACodeInvalid
+	}
+
+	if err := s.advanceStep(ctx, factor, step); err != nil {
+		if errors.Is(err, domain.ErrMFACodeInvalid) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("mfa confirm: advance step: %w", err)
+	}
+
+	factor.Confirm()
+	if err := s.mfaRepo.UpdateFactor(ctx, factor); err != nil {
+		return nil, fmt.Errorf("mfa confirm: update factor: %w", err)
+	}
+
+	codes, err := s.issueRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("mfa confirm: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventMFAEnrolled, &userID, ipAddress, map[string]interface{}{
+		"method": MFAMethodTOTP,
+	})
+
+	return codes, nil
+}
+
+// Verify checks a second-factor code for an enrolled user. code may be either
+// a current TOTP code or one of the user's unused recovery codes; the method
+// that matched is returned so callers can audit it.
+func (s *MFAService) Verify(ctx context.Context, userID uuid.UUID, code string) (string, error) {
+	factor, err := s.mfaRepo.FindFactorByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFANotEnrolled) {
+			return "", err
+		}
+		return "", fmt.Errorf("mfa verify: factor lookup: %w", err)
+	}
+	if !factor.IsConfirmed() {
+		return "", domain.ErrMFANotEnrolled
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != totpDigits {
+		if err := s.mfaRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(normalized)); err != nil {
+			if errors.Is(err, domain.ErrMFACodeInvalid) {
+				return "", err
+			}
+			return "", fmt.Errorf("mfa verify: use recovery code: %w", err)
+		}
+		return MFAMethodRecoveryCode, nil
+	}
+
+	step, ok := validateTOTP(factor.Secret, normalized, time.Now(), factor.LastUsedStep)
+	if !ok {
+		return "", domain.ErrMFACodeInvalid
+	}
+	if err := s.advanceStep(ctx, factor, step); err != nil {
+		if errors.Is(err, domain.ErrMFACodeInvalid) {
+			return "", err
+		}
+		return "", fmt.Errorf("mfa verify: advance step: %w", err)
+	}
+	return MFAMethodTOTP, nil
+}
+
+// advanceStep consumes a validated TOTP time step. The stored step only
+// moves forward, so of two requests racing with the same code only one
+// succeeds; the other gets ErrMFACodeInvalid as a replay.
+func (s *MFAService) advanceStep(ctx context.Context, factor *domain.MFAFactor, step int64) error {
+	if err := s.mfaRepo.AdvanceStep(ctx, factor.ID, step); err != nil {
+		return err
+	}
+	factor.LastUsedStep = step
+	return nil
+}
+
+// Disable removes the user's factor and recovery codes after re-verifying a
+// current code. Users whose role requires MFA will be made to enroll again
+// at their next login.
+func (s *MFAService) Disable(ctx context.Context, userID uuid.UUID, code, ipAddress string) error {
+	if _, err := s.verifyForAction(ctx, userID, code, ipAddress, "disable"); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.DeleteForUser(ctx, userID); err != nil {
+		return fmt.Errorf("mfa disable: delete factor: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventMFADisabled, &userID, ipAddress, nil)
+
+	return nil
+}
+
+// RegenerateRecoveryCodes invalidates all of the user's recovery codes and
+// issues a new set, after re-verifying a current code.
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code, ipAddress string) ([]string, error) {
+	if _, err := s.verifyForAction(ctx, userID, code, ipAddress, "regenerate_recovery_codes"); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.issueRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("mfa regenerate recovery codes: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventMFARecoveryCodesReset, &userID, ipAddress, nil)
+
+	return codes, nil
+}
+
+// RemainingRecoveryCodes returns how many unused recovery codes the user has.
+func (s *MFAService) RemainingRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	count, err := s.mfaRepo.CountUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("mfa: count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+// CreateChallenge issues a short-lived challenge for a user who has passed
+// the password step, bound to the tenant they selected. Returns the plain
+// token for the client; only its hash is stored.
+func (s *MFAService) CreateChallenge(ctx context.Context, userID, tenantID uuid.UUID) (string, error) {
+	plainToken, tokenHash, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return "", fmt.Errorf("mfa challenge: generate token: %w", err)
+	}
+
+	challenge := &domain.MFAChallenge{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TenantID:  tenantID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(mfaChallengeTTL),
+	}
+	if err := s.challengeRepo.Create(ctx, challenge); err != nil {
+		return "", fmt.Errorf("mfa challenge: create: %w", err)
+	}
+
+	return plainToken, nil
+}
+
+// FindChallenge looks up a pending challenge by its plain token. Used,
+// expired and exhausted challenges all return ErrMFAChallengeInvalid.
+func (s *MFAService) FindChallenge(ctx context.Context, plainToken string) (*domain.MFAChallenge, error) {
+	challenge, err := s.challengeRepo.FindByToken(ctx, s.passwordSvc.HashResetToken(plainToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrMFAChallengeInvalid) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("mfa challenge: lookup: %w", err)
+	}
+	if !challenge.IsValid() || challenge.Attempts >= maxMFAChallengeAttempts {
+		return nil, domain.ErrMFAChallengeInvalid
+	}
+	return challenge, nil
+}
+
+// RecordFailedAttempt counts a wrong code against a challenge.
+func (s *MFAService) RecordFailedAttempt(ctx context.Context, challengeID uuid.UUID) error {
+	if err := s.challengeRepo.IncrementAttempts(ctx, challengeID); err != nil {
+		return fmt.Errorf("mfa challenge: increment attempts: %w", err)
+	}
+	return nil
+}
+
+// CompleteChallenge marks a challenge as used so it can't be answered twice.
+func (s *MFAService) CompleteChallenge(ctx context.Context, challengeID uuid.UUID) error {
+	if err := s.challengeRepo.MarkUsed(ctx, challengeID); err != nil {
+		if errors.Is(err, domain.ErrMFAChallengeInvalid) {
+			return err
+		}
+		return fmt.Errorf("mfa challenge: mark used: %w", err)
+	}
+	return nil
+}
+
+// verifyForAction re-verifies a code before a sensitive change to the
+// user's own MFA settings, auditing failures.
+func (s *MFAService) verifyForAction(ctx context.Context, userID uuid.UUID, code, ipAddress, action string) (string, error) {
+	method, err := s.Verify(ctx, userID, code)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFACodeInvalid) {
+			s.logEvent(ctx, domain.EventMFAFailed, &userID, ipAddress, map[string]interface{}{
+				"action": action,
+				"reason": "invalid_code",
+			})
+		}
+		return "", err
+	}
+	return method, nil
+}
+
+// issueRecoveryCodes generates recoveryCodeCount new codes, replaces the
+// user's stored set with their hashes, and returns the plaintext codes.
+func (s *MFAService) issueRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	plain := make([]string, recoveryCodeCount)
+	records := make([]*domain.MFARecoveryCode, recoveryCodeCount)
+	for i := range plain {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+		plain[i] = code
+		records[i] = &domain.MFARecoveryCode{
+			ID:       uuid.New(),
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(normalizeRecoveryCode(code)),
+		}
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, records); err != nil {
+		return nil, fmt.Errorf("store recovery codes: %w", err)
+	}
+	return plain, nil
+}
+
+// generateRecoveryCode returns a random code formatted as "xxxxx-xxxxx".
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	code := strings.ToLower(totpEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode strips the formatting users may or may not type.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+// hashRecoveryCode hashes a normalized recovery code for storage and lookup.
+func hashRecoveryCode(code string) string {
+	hash := sha256.Sum256([]byte(code))
+	return base64.URLEncoding.EncodeToString(hash[:])
+}
+
+// logEvent logs an MFA management event. These are user-level (not
+// tenant-scoped) since a factor protects every tenant the user belongs to.
+func (s *MFAService) logEvent(ctx context.Context, eventType domain.AuthEventType, userID *uuid.UUID, ipAddress string, metadata map[string]interface{}) {
+	event := newAuthEvent(ctx, eventType, userID, nil, ipAddress, "")
+	if metadata != nil {
+		event.Metadata = metadata
+	}
+	_ = s.eventRepo.Create(ctx, event)
+}
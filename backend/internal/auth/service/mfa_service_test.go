@@ -0,0 +1,580 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+)
+
+func setupMFAService(t *testing.T) (*MFAService, *mock.MockMFARepository, *mock.MockMFAChallengeRepository, *mock.MockAuthEventRepository) {
+	t.Helper()
+
+	mfaRepo := mock.NewMockMFARepository()
+	challengeRepo := mock.NewMockMFAChallengeRepository()
+	eventRepo := mock.NewMockAuthEventRepository()
+
+	svc := NewMFAService(MFAServiceConfig{
+		MFARepo:       mfaRepo,
+		ChallengeRepo: challengeRepo,
+		EventRepo:     eventRepo,
+	})
+	return svc, mfaRepo, challengeRepo, eventRepo
+}
+
+// codeAt returns the TOTP code for secret at the given step offset from now.
+func codeAt(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("invalid secret: %v", err)
+	}
+	return totpCode(key, totpStep(time.Now())+offset)
+}
+
+// enrollUser runs the full enrollment for userID and returns the secret and recovery codes.
+func enrollUser(t *testing.T, svc *MFAService, userID uuid.UUID) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	enrollment, err := svc.BeginEnrollment(ctx, userID, "user@example.com")
+	if err != nil {
+		t.Fatalf("BeginEnrollment failed: %v", err)
+	}
+	codes, err := svc.ConfirmEnrollment(ctx, userID, codeAt(t, enrollment.Secret, 0), "127.0.0.1")
+	if err != nil {
+		t.Fatalf("ConfirmEnrollment failed: %v", err)
+	}
+	return enrollment.Secret, codes
+}
+
+func TestMFAService_Enrollment(t *testing.T) {
+	svc, _, _, eventRepo := setupMFAService(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	enrollment, err := svc.BeginEnrollment(ctx, userID, "user@example.com")
+	if err != nil {
+		t.Fatalf("BeginEnrollment failed: %v", err)
+	}
+	if enrollment.Secret == "" || enrollment.URI == "" {
+		t.Fatal("BeginEnrollment should return a secret and URI")
+	}
+
+	// A pending factor does not count as enrolled
+	if enrolled, _ := svc.IsEnrolled(ctx, userID); enrolled {
+		t.Error("User should not be enrolled before confirmation")
+	}
+
+	if _, err := svc.ConfirmEnrollment(ctx, userID, "000000", ""); err != domain.ErrMFACodeInvalid {
+		t.Errorf("ConfirmEnrollment with wrong code error = %v, want ErrMFACodeInvalid", err)
+	}
+
+	codes, err := svc.ConfirmEnrollment(ctx, userID, codeAt(t, enrollment.Secret, 0), "127.0.0.1")
+	if err != nil {
+		t.Fatalf("ConfirmEnrollment failed: %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Errorf("len(codes) = %d, want %d", len(codes), recoveryCodeCount)
+	}
+	if enrolled, _ := svc.IsEnrolled(ctx, userID); !enrolled {
+		t.Error("User should be enrolled after confirmation")
+	}
+	if remaining, _ := svc.RemainingRecoveryCodes(ctx, userID); remaining != recoveryCodeCount {
+		t.Errorf("RemainingRecoveryCodes = %d, want %d", remaining, recoveryCodeCount)
+	}
+	if !hasEventType(eventRepo, domain.EventMFAEnrolled) {
+		t.Error("Expected mfa_enrolled event")
+	}
+
+	if _, err := svc.BeginEnrollment(ctx, userID, "user@example.com"); err != domain.ErrMFAAlreadyEnrolled {
+		t.Errorf("Second BeginEnrollment error = %v, want ErrMFAAlreadyEnrolled", err)
+	}
+}
+
+func TestMFAService_ConfirmEnrollment_NotStarted(t *testing.T) {
+	svc, _, _, _ := setupMFAService(t)
+
+	if _, err := svc.ConfirmEnrollment(context.Background(), uuid.New(), "123456", ""); err != domain.ErrMFANotEnrolled {
+		t.Errorf("ConfirmEnrollment error = %v, want ErrMFANotEnrolled", err)
+	}
+}
+
+func TestMFAService_Verify_TOTP(t *testing.T) {
+	svc, _, _, _ := setupMFAService(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	secret, _ := enrollUser(t, svc, userID)
+
+	// The enrollment code consumed the current step, so it can't be replayed
+	if _, err := svc.Verify(ctx, userID, codeAt(t, secret, 0)); err != domain.ErrMFACodeInvalid {
+		t.Errorf("Replayed code error = %v, want ErrMFACodeInvalid", err)
+	}
+
+	method, err := svc.Verify(ctx, userID, codeAt(t, secret, 1))
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if method != MFAMethodTOTP {
+		t.Errorf("method = %s, want %s", method, MFAMethodTOTP)
+	}
+}
+
+func TestMFAService_Verify_ConcurrentReplay(t *testing.T) {
+	svc, mfaRepo, _, _ := setupMFAService(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	secret, _ := enrollUser(t, svc, userID)
+
+	// Both requests read the factor before either consumes the step
+	stored, _ := mfaRepo.FindFactorByUser(ctx, userID)
+	snapshot := *stored
+	mfaRepo.FindFactorByUserFunc = func(ctx context.Context, userID uuid.UUID) (*domain.MFAFactor, error) {
+		factor := snapshot
+		return &factor, nil
+	}
+
+	code := codeAt(t, secret, 1)
+	if _, err := svc.Verify(ctx, userID, code); err != nil {
+		t.Fatalf("first Verify failed: %v", err)
+	}
+	if _, err := svc.Verify(ctx, userID, code); err != domain.ErrMFACodeInvalid {
+		t.Errorf("racing Verify error = %v, want ErrMFACodeInvalid", err)
+	}
+}
+
+func TestMFAService_Verify_RecoveryCodeSingleUse(t *testing.T) {
+	svc, _, _, _ := setupMFAService(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	_, codes := enrollUser(t, svc, userID)
+
+	// Recovery codes are accepted regardless of case and formatting
+	method, err := svc.Verify(ctx, userID, " "+strings.ToUpper(codes[0])+" ")
+	if err != nil {
+		t.Fatalf("Verify with recovery code failed: %v", err)
+	}
+	if method != MFAMethodRecoveryCode {
+		t.Errorf("method = %s, want %s", method, MFAMethodRecoveryCode)
+	}
+
+	if _, err := svc.Verify(ctx, userID, codes[0]); err != domain.ErrMFACodeInvalid {
+		t.Errorf("Reused recovery code error = %v, want ErrMFACodeInvalid", err)
+	}
+	if remaining, _ := svc.RemainingRecoveryCodes(ctx, userID); remaining != recoveryCodeCount-1 {
+		t.Errorf("RemainingRecoveryCodes = %d, want %d", remaining, recoveryCodeCount-1)
+	}
+}
+
+func TestMFAService_Verify_NotEnrolled(t *testing.T) {
+	svc, _, _, _ := setupMFAService(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	if _, err := svc.Verify(ctx, userID, "123456"); err != domain.ErrMFANotEnrolled {
+		t.Errorf("Verify error = %v, want ErrMFANotEnrolled", err)
+	}
+
+	// A pending factor can't be used to verify
+	svc.BeginEnrollment(ctx, userID, "user@example.com")
+	if _, err := svc.Verify(ctx, userID, "123456"); err != domain.ErrMFANotEnrolled {
+		t.Errorf("Verify with pending factor error = %v, want ErrMFANotEnrolled", err)
+	}
+}
+
+func TestMFAService_Disable(t *testing.T) {
+	svc, _, _, eventRepo := setupMFAService(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	secret, _ := enrollUser(t, svc, userID)
+
+	if err := svc.Disable(ctx, userID, "000000", ""); err != domain.ErrMFACodeInvalid {
+		t.Errorf("Disable with wrong code error = %v, want ErrMFACodeInvalid", err)
+	}
+	if !hasEventType(eventRepo, domain.EventMFAFailed) {
+		t.Error("Expected mfa_failed event for wrong code")
+	}
+
+	if err := svc.Disable(ctx, userID, codeAt(t, secret, 1), "127.0.0.1"); err != nil {
+		t.Fatalf("Disable failed: %v", err)
+	}
+	if enrolled, _ := svc.IsEnrolled(ctx, userID); enrolled {
+		t.Error("User should not be enrolled after disable")
+	}
+	if remaining, _ := svc.RemainingRecoveryCodes(ctx, userID); remaining != 0 {
+		t.Errorf("RemainingRecoveryCodes = %d, want 0", remaining)
+	}
+	if !hasEventType(eventRepo, domain.EventMFADisabled) {
+		t.Error("Expected mfa_disabled event")
+	}
+}
+
+func TestMFAService_RegenerateRecoveryCodes(t *testing.T) {
+	svc, _, _, eventRepo := setupMFAService(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	_, oldCodes := enrollUser(t, svc, userID)
+
+	// A recovery code can authorize regeneration, and is consumed doing so
+	newCodes, err := svc.RegenerateRecoveryCodes(ctx, userID, oldCodes[0], "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes failed: %v", err)
+	}
+	if len(newCodes) != recoveryCodeCount {
+		t.Errorf("len(newCodes) = %d, want %d", len(newCodes), recoveryCodeCount)
+	}
+	if _, err := svc.Verify(ctx, userID, oldCodes[1]); err != domain.ErrMFACodeInvalid {
+		t.Errorf("Old recovery code error = %v, want ErrMFACodeInvalid", err)
+	}
+	if _, err := svc.Verify(ctx, userID, newCodes[0]); err != nil {
+		t.Errorf("New recovery code should verify, got %v", err)
+	}
+	if !hasEventType(eventRepo, domain.EventMFARecoveryCodesReset) {
+		t.Error("Expected mfa_recovery_codes_reset event")
+	}
+}
+
+func TestMFAService_Challenge(t *testing.T) {
+	svc, _, _, _ := setupMFAService(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	tenantID := uuid.New()
+
+	token, err := svc.CreateChallenge(ctx, userID, tenantID)
+	if err != nil {
+		t.Fatalf("CreateChallenge failed: %v", err)
+	}
+
+	challenge, err := svc.FindChallenge(ctx, token)
+	if err != nil {
+		t.Fatalf("FindChallenge failed: %v", err)
+	}
+	if challenge.UserID != userID || challenge.TenantID != tenantID {
+		t.Error("Challenge should be bound to the user and tenant")
+	}
+
+	if _, err := svc.FindChallenge(ctx, "unknown"); err != domain.ErrMFAChallengeInvalid {
+		t.Errorf("FindChallenge(unknown) error = %v, want ErrMFAChallengeInvalid", err)
+	}
+
+	if err := svc.CompleteChallenge(ctx, challenge.ID); err != nil {
+		t.Fatalf("CompleteChallenge failed: %v", err)
+	}
+	if _, err := svc.FindChallenge(ctx, token); err != domain.ErrMFAChallengeInvalid {
+		t.Errorf("FindChallenge after completion error = %v, want ErrMFAChallengeInvalid", err)
+	}
+}
+
+func TestMFAService_Challenge_Expired(t *testing.T) {
+	svc, _, challengeRepo, _ := setupMFAService(t)
+	ctx := context.Background()
+
+	token, _ := svc.CreateChallenge(ctx, uuid.New(), uuid.New())
+	challenge, _ := challengeRepo.FindByToken(ctx, NewPasswordService().HashResetToken(token))
+	challenge.ExpiresAt = time.Now().Add(-time.Second)
+
+	if _, err := svc.FindChallenge(ctx, token); err != domain.ErrMFAChallengeInvalid {
+		t.Errorf("FindChallenge error = %v, want ErrMFAChallengeInvalid", err)
+	}
+}
+
+func TestMFAService_Challenge_MaxAttempts(t *testing.T) {
+	svc, _, _, _ := setupMFAService(t)
+	ctx := context.Background()
+
+	token, _ := svc.CreateChallenge(ctx, uuid.New(), uuid.New())
+	challenge, _ := svc.FindChallenge(ctx, token)
+
+	for i := 0; i < maxMFAChallengeAttempts; i++ {
+		if err := svc.RecordFailedAttempt(ctx, challenge.ID); err != nil {
+			t.Fatalf("RecordFailedAttempt failed: %v", err)
+		}
+	}
+
+	if _, err := svc.FindChallenge(ctx, token); err != domain.ErrMFAChallengeInvalid {
+		t.Errorf("FindChallenge error = %v, want ErrMFAChallengeInvalid", err)
+	}
+}
+
+func TestMFAService_PropagatesRepoErrors(t *testing.T) {
+	svc, mfaRepo, _, _ := setupMFAService(t)
+	ctx := context.Background()
+
+	dbErr := errors.New("db down")
+	mfaRepo.FindFactorByUserFunc = func(ctx context.Context, userID uuid.UUID) (*domain.MFAFactor, error) {
+		return nil, dbErr
+	}
+
+	if _, err := svc.IsEnrolled(ctx, uuid.New()); !errors.Is(err, dbErr) {
+		t.Errorf("IsEnrolled error = %v, want wrapped db error", err)
+	}
+	if _, err := svc.Verify(ctx, uuid.New(), "123456"); !errors.Is(err, dbErr) {
+		t.Errorf("Verify error = %v, want wrapped db error", err)
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"abcde-fghij", "abcdefghij"},
+		{" ABCDE-FGHIJ ", "abcdefghij"},
+		{"abcde fghij", "abcdefghij"},
+	}
+	for _, tt := range tests {
+		if got := normalizeRecoveryCode(tt.in); got != tt.want {
+			t.Errorf("normalizeRecoveryCode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// ============ AuthService MFA login flow ============
+
+func setupAuthServiceWithMFA(t *testing.T) (*AuthService, *MFAService, *mock.MockUserRepository, *mock.MockTenantRepository, *mock.MockAuthEventRepository) {
+	t.Helper()
+
+	authSvc, userRepo, sessionRepo, tenantRepo, eventRepo := setupAuthService(t)
+	mfaSvc := NewMFAService(MFAServiceConfig{
+		MFARepo:       mock.NewMockMFARepository(),
+		ChallengeRepo: mock.NewMockMFAChallengeRepository(),
+		EventRepo:     eventRepo,
+	})
+
+	authSvc = NewAuthService(AuthServiceConfig{
+		UserRepo:     userRepo,
+		SessionRepo:  sessionRepo,
+		EventRepo:    eventRepo,
+		TenantRepo:   tenantRepo,
+		RoleRepo:     mock.NewMockUserTenantRoleRepository(),
+		TokenService: authSvc.tokenService,
+		MFAService:   mfaSvc,
+	})
+
+	return authSvc, mfaSvc, userRepo, tenantRepo, eventRepo
+}
+
+func addMFATestUser(t *testing.T, userRepo *mock.MockUserRepository, tenantRepo *mock.MockTenantRepository, role domain.Role) (*domain.User, *domain.Tenant) {
+	t.Helper()
+
+	passwordHash, _ := NewPasswordService().Hash("Password123!")
+	tenant := &domain.Tenant{ID: uuid.New(), Name: "Restaurant", Slug: "restaurant", IsActive: true}
+	user := &domain.User{
+		ID:           uuid.New(),
+		Email:        "mfa@example.com",
+		PasswordHash: passwordHash,
+		IsActive:     true,
+		TenantRoles: []domain.UserTenantRole{
+			{TenantID: tenant.ID, Role: role, Tenant: *tenant},
+		},
+	}
+	userRepo.AddUser(user)
+	tenantRepo.AddTenant(tenant)
+	return user, tenant
+}
+
+func TestAuthService_Login_MFANotRequiredForStaff(t *testing.T) {
+	authSvc, _, userRepo, tenantRepo, _ := setupAuthServiceWithMFA(t)
+	addMFATestUser(t, userRepo, tenantRepo, domain.RoleWaiter)
+
+	resp, err := authSvc.Login(context.Background(), LoginRequest{Email: "mfa@example.com", Password: "Password123!"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if resp.TokenPair == nil || resp.MFAToken != "" {
+		t.Error("Unenrolled staff should receive tokens directly")
+	}
+}
+
+func TestAuthService_Login_MFAEnrollmentRequired(t *testing.T) {
+	authSvc, _, userRepo, tenantRepo, _ := setupAuthServiceWithMFA(t)
+	addMFATestUser(t, userRepo, tenantRepo, domain.RoleManager)
+	ctx := context.Background()
+
+	resp, err := authSvc.Login(ctx, LoginRequest{Email: "mfa@example.com", Password: "Password123!"})
+	if err != domain.ErrMFAEnrollmentRequired {
+		t.Fatalf("Login error = %v, want ErrMFAEnrollmentRequired", err)
+	}
+	if resp == nil || resp.MFAToken == "" || resp.TokenPair != nil {
+		t.Fatal("Expected an MFA token and no access token")
+	}
+
+	enrollment, err := authSvc.BeginMFAEnrollment(ctx, resp.MFAToken)
+	if err != nil {
+		t.Fatalf("BeginMFAEnrollment failed: %v", err)
+	}
+
+	verified, err := authSvc.VerifyMFA(ctx, MFAVerifyRequest{
+		MFAToken: resp.MFAToken,
+		Code:     codeAt(t, enrollment.Secret, 0),
+	})
+	if err != nil {
+		t.Fatalf("VerifyMFA failed: %v", err)
+	}
+	if verified.TokenPair == nil || verified.TokenPair.AccessToken == "" {
+		t.Error("VerifyMFA should issue tokens")
+	}
+	if len(verified.RecoveryCodes) != recoveryCodeCount {
+		t.Errorf("len(RecoveryCodes) = %d, want %d", len(verified.RecoveryCodes), recoveryCodeCount)
+	}
+
+	// The challenge is single-use
+	if _, err := authSvc.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: resp.MFAToken, Code: codeAt(t, enrollment.Secret, 1)}); err != domain.ErrMFAChallengeInvalid {
+		t.Errorf("Reused challenge error = %v, want ErrMFAChallengeInvalid", err)
+	}
+}
+
+func TestAuthService_Login_MFARequired(t *testing.T) {
+	authSvc, mfaSvc, userRepo, tenantRepo, eventRepo := setupAuthServiceWithMFA(t)
+	user, tenant := addMFATestUser(t, userRepo, tenantRepo, domain.RoleWaiter)
+	secret, _ := enrollUser(t, mfaSvc, user.ID)
+	ctx := context.Background()
+
+	// Once enrolled, MFA applies regardless of role
+	resp, err := authSvc.Login(ctx, LoginRequest{Email: "mfa@example.com", Password: "Password123!"})
+	if err != domain.ErrMFARequired {
+		t.Fatalf("Login error = %v, want ErrMFARequired", err)
+	}
+	if resp.TenantID != tenant.ID {
+		t.Errorf("TenantID = %v, want %v", resp.TenantID, tenant.ID)
+	}
+
+	if _, err := authSvc.BeginMFAEnrollment(ctx, resp.MFAToken); err != domain.ErrMFAAlreadyEnrolled {
+		t.Errorf("BeginMFAEnrollment error = %v, want ErrMFAAlreadyEnrolled", err)
+	}
+
+	if _, err := authSvc.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: resp.MFAToken, Code: "000000"}); err != domain.ErrMFACodeInvalid {
+		t.Fatalf("VerifyMFA with wrong code error = %v, want ErrMFACodeInvalid", err)
+	}
+
+	verified, err := authSvc.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: resp.MFAToken, Code: codeAt(t, secret, 1)})
+	if err != nil {
+		t.Fatalf("VerifyMFA failed: %v", err)
+	}
+	if verified.TokenPair == nil {
+		t.Error("VerifyMFA should issue an access token")
+	}
+	if verified.RecoveryCodes != nil {
+		t.Error("RecoveryCodes should only be returned on enrollment")
+	}
+
+	if !hasEventType(eventRepo, domain.EventMFAFailed) || !hasEventType(eventRepo, domain.EventMFASuccess) {
+		t.Error("Expected mfa_failed and mfa_success events")
+	}
+}
+
+func TestAuthService_Refresh_ElevationRequiresMFA(t *testing.T) {
+	authSvc, mfaSvc, userRepo, tenantRepo, _ := setupAuthServiceWithMFA(t)
+	user, _ := addMFATestUser(t, userRepo, tenantRepo, domain.RoleWaiter)
+	ctx := context.Background()
+
+	// A waiter signs in with just their password
+	login, err := authSvc.Login(ctx, LoginRequest{Email: "mfa@example.com", Password: "Password123!"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	// and is elevated to manager for a shift
+	validFrom, validUntil := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	tr := &user.TenantRoles[0]
+	tr.ElevatedRole, tr.ValidFrom, tr.ValidUntil = domain.RoleManager, &validFrom, &validUntil
+
+	if _, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: login.TokenPair.RefreshToken}); err != domain.ErrMFASignInRequired {
+		t.Fatalf("Refresh into an elevation that requires MFA = %v, want ErrMFASignInRequired", err)
+	}
+	if _, err := authSvc.Login(ctx, LoginRequest{Email: "mfa@example.com", Password: "Password123!"}); err != domain.ErrMFAEnrollmentRequired {
+		t.Fatalf("Login while elevated = %v, want ErrMFAEnrollmentRequired", err)
+	}
+
+	// Signing in with a second factor picks up the elevation, and keeps it
+	secret, _ := enrollUser(t, mfaSvc, user.ID)
+	resp, err := authSvc.Login(ctx, LoginRequest{Email: "mfa@example.com", Password: "Password123!"})
+	if err != domain.ErrMFARequired {
+		t.Fatalf("Login error = %v, want ErrMFARequired", err)
+	}
+	verified, err := authSvc.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: resp.MFAToken, Code: codeAt(t, secret, 1)})
+	if err != nil {
+		t.Fatalf("VerifyMFA failed: %v", err)
+	}
+	pair, err := authSvc.Refresh(ctx, RefreshRequest{RefreshToken: verified.TokenPair.RefreshToken})
+	if err != nil {
+		t.Fatalf("Refresh after MFA failed: %v", err)
+	}
+	claims, _ := authSvc.ValidateToken(ctx, pair.AccessToken)
+	if claims.Role != domain.RoleManager {
+		t.Errorf("Role after refresh = %v, want %v", claims.Role, domain.RoleManager)
+	}
+}
+
+func TestAuthService_VerifyMFA_RechecksAccount(t *testing.T) {
+	authSvc, mfaSvc, userRepo, tenantRepo, _ := setupAuthServiceWithMFA(t)
+	user, _ := addMFATestUser(t, userRepo, tenantRepo, domain.RoleOwner)
+	secret, _ := enrollUser(t, mfaSvc, user.ID)
+	ctx := context.Background()
+
+	resp, _ := authSvc.Login(ctx, LoginRequest{Email: "mfa@example.com", Password: "Password123!"})
+
+	// Deactivated between the password step and the second factor
+	user.IsActive = false
+
+	if _, err := authSvc.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: resp.MFAToken, Code: codeAt(t, secret, 1)}); err != domain.ErrAccountDisabled {
+		t.Errorf("VerifyMFA error = %v, want ErrAccountDisabled", err)
+	}
+}
+
+func TestAuthService_VerifyMFA_Disabled(t *testing.T) {
+	authSvc, _, _, _, _ := setupAuthService(t)
+
+	if _, err := authSvc.VerifyMFA(context.Background(), MFAVerifyRequest{MFAToken: "x", Code: "123456"}); err != domain.ErrMFAChallengeInvalid {
+		t.Errorf("VerifyMFA error = %v, want ErrMFAChallengeInvalid", err)
+	}
+}
+
+func TestAuthService_SwitchTenant_MFAEnrollmentRequired(t *testing.T) {
+	authSvc, mfaSvc, userRepo, tenantRepo, _ := setupAuthServiceWithMFA(t)
+	ctx := context.Background()
+
+	// A waiter in one tenant is a manager in another
+	user, _ := addMFATestUser(t, userRepo, tenantRepo, domain.RoleWaiter)
+	managed := &domain.Tenant{ID: uuid.New(), Name: "Managed", Slug: "managed", IsActive: true}
+	tenantRepo.AddTenant(managed)
+	user.TenantRoles = append(user.TenantRoles, domain.UserTenantRole{TenantID: managed.ID, Role: domain.RoleManager, Tenant: *managed})
+
+	login, err := authSvc.Login(ctx, LoginRequest{Email: "mfa@example.com", Password: "Password123!", TenantID: &user.TenantRoles[0].TenantID})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	claims, _ := authSvc.ValidateToken(ctx, login.TokenPair.AccessToken)
+	sessionID, _ := claims.GetSessionID()
+
+	req := SwitchTenantRequest{UserID: user.ID, SessionID: sessionID, TenantID: managed.ID}
+	resp, err := authSvc.SwitchTenant(ctx, req)
+	if !errors.Is(err, domain.ErrMFAEnrollmentRequired) {
+		t.Fatalf("SwitchTenant error = %v, want ErrMFAEnrollmentRequired", err)
+	}
+	if resp.MFAToken == "" {
+		t.Fatal("expected an MFA challenge for the target tenant")
+	}
+	challenge, err := mfaSvc.FindChallenge(ctx, resp.MFAToken)
+	if err != nil {
+		t.Fatalf("FindChallenge failed: %v", err)
+	}
+	if challenge.TenantID != managed.ID {
+		t.Errorf("challenge tenant = %s, want %s", challenge.TenantID, managed.ID)
+	}
+
+	// Once enrolled, the MFA completed at login covers the switch
+	enrollUser(t, mfaSvc, user.ID)
+	resp, err = authSvc.SwitchTenant(ctx, req)
+	if err != nil {
+		t.Fatalf("SwitchTenant after enrollment failed: %v", err)
+	}
+	if resp.TenantID != managed.ID || resp.Role != domain.RoleManager {
+		t.Errorf("switched to %s as %s, want %s as manager", resp.TenantID, resp.Role, managed.ID)
+	}
+}
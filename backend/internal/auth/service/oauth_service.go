@@ -0,0 +1,898 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+	"github.com/solobueno/erp/pkg/oauth"
+)
+
+// OAuthService is the OAuth 2.0 authorization server that gives third-party
+// clients delegated access to tenants. It registers clients, records the
+// consent a tenant owner gives them, and issues tokens under the
+// authorization code (with PKCE), client credentials and refresh token
+// grants. Access tokens are ordinary JWTs carrying a client_id claim, so the
+// rest of the API verifies them like any other access token.
+type OAuthService struct {
+	clients       repository.OAuthClientRepository
+	consents      repository.OAuthConsentRepository
+	codes         repository.OAuthCodeRepository
+	refreshTokens repository.OAuthRefreshTokenRepository
+	userRepo      repository.UserRepository
+	eventRepo     repository.AuthEventRepository
+	tokenService  *TokenService
+	revocations   repository.TokenRevocationStore
+	passwordSvc   *PasswordService
+}
+
+// OAuthServiceConfig holds configuration for OAuthService.
+type OAuthServiceConfig struct {
+	Clients       repository.OAuthClientRepository
+	Consents      repository.OAuthConsentRepository
+	Codes         repository.OAuthCodeRepository
+	RefreshTokens repository.OAuthRefreshTokenRepository
+	UserRepo      repository.UserRepository
+	EventRepo     repository.AuthEventRepository
+	TokenService  *TokenService
+	// RevocationStore lets clients revoke access tokens at the revocation
+	// endpoint. If nil, only refresh tokens can be revoked there.
+	RevocationStore repository.TokenRevocationStore
+}
+
+// NewOAuthService creates a new OAuthService.
+func NewOAuthService(cfg OAuthServiceConfig) *OAuthService {
+	return &OAuthService{
+		clients:       cfg.Clients,
+		consents:      cfg.Consents,
+		codes:         cfg.Codes,
+		refreshTokens: cfg.RefreshTokens,
+		userRepo:      cfg.UserRepo,
+		eventRepo:     cfg.EventRepo,
+		tokenService:  cfg.TokenService,
+		revocations:   cfg.RevocationStore,
+		passwordSvc:   NewPasswordService(),
+	}
+}
+
+// ListClients returns the OAuth clients a tenant has registered, ordered by
+// name.
+func (s *OAuthService) ListClients(ctx context.Context, tenantID uuid.UUID) ([]*domain.OAuthClient, error) {
+	clients, err := s.clients.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list oauth clients: %w", err)
+	}
+	return clients, nil
+}
+
+// GetClient returns one of the OAuth clients a tenant has registered.
+func (s *OAuthService) GetClient(ctx context.Context, tenantID, id uuid.UUID) (*domain.OAuthClient, error) {
+	client, err := s.findTenantClient(ctx, tenantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("get oauth client: %w", err)
+	}
+	return client, nil
+}
+
+// CreateOAuthClientRequest contains the data for registering an OAuth client.
+type CreateOAuthClientRequest struct {
+	TenantID     uuid.UUID
+	Name         string
+	RedirectURIs []string
+	Scopes       []domain.Permission
+	// Public registers a client that can't keep a secret, such as a mobile
+	// app. It gets no secret and can't use the client credentials grant.
+	Public    bool
+	CreatedBy uuid.UUID
+	IPAddress string
+}
+
+// CreateClient registers an OAuth client in a tenant. Its scopes, the most
+// any tenant can grant it, can only be ones the caller holds (callerPerms).
+// A confidential client's secret is returned only here; just its hash is
+// stored.
+func (s *OAuthService) CreateClient(ctx context.Context, req CreateOAuthClientRequest, callerPerms []domain.Permission) (*domain.OAuthClient, string, error) {
+	name, err := domain.NormalizeOAuthClientName(req.Name)
+	if err != nil {
+		return nil, "", err
+	}
+	redirectURIs, err := checkRedirectURIs(req.RedirectURIs)
+	if err != nil {
+		return nil, "", err
+	}
+	scopes, err := checkGrantablePermissions(req.Scopes, callerPerms)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := &domain.OAuthClient{
+		ID:           uuid.New(),
+		TenantID:     req.TenantID,
+		Name:         name,
+		RedirectURIs: redirectURIs,
+		Scopes:       scopes,
+		Public:       req.Public,
+		CreatedBy:    req.CreatedBy,
+	}
+	var secret string
+	if !client.Public {
+		var hash string
+		if secret, hash, err = s.passwordSvc.GenerateResetToken(); err != nil {
+			return nil, "", fmt.Errorf("create oauth client: generate secret: %w", err)
+		}
+		client.SecretHash = hash
+	}
+	if err := s.clients.Create(ctx, client); err != nil {
+		return nil, "", fmt.Errorf("create oauth client: save: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventOAuthClientCreated, &req.CreatedBy, &req.TenantID, req.IPAddress, map[string]interface{}{
+		"client_id": client.ID,
+		"name":      client.Name,
+		"public":    client.Public,
+		"scopes":    client.Scopes,
+	})
+
+	return client, secret, nil
+}
+
+// UpdateOAuthClientRequest contains the data for updating an OAuth client.
+// Nil fields are left unchanged.
+type UpdateOAuthClientRequest struct {
+	TenantID     uuid.UUID
+	ID           uuid.UUID
+	Name         *string
+	RedirectURIs []string
+	Scopes       []domain.Permission
+	UpdatedBy    uuid.UUID
+	IPAddress    string
+}
+
+// UpdateClient renames an OAuth client or replaces its redirect URIs or
+// scopes. Narrowing the scopes narrows the client's next tokens in every
+// tenant, whatever was consented to.
+func (s *OAuthService) UpdateClient(ctx context.Context, req UpdateOAuthClientRequest, callerPerms []domain.Permission) (*domain.OAuthClient, error) {
+	client, err := s.findTenantClient(ctx, req.TenantID, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("update oauth client: lookup: %w", err)
+	}
+
+	if req.Name != nil {
+		name, err := domain.NormalizeOAuthClientName(*req.Name)
+		if err != nil {
+			return nil, err
+		}
+		client.Name = name
+	}
+	if req.RedirectURIs != nil {
+		redirectURIs, err := checkRedirectURIs(req.RedirectURIs)
+		if err != nil {
+			return nil, err
+		}
+		client.RedirectURIs = redirectURIs
+	}
+	if req.Scopes != nil {
+		scopes, err := checkGrantablePermissions(req.Scopes, callerPerms)
+		if err != nil {
+			return nil, err
+		}
+		client.Scopes = scopes
+	}
+
+	if err := s.clients.Update(ctx, client); err != nil {
+		return nil, fmt.Errorf("update oauth client: save: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventOAuthClientUpdated, &req.UpdatedBy, &req.TenantID, req.IPAddress, map[string]interface{}{
+		"client_id":     client.ID,
+		"name":          client.Name,
+		"redirect_uris": client.RedirectURIs,
+		"scopes":        client.Scopes,
+	})
+
+	return client, nil
+}
+
+// DeleteClient deletes an OAuth client along with the consents, codes and
+// refresh tokens issued to it in any tenant. Its access tokens stop working
+// at once.
+func (s *OAuthService) DeleteClient(ctx context.Context, tenantID, id, deletedBy uuid.UUID, ipAddress string) error {
+	client, err := s.findTenantClient(ctx, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("delete oauth client: lookup: %w", err)
+	}
+	if err := s.clients.Delete(ctx, tenantID, id); err != nil {
+		return fmt.Errorf("delete oauth client: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventOAuthClientDeleted, &deletedBy, &tenantID, ipAddress, map[string]interface{}{
+		"client_id": client.ID,
+		"name":      client.Name,
+	})
+
+	return nil
+}
+
+// RotateClientSecret replaces a confidential client's secret and returns
+// the new one. The old secret stops working at once; tokens already issued
+// are unaffected.
+func (s *OAuthService) RotateClientSecret(ctx context.Context, tenantID, id, rotatedBy uuid.UUID, ipAddress string) (string, error) {
+	client, err := s.findTenantClient(ctx, tenantID, id)
+	if err != nil {
+		return "", fmt.Errorf("rotate client secret: lookup: %w", err)
+	}
+	if client.Public {
+		return "", domain.ErrOAuthClientPublic
+	}
+
+	secret, hash, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return "", fmt.Errorf("rotate client secret: generate: %w", err)
+	}
+	client.SecretHash = hash
+	if err := s.clients.Update(ctx, client); err != nil {
+		return "", fmt.Errorf("rotate client secret: save: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventOAuthSecretRotated, &rotatedBy, &tenantID, ipAddress, map[string]interface{}{
+		"client_id": client.ID,
+	})
+
+	return secret, nil
+}
+
+// AuthorizeRequest contains an authorization request (RFC 6749 section
+// 4.1.1) made by a signed-in user on behalf of their tenant.
+type AuthorizeRequest struct {
+	TenantID            uuid.UUID
+	UserID              uuid.UUID
+	ClientID            string
+	RedirectURI         string
+	ResponseType        string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	IPAddress           string
+}
+
+// OAuthAuthorization is a validated authorization request, to be shown to
+// the user for consent.
+type OAuthAuthorization struct {
+	Client      *domain.OAuthClient
+	RedirectURI string
+	Scopes      []domain.Permission
+	// Consented is what the tenant has already granted the client, if
+	// anything.
+	Consented []domain.Permission
+	// RedirectTo is set instead when the request is invalid in a way the
+	// client must be told of: the user is sent back to it with the error.
+	RedirectTo string
+}
+
+// ValidateAuthorization checks an authorization request before the user is
+// asked to approve it. The requested scopes default to all of the client's,
+// and must be ones both the client and the user (userPerms) have. An unknown
+// client or unregistered redirect URI is returned as ErrOAuthClientNotFound
+// or ErrOAuthRedirectURI, since the user can't safely be sent back to the
+// client; other problems set RedirectTo.
+func (s *OAuthService) ValidateAuthorization(ctx context.Context, req AuthorizeRequest, userPerms []domain.Permission) (*OAuthAuthorization, error) {
+	clientID, err := uuid.Parse(req.ClientID)
+	if err != nil {
+		return nil, domain.ErrOAuthClientNotFound
+	}
+	client, err := s.clients.FindByID(ctx, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("authorize: client lookup: %w", err)
+	}
+
+	// A client with a single redirect URI may leave it out
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !client.HasRedirectURI(redirectURI) {
+		return nil, domain.ErrOAuthRedirectURI
+	}
+
+	auth := &OAuthAuthorization{Client: client, RedirectURI: redirectURI}
+	reject := func(e *oauth.Error) (*OAuthAuthorization, error) {
+		return &OAuthAuthorization{RedirectTo: oauth.RedirectError(redirectURI, e, req.State)}, nil
+	}
+
+	if req.ResponseType != oauth.ResponseTypeCode {
+		return reject(oauth.NewError(oauth.ErrorUnsupportedResponseType, "response_type must be %q", oauth.ResponseTypeCode))
+	}
+	if req.CodeChallengeMethod != oauth.CodeChallengeMethodS256 || !oauth.ValidCodeChallenge(req.CodeChallenge) {
+		return reject(oauth.NewError(oauth.ErrorInvalidRequest, "a PKCE code_challenge with method %s is required", oauth.CodeChallengeMethodS256))
+	}
+	scopes, oerr := requestedScopes(req.Scope, client.Scopes, userPerms)
+	if oerr != nil {
+		return reject(oerr)
+	}
+	auth.Scopes = scopes
+
+	consent, err := s.consents.FindActive(ctx, req.TenantID, client.ID)
+	if err != nil && !errors.Is(err, domain.ErrOAuthConsentNotFound) {
+		return nil, fmt.Errorf("authorize: consent lookup: %w", err)
+	}
+	if consent != nil {
+		auth.Consented = consent.Scopes
+	}
+
+	return auth, nil
+}
+
+// Authorize completes an authorization request once the user has approved
+// or denied it, returning where to send the user back to the client. On
+// approval, the tenant's consent for the client is recorded, replacing any
+// earlier one, and an authorization code is issued for the client to
+// exchange at the token endpoint.
+func (s *OAuthService) Authorize(ctx context.Context, req AuthorizeRequest, approve bool, userPerms []domain.Permission) (string, error) {
+	auth, err := s.ValidateAuthorization(ctx, req, userPerms)
+	if err != nil {
+		return "", err
+	}
+	if auth.RedirectTo != "" {
+		return auth.RedirectTo, nil
+	}
+	if !approve {
+		return oauth.RedirectError(auth.RedirectURI, oauth.NewError(oauth.ErrorAccessDenied, "the user denied the request"), req.State), nil
+	}
+
+	consent, err := s.consents.FindActive(ctx, req.TenantID, auth.Client.ID)
+	switch {
+	case err == nil:
+		consent.Scopes = auth.Scopes
+		consent.GrantedBy = req.UserID
+		err = s.consents.Update(ctx, consent)
+	case errors.Is(err, domain.ErrOAuthConsentNotFound):
+		consent = &domain.OAuthConsent{
+			TenantID:  req.TenantID,
+			ClientID:  auth.Client.ID,
+			GrantedBy: req.UserID,
+			Scopes:    auth.Scopes,
+		}
+		err = s.consents.Create(ctx, consent)
+	}
+	if err != nil {
+		return "", fmt.Errorf("authorize: save consent: %w", err)
+	}
+
+	plainCode, codeHash, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return "", fmt.Errorf("authorize: generate code: %w", err)
+	}
+	code := &domain.OAuthAuthorizationCode{
+		ID:            uuid.New(),
+		CodeHash:      codeHash,
+		ClientID:      auth.Client.ID,
+		TenantID:      req.TenantID,
+		UserID:        req.UserID,
+		RedirectURI:   auth.RedirectURI,
+		Scopes:        auth.Scopes,
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     time.Now().Add(domain.OAuthCodeTTL),
+	}
+	if err := s.codes.Create(ctx, code); err != nil {
+		return "", fmt.Errorf("authorize: save code: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventOAuthConsentGranted, &req.UserID, &req.TenantID, req.IPAddress, map[string]interface{}{
+		"client_id":  auth.Client.ID,
+		"consent_id": consent.ID,
+		"scopes":     auth.Scopes,
+	})
+
+	return oauth.RedirectCode(auth.RedirectURI, plainCode, req.State), nil
+}
+
+// TokenRequest contains a token request (RFC 6749 section 3.2) from a
+// client that has already extracted its credentials.
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	// Code, RedirectURI and CodeVerifier are used by the authorization
+	// code grant.
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	// RefreshToken is used by the refresh token grant.
+	RefreshToken string
+	// Scope optionally narrows the client credentials and refresh token
+	// grants.
+	Scope     string
+	IPAddress string
+}
+
+// Token handles a request to the token endpoint. Protocol errors are
+// returned as *oauth.Error, to be written to the client as is; any other
+// error is a server error.
+func (s *OAuthService) Token(ctx context.Context, req TokenRequest) (*oauth.TokenResponse, error) {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case oauth.GrantAuthorizationCode:
+		return s.exchangeCode(ctx, client, req)
+	case oauth.GrantRefreshToken:
+		return s.refresh(ctx, client, req)
+	case oauth.GrantClientCredentials:
+		return s.clientCredentials(client, req)
+	case "":
+		return nil, oauth.NewError(oauth.ErrorInvalidRequest, "grant_type is required")
+	default:
+		return nil, oauth.NewError(oauth.ErrorUnsupportedGrantType, "grant_type %q is not supported", req.GrantType)
+	}
+}
+
+// exchangeCode handles the authorization code grant. A code is single-use:
+// presenting it again revokes the refresh tokens it was exchanged for,
+// since one of the two parties holding it shouldn't.
+func (s *OAuthService) exchangeCode(ctx context.Context, client *domain.OAuthClient, req TokenRequest) (*oauth.TokenResponse, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, oauth.NewError(oauth.ErrorInvalidRequest, "code and code_verifier are required")
+	}
+	invalid := oauth.NewError(oauth.ErrorInvalidGrant, "authorization code is invalid, expired or already used")
+
+	code, err := s.codes.FindByHash(ctx, s.passwordSvc.HashResetToken(req.Code))
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthGrantInvalid) {
+			return nil, invalid
+		}
+		return nil, fmt.Errorf("token: code lookup: %w", err)
+	}
+	if code.ClientID != client.ID || code.RedirectURI != req.RedirectURI || !oauth.VerifyCodeVerifier(req.CodeVerifier, code.CodeChallenge) {
+		return nil, invalid
+	}
+
+	used := code.UsedAt != nil
+	if !used {
+		err = s.codes.MarkUsed(ctx, code.ID)
+	}
+	if used || errors.Is(err, domain.ErrOAuthGrantInvalid) {
+		if _, err := s.refreshTokens.RevokeFamily(ctx, code.ID); err != nil {
+			return nil, fmt.Errorf("token: revoke code family: %w", err)
+		}
+		s.logEvent(ctx, domain.EventOAuthTokenReused, &code.UserID, &code.TenantID, req.IPAddress, map[string]interface{}{
+			"client_id": client.ID,
+			"grant":     oauth.GrantAuthorizationCode,
+		})
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("token: mark code used: %w", err)
+	}
+	if code.IsExpired() {
+		return nil, invalid
+	}
+
+	perms, err := s.grantedPermissions(ctx, client, code.TenantID, code.UserID, code.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueUserTokens(ctx, client, code.TenantID, code.UserID, code.ID, code.Scopes, perms, nil)
+}
+
+// refresh handles the refresh token grant. Like a session's refresh token,
+// an OAuth refresh token is rotated on every use, and replaying a rotated
+// one revokes its whole family.
+func (s *OAuthService) refresh(ctx context.Context, client *domain.OAuthClient, req TokenRequest) (*oauth.TokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, oauth.NewError(oauth.ErrorInvalidRequest, "refresh_token is required")
+	}
+	invalid := oauth.NewError(oauth.ErrorInvalidGrant, "refresh token is invalid, expired or revoked")
+
+	token, err := s.refreshTokens.FindByHash(ctx, s.tokenService.HashRefreshToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthGrantInvalid) {
+			return nil, invalid
+		}
+		return nil, fmt.Errorf("token: refresh token lookup: %w", err)
+	}
+	if token.ClientID != client.ID {
+		return nil, invalid
+	}
+	if token.IsRevoked() {
+		if token.IsRotated() {
+			if _, err := s.refreshTokens.RevokeFamily(ctx, token.FamilyID); err != nil {
+				return nil, fmt.Errorf("token: revoke family: %w", err)
+			}
+			s.logEvent(ctx, domain.EventOAuthTokenReused, &token.UserID, &token.TenantID, req.IPAddress, map[string]interface{}{
+				"client_id": client.ID,
+				"grant":     oauth.GrantRefreshToken,
+				"family_id": token.FamilyID,
+			})
+		}
+		return nil, invalid
+	}
+	if token.IsExpired() {
+		return nil, invalid
+	}
+
+	// The new access token may be narrowed, but the refresh token keeps the
+	// scopes originally granted
+	scopes := []domain.Permission(token.Scopes)
+	if requested := scopePermissions(req.Scope); requested != nil {
+		for _, p := range requested {
+			if !slices.Contains(token.Scopes, p) {
+				return nil, oauth.NewError(oauth.ErrorInvalidScope, "scope %q was not granted", p)
+			}
+		}
+		scopes = requested
+	}
+
+	perms, err := s.grantedPermissions(ctx, client, token.TenantID, token.UserID, scopes)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueUserTokens(ctx, client, token.TenantID, token.UserID, token.FamilyID, token.Scopes, perms, token)
+}
+
+// clientCredentials handles the client credentials grant, under which a
+// confidential client acts on its own behalf in the tenant that registered
+// it. No refresh token is issued; the client just asks again.
+func (s *OAuthService) clientCredentials(client *domain.OAuthClient, req TokenRequest) (*oauth.TokenResponse, error) {
+	if client.Public {
+		return nil, oauth.NewError(oauth.ErrorUnauthorizedClient, "public clients can't use the client credentials grant")
+	}
+	perms, oerr := requestedScopes(req.Scope, client.Scopes, client.Scopes)
+	if oerr != nil {
+		return nil, oerr
+	}
+
+	accessToken, expiresAt, err := s.tokenService.GenerateClientAccessToken(client.ID, client.TenantID, client.ID, perms)
+	if err != nil {
+		return nil, fmt.Errorf("token: access token generation: %w", err)
+	}
+	return &oauth.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   oauth.TokenTypeBearer,
+		ExpiresIn:   int(time.Until(expiresAt).Seconds()),
+		Scope:       oauth.FormatScope(permissionClaim(perms)),
+	}, nil
+}
+
+// grantedPermissions returns what a client may do for a user in a tenant
+// right now: the requested scopes, less anything the tenant's consent, the
+// client's registration or the user's current permissions no longer allow.
+// The grant is invalid once the consent is revoked or the user has left
+// the tenant or been deactivated.
+func (s *OAuthService) grantedPermissions(ctx context.Context, client *domain.OAuthClient, tenantID, userID uuid.UUID, scopes []domain.Permission) ([]domain.Permission, error) {
+	invalid := oauth.NewError(oauth.ErrorInvalidGrant, "the grant is no longer valid")
+
+	consent, err := s.consents.FindActive(ctx, tenantID, client.ID)
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthConsentNotFound) {
+			return nil, invalid
+		}
+		return nil, fmt.Errorf("token: consent lookup: %w", err)
+	}
+	user, err := s.userRepo.FindByIDWithTenants(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, invalid
+		}
+		return nil, fmt.Errorf("token: user lookup: %w", err)
+	}
+	if !user.CanLogin() || user.GetRoleForTenant(tenantID) == "" {
+		return nil, invalid
+	}
+
+	userPerms := user.GetPermissionsForTenant(tenantID)
+	perms := make([]domain.Permission, 0, len(scopes))
+	for _, p := range scopes {
+		if slices.Contains(consent.Scopes, p) && client.HasScope(p) && slices.Contains(userPerms, p) {
+			perms = append(perms, p)
+		}
+	}
+	return sortedPermissions(perms), nil
+}
+
+// issueUserTokens issues an access token for a user's grant and a new
+// refresh token in the grant's family. A refresh token being exchanged
+// (previous) is rotated to the new one; scopes are what the new refresh
+// token may later be exchanged for.
+func (s *OAuthService) issueUserTokens(ctx context.Context, client *domain.OAuthClient, tenantID, userID, familyID uuid.UUID, scopes, perms []domain.Permission, previous *domain.OAuthRefreshToken) (*oauth.TokenResponse, error) {
+	accessToken, expiresAt, err := s.tokenService.GenerateClientAccessToken(userID, tenantID, client.ID, perms)
+	if err != nil {
+		return nil, fmt.Errorf("token: access token generation: %w", err)
+	}
+
+	plainRefresh, _, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return nil, fmt.Errorf("token: refresh token generation: %w", err)
+	}
+	next := &domain.OAuthRefreshToken{
+		ID:        uuid.New(),
+		TokenHash: s.tokenService.HashRefreshToken(plainRefresh),
+		ClientID:  client.ID,
+		TenantID:  tenantID,
+		UserID:    userID,
+		FamilyID:  familyID,
+		Scopes:    scopes,
+		ExpiresAt: s.tokenService.GetRefreshTokenExpiry(),
+	}
+	if previous == nil {
+		err = s.refreshTokens.Create(ctx, next)
+	} else {
+		err = s.refreshTokens.Rotate(ctx, previous.ID, next)
+	}
+	if err != nil {
+		// Lost a race with another exchange of the same refresh token
+		if errors.Is(err, domain.ErrOAuthGrantInvalid) {
+			return nil, oauth.NewError(oauth.ErrorInvalidGrant, "refresh token is invalid, expired or revoked")
+		}
+		return nil, fmt.Errorf("token: save refresh token: %w", err)
+	}
+
+	return &oauth.TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    oauth.TokenTypeBearer,
+		ExpiresIn:    int(time.Until(expiresAt).Seconds()),
+		RefreshToken: plainRefresh,
+		Scope:        oauth.FormatScope(permissionClaim(perms)),
+	}, nil
+}
+
+// RevokeTokenRequest contains a token revocation request (RFC 7009).
+type RevokeTokenRequest struct {
+	ClientID      string
+	ClientSecret  string
+	Token         string
+	TokenTypeHint string
+	IPAddress     string
+}
+
+// RevokeToken handles a request to the revocation endpoint. Revoking a
+// refresh token revokes every refresh token of its grant; revoking an access
+// token revokes just that token. As RFC 7009 requires, a token that is
+// unknown, already revoked or issued to another client is silently ignored,
+// so only a failure to authenticate the client is reported (as *oauth.Error).
+func (s *OAuthService) RevokeToken(ctx context.Context, req RevokeTokenRequest) error {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return err
+	}
+	if req.Token == "" {
+		return oauth.NewError(oauth.ErrorInvalidRequest, "token is required")
+	}
+
+	// The hint only decides which kind of token is looked for first
+	revokers := []func(context.Context, *domain.OAuthClient, string) (bool, error){s.revokeRefreshToken, s.revokeAccessToken}
+	if req.TokenTypeHint == oauth.TokenHintAccessToken {
+		slices.Reverse(revokers)
+	}
+	for _, revoke := range revokers {
+		revoked, err := revoke(ctx, client, req.Token)
+		if err != nil {
+			return fmt.Errorf("revoke oauth token: %w", err)
+		}
+		if revoked {
+			return nil
+		}
+	}
+	return nil
+}
+
+// revokeRefreshToken revokes the grant a client's refresh token belongs to,
+// reporting whether the token was one.
+func (s *OAuthService) revokeRefreshToken(ctx context.Context, client *domain.OAuthClient, plain string) (bool, error) {
+	token, err := s.refreshTokens.FindByHash(ctx, s.tokenService.HashRefreshToken(plain))
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthGrantInvalid) {
+			return false, nil
+		}
+		return false, err
+	}
+	if token.ClientID != client.ID {
+		return false, nil
+	}
+	_, err = s.refreshTokens.RevokeFamily(ctx, token.FamilyID)
+	return true, err
+}
+
+// revokeAccessToken revokes one of a client's access tokens, reporting
+// whether the token was one.
+func (s *OAuthService) revokeAccessToken(ctx context.Context, client *domain.OAuthClient, plain string) (bool, error) {
+	claims, err := s.tokenService.ValidateAccessToken(plain)
+	if err != nil || claims.ClientID != client.ID.String() {
+		return false, nil
+	}
+	if s.revocations == nil || claims.ExpiresAt == nil {
+		return true, nil
+	}
+	subject, err := claims.GetUserID()
+	if err != nil {
+		return false, nil
+	}
+	return true, s.revocations.RevokeToken(ctx, &domain.RevokedAccessToken{
+		JTI:       claims.ID,
+		UserID:    subject,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+}
+
+// ValidateClientToken checks that an access token issued to an OAuth client
+// is still backed by a grant: the client still exists and, for a token
+// issued on a user's behalf, the tenant's consent hasn't been revoked or
+// narrowed below the token's permissions. It returns ErrTokenRevoked if not.
+func (s *OAuthService) ValidateClientToken(ctx context.Context, claims *domain.Claims) error {
+	clientID, ok := claims.GetClientID()
+	if !ok {
+		return domain.ErrTokenInvalid
+	}
+
+	if claims.Subject == clientID.String() {
+		client, err := s.clients.FindByID(ctx, clientID)
+		if err != nil {
+			if errors.Is(err, domain.ErrOAuthClientNotFound) {
+				return domain.ErrTokenRevoked
+			}
+			return fmt.Errorf("validate client token: client lookup: %w", err)
+		}
+		if client.TenantID != claims.TenantID {
+			return domain.ErrTokenRevoked
+		}
+		return nil
+	}
+
+	consent, err := s.consents.FindActive(ctx, claims.TenantID, clientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthConsentNotFound) {
+			return domain.ErrTokenRevoked
+		}
+		return fmt.Errorf("validate client token: consent lookup: %w", err)
+	}
+	if !consent.Covers(claims.Permissions) {
+		return domain.ErrTokenRevoked
+	}
+	return nil
+}
+
+// ListConsents returns the OAuth clients a tenant has authorized, with the
+// scopes each was granted.
+func (s *OAuthService) ListConsents(ctx context.Context, tenantID uuid.UUID) ([]*domain.OAuthConsent, error) {
+	consents, err := s.consents.ListActiveByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list oauth consents: %w", err)
+	}
+	return consents, nil
+}
+
+// RevokeConsent withdraws a tenant's authorization of an OAuth client. The
+// client's refresh tokens in the tenant are revoked, and its access tokens
+// stop working at once.
+func (s *OAuthService) RevokeConsent(ctx context.Context, tenantID, id, revokedBy uuid.UUID, ipAddress string) error {
+	consent, err := s.consents.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("revoke oauth consent: lookup: %w", err)
+	}
+	if consent.IsRevoked() {
+		return domain.ErrOAuthConsentNotFound
+	}
+	if err := s.consents.Revoke(ctx, consent.ID); err != nil {
+		return fmt.Errorf("revoke oauth consent: %w", err)
+	}
+	revoked, err := s.refreshTokens.RevokeByClient(ctx, tenantID, consent.ClientID)
+	if err != nil {
+		return fmt.Errorf("revoke oauth consent: revoke refresh tokens: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventOAuthConsentRevoked, &revokedBy, &tenantID, ipAddress, map[string]interface{}{
+		"client_id":      consent.ClientID,
+		"consent_id":     consent.ID,
+		"revoked_tokens": revoked,
+	})
+
+	return nil
+}
+
+// authenticateClient authenticates a client at the token or revocation
+// endpoint. A confidential client must present its secret; a public client
+// must not present one.
+func (s *OAuthService) authenticateClient(ctx context.Context, clientID, secret string) (*domain.OAuthClient, error) {
+	invalid := oauth.NewError(oauth.ErrorInvalidClient, "client authentication failed")
+
+	id, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, invalid
+	}
+	client, err := s.clients.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthClientNotFound) {
+			return nil, invalid
+		}
+		return nil, fmt.Errorf("authenticate oauth client: %w", err)
+	}
+
+	if client.Public {
+		if secret != "" {
+			return nil, invalid
+		}
+		return client, nil
+	}
+	if secret == "" || subtle.ConstantTimeCompare([]byte(s.passwordSvc.HashResetToken(secret)), []byte(client.SecretHash)) != 1 {
+		return nil, invalid
+	}
+	return client, nil
+}
+
+// findTenantClient returns an OAuth client registered by a tenant. Clients
+// of other tenants are reported as not found.
+func (s *OAuthService) findTenantClient(ctx context.Context, tenantID, id uuid.UUID) (*domain.OAuthClient, error) {
+	client, err := s.clients.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if client.TenantID != tenantID {
+		return nil, domain.ErrOAuthClientNotFound
+	}
+	return client, nil
+}
+
+// checkRedirectURIs validates a client's redirect URIs, dropping
+// duplicates.
+func checkRedirectURIs(uris []string) (domain.RedirectURIList, error) {
+	out := make(domain.RedirectURIList, 0, len(uris))
+	for _, uri := range uris {
+		if !oauth.ValidRedirectURI(uri) {
+			return nil, domain.ErrOAuthRedirectURI
+		}
+		if !slices.Contains(out, uri) {
+			out = append(out, uri)
+		}
+	}
+	if len(out) == 0 || len(out) > domain.MaxOAuthRedirectURIs {
+		return nil, domain.ErrOAuthRedirectURI
+	}
+	return out, nil
+}
+
+// requestedScopes returns the permissions a scope parameter asks for,
+// defaulting an empty one to all of allowed. Every scope must be in both
+// allowed and held.
+func requestedScopes(scope string, allowed, held []domain.Permission) ([]domain.Permission, *oauth.Error) {
+	perms := scopePermissions(scope)
+	if perms == nil {
+		perms = allowed
+	}
+	for _, p := range perms {
+		if !slices.Contains(allowed, p) || !slices.Contains(held, p) {
+			return nil, oauth.NewError(oauth.ErrorInvalidScope, "scope %q can't be granted", p)
+		}
+	}
+	if len(perms) == 0 {
+		return nil, oauth.NewError(oauth.ErrorInvalidScope, "no scope can be granted")
+	}
+	return sortedPermissions(perms), nil
+}
+
+// scopePermissions parses a scope parameter into permissions, returning nil
+// for an empty one.
+func scopePermissions(scope string) []domain.Permission {
+	var perms []domain.Permission
+	for _, s := range oauth.ParseScope(scope) {
+		perms = append(perms, domain.Permission(s))
+	}
+	return perms
+}
+
+// logEvent logs an authentication event.
+func (s *OAuthService) logEvent(ctx context.Context, eventType domain.AuthEventType, userID, tenantID *uuid.UUID, ipAddress string, metadata map[string]interface{}) {
+	event := newAuthEvent(ctx, eventType, userID, tenantID, ipAddress, "")
+	if metadata != nil {
+		event.Metadata = metadata
+	}
+	// Fire and forget - don't fail the request if logging fails
+	_ = s.eventRepo.Create(ctx, event)
+}
@@ -0,0 +1,558 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/pkg/oauth"
+)
+
+const (
+	testRedirectURI  = "https://reservas.example.com/cb"
+	testCodeVerifier = "dBjftJeZ4CVP-mJ92K9ZBuTxmfPmOgS1mWXGmAu8VkV"
+)
+
+// oauthTestEnv bundles an OAuthService, an AuthService that validates its
+// tokens, and a confidential client registered by a partner tenant that
+// the owner of tenantID can authorize.
+type oauthTestEnv struct {
+	svc       *OAuthService
+	auth      *AuthService
+	codes     *mock.MockOAuthCodeRepository
+	eventRepo *mock.MockAuthEventRepository
+	tenantID  uuid.UUID
+	owner     *domain.User
+	client    *domain.OAuthClient
+	secret    string
+}
+
+func setupOAuthService(t *testing.T) *oauthTestEnv {
+	t.Helper()
+
+	authSvc, userRepo, sessionRepo, tenantRepo, eventRepo := setupAuthService(t)
+	store := repository.NewMemoryTokenRevocationStore(time.Minute)
+	env := &oauthTestEnv{
+		codes:     mock.NewMockOAuthCodeRepository(),
+		eventRepo: eventRepo,
+		tenantID:  uuid.New(),
+	}
+	env.svc = NewOAuthService(OAuthServiceConfig{
+		Clients:         mock.NewMockOAuthClientRepository(),
+		Consents:        mock.NewMockOAuthConsentRepository(),
+		Codes:           env.codes,
+		RefreshTokens:   mock.NewMockOAuthRefreshTokenRepository(),
+		UserRepo:        userRepo,
+		EventRepo:       eventRepo,
+		TokenService:    authSvc.tokenService,
+		RevocationStore: store,
+	})
+	env.auth = NewAuthService(AuthServiceConfig{
+		UserRepo:        userRepo,
+		SessionRepo:     sessionRepo,
+		EventRepo:       eventRepo,
+		TenantRepo:      tenantRepo,
+		RoleRepo:        mock.NewMockUserTenantRoleRepository(),
+		TokenService:    authSvc.tokenService,
+		RevocationStore: store,
+		OAuth:           env.svc,
+	})
+
+	env.owner = &domain.User{
+		ID:       uuid.New(),
+		Email:    "owner@example.com",
+		IsActive: true,
+		TenantRoles: []domain.UserTenantRole{
+			{TenantID: env.tenantID, Role: domain.RoleOwner},
+		},
+	}
+	userRepo.AddUser(env.owner)
+
+	client, secret, err := env.svc.CreateClient(context.Background(), CreateOAuthClientRequest{
+		TenantID:     uuid.New(),
+		Name:         " Reservas Online ",
+		RedirectURIs: []string{testRedirectURI, testRedirectURI},
+		Scopes:       []domain.Permission{domain.PermTerminalsManage, domain.PermUsersManage},
+		CreatedBy:    uuid.New(),
+	}, domain.RoleAdmin.Permissions())
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	env.client, env.secret = client, secret
+	return env
+}
+
+// authorizeRequest returns an authorization request from the env's owner
+// for the env's client.
+func (e *oauthTestEnv) authorizeRequest(scope string) AuthorizeRequest {
+	sum := sha256.Sum256([]byte(testCodeVerifier))
+	return AuthorizeRequest{
+		TenantID:            e.tenantID,
+		UserID:              e.owner.ID,
+		ClientID:            e.client.ID.String(),
+		RedirectURI:         testRedirectURI,
+		ResponseType:        oauth.ResponseTypeCode,
+		Scope:               scope,
+		State:               "xyz",
+		CodeChallenge:       base64.RawURLEncoding.EncodeToString(sum[:]),
+		CodeChallengeMethod: oauth.CodeChallengeMethodS256,
+	}
+}
+
+// authorize approves an authorization request and returns the code.
+func (e *oauthTestEnv) authorize(t *testing.T, scope string) string {
+	t.Helper()
+	redirectTo, err := e.svc.Authorize(context.Background(), e.authorizeRequest(scope), true, e.owner.GetPermissionsForTenant(e.tenantID))
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+	query := redirectQuery(t, redirectTo)
+	if query.Get("code") == "" || query.Get("state") != "xyz" {
+		t.Fatalf("Authorize redirected to %s, want a code and the state", redirectTo)
+	}
+	return query.Get("code")
+}
+
+// exchange exchanges an authorization code for tokens.
+func (e *oauthTestEnv) exchange(code string) (*oauth.TokenResponse, error) {
+	return e.svc.Token(context.Background(), TokenRequest{
+		GrantType:    oauth.GrantAuthorizationCode,
+		ClientID:     e.client.ID.String(),
+		ClientSecret: e.secret,
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testCodeVerifier,
+	})
+}
+
+// refresh exchanges a refresh token for new tokens.
+func (e *oauthTestEnv) refresh(refreshToken, scope string) (*oauth.TokenResponse, error) {
+	return e.svc.Token(context.Background(), TokenRequest{
+		GrantType:    oauth.GrantRefreshToken,
+		ClientID:     e.client.ID.String(),
+		ClientSecret: e.secret,
+		RefreshToken: refreshToken,
+		Scope:        scope,
+	})
+}
+
+func redirectQuery(t *testing.T, redirectTo string) url.Values {
+	t.Helper()
+	u, err := url.Parse(redirectTo)
+	if err != nil {
+		t.Fatalf("invalid redirect %q: %v", redirectTo, err)
+	}
+	if !strings.HasPrefix(redirectTo, testRedirectURI+"?") {
+		t.Errorf("redirect %q should go to the client's redirect URI", redirectTo)
+	}
+	return u.Query()
+}
+
+func assertOAuthError(t *testing.T, err error, code string) {
+	t.Helper()
+	var oerr *oauth.Error
+	if !errors.As(err, &oerr) || oerr.Code != code {
+		t.Errorf("error = %v, want oauth error %s", err, code)
+	}
+}
+
+func TestOAuthService_CreateClient(t *testing.T) {
+	env := setupOAuthService(t)
+	ctx := context.Background()
+
+	if env.client.Name != "Reservas Online" || len(env.client.RedirectURIs) != 1 {
+		t.Errorf("client = %+v, want a trimmed name and deduplicated redirect URIs", env.client)
+	}
+	if env.secret == "" || env.client.SecretHash == "" || env.client.SecretHash == env.secret {
+		t.Error("confidential client should get a secret, stored hashed")
+	}
+
+	public, secret, err := env.svc.CreateClient(ctx, CreateOAuthClientRequest{
+		TenantID: env.tenantID, Name: "Delivery app", RedirectURIs: []string{"http://localhost:8080/cb"}, Public: true,
+	}, nil)
+	if err != nil || secret != "" || public.SecretHash != "" {
+		t.Errorf("public client = %+v, %q, %v, want no secret", public, secret, err)
+	}
+	if _, err := env.svc.RotateClientSecret(ctx, env.tenantID, public.ID, uuid.New(), ""); err != domain.ErrOAuthClientPublic {
+		t.Errorf("RotateClientSecret of a public client error = %v, want ErrOAuthClientPublic", err)
+	}
+
+	tests := []struct {
+		name string
+		req  CreateOAuthClientRequest
+		want error
+	}{
+		{"no name", CreateOAuthClientRequest{RedirectURIs: []string{testRedirectURI}}, domain.ErrOAuthClientName},
+		{"no redirect uri", CreateOAuthClientRequest{Name: "App"}, domain.ErrOAuthRedirectURI},
+		{"http redirect uri", CreateOAuthClientRequest{Name: "App", RedirectURIs: []string{"http://reservas.example.com/cb"}}, domain.ErrOAuthRedirectURI},
+		{"scope not held", CreateOAuthClientRequest{Name: "App", RedirectURIs: []string{testRedirectURI}, Scopes: []domain.Permission{domain.PermUsersImpersonate}}, domain.ErrPermissionNotHeld},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := env.svc.CreateClient(ctx, tt.req, domain.RoleManager.Permissions()); !errors.Is(err, tt.want) {
+				t.Errorf("CreateClient error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOAuthService_RotateClientSecret(t *testing.T) {
+	env := setupOAuthService(t)
+	ctx := context.Background()
+
+	if _, err := env.svc.RotateClientSecret(ctx, env.tenantID, env.client.ID, uuid.New(), ""); !errors.Is(err, domain.ErrOAuthClientNotFound) {
+		t.Errorf("RotateClientSecret from another tenant error = %v, want ErrOAuthClientNotFound", err)
+	}
+	secret, err := env.svc.RotateClientSecret(ctx, env.client.TenantID, env.client.ID, uuid.New(), "")
+	if err != nil {
+		t.Fatalf("RotateClientSecret failed: %v", err)
+	}
+
+	req := TokenRequest{GrantType: oauth.GrantClientCredentials, ClientID: env.client.ID.String(), ClientSecret: env.secret}
+	if _, err := env.svc.Token(ctx, req); err == nil {
+		t.Error("old secret should stop working")
+	} else {
+		assertOAuthError(t, err, oauth.ErrorInvalidClient)
+	}
+	req.ClientSecret = secret
+	if _, err := env.svc.Token(ctx, req); err != nil {
+		t.Errorf("new secret should work: %v", err)
+	}
+}
+
+func TestOAuthService_ValidateAuthorization(t *testing.T) {
+	env := setupOAuthService(t)
+	ctx := context.Background()
+	perms := env.owner.GetPermissionsForTenant(env.tenantID)
+
+	auth, err := env.svc.ValidateAuthorization(ctx, env.authorizeRequest(""), perms)
+	if err != nil || auth.RedirectTo != "" {
+		t.Fatalf("ValidateAuthorization = %+v, %v", auth, err)
+	}
+	if len(auth.Scopes) != 2 || auth.Consented != nil {
+		t.Errorf("Scopes = %v, Consented = %v, want the client's scopes and no consent yet", auth.Scopes, auth.Consented)
+	}
+
+	// The user can't be sent back to an unknown client or redirect URI
+	req := env.authorizeRequest("")
+	req.ClientID = uuid.NewString()
+	if _, err := env.svc.ValidateAuthorization(ctx, req, perms); !errors.Is(err, domain.ErrOAuthClientNotFound) {
+		t.Errorf("unknown client error = %v, want ErrOAuthClientNotFound", err)
+	}
+	req = env.authorizeRequest("")
+	req.RedirectURI = "https://evil.example.com/cb"
+	if _, err := env.svc.ValidateAuthorization(ctx, req, perms); !errors.Is(err, domain.ErrOAuthRedirectURI) {
+		t.Errorf("unregistered redirect URI error = %v, want ErrOAuthRedirectURI", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*AuthorizeRequest)
+		perms  []domain.Permission
+		want   string
+	}{
+		{"token response type", func(r *AuthorizeRequest) { r.ResponseType = "token" }, perms, oauth.ErrorUnsupportedResponseType},
+		{"no pkce", func(r *AuthorizeRequest) { r.CodeChallenge = "" }, perms, oauth.ErrorInvalidRequest},
+		{"plain pkce", func(r *AuthorizeRequest) { r.CodeChallengeMethod = "plain" }, perms, oauth.ErrorInvalidRequest},
+		{"scope beyond client", func(r *AuthorizeRequest) { r.Scope = string(domain.PermUsersImpersonate) }, perms, oauth.ErrorInvalidScope},
+		{"scope beyond user", func(r *AuthorizeRequest) {}, domain.RoleWaiter.Permissions(), oauth.ErrorInvalidScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := env.authorizeRequest("")
+			req.RedirectURI = "" // defaults to the client's only redirect URI
+			tt.modify(&req)
+			auth, err := env.svc.ValidateAuthorization(ctx, req, tt.perms)
+			if err != nil {
+				t.Fatalf("ValidateAuthorization error = %v, want a redirect", err)
+			}
+			query := redirectQuery(t, auth.RedirectTo)
+			if query.Get("error") != tt.want || query.Get("state") != "xyz" {
+				t.Errorf("redirect = %s, want error %s with the state", auth.RedirectTo, tt.want)
+			}
+		})
+	}
+}
+
+func TestOAuthService_Authorize_Deny(t *testing.T) {
+	env := setupOAuthService(t)
+	ctx := context.Background()
+
+	redirectTo, err := env.svc.Authorize(ctx, env.authorizeRequest(""), false, env.owner.GetPermissionsForTenant(env.tenantID))
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+	if query := redirectQuery(t, redirectTo); query.Get("error") != oauth.ErrorAccessDenied {
+		t.Errorf("redirect = %s, want access_denied", redirectTo)
+	}
+	if consents, _ := env.svc.ListConsents(ctx, env.tenantID); len(consents) != 0 {
+		t.Errorf("denying should record no consent, got %d", len(consents))
+	}
+}
+
+func TestOAuthService_AuthorizationCodeFlow(t *testing.T) {
+	env := setupOAuthService(t)
+	ctx := context.Background()
+
+	code := env.authorize(t, string(domain.PermTerminalsManage))
+	resp, err := env.exchange(code)
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+	if resp.TokenType != oauth.TokenTypeBearer || resp.RefreshToken == "" || resp.Scope != string(domain.PermTerminalsManage) || resp.ExpiresIn <= 0 {
+		t.Errorf("token response = %+v", resp)
+	}
+
+	claims, err := env.auth.ValidateToken(ctx, resp.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if claims.Subject != env.owner.ID.String() || claims.TenantID != env.tenantID || claims.ClientID != env.client.ID.String() {
+		t.Errorf("claims = %+v, want the owner in the tenant, via the client", claims)
+	}
+	if claims.Role != domain.OAuthClientRole || claims.Email != "" {
+		t.Errorf("claims role = %q, email = %q, want the client role and no email", claims.Role, claims.Email)
+	}
+	if !claims.HasPermission(domain.PermTerminalsManage) || claims.HasPermission(domain.PermUsersManage) {
+		t.Errorf("claims permissions = %v, want only the approved scope", claims.Permissions)
+	}
+
+	consents, _ := env.svc.ListConsents(ctx, env.tenantID)
+	if len(consents) != 1 || consents[0].GrantedBy != env.owner.ID {
+		t.Fatalf("ListConsents = %+v, want the owner's consent", consents)
+	}
+
+	// Approving again replaces the consent's scopes rather than adding one
+	env.authorize(t, "")
+	if consents, _ := env.svc.ListConsents(ctx, env.tenantID); len(consents) != 1 || len(consents[0].Scopes) != 2 {
+		t.Errorf("ListConsents = %+v, want one consent with both scopes", consents)
+	}
+
+	// Replaying the code revokes the refresh token it was exchanged for
+	_, err = env.exchange(code)
+	assertOAuthError(t, err, oauth.ErrorInvalidGrant)
+	_, err = env.refresh(resp.RefreshToken, "")
+	assertOAuthError(t, err, oauth.ErrorInvalidGrant)
+
+	var reused bool
+	for _, e := range env.eventRepo.GetEvents() {
+		reused = reused || e.EventType == domain.EventOAuthTokenReused
+	}
+	if !reused {
+		t.Error("expected an oauth_token_reused event")
+	}
+}
+
+func TestOAuthService_ExchangeCode_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*TokenRequest, *oauthTestEnv)
+		want   string
+	}{
+		{"wrong verifier", func(r *TokenRequest, _ *oauthTestEnv) { r.CodeVerifier = strings.Repeat("a", 43) }, oauth.ErrorInvalidGrant},
+		{"no verifier", func(r *TokenRequest, _ *oauthTestEnv) { r.CodeVerifier = "" }, oauth.ErrorInvalidRequest},
+		{"wrong redirect uri", func(r *TokenRequest, _ *oauthTestEnv) { r.RedirectURI = testRedirectURI + "/" }, oauth.ErrorInvalidGrant},
+		{"unknown code", func(r *TokenRequest, _ *oauthTestEnv) { r.Code = "nope" }, oauth.ErrorInvalidGrant},
+		{"wrong secret", func(r *TokenRequest, _ *oauthTestEnv) { r.ClientSecret = "nope" }, oauth.ErrorInvalidClient},
+		{"no secret", func(r *TokenRequest, _ *oauthTestEnv) { r.ClientSecret = "" }, oauth.ErrorInvalidClient},
+		{"expired", func(r *TokenRequest, e *oauthTestEnv) {
+			code, _ := e.codes.FindByHash(context.Background(), e.svc.passwordSvc.HashResetToken(r.Code))
+			code.ExpiresAt = time.Now().Add(-time.Second)
+		}, oauth.ErrorInvalidGrant},
+		{"user left tenant", func(_ *TokenRequest, e *oauthTestEnv) { e.owner.TenantRoles = nil }, oauth.ErrorInvalidGrant},
+		{"user deactivated", func(_ *TokenRequest, e *oauthTestEnv) { e.owner.IsActive = false }, oauth.ErrorInvalidGrant},
+		{"unsupported grant", func(r *TokenRequest, _ *oauthTestEnv) { r.GrantType = "password" }, oauth.ErrorUnsupportedGrantType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupOAuthService(t)
+			req := TokenRequest{
+				GrantType:    oauth.GrantAuthorizationCode,
+				ClientID:     env.client.ID.String(),
+				ClientSecret: env.secret,
+				Code:         env.authorize(t, ""),
+				RedirectURI:  testRedirectURI,
+				CodeVerifier: testCodeVerifier,
+			}
+			tt.modify(&req, env)
+			_, err := env.svc.Token(context.Background(), req)
+			assertOAuthError(t, err, tt.want)
+		})
+	}
+}
+
+func TestOAuthService_Refresh(t *testing.T) {
+	env := setupOAuthService(t)
+	ctx := context.Background()
+
+	first, err := env.exchange(env.authorize(t, ""))
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+
+	// Narrowing the access token keeps the refresh token's scopes
+	second, err := env.refresh(first.RefreshToken, string(domain.PermUsersManage))
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if second.Scope != string(domain.PermUsersManage) || second.RefreshToken == first.RefreshToken {
+		t.Errorf("refresh = %+v, want a narrowed scope and a rotated refresh token", second)
+	}
+	third, err := env.refresh(second.RefreshToken, "")
+	if err != nil {
+		t.Fatalf("second refresh failed: %v", err)
+	}
+	if third.Scope != "terminals.manage users.manage" {
+		t.Errorf("Scope = %q, want both scopes back", third.Scope)
+	}
+
+	_, err = env.refresh(third.RefreshToken, string(domain.PermUsersImpersonate))
+	assertOAuthError(t, err, oauth.ErrorInvalidScope)
+
+	// Replaying a rotated token revokes the family, including the latest
+	_, err = env.refresh(first.RefreshToken, "")
+	assertOAuthError(t, err, oauth.ErrorInvalidGrant)
+	_, err = env.refresh(third.RefreshToken, "")
+	assertOAuthError(t, err, oauth.ErrorInvalidGrant)
+
+	// Another client can't use the token
+	other, otherSecret, _ := env.svc.CreateClient(ctx, CreateOAuthClientRequest{
+		TenantID: env.client.TenantID, Name: "Other", RedirectURIs: []string{testRedirectURI},
+	}, nil)
+	fresh, _ := env.exchange(env.authorize(t, ""))
+	_, err = env.svc.Token(ctx, TokenRequest{
+		GrantType: oauth.GrantRefreshToken, ClientID: other.ID.String(), ClientSecret: otherSecret, RefreshToken: fresh.RefreshToken,
+	})
+	assertOAuthError(t, err, oauth.ErrorInvalidGrant)
+}
+
+func TestOAuthService_ClientCredentials(t *testing.T) {
+	env := setupOAuthService(t)
+	ctx := context.Background()
+
+	resp, err := env.svc.Token(ctx, TokenRequest{
+		GrantType: oauth.GrantClientCredentials, ClientID: env.client.ID.String(), ClientSecret: env.secret, Scope: string(domain.PermTerminalsManage),
+	})
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if resp.RefreshToken != "" || resp.Scope != string(domain.PermTerminalsManage) {
+		t.Errorf("token response = %+v, want a narrowed scope and no refresh token", resp)
+	}
+	claims, err := env.auth.ValidateToken(ctx, resp.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if claims.Subject != env.client.ID.String() || claims.TenantID != env.client.TenantID {
+		t.Errorf("claims = %+v, want the client itself in its registering tenant", claims)
+	}
+
+	_, err = env.svc.Token(ctx, TokenRequest{
+		GrantType: oauth.GrantClientCredentials, ClientID: env.client.ID.String(), ClientSecret: env.secret, Scope: string(domain.PermUsersImpersonate),
+	})
+	assertOAuthError(t, err, oauth.ErrorInvalidScope)
+
+	public, _, _ := env.svc.CreateClient(ctx, CreateOAuthClientRequest{
+		TenantID: env.tenantID, Name: "Delivery app", RedirectURIs: []string{testRedirectURI}, Public: true,
+	}, nil)
+	_, err = env.svc.Token(ctx, TokenRequest{GrantType: oauth.GrantClientCredentials, ClientID: public.ID.String()})
+	assertOAuthError(t, err, oauth.ErrorUnauthorizedClient)
+
+	// Deleting the client ends its tokens
+	if err := env.svc.DeleteClient(ctx, env.client.TenantID, env.client.ID, uuid.New(), ""); err != nil {
+		t.Fatalf("DeleteClient failed: %v", err)
+	}
+	if _, err := env.auth.ValidateToken(ctx, resp.AccessToken); err != domain.ErrTokenRevoked {
+		t.Errorf("ValidateToken after DeleteClient error = %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestOAuthService_RevokeConsent(t *testing.T) {
+	env := setupOAuthService(t)
+	ctx := context.Background()
+
+	resp, err := env.exchange(env.authorize(t, ""))
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+	consents, _ := env.svc.ListConsents(ctx, env.tenantID)
+
+	if err := env.svc.RevokeConsent(ctx, uuid.New(), consents[0].ID, env.owner.ID, ""); !errors.Is(err, domain.ErrOAuthConsentNotFound) {
+		t.Errorf("RevokeConsent from another tenant error = %v, want ErrOAuthConsentNotFound", err)
+	}
+	if err := env.svc.RevokeConsent(ctx, env.tenantID, consents[0].ID, env.owner.ID, ""); err != nil {
+		t.Fatalf("RevokeConsent failed: %v", err)
+	}
+
+	if _, err := env.auth.ValidateToken(ctx, resp.AccessToken); err != domain.ErrTokenRevoked {
+		t.Errorf("ValidateToken after RevokeConsent error = %v, want ErrTokenRevoked", err)
+	}
+	_, err = env.refresh(resp.RefreshToken, "")
+	assertOAuthError(t, err, oauth.ErrorInvalidGrant)
+	if err := env.svc.RevokeConsent(ctx, env.tenantID, consents[0].ID, env.owner.ID, ""); !errors.Is(err, domain.ErrOAuthConsentNotFound) {
+		t.Errorf("second RevokeConsent error = %v, want ErrOAuthConsentNotFound", err)
+	}
+}
+
+func TestOAuthService_ValidateClientToken_NarrowedConsent(t *testing.T) {
+	env := setupOAuthService(t)
+	ctx := context.Background()
+
+	resp, _ := env.exchange(env.authorize(t, ""))
+	env.authorize(t, string(domain.PermTerminalsManage))
+
+	if _, err := env.auth.ValidateToken(ctx, resp.AccessToken); err != domain.ErrTokenRevoked {
+		t.Errorf("ValidateToken error = %v, want ErrTokenRevoked once the consent no longer covers the token", err)
+	}
+}
+
+func TestOAuthService_RevokeToken(t *testing.T) {
+	env := setupOAuthService(t)
+	ctx := context.Background()
+
+	resp, _ := env.exchange(env.authorize(t, ""))
+	revoke := func(token, hint, secret string) error {
+		return env.svc.RevokeToken(ctx, RevokeTokenRequest{
+			ClientID: env.client.ID.String(), ClientSecret: secret, Token: token, TokenTypeHint: hint,
+		})
+	}
+
+	assertOAuthError(t, revoke(resp.AccessToken, "", "nope"), oauth.ErrorInvalidClient)
+	if err := revoke("unknown-token", "", env.secret); err != nil {
+		t.Errorf("revoking an unknown token should succeed: %v", err)
+	}
+
+	// The hint is only a hint
+	if err := revoke(resp.AccessToken, oauth.TokenHintRefreshToken, env.secret); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+	if _, err := env.auth.ValidateToken(ctx, resp.AccessToken); err != domain.ErrTokenRevoked {
+		t.Errorf("ValidateToken after revoke error = %v, want ErrTokenRevoked", err)
+	}
+
+	if err := revoke(resp.RefreshToken, "", env.secret); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+	_, err := env.refresh(resp.RefreshToken, "")
+	assertOAuthError(t, err, oauth.ErrorInvalidGrant)
+}
+
+func TestAuthService_ValidateToken_ClientTokenWithoutOAuth(t *testing.T) {
+	env := setupOAuthService(t)
+	resp, _ := env.exchange(env.authorize(t, ""))
+
+	authSvc, _, _, _, _ := setupAuthService(t)
+	authSvc.tokenService = env.auth.tokenService
+	if _, err := authSvc.ValidateToken(context.Background(), resp.AccessToken); err != domain.ErrTokenInvalid {
+		t.Errorf("ValidateToken error = %v, want ErrTokenInvalid without an OAuth service", err)
+	}
+}
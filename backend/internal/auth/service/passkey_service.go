@@ -0,0 +1,597 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+	"github.com/solobueno/erp/pkg/webauthn"
+)
+
+// LoginMethodPasskey is recorded in login event metadata for passwordless
+// passkey logins.
+const LoginMethodPasskey = domain.SignInPasskey
+
+// PasskeyService handles WebAuthn passkeys: registering them to a signed-in
+// user, passwordless login with one, and using one as the second factor
+// after a password. Every ceremony is a begin/finish pair: begin returns the
+// options for the browser's WebAuthn API along with a single-use challenge,
+// finish verifies what the authenticator signed.
+type PasskeyService struct {
+	passkeys    repository.PasskeyRepository
+	challenges  repository.PasskeyChallengeRepository
+	userRepo    repository.UserRepository
+	tenantRepo  repository.TenantRepository
+	eventRepo   repository.AuthEventRepository
+	authService *AuthService
+	mfaService  *MFAService
+	rateLimiter RateLimiter
+	passwordSvc *PasswordService
+	webauthn    webauthn.Config
+}
+
+// PasskeyServiceConfig holds configuration for PasskeyService.
+type PasskeyServiceConfig struct {
+	Passkeys   repository.PasskeyRepository
+	Challenges repository.PasskeyChallengeRepository
+	UserRepo   repository.UserRepository
+	TenantRepo repository.TenantRepository
+	EventRepo  repository.AuthEventRepository
+	// AuthService issues the session once a passkey has signed the user in.
+	AuthService *AuthService
+	// MFAService holds the login challenges a passkey can complete.
+	MFAService *MFAService
+	// RateLimiter limits passwordless login attempts per IP. Optional.
+	RateLimiter RateLimiter
+	// WebAuthn identifies this server to authenticators. With no RPID,
+	// every ceremony fails with ErrPasskeysUnavailable.
+	WebAuthn webauthn.Config
+}
+
+// NewPasskeyService creates a new PasskeyService.
+func NewPasskeyService(cfg PasskeyServiceConfig) *PasskeyService {
+	return &PasskeyService{
+		passkeys:    cfg.Passkeys,
+		challenges:  cfg.Challenges,
+		userRepo:    cfg.UserRepo,
+		tenantRepo:  cfg.TenantRepo,
+		eventRepo:   cfg.EventRepo,
+		authService: cfg.AuthService,
+		mfaService:  cfg.MFAService,
+		rateLimiter: cfg.RateLimiter,
+		passwordSvc: NewPasswordService(),
+		webauthn:    cfg.WebAuthn,
+	}
+}
+
+// Enabled reports whether a relying party is configured.
+func (s *PasskeyService) Enabled() bool {
+	return s.webauthn.RPID != ""
+}
+
+// List returns a user's passkeys, oldest first.
+func (s *PasskeyService) List(ctx context.Context, userID uuid.UUID) ([]*domain.Passkey, error) {
+	passkeys, err := s.passkeys.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list passkeys: %w", err)
+	}
+	return passkeys, nil
+}
+
+// BeginRegistration starts adding a passkey to a signed-in user, whose role
+// in the current tenant must allow passkey login. The options exclude the
+// user's existing passkeys, so an authenticator isn't registered twice.
+func (s *PasskeyService) BeginRegistration(ctx context.Context, userID uuid.UUID, role domain.Role) (*webauthn.CreationOptions, error) {
+	if !s.Enabled() {
+		return nil, domain.ErrPasskeysUnavailable
+	}
+	if !role.AllowsPasskeyLogin() {
+		return nil, domain.ErrPasskeyNotAllowed
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("passkey register: user lookup: %w", err)
+	}
+	existing, err := s.passkeys.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("passkey register: %w", err)
+	}
+	if len(existing) >= domain.MaxPasskeysPerUser {
+		return nil, domain.ErrPasskeyLimit
+	}
+
+	challenge, err := s.createChallenge(ctx, domain.PasskeyPurposeRegister, &userID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("passkey register: %w", err)
+	}
+
+	return s.webauthn.CreationOptions(webauthn.User{
+		ID:          userID[:],
+		Name:        user.Email,
+		DisplayName: user.FullName(),
+	}, challenge, descriptors(existing)), nil
+}
+
+// FinishPasskeyRegistrationRequest contains the authenticator's answer to
+// BeginRegistration.
+type FinishPasskeyRegistrationRequest struct {
+	UserID     uuid.UUID
+	TenantID   uuid.UUID // Tenant of the session, for the audit trail
+	Role       domain.Role
+	Name       string // Optional; defaults to "Passkey"
+	Credential *webauthn.RegistrationResponse
+	IPAddress  string
+	UserAgent  string
+}
+
+// FinishRegistration verifies a new credential and stores it as a passkey.
+func (s *PasskeyService) FinishRegistration(ctx context.Context, req FinishPasskeyRegistrationRequest) (*domain.Passkey, error) {
+	if !s.Enabled() {
+		return nil, domain.ErrPasskeysUnavailable
+	}
+	if !req.Role.AllowsPasskeyLogin() {
+		return nil, domain.ErrPasskeyNotAllowed
+	}
+	name, err := domain.NormalizePasskeyName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if req.Credential == nil {
+		return nil, domain.ErrPasskeyInvalid
+	}
+
+	challenge, record, err := s.consumeChallenge(ctx, req.Credential.Challenge, domain.PasskeyPurposeRegister)
+	if err != nil {
+		return nil, err
+	}
+	if record.UserID == nil || *record.UserID != req.UserID {
+		return nil, domain.ErrPasskeyChallengeInvalid
+	}
+
+	cred, err := s.webauthn.VerifyRegistration(req.Credential, challenge)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrPasskeyInvalid, err)
+	}
+
+	// Re-check the limit: several registrations may have been started at once
+	count, err := s.passkeys.CountByUser(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("passkey register: %w", err)
+	}
+	if count >= domain.MaxPasskeysPerUser {
+		return nil, domain.ErrPasskeyLimit
+	}
+	if _, err := s.passkeys.FindByCredentialID(ctx, cred.ID); err == nil {
+		return nil, domain.ErrPasskeyExists
+	} else if !errors.Is(err, domain.ErrPasskeyNotFound) {
+		return nil, fmt.Errorf("passkey register: credential lookup: %w", err)
+	}
+
+	passkey := &domain.Passkey{
+		ID:             uuid.New(),
+		UserID:         req.UserID,
+		CredentialID:   cred.ID,
+		PublicKey:      cred.PublicKey,
+		SignCount:      cred.SignCount,
+		AAGUID:         cred.AAGUID,
+		Name:           name,
+		Transports:     cred.Transports,
+		BackupEligible: cred.BackupEligible,
+		BackedUp:       cred.BackedUp,
+	}
+	if err := s.passkeys.Create(ctx, passkey); err != nil {
+		return nil, fmt.Errorf("passkey register: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventPasskeyRegistered, &req.UserID, &req.TenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+		"passkey_id":      passkey.ID.String(),
+		"name":            passkey.Name,
+		"backup_eligible": passkey.BackupEligible,
+	})
+
+	return passkey, nil
+}
+
+// RenamePasskeyRequest contains the data needed to rename a passkey.
+type RenamePasskeyRequest struct {
+	UserID    uuid.UUID
+	TenantID  uuid.UUID // Tenant of the session, for the audit trail
+	PasskeyID uuid.UUID
+	Name      string
+	IPAddress string
+	UserAgent string
+}
+
+// Rename changes the name of one of a user's passkeys.
+func (s *PasskeyService) Rename(ctx context.Context, req RenamePasskeyRequest) (*domain.Passkey, error) {
+	name, err := domain.NormalizePasskeyName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	passkey, err := s.passkeys.FindByID(ctx, req.UserID, req.PasskeyID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPasskeyNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("rename passkey: %w", err)
+	}
+	if passkey.Name == name {
+		return passkey, nil
+	}
+
+	oldName := passkey.Name
+	passkey.Name = name
+	if err := s.passkeys.Update(ctx, passkey); err != nil {
+		return nil, fmt.Errorf("rename passkey: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventPasskeyRenamed, &req.UserID, &req.TenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+		"passkey_id": passkey.ID.String(),
+		"old_name":   oldName,
+		"new_name":   name,
+	})
+
+	return passkey, nil
+}
+
+// RemovePasskeyRequest contains the data needed to remove a passkey.
+type RemovePasskeyRequest struct {
+	UserID    uuid.UUID
+	TenantID  uuid.UUID // Tenant of the session, for the audit trail
+	PasskeyID uuid.UUID
+	IPAddress string
+	UserAgent string
+}
+
+// Remove deletes one of a user's passkeys. Removing the last one leaves a
+// user whose role requires MFA to enroll again at their next login.
+func (s *PasskeyService) Remove(ctx context.Context, req RemovePasskeyRequest) error {
+	passkey, err := s.passkeys.FindByID(ctx, req.UserID, req.PasskeyID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPasskeyNotFound) {
+			return err
+		}
+		return fmt.Errorf("remove passkey: %w", err)
+	}
+	if err := s.passkeys.Delete(ctx, req.UserID, req.PasskeyID); err != nil {
+		if errors.Is(err, domain.ErrPasskeyNotFound) {
+			return err
+		}
+		return fmt.Errorf("remove passkey: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventPasskeyRemoved, &req.UserID, &req.TenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+		"passkey_id": passkey.ID.String(),
+		"name":       passkey.Name,
+	})
+	return nil
+}
+
+// BeginLogin starts a passwordless login. The options carry no allow list:
+// the browser offers the user's discoverable passkeys, and the one chosen
+// identifies the user.
+func (s *PasskeyService) BeginLogin(ctx context.Context) (*webauthn.RequestOptions, error) {
+	if !s.Enabled() {
+		return nil, domain.ErrPasskeysUnavailable
+	}
+
+	challenge, err := s.createChallenge(ctx, domain.PasskeyPurposeLogin, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("passkey login: %w", err)
+	}
+	return s.webauthn.RequestOptions(challenge, nil), nil
+}
+
+// FinishPasskeyLoginRequest contains the authenticator's answer to
+// BeginLogin.
+type FinishPasskeyLoginRequest struct {
+	Credential *webauthn.AssertionResponse
+	TenantID   *uuid.UUID // Optional - required if user has multiple tenants
+	IPAddress  string
+	UserAgent  string
+}
+
+// FinishLogin verifies a passkey assertion and issues a session. The
+// authenticator verified the user with a PIN or biometric, so the passkey
+// counts as both factors and no MFA challenge follows.
+//
+// The tenant is chosen as in AuthService.Login, and the user's role there
+// must allow passkey login. A tenant that has turned password login off in
+// favour of SSO turns passkey login off with it. A multi-tenant user who
+// named no tenant gets ErrTenantRequired with their tenants, and must run a
+// new ceremony naming one.
+func (s *PasskeyService) FinishLogin(ctx context.Context, req FinishPasskeyLoginRequest) (*LoginResponse, error) {
+	if !s.Enabled() {
+		return nil, domain.ErrPasskeysUnavailable
+	}
+
+	// Check rate limit
+	if s.rateLimiter != nil {
+		allowed, err := s.rateLimiter.Allow(ctx, req.IPAddress)
+		if err != nil {
+			return nil, fmt.Errorf("passkey login: rate limit check: %w", err)
+		}
+		if !allowed {
+			s.logEvent(ctx, domain.EventLoginFailed, nil, nil, req.IPAddress, req.UserAgent, map[string]interface{}{
+				"method": LoginMethodPasskey,
+				"reason": "rate_limit_exceeded",
+			})
+			return nil, domain.ErrRateLimitExceeded
+		}
+	}
+	if req.Credential == nil {
+		return nil, domain.ErrPasskeyInvalid
+	}
+
+	challenge, _, err := s.consumeChallenge(ctx, req.Credential.Challenge, domain.PasskeyPurposeLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	fail := func(userID, tenantID *uuid.UUID, reason string, err error) error {
+		s.logEvent(ctx, domain.EventLoginFailed, userID, tenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+			"method": LoginMethodPasskey,
+			"reason": reason,
+		})
+		return err
+	}
+
+	passkey, err := s.passkeys.FindByCredentialID(ctx, req.Credential.RawID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPasskeyNotFound) {
+			return nil, fail(nil, nil, "unknown_credential", domain.ErrPasskeyInvalid)
+		}
+		return nil, fmt.Errorf("passkey login: credential lookup: %w", err)
+	}
+	// A discoverable credential names its user; it must be the owner on record
+	userHandle := req.Credential.Response.UserHandle
+	if len(userHandle) > 0 && !bytes.Equal(userHandle, passkey.UserID[:]) {
+		return nil, fail(&passkey.UserID, nil, "user_handle_mismatch", domain.ErrPasskeyInvalid)
+	}
+
+	user, err := s.userRepo.FindByIDWithTenants(ctx, passkey.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("passkey login: user lookup: %w", err)
+	}
+	if user.IsLocked() {
+		s.logEvent(ctx, domain.EventLoginFailed, &user.ID, nil, req.IPAddress, req.UserAgent, map[string]interface{}{
+			"method": LoginMethodPasskey,
+			"reason": "account_locked",
+		})
+		return &LoginResponse{LockedUntil: user.LockedUntil}, domain.ErrAccountLocked
+	}
+	if !user.CanLogin() {
+		return nil, fail(&user.ID, nil, "account_disabled", domain.ErrAccountDisabled)
+	}
+
+	if err := s.verifyAssertion(ctx, passkey, req.Credential, challenge, nil, req.IPAddress, req.UserAgent); err != nil {
+		return nil, err
+	}
+
+	// Handle tenant selection
+	tenantID, role, tenants, err := selectTenant(user, req.TenantID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTenantRequired) {
+			return &LoginResponse{User: user, Tenants: tenants}, err
+		}
+		return nil, err
+	}
+
+	// Verify tenant is active
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("passkey login: tenant lookup: %w", err)
+	}
+	if !tenant.IsOperational() {
+		return nil, domain.ErrTenantInactive
+	}
+
+	if !role.AllowsPasskeyLogin() {
+		return nil, fail(&user.ID, &tenantID, "role_not_allowed", domain.ErrPasskeyNotAllowed)
+	}
+	allowed, err := s.authService.passwordLoginAllowed(ctx, tenantID, role)
+	if err != nil {
+		return nil, fmt.Errorf("passkey login: %w", err)
+	}
+	if !allowed {
+		return nil, fail(&user.ID, &tenantID, "password_login_disabled", domain.ErrPasswordLoginDisabled)
+	}
+
+	resp, err := s.authService.issueSession(ctx, user, tenantID, role, domain.SignInPasskey, req.IPAddress, req.UserAgent, map[string]interface{}{
+		"method":     LoginMethodPasskey,
+		"passkey_id": passkey.ID.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("passkey login: %w", err)
+	}
+	return resp, nil
+}
+
+// BeginMFA starts answering a login challenge (from ErrMFARequired) with a
+// passkey. The options allow only the user's own passkeys.
+func (s *PasskeyService) BeginMFA(ctx context.Context, mfaToken string) (*webauthn.RequestOptions, error) {
+	if !s.Enabled() {
+		return nil, domain.ErrPasskeysUnavailable
+	}
+
+	mfaChallenge, user, _, err := s.authService.loadMFAChallenge(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	passkeys, err := s.passkeys.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("passkey mfa: %w", err)
+	}
+	if len(passkeys) == 0 {
+		return nil, domain.ErrMFANotEnrolled
+	}
+
+	challenge, err := s.createChallenge(ctx, domain.PasskeyPurposeMFA, &user.ID, &mfaChallenge.ID)
+	if err != nil {
+		return nil, fmt.Errorf("passkey mfa: %w", err)
+	}
+	return s.webauthn.RequestOptions(challenge, descriptors(passkeys)), nil
+}
+
+// FinishPasskeyMFARequest contains the authenticator's answer to BeginMFA.
+type FinishPasskeyMFARequest struct {
+	MFAToken   string
+	Credential *webauthn.AssertionResponse
+	IPAddress  string
+	UserAgent  string
+}
+
+// FinishMFA verifies a passkey assertion against a login challenge and
+// issues the session, as AuthService.VerifyMFA does for a code. A failed
+// assertion counts against the challenge's attempts.
+func (s *PasskeyService) FinishMFA(ctx context.Context, req FinishPasskeyMFARequest) (*LoginResponse, error) {
+	if !s.Enabled() {
+		return nil, domain.ErrPasskeysUnavailable
+	}
+
+	mfaChallenge, user, role, err := s.authService.loadMFAChallenge(ctx, req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	if req.Credential == nil {
+		return nil, domain.ErrPasskeyInvalid
+	}
+
+	challenge, record, err := s.consumeChallenge(ctx, req.Credential.Challenge, domain.PasskeyPurposeMFA)
+	if err != nil {
+		return nil, err
+	}
+	if record.MFAChallengeID == nil || *record.MFAChallengeID != mfaChallenge.ID {
+		return nil, domain.ErrPasskeyChallengeInvalid
+	}
+
+	passkey, err := s.passkeys.FindByCredentialID(ctx, req.Credential.RawID)
+	if err != nil && !errors.Is(err, domain.ErrPasskeyNotFound) {
+		return nil, fmt.Errorf("passkey mfa: credential lookup: %w", err)
+	}
+	if err == nil && passkey.UserID == user.ID {
+		err = s.verifyAssertion(ctx, passkey, req.Credential, challenge, &mfaChallenge.TenantID, req.IPAddress, req.UserAgent)
+	} else {
+		err = domain.ErrPasskeyInvalid
+	}
+	if err != nil {
+		if !errors.Is(err, domain.ErrPasskeyInvalid) {
+			return nil, err
+		}
+		if err := s.mfaService.RecordFailedAttempt(ctx, mfaChallenge.ID); err != nil {
+			return nil, fmt.Errorf("passkey mfa: %w", err)
+		}
+		s.logEvent(ctx, domain.EventMFAFailed, &user.ID, &mfaChallenge.TenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+			"method": MFAMethodPasskey,
+			"reason": "invalid_passkey",
+		})
+		return nil, domain.ErrPasskeyInvalid
+	}
+
+	return s.authService.completeMFA(ctx, mfaChallenge, user, role, MFAMethodPasskey, req.IPAddress, req.UserAgent)
+}
+
+// verifyAssertion checks an assertion against a stored passkey and records
+// its use. A counter that didn't increase means the passkey may have been
+// cloned; the assertion is refused and the failure audited.
+func (s *PasskeyService) verifyAssertion(ctx context.Context, passkey *domain.Passkey, credential *webauthn.AssertionResponse, challenge []byte, tenantID *uuid.UUID, ipAddress, userAgent string) error {
+	assertion, err := s.webauthn.VerifyAssertion(credential, challenge, passkey.PublicKey, passkey.SignCount)
+	if err != nil {
+		reason := "verification_failed"
+		if errors.Is(err, webauthn.ErrSignCount) {
+			reason = "sign_count_not_increased"
+		}
+		s.logEvent(ctx, domain.EventPasskeyFailed, &passkey.UserID, tenantID, ipAddress, userAgent, map[string]interface{}{
+			"passkey_id": passkey.ID.String(),
+			"reason":     reason,
+		})
+		return fmt.Errorf("%w: %v", domain.ErrPasskeyInvalid, err)
+	}
+
+	passkey.RecordUse(assertion.SignCount, assertion.BackedUp)
+	if err := s.passkeys.Update(ctx, passkey); err != nil {
+		return fmt.Errorf("passkey: record use: %w", err)
+	}
+	return nil
+}
+
+// createChallenge generates a ceremony challenge and stores its hash.
+func (s *PasskeyService) createChallenge(ctx context.Context, purpose domain.PasskeyPurpose, userID, mfaChallengeID *uuid.UUID) ([]byte, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, fmt.Errorf("generate challenge: %w", err)
+	}
+
+	record := &domain.PasskeyChallenge{
+		ID:             uuid.New(),
+		ChallengeHash:  s.hashChallenge(challenge),
+		Purpose:        purpose,
+		UserID:         userID,
+		MFAChallengeID: mfaChallengeID,
+		ExpiresAt:      time.Now().Add(domain.PasskeyChallengeTTL),
+	}
+	if err := s.challenges.Create(ctx, record); err != nil {
+		return nil, fmt.Errorf("create challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// consumeChallenge looks up the ceremony a response claims to answer and
+// spends it before anything is verified, so a response can't be replayed.
+// It returns the challenge, which the response is then verified against.
+func (s *PasskeyService) consumeChallenge(ctx context.Context, claimed func() ([]byte, error), purpose domain.PasskeyPurpose) ([]byte, *domain.PasskeyChallenge, error) {
+	challenge, err := claimed()
+	if err != nil || len(challenge) == 0 {
+		return nil, nil, domain.ErrPasskeyChallengeInvalid
+	}
+
+	record, err := s.challenges.FindByChallenge(ctx, s.hashChallenge(challenge))
+	if err != nil {
+		if errors.Is(err, domain.ErrPasskeyChallengeInvalid) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("passkey challenge lookup: %w", err)
+	}
+	if !record.IsValid() || record.Purpose != purpose {
+		return nil, nil, domain.ErrPasskeyChallengeInvalid
+	}
+	if err := s.challenges.MarkUsed(ctx, record.ID); err != nil {
+		if errors.Is(err, domain.ErrPasskeyChallengeInvalid) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("passkey challenge: mark used: %w", err)
+	}
+	return challenge, record, nil
+}
+
+// hashChallenge hashes a challenge for storage, as reset tokens are.
+func (s *PasskeyService) hashChallenge(challenge []byte) string {
+	return s.passwordSvc.HashResetToken(base64.RawURLEncoding.EncodeToString(challenge))
+}
+
+// descriptors lists passkeys for an allow or exclude list.
+func descriptors(passkeys []*domain.Passkey) []webauthn.CredentialDescriptor {
+	list := make([]webauthn.CredentialDescriptor, 0, len(passkeys))
+	for _, p := range passkeys {
+		list = append(list, webauthn.NewCredentialDescriptor(p.CredentialID, p.Transports))
+	}
+	return list
+}
+
+// logEvent logs a passkey event.
+func (s *PasskeyService) logEvent(ctx context.Context, eventType domain.AuthEventType, userID, tenantID *uuid.UUID, ipAddress, userAgent string, metadata map[string]interface{}) {
+	event := newAuthEvent(ctx, eventType, userID, tenantID, ipAddress, userAgent)
+	if metadata != nil {
+		event.Metadata = metadata
+	}
+	// Fire and forget - don't fail the request if logging fails
+	_ = s.eventRepo.Create(ctx, event)
+}
@@ -0,0 +1,487 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/pkg/webauthn"
+	"github.com/solobueno/erp/pkg/webauthn/webauthntest"
+)
+
+const testPasskeyOrigin = "https://erp.example.com"
+
+// passkeyTestEnv bundles a PasskeyService and the AuthService and
+// MFAService it completes logins through, sharing in-memory repositories.
+type passkeyTestEnv struct {
+	svc        *PasskeyService
+	authSvc    *AuthService
+	mfaSvc     *MFAService
+	passkeys   *mock.MockPasskeyRepository
+	userRepo   *mock.MockUserRepository
+	tenantRepo *mock.MockTenantRepository
+	eventRepo  *mock.MockAuthEventRepository
+}
+
+func setupPasskeyService(t *testing.T) *passkeyTestEnv {
+	t.Helper()
+
+	base, userRepo, sessionRepo, tenantRepo, eventRepo := setupAuthService(t)
+	env := &passkeyTestEnv{
+		passkeys:   mock.NewMockPasskeyRepository(),
+		userRepo:   userRepo,
+		tenantRepo: tenantRepo,
+		eventRepo:  eventRepo,
+	}
+	env.mfaSvc = NewMFAService(MFAServiceConfig{
+		MFARepo:       mock.NewMockMFARepository(),
+		ChallengeRepo: mock.NewMockMFAChallengeRepository(),
+		EventRepo:     eventRepo,
+		Passkeys:      env.passkeys,
+	})
+	env.authSvc = NewAuthService(AuthServiceConfig{
+		UserRepo:     userRepo,
+		SessionRepo:  sessionRepo,
+		EventRepo:    eventRepo,
+		TenantRepo:   tenantRepo,
+		RoleRepo:     mock.NewMockUserTenantRoleRepository(),
+		TokenService: base.tokenService,
+		MFAService:   env.mfaSvc,
+	})
+	env.svc = NewPasskeyService(PasskeyServiceConfig{
+		Passkeys:    env.passkeys,
+		Challenges:  mock.NewMockPasskeyChallengeRepository(),
+		UserRepo:    userRepo,
+		TenantRepo:  tenantRepo,
+		EventRepo:   eventRepo,
+		AuthService: env.authSvc,
+		MFAService:  env.mfaSvc,
+		WebAuthn: webauthn.Config{
+			RPID:    "erp.example.com",
+			RPName:  "Solobueno ERP",
+			Origins: []string{testPasskeyOrigin},
+		},
+	})
+	return env
+}
+
+// register runs a registration ceremony for user with authenticator.
+func (e *passkeyTestEnv) register(t *testing.T, authenticator *webauthntest.Authenticator, user *domain.User, tenant *domain.Tenant) (*domain.Passkey, error) {
+	t.Helper()
+	ctx := context.Background()
+	role := user.GetRoleForTenant(tenant.ID)
+
+	options, err := e.svc.BeginRegistration(ctx, user.ID, role)
+	if err != nil {
+		return nil, err
+	}
+	var credential webauthn.RegistrationResponse
+	answer(t, options, authenticator.Register, &credential)
+
+	return e.svc.FinishRegistration(ctx, FinishPasskeyRegistrationRequest{
+		UserID:     user.ID,
+		TenantID:   tenant.ID,
+		Role:       role,
+		Name:       "Office laptop",
+		Credential: &credential,
+		IPAddress:  "127.0.0.1",
+	})
+}
+
+// login runs a passwordless login ceremony with authenticator.
+func (e *passkeyTestEnv) login(t *testing.T, authenticator *webauthntest.Authenticator, tenantID *uuid.UUID) (*LoginResponse, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	options, err := e.svc.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin failed: %v", err)
+	}
+	var credential webauthn.AssertionResponse
+	answer(t, options, authenticator.Login, &credential)
+
+	return e.svc.FinishLogin(ctx, FinishPasskeyLoginRequest{
+		Credential: &credential,
+		TenantID:   tenantID,
+		IPAddress:  "127.0.0.1",
+	})
+}
+
+// answer passes options through a software authenticator ceremony and
+// decodes its response into credential.
+func answer(t *testing.T, options interface{}, ceremony func([]byte) ([]byte, error), credential interface{}) {
+	t.Helper()
+
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		t.Fatalf("marshal options: %v", err)
+	}
+	response, err := ceremony(optionsJSON)
+	if err != nil {
+		t.Fatalf("authenticator failed: %v", err)
+	}
+	if err := json.Unmarshal(response, credential); err != nil {
+		t.Fatalf("unmarshal credential: %v", err)
+	}
+}
+
+func TestPasskeyService_RegisterAndLogin(t *testing.T) {
+	env := setupPasskeyService(t)
+	user, tenant := addMFATestUser(t, env.userRepo, env.tenantRepo, domain.RoleManager)
+	authenticator := webauthntest.New(testPasskeyOrigin)
+
+	passkey, err := env.register(t, authenticator, user, tenant)
+	if err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	if passkey.Name != "Office laptop" || passkey.UserID != user.ID {
+		t.Errorf("passkey = %+v", passkey)
+	}
+	if !slices.Equal(passkey.Transports, domain.TransportList{"internal"}) {
+		t.Errorf("Transports = %v, want [internal]", passkey.Transports)
+	}
+
+	// The authenticator is excluded from registering again
+	options, err := env.svc.BeginRegistration(context.Background(), user.ID, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("BeginRegistration failed: %v", err)
+	}
+	optionsJSON, _ := json.Marshal(options)
+	if _, err := authenticator.Register(optionsJSON); !errors.Is(err, webauthntest.ErrExcluded) {
+		t.Errorf("second registration error = %v, want ErrExcluded", err)
+	}
+
+	resp, err := env.login(t, authenticator, nil)
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	if resp.TokenPair == nil || resp.MFAToken != "" {
+		t.Error("a passkey login should issue tokens without an MFA challenge")
+	}
+	if resp.TenantID != tenant.ID || resp.Role != domain.RoleManager {
+		t.Errorf("session = %s/%s, want %s/manager", resp.TenantID, resp.Role, tenant.ID)
+	}
+	if passkey.SignCount != 1 || passkey.LastUsedAt == nil {
+		t.Errorf("use not recorded: sign count %d, last used %v", passkey.SignCount, passkey.LastUsedAt)
+	}
+
+	if !hasEventType(env.eventRepo, domain.EventPasskeyRegistered) {
+		t.Error("expected a passkey_registered event")
+	}
+	var login *domain.AuthEvent
+	for _, ev := range env.eventRepo.GetEvents() {
+		if ev.EventType == domain.EventLoginSuccess {
+			login = ev
+		}
+	}
+	if login == nil || login.Metadata["method"] != LoginMethodPasskey {
+		t.Errorf("expected a login_success event with method passkey, got %+v", login)
+	}
+}
+
+func TestPasskeyService_Registration_Rejected(t *testing.T) {
+	env := setupPasskeyService(t)
+	ctx := context.Background()
+
+	t.Run("role not allowed", func(t *testing.T) {
+		if _, err := env.svc.BeginRegistration(ctx, uuid.New(), domain.RoleWaiter); err != domain.ErrPasskeyNotAllowed {
+			t.Errorf("error = %v, want ErrPasskeyNotAllowed", err)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		userID := uuid.New()
+		env.userRepo.AddUser(&domain.User{ID: userID, Email: "limit@example.com", IsActive: true})
+		for i := range domain.MaxPasskeysPerUser {
+			_ = env.passkeys.Create(ctx, &domain.Passkey{UserID: userID, CredentialID: []byte{byte(i)}})
+		}
+		if _, err := env.svc.BeginRegistration(ctx, userID, domain.RoleOwner); err != domain.ErrPasskeyLimit {
+			t.Errorf("error = %v, want ErrPasskeyLimit", err)
+		}
+	})
+
+	t.Run("challenge for another user", func(t *testing.T) {
+		user, tenant := addMFATestUser(t, env.userRepo, env.tenantRepo, domain.RoleOwner)
+		options, err := env.svc.BeginRegistration(ctx, user.ID, domain.RoleOwner)
+		if err != nil {
+			t.Fatalf("BeginRegistration failed: %v", err)
+		}
+		var credential webauthn.RegistrationResponse
+		answer(t, options, webauthntest.New(testPasskeyOrigin).Register, &credential)
+
+		_, err = env.svc.FinishRegistration(ctx, FinishPasskeyRegistrationRequest{
+			UserID:     uuid.New(),
+			TenantID:   tenant.ID,
+			Role:       domain.RoleOwner,
+			Credential: &credential,
+		})
+		if err != domain.ErrPasskeyChallengeInvalid {
+			t.Errorf("error = %v, want ErrPasskeyChallengeInvalid", err)
+		}
+	})
+
+	t.Run("wrong origin", func(t *testing.T) {
+		user, tenant := addMFATestUser(t, env.userRepo, env.tenantRepo, domain.RoleOwner)
+		if _, err := env.register(t, webauthntest.New("https://evil.example.com"), user, tenant); !errors.Is(err, domain.ErrPasskeyInvalid) {
+			t.Errorf("error = %v, want ErrPasskeyInvalid", err)
+		}
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		svc := NewPasskeyService(PasskeyServiceConfig{})
+		if _, err := svc.BeginRegistration(ctx, uuid.New(), domain.RoleOwner); err != domain.ErrPasskeysUnavailable {
+			t.Errorf("BeginRegistration error = %v, want ErrPasskeysUnavailable", err)
+		}
+		if _, err := svc.BeginLogin(ctx); err != domain.ErrPasskeysUnavailable {
+			t.Errorf("BeginLogin error = %v, want ErrPasskeysUnavailable", err)
+		}
+	})
+}
+
+func TestPasskeyService_Login_Rejected(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cloned authenticator", func(t *testing.T) {
+		env := setupPasskeyService(t)
+		user, tenant := addMFATestUser(t, env.userRepo, env.tenantRepo, domain.RoleOwner)
+		authenticator := webauthntest.New(testPasskeyOrigin)
+		if _, err := env.register(t, authenticator, user, tenant); err != nil {
+			t.Fatalf("register failed: %v", err)
+		}
+		clone := authenticator.Clone()
+
+		if _, err := env.login(t, authenticator, nil); err != nil {
+			t.Fatalf("login failed: %v", err)
+		}
+		if _, err := env.login(t, clone, nil); !errors.Is(err, domain.ErrPasskeyInvalid) {
+			t.Errorf("cloned login error = %v, want ErrPasskeyInvalid", err)
+		}
+		if !hasEventType(env.eventRepo, domain.EventPasskeyFailed) {
+			t.Error("expected a passkey_failed event")
+		}
+	})
+
+	t.Run("challenge replay", func(t *testing.T) {
+		env := setupPasskeyService(t)
+		user, tenant := addMFATestUser(t, env.userRepo, env.tenantRepo, domain.RoleOwner)
+		authenticator := webauthntest.New(testPasskeyOrigin)
+		if _, err := env.register(t, authenticator, user, tenant); err != nil {
+			t.Fatalf("register failed: %v", err)
+		}
+
+		options, _ := env.svc.BeginLogin(ctx)
+		var credential webauthn.AssertionResponse
+		answer(t, options, authenticator.Login, &credential)
+		req := FinishPasskeyLoginRequest{Credential: &credential}
+		if _, err := env.svc.FinishLogin(ctx, req); err != nil {
+			t.Fatalf("FinishLogin failed: %v", err)
+		}
+		if _, err := env.svc.FinishLogin(ctx, req); err != domain.ErrPasskeyChallengeInvalid {
+			t.Errorf("replayed FinishLogin error = %v, want ErrPasskeyChallengeInvalid", err)
+		}
+	})
+
+	t.Run("unknown credential", func(t *testing.T) {
+		env := setupPasskeyService(t)
+		user, tenant := addMFATestUser(t, env.userRepo, env.tenantRepo, domain.RoleOwner)
+		authenticator := webauthntest.New(testPasskeyOrigin)
+		passkey, err := env.register(t, authenticator, user, tenant)
+		if err != nil {
+			t.Fatalf("register failed: %v", err)
+		}
+		_ = env.passkeys.Delete(ctx, user.ID, passkey.ID)
+
+		if _, err := env.login(t, authenticator, nil); !errors.Is(err, domain.ErrPasskeyInvalid) {
+			t.Errorf("error = %v, want ErrPasskeyInvalid", err)
+		}
+	})
+
+	t.Run("role not allowed in tenant", func(t *testing.T) {
+		env := setupPasskeyService(t)
+		user, tenant := addMFATestUser(t, env.userRepo, env.tenantRepo, domain.RoleOwner)
+		authenticator := webauthntest.New(testPasskeyOrigin)
+		if _, err := env.register(t, authenticator, user, tenant); err != nil {
+			t.Fatalf("register failed: %v", err)
+		}
+
+		// Demoted since registering
+		user.TenantRoles[0].Role = domain.RoleWaiter
+		if _, err := env.login(t, authenticator, nil); err != domain.ErrPasskeyNotAllowed {
+			t.Errorf("error = %v, want ErrPasskeyNotAllowed", err)
+		}
+	})
+
+	t.Run("account disabled", func(t *testing.T) {
+		env := setupPasskeyService(t)
+		user, tenant := addMFATestUser(t, env.userRepo, env.tenantRepo, domain.RoleOwner)
+		authenticator := webauthntest.New(testPasskeyOrigin)
+		if _, err := env.register(t, authenticator, user, tenant); err != nil {
+			t.Fatalf("register failed: %v", err)
+		}
+
+		user.IsActive = false
+		if _, err := env.login(t, authenticator, nil); err != domain.ErrAccountDisabled {
+			t.Errorf("error = %v, want ErrAccountDisabled", err)
+		}
+	})
+
+	t.Run("user not verified", func(t *testing.T) {
+		env := setupPasskeyService(t)
+		user, tenant := addMFATestUser(t, env.userRepo, env.tenantRepo, domain.RoleOwner)
+		authenticator := webauthntest.New(testPasskeyOrigin)
+		if _, err := env.register(t, authenticator, user, tenant); err != nil {
+			t.Fatalf("register failed: %v", err)
+		}
+
+		authenticator.UserVerified = false
+		if _, err := env.login(t, authenticator, nil); !errors.Is(err, domain.ErrPasskeyInvalid) {
+			t.Errorf("error = %v, want ErrPasskeyInvalid", err)
+		}
+	})
+}
+
+func TestPasskeyService_Login_TenantRequired(t *testing.T) {
+	env := setupPasskeyService(t)
+	user, tenant := addMFATestUser(t, env.userRepo, env.tenantRepo, domain.RoleOwner)
+	other := &domain.Tenant{ID: uuid.New(), Name: "Second", Slug: "second", IsActive: true}
+	env.tenantRepo.AddTenant(other)
+	user.TenantRoles = append(user.TenantRoles, domain.UserTenantRole{TenantID: other.ID, Role: domain.RoleManager, Tenant: *other})
+
+	authenticator := webauthntest.New(testPasskeyOrigin)
+	if _, err := env.register(t, authenticator, user, tenant); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+
+	resp, err := env.login(t, authenticator, nil)
+	if err != domain.ErrTenantRequired {
+		t.Fatalf("error = %v, want ErrTenantRequired", err)
+	}
+	if len(resp.Tenants) != 2 {
+		t.Errorf("len(Tenants) = %d, want 2", len(resp.Tenants))
+	}
+
+	resp, err = env.login(t, authenticator, &other.ID)
+	if err != nil {
+		t.Fatalf("login with tenant failed: %v", err)
+	}
+	if resp.TenantID != other.ID {
+		t.Errorf("TenantID = %s, want %s", resp.TenantID, other.ID)
+	}
+}
+
+func TestPasskeyService_MFA(t *testing.T) {
+	env := setupPasskeyService(t)
+	user, tenant := addMFATestUser(t, env.userRepo, env.tenantRepo, domain.RoleManager)
+	authenticator := webauthntest.New(testPasskeyOrigin)
+	if _, err := env.register(t, authenticator, user, tenant); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	ctx := context.Background()
+
+	// A passkey enrolls the user in MFA
+	login, err := env.authSvc.Login(ctx, LoginRequest{Email: "mfa@example.com", Password: "Password123!"})
+	if err != domain.ErrMFARequired {
+		t.Fatalf("Login error = %v, want ErrMFARequired", err)
+	}
+	if !slices.Equal(login.MFAMethods, []string{MFAMethodPasskey}) {
+		t.Errorf("MFAMethods = %v, want [passkey]", login.MFAMethods)
+	}
+
+	// Neither a TOTP code nor a new TOTP enrollment can stand in for it
+	if _, err := env.authSvc.BeginMFAEnrollment(ctx, login.MFAToken); err != domain.ErrMFAAlreadyEnrolled {
+		t.Errorf("BeginMFAEnrollment error = %v, want ErrMFAAlreadyEnrolled", err)
+	}
+	if _, err := env.authSvc.VerifyMFA(ctx, MFAVerifyRequest{MFAToken: login.MFAToken, Code: "123456"}); err != domain.ErrMFACodeInvalid {
+		t.Errorf("VerifyMFA error = %v, want ErrMFACodeInvalid", err)
+	}
+
+	// Another user's passkey doesn't answer the challenge
+	stranger := webauthntest.New(testPasskeyOrigin)
+	strangerUser := &domain.User{ID: uuid.New(), Email: "stranger@example.com", IsActive: true,
+		TenantRoles: []domain.UserTenantRole{{TenantID: tenant.ID, Role: domain.RoleOwner}}}
+	env.userRepo.AddUser(strangerUser)
+	if _, err := env.register(t, stranger, strangerUser, tenant); err != nil {
+		t.Fatalf("register stranger failed: %v", err)
+	}
+	options, err := env.svc.BeginMFA(ctx, login.MFAToken)
+	if err != nil {
+		t.Fatalf("BeginMFA failed: %v", err)
+	}
+	if len(options.AllowCredentials) != 1 {
+		t.Errorf("len(AllowCredentials) = %d, want 1", len(options.AllowCredentials))
+	}
+	options.AllowCredentials = nil
+	var forged webauthn.AssertionResponse
+	answer(t, options, stranger.Login, &forged)
+	if _, err := env.svc.FinishMFA(ctx, FinishPasskeyMFARequest{MFAToken: login.MFAToken, Credential: &forged}); err != domain.ErrPasskeyInvalid {
+		t.Errorf("FinishMFA with another user's passkey error = %v, want ErrPasskeyInvalid", err)
+	}
+
+	options, err = env.svc.BeginMFA(ctx, login.MFAToken)
+	if err != nil {
+		t.Fatalf("BeginMFA failed: %v", err)
+	}
+	var credential webauthn.AssertionResponse
+	answer(t, options, authenticator.Login, &credential)
+	resp, err := env.svc.FinishMFA(ctx, FinishPasskeyMFARequest{MFAToken: login.MFAToken, Credential: &credential})
+	if err != nil {
+		t.Fatalf("FinishMFA failed: %v", err)
+	}
+	if resp.TokenPair == nil {
+		t.Error("FinishMFA should issue tokens")
+	}
+
+	// The login challenge is spent
+	if _, err := env.svc.BeginMFA(ctx, login.MFAToken); err != domain.ErrMFAChallengeInvalid {
+		t.Errorf("BeginMFA after completion error = %v, want ErrMFAChallengeInvalid", err)
+	}
+
+	var success *domain.AuthEvent
+	for _, ev := range env.eventRepo.GetEvents() {
+		if ev.EventType == domain.EventMFASuccess {
+			success = ev
+		}
+	}
+	if success == nil || success.Metadata["method"] != MFAMethodPasskey {
+		t.Errorf("expected an mfa_success event with method passkey, got %+v", success)
+	}
+}
+
+func TestPasskeyService_RenameAndRemove(t *testing.T) {
+	env := setupPasskeyService(t)
+	user, tenant := addMFATestUser(t, env.userRepo, env.tenantRepo, domain.RoleOwner)
+	passkey, err := env.register(t, webauthntest.New(testPasskeyOrigin), user, tenant)
+	if err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	ctx := context.Background()
+
+	renamed, err := env.svc.Rename(ctx, RenamePasskeyRequest{UserID: user.ID, TenantID: tenant.ID, PasskeyID: passkey.ID, Name: "  Phone  "})
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if renamed.Name != "Phone" {
+		t.Errorf("Name = %q, want %q", renamed.Name, "Phone")
+	}
+
+	// Another user's passkey looks like a missing one
+	if err := env.svc.Remove(ctx, RemovePasskeyRequest{UserID: uuid.New(), PasskeyID: passkey.ID}); err != domain.ErrPasskeyNotFound {
+		t.Errorf("Remove by another user error = %v, want ErrPasskeyNotFound", err)
+	}
+	if err := env.svc.Remove(ctx, RemovePasskeyRequest{UserID: user.ID, TenantID: tenant.ID, PasskeyID: passkey.ID}); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	passkeys, _ := env.svc.List(ctx, user.ID)
+	if len(passkeys) != 0 {
+		t.Errorf("len(passkeys) = %d, want 0", len(passkeys))
+	}
+
+	if !hasEventType(env.eventRepo, domain.EventPasskeyRenamed) || !hasEventType(env.eventRepo, domain.EventPasskeyRemoved) {
+		t.Error("expected passkey_renamed and passkey_removed events")
+	}
+}
@@ -2,11 +2,14 @@
 package service
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"unicode"
 
 	"github.com/alexedwards/argon2id"
@@ -19,44 +22,213 @@ var (
 	ErrPasswordTooWeak = errors.New("password must contain at least one uppercase letter, one lowercase letter, and one number")
 	// ErrPasswordMismatch is returned when password verification fails.
 	ErrPasswordMismatch = errors.New("password does not match")
+	// ErrUnknownPepper is returned when a hash was made with a pepper
+	// version that is no longer configured.
+	ErrUnknownPepper = errors.New("password hash uses an unknown pepper version")
 )
 
+// pepperPrefix marks a peppered hash, followed by the pepper version and
+// the Argon2id encoding, e.g. "$pepper=2$argon2id$v=19$m=65536,t=3,p=4$...".
+const pepperPrefix = "$pepper="
+
+// minPepperLength is the shortest pepper secret accepted, in bytes.
+const minPepperLength = 32
+
+// DefaultPasswordHashParams returns the Argon2id parameters new hashes use
+// unless configured otherwise, following the OWASP recommendation.
+func DefaultPasswordHashParams() *argon2id.Params {
+	return &argon2id.Params{
+		Memory:      64 * 1024, // 64 MB
+		Iterations:  3,
+		Parallelism: 4,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// PasswordPepper is a server-side secret mixed into passwords with
+// HMAC-SHA256 before they are hashed, so a leaked database alone isn't
+// enough to crack them. The version is recorded in each hash so the pepper
+// can be rotated.
+type PasswordPepper struct {
+	Version int
+	Secret  []byte
+}
+
+// PasswordHashingConfig holds configuration for PasswordService.
+type PasswordHashingConfig struct {
+	// Params are the Argon2id parameters for new hashes. Defaults to
+	// DefaultPasswordHashParams.
+	Params *argon2id.Params
+	// Peppers are the peppers hashes may have been made with. New hashes
+	// use the highest version; older ones are kept to verify hashes not yet
+	// upgraded at login. If empty, passwords aren't peppered.
+	Peppers []PasswordPepper
+}
+
+// PasswordHashInfo describes how a stored hash was made.
+type PasswordHashInfo struct {
+	Params argon2id.Params
+	// PepperVersion is 0 for hashes made without a pepper.
+	PepperVersion int
+	// Current is true if the hash uses the configured parameters and
+	// pepper, so it won't be upgraded at the next login.
+	Current bool
+}
+
 // PasswordService handles password hashing and verification.
 type PasswordService struct {
-	params *argon2id.Params
+	params        *argon2id.Params
+	peppers       map[int][]byte
+	pepperVersion int
 }
 
-// NewPasswordService creates a new PasswordService with recommended OWASP parameters.
+// NewPasswordService creates a new PasswordService with the default
+// parameters and no pepper.
 func NewPasswordService() *PasswordService {
-	return &PasswordService{
-		params: &argon2id.Params{
-			Memory:      64 * 1024, // 64 MB
-			Iterations:  3,
-			Parallelism: 4,
-			SaltLength:  16,
-			KeyLength:   32,
-		},
+	return &PasswordService{params: DefaultPasswordHashParams()}
+}
+
+// NewPasswordServiceWithConfig creates a PasswordService with custom
+// parameters and peppers.
+func NewPasswordServiceWithConfig(cfg PasswordHashingConfig) (*PasswordService, error) {
+	s := NewPasswordService()
+	if cfg.Params != nil {
+		s.params = cfg.Params
+	}
+	for _, pepper := range cfg.Peppers {
+		if pepper.Version < 1 {
+			return nil, fmt.Errorf("pepper version must be positive, got %d", pepper.Version)
+		}
+		if len(pepper.Secret) < minPepperLength {
+			return nil, fmt.Errorf("pepper %d must be at least %d bytes", pepper.Version, minPepperLength)
+		}
+		if _, ok := s.peppers[pepper.Version]; ok {
+			return nil, fmt.Errorf("pepper %d configured twice", pepper.Version)
+		}
+		if s.peppers == nil {
+			s.peppers = make(map[int][]byte)
+		}
+		s.peppers[pepper.Version] = pepper.Secret
+		s.pepperVersion = max(s.pepperVersion, pepper.Version)
+	}
+	return s, nil
+}
+
+// ParsePasswordPeppers parses a comma-separated list of version:secret
+// pairs with base64-encoded secrets, e.g. "1:c2VjcmV0...,2:b3RoZXI...".
+func ParsePasswordPeppers(s string) ([]PasswordPepper, error) {
+	var peppers []PasswordPepper
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		version, secret, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("pepper %q: expected version:secret", entry)
+		}
+		v, err := strconv.Atoi(version)
+		if err != nil {
+			return nil, fmt.Errorf("pepper version %q: %w", version, err)
+		}
+		key, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil {
+			return nil, fmt.Errorf("pepper %d secret: %w", v, err)
+		}
+		peppers = append(peppers, PasswordPepper{Version: v, Secret: key})
 	}
+	return peppers, nil
 }
 
-// Hash creates a hash of the given password using Argon2id.
+// Hash creates a hash of the given password using Argon2id, peppered with
+// the current pepper if one is configured.
 func (s *PasswordService) Hash(password string) (string, error) {
+	prefix := ""
+	if s.pepperVersion != 0 {
+		password = applyPepper(s.peppers[s.pepperVersion], password)
+		prefix = pepperPrefix + strconv.Itoa(s.pepperVersion)
+	}
 	hash, err := argon2id.CreateHash(password, s.params)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
-	return hash, nil
+	return prefix + hash, nil
 }
 
-// Verify checks if the given password matches the hash.
+// Verify checks if the given password matches the hash. Hashes made with
+// older parameters or peppers still verify as long as the pepper is
+// configured.
 func (s *PasswordService) Verify(password, hash string) (bool, error) {
-	match, err := argon2id.ComparePasswordAndHash(password, hash)
+	version, encoded, err := splitPepper(hash)
+	if err != nil {
+		return false, fmt.Errorf("failed to verify password: %w", err)
+	}
+	if version != 0 {
+		secret, ok := s.peppers[version]
+		if !ok {
+			return false, ErrUnknownPepper
+		}
+		password = applyPepper(secret, password)
+	}
+	match, err := argon2id.ComparePasswordAndHash(password, encoded)
 	if err != nil {
 		return false, fmt.Errorf("failed to verify password: %w", err)
 	}
 	return match, nil
 }
 
+// Inspect reports the parameters and pepper version a hash was made with.
+func (s *PasswordService) Inspect(hash string) (*PasswordHashInfo, error) {
+	version, encoded, err := splitPepper(hash)
+	if err != nil {
+		return nil, err
+	}
+	params, salt, _, err := argon2id.DecodeHash(encoded)
+	if err != nil {
+		return nil, err
+	}
+	params.SaltLength = uint32(len(salt))
+	return &PasswordHashInfo{
+		Params:        *params,
+		PepperVersion: version,
+		Current:       *params == *s.params && version == s.pepperVersion,
+	}, nil
+}
+
+// NeedsRehash reports whether a hash was made with parameters or a pepper
+// other than the current ones, so it should be replaced the next time the
+// password is known. Hashes that can't be parsed are left alone.
+func (s *PasswordService) NeedsRehash(hash string) bool {
+	info, err := s.Inspect(hash)
+	return err == nil && !info.Current
+}
+
+// splitPepper separates a hash's pepper version, 0 if unpeppered, from
+// its Argon2id encoding.
+func splitPepper(hash string) (int, string, error) {
+	rest, ok := strings.CutPrefix(hash, pepperPrefix)
+	if !ok {
+		return 0, hash, nil
+	}
+	i := strings.IndexByte(rest, '$')
+	if i < 0 {
+		return 0, "", argon2id.ErrInvalidHash
+	}
+	version, err := strconv.Atoi(rest[:i])
+	if err != nil || version < 1 {
+		return 0, "", argon2id.ErrInvalidHash
+	}
+	return version, rest[i:], nil
+}
+
+// applyPepper mixes the pepper into a password.
+func applyPepper(secret []byte, password string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(password))
+	return base64.RawStdEncoding.EncodeToString(mac.Sum(nil))
+}
+
 // ValidatePassword checks if a password meets the minimum requirements.
 // Requirements:
 // - At least 8 characters long
@@ -87,43 +259,6 @@ func (s *PasswordService) ValidatePassword(password string) error {
 	return nil
 }
 
-// GenerateTemporaryPassword generates a secure temporary password.
-// The password will be 12 characters and meet all complexity requirements.
-func (s *PasswordService) GenerateTemporaryPassword() (string, error) {
-	const (
-		upperChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
-		lowerChars = "abcdefghijklmnopqrstuvwxyz"
-		digitChars = "0123456789"
-		allChars   = upperChars + lowerChars + digitChars
-		length     = 12
-	)
-
-	// Generate random bytes
-	bytes := make([]byte, length)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", fmt.Errorf("failed to generate random bytes: %w", err)
-	}
-
-	// Ensure at least one of each required character type
-	password := make([]byte, length)
-	password[0] = upperChars[int(bytes[0])%len(upperChars)]
-	password[1] = lowerChars[int(bytes[1])%len(lowerChars)]
-	password[2] = digitChars[int(bytes[2])%len(digitChars)]
-
-	// Fill the rest randomly
-	for i := 3; i < length; i++ {
-		password[i] = allChars[int(bytes[i])%len(allChars)]
-	}
-
-	// Shuffle the password
-	for i := len(password) - 1; i > 0; i-- {
-		j := int(bytes[i]) % (i + 1)
-		password[i], password[j] = password[j], password[i]
-	}
-
-	return string(password), nil
-}
-
 // GenerateResetToken generates a cryptographically secure reset token.
 // Returns both the plain token (to send to user) and the hash (to store in DB).
 func (s *PasswordService) GenerateResetToken() (plainToken, tokenHash string, err error) {
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+)
+
+// PasswordPolicyService manages tenants' password policies and checks new
+// passwords against them, the user's previous passwords and a list of
+// breached passwords. A user in several tenants follows the strictest
+// combination of their policies, since the password is shared by all.
+type PasswordPolicyService struct {
+	policies    repository.PasswordPolicyRepository
+	history     repository.PasswordHistoryRepository
+	roleRepo    repository.UserTenantRoleRepository
+	eventRepo   repository.AuthEventRepository
+	breached    BreachedPasswords
+	passwordSvc *PasswordService
+}
+
+// PasswordPolicyServiceConfig holds configuration for PasswordPolicyService.
+type PasswordPolicyServiceConfig struct {
+	// Policies stores tenants' policies. If nil, every tenant uses
+	// domain.DefaultPasswordPolicy.
+	Policies repository.PasswordPolicyRepository
+	// History keeps replaced password hashes. If nil, reuse isn't checked.
+	History repository.PasswordHistoryRepository
+	// RoleRepo finds the tenants whose policies apply to a user.
+	RoleRepo  repository.UserTenantRoleRepository
+	EventRepo repository.AuthEventRepository
+	// Breached lists passwords that must never be used. Defaults to the
+	// list bundled with the module (BundledBreachedPasswords).
+	Breached BreachedPasswords
+	// Passwords hashes and verifies passwords. Defaults to
+	// NewPasswordService(), without a pepper.
+	Passwords *PasswordService
+}
+
+// NewPasswordPolicyService creates a new PasswordPolicyService.
+func NewPasswordPolicyService(cfg PasswordPolicyServiceConfig) *PasswordPolicyService {
+	passwordSvc := cfg.Passwords
+	if passwordSvc == nil {
+		passwordSvc = NewPasswordService()
+	}
+	breached := cfg.Breached
+	if breached == nil {
+		breached = BundledBreachedPasswords()
+	}
+	return &PasswordPolicyService{
+		policies:    cfg.Policies,
+		history:     cfg.History,
+		roleRepo:    cfg.RoleRepo,
+		eventRepo:   cfg.EventRepo,
+		breached:    breached,
+		passwordSvc: passwordSvc,
+	}
+}
+
+// GetPolicy returns a tenant's password policy, or the default policy if
+// it hasn't set one.
+func (s *PasswordPolicyService) GetPolicy(ctx context.Context, tenantID uuid.UUID) (*domain.PasswordPolicy, error) {
+	if s.policies == nil {
+		policy := domain.DefaultPasswordPolicy()
+		policy.TenantID = tenantID
+		return &policy, nil
+	}
+	policy, err := s.policies.FindByTenant(ctx, tenantID)
+	if errors.Is(err, domain.ErrPasswordPolicyNotFound) {
+		defaults := domain.DefaultPasswordPolicy()
+		defaults.TenantID = tenantID
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get password policy: %w", err)
+	}
+	return policy, nil
+}
+
+// SavePasswordPolicyRequest contains a tenant's new password policy.
+type SavePasswordPolicyRequest struct {
+	TenantID         uuid.UUID
+	UpdatedBy        uuid.UUID
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	MaxAgeDays       int
+	HistoryCount     int
+	IPAddress        string
+	UserAgent        string
+}
+
+// SavePolicy creates or replaces a tenant's password policy. Existing
+// passwords aren't checked against it until they are next changed, but a
+// shorter maximum age applies from the members' next login.
+func (s *PasswordPolicyService) SavePolicy(ctx context.Context, req SavePasswordPolicyRequest) (*domain.PasswordPolicy, error) {
+	if s.policies == nil {
+		return nil, errors.New("save password policy: no policy repository configured")
+	}
+
+	policy, err := s.policies.FindByTenant(ctx, req.TenantID)
+	creating := errors.Is(err, domain.ErrPasswordPolicyNotFound)
+	if err != nil && !creating {
+		return nil, fmt.Errorf("save password policy: lookup: %w", err)
+	}
+	if creating {
+		policy = &domain.PasswordPolicy{ID: uuid.New(), TenantID: req.TenantID}
+	}
+
+	policy.MinLength = req.MinLength
+	policy.RequireUppercase = req.RequireUppercase
+	policy.RequireLowercase = req.RequireLowercase
+	policy.RequireDigit = req.RequireDigit
+	policy.RequireSymbol = req.RequireSymbol
+	policy.MaxAgeDays = req.MaxAgeDays
+	policy.HistoryCount = req.HistoryCount
+	policy.UpdatedBy = &req.UpdatedBy
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	if creating {
+		err = s.policies.Create(ctx, policy)
+	} else {
+		err = s.policies.Update(ctx, policy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("save password policy: %w", err)
+	}
+
+	event := newAuthEvent(ctx, domain.EventPasswordPolicyUpdated, &req.UpdatedBy, &req.TenantID, req.IPAddress, req.UserAgent)
+	event.Metadata = map[string]interface{}{
+		"min_length":        policy.MinLength,
+		"require_uppercase": policy.RequireUppercase,
+		"require_lowercase": policy.RequireLowercase,
+		"require_digit":     policy.RequireDigit,
+		"require_symbol":    policy.RequireSymbol,
+		"max_age_days":      policy.MaxAgeDays,
+		"history_count":     policy.HistoryCount,
+	}
+	_ = s.eventRepo.Create(ctx, event)
+
+	return policy, nil
+}
+
+// PolicyForUser returns the strictest combination of the policies of every
+// tenant the user belongs to.
+func (s *PasswordPolicyService) PolicyForUser(ctx context.Context, userID uuid.UUID) (domain.PasswordPolicy, error) {
+	if s.roleRepo == nil {
+		return domain.DefaultPasswordPolicy(), nil
+	}
+	roles, err := s.roleRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return domain.PasswordPolicy{}, fmt.Errorf("password policy: list tenants: %w", err)
+	}
+	tenantIDs := make([]uuid.UUID, 0, len(roles))
+	for _, role := range roles {
+		tenantIDs = append(tenantIDs, role.TenantID)
+	}
+	return s.PolicyForTenants(ctx, tenantIDs)
+}
+
+// PolicyForTenants returns the strictest combination of the given
+// tenants' policies, or the default policy for no tenants.
+func (s *PasswordPolicyService) PolicyForTenants(ctx context.Context, tenantIDs []uuid.UUID) (domain.PasswordPolicy, error) {
+	if len(tenantIDs) == 0 {
+		return domain.DefaultPasswordPolicy(), nil
+	}
+
+	byTenant := make(map[uuid.UUID]domain.PasswordPolicy, len(tenantIDs))
+	if s.policies != nil {
+		policies, err := s.policies.ListByTenants(ctx, tenantIDs)
+		if err != nil {
+			return domain.PasswordPolicy{}, fmt.Errorf("password policy: lookup: %w", err)
+		}
+		for _, p := range policies {
+			byTenant[p.TenantID] = *p
+		}
+	}
+
+	var combined domain.PasswordPolicy
+	for i, tenantID := range tenantIDs {
+		policy, ok := byTenant[tenantID]
+		if !ok {
+			policy = domain.DefaultPasswordPolicy()
+		}
+		if i == 0 {
+			combined = policy
+		} else {
+			combined = combined.Strictest(policy)
+		}
+	}
+	combined.ID, combined.TenantID, combined.UpdatedBy = uuid.Nil, uuid.Nil, nil
+	return combined, nil
+}
+
+// CheckPassword checks a new password against policy and the breached
+// password list and, for an existing user, against their current and
+// recent passwords. It returns a *domain.PasswordRuleError naming the
+// first rule broken.
+func (s *PasswordPolicyService) CheckPassword(ctx context.Context, user *domain.User, policy domain.PasswordPolicy, password string) error {
+	if err := policy.CheckComposition(password); err != nil {
+		return err
+	}
+	if s.breached.Contains(password) {
+		return &domain.PasswordRuleError{Rule: domain.PasswordRuleBreached}
+	}
+	if user == nil || policy.HistoryCount == 0 {
+		return nil
+	}
+
+	// The current password counts towards the history
+	hashes := []string{user.PasswordHash}
+	if s.history != nil && policy.HistoryCount > 1 {
+		entries, err := s.history.ListRecent(ctx, user.ID, policy.HistoryCount-1)
+		if err != nil {
+			return fmt.Errorf("check password: history lookup: %w", err)
+		}
+		for _, e := range entries {
+			hashes = append(hashes, e.PasswordHash)
+		}
+	}
+	for _, hash := range hashes {
+		if hash == "" {
+			continue
+		}
+		match, err := s.passwordSvc.Verify(password, hash)
+		if errors.Is(err, ErrUnknownPepper) {
+			// Made with a retired pepper, so it can't be compared
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("check password: compare history: %w", err)
+		}
+		if match {
+			return &domain.PasswordRuleError{Rule: domain.PasswordRuleReused, Limit: policy.HistoryCount}
+		}
+	}
+	return nil
+}
+
+// RecordPasswordChange keeps the hash a user's password replaced, so it
+// can't be reused while it is among their most recent
+// domain.MaxPasswordHistory passwords.
+func (s *PasswordPolicyService) RecordPasswordChange(ctx context.Context, userID uuid.UUID, oldHash string) error {
+	if s.history == nil || oldHash == "" {
+		return nil
+	}
+	if err := s.history.Create(ctx, &domain.PasswordHistoryEntry{ID: uuid.New(), UserID: userID, PasswordHash: oldHash}); err != nil {
+		return fmt.Errorf("record password history: %w", err)
+	}
+	if err := s.history.Prune(ctx, userID, domain.MaxPasswordHistory-1); err != nil {
+		return fmt.Errorf("prune password history: %w", err)
+	}
+	return nil
+}
+
+// IsExpired reports whether the user's password is older than the maximum
+// age of the strictest of their tenants' policies.
+func (s *PasswordPolicyService) IsExpired(ctx context.Context, user *domain.User) (bool, int, error) {
+	policy, err := s.PolicyForUser(ctx, user.ID)
+	if err != nil {
+		return false, 0, err
+	}
+	return policy.IsExpired(user.PasswordChangedAt), policy.MaxAgeDays, nil
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+)
+
+// passwordPolicyTestEnv bundles a PasswordPolicyService over mock
+// repositories, with a user who is a member of two tenants.
+type passwordPolicyTestEnv struct {
+	svc       *PasswordPolicyService
+	policies  *mock.MockPasswordPolicyRepository
+	history   *mock.MockPasswordHistoryRepository
+	roleRepo  *mock.MockUserTenantRoleRepository
+	eventRepo *mock.MockAuthEventRepository
+	user      *domain.User
+	tenantA   uuid.UUID
+	tenantB   uuid.UUID
+}
+
+func setupPasswordPolicyService(t *testing.T) *passwordPolicyTestEnv {
+	t.Helper()
+
+	env := &passwordPolicyTestEnv{
+		policies:  mock.NewMockPasswordPolicyRepository(),
+		history:   mock.NewMockPasswordHistoryRepository(),
+		roleRepo:  mock.NewMockUserTenantRoleRepository(),
+		eventRepo: mock.NewMockAuthEventRepository(),
+		tenantA:   uuid.New(),
+		tenantB:   uuid.New(),
+	}
+	env.svc = NewPasswordPolicyService(PasswordPolicyServiceConfig{
+		Policies:  env.policies,
+		History:   env.history,
+		RoleRepo:  env.roleRepo,
+		EventRepo: env.eventRepo,
+	})
+
+	passwordHash, _ := NewPasswordService().Hash("Current123!")
+	env.user = &domain.User{ID: uuid.New(), Email: "ana@example.com", PasswordHash: passwordHash, IsActive: true}
+	env.roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: env.user.ID, TenantID: env.tenantA, Role: domain.RoleWaiter})
+	env.roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: env.user.ID, TenantID: env.tenantB, Role: domain.RoleWaiter})
+	return env
+}
+
+// passwordRule returns the rule a password check broke, or "" if it passed.
+func passwordRule(t *testing.T, err error) domain.PasswordRule {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	var ruleErr *domain.PasswordRuleError
+	if !errors.As(err, &ruleErr) {
+		t.Fatalf("expected a *domain.PasswordRuleError, got %v", err)
+	}
+	if !errors.Is(err, domain.ErrPasswordWeak) {
+		t.Errorf("rule error should match ErrPasswordWeak")
+	}
+	return ruleErr.Rule
+}
+
+func TestPasswordPolicyService_GetPolicy_DefaultsWhenUnset(t *testing.T) {
+	env := setupPasswordPolicyService(t)
+
+	policy, err := env.svc.GetPolicy(context.Background(), env.tenantA)
+	if err != nil {
+		t.Fatalf("GetPolicy failed: %v", err)
+	}
+	want := domain.DefaultPasswordPolicy()
+	if policy.MinLength != want.MinLength || !policy.RequireUppercase || !policy.RequireDigit || policy.RequireSymbol {
+		t.Errorf("GetPolicy = %+v, want the default policy", policy)
+	}
+	if policy.TenantID != env.tenantA {
+		t.Errorf("TenantID = %v, want %v", policy.TenantID, env.tenantA)
+	}
+}
+
+func TestPasswordPolicyService_SavePolicy(t *testing.T) {
+	env := setupPasswordPolicyService(t)
+	ctx := context.Background()
+	adminID := uuid.New()
+
+	req := SavePasswordPolicyRequest{
+		TenantID:     env.tenantA,
+		UpdatedBy:    adminID,
+		MinLength:    12,
+		RequireDigit: true,
+		MaxAgeDays:   90,
+		HistoryCount: 5,
+	}
+	if _, err := env.svc.SavePolicy(ctx, req); err != nil {
+		t.Fatalf("SavePolicy (create) failed: %v", err)
+	}
+
+	req.MinLength = 14
+	req.RequireSymbol = true
+	saved, err := env.svc.SavePolicy(ctx, req)
+	if err != nil {
+		t.Fatalf("SavePolicy (update) failed: %v", err)
+	}
+
+	found, _ := env.svc.GetPolicy(ctx, env.tenantA)
+	if found.ID != saved.ID || found.MinLength != 14 || !found.RequireSymbol || found.RequireUppercase || found.HistoryCount != 5 {
+		t.Errorf("GetPolicy after update = %+v", found)
+	}
+	if !hasEventType(env.eventRepo, domain.EventPasswordPolicyUpdated) {
+		t.Error("expected a password_policy_updated event")
+	}
+}
+
+func TestPasswordPolicyService_SavePolicy_RejectsOutOfRange(t *testing.T) {
+	env := setupPasswordPolicyService(t)
+
+	for _, req := range []SavePasswordPolicyRequest{
+		{MinLength: 6},
+		{MinLength: 200},
+		{MinLength: 8, MaxAgeDays: -1},
+		{MinLength: 8, MaxAgeDays: 400},
+		{MinLength: 8, HistoryCount: 25},
+	} {
+		req.TenantID = env.tenantA
+		if _, err := env.svc.SavePolicy(context.Background(), req); !errors.Is(err, domain.ErrPasswordPolicyInvalid) {
+			t.Errorf("SavePolicy(%+v) error = %v, want ErrPasswordPolicyInvalid", req, err)
+		}
+	}
+}
+
+func TestPasswordPolicyService_PolicyForUser_Strictest(t *testing.T) {
+	env := setupPasswordPolicyService(t)
+	env.policies.AddPolicy(&domain.PasswordPolicy{TenantID: env.tenantA, MinLength: 12, MaxAgeDays: 90, HistoryCount: 3})
+	env.policies.AddPolicy(&domain.PasswordPolicy{TenantID: env.tenantB, MinLength: 10, RequireSymbol: true, MaxAgeDays: 30})
+
+	policy, err := env.svc.PolicyForUser(context.Background(), env.user.ID)
+	if err != nil {
+		t.Fatalf("PolicyForUser failed: %v", err)
+	}
+	if policy.MinLength != 12 || !policy.RequireSymbol || policy.RequireUppercase || policy.MaxAgeDays != 30 || policy.HistoryCount != 3 {
+		t.Errorf("PolicyForUser = %+v, want 12 chars, symbol, 30 days, 3 history", policy)
+	}
+}
+
+func TestPasswordPolicyService_PolicyForUser_TenantWithoutPolicyUsesDefault(t *testing.T) {
+	env := setupPasswordPolicyService(t)
+	env.policies.AddPolicy(&domain.PasswordPolicy{TenantID: env.tenantA, MinLength: 8})
+
+	policy, _ := env.svc.PolicyForUser(context.Background(), env.user.ID)
+	if !policy.RequireUppercase || !policy.RequireLowercase || !policy.RequireDigit {
+		t.Errorf("PolicyForUser = %+v, want tenant B's default character classes", policy)
+	}
+}
+
+func TestPasswordPolicyService_CheckPassword_Rules(t *testing.T) {
+	env := setupPasswordPolicyService(t)
+	policy := domain.DefaultPasswordPolicy()
+	policy.RequireSymbol = true
+	policy.MinLength = 10
+
+	tests := []struct {
+		password string
+		want     domain.PasswordRule
+	}{
+		{"Sh0rt!", domain.PasswordRuleLength},
+		{"lowercase-only-1", domain.PasswordRuleUppercase},
+		{"UPPERCASE-ONLY-1", domain.PasswordRuleLowercase},
+		{"No-Digits-Here", domain.PasswordRuleDigit},
+		{"NoSymbols12345", domain.PasswordRuleSymbol},
+		{"Password1!", domain.PasswordRuleBreached},
+		{"Correct-Horse-42", ""},
+	}
+	for _, tt := range tests {
+		err := env.svc.CheckPassword(context.Background(), nil, policy, tt.password)
+		if got := passwordRule(t, err); got != tt.want {
+			t.Errorf("CheckPassword(%q) rule = %q, want %q", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestPasswordPolicyService_CheckPassword_Breached(t *testing.T) {
+	env := setupPasswordPolicyService(t)
+	policy := domain.DefaultPasswordPolicy()
+
+	// Meets the character rules, but is a common password in any case
+	for _, password := range []string{"Password123", "pASSWORD123", "Summer2024"} {
+		if got := passwordRule(t, env.svc.CheckPassword(context.Background(), nil, policy, password)); got != domain.PasswordRuleBreached {
+			t.Errorf("CheckPassword(%q) rule = %q, want breached", password, got)
+		}
+	}
+}
+
+func TestPasswordPolicyService_CheckPassword_Reuse(t *testing.T) {
+	env := setupPasswordPolicyService(t)
+	ctx := context.Background()
+	policy := domain.DefaultPasswordPolicy()
+	policy.HistoryCount = 3
+
+	// Replaced passwords, oldest first; Current123! is the current one
+	for _, old := range []string{"Oldest-Pass1", "Older-Pass1", "Recent-Pass1"} {
+		hash, _ := NewPasswordService().Hash(old)
+		env.history.Create(ctx, &domain.PasswordHistoryEntry{UserID: env.user.ID, PasswordHash: hash, CreatedAt: time.Now()})
+		time.Sleep(time.Millisecond)
+	}
+
+	tests := []struct {
+		password string
+		want     domain.PasswordRule
+	}{
+		{"Current123!", domain.PasswordRuleReused},
+		{"Recent-Pass1", domain.PasswordRuleReused},
+		{"Older-Pass1", domain.PasswordRuleReused},
+		{"Oldest-Pass1", ""}, // Fourth most recent, outside a history of 3
+	}
+	for _, tt := range tests {
+		err := env.svc.CheckPassword(ctx, env.user, policy, tt.password)
+		if got := passwordRule(t, err); got != tt.want {
+			t.Errorf("CheckPassword(%q) rule = %q, want %q", tt.password, got, tt.want)
+		}
+	}
+
+	policy.HistoryCount = 0
+	if err := env.svc.CheckPassword(ctx, env.user, policy, "Current123!"); err != nil {
+		t.Errorf("with no history rule, reusing the current password should pass: %v", err)
+	}
+}
+
+func TestPasswordPolicyService_CheckPassword_SkipsRetiredPepper(t *testing.T) {
+	env := setupPasswordPolicyService(t)
+	ctx := context.Background()
+	policy := domain.DefaultPasswordPolicy()
+	policy.HistoryCount = 3
+
+	// A history entry made with a pepper that has since been retired
+	retired, err := NewPasswordServiceWithConfig(PasswordHashingConfig{Peppers: []PasswordPepper{testPepper(1)}})
+	if err != nil {
+		t.Fatalf("NewPasswordServiceWithConfig: %v", err)
+	}
+	hash, _ := retired.Hash("Retired-Pass1")
+	env.history.Create(ctx, &domain.PasswordHistoryEntry{UserID: env.user.ID, PasswordHash: hash, CreatedAt: time.Now()})
+
+	if err := env.svc.CheckPassword(ctx, env.user, policy, "Retired-Pass1"); err != nil {
+		t.Errorf("a hash that can't be compared should be skipped, got %v", err)
+	}
+	if got := passwordRule(t, env.svc.CheckPassword(ctx, env.user, policy, "Current123!")); got != domain.PasswordRuleReused {
+		t.Errorf("the current password should still be checked, rule = %q", got)
+	}
+}
+
+func TestPasswordPolicyService_RecordPasswordChange_Prunes(t *testing.T) {
+	env := setupPasswordPolicyService(t)
+	ctx := context.Background()
+
+	for i := 0; i < domain.MaxPasswordHistory+3; i++ {
+		if err := env.svc.RecordPasswordChange(ctx, env.user.ID, "hash"); err != nil {
+			t.Fatalf("RecordPasswordChange failed: %v", err)
+		}
+	}
+	// With the current password, that makes MaxPasswordHistory in all
+	if got := env.history.CountForUser(env.user.ID); got != domain.MaxPasswordHistory-1 {
+		t.Errorf("history entries = %d, want %d", got, domain.MaxPasswordHistory-1)
+	}
+}
+
+func TestUserService_ChangePassword_EnforcesTenantPolicy(t *testing.T) {
+	env := setupPasswordPolicyService(t)
+	userRepo := mock.NewMockUserRepository()
+	userSvc := NewUserService(UserServiceConfig{
+		UserRepo:         userRepo,
+		RoleRepo:         env.roleRepo,
+		SessionRepo:      mock.NewMockSessionRepository(),
+		EventRepo:        env.eventRepo,
+		PasswordReset:    mock.NewMockPasswordResetRepository(),
+		PasswordPolicies: env.svc,
+	})
+	userRepo.AddUser(env.user)
+	env.policies.AddPolicy(&domain.PasswordPolicy{TenantID: env.tenantB, MinLength: 16, HistoryCount: 2})
+	ctx := context.Background()
+
+	change := func(current, next string) error {
+		return userSvc.ChangePassword(ctx, ChangePasswordRequest{UserID: env.user.ID, CurrentPassword: current, NewPassword: next})
+	}
+
+	if got := passwordRule(t, change("Current123!", "Short-Pass-1")); got != domain.PasswordRuleLength {
+		t.Fatalf("12-character password rule = %q, want length (tenant B requires 16)", got)
+	}
+	if err := change("Current123!", "Long-Enough-Passphrase-1"); err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+
+	updated, _ := userRepo.FindByID(ctx, env.user.ID)
+	if updated.PasswordChangedAt == nil || time.Since(*updated.PasswordChangedAt) > time.Minute {
+		t.Errorf("PasswordChangedAt = %v, want now", updated.PasswordChangedAt)
+	}
+	if env.history.CountForUser(env.user.ID) != 1 {
+		t.Error("the replaced password should be kept in the history")
+	}
+
+	// Changing back to the previous password is reuse
+	if got := passwordRule(t, change("Long-Enough-Passphrase-1", "Current123!-but-longer")); got != "" {
+		t.Fatalf("a new password should pass, got rule %q", got)
+	}
+	if got := passwordRule(t, change("Current123!-but-longer", "Long-Enough-Passphrase-1")); got != domain.PasswordRuleReused {
+		t.Errorf("reusing the previous password rule = %q, want reused", got)
+	}
+}
+
+func TestAuthService_Login_ExpiresOldPassword(t *testing.T) {
+	authSvc, userRepo, _, tenantRepo, eventRepo := setupAuthService(t)
+	env := setupPasswordPolicyService(t)
+	authSvc.pwdPolicies = env.svc
+	ctx := context.Background()
+
+	env.policies.AddPolicy(&domain.PasswordPolicy{TenantID: env.tenantA, MinLength: 8, MaxAgeDays: 90})
+	tenantRepo.AddTenant(&domain.Tenant{ID: env.tenantA, Name: "Casa Ana", Slug: "casa-ana", IsActive: true})
+	tenantRepo.AddTenant(&domain.Tenant{ID: env.tenantB, Name: "Casa Bea", Slug: "casa-bea", IsActive: true})
+
+	changedAt := time.Now().Add(-91 * 24 * time.Hour)
+	env.user.PasswordChangedAt = &changedAt
+	env.user.TenantRoles = []domain.UserTenantRole{{UserID: env.user.ID, TenantID: env.tenantA, Role: domain.RoleWaiter}}
+	userRepo.AddUser(env.user)
+
+	resp, err := authSvc.Login(ctx, LoginRequest{Email: env.user.Email, Password: "Current123!"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if !resp.User.MustResetPwd {
+		t.Error("a password older than the policy's maximum age should require a reset")
+	}
+	if !hasEventType(eventRepo, domain.EventPasswordExpired) {
+		t.Error("expected a password_expired event")
+	}
+
+	// A recently changed password isn't expired
+	recent := time.Now().Add(-time.Hour)
+	fresh := &domain.User{ID: uuid.New(), Email: "bea@example.com", PasswordHash: env.user.PasswordHash, PasswordChangedAt: &recent, IsActive: true,
+		TenantRoles: []domain.UserTenantRole{{TenantID: env.tenantA, Role: domain.RoleWaiter}}}
+	userRepo.AddUser(fresh)
+	env.roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: fresh.ID, TenantID: env.tenantA, Role: domain.RoleWaiter})
+	resp, err = authSvc.Login(ctx, LoginRequest{Email: fresh.Email, Password: "Current123!"})
+	if err != nil || resp.User.MustResetPwd {
+		t.Errorf("Login with a recent password = %v, must reset %v; want no reset", err, resp != nil && resp.User.MustResetPwd)
+	}
+}
+
+func TestBreachedPasswordFilter(t *testing.T) {
+	filter, err := NewBreachedPasswordFilter(strings.NewReader("hunter2\n\n  Tr0ub4dor  \n"))
+	if err != nil {
+		t.Fatalf("NewBreachedPasswordFilter failed: %v", err)
+	}
+	if filter.Len() != 2 {
+		t.Errorf("Len() = %d, want 2 (blank lines skipped)", filter.Len())
+	}
+	for _, password := range []string{"hunter2", "HUNTER2", "tr0ub4dor"} {
+		if !filter.Contains(password) {
+			t.Errorf("Contains(%q) = false, want true", password)
+		}
+	}
+	if filter.Contains("correct horse battery staple") {
+		t.Error("Contains should be false for a password not in the list")
+	}
+
+	if BundledBreachedPasswords().Len() < 10000 {
+		t.Errorf("bundled list has %d passwords, want at least 10000", BundledBreachedPasswords().Len())
+	}
+}
@@ -1,8 +1,12 @@
 package service
 
 import (
+	"bytes"
+	"errors"
 	"strings"
 	"testing"
+
+	"github.com/alexedwards/argon2id"
 )
 
 func TestPasswordService_HashAndVerify(t *testing.T) {
@@ -105,32 +109,6 @@ func TestPasswordService_ValidatePassword(t *testing.T) {
 	}
 }
 
-func TestPasswordService_GenerateTemporaryPassword(t *testing.T) {
-	svc := NewPasswordService()
-
-	// Generate multiple passwords and check they're unique and valid
-	seen := make(map[string]bool)
-	for i := 0; i < 10; i++ {
-		password, err := svc.GenerateTemporaryPassword()
-		if err != nil {
-			t.Fatalf("failed to generate temporary password: %v", err)
-		}
-
-		if len(password) != 12 {
-			t.Errorf("expected password length 12, got %d", len(password))
-		}
-
-		if err := svc.ValidatePassword(password); err != nil {
-			t.Errorf("generated password %q failed validation: %v", password, err)
-		}
-
-		if seen[password] {
-			t.Error("generated duplicate password")
-		}
-		seen[password] = true
-	}
-}
-
 func TestPasswordService_GenerateResetToken(t *testing.T) {
 	svc := NewPasswordService()
 
@@ -253,6 +231,136 @@ func TestPasswordService_HashFormat(t *testing.T) {
 	}
 }
 
+// testPepper returns a pepper with a secret long enough to be accepted.
+func testPepper(version int) PasswordPepper {
+	return PasswordPepper{Version: version, Secret: bytes.Repeat([]byte{byte(version)}, minPepperLength)}
+}
+
+func newPepperedPasswordService(t *testing.T, peppers ...PasswordPepper) *PasswordService {
+	t.Helper()
+	svc, err := NewPasswordServiceWithConfig(PasswordHashingConfig{Peppers: peppers})
+	if err != nil {
+		t.Fatalf("NewPasswordServiceWithConfig: %v", err)
+	}
+	return svc
+}
+
+func TestPasswordService_Pepper(t *testing.T) {
+	svc := newPepperedPasswordService(t, testPepper(1))
+
+	hash, err := svc.Hash("SecurePassword123")
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$pepper=1$argon2id$") {
+		t.Errorf("expected a pepper-1 hash, got %s", hash)
+	}
+	if match, err := svc.Verify("SecurePassword123", hash); err != nil || !match {
+		t.Errorf("expected password to match, got %v, %v", match, err)
+	}
+	if match, _ := svc.Verify("WrongPassword123", hash); match {
+		t.Error("expected wrong password to not match")
+	}
+
+	// Without the pepper the hash is useless
+	if _, err := NewPasswordService().Verify("SecurePassword123", hash); !errors.Is(err, ErrUnknownPepper) {
+		t.Errorf("expected ErrUnknownPepper, got %v", err)
+	}
+	// And a different secret under the same version doesn't match
+	other := newPepperedPasswordService(t, PasswordPepper{Version: 1, Secret: bytes.Repeat([]byte{9}, minPepperLength)})
+	if match, _ := other.Verify("SecurePassword123", hash); match {
+		t.Error("expected a different pepper secret to not match")
+	}
+}
+
+func TestPasswordService_PepperRotation(t *testing.T) {
+	unpeppered, _ := NewPasswordService().Hash("SecurePassword123")
+	v1, _ := newPepperedPasswordService(t, testPepper(1)).Hash("SecurePassword123")
+
+	svc := newPepperedPasswordService(t, testPepper(1), testPepper(2))
+	v2, _ := svc.Hash("SecurePassword123")
+	if !strings.HasPrefix(v2, "$pepper=2$") {
+		t.Errorf("expected new hashes to use the highest pepper, got %s", v2)
+	}
+
+	for name, hash := range map[string]string{"unpeppered": unpeppered, "v1": v1, "v2": v2} {
+		if match, err := svc.Verify("SecurePassword123", hash); err != nil || !match {
+			t.Errorf("%s: expected password to match, got %v, %v", name, match, err)
+		}
+	}
+	if !svc.NeedsRehash(unpeppered) || !svc.NeedsRehash(v1) {
+		t.Error("expected hashes without the current pepper to need a rehash")
+	}
+	if svc.NeedsRehash(v2) {
+		t.Error("expected a current hash to not need a rehash")
+	}
+}
+
+func TestPasswordService_NeedsRehash_Params(t *testing.T) {
+	weak := &argon2id.Params{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	old, err := NewPasswordServiceWithConfig(PasswordHashingConfig{Params: weak})
+	if err != nil {
+		t.Fatalf("NewPasswordServiceWithConfig: %v", err)
+	}
+	hash, _ := old.Hash("SecurePassword123")
+
+	svc := NewPasswordService()
+	if !svc.NeedsRehash(hash) {
+		t.Error("expected a hash with outdated parameters to need a rehash")
+	}
+	if old.NeedsRehash(hash) {
+		t.Error("expected a hash with the configured parameters to not need a rehash")
+	}
+	if svc.NeedsRehash("not-a-hash") {
+		t.Error("expected an unparseable hash to be left alone")
+	}
+
+	info, err := svc.Inspect(hash)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if info.Params != *weak || info.PepperVersion != 0 || info.Current {
+		t.Errorf("unexpected hash info: %+v", info)
+	}
+}
+
+func TestNewPasswordServiceWithConfig_InvalidPeppers(t *testing.T) {
+	tests := []struct {
+		name    string
+		peppers []PasswordPepper
+	}{
+		{"zero version", []PasswordPepper{{Version: 0, Secret: testPepper(1).Secret}}},
+		{"short secret", []PasswordPepper{{Version: 1, Secret: []byte("short")}}},
+		{"duplicate version", []PasswordPepper{testPepper(1), testPepper(1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPasswordServiceWithConfig(PasswordHashingConfig{Peppers: tt.peppers}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParsePasswordPeppers(t *testing.T) {
+	peppers, err := ParsePasswordPeppers("1:AQID, 2:BAUG")
+	if err != nil {
+		t.Fatalf("ParsePasswordPeppers: %v", err)
+	}
+	if len(peppers) != 2 || peppers[0].Version != 1 || !bytes.Equal(peppers[1].Secret, []byte{4, 5, 6}) {
+		t.Errorf("unexpected peppers: %+v", peppers)
+	}
+
+	if peppers, err := ParsePasswordPeppers(""); err != nil || len(peppers) != 0 {
+		t.Errorf("expected no peppers, got %v, %v", peppers, err)
+	}
+	for _, bad := range []string{"AQID", "x:AQID", "1:not base64!"} {
+		if _, err := ParsePasswordPeppers(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
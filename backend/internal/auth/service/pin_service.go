@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+)
+
+// maxFailedPINAttempts is the number of consecutive wrong PINs that locks a staff PIN.
+const maxFailedPINAttempts = 5
+
+// pinLockDuration is how long a staff PIN stays locked after hitting maxFailedPINAttempts.
+const pinLockDuration = 15 * time.Minute
+
+// defaultPINTokenTTL is how long an access token issued by PIN login lasts.
+// There is no refresh token: the next user simply logs in over it.
+const defaultPINTokenTTL = 15 * time.Minute
+
+// PINService handles staff PINs, registered POS terminals, and fast PIN
+// login on those terminals.
+type PINService struct {
+	pinRepo      repository.StaffPINRepository
+	terminalRepo repository.TerminalRepository
+	userRepo     repository.UserRepository
+	tenantRepo   repository.TenantRepository
+	eventRepo    repository.AuthEventRepository
+	tokenService *TokenService
+	passwordSvc  *PasswordService
+	rateLimiter  RateLimiter
+	tokenTTL     time.Duration
+}
+
+// PINServiceConfig holds configuration for PINService.
+type PINServiceConfig struct {
+	PINRepo      repository.StaffPINRepository
+	TerminalRepo repository.TerminalRepository
+	UserRepo     repository.UserRepository
+	TenantRepo   repository.TenantRepository
+	EventRepo    repository.AuthEventRepository
+	TokenService *TokenService
+	// RateLimiter limits PIN login attempts per terminal. If nil, only the
+	// per-PIN lockout applies.
+	RateLimiter RateLimiter
+	// TokenTTL is the lifetime of PIN login access tokens. Defaults to 15 minutes.
+	TokenTTL time.Duration
+	// Passwords hashes and verifies passwords and PINs. Defaults to
+	// NewPasswordService(), without a pepper.
+	Passwords *PasswordService
+}
+
+// NewPINService creates a new PINService.
+func NewPINService(cfg PINServiceConfig) *PINService {
+	passwordSvc := cfg.Passwords
+	if passwordSvc == nil {
+		passwordSvc = NewPasswordService()
+	}
+	tokenTTL := cfg.TokenTTL
+	if tokenTTL == 0 {
+		tokenTTL = defaultPINTokenTTL
+	}
+	return &PINService{
+		pinRepo:      cfg.PINRepo,
+		terminalRepo: cfg.TerminalRepo,
+		userRepo:     cfg.UserRepo,
+		tenantRepo:   cfg.TenantRepo,
+		eventRepo:    cfg.EventRepo,
+		tokenService: cfg.TokenService,
+		passwordSvc:  passwordSvc,
+		rateLimiter:  cfg.RateLimiter,
+		tokenTTL:     tokenTTL,
+	}
+}
+
+// RegisterTerminal registers a shared POS device for a tenant and returns it
+// with its plain device token. The token is shown once; only its hash is stored.
+func (s *PINService) RegisterTerminal(ctx context.Context, tenantID, createdBy uuid.UUID, name, ipAddress string) (*domain.Terminal, string, error) {
+	plainToken, tokenHash, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("register terminal: generate token: %w", err)
+	}
+
+	terminal := &domain.Terminal{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Name:      name,
+		TokenHash: tokenHash,
+		CreatedBy: createdBy,
+	}
+	if err := s.terminalRepo.Create(ctx, terminal); err != nil {
+		return nil, "", fmt.Errorf("register terminal: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventTerminalRegistered, &createdBy, &tenantID, ipAddress, "", map[string]interface{}{
+		"terminal_id": terminal.ID.String(),
+		"name":        name,
+	})
+
+	return terminal, plainToken, nil
+}
+
+// ListTerminals returns a tenant's active terminals.
+func (s *PINService) ListTerminals(ctx context.Context, tenantID uuid.UUID) ([]*domain.Terminal, error) {
+	terminals, err := s.terminalRepo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list terminals: %w", err)
+	}
+	return terminals, nil
+}
+
+// RevokeTerminal revokes one of a tenant's terminals. Terminals belonging to
+// another tenant are reported as not found.
+func (s *PINService) RevokeTerminal(ctx context.Context, tenantID, terminalID, revokedBy uuid.UUID, ipAddress string) error {
+	terminal, err := s.terminalRepo.FindByID(ctx, terminalID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTerminalNotFound) {
+			return err
+		}
+		return fmt.Errorf("revoke terminal: lookup: %w", err)
+	}
+	if terminal.TenantID != tenantID || terminal.IsRevoked() {
+		return domain.ErrTerminalNotFound
+	}
+
+	if err := s.terminalRepo.Revoke(ctx, terminalID); err != nil {
+		if errors.Is(err, domain.ErrTerminalNotFound) {
+			return err
+		}
+		return fmt.Errorf("revoke terminal: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventTerminalRevoked, &revokedBy, &tenantID, ipAddress, "", map[string]interface{}{
+		"terminal_id": terminalID.String(),
+	})
+	return nil
+}
+
+// AuthenticateTerminal looks up a terminal by its plain device token.
+// Unknown and revoked terminals return ErrTerminalInvalid.
+func (s *PINService) AuthenticateTerminal(ctx context.Context, plainToken string) (*domain.Terminal, error) {
+	if plainToken == "" {
+		return nil, domain.ErrTerminalInvalid
+	}
+	terminal, err := s.terminalRepo.FindByToken(ctx, s.passwordSvc.HashResetToken(plainToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrTerminalNotFound) {
+			return nil, domain.ErrTerminalInvalid
+		}
+		return nil, fmt.Errorf("authenticate terminal: %w", err)
+	}
+	if terminal.IsRevoked() {
+		return nil, domain.ErrTerminalInvalid
+	}
+	return terminal, nil
+}
+
+// ListStaff returns the users a terminal can offer for PIN login: active
+// members of its tenant, at a role allowed to use a PIN, who have set one.
+func (s *PINService) ListStaff(ctx context.Context, terminal *domain.Terminal) ([]*domain.User, error) {
+	pins, err := s.pinRepo.ListByTenant(ctx, terminal.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list staff: %w", err)
+	}
+
+	users := make([]*domain.User, 0, len(pins))
+	for _, pin := range pins {
+		user, err := s.userRepo.FindByIDWithTenants(ctx, pin.UserID)
+		if err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("list staff: user lookup: %w", err)
+		}
+		role := user.GetRoleForTenant(terminal.TenantID)
+		if !user.CanLogin() || role == "" || !role.AllowsPINLogin() {
+			continue
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// SetPINRequest contains the data needed to set a staff PIN.
+type SetPINRequest struct {
+	UserID          uuid.UUID
+	TenantID        uuid.UUID
+	CurrentPassword string
+	PIN             string
+	IPAddress       string
+}
+
+// SetPIN sets or replaces the user's PIN for a tenant. The user confirms
+// with their password, since a PIN is a credential in its own right. Any
+// role can set one: cashier and below log in with it, while manager and
+// above can only use it to approve actions on a terminal (see
+// ApprovalService).
+func (s *PINService) SetPIN(ctx context.Context, req SetPINRequest) error {
+	user, err := s.userRepo.FindByIDWithTenants(ctx, req.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return err
+		}
+		return fmt.Errorf("set pin: user lookup: %w", err)
+	}
+
+	if !user.HasTenant(req.TenantID) {
+		return domain.ErrUserNotInTenant
+	}
+
+	match, err := s.passwordSvc.Verify(req.CurrentPassword, user.PasswordHash)
+	if err != nil {
+		return fmt.Errorf("set pin: password verify: %w", err)
+	}
+	if !match {
+		return domain.ErrPasswordIncorrect
+	}
+
+	if err := domain.ValidatePIN(req.PIN); err != nil {
+		return err
+	}
+	pinHash, err := s.passwordSvc.Hash(req.PIN)
+	if err != nil {
+		return fmt.Errorf("set pin: hash: %w", err)
+	}
+
+	if err := s.pinRepo.Save(ctx, &domain.StaffPIN{
+		ID:       uuid.New(),
+		UserID:   user.ID,
+		TenantID: req.TenantID,
+		PINHash:  pinHash,
+	}); err != nil {
+		return fmt.Errorf("set pin: save: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventPINSet, &user.ID, &req.TenantID, req.IPAddress, "", nil)
+	return nil
+}
+
+// RemovePIN removes the user's PIN for a tenant, turning off PIN login for them.
+func (s *PINService) RemovePIN(ctx context.Context, userID, tenantID uuid.UUID, ipAddress string) error {
+	if err := s.pinRepo.Delete(ctx, userID, tenantID); err != nil {
+		return fmt.Errorf("remove pin: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventPINRemoved, &userID, &tenantID, ipAddress, "", nil)
+	return nil
+}
+
+// PINLoginRequest contains the data needed for PIN login.
+type PINLoginRequest struct {
+	TerminalToken string
+	UserID        uuid.UUID
+	PIN           string
+	IPAddress     string
+	UserAgent     string
+}
+
+// Login authenticates a staff member by PIN on a registered terminal and
+// issues a short-lived access token scoped to the terminal's tenant. No
+// refresh token or session is created.
+func (s *PINService) Login(ctx context.Context, req PINLoginRequest) (*LoginResponse, error) {
+	terminal, err := s.AuthenticateTerminal(ctx, req.TerminalToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrTerminalInvalid) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("pin login: %w", err)
+	}
+	tenantID := terminal.TenantID
+	metadata := func(reason string) map[string]interface{} {
+		return map[string]interface{}{
+			"method":      "pin",
+			"terminal_id": terminal.ID.String(),
+			"reason":      reason,
+		}
+	}
+
+	// Check rate limit (per terminal, so cycling through users doesn't help)
+	if s.rateLimiter != nil {
+		allowed, err := s.rateLimiter.Allow(ctx, terminal.ID.String())
+		if err != nil {
+			return nil, fmt.Errorf("pin login: rate limit check: %w", err)
+		}
+		if !allowed {
+			s.logEvent(ctx, domain.EventLoginFailed, nil, &tenantID, req.IPAddress, req.UserAgent, metadata("rate_limit_exceeded"))
+			return nil, domain.ErrRateLimitExceeded
+		}
+	}
+
+	user, err := s.userRepo.FindByIDWithTenants(ctx, req.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			s.logEvent(ctx, domain.EventLoginFailed, nil, &tenantID, req.IPAddress, req.UserAgent, metadata("user_not_found"))
+			return nil, domain.ErrPINInvalid
+		}
+		return nil, fmt.Errorf("pin login: user lookup: %w", err)
+	}
+
+	role := user.GetRoleForTenant(tenantID)
+	if role == "" {
+		s.logEvent(ctx, domain.EventLoginFailed, &user.ID, &tenantID, req.IPAddress, req.UserAgent, metadata("not_in_tenant"))
+		return nil, domain.ErrPINInvalid
+	}
+	if !role.AllowsPINLogin() {
+		s.logEvent(ctx, domain.EventLoginFailed, &user.ID, &tenantID, req.IPAddress, req.UserAgent, metadata("role_not_allowed"))
+		return nil, domain.ErrPINNotAllowed
+	}
+
+	lockedUntil, err := s.checkPIN(ctx, user.ID, tenantID, req.PIN, terminal.ID, req.IPAddress, req.UserAgent)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrPINNotSet):
+			s.logEvent(ctx, domain.EventLoginFailed, &user.ID, &tenantID, req.IPAddress, req.UserAgent, metadata("pin_not_set"))
+			return nil, domain.ErrPINInvalid
+		case errors.Is(err, domain.ErrPINLocked):
+			s.logEvent(ctx, domain.EventLoginFailed, &user.ID, &tenantID, req.IPAddress, req.UserAgent, metadata("pin_locked"))
+			return &LoginResponse{LockedUntil: lockedUntil}, err
+		case errors.Is(err, domain.ErrPINInvalid):
+			s.logEvent(ctx, domain.EventLoginFailed, &user.ID, &tenantID, req.IPAddress, req.UserAgent, metadata("invalid_pin"))
+			return nil, err
+		default:
+			return nil, fmt.Errorf("pin login: %w", err)
+		}
+	}
+
+	if !user.CanLogin() {
+		s.logEvent(ctx, domain.EventLoginFailed, &user.ID, &tenantID, req.IPAddress, req.UserAgent, metadata("account_disabled"))
+		return nil, domain.ErrAccountDisabled
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("pin login: tenant lookup: %w", err)
+	}
+	if !tenant.IsOperational() {
+		return nil, domain.ErrTenantInactive
+	}
+
+	tokenPair, err := s.tokenService.GenerateAccessToken(user, tenantID, role, user.GetPermissionsForTenant(tenantID), s.tokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("pin login: token generation: %w", err)
+	}
+
+	// Last-used is informational; a failed write doesn't fail the login
+	_ = s.terminalRepo.TouchLastUsed(ctx, terminal.ID, time.Now())
+
+	s.logEvent(ctx, domain.EventLoginSuccess, &user.ID, &tenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+		"method":      "pin",
+		"terminal_id": terminal.ID.String(),
+	})
+
+	return &LoginResponse{
+		TokenPair: tokenPair,
+		User:      user,
+		TenantID:  tenantID,
+		Role:      role,
+	}, nil
+}
+
+// checkPIN verifies a user's PIN for a tenant against the per-PIN lockout.
+// It returns ErrPINNotSet, ErrPINLocked (with the end of the lock) or
+// ErrPINInvalid, and a match clears any earlier failed attempts.
+func (s *PINService) checkPIN(ctx context.Context, userID, tenantID uuid.UUID, plainPIN string, terminalID uuid.UUID, ipAddress, userAgent string) (*time.Time, error) {
+	pin, err := s.pinRepo.FindByUserAndTenant(ctx, userID, tenantID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPINNotSet) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("pin lookup: %w", err)
+	}
+
+	// Check PIN lockout (independent of the password lockout)
+	if pin.IsLocked() {
+		return pin.LockedUntil, domain.ErrPINLocked
+	}
+
+	match, err := s.passwordSvc.Verify(plainPIN, pin.PINHash)
+	if err != nil {
+		return nil, fmt.Errorf("pin verify: %w", err)
+	}
+	if !match {
+		locked := pin.RecordFailure(maxFailedPINAttempts, pinLockDuration)
+		if err := s.pinRepo.Update(ctx, pin); err != nil {
+			return nil, fmt.Errorf("update failed attempts: %w", err)
+		}
+		if locked {
+			s.logEvent(ctx, domain.EventPINLocked, &userID, &tenantID, ipAddress, userAgent, map[string]interface{}{
+				"terminal_id":     terminalID.String(),
+				"failed_attempts": pin.FailedAttempts,
+			})
+		}
+		return nil, domain.ErrPINInvalid
+	}
+
+	// Successful PIN check - reset lockout counter
+	if pin.FailedAttempts != 0 || pin.LockedUntil != nil {
+		pin.ResetFailures()
+		if err := s.pinRepo.Update(ctx, pin); err != nil {
+			return nil, fmt.Errorf("reset failed attempts: %w", err)
+		}
+	}
+	return nil, nil
+}
+
+// logEvent logs a PIN or terminal event.
+func (s *PINService) logEvent(ctx context.Context, eventType domain.AuthEventType, userID, tenantID *uuid.UUID, ipAddress, userAgent string, metadata map[string]interface{}) {
+	event := newAuthEvent(ctx, eventType, userID, tenantID, ipAddress, userAgent)
+	if metadata != nil {
+		event.Metadata = metadata
+	}
+	// Fire and forget - don't fail the request if logging fails
+	_ = s.eventRepo.Create(ctx, event)
+}
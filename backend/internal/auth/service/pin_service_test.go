@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/pkg/jwt"
+)
+
+// pinTestEnv bundles a PINService with its mock repositories and a
+// registered terminal for one tenant.
+type pinTestEnv struct {
+	svc           *PINService
+	tokenSvc      *TokenService
+	userRepo      *mock.MockUserRepository
+	pinRepo       *mock.MockStaffPINRepository
+	terminalRepo  *mock.MockTerminalRepository
+	tenantRepo    *mock.MockTenantRepository
+	eventRepo     *mock.MockAuthEventRepository
+	tenantID      uuid.UUID
+	terminal      *domain.Terminal
+	terminalToken string
+}
+
+func setupPINService(t *testing.T, rateLimiter RateLimiter) *pinTestEnv {
+	t.Helper()
+
+	_, privatePEM, publicPEM := testKeyPair(t)
+	km := jwt.NewKeyManager()
+	if err := km.LoadPrivateKeyFromPEM(privatePEM); err != nil {
+		t.Fatalf("failed to load private key: %v", err)
+	}
+	if err := km.LoadPublicKeyFromPEM(publicPEM); err != nil {
+		t.Fatalf("failed to load public key: %v", err)
+	}
+
+	env := &pinTestEnv{
+		tokenSvc:     NewTokenService(km, jwt.DefaultTokenGeneratorConfig()),
+		userRepo:     mock.NewMockUserRepository(),
+		pinRepo:      mock.NewMockStaffPINRepository(),
+		terminalRepo: mock.NewMockTerminalRepository(),
+		tenantRepo:   mock.NewMockTenantRepository(),
+		eventRepo:    mock.NewMockAuthEventRepository(),
+		tenantID:     uuid.New(),
+	}
+	env.svc = NewPINService(PINServiceConfig{
+		PINRepo:      env.pinRepo,
+		TerminalRepo: env.terminalRepo,
+		UserRepo:     env.userRepo,
+		TenantRepo:   env.tenantRepo,
+		EventRepo:    env.eventRepo,
+		TokenService: env.tokenSvc,
+		RateLimiter:  rateLimiter,
+		TokenTTL:     5 * time.Minute,
+	})
+	env.tenantRepo.AddTenant(&domain.Tenant{ID: env.tenantID, Name: "Acme Diner", IsActive: true})
+
+	terminal, token, err := env.svc.RegisterTerminal(context.Background(), env.tenantID, uuid.New(), "Bar tablet", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RegisterTerminal failed: %v", err)
+	}
+	env.terminal = terminal
+	env.terminalToken = token
+
+	return env
+}
+
+// addStaff creates an active user with the given role in the env's tenant
+// and, if pin is non-empty, sets their PIN.
+func (e *pinTestEnv) addStaff(t *testing.T, role domain.Role, pin string) *domain.User {
+	t.Helper()
+
+	passwordHash, _ := NewPasswordService().Hash("Password123!")
+	user := &domain.User{
+		ID:           uuid.New(),
+		Email:        uuid.NewString() + "@example.com",
+		PasswordHash: passwordHash,
+		IsActive:     true,
+		TenantRoles:  []domain.UserTenantRole{{TenantID: e.tenantID, Role: role}},
+	}
+	e.userRepo.AddUser(user)
+
+	if pin != "" {
+		if err := e.svc.SetPIN(context.Background(), SetPINRequest{
+			UserID:          user.ID,
+			TenantID:        e.tenantID,
+			CurrentPassword: "Password123!",
+			PIN:             pin,
+		}); err != nil {
+			t.Fatalf("SetPIN failed: %v", err)
+		}
+	}
+	return user
+}
+
+func TestPINService_Login_Success(t *testing.T) {
+	env := setupPINService(t, nil)
+	ctx := context.Background()
+	waiter := env.addStaff(t, domain.RoleWaiter, "2580")
+
+	resp, err := env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: waiter.ID, PIN: "2580"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if resp.TokenPair.RefreshToken != "" {
+		t.Error("PIN login should not issue a refresh token")
+	}
+	if until := time.Until(resp.TokenPair.ExpiresAt); until > 5*time.Minute {
+		t.Errorf("token should be short-lived, expires in %v", until)
+	}
+
+	claims, err := env.tokenSvc.ValidateAccessToken(resp.TokenPair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+	if claims.TenantID != env.tenantID {
+		t.Errorf("token tenant = %s, want terminal tenant %s", claims.TenantID, env.tenantID)
+	}
+	if claims.Role != domain.RoleWaiter {
+		t.Errorf("token role = %s, want %s", claims.Role, domain.RoleWaiter)
+	}
+	if claims.SessionID != "" {
+		t.Error("PIN login token should not reference a session")
+	}
+
+	terminal, _ := env.terminalRepo.FindByID(ctx, env.terminal.ID)
+	if terminal.LastUsedAt == nil {
+		t.Error("terminal last-used time should be recorded")
+	}
+	if !hasEventType(env.eventRepo, domain.EventLoginSuccess) {
+		t.Error("expected a login_success event")
+	}
+}
+
+func TestPINService_Login_InvalidTerminal(t *testing.T) {
+	env := setupPINService(t, nil)
+	ctx := context.Background()
+	waiter := env.addStaff(t, domain.RoleWaiter, "2580")
+
+	if _, err := env.svc.Login(ctx, PINLoginRequest{TerminalToken: "not-a-terminal", UserID: waiter.ID, PIN: "2580"}); !errors.Is(err, domain.ErrTerminalInvalid) {
+		t.Errorf("Expected ErrTerminalInvalid for an unknown terminal, got %v", err)
+	}
+
+	if err := env.svc.RevokeTerminal(ctx, env.tenantID, env.terminal.ID, uuid.New(), "127.0.0.1"); err != nil {
+		t.Fatalf("RevokeTerminal failed: %v", err)
+	}
+	if _, err := env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: waiter.ID, PIN: "2580"}); !errors.Is(err, domain.ErrTerminalInvalid) {
+		t.Errorf("Expected ErrTerminalInvalid for a revoked terminal, got %v", err)
+	}
+}
+
+func TestPINService_Login_WrongPINLocks(t *testing.T) {
+	env := setupPINService(t, nil)
+	ctx := context.Background()
+	waiter := env.addStaff(t, domain.RoleWaiter, "2580")
+
+	for i := 0; i < maxFailedPINAttempts; i++ {
+		if _, err := env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: waiter.ID, PIN: "0000"}); !errors.Is(err, domain.ErrPINInvalid) {
+			t.Fatalf("attempt %d: expected ErrPINInvalid, got %v", i+1, err)
+		}
+	}
+	if !hasEventType(env.eventRepo, domain.EventPINLocked) {
+		t.Error("expected a pin_locked event")
+	}
+
+	resp, err := env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: waiter.ID, PIN: "2580"})
+	if !errors.Is(err, domain.ErrPINLocked) {
+		t.Fatalf("Expected ErrPINLocked even with the right PIN, got %v", err)
+	}
+	if resp == nil || resp.LockedUntil == nil {
+		t.Error("locked response should carry LockedUntil")
+	}
+}
+
+func TestPINService_Login_SuccessResetsFailures(t *testing.T) {
+	env := setupPINService(t, nil)
+	ctx := context.Background()
+	waiter := env.addStaff(t, domain.RoleWaiter, "2580")
+
+	env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: waiter.ID, PIN: "0000"})
+	if _, err := env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: waiter.ID, PIN: "2580"}); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	pin, _ := env.pinRepo.FindByUserAndTenant(ctx, waiter.ID, env.tenantID)
+	if pin.FailedAttempts != 0 {
+		t.Errorf("FailedAttempts = %d after a successful login, want 0", pin.FailedAttempts)
+	}
+}
+
+func TestPINService_Login_RoleNotAllowed(t *testing.T) {
+	env := setupPINService(t, nil)
+	ctx := context.Background()
+	cashier := env.addStaff(t, domain.RoleCashier, "2580")
+
+	// Promoted after setting a PIN: the role is checked at login, not just at setup
+	cashier.TenantRoles[0].Role = domain.RoleManager
+
+	if _, err := env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: cashier.ID, PIN: "2580"}); !errors.Is(err, domain.ErrPINNotAllowed) {
+		t.Errorf("Expected ErrPINNotAllowed for a manager, got %v", err)
+	}
+}
+
+func TestPINService_Login_OtherTenant(t *testing.T) {
+	env := setupPINService(t, nil)
+	ctx := context.Background()
+	waiter := env.addStaff(t, domain.RoleWaiter, "2580")
+
+	otherTenant := uuid.New()
+	env.tenantRepo.AddTenant(&domain.Tenant{ID: otherTenant, IsActive: true})
+	_, otherToken, _ := env.svc.RegisterTerminal(ctx, otherTenant, uuid.New(), "Other tablet", "127.0.0.1")
+
+	if _, err := env.svc.Login(ctx, PINLoginRequest{TerminalToken: otherToken, UserID: waiter.ID, PIN: "2580"}); !errors.Is(err, domain.ErrPINInvalid) {
+		t.Errorf("Expected ErrPINInvalid on another tenant's terminal, got %v", err)
+	}
+}
+
+func TestPINService_Login_DisabledAccount(t *testing.T) {
+	env := setupPINService(t, nil)
+	ctx := context.Background()
+	waiter := env.addStaff(t, domain.RoleWaiter, "2580")
+	waiter.IsActive = false
+
+	if _, err := env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: waiter.ID, PIN: "2580"}); !errors.Is(err, domain.ErrAccountDisabled) {
+		t.Errorf("Expected ErrAccountDisabled, got %v", err)
+	}
+}
+
+func TestPINService_Login_RateLimited(t *testing.T) {
+	limiter := NewMemoryRateLimiter(RateLimiterConfig{MaxRequests: 2, Window: time.Minute, KeyPrefix: "pin_login:"})
+	env := setupPINService(t, limiter)
+	ctx := context.Background()
+	waiter := env.addStaff(t, domain.RoleWaiter, "2580")
+	cashier := env.addStaff(t, domain.RoleCashier, "7391")
+
+	env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: waiter.ID, PIN: "0000"})
+	env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: cashier.ID, PIN: "0000"})
+
+	// The limit is per terminal, so moving on to another user doesn't reset it
+	if _, err := env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: cashier.ID, PIN: "7391"}); !errors.Is(err, domain.ErrRateLimitExceeded) {
+		t.Errorf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+}
+
+func TestPINService_SetPIN(t *testing.T) {
+	env := setupPINService(t, nil)
+	ctx := context.Background()
+	waiter := env.addStaff(t, domain.RoleWaiter, "")
+	manager := env.addStaff(t, domain.RoleManager, "")
+
+	tests := []struct {
+		name     string
+		userID   uuid.UUID
+		password string
+		pin      string
+		wantErr  error
+	}{
+		{"valid", waiter.ID, "Password123!", "2580", nil},
+		{"wrong password", waiter.ID, "WrongPassword1!", "2580", domain.ErrPasswordIncorrect},
+		{"too short", waiter.ID, "Password123!", "258", domain.ErrPINFormat},
+		{"too simple", waiter.ID, "Password123!", "1234", domain.ErrPINTooSimple},
+		{"manager (approvals only)", manager.ID, "Password123!", "2580", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := env.svc.SetPIN(ctx, SetPINRequest{UserID: tt.userID, TenantID: env.tenantID, CurrentPassword: tt.password, PIN: tt.pin})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetPIN() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if !hasEventType(env.eventRepo, domain.EventPINSet) {
+		t.Error("expected a pin_set event")
+	}
+}
+
+func TestPINService_RemovePIN(t *testing.T) {
+	env := setupPINService(t, nil)
+	ctx := context.Background()
+	waiter := env.addStaff(t, domain.RoleWaiter, "2580")
+
+	if err := env.svc.RemovePIN(ctx, waiter.ID, env.tenantID, "127.0.0.1"); err != nil {
+		t.Fatalf("RemovePIN failed: %v", err)
+	}
+	if _, err := env.svc.Login(ctx, PINLoginRequest{TerminalToken: env.terminalToken, UserID: waiter.ID, PIN: "2580"}); !errors.Is(err, domain.ErrPINInvalid) {
+		t.Errorf("Expected ErrPINInvalid after removing the PIN, got %v", err)
+	}
+}
+
+func TestPINService_ListStaff(t *testing.T) {
+	env := setupPINService(t, nil)
+	ctx := context.Background()
+	waiter := env.addStaff(t, domain.RoleWaiter, "2580")
+	env.addStaff(t, domain.RoleCashier, "")
+	inactive := env.addStaff(t, domain.RoleKitchen, "7391")
+	inactive.IsActive = false
+
+	staff, err := env.svc.ListStaff(ctx, env.terminal)
+	if err != nil {
+		t.Fatalf("ListStaff failed: %v", err)
+	}
+	if len(staff) != 1 || staff[0].ID != waiter.ID {
+		t.Errorf("expected only the active waiter with a PIN, got %d users", len(staff))
+	}
+}
+
+func TestPINService_RevokeTerminal_OtherTenant(t *testing.T) {
+	env := setupPINService(t, nil)
+	ctx := context.Background()
+
+	if err := env.svc.RevokeTerminal(ctx, uuid.New(), env.terminal.ID, uuid.New(), "127.0.0.1"); !errors.Is(err, domain.ErrTerminalNotFound) {
+		t.Errorf("Expected ErrTerminalNotFound for another tenant's terminal, got %v", err)
+	}
+	if _, err := env.svc.AuthenticateTerminal(ctx, env.terminalToken); err != nil {
+		t.Errorf("terminal should still work, got %v", err)
+	}
+}
@@ -52,3 +52,26 @@ func DefaultPasswordResetRateLimiterConfig() RateLimiterConfig {
 		KeyPrefix:   "password_reset:",
 	}
 }
+
+// DefaultPINLoginRateLimiterConfig returns the default config for staff PIN
+// login rate limiting: 20 attempts per minute per terminal. This is looser
+// than password login since staff switch users on a terminal all shift, and
+// per-PIN lockout stops guessing any one PIN.
+func DefaultPINLoginRateLimiterConfig() RateLimiterConfig {
+	return RateLimiterConfig{
+		MaxRequests: 20,
+		Window:      time.Minute,
+		KeyPrefix:   "pin_login:",
+	}
+}
+
+// DefaultApprovalRateLimiterConfig returns the default config for step-up
+// approval rate limiting: 10 attempts per minute per requesting user, so a
+// cashier can't sit at the till guessing a manager's password or PIN.
+func DefaultApprovalRateLimiterConfig() RateLimiterConfig {
+	return RateLimiterConfig{
+		MaxRequests: 10,
+		Window:      time.Minute,
+		KeyPrefix:   "approval:",
+	}
+}
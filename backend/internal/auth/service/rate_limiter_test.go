@@ -199,3 +199,17 @@ func TestDefaultPasswordResetRateLimiterConfig(t *testing.T) {
 		t.Errorf("KeyPrefix = %q, want %q", cfg.KeyPrefix, "password_reset:")
 	}
 }
+
+func TestDefaultPINLoginRateLimiterConfig(t *testing.T) {
+	cfg := DefaultPINLoginRateLimiterConfig()
+
+	if cfg.MaxRequests != 20 {
+		t.Errorf("MaxRequests = %d, want 20", cfg.MaxRequests)
+	}
+	if cfg.Window != time.Minute {
+		t.Errorf("Window = %v, want 1 minute", cfg.Window)
+	}
+	if cfg.KeyPrefix != "pin_login:" {
+		t.Errorf("KeyPrefix = %q, want %q", cfg.KeyPrefix, "pin_login:")
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+	"github.com/solobueno/erp/pkg/jwt"
+)
+
+// RoleService handles tenant-defined custom roles.
+type RoleService struct {
+	customRoles    repository.CustomRoleRepository
+	roleRepo       repository.UserTenantRoleRepository
+	eventRepo      repository.AuthEventRepository
+	revocations    repository.TokenRevocationStore
+	accessTokenTTL time.Duration
+}
+
+// RoleServiceConfig holds configuration for RoleService.
+type RoleServiceConfig struct {
+	CustomRoles repository.CustomRoleRepository
+	RoleRepo    repository.UserTenantRoleRepository
+	EventRepo   repository.AuthEventRepository
+	// RevocationStore revokes the access tokens of a custom role's assignees
+	// when its permissions change. If nil, they stay valid until they expire.
+	RevocationStore repository.TokenRevocationStore
+	// AccessTokenTTL is how long revocations are kept. Defaults to the
+	// jwt package's default access token TTL.
+	AccessTokenTTL time.Duration
+}
+
+// NewRoleService creates a new RoleService.
+func NewRoleService(cfg RoleServiceConfig) *RoleService {
+	accessTokenTTL := cfg.AccessTokenTTL
+	if accessTokenTTL == 0 {
+		accessTokenTTL = jwt.DefaultTokenGeneratorConfig().AccessTokenTTL
+	}
+	return &RoleService{
+		customRoles:    cfg.CustomRoles,
+		roleRepo:       cfg.RoleRepo,
+		eventRepo:      cfg.EventRepo,
+		revocations:    cfg.RevocationStore,
+		accessTokenTTL: accessTokenTTL,
+	}
+}
+
+// ListCustomRoles returns a tenant's custom roles, ordered by name.
+func (s *RoleService) ListCustomRoles(ctx context.Context, tenantID uuid.UUID) ([]*domain.CustomRole, error) {
+	roles, err := s.customRoles.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list custom roles: %w", err)
+	}
+	return roles, nil
+}
+
+// GetCustomRole returns one of a tenant's custom roles.
+func (s *RoleService) GetCustomRole(ctx context.Context, tenantID, id uuid.UUID) (*domain.CustomRole, error) {
+	role, err := s.customRoles.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("get custom role: %w", err)
+	}
+	return role, nil
+}
+
+// CreateCustomRoleRequest contains the data for creating a custom role.
+type CreateCustomRoleRequest struct {
+	TenantID    uuid.UUID
+	Name        string
+	BaseRole    domain.Role
+	Permissions []domain.Permission
+	CreatedBy   uuid.UUID
+	IPAddress   string
+}
+
+// CreateCustomRole creates a custom role in a tenant. The caller must be able
+// to assign the base role, and can only grant permissions they hold
+// themselves (callerPerms), so a custom role is never a way to escalate.
+func (s *RoleService) CreateCustomRole(ctx context.Context, req CreateCustomRoleRequest, callerRole domain.Role, callerPerms []domain.Permission) (*domain.CustomRole, error) {
+	if !callerRole.CanAssign(req.BaseRole) {
+		return nil, domain.ErrCannotAssignRole
+	}
+
+	name, err := domain.NormalizeCustomRoleName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	perms, err := checkGrantablePermissions(req.Permissions, callerPerms)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(ctx, req.TenantID, name, uuid.Nil); err != nil {
+		return nil, fmt.Errorf("create custom role: %w", err)
+	}
+
+	role := &domain.CustomRole{
+		TenantID:    req.TenantID,
+		Name:        name,
+		BaseRole:    req.BaseRole,
+		Permissions: perms,
+		CreatedBy:   req.CreatedBy,
+	}
+	if err := s.customRoles.Create(ctx, role); err != nil {
+		return nil, fmt.Errorf("create custom role: save: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventCustomRoleCreated, &req.CreatedBy, &req.TenantID, req.IPAddress, map[string]interface{}{
+		"custom_role_id": role.ID,
+		"name":           role.Name,
+		"base_role":      role.BaseRole,
+		"permissions":    role.Permissions,
+	})
+
+	return role, nil
+}
+
+// UpdateCustomRoleRequest contains the data for updating a custom role. The
+// base role is fixed at creation; nil fields are left unchanged.
+type UpdateCustomRoleRequest struct {
+	TenantID    uuid.UUID
+	ID          uuid.UUID
+	Name        *string
+	Permissions []domain.Permission
+	UpdatedBy   uuid.UUID
+	IPAddress   string
+}
+
+// UpdateCustomRole renames a custom role or replaces its permissions. When
+// the permissions change, the access tokens of everyone holding the role are
+// revoked so they pick up the new set on refresh.
+func (s *RoleService) UpdateCustomRole(ctx context.Context, req UpdateCustomRoleRequest, callerRole domain.Role, callerPerms []domain.Permission) (*domain.CustomRole, error) {
+	role, err := s.customRoles.FindByID(ctx, req.TenantID, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("update custom role: lookup: %w", err)
+	}
+	if !callerRole.CanManage(role.BaseRole) {
+		return nil, domain.ErrCannotManageRole
+	}
+
+	if req.Name != nil {
+		name, err := domain.NormalizeCustomRoleName(*req.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkNameAvailable(ctx, req.TenantID, name, role.ID); err != nil {
+			return nil, fmt.Errorf("update custom role: %w", err)
+		}
+		role.Name = name
+	}
+
+	permissionsChanged := false
+	if req.Permissions != nil {
+		perms, err := checkGrantablePermissions(req.Permissions, callerPerms)
+		if err != nil {
+			return nil, err
+		}
+		permissionsChanged = !slices.Equal(perms, sortedPermissions(role.Permissions))
+		role.Permissions = perms
+	}
+
+	if err := s.customRoles.Update(ctx, role); err != nil {
+		return nil, fmt.Errorf("update custom role: save: %w", err)
+	}
+
+	if permissionsChanged {
+		assignments, err := s.roleRepo.ListByCustomRole(ctx, role.ID)
+		if err != nil {
+			return nil, fmt.Errorf("update custom role: list assignees: %w", err)
+		}
+		for _, a := range assignments {
+			if err := revokeUserAccessTokens(ctx, s.revocations, a.UserID, s.accessTokenTTL); err != nil {
+				return nil, fmt.Errorf("update custom role: revoke access tokens: %w", err)
+			}
+		}
+	}
+
+	s.logEvent(ctx, domain.EventCustomRoleUpdated, &req.UpdatedBy, &req.TenantID, req.IPAddress, map[string]interface{}{
+		"custom_role_id": role.ID,
+		"name":           role.Name,
+		"permissions":    role.Permissions,
+	})
+
+	return role, nil
+}
+
+// DeleteCustomRoleRequest contains the data for deleting a custom role.
+type DeleteCustomRoleRequest struct {
+	TenantID  uuid.UUID
+	ID        uuid.UUID
+	DeletedBy uuid.UUID
+	IPAddress string
+}
+
+// DeleteCustomRole deletes a custom role. A role still assigned to someone
+// can't be deleted; move them to another role first.
+func (s *RoleService) DeleteCustomRole(ctx context.Context, req DeleteCustomRoleRequest, callerRole domain.Role) error {
+	role, err := s.customRoles.FindByID(ctx, req.TenantID, req.ID)
+	if err != nil {
+		return fmt.Errorf("delete custom role: lookup: %w", err)
+	}
+	if !callerRole.CanManage(role.BaseRole) {
+		return domain.ErrCannotManageRole
+	}
+
+	assignments, err := s.roleRepo.ListByCustomRole(ctx, role.ID)
+	if err != nil {
+		return fmt.Errorf("delete custom role: list assignees: %w", err)
+	}
+	if len(assignments) > 0 {
+		return domain.ErrCustomRoleInUse
+	}
+
+	if err := s.customRoles.Delete(ctx, req.TenantID, role.ID); err != nil {
+		return fmt.Errorf("delete custom role: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventCustomRoleDeleted, &req.DeletedBy, &req.TenantID, req.IPAddress, map[string]interface{}{
+		"custom_role_id": role.ID,
+		"name":           role.Name,
+	})
+
+	return nil
+}
+
+// checkNameAvailable returns ErrCustomRoleExists if another of the tenant's
+// custom roles (other than exceptID) already uses the name.
+func (s *RoleService) checkNameAvailable(ctx context.Context, tenantID uuid.UUID, name string, exceptID uuid.UUID) error {
+	existing, err := s.customRoles.FindByName(ctx, tenantID, name)
+	if err != nil {
+		if errors.Is(err, domain.ErrCustomRoleNotFound) {
+			return nil
+		}
+		return fmt.Errorf("lookup name: %w", err)
+	}
+	if existing.ID != exceptID {
+		return domain.ErrCustomRoleExists
+	}
+	return nil
+}
+
+// logEvent logs an authentication event.
+func (s *RoleService) logEvent(ctx context.Context, eventType domain.AuthEventType, userID, tenantID *uuid.UUID, ipAddress string, metadata map[string]interface{}) {
+	event := newAuthEvent(ctx, eventType, userID, tenantID, ipAddress, "")
+	if metadata != nil {
+		event.Metadata = metadata
+	}
+	_ = s.eventRepo.Create(ctx, event)
+}
+
+// checkGrantablePermissions checks every permission is registered and held
+// by the caller, and returns them sorted with duplicates removed.
+func checkGrantablePermissions(perms, callerPerms []domain.Permission) ([]domain.Permission, error) {
+	out := make([]domain.Permission, 0, len(perms))
+	for _, p := range perms {
+		if !p.IsRegistered() {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownPermission, p)
+		}
+		if !slices.Contains(callerPerms, p) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrPermissionNotHeld, p)
+		}
+		out = append(out, p)
+	}
+	return sortedPermissions(out), nil
+}
+
+// sortedPermissions returns a sorted copy of perms with duplicates removed.
+func sortedPermissions(perms []domain.Permission) []domain.Permission {
+	out := slices.Clone(perms)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+)
+
+// roleTestEnv bundles a RoleService with its mock repositories.
+type roleTestEnv struct {
+	svc         *RoleService
+	customRoles *mock.MockCustomRoleRepository
+	roleRepo    *mock.MockUserTenantRoleRepository
+	eventRepo   *mock.MockAuthEventRepository
+	revocations *repository.MemoryTokenRevocationStore
+	tenantID    uuid.UUID
+}
+
+func setupRoleService(t *testing.T) *roleTestEnv {
+	t.Helper()
+
+	env := &roleTestEnv{
+		customRoles: mock.NewMockCustomRoleRepository(),
+		roleRepo:    mock.NewMockUserTenantRoleRepository(),
+		eventRepo:   mock.NewMockAuthEventRepository(),
+		revocations: repository.NewMemoryTokenRevocationStore(time.Minute),
+		tenantID:    uuid.New(),
+	}
+	env.svc = NewRoleService(RoleServiceConfig{
+		CustomRoles:     env.customRoles,
+		RoleRepo:        env.roleRepo,
+		EventRepo:       env.eventRepo,
+		RevocationStore: env.revocations,
+	})
+	return env
+}
+
+// createBartender creates a waiter-based custom role granting users.manage.
+func (e *roleTestEnv) createBartender(t *testing.T) *domain.CustomRole {
+	t.Helper()
+	role, err := e.svc.CreateCustomRole(context.Background(), CreateCustomRoleRequest{
+		TenantID:    e.tenantID,
+		Name:        " Bartender ",
+		BaseRole:    domain.RoleWaiter,
+		Permissions: []domain.Permission{domain.PermUsersManage, domain.PermUsersManage},
+		CreatedBy:   uuid.New(),
+	}, domain.RoleAdmin, domain.RoleAdmin.Permissions())
+	if err != nil {
+		t.Fatalf("CreateCustomRole failed: %v", err)
+	}
+	return role
+}
+
+func TestRoleService_CreateCustomRole(t *testing.T) {
+	env := setupRoleService(t)
+
+	role := env.createBartender(t)
+	if role.Name != "Bartender" {
+		t.Errorf("Name = %q, want trimmed Bartender", role.Name)
+	}
+	if len(role.Permissions) != 1 || role.Permissions[0] != domain.PermUsersManage {
+		t.Errorf("Permissions = %v, want [users.manage] without duplicates", role.Permissions)
+	}
+
+	events := env.eventRepo.GetEvents()
+	if len(events) != 1 || events[0].EventType != domain.EventCustomRoleCreated {
+		t.Errorf("events = %v, want one custom_role_created", events)
+	}
+
+	roles, _ := env.svc.ListCustomRoles(context.Background(), env.tenantID)
+	if len(roles) != 1 {
+		t.Errorf("ListCustomRoles len = %d, want 1", len(roles))
+	}
+}
+
+func TestRoleService_CreateCustomRole_Errors(t *testing.T) {
+	env := setupRoleService(t)
+	env.createBartender(t)
+
+	tests := []struct {
+		name        string
+		req         CreateCustomRoleRequest
+		callerRole  domain.Role
+		callerPerms []domain.Permission
+		wantErr     error
+	}{
+		{
+			name:       "base role not below caller",
+			req:        CreateCustomRoleRequest{Name: "Deputy", BaseRole: domain.RoleAdmin},
+			callerRole: domain.RoleAdmin,
+			wantErr:    domain.ErrCannotAssignRole,
+		},
+		{
+			name:       "owner base role",
+			req:        CreateCustomRoleRequest{Name: "Co-owner", BaseRole: domain.RoleOwner},
+			callerRole: domain.RoleOwner,
+			wantErr:    domain.ErrCannotAssignRole,
+		},
+		{
+			name:       "built-in name",
+			req:        CreateCustomRoleRequest{Name: "cashier", BaseRole: domain.RoleWaiter},
+			callerRole: domain.RoleAdmin,
+			wantErr:    domain.ErrCustomRoleName,
+		},
+		{
+			name:       "duplicate name",
+			req:        CreateCustomRoleRequest{Name: "BARTENDER", BaseRole: domain.RoleWaiter},
+			callerRole: domain.RoleAdmin,
+			wantErr:    domain.ErrCustomRoleExists,
+		},
+		{
+			name:        "unknown permission",
+			req:         CreateCustomRoleRequest{Name: "Host", BaseRole: domain.RoleViewer, Permissions: []domain.Permission{"tables.seat"}},
+			callerRole:  domain.RoleAdmin,
+			callerPerms: []domain.Permission{"tables.seat"},
+			wantErr:     domain.ErrUnknownPermission,
+		},
+		{
+			name:       "permission the caller lacks",
+			req:        CreateCustomRoleRequest{Name: "Host", BaseRole: domain.RoleViewer, Permissions: []domain.Permission{domain.PermTerminalsManage}},
+			callerRole: domain.RoleAdmin,
+			wantErr:    domain.ErrPermissionNotHeld,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.TenantID = env.tenantID
+			_, err := env.svc.CreateCustomRole(context.Background(), tt.req, tt.callerRole, tt.callerPerms)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateCustomRole error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRoleService_UpdateCustomRole(t *testing.T) {
+	env := setupRoleService(t)
+	ctx := context.Background()
+	role := env.createBartender(t)
+
+	bartenderID := uuid.New()
+	env.roleRepo.AddRole(&domain.UserTenantRole{
+		ID: uuid.New(), UserID: bartenderID, TenantID: env.tenantID,
+		Role: domain.RoleWaiter, CustomRoleID: &role.ID, CustomRole: role,
+	})
+
+	// Renaming alone doesn't touch anyone's tokens
+	name := "Head bartender"
+	updated, err := env.svc.UpdateCustomRole(ctx, UpdateCustomRoleRequest{
+		TenantID: env.tenantID, ID: role.ID, Name: &name,
+	}, domain.RoleAdmin, domain.RoleAdmin.Permissions())
+	if err != nil {
+		t.Fatalf("UpdateCustomRole failed: %v", err)
+	}
+	if updated.Name != name {
+		t.Errorf("Name = %q, want %q", updated.Name, name)
+	}
+	if revoked, _ := env.revocations.IsRevoked(ctx, "jti", bartenderID, time.Now().Add(-time.Second)); revoked {
+		t.Error("renaming should not revoke assignees' tokens")
+	}
+
+	updated, err = env.svc.UpdateCustomRole(ctx, UpdateCustomRoleRequest{
+		TenantID: env.tenantID, ID: role.ID, Permissions: []domain.Permission{domain.PermTerminalsManage},
+	}, domain.RoleOwner, domain.RoleOwner.Permissions())
+	if err != nil {
+		t.Fatalf("UpdateCustomRole failed: %v", err)
+	}
+	if len(updated.Permissions) != 1 || updated.Permissions[0] != domain.PermTerminalsManage {
+		t.Errorf("Permissions = %v, want [terminals.manage]", updated.Permissions)
+	}
+	if revoked, _ := env.revocations.IsRevoked(ctx, "jti", bartenderID, time.Now().Add(-time.Second)); !revoked {
+		t.Error("changing permissions should revoke assignees' tokens")
+	}
+}
+
+func TestRoleService_UpdateCustomRole_Errors(t *testing.T) {
+	env := setupRoleService(t)
+	ctx := context.Background()
+	role := env.createBartender(t)
+
+	if _, err := env.svc.UpdateCustomRole(ctx, UpdateCustomRoleRequest{TenantID: uuid.New(), ID: role.ID}, domain.RoleAdmin, nil); !errors.Is(err, domain.ErrCustomRoleNotFound) {
+		t.Errorf("other tenant error = %v, want ErrCustomRoleNotFound", err)
+	}
+	if _, err := env.svc.UpdateCustomRole(ctx, UpdateCustomRoleRequest{TenantID: env.tenantID, ID: role.ID}, domain.RoleWaiter, nil); !errors.Is(err, domain.ErrCannotManageRole) {
+		t.Errorf("caller at base role error = %v, want ErrCannotManageRole", err)
+	}
+	perms := []domain.Permission{domain.PermTerminalsManage}
+	if _, err := env.svc.UpdateCustomRole(ctx, UpdateCustomRoleRequest{TenantID: env.tenantID, ID: role.ID, Permissions: perms}, domain.RoleAdmin, nil); !errors.Is(err, domain.ErrPermissionNotHeld) {
+		t.Errorf("ungranted permission error = %v, want ErrPermissionNotHeld", err)
+	}
+}
+
+func TestRoleService_DeleteCustomRole(t *testing.T) {
+	env := setupRoleService(t)
+	ctx := context.Background()
+	role := env.createBartender(t)
+
+	assignment := &domain.UserTenantRole{
+		ID: uuid.New(), UserID: uuid.New(), TenantID: env.tenantID,
+		Role: domain.RoleWaiter, CustomRoleID: &role.ID, CustomRole: role,
+	}
+	env.roleRepo.AddRole(assignment)
+
+	req := DeleteCustomRoleRequest{TenantID: env.tenantID, ID: role.ID}
+	if err := env.svc.DeleteCustomRole(ctx, req, domain.RoleAdmin); !errors.Is(err, domain.ErrCustomRoleInUse) {
+		t.Fatalf("DeleteCustomRole while assigned error = %v, want ErrCustomRoleInUse", err)
+	}
+
+	env.roleRepo.Delete(ctx, assignment.ID)
+	if err := env.svc.DeleteCustomRole(ctx, req, domain.RoleAdmin); err != nil {
+		t.Fatalf("DeleteCustomRole failed: %v", err)
+	}
+	if _, err := env.svc.GetCustomRole(ctx, env.tenantID, role.ID); !errors.Is(err, domain.ErrCustomRoleNotFound) {
+		t.Errorf("GetCustomRole after delete error = %v, want ErrCustomRoleNotFound", err)
+	}
+}
@@ -0,0 +1,826 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+	"github.com/solobueno/erp/pkg/scim"
+)
+
+// SCIM list paging.
+const (
+	DefaultSCIMCount = 100 // Resources per page when the client doesn't ask
+	MaxSCIMCount     = 200 // Most resources returned in one page
+
+	scimLoadPageSize = 100 // Users loaded at a time when listing a tenant
+)
+
+// SCIMService provisions a tenant's staff for its HR system or identity
+// provider over SCIM 2.0. Users are the tenant's members (and those
+// deprovisioned from it over SCIM, shown inactive); groups are its roles,
+// built-in and custom, with a member's standing role as their group.
+//
+// A SCIM client acts with domain.SCIMRole: it manages staff below admin and
+// can't change admins or owners. Changes are made through UserService with
+// the same checks and audit trail as the users API, attributed to the admin
+// who created the token.
+type SCIMService struct {
+	tokens      repository.SCIMTokenRepository
+	links       repository.SCIMUserLinkRepository
+	userRepo    repository.UserRepository
+	roleRepo    repository.UserTenantRoleRepository
+	customRoles repository.CustomRoleRepository
+	eventRepo   repository.AuthEventRepository
+	userService *UserService
+	passwordSvc *PasswordService
+}
+
+// SCIMServiceConfig holds configuration for SCIMService.
+type SCIMServiceConfig struct {
+	Tokens    repository.SCIMTokenRepository
+	Links     repository.SCIMUserLinkRepository
+	UserRepo  repository.UserRepository
+	RoleRepo  repository.UserTenantRoleRepository
+	EventRepo repository.AuthEventRepository
+	// UserService applies role changes and deprovisioning.
+	UserService *UserService
+	// CustomRoles lists the tenant's custom roles as groups. If nil, only
+	// the built-in roles are.
+	CustomRoles repository.CustomRoleRepository
+	// Passwords hashes and verifies passwords. Defaults to
+	// NewPasswordService(), without a pepper.
+	Passwords *PasswordService
+}
+
+// NewSCIMService creates a new SCIMService.
+func NewSCIMService(cfg SCIMServiceConfig) *SCIMService {
+	passwordSvc := cfg.Passwords
+	if passwordSvc == nil {
+		passwordSvc = NewPasswordService()
+	}
+	return &SCIMService{
+		tokens:      cfg.Tokens,
+		links:       cfg.Links,
+		userRepo:    cfg.UserRepo,
+		roleRepo:    cfg.RoleRepo,
+		customRoles: cfg.CustomRoles,
+		eventRepo:   cfg.EventRepo,
+		userService: cfg.UserService,
+		passwordSvc: passwordSvc,
+	}
+}
+
+// CreateToken creates a bearer token for a tenant's SCIM client. The plain
+// token is returned only here; just its hash is stored.
+func (s *SCIMService) CreateToken(ctx context.Context, tenantID, createdBy uuid.UUID, name, ipAddress string) (*domain.SCIMToken, string, error) {
+	plainToken, tokenHash, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("create scim token: generate token: %w", err)
+	}
+
+	token := &domain.SCIMToken{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Name:      name,
+		TokenHash: tokenHash,
+		CreatedBy: createdBy,
+	}
+	if err := s.tokens.Create(ctx, token); err != nil {
+		return nil, "", fmt.Errorf("create scim token: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventSCIMTokenCreated, &createdBy, &tenantID, ipAddress, map[string]interface{}{
+		"scim_token_id": token.ID.String(),
+		"name":          name,
+	})
+
+	return token, plainToken, nil
+}
+
+// ListTokens returns a tenant's active SCIM tokens.
+func (s *SCIMService) ListTokens(ctx context.Context, tenantID uuid.UUID) ([]*domain.SCIMToken, error) {
+	tokens, err := s.tokens.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list scim tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken revokes one of a tenant's SCIM tokens. Tokens belonging to
+// another tenant are reported as not found.
+func (s *SCIMService) RevokeToken(ctx context.Context, tenantID, tokenID, revokedBy uuid.UUID, ipAddress string) error {
+	token, err := s.tokens.FindByID(ctx, tokenID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSCIMTokenNotFound) {
+			return err
+		}
+		return fmt.Errorf("revoke scim token: lookup: %w", err)
+	}
+	if token.TenantID != tenantID || token.IsRevoked() {
+		return domain.ErrSCIMTokenNotFound
+	}
+
+	if err := s.tokens.Revoke(ctx, tokenID); err != nil {
+		if errors.Is(err, domain.ErrSCIMTokenNotFound) {
+			return err
+		}
+		return fmt.Errorf("revoke scim token: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventSCIMTokenRevoked, &revokedBy, &tenantID, ipAddress, map[string]interface{}{
+		"scim_token_id": tokenID.String(),
+		"name":          token.Name,
+	})
+
+	return nil
+}
+
+// AuthenticateToken looks up a SCIM token by its plain value and records its
+// use.
+func (s *SCIMService) AuthenticateToken(ctx context.Context, plainToken string) (*domain.SCIMToken, error) {
+	if plainToken == "" {
+		return nil, domain.ErrSCIMTokenInvalid
+	}
+	token, err := s.tokens.FindByToken(ctx, s.passwordSvc.HashResetToken(plainToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrSCIMTokenNotFound) {
+			return nil, domain.ErrSCIMTokenInvalid
+		}
+		return nil, fmt.Errorf("authenticate scim token: %w", err)
+	}
+	if token.IsRevoked() {
+		return nil, domain.ErrSCIMTokenInvalid
+	}
+
+	// Best effort: the timestamp only helps admins spot unused tokens
+	_ = s.tokens.TouchLastUsed(ctx, token.ID, time.Now())
+
+	return token, nil
+}
+
+// SCIMListRequest contains the query of a SCIM list request.
+type SCIMListRequest struct {
+	Filter     string
+	StartIndex int // 1-based
+	Count      int // Capped at MaxSCIMCount
+	// ExcludeMembers leaves group members out of the response.
+	ExcludeMembers bool
+}
+
+// scimMember is a user visible to a tenant's SCIM client. Role is nil for a
+// user deprovisioned over SCIM.
+type scimMember struct {
+	user *domain.User
+	role *domain.UserTenantRole
+	link *domain.SCIMUserLink
+}
+
+// ListUsers returns the tenant's users matching the request's filter,
+// ordered by userName.
+func (s *SCIMService) ListUsers(ctx context.Context, token *domain.SCIMToken, req SCIMListRequest) (*scim.ListResponse, error) {
+	filter, err := parseSCIMFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.listMembers(ctx, token.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("scim list users: %w", err)
+	}
+
+	var resources []interface{}
+	for _, m := range members {
+		user := toSCIMUser(m)
+		if filter != nil {
+			resource, err := scim.ToMap(user)
+			if err != nil {
+				return nil, fmt.Errorf("scim list users: %w", err)
+			}
+			if !filter.Matches(resource) {
+				continue
+			}
+		}
+		resources = append(resources, user)
+	}
+	return scim.NewListResponse(resources, req.StartIndex, listCount(req.Count)), nil
+}
+
+// GetUser returns one of the tenant's users.
+func (s *SCIMService) GetUser(ctx context.Context, token *domain.SCIMToken, id string) (*scim.User, error) {
+	member, err := s.findMember(ctx, token.TenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toSCIMUser(member), nil
+}
+
+// CreateUser provisions a user into the tenant as a viewer; their role is
+// set by adding them to a group. A new account gets a random password it
+// must reset, so its owner signs in through SSO or a password reset, and is
+// mailed a verification link since resets only go to verified addresses.
+//
+// An existing account is only added if it was provisioned into the tenant
+// before (a rehire); anyone else has to be invited, since joining them to
+// the tenant needs their consent.
+func (s *SCIMService) CreateUser(ctx context.Context, token *domain.SCIMToken, req *scim.User, ipAddress string) (*scim.User, error) {
+	email := strings.TrimSpace(req.UserName)
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return nil, scim.BadRequest(scim.ErrorInvalidValue, "userName must be an email address")
+	}
+	if !req.IsActive() {
+		return nil, scim.BadRequest(scim.ErrorInvalidValue, "users can only be provisioned active")
+	}
+
+	existing, err := s.userRepo.FindByEmailWithTenants(ctx, email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, fmt.Errorf("scim create user: existing-user lookup: %w", err)
+	}
+
+	user := existing
+	if existing != nil {
+		if existing.HasTenant(token.TenantID) {
+			return nil, scim.NewError(http.StatusConflict, scim.ErrorUniqueness, "userName is already a member of the tenant")
+		}
+		if _, err := s.links.FindByUser(ctx, token.TenantID, existing.ID); err != nil {
+			if errors.Is(err, domain.ErrSCIMLinkNotFound) {
+				return nil, scim.NewError(http.StatusConflict, scim.ErrorUniqueness, "userName belongs to an existing account, invite them to the tenant instead")
+			}
+			return nil, fmt.Errorf("scim create user: link lookup: %w", err)
+		}
+	} else {
+		password, _, err := s.passwordSvc.GenerateResetToken()
+		if err != nil {
+			return nil, fmt.Errorf("scim create user: generate password: %w", err)
+		}
+		passwordHash, err := s.passwordSvc.Hash(password)
+		if err != nil {
+			return nil, fmt.Errorf("scim create user: hash password: %w", err)
+		}
+		passwordChangedAt := time.Now()
+		user = &domain.User{
+			ID:                uuid.New(),
+			Email:             email,
+			PasswordHash:      passwordHash,
+			PasswordChangedAt: &passwordChangedAt,
+			IsActive:          true,
+			MustResetPwd:      true,
+		}
+		setSCIMName(user, req)
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return nil, fmt.Errorf("scim create user: insert user: %w", err)
+		}
+		if err := s.userService.sendEmailVerification(ctx, user, ipAddress); err != nil {
+			return nil, fmt.Errorf("scim create user: %w", err)
+		}
+	}
+
+	role, err := s.addToTenant(ctx, token, user, existing == nil, ipAddress)
+	if err != nil {
+		return nil, fmt.Errorf("scim create user: %w", err)
+	}
+
+	link := &domain.SCIMUserLink{TenantID: token.TenantID, UserID: user.ID, ExternalID: req.ExternalID}
+	if err := s.links.Upsert(ctx, link); err != nil {
+		return nil, fmt.Errorf("scim create user: save link: %w", err)
+	}
+
+	return toSCIMUser(&scimMember{user: user, role: role, link: link}), nil
+}
+
+// ReplaceUser updates one of the tenant's users to match req (PUT). The
+// userName can't change. Setting active to false deprovisions the user
+// from the tenant; setting it back to true provisions them again.
+func (s *SCIMService) ReplaceUser(ctx context.Context, token *domain.SCIMToken, id string, req *scim.User, ipAddress string) (*scim.User, error) {
+	member, err := s.findMember(ctx, token.TenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyUser(ctx, token, member, req, ipAddress); err != nil {
+		return nil, err
+	}
+	return toSCIMUser(member), nil
+}
+
+// PatchUser applies PATCH operations to one of the tenant's users, with
+// the same rules as ReplaceUser.
+func (s *SCIMService) PatchUser(ctx context.Context, token *domain.SCIMToken, id string, ops []scim.PatchOperation, ipAddress string) (*scim.User, error) {
+	member, err := s.findMember(ctx, token.TenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := scim.ToMap(toSCIMUser(member))
+	if err != nil {
+		return nil, fmt.Errorf("scim patch user: %w", err)
+	}
+	if err := scim.ApplyPatch(resource, ops); err != nil {
+		return nil, err
+	}
+	var desired scim.User
+	if err := scim.FromMap(resource, &desired); err != nil {
+		return nil, err
+	}
+
+	if err := s.applyUser(ctx, token, member, &desired, ipAddress); err != nil {
+		return nil, err
+	}
+	return toSCIMUser(member), nil
+}
+
+// DeleteUser deprovisions one of the tenant's users. The account itself is
+// kept, as are its other tenants, and it stays visible as inactive so the
+// client can provision it again.
+func (s *SCIMService) DeleteUser(ctx context.Context, token *domain.SCIMToken, id, ipAddress string) error {
+	member, err := s.findMember(ctx, token.TenantID, id)
+	if err != nil {
+		return err
+	}
+	if member.role == nil {
+		return nil
+	}
+	if err := s.deprovision(ctx, token, member, ipAddress); err != nil {
+		return fmt.Errorf("scim delete user: %w", err)
+	}
+	return nil
+}
+
+// applyUser updates a member to match the desired resource.
+func (s *SCIMService) applyUser(ctx context.Context, token *domain.SCIMToken, member *scimMember, desired *scim.User, ipAddress string) error {
+	if member.role != nil && !domain.SCIMRole.CanManage(member.role.EffectiveRole()) {
+		return domain.ErrCannotManageRole
+	}
+	if !strings.EqualFold(strings.TrimSpace(desired.UserName), member.user.Email) {
+		return scim.BadRequest(scim.ErrorMutability, "userName can't be changed")
+	}
+
+	user := member.user
+	firstName, lastName := user.FirstName, user.LastName
+	setSCIMName(user, desired)
+	if user.FirstName != firstName || user.LastName != lastName {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("scim update user: save: %w", err)
+		}
+	}
+
+	externalID := ""
+	if member.link != nil {
+		externalID = member.link.ExternalID
+	}
+	if desired.ExternalID != externalID {
+		link := &domain.SCIMUserLink{TenantID: token.TenantID, UserID: user.ID, ExternalID: desired.ExternalID}
+		if err := s.links.Upsert(ctx, link); err != nil {
+			return fmt.Errorf("scim update user: save link: %w", err)
+		}
+		member.link = link
+	}
+
+	switch {
+	case member.role != nil && !desired.IsActive():
+		if err := s.deprovision(ctx, token, member, ipAddress); err != nil {
+			return fmt.Errorf("scim update user: %w", err)
+		}
+	case member.role == nil && desired.IsActive():
+		role, err := s.addToTenant(ctx, token, user, false, ipAddress)
+		if err != nil {
+			return fmt.Errorf("scim update user: %w", err)
+		}
+		member.role = role
+	}
+	return nil
+}
+
+// addToTenant gives a user the viewer role in the token's tenant.
+func (s *SCIMService) addToTenant(ctx context.Context, token *domain.SCIMToken, user *domain.User, newAccount bool, ipAddress string) (*domain.UserTenantRole, error) {
+	role := &domain.UserTenantRole{
+		ID:       uuid.New(),
+		UserID:   user.ID,
+		TenantID: token.TenantID,
+		Role:     domain.RoleViewer,
+	}
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		return nil, fmt.Errorf("insert role: %w", err)
+	}
+
+	eventType := domain.EventTenantRoleAdded
+	if newAccount {
+		eventType = domain.EventAccountCreated
+	}
+	s.logEvent(ctx, eventType, &user.ID, &token.TenantID, ipAddress, map[string]interface{}{
+		"created_by":    token.CreatedBy,
+		"role":          role.Role,
+		"scim_token_id": token.ID.String(),
+	})
+	return role, nil
+}
+
+// deprovision removes a member from the tenant and revokes their sessions.
+func (s *SCIMService) deprovision(ctx context.Context, token *domain.SCIMToken, member *scimMember, ipAddress string) error {
+	if !domain.SCIMRole.CanManage(member.role.EffectiveRole()) {
+		return domain.ErrCannotManageRole
+	}
+	err := s.userService.RemoveFromTenant(ctx, RemoveFromTenantRequest{
+		UserID:    member.user.ID,
+		TenantID:  token.TenantID,
+		RemovedBy: token.CreatedBy,
+		IPAddress: ipAddress,
+	}, domain.SCIMRole)
+	if err != nil {
+		return err
+	}
+
+	// Keep the link, so the client can provision the user again
+	if member.link == nil {
+		member.link = &domain.SCIMUserLink{TenantID: token.TenantID, UserID: member.user.ID}
+		if err := s.links.Upsert(ctx, member.link); err != nil {
+			return fmt.Errorf("save link: %w", err)
+		}
+	}
+	member.role = nil
+	return nil
+}
+
+// scimGroup is one of a tenant's roles as a SCIM group.
+type scimGroup struct {
+	id         string
+	name       string
+	role       domain.Role
+	customRole *domain.CustomRole
+}
+
+// has reports whether a role assignment's standing role is the group.
+func (g *scimGroup) has(role *domain.UserTenantRole) bool {
+	if g.customRole != nil {
+		return role.CustomRoleID != nil && *role.CustomRoleID == g.customRole.ID
+	}
+	return role.CustomRoleID == nil && role.Role == g.role
+}
+
+// ListGroups returns the tenant's roles matching the request's filter: the
+// built-in roles, then its custom roles.
+func (s *SCIMService) ListGroups(ctx context.Context, token *domain.SCIMToken, req SCIMListRequest) (*scim.ListResponse, error) {
+	filter, err := parseSCIMFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.listGroups(ctx, token.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("scim list groups: %w", err)
+	}
+	var members []*scimMember
+	if !req.ExcludeMembers || filter != nil {
+		if members, err = s.listMembers(ctx, token.TenantID); err != nil {
+			return nil, fmt.Errorf("scim list groups: %w", err)
+		}
+	}
+
+	var resources []interface{}
+	for _, g := range groups {
+		group := toSCIMGroup(g, members)
+		if filter != nil {
+			resource, err := scim.ToMap(group)
+			if err != nil {
+				return nil, fmt.Errorf("scim list groups: %w", err)
+			}
+			if !filter.Matches(resource) {
+				continue
+			}
+		}
+		if req.ExcludeMembers {
+			group.Members = nil
+		}
+		resources = append(resources, group)
+	}
+	return scim.NewListResponse(resources, req.StartIndex, listCount(req.Count)), nil
+}
+
+// GetGroup returns one of the tenant's roles.
+func (s *SCIMService) GetGroup(ctx context.Context, token *domain.SCIMToken, id string, excludeMembers bool) (*scim.Group, error) {
+	g, err := s.findGroup(ctx, token.TenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if excludeMembers {
+		return toSCIMGroup(g, nil), nil
+	}
+	members, err := s.listMembers(ctx, token.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("scim get group: %w", err)
+	}
+	return toSCIMGroup(g, members), nil
+}
+
+// ReplaceGroup sets a role's members to those of req (PUT). Members added
+// are given the role; members removed drop to viewer. The group's name
+// can't change.
+func (s *SCIMService) ReplaceGroup(ctx context.Context, token *domain.SCIMToken, id string, req *scim.Group, ipAddress string) (*scim.Group, error) {
+	return s.updateGroup(ctx, token, id, ipAddress, func(*scim.Group) (*scim.Group, error) {
+		return req, nil
+	})
+}
+
+// PatchGroup applies PATCH operations to a role's members, with the same
+// rules as ReplaceGroup.
+func (s *SCIMService) PatchGroup(ctx context.Context, token *domain.SCIMToken, id string, ops []scim.PatchOperation, ipAddress string) (*scim.Group, error) {
+	return s.updateGroup(ctx, token, id, ipAddress, func(current *scim.Group) (*scim.Group, error) {
+		resource, err := scim.ToMap(current)
+		if err != nil {
+			return nil, err
+		}
+		if err := scim.ApplyPatch(resource, ops); err != nil {
+			return nil, err
+		}
+		var desired scim.Group
+		if err := scim.FromMap(resource, &desired); err != nil {
+			return nil, err
+		}
+		return &desired, nil
+	})
+}
+
+// updateGroup changes a role's members to those of the group desired
+// returns for its current state.
+func (s *SCIMService) updateGroup(ctx context.Context, token *domain.SCIMToken, id, ipAddress string, desired func(*scim.Group) (*scim.Group, error)) (*scim.Group, error) {
+	g, err := s.findGroup(ctx, token.TenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.listMembers(ctx, token.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("scim update group: %w", err)
+	}
+
+	current := toSCIMGroup(g, members)
+	want, err := desired(current)
+	if err != nil {
+		return nil, err
+	}
+	if want.DisplayName != "" && !strings.EqualFold(strings.TrimSpace(want.DisplayName), g.name) {
+		return nil, scim.BadRequest(scim.ErrorMutability, "displayName can't be changed")
+	}
+
+	byID := make(map[string]*scimMember, len(members))
+	for _, m := range members {
+		if m.role != nil {
+			byID[m.user.ID.String()] = m
+		}
+	}
+	isMember := func(group *scim.Group, id string) bool {
+		return slices.ContainsFunc(group.Members, func(v scim.MultiValue) bool { return strings.EqualFold(v.Value, id) })
+	}
+
+	// Check every change before making any
+	var added, removed []*scimMember
+	for _, v := range want.Members {
+		m, ok := byID[strings.ToLower(v.Value)]
+		if !ok {
+			return nil, scim.BadRequest(scim.ErrorInvalidValue, "%s is not a member of the tenant", v.Value)
+		}
+		if !g.has(m.role) && !slices.Contains(added, m) {
+			added = append(added, m)
+		}
+	}
+	for _, v := range current.Members {
+		if !isMember(want, v.Value) {
+			removed = append(removed, byID[v.Value])
+		}
+	}
+	if len(added) > 0 && !domain.SCIMRole.CanAssign(g.role) {
+		return nil, domain.ErrCannotAssignRole
+	}
+	for _, m := range slices.Concat(added, removed) {
+		if !domain.SCIMRole.CanManage(m.role.EffectiveRole()) {
+			return nil, domain.ErrCannotManageRole
+		}
+	}
+
+	for _, m := range added {
+		req := UpdateRoleRequest{UserID: m.user.ID, TenantID: token.TenantID, NewRole: g.role, UpdatedBy: token.CreatedBy, IPAddress: ipAddress}
+		if g.customRole != nil {
+			req.CustomRoleID = &g.customRole.ID
+		}
+		if err := s.userService.UpdateRole(ctx, req, domain.SCIMRole); err != nil {
+			return nil, fmt.Errorf("scim update group: add member: %w", err)
+		}
+	}
+	if g.role != domain.RoleViewer || g.customRole != nil {
+		for _, m := range removed {
+			req := UpdateRoleRequest{UserID: m.user.ID, TenantID: token.TenantID, NewRole: domain.RoleViewer, UpdatedBy: token.CreatedBy, IPAddress: ipAddress}
+			if err := s.userService.UpdateRole(ctx, req, domain.SCIMRole); err != nil {
+				return nil, fmt.Errorf("scim update group: remove member: %w", err)
+			}
+		}
+	}
+
+	return s.GetGroup(ctx, token, id, false)
+}
+
+// listMembers returns the users visible to the tenant's SCIM client,
+// ordered by email: its members, and the users deprovisioned from it.
+func (s *SCIMService) listMembers(ctx context.Context, tenantID uuid.UUID) ([]*scimMember, error) {
+	links, err := s.links.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list links: %w", err)
+	}
+	linkByUser := make(map[uuid.UUID]*domain.SCIMUserLink, len(links))
+	for _, l := range links {
+		linkByUser[l.UserID] = l
+	}
+
+	var members []*scimMember
+	seen := make(map[uuid.UUID]bool)
+	for offset := 0; ; offset += scimLoadPageSize {
+		users, total, err := s.userRepo.ListByTenant(ctx, tenantID, offset, scimLoadPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("list users: %w", err)
+		}
+		for _, u := range users {
+			if role := u.GetTenantRole(tenantID); role != nil && !seen[u.ID] {
+				seen[u.ID] = true
+				members = append(members, &scimMember{user: u, role: role, link: linkByUser[u.ID]})
+			}
+		}
+		if len(users) == 0 || int64(offset+len(users)) >= total {
+			break
+		}
+	}
+
+	for _, l := range links {
+		if seen[l.UserID] {
+			continue
+		}
+		user, err := s.userRepo.FindByID(ctx, l.UserID)
+		if err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("deprovisioned user lookup: %w", err)
+		}
+		members = append(members, &scimMember{user: user, link: l})
+	}
+
+	slices.SortFunc(members, func(a, b *scimMember) int { return strings.Compare(a.user.Email, b.user.Email) })
+	return members, nil
+}
+
+// findMember looks up a user visible to the tenant's SCIM client. Anyone
+// else is reported as not found.
+func (s *SCIMService) findMember(ctx context.Context, tenantID uuid.UUID, id string) (*scimMember, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	role, err := s.roleRepo.FindByUserAndTenant(ctx, userID, tenantID)
+	if err != nil && !errors.Is(err, domain.ErrUserNotInTenant) {
+		return nil, fmt.Errorf("scim user lookup: role: %w", err)
+	}
+	link, err := s.links.FindByUser(ctx, tenantID, userID)
+	if err != nil && !errors.Is(err, domain.ErrSCIMLinkNotFound) {
+		return nil, fmt.Errorf("scim user lookup: link: %w", err)
+	}
+	if role == nil && link == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scim user lookup: %w", err)
+	}
+	return &scimMember{user: user, role: role, link: link}, nil
+}
+
+// listGroups returns the tenant's built-in and custom roles.
+func (s *SCIMService) listGroups(ctx context.Context, tenantID uuid.UUID) ([]*scimGroup, error) {
+	var groups []*scimGroup
+	for _, r := range domain.AllRoles() {
+		groups = append(groups, &scimGroup{id: string(r), name: string(r), role: r})
+	}
+	if s.customRoles == nil {
+		return groups, nil
+	}
+	customRoles, err := s.customRoles.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list custom roles: %w", err)
+	}
+	for _, c := range customRoles {
+		groups = append(groups, &scimGroup{id: c.ID.String(), name: c.Name, role: c.BaseRole, customRole: c})
+	}
+	return groups, nil
+}
+
+// findGroup looks up one of the tenant's roles by group ID: a built-in
+// role's name or a custom role's ID.
+func (s *SCIMService) findGroup(ctx context.Context, tenantID uuid.UUID, id string) (*scimGroup, error) {
+	if role, err := domain.ParseRole(id); err == nil {
+		return &scimGroup{id: string(role), name: string(role), role: role}, nil
+	}
+	customRoleID, err := uuid.Parse(id)
+	if err != nil || s.customRoles == nil {
+		return nil, domain.ErrCustomRoleNotFound
+	}
+	c, err := s.customRoles.FindByID(ctx, tenantID, customRoleID)
+	if err != nil {
+		if errors.Is(err, domain.ErrCustomRoleNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scim group lookup: %w", err)
+	}
+	return &scimGroup{id: c.ID.String(), name: c.Name, role: c.BaseRole, customRole: c}, nil
+}
+
+// toSCIMUser maps a member to a SCIM user.
+func toSCIMUser(m *scimMember) *scim.User {
+	user := m.user
+	active := scim.Bool(m.role != nil)
+	created, updated := user.CreatedAt, user.UpdatedAt
+	u := &scim.User{
+		Schemas:     []string{scim.UserSchema},
+		ID:          user.ID.String(),
+		UserName:    user.Email,
+		DisplayName: strings.TrimSpace(user.FullName()),
+		Emails:      []scim.MultiValue{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta:        &scim.Meta{ResourceType: "User", Created: &created, LastModified: &updated},
+	}
+	if user.FirstName != "" || user.LastName != "" {
+		u.Name = &scim.Name{Formatted: u.DisplayName, GivenName: user.FirstName, FamilyName: user.LastName}
+	}
+	if m.link != nil {
+		u.ExternalID = m.link.ExternalID
+	}
+	if m.role != nil {
+		id := string(m.role.Role)
+		if m.role.CustomRoleID != nil {
+			id = m.role.CustomRoleID.String()
+		}
+		u.Groups = []scim.MultiValue{{Value: id, Display: m.role.StandingRoleName()}}
+	}
+	return u
+}
+
+// toSCIMGroup maps a role to a SCIM group with its members among members.
+func toSCIMGroup(g *scimGroup, members []*scimMember) *scim.Group {
+	group := &scim.Group{
+		Schemas:     []string{scim.GroupSchema},
+		ID:          g.id,
+		DisplayName: g.name,
+		Meta:        &scim.Meta{ResourceType: "Group"},
+	}
+	if g.customRole != nil {
+		created, updated := g.customRole.CreatedAt, g.customRole.UpdatedAt
+		group.Meta.Created, group.Meta.LastModified = &created, &updated
+	}
+	for _, m := range members {
+		if m.role != nil && g.has(m.role) {
+			group.Members = append(group.Members, scim.MultiValue{Value: m.user.ID.String(), Display: strings.TrimSpace(m.user.FullName())})
+		}
+	}
+	return group
+}
+
+// setSCIMName sets a user's name from a SCIM user, falling back to its
+// displayName if it has no name components.
+func setSCIMName(user *domain.User, req *scim.User) {
+	switch {
+	case req.Name != nil && (req.Name.GivenName != "" || req.Name.FamilyName != ""):
+		user.FirstName, user.LastName = req.Name.GivenName, req.Name.FamilyName
+	case req.DisplayName != "" && user.FirstName == "" && user.LastName == "":
+		user.FirstName = req.DisplayName
+	}
+}
+
+// parseSCIMFilter parses a list request's filter, if any.
+func parseSCIMFilter(filter string) (scim.Filter, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+	return scim.ParseFilter(filter)
+}
+
+// listCount caps a list request's count.
+func listCount(count int) int {
+	return min(max(count, 0), MaxSCIMCount)
+}
+
+// logEvent logs an authentication event.
+func (s *SCIMService) logEvent(ctx context.Context, eventType domain.AuthEventType, userID, tenantID *uuid.UUID, ipAddress string, metadata map[string]interface{}) {
+	event := newAuthEvent(ctx, eventType, userID, tenantID, ipAddress, "")
+	if metadata != nil {
+		event.Metadata = metadata
+	}
+	// Fire and forget - don't fail the request if logging fails
+	_ = s.eventRepo.Create(ctx, event)
+}
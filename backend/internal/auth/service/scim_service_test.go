@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/pkg/scim"
+)
+
+// scimTestEnv bundles a SCIMService with its mock repositories, a tenant's
+// SCIM token and a waiter and an admin in the tenant.
+type scimTestEnv struct {
+	svc         *SCIMService
+	tokens      *mock.MockSCIMTokenRepository
+	links       *mock.MockSCIMUserLinkRepository
+	userRepo    *mock.MockUserRepository
+	roleRepo    *mock.MockUserTenantRoleRepository
+	sessionRepo *mock.MockSessionRepository
+	customRoles *mock.MockCustomRoleRepository
+	eventRepo   *mock.MockAuthEventRepository
+	revocations *repository.MemoryTokenRevocationStore
+	tenantID    uuid.UUID
+	token       *domain.SCIMToken
+	waiter      *domain.User
+	admin       *domain.User
+}
+
+func setupSCIMService(t *testing.T) *scimTestEnv {
+	t.Helper()
+
+	env := &scimTestEnv{
+		tokens:      mock.NewMockSCIMTokenRepository(),
+		links:       mock.NewMockSCIMUserLinkRepository(),
+		userRepo:    mock.NewMockUserRepository(),
+		roleRepo:    mock.NewMockUserTenantRoleRepository(),
+		sessionRepo: mock.NewMockSessionRepository(),
+		customRoles: mock.NewMockCustomRoleRepository(),
+		eventRepo:   mock.NewMockAuthEventRepository(),
+		revocations: repository.NewMemoryTokenRevocationStore(time.Minute),
+		tenantID:    uuid.New(),
+	}
+	env.userRepo.RolesLookup = func(userID uuid.UUID) []domain.UserTenantRole {
+		ptrs, _ := env.roleRepo.ListByUser(context.Background(), userID)
+		roles := make([]domain.UserTenantRole, len(ptrs))
+		for i, p := range ptrs {
+			roles[i] = *p
+		}
+		return roles
+	}
+
+	userSvc := NewUserService(UserServiceConfig{
+		UserRepo:        env.userRepo,
+		RoleRepo:        env.roleRepo,
+		SessionRepo:     env.sessionRepo,
+		EventRepo:       env.eventRepo,
+		RevocationStore: env.revocations,
+		CustomRoles:     env.customRoles,
+		EmailTokens:     mock.NewMockEmailTokenRepository(),
+	})
+	env.svc = NewSCIMService(SCIMServiceConfig{
+		Tokens:      env.tokens,
+		Links:       env.links,
+		UserRepo:    env.userRepo,
+		RoleRepo:    env.roleRepo,
+		EventRepo:   env.eventRepo,
+		UserService: userSvc,
+		CustomRoles: env.customRoles,
+	})
+
+	env.waiter = env.addMember(t, "luis@casa-ana.com", "Luis", domain.RoleWaiter)
+	env.admin = env.addMember(t, "admin@casa-ana.com", "Ana", domain.RoleAdmin)
+	env.token = &domain.SCIMToken{TenantID: env.tenantID, Name: "Workday", TokenHash: "hash", CreatedBy: env.admin.ID}
+	env.tokens.Create(context.Background(), env.token)
+	return env
+}
+
+// addMember adds an active user with a role in the env's tenant.
+func (e *scimTestEnv) addMember(t *testing.T, email, firstName string, role domain.Role) *domain.User {
+	t.Helper()
+	user := &domain.User{ID: uuid.New(), Email: email, FirstName: firstName, IsActive: true}
+	e.userRepo.AddUser(user)
+	e.roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: user.ID, TenantID: e.tenantID, Role: role})
+	return user
+}
+
+func (e *scimTestEnv) role(userID uuid.UUID) *domain.UserTenantRole {
+	role, err := e.roleRepo.FindByUserAndTenant(context.Background(), userID, e.tenantID)
+	if err != nil {
+		return nil
+	}
+	return role
+}
+
+func patchOp(kind, path, value string) scim.PatchOperation {
+	op := scim.PatchOperation{Op: kind, Path: path}
+	if value != "" {
+		op.Value = json.RawMessage(value)
+	}
+	return op
+}
+
+// wantSCIMError checks err is a SCIM error with the given status and type.
+func wantSCIMError(t *testing.T, err error, status int, scimType string) {
+	t.Helper()
+	var scimErr *scim.Error
+	if !errors.As(err, &scimErr) || scimErr.Status != status || scimErr.ScimType != scimType {
+		t.Fatalf("error = %v, want a %d %s SCIM error", err, status, scimType)
+	}
+}
+
+func TestSCIMService_Tokens(t *testing.T) {
+	env := setupSCIMService(t)
+	ctx := context.Background()
+
+	token, plain, err := env.svc.CreateToken(ctx, env.tenantID, env.admin.ID, "Okta", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	if plain == "" || token.TokenHash == plain {
+		t.Fatal("CreateToken should return the plain token and store only its hash")
+	}
+
+	authed, err := env.svc.AuthenticateToken(ctx, plain)
+	if err != nil || authed.ID != token.ID {
+		t.Fatalf("AuthenticateToken = %v, %v, want the created token", authed, err)
+	}
+	if authed.LastUsedAt == nil {
+		t.Error("AuthenticateToken should record the token's use")
+	}
+	if _, err := env.svc.AuthenticateToken(ctx, "wrong"); !errors.Is(err, domain.ErrSCIMTokenInvalid) {
+		t.Errorf("AuthenticateToken with a wrong token error = %v, want ErrSCIMTokenInvalid", err)
+	}
+
+	if tokens, _ := env.svc.ListTokens(ctx, env.tenantID); len(tokens) != 2 {
+		t.Errorf("ListTokens len = %d, want 2", len(tokens))
+	}
+
+	if err := env.svc.RevokeToken(ctx, uuid.New(), token.ID, env.admin.ID, ""); !errors.Is(err, domain.ErrSCIMTokenNotFound) {
+		t.Errorf("RevokeToken from another tenant error = %v, want ErrSCIMTokenNotFound", err)
+	}
+	if err := env.svc.RevokeToken(ctx, env.tenantID, token.ID, env.admin.ID, ""); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+	if _, err := env.svc.AuthenticateToken(ctx, plain); !errors.Is(err, domain.ErrSCIMTokenInvalid) {
+		t.Errorf("AuthenticateToken after revoke error = %v, want ErrSCIMTokenInvalid", err)
+	}
+
+	var created, revoked int
+	for _, e := range env.eventRepo.GetEvents() {
+		switch e.EventType {
+		case domain.EventSCIMTokenCreated:
+			created++
+		case domain.EventSCIMTokenRevoked:
+			revoked++
+		}
+	}
+	if created != 1 || revoked != 1 {
+		t.Errorf("events: %d created, %d revoked, want 1 of each", created, revoked)
+	}
+}
+
+func TestSCIMService_CreateUser(t *testing.T) {
+	env := setupSCIMService(t)
+	ctx := context.Background()
+
+	user, err := env.svc.CreateUser(ctx, env.token, &scim.User{
+		UserName:   "maria@casa-ana.com",
+		ExternalID: "HR-1042",
+		Name:       &scim.Name{GivenName: "María", FamilyName: "Pérez"},
+	}, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if user.ExternalID != "HR-1042" || !user.IsActive() || user.Name.GivenName != "María" {
+		t.Errorf("created user = %+v", user)
+	}
+	if len(user.Groups) != 1 || user.Groups[0].Value != string(domain.RoleViewer) {
+		t.Errorf("groups = %+v, want viewer", user.Groups)
+	}
+
+	account, _ := env.userRepo.FindByEmail(ctx, "maria@casa-ana.com")
+	if account == nil || !account.MustResetPwd || account.PasswordHash == "" {
+		t.Fatalf("account = %+v, want one with a random password to reset", account)
+	}
+	if role := env.role(account.ID); role == nil || role.Role != domain.RoleViewer {
+		t.Errorf("role = %+v, want viewer", role)
+	}
+	var logged bool
+	for _, e := range env.eventRepo.GetEvents() {
+		if e.EventType == domain.EventAccountCreated && e.Metadata["scim_token_id"] == env.token.ID.String() {
+			logged = true
+		}
+	}
+	if !logged {
+		t.Error("account_created should be logged with the SCIM token")
+	}
+
+	// Members can't be provisioned twice, and other accounts need an invitation
+	_, err = env.svc.CreateUser(ctx, env.token, &scim.User{UserName: "luis@casa-ana.com"}, "")
+	wantSCIMError(t, err, http.StatusConflict, scim.ErrorUniqueness)
+	env.userRepo.AddUser(&domain.User{ID: uuid.New(), Email: "elsewhere@example.com", IsActive: true})
+	_, err = env.svc.CreateUser(ctx, env.token, &scim.User{UserName: "elsewhere@example.com"}, "")
+	wantSCIMError(t, err, http.StatusConflict, scim.ErrorUniqueness)
+
+	_, err = env.svc.CreateUser(ctx, env.token, &scim.User{UserName: "not an email"}, "")
+	wantSCIMError(t, err, http.StatusBadRequest, scim.ErrorInvalidValue)
+	inactive := scim.Bool(false)
+	_, err = env.svc.CreateUser(ctx, env.token, &scim.User{UserName: "new@casa-ana.com", Active: &inactive}, "")
+	wantSCIMError(t, err, http.StatusBadRequest, scim.ErrorInvalidValue)
+}
+
+func TestSCIMService_Deprovision(t *testing.T) {
+	env := setupSCIMService(t)
+	ctx := context.Background()
+
+	session := &domain.Session{UserID: env.waiter.ID, TenantID: env.tenantID, RefreshToken: "r", ExpiresAt: time.Now().Add(time.Hour)}
+	env.sessionRepo.Create(ctx, session)
+	issuedAt := time.Now().Add(-time.Second)
+
+	// Azure AD sends active as a string
+	user, err := env.svc.PatchUser(ctx, env.token, env.waiter.ID.String(), []scim.PatchOperation{
+		patchOp("Replace", "active", `"False"`),
+	}, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("PatchUser failed: %v", err)
+	}
+	if user.IsActive() || len(user.Groups) != 0 {
+		t.Errorf("deprovisioned user = %+v, want inactive without groups", user)
+	}
+	if env.role(env.waiter.ID) != nil {
+		t.Error("deprovisioning should remove the tenant role")
+	}
+	if s, _ := env.sessionRepo.FindByID(ctx, session.ID); !s.IsRevoked() {
+		t.Error("deprovisioning should revoke the user's sessions in the tenant")
+	}
+	if revoked, _ := env.revocations.IsRevoked(ctx, "", env.waiter.ID, issuedAt); !revoked {
+		t.Error("deprovisioning should revoke the user's access tokens")
+	}
+
+	// The user stays visible as inactive, and can be provisioned again
+	got, err := env.svc.GetUser(ctx, env.token, env.waiter.ID.String())
+	if err != nil || got.IsActive() {
+		t.Fatalf("GetUser = %+v, %v, want the inactive user", got, err)
+	}
+	user, err = env.svc.PatchUser(ctx, env.token, env.waiter.ID.String(), []scim.PatchOperation{
+		patchOp("replace", "", `{"active":true}`),
+	}, "")
+	if err != nil || !user.IsActive() {
+		t.Fatalf("reactivating PatchUser = %+v, %v", user, err)
+	}
+	if role := env.role(env.waiter.ID); role == nil || role.Role != domain.RoleViewer {
+		t.Errorf("reactivated role = %+v, want viewer", role)
+	}
+
+	// DELETE deprovisions too, and a rehire can be provisioned by userName
+	if err := env.svc.DeleteUser(ctx, env.token, env.waiter.ID.String(), ""); err != nil {
+		t.Fatalf("DeleteUser failed: %v", err)
+	}
+	if env.role(env.waiter.ID) != nil {
+		t.Error("DeleteUser should remove the tenant role")
+	}
+	if _, err := env.svc.CreateUser(ctx, env.token, &scim.User{UserName: "luis@casa-ana.com"}, ""); err != nil {
+		t.Fatalf("CreateUser for a rehire failed: %v", err)
+	}
+
+	removed := 0
+	for _, e := range env.eventRepo.GetEvents() {
+		if e.EventType == domain.EventTenantRoleRemoved && e.Metadata["removed_by"] == env.admin.ID {
+			removed++
+		}
+	}
+	if removed != 2 {
+		t.Errorf("tenant_role_removed events = %d, want 2 attributed to the token's creator", removed)
+	}
+}
+
+func TestSCIMService_UpdateUser(t *testing.T) {
+	env := setupSCIMService(t)
+	ctx := context.Background()
+	id := env.waiter.ID.String()
+
+	user, err := env.svc.PatchUser(ctx, env.token, id, []scim.PatchOperation{
+		patchOp("replace", "name.familyName", `"García"`),
+		patchOp("add", "externalId", `"HR-7"`),
+	}, "")
+	if err != nil {
+		t.Fatalf("PatchUser failed: %v", err)
+	}
+	if user.Name.FamilyName != "García" || user.Name.GivenName != "Luis" || user.ExternalID != "HR-7" {
+		t.Errorf("patched user = %+v, name %+v", user, user.Name)
+	}
+	if account, _ := env.userRepo.FindByID(ctx, env.waiter.ID); account.LastName != "García" {
+		t.Errorf("LastName = %q, want García", account.LastName)
+	}
+
+	user, err = env.svc.ReplaceUser(ctx, env.token, id, &scim.User{UserName: "LUIS@casa-ana.com", Name: &scim.Name{GivenName: "Luis", FamilyName: "Ruiz"}}, "")
+	if err != nil {
+		t.Fatalf("ReplaceUser failed: %v", err)
+	}
+	if user.Name.FamilyName != "Ruiz" || user.ExternalID != "" {
+		t.Errorf("replaced user = %+v", user)
+	}
+
+	_, err = env.svc.PatchUser(ctx, env.token, id, []scim.PatchOperation{patchOp("replace", "userName", `"other@casa-ana.com"`)}, "")
+	wantSCIMError(t, err, http.StatusBadRequest, scim.ErrorMutability)
+	_, err = env.svc.PatchUser(ctx, env.token, id, []scim.PatchOperation{patchOp("replace", "active", `"maybe"`)}, "")
+	wantSCIMError(t, err, http.StatusBadRequest, scim.ErrorInvalidValue)
+
+	// Admins and owners are out of a SCIM client's reach
+	_, err = env.svc.PatchUser(ctx, env.token, env.admin.ID.String(), []scim.PatchOperation{patchOp("replace", "active", "false")}, "")
+	if !errors.Is(err, domain.ErrCannotManageRole) {
+		t.Errorf("PatchUser on an admin error = %v, want ErrCannotManageRole", err)
+	}
+	if err := env.svc.DeleteUser(ctx, env.token, env.admin.ID.String(), ""); !errors.Is(err, domain.ErrCannotManageRole) {
+		t.Errorf("DeleteUser on an admin error = %v, want ErrCannotManageRole", err)
+	}
+
+	// Users outside the tenant don't exist for it
+	outsider := &domain.User{ID: uuid.New(), Email: "outsider@example.com", IsActive: true}
+	env.userRepo.AddUser(outsider)
+	for _, id := range []string{outsider.ID.String(), uuid.NewString(), "not-a-uuid"} {
+		if _, err := env.svc.GetUser(ctx, env.token, id); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("GetUser(%s) error = %v, want ErrUserNotFound", id, err)
+		}
+	}
+}
+
+func TestSCIMService_ListUsers(t *testing.T) {
+	env := setupSCIMService(t)
+	ctx := context.Background()
+	env.addMember(t, "carla@casa-ana.com", "Carla", domain.RoleCashier)
+	env.userRepo.AddUser(&domain.User{ID: uuid.New(), Email: "outsider@example.com", IsActive: true})
+
+	all, err := env.svc.ListUsers(ctx, env.token, SCIMListRequest{StartIndex: 1, Count: DefaultSCIMCount})
+	if err != nil {
+		t.Fatalf("ListUsers failed: %v", err)
+	}
+	if all.TotalResults != 3 {
+		t.Fatalf("TotalResults = %d, want the tenant's 3 members", all.TotalResults)
+	}
+	if first := all.Resources[0].(*scim.User); first.UserName != "admin@casa-ana.com" {
+		t.Errorf("first user = %s, want users ordered by userName", first.UserName)
+	}
+
+	page, _ := env.svc.ListUsers(ctx, env.token, SCIMListRequest{StartIndex: 2, Count: 1})
+	if page.TotalResults != 3 || page.ItemsPerPage != 1 || page.Resources[0].(*scim.User).UserName != "carla@casa-ana.com" {
+		t.Errorf("page = %+v, want the second user", page)
+	}
+
+	found, err := env.svc.ListUsers(ctx, env.token, SCIMListRequest{Filter: `userName eq "LUIS@casa-ana.com"`, StartIndex: 1, Count: DefaultSCIMCount})
+	if err != nil {
+		t.Fatalf("ListUsers with a filter failed: %v", err)
+	}
+	if found.TotalResults != 1 || found.Resources[0].(*scim.User).ID != env.waiter.ID.String() {
+		t.Errorf("filtered = %+v, want the waiter", found)
+	}
+
+	_, err = env.svc.ListUsers(ctx, env.token, SCIMListRequest{Filter: `userName eq`})
+	wantSCIMError(t, err, http.StatusBadRequest, scim.ErrorInvalidFilter)
+}
+
+func TestSCIMService_Groups(t *testing.T) {
+	env := setupSCIMService(t)
+	ctx := context.Background()
+	bartender := &domain.CustomRole{ID: uuid.New(), TenantID: env.tenantID, Name: "Bartender", BaseRole: domain.RoleWaiter}
+	env.customRoles.AddCustomRole(bartender)
+	waiterID := env.waiter.ID.String()
+
+	groups, err := env.svc.ListGroups(ctx, env.token, SCIMListRequest{StartIndex: 1, Count: DefaultSCIMCount, ExcludeMembers: true})
+	if err != nil {
+		t.Fatalf("ListGroups failed: %v", err)
+	}
+	if groups.TotalResults != len(domain.AllRoles())+1 {
+		t.Errorf("TotalResults = %d, want the built-in roles and Bartender", groups.TotalResults)
+	}
+	filtered, _ := env.svc.ListGroups(ctx, env.token, SCIMListRequest{Filter: `displayName eq "bartender"`, StartIndex: 1, Count: DefaultSCIMCount})
+	if filtered.TotalResults != 1 || filtered.Resources[0].(*scim.Group).ID != bartender.ID.String() {
+		t.Errorf("filtered groups = %+v, want Bartender", filtered)
+	}
+
+	// Adding a member gives them the role
+	group, err := env.svc.PatchGroup(ctx, env.token, "manager", []scim.PatchOperation{
+		patchOp("add", "members", `[{"value":"`+waiterID+`"}]`),
+	}, "")
+	if err != nil {
+		t.Fatalf("PatchGroup add failed: %v", err)
+	}
+	if len(group.Members) != 1 || group.Members[0].Value != waiterID {
+		t.Errorf("manager members = %+v, want the waiter", group.Members)
+	}
+	if role := env.role(env.waiter.ID); role.Role != domain.RoleManager {
+		t.Errorf("role = %s, want manager", role.Role)
+	}
+
+	// Moving to a custom role, then removing them drops them to viewer
+	if _, err := env.svc.ReplaceGroup(ctx, env.token, bartender.ID.String(), &scim.Group{
+		DisplayName: "Bartender",
+		Members:     []scim.MultiValue{{Value: waiterID}},
+	}, ""); err != nil {
+		t.Fatalf("ReplaceGroup failed: %v", err)
+	}
+	if role := env.role(env.waiter.ID); role.CustomRoleID == nil || *role.CustomRoleID != bartender.ID {
+		t.Errorf("role = %+v, want Bartender", role)
+	}
+	if manager, _ := env.svc.GetGroup(ctx, env.token, "manager", false); len(manager.Members) != 0 {
+		t.Errorf("manager members = %+v, want none after the move", manager.Members)
+	}
+	if _, err := env.svc.PatchGroup(ctx, env.token, bartender.ID.String(), []scim.PatchOperation{
+		patchOp("remove", `members[value eq "`+waiterID+`"]`, ""),
+	}, ""); err != nil {
+		t.Fatalf("PatchGroup remove failed: %v", err)
+	}
+	if role := env.role(env.waiter.ID); role.Role != domain.RoleViewer || role.CustomRoleID != nil {
+		t.Errorf("role = %+v, want viewer", role)
+	}
+
+	tests := []struct {
+		name    string
+		group   string
+		ops     []scim.PatchOperation
+		wantErr error
+	}{
+		{"admin can't be assigned", "admin", []scim.PatchOperation{patchOp("add", "members", `[{"value":"`+waiterID+`"}]`)}, domain.ErrCannotAssignRole},
+		{"admins can't be moved", "viewer", []scim.PatchOperation{patchOp("add", "members", `[{"value":"`+env.admin.ID.String()+`"}]`)}, domain.ErrCannotManageRole},
+		{"admins can't be removed", "admin", []scim.PatchOperation{patchOp("remove", "members", "")}, domain.ErrCannotManageRole},
+		{"unknown group", "sommelier", []scim.PatchOperation{patchOp("add", "members", `[]`)}, domain.ErrCustomRoleNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := env.svc.PatchGroup(ctx, env.token, tt.group, tt.ops, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("PatchGroup error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	_, err = env.svc.PatchGroup(ctx, env.token, "manager", []scim.PatchOperation{patchOp("add", "members", `[{"value":"`+uuid.NewString()+`"}]`)}, "")
+	wantSCIMError(t, err, http.StatusBadRequest, scim.ErrorInvalidValue)
+	_, err = env.svc.PatchGroup(ctx, env.token, "manager", []scim.PatchOperation{patchOp("replace", "displayName", `"Boss"`)}, "")
+	wantSCIMError(t, err, http.StatusBadRequest, scim.ErrorMutability)
+}
@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+)
+
+// apiKeyTouchInterval is how stale an API key's last-used time may get
+// before a request updates it, so busy integrations don't write on every
+// call.
+const apiKeyTouchInterval = time.Minute
+
+// ServiceAccountService handles tenant service accounts and their API keys.
+type ServiceAccountService struct {
+	accounts    repository.ServiceAccountRepository
+	keys        repository.APIKeyRepository
+	eventRepo   repository.AuthEventRepository
+	passwordSvc *PasswordService
+}
+
+// ServiceAccountServiceConfig holds configuration for ServiceAccountService.
+type ServiceAccountServiceConfig struct {
+	Accounts  repository.ServiceAccountRepository
+	APIKeys   repository.APIKeyRepository
+	EventRepo repository.AuthEventRepository
+}
+
+// NewServiceAccountService creates a new ServiceAccountService.
+func NewServiceAccountService(cfg ServiceAccountServiceConfig) *ServiceAccountService {
+	return &ServiceAccountService{
+		accounts:    cfg.Accounts,
+		keys:        cfg.APIKeys,
+		eventRepo:   cfg.EventRepo,
+		passwordSvc: NewPasswordService(),
+	}
+}
+
+// List returns a tenant's service accounts, ordered by name.
+func (s *ServiceAccountService) List(ctx context.Context, tenantID uuid.UUID) ([]*domain.ServiceAccount, error) {
+	accounts, err := s.accounts.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list service accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// Get returns one of a tenant's service accounts.
+func (s *ServiceAccountService) Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.ServiceAccount, error) {
+	account, err := s.accounts.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("get service account: %w", err)
+	}
+	return account, nil
+}
+
+// CreateServiceAccountRequest contains the data for creating a service account.
+type CreateServiceAccountRequest struct {
+	TenantID    uuid.UUID
+	Name        string
+	Description string
+	Scopes      []domain.Permission
+	CreatedBy   uuid.UUID
+	IPAddress   string
+}
+
+// Create creates a service account in a tenant. Like a custom role's
+// permissions, its scopes can only be ones the caller holds (callerPerms).
+func (s *ServiceAccountService) Create(ctx context.Context, req CreateServiceAccountRequest, callerPerms []domain.Permission) (*domain.ServiceAccount, error) {
+	name, err := domain.NormalizeServiceAccountName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	scopes, err := checkGrantablePermissions(req.Scopes, callerPerms)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &domain.ServiceAccount{
+		TenantID:    req.TenantID,
+		Name:        name,
+		Description: req.Description,
+		Scopes:      scopes,
+		CreatedBy:   req.CreatedBy,
+	}
+	if err := s.accounts.Create(ctx, account); err != nil {
+		return nil, fmt.Errorf("create service account: save: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventServiceAccountCreated, &req.CreatedBy, &req.TenantID, req.IPAddress, map[string]interface{}{
+		"service_account_id": account.ID,
+		"name":               account.Name,
+		"scopes":             account.Scopes,
+	})
+
+	return account, nil
+}
+
+// UpdateServiceAccountRequest contains the data for updating a service
+// account. Nil fields are left unchanged.
+type UpdateServiceAccountRequest struct {
+	TenantID    uuid.UUID
+	ID          uuid.UUID
+	Name        *string
+	Description *string
+	Scopes      []domain.Permission
+	UpdatedBy   uuid.UUID
+	IPAddress   string
+}
+
+// Update renames a service account or replaces its scopes. Keys carry no
+// scopes of their own, so new scopes apply to the account's next request.
+func (s *ServiceAccountService) Update(ctx context.Context, req UpdateServiceAccountRequest, callerPerms []domain.Permission) (*domain.ServiceAccount, error) {
+	account, err := s.accounts.FindByID(ctx, req.TenantID, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("update service account: lookup: %w", err)
+	}
+
+	if req.Name != nil {
+		name, err := domain.NormalizeServiceAccountName(*req.Name)
+		if err != nil {
+			return nil, err
+		}
+		account.Name = name
+	}
+	if req.Description != nil {
+		account.Description = *req.Description
+	}
+	if req.Scopes != nil {
+		scopes, err := checkGrantablePermissions(req.Scopes, callerPerms)
+		if err != nil {
+			return nil, err
+		}
+		account.Scopes = scopes
+	}
+
+	if err := s.accounts.Update(ctx, account); err != nil {
+		return nil, fmt.Errorf("update service account: save: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventServiceAccountUpdated, &req.UpdatedBy, &req.TenantID, req.IPAddress, map[string]interface{}{
+		"service_account_id": account.ID,
+		"name":               account.Name,
+		"scopes":             account.Scopes,
+	})
+
+	return account, nil
+}
+
+// Delete deletes a service account. Its API keys stop working at once.
+func (s *ServiceAccountService) Delete(ctx context.Context, tenantID, id, deletedBy uuid.UUID, ipAddress string) error {
+	account, err := s.accounts.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("delete service account: lookup: %w", err)
+	}
+	if err := s.accounts.Delete(ctx, tenantID, id); err != nil {
+		return fmt.Errorf("delete service account: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventServiceAccountDeleted, &deletedBy, &tenantID, ipAddress, map[string]interface{}{
+		"service_account_id": account.ID,
+		"name":               account.Name,
+	})
+
+	return nil
+}
+
+// ListAPIKeys returns a service account's API keys that have not been
+// revoked, including expired ones.
+func (s *ServiceAccountService) ListAPIKeys(ctx context.Context, tenantID, serviceAccountID uuid.UUID) ([]*domain.APIKey, error) {
+	if _, err := s.accounts.FindByID(ctx, tenantID, serviceAccountID); err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	keys, err := s.keys.ListByServiceAccount(ctx, serviceAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// CreateAPIKeyRequest contains the data for creating an API key.
+type CreateAPIKeyRequest struct {
+	TenantID         uuid.UUID
+	ServiceAccountID uuid.UUID
+	Name             string
+	// ExpiresIn is how long the key is valid, at most MaxAPIKeyLifetime.
+	// Zero means DefaultAPIKeyLifetime.
+	ExpiresIn time.Duration
+	CreatedBy uuid.UUID
+	IPAddress string
+}
+
+// CreateAPIKey creates an API key for a service account. The plain key is
+// returned only here; just its hash is stored.
+func (s *ServiceAccountService) CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (*domain.APIKey, string, error) {
+	lifetime, err := apiKeyLifetime(req.ExpiresIn)
+	if err != nil {
+		return nil, "", err
+	}
+	account, err := s.accounts.FindByID(ctx, req.TenantID, req.ServiceAccountID)
+	if err != nil {
+		return nil, "", fmt.Errorf("create api key: lookup: %w", err)
+	}
+
+	key, plainKey, err := s.issueAPIKey(ctx, account, req.Name, lifetime, nil, req.CreatedBy)
+	if err != nil {
+		return nil, "", fmt.Errorf("create api key: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventAPIKeyCreated, &req.CreatedBy, &req.TenantID, req.IPAddress, map[string]interface{}{
+		"service_account_id": account.ID,
+		"api_key_id":         key.ID,
+		"prefix":             key.Prefix,
+		"expires_at":         key.ExpiresAt,
+	})
+
+	return key, plainKey, nil
+}
+
+// RotateAPIKeyRequest contains the data for rotating an API key.
+type RotateAPIKeyRequest struct {
+	TenantID         uuid.UUID
+	ServiceAccountID uuid.UUID
+	KeyID            uuid.UUID
+	// ExpiresIn is how long the new key is valid, as for CreateAPIKeyRequest.
+	ExpiresIn time.Duration
+	// GracePeriod is how long the old key keeps working, at most
+	// MaxAPIKeyRotationGrace. Zero revokes it right away.
+	GracePeriod time.Duration
+	RotatedBy   uuid.UUID
+	IPAddress   string
+}
+
+// RotateAPIKey replaces an API key with a new one of the same name. The old
+// key keeps working for the grace period so the integration can be switched
+// over without downtime; it never gets more time than it already had.
+func (s *ServiceAccountService) RotateAPIKey(ctx context.Context, req RotateAPIKeyRequest) (*domain.APIKey, string, error) {
+	lifetime, err := apiKeyLifetime(req.ExpiresIn)
+	if err != nil {
+		return nil, "", err
+	}
+	if req.GracePeriod < 0 || req.GracePeriod > domain.MaxAPIKeyRotationGrace {
+		return nil, "", domain.ErrAPIKeyLifetime
+	}
+	account, old, err := s.findAPIKey(ctx, req.TenantID, req.ServiceAccountID, req.KeyID)
+	if err != nil {
+		return nil, "", fmt.Errorf("rotate api key: %w", err)
+	}
+
+	key, plainKey, err := s.issueAPIKey(ctx, account, old.Name, lifetime, &old.ID, req.RotatedBy)
+	if err != nil {
+		return nil, "", fmt.Errorf("rotate api key: %w", err)
+	}
+
+	if req.GracePeriod == 0 {
+		err = s.keys.Revoke(ctx, old.ID)
+	} else if graceEnd := time.Now().Add(req.GracePeriod); graceEnd.Before(old.ExpiresAt) {
+		err = s.keys.SetExpiry(ctx, old.ID, graceEnd)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("rotate api key: retire old key: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventAPIKeyRotated, &req.RotatedBy, &req.TenantID, req.IPAddress, map[string]interface{}{
+		"service_account_id": account.ID,
+		"api_key_id":         key.ID,
+		"prefix":             key.Prefix,
+		"rotated_from_id":    old.ID,
+		"grace_period":       req.GracePeriod.String(),
+	})
+
+	return key, plainKey, nil
+}
+
+// RevokeAPIKey revokes one of a service account's API keys.
+func (s *ServiceAccountService) RevokeAPIKey(ctx context.Context, tenantID, serviceAccountID, keyID, revokedBy uuid.UUID, ipAddress string) error {
+	account, key, err := s.findAPIKey(ctx, tenantID, serviceAccountID, keyID)
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+	if err := s.keys.Revoke(ctx, key.ID); err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventAPIKeyRevoked, &revokedBy, &tenantID, ipAddress, map[string]interface{}{
+		"service_account_id": account.ID,
+		"api_key_id":         key.ID,
+		"prefix":             key.Prefix,
+	})
+
+	return nil
+}
+
+// Authenticate looks up the service account an API key belongs to and
+// records the key's use. It returns ErrAPIKeyExpired for a key that has
+// expired and ErrAPIKeyInvalid for any other key that doesn't work.
+func (s *ServiceAccountService) Authenticate(ctx context.Context, plainKey string) (*domain.ServiceAccount, *domain.APIKey, error) {
+	key, err := s.keys.FindByHash(ctx, s.passwordSvc.HashResetToken(plainKey))
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, nil, domain.ErrAPIKeyInvalid
+		}
+		return nil, nil, fmt.Errorf("authenticate api key: %w", err)
+	}
+	if key.IsRevoked() {
+		return nil, nil, domain.ErrAPIKeyInvalid
+	}
+	if key.IsExpired() {
+		return nil, nil, domain.ErrAPIKeyExpired
+	}
+
+	account, err := s.accounts.FindByID(ctx, key.TenantID, key.ServiceAccountID)
+	if err != nil {
+		if errors.Is(err, domain.ErrServiceAccountNotFound) {
+			return nil, nil, domain.ErrAPIKeyInvalid
+		}
+		return nil, nil, fmt.Errorf("authenticate api key: lookup account: %w", err)
+	}
+
+	if now := time.Now(); key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		// Best effort: the timestamp only helps admins spot unused keys
+		_ = s.keys.TouchLastUsed(ctx, key.ID, now)
+	}
+
+	return account, key, nil
+}
+
+// findAPIKey returns a tenant's service account and one of its API keys
+// that has not been revoked. Keys of other accounts are reported as not
+// found.
+func (s *ServiceAccountService) findAPIKey(ctx context.Context, tenantID, serviceAccountID, keyID uuid.UUID) (*domain.ServiceAccount, *domain.APIKey, error) {
+	account, err := s.accounts.FindByID(ctx, tenantID, serviceAccountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("lookup account: %w", err)
+	}
+	key, err := s.keys.FindByID(ctx, keyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("lookup key: %w", err)
+	}
+	if key.ServiceAccountID != account.ID || key.IsRevoked() {
+		return nil, nil, domain.ErrAPIKeyNotFound
+	}
+	return account, key, nil
+}
+
+// issueAPIKey generates and stores a new key for a service account. Keys
+// look like "sbk_1a2b3c4d_<secret>": the prefix up to the second underscore
+// is stored in the clear, and the whole key is hashed.
+func (s *ServiceAccountService) issueAPIKey(ctx context.Context, account *domain.ServiceAccount, name string, lifetime time.Duration, rotatedFrom *uuid.UUID, createdBy uuid.UUID) (*domain.APIKey, string, error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return nil, "", fmt.Errorf("generate prefix: %w", err)
+	}
+	secret, _, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("generate key: %w", err)
+	}
+	prefix := domain.APIKeyPrefix + hex.EncodeToString(id)
+	plainKey := prefix + "_" + secret
+
+	key := &domain.APIKey{
+		ID:               uuid.New(),
+		ServiceAccountID: account.ID,
+		TenantID:         account.TenantID,
+		Name:             name,
+		Prefix:           prefix,
+		KeyHash:          s.passwordSvc.HashResetToken(plainKey),
+		ExpiresAt:        time.Now().Add(lifetime),
+		RotatedFromID:    rotatedFrom,
+		CreatedBy:        createdBy,
+	}
+	if err := s.keys.Create(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("save: %w", err)
+	}
+	return key, plainKey, nil
+}
+
+// apiKeyLifetime returns the lifetime of a new API key, defaulting a zero
+// expiresIn.
+func apiKeyLifetime(expiresIn time.Duration) (time.Duration, error) {
+	if expiresIn == 0 {
+		return domain.DefaultAPIKeyLifetime, nil
+	}
+	if expiresIn < 24*time.Hour || expiresIn > domain.MaxAPIKeyLifetime {
+		return 0, domain.ErrAPIKeyLifetime
+	}
+	return expiresIn, nil
+}
+
+// logEvent logs an authentication event.
+func (s *ServiceAccountService) logEvent(ctx context.Context, eventType domain.AuthEventType, userID, tenantID *uuid.UUID, ipAddress string, metadata map[string]interface{}) {
+	event := newAuthEvent(ctx, eventType, userID, tenantID, ipAddress, "")
+	if metadata != nil {
+		event.Metadata = metadata
+	}
+	// Fire and forget - don't fail the request if logging fails
+	_ = s.eventRepo.Create(ctx, event)
+}
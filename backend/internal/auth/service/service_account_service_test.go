@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+)
+
+// serviceAccountTestEnv bundles a ServiceAccountService with its mock
+// repositories and a tenant's service account.
+type serviceAccountTestEnv struct {
+	svc       *ServiceAccountService
+	accounts  *mock.MockServiceAccountRepository
+	keys      *mock.MockAPIKeyRepository
+	eventRepo *mock.MockAuthEventRepository
+	tenantID  uuid.UUID
+	adminID   uuid.UUID
+	account   *domain.ServiceAccount
+}
+
+func setupServiceAccountService(t *testing.T) *serviceAccountTestEnv {
+	t.Helper()
+
+	env := &serviceAccountTestEnv{
+		accounts:  mock.NewMockServiceAccountRepository(),
+		keys:      mock.NewMockAPIKeyRepository(),
+		eventRepo: mock.NewMockAuthEventRepository(),
+		tenantID:  uuid.New(),
+		adminID:   uuid.New(),
+	}
+	env.svc = NewServiceAccountService(ServiceAccountServiceConfig{
+		Accounts:  env.accounts,
+		APIKeys:   env.keys,
+		EventRepo: env.eventRepo,
+	})
+
+	account, err := env.svc.Create(context.Background(), CreateServiceAccountRequest{
+		TenantID:  env.tenantID,
+		Name:      " Accounting sync ",
+		Scopes:    []domain.Permission{domain.PermUsersManage, domain.PermUsersManage},
+		CreatedBy: env.adminID,
+	}, domain.RoleAdmin.Permissions())
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	env.account = account
+	return env
+}
+
+// createKey creates an API key for the env's service account.
+func (e *serviceAccountTestEnv) createKey(t *testing.T) (*domain.APIKey, string) {
+	t.Helper()
+	key, plain, err := e.svc.CreateAPIKey(context.Background(), CreateAPIKeyRequest{
+		TenantID:         e.tenantID,
+		ServiceAccountID: e.account.ID,
+		Name:             "production",
+		CreatedBy:        e.adminID,
+	})
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	return key, plain
+}
+
+func TestServiceAccountService_Create(t *testing.T) {
+	env := setupServiceAccountService(t)
+
+	if env.account.Name != "Accounting sync" {
+		t.Errorf("Name = %q, want trimmed Accounting sync", env.account.Name)
+	}
+	if len(env.account.Scopes) != 1 || !env.account.HasScope(domain.PermUsersManage) {
+		t.Errorf("Scopes = %v, want [users.manage] without duplicates", env.account.Scopes)
+	}
+
+	tests := []struct {
+		name    string
+		req     CreateServiceAccountRequest
+		wantErr error
+	}{
+		{"blank name", CreateServiceAccountRequest{Name: " "}, domain.ErrServiceAccountName},
+		{"unknown scope", CreateServiceAccountRequest{Name: "Sync", Scopes: []domain.Permission{"nope.nope"}}, domain.ErrUnknownPermission},
+		{"scope not held", CreateServiceAccountRequest{Name: "Sync", Scopes: []domain.Permission{domain.PermTerminalsManage}}, domain.ErrPermissionNotHeld},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.TenantID = env.tenantID
+			_, err := env.svc.Create(context.Background(), tt.req, domain.RoleWaiter.Permissions())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceAccountService_Update(t *testing.T) {
+	env := setupServiceAccountService(t)
+	ctx := context.Background()
+
+	name, description := "Ledger sync", "Nightly export"
+	account, err := env.svc.Update(ctx, UpdateServiceAccountRequest{
+		TenantID: env.tenantID, ID: env.account.ID, Name: &name, Description: &description, Scopes: []domain.Permission{},
+	}, domain.RoleAdmin.Permissions())
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if account.Name != name || account.Description != description || len(account.Scopes) != 0 {
+		t.Errorf("account = %+v", account)
+	}
+
+	_, err = env.svc.Update(ctx, UpdateServiceAccountRequest{TenantID: uuid.New(), ID: env.account.ID}, nil)
+	if !errors.Is(err, domain.ErrServiceAccountNotFound) {
+		t.Errorf("Update from another tenant error = %v, want ErrServiceAccountNotFound", err)
+	}
+}
+
+func TestServiceAccountService_Authenticate(t *testing.T) {
+	env := setupServiceAccountService(t)
+	ctx := context.Background()
+	key, plain := env.createKey(t)
+
+	if !strings.HasPrefix(plain, key.Prefix+"_") || len(key.Prefix) != len(domain.APIKeyPrefix)+8 {
+		t.Errorf("key %q, prefix %q, want the prefix then the secret", plain, key.Prefix)
+	}
+	if key.KeyHash == plain || strings.Contains(key.KeyHash, key.Prefix) {
+		t.Error("CreateAPIKey should store only the key's hash")
+	}
+	if d := time.Until(key.ExpiresAt) - domain.DefaultAPIKeyLifetime; d > time.Minute || d < -time.Minute {
+		t.Errorf("ExpiresAt = %v, want the default lifetime", key.ExpiresAt)
+	}
+
+	account, authed, err := env.svc.Authenticate(ctx, plain)
+	if err != nil || account.ID != env.account.ID || authed.ID != key.ID {
+		t.Fatalf("Authenticate = %v, %v, %v, want the account and key", account, authed, err)
+	}
+	if authed.LastUsedAt == nil {
+		t.Fatal("Authenticate should record the key's use")
+	}
+	lastUsed := *authed.LastUsedAt
+	env.svc.Authenticate(ctx, plain)
+	if !authed.LastUsedAt.Equal(lastUsed) {
+		t.Error("Authenticate should not record every use")
+	}
+
+	if _, _, err := env.svc.Authenticate(ctx, domain.APIKeyPrefix+"wrong"); !errors.Is(err, domain.ErrAPIKeyInvalid) {
+		t.Errorf("Authenticate with a wrong key error = %v, want ErrAPIKeyInvalid", err)
+	}
+
+	env.keys.SetExpiry(ctx, key.ID, time.Now().Add(-time.Second))
+	if _, _, err := env.svc.Authenticate(ctx, plain); !errors.Is(err, domain.ErrAPIKeyExpired) {
+		t.Errorf("Authenticate with an expired key error = %v, want ErrAPIKeyExpired", err)
+	}
+
+	_, plain = env.createKey(t)
+	if err := env.svc.Delete(ctx, env.tenantID, env.account.ID, env.adminID, ""); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, _, err := env.svc.Authenticate(ctx, plain); !errors.Is(err, domain.ErrAPIKeyInvalid) {
+		t.Errorf("Authenticate after Delete error = %v, want ErrAPIKeyInvalid", err)
+	}
+}
+
+func TestServiceAccountService_CreateAPIKey_Lifetime(t *testing.T) {
+	env := setupServiceAccountService(t)
+
+	for _, expiresIn := range []time.Duration{time.Hour, domain.MaxAPIKeyLifetime + time.Hour, -24 * time.Hour} {
+		_, _, err := env.svc.CreateAPIKey(context.Background(), CreateAPIKeyRequest{
+			TenantID: env.tenantID, ServiceAccountID: env.account.ID, Name: "short", ExpiresIn: expiresIn,
+		})
+		if !errors.Is(err, domain.ErrAPIKeyLifetime) {
+			t.Errorf("CreateAPIKey expiring in %v error = %v, want ErrAPIKeyLifetime", expiresIn, err)
+		}
+	}
+
+	_, _, err := env.svc.CreateAPIKey(context.Background(), CreateAPIKeyRequest{
+		TenantID: uuid.New(), ServiceAccountID: env.account.ID, Name: "elsewhere",
+	})
+	if !errors.Is(err, domain.ErrServiceAccountNotFound) {
+		t.Errorf("CreateAPIKey from another tenant error = %v, want ErrServiceAccountNotFound", err)
+	}
+}
+
+func TestServiceAccountService_RotateAPIKey(t *testing.T) {
+	env := setupServiceAccountService(t)
+	ctx := context.Background()
+	old, oldPlain := env.createKey(t)
+
+	rotate := func(keyID uuid.UUID, grace time.Duration) (*domain.APIKey, string, error) {
+		return env.svc.RotateAPIKey(ctx, RotateAPIKeyRequest{
+			TenantID: env.tenantID, ServiceAccountID: env.account.ID, KeyID: keyID, GracePeriod: grace, RotatedBy: env.adminID,
+		})
+	}
+
+	key, plain, err := rotate(old.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("RotateAPIKey failed: %v", err)
+	}
+	if key.Name != old.Name || key.RotatedFromID == nil || *key.RotatedFromID != old.ID {
+		t.Errorf("rotated key = %+v, want a replacement for %s", key, old.ID)
+	}
+	if d := time.Until(old.ExpiresAt) - time.Hour; d > time.Minute || d < -time.Minute {
+		t.Errorf("old key expires at %v, want after the grace period", old.ExpiresAt)
+	}
+	for _, k := range []string{oldPlain, plain} {
+		if _, _, err := env.svc.Authenticate(ctx, k); err != nil {
+			t.Errorf("Authenticate during the grace period failed: %v", err)
+		}
+	}
+
+	if _, _, err := rotate(key.ID, domain.MaxAPIKeyRotationGrace+time.Hour); !errors.Is(err, domain.ErrAPIKeyLifetime) {
+		t.Errorf("RotateAPIKey with a long grace period error = %v, want ErrAPIKeyLifetime", err)
+	}
+	if _, _, err := rotate(uuid.New(), 0); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("RotateAPIKey of an unknown key error = %v, want ErrAPIKeyNotFound", err)
+	}
+
+	if _, _, err := rotate(key.ID, 0); err != nil {
+		t.Fatalf("RotateAPIKey without grace failed: %v", err)
+	}
+	if _, _, err := env.svc.Authenticate(ctx, plain); !errors.Is(err, domain.ErrAPIKeyInvalid) {
+		t.Errorf("Authenticate with a key rotated without grace error = %v, want ErrAPIKeyInvalid", err)
+	}
+
+	var rotated int
+	for _, e := range env.eventRepo.GetEvents() {
+		if e.EventType == domain.EventAPIKeyRotated {
+			rotated++
+		}
+	}
+	if rotated != 2 {
+		t.Errorf("events: %d rotated, want 2", rotated)
+	}
+}
+
+func TestServiceAccountService_RevokeAPIKey(t *testing.T) {
+	env := setupServiceAccountService(t)
+	ctx := context.Background()
+	key, plain := env.createKey(t)
+
+	other, err := env.svc.Create(ctx, CreateServiceAccountRequest{TenantID: env.tenantID, Name: "Delivery"}, nil)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := env.svc.RevokeAPIKey(ctx, env.tenantID, other.ID, key.ID, env.adminID, ""); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("RevokeAPIKey through another account error = %v, want ErrAPIKeyNotFound", err)
+	}
+
+	if err := env.svc.RevokeAPIKey(ctx, env.tenantID, env.account.ID, key.ID, env.adminID, ""); err != nil {
+		t.Fatalf("RevokeAPIKey failed: %v", err)
+	}
+	if _, _, err := env.svc.Authenticate(ctx, plain); !errors.Is(err, domain.ErrAPIKeyInvalid) {
+		t.Errorf("Authenticate after revoke error = %v, want ErrAPIKeyInvalid", err)
+	}
+	if keys, _ := env.svc.ListAPIKeys(ctx, env.tenantID, env.account.ID); len(keys) != 0 {
+		t.Errorf("ListAPIKeys len = %d after revoke, want 0", len(keys))
+	}
+}
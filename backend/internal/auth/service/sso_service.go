@@ -0,0 +1,433 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
+	"github.com/solobueno/erp/pkg/oidc"
+)
+
+// ssoHTTPTimeout bounds each request to an identity provider.
+const ssoHTTPTimeout = 10 * time.Second
+
+// LoginMethodSSO is recorded in login event metadata for SSO logins.
+const LoginMethodSSO = domain.SignInSSO
+
+// SSOService handles per-tenant OpenID Connect single sign-on: the tenant's
+// identity provider configuration, the authorization code + PKCE login flow,
+// and linking provider accounts to existing users by verified email.
+type SSOService struct {
+	configs     repository.SSOConfigRepository
+	states      repository.SSOLoginStateRepository
+	identities  repository.UserIdentityRepository
+	tenantRepo  repository.TenantRepository
+	userRepo    repository.UserRepository
+	eventRepo   repository.AuthEventRepository
+	authService *AuthService
+	passwordSvc *PasswordService
+	httpClient  *http.Client
+	redirectURL string
+
+	mu        sync.Mutex
+	providers map[string]*oidc.Provider // Discovered providers by issuer
+}
+
+// SSOServiceConfig holds configuration for SSOService.
+type SSOServiceConfig struct {
+	Configs    repository.SSOConfigRepository
+	States     repository.SSOLoginStateRepository
+	Identities repository.UserIdentityRepository
+	TenantRepo repository.TenantRepository
+	UserRepo   repository.UserRepository
+	EventRepo  repository.AuthEventRepository
+	// AuthService issues the session once the identity provider has
+	// signed the user in.
+	AuthService *AuthService
+	// RedirectURL is where identity providers send users back to, and must
+	// be registered with each tenant's provider. The page there posts the
+	// code and state it receives to /auth/sso/callback.
+	RedirectURL string
+	// HTTPClient talks to identity providers. Defaults to a client with a
+	// 10 second timeout.
+	HTTPClient *http.Client
+}
+
+// NewSSOService creates a new SSOService.
+func NewSSOService(cfg SSOServiceConfig) *SSOService {
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: ssoHTTPTimeout}
+	}
+	return &SSOService{
+		configs:     cfg.Configs,
+		states:      cfg.States,
+		identities:  cfg.Identities,
+		tenantRepo:  cfg.TenantRepo,
+		userRepo:    cfg.UserRepo,
+		eventRepo:   cfg.EventRepo,
+		authService: cfg.AuthService,
+		passwordSvc: NewPasswordService(),
+		httpClient:  httpClient,
+		redirectURL: cfg.RedirectURL,
+		providers:   make(map[string]*oidc.Provider),
+	}
+}
+
+// RedirectURL returns the callback URL to register with identity providers.
+func (s *SSOService) RedirectURL() string {
+	return s.redirectURL
+}
+
+// GetConfig returns a tenant's SSO configuration.
+func (s *SSOService) GetConfig(ctx context.Context, tenantID uuid.UUID) (*domain.TenantSSOConfig, error) {
+	cfg, err := s.configs.FindByTenant(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSSONotConfigured) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("get sso config: %w", err)
+	}
+	return cfg, nil
+}
+
+// SaveSSOConfigRequest contains the data needed to set up or change a
+// tenant's identity provider.
+type SaveSSOConfigRequest struct {
+	TenantID              uuid.UUID
+	UpdatedBy             uuid.UUID
+	Issuer                string
+	ClientID              string
+	ClientSecret          string // Optional on update; empty keeps the current secret
+	AllowedDomains        []string
+	PasswordLoginDisabled bool
+	IPAddress             string
+	UserAgent             string
+}
+
+// SaveConfig creates or replaces a tenant's SSO configuration. The issuer
+// is discovered before anything is saved, so a typo can't lock staff out.
+func (s *SSOService) SaveConfig(ctx context.Context, req SaveSSOConfigRequest) (*domain.TenantSSOConfig, error) {
+	issuer := strings.TrimSpace(req.Issuer)
+	clientID := strings.TrimSpace(req.ClientID)
+	if u, err := url.Parse(issuer); err != nil || u.Scheme != "https" || u.Host == "" || clientID == "" {
+		return nil, domain.ErrSSOConfigInvalid
+	}
+	domains, err := domain.NormalizeEmailDomains(req.AllowedDomains)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := s.configs.FindByTenant(ctx, req.TenantID)
+	creating := errors.Is(err, domain.ErrSSONotConfigured)
+	if err != nil && !creating {
+		return nil, fmt.Errorf("save sso config: lookup: %w", err)
+	}
+	if creating {
+		cfg = &domain.TenantSSOConfig{ID: uuid.New(), TenantID: req.TenantID}
+	}
+	secret := req.ClientSecret
+	if secret == "" {
+		secret = cfg.ClientSecret
+	}
+	if secret == "" {
+		return nil, domain.ErrSSOConfigInvalid
+	}
+
+	provider, err := oidc.Discover(ctx, s.httpClient, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrSSOProviderUnavailable, err)
+	}
+	s.mu.Lock()
+	s.providers[issuer] = provider
+	s.mu.Unlock()
+
+	cfg.Issuer = issuer
+	cfg.ClientID = clientID
+	cfg.ClientSecret = secret
+	cfg.AllowedDomains = domains
+	cfg.PasswordLoginDisabled = req.PasswordLoginDisabled
+	cfg.UpdatedBy = &req.UpdatedBy
+
+	if creating {
+		err = s.configs.Create(ctx, cfg)
+	} else {
+		err = s.configs.Update(ctx, cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("save sso config: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventSSOConfigUpdated, &req.UpdatedBy, &req.TenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+		"issuer":                  issuer,
+		"client_id":               clientID,
+		"allowed_domains":         []string(domains),
+		"password_login_disabled": req.PasswordLoginDisabled,
+		"secret_changed":          req.ClientSecret != "",
+	})
+
+	return cfg, nil
+}
+
+// DeleteConfig removes a tenant's SSO configuration, turning password
+// login back on for everyone. Linked identities are kept, so staff go
+// straight back to their accounts if SSO is set up again.
+func (s *SSOService) DeleteConfig(ctx context.Context, tenantID, deletedBy uuid.UUID, ipAddress, userAgent string) error {
+	if err := s.configs.Delete(ctx, tenantID); err != nil {
+		if errors.Is(err, domain.ErrSSONotConfigured) {
+			return err
+		}
+		return fmt.Errorf("delete sso config: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventSSOConfigDeleted, &deletedBy, &tenantID, ipAddress, userAgent, nil)
+	return nil
+}
+
+// StartLogin begins an SSO login to the tenant with the given slug and
+// returns the identity provider URL to send the user's browser to. An
+// unknown tenant looks the same as one without SSO.
+func (s *SSOService) StartLogin(ctx context.Context, tenantSlug string) (string, error) {
+	tenant, err := s.tenantRepo.FindBySlug(ctx, tenantSlug)
+	if err != nil {
+		if errors.Is(err, domain.ErrTenantNotFound) {
+			return "", domain.ErrSSONotConfigured
+		}
+		return "", fmt.Errorf("sso start: tenant lookup: %w", err)
+	}
+	if !tenant.IsOperational() {
+		return "", domain.ErrTenantInactive
+	}
+
+	cfg, err := s.GetConfig(ctx, tenant.ID)
+	if err != nil {
+		return "", err
+	}
+	provider, err := s.provider(ctx, cfg.Issuer)
+	if err != nil {
+		return "", err
+	}
+
+	plainState, stateHash, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return "", fmt.Errorf("sso start: generate state: %w", err)
+	}
+	nonce, err := oidc.NewNonce()
+	if err != nil {
+		return "", fmt.Errorf("sso start: generate nonce: %w", err)
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", fmt.Errorf("sso start: generate code verifier: %w", err)
+	}
+
+	state := &domain.SSOLoginState{
+		ID:           uuid.New(),
+		TenantID:     tenant.ID,
+		StateHash:    stateHash,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(domain.SSOLoginStateTTL),
+	}
+	if err := s.states.Create(ctx, state); err != nil {
+		return "", fmt.Errorf("sso start: create state: %w", err)
+	}
+
+	return provider.AuthCodeURL(oidc.AuthRequest{
+		ClientID:      cfg.ClientID,
+		RedirectURI:   s.redirectURL,
+		State:         plainState,
+		Nonce:         nonce,
+		CodeChallenge: oidc.CodeChallengeS256(verifier),
+	}), nil
+}
+
+// CompleteSSOLoginRequest contains what the identity provider sent back.
+type CompleteSSOLoginRequest struct {
+	State     string
+	Code      string
+	IPAddress string
+	UserAgent string
+}
+
+// CompleteLogin finishes an SSO login: it redeems the code at the identity
+// provider, validates the ID token, resolves the user and issues a session
+// for the tenant the login was started for.
+//
+// A provider account is linked to a user the first time it signs in, by
+// its email - which the provider must have verified and which must be in
+// one of the tenant's allowed domains. Linked accounts are found by subject
+// from then on, but the email rules are still applied on every login.
+// Second factors are the identity provider's responsibility, so it only
+// signs in roles the member who configured it could manage; anyone else
+// gets ErrSSORoleNotAllowed and signs in with a password.
+func (s *SSOService) CompleteLogin(ctx context.Context, req CompleteSSOLoginRequest) (*LoginResponse, error) {
+	state, err := s.states.FindByState(ctx, s.passwordSvc.HashResetToken(req.State))
+	if err != nil {
+		if errors.Is(err, domain.ErrSSOStateInvalid) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("sso callback: state lookup: %w", err)
+	}
+	if !state.IsValid() {
+		return nil, domain.ErrSSOStateInvalid
+	}
+	// Spend the state before talking to the provider, so it can't be replayed
+	if err := s.states.MarkUsed(ctx, state.ID); err != nil {
+		if errors.Is(err, domain.ErrSSOStateInvalid) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("sso callback: mark state used: %w", err)
+	}
+	tenantID := state.TenantID
+
+	cfg, err := s.GetConfig(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := s.provider(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	fail := func(userID *uuid.UUID, reason string, err error) error {
+		s.logEvent(ctx, domain.EventLoginFailed, userID, &tenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+			"method": LoginMethodSSO,
+			"issuer": cfg.Issuer,
+			"reason": reason,
+		})
+		return err
+	}
+
+	token, err := provider.Exchange(ctx, oidc.TokenRequest{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURI:  s.redirectURL,
+		Code:         req.Code,
+		CodeVerifier: state.CodeVerifier,
+	})
+	if err != nil {
+		return nil, fail(nil, "code_exchange_failed", fmt.Errorf("%w: %v", domain.ErrSSOTokenInvalid, err))
+	}
+	idToken, err := provider.VerifyIDToken(ctx, token.IDToken, cfg.ClientID, state.Nonce)
+	if err != nil {
+		return nil, fail(nil, "id_token_invalid", fmt.Errorf("%w: %v", domain.ErrSSOTokenInvalid, err))
+	}
+	if !idToken.EmailVerified || !cfg.AllowsEmail(idToken.Email) {
+		return nil, fail(nil, "email_not_allowed", domain.ErrSSOEmailNotAllowed)
+	}
+
+	identity, err := s.identities.FindBySubject(ctx, idToken.Issuer, idToken.Subject)
+	if err != nil && !errors.Is(err, domain.ErrSSOIdentityNotFound) {
+		return nil, fmt.Errorf("sso callback: identity lookup: %w", err)
+	}
+
+	var user *domain.User
+	if identity != nil {
+		user, err = s.userRepo.FindByIDWithTenants(ctx, identity.UserID)
+	} else {
+		user, err = s.userRepo.FindByEmailWithTenants(ctx, idToken.Email)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, fail(nil, "no_account", domain.ErrSSOAccountNotFound)
+		}
+		return nil, fmt.Errorf("sso callback: user lookup: %w", err)
+	}
+
+	if !user.CanLogin() {
+		return nil, fail(&user.ID, "account_disabled", domain.ErrAccountDisabled)
+	}
+	role := user.GetRoleForTenant(tenantID)
+	if role == "" {
+		return nil, fail(&user.ID, "not_in_tenant", domain.ErrSSOAccountNotFound)
+	}
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("sso callback: tenant lookup: %w", err)
+	}
+	if !tenant.IsOperational() {
+		return nil, domain.ErrTenantInactive
+	}
+	allowed, err := s.authService.ssoAllowsRole(ctx, cfg, role)
+	if err != nil {
+		return nil, fmt.Errorf("sso callback: %w", err)
+	}
+	if !allowed {
+		return nil, fail(&user.ID, "role_not_allowed", domain.ErrSSORoleNotAllowed)
+	}
+
+	// Link on first sign-in, once we know the account belongs in this tenant
+	if identity == nil {
+		identity = &domain.UserIdentity{
+			ID:      uuid.New(),
+			UserID:  user.ID,
+			Issuer:  idToken.Issuer,
+			Subject: idToken.Subject,
+			Email:   idToken.Email,
+		}
+		if err := s.identities.Create(ctx, identity); err != nil {
+			return nil, fmt.Errorf("sso callback: link identity: %w", err)
+		}
+		s.logEvent(ctx, domain.EventSSOIdentityLinked, &user.ID, &tenantID, req.IPAddress, req.UserAgent, map[string]interface{}{
+			"issuer":  idToken.Issuer,
+			"subject": idToken.Subject,
+			"email":   idToken.Email,
+		})
+	}
+	if err := s.identities.TouchLastLogin(ctx, identity.ID); err != nil {
+		return nil, fmt.Errorf("sso callback: touch identity: %w", err)
+	}
+
+	resp, err := s.authService.issueSession(ctx, user, tenantID, role, domain.SignInSSO, req.IPAddress, req.UserAgent, map[string]interface{}{
+		"method": LoginMethodSSO,
+		"issuer": idToken.Issuer,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sso callback: %w", err)
+	}
+	return resp, nil
+}
+
+// provider returns the discovered identity provider for an issuer,
+// discovering it on first use.
+func (s *SSOService) provider(ctx context.Context, issuer string) (*oidc.Provider, error) {
+	s.mu.Lock()
+	provider, ok := s.providers[issuer]
+	s.mu.Unlock()
+	if ok {
+		return provider, nil
+	}
+
+	provider, err := oidc.Discover(ctx, s.httpClient, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrSSOProviderUnavailable, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Keep whichever got here first, so its cached keys are shared
+	if existing, ok := s.providers[issuer]; ok {
+		return existing, nil
+	}
+	s.providers[issuer] = provider
+	return provider, nil
+}
+
+// logEvent logs an SSO event.
+func (s *SSOService) logEvent(ctx context.Context, eventType domain.AuthEventType, userID, tenantID *uuid.UUID, ipAddress, userAgent string, metadata map[string]interface{}) {
+	event := newAuthEvent(ctx, eventType, userID, tenantID, ipAddress, userAgent)
+	if metadata != nil {
+		event.Metadata = metadata
+	}
+	// Fire and forget - don't fail the request if logging fails
+	_ = s.eventRepo.Create(ctx, event)
+}
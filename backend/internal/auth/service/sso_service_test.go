@@ -0,0 +1,563 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository/mock"
+	"github.com/solobueno/erp/pkg/oidc/oidctest"
+)
+
+const testSSORedirectURL = "https://app.example.com/sso/callback"
+
+// ssoTestEnv bundles an SSOService wired to a stub identity provider, with
+// a tenant that has SSO configured by its owner and a waiter in it.
+type ssoTestEnv struct {
+	svc        *SSOService
+	authSvc    *AuthService
+	idp        *oidctest.Server
+	configs    *mock.MockSSOConfigRepository
+	states     *mock.MockSSOLoginStateRepository
+	identities *mock.MockUserIdentityRepository
+	userRepo   *mock.MockUserRepository
+	tenantRepo *mock.MockTenantRepository
+	eventRepo  *mock.MockAuthEventRepository
+	tenant     *domain.Tenant
+	owner      *domain.User
+	waiter     *domain.User
+}
+
+func setupSSOService(t *testing.T) *ssoTestEnv {
+	t.Helper()
+
+	authSvc, userRepo, _, tenantRepo, eventRepo := setupAuthService(t)
+	env := &ssoTestEnv{
+		authSvc:    authSvc,
+		idp:        oidctest.NewServer("erp-client", "erp-secret"),
+		configs:    mock.NewMockSSOConfigRepository(),
+		states:     mock.NewMockSSOLoginStateRepository(),
+		identities: mock.NewMockUserIdentityRepository(),
+		userRepo:   userRepo,
+		tenantRepo: tenantRepo,
+		eventRepo:  eventRepo,
+	}
+	t.Cleanup(env.idp.Close)
+	authSvc.ssoConfigs = env.configs
+
+	env.svc = NewSSOService(SSOServiceConfig{
+		Configs:     env.configs,
+		States:      env.states,
+		Identities:  env.identities,
+		TenantRepo:  tenantRepo,
+		UserRepo:    userRepo,
+		EventRepo:   eventRepo,
+		AuthService: authSvc,
+		RedirectURL: testSSORedirectURL,
+		HTTPClient:  env.idp.Client(),
+	})
+
+	env.tenant = &domain.Tenant{ID: uuid.New(), Name: "Casa Ana", Slug: "casa-ana", IsActive: true}
+	tenantRepo.AddTenant(env.tenant)
+	env.owner = env.addMember(t, "owner@casa-ana.com", env.tenant.ID, domain.RoleOwner)
+	env.configs.AddConfig(&domain.TenantSSOConfig{
+		TenantID:       env.tenant.ID,
+		Issuer:         env.idp.Issuer(),
+		ClientID:       "erp-client",
+		ClientSecret:   "erp-secret",
+		AllowedDomains: domain.DomainList{"casa-ana.com"},
+		UpdatedBy:      &env.owner.ID,
+	})
+	env.waiter = env.addMember(t, "ana@casa-ana.com", env.tenant.ID, domain.RoleWaiter)
+	return env
+}
+
+// addMember adds an active user with a password and a role in a tenant.
+func (e *ssoTestEnv) addMember(t *testing.T, email string, tenantID uuid.UUID, role domain.Role) *domain.User {
+	t.Helper()
+
+	passwordHash, _ := NewPasswordService().Hash("Password123!")
+	userID := uuid.New()
+	user := &domain.User{
+		ID:           userID,
+		Email:        email,
+		PasswordHash: passwordHash,
+		IsActive:     true,
+		TenantRoles:  []domain.UserTenantRole{{UserID: userID, TenantID: tenantID, Role: role}},
+	}
+	e.userRepo.AddUser(user)
+	return user
+}
+
+// signIn starts an SSO login to the env's tenant, signs in at the stub IdP
+// as identity and completes the login with the code it sends back.
+func (e *ssoTestEnv) signIn(t *testing.T, identity oidctest.Identity) (*LoginResponse, error) {
+	t.Helper()
+
+	authURL, err := e.svc.StartLogin(context.Background(), e.tenant.Slug)
+	if err != nil {
+		t.Fatalf("StartLogin failed: %v", err)
+	}
+	e.idp.SetIdentity(identity)
+	callback, err := e.idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+	return e.svc.CompleteLogin(context.Background(), CompleteSSOLoginRequest{
+		State:     callback.Query().Get("state"),
+		Code:      callback.Query().Get("code"),
+		IPAddress: "127.0.0.1",
+	})
+}
+
+// hasEventReason reports whether an event of eventType was recorded with
+// reason in its metadata.
+func (e *ssoTestEnv) hasEventReason(eventType domain.AuthEventType, reason string) bool {
+	for _, ev := range e.eventRepo.GetEvents() {
+		if ev.EventType == eventType && ev.Metadata["reason"] == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSSOService_Login_LinksByVerifiedEmail(t *testing.T) {
+	env := setupSSOService(t)
+	ctx := context.Background()
+
+	resp, err := env.signIn(t, oidctest.Identity{Subject: "idp-ana", Email: "ana@casa-ana.com", EmailVerified: true})
+	if err != nil {
+		t.Fatalf("CompleteLogin failed: %v", err)
+	}
+	if resp.TokenPair == nil || resp.User.ID != env.waiter.ID || resp.TenantID != env.tenant.ID || resp.Role != domain.RoleWaiter {
+		t.Fatalf("unexpected login response: %+v", resp)
+	}
+	claims, err := env.authSvc.ValidateToken(ctx, resp.TokenPair.AccessToken)
+	if err != nil || claims.Subject != env.waiter.ID.String() {
+		t.Fatalf("ValidateToken = %+v, %v", claims, err)
+	}
+
+	identities := env.identities.Identities()
+	if len(identities) != 1 || identities[0].UserID != env.waiter.ID || identities[0].Issuer != env.idp.Issuer() || identities[0].Subject != "idp-ana" {
+		t.Fatalf("identities = %+v, want ana linked by subject", identities)
+	}
+	if identities[0].LastLoginAt == nil {
+		t.Error("LastLoginAt should be set")
+	}
+	if !hasEventType(env.eventRepo, domain.EventSSOIdentityLinked) {
+		t.Error("expected an sso_identity_linked event")
+	}
+	var login *domain.AuthEvent
+	for _, ev := range env.eventRepo.GetEvents() {
+		if ev.EventType == domain.EventLoginSuccess {
+			login = ev
+		}
+	}
+	if login == nil || login.Metadata["method"] != LoginMethodSSO || login.Metadata["issuer"] != env.idp.Issuer() {
+		t.Errorf("login_success event = %+v, want method sso", login)
+	}
+
+	// Once linked, the subject finds the user even after their email changes here
+	env.waiter.Email = "ana.g@casa-ana.com"
+	resp, err = env.signIn(t, oidctest.Identity{Subject: "idp-ana", Email: "ana@casa-ana.com", EmailVerified: true})
+	if err != nil {
+		t.Fatalf("second CompleteLogin failed: %v", err)
+	}
+	if resp.User.ID != env.waiter.ID || len(env.identities.Identities()) != 1 {
+		t.Error("second login should reuse the existing link")
+	}
+}
+
+func TestSSOService_Login_Rejections(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, env *ssoTestEnv)
+		identity oidctest.Identity
+		wantErr  error
+		reason   string
+	}{
+		{
+			name:     "unverified email",
+			identity: oidctest.Identity{Subject: "idp-ana", Email: "ana@casa-ana.com"},
+			wantErr:  domain.ErrSSOEmailNotAllowed,
+			reason:   "email_not_allowed",
+		},
+		{
+			name: "domain not allowed",
+			setup: func(t *testing.T, env *ssoTestEnv) {
+				env.addMember(t, "ana@gmail.com", env.tenant.ID, domain.RoleWaiter)
+			},
+			identity: oidctest.Identity{Subject: "idp-ana", Email: "ana@gmail.com", EmailVerified: true},
+			wantErr:  domain.ErrSSOEmailNotAllowed,
+			reason:   "email_not_allowed",
+		},
+		{
+			name:     "no account",
+			identity: oidctest.Identity{Subject: "idp-bea", Email: "bea@casa-ana.com", EmailVerified: true},
+			wantErr:  domain.ErrSSOAccountNotFound,
+			reason:   "no_account",
+		},
+		{
+			name: "member of another tenant only",
+			setup: func(t *testing.T, env *ssoTestEnv) {
+				env.addMember(t, "bea@casa-ana.com", uuid.New(), domain.RoleWaiter)
+			},
+			identity: oidctest.Identity{Subject: "idp-bea", Email: "bea@casa-ana.com", EmailVerified: true},
+			wantErr:  domain.ErrSSOAccountNotFound,
+			reason:   "not_in_tenant",
+		},
+		{
+			name:     "account disabled",
+			setup:    func(t *testing.T, env *ssoTestEnv) { env.waiter.IsActive = false },
+			identity: oidctest.Identity{Subject: "idp-ana", Email: "ana@casa-ana.com", EmailVerified: true},
+			wantErr:  domain.ErrAccountDisabled,
+			reason:   "account_disabled",
+		},
+		{
+			name: "linked identity outside the allowed domains",
+			setup: func(t *testing.T, env *ssoTestEnv) {
+				env.identities.AddIdentity(&domain.UserIdentity{UserID: env.waiter.ID, Issuer: env.idp.Issuer(), Subject: "idp-ana"})
+			},
+			identity: oidctest.Identity{Subject: "idp-ana", Email: "ana@elsewhere.com", EmailVerified: true},
+			wantErr:  domain.ErrSSOEmailNotAllowed,
+			reason:   "email_not_allowed",
+		},
+		{
+			name:     "owner",
+			identity: oidctest.Identity{Subject: "idp-owner", Email: "owner@casa-ana.com", EmailVerified: true},
+			wantErr:  domain.ErrSSORoleNotAllowed,
+			reason:   "role_not_allowed",
+		},
+		{
+			name: "configured by an admin, for a fellow admin",
+			setup: func(t *testing.T, env *ssoTestEnv) {
+				admin := env.addMember(t, "admin@casa-ana.com", env.tenant.ID, domain.RoleAdmin)
+				env.addMember(t, "bea@casa-ana.com", env.tenant.ID, domain.RoleAdmin)
+				cfg, _ := env.configs.FindByTenant(context.Background(), env.tenant.ID)
+				cfg.UpdatedBy = &admin.ID
+			},
+			identity: oidctest.Identity{Subject: "idp-bea", Email: "bea@casa-ana.com", EmailVerified: true},
+			wantErr:  domain.ErrSSORoleNotAllowed,
+			reason:   "role_not_allowed",
+		},
+		{
+			name: "configured by someone no longer in the tenant",
+			setup: func(t *testing.T, env *ssoTestEnv) {
+				cfg, _ := env.configs.FindByTenant(context.Background(), env.tenant.ID)
+				gone := uuid.New()
+				cfg.UpdatedBy = &gone
+			},
+			identity: oidctest.Identity{Subject: "idp-ana", Email: "ana@casa-ana.com", EmailVerified: true},
+			wantErr:  domain.ErrSSORoleNotAllowed,
+			reason:   "role_not_allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupSSOService(t)
+			if tt.setup != nil {
+				tt.setup(t, env)
+			}
+			identitiesBefore := len(env.identities.Identities())
+
+			_, err := env.signIn(t, tt.identity)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteLogin error = %v, want %v", err, tt.wantErr)
+			}
+			if !env.hasEventReason(domain.EventLoginFailed, tt.reason) {
+				t.Errorf("expected a login_failed event with reason %s", tt.reason)
+			}
+			if len(env.identities.Identities()) != identitiesBefore {
+				t.Error("a rejected login must not link an identity")
+			}
+		})
+	}
+}
+
+func TestAuthService_Login_PasswordKeptForRolesSSOCannotSignIn(t *testing.T) {
+	env := setupSSOService(t)
+	ctx := context.Background()
+	admin := env.addMember(t, "admin@casa-ana.com", env.tenant.ID, domain.RoleAdmin)
+	otherAdmin := env.addMember(t, "bea@casa-ana.com", env.tenant.ID, domain.RoleAdmin)
+	cfg, _ := env.configs.FindByTenant(ctx, env.tenant.ID)
+	cfg.UpdatedBy = &admin.ID
+	cfg.PasswordLoginDisabled = true
+
+	// An admin's identity provider can't sign in another admin, so they
+	// keep their password; the waiter it can sign in doesn't
+	if _, err := env.authSvc.Login(ctx, LoginRequest{Email: otherAdmin.Email, Password: "Password123!"}); err != nil {
+		t.Errorf("admin Login failed: %v", err)
+	}
+	if _, err := env.authSvc.Login(ctx, LoginRequest{Email: env.waiter.Email, Password: "Password123!"}); !errors.Is(err, domain.ErrPasswordLoginDisabled) {
+		t.Errorf("waiter Login error = %v, want ErrPasswordLoginDisabled", err)
+	}
+}
+
+func TestSSOService_CompleteLogin_State(t *testing.T) {
+	env := setupSSOService(t)
+	ctx := context.Background()
+	env.idp.SetIdentity(oidctest.Identity{Subject: "idp-ana", Email: "ana@casa-ana.com", EmailVerified: true})
+
+	if _, err := env.svc.CompleteLogin(ctx, CompleteSSOLoginRequest{State: "forged", Code: "code"}); !errors.Is(err, domain.ErrSSOStateInvalid) {
+		t.Errorf("unknown state: error = %v, want ErrSSOStateInvalid", err)
+	}
+
+	authURL, _ := env.svc.StartLogin(ctx, env.tenant.Slug)
+	callback, err := env.idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+	req := CompleteSSOLoginRequest{State: callback.Query().Get("state"), Code: callback.Query().Get("code")}
+	if _, err := env.svc.CompleteLogin(ctx, req); err != nil {
+		t.Fatalf("CompleteLogin failed: %v", err)
+	}
+	if _, err := env.svc.CompleteLogin(ctx, req); !errors.Is(err, domain.ErrSSOStateInvalid) {
+		t.Errorf("replayed state: error = %v, want ErrSSOStateInvalid", err)
+	}
+
+	authURL, _ = env.svc.StartLogin(ctx, env.tenant.Slug)
+	callback, _ = env.idp.Authorize(authURL)
+	for _, s := range env.states.States() {
+		s.ExpiresAt = time.Now().Add(-time.Second)
+	}
+	req = CompleteSSOLoginRequest{State: callback.Query().Get("state"), Code: callback.Query().Get("code")}
+	if _, err := env.svc.CompleteLogin(ctx, req); !errors.Is(err, domain.ErrSSOStateInvalid) {
+		t.Errorf("expired state: error = %v, want ErrSSOStateInvalid", err)
+	}
+
+	// A code is only good with the verifier of the login that requested it
+	authURL, _ = env.svc.StartLogin(ctx, env.tenant.Slug)
+	stolen, _ := env.idp.Authorize(authURL)
+	authURL, _ = env.svc.StartLogin(ctx, env.tenant.Slug)
+	mine, _ := env.idp.Authorize(authURL)
+	req = CompleteSSOLoginRequest{State: mine.Query().Get("state"), Code: stolen.Query().Get("code")}
+	if _, err := env.svc.CompleteLogin(ctx, req); !errors.Is(err, domain.ErrSSOTokenInvalid) {
+		t.Errorf("code from another login: error = %v, want ErrSSOTokenInvalid", err)
+	}
+	if !env.hasEventReason(domain.EventLoginFailed, "code_exchange_failed") {
+		t.Error("expected a login_failed event for the failed exchange")
+	}
+}
+
+func TestSSOService_StartLogin(t *testing.T) {
+	env := setupSSOService(t)
+	ctx := context.Background()
+
+	authURL, err := env.svc.StartLogin(ctx, env.tenant.Slug)
+	if err != nil {
+		t.Fatalf("StartLogin failed: %v", err)
+	}
+	states := env.states.States()
+	if len(states) != 1 || states[0].TenantID != env.tenant.ID || states[0].Nonce == "" || states[0].CodeVerifier == "" {
+		t.Fatalf("states = %+v, want one pending state for the tenant", states)
+	}
+	callback, err := env.idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+	if callback.Scheme+"://"+callback.Host+callback.Path != testSSORedirectURL {
+		t.Errorf("callback = %s, want the configured redirect URL", callback)
+	}
+	if callback.Query().Get("state") == states[0].StateHash {
+		t.Error("the state parameter should be stored hashed")
+	}
+
+	if _, err := env.svc.StartLogin(ctx, "no-such-tenant"); !errors.Is(err, domain.ErrSSONotConfigured) {
+		t.Errorf("unknown tenant: error = %v, want ErrSSONotConfigured", err)
+	}
+	other := &domain.Tenant{ID: uuid.New(), Slug: "no-sso", IsActive: true}
+	env.tenantRepo.AddTenant(other)
+	if _, err := env.svc.StartLogin(ctx, "no-sso"); !errors.Is(err, domain.ErrSSONotConfigured) {
+		t.Errorf("tenant without SSO: error = %v, want ErrSSONotConfigured", err)
+	}
+	env.tenant.IsActive = false
+	if _, err := env.svc.StartLogin(ctx, env.tenant.Slug); !errors.Is(err, domain.ErrTenantInactive) {
+		t.Errorf("inactive tenant: error = %v, want ErrTenantInactive", err)
+	}
+}
+
+func TestSSOService_SaveConfig(t *testing.T) {
+	env := setupSSOService(t)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	adminID := uuid.New()
+
+	req := SaveSSOConfigRequest{
+		TenantID:              tenantID,
+		UpdatedBy:             adminID,
+		Issuer:                env.idp.Issuer(),
+		ClientID:              "erp-client",
+		ClientSecret:          "erp-secret",
+		AllowedDomains:        []string{"@Example.com"},
+		PasswordLoginDisabled: true,
+	}
+	cfg, err := env.svc.SaveConfig(ctx, req)
+	if err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+	if cfg.Issuer != env.idp.Issuer() || cfg.ClientSecret != "erp-secret" || !cfg.PasswordLoginDisabled || cfg.AllowedDomains[0] != "example.com" {
+		t.Errorf("saved config = %+v", cfg)
+	}
+	if !hasEventType(env.eventRepo, domain.EventSSOConfigUpdated) {
+		t.Error("expected an sso_config_updated event")
+	}
+
+	// Updating without a secret keeps the current one
+	req.ClientSecret = ""
+	req.PasswordLoginDisabled = false
+	if cfg, err = env.svc.SaveConfig(ctx, req); err != nil {
+		t.Fatalf("SaveConfig update failed: %v", err)
+	}
+	if cfg.ClientSecret != "erp-secret" || cfg.PasswordLoginDisabled {
+		t.Errorf("updated config = %+v, want the secret kept and password login on", cfg)
+	}
+
+	invalid := []struct {
+		name    string
+		modify  func(r *SaveSSOConfigRequest)
+		wantErr error
+	}{
+		{"http issuer", func(r *SaveSSOConfigRequest) { r.Issuer = "http://idp.example.com" }, domain.ErrSSOConfigInvalid},
+		{"no client id", func(r *SaveSSOConfigRequest) { r.ClientID = " " }, domain.ErrSSOConfigInvalid},
+		{"no domains", func(r *SaveSSOConfigRequest) { r.AllowedDomains = nil }, domain.ErrSSOConfigInvalid},
+		{"no secret on create", func(r *SaveSSOConfigRequest) { r.TenantID = uuid.New() }, domain.ErrSSOConfigInvalid},
+		{"unreachable issuer", func(r *SaveSSOConfigRequest) { r.Issuer = "https://127.0.0.1:1" }, domain.ErrSSOProviderUnavailable},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			r := req
+			tt.modify(&r)
+			if _, err := env.svc.SaveConfig(ctx, r); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SaveConfig error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := env.svc.DeleteConfig(ctx, tenantID, adminID, "", ""); err != nil {
+		t.Fatalf("DeleteConfig failed: %v", err)
+	}
+	if err := env.svc.DeleteConfig(ctx, tenantID, adminID, "", ""); !errors.Is(err, domain.ErrSSONotConfigured) {
+		t.Errorf("second DeleteConfig error = %v, want ErrSSONotConfigured", err)
+	}
+}
+
+func TestAuthService_Login_PasswordLoginDisabled(t *testing.T) {
+	env := setupSSOService(t)
+	ctx := context.Background()
+
+	cfg, _ := env.configs.FindByTenant(ctx, env.tenant.ID)
+	cfg.PasswordLoginDisabled = true
+
+	if _, err := env.authSvc.Login(ctx, LoginRequest{Email: env.waiter.Email, Password: "Password123!"}); !errors.Is(err, domain.ErrPasswordLoginDisabled) {
+		t.Errorf("waiter Login error = %v, want ErrPasswordLoginDisabled", err)
+	}
+	if !env.hasEventReason(domain.EventLoginFailed, "password_login_disabled") {
+		t.Error("expected a login_failed event with reason password_login_disabled")
+	}
+
+	// Owners keep password login as a break-glass path
+	if _, err := env.authSvc.Login(ctx, LoginRequest{Email: env.owner.Email, Password: "Password123!"}); err != nil {
+		t.Errorf("owner Login failed: %v", err)
+	}
+
+	// SSO still works for the members it signs in
+	if _, err := env.signIn(t, oidctest.Identity{Subject: "idp-ana", Email: "ana@casa-ana.com", EmailVerified: true}); err != nil {
+		t.Errorf("SSO login failed: %v", err)
+	}
+}
+
+func TestAuthService_SwitchTenant_PasswordLoginDisabled(t *testing.T) {
+	authSvc, userRepo, _, tenantRepo, _ := setupAuthService(t)
+	configs := mock.NewMockSSOConfigRepository()
+	authSvc.ssoConfigs = configs
+	ctx := context.Background()
+
+	login, _, to := loginMultiTenant(t, authSvc, userRepo, tenantRepo)
+	ownerID := uuid.New()
+	userRepo.AddUser(&domain.User{ID: ownerID, Email: "owner@example.com", IsActive: true,
+		TenantRoles: []domain.UserTenantRole{{UserID: ownerID, TenantID: to.ID, Role: domain.RoleOwner}}})
+	configs.AddConfig(&domain.TenantSSOConfig{TenantID: to.ID, PasswordLoginDisabled: true, UpdatedBy: &ownerID})
+
+	_, err := authSvc.SwitchTenant(ctx, SwitchTenantRequest{
+		UserID:    login.User.ID,
+		SessionID: sessionIDFromToken(t, authSvc, login.TokenPair.AccessToken),
+		TenantID:  to.ID,
+	})
+	if !errors.Is(err, domain.ErrPasswordLoginDisabled) {
+		t.Errorf("SwitchTenant error = %v, want ErrPasswordLoginDisabled", err)
+	}
+}
+
+func TestAuthService_SwitchTenant_FromSSO(t *testing.T) {
+	env := setupSSOService(t)
+	ctx := context.Background()
+
+	// The waiter is also a manager in a second tenant
+	other := &domain.Tenant{ID: uuid.New(), Name: "Casa Bea", Slug: "casa-bea", IsActive: true}
+	env.tenantRepo.AddTenant(other)
+	env.waiter.TenantRoles = append(env.waiter.TenantRoles, domain.UserTenantRole{UserID: env.waiter.ID, TenantID: other.ID, Role: domain.RoleManager})
+	otherOwner := env.addMember(t, "owner@casa-bea.com", other.ID, domain.RoleOwner)
+
+	login, err := env.signIn(t, oidctest.Identity{Subject: "idp-ana", Email: "ana@casa-ana.com", EmailVerified: true})
+	if err != nil {
+		t.Fatalf("CompleteLogin failed: %v", err)
+	}
+	sessionID := sessionIDFromToken(t, env.authSvc, login.TokenPair.AccessToken)
+	session, _ := env.authSvc.sessionRepo.FindByID(ctx, sessionID)
+	if session.SignInMethod != domain.SignInSSO {
+		t.Fatalf("SignInMethod = %q, want sso", session.SignInMethod)
+	}
+	switchReq := SwitchTenantRequest{UserID: env.waiter.ID, SessionID: sessionID, TenantID: other.ID}
+
+	// The identity provider only vouched for the waiter to the first tenant
+	if _, err := env.authSvc.SwitchTenant(ctx, switchReq); err != domain.ErrSignInRequired {
+		t.Fatalf("SwitchTenant without SSO in the target error = %v, want ErrSignInRequired", err)
+	}
+
+	// With MFA they can prove who they are with a fresh challenge instead
+	mfaSvc := NewMFAService(MFAServiceConfig{
+		MFARepo:       mock.NewMockMFARepository(),
+		ChallengeRepo: mock.NewMockMFAChallengeRepository(),
+		EventRepo:     env.eventRepo,
+	})
+	env.authSvc.mfaService = mfaSvc
+	enrollUser(t, mfaSvc, env.waiter.ID)
+	resp, err := env.authSvc.SwitchTenant(ctx, switchReq)
+	if err != domain.ErrMFARequired {
+		t.Fatalf("SwitchTenant with MFA error = %v, want ErrMFARequired", err)
+	}
+	if resp.MFAToken == "" || resp.TokenPair != nil {
+		t.Errorf("SwitchTenant response = %+v, want only an MFA challenge", resp)
+	}
+	if session, _ := env.authSvc.sessionRepo.FindByID(ctx, sessionID); !session.IsValid() {
+		t.Error("the SSO session should stay valid until the switch completes")
+	}
+	env.authSvc.mfaService = nil
+
+	// A tenant with the same identity provider accepts the login as is,
+	// as long as its own config would have signed the waiter in
+	otherCfg := &domain.TenantSSOConfig{
+		TenantID:       other.ID,
+		Issuer:         env.idp.Issuer(),
+		ClientID:       "erp-client",
+		ClientSecret:   "erp-secret",
+		AllowedDomains: domain.DomainList{"casa-bea.com"},
+		UpdatedBy:      &otherOwner.ID,
+	}
+	env.configs.AddConfig(otherCfg)
+	if _, err := env.authSvc.SwitchTenant(ctx, switchReq); err != domain.ErrSignInRequired {
+		t.Fatalf("SwitchTenant to a tenant not allowing the email error = %v, want ErrSignInRequired", err)
+	}
+	otherCfg.AllowedDomains = domain.DomainList{"casa-ana.com"}
+	switched, err := env.authSvc.SwitchTenant(ctx, switchReq)
+	if err != nil {
+		t.Fatalf("SwitchTenant to a tenant with the same identity provider failed: %v", err)
+	}
+	if switched.TenantID != other.ID || switched.Role != domain.RoleManager {
+		t.Errorf("switched to %s as %s, want %s as manager", switched.TenantID, switched.Role, other.ID)
+	}
+}
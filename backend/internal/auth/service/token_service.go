@@ -10,6 +10,7 @@ import (
 
 // TokenService provides token generation and validation using domain types.
 type TokenService struct {
+	keyManager      *jwt.KeyManager
 	generator       *jwt.TokenGenerator
 	validator       *jwt.TokenValidator
 	passwordService *PasswordService
@@ -17,17 +18,24 @@ type TokenService struct {
 
 // NewTokenService creates a new TokenService.
 func NewTokenService(keyManager *jwt.KeyManager, cfg jwt.TokenGeneratorConfig) *TokenService {
+	validator := jwt.NewTokenValidator(keyManager, cfg.Issuer, cfg.Audience)
+	if len(cfg.AllowedAlgorithms) > 0 {
+		validator.SetAllowedAlgorithms(cfg.AllowedAlgorithms)
+	}
+
 	return &TokenService{
+		keyManager:      keyManager,
 		generator:       jwt.NewTokenGenerator(keyManager, cfg),
-		validator:       jwt.NewTokenValidator(keyManager, cfg.Issuer, cfg.Audience),
+		validator:       validator,
 		passwordService: NewPasswordService(),
 	}
 }
 
-// GenerateTokenPair generates a new access and refresh token pair.
-func (s *TokenService) GenerateTokenPair(user *domain.User, tenantID uuid.UUID, role domain.Role) (*domain.TokenPair, string, error) {
+// GenerateTokenPair generates a new access and refresh token pair for the
+// given session. permissions is the user's permission set in the tenant.
+func (s *TokenService) GenerateTokenPair(user *domain.User, sessionID, tenantID uuid.UUID, role domain.Role, permissions []domain.Permission) (*domain.TokenPair, string, error) {
 	// Generate access token
-	accessToken, expiresAt, err := s.generator.GenerateAccessToken(user.ID, tenantID, user.Email, string(role))
+	accessToken, expiresAt, err := s.generator.GenerateAccessToken(user.ID, tenantID, sessionID, user.Email, string(role), permissionClaim(permissions))
 	if err != nil {
 		return nil, "", err
 	}
@@ -43,6 +51,36 @@ func (s *TokenService) GenerateTokenPair(user *domain.User, tenantID uuid.UUID,
 	return tokenPair, refreshTokenHash, nil
 }
 
+// GenerateAccessToken generates a standalone access token that expires after
+// ttl, with no refresh token or session behind it.
+func (s *TokenService) GenerateAccessToken(user *domain.User, tenantID uuid.UUID, role domain.Role, permissions []domain.Permission, ttl time.Duration) (*domain.TokenPair, error) {
+	accessToken, expiresAt, err := s.generator.GenerateAccessTokenWithTTL(user.ID, tenantID, uuid.Nil, user.Email, string(role), permissionClaim(permissions), ttl)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewTokenPair(accessToken, "", expiresAt), nil
+}
+
+// GenerateImpersonationToken generates a standalone access token for user
+// that actorID uses to act as them, carrying actorID in the act claim. Like
+// GenerateAccessToken it has no refresh token or session behind it.
+func (s *TokenService) GenerateImpersonationToken(user *domain.User, tenantID, actorID uuid.UUID, role domain.Role, permissions []domain.Permission, ttl time.Duration) (*domain.TokenPair, error) {
+	accessToken, expiresAt, err := s.generator.GenerateImpersonationToken(user.ID, tenantID, actorID, user.Email, string(role), permissionClaim(permissions), ttl)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewTokenPair(accessToken, "", expiresAt), nil
+}
+
+// GenerateClientAccessToken generates an access token for an OAuth client,
+// carrying clientID in the client_id claim. subject is the user who
+// authorized the client, or the client itself under the client credentials
+// grant. The token holds OAuthClientRole and has no refresh token or
+// session behind it; OAuth refresh tokens are issued by OAuthService.
+func (s *TokenService) GenerateClientAccessToken(subject, tenantID, clientID uuid.UUID, permissions []domain.Permission) (string, time.Time, error) {
+	return s.generator.GenerateClientAccessToken(subject, tenantID, clientID.String(), string(domain.OAuthClientRole), permissionClaim(permissions))
+}
+
 // ValidateAccessToken validates an access token and returns the claims.
 func (s *TokenService) ValidateAccessToken(tokenString string) (*domain.Claims, error) {
 	jwtClaims, err := s.validator.ValidateToken(tokenString)
@@ -65,11 +103,37 @@ func (s *TokenService) ValidateAccessToken(tokenString string) (*domain.Claims,
 		TenantID:         jwtClaims.TenantID,
 		Role:             domain.Role(jwtClaims.Role),
 		Email:            jwtClaims.Email,
+		SessionID:        jwtClaims.SessionID,
+		ClientID:         jwtClaims.ClientID,
+	}
+	if jwtClaims.Actor != nil {
+		claims.Actor = &domain.Actor{Subject: jwtClaims.Actor.Subject}
+	}
+	if jwtClaims.Permissions != nil {
+		claims.Permissions = make([]domain.Permission, len(jwtClaims.Permissions))
+		for i, p := range jwtClaims.Permissions {
+			claims.Permissions[i] = domain.Permission(p)
+		}
 	}
 
 	return claims, nil
 }
 
+// permissionClaim returns the perms claim for a permission set.
+func permissionClaim(perms []domain.Permission) []string {
+	claim := make([]string, len(perms))
+	for i, p := range perms {
+		claim[i] = string(p)
+	}
+	return claim
+}
+
+// JWKS returns the public keys that verify access tokens, for publishing at
+// /.well-known/jwks.json.
+func (s *TokenService) JWKS() jwt.JWKS {
+	return s.keyManager.JWKS()
+}
+
 // HashRefreshToken hashes a plain refresh token for comparison.
 func (s *TokenService) HashRefreshToken(plainToken string) string {
 	return s.passwordService.HashRefreshToken(plainToken)
@@ -1,7 +1,15 @@
 package service
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"slices"
 	"testing"
+
+	"github.com/google/uuid"
+	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/pkg/jwt"
 )
 
 func TestTokenService_HashRefreshToken(t *testing.T) {
@@ -35,3 +43,68 @@ func TestTokenService_HashRefreshToken(t *testing.T) {
 		t.Error("Hash should not equal original token")
 	}
 }
+
+func TestTokenService_AllowedAlgorithms(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	km := jwt.NewKeyManager()
+	km.AddKey("ec-key", ecKey)
+	km.Activate("ec-key", 0)
+
+	user := &domain.User{ID: uuid.New(), Email: "user@example.com"}
+	cfg := jwt.DefaultTokenGeneratorConfig()
+
+	pair, _, err := NewTokenService(km, cfg).GenerateTokenPair(user, uuid.New(), uuid.New(), domain.RoleCashier, domain.RoleCashier.Permissions())
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+	if _, err := NewTokenService(km, cfg).ValidateAccessToken(pair.AccessToken); err != nil {
+		t.Errorf("ES256 token should validate by default: %v", err)
+	}
+
+	cfg.AllowedAlgorithms = []string{jwt.AlgRS256}
+	if _, err := NewTokenService(km, cfg).ValidateAccessToken(pair.AccessToken); err != domain.ErrTokenInvalid {
+		t.Errorf("expected ErrTokenInvalid for a disallowed algorithm, got %v", err)
+	}
+}
+
+func TestTokenService_PermissionsClaim(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	km := jwt.NewKeyManager()
+	km.AddKey("ec-key", ecKey)
+	km.Activate("ec-key", 0)
+	svc := NewTokenService(km, jwt.DefaultTokenGeneratorConfig())
+
+	user := &domain.User{ID: uuid.New(), Email: "user@example.com"}
+	pair, _, err := svc.GenerateTokenPair(user, uuid.New(), uuid.New(), domain.RoleManager, domain.RoleManager.Permissions())
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+
+	claims, err := svc.ValidateAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+	if !slices.Equal(claims.Permissions, domain.RoleManager.Permissions()) {
+		t.Errorf("perms claim = %v, want %v", claims.Permissions, domain.RoleManager.Permissions())
+	}
+
+	// A custom role granting nothing must not fall back to its base role's
+	// defaults
+	pair, _, err = svc.GenerateTokenPair(user, uuid.New(), uuid.New(), domain.RoleManager, []domain.Permission{})
+	if err != nil {
+		t.Fatalf("GenerateTokenPair failed: %v", err)
+	}
+	claims, err = svc.ValidateAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken failed: %v", err)
+	}
+	if perms := claims.GetPermissions(); len(perms) != 0 {
+		t.Errorf("GetPermissions() with empty claim = %v, want none", perms)
+	}
+}
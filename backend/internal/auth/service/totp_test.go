@@ -0,0 +1,107 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 test key from RFC 6238 Appendix B ("12345678901234567890").
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 Appendix B SHA1 values, truncated to the 6 digits we issue.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got := totpCode(rfc6238Secret, totpStep(time.Unix(tt.unix, 0)))
+		if got != tt.want {
+			t.Errorf("totpCode(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := totpEncoding.EncodeToString(rfc6238Secret)
+	now := time.Unix(1111111111, 0)
+	step := totpStep(now)
+
+	tests := []struct {
+		name     string
+		code     string
+		lastUsed int64
+		wantOK   bool
+		wantStep int64
+	}{
+		{"current step", totpCode(rfc6238Secret, step), 0, true, step},
+		{"previous step within skew", totpCode(rfc6238Secret, step-1), 0, true, step - 1},
+		{"next step within skew", totpCode(rfc6238Secret, step+1), 0, true, step + 1},
+		{"outside skew", totpCode(rfc6238Secret, step-2), 0, false, 0},
+		{"replayed step", totpCode(rfc6238Secret, step), step, false, 0},
+		{"older than last used", totpCode(rfc6238Secret, step-1), step - 1, false, 0},
+		{"surrounding whitespace", " " + totpCode(rfc6238Secret, step) + " ", 0, true, step},
+		{"wrong length", "12345", 0, false, 0},
+		{"wrong code", "000000", 0, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := validateTOTP(secret, tt.code, now, tt.lastUsed)
+			if ok != tt.wantOK {
+				t.Fatalf("validateTOTP() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if gotStep != tt.wantStep {
+				t.Errorf("validateTOTP() step = %d, want %d", gotStep, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestValidateTOTP_InvalidSecret(t *testing.T) {
+	if _, ok := validateTOTP("not base32!", "123456", time.Now(), 0); ok {
+		t.Error("validateTOTP should reject an undecodable secret")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatalf("generateTOTPSecret failed: %v", err)
+	}
+
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret is not valid base32: %v", err)
+	}
+	if len(key) != totpSecretSize {
+		t.Errorf("len(key) = %d, want %d", len(key), totpSecretSize)
+	}
+
+	other, _ := generateTOTPSecret()
+	if secret == other {
+		t.Error("generated secrets should be unique")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := totpURI("Solobueno ERP", "owner@example.com", "JBSWY3DPEHPK3PXP")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/Solobueno%20ERP:owner@example.com?") {
+		t.Errorf("unexpected URI prefix: %s", uri)
+	}
+	for _, want := range []string{"secret=JBSWY3DPEHPK3PXP", "issuer=Solobueno+ERP", "digits=6", "period=30", "algorithm=SHA1"} {
+		if !strings.Contains(uri, want) {
+			t.Errorf("URI %s missing %s", uri, want)
+		}
+	}
+}
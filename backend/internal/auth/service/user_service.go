@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/mail"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/solobueno/erp/internal/auth/domain"
 	"github.com/solobueno/erp/internal/auth/repository"
+	"github.com/solobueno/erp/pkg/jwt"
 )
 
 // UserService handles user management operations.
@@ -19,9 +22,16 @@ type UserService struct {
 	sessionRepo      repository.SessionRepository
 	eventRepo        repository.AuthEventRepository
 	passwordReset    repository.PasswordResetRepository
+	emailTokens      repository.EmailTokenRepository
+	passwordPolicies *PasswordPolicyService
+	invitations      repository.InvitationRepository
+	transfers        repository.OwnershipTransferRepository
+	customRoles      repository.CustomRoleRepository
 	passwordSvc      *PasswordService
 	resetRateLimiter RateLimiter
 	emailer          Emailer
+	revocations      repository.TokenRevocationStore
+	accessTokenTTL   time.Duration
 }
 
 // UserServiceConfig holds configuration for UserService.
@@ -31,57 +41,98 @@ type UserServiceConfig struct {
 	SessionRepo      repository.SessionRepository
 	EventRepo        repository.AuthEventRepository
 	PasswordReset    repository.PasswordResetRepository
+	Invitations      repository.InvitationRepository
+	Transfers        repository.OwnershipTransferRepository
 	ResetRateLimiter RateLimiter
-	// Emailer sends temp-password/tenant-link notifications. Defaults to a
+	// Emailer sends invitation, ownership transfer, password reset and email
+	// verification emails. Defaults to a
 	// logging stub (LogEmailer) if not provided.
 	Emailer Emailer
+	// RevocationStore revokes a user's access tokens on password change,
+	// deactivation and role change. If nil, they stay valid until they expire.
+	RevocationStore repository.TokenRevocationStore
+	// AccessTokenTTL is how long revocations are kept. Defaults to the
+	// jwt package's default access token TTL.
+	AccessTokenTTL time.Duration
+	// CustomRoles looks up tenant custom roles assigned through UpdateRole.
+	// If nil, only built-in roles can be assigned.
+	CustomRoles repository.CustomRoleRepository
+	// EmailTokens stores the emailed links that verify a user's email and
+	// change or restore it.
+	EmailTokens repository.EmailTokenRepository
+	// PasswordPolicies checks new passwords against the tenants' policies,
+	// the user's password history and the breached-password list. Defaults
+	// to the default policy and the bundled breached-password list.
+	PasswordPolicies *PasswordPolicyService
+	// Passwords hashes and verifies passwords. Defaults to
+	// NewPasswordService(), without a pepper.
+	Passwords *PasswordService
 }
 
 // NewUserService creates a new UserService.
 func NewUserService(cfg UserServiceConfig) *UserService {
+	passwordSvc := cfg.Passwords
+	if passwordSvc == nil {
+		passwordSvc = NewPasswordService()
+	}
 	emailer := cfg.Emailer
 	if emailer == nil {
 		emailer = NewLogEmailer()
 	}
+	accessTokenTTL := cfg.AccessTokenTTL
+	if accessTokenTTL == 0 {
+		accessTokenTTL = jwt.DefaultTokenGeneratorConfig().AccessTokenTTL
+	}
+	passwordPolicies := cfg.PasswordPolicies
+	if passwordPolicies == nil {
+		passwordPolicies = NewPasswordPolicyService(PasswordPolicyServiceConfig{EventRepo: cfg.EventRepo, Passwords: passwordSvc})
+	}
 	return &UserService{
 		userRepo:         cfg.UserRepo,
 		roleRepo:         cfg.RoleRepo,
 		sessionRepo:      cfg.SessionRepo,
 		eventRepo:        cfg.EventRepo,
 		passwordReset:    cfg.PasswordReset,
-		passwordSvc:      NewPasswordService(),
+		emailTokens:      cfg.EmailTokens,
+		passwordPolicies: passwordPolicies,
+		invitations:      cfg.Invitations,
+		transfers:        cfg.Transfers,
+		customRoles:      cfg.CustomRoles,
+		passwordSvc:      passwordSvc,
 		resetRateLimiter: cfg.ResetRateLimiter,
 		emailer:          emailer,
+		revocations:      cfg.RevocationStore,
+		accessTokenTTL:   accessTokenTTL,
 	}
 }
 
-// CreateUserRequest contains the data needed to create a user.
-type CreateUserRequest struct {
+// InviteRequest contains the data needed to invite someone to a tenant.
+type InviteRequest struct {
 	Email     string
 	FirstName string
 	LastName  string
 	TenantID  uuid.UUID
 	Role      domain.Role
-	CreatedBy uuid.UUID // User performing the creation
+	InvitedBy uuid.UUID // User sending the invitation
 	IPAddress string
 }
 
-// CreateUserResponse contains the result of user creation.
-type CreateUserResponse struct {
-	User *domain.User
-	// TemporaryPassword is set only for a brand-new account; it is emailed to
-	// the user and must never be surfaced in an API response.
-	TemporaryPassword string
-	// LinkedExistingAccount is true when the email already had a global
-	// account and this call linked a new tenant role to it instead of
-	// creating a duplicate account.
-	LinkedExistingAccount bool
+// InviteResponse contains the result of sending or resending an invitation.
+type InviteResponse struct {
+	Invitation *domain.Invitation
+	// Token is the plaintext invite token; it is emailed to the invitee and
+	// must never be surfaced in an API response.
+	Token string
+	// ExistingAccount is true when the email already has a global account;
+	// the invitee confirms that account to join instead of setting a password.
+	ExistingAccount bool
 }
 
-// Create creates a new user with a temporary password, or, if the email
-// already has a global account in a different tenant, links a new tenant
-// role to that existing user instead of creating a duplicate.
-func (s *UserService) Create(ctx context.Context, req CreateUserRequest, callerRole domain.Role) (*CreateUserResponse, error) {
+// Invite invites someone to join a tenant with a role. Nothing is created
+// until the invitee accepts: a new user sets their own password, and an
+// existing user (per FR-012, email is globally unique) confirms their
+// account to join the tenant rather than being linked silently.
+func (s *UserService) Invite(ctx context.Context, req InviteRequest, callerRole domain.Role) (*InviteResponse, error) {
 	// Check if caller can assign this role
 	if !callerRole.CanAssign(req.Role) {
 		return nil, domain.ErrCannotAssignRole
@@ -89,102 +140,301 @@ func (s *UserService) Create(ctx context.Context, req CreateUserRequest, callerR
 
 	existing, err := s.userRepo.FindByEmailWithTenants(ctx, req.Email)
 	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
-		return nil, fmt.Errorf("create user: existing-user lookup: %w", err)
+		return nil, fmt.Errorf("invite: existing-user lookup: %w", err)
+	}
+	if existing != nil && existing.HasTenant(req.TenantID) {
+		return nil, domain.ErrEmailExists
 	}
-	if err == nil {
-		return s.linkExistingUserToTenant(ctx, existing, req)
+
+	// One outstanding invitation per email per tenant; an expired one is
+	// replaced rather than making the manager revoke it first.
+	pending, err := s.invitations.FindPending(ctx, req.TenantID, req.Email)
+	switch {
+	case err == nil && !pending.IsExpired():
+		return nil, domain.ErrInvitationPending
+	case err == nil:
+		if err := s.invitations.Revoke(ctx, pending.ID); err != nil {
+			return nil, fmt.Errorf("invite: revoke expired invitation: %w", err)
+		}
+	case !errors.Is(err, domain.ErrInvitationNotFound):
+		return nil, fmt.Errorf("invite: pending lookup: %w", err)
 	}
 
-	// Generate temporary password
-	tempPassword, err := s.passwordSvc.GenerateTemporaryPassword()
+	plainToken, tokenHash, err := s.passwordSvc.GenerateResetToken()
 	if err != nil {
-		return nil, fmt.Errorf("create user: generate temp password: %w", err)
+		return nil, fmt.Errorf("invite: generate token: %w", err)
 	}
 
-	// Hash the password
-	passwordHash, err := s.passwordSvc.Hash(tempPassword)
+	invitation := &domain.Invitation{
+		ID:        uuid.New(),
+		TenantID:  req.TenantID,
+		Email:     req.Email,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Role:      req.Role,
+		TokenHash: tokenHash,
+		InvitedBy: req.InvitedBy,
+		ExpiresAt: time.Now().Add(domain.InvitationTTL),
+	}
+
+	if err := s.invitations.Create(ctx, invitation); err != nil {
+		return nil, fmt.Errorf("invite: store invitation: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventInvitationSent, &req.InvitedBy, &req.TenantID, req.IPAddress, "", map[string]interface{}{
+		"invitation_id": invitation.ID,
+		"email":         invitation.Email,
+		"role":          invitation.Role,
+	})
+
+	s.sendInvitation(ctx, invitation, plainToken, existing != nil, req.IPAddress)
+
+	return &InviteResponse{
+		Invitation:      invitation,
+		Token:           plainToken,
+		ExistingAccount: existing != nil,
+	}, nil
+}
+
+// ListInvitations returns a tenant's outstanding invitations, including
+// expired ones that can still be resent.
+func (s *UserService) ListInvitations(ctx context.Context, tenantID uuid.UUID) ([]*domain.Invitation, error) {
+	return s.invitations.ListPendingByTenant(ctx, tenantID)
+}
+
+// InvitationActionRequest identifies an invitation a manager is resending
+// or revoking.
+type InvitationActionRequest struct {
+	InvitationID uuid.UUID
+	TenantID     uuid.UUID
+	PerformedBy  uuid.UUID
+	IPAddress    string
+}
+
+// ResendInvitation emails a fresh invite link and restarts the expiry. The
+// previous link stops working.
+func (s *UserService) ResendInvitation(ctx context.Context, req InvitationActionRequest, callerRole domain.Role) (*InviteResponse, error) {
+	invitation, err := s.findManageableInvitation(ctx, req.InvitationID, req.TenantID, callerRole)
 	if err != nil {
-		return nil, fmt.Errorf("create user: hash password: %w", err)
+		return nil, err
 	}
 
-	// Create user
-	user := &domain.User{
-		ID:           uuid.New(),
-		Email:        req.Email,
-		PasswordHash: passwordHash,
-		FirstName:    req.FirstName,
-		LastName:     req.LastName,
-		IsActive:     true,
-		MustResetPwd: true,
+	plainToken, tokenHash, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return nil, fmt.Errorf("resend invitation: generate token: %w", err)
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("create user: insert user: %w", err)
+	invitation.TokenHash = tokenHash
+	invitation.ExpiresAt = time.Now().Add(domain.InvitationTTL)
+
+	if err := s.invitations.Update(ctx, invitation); err != nil {
+		return nil, fmt.Errorf("resend invitation: save: %w", err)
 	}
 
-	// Create tenant role assignment
-	roleAssignment := &domain.UserTenantRole{
-		ID:       uuid.New(),
-		UserID:   user.ID,
-		TenantID: req.TenantID,
-		Role:     req.Role,
+	existing, err := s.userRepo.FindByEmail(ctx, invitation.Email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, fmt.Errorf("resend invitation: existing-user lookup: %w", err)
 	}
 
-	if err := s.roleRepo.Create(ctx, roleAssignment); err != nil {
-		// Rollback user creation would be ideal here with a transaction
-		return nil, fmt.Errorf("create user: insert role: %w", err)
+	s.logEvent(ctx, domain.EventInvitationResent, &req.PerformedBy, &req.TenantID, req.IPAddress, "", map[string]interface{}{
+		"invitation_id": invitation.ID,
+		"email":         invitation.Email,
+	})
+
+	s.sendInvitation(ctx, invitation, plainToken, existing != nil, req.IPAddress)
+
+	return &InviteResponse{
+		Invitation:      invitation,
+		Token:           plainToken,
+		ExistingAccount: existing != nil,
+	}, nil
+}
+
+// RevokeInvitation cancels an outstanding invitation so its link stops working.
+func (s *UserService) RevokeInvitation(ctx context.Context, req InvitationActionRequest, callerRole domain.Role) error {
+	invitation, err := s.findManageableInvitation(ctx, req.InvitationID, req.TenantID, callerRole)
+	if err != nil {
+		return err
 	}
 
-	// Log event
-	s.logEvent(ctx, domain.EventAccountCreated, &user.ID, &req.TenantID, req.IPAddress, "", map[string]interface{}{
-		"created_by": req.CreatedBy,
-		"role":       req.Role,
+	if err := s.invitations.Revoke(ctx, invitation.ID); err != nil {
+		return fmt.Errorf("revoke invitation: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventInvitationRevoked, &req.PerformedBy, &req.TenantID, req.IPAddress, "", map[string]interface{}{
+		"invitation_id": invitation.ID,
+		"email":         invitation.Email,
 	})
 
-	if err := s.emailer.SendTemporaryPassword(ctx, user.Email, tempPassword); err != nil {
-		s.logEmailFailure(ctx, "temporary_password", user.ID, &req.TenantID, req.IPAddress, err)
+	return nil
+}
+
+// findManageableInvitation loads an outstanding invitation in the tenant for
+// a role the caller is allowed to assign. Invitations in other tenants are
+// reported as not found.
+func (s *UserService) findManageableInvitation(ctx context.Context, id, tenantID uuid.UUID, callerRole domain.Role) (*domain.Invitation, error) {
+	invitation, err := s.invitations.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.TenantID != tenantID || !invitation.IsPending() {
+		return nil, domain.ErrInvitationNotFound
+	}
+	if !callerRole.CanAssign(invitation.Role) {
+		return nil, domain.ErrCannotAssignRole
 	}
+	return invitation, nil
+}
 
-	return &CreateUserResponse{
-		User:              user,
-		TemporaryPassword: tempPassword,
-	}, nil
+// AcceptInvitationRequest contains the data for accepting an invitation.
+type AcceptInvitationRequest struct {
+	Token string
+	// Password is the new account's password, or the existing account's
+	// current password when the invitee already has one.
+	Password  string
+	IPAddress string
 }
 
-// linkExistingUserToTenant links a new tenant role to a user who already has
-// a global account in a different tenant, per FR-012: email is globally
-// unique, so a duplicate account/password must never be created.
-func (s *UserService) linkExistingUserToTenant(ctx context.Context, existing *domain.User, req CreateUserRequest) (*CreateUserResponse, error) {
-	if existing.HasTenant(req.TenantID) {
-		return nil, domain.ErrEmailExists
+// AcceptInvitationResponse contains the result of accepting an invitation.
+type AcceptInvitationResponse struct {
+	User            *domain.User
+	TenantID        uuid.UUID
+	Role            domain.Role
+	ExistingAccount bool
+}
+
+// AcceptInvitation redeems an invite token. If the invited email has no
+// account yet, one is created with the password the invitee chose. If it
+// already has one, the invitee must confirm it with their current password
+// before the tenant role is added.
+func (s *UserService) AcceptInvitation(ctx context.Context, req AcceptInvitationRequest) (*AcceptInvitationResponse, error) {
+	invitation, err := s.invitations.FindByToken(ctx, s.passwordSvc.HashResetToken(req.Token))
+	if err != nil {
+		return nil, fmt.Errorf("accept invitation: token lookup: %w", err)
+	}
+
+	switch {
+	case invitation.IsAccepted():
+		return nil, domain.ErrInvitationAccepted
+	case invitation.IsRevoked():
+		return nil, domain.ErrInvitationRevoked
+	case invitation.IsExpired():
+		return nil, domain.ErrInvitationExpired
+	}
+
+	existing, err := s.userRepo.FindByEmailWithTenants(ctx, invitation.Email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, fmt.Errorf("accept invitation: existing-user lookup: %w", err)
+	}
+
+	var user *domain.User
+	if existing != nil {
+		if err := s.confirmExistingAccount(existing, req.Password); err != nil {
+			return nil, err
+		}
+		if existing.HasTenant(invitation.TenantID) {
+			return nil, domain.ErrEmailExists
+		}
+		user = existing
+	} else {
+		policy, err := s.passwordPolicies.PolicyForTenants(ctx, []uuid.UUID{invitation.TenantID})
+		if err != nil {
+			return nil, fmt.Errorf("accept invitation: %w", err)
+		}
+		if err := s.passwordPolicies.CheckPassword(ctx, nil, policy, req.Password); err != nil {
+			return nil, err
+		}
+		passwordHash, err := s.passwordSvc.Hash(req.Password)
+		if err != nil {
+			return nil, fmt.Errorf("accept invitation: hash password: %w", err)
+		}
+		passwordChangedAt := time.Now()
+		user = &domain.User{
+			ID:                uuid.New(),
+			Email:             invitation.Email,
+			PasswordHash:      passwordHash,
+			PasswordChangedAt: &passwordChangedAt,
+			FirstName:         invitation.FirstName,
+			LastName:          invitation.LastName,
+			IsActive:          true,
+		}
+	}
+
+	// The invite link was mailed to the address, which verifies it
+	now := time.Now()
+	verifyEmail := !user.IsEmailVerified()
+	if verifyEmail {
+		user.EmailVerifiedAt = &now
+	}
+
+	// Claim the invitation before writing, so a token raced by two
+	// requests only ever creates one account/role.
+	if err := s.invitations.MarkAccepted(ctx, invitation.ID); err != nil {
+		return nil, fmt.Errorf("accept invitation: mark accepted: %w", err)
+	}
+
+	if existing == nil {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return nil, fmt.Errorf("accept invitation: insert user: %w", err)
+		}
+	} else if verifyEmail {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, fmt.Errorf("accept invitation: verify email: %w", err)
+		}
 	}
 
 	roleAssignment := &domain.UserTenantRole{
 		ID:       uuid.New(),
-		UserID:   existing.ID,
-		TenantID: req.TenantID,
-		Role:     req.Role,
+		UserID:   user.ID,
+		TenantID: invitation.TenantID,
+		Role:     invitation.Role,
 	}
 
 	if err := s.roleRepo.Create(ctx, roleAssignment); err != nil {
-		return nil, fmt.Errorf("link existing user: insert role: %w", err)
+		// Rollback user creation would be ideal here with a transaction
+		return nil, fmt.Errorf("accept invitation: insert role: %w", err)
 	}
 
-	s.logEvent(ctx, domain.EventTenantRoleAdded, &existing.ID, &req.TenantID, req.IPAddress, "", map[string]interface{}{
-		"created_by": req.CreatedBy,
-		"role":       req.Role,
-	})
-
-	if err := s.emailer.SendTenantLinked(ctx, existing.Email, req.TenantID); err != nil {
-		s.logEmailFailure(ctx, "tenant_linked", existing.ID, &req.TenantID, req.IPAddress, err)
+	eventType := domain.EventAccountCreated
+	if existing != nil {
+		eventType = domain.EventTenantRoleAdded
 	}
+	s.logEvent(ctx, eventType, &user.ID, &invitation.TenantID, req.IPAddress, "", map[string]interface{}{
+		"created_by": invitation.InvitedBy,
+		"role":       invitation.Role,
+	})
+	s.logEvent(ctx, domain.EventInvitationAccepted, &user.ID, &invitation.TenantID, req.IPAddress, "", map[string]interface{}{
+		"invitation_id":    invitation.ID,
+		"existing_account": existing != nil,
+	})
 
-	return &CreateUserResponse{
-		User:                  existing,
-		LinkedExistingAccount: true,
+	return &AcceptInvitationResponse{
+		User:            user,
+		TenantID:        invitation.TenantID,
+		Role:            invitation.Role,
+		ExistingAccount: existing != nil,
 	}, nil
 }
 
+// confirmExistingAccount checks that the person accepting an invitation or
+// ownership transfer for an existing account can sign in to it.
+func (s *UserService) confirmExistingAccount(user *domain.User, password string) error {
+	if !user.CanLogin() {
+		return domain.ErrAccountDisabled
+	}
+	if user.IsLocked() {
+		return domain.ErrAccountLocked
+	}
+	match, err := s.passwordSvc.Verify(password, user.PasswordHash)
+	if err != nil {
+		return fmt.Errorf("confirm account: verify password: %w", err)
+	}
+	if !match {
+		return domain.ErrInvalidCredentials
+	}
+	return nil
+}
+
 // GetByID retrieves a user by ID.
 func (s *UserService) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 	return s.userRepo.FindByIDWithTenants(ctx, id)
@@ -221,6 +471,14 @@ func (s *UserService) Update(ctx context.Context, req UpdateRequest, callerRole
 	if req.LastName != nil {
 		user.LastName = *req.LastName
 	}
+	// A disabled owner can't run the tenant, so disabling the last active
+	// owner of any tenant is refused like removing them
+	if req.IsActive != nil && !*req.IsActive && user.IsActive {
+		if err := s.roleRepo.DeactivateUnlessLastOwner(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("update user: %w", err)
+		}
+	}
+
 	if req.IsActive != nil {
 		user.IsActive = *req.IsActive
 
@@ -228,8 +486,11 @@ func (s *UserService) Update(ctx context.Context, req UpdateRequest, callerRole
 		eventType := domain.EventAccountEnabled
 		if !*req.IsActive {
 			eventType = domain.EventAccountDisabled
-			// Revoke all sessions when disabling
+			// Revoke all sessions and access tokens when disabling
 			_ = s.sessionRepo.RevokeAllForUser(ctx, user.ID)
+			if err := revokeUserAccessTokens(ctx, s.revocations, user.ID, s.accessTokenTTL); err != nil {
+				return nil, fmt.Errorf("update user: revoke access tokens: %w", err)
+			}
 		}
 		s.logEvent(ctx, eventType, &user.ID, &req.TenantID, req.IPAddress, "", map[string]interface{}{
 			"updated_by": req.UpdatedBy,
@@ -245,15 +506,30 @@ func (s *UserService) Update(ctx context.Context, req UpdateRequest, callerRole
 
 // UpdateRoleRequest contains the data for updating a user's role.
 type UpdateRoleRequest struct {
-	UserID    uuid.UUID
-	TenantID  uuid.UUID
-	NewRole   domain.Role
-	UpdatedBy uuid.UUID
-	IPAddress string
+	UserID   uuid.UUID
+	TenantID uuid.UUID
+	NewRole  domain.Role
+	// CustomRoleID assigns one of the tenant's custom roles instead; NewRole
+	// is then taken from its base role.
+	CustomRoleID *uuid.UUID
+	UpdatedBy    uuid.UUID
+	IPAddress    string
 }
 
-// UpdateRole changes a user's role in a tenant.
+// UpdateRole changes a user's role in a tenant. A custom role is ranked by
+// its base role, so the same hierarchy rules apply to it. Any temporary
+// elevation is cleared: the new role is the one that applies.
 func (s *UserService) UpdateRole(ctx context.Context, req UpdateRoleRequest, callerRole domain.Role) error {
+	var customRole *domain.CustomRole
+	if req.CustomRoleID != nil {
+		var err error
+		customRole, err = s.findCustomRole(ctx, req.TenantID, *req.CustomRoleID)
+		if err != nil {
+			return fmt.Errorf("update role: lookup custom role: %w", err)
+		}
+		req.NewRole = customRole.BaseRole
+	}
+
 	// Check if caller can assign the new role
 	if !callerRole.CanAssign(req.NewRole) {
 		return domain.ErrCannotAssignRole
@@ -266,27 +542,298 @@ func (s *UserService) UpdateRole(ctx context.Context, req UpdateRoleRequest, cal
 	}
 
 	// Check if caller can manage the current role
-	if !callerRole.CanManage(roleAssignment.Role) {
+	if !callerRole.CanManage(roleAssignment.EffectiveRole()) {
 		return domain.ErrCannotManageRole
 	}
 
-	oldRole := roleAssignment.Role
+	// Owners only change hands through an ownership transfer; never leave
+	// the tenant without an active one, whatever the role ranking allows
+	save := s.roleRepo.Update
+	if roleAssignment.Role == domain.RoleOwner && req.NewRole != domain.RoleOwner {
+		save = s.roleRepo.UpdateUnlessLastOwner
+	}
+
+	oldRole := roleAssignment.RoleName()
+	hadElevation := roleAssignment.HasElevation()
 	roleAssignment.Role = req.NewRole
+	roleAssignment.CustomRoleID = req.CustomRoleID
+	roleAssignment.CustomRole = customRole
+	roleAssignment.ClearElevation()
 
-	if err := s.roleRepo.Update(ctx, roleAssignment); err != nil {
+	if err := save(ctx, roleAssignment); err != nil {
 		return fmt.Errorf("update role: save: %w", err)
 	}
 
+	// Access tokens carry the old role; revoke them so the user has to
+	// refresh and picks up the new one
+	if err := revokeUserAccessTokens(ctx, s.revocations, req.UserID, s.accessTokenTTL); err != nil {
+		return fmt.Errorf("update role: revoke access tokens: %w", err)
+	}
+
 	// Log role change
-	s.logEvent(ctx, domain.EventRoleChanged, &req.UserID, &req.TenantID, req.IPAddress, "", map[string]interface{}{
+	metadata := map[string]interface{}{
 		"old_role":   oldRole,
-		"new_role":   req.NewRole,
+		"new_role":   roleAssignment.RoleName(),
 		"updated_by": req.UpdatedBy,
-	})
+	}
+	if customRole != nil {
+		metadata["custom_role_id"] = customRole.ID
+	}
+	if hadElevation {
+		metadata["elevation_cleared"] = true
+	}
+	s.logEvent(ctx, domain.EventRoleChanged, &req.UserID, &req.TenantID, req.IPAddress, "", metadata)
+
+	return nil
+}
+
+// ElevateRoleRequest contains the data for temporarily elevating a user's role.
+type ElevateRoleRequest struct {
+	UserID   uuid.UUID
+	TenantID uuid.UUID
+	NewRole  domain.Role
+	// CustomRoleID elevates to one of the tenant's custom roles instead;
+	// NewRole is then taken from its base role.
+	CustomRoleID *uuid.UUID
+	// ValidFrom is when the elevation starts. If nil or in the past, it
+	// starts now.
+	ValidFrom  *time.Time
+	ValidUntil time.Time
+	ElevatedBy uuid.UUID
+	IPAddress  string
+}
+
+// ElevateRole gives a user a higher role in a tenant for a time window, e.g.
+// a waiter covering as shift lead for one night. The standing role is kept
+// and applies again once the window ends, when ProcessRoleElevations revokes
+// the sessions issued under the elevated role.
+//
+// A new elevation replaces any existing one. Replacing a running elevation
+// with one that also starts now extends it; otherwise the existing one is
+// ended first.
+func (s *UserService) ElevateRole(ctx context.Context, req ElevateRoleRequest, callerRole domain.Role) (*domain.UserTenantRole, error) {
+	now := time.Now()
+	validFrom := now
+	if req.ValidFrom != nil && req.ValidFrom.After(now) {
+		validFrom = *req.ValidFrom
+	}
+	if !req.ValidUntil.After(validFrom) || req.ValidUntil.Sub(validFrom) > domain.MaxRoleElevation {
+		return nil, domain.ErrElevationWindow
+	}
+
+	var customRole *domain.CustomRole
+	if req.CustomRoleID != nil {
+		var err error
+		customRole, err = s.findCustomRole(ctx, req.TenantID, *req.CustomRoleID)
+		if err != nil {
+			return nil, fmt.Errorf("elevate role: lookup custom role: %w", err)
+		}
+		req.NewRole = customRole.BaseRole
+	}
+
+	if !callerRole.CanAssign(req.NewRole) {
+		return nil, domain.ErrCannotAssignRole
+	}
+
+	roleAssignment, err := s.roleRepo.FindByUserAndTenant(ctx, req.UserID, req.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("elevate role: lookup: %w", err)
+	}
+	if !callerRole.CanManage(roleAssignment.EffectiveRole()) {
+		return nil, domain.ErrCannotManageRole
+	}
+
+	// An elevation can't be used to demote, and must actually change
+	// something: a custom role on the same base role grants other permissions
+	sameRole := req.NewRole == roleAssignment.Role &&
+		((req.CustomRoleID == nil && roleAssignment.CustomRoleID == nil) ||
+			(req.CustomRoleID != nil && roleAssignment.CustomRoleID != nil && *req.CustomRoleID == *roleAssignment.CustomRoleID))
+	if req.NewRole.Level() < roleAssignment.Role.Level() || sameRole {
+		return nil, domain.ErrElevationNotAbove
+	}
+
+	startsNow := !validFrom.After(now)
+	extending := startsNow && roleAssignment.IsElevated()
+	if extending && req.ValidUntil.Sub(*roleAssignment.ValidFrom) > domain.MaxRoleElevation {
+		return nil, domain.ErrElevationWindow
+	}
+	if roleAssignment.HasElevation() && !extending {
+		if err := s.endElevation(ctx, roleAssignment, domain.EventRoleElevationEnded, &req.ElevatedBy, req.IPAddress, now); err != nil {
+			return nil, fmt.Errorf("elevate role: end current elevation: %w", err)
+		}
+	}
+
+	roleAssignment.ElevatedRole = req.NewRole
+	roleAssignment.ElevatedCustomRoleID = req.CustomRoleID
+	roleAssignment.ElevatedCustomRole = customRole
+	roleAssignment.ValidUntil = &req.ValidUntil
+	roleAssignment.ElevatedBy = &req.ElevatedBy
+	if !extending {
+		// An extension keeps the original start, so the sessions issued
+		// since then are still revoked when it ends
+		roleAssignment.ValidFrom = &validFrom
+		roleAssignment.ElevationStartedAt = nil
+		if startsNow {
+			roleAssignment.ElevationStartedAt = &now
+		}
+	}
+
+	if err := s.roleRepo.Update(ctx, roleAssignment); err != nil {
+		return nil, fmt.Errorf("elevate role: save: %w", err)
+	}
+
+	// Access tokens carry the old role; revoke them so the user has to
+	// refresh and picks up the elevated one
+	if startsNow {
+		if err := revokeUserAccessTokens(ctx, s.revocations, req.UserID, s.accessTokenTTL); err != nil {
+			return nil, fmt.Errorf("elevate role: revoke access tokens: %w", err)
+		}
+	}
+
+	metadata := elevationMetadata(roleAssignment)
+	metadata["elevated_by"] = req.ElevatedBy
+	if extending {
+		metadata["extended"] = true
+	}
+	s.logEvent(ctx, domain.EventRoleElevated, &req.UserID, &req.TenantID, req.IPAddress, "", metadata)
+	if startsNow && !extending {
+		s.logEvent(ctx, domain.EventRoleElevationStarted, &req.UserID, &req.TenantID, req.IPAddress, "", elevationMetadata(roleAssignment))
+	}
+
+	return roleAssignment, nil
+}
+
+// EndElevationRequest contains the data for ending a role elevation early.
+type EndElevationRequest struct {
+	UserID    uuid.UUID
+	TenantID  uuid.UUID
+	EndedBy   uuid.UUID
+	IPAddress string
+}
+
+// EndElevation ends or cancels a user's role elevation before its window is
+// over, reverting them to their standing role.
+func (s *UserService) EndElevation(ctx context.Context, req EndElevationRequest, callerRole domain.Role) error {
+	roleAssignment, err := s.roleRepo.FindByUserAndTenant(ctx, req.UserID, req.TenantID)
+	if err != nil {
+		return fmt.Errorf("end elevation: lookup: %w", err)
+	}
+	if !callerRole.CanManage(roleAssignment.EffectiveRole()) {
+		return domain.ErrCannotManageRole
+	}
+	if !roleAssignment.HasElevation() {
+		return domain.ErrNoElevation
+	}
+
+	if err := s.endElevation(ctx, roleAssignment, domain.EventRoleElevationEnded, &req.EndedBy, req.IPAddress, time.Now()); err != nil {
+		return fmt.Errorf("end elevation: %w", err)
+	}
+	return nil
+}
+
+// ProcessRoleElevations starts and expires the role elevations that are due
+// at now, and returns how many it processed. Starting one revokes the user's
+// access tokens so they pick up the elevated role; expiring one reverts the
+// user to their standing role and revokes the sessions issued under the
+// elevated one. A failure on one elevation doesn't stop the others.
+func (s *UserService) ProcessRoleElevations(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.roleRepo.ListDueElevations(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("process role elevations: list: %w", err)
+	}
+
+	var errs []error
+	processed := 0
+	for _, roleAssignment := range due {
+		if !now.Before(*roleAssignment.ValidUntil) {
+			err = s.endElevation(ctx, roleAssignment, domain.EventRoleElevationExpired, nil, "", now)
+		} else {
+			err = s.startElevation(ctx, roleAssignment, now)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("process role elevations: user %s: %w", roleAssignment.UserID, err))
+			continue
+		}
+		processed++
+	}
+	return processed, errors.Join(errs...)
+}
+
+// RunElevationExpirer calls ProcessRoleElevations every checkEvery until ctx
+// is cancelled.
+func (s *UserService) RunElevationExpirer(ctx context.Context, checkEvery time.Duration) {
+	ticker := time.NewTicker(checkEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := s.ProcessRoleElevations(ctx, now); err != nil {
+				log.Printf("ERROR: role elevation expirer: %v", err)
+			}
+		}
+	}
+}
+
+// startElevation records that a scheduled elevation has started.
+func (s *UserService) startElevation(ctx context.Context, roleAssignment *domain.UserTenantRole, now time.Time) error {
+	roleAssignment.ElevationStartedAt = &now
+	if err := s.roleRepo.Update(ctx, roleAssignment); err != nil {
+		return fmt.Errorf("start elevation: save: %w", err)
+	}
+	if err := revokeUserAccessTokens(ctx, s.revocations, roleAssignment.UserID, s.accessTokenTTL); err != nil {
+		return fmt.Errorf("start elevation: revoke access tokens: %w", err)
+	}
+	s.logEvent(ctx, domain.EventRoleElevationStarted, &roleAssignment.UserID, &roleAssignment.TenantID, "", "", elevationMetadata(roleAssignment))
+	return nil
+}
+
+// endElevation clears an elevation and, if it had started, revokes the
+// user's access tokens and the sessions they were issued in the tenant since
+// it started. endedBy is nil when it expired.
+func (s *UserService) endElevation(ctx context.Context, roleAssignment *domain.UserTenantRole, eventType domain.AuthEventType, endedBy *uuid.UUID, ipAddress string, now time.Time) error {
+	metadata := elevationMetadata(roleAssignment)
+	if endedBy != nil {
+		metadata["ended_by"] = *endedBy
+	}
+	validFrom := *roleAssignment.ValidFrom
+
+	roleAssignment.ClearElevation()
+	if err := s.roleRepo.Update(ctx, roleAssignment); err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+
+	if !validFrom.After(now) {
+		if err := revokeUserAccessTokens(ctx, s.revocations, roleAssignment.UserID, s.accessTokenTTL); err != nil {
+			return fmt.Errorf("revoke access tokens: %w", err)
+		}
+		revoked, err := s.sessionRepo.RevokeForUserInTenantSince(ctx, roleAssignment.UserID, roleAssignment.TenantID, validFrom)
+		if err != nil {
+			return fmt.Errorf("revoke sessions: %w", err)
+		}
+		metadata["sessions_revoked"] = revoked
+	}
 
+	s.logEvent(ctx, eventType, &roleAssignment.UserID, &roleAssignment.TenantID, ipAddress, "", metadata)
 	return nil
 }
 
+// elevationMetadata describes an elevation for the audit log.
+func elevationMetadata(roleAssignment *domain.UserTenantRole) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"role":          roleAssignment.StandingRoleName(),
+		"elevated_role": roleAssignment.ElevatedRoleName(),
+		"valid_from":    roleAssignment.ValidFrom,
+		"valid_until":   roleAssignment.ValidUntil,
+	}
+	if roleAssignment.ElevatedCustomRoleID != nil {
+		metadata["custom_role_id"] = *roleAssignment.ElevatedCustomRoleID
+	}
+	return metadata
+}
+
 // UnlockRequest contains the data for clearing an account lockout.
 type UnlockRequest struct {
 	UserID    uuid.UUID
@@ -307,8 +854,7 @@ func (s *UserService) Unlock(ctx context.Context, req UnlockRequest, callerRole
 		return nil, domain.ErrCannotManageRole
 	}
 
-	user.FailedLoginCount = 0
-	user.LockedUntil = nil
+	user.ResetLoginFailures()
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, fmt.Errorf("unlock user: save: %w", err)
@@ -321,65 +867,424 @@ func (s *UserService) Unlock(ctx context.Context, req UnlockRequest, callerRole
 	return user, nil
 }
 
-// List retrieves users in a tenant with pagination.
-func (s *UserService) List(ctx context.Context, tenantID uuid.UUID, page, limit int) ([]*domain.User, int64, error) {
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 || limit > 100 {
-		limit = 20
+// ListUserSessions returns a user's active sessions in a tenant, for a
+// manager reviewing where an employee is signed in. Sessions the user has in
+// other tenants are not visible.
+func (s *UserService) ListUserSessions(ctx context.Context, userID, tenantID uuid.UUID, callerRole domain.Role) ([]*domain.Session, error) {
+	if err := s.checkCanManageInTenant(ctx, userID, tenantID, callerRole); err != nil {
+		return nil, fmt.Errorf("list user sessions: %w", err)
 	}
-	offset := (page - 1) * limit
 
-	return s.userRepo.ListByTenant(ctx, tenantID, offset, limit)
+	sessions, err := s.sessionRepo.ListActiveForUserInTenant(ctx, userID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list user sessions: %w", err)
+	}
+	return sessions, nil
 }
 
-// ChangePasswordRequest contains the data for changing a password.
-type ChangePasswordRequest struct {
-	UserID          uuid.UUID
-	CurrentPassword string
-	NewPassword     string
-	IPAddress       string
+// RevokeUserSessionsRequest contains the data for revoking an employee's sessions.
+type RevokeUserSessionsRequest struct {
+	UserID   uuid.UUID
+	TenantID uuid.UUID
+	// SessionID selects a single session. If uuid.Nil, every session the
+	// user has in the tenant is revoked.
+	SessionID uuid.UUID
+	RevokedBy uuid.UUID
+	IPAddress string
 }
 
-// ChangePassword changes a user's password.
-func (s *UserService) ChangePassword(ctx context.Context, req ChangePasswordRequest) error {
-	user, err := s.userRepo.FindByID(ctx, req.UserID)
-	if err != nil {
-		return fmt.Errorf("change password: lookup: %w", err)
+// RevokeUserSessions signs an employee out of one or all of their sessions in
+// a tenant (e.g. a lost or shared device).
+func (s *UserService) RevokeUserSessions(ctx context.Context, req RevokeUserSessionsRequest, callerRole domain.Role) error {
+	if err := s.checkCanManageInTenant(ctx, req.UserID, req.TenantID, callerRole); err != nil {
+		return fmt.Errorf("revoke user sessions: %w", err)
 	}
 
-	// Verify current password
-	match, err := s.passwordSvc.Verify(req.CurrentPassword, user.PasswordHash)
-	if err != nil {
-		return fmt.Errorf("change password: verify: %w", err)
-	}
-	if !match {
-		return domain.ErrPasswordIncorrect
+	metadata := map[string]interface{}{
+		"revoked_by": req.RevokedBy,
 	}
 
-	// Validate new password
-	if err := s.passwordSvc.ValidatePassword(req.NewPassword); err != nil {
-		return domain.ErrPasswordWeak
+	if req.SessionID == uuid.Nil {
+		if err := s.sessionRepo.RevokeAllForUserInTenant(ctx, req.UserID, req.TenantID); err != nil {
+			return fmt.Errorf("revoke user sessions: revoke all: %w", err)
+		}
+		metadata["scope"] = "tenant_sessions"
+	} else {
+		session, err := s.sessionRepo.FindByID(ctx, req.SessionID)
+		if err != nil {
+			return fmt.Errorf("revoke user sessions: session lookup: %w", err)
+		}
+		if session.UserID != req.UserID || session.TenantID != req.TenantID || session.IsRevoked() {
+			return domain.ErrSessionNotFound
+		}
+		if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+			return fmt.Errorf("revoke user sessions: revoke: %w", err)
+		}
+		metadata["scope"] = "session"
+		metadata["session_id"] = session.ID.String()
 	}
 
-	// Hash new password
-	newHash, err := s.passwordSvc.Hash(req.NewPassword)
-	if err != nil {
-		return fmt.Errorf("change password: hash: %w", err)
-	}
+	s.logEvent(ctx, domain.EventSessionRevoked, &req.UserID, &req.TenantID, req.IPAddress, "", metadata)
 
-	user.PasswordHash = newHash
-	user.MustResetPwd = false
+	return nil
+}
 
-	if err := s.userRepo.Update(ctx, user); err != nil {
-		return fmt.Errorf("change password: save: %w", err)
-	}
+// RemoveFromTenantRequest contains the data for removing a user from a tenant.
+type RemoveFromTenantRequest struct {
+	UserID    uuid.UUID
+	TenantID  uuid.UUID
+	RemovedBy uuid.UUID
+	IPAddress string
+}
+
+// RemoveFromTenant offboards a user from one tenant: their role there is
+// deleted and their sessions in it are revoked. The account itself and any
+// other tenants it belongs to are left alone (disabling the account with
+// is_active=false is the global switch). Users may remove themselves;
+// removing anyone else requires a role that can manage theirs. A tenant
+// always keeps at least one active owner.
+func (s *UserService) RemoveFromTenant(ctx context.Context, req RemoveFromTenantRequest, callerRole domain.Role) error {
+	roleAssignment, err := s.roleRepo.FindByUserAndTenant(ctx, req.UserID, req.TenantID)
+	if err != nil {
+		return fmt.Errorf("remove from tenant: lookup: %w", err)
+	}
+
+	if req.UserID != req.RemovedBy && !callerRole.CanManage(roleAssignment.EffectiveRole()) {
+		return domain.ErrCannotManageRole
+	}
+
+	remove := s.roleRepo.DeleteByUserAndTenant
+	if roleAssignment.Role == domain.RoleOwner {
+		remove = s.roleRepo.DeleteUnlessLastOwner
+	}
+	if err := remove(ctx, req.UserID, req.TenantID); err != nil {
+		return fmt.Errorf("remove from tenant: delete role: %w", err)
+	}
+
+	if err := s.sessionRepo.RevokeAllForUserInTenant(ctx, req.UserID, req.TenantID); err != nil {
+		return fmt.Errorf("remove from tenant: revoke sessions: %w", err)
+	}
+
+	// Access tokens for the tenant stay valid until they expire otherwise
+	if err := revokeUserAccessTokens(ctx, s.revocations, req.UserID, s.accessTokenTTL); err != nil {
+		return fmt.Errorf("remove from tenant: revoke access tokens: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventTenantRoleRemoved, &req.UserID, &req.TenantID, req.IPAddress, "", map[string]interface{}{
+		"role":       roleAssignment.Role,
+		"removed_by": req.RemovedBy,
+	})
+
+	return nil
+}
+
+// StartOwnershipTransferRequest contains the data for starting an ownership transfer.
+type StartOwnershipTransferRequest struct {
+	TenantID   uuid.UUID
+	FromUserID uuid.UUID // Current owner starting the transfer
+	ToUserID   uuid.UUID
+	IPAddress  string
+}
+
+// StartOwnershipTransferResponse contains the result of starting an ownership transfer.
+type StartOwnershipTransferResponse struct {
+	Transfer *domain.OwnershipTransfer
+	// Token is the plaintext confirmation token; it is emailed to the
+	// recipient and must never be surfaced in an API response.
+	Token string
+}
+
+// StartOwnershipTransfer lets a tenant owner offer the owner role to another
+// active member of the tenant. Nothing changes until the recipient confirms
+// with the emailed token and their password (ConfirmOwnershipTransfer).
+func (s *UserService) StartOwnershipTransfer(ctx context.Context, req StartOwnershipTransferRequest) (*StartOwnershipTransferResponse, error) {
+	// The owner check uses the stored role rather than the token claim, so a
+	// demoted owner's unexpired token can't start a transfer
+	from, err := s.roleRepo.FindByUserAndTenant(ctx, req.FromUserID, req.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("start ownership transfer: owner lookup: %w", err)
+	}
+	if from.Role != domain.RoleOwner {
+		return nil, domain.ErrInsufficientRole
+	}
+
+	if req.ToUserID == req.FromUserID {
+		return nil, domain.ErrOwnershipTransferTarget
+	}
+	to, err := s.roleRepo.FindByUserAndTenant(ctx, req.ToUserID, req.TenantID)
+	if errors.Is(err, domain.ErrUserNotInTenant) {
+		return nil, domain.ErrOwnershipTransferTarget
+	}
+	if err != nil {
+		return nil, fmt.Errorf("start ownership transfer: recipient lookup: %w", err)
+	}
+	if to.Role == domain.RoleOwner {
+		return nil, domain.ErrOwnershipTransferTarget
+	}
+	recipient, err := s.userRepo.FindByID(ctx, req.ToUserID)
+	if err != nil {
+		return nil, fmt.Errorf("start ownership transfer: recipient lookup: %w", err)
+	}
+	if !recipient.CanLogin() {
+		return nil, domain.ErrOwnershipTransferTarget
+	}
+
+	// One outstanding transfer per tenant; an expired one is replaced rather
+	// than making the owner cancel it first.
+	pending, err := s.transfers.FindPendingByTenant(ctx, req.TenantID)
+	switch {
+	case err == nil && !pending.IsExpired():
+		return nil, domain.ErrOwnershipTransferPending
+	case err == nil:
+		if err := s.transfers.Cancel(ctx, pending.ID); err != nil {
+			return nil, fmt.Errorf("start ownership transfer: cancel expired transfer: %w", err)
+		}
+	case !errors.Is(err, domain.ErrOwnershipTransferNotFound):
+		return nil, fmt.Errorf("start ownership transfer: pending lookup: %w", err)
+	}
+
+	plainToken, tokenHash, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return nil, fmt.Errorf("start ownership transfer: generate token: %w", err)
+	}
+
+	transfer := &domain.OwnershipTransfer{
+		ID:         uuid.New(),
+		TenantID:   req.TenantID,
+		FromUserID: req.FromUserID,
+		ToUserID:   req.ToUserID,
+		TokenHash:  tokenHash,
+		ExpiresAt:  time.Now().Add(domain.OwnershipTransferTTL),
+	}
+
+	if err := s.transfers.Create(ctx, transfer); err != nil {
+		return nil, fmt.Errorf("start ownership transfer: store transfer: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventOwnerTransferStarted, &req.FromUserID, &req.TenantID, req.IPAddress, "", map[string]interface{}{
+		"transfer_id": transfer.ID,
+		"to_user_id":  req.ToUserID,
+	})
+
+	if err := s.emailer.SendOwnershipTransferRequest(ctx, recipient.Email, req.TenantID, plainToken); err != nil {
+		s.logEmailFailure(ctx, "ownership_transfer_request", recipient.ID, &req.TenantID, req.IPAddress, err)
+	}
+
+	return &StartOwnershipTransferResponse{Transfer: transfer, Token: plainToken}, nil
+}
+
+// CancelOwnershipTransferRequest contains the data for cancelling a tenant's
+// pending ownership transfer.
+type CancelOwnershipTransferRequest struct {
+	TenantID    uuid.UUID
+	CancelledBy uuid.UUID
+	IPAddress   string
+}
+
+// CancelOwnershipTransfer withdraws a tenant's pending ownership transfer so
+// its confirmation link stops working.
+func (s *UserService) CancelOwnershipTransfer(ctx context.Context, req CancelOwnershipTransferRequest) error {
+	transfer, err := s.transfers.FindPendingByTenant(ctx, req.TenantID)
+	if err != nil {
+		return fmt.Errorf("cancel ownership transfer: %w", err)
+	}
+
+	if err := s.transfers.Cancel(ctx, transfer.ID); err != nil {
+		return fmt.Errorf("cancel ownership transfer: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventOwnerTransferCancelled, &req.CancelledBy, &req.TenantID, req.IPAddress, "", map[string]interface{}{
+		"transfer_id": transfer.ID,
+		"to_user_id":  transfer.ToUserID,
+	})
+
+	return nil
+}
+
+// ConfirmOwnershipTransferRequest contains the data for confirming an ownership transfer.
+type ConfirmOwnershipTransferRequest struct {
+	Token string
+	// Password is the recipient's current password, re-authenticating them
+	// on top of the emailed link.
+	Password  string
+	IPAddress string
+}
+
+// ConfirmOwnershipTransferResponse contains the result of a completed ownership transfer.
+type ConfirmOwnershipTransferResponse struct {
+	Transfer *domain.OwnershipTransfer
+	// PreviousOwnerRole is the role the previous owner now holds (the
+	// recipient's role before the swap).
+	PreviousOwnerRole domain.Role
+}
+
+// ConfirmOwnershipTransfer completes a transfer: the recipient becomes owner
+// and the previous owner takes the recipient's old role, in one atomic
+// swap so the tenant is never left without an owner. Both parties' access
+// tokens are revoked so they pick up their new roles, and both are notified.
+func (s *UserService) ConfirmOwnershipTransfer(ctx context.Context, req ConfirmOwnershipTransferRequest) (*ConfirmOwnershipTransferResponse, error) {
+	transfer, err := s.transfers.FindByToken(ctx, s.passwordSvc.HashResetToken(req.Token))
+	if err != nil {
+		return nil, fmt.Errorf("confirm ownership transfer: token lookup: %w", err)
+	}
+
+	switch {
+	case transfer.IsConfirmed():
+		return nil, domain.ErrOwnershipTransferUsed
+	case transfer.IsCancelled():
+		return nil, domain.ErrOwnershipTransferCancelled
+	case transfer.IsExpired():
+		return nil, domain.ErrOwnershipTransferExpired
+	}
+
+	recipient, err := s.userRepo.FindByID(ctx, transfer.ToUserID)
+	if err != nil {
+		return nil, fmt.Errorf("confirm ownership transfer: recipient lookup: %w", err)
+	}
+	if err := s.confirmExistingAccount(recipient, req.Password); err != nil {
+		return nil, err
+	}
+
+	// Claim the transfer before swapping, so a token raced by two requests
+	// only ever swaps once.
+	if err := s.transfers.MarkConfirmed(ctx, transfer.ID); err != nil {
+		return nil, fmt.Errorf("confirm ownership transfer: mark confirmed: %w", err)
+	}
+
+	previousRole, err := s.roleRepo.TransferOwnership(ctx, transfer.TenantID, transfer.FromUserID, transfer.ToUserID)
+	if err != nil {
+		return nil, fmt.Errorf("confirm ownership transfer: swap roles: %w", err)
+	}
+	now := time.Now()
+	transfer.ConfirmedAt = &now
+
+	for _, userID := range []uuid.UUID{transfer.FromUserID, transfer.ToUserID} {
+		if err := revokeUserAccessTokens(ctx, s.revocations, userID, s.accessTokenTTL); err != nil {
+			return nil, fmt.Errorf("confirm ownership transfer: revoke access tokens: %w", err)
+		}
+	}
+
+	s.logEvent(ctx, domain.EventOwnerTransferred, &transfer.ToUserID, &transfer.TenantID, req.IPAddress, "", map[string]interface{}{
+		"transfer_id":         transfer.ID,
+		"from_user_id":        transfer.FromUserID,
+		"previous_owner_role": previousRole,
+	})
+
+	s.notifyOwnershipTransferred(ctx, transfer, recipient, req.IPAddress)
+
+	return &ConfirmOwnershipTransferResponse{Transfer: transfer, PreviousOwnerRole: previousRole}, nil
+}
+
+// notifyOwnershipTransferred emails both parties to a completed transfer.
+// Failures are logged and audited; the transfer itself has already happened.
+func (s *UserService) notifyOwnershipTransferred(ctx context.Context, transfer *domain.OwnershipTransfer, recipient *domain.User, ipAddress string) {
+	if err := s.emailer.SendOwnershipTransferred(ctx, recipient.Email, transfer.TenantID, true); err != nil {
+		s.logEmailFailure(ctx, "ownership_transferred", recipient.ID, &transfer.TenantID, ipAddress, err)
+	}
+
+	previousOwner, err := s.userRepo.FindByID(ctx, transfer.FromUserID)
+	if err != nil {
+		log.Printf("ERROR: failed to look up previous owner %s for ownership transfer %s: %v", transfer.FromUserID, transfer.ID, err)
+		return
+	}
+	if err := s.emailer.SendOwnershipTransferred(ctx, previousOwner.Email, transfer.TenantID, false); err != nil {
+		s.logEmailFailure(ctx, "ownership_transferred", previousOwner.ID, &transfer.TenantID, ipAddress, err)
+	}
+}
+
+// checkCanManageInTenant verifies the target user belongs to the tenant and
+// holds a role the caller is allowed to manage.
+func (s *UserService) checkCanManageInTenant(ctx context.Context, userID, tenantID uuid.UUID, callerRole domain.Role) error {
+	roleAssignment, err := s.roleRepo.FindByUserAndTenant(ctx, userID, tenantID)
+	if err != nil {
+		return fmt.Errorf("role lookup: %w", err)
+	}
+	if !callerRole.CanManage(roleAssignment.EffectiveRole()) {
+		return domain.ErrCannotManageRole
+	}
+	return nil
+}
+
+// findCustomRole looks up one of a tenant's custom roles.
+func (s *UserService) findCustomRole(ctx context.Context, tenantID, id uuid.UUID) (*domain.CustomRole, error) {
+	if s.customRoles == nil {
+		return nil, domain.ErrCustomRoleNotFound
+	}
+	return s.customRoles.FindByID(ctx, tenantID, id)
+}
+
+// List retrieves users in a tenant with pagination.
+func (s *UserService) List(ctx context.Context, tenantID uuid.UUID, page, limit int) ([]*domain.User, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	return s.userRepo.ListByTenant(ctx, tenantID, offset, limit)
+}
+
+// ChangePasswordRequest contains the data for changing a password.
+type ChangePasswordRequest struct {
+	UserID          uuid.UUID
+	CurrentPassword string
+	NewPassword     string
+	IPAddress       string
+}
+
+// ChangePassword changes a user's password.
+func (s *UserService) ChangePassword(ctx context.Context, req ChangePasswordRequest) error {
+	user, err := s.userRepo.FindByID(ctx, req.UserID)
+	if err != nil {
+		return fmt.Errorf("change password: lookup: %w", err)
+	}
+
+	// Verify current password
+	match, err := s.passwordSvc.Verify(req.CurrentPassword, user.PasswordHash)
+	if err != nil {
+		return fmt.Errorf("change password: verify: %w", err)
+	}
+	if !match {
+		return domain.ErrPasswordIncorrect
+	}
+
+	// Check the new password against the policies of the user's tenants
+	policy, err := s.passwordPolicies.PolicyForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("change password: %w", err)
+	}
+	if err := s.passwordPolicies.CheckPassword(ctx, user, policy, req.NewPassword); err != nil {
+		return err
+	}
+
+	// Hash new password
+	newHash, err := s.passwordSvc.Hash(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("change password: hash: %w", err)
+	}
+
+	oldHash := user.PasswordHash
+	now := time.Now()
+	user.PasswordHash = newHash
+	user.PasswordChangedAt = &now
+	user.MustResetPwd = false
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("change password: save: %w", err)
+	}
+	if err := s.passwordPolicies.RecordPasswordChange(ctx, user.ID, oldHash); err != nil {
+		return fmt.Errorf("change password: %w", err)
+	}
 
 	// Revoke all sessions per FR-014
 	if err := s.sessionRepo.RevokeAllForUser(ctx, user.ID); err != nil {
 		return fmt.Errorf("change password: revoke sessions: %w", err)
 	}
+	if err := revokeUserAccessTokens(ctx, s.revocations, user.ID, s.accessTokenTTL); err != nil {
+		return fmt.Errorf("change password: revoke access tokens: %w", err)
+	}
 
 	// Log password change
 	s.logEvent(ctx, domain.EventPasswordChanged, &user.ID, nil, req.IPAddress, "", nil)
@@ -414,6 +1319,16 @@ func (s *UserService) RequestPasswordReset(ctx context.Context, email, ipAddress
 		return fmt.Errorf("request password reset: user lookup: %w", err)
 	}
 
+	// Only mail a reset link to an address the user has proven they read,
+	// still without revealing whether it was sent
+	if !user.IsEmailVerified() {
+		s.logEvent(ctx, domain.EventPasswordResetRequested, &user.ID, nil, ipAddress, "", map[string]interface{}{
+			"found":          true,
+			"email_verified": false,
+		})
+		return nil
+	}
+
 	// Generate reset token
 	plainToken, tokenHash, err := s.passwordSvc.GenerateResetToken()
 	if err != nil {
@@ -446,11 +1361,6 @@ func (s *UserService) RequestPasswordReset(ctx context.Context, email, ipAddress
 
 // CompletePasswordReset completes the password reset flow.
 func (s *UserService) CompletePasswordReset(ctx context.Context, token, newPassword, ipAddress string) error {
-	// Validate new password first
-	if err := s.passwordSvc.ValidatePassword(newPassword); err != nil {
-		return domain.ErrPasswordWeak
-	}
-
 	// Hash the provided token
 	tokenHash := s.passwordSvc.HashResetToken(token)
 
@@ -468,25 +1378,44 @@ func (s *UserService) CompletePasswordReset(ctx context.Context, token, newPassw
 		return domain.ErrPasswordResetExpired
 	}
 
-	// Hash new password
-	newHash, err := s.passwordSvc.Hash(newPassword)
-	if err != nil {
-		return fmt.Errorf("complete password reset: hash password: %w", err)
-	}
-
 	// Get user
 	user, err := s.userRepo.FindByID(ctx, resetToken.UserID)
 	if err != nil {
 		return fmt.Errorf("complete password reset: user lookup: %w", err)
 	}
+	if !user.IsEmailVerified() {
+		return domain.ErrEmailNotVerified
+	}
+
+	// Check the new password before the token is spent, so a rejected
+	// password can be retried with the same link
+	policy, err := s.passwordPolicies.PolicyForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("complete password reset: %w", err)
+	}
+	if err := s.passwordPolicies.CheckPassword(ctx, user, policy, newPassword); err != nil {
+		return err
+	}
+
+	// Hash new password
+	newHash, err := s.passwordSvc.Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("complete password reset: hash password: %w", err)
+	}
 
 	// Update password
+	oldHash := user.PasswordHash
+	now := time.Now()
 	user.PasswordHash = newHash
+	user.PasswordChangedAt = &now
 	user.MustResetPwd = false
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return fmt.Errorf("complete password reset: save password: %w", err)
 	}
+	if err := s.passwordPolicies.RecordPasswordChange(ctx, user.ID, oldHash); err != nil {
+		return fmt.Errorf("complete password reset: %w", err)
+	}
 
 	// Mark token as used
 	if err := s.passwordReset.MarkUsed(ctx, resetToken.ID); err != nil {
@@ -497,6 +1426,9 @@ func (s *UserService) CompletePasswordReset(ctx context.Context, token, newPassw
 	if err := s.sessionRepo.RevokeAllForUser(ctx, user.ID); err != nil {
 		return fmt.Errorf("complete password reset: revoke sessions: %w", err)
 	}
+	if err := revokeUserAccessTokens(ctx, s.revocations, user.ID, s.accessTokenTTL); err != nil {
+		return fmt.Errorf("complete password reset: revoke access tokens: %w", err)
+	}
 
 	// Log password reset completion
 	s.logEvent(ctx, domain.EventPasswordResetCompleted, &user.ID, nil, ipAddress, "", nil)
@@ -504,15 +1436,423 @@ func (s *UserService) CompletePasswordReset(ctx context.Context, token, newPassw
 	return nil
 }
 
+// maxEmailTokensPerHour caps how many verification or change emails a user
+// can have sent in an hour.
+const maxEmailTokensPerHour = 3
+
+// RequestEmailVerification emails the user a link that verifies their
+// current email address (24-hour TTL). Only the newest link works.
+func (s *UserService) RequestEmailVerification(ctx context.Context, userID uuid.UUID, ipAddress string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("request email verification: lookup: %w", err)
+	}
+	if user.IsEmailVerified() {
+		return domain.ErrEmailAlreadyVerified
+	}
+	if err := s.checkEmailTokenRate(ctx, user.ID, domain.EmailTokenVerify); err != nil {
+		return fmt.Errorf("request email verification: %w", err)
+	}
+
+	if err := s.sendEmailVerification(ctx, user, ipAddress); err != nil {
+		return fmt.Errorf("request email verification: %w", err)
+	}
+	return nil
+}
+
+// sendEmailVerification issues a verification link for the user's current
+// email and mails it. Delivery failures are logged and audited.
+func (s *UserService) sendEmailVerification(ctx context.Context, user *domain.User, ipAddress string) error {
+	if err := s.emailTokens.DeleteForUser(ctx, user.ID, domain.EmailTokenVerify); err != nil {
+		return fmt.Errorf("delete old tokens: %w", err)
+	}
+	token, err := s.createEmailToken(ctx, user.ID, domain.EmailTokenVerify, user.Email, domain.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	s.logEvent(ctx, domain.EventEmailVerificationSent, &user.ID, nil, ipAddress, "", nil)
+
+	if err := s.emailer.SendEmailVerification(ctx, user.Email, token); err != nil {
+		s.logEmailFailure(ctx, "email_verification", user.ID, nil, ipAddress, err)
+	}
+	return nil
+}
+
+// VerifyEmail redeems a verification link. It only verifies the address it
+// was sent to, so a link sent before an email change no longer works.
+func (s *UserService) VerifyEmail(ctx context.Context, token, ipAddress string) error {
+	emailToken, user, err := s.findEmailToken(ctx, token, domain.EmailTokenVerify)
+	if err != nil {
+		return fmt.Errorf("verify email: %w", err)
+	}
+	if emailToken.Email != user.Email {
+		return domain.ErrEmailTokenInvalid
+	}
+
+	if err := s.emailTokens.MarkUsed(ctx, emailToken.ID); err != nil {
+		return fmt.Errorf("verify email: mark token used: %w", err)
+	}
+
+	if !user.IsEmailVerified() {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("verify email: save: %w", err)
+		}
+	}
+
+	s.logEvent(ctx, domain.EventEmailVerified, &user.ID, nil, ipAddress, "", map[string]interface{}{
+		"email": user.Email,
+	})
+
+	return nil
+}
+
+// RequestEmailChangeRequest contains the data for changing a user's email.
+type RequestEmailChangeRequest struct {
+	UserID          uuid.UUID
+	NewEmail        string
+	CurrentPassword string
+	IPAddress       string
+}
+
+// RequestEmailChange starts moving a user to a new email address. Nothing
+// changes until the link mailed to the new address is followed
+// (ConfirmEmailChange, 1-hour TTL); only the newest link works.
+func (s *UserService) RequestEmailChange(ctx context.Context, req RequestEmailChangeRequest) error {
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if addr, err := mail.ParseAddress(newEmail); err != nil || addr.Address != newEmail {
+		return domain.ErrEmailInvalid
+	}
+
+	user, err := s.userRepo.FindByID(ctx, req.UserID)
+	if err != nil {
+		return fmt.Errorf("request email change: lookup: %w", err)
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return domain.ErrEmailUnchanged
+	}
+
+	match, err := s.passwordSvc.Verify(req.CurrentPassword, user.PasswordHash)
+	if err != nil {
+		return fmt.Errorf("request email change: verify: %w", err)
+	}
+	if !match {
+		return domain.ErrPasswordIncorrect
+	}
+
+	exists, err := s.userRepo.ExistsByEmail(ctx, newEmail)
+	if err != nil {
+		return fmt.Errorf("request email change: email lookup: %w", err)
+	}
+	if exists {
+		return domain.ErrEmailExists
+	}
+	if err := s.checkEmailTokenRate(ctx, user.ID, domain.EmailTokenChange); err != nil {
+		return fmt.Errorf("request email change: %w", err)
+	}
+
+	if err := s.emailTokens.DeleteForUser(ctx, user.ID, domain.EmailTokenChange); err != nil {
+		return fmt.Errorf("request email change: delete old tokens: %w", err)
+	}
+	token, err := s.createEmailToken(ctx, user.ID, domain.EmailTokenChange, newEmail, domain.EmailChangeTTL)
+	if err != nil {
+		return fmt.Errorf("request email change: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventEmailChangeRequested, &user.ID, nil, req.IPAddress, "", map[string]interface{}{
+		"new_email": newEmail,
+	})
+
+	if err := s.emailer.SendEmailChangeConfirmation(ctx, newEmail, token); err != nil {
+		s.logEmailFailure(ctx, "email_change", user.ID, nil, req.IPAddress, err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange redeems the link mailed to a new address: the user
+// moves to it, verified, and is signed out everywhere. The previous address
+// is told and gets a link that undoes the change for 7 days
+// (RevertEmailChange).
+func (s *UserService) ConfirmEmailChange(ctx context.Context, token, ipAddress string) error {
+	emailToken, user, err := s.findEmailToken(ctx, token, domain.EmailTokenChange)
+	if err != nil {
+		return fmt.Errorf("confirm email change: %w", err)
+	}
+
+	// The address may have been registered since the change was requested
+	exists, err := s.userRepo.ExistsByEmail(ctx, emailToken.Email)
+	if err != nil {
+		return fmt.Errorf("confirm email change: email lookup: %w", err)
+	}
+	if exists {
+		return domain.ErrEmailExists
+	}
+
+	if err := s.emailTokens.MarkUsed(ctx, emailToken.ID); err != nil {
+		return fmt.Errorf("confirm email change: mark token used: %w", err)
+	}
+
+	oldEmail := user.Email
+	revertToken, err := s.createEmailToken(ctx, user.ID, domain.EmailTokenRevert, oldEmail, domain.EmailChangeRevertTTL)
+	if err != nil {
+		return fmt.Errorf("confirm email change: %w", err)
+	}
+
+	now := time.Now()
+	user.Email = emailToken.Email
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("confirm email change: save: %w", err)
+	}
+
+	if err := s.signOutEverywhere(ctx, user.ID); err != nil {
+		return fmt.Errorf("confirm email change: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventEmailChanged, &user.ID, nil, ipAddress, "", map[string]interface{}{
+		"old_email": oldEmail,
+		"new_email": user.Email,
+	})
+
+	if err := s.emailer.SendEmailChanged(ctx, oldEmail, user.Email, revertToken); err != nil {
+		s.logEmailFailure(ctx, "email_changed", user.ID, nil, ipAddress, err)
+	}
+
+	return nil
+}
+
+// RevertEmailChange redeems the undo link mailed to the previous address
+// after a change. The account moves back to that address, pending changes
+// are cancelled, and everyone is signed out. Whoever made the change knew
+// the password, so the user is also asked to set a new one.
+func (s *UserService) RevertEmailChange(ctx context.Context, token, ipAddress string) error {
+	emailToken, user, err := s.findEmailToken(ctx, token, domain.EmailTokenRevert)
+	if err != nil {
+		return fmt.Errorf("revert email change: %w", err)
+	}
+
+	changedTo := user.Email
+	if changedTo != emailToken.Email {
+		exists, err := s.userRepo.ExistsByEmail(ctx, emailToken.Email)
+		if err != nil {
+			return fmt.Errorf("revert email change: email lookup: %w", err)
+		}
+		if exists {
+			return domain.ErrEmailExists
+		}
+	}
+
+	if err := s.emailTokens.MarkUsed(ctx, emailToken.ID); err != nil {
+		return fmt.Errorf("revert email change: mark token used: %w", err)
+	}
+	if err := s.emailTokens.DeleteForUser(ctx, user.ID, domain.EmailTokenChange); err != nil {
+		return fmt.Errorf("revert email change: delete pending changes: %w", err)
+	}
+
+	now := time.Now()
+	user.Email = emailToken.Email
+	user.EmailVerifiedAt = &now
+	user.MustResetPwd = true
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("revert email change: save: %w", err)
+	}
+
+	if err := s.signOutEverywhere(ctx, user.ID); err != nil {
+		return fmt.Errorf("revert email change: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventEmailChangeReverted, &user.ID, nil, ipAddress, "", map[string]interface{}{
+		"reverted_from": changedTo,
+		"email":         user.Email,
+	})
+
+	return nil
+}
+
+// RequestAccountUnlock mails a link that lifts a lockout (UnlockAccount,
+// 1-hour TTL) to a locked-out user's verified address. Like password
+// resets, it never reveals whether the account exists or is locked.
+func (s *UserService) RequestAccountUnlock(ctx context.Context, email, ipAddress string) error {
+	if s.resetRateLimiter != nil {
+		allowed, err := s.resetRateLimiter.Allow(ctx, "unlock:"+email)
+		if err != nil {
+			return fmt.Errorf("request account unlock: rate limit check: %w", err)
+		}
+		if !allowed {
+			return domain.ErrRateLimitExceeded
+		}
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			s.logEvent(ctx, domain.EventAccountUnlockRequested, nil, nil, ipAddress, "", map[string]interface{}{
+				"email": email,
+				"found": false,
+			})
+			return nil
+		}
+		return fmt.Errorf("request account unlock: user lookup: %w", err)
+	}
+
+	lockedUnknown, _ := user.IsLockedOn(false)
+	lockedKnown, _ := user.IsLockedOn(true)
+	locked := lockedUnknown || lockedKnown
+	if !locked || !user.IsEmailVerified() {
+		s.logEvent(ctx, domain.EventAccountUnlockRequested, &user.ID, nil, ipAddress, "", map[string]interface{}{
+			"found":          true,
+			"locked":         locked,
+			"email_verified": user.IsEmailVerified(),
+		})
+		return nil
+	}
+
+	if err := s.emailTokens.DeleteForUser(ctx, user.ID, domain.EmailTokenUnlock); err != nil {
+		return fmt.Errorf("request account unlock: delete old tokens: %w", err)
+	}
+	token, err := s.createEmailToken(ctx, user.ID, domain.EmailTokenUnlock, user.Email, domain.AccountUnlockTTL)
+	if err != nil {
+		return fmt.Errorf("request account unlock: %w", err)
+	}
+
+	s.logEvent(ctx, domain.EventAccountUnlockRequested, &user.ID, nil, ipAddress, "", map[string]interface{}{
+		"found":  true,
+		"locked": true,
+	})
+
+	if err := s.emailer.SendAccountUnlock(ctx, user.Email, token); err != nil {
+		s.logEmailFailure(ctx, "account_unlock", user.ID, nil, ipAddress, err)
+	}
+
+	return nil
+}
+
+// UnlockAccount redeems an unlock link, clearing the user's failed login
+// counts and lockouts on every device.
+func (s *UserService) UnlockAccount(ctx context.Context, token, ipAddress string) error {
+	emailToken, user, err := s.findEmailToken(ctx, token, domain.EmailTokenUnlock)
+	if err != nil {
+		return fmt.Errorf("unlock account: %w", err)
+	}
+	if emailToken.Email != user.Email {
+		return domain.ErrEmailTokenInvalid
+	}
+
+	if err := s.emailTokens.MarkUsed(ctx, emailToken.ID); err != nil {
+		return fmt.Errorf("unlock account: mark token used: %w", err)
+	}
+
+	if user.ResetLoginFailures() {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("unlock account: save: %w", err)
+		}
+	}
+
+	s.logEvent(ctx, domain.EventAccountUnlocked, &user.ID, nil, ipAddress, "", map[string]interface{}{
+		"method": "email",
+	})
+
+	return nil
+}
+
+// findEmailToken looks up an unused, unexpired email token with the given
+// purpose and the user it belongs to.
+func (s *UserService) findEmailToken(ctx context.Context, token string, purpose domain.EmailTokenPurpose) (*domain.EmailToken, *domain.User, error) {
+	emailToken, err := s.emailTokens.FindByToken(ctx, s.passwordSvc.HashResetToken(token))
+	if err != nil {
+		return nil, nil, fmt.Errorf("token lookup: %w", err)
+	}
+	switch {
+	case emailToken.Purpose != purpose:
+		return nil, nil, domain.ErrEmailTokenInvalid
+	case emailToken.IsUsed():
+		return nil, nil, domain.ErrEmailTokenUsed
+	case emailToken.IsExpired():
+		return nil, nil, domain.ErrEmailTokenExpired
+	}
+
+	user, err := s.userRepo.FindByID(ctx, emailToken.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("user lookup: %w", err)
+	}
+	return emailToken, user, nil
+}
+
+// createEmailToken stores a new email token and returns its plaintext.
+func (s *UserService) createEmailToken(ctx context.Context, userID uuid.UUID, purpose domain.EmailTokenPurpose, email string, ttl time.Duration) (string, error) {
+	plainToken, tokenHash, err := s.passwordSvc.GenerateResetToken()
+	if err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	emailToken := &domain.EmailToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Purpose:   purpose,
+		Email:     email,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.emailTokens.Create(ctx, emailToken); err != nil {
+		return "", fmt.Errorf("store token: %w", err)
+	}
+	return plainToken, nil
+}
+
+// checkEmailTokenRate returns ErrRateLimitExceeded once a user has had
+// maxEmailTokensPerHour emails of one kind sent in the last hour, counted
+// from the audit log since older links are deleted as new ones are sent.
+func (s *UserService) checkEmailTokenRate(ctx context.Context, userID uuid.UUID, purpose domain.EmailTokenPurpose) error {
+	eventType := domain.EventEmailVerificationSent
+	if purpose == domain.EmailTokenChange {
+		eventType = domain.EventEmailChangeRequested
+	}
+	recent, err := s.eventRepo.FindByUserAndType(ctx, userID, eventType, time.Now().Add(-time.Hour))
+	if err != nil {
+		return fmt.Errorf("rate limit check: %w", err)
+	}
+	if len(recent) >= maxEmailTokensPerHour {
+		return domain.ErrRateLimitExceeded
+	}
+	return nil
+}
+
+// signOutEverywhere revokes all of a user's sessions and access tokens.
+func (s *UserService) signOutEverywhere(ctx context.Context, userID uuid.UUID) error {
+	if err := s.sessionRepo.RevokeAllForUser(ctx, userID); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	if err := revokeUserAccessTokens(ctx, s.revocations, userID, s.accessTokenTTL); err != nil {
+		return fmt.Errorf("revoke access tokens: %w", err)
+	}
+	return nil
+}
+
 // logEvent logs an authentication event.
 func (s *UserService) logEvent(ctx context.Context, eventType domain.AuthEventType, userID, tenantID *uuid.UUID, ipAddress, userAgent string, metadata map[string]interface{}) {
-	event := domain.NewAuthEvent(eventType, userID, tenantID, ipAddress, userAgent)
+	event := newAuthEvent(ctx, eventType, userID, tenantID, ipAddress, userAgent)
 	if metadata != nil {
 		event.Metadata = metadata
 	}
 	_ = s.eventRepo.Create(ctx, event)
 }
 
+// sendInvitation emails an invite link. Like other auth emails, a delivery
+// failure is logged and audited but does not fail the invite; the manager
+// can resend it.
+func (s *UserService) sendInvitation(ctx context.Context, invitation *domain.Invitation, token string, existingAccount bool, ipAddress string) {
+	if err := s.emailer.SendInvitation(ctx, invitation.Email, invitation.TenantID, token, existingAccount); err != nil {
+		log.Printf("ERROR: failed to send invitation email for invitation %s: %v", invitation.ID, err)
+		s.logEvent(ctx, domain.EventEmailDeliveryFailed, nil, &invitation.TenantID, ipAddress, "", map[string]interface{}{
+			"email_type":    "invitation",
+			"invitation_id": invitation.ID,
+			"error":         err.Error(),
+		})
+	}
+}
+
 // logEmailFailure records an email delivery failure per FR-015: logged at
 // error level and audited, but never blocks the caller (the account/token
 // action it's attached to has already succeeded).
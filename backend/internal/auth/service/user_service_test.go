@@ -8,21 +8,40 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/solobueno/erp/internal/auth/domain"
+	"github.com/solobueno/erp/internal/auth/repository"
 	"github.com/solobueno/erp/internal/auth/repository/mock"
 )
 
 // failingEmailer always fails, for testing FR-015's log+audit-on-failure path.
 type failingEmailer struct{ err error }
 
-func (f *failingEmailer) SendTemporaryPassword(ctx context.Context, toEmail, tempPassword string) error {
+func (f *failingEmailer) SendInvitation(ctx context.Context, toEmail string, tenantID uuid.UUID, inviteToken string, existingAccount bool) error {
 	return f.err
 }
-func (f *failingEmailer) SendTenantLinked(ctx context.Context, toEmail string, tenantID uuid.UUID) error {
+func (f *failingEmailer) SendOwnershipTransferRequest(ctx context.Context, toEmail string, tenantID uuid.UUID, transferToken string) error {
+	return f.err
+}
+func (f *failingEmailer) SendOwnershipTransferred(ctx context.Context, toEmail string, tenantID uuid.UUID, newOwner bool) error {
 	return f.err
 }
 func (f *failingEmailer) SendPasswordReset(ctx context.Context, toEmail, resetToken string) error {
 	return f.err
 }
+func (f *failingEmailer) SendEmailVerification(ctx context.Context, toEmail, verifyToken string) error {
+	return f.err
+}
+func (f *failingEmailer) SendEmailChangeConfirmation(ctx context.Context, toEmail, changeToken string) error {
+	return f.err
+}
+func (f *failingEmailer) SendEmailChanged(ctx context.Context, toEmail, newEmail, revertToken string) error {
+	return f.err
+}
+func (f *failingEmailer) SendRefreshTokenReuse(ctx context.Context, toEmail string) error {
+	return f.err
+}
+func (f *failingEmailer) SendAccountUnlock(ctx context.Context, toEmail, unlockToken string) error {
+	return f.err
+}
 
 func setupUserService(t *testing.T) (*UserService, *mock.MockUserRepository, *mock.MockUserTenantRoleRepository, *mock.MockSessionRepository, *mock.MockPasswordResetRepository) {
 	t.Helper()
@@ -33,6 +52,11 @@ func setupUserService(t *testing.T) (*UserService, *mock.MockUserRepository, *mo
 	eventRepo := mock.NewMockAuthEventRepository()
 	passwordResetRepo := mock.NewMockPasswordResetRepository()
 
+	roleRepo.UserLookup = func(userID uuid.UUID) *domain.User {
+		user, _ := userRepo.FindByID(context.Background(), userID)
+		return user
+	}
+
 	userSvc := NewUserService(UserServiceConfig{
 		UserRepo:         userRepo,
 		RoleRepo:         roleRepo,
@@ -45,47 +69,96 @@ func setupUserService(t *testing.T) (*UserService, *mock.MockUserRepository, *mo
 	return userSvc, userRepo, roleRepo, sessionRepo, passwordResetRepo
 }
 
-func TestUserService_Create_Success(t *testing.T) {
-	userSvc, _, _, _, _ := setupUserService(t)
+func setupInvitationTest(t *testing.T, emailer Emailer) (*UserService, *mock.MockUserRepository, *mock.MockUserTenantRoleRepository, *mock.MockInvitationRepository, *mock.MockAuthEventRepository) {
+	t.Helper()
+
+	userRepo := mock.NewMockUserRepository()
+	roleRepo := mock.NewMockUserTenantRoleRepository()
+	invitationRepo := mock.NewMockInvitationRepository()
+	eventRepo := mock.NewMockAuthEventRepository()
+
+	userSvc := NewUserService(UserServiceConfig{
+		UserRepo:      userRepo,
+		RoleRepo:      roleRepo,
+		SessionRepo:   mock.NewMockSessionRepository(),
+		EventRepo:     eventRepo,
+		PasswordReset: mock.NewMockPasswordResetRepository(),
+		Invitations:   invitationRepo,
+		Emailer:       emailer,
+	})
+
+	return userSvc, userRepo, roleRepo, invitationRepo, eventRepo
+}
+
+// addInvitation stores a pending invitation for token and returns it.
+func addInvitation(invitationRepo *mock.MockInvitationRepository, email, token string, tenantID uuid.UUID, role domain.Role) *domain.Invitation {
+	invitation := &domain.Invitation{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Email:     email,
+		FirstName: "New",
+		LastName:  "Hire",
+		Role:      role,
+		TokenHash: NewPasswordService().HashResetToken(token),
+		InvitedBy: uuid.New(),
+		ExpiresAt: time.Now().Add(domain.InvitationTTL),
+	}
+	invitationRepo.AddInvitation(invitation)
+	return invitation
+}
+
+func TestUserService_Invite_Success(t *testing.T) {
+	userSvc, userRepo, _, invitationRepo, eventRepo := setupInvitationTest(t, nil)
 	ctx := context.Background()
 
 	tenantID := uuid.New()
-	creatorID := uuid.New()
+	inviterID := uuid.New()
 
-	resp, err := userSvc.Create(ctx, CreateUserRequest{
+	resp, err := userSvc.Invite(ctx, InviteRequest{
 		Email:     "new@example.com",
 		FirstName: "New",
 		LastName:  "User",
 		TenantID:  tenantID,
 		Role:      domain.RoleWaiter,
-		CreatedBy: creatorID,
+		InvitedBy: inviterID,
 		IPAddress: "127.0.0.1",
 	}, domain.RoleManager)
 
 	if err != nil {
-		t.Fatalf("Create failed: %v", err)
+		t.Fatalf("Invite failed: %v", err)
 	}
-
-	if resp.User == nil {
-		t.Fatal("User should not be nil")
+	if resp.Token == "" {
+		t.Error("Token should not be empty")
+	}
+	if resp.ExistingAccount {
+		t.Error("ExistingAccount should be false for a new email")
+	}
+	if resp.Invitation.TokenHash == resp.Token {
+		t.Error("Invitation should store the token hash, not the token")
+	}
+	if time.Until(resp.Invitation.ExpiresAt) < domain.InvitationTTL-time.Minute {
+		t.Errorf("ExpiresAt = %v, want about %v from now", resp.Invitation.ExpiresAt, domain.InvitationTTL)
 	}
-	if resp.User.Email != "new@example.com" {
-		t.Errorf("Email = %q, want %q", resp.User.Email, "new@example.com")
+
+	// Nothing is created until the invitee accepts
+	if _, err := userRepo.FindByEmail(ctx, "new@example.com"); err != domain.ErrUserNotFound {
+		t.Errorf("no user should exist before acceptance, got %v", err)
 	}
-	if resp.TemporaryPassword == "" {
-		t.Error("TemporaryPassword should not be empty")
+	pending, _ := invitationRepo.ListPendingByTenant(ctx, tenantID)
+	if len(pending) != 1 {
+		t.Errorf("expected 1 pending invitation, got %d", len(pending))
 	}
-	if !resp.User.MustResetPwd {
-		t.Error("MustResetPwd should be true")
+	if !hasEventType(eventRepo, domain.EventInvitationSent) {
+		t.Error("expected an invitation_sent AuthEvent to be recorded")
 	}
 }
 
-func TestUserService_Create_CannotAssignRole(t *testing.T) {
-	userSvc, _, _, _, _ := setupUserService(t)
+func TestUserService_Invite_CannotAssignRole(t *testing.T) {
+	userSvc, _, _, _, _ := setupInvitationTest(t, nil)
 	ctx := context.Background()
 
-	// Waiter trying to create a Manager
-	_, err := userSvc.Create(ctx, CreateUserRequest{
+	// Waiter trying to invite a Manager
+	_, err := userSvc.Invite(ctx, InviteRequest{
 		Email:    "new@example.com",
 		TenantID: uuid.New(),
 		Role:     domain.RoleManager,
@@ -96,8 +169,8 @@ func TestUserService_Create_CannotAssignRole(t *testing.T) {
 	}
 }
 
-func TestUserService_Create_EmailExistsInSameTenant(t *testing.T) {
-	userSvc, userRepo, _, _, _ := setupUserService(t)
+func TestUserService_Invite_EmailExistsInSameTenant(t *testing.T) {
+	userSvc, userRepo, _, _, _ := setupInvitationTest(t, nil)
 	ctx := context.Background()
 
 	tenantID := uuid.New()
@@ -110,7 +183,7 @@ func TestUserService_Create_EmailExistsInSameTenant(t *testing.T) {
 		},
 	})
 
-	_, err := userSvc.Create(ctx, CreateUserRequest{
+	_, err := userSvc.Invite(ctx, InviteRequest{
 		Email:    "existing@example.com",
 		TenantID: tenantID,
 		Role:     domain.RoleWaiter,
@@ -121,100 +194,287 @@ func TestUserService_Create_EmailExistsInSameTenant(t *testing.T) {
 	}
 }
 
-func TestUserService_Create_LinksExistingUserToNewTenant(t *testing.T) {
-	userSvc, userRepo, _, _, _ := setupUserService(t)
+func TestUserService_Invite_ExistingAccountInOtherTenant(t *testing.T) {
+	userSvc, userRepo, roleRepo, _, _ := setupInvitationTest(t, nil)
 	ctx := context.Background()
 
 	existingID := uuid.New()
-	otherTenantID := uuid.New()
 	userRepo.AddUser(&domain.User{
 		ID:    existingID,
 		Email: "existing@example.com",
 		TenantRoles: []domain.UserTenantRole{
-			{UserID: existingID, TenantID: otherTenantID, Role: domain.RoleWaiter},
+			{UserID: existingID, TenantID: uuid.New(), Role: domain.RoleWaiter},
 		},
 	})
 
-	newTenantID := uuid.New()
-	resp, err := userSvc.Create(ctx, CreateUserRequest{
+	resp, err := userSvc.Invite(ctx, InviteRequest{
 		Email:    "existing@example.com",
-		TenantID: newTenantID,
+		TenantID: uuid.New(),
 		Role:     domain.RoleManager,
 	}, domain.RoleAdmin)
 
 	if err != nil {
-		t.Fatalf("Create failed: %v", err)
+		t.Fatalf("Invite failed: %v", err)
 	}
-	if !resp.LinkedExistingAccount {
-		t.Error("expected LinkedExistingAccount to be true")
+	if !resp.ExistingAccount {
+		t.Error("expected ExistingAccount to be true")
 	}
-	if resp.TemporaryPassword != "" {
-		t.Error("linked existing user must not get a new temporary password")
-	}
-	if resp.User.ID != existingID {
-		t.Errorf("expected the same existing user, got a different ID")
+	// The existing user is not linked until they accept
+	if roles, _ := roleRepo.ListByUser(ctx, existingID); len(roles) != 0 {
+		t.Errorf("existing user should not be linked before accepting, got %d roles", len(roles))
 	}
 }
 
-func TestUserService_Create_LogsEmailFailure(t *testing.T) {
-	userRepo := mock.NewMockUserRepository()
-	roleRepo := mock.NewMockUserTenantRoleRepository()
-	sessionRepo := mock.NewMockSessionRepository()
-	eventRepo := mock.NewMockAuthEventRepository()
-	passwordResetRepo := mock.NewMockPasswordResetRepository()
-	emailer := &failingEmailer{err: errors.New("smtp timeout")}
+func TestUserService_Invite_Pending(t *testing.T) {
+	userSvc, _, _, invitationRepo, _ := setupInvitationTest(t, nil)
+	ctx := context.Background()
 
-	userSvc := NewUserService(UserServiceConfig{
-		UserRepo: userRepo, RoleRepo: roleRepo, SessionRepo: sessionRepo,
-		EventRepo: eventRepo, PasswordReset: passwordResetRepo, Emailer: emailer,
-	})
+	tenantID := uuid.New()
+	req := InviteRequest{Email: "new@example.com", TenantID: tenantID, Role: domain.RoleWaiter}
+
+	first, err := userSvc.Invite(ctx, req, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("Invite failed: %v", err)
+	}
+	if _, err := userSvc.Invite(ctx, req, domain.RoleManager); err != domain.ErrInvitationPending {
+		t.Errorf("Expected ErrInvitationPending, got %v", err)
+	}
+
+	// An expired invitation is replaced rather than blocking a new one
+	first.Invitation.ExpiresAt = time.Now().Add(-time.Minute)
+	second, err := userSvc.Invite(ctx, req, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("Invite after expiry failed: %v", err)
+	}
+	if second.Invitation.ID == first.Invitation.ID {
+		t.Error("expected a new invitation")
+	}
+	if !first.Invitation.IsRevoked() {
+		t.Error("expired invitation should be revoked when replaced")
+	}
+	if pending, _ := invitationRepo.ListPendingByTenant(ctx, tenantID); len(pending) != 1 {
+		t.Errorf("expected 1 pending invitation, got %d", len(pending))
+	}
+}
+
+func TestUserService_Invite_LogsEmailFailure(t *testing.T) {
+	userSvc, _, _, _, eventRepo := setupInvitationTest(t, &failingEmailer{err: errors.New("smtp timeout")})
 	ctx := context.Background()
 
-	resp, err := userSvc.Create(ctx, CreateUserRequest{
+	resp, err := userSvc.Invite(ctx, InviteRequest{
 		Email: "new@example.com", FirstName: "New", LastName: "User",
 		TenantID: uuid.New(), Role: domain.RoleWaiter,
 	}, domain.RoleManager)
 
 	if err != nil {
-		t.Fatalf("Create should still succeed when email delivery fails: %v", err)
+		t.Fatalf("Invite should still succeed when email delivery fails: %v", err)
 	}
-	if resp.TemporaryPassword == "" {
-		t.Error("TemporaryPassword should still be generated even if the email fails")
+	if resp.Invitation == nil {
+		t.Error("Invitation should still be stored even if the email fails")
 	}
 	if !hasEventType(eventRepo, domain.EventEmailDeliveryFailed) {
 		t.Error("expected an email_delivery_failed AuthEvent to be recorded")
 	}
 }
 
-func TestUserService_LinkExistingUser_LogsEmailFailure(t *testing.T) {
-	userRepo := mock.NewMockUserRepository()
-	roleRepo := mock.NewMockUserTenantRoleRepository()
-	sessionRepo := mock.NewMockSessionRepository()
-	eventRepo := mock.NewMockAuthEventRepository()
-	passwordResetRepo := mock.NewMockPasswordResetRepository()
-	emailer := &failingEmailer{err: errors.New("smtp timeout")}
+func TestUserService_AcceptInvitation_NewAccount(t *testing.T) {
+	userSvc, userRepo, roleRepo, invitationRepo, eventRepo := setupInvitationTest(t, nil)
+	ctx := context.Background()
 
-	userSvc := NewUserService(UserServiceConfig{
-		UserRepo: userRepo, RoleRepo: roleRepo, SessionRepo: sessionRepo,
-		EventRepo: eventRepo, PasswordReset: passwordResetRepo, Emailer: emailer,
-	})
+	tenantID := uuid.New()
+	invitation := addInvitation(invitationRepo, "new@example.com", "invite-token", tenantID, domain.RoleCashier)
+
+	resp, err := userSvc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: "invite-token", Password: "NewPassword123!"})
+	if err != nil {
+		t.Fatalf("AcceptInvitation failed: %v", err)
+	}
+	if resp.ExistingAccount {
+		t.Error("ExistingAccount should be false")
+	}
+	if resp.TenantID != tenantID || resp.Role != domain.RoleCashier {
+		t.Errorf("joined %s as %s, want %s as cashier", resp.TenantID, resp.Role, tenantID)
+	}
+
+	user, err := userRepo.FindByEmail(ctx, "new@example.com")
+	if err != nil {
+		t.Fatalf("user should be created: %v", err)
+	}
+	if user.MustResetPwd {
+		t.Error("MustResetPwd should be false; the invitee chose their own password")
+	}
+	if user.FirstName != invitation.FirstName || user.LastName != invitation.LastName {
+		t.Errorf("name = %s %s, want %s %s", user.FirstName, user.LastName, invitation.FirstName, invitation.LastName)
+	}
+	if match, _ := NewPasswordService().Verify("NewPassword123!", user.PasswordHash); !match {
+		t.Error("password should be the one chosen by the invitee")
+	}
+	if role, err := roleRepo.FindByUserAndTenant(ctx, user.ID, tenantID); err != nil || role.Role != domain.RoleCashier {
+		t.Errorf("expected a cashier role in the tenant, got %v, %v", role, err)
+	}
+	if !invitation.IsAccepted() {
+		t.Error("invitation should be marked accepted")
+	}
+	if !hasEventType(eventRepo, domain.EventInvitationAccepted) {
+		t.Error("expected an invitation_accepted AuthEvent to be recorded")
+	}
+
+	// Single use
+	if _, err := userSvc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: "invite-token", Password: "NewPassword123!"}); err != domain.ErrInvitationAccepted {
+		t.Errorf("second accept: expected ErrInvitationAccepted, got %v", err)
+	}
+}
+
+func TestUserService_AcceptInvitation_ExistingAccount(t *testing.T) {
+	userSvc, userRepo, roleRepo, invitationRepo, _ := setupInvitationTest(t, nil)
 	ctx := context.Background()
 
+	hash, _ := NewPasswordService().Hash("Existing123!")
 	existingID := uuid.New()
 	userRepo.AddUser(&domain.User{
-		ID: existingID, Email: "existing@example.com",
+		ID: existingID, Email: "existing@example.com", PasswordHash: hash, IsActive: true,
 		TenantRoles: []domain.UserTenantRole{{UserID: existingID, TenantID: uuid.New(), Role: domain.RoleWaiter}},
 	})
+	tenantID := uuid.New()
+	invitation := addInvitation(invitationRepo, "existing@example.com", "invite-token", tenantID, domain.RoleManager)
 
-	_, err := userSvc.Create(ctx, CreateUserRequest{
-		Email: "existing@example.com", TenantID: uuid.New(), Role: domain.RoleManager,
-	}, domain.RoleAdmin)
+	// The existing account must be confirmed with its own password
+	_, err := userSvc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: "invite-token", Password: "SomethingElse123!"})
+	if err != domain.ErrInvalidCredentials {
+		t.Fatalf("wrong password: expected ErrInvalidCredentials, got %v", err)
+	}
+	if !invitation.IsValid() {
+		t.Error("a failed confirmation must not use up the invitation")
+	}
 
+	resp, err := userSvc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: "invite-token", Password: "Existing123!"})
 	if err != nil {
-		t.Fatalf("Create should still succeed when email delivery fails: %v", err)
+		t.Fatalf("AcceptInvitation failed: %v", err)
 	}
-	if !hasEventType(eventRepo, domain.EventEmailDeliveryFailed) {
-		t.Error("expected an email_delivery_failed AuthEvent to be recorded")
+	if !resp.ExistingAccount {
+		t.Error("ExistingAccount should be true")
+	}
+	if resp.User.ID != existingID {
+		t.Error("expected the existing user, not a new account")
+	}
+	if role, err := roleRepo.FindByUserAndTenant(ctx, existingID, tenantID); err != nil || role.Role != domain.RoleManager {
+		t.Errorf("expected a manager role in the tenant, got %v, %v", role, err)
+	}
+}
+
+func TestUserService_AcceptInvitation_Errors(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		token    string
+		password string
+		modify   func(inv *domain.Invitation)
+		wantErr  error
+	}{
+		{name: "unknown token", token: "wrong-token", password: "NewPassword123!", wantErr: domain.ErrInvitationInvalid},
+		{name: "expired", token: "invite-token", password: "NewPassword123!", modify: func(inv *domain.Invitation) { inv.ExpiresAt = now.Add(-time.Hour) }, wantErr: domain.ErrInvitationExpired},
+		{name: "revoked", token: "invite-token", password: "NewPassword123!", modify: func(inv *domain.Invitation) { inv.RevokedAt = &now }, wantErr: domain.ErrInvitationRevoked},
+		{name: "accepted", token: "invite-token", password: "NewPassword123!", modify: func(inv *domain.Invitation) { inv.AcceptedAt = &now }, wantErr: domain.ErrInvitationAccepted},
+		{name: "weak password", token: "invite-token", password: "weak", wantErr: domain.ErrPasswordWeak},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userSvc, userRepo, _, invitationRepo, _ := setupInvitationTest(t, nil)
+			invitation := addInvitation(invitationRepo, "new@example.com", "invite-token", uuid.New(), domain.RoleWaiter)
+			if tt.modify != nil {
+				tt.modify(invitation)
+			}
+
+			_, err := userSvc.AcceptInvitation(context.Background(), AcceptInvitationRequest{Token: tt.token, Password: tt.password})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+			if _, err := userRepo.FindByEmail(context.Background(), "new@example.com"); err != domain.ErrUserNotFound {
+				t.Error("no account should be created")
+			}
+		})
+	}
+}
+
+func TestUserService_ResendInvitation(t *testing.T) {
+	userSvc, _, _, invitationRepo, eventRepo := setupInvitationTest(t, nil)
+	ctx := context.Background()
+
+	tenantID := uuid.New()
+	invitation := addInvitation(invitationRepo, "new@example.com", "old-token", tenantID, domain.RoleWaiter)
+	invitation.ExpiresAt = time.Now().Add(-time.Hour)
+
+	resp, err := userSvc.ResendInvitation(ctx, InvitationActionRequest{InvitationID: invitation.ID, TenantID: tenantID}, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("ResendInvitation failed: %v", err)
+	}
+	if resp.Token == "" || resp.Token == "old-token" {
+		t.Error("expected a fresh token")
+	}
+	if !invitation.IsValid() {
+		t.Error("resending should restart the expiry")
+	}
+	if !hasEventType(eventRepo, domain.EventInvitationResent) {
+		t.Error("expected an invitation_resent AuthEvent to be recorded")
+	}
+
+	if _, err := userSvc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: "old-token", Password: "NewPassword123!"}); !errors.Is(err, domain.ErrInvitationInvalid) {
+		t.Errorf("old token: expected ErrInvitationInvalid, got %v", err)
+	}
+	if _, err := userSvc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: resp.Token, Password: "NewPassword123!"}); err != nil {
+		t.Errorf("new token should be accepted: %v", err)
+	}
+}
+
+func TestUserService_InvitationActions_Authorization(t *testing.T) {
+	userSvc, _, _, invitationRepo, _ := setupInvitationTest(t, nil)
+	ctx := context.Background()
+
+	tenantID := uuid.New()
+	invitation := addInvitation(invitationRepo, "admin@example.com", "invite-token", tenantID, domain.RoleAdmin)
+
+	// Invitations in another tenant are invisible
+	otherTenant := InvitationActionRequest{InvitationID: invitation.ID, TenantID: uuid.New()}
+	if _, err := userSvc.ResendInvitation(ctx, otherTenant, domain.RoleOwner); err != domain.ErrInvitationNotFound {
+		t.Errorf("other tenant resend: expected ErrInvitationNotFound, got %v", err)
+	}
+	if err := userSvc.RevokeInvitation(ctx, otherTenant, domain.RoleOwner); err != domain.ErrInvitationNotFound {
+		t.Errorf("other tenant revoke: expected ErrInvitationNotFound, got %v", err)
+	}
+
+	// A manager cannot manage an invitation for an admin
+	sameTenant := InvitationActionRequest{InvitationID: invitation.ID, TenantID: tenantID}
+	if _, err := userSvc.ResendInvitation(ctx, sameTenant, domain.RoleManager); err != domain.ErrCannotAssignRole {
+		t.Errorf("manager resend: expected ErrCannotAssignRole, got %v", err)
+	}
+	if err := userSvc.RevokeInvitation(ctx, sameTenant, domain.RoleManager); err != domain.ErrCannotAssignRole {
+		t.Errorf("manager revoke: expected ErrCannotAssignRole, got %v", err)
+	}
+}
+
+func TestUserService_RevokeInvitation(t *testing.T) {
+	userSvc, _, _, invitationRepo, eventRepo := setupInvitationTest(t, nil)
+	ctx := context.Background()
+
+	tenantID := uuid.New()
+	invitation := addInvitation(invitationRepo, "new@example.com", "invite-token", tenantID, domain.RoleWaiter)
+	req := InvitationActionRequest{InvitationID: invitation.ID, TenantID: tenantID}
+
+	if err := userSvc.RevokeInvitation(ctx, req, domain.RoleManager); err != nil {
+		t.Fatalf("RevokeInvitation failed: %v", err)
+	}
+	if !hasEventType(eventRepo, domain.EventInvitationRevoked) {
+		t.Error("expected an invitation_revoked AuthEvent to be recorded")
+	}
+	if err := userSvc.RevokeInvitation(ctx, req, domain.RoleManager); err != domain.ErrInvitationNotFound {
+		t.Errorf("second revoke: expected ErrInvitationNotFound, got %v", err)
+	}
+	if _, err := userSvc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: "invite-token", Password: "NewPassword123!"}); err != domain.ErrInvitationRevoked {
+		t.Errorf("accept after revoke: expected ErrInvitationRevoked, got %v", err)
+	}
+	if pending, _ := userSvc.ListInvitations(ctx, tenantID); len(pending) != 0 {
+		t.Errorf("revoked invitation should not be listed, got %d", len(pending))
 	}
 }
 
@@ -232,7 +492,8 @@ func TestUserService_RequestPasswordReset_LogsEmailFailure(t *testing.T) {
 	})
 	ctx := context.Background()
 
-	userRepo.AddUser(&domain.User{ID: uuid.New(), Email: "reset@example.com"})
+	verifiedAt := time.Now()
+	userRepo.AddUser(&domain.User{ID: uuid.New(), Email: "reset@example.com", EmailVerifiedAt: &verifiedAt})
 
 	if err := userSvc.RequestPasswordReset(ctx, "reset@example.com", "127.0.0.1"); err != nil {
 		t.Fatalf("RequestPasswordReset should still succeed when email delivery fails: %v", err)
@@ -511,119 +772,1035 @@ func TestUserService_UpdateRole_CannotAssign(t *testing.T) {
 	}
 }
 
-func TestUserService_List(t *testing.T) {
-	userSvc, userRepo, _, _, _ := setupUserService(t)
+func TestUserService_UpdateRole_CustomRole(t *testing.T) {
+	userSvc, _, roleRepo, _, _ := setupUserService(t)
+	customRoles := mock.NewMockCustomRoleRepository()
+	userSvc.customRoles = customRoles
 	ctx := context.Background()
 
 	tenantID := uuid.New()
+	userID := uuid.New()
+	assignment := &domain.UserTenantRole{ID: uuid.New(), UserID: userID, TenantID: tenantID, Role: domain.RoleViewer}
+	roleRepo.AddRole(assignment)
 
-	userRepo.ListByTenantFunc = func(ctx context.Context, tid uuid.UUID, offset, limit int) ([]*domain.User, int64, error) {
-		return []*domain.User{
-			{ID: uuid.New(), Email: "user1@example.com"},
-			{ID: uuid.New(), Email: "user2@example.com"},
-		}, 2, nil
+	bartender := &domain.CustomRole{
+		ID: uuid.New(), TenantID: tenantID, Name: "Bartender", BaseRole: domain.RoleWaiter,
+		Permissions: domain.PermissionList{domain.PermUsersManage},
+	}
+	lead := &domain.CustomRole{ID: uuid.New(), TenantID: tenantID, Name: "Shift lead", BaseRole: domain.RoleManager}
+	elsewhere := &domain.CustomRole{ID: uuid.New(), TenantID: uuid.New(), Name: "Host", BaseRole: domain.RoleViewer}
+	customRoles.AddCustomRole(bartender)
+	customRoles.AddCustomRole(lead)
+	customRoles.AddCustomRole(elsewhere)
+
+	// The custom role ranks as its base role: a manager can hand out a
+	// waiter-based role but not a manager-based one
+	err := userSvc.UpdateRole(ctx, UpdateRoleRequest{UserID: userID, TenantID: tenantID, CustomRoleID: &lead.ID}, domain.RoleManager)
+	if err != domain.ErrCannotAssignRole {
+		t.Errorf("manager-based custom role error = %v, want ErrCannotAssignRole", err)
+	}
+	err = userSvc.UpdateRole(ctx, UpdateRoleRequest{UserID: userID, TenantID: tenantID, CustomRoleID: &elsewhere.ID}, domain.RoleManager)
+	if !errors.Is(err, domain.ErrCustomRoleNotFound) {
+		t.Errorf("other tenant's custom role error = %v, want ErrCustomRoleNotFound", err)
 	}
 
-	users, total, err := userSvc.List(ctx, tenantID, 1, 20)
+	err = userSvc.UpdateRole(ctx, UpdateRoleRequest{UserID: userID, TenantID: tenantID, CustomRoleID: &bartender.ID}, domain.RoleManager)
 	if err != nil {
-		t.Fatalf("List failed: %v", err)
+		t.Fatalf("UpdateRole failed: %v", err)
+	}
+	if assignment.Role != domain.RoleWaiter || assignment.CustomRoleID == nil || *assignment.CustomRoleID != bartender.ID {
+		t.Fatalf("assignment = %s/%v, want waiter with the bartender role", assignment.Role, assignment.CustomRoleID)
+	}
+	if perms := assignment.Permissions(); len(perms) != 1 || perms[0] != domain.PermUsersManage {
+		t.Errorf("Permissions() = %v, want the bartender's", perms)
 	}
 
-	if len(users) != 2 {
-		t.Errorf("len(users) = %d, want 2", len(users))
+	// Assigning a built-in role clears the custom one
+	err = userSvc.UpdateRole(ctx, UpdateRoleRequest{UserID: userID, TenantID: tenantID, NewRole: domain.RoleCashier}, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("UpdateRole failed: %v", err)
 	}
-	if total != 2 {
-		t.Errorf("total = %d, want 2", total)
+	if assignment.Role != domain.RoleCashier || assignment.CustomRoleID != nil || assignment.CustomRole != nil {
+		t.Errorf("assignment = %s/%v, want plain cashier", assignment.Role, assignment.CustomRoleID)
 	}
 }
 
-func TestUserService_List_Pagination(t *testing.T) {
-	userSvc, userRepo, _, _, _ := setupUserService(t)
-	ctx := context.Background()
+type elevationTestEnv struct {
+	svc         *UserService
+	roleRepo    *mock.MockUserTenantRoleRepository
+	sessionRepo *mock.MockSessionRepository
+	eventRepo   *mock.MockAuthEventRepository
+	revocations *repository.MemoryTokenRevocationStore
+	waiter      *domain.UserTenantRole
+}
 
-	called := false
-	userRepo.ListByTenantFunc = func(ctx context.Context, tid uuid.UUID, offset, limit int) ([]*domain.User, int64, error) {
-		called = true
-		// Verify pagination parameters
-		if offset != 20 {
-			t.Errorf("offset = %d, want 20", offset)
-		}
-		if limit != 10 {
-			t.Errorf("limit = %d, want 10", limit)
-		}
-		return []*domain.User{}, 0, nil
+// setupElevationTest returns a UserService with a waiter in tenant to elevate.
+func setupElevationTest(t *testing.T) *elevationTestEnv {
+	t.Helper()
+
+	env := &elevationTestEnv{
+		roleRepo:    mock.NewMockUserTenantRoleRepository(),
+		sessionRepo: mock.NewMockSessionRepository(),
+		eventRepo:   mock.NewMockAuthEventRepository(),
+		revocations: repository.NewMemoryTokenRevocationStore(time.Minute),
 	}
+	env.svc = NewUserService(UserServiceConfig{
+		UserRepo:        mock.NewMockUserRepository(),
+		RoleRepo:        env.roleRepo,
+		SessionRepo:     env.sessionRepo,
+		EventRepo:       env.eventRepo,
+		PasswordReset:   mock.NewMockPasswordResetRepository(),
+		RevocationStore: env.revocations,
+		CustomRoles:     mock.NewMockCustomRoleRepository(),
+	})
 
-	userSvc.List(ctx, uuid.New(), 3, 10) // page 3, limit 10 => offset 20
+	env.waiter = &domain.UserTenantRole{ID: uuid.New(), UserID: uuid.New(), TenantID: uuid.New(), Role: domain.RoleWaiter}
+	env.roleRepo.AddRole(env.waiter)
+	return env
+}
 
-	if !called {
-		t.Error("ListByTenantFunc should have been called")
+// accessTokensRevoked reports whether a token the waiter was issued a
+// couple of seconds ago is now revoked.
+func (env *elevationTestEnv) accessTokensRevoked(t *testing.T) bool {
+	t.Helper()
+	revoked, err := env.revocations.IsRevoked(context.Background(), "", env.waiter.UserID, time.Now().Add(-2*time.Second))
+	if err != nil {
+		t.Fatalf("IsRevoked failed: %v", err)
 	}
+	return revoked
 }
 
-func TestUserService_List_DefaultPagination(t *testing.T) {
-	userSvc, userRepo, _, _, _ := setupUserService(t)
+func TestUserService_ElevateRole(t *testing.T) {
+	env := setupElevationTest(t)
 	ctx := context.Background()
 
-	userRepo.ListByTenantFunc = func(ctx context.Context, tid uuid.UUID, offset, limit int) ([]*domain.User, int64, error) {
-		if limit != 20 {
-			t.Errorf("default limit = %d, want 20", limit)
-		}
-		if offset != 0 {
-			t.Errorf("default offset = %d, want 0", offset)
-		}
-		return []*domain.User{}, 0, nil
+	managerID := uuid.New()
+	validUntil := time.Now().Add(8 * time.Hour)
+	_, err := env.svc.ElevateRole(ctx, ElevateRoleRequest{
+		UserID:     env.waiter.UserID,
+		TenantID:   env.waiter.TenantID,
+		NewRole:    domain.RoleCashier,
+		ValidUntil: validUntil,
+		ElevatedBy: managerID,
+	}, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("ElevateRole failed: %v", err)
 	}
 
-	// Invalid page/limit should use defaults
-	userSvc.List(ctx, uuid.New(), 0, 0)
+	if env.waiter.Role != domain.RoleWaiter {
+		t.Errorf("standing role = %q, want it kept as waiter", env.waiter.Role)
+	}
+	if env.waiter.EffectiveRole() != domain.RoleCashier {
+		t.Errorf("EffectiveRole() = %q, want cashier", env.waiter.EffectiveRole())
+	}
+	if env.waiter.ElevationStartedAt == nil || env.waiter.ElevatedBy == nil || *env.waiter.ElevatedBy != managerID {
+		t.Errorf("elevation started at %v by %v, want started now by the manager", env.waiter.ElevationStartedAt, env.waiter.ElevatedBy)
+	}
+	if !env.accessTokensRevoked(t) {
+		t.Error("access tokens issued under the standing role should be revoked")
+	}
+	if !hasEventType(env.eventRepo, domain.EventRoleElevated) || !hasEventType(env.eventRepo, domain.EventRoleElevationStarted) {
+		t.Error("expected role_elevated and role_elevation_started events")
+	}
 }
 
-func TestUserService_ChangePassword_Success(t *testing.T) {
-	userSvc, userRepo, _, sessionRepo, _ := setupUserService(t)
+func TestUserService_ElevateRole_Scheduled(t *testing.T) {
+	env := setupElevationTest(t)
 	ctx := context.Background()
 
-	userID := uuid.New()
-	passwordHash, _ := NewPasswordService().Hash("OldPassword123!")
-
-	userRepo.AddUser(&domain.User{
-		ID:           userID,
-		Email:        "test@example.com",
-		PasswordHash: passwordHash,
-		MustResetPwd: true,
-	})
-
-	err := userSvc.ChangePassword(ctx, ChangePasswordRequest{
-		UserID:          userID,
-		CurrentPassword: "OldPassword123!",
-		NewPassword:     "NewPassword456!",
-		IPAddress:       "127.0.0.1",
-	})
-
+	validFrom := time.Now().Add(time.Hour)
+	_, err := env.svc.ElevateRole(ctx, ElevateRoleRequest{
+		UserID:     env.waiter.UserID,
+		TenantID:   env.waiter.TenantID,
+		NewRole:    domain.RoleCashier,
+		ValidFrom:  &validFrom,
+		ValidUntil: validFrom.Add(4 * time.Hour),
+	}, domain.RoleManager)
 	if err != nil {
-		t.Fatalf("ChangePassword failed: %v", err)
+		t.Fatalf("ElevateRole failed: %v", err)
 	}
 
-	// Verify password was changed
-	user, _ := userRepo.FindByID(ctx, userID)
-	match, _ := NewPasswordService().Verify("NewPassword456!", user.PasswordHash)
-	if !match {
-		t.Error("Password should have been changed")
+	if env.waiter.EffectiveRole() != domain.RoleWaiter || env.waiter.ElevationStartedAt != nil {
+		t.Errorf("scheduled elevation: EffectiveRole() = %q, started %v, want waiter and not started", env.waiter.EffectiveRole(), env.waiter.ElevationStartedAt)
 	}
-	if user.MustResetPwd {
-		t.Error("MustResetPwd should be false after change")
+	if env.accessTokensRevoked(t) {
+		t.Error("a scheduled elevation should not revoke access tokens yet")
+	}
+	if hasEventType(env.eventRepo, domain.EventRoleElevationStarted) {
+		t.Error("a scheduled elevation should not be logged as started yet")
 	}
+}
 
-	// Verify sessions were revoked (mock doesn't fail)
-	_ = sessionRepo
+func TestUserService_ElevateRole_Errors(t *testing.T) {
+	env := setupElevationTest(t)
+	ctx := context.Background()
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	later := now.Add(2 * time.Hour)
+
+	owner := &domain.UserTenantRole{ID: uuid.New(), UserID: uuid.New(), TenantID: env.waiter.TenantID, Role: domain.RoleAdmin}
+	env.roleRepo.AddRole(owner)
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		newRole    domain.Role
+		validFrom  *time.Time
+		validUntil time.Time
+		caller     domain.Role
+		want       error
+	}{
+		{"ends in the past", env.waiter.UserID, domain.RoleCashier, nil, past, domain.RoleManager, domain.ErrElevationWindow},
+		{"ends before it starts", env.waiter.UserID, domain.RoleCashier, &later, now.Add(time.Hour), domain.RoleManager, domain.ErrElevationWindow},
+		{"too long", env.waiter.UserID, domain.RoleCashier, nil, now.Add(domain.MaxRoleElevation + time.Hour), domain.RoleManager, domain.ErrElevationWindow},
+		{"demotion", env.waiter.UserID, domain.RoleViewer, nil, later, domain.RoleManager, domain.ErrElevationNotAbove},
+		{"same role", env.waiter.UserID, domain.RoleWaiter, nil, later, domain.RoleManager, domain.ErrElevationNotAbove},
+		{"to caller's own role", env.waiter.UserID, domain.RoleManager, nil, later, domain.RoleManager, domain.ErrCannotAssignRole},
+		{"higher-ranked user", owner.UserID, domain.RoleCashier, nil, later, domain.RoleManager, domain.ErrCannotManageRole},
+		{"not in tenant", uuid.New(), domain.RoleCashier, nil, later, domain.RoleManager, domain.ErrUserNotInTenant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := env.svc.ElevateRole(ctx, ElevateRoleRequest{
+				UserID:     tt.userID,
+				TenantID:   env.waiter.TenantID,
+				NewRole:    tt.newRole,
+				ValidFrom:  tt.validFrom,
+				ValidUntil: tt.validUntil,
+			}, tt.caller)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ElevateRole error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if env.waiter.HasElevation() {
+		t.Error("a rejected elevation should not be recorded")
+	}
 }
 
-func TestUserService_ChangePassword_WrongCurrent(t *testing.T) {
-	userSvc, userRepo, _, _, _ := setupUserService(t)
+func TestUserService_ElevateRole_CustomRole(t *testing.T) {
+	env := setupElevationTest(t)
 	ctx := context.Background()
 
-	userID := uuid.New()
-	passwordHash, _ := NewPasswordService().Hash("CorrectPassword123!")
+	// A custom role on the waiter's own base role still counts as an
+	// elevation: it grants other permissions
+	lead := &domain.CustomRole{
+		ID: uuid.New(), TenantID: env.waiter.TenantID, Name: "Shift lead", BaseRole: domain.RoleWaiter,
+		Permissions: domain.PermissionList{domain.PermUsersManage},
+	}
+	env.svc.customRoles.(*mock.MockCustomRoleRepository).AddCustomRole(lead)
+
+	_, err := env.svc.ElevateRole(ctx, ElevateRoleRequest{
+		UserID:       env.waiter.UserID,
+		TenantID:     env.waiter.TenantID,
+		CustomRoleID: &lead.ID,
+		ValidUntil:   time.Now().Add(8 * time.Hour),
+	}, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("ElevateRole failed: %v", err)
+	}
+	if env.waiter.RoleName() != "Shift lead" {
+		t.Errorf("RoleName() = %q, want Shift lead", env.waiter.RoleName())
+	}
+	if perms := env.waiter.Permissions(); len(perms) != 1 || perms[0] != domain.PermUsersManage {
+		t.Errorf("Permissions() = %v, want the shift lead's", perms)
+	}
+}
+
+func TestUserService_ElevateRole_Extend(t *testing.T) {
+	env := setupElevationTest(t)
+	ctx := context.Background()
+
+	validFrom := time.Now().Add(-time.Hour)
+	validUntil := time.Now().Add(time.Hour)
+	env.waiter.ElevatedRole = domain.RoleCashier
+	env.waiter.ValidFrom, env.waiter.ValidUntil, env.waiter.ElevationStartedAt = &validFrom, &validUntil, &validFrom
+
+	_, err := env.svc.ElevateRole(ctx, ElevateRoleRequest{
+		UserID:     env.waiter.UserID,
+		TenantID:   env.waiter.TenantID,
+		NewRole:    domain.RoleCashier,
+		ValidUntil: time.Now().Add(3 * time.Hour),
+	}, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("ElevateRole failed: %v", err)
+	}
+
+	// Extending keeps the original start, so the sessions issued since then
+	// are still revoked when it ends
+	if !env.waiter.ValidFrom.Equal(validFrom) || !env.waiter.IsElevated() {
+		t.Errorf("extended elevation from %v, elevated = %v, want still running from %v", env.waiter.ValidFrom, env.waiter.IsElevated(), validFrom)
+	}
+	if hasEventType(env.eventRepo, domain.EventRoleElevationEnded) {
+		t.Error("extending a running elevation should not end it")
+	}
+}
+
+func TestUserService_EndElevation(t *testing.T) {
+	env := setupElevationTest(t)
+	ctx := context.Background()
+
+	validFrom := time.Now().Add(-time.Hour)
+	validUntil := time.Now().Add(time.Hour)
+	env.waiter.ElevatedRole = domain.RoleCashier
+	env.waiter.ValidFrom, env.waiter.ValidUntil, env.waiter.ElevationStartedAt = &validFrom, &validUntil, &validFrom
+
+	before := &domain.Session{ID: uuid.New(), UserID: env.waiter.UserID, TenantID: env.waiter.TenantID, RefreshToken: "before", CreatedAt: validFrom.Add(-time.Hour), ExpiresAt: validUntil}
+	during := &domain.Session{ID: uuid.New(), UserID: env.waiter.UserID, TenantID: env.waiter.TenantID, RefreshToken: "during", CreatedAt: validFrom.Add(time.Minute), ExpiresAt: validUntil}
+	env.sessionRepo.Create(ctx, before)
+	env.sessionRepo.Create(ctx, during)
+
+	err := env.svc.EndElevation(ctx, EndElevationRequest{UserID: env.waiter.UserID, TenantID: env.waiter.TenantID, EndedBy: uuid.New()}, domain.RoleCashier)
+	if err != domain.ErrCannotManageRole {
+		t.Errorf("EndElevation by a cashier error = %v, want ErrCannotManageRole", err)
+	}
+
+	err = env.svc.EndElevation(ctx, EndElevationRequest{UserID: env.waiter.UserID, TenantID: env.waiter.TenantID, EndedBy: uuid.New()}, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("EndElevation failed: %v", err)
+	}
+
+	if env.waiter.HasElevation() || env.waiter.EffectiveRole() != domain.RoleWaiter {
+		t.Errorf("after EndElevation: EffectiveRole() = %q, want waiter with no elevation", env.waiter.EffectiveRole())
+	}
+	if before.IsRevoked() || !during.IsRevoked() {
+		t.Errorf("revoked before/during = %v/%v, want only the session issued during the elevation", before.IsRevoked(), during.IsRevoked())
+	}
+	if !env.accessTokensRevoked(t) {
+		t.Error("access tokens issued under the elevated role should be revoked")
+	}
+	if !hasEventType(env.eventRepo, domain.EventRoleElevationEnded) {
+		t.Error("expected a role_elevation_ended event")
+	}
+
+	err = env.svc.EndElevation(ctx, EndElevationRequest{UserID: env.waiter.UserID, TenantID: env.waiter.TenantID}, domain.RoleManager)
+	if err != domain.ErrNoElevation {
+		t.Errorf("EndElevation without an elevation error = %v, want ErrNoElevation", err)
+	}
+}
+
+func TestUserService_ProcessRoleElevations(t *testing.T) {
+	env := setupElevationTest(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// The waiter's elevation has ended; a cashier's scheduled one has begun
+	validFrom, validUntil := now.Add(-8*time.Hour), now.Add(-time.Minute)
+	env.waiter.ElevatedRole = domain.RoleCashier
+	env.waiter.ValidFrom, env.waiter.ValidUntil, env.waiter.ElevationStartedAt = &validFrom, &validUntil, &validFrom
+	during := &domain.Session{ID: uuid.New(), UserID: env.waiter.UserID, TenantID: env.waiter.TenantID, RefreshToken: "during", CreatedAt: validFrom.Add(time.Hour), ExpiresAt: now.Add(time.Hour)}
+	env.sessionRepo.Create(ctx, during)
+
+	startFrom, startUntil := now.Add(-time.Minute), now.Add(time.Hour)
+	cashier := &domain.UserTenantRole{
+		ID: uuid.New(), UserID: uuid.New(), TenantID: env.waiter.TenantID, Role: domain.RoleCashier,
+		ElevatedRole: domain.RoleManager, ValidFrom: &startFrom, ValidUntil: &startUntil,
+	}
+	env.roleRepo.AddRole(cashier)
+
+	processed, err := env.svc.ProcessRoleElevations(ctx, now)
+	if err != nil {
+		t.Fatalf("ProcessRoleElevations failed: %v", err)
+	}
+	if processed != 2 {
+		t.Errorf("processed = %d, want 2", processed)
+	}
+
+	if env.waiter.HasElevation() {
+		t.Error("the ended elevation should be cleared")
+	}
+	if !during.IsRevoked() {
+		t.Error("the session issued under the expired elevation should be revoked")
+	}
+	if !env.accessTokensRevoked(t) {
+		t.Error("access tokens issued under the expired elevation should be revoked")
+	}
+	if !hasEventType(env.eventRepo, domain.EventRoleElevationExpired) {
+		t.Error("expected a role_elevation_expired event")
+	}
+
+	if cashier.ElevationStartedAt == nil || cashier.EffectiveRole() != domain.RoleManager {
+		t.Errorf("started elevation: started at %v, EffectiveRole() = %q, want started and manager", cashier.ElevationStartedAt, cashier.EffectiveRole())
+	}
+	if !hasEventType(env.eventRepo, domain.EventRoleElevationStarted) {
+		t.Error("expected a role_elevation_started event")
+	}
+
+	// Nothing is left to do on the next run
+	if processed, _ := env.svc.ProcessRoleElevations(ctx, now); processed != 0 {
+		t.Errorf("second run processed = %d, want 0", processed)
+	}
+}
+
+func TestUserService_UpdateRole_ClearsElevation(t *testing.T) {
+	env := setupElevationTest(t)
+	ctx := context.Background()
+
+	validFrom, validUntil := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	env.waiter.ElevatedRole = domain.RoleCashier
+	env.waiter.ValidFrom, env.waiter.ValidUntil = &validFrom, &validUntil
+
+	err := env.svc.UpdateRole(ctx, UpdateRoleRequest{UserID: env.waiter.UserID, TenantID: env.waiter.TenantID, NewRole: domain.RoleKitchen}, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("UpdateRole failed: %v", err)
+	}
+	if env.waiter.HasElevation() || env.waiter.EffectiveRole() != domain.RoleKitchen {
+		t.Errorf("after UpdateRole: EffectiveRole() = %q, elevated = %v, want kitchen and no elevation", env.waiter.EffectiveRole(), env.waiter.HasElevation())
+	}
+}
+
+// addEmployeeSessions gives a waiter two active sessions in tenantID and one
+// in another tenant.
+func addEmployeeSessions(t *testing.T, roleRepo *mock.MockUserTenantRoleRepository, sessionRepo *mock.MockSessionRepository, tenantID uuid.UUID) (uuid.UUID, []*domain.Session) {
+	t.Helper()
+
+	userID := uuid.New()
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: userID, TenantID: tenantID, Role: domain.RoleWaiter})
+
+	sessions := []*domain.Session{
+		{ID: uuid.New(), UserID: userID, TenantID: tenantID, RefreshToken: "a", ExpiresAt: time.Now().Add(time.Hour)},
+		{ID: uuid.New(), UserID: userID, TenantID: tenantID, RefreshToken: "b", ExpiresAt: time.Now().Add(time.Hour)},
+		{ID: uuid.New(), UserID: userID, TenantID: uuid.New(), RefreshToken: "c", ExpiresAt: time.Now().Add(time.Hour)},
+	}
+	for _, s := range sessions {
+		if err := sessionRepo.Create(context.Background(), s); err != nil {
+			t.Fatalf("Create session failed: %v", err)
+		}
+	}
+	return userID, sessions
+}
+
+func TestUserService_ListUserSessions(t *testing.T) {
+	userSvc, _, roleRepo, sessionRepo, _ := setupUserService(t)
+	tenantID := uuid.New()
+	userID, _ := addEmployeeSessions(t, roleRepo, sessionRepo, tenantID)
+
+	sessions, err := userSvc.ListUserSessions(context.Background(), userID, tenantID, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("ListUserSessions failed: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Errorf("Expected only the 2 sessions in this tenant, got %d", len(sessions))
+	}
+}
+
+func TestUserService_ListUserSessions_Authorization(t *testing.T) {
+	userSvc, _, roleRepo, sessionRepo, _ := setupUserService(t)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	userID, _ := addEmployeeSessions(t, roleRepo, sessionRepo, tenantID)
+
+	if _, err := userSvc.ListUserSessions(ctx, userID, tenantID, domain.RoleWaiter); !errors.Is(err, domain.ErrCannotManageRole) {
+		t.Errorf("Expected ErrCannotManageRole for a peer, got %v", err)
+	}
+	if _, err := userSvc.ListUserSessions(ctx, userID, uuid.New(), domain.RoleOwner); !errors.Is(err, domain.ErrUserNotInTenant) {
+		t.Errorf("Expected ErrUserNotInTenant for another tenant, got %v", err)
+	}
+}
+
+func TestUserService_RevokeUserSessions_All(t *testing.T) {
+	userSvc, _, roleRepo, sessionRepo, _ := setupUserService(t)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	userID, sessions := addEmployeeSessions(t, roleRepo, sessionRepo, tenantID)
+	eventRepo := userSvc.eventRepo.(*mock.MockAuthEventRepository)
+
+	err := userSvc.RevokeUserSessions(ctx, RevokeUserSessionsRequest{
+		UserID:    userID,
+		TenantID:  tenantID,
+		RevokedBy: uuid.New(),
+	}, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("RevokeUserSessions failed: %v", err)
+	}
+
+	for i, s := range sessions {
+		found, _ := sessionRepo.FindByID(ctx, s.ID)
+		inTenant := i < 2
+		if found.IsRevoked() != inTenant {
+			t.Errorf("session %d: revoked = %v, want %v", i, found.IsRevoked(), inTenant)
+		}
+	}
+	if !hasEventType(eventRepo, domain.EventSessionRevoked) {
+		t.Error("Expected session_revoked event")
+	}
+}
+
+func TestUserService_RevokeUserSessions_One(t *testing.T) {
+	userSvc, _, roleRepo, sessionRepo, _ := setupUserService(t)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	userID, sessions := addEmployeeSessions(t, roleRepo, sessionRepo, tenantID)
+
+	req := RevokeUserSessionsRequest{UserID: userID, TenantID: tenantID, SessionID: sessions[0].ID}
+	if err := userSvc.RevokeUserSessions(ctx, req, domain.RoleManager); err != nil {
+		t.Fatalf("RevokeUserSessions failed: %v", err)
+	}
+
+	found, _ := sessionRepo.FindByID(ctx, sessions[0].ID)
+	if !found.IsRevoked() {
+		t.Error("Selected session should be revoked")
+	}
+	found, _ = sessionRepo.FindByID(ctx, sessions[1].ID)
+	if found.IsRevoked() {
+		t.Error("Other session should not be revoked")
+	}
+
+	// A session from another tenant can't be reached through this tenant
+	req.SessionID = sessions[2].ID
+	if err := userSvc.RevokeUserSessions(ctx, req, domain.RoleManager); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestUserService_RevokeUserSessions_CannotManage(t *testing.T) {
+	userSvc, _, roleRepo, _, _ := setupUserService(t)
+	tenantID := uuid.New()
+	managerID := uuid.New()
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: managerID, TenantID: tenantID, Role: domain.RoleManager})
+
+	// Manager trying to sign out another manager
+	err := userSvc.RevokeUserSessions(context.Background(), RevokeUserSessionsRequest{UserID: managerID, TenantID: tenantID}, domain.RoleManager)
+	if !errors.Is(err, domain.ErrCannotManageRole) {
+		t.Errorf("Expected ErrCannotManageRole, got %v", err)
+	}
+}
+
+func TestUserService_RemoveFromTenant(t *testing.T) {
+	userSvc, _, roleRepo, sessionRepo, _ := setupUserService(t)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	userID, sessions := addEmployeeSessions(t, roleRepo, sessionRepo, tenantID)
+	otherTenantID := sessions[2].TenantID
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: userID, TenantID: otherTenantID, Role: domain.RoleWaiter})
+	eventRepo := userSvc.eventRepo.(*mock.MockAuthEventRepository)
+
+	err := userSvc.RemoveFromTenant(ctx, RemoveFromTenantRequest{
+		UserID:    userID,
+		TenantID:  tenantID,
+		RemovedBy: uuid.New(),
+	}, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("RemoveFromTenant failed: %v", err)
+	}
+
+	if _, err := roleRepo.FindByUserAndTenant(ctx, userID, tenantID); !errors.Is(err, domain.ErrUserNotInTenant) {
+		t.Errorf("Expected role in tenant to be removed, got %v", err)
+	}
+	if _, err := roleRepo.FindByUserAndTenant(ctx, userID, otherTenantID); err != nil {
+		t.Errorf("Role in other tenant should be kept: %v", err)
+	}
+	for i, s := range sessions {
+		found, _ := sessionRepo.FindByID(ctx, s.ID)
+		inTenant := i < 2
+		if found.IsRevoked() != inTenant {
+			t.Errorf("session %d: revoked = %v, want %v", i, found.IsRevoked(), inTenant)
+		}
+	}
+	if !hasEventType(eventRepo, domain.EventTenantRoleRemoved) {
+		t.Error("Expected tenant_role_removed event")
+	}
+}
+
+func TestUserService_RemoveFromTenant_Authorization(t *testing.T) {
+	userSvc, _, roleRepo, _, _ := setupUserService(t)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	managerID := uuid.New()
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: managerID, TenantID: tenantID, Role: domain.RoleManager})
+
+	// Manager trying to remove another manager
+	err := userSvc.RemoveFromTenant(ctx, RemoveFromTenantRequest{UserID: managerID, TenantID: tenantID, RemovedBy: uuid.New()}, domain.RoleManager)
+	if !errors.Is(err, domain.ErrCannotManageRole) {
+		t.Errorf("Expected ErrCannotManageRole, got %v", err)
+	}
+
+	err = userSvc.RemoveFromTenant(ctx, RemoveFromTenantRequest{UserID: managerID, TenantID: uuid.New(), RemovedBy: uuid.New()}, domain.RoleOwner)
+	if !errors.Is(err, domain.ErrUserNotInTenant) {
+		t.Errorf("Expected ErrUserNotInTenant for another tenant, got %v", err)
+	}
+
+	// Anyone may leave a tenant themselves
+	err = userSvc.RemoveFromTenant(ctx, RemoveFromTenantRequest{UserID: managerID, TenantID: tenantID, RemovedBy: managerID}, domain.RoleManager)
+	if err != nil {
+		t.Errorf("Self-removal failed: %v", err)
+	}
+}
+
+func TestUserService_RemoveFromTenant_LastOwner(t *testing.T) {
+	userSvc, _, roleRepo, _, _ := setupUserService(t)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	ownerID := uuid.New()
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: ownerID, TenantID: tenantID, Role: domain.RoleOwner})
+
+	req := RemoveFromTenantRequest{UserID: ownerID, TenantID: tenantID, RemovedBy: ownerID}
+	if err := userSvc.RemoveFromTenant(ctx, req, domain.RoleOwner); !errors.Is(err, domain.ErrLastOwner) {
+		t.Fatalf("Expected ErrLastOwner, got %v", err)
+	}
+	if _, err := roleRepo.FindByUserAndTenant(ctx, ownerID, tenantID); err != nil {
+		t.Errorf("Last owner should keep their role: %v", err)
+	}
+
+	// With a second owner in place the first one can leave
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, Role: domain.RoleOwner})
+	if err := userSvc.RemoveFromTenant(ctx, req, domain.RoleOwner); err != nil {
+		t.Errorf("RemoveFromTenant with another owner failed: %v", err)
+	}
+}
+
+func TestUserService_Update_DeactivateLastOwner(t *testing.T) {
+	userSvc, userRepo, roleRepo, _, _ := setupUserService(t)
+	ctx := context.Background()
+	ownerID := uuid.New()
+	otherTenantID := uuid.New()
+	ownerRole := domain.UserTenantRole{ID: uuid.New(), UserID: ownerID, TenantID: otherTenantID, Role: domain.RoleOwner}
+	roleRepo.AddRole(&ownerRole)
+	userRepo.AddUser(&domain.User{ID: ownerID, Email: "owner@example.com", IsActive: true, TenantRoles: []domain.UserTenantRole{ownerRole}})
+
+	// Disabling the account would leave the other tenant without a usable owner
+	isActive := false
+	_, err := userSvc.Update(ctx, UpdateRequest{UserID: ownerID, TenantID: uuid.New(), IsActive: &isActive}, domain.RoleAdmin)
+	if !errors.Is(err, domain.ErrLastOwner) {
+		t.Errorf("Expected ErrLastOwner, got %v", err)
+	}
+}
+
+func TestUserService_LastOwner_OtherOwnerDisabled(t *testing.T) {
+	userSvc, userRepo, roleRepo, _, _ := setupUserService(t)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	owner := domain.UserTenantRole{ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, Role: domain.RoleOwner}
+	disabled := domain.UserTenantRole{ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, Role: domain.RoleOwner}
+	roleRepo.AddRole(&owner)
+	roleRepo.AddRole(&disabled)
+	userRepo.AddUser(&domain.User{ID: owner.UserID, Email: "owner@example.com", IsActive: true, TenantRoles: []domain.UserTenantRole{owner}})
+	userRepo.AddUser(&domain.User{ID: disabled.UserID, Email: "disabled@example.com", IsActive: false, TenantRoles: []domain.UserTenantRole{disabled}})
+
+	// A disabled second owner can't run the tenant, so it doesn't count
+	err := userSvc.RemoveFromTenant(ctx, RemoveFromTenantRequest{UserID: owner.UserID, TenantID: tenantID, RemovedBy: owner.UserID}, domain.RoleOwner)
+	if !errors.Is(err, domain.ErrLastOwner) {
+		t.Errorf("RemoveFromTenant: expected ErrLastOwner, got %v", err)
+	}
+	isActive := false
+	_, err = userSvc.Update(ctx, UpdateRequest{UserID: owner.UserID, TenantID: uuid.New(), IsActive: &isActive}, domain.RoleAdmin)
+	if !errors.Is(err, domain.ErrLastOwner) {
+		t.Errorf("Update: expected ErrLastOwner, got %v", err)
+	}
+
+	if role, err := roleRepo.FindByUserAndTenant(ctx, owner.UserID, tenantID); err != nil || role.Role != domain.RoleOwner {
+		t.Errorf("Owner should keep their role, got %v, %v", role, err)
+	}
+	if user, _ := userRepo.FindByID(ctx, owner.UserID); !user.IsActive {
+		t.Error("Owner should stay active")
+	}
+}
+
+func TestUserService_LastOwner_Concurrent(t *testing.T) {
+	tests := []struct {
+		name string
+		// second takes the owner role away from the second owner while the
+		// first owner leaves the tenant.
+		second func(userSvc *UserService, userID, tenantID uuid.UUID) error
+	}{
+		{"both leave", func(userSvc *UserService, userID, tenantID uuid.UUID) error {
+			return userSvc.RemoveFromTenant(context.Background(), RemoveFromTenantRequest{UserID: userID, TenantID: tenantID, RemovedBy: userID}, domain.RoleOwner)
+		}},
+		{"one leaves, one is disabled", func(userSvc *UserService, userID, tenantID uuid.UUID) error {
+			isActive := false
+			_, err := userSvc.Update(context.Background(), UpdateRequest{UserID: userID, TenantID: uuid.New(), IsActive: &isActive}, domain.RoleAdmin)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userSvc, userRepo, roleRepo, _, _ := setupUserService(t)
+			ctx := context.Background()
+			tenantID := uuid.New()
+			first := domain.UserTenantRole{ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, Role: domain.RoleOwner}
+			second := domain.UserTenantRole{ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, Role: domain.RoleOwner}
+			roleRepo.AddRole(&first)
+			roleRepo.AddRole(&second)
+			userRepo.AddUser(&domain.User{ID: first.UserID, Email: "first@example.com", IsActive: true})
+			userRepo.AddUser(&domain.User{ID: second.UserID, Email: "second@example.com", IsActive: true})
+			// Hand out copies, as a database would, so the goroutines don't
+			// share the stored users
+			userRepo.FindByIDWithTenantsFunc = func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+				user, err := userRepo.FindByID(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				userCopy := *user
+				return &userCopy, nil
+			}
+
+			start := make(chan struct{})
+			errs := make(chan error, 2)
+			go func() {
+				<-start
+				errs <- userSvc.RemoveFromTenant(ctx, RemoveFromTenantRequest{UserID: first.UserID, TenantID: tenantID, RemovedBy: first.UserID}, domain.RoleOwner)
+			}()
+			go func() {
+				<-start
+				errs <- tt.second(userSvc, second.UserID, tenantID)
+			}()
+			close(start)
+
+			var succeeded, refused int
+			for i := 0; i < 2; i++ {
+				switch err := <-errs; {
+				case err == nil:
+					succeeded++
+				case errors.Is(err, domain.ErrLastOwner):
+					refused++
+				default:
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if succeeded != 1 || refused != 1 {
+				t.Errorf("succeeded = %d, refused with ErrLastOwner = %d, want 1 and 1", succeeded, refused)
+			}
+		})
+	}
+}
+
+// setupTransferTest builds a UserService with an owner and a manager in one
+// tenant, both with password "Password123!".
+func setupTransferTest(t *testing.T, emailer Emailer) (*UserService, *mock.MockUserRepository, *mock.MockUserTenantRoleRepository, *mock.MockOwnershipTransferRepository, *mock.MockAuthEventRepository, *domain.UserTenantRole, *domain.UserTenantRole) {
+	t.Helper()
+
+	userRepo := mock.NewMockUserRepository()
+	roleRepo := mock.NewMockUserTenantRoleRepository()
+	transferRepo := mock.NewMockOwnershipTransferRepository()
+	eventRepo := mock.NewMockAuthEventRepository()
+
+	userSvc := NewUserService(UserServiceConfig{
+		UserRepo:      userRepo,
+		RoleRepo:      roleRepo,
+		SessionRepo:   mock.NewMockSessionRepository(),
+		EventRepo:     eventRepo,
+		PasswordReset: mock.NewMockPasswordResetRepository(),
+		Transfers:     transferRepo,
+		Emailer:       emailer,
+	})
+
+	hash, _ := NewPasswordService().Hash("Password123!")
+	tenantID := uuid.New()
+	owner := &domain.UserTenantRole{ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, Role: domain.RoleOwner}
+	manager := &domain.UserTenantRole{ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, Role: domain.RoleManager}
+	roleRepo.AddRole(owner)
+	roleRepo.AddRole(manager)
+	userRepo.AddUser(&domain.User{ID: owner.UserID, Email: "owner@example.com", PasswordHash: hash, IsActive: true})
+	userRepo.AddUser(&domain.User{ID: manager.UserID, Email: "manager@example.com", PasswordHash: hash, IsActive: true})
+
+	return userSvc, userRepo, roleRepo, transferRepo, eventRepo, owner, manager
+}
+
+func TestUserService_StartOwnershipTransfer(t *testing.T) {
+	userSvc, _, roleRepo, transferRepo, eventRepo, owner, manager := setupTransferTest(t, nil)
+	ctx := context.Background()
+
+	resp, err := userSvc.StartOwnershipTransfer(ctx, StartOwnershipTransferRequest{
+		TenantID:   owner.TenantID,
+		FromUserID: owner.UserID,
+		ToUserID:   manager.UserID,
+	})
+	if err != nil {
+		t.Fatalf("StartOwnershipTransfer failed: %v", err)
+	}
+	if resp.Token == "" || resp.Transfer.TokenHash == resp.Token {
+		t.Error("Transfer should store the token hash and return the token")
+	}
+	if _, err := transferRepo.FindPendingByTenant(ctx, owner.TenantID); err != nil {
+		t.Errorf("Expected a pending transfer, got %v", err)
+	}
+	if !hasEventType(eventRepo, domain.EventOwnerTransferStarted) {
+		t.Error("Expected ownership_transfer_started event")
+	}
+
+	// Nothing changes until the recipient confirms
+	if role, _ := roleRepo.FindByUserAndTenant(ctx, manager.UserID, manager.TenantID); role.Role != domain.RoleManager {
+		t.Errorf("Recipient role = %s before confirmation, want manager", role.Role)
+	}
+
+	_, err = userSvc.StartOwnershipTransfer(ctx, StartOwnershipTransferRequest{TenantID: owner.TenantID, FromUserID: owner.UserID, ToUserID: manager.UserID})
+	if !errors.Is(err, domain.ErrOwnershipTransferPending) {
+		t.Errorf("Expected ErrOwnershipTransferPending, got %v", err)
+	}
+}
+
+func TestUserService_StartOwnershipTransfer_Errors(t *testing.T) {
+	userSvc, userRepo, roleRepo, _, _, owner, manager := setupTransferTest(t, nil)
+	ctx := context.Background()
+
+	disabledID := uuid.New()
+	roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: disabledID, TenantID: owner.TenantID, Role: domain.RoleWaiter})
+	userRepo.AddUser(&domain.User{ID: disabledID, Email: "disabled@example.com", IsActive: false})
+
+	tests := []struct {
+		name    string
+		from    uuid.UUID
+		to      uuid.UUID
+		wantErr error
+	}{
+		{"caller is not owner", manager.UserID, owner.UserID, domain.ErrInsufficientRole},
+		{"transfer to self", owner.UserID, owner.UserID, domain.ErrOwnershipTransferTarget},
+		{"recipient not in tenant", owner.UserID, uuid.New(), domain.ErrOwnershipTransferTarget},
+		{"recipient disabled", owner.UserID, disabledID, domain.ErrOwnershipTransferTarget},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := userSvc.StartOwnershipTransfer(ctx, StartOwnershipTransferRequest{TenantID: owner.TenantID, FromUserID: tt.from, ToUserID: tt.to})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUserService_ConfirmOwnershipTransfer(t *testing.T) {
+	userSvc, _, roleRepo, _, eventRepo, owner, manager := setupTransferTest(t, nil)
+	ctx := context.Background()
+
+	started, err := userSvc.StartOwnershipTransfer(ctx, StartOwnershipTransferRequest{TenantID: owner.TenantID, FromUserID: owner.UserID, ToUserID: manager.UserID})
+	if err != nil {
+		t.Fatalf("StartOwnershipTransfer failed: %v", err)
+	}
+
+	// The recipient must re-authenticate
+	_, err = userSvc.ConfirmOwnershipTransfer(ctx, ConfirmOwnershipTransferRequest{Token: started.Token, Password: "WrongPassword1!"})
+	if !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Fatalf("Expected ErrInvalidCredentials, got %v", err)
+	}
+	if !started.Transfer.IsValid() {
+		t.Error("A failed confirmation must not use up the transfer")
+	}
+
+	resp, err := userSvc.ConfirmOwnershipTransfer(ctx, ConfirmOwnershipTransferRequest{Token: started.Token, Password: "Password123!"})
+	if err != nil {
+		t.Fatalf("ConfirmOwnershipTransfer failed: %v", err)
+	}
+	if resp.PreviousOwnerRole != domain.RoleManager {
+		t.Errorf("PreviousOwnerRole = %s, want manager", resp.PreviousOwnerRole)
+	}
+
+	if role, _ := roleRepo.FindByUserAndTenant(ctx, manager.UserID, manager.TenantID); role.Role != domain.RoleOwner {
+		t.Errorf("Recipient role = %s, want owner", role.Role)
+	}
+	if role, _ := roleRepo.FindByUserAndTenant(ctx, owner.UserID, owner.TenantID); role.Role != domain.RoleManager {
+		t.Errorf("Previous owner role = %s, want manager", role.Role)
+	}
+	if !hasEventType(eventRepo, domain.EventOwnerTransferred) {
+		t.Error("Expected ownership_transferred event")
+	}
+
+	_, err = userSvc.ConfirmOwnershipTransfer(ctx, ConfirmOwnershipTransferRequest{Token: started.Token, Password: "Password123!"})
+	if !errors.Is(err, domain.ErrOwnershipTransferUsed) {
+		t.Errorf("Expected ErrOwnershipTransferUsed on reuse, got %v", err)
+	}
+}
+
+func TestUserService_ConfirmOwnershipTransfer_Errors(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		transfer func(*domain.OwnershipTransfer)
+		wantErr  error
+	}{
+		{"expired", func(tr *domain.OwnershipTransfer) { tr.ExpiresAt = now.Add(-time.Minute) }, domain.ErrOwnershipTransferExpired},
+		{"cancelled", func(tr *domain.OwnershipTransfer) { tr.CancelledAt = &now }, domain.ErrOwnershipTransferCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userSvc, _, _, transferRepo, _, owner, manager := setupTransferTest(t, nil)
+			transfer := &domain.OwnershipTransfer{
+				ID:         uuid.New(),
+				TenantID:   owner.TenantID,
+				FromUserID: owner.UserID,
+				ToUserID:   manager.UserID,
+				TokenHash:  NewPasswordService().HashResetToken("transfer-token"),
+				ExpiresAt:  now.Add(domain.OwnershipTransferTTL),
+			}
+			tt.transfer(transfer)
+			transferRepo.AddTransfer(transfer)
+
+			_, err := userSvc.ConfirmOwnershipTransfer(context.Background(), ConfirmOwnershipTransferRequest{Token: "transfer-token", Password: "Password123!"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	userSvc, _, _, _, _, _, _ := setupTransferTest(t, nil)
+	_, err := userSvc.ConfirmOwnershipTransfer(context.Background(), ConfirmOwnershipTransferRequest{Token: "unknown", Password: "Password123!"})
+	if !errors.Is(err, domain.ErrOwnershipTransferInvalid) {
+		t.Errorf("Expected ErrOwnershipTransferInvalid, got %v", err)
+	}
+}
+
+func TestUserService_ConfirmOwnershipTransfer_EmailFailure(t *testing.T) {
+	userSvc, _, roleRepo, _, eventRepo, owner, manager := setupTransferTest(t, &failingEmailer{err: errors.New("smtp down")})
+	ctx := context.Background()
+
+	started, err := userSvc.StartOwnershipTransfer(ctx, StartOwnershipTransferRequest{TenantID: owner.TenantID, FromUserID: owner.UserID, ToUserID: manager.UserID})
+	if err != nil {
+		t.Fatalf("StartOwnershipTransfer should not fail on email errors: %v", err)
+	}
+	if _, err := userSvc.ConfirmOwnershipTransfer(ctx, ConfirmOwnershipTransferRequest{Token: started.Token, Password: "Password123!"}); err != nil {
+		t.Fatalf("ConfirmOwnershipTransfer should not fail on email errors: %v", err)
+	}
+
+	if role, _ := roleRepo.FindByUserAndTenant(ctx, manager.UserID, manager.TenantID); role.Role != domain.RoleOwner {
+		t.Errorf("Recipient role = %s, want owner", role.Role)
+	}
+	if !hasEventType(eventRepo, domain.EventEmailDeliveryFailed) {
+		t.Error("Expected email_delivery_failed event")
+	}
+}
+
+func TestUserService_CancelOwnershipTransfer(t *testing.T) {
+	userSvc, _, _, _, eventRepo, owner, manager := setupTransferTest(t, nil)
+	ctx := context.Background()
+
+	cancelReq := CancelOwnershipTransferRequest{TenantID: owner.TenantID, CancelledBy: owner.UserID}
+	if err := userSvc.CancelOwnershipTransfer(ctx, cancelReq); !errors.Is(err, domain.ErrOwnershipTransferNotFound) {
+		t.Errorf("Expected ErrOwnershipTransferNotFound with nothing pending, got %v", err)
+	}
+
+	started, err := userSvc.StartOwnershipTransfer(ctx, StartOwnershipTransferRequest{TenantID: owner.TenantID, FromUserID: owner.UserID, ToUserID: manager.UserID})
+	if err != nil {
+		t.Fatalf("StartOwnershipTransfer failed: %v", err)
+	}
+	if err := userSvc.CancelOwnershipTransfer(ctx, cancelReq); err != nil {
+		t.Fatalf("CancelOwnershipTransfer failed: %v", err)
+	}
+	if !hasEventType(eventRepo, domain.EventOwnerTransferCancelled) {
+		t.Error("Expected ownership_transfer_cancelled event")
+	}
+
+	_, err = userSvc.ConfirmOwnershipTransfer(ctx, ConfirmOwnershipTransferRequest{Token: started.Token, Password: "Password123!"})
+	if !errors.Is(err, domain.ErrOwnershipTransferCancelled) {
+		t.Errorf("Expected ErrOwnershipTransferCancelled, got %v", err)
+	}
+}
+
+func TestUserService_List(t *testing.T) {
+	userSvc, userRepo, _, _, _ := setupUserService(t)
+	ctx := context.Background()
+
+	tenantID := uuid.New()
+
+	userRepo.ListByTenantFunc = func(ctx context.Context, tid uuid.UUID, offset, limit int) ([]*domain.User, int64, error) {
+		return []*domain.User{
+			{ID: uuid.New(), Email: "user1@example.com"},
+			{ID: uuid.New(), Email: "user2@example.com"},
+		}, 2, nil
+	}
+
+	users, total, err := userSvc.List(ctx, tenantID, 1, 20)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if len(users) != 2 {
+		t.Errorf("len(users) = %d, want 2", len(users))
+	}
+	if total != 2 {
+		t.Errorf("total = %d, want 2", total)
+	}
+}
+
+func TestUserService_List_Pagination(t *testing.T) {
+	userSvc, userRepo, _, _, _ := setupUserService(t)
+	ctx := context.Background()
+
+	called := false
+	userRepo.ListByTenantFunc = func(ctx context.Context, tid uuid.UUID, offset, limit int) ([]*domain.User, int64, error) {
+		called = true
+		// Verify pagination parameters
+		if offset != 20 {
+			t.Errorf("offset = %d, want 20", offset)
+		}
+		if limit != 10 {
+			t.Errorf("limit = %d, want 10", limit)
+		}
+		return []*domain.User{}, 0, nil
+	}
+
+	userSvc.List(ctx, uuid.New(), 3, 10) // page 3, limit 10 => offset 20
+
+	if !called {
+		t.Error("ListByTenantFunc should have been called")
+	}
+}
+
+func TestUserService_List_DefaultPagination(t *testing.T) {
+	userSvc, userRepo, _, _, _ := setupUserService(t)
+	ctx := context.Background()
+
+	userRepo.ListByTenantFunc = func(ctx context.Context, tid uuid.UUID, offset, limit int) ([]*domain.User, int64, error) {
+		if limit != 20 {
+			t.Errorf("default limit = %d, want 20", limit)
+		}
+		if offset != 0 {
+			t.Errorf("default offset = %d, want 0", offset)
+		}
+		return []*domain.User{}, 0, nil
+	}
+
+	// Invalid page/limit should use defaults
+	userSvc.List(ctx, uuid.New(), 0, 0)
+}
+
+func TestUserService_ChangePassword_Success(t *testing.T) {
+	userSvc, userRepo, _, sessionRepo, _ := setupUserService(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	passwordHash, _ := NewPasswordService().Hash("OldPassword123!")
+
+	userRepo.AddUser(&domain.User{
+		ID:           userID,
+		Email:        "test@example.com",
+		PasswordHash: passwordHash,
+		MustResetPwd: true,
+	})
+
+	err := userSvc.ChangePassword(ctx, ChangePasswordRequest{
+		UserID:          userID,
+		CurrentPassword: "OldPassword123!",
+		NewPassword:     "NewPassword456!",
+		IPAddress:       "127.0.0.1",
+	})
+
+	if err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+
+	// Verify password was changed
+	user, _ := userRepo.FindByID(ctx, userID)
+	match, _ := NewPasswordService().Verify("NewPassword456!", user.PasswordHash)
+	if !match {
+		t.Error("Password should have been changed")
+	}
+	if user.MustResetPwd {
+		t.Error("MustResetPwd should be false after change")
+	}
+
+	// Verify sessions were revoked (mock doesn't fail)
+	_ = sessionRepo
+}
+
+func TestUserService_ChangePassword_WrongCurrent(t *testing.T) {
+	userSvc, userRepo, _, _, _ := setupUserService(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	passwordHash, _ := NewPasswordService().Hash("CorrectPassword123!")
 
 	userRepo.AddUser(&domain.User{
 		ID:           userID,
@@ -659,8 +1836,9 @@ func TestUserService_ChangePassword_WeakNew(t *testing.T) {
 		NewPassword:     "weak", // Too weak
 	})
 
-	if err != domain.ErrPasswordWeak {
-		t.Errorf("Expected ErrPasswordWeak, got %v", err)
+	var ruleErr *domain.PasswordRuleError
+	if !errors.Is(err, domain.ErrPasswordWeak) || !errors.As(err, &ruleErr) || ruleErr.Rule != domain.PasswordRuleLength {
+		t.Errorf("Expected ErrPasswordWeak for the length rule, got %v", err)
 	}
 }
 
@@ -730,12 +1908,14 @@ func TestUserService_CompletePasswordReset_Success(t *testing.T) {
 
 	userID := uuid.New()
 	passwordHash, _ := NewPasswordService().Hash("OldPassword123!")
+	verifiedAt := time.Now()
 
 	userRepo.AddUser(&domain.User{
-		ID:           userID,
-		Email:        "test@example.com",
-		PasswordHash: passwordHash,
-		MustResetPwd: true,
+		ID:              userID,
+		Email:           "test@example.com",
+		PasswordHash:    passwordHash,
+		MustResetPwd:    true,
+		EmailVerifiedAt: &verifiedAt,
 	})
 
 	// Create a valid reset token
@@ -823,12 +2003,387 @@ func TestUserService_CompletePasswordReset_TokenExpired(t *testing.T) {
 }
 
 func TestUserService_CompletePasswordReset_WeakPassword(t *testing.T) {
-	userSvc, _, _, _, _ := setupUserService(t)
+	userSvc, userRepo, _, _, passwordResetRepo := setupUserService(t)
 	ctx := context.Background()
 
-	err := userSvc.CompletePasswordReset(ctx, "any-token", "weak", "127.0.0.1")
+	userID := uuid.New()
+	verifiedAt := time.Now()
+	userRepo.AddUser(&domain.User{ID: userID, Email: "test@example.com", EmailVerifiedAt: &verifiedAt})
+	tokenID := uuid.New()
+	passwordResetRepo.AddToken(&domain.PasswordResetToken{
+		ID:        tokenID,
+		UserID:    userID,
+		TokenHash: NewPasswordService().HashResetToken("reset-token-weak"),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	err := userSvc.CompletePasswordReset(ctx, "reset-token-weak", "weak", "127.0.0.1")
 
-	if err != domain.ErrPasswordWeak {
+	if !errors.Is(err, domain.ErrPasswordWeak) {
 		t.Errorf("Expected ErrPasswordWeak, got %v", err)
 	}
+
+	// A rejected password leaves the link usable for another try
+	if err := userSvc.CompletePasswordReset(ctx, "reset-token-weak", "NewSecurePassword123!", "127.0.0.1"); err != nil {
+		t.Errorf("retry with the same token failed: %v", err)
+	}
+}
+
+func TestUserService_RevokesAccessTokens(t *testing.T) {
+	ctx := context.Background()
+	passwordHash, _ := NewPasswordService().Hash("OldPassword123!")
+
+	tests := []struct {
+		name   string
+		action func(userSvc *UserService, userID, tenantID uuid.UUID) error
+	}{
+		{"password change", func(userSvc *UserService, userID, tenantID uuid.UUID) error {
+			return userSvc.ChangePassword(ctx, ChangePasswordRequest{UserID: userID, CurrentPassword: "OldPassword123!", NewPassword: "NewPassword456!"})
+		}},
+		{"deactivation", func(userSvc *UserService, userID, tenantID uuid.UUID) error {
+			isActive := false
+			_, err := userSvc.Update(ctx, UpdateRequest{UserID: userID, TenantID: tenantID, IsActive: &isActive}, domain.RoleManager)
+			return err
+		}},
+		{"role change", func(userSvc *UserService, userID, tenantID uuid.UUID) error {
+			return userSvc.UpdateRole(ctx, UpdateRoleRequest{UserID: userID, TenantID: tenantID, NewRole: domain.RoleCashier}, domain.RoleManager)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := mock.NewMockUserRepository()
+			roleRepo := mock.NewMockUserTenantRoleRepository()
+			store := repository.NewMemoryTokenRevocationStore(time.Minute)
+			userSvc := NewUserService(UserServiceConfig{
+				UserRepo:        userRepo,
+				RoleRepo:        roleRepo,
+				SessionRepo:     mock.NewMockSessionRepository(),
+				EventRepo:       mock.NewMockAuthEventRepository(),
+				PasswordReset:   mock.NewMockPasswordResetRepository(),
+				RevocationStore: store,
+			})
+
+			userID := uuid.New()
+			tenantID := uuid.New()
+			userRepo.AddUser(&domain.User{
+				ID:           userID,
+				Email:        "test@example.com",
+				PasswordHash: passwordHash,
+				IsActive:     true,
+				TenantRoles:  []domain.UserTenantRole{{TenantID: tenantID, Role: domain.RoleWaiter}},
+			})
+			roleRepo.AddRole(&domain.UserTenantRole{ID: uuid.New(), UserID: userID, TenantID: tenantID, Role: domain.RoleWaiter})
+
+			if err := tt.action(userSvc, userID, tenantID); err != nil {
+				t.Fatalf("%s failed: %v", tt.name, err)
+			}
+
+			revoked, _ := store.IsRevoked(ctx, "", userID, time.Now().Add(-2*time.Second))
+			if !revoked {
+				t.Errorf("Access tokens issued before the %s should be revoked", tt.name)
+			}
+		})
+	}
+}
+
+// emailLinkRecorder records the latest verification, change, undo and
+// unlock link sent to each address.
+type emailLinkRecorder struct {
+	failingEmailer
+	links map[string]string
+}
+
+func (e *emailLinkRecorder) SendEmailVerification(ctx context.Context, toEmail, verifyToken string) error {
+	e.links[toEmail] = verifyToken
+	return nil
+}
+func (e *emailLinkRecorder) SendEmailChangeConfirmation(ctx context.Context, toEmail, changeToken string) error {
+	e.links[toEmail] = changeToken
+	return nil
+}
+func (e *emailLinkRecorder) SendEmailChanged(ctx context.Context, toEmail, newEmail, revertToken string) error {
+	e.links[toEmail] = revertToken
+	return nil
+}
+func (e *emailLinkRecorder) SendAccountUnlock(ctx context.Context, toEmail, unlockToken string) error {
+	e.links[toEmail] = unlockToken
+	return nil
+}
+
+type emailTestEnv struct {
+	svc         *UserService
+	userRepo    *mock.MockUserRepository
+	sessionRepo *mock.MockSessionRepository
+	resets      *mock.MockPasswordResetRepository
+	eventRepo   *mock.MockAuthEventRepository
+	emailer     *emailLinkRecorder
+	user        *domain.User
+}
+
+// setupEmailTest returns a UserService with an unverified user whose
+// password is OldPassword123!.
+func setupEmailTest(t *testing.T) *emailTestEnv {
+	t.Helper()
+
+	env := &emailTestEnv{
+		userRepo:    mock.NewMockUserRepository(),
+		sessionRepo: mock.NewMockSessionRepository(),
+		resets:      mock.NewMockPasswordResetRepository(),
+		eventRepo:   mock.NewMockAuthEventRepository(),
+		emailer:     &emailLinkRecorder{links: map[string]string{}},
+	}
+	env.svc = NewUserService(UserServiceConfig{
+		UserRepo:      env.userRepo,
+		RoleRepo:      mock.NewMockUserTenantRoleRepository(),
+		SessionRepo:   env.sessionRepo,
+		EventRepo:     env.eventRepo,
+		PasswordReset: env.resets,
+		Emailer:       env.emailer,
+		EmailTokens:   mock.NewMockEmailTokenRepository(),
+	})
+
+	passwordHash, _ := NewPasswordService().Hash("OldPassword123!")
+	env.user = &domain.User{ID: uuid.New(), Email: "ana@example.com", PasswordHash: passwordHash, IsActive: true}
+	env.userRepo.AddUser(env.user)
+	return env
+}
+
+func TestUserService_PasswordReset_RequiresVerifiedEmail(t *testing.T) {
+	env := setupEmailTest(t)
+	ctx := context.Background()
+
+	if err := env.svc.RequestPasswordReset(ctx, "ana@example.com", "127.0.0.1"); err != nil {
+		t.Fatalf("RequestPasswordReset should not reveal that the email is unverified: %v", err)
+	}
+	if n, _ := env.resets.CountRecentForUser(ctx, env.user.ID, time.Now().Add(-time.Minute)); n != 0 {
+		t.Errorf("reset tokens issued to an unverified address = %d, want 0", n)
+	}
+
+	plainToken := "reset-token-12345"
+	env.resets.AddToken(&domain.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    env.user.ID,
+		TokenHash: NewPasswordService().HashResetToken(plainToken),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	err := env.svc.CompletePasswordReset(ctx, plainToken, "NewSecurePassword123!", "127.0.0.1")
+	if !errors.Is(err, domain.ErrEmailNotVerified) {
+		t.Errorf("CompletePasswordReset() error = %v, want ErrEmailNotVerified", err)
+	}
+}
+
+func TestUserService_VerifyEmail(t *testing.T) {
+	env := setupEmailTest(t)
+	ctx := context.Background()
+
+	if err := env.svc.RequestEmailVerification(ctx, env.user.ID, "127.0.0.1"); err != nil {
+		t.Fatalf("RequestEmailVerification() error = %v", err)
+	}
+	first := env.emailer.links["ana@example.com"]
+	if err := env.svc.RequestEmailVerification(ctx, env.user.ID, "127.0.0.1"); err != nil {
+		t.Fatalf("RequestEmailVerification() error = %v", err)
+	}
+	latest := env.emailer.links["ana@example.com"]
+
+	if err := env.svc.VerifyEmail(ctx, first, "127.0.0.1"); !errors.Is(err, domain.ErrEmailTokenInvalid) {
+		t.Errorf("VerifyEmail(superseded link) error = %v, want ErrEmailTokenInvalid", err)
+	}
+	if err := env.svc.VerifyEmail(ctx, latest, "127.0.0.1"); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	if !env.user.IsEmailVerified() {
+		t.Error("email should be verified")
+	}
+	if err := env.svc.VerifyEmail(ctx, latest, "127.0.0.1"); !errors.Is(err, domain.ErrEmailTokenUsed) {
+		t.Errorf("VerifyEmail(reused link) error = %v, want ErrEmailTokenUsed", err)
+	}
+	if err := env.svc.RequestEmailVerification(ctx, env.user.ID, "127.0.0.1"); !errors.Is(err, domain.ErrEmailAlreadyVerified) {
+		t.Errorf("RequestEmailVerification() error = %v, want ErrEmailAlreadyVerified", err)
+	}
+}
+
+func TestUserService_RequestEmailVerification_RateLimited(t *testing.T) {
+	env := setupEmailTest(t)
+	ctx := context.Background()
+
+	for i := 0; i < maxEmailTokensPerHour; i++ {
+		if err := env.svc.RequestEmailVerification(ctx, env.user.ID, "127.0.0.1"); err != nil {
+			t.Fatalf("RequestEmailVerification() #%d error = %v", i+1, err)
+		}
+	}
+	if err := env.svc.RequestEmailVerification(ctx, env.user.ID, "127.0.0.1"); !errors.Is(err, domain.ErrRateLimitExceeded) {
+		t.Errorf("RequestEmailVerification() error = %v, want ErrRateLimitExceeded", err)
+	}
+}
+
+func TestUserService_RequestEmailChange_Errors(t *testing.T) {
+	env := setupEmailTest(t)
+	env.userRepo.AddUser(&domain.User{ID: uuid.New(), Email: "taken@example.com"})
+
+	tests := []struct {
+		name     string
+		newEmail string
+		password string
+		wantErr  error
+	}{
+		{"malformed address", "not-an-email", "OldPassword123!", domain.ErrEmailInvalid},
+		{"display name", "Ana <new@example.com>", "OldPassword123!", domain.ErrEmailInvalid},
+		{"same address", "ANA@example.com", "OldPassword123!", domain.ErrEmailUnchanged},
+		{"wrong password", "new@example.com", "WrongPassword1!", domain.ErrPasswordIncorrect},
+		{"address taken", "taken@example.com", "OldPassword123!", domain.ErrEmailExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := env.svc.RequestEmailChange(context.Background(), RequestEmailChangeRequest{
+				UserID: env.user.ID, NewEmail: tt.newEmail, CurrentPassword: tt.password,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RequestEmailChange() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if len(env.emailer.links) != 0 {
+		t.Errorf("rejected changes sent links to %v", env.emailer.links)
+	}
+}
+
+func TestUserService_EmailChange_ConfirmAndRevert(t *testing.T) {
+	env := setupEmailTest(t)
+	ctx := context.Background()
+	env.sessionRepo.Create(ctx, &domain.Session{ID: uuid.New(), UserID: env.user.ID, ExpiresAt: time.Now().Add(time.Hour)})
+
+	err := env.svc.RequestEmailChange(ctx, RequestEmailChangeRequest{
+		UserID: env.user.ID, NewEmail: "ana.new@example.com", CurrentPassword: "OldPassword123!",
+	})
+	if err != nil {
+		t.Fatalf("RequestEmailChange() error = %v", err)
+	}
+	if env.user.Email != "ana@example.com" {
+		t.Fatal("email must not change before the new address confirms it")
+	}
+
+	if err := env.svc.ConfirmEmailChange(ctx, env.emailer.links["ana.new@example.com"], "127.0.0.1"); err != nil {
+		t.Fatalf("ConfirmEmailChange() error = %v", err)
+	}
+	if env.user.Email != "ana.new@example.com" || !env.user.IsEmailVerified() {
+		t.Errorf("after confirming, email = %q (verified %v), want the new address verified", env.user.Email, env.user.IsEmailVerified())
+	}
+	if sessions, _ := env.sessionRepo.ListActiveForUser(ctx, env.user.ID); len(sessions) != 0 {
+		t.Errorf("active sessions after email change = %d, want 0", len(sessions))
+	}
+
+	revertToken, ok := env.emailer.links["ana@example.com"]
+	if !ok {
+		t.Fatal("the previous address should be sent an undo link")
+	}
+	if err := env.svc.RevertEmailChange(ctx, revertToken, "127.0.0.1"); err != nil {
+		t.Fatalf("RevertEmailChange() error = %v", err)
+	}
+	if env.user.Email != "ana@example.com" || !env.user.MustResetPwd {
+		t.Errorf("after undoing, email = %q (must reset %v), want the previous address and a forced reset", env.user.Email, env.user.MustResetPwd)
+	}
+	if !hasEventType(env.eventRepo, domain.EventEmailChangeReverted) {
+		t.Error("expected an email_change_reverted AuthEvent to be recorded")
+	}
+	if err := env.svc.RevertEmailChange(ctx, revertToken, "127.0.0.1"); !errors.Is(err, domain.ErrEmailTokenUsed) {
+		t.Errorf("RevertEmailChange(reused link) error = %v, want ErrEmailTokenUsed", err)
+	}
+}
+
+func TestUserService_ConfirmEmailChange_AddressTakenSinceRequest(t *testing.T) {
+	env := setupEmailTest(t)
+	ctx := context.Background()
+
+	err := env.svc.RequestEmailChange(ctx, RequestEmailChangeRequest{
+		UserID: env.user.ID, NewEmail: "ana.new@example.com", CurrentPassword: "OldPassword123!",
+	})
+	if err != nil {
+		t.Fatalf("RequestEmailChange() error = %v", err)
+	}
+	env.userRepo.AddUser(&domain.User{ID: uuid.New(), Email: "ana.new@example.com"})
+
+	err = env.svc.ConfirmEmailChange(ctx, env.emailer.links["ana.new@example.com"], "127.0.0.1")
+	if !errors.Is(err, domain.ErrEmailExists) {
+		t.Errorf("ConfirmEmailChange() error = %v, want ErrEmailExists", err)
+	}
+	if env.user.Email != "ana@example.com" {
+		t.Errorf("email = %q, want it unchanged", env.user.Email)
+	}
+}
+
+func TestUserService_EmailTokens_WrongPurposeRejected(t *testing.T) {
+	env := setupEmailTest(t)
+	ctx := context.Background()
+
+	if err := env.svc.RequestEmailVerification(ctx, env.user.ID, "127.0.0.1"); err != nil {
+		t.Fatalf("RequestEmailVerification() error = %v", err)
+	}
+	verifyToken := env.emailer.links["ana@example.com"]
+
+	if err := env.svc.ConfirmEmailChange(ctx, verifyToken, "127.0.0.1"); !errors.Is(err, domain.ErrEmailTokenInvalid) {
+		t.Errorf("ConfirmEmailChange(verification link) error = %v, want ErrEmailTokenInvalid", err)
+	}
+	if err := env.svc.RevertEmailChange(ctx, verifyToken, "127.0.0.1"); !errors.Is(err, domain.ErrEmailTokenInvalid) {
+		t.Errorf("RevertEmailChange(verification link) error = %v, want ErrEmailTokenInvalid", err)
+	}
+}
+
+func TestUserService_AccountUnlock(t *testing.T) {
+	env := setupEmailTest(t)
+	ctx := context.Background()
+
+	// Not locked: nothing is sent
+	verifiedAt := time.Now()
+	env.user.EmailVerifiedAt = &verifiedAt
+	if err := env.svc.RequestAccountUnlock(ctx, env.user.Email, "127.0.0.1"); err != nil {
+		t.Fatalf("RequestAccountUnlock() error = %v", err)
+	}
+	if _, sent := env.emailer.links[env.user.Email]; sent {
+		t.Fatal("an unlock link should only be sent to a locked account")
+	}
+	if err := env.svc.RequestAccountUnlock(ctx, "nobody@example.com", "127.0.0.1"); err != nil {
+		t.Errorf("RequestAccountUnlock should not reveal that the email is unknown: %v", err)
+	}
+
+	lockedUntil := time.Now().Add(time.Hour)
+	env.user.FailedLoginCount, env.user.LockedUntil = 7, &lockedUntil
+	env.user.KnownDeviceFailedCount, env.user.KnownDeviceLockedUntil = 10, &lockedUntil
+	if err := env.svc.RequestAccountUnlock(ctx, env.user.Email, "127.0.0.1"); err != nil {
+		t.Fatalf("RequestAccountUnlock() error = %v", err)
+	}
+	token := env.emailer.links[env.user.Email]
+	if token == "" {
+		t.Fatal("expected an unlock link")
+	}
+
+	if err := env.svc.UnlockAccount(ctx, token, "127.0.0.1"); err != nil {
+		t.Fatalf("UnlockAccount() error = %v", err)
+	}
+	if locked, _ := env.user.IsLockedOn(true); locked || env.user.IsLocked() || env.user.FailedLoginCount != 0 {
+		t.Errorf("user still locked after unlock: %+v", env.user)
+	}
+	if !hasEventType(env.eventRepo, domain.EventAccountUnlocked) {
+		t.Error("expected an account_unlocked event")
+	}
+	if err := env.svc.UnlockAccount(ctx, token, "127.0.0.1"); !errors.Is(err, domain.ErrEmailTokenUsed) {
+		t.Errorf("UnlockAccount(reused link) error = %v, want ErrEmailTokenUsed", err)
+	}
+}
+
+func TestUserService_RequestAccountUnlock_RequiresVerifiedEmail(t *testing.T) {
+	env := setupEmailTest(t)
+	lockedUntil := time.Now().Add(time.Hour)
+	env.user.FailedLoginCount, env.user.LockedUntil = 5, &lockedUntil
+
+	if err := env.svc.RequestAccountUnlock(context.Background(), env.user.Email, "127.0.0.1"); err != nil {
+		t.Fatalf("RequestAccountUnlock should not reveal that the email is unverified: %v", err)
+	}
+	if _, sent := env.emailer.links[env.user.Email]; sent {
+		t.Error("an unlock link should not be sent to an unverified address")
+	}
+	if !hasEventType(env.eventRepo, domain.EventAccountUnlockRequested) {
+		t.Error("expected an account_unlock_requested event")
+	}
 }
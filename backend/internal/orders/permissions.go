@@ -0,0 +1,31 @@
+// Package orders handles dine-in and takeaway orders and their kitchen tickets.
+package orders
+
+import "github.com/solobueno/erp/internal/auth/domain"
+
+// Permissions declared by the orders module.
+const (
+	PermCreate domain.Permission = "orders.create"
+	PermVoid   domain.Permission = "orders.void"
+	PermBump   domain.Permission = "orders.bump"
+)
+
+func init() {
+	domain.RegisterPermissions(
+		domain.PermissionDefinition{
+			Permission:  PermCreate,
+			Description: "Open orders and add items to them",
+			Roles:       []domain.Role{domain.RoleOwner, domain.RoleAdmin, domain.RoleManager, domain.RoleCashier, domain.RoleWaiter},
+		},
+		domain.PermissionDefinition{
+			Permission:  PermVoid,
+			Description: "Void items or whole orders after they were sent to the kitchen",
+			Roles:       domain.RolesAtLeast(domain.RoleManager),
+		},
+		domain.PermissionDefinition{
+			Permission:  PermBump,
+			Description: "Mark kitchen tickets as prepared",
+			Roles:       []domain.Role{domain.RoleOwner, domain.RoleAdmin, domain.RoleManager, domain.RoleKitchen},
+		},
+	)
+}
@@ -0,0 +1,25 @@
+// Package payments handles taking payments for orders and refunding them.
+package payments
+
+import "github.com/solobueno/erp/internal/auth/domain"
+
+// Permissions declared by the payments module.
+const (
+	PermCollect domain.Permission = "payments.collect"
+	PermRefund  domain.Permission = "payments.refund"
+)
+
+func init() {
+	domain.RegisterPermissions(
+		domain.PermissionDefinition{
+			Permission:  PermCollect,
+			Description: "Take payments for orders",
+			Roles:       domain.RolesAtLeast(domain.RoleCashier),
+		},
+		domain.PermissionDefinition{
+			Permission:  PermRefund,
+			Description: "Refund a payment",
+			Roles:       domain.RolesAtLeast(domain.RoleManager),
+		},
+	)
+}
@@ -0,0 +1,19 @@
+// Package reporting produces sales and operational reports.
+package reporting
+
+import "github.com/solobueno/erp/internal/auth/domain"
+
+// Permissions declared by the reporting module.
+const (
+	PermRevenueView domain.Permission = "reporting.revenue.view"
+)
+
+func init() {
+	domain.RegisterPermissions(
+		domain.PermissionDefinition{
+			Permission:  PermRevenueView,
+			Description: "See sales and revenue figures",
+			Roles:       []domain.Role{domain.RoleOwner, domain.RoleAdmin, domain.RoleManager, domain.RoleViewer},
+		},
+	)
+}
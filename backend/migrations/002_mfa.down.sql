@@ -0,0 +1,19 @@
+-- Auth Module: Rollback TOTP multi-factor authentication
+-- This migration drops all tables created by 002_mfa.up.sql
+
+-- Restore the pre-MFA event type list. NOT VALID keeps any existing MFA
+-- audit rows (the audit trail is never rewritten) while rejecting new ones.
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed'
+)) NOT VALID;
+
+DROP TRIGGER IF EXISTS update_mfa_factors_updated_at ON mfa_factors;
+
+DROP TABLE IF EXISTS mfa_challenges;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS mfa_factors;
@@ -0,0 +1,58 @@
+-- Auth Module: TOTP multi-factor authentication
+-- Adds TOTP factors, recovery codes and login MFA challenges, and extends the
+-- auth_events type list with the MFA audit events.
+
+-- TOTP factors (one per user; pending until confirmed_at is set)
+CREATE TABLE IF NOT EXISTS mfa_factors (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    secret          VARCHAR(64) NOT NULL,
+    last_used_step  BIGINT NOT NULL DEFAULT 0,
+    confirmed_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_mfa_factors_updated_at
+    BEFORE UPDATE ON mfa_factors
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Single-use recovery codes (hashed)
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash       VARCHAR(255) NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    used_at         TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user ON mfa_recovery_codes(user_id);
+
+-- Short-lived challenges between the password step and the second factor
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    token_hash      VARCHAR(255) NOT NULL UNIQUE,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at      TIMESTAMPTZ NOT NULL,
+    used_at         TIMESTAMPTZ,
+    CONSTRAINT valid_mfa_challenge_expiry CHECK (expires_at > created_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_user ON mfa_challenges(user_id);
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_expires ON mfa_challenges(expires_at);
+
+-- Extend the auth event types (email_delivery_failed was emitted by the
+-- service but missing from 001's list)
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset'
+));
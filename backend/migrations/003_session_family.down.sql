@@ -0,0 +1,8 @@
+-- Auth Module: Rollback refresh-token family tracking
+-- This migration drops the columns added by 003_session_family.up.sql
+
+DROP INDEX IF EXISTS idx_sessions_parent;
+DROP INDEX IF EXISTS idx_sessions_family;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS parent_id;
+ALTER TABLE sessions DROP COLUMN IF EXISTS family_id;
@@ -0,0 +1,14 @@
+-- Auth Module: Refresh-token family tracking
+-- Each refresh rotates a session into a new one. family_id groups every
+-- session rotated from the same login and parent_id links a session to the
+-- one it replaced, so replaying a rotated refresh token revokes the family.
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS family_id UUID;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES sessions(id) ON DELETE SET NULL;
+
+-- Existing sessions each start their own family
+UPDATE sessions SET family_id = id WHERE family_id IS NULL;
+ALTER TABLE sessions ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_family ON sessions(family_id) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_sessions_parent ON sessions(parent_id);
@@ -0,0 +1,5 @@
+-- Auth Module: Rollback access-token revocation list
+-- This migration drops the tables created by 004_token_revocation.up.sql
+
+DROP TABLE IF EXISTS user_token_revocations;
+DROP TABLE IF EXISTS revoked_access_tokens;
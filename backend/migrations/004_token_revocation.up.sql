@@ -0,0 +1,24 @@
+-- Auth Module: Access-token revocation list
+-- Access tokens are otherwise trusted until they expire. RequireAuth checks
+-- both tables: a single token can be revoked by jti (logout), and every token
+-- issued to a user before a cutoff (logout everywhere, password change,
+-- deactivation, role change). Rows are only needed until the tokens they
+-- cover have expired.
+
+CREATE TABLE IF NOT EXISTS revoked_access_tokens (
+    jti             VARCHAR(64) PRIMARY KEY,
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    revoked_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_access_tokens_user ON revoked_access_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_revoked_access_tokens_expires ON revoked_access_tokens(expires_at);
+
+CREATE TABLE IF NOT EXISTS user_token_revocations (
+    user_id         UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    revoked_before  TIMESTAMPTZ NOT NULL,
+    expires_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_token_revocations_expires ON user_token_revocations(expires_at);
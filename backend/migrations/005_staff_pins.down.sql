@@ -0,0 +1,20 @@
+-- Auth Module: Rollback staff PIN login
+-- This migration drops all tables created by 005_staff_pins.up.sql
+
+-- Restore the pre-PIN event type list. NOT VALID keeps any existing PIN and
+-- terminal audit rows while rejecting new ones.
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset'
+)) NOT VALID;
+
+DROP TRIGGER IF EXISTS update_staff_pins_updated_at ON staff_pins;
+
+DROP TABLE IF EXISTS staff_pins;
+DROP TABLE IF EXISTS pos_terminals;
@@ -0,0 +1,51 @@
+-- Auth Module: Staff PIN login on shared POS terminals
+-- Registered terminals authenticate with a device token (stored hashed).
+-- Staff at cashier level and below can set a per-tenant PIN and log in on
+-- a terminal with it; each PIN has its own failed-attempt lockout.
+
+-- Registered POS terminals (revoked terminals are kept for the audit trail)
+CREATE TABLE IF NOT EXISTS pos_terminals (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name            VARCHAR(100) NOT NULL,
+    token_hash      VARCHAR(255) NOT NULL UNIQUE,
+    created_by      UUID REFERENCES users(id) ON DELETE SET NULL,
+    last_used_at    TIMESTAMPTZ,
+    revoked_at      TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pos_terminals_tenant ON pos_terminals(tenant_id);
+
+-- Staff PINs (one per user per tenant, hashed)
+CREATE TABLE IF NOT EXISTS staff_pins (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    pin_hash        VARCHAR(255) NOT NULL,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT staff_pins_user_tenant_unique UNIQUE (user_id, tenant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_pins_tenant ON staff_pins(tenant_id);
+
+CREATE TRIGGER update_staff_pins_updated_at
+    BEFORE UPDATE ON staff_pins
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Extend the auth event types with PIN and terminal audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked'
+));
@@ -0,0 +1,22 @@
+-- Auth Module: Rollback user invitations
+-- This migration drops all tables created by 007_user_invitations.up.sql
+
+-- Restore the pre-invitation event type list. NOT VALID keeps any existing
+-- invitation audit rows while rejecting new ones.
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched'
+)) NOT VALID;
+
+DROP TRIGGER IF EXISTS update_user_invitations_updated_at ON user_invitations;
+
+DROP TABLE IF EXISTS user_invitations;
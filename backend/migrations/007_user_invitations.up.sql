@@ -0,0 +1,51 @@
+-- Auth Module: User invitations
+-- Managers invite staff by email instead of creating accounts with emailed
+-- temporary passwords. The invite link carries a single-use token (stored
+-- hashed); the invitee sets their own password when accepting, or confirms
+-- their existing account to join the tenant.
+
+-- Invitations (accepted and revoked invitations are kept for the audit trail)
+CREATE TABLE IF NOT EXISTS user_invitations (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    email           VARCHAR(255) NOT NULL,
+    first_name      VARCHAR(100) NOT NULL,
+    last_name       VARCHAR(100) NOT NULL,
+    role            VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'admin', 'manager', 'cashier', 'waiter', 'kitchen', 'viewer')),
+    token_hash      VARCHAR(255) NOT NULL UNIQUE,
+    invited_by      UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at      TIMESTAMPTZ NOT NULL,
+    accepted_at     TIMESTAMPTZ,
+    revoked_at      TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_invitations_tenant ON user_invitations(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_user_invitations_email ON user_invitations(email);
+
+-- At most one outstanding invitation per email per tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_invitations_pending
+    ON user_invitations(tenant_id, email)
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+CREATE TRIGGER update_user_invitations_updated_at
+    BEFORE UPDATE ON user_invitations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Extend the auth event types with invitation audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted'
+));
@@ -0,0 +1,20 @@
+-- Auth Module: Tenant offboarding
+-- Managers can remove an employee from one tenant without disabling their
+-- account everywhere; each removal is audited.
+
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed'
+));
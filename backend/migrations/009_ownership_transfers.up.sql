@@ -0,0 +1,45 @@
+-- Auth Module: Ownership transfers
+-- Nobody outranks an owner, so the owner role can only change hands through
+-- a transfer: the current owner starts it, and the recipient confirms with
+-- a single-use emailed token (stored hashed) and their password. The two
+-- members' roles are then swapped.
+
+-- Transfers (confirmed and cancelled transfers are kept for the audit trail)
+CREATE TABLE IF NOT EXISTS ownership_transfers (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    from_user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash      VARCHAR(255) NOT NULL UNIQUE,
+    expires_at      TIMESTAMPTZ NOT NULL,
+    confirmed_at    TIMESTAMPTZ,
+    cancelled_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ownership_transfers_tenant ON ownership_transfers(tenant_id);
+
+-- At most one outstanding transfer per tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ownership_transfers_pending
+    ON ownership_transfers(tenant_id)
+    WHERE confirmed_at IS NULL AND cancelled_at IS NULL;
+
+-- Extend the auth event types with ownership transfer audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred'
+));
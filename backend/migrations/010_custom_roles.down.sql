@@ -0,0 +1,31 @@
+-- Auth Module: Rollback tenant custom roles
+-- This migration drops all tables and columns created by 010_custom_roles.up.sql
+
+-- Restore the pre-custom-role event type list. NOT VALID keeps any existing
+-- custom role audit rows while rejecting new ones.
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred'
+)) NOT VALID;
+
+-- Assignments fall back to their base role's default permissions
+ALTER TABLE user_tenant_roles DROP CONSTRAINT IF EXISTS user_tenant_roles_custom_role_fk;
+DROP INDEX IF EXISTS idx_utr_custom_role;
+ALTER TABLE user_tenant_roles DROP COLUMN IF EXISTS custom_role_id;
+
+DROP TRIGGER IF EXISTS update_custom_roles_updated_at ON custom_roles;
+DROP TABLE IF EXISTS custom_roles;
@@ -0,0 +1,53 @@
+-- Auth Module: Tenant custom roles
+-- Tenants define their own roles ("bartender", "host", "shift lead"). Each
+-- derives from a built-in base role, which places it in the role hierarchy,
+-- and carries its own permission set. An assignment keeps the base role in
+-- user_tenant_roles.role and points at the custom role for its permissions.
+
+CREATE TABLE IF NOT EXISTS custom_roles (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name            VARCHAR(50) NOT NULL,
+    base_role       VARCHAR(20) NOT NULL CHECK (base_role IN ('admin', 'manager', 'cashier', 'waiter', 'kitchen', 'viewer')),
+    permissions     JSONB NOT NULL DEFAULT '[]',
+    created_by      UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- Target of the same-tenant foreign key from user_tenant_roles
+    UNIQUE(id, tenant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_custom_roles_tenant ON custom_roles(tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_roles_tenant_name ON custom_roles(tenant_id, LOWER(name));
+
+CREATE TRIGGER update_custom_roles_updated_at
+    BEFORE UPDATE ON custom_roles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- A custom role can only be assigned within its own tenant, and can't be
+-- deleted while assigned
+ALTER TABLE user_tenant_roles ADD COLUMN IF NOT EXISTS custom_role_id UUID;
+ALTER TABLE user_tenant_roles ADD CONSTRAINT user_tenant_roles_custom_role_fk
+    FOREIGN KEY (custom_role_id, tenant_id) REFERENCES custom_roles(id, tenant_id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_utr_custom_role ON user_tenant_roles(custom_role_id);
+
+-- Extend the auth event types with custom role audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted'
+));
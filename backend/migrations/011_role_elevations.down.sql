@@ -0,0 +1,36 @@
+-- Auth Module: Rollback temporary role elevation
+-- This migration drops all columns created by 011_role_elevations.up.sql
+
+-- Restore the pre-elevation event type list. NOT VALID keeps any existing
+-- elevation audit rows while rejecting new ones.
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted'
+)) NOT VALID;
+
+-- Elevated members fall back to their standing role
+DROP INDEX IF EXISTS idx_utr_elevated_custom_role;
+DROP INDEX IF EXISTS idx_utr_elevation_valid_until;
+ALTER TABLE user_tenant_roles DROP CONSTRAINT IF EXISTS user_tenant_roles_elevation_window_check;
+ALTER TABLE user_tenant_roles DROP CONSTRAINT IF EXISTS user_tenant_roles_elevated_custom_role_fk;
+ALTER TABLE user_tenant_roles DROP COLUMN IF EXISTS elevation_started_at;
+ALTER TABLE user_tenant_roles DROP COLUMN IF EXISTS elevated_by;
+ALTER TABLE user_tenant_roles DROP COLUMN IF EXISTS valid_until;
+ALTER TABLE user_tenant_roles DROP COLUMN IF EXISTS valid_from;
+ALTER TABLE user_tenant_roles DROP COLUMN IF EXISTS elevated_custom_role_id;
+ALTER TABLE user_tenant_roles DROP COLUMN IF EXISTS elevated_role;
@@ -0,0 +1,47 @@
+-- Auth Module: Temporary role elevation
+-- A member can be elevated to a higher role for a time window (a waiter
+-- covering as shift lead for a night). The standing role stays in role and
+-- custom_role_id; the elevated role applies between valid_from and
+-- valid_until, after which a background expirer clears it and revokes the
+-- sessions issued under it.
+
+ALTER TABLE user_tenant_roles ADD COLUMN IF NOT EXISTS elevated_role VARCHAR(20) NOT NULL DEFAULT ''
+    CHECK (elevated_role IN ('', 'admin', 'manager', 'cashier', 'waiter', 'kitchen', 'viewer'));
+ALTER TABLE user_tenant_roles ADD COLUMN IF NOT EXISTS elevated_custom_role_id UUID;
+ALTER TABLE user_tenant_roles ADD COLUMN IF NOT EXISTS valid_from TIMESTAMPTZ;
+ALTER TABLE user_tenant_roles ADD COLUMN IF NOT EXISTS valid_until TIMESTAMPTZ;
+ALTER TABLE user_tenant_roles ADD COLUMN IF NOT EXISTS elevated_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE user_tenant_roles ADD COLUMN IF NOT EXISTS elevation_started_at TIMESTAMPTZ;
+
+ALTER TABLE user_tenant_roles ADD CONSTRAINT user_tenant_roles_elevated_custom_role_fk
+    FOREIGN KEY (elevated_custom_role_id, tenant_id) REFERENCES custom_roles(id, tenant_id) ON DELETE RESTRICT;
+ALTER TABLE user_tenant_roles ADD CONSTRAINT user_tenant_roles_elevation_window_check
+    CHECK (elevated_role = '' OR (valid_from IS NOT NULL AND valid_until > valid_from));
+
+-- The expirer scans for elevations that are due to start or end
+CREATE INDEX IF NOT EXISTS idx_utr_elevation_valid_until ON user_tenant_roles(valid_until)
+    WHERE elevated_role <> '';
+CREATE INDEX IF NOT EXISTS idx_utr_elevated_custom_role ON user_tenant_roles(elevated_custom_role_id);
+
+-- Extend the auth event types with role elevation audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired'
+));
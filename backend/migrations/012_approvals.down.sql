@@ -0,0 +1,34 @@
+-- Auth Module: Rollback step-up manager approvals
+-- This migration drops all tables created by 012_approvals.up.sql
+
+-- Restore the pre-approval event type list. NOT VALID keeps any existing
+-- approval audit rows while rejecting new ones.
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired'
+)) NOT VALID;
+
+-- PINs set by managers and above for approvals have no other use
+DELETE FROM staff_pins sp
+    USING user_tenant_roles utr
+    WHERE utr.user_id = sp.user_id AND utr.tenant_id = sp.tenant_id
+      AND utr.role IN ('owner', 'admin', 'manager');
+
+DROP TABLE IF EXISTS approvals;
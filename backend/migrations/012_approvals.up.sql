@@ -0,0 +1,54 @@
+-- Auth Module: Step-up manager approvals
+-- Sensitive actions (refunds, voids) need a manager's approval on the
+-- cashier's device. The manager enters their password, or their PIN on a
+-- registered terminal, and the cashier gets a short-lived, single-use token
+-- (stored hashed) bound to the action, the resource and the cashier. The
+-- module performing the action consumes the token.
+-- Staff PINs are no longer limited to cashier and below: managers and above
+-- can set one for approvals, though PIN login stays limited to cashier and
+-- below.
+
+-- Approvals (used and expired approvals are kept for the audit trail)
+CREATE TABLE IF NOT EXISTS approvals (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    action          VARCHAR(100) NOT NULL,
+    resource_id     VARCHAR(255) NOT NULL,
+    requested_by    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    approved_by     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method          VARCHAR(20) NOT NULL CHECK (method IN ('password', 'pin')),
+    terminal_id     UUID REFERENCES pos_terminals(id) ON DELETE SET NULL,
+    token_hash      VARCHAR(255) NOT NULL UNIQUE,
+    expires_at      TIMESTAMPTZ NOT NULL,
+    used_at         TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT approvals_not_self_check CHECK (approved_by <> requested_by)
+);
+
+CREATE INDEX IF NOT EXISTS idx_approvals_tenant ON approvals(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_approvals_requested_by ON approvals(requested_by);
+CREATE INDEX IF NOT EXISTS idx_approvals_approved_by ON approvals(approved_by);
+
+-- Extend the auth event types with approval audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired',
+    'approval_granted', 'approval_denied', 'approval_used'
+));
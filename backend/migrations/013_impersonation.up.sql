@@ -0,0 +1,34 @@
+-- Auth Module: Admin impersonation
+-- Admins can sign in as a lower-ranked user to see what they see. The
+-- impersonation access token carries the admin in its act claim, and every
+-- audit event recorded under it names the admin in actor_id next to the
+-- impersonated user_id.
+
+ALTER TABLE auth_events ADD COLUMN IF NOT EXISTS actor_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_auth_events_actor ON auth_events(actor_id) WHERE actor_id IS NOT NULL;
+
+-- Extend the auth event types with the impersonation audit event
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired',
+    'approval_granted', 'approval_denied', 'approval_used',
+    'impersonation_started'
+));
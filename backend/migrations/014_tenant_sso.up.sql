@@ -0,0 +1,80 @@
+-- Auth Module: Tenant single sign-on (OpenID Connect)
+-- A tenant can send its staff to its own identity provider instead of the
+-- password login. The provider is configured per tenant (issuer, client
+-- credentials, allowed email domains); staff are linked to their existing
+-- account by verified email on their first SSO login, and by the provider's
+-- subject identifier from then on. The tenant can also turn password login
+-- off for everyone but its owners.
+
+-- One identity provider per tenant
+CREATE TABLE IF NOT EXISTS tenant_sso_configs (
+    id                      UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id               UUID NOT NULL UNIQUE REFERENCES tenants(id) ON DELETE CASCADE,
+    issuer                  VARCHAR(500) NOT NULL,
+    client_id               VARCHAR(255) NOT NULL,
+    client_secret           VARCHAR(500) NOT NULL,
+    allowed_domains         JSONB NOT NULL DEFAULT '[]',
+    password_login_disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by              UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_tenant_sso_configs_updated_at
+    BEFORE UPDATE ON tenant_sso_configs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Pending logins: the state parameter (stored hashed) ties the provider's
+-- callback to the nonce and PKCE code verifier of the request that started it
+CREATE TABLE IF NOT EXISTS sso_login_states (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    state_hash      VARCHAR(255) NOT NULL UNIQUE,
+    nonce           VARCHAR(255) NOT NULL,
+    code_verifier   VARCHAR(255) NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at      TIMESTAMPTZ NOT NULL,
+    used_at         TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_sso_login_states_expires ON sso_login_states(expires_at);
+
+-- Identity provider accounts linked to users
+CREATE TABLE IF NOT EXISTS user_identities (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer          VARCHAR(500) NOT NULL,
+    subject         VARCHAR(255) NOT NULL,
+    email           VARCHAR(255),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_login_at   TIMESTAMPTZ,
+    UNIQUE(issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities(user_id);
+
+-- Extend the auth event types with single sign-on audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired',
+    'approval_granted', 'approval_denied', 'approval_used',
+    'impersonation_started',
+    'sso_config_updated', 'sso_config_deleted', 'sso_identity_linked'
+));
@@ -0,0 +1,65 @@
+-- Auth Module: SCIM 2.0 provisioning
+-- A tenant's HR system or identity provider provisions staff through the
+-- SCIM API (/scim/v2) instead of managers inviting them by hand. Each client
+-- authenticates with a per-tenant bearer token; users it provisions are
+-- linked to the tenant with the id the client knows them by, and
+-- deprovisioning removes them from the tenant and revokes their sessions.
+
+-- Bearer tokens for SCIM clients (hashed, shown once when created)
+CREATE TABLE IF NOT EXISTS scim_tokens (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name            VARCHAR(100) NOT NULL,
+    token_hash      VARCHAR(255) NOT NULL UNIQUE,
+    created_by      UUID REFERENCES users(id) ON DELETE SET NULL,
+    last_used_at    TIMESTAMPTZ,
+    revoked_at      TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scim_tokens_tenant ON scim_tokens(tenant_id);
+
+-- Users provisioned over SCIM, kept after deprovisioning so a rehire can be
+-- provisioned again
+CREATE TABLE IF NOT EXISTS scim_user_links (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    external_id     VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT scim_user_links_tenant_user_unique UNIQUE (tenant_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scim_user_links_user ON scim_user_links(user_id);
+
+CREATE TRIGGER update_scim_user_links_updated_at
+    BEFORE UPDATE ON scim_user_links
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Extend the auth event types with SCIM token audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired',
+    'approval_granted', 'approval_denied', 'approval_used',
+    'impersonation_started',
+    'sso_config_updated', 'sso_config_deleted', 'sso_identity_linked',
+    'scim_token_created', 'scim_token_revoked'
+));
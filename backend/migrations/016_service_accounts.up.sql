@@ -0,0 +1,74 @@
+-- Auth Module: Service accounts and API keys
+-- Integrations such as an accounting sync or a delivery platform run as a
+-- tenant service account instead of a staff member's login. A service
+-- account holds a fixed set of permissions (its scopes) and authenticates
+-- with prefixed API keys, which always expire and can be rotated with a
+-- grace period during which the old and new keys both work.
+
+-- Non-human identities of a tenant
+CREATE TABLE IF NOT EXISTS service_accounts (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name            VARCHAR(100) NOT NULL,
+    description     VARCHAR(500) NOT NULL DEFAULT '',
+    scopes          JSONB NOT NULL DEFAULT '[]',
+    created_by      UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_accounts_tenant ON service_accounts(tenant_id);
+
+CREATE TRIGGER update_service_accounts_updated_at
+    BEFORE UPDATE ON service_accounts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- API keys of service accounts (hashed, shown once when created; the prefix
+-- is kept so admins can tell keys apart)
+CREATE TABLE IF NOT EXISTS api_keys (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    service_account_id  UUID NOT NULL REFERENCES service_accounts(id) ON DELETE CASCADE,
+    tenant_id           UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name                VARCHAR(100) NOT NULL,
+    prefix              VARCHAR(20) NOT NULL,
+    key_hash            VARCHAR(255) NOT NULL UNIQUE,
+    expires_at          TIMESTAMPTZ NOT NULL,
+    last_used_at        TIMESTAMPTZ,
+    revoked_at          TIMESTAMPTZ,
+    rotated_from_id     UUID REFERENCES api_keys(id) ON DELETE SET NULL,
+    created_by          UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_service_account ON api_keys(service_account_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON api_keys(tenant_id);
+
+-- Extend the auth event types with service account audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired',
+    'approval_granted', 'approval_denied', 'approval_used',
+    'impersonation_started',
+    'sso_config_updated', 'sso_config_deleted', 'sso_identity_linked',
+    'scim_token_created', 'scim_token_revoked',
+    'service_account_created', 'service_account_updated',
+    'service_account_deleted',
+    'api_key_created', 'api_key_rotated', 'api_key_revoked'
+));
@@ -0,0 +1,40 @@
+-- Auth Module: Rollback OAuth authorization server
+-- This migration drops all tables created by 017_oauth.up.sql
+
+-- Restore the pre-OAuth event type list. NOT VALID keeps any existing OAuth
+-- audit rows while rejecting new ones.
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired',
+    'approval_granted', 'approval_denied', 'approval_used',
+    'impersonation_started',
+    'sso_config_updated', 'sso_config_deleted', 'sso_identity_linked',
+    'scim_token_created', 'scim_token_revoked',
+    'service_account_created', 'service_account_updated',
+    'service_account_deleted',
+    'api_key_created', 'api_key_rotated', 'api_key_revoked'
+)) NOT VALID;
+
+DROP TABLE IF EXISTS oauth_refresh_tokens;
+DROP TABLE IF EXISTS oauth_authorization_codes;
+DROP TRIGGER IF EXISTS update_oauth_consents_updated_at ON oauth_consents;
+DROP TABLE IF EXISTS oauth_consents;
+DROP TRIGGER IF EXISTS update_oauth_clients_updated_at ON oauth_clients;
+DROP TABLE IF EXISTS oauth_clients;
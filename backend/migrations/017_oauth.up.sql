@@ -0,0 +1,119 @@
+-- Auth Module: OAuth 2.0 authorization server
+-- Third-party apps such as reservation or delivery platforms register as
+-- OAuth clients and act on a tenant's behalf once its owner authorizes them,
+-- using the authorization code flow with PKCE. Confidential clients can also
+-- act as themselves in their own tenant with the client credentials grant.
+-- Secrets, codes and refresh tokens are stored hashed; refresh tokens are
+-- rotated on every use and replaying one revokes its whole family.
+
+-- Registered third-party apps. scopes is the most any tenant can grant.
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name            VARCHAR(100) NOT NULL,
+    redirect_uris   JSONB NOT NULL DEFAULT '[]',
+    scopes          JSONB NOT NULL DEFAULT '[]',
+    public          BOOLEAN NOT NULL DEFAULT FALSE,
+    secret_hash     VARCHAR(255) NOT NULL DEFAULT '',
+    created_by      UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_clients_tenant ON oauth_clients(tenant_id);
+
+CREATE TRIGGER update_oauth_clients_updated_at
+    BEFORE UPDATE ON oauth_clients
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- A tenant's authorization of a client (at most one active per client)
+CREATE TABLE IF NOT EXISTS oauth_consents (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    client_id       UUID NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    granted_by      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scopes          JSONB NOT NULL DEFAULT '[]',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at      TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_consents_tenant ON oauth_consents(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_consents_client ON oauth_consents(client_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_consents_active
+    ON oauth_consents(tenant_id, client_id) WHERE revoked_at IS NULL;
+
+CREATE TRIGGER update_oauth_consents_updated_at
+    BEFORE UPDATE ON oauth_consents
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Single-use authorization codes (hashed, valid for 10 minutes)
+CREATE TABLE IF NOT EXISTS oauth_authorization_codes (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code_hash       VARCHAR(255) NOT NULL UNIQUE,
+    client_id       UUID NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri    VARCHAR(2000) NOT NULL,
+    scopes          JSONB NOT NULL DEFAULT '[]',
+    code_challenge  VARCHAR(128) NOT NULL,
+    expires_at      TIMESTAMPTZ NOT NULL,
+    used_at         TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_authorization_codes_client ON oauth_authorization_codes(client_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_authorization_codes_expires ON oauth_authorization_codes(expires_at);
+
+-- Refresh tokens issued to clients on a user's behalf (hashed). Tokens
+-- descending from one authorization code share its ID as family_id.
+CREATE TABLE IF NOT EXISTS oauth_refresh_tokens (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    token_hash      VARCHAR(255) NOT NULL UNIQUE,
+    client_id       UUID NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id       UUID NOT NULL,
+    scopes          JSONB NOT NULL DEFAULT '[]',
+    expires_at      TIMESTAMPTZ NOT NULL,
+    revoked_at      TIMESTAMPTZ,
+    replaced_by_id  UUID REFERENCES oauth_refresh_tokens(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_refresh_tokens_client ON oauth_refresh_tokens(client_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_refresh_tokens_tenant ON oauth_refresh_tokens(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_refresh_tokens_family ON oauth_refresh_tokens(family_id);
+
+-- Extend the auth event types with OAuth audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired',
+    'approval_granted', 'approval_denied', 'approval_used',
+    'impersonation_started',
+    'sso_config_updated', 'sso_config_deleted', 'sso_identity_linked',
+    'scim_token_created', 'scim_token_revoked',
+    'service_account_created', 'service_account_updated',
+    'service_account_deleted',
+    'api_key_created', 'api_key_rotated', 'api_key_revoked',
+    'oauth_client_created', 'oauth_client_updated', 'oauth_client_deleted',
+    'oauth_secret_rotated', 'oauth_consent_granted', 'oauth_consent_revoked',
+    'oauth_token_reused'
+));
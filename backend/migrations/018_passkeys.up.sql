@@ -0,0 +1,74 @@
+-- Auth Module: WebAuthn passkeys
+-- Managers and above can register passkeys and use them either to sign in
+-- without a password or as the second factor after one. Only the public key
+-- is stored; sign_count is the authenticator's counter, which must increase
+-- on every assertion to catch cloned credentials.
+
+-- Registered passkeys (at most 10 per user, enforced by the service)
+CREATE TABLE IF NOT EXISTS passkeys (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id   BYTEA NOT NULL UNIQUE,
+    public_key      BYTEA NOT NULL,
+    sign_count      BIGINT NOT NULL DEFAULT 0,
+    aaguid          BYTEA,
+    name            VARCHAR(100) NOT NULL,
+    transports      JSONB NOT NULL DEFAULT '[]',
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    backed_up       BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_passkeys_user ON passkeys(user_id);
+
+-- Single-use registration, login and MFA challenges (hashed, valid for 5
+-- minutes). Login challenges are not bound to a user until answered.
+CREATE TABLE IF NOT EXISTS passkey_challenges (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    challenge_hash   VARCHAR(255) NOT NULL UNIQUE,
+    purpose          VARCHAR(20) NOT NULL,
+    user_id          UUID REFERENCES users(id) ON DELETE CASCADE,
+    mfa_challenge_id UUID REFERENCES mfa_challenges(id) ON DELETE CASCADE,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at       TIMESTAMPTZ NOT NULL,
+    used_at          TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_passkey_challenges_user ON passkey_challenges(user_id);
+CREATE INDEX IF NOT EXISTS idx_passkey_challenges_expires ON passkey_challenges(expires_at);
+
+-- Extend the auth event types with passkey audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired',
+    'approval_granted', 'approval_denied', 'approval_used',
+    'impersonation_started',
+    'sso_config_updated', 'sso_config_deleted', 'sso_identity_linked',
+    'scim_token_created', 'scim_token_revoked',
+    'service_account_created', 'service_account_updated',
+    'service_account_deleted',
+    'api_key_created', 'api_key_rotated', 'api_key_revoked',
+    'oauth_client_created', 'oauth_client_updated', 'oauth_client_deleted',
+    'oauth_secret_rotated', 'oauth_consent_granted', 'oauth_consent_revoked',
+    'oauth_token_reused',
+    'passkey_registered', 'passkey_renamed', 'passkey_removed',
+    'passkey_failed'
+));
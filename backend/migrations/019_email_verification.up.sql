@@ -0,0 +1,74 @@
+-- Auth Module: Email verification and email change
+-- Password resets are only sent to verified addresses. An email change only
+-- takes effect once the new address confirms it, and the previous address
+-- can undo it for 7 days.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+
+-- Existing users have already proven their address if they accepted an
+-- invitation sent to it or completed a password reset
+UPDATE users u SET email_verified_at = i.accepted_at
+FROM user_invitations i
+WHERE u.email_verified_at IS NULL
+  AND i.accepted_at IS NOT NULL
+  AND LOWER(i.email) = LOWER(u.email);
+
+UPDATE users u SET email_verified_at = t.used_at
+FROM password_reset_tokens t
+WHERE u.email_verified_at IS NULL
+  AND t.used_at IS NOT NULL
+  AND t.user_id = u.id;
+
+-- Single-use verification, change and undo links (hashed). email is the
+-- address the link was sent to.
+CREATE TABLE IF NOT EXISTS email_tokens (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose     VARCHAR(20) NOT NULL,
+    email       VARCHAR(255) NOT NULL,
+    token_hash  VARCHAR(255) NOT NULL UNIQUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    CONSTRAINT valid_email_token_expiry CHECK (expires_at > created_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_tokens_user ON email_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_email_tokens_expires ON email_tokens(expires_at);
+
+-- Extend the auth event types with email verification audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired',
+    'approval_granted', 'approval_denied', 'approval_used',
+    'impersonation_started',
+    'sso_config_updated', 'sso_config_deleted', 'sso_identity_linked',
+    'scim_token_created', 'scim_token_revoked',
+    'service_account_created', 'service_account_updated',
+    'service_account_deleted',
+    'api_key_created', 'api_key_rotated', 'api_key_revoked',
+    'oauth_client_created', 'oauth_client_updated', 'oauth_client_deleted',
+    'oauth_secret_rotated', 'oauth_consent_granted', 'oauth_consent_revoked',
+    'oauth_token_reused',
+    'passkey_registered', 'passkey_renamed', 'passkey_removed',
+    'passkey_failed',
+    'email_verification_sent', 'email_verified',
+    'email_change_requested', 'email_changed', 'email_change_reverted'
+));
@@ -0,0 +1,76 @@
+-- Auth Module: Tenant password policies
+-- Each tenant sets the length, character classes, maximum age and reuse
+-- rules for its members' passwords. A user in several tenants follows the
+-- strictest combination. Replaced password hashes are kept to prevent reuse.
+
+-- When the password was last set. Existing passwords start their maximum
+-- age from this migration.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ;
+UPDATE users SET password_changed_at = NOW() WHERE password_changed_at IS NULL;
+
+-- One policy per tenant; tenants without one use the default policy
+CREATE TABLE IF NOT EXISTS tenant_password_policies (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id          UUID NOT NULL UNIQUE REFERENCES tenants(id) ON DELETE CASCADE,
+    min_length         INTEGER NOT NULL DEFAULT 8,
+    require_uppercase  BOOLEAN NOT NULL DEFAULT FALSE,
+    require_lowercase  BOOLEAN NOT NULL DEFAULT FALSE,
+    require_digit      BOOLEAN NOT NULL DEFAULT FALSE,
+    require_symbol     BOOLEAN NOT NULL DEFAULT FALSE,
+    max_age_days       INTEGER NOT NULL DEFAULT 0,
+    history_count      INTEGER NOT NULL DEFAULT 0,
+    updated_by         UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT valid_password_min_length CHECK (min_length BETWEEN 8 AND 128),
+    CONSTRAINT valid_password_max_age CHECK (max_age_days BETWEEN 0 AND 365),
+    CONSTRAINT valid_password_history CHECK (history_count BETWEEN 0 AND 24)
+);
+
+-- Hashes of passwords users have replaced, newest kept, for reuse checks
+CREATE TABLE IF NOT EXISTS password_history (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id        UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash  VARCHAR(255) NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user ON password_history(user_id, created_at DESC);
+
+-- Extend the auth event types with password policy audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired',
+    'approval_granted', 'approval_denied', 'approval_used',
+    'impersonation_started',
+    'sso_config_updated', 'sso_config_deleted', 'sso_identity_linked',
+    'scim_token_created', 'scim_token_revoked',
+    'service_account_created', 'service_account_updated',
+    'service_account_deleted',
+    'api_key_created', 'api_key_rotated', 'api_key_revoked',
+    'oauth_client_created', 'oauth_client_updated', 'oauth_client_deleted',
+    'oauth_secret_rotated', 'oauth_consent_granted', 'oauth_consent_revoked',
+    'oauth_token_reused',
+    'passkey_registered', 'passkey_renamed', 'passkey_removed',
+    'passkey_failed',
+    'email_verification_sent', 'email_verified',
+    'email_change_requested', 'email_changed', 'email_change_reverted',
+    'password_policy_updated', 'password_expired'
+));
@@ -0,0 +1,80 @@
+-- Auth Module: Progressive account lockout
+-- Each tenant sets how many consecutive wrong passwords lock logins and for
+-- how long; the delay doubles with every further failure. Devices a user has
+-- logged in from before are counted separately, so guessing elsewhere can't
+-- lock staff out of the devices they use. A user in several tenants follows
+-- the strictest combination.
+
+-- Failures and lockout for known devices; failed_login_count and
+-- locked_until keep counting unknown devices
+ALTER TABLE users ADD COLUMN IF NOT EXISTS known_device_failed_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS known_device_locked_until TIMESTAMPTZ;
+
+-- One policy per tenant; tenants without one use the default policy
+CREATE TABLE IF NOT EXISTS tenant_lockout_policies (
+    id                        UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id                 UUID NOT NULL UNIQUE REFERENCES tenants(id) ON DELETE CASCADE,
+    unknown_device_threshold  INTEGER NOT NULL DEFAULT 5,
+    known_device_threshold    INTEGER NOT NULL DEFAULT 10,
+    base_delay_seconds        INTEGER NOT NULL DEFAULT 60,
+    max_delay_seconds         INTEGER NOT NULL DEFAULT 900,
+    updated_by                UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at                TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at                TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT valid_lockout_unknown_threshold CHECK (unknown_device_threshold BETWEEN 1 AND 100),
+    CONSTRAINT valid_lockout_known_threshold CHECK (known_device_threshold BETWEEN 1 AND 100),
+    CONSTRAINT valid_lockout_base_delay CHECK (base_delay_seconds BETWEEN 1 AND 86400),
+    CONSTRAINT valid_lockout_max_delay CHECK (max_delay_seconds BETWEEN base_delay_seconds AND 86400)
+);
+
+-- Devices users have logged in from, identified by the hash of the device
+-- token handed out at login
+CREATE TABLE IF NOT EXISTS known_devices (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash    VARCHAR(255) NOT NULL UNIQUE,
+    device_info   VARCHAR(500),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_known_devices_user ON known_devices(user_id);
+
+-- Extend the auth event types with lockout audit events
+ALTER TABLE auth_events DROP CONSTRAINT IF EXISTS auth_events_event_type_check;
+ALTER TABLE auth_events ADD CONSTRAINT auth_events_event_type_check CHECK (event_type IN (
+    'login_success', 'login_failed', 'logout', 'token_refresh',
+    'password_changed', 'password_reset_requested', 'password_reset_completed',
+    'account_created', 'account_disabled', 'account_enabled',
+    'account_locked', 'account_unlocked', 'tenant_role_added',
+    'role_changed', 'session_revoked', 'email_delivery_failed',
+    'mfa_enrolled', 'mfa_disabled', 'mfa_success', 'mfa_failed',
+    'mfa_recovery_codes_reset',
+    'pin_set', 'pin_removed', 'pin_locked',
+    'terminal_registered', 'terminal_revoked',
+    'tenant_switched',
+    'invitation_sent', 'invitation_resent', 'invitation_revoked',
+    'invitation_accepted',
+    'tenant_role_removed',
+    'ownership_transfer_started', 'ownership_transfer_cancelled',
+    'ownership_transferred',
+    'custom_role_created', 'custom_role_updated', 'custom_role_deleted',
+    'role_elevated', 'role_elevation_started', 'role_elevation_ended',
+    'role_elevation_expired',
+    'approval_granted', 'approval_denied', 'approval_used',
+    'impersonation_started',
+    'sso_config_updated', 'sso_config_deleted', 'sso_identity_linked',
+    'scim_token_created', 'scim_token_revoked',
+    'service_account_created', 'service_account_updated',
+    'service_account_deleted',
+    'api_key_created', 'api_key_rotated', 'api_key_revoked',
+    'oauth_client_created', 'oauth_client_updated', 'oauth_client_deleted',
+    'oauth_secret_rotated', 'oauth_consent_granted', 'oauth_consent_revoked',
+    'oauth_token_reused',
+    'passkey_registered', 'passkey_renamed', 'passkey_removed',
+    'passkey_failed',
+    'email_verification_sent', 'email_verified',
+    'email_change_requested', 'email_changed', 'email_change_reverted',
+    'password_policy_updated', 'password_expired',
+    'lockout_policy_updated', 'account_unlock_requested'
+));
@@ -0,0 +1,4 @@
+-- Auth Module: Rollback session sign-in methods
+-- This migration drops the column created by 022_session_sign_in_method.up.sql
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS sign_in_method;
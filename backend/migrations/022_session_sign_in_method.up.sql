@@ -0,0 +1,9 @@
+-- Auth Module: Record how each session was signed in
+-- A session signed in through SSO is only vouched for by that tenant's
+-- identity provider, so switching it to another tenant may need a fresh
+-- second factor. Rotated sessions inherit their parent's method.
+
+-- Sessions from before this migration are marked unknown and treated like
+-- SSO sessions when switching tenants
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS sign_in_method VARCHAR(20) NOT NULL DEFAULT 'unknown';
+ALTER TABLE sessions ALTER COLUMN sign_in_method DROP DEFAULT;
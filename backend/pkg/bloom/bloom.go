@@ -0,0 +1,75 @@
+// Package bloom implements a Bloom filter: a compact set that answers
+// "definitely not present" or "probably present" with a chosen false
+// positive rate, and never a false negative. It suits checking candidate
+// secrets against a large list without keeping the list in memory.
+package bloom
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+)
+
+// Filter is a Bloom filter. It is not safe for concurrent Add calls, but
+// any number of goroutines may call Test once it has been filled.
+type Filter struct {
+	bits   []uint64
+	m      uint64 // Number of bits
+	k      uint64 // Number of hash functions
+	length int    // Number of items added
+}
+
+// New returns a filter sized to hold n items with a false positive rate of
+// about p (for example 0.001). n below 1 is treated as 1, and p is clamped
+// to (0, 0.5].
+func New(n int, p float64) *Filter {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p > 0.5 {
+		p = math.Min(math.Max(p, 1e-9), 0.5)
+	}
+
+	// Optimal sizes: m = -n ln p / (ln 2)^2, k = m/n ln 2
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	m = (m + 63) / 64 * 64
+	k := uint64(math.Max(1, math.Round(float64(m)/float64(n)*math.Ln2)))
+
+	return &Filter{bits: make([]uint64, m/64), m: m, k: k}
+}
+
+// Add inserts item into the filter.
+func (f *Filter) Add(item []byte) {
+	h1, h2 := hashes(item)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.length++
+}
+
+// Test reports whether item may have been added. False means it was
+// definitely not added.
+func (f *Filter) Test(item []byte) bool {
+	h1, h2 := hashes(item)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Len returns the number of items added.
+func (f *Filter) Len() int {
+	return f.length
+}
+
+// hashes derives the two base hashes the k bit positions are built from
+// (Kirsch and Mitzenmacher's double hashing). h2 is odd so the positions
+// don't collapse onto a cycle of the filter size.
+func hashes(item []byte) (uint64, uint64) {
+	sum := sha256.Sum256(item)
+	return binary.LittleEndian.Uint64(sum[0:8]), binary.LittleEndian.Uint64(sum[8:16]) | 1
+}
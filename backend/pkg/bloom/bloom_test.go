@@ -0,0 +1,53 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFilter_NoFalseNegatives(t *testing.T) {
+	f := New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add([]byte(fmt.Sprintf("item-%d", i)))
+	}
+
+	for i := 0; i < 1000; i++ {
+		if !f.Test([]byte(fmt.Sprintf("item-%d", i))) {
+			t.Fatalf("Test(item-%d) = false for an added item", i)
+		}
+	}
+	if f.Len() != 1000 {
+		t.Errorf("Len() = %d, want 1000", f.Len())
+	}
+}
+
+func TestFilter_FalsePositiveRate(t *testing.T) {
+	f := New(10000, 0.001)
+	for i := 0; i < 10000; i++ {
+		f.Add([]byte(fmt.Sprintf("added-%d", i)))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 100000; i++ {
+		if f.Test([]byte(fmt.Sprintf("other-%d", i))) {
+			falsePositives++
+		}
+	}
+	// Expect about 100; allow generous slack
+	if falsePositives > 300 {
+		t.Errorf("false positives = %d in 100000, want about 100", falsePositives)
+	}
+}
+
+func TestNew_ClampsArguments(t *testing.T) {
+	for _, tt := range []struct {
+		n int
+		p float64
+	}{{0, 0.01}, {-5, 0.01}, {10, 0}, {10, 2}} {
+		f := New(tt.n, tt.p)
+		f.Add([]byte("x"))
+		if !f.Test([]byte("x")) {
+			t.Errorf("New(%d, %v) filter lost an added item", tt.n, tt.p)
+		}
+	}
+}
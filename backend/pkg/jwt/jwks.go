@@ -0,0 +1,143 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517). RSA keys set n and
+// e, EC keys set crv, x and y, and Ed25519 (OKP, RFC 8037) keys set crv and x.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n,omitempty"`
+	Exponent  string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set, the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every key that can verify tokens (the
+// active key and any verify-only keys), so other services and apps can
+// validate access tokens offline. Retired keys are left out.
+func (km *KeyManager) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range km.Keys() {
+		if key.Status == KeyStatusRetired || key.PublicKey == nil {
+			continue
+		}
+		jwk, ok := toJWK(key.PublicKey)
+		if !ok {
+			continue
+		}
+		jwk.Use = "sig"
+		jwk.Algorithm = key.Algorithm
+		jwk.KeyID = key.ID
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// PublicKey decodes the key material of a JWK, such as one fetched from an
+// identity provider's JWKS. It supports the same key types toJWK encodes:
+// RSA, EC on P-256, P-384 and P-521, and Ed25519.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch k.KeyType {
+	case "RSA":
+		n, err := decode(k.Modulus)
+		if err != nil || len(n) == 0 {
+			return nil, fmt.Errorf("%w: bad RSA modulus", ErrKeyInvalid)
+		}
+		e, err := decode(k.Exponent)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("%w: bad RSA exponent", ErrKeyInvalid)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if exponent.Int64() < 3 {
+			return nil, fmt.Errorf("%w: bad RSA exponent", ErrKeyInvalid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("%w: unsupported curve %q", ErrKeyInvalid, k.Curve)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		x, errX := decode(k.X)
+		y, errY := decode(k.Y)
+		if errX != nil || errY != nil || len(x) != size || len(y) != size {
+			return nil, fmt.Errorf("%w: bad EC coordinates", ErrKeyInvalid)
+		}
+		// Parsing the uncompressed point also checks it is on the curve
+		point := append(append([]byte{4}, x...), y...)
+		key, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrKeyInvalid, err)
+		}
+		return key, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("%w: unsupported curve %q", ErrKeyInvalid, k.Curve)
+		}
+		x, err := decode(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: bad Ed25519 key", ErrKeyInvalid)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %q", ErrKeyInvalid, k.KeyType)
+	}
+}
+
+// toJWK encodes the key material of a public key.
+func toJWK(publicKey crypto.PublicKey) (JWK, bool) {
+	encode := base64.RawURLEncoding.EncodeToString
+
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			KeyType:  "RSA",
+			Modulus:  encode(k.N.Bytes()),
+			Exponent: encode(big.NewInt(int64(k.E)).Bytes()),
+		}, true
+	case *ecdsa.PublicKey:
+		// Coordinates are padded to the curve size, as RFC 7518 requires
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			KeyType: "EC",
+			Curve:   k.Curve.Params().Name,
+			X:       encode(k.X.FillBytes(make([]byte, size))),
+			Y:       encode(k.Y.FillBytes(make([]byte, size))),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       encode(k),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
@@ -23,9 +23,19 @@ var (
 // Claims represents the JWT payload for access tokens.
 type Claims struct {
 	jwt.RegisteredClaims
-	TenantID uuid.UUID `json:"tenant_id"`
-	Role     string    `json:"role"`
-	Email    string    `json:"email"`
+	TenantID    uuid.UUID `json:"tenant_id"`
+	Role        string    `json:"role"`
+	Email       string    `json:"email"`
+	SessionID   string    `json:"sid,omitempty"`       // Session the token was issued for
+	Permissions []string  `json:"perms"`               // Permissions granted for the tenant; null if not set
+	Actor       *Actor    `json:"act,omitempty"`       // User acting as the subject (impersonation)
+	ClientID    string    `json:"client_id,omitempty"` // OAuth client the token was issued to (RFC 9068)
+}
+
+// Actor is the act claim (RFC 8693): the user actually making requests with
+// a token issued for someone else.
+type Actor struct {
+	Subject string `json:"sub"`
 }
 
 // TokenGenerator handles JWT token generation.
@@ -43,6 +53,9 @@ type TokenGeneratorConfig struct {
 	Audience        []string
 	AccessTokenTTL  time.Duration
 	RefreshTokenTTL time.Duration
+	// AllowedAlgorithms limits the signing algorithms accepted when
+	// validating. Empty accepts every supported algorithm.
+	AllowedAlgorithms []string
 }
 
 // DefaultTokenGeneratorConfig returns default configuration.
@@ -66,15 +79,49 @@ func NewTokenGenerator(keyManager *KeyManager, cfg TokenGeneratorConfig) *TokenG
 	}
 }
 
-// GenerateAccessToken generates a new JWT access token.
-func (g *TokenGenerator) GenerateAccessToken(userID, tenantID uuid.UUID, email, role string) (string, time.Time, error) {
-	privateKey, err := g.keyManager.GetPrivateKey()
+// GenerateAccessToken generates a new JWT access token. sessionID identifies
+// the server-side session backing the token; uuid.Nil omits the sid claim.
+// permissions is carried in the perms claim so resource servers can authorize
+// without a lookup.
+func (g *TokenGenerator) GenerateAccessToken(userID, tenantID, sessionID uuid.UUID, email, role string, permissions []string) (string, time.Time, error) {
+	return g.GenerateAccessTokenWithTTL(userID, tenantID, sessionID, email, role, permissions, g.accessTokenTTL)
+}
+
+// GenerateAccessTokenWithTTL generates a new JWT access token that expires
+// after ttl instead of the configured access token TTL.
+func (g *TokenGenerator) GenerateAccessTokenWithTTL(userID, tenantID, sessionID uuid.UUID, email, role string, permissions []string, ttl time.Duration) (string, time.Time, error) {
+	return g.generateAccessToken(userID, tenantID, sessionID, uuid.Nil, "", email, role, permissions, ttl)
+}
+
+// GenerateImpersonationToken generates an access token for userID that
+// actorID uses to act as them. The actor is carried in the act claim, and
+// the token has no session behind it.
+func (g *TokenGenerator) GenerateImpersonationToken(userID, tenantID, actorID uuid.UUID, email, role string, permissions []string, ttl time.Duration) (string, time.Time, error) {
+	return g.generateAccessToken(userID, tenantID, uuid.Nil, actorID, "", email, role, permissions, ttl)
+}
+
+// GenerateClientAccessToken generates an access token issued to an OAuth
+// client. subject is the user who authorized the client, or the client
+// itself when it acts on its own behalf; the client is carried in the
+// client_id claim, and the token has no session behind it.
+func (g *TokenGenerator) GenerateClientAccessToken(subject, tenantID uuid.UUID, clientID, role string, permissions []string) (string, time.Time, error) {
+	return g.generateAccessToken(subject, tenantID, uuid.Nil, uuid.Nil, clientID, "", role, permissions, g.accessTokenTTL)
+}
+
+// generateAccessToken signs an access token. uuid.Nil omits the sid and act
+// claims, and an empty clientID the client_id claim.
+func (g *TokenGenerator) generateAccessToken(userID, tenantID, sessionID, actorID uuid.UUID, clientID, email, role string, permissions []string, ttl time.Duration) (string, time.Time, error) {
+	keyID, algorithm, privateKey, err := g.keyManager.SigningKey()
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("failed to get private key: %w", err)
 	}
+	method := jwt.GetSigningMethod(algorithm)
+	if method == nil {
+		return "", time.Time{}, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
 
 	now := time.Now()
-	expiresAt := now.Add(g.accessTokenTTL)
+	expiresAt := now.Add(ttl)
 
 	claims := Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -86,15 +133,23 @@ func (g *TokenGenerator) GenerateAccessToken(userID, tenantID uuid.UUID, email,
 			NotBefore: jwt.NewNumericDate(now),
 			ID:        uuid.New().String(),
 		},
-		TenantID: tenantID,
-		Role:     role,
-		Email:    email,
+		TenantID:    tenantID,
+		Role:        role,
+		Email:       email,
+		Permissions: permissions,
+		ClientID:    clientID,
+	}
+	if sessionID != uuid.Nil {
+		claims.SessionID = sessionID.String()
+	}
+	if actorID != uuid.Nil {
+		claims.Actor = &Actor{Subject: actorID.String()}
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token := jwt.NewWithClaims(method, claims)
 
-	// Set key ID in header for key rotation support
-	token.Header["kid"] = g.keyManager.GetKeyID()
+	// Set key ID in header so validators pick the right key from the ring
+	token.Header["kid"] = keyID
 
 	tokenString, err := token.SignedString(privateKey)
 	if err != nil {
@@ -127,31 +182,48 @@ type TokenValidator struct {
 	keyManager *KeyManager
 	issuer     string
 	audience   []string
+	algorithms []string
 }
 
-// NewTokenValidator creates a new TokenValidator.
+// NewTokenValidator creates a new TokenValidator that accepts every
+// supported signing algorithm. Use SetAllowedAlgorithms to narrow it.
 func NewTokenValidator(keyManager *KeyManager, issuer string, audience []string) *TokenValidator {
 	return &TokenValidator{
 		keyManager: keyManager,
 		issuer:     issuer,
 		audience:   audience,
+		algorithms: SupportedAlgorithms(),
 	}
 }
 
-// ValidateToken validates a JWT token and returns the claims.
-func (v *TokenValidator) ValidateToken(tokenString string) (*Claims, error) {
-	publicKey, err := v.keyManager.GetPublicKey()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get public key: %w", err)
-	}
+// SetAllowedAlgorithms sets the signing algorithms accepted during
+// validation. Tokens using any other alg are rejected before their
+// signature is checked.
+func (v *TokenValidator) SetAllowedAlgorithms(algorithms []string) {
+	v.algorithms = algorithms
+}
 
+// ValidateToken validates a JWT token and returns the claims. The
+// verification key is chosen by the token's kid header; tokens signed by a
+// retired or unknown key, or whose alg is not allowed or does not match the
+// key's, are rejected.
+func (v *TokenValidator) ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			// Tokens without a kid predate the key ring; only the active key can verify them
+			kid = v.keyManager.GetKeyID()
+		}
+		algorithm, publicKey, err := v.keyManager.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		// A key only verifies the algorithm it was created for
+		if token.Method.Alg() != algorithm {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return publicKey, nil
-	})
+	}, jwt.WithValidMethods(v.algorithms))
 
 	if err != nil {
 		return nil, v.parseError(err)
@@ -1,11 +1,17 @@
 package jwt
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
@@ -19,9 +25,12 @@ func generateTestKeyPair(t *testing.T) *KeyManager {
 	}
 
 	km := NewKeyManager()
-	km.privateKey = privateKey
-	km.publicKey = &privateKey.PublicKey
-	km.keyID = "test-key-1"
+	if err := km.AddKey("test-key-1", privateKey); err != nil {
+		t.Fatalf("failed to add key: %v", err)
+	}
+	if err := km.Activate("test-key-1", 0); err != nil {
+		t.Fatalf("failed to activate key: %v", err)
+	}
 
 	return km
 }
@@ -35,11 +44,13 @@ func TestTokenGeneratorAndValidator(t *testing.T) {
 
 	userID := uuid.New()
 	tenantID := uuid.New()
+	sessionID := uuid.New()
 	email := "test@example.com"
 	role := "manager"
+	permissions := []string{"orders.void", "users.manage"}
 
 	// Generate access token
-	token, expiresAt, err := generator.GenerateAccessToken(userID, tenantID, email, role)
+	token, expiresAt, err := generator.GenerateAccessToken(userID, tenantID, sessionID, email, role, permissions)
 	if err != nil {
 		t.Fatalf("failed to generate access token: %v", err)
 	}
@@ -73,6 +84,125 @@ func TestTokenGeneratorAndValidator(t *testing.T) {
 	if claims.Role != role {
 		t.Errorf("expected role %s, got %s", role, claims.Role)
 	}
+
+	if claims.SessionID != sessionID.String() {
+		t.Errorf("expected session ID %s, got %s", sessionID, claims.SessionID)
+	}
+
+	if len(claims.Permissions) != 2 || claims.Permissions[0] != "orders.void" || claims.Permissions[1] != "users.manage" {
+		t.Errorf("expected permissions %v, got %v", permissions, claims.Permissions)
+	}
+}
+
+func TestAccessTokenWithTTL(t *testing.T) {
+	km := generateTestKeyPair(t)
+	cfg := DefaultTokenGeneratorConfig()
+	generator := NewTokenGenerator(km, cfg)
+
+	token, expiresAt, err := generator.GenerateAccessTokenWithTTL(uuid.New(), uuid.New(), uuid.Nil, "test@example.com", "waiter", nil, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	if until := time.Until(expiresAt); until > 5*time.Minute || until < 4*time.Minute {
+		t.Errorf("expected expiry in about 5 minutes, got %v", until)
+	}
+
+	claims, err := ParseUnverified(token)
+	if err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	if !claims.ExpiresAt.Time.Equal(expiresAt.Truncate(time.Second)) {
+		t.Errorf("exp claim %v does not match returned expiry %v", claims.ExpiresAt.Time, expiresAt)
+	}
+}
+
+func TestAccessTokenWithoutSession(t *testing.T) {
+	km := generateTestKeyPair(t)
+	cfg := DefaultTokenGeneratorConfig()
+	generator := NewTokenGenerator(km, cfg)
+
+	token, _, err := generator.GenerateAccessToken(uuid.New(), uuid.New(), uuid.Nil, "test@example.com", "user", nil)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	claims, err := ParseUnverified(token)
+	if err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+
+	if claims.SessionID != "" {
+		t.Errorf("expected no session ID, got %s", claims.SessionID)
+	}
+}
+
+func TestImpersonationToken(t *testing.T) {
+	km := generateTestKeyPair(t)
+	cfg := DefaultTokenGeneratorConfig()
+	generator := NewTokenGenerator(km, cfg)
+	validator := NewTokenValidator(km, cfg.Issuer, cfg.Audience)
+
+	userID, actorID := uuid.New(), uuid.New()
+	token, _, err := generator.GenerateImpersonationToken(userID, uuid.New(), actorID, "waiter@example.com", "waiter", []string{"orders.view"}, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	claims, err := validator.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("failed to validate token: %v", err)
+	}
+	if claims.Subject != userID.String() {
+		t.Errorf("expected subject %s, got %s", userID, claims.Subject)
+	}
+	if claims.Actor == nil || claims.Actor.Subject != actorID.String() {
+		t.Errorf("expected act claim for %s, got %+v", actorID, claims.Actor)
+	}
+	if claims.SessionID != "" {
+		t.Errorf("expected no session ID, got %s", claims.SessionID)
+	}
+
+	// Ordinary tokens carry no act claim
+	token, _, err = generator.GenerateAccessToken(userID, uuid.New(), uuid.New(), "waiter@example.com", "waiter", nil)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	if claims, _ := ParseUnverified(token); claims.Actor != nil {
+		t.Errorf("expected no act claim, got %+v", claims.Actor)
+	}
+}
+
+func TestClientAccessToken(t *testing.T) {
+	km := generateTestKeyPair(t)
+	cfg := DefaultTokenGeneratorConfig()
+	generator := NewTokenGenerator(km, cfg)
+	validator := NewTokenValidator(km, cfg.Issuer, cfg.Audience)
+
+	userID, clientID := uuid.New(), uuid.New()
+	token, expiresAt, err := generator.GenerateClientAccessToken(userID, uuid.New(), clientID.String(), "viewer", []string{"reservations.manage"})
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	claims, err := validator.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("failed to validate token: %v", err)
+	}
+	if claims.Subject != userID.String() || claims.ClientID != clientID.String() {
+		t.Errorf("expected subject %s and client %s, got %s and %s", userID, clientID, claims.Subject, claims.ClientID)
+	}
+	if claims.SessionID != "" || claims.Email != "" {
+		t.Errorf("expected no session or email, got %q and %q", claims.SessionID, claims.Email)
+	}
+	if time.Until(expiresAt) > cfg.AccessTokenTTL {
+		t.Errorf("expiry %v is beyond the access token TTL", expiresAt)
+	}
+
+	// Ordinary tokens carry no client_id claim
+	token, _, _ = generator.GenerateAccessToken(userID, uuid.New(), uuid.New(), "waiter@example.com", "waiter", nil)
+	if claims, _ := ParseUnverified(token); claims.ClientID != "" {
+		t.Errorf("expected no client_id claim, got %q", claims.ClientID)
+	}
 }
 
 func TestRefreshTokenGeneration(t *testing.T) {
@@ -144,7 +274,7 @@ func TestDifferentKeyPairs(t *testing.T) {
 	cfg := DefaultTokenGeneratorConfig()
 	generator := NewTokenGenerator(km1, cfg)
 
-	token, _, err := generator.GenerateAccessToken(uuid.New(), uuid.New(), "test@example.com", "user")
+	token, _, err := generator.GenerateAccessToken(uuid.New(), uuid.New(), uuid.New(), "test@example.com", "user", nil)
 	if err != nil {
 		t.Fatalf("failed to generate token: %v", err)
 	}
@@ -169,7 +299,7 @@ func TestParseUnverified(t *testing.T) {
 	email := "test@example.com"
 	role := "manager"
 
-	token, _, err := generator.GenerateAccessToken(userID, tenantID, email, role)
+	token, _, err := generator.GenerateAccessToken(userID, tenantID, uuid.New(), email, role, nil)
 	if err != nil {
 		t.Fatalf("failed to generate token: %v", err)
 	}
@@ -196,7 +326,7 @@ func TestGetUserIDFromClaims(t *testing.T) {
 	validator := NewTokenValidator(km, cfg.Issuer, cfg.Audience)
 
 	userID := uuid.New()
-	token, _, err := generator.GenerateAccessToken(userID, uuid.New(), "test@example.com", "user")
+	token, _, err := generator.GenerateAccessToken(userID, uuid.New(), uuid.New(), "test@example.com", "user", nil)
 	if err != nil {
 		t.Fatalf("failed to generate token: %v", err)
 	}
@@ -251,3 +381,118 @@ func TestTokenTTL(t *testing.T) {
 		t.Errorf("expected refresh TTL %v, got %v", cfg.RefreshTokenTTL, generator.GetRefreshTokenTTL())
 	}
 }
+
+// newTestKeyRing returns a KeyManager whose active key is signer.
+func newTestKeyRing(t *testing.T, keyID string, signer crypto.Signer) *KeyManager {
+	t.Helper()
+
+	km := NewKeyManager()
+	if err := km.AddKey(keyID, signer); err != nil {
+		t.Fatalf("failed to add key: %v", err)
+	}
+	if err := km.Activate(keyID, 0); err != nil {
+		t.Fatalf("failed to activate key: %v", err)
+	}
+	return km
+}
+
+func TestSigningAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name   string
+		signer crypto.Signer
+		alg    string
+	}{
+		{name: "RSA", signer: rsaKey, alg: AlgRS256},
+		{name: "ECDSA P-256", signer: ecKey, alg: AlgES256},
+		{name: "Ed25519", signer: edKey, alg: AlgEdDSA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km := newTestKeyRing(t, "test-key", tt.signer)
+			cfg := DefaultTokenGeneratorConfig()
+			generator := NewTokenGenerator(km, cfg)
+			validator := NewTokenValidator(km, cfg.Issuer, cfg.Audience)
+
+			token, _, err := generator.GenerateAccessToken(uuid.New(), uuid.New(), uuid.New(), "test@example.com", "cashier", nil)
+			if err != nil {
+				t.Fatalf("failed to generate token: %v", err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			if err != nil {
+				t.Fatalf("failed to parse token: %v", err)
+			}
+			if parsed.Header["alg"] != tt.alg {
+				t.Errorf("expected alg %s, got %v", tt.alg, parsed.Header["alg"])
+			}
+
+			if _, err := validator.ValidateToken(token); err != nil {
+				t.Errorf("failed to validate %s token: %v", tt.alg, err)
+			}
+		})
+	}
+}
+
+func TestTokenValidator_AllowedAlgorithms(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	km := newTestKeyRing(t, "test-key", ecKey)
+	cfg := DefaultTokenGeneratorConfig()
+
+	token, _, err := NewTokenGenerator(km, cfg).GenerateAccessToken(uuid.New(), uuid.New(), uuid.New(), "test@example.com", "cashier", nil)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	validator := NewTokenValidator(km, cfg.Issuer, cfg.Audience)
+	validator.SetAllowedAlgorithms([]string{AlgRS256})
+
+	if _, err := validator.ValidateToken(token); err == nil {
+		t.Error("expected ES256 token to be rejected when only RS256 is allowed")
+	}
+}
+
+func TestTokenValidator_AlgorithmMustMatchKey(t *testing.T) {
+	km := generateTestKeyPair(t)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err := km.AddKey("ec-key", ecKey); err != nil {
+		t.Fatalf("failed to add key: %v", err)
+	}
+	cfg := DefaultTokenGeneratorConfig()
+	validator := NewTokenValidator(km, cfg.Issuer, cfg.Audience)
+
+	sign := func(kid string, method jwt.SigningMethod, key interface{}) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   uuid.New().String(),
+				Issuer:    cfg.Issuer,
+				Audience:  cfg.Audience,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return signed
+	}
+
+	if _, err := validator.ValidateToken(sign("ec-key", jwt.SigningMethodES256, ecKey)); err != nil {
+		t.Errorf("expected ES256 token for the EC key to validate: %v", err)
+	}
+	if _, err := validator.ValidateToken(sign("test-key-1", jwt.SigningMethodES256, ecKey)); err == nil {
+		t.Error("expected ES256 token claiming the RSA key's kid to be rejected")
+	}
+
+	publicKey, _ := km.GetPublicKey()
+	hmacSecret, _ := x509.MarshalPKIXPublicKey(publicKey)
+	if _, err := validator.ValidateToken(sign("test-key-1", jwt.SigningMethodHS256, hmacSecret)); err == nil {
+		t.Error("expected HS256 token keyed with the public key to be rejected")
+	}
+}
@@ -2,13 +2,19 @@
 package jwt
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"sync"
+	"time"
 )
 
 var (
@@ -18,19 +24,91 @@ var (
 	ErrKeyInvalid = errors.New("key is invalid")
 	// ErrKeyNotLoaded is returned when attempting to use a key that hasn't been loaded.
 	ErrKeyNotLoaded = errors.New("key has not been loaded")
+	// ErrKeyUnknown is returned when a key ID is not in the key ring.
+	ErrKeyUnknown = errors.New("key id is not in the key ring")
+	// ErrKeyExists is returned when adding a key whose ID is already in the key ring.
+	ErrKeyExists = errors.New("key id is already in the key ring")
+	// ErrKeyRetired is returned when a retired key is used.
+	ErrKeyRetired = errors.New("key has been retired")
+	// ErrKeyActive is returned when attempting to retire the active signing key.
+	ErrKeyActive = errors.New("cannot retire the active signing key")
 )
 
-// KeyManager handles RSA key loading and caching for JWT operations.
+// Supported signing algorithms. The algorithm is picked from the key type:
+// RSA keys sign RS256, ECDSA P-256 keys sign ES256 and Ed25519 keys sign EdDSA.
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// SupportedAlgorithms returns every signing algorithm the key ring can hold.
+func SupportedAlgorithms() []string {
+	return []string{AlgRS256, AlgES256, AlgEdDSA}
+}
+
+// algorithmFor returns the signing algorithm for a public key, or
+// ErrKeyInvalid for unsupported key types and curves.
+func algorithmFor(publicKey crypto.PublicKey) (string, error) {
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return AlgRS256, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return "", fmt.Errorf("%w: only the P-256 curve is supported", ErrKeyInvalid)
+		}
+		return AlgES256, nil
+	case ed25519.PublicKey:
+		return AlgEdDSA, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported key type %T", ErrKeyInvalid, publicKey)
+	}
+}
+
+// KeyStatus is the lifecycle state of a key in the key ring.
+type KeyStatus string
+
+const (
+	// KeyStatusActive signs new tokens. Exactly one key is active at a time.
+	KeyStatusActive KeyStatus = "active"
+	// KeyStatusVerifyOnly is published and accepted for verification but never
+	// signs: either the next key, published ahead of activation so clients
+	// caching the JWKS already know it, or the previous key, kept until the
+	// tokens it signed have expired.
+	KeyStatusVerifyOnly KeyStatus = "verify_only"
+	// KeyStatusRetired is neither published nor accepted.
+	KeyStatusRetired KeyStatus = "retired"
+)
+
+// Key is a key in the key ring, identified by the kid written to token headers.
+type Key struct {
+	ID         string
+	Status     KeyStatus
+	Algorithm  string        // RS256, ES256 or EdDSA, from the key type
+	PrivateKey crypto.Signer // nil for keys that can only verify
+	PublicKey  crypto.PublicKey
+	CreatedAt  time.Time
+	// ActivatedAt is when the key last started signing (zero if never).
+	ActivatedAt time.Time
+	// RetireAt is when a demoted key stops verifying. Zero means it stays
+	// verify-only until retired explicitly.
+	RetireAt time.Time
+}
+
+// KeyManager holds the ring of keys used for JWT operations. One key is
+// active and signs new tokens; verify-only keys are still accepted, so keys
+// can be rotated without invalidating tokens already issued. Keys are
+// selected by the kid token header.
 type KeyManager struct {
-	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
-	keyID      string
-	mu         sync.RWMutex
+	keys     map[string]*Key
+	activeID string
+	now      func() time.Time
+	mu       sync.RWMutex
 }
 
 // NewKeyManager creates a new KeyManager instance.
 func NewKeyManager() *KeyManager {
-	return &KeyManager{}
+	return &KeyManager{keys: make(map[string]*Key), now: time.Now}
 }
 
 // LoadPrivateKeyFromFile loads an RSA private key from a PEM file.
@@ -46,23 +124,29 @@ func (km *KeyManager) LoadPrivateKeyFromFile(path string) error {
 	return km.LoadPrivateKeyFromPEM(data)
 }
 
-// LoadPrivateKeyFromPEM loads an RSA private key from PEM data.
+// LoadPrivateKeyFromPEM loads an RSA, ECDSA P-256 or Ed25519 private key
+// from PEM data as the active signing key.
 func (km *KeyManager) LoadPrivateKeyFromPEM(pemData []byte) error {
 	block, _ := pem.Decode(pemData)
 	if block == nil {
 		return fmt.Errorf("%w: failed to decode PEM block", ErrKeyInvalid)
 	}
 
-	var privateKey *rsa.PrivateKey
-	var err error
+	var privateKey crypto.Signer
 
-	// Try PKCS#8 first (modern format)
+	// Try PKCS#8 first (modern format, any key type)
 	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
 	if err == nil {
 		var ok bool
-		privateKey, ok = key.(*rsa.PrivateKey)
+		privateKey, ok = key.(crypto.Signer)
 		if !ok {
-			return fmt.Errorf("%w: not an RSA private key", ErrKeyInvalid)
+			return fmt.Errorf("%w: unsupported private key type %T", ErrKeyInvalid, key)
+		}
+	} else if block.Type == "EC PRIVATE KEY" {
+		// SEC 1 (traditional EC format)
+		privateKey, err = x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrKeyInvalid, err)
 		}
 	} else {
 		// Fall back to PKCS#1 (traditional RSA format)
@@ -72,11 +156,18 @@ func (km *KeyManager) LoadPrivateKeyFromPEM(pemData []byte) error {
 		}
 	}
 
+	algorithm, err := algorithmFor(privateKey.Public())
+	if err != nil {
+		return err
+	}
+
 	km.mu.Lock()
 	defer km.mu.Unlock()
 
-	km.privateKey = privateKey
-	km.publicKey = &privateKey.PublicKey
+	active := km.activeKeyLocked()
+	active.Algorithm = algorithm
+	active.PrivateKey = privateKey
+	active.PublicKey = privateKey.Public()
 
 	return nil
 }
@@ -94,42 +185,62 @@ func (km *KeyManager) LoadPublicKeyFromFile(path string) error {
 	return km.LoadPublicKeyFromPEM(data)
 }
 
-// LoadPublicKeyFromPEM loads an RSA public key from PEM data.
+// LoadPublicKeyFromPEM loads an RSA, ECDSA P-256 or Ed25519 public key from
+// PEM data as the active key's verification key. It must be the same key
+// type as an already loaded private key.
 func (km *KeyManager) LoadPublicKeyFromPEM(pemData []byte) error {
-	block, _ := pem.Decode(pemData)
-	if block == nil {
-		return fmt.Errorf("%w: failed to decode PEM block", ErrKeyInvalid)
+	publicKey, err := parsePublicKeyPEM(pemData)
+	if err != nil {
+		return err
 	}
-
-	var publicKey *rsa.PublicKey
-
-	// Try PKIX format first
-	key, err := x509.ParsePKIXPublicKey(block.Bytes)
-	if err == nil {
-		var ok bool
-		publicKey, ok = key.(*rsa.PublicKey)
-		if !ok {
-			return fmt.Errorf("%w: not an RSA public key", ErrKeyInvalid)
-		}
-	} else {
-		// Try PKCS#1 format
-		publicKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
-		if err != nil {
-			return fmt.Errorf("%w: %v", ErrKeyInvalid, err)
-		}
+	algorithm, err := algorithmFor(publicKey)
+	if err != nil {
+		return err
 	}
 
 	km.mu.Lock()
 	defer km.mu.Unlock()
 
-	km.publicKey = publicKey
+	active := km.activeKeyLocked()
+	if active.PrivateKey != nil && active.Algorithm != algorithm {
+		return fmt.Errorf("%w: %s public key does not match %s private key", ErrKeyInvalid, algorithm, active.Algorithm)
+	}
+	active.Algorithm = algorithm
+	active.PublicKey = publicKey
 
 	return nil
 }
 
+// parsePublicKeyPEM parses a PKIX encoded public key, or a PKCS#1 encoded
+// RSA public key.
+func parsePublicKeyPEM(pemData []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("%w: failed to decode PEM block", ErrKeyInvalid)
+	}
+
+	// Try PKIX format first
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err == nil {
+		return publicKey, nil
+	}
+
+	// Try PKCS#1 format
+	rsaKey, err := x509.ParsePKCS1PublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyInvalid, err)
+	}
+	return rsaKey, nil
+}
+
 // LoadKeysFromEnv loads keys from environment variables.
 // Expects JWT_PRIVATE_KEY and JWT_PUBLIC_KEY to contain PEM-encoded keys,
 // or JWT_PRIVATE_KEY_FILE and JWT_PUBLIC_KEY_FILE to contain file paths.
+// RSA, ECDSA P-256 and Ed25519 keys are accepted; the key type sets the
+// signing algorithm.
+// JWT_KEY_ID names the active key. When rotating keys between deployments,
+// set JWT_PREVIOUS_PUBLIC_KEY and JWT_PREVIOUS_KEY_ID to the old key so it
+// keeps verifying tokens it already signed.
 func (km *KeyManager) LoadKeysFromEnv() error {
 	// Try direct PEM content first
 	if privateKeyPEM := os.Getenv("JWT_PRIVATE_KEY"); privateKeyPEM != "" {
@@ -153,60 +264,245 @@ func (km *KeyManager) LoadKeysFromEnv() error {
 	}
 
 	// Load key ID from env
-	km.keyID = os.Getenv("JWT_KEY_ID")
-	if km.keyID == "" {
-		km.keyID = "key-1" // Default key ID
+	keyID := os.Getenv("JWT_KEY_ID")
+	if keyID == "" {
+		keyID = "key-1" // Default key ID
+	}
+	km.SetKeyID(keyID)
+
+	// The key a deployment rotated away from stays verify-only, so tokens it
+	// signed remain valid until they expire.
+	if prevPEM := os.Getenv("JWT_PREVIOUS_PUBLIC_KEY"); prevPEM != "" {
+		prevID := os.Getenv("JWT_PREVIOUS_KEY_ID")
+		if prevID == "" {
+			return errors.New("JWT_PREVIOUS_KEY_ID is required with JWT_PREVIOUS_PUBLIC_KEY")
+		}
+		publicKey, err := parsePublicKeyPEM([]byte(prevPEM))
+		if err != nil {
+			return fmt.Errorf("failed to load previous public key from env: %w", err)
+		}
+		if err := km.AddVerificationKey(prevID, publicKey); err != nil {
+			return fmt.Errorf("failed to add previous public key: %w", err)
+		}
 	}
 
 	return nil
 }
 
-// SetKeyID sets the key ID used in JWT headers.
+// activeKeyLocked returns the active key, creating an empty one if the ring
+// has none yet. Callers must hold the write lock.
+func (km *KeyManager) activeKeyLocked() *Key {
+	if key, ok := km.keys[km.activeID]; ok {
+		return key
+	}
+	now := km.now()
+	key := &Key{ID: km.activeID, Status: KeyStatusActive, CreatedAt: now, ActivatedAt: now}
+	km.keys[km.activeID] = key
+	return key
+}
+
+// SetKeyID sets the ID of the active key, used as the kid in JWT headers.
 func (km *KeyManager) SetKeyID(keyID string) {
 	km.mu.Lock()
 	defer km.mu.Unlock()
-	km.keyID = keyID
+
+	if key, ok := km.keys[km.activeID]; ok {
+		delete(km.keys, km.activeID)
+		key.ID = keyID
+		km.keys[keyID] = key
+	}
+	km.activeID = keyID
 }
 
-// GetKeyID returns the current key ID.
+// GetKeyID returns the ID of the active key.
 func (km *KeyManager) GetKeyID() string {
 	km.mu.RLock()
 	defer km.mu.RUnlock()
-	return km.keyID
+	return km.activeID
+}
+
+// GetPrivateKey returns the active private key.
+func (km *KeyManager) GetPrivateKey() (crypto.Signer, error) {
+	_, _, privateKey, err := km.SigningKey()
+	return privateKey, err
 }
 
-// GetPrivateKey returns the loaded private key.
-func (km *KeyManager) GetPrivateKey() (*rsa.PrivateKey, error) {
+// GetPublicKey returns the active public key.
+func (km *KeyManager) GetPublicKey() (crypto.PublicKey, error) {
 	km.mu.RLock()
 	defer km.mu.RUnlock()
 
-	if km.privateKey == nil {
+	key, ok := km.keys[km.activeID]
+	if !ok || key.PublicKey == nil {
 		return nil, ErrKeyNotLoaded
 	}
-	return km.privateKey, nil
+	return key.PublicKey, nil
 }
 
-// GetPublicKey returns the loaded public key.
-func (km *KeyManager) GetPublicKey() (*rsa.PublicKey, error) {
+// HasPrivateKey returns true if an active private key has been loaded.
+func (km *KeyManager) HasPrivateKey() bool {
+	_, err := km.GetPrivateKey()
+	return err == nil
+}
+
+// HasPublicKey returns true if an active public key has been loaded.
+func (km *KeyManager) HasPublicKey() bool {
+	_, err := km.GetPublicKey()
+	return err == nil
+}
+
+// SigningKey returns the active key's ID, algorithm and private key
+// together, so a concurrent rotation can't pair a token's kid with the
+// wrong key.
+func (km *KeyManager) SigningKey() (string, string, crypto.Signer, error) {
 	km.mu.RLock()
 	defer km.mu.RUnlock()
 
-	if km.publicKey == nil {
-		return nil, ErrKeyNotLoaded
+	key, ok := km.keys[km.activeID]
+	if !ok || key.PrivateKey == nil {
+		return "", "", nil, ErrKeyNotLoaded
 	}
-	return km.publicKey, nil
+	return key.ID, key.Algorithm, key.PrivateKey, nil
 }
 
-// HasPrivateKey returns true if a private key has been loaded.
-func (km *KeyManager) HasPrivateKey() bool {
+// VerificationKey returns the algorithm and public key for a kid. Active and
+// verify-only keys are returned; retired and unknown keys are not.
+func (km *KeyManager) VerificationKey(keyID string) (string, crypto.PublicKey, error) {
 	km.mu.RLock()
 	defer km.mu.RUnlock()
-	return km.privateKey != nil
+
+	key, ok := km.keys[keyID]
+	if !ok || key.PublicKey == nil {
+		return "", nil, ErrKeyUnknown
+	}
+	if key.Status == KeyStatusRetired {
+		return "", nil, ErrKeyRetired
+	}
+	return key.Algorithm, key.PublicKey, nil
 }
 
-// HasPublicKey returns true if a public key has been loaded.
-func (km *KeyManager) HasPublicKey() bool {
+// AddKey adds a signing key to the ring as verify-only. It is published
+// (and accepted) straight away but only signs once activated. The key must
+// be RSA, ECDSA P-256 or Ed25519.
+func (km *KeyManager) AddKey(keyID string, privateKey crypto.Signer) error {
+	return km.addKey(&Key{ID: keyID, PrivateKey: privateKey, PublicKey: privateKey.Public()})
+}
+
+// AddVerificationKey adds a public key that can verify tokens but never sign,
+// e.g. the key a previous deployment signed with.
+func (km *KeyManager) AddVerificationKey(keyID string, publicKey crypto.PublicKey) error {
+	return km.addKey(&Key{ID: keyID, PublicKey: publicKey})
+}
+
+func (km *KeyManager) addKey(key *Key) error {
+	if key.ID == "" {
+		return fmt.Errorf("%w: key id is required", ErrKeyInvalid)
+	}
+	algorithm, err := algorithmFor(key.PublicKey)
+	if err != nil {
+		return err
+	}
+	key.Algorithm = algorithm
+
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	if _, exists := km.keys[key.ID]; exists {
+		return fmt.Errorf("%w: %s", ErrKeyExists, key.ID)
+	}
+	key.Status = KeyStatusVerifyOnly
+	key.CreatedAt = km.now()
+	km.keys[key.ID] = key
+	return nil
+}
+
+// Activate makes a key the active signing key. The previously active key is
+// demoted to verify-only and retires automatically after grace (which should
+// be at least the access token TTL); a zero grace keeps it until retired
+// explicitly.
+func (km *KeyManager) Activate(keyID string, grace time.Duration) error {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	key, ok := km.keys[keyID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrKeyUnknown, keyID)
+	}
+	if key.Status == KeyStatusRetired {
+		return fmt.Errorf("%w: %s", ErrKeyRetired, keyID)
+	}
+	if key.PrivateKey == nil {
+		return fmt.Errorf("%w: %s has no private key", ErrKeyNotLoaded, keyID)
+	}
+	if keyID == km.activeID {
+		return nil
+	}
+
+	now := km.now()
+	if prev, ok := km.keys[km.activeID]; ok {
+		prev.Status = KeyStatusVerifyOnly
+		if grace > 0 {
+			prev.RetireAt = now.Add(grace)
+		}
+	}
+
+	key.Status = KeyStatusActive
+	key.ActivatedAt = now
+	key.RetireAt = time.Time{}
+	km.activeID = keyID
+	return nil
+}
+
+// Retire stops a key from verifying tokens and removes it from the JWKS.
+// Tokens it signed are rejected from then on.
+func (km *KeyManager) Retire(keyID string) error {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	key, ok := km.keys[keyID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrKeyUnknown, keyID)
+	}
+	if keyID == km.activeID {
+		return ErrKeyActive
+	}
+	key.Status = KeyStatusRetired
+	key.PrivateKey = nil
+	return nil
+}
+
+// RetireExpired retires verify-only keys whose grace period has ended and
+// returns their IDs.
+func (km *KeyManager) RetireExpired(now time.Time) []string {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	var retired []string
+	for id, key := range km.keys {
+		if key.Status == KeyStatusVerifyOnly && !key.RetireAt.IsZero() && !now.Before(key.RetireAt) {
+			key.Status = KeyStatusRetired
+			key.PrivateKey = nil
+			retired = append(retired, id)
+		}
+	}
+	sort.Strings(retired)
+	return retired
+}
+
+// Keys returns a snapshot of every key in the ring, oldest first.
+func (km *KeyManager) Keys() []Key {
 	km.mu.RLock()
 	defer km.mu.RUnlock()
-	return km.publicKey != nil
+
+	keys := make([]Key, 0, len(km.keys))
+	for _, key := range km.keys {
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys
 }
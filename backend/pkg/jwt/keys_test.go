@@ -0,0 +1,380 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newTestRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return privateKey
+}
+
+func signTestToken(t *testing.T, km *KeyManager) string {
+	t.Helper()
+
+	generator := NewTokenGenerator(km, DefaultTokenGeneratorConfig())
+	token, _, err := generator.GenerateAccessToken(uuid.New(), uuid.New(), uuid.New(), "test@example.com", "waiter", nil)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	return token
+}
+
+func TestKeyRing_RotationKeepsOldTokensValid(t *testing.T) {
+	km := generateTestKeyPair(t)
+	cfg := DefaultTokenGeneratorConfig()
+	validator := NewTokenValidator(km, cfg.Issuer, cfg.Audience)
+
+	oldToken := signTestToken(t, km)
+
+	if err := km.AddKey("test-key-2", newTestRSAKey(t)); err != nil {
+		t.Fatalf("AddKey failed: %v", err)
+	}
+	if km.GetKeyID() != "test-key-1" {
+		t.Error("Added key should not sign until activated")
+	}
+	if err := km.Activate("test-key-2", time.Hour); err != nil {
+		t.Fatalf("Activate failed: %v", err)
+	}
+
+	newToken := signTestToken(t, km)
+
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := validator.ValidateToken(token); err != nil {
+			t.Errorf("%s token should validate after rotation: %v", name, err)
+		}
+	}
+
+	if err := km.Retire("test-key-1"); err != nil {
+		t.Fatalf("Retire failed: %v", err)
+	}
+	if _, err := validator.ValidateToken(oldToken); err == nil {
+		t.Error("Token signed by a retired key should be rejected")
+	}
+	if _, err := validator.ValidateToken(newToken); err != nil {
+		t.Errorf("Token signed by the active key should still validate: %v", err)
+	}
+}
+
+func TestKeyRing_UnknownKid(t *testing.T) {
+	km := generateTestKeyPair(t)
+	token := signTestToken(t, km)
+
+	other := NewKeyManager()
+	if err := other.AddKey("another-key", newTestRSAKey(t)); err != nil {
+		t.Fatalf("AddKey failed: %v", err)
+	}
+	other.Activate("another-key", 0)
+
+	cfg := DefaultTokenGeneratorConfig()
+	if _, err := NewTokenValidator(other, cfg.Issuer, cfg.Audience).ValidateToken(token); err == nil {
+		t.Error("Token with a kid missing from the ring should be rejected")
+	}
+}
+
+func TestKeyRing_Errors(t *testing.T) {
+	km := generateTestKeyPair(t)
+	publicOnly := &newTestRSAKey(t).PublicKey
+
+	if err := km.AddKey("test-key-1", newTestRSAKey(t)); !errors.Is(err, ErrKeyExists) {
+		t.Errorf("AddKey duplicate: expected ErrKeyExists, got %v", err)
+	}
+	if err := km.AddVerificationKey("", publicOnly); !errors.Is(err, ErrKeyInvalid) {
+		t.Errorf("AddVerificationKey without id: expected ErrKeyInvalid, got %v", err)
+	}
+	if err := km.AddVerificationKey("verify-only", publicOnly); err != nil {
+		t.Fatalf("AddVerificationKey failed: %v", err)
+	}
+	if err := km.Activate("verify-only", 0); !errors.Is(err, ErrKeyNotLoaded) {
+		t.Errorf("Activate public-only key: expected ErrKeyNotLoaded, got %v", err)
+	}
+	if err := km.Activate("missing", 0); !errors.Is(err, ErrKeyUnknown) {
+		t.Errorf("Activate unknown key: expected ErrKeyUnknown, got %v", err)
+	}
+	if err := km.Retire("test-key-1"); !errors.Is(err, ErrKeyActive) {
+		t.Errorf("Retire active key: expected ErrKeyActive, got %v", err)
+	}
+	if err := km.Retire("verify-only"); err != nil {
+		t.Fatalf("Retire failed: %v", err)
+	}
+	if _, _, err := km.VerificationKey("verify-only"); !errors.Is(err, ErrKeyRetired) {
+		t.Errorf("VerificationKey retired: expected ErrKeyRetired, got %v", err)
+	}
+	if err := km.Activate("verify-only", 0); !errors.Is(err, ErrKeyRetired) {
+		t.Errorf("Activate retired key: expected ErrKeyRetired, got %v", err)
+	}
+}
+
+func TestKeyRing_RetireExpired(t *testing.T) {
+	km := generateTestKeyPair(t)
+	now := time.Now()
+	km.now = func() time.Time { return now }
+
+	km.AddKey("test-key-2", newTestRSAKey(t))
+	km.Activate("test-key-2", time.Hour)
+
+	if retired := km.RetireExpired(now.Add(59 * time.Minute)); len(retired) != 0 {
+		t.Errorf("Nothing should retire within the grace period, got %v", retired)
+	}
+	retired := km.RetireExpired(now.Add(time.Hour))
+	if len(retired) != 1 || retired[0] != "test-key-1" {
+		t.Errorf("RetireExpired = %v, want [test-key-1]", retired)
+	}
+}
+
+func TestKeyManager_LegacyLoadAndSetKeyID(t *testing.T) {
+	privateKey := newTestRSAKey(t)
+	privPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+
+	km := NewKeyManager()
+	if err := km.LoadPrivateKeyFromPEM(privPEM); err != nil {
+		t.Fatalf("LoadPrivateKeyFromPEM failed: %v", err)
+	}
+	km.SetKeyID("env-key")
+
+	if km.GetKeyID() != "env-key" {
+		t.Errorf("GetKeyID = %q, want env-key", km.GetKeyID())
+	}
+	if !km.HasPrivateKey() || !km.HasPublicKey() {
+		t.Error("Loaded key should be usable for signing and verifying")
+	}
+	if _, _, err := km.VerificationKey("env-key"); err != nil {
+		t.Errorf("Renamed key should be found by its new kid: %v", err)
+	}
+	if len(km.Keys()) != 1 {
+		t.Errorf("Expected a single key in the ring, got %d", len(km.Keys()))
+	}
+}
+
+func TestLoadKeysFromEnv_PreviousKey(t *testing.T) {
+	current := newTestRSAKey(t)
+	previous := newTestRSAKey(t)
+	prevPub, _ := x509.MarshalPKIXPublicKey(&previous.PublicKey)
+
+	t.Setenv("JWT_PRIVATE_KEY", string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(current)})))
+	t.Setenv("JWT_KEY_ID", "key-2")
+	t.Setenv("JWT_PREVIOUS_PUBLIC_KEY", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: prevPub})))
+	t.Setenv("JWT_PREVIOUS_KEY_ID", "key-1")
+
+	km := NewKeyManager()
+	if err := km.LoadKeysFromEnv(); err != nil {
+		t.Fatalf("LoadKeysFromEnv failed: %v", err)
+	}
+
+	if km.GetKeyID() != "key-2" {
+		t.Errorf("Active key = %q, want key-2", km.GetKeyID())
+	}
+	_, pub, err := km.VerificationKey("key-1")
+	if err != nil {
+		t.Fatalf("Previous key should verify: %v", err)
+	}
+	if !previous.PublicKey.Equal(pub) {
+		t.Error("Previous key does not match the configured public key")
+	}
+
+	t.Setenv("JWT_PREVIOUS_KEY_ID", "")
+	if err := NewKeyManager().LoadKeysFromEnv(); err == nil {
+		t.Error("Expected an error when the previous key has no ID")
+	}
+}
+
+func TestKeyManager_JWKS(t *testing.T) {
+	km := generateTestKeyPair(t)
+	second := newTestRSAKey(t)
+	km.AddKey("test-key-2", second)
+	km.AddVerificationKey("test-key-3", &newTestRSAKey(t).PublicKey)
+	km.Retire("test-key-3")
+
+	set := km.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("Expected active and verify-only keys only, got %d", len(set.Keys))
+	}
+
+	var jwk *JWK
+	for i := range set.Keys {
+		if set.Keys[i].KeyID == "test-key-2" {
+			jwk = &set.Keys[i]
+		}
+	}
+	if jwk == nil {
+		t.Fatal("Pre-published key should be in the JWKS")
+	}
+	if jwk.KeyType != "RSA" || jwk.Algorithm != "RS256" || jwk.Use != "sig" {
+		t.Errorf("unexpected JWK metadata: %+v", jwk)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(jwk.Modulus)
+	if err != nil {
+		t.Fatalf("modulus is not base64url: %v", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.Exponent)
+	if err != nil {
+		t.Fatalf("exponent is not base64url: %v", err)
+	}
+	if new(big.Int).SetBytes(n).Cmp(second.PublicKey.N) != 0 || int(new(big.Int).SetBytes(e).Int64()) != second.PublicKey.E {
+		t.Error("JWK does not match the public key")
+	}
+}
+
+func TestKeyManager_LoadECAndEd25519FromPEM(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecSEC1, _ := x509.MarshalECPrivateKey(ecKey)
+	ecPKCS8, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+	ecPub, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edPKCS8, _ := x509.MarshalPKCS8PrivateKey(edKey)
+	edPKIX, _ := x509.MarshalPKIXPublicKey(edPub)
+
+	tests := []struct {
+		name    string
+		private *pem.Block
+		public  *pem.Block
+		alg     string
+	}{
+		{"EC SEC 1", &pem.Block{Type: "EC PRIVATE KEY", Bytes: ecSEC1}, &pem.Block{Type: "PUBLIC KEY", Bytes: ecPub}, AlgES256},
+		{"EC PKCS#8", &pem.Block{Type: "PRIVATE KEY", Bytes: ecPKCS8}, &pem.Block{Type: "PUBLIC KEY", Bytes: ecPub}, AlgES256},
+		{"Ed25519 PKCS#8", &pem.Block{Type: "PRIVATE KEY", Bytes: edPKCS8}, &pem.Block{Type: "PUBLIC KEY", Bytes: edPKIX}, AlgEdDSA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km := NewKeyManager()
+			if err := km.LoadPrivateKeyFromPEM(pem.EncodeToMemory(tt.private)); err != nil {
+				t.Fatalf("LoadPrivateKeyFromPEM failed: %v", err)
+			}
+			if err := km.LoadPublicKeyFromPEM(pem.EncodeToMemory(tt.public)); err != nil {
+				t.Fatalf("LoadPublicKeyFromPEM failed: %v", err)
+			}
+			km.SetKeyID("env-key")
+
+			_, alg, _, err := km.SigningKey()
+			if err != nil {
+				t.Fatalf("SigningKey failed: %v", err)
+			}
+			if alg != tt.alg {
+				t.Errorf("algorithm = %s, want %s", alg, tt.alg)
+			}
+			cfg := DefaultTokenGeneratorConfig()
+			if _, err := NewTokenValidator(km, cfg.Issuer, cfg.Audience).ValidateToken(signTestToken(t, km)); err != nil {
+				t.Errorf("token signed with loaded key should validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyManager_RejectsMismatchedAndUnsupportedKeys(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecSEC1, _ := x509.MarshalECPrivateKey(ecKey)
+	rsaPub, _ := x509.MarshalPKIXPublicKey(&newTestRSAKey(t).PublicKey)
+
+	km := NewKeyManager()
+	if err := km.LoadPrivateKeyFromPEM(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecSEC1})); err != nil {
+		t.Fatalf("LoadPrivateKeyFromPEM failed: %v", err)
+	}
+	if err := km.LoadPublicKeyFromPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaPub})); !errors.Is(err, ErrKeyInvalid) {
+		t.Errorf("RSA public key for EC private key: expected ErrKeyInvalid, got %v", err)
+	}
+
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err := km.AddKey("p384", p384Key); !errors.Is(err, ErrKeyInvalid) {
+		t.Errorf("P-384 key: expected ErrKeyInvalid, got %v", err)
+	}
+}
+
+func TestKeyManager_JWKS_ECAndEd25519(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	km := NewKeyManager()
+	km.AddKey("ec-key", ecKey)
+	km.AddKey("ed-key", edKey)
+
+	byID := map[string]JWK{}
+	for _, jwk := range km.JWKS().Keys {
+		byID[jwk.KeyID] = jwk
+	}
+
+	ec := byID["ec-key"]
+	if ec.KeyType != "EC" || ec.Curve != "P-256" || ec.Algorithm != AlgES256 || ec.Modulus != "" {
+		t.Errorf("unexpected EC JWK: %+v", ec)
+	}
+	x, _ := base64.RawURLEncoding.DecodeString(ec.X)
+	y, _ := base64.RawURLEncoding.DecodeString(ec.Y)
+	if len(x) != 32 || len(y) != 32 || new(big.Int).SetBytes(x).Cmp(ecKey.X) != 0 || new(big.Int).SetBytes(y).Cmp(ecKey.Y) != 0 {
+		t.Error("EC JWK coordinates do not match the public key")
+	}
+
+	ed := byID["ed-key"]
+	if ed.KeyType != "OKP" || ed.Curve != "Ed25519" || ed.Algorithm != AlgEdDSA {
+		t.Errorf("unexpected Ed25519 JWK: %+v", ed)
+	}
+	if ed.X != base64.RawURLEncoding.EncodeToString(edPub) {
+		t.Error("Ed25519 JWK does not match the public key")
+	}
+}
+
+func TestJWK_PublicKey_RoundTrip(t *testing.T) {
+	rsaKey := newTestRSAKey(t)
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	edPub, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	for name, pub := range map[string]interface{ Equal(x crypto.PublicKey) bool }{
+		"rsa":     &rsaKey.PublicKey,
+		"ec":      &p384Key.PublicKey,
+		"ed25519": edPub,
+	} {
+		jwk, ok := toJWK(pub)
+		if !ok {
+			t.Fatalf("%s: toJWK failed", name)
+		}
+		decoded, err := jwk.PublicKey()
+		if err != nil {
+			t.Fatalf("%s: PublicKey() error = %v", name, err)
+		}
+		if !pub.Equal(decoded) {
+			t.Errorf("%s: decoded key does not match the original", name)
+		}
+	}
+}
+
+func TestJWK_PublicKey_Invalid(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	valid, _ := toJWK(&ecKey.PublicKey)
+	offCurve := valid
+	offCurve.Y = valid.X
+
+	tests := map[string]JWK{
+		"unknown key type": {KeyType: "oct"},
+		"rsa no modulus":   {KeyType: "RSA", Exponent: "AQAB"},
+		"rsa bad exponent": {KeyType: "RSA", Modulus: "AQAB", Exponent: "AQ"},
+		"unknown curve":    {KeyType: "EC", Curve: "secp256k1", X: valid.X, Y: valid.Y},
+		"short coordinate": {KeyType: "EC", Curve: "P-256", X: "AQAB", Y: valid.Y},
+		"point off curve":  offCurve,
+		"short ed25519":    {KeyType: "OKP", Curve: "Ed25519", X: "AQAB"},
+		"x25519":           {KeyType: "OKP", Curve: "X25519", X: valid.X},
+	}
+	for name, jwk := range tests {
+		if _, err := jwk.PublicKey(); !errors.Is(err, ErrKeyInvalid) {
+			t.Errorf("%s: expected ErrKeyInvalid, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// KeyRotatorConfig holds configuration for scheduled key rotation.
+type KeyRotatorConfig struct {
+	// Interval is how long a key signs before it is replaced.
+	Interval time.Duration
+	// PrePublish is how long a new key sits in the JWKS as verify-only before
+	// it starts signing, so clients that cache the JWKS learn it first.
+	PrePublish time.Duration
+	// Grace is how long the replaced key keeps verifying after it stops
+	// signing. It must be at least the access token TTL.
+	Grace time.Duration
+	// Algorithm is the signing algorithm of generated keys: RS256, ES256 or
+	// EdDSA.
+	Algorithm string
+	// KeyBits is the RSA key size for generated keys. Ignored for ES256 and
+	// EdDSA.
+	KeyBits int
+}
+
+// DefaultKeyRotatorConfig returns default configuration.
+func DefaultKeyRotatorConfig() KeyRotatorConfig {
+	return KeyRotatorConfig{
+		Interval:   30 * 24 * time.Hour,
+		PrePublish: time.Hour,
+		Grace:      2 * time.Hour, // twice the 60 minute access token TTL
+		Algorithm:  AlgRS256,
+		KeyBits:    2048,
+	}
+}
+
+// KeyRotator rotates the active signing key on a schedule. Each rotation
+// generates a key, publishes it as verify-only for PrePublish, activates it,
+// then retires the previous key once Grace has passed.
+//
+// Generated keys live only in this process's key ring, so scheduled rotation
+// suits single-instance deployments. Multi-instance deployments should rotate
+// through configuration instead (see LoadKeysFromEnv).
+type KeyRotator struct {
+	keyManager *KeyManager
+	config     KeyRotatorConfig
+
+	mu        sync.Mutex
+	pendingID string
+	pendingAt time.Time // when the pending key is activated
+}
+
+// NewKeyRotator creates a new KeyRotator.
+func NewKeyRotator(keyManager *KeyManager, cfg KeyRotatorConfig) *KeyRotator {
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = DefaultKeyRotatorConfig().Algorithm
+	}
+	if cfg.KeyBits == 0 {
+		cfg.KeyBits = DefaultKeyRotatorConfig().KeyBits
+	}
+	return &KeyRotator{keyManager: keyManager, config: cfg}
+}
+
+// Tick advances the rotation schedule to now: retiring keys whose grace has
+// ended, activating a pending key that has been published long enough, and
+// publishing the next key when the active one is due for replacement.
+func (r *KeyRotator) Tick(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range r.keyManager.RetireExpired(now) {
+		log.Printf("jwt: retired signing key %s", id)
+	}
+
+	if r.pendingID == "" {
+		if !r.dueLocked(now) {
+			return nil
+		}
+		if err := r.publishLocked(now); err != nil {
+			return err
+		}
+	}
+
+	if now.Before(r.pendingAt) {
+		return nil
+	}
+	if err := r.keyManager.Activate(r.pendingID, r.config.Grace); err != nil {
+		return fmt.Errorf("activate key %s: %w", r.pendingID, err)
+	}
+	log.Printf("jwt: activated signing key %s", r.pendingID)
+	r.pendingID = ""
+	return nil
+}
+
+// publishLocked generates the next key and adds it to the ring as verify-only.
+func (r *KeyRotator) publishLocked(now time.Time) error {
+	id, err := newKeyID()
+	if err != nil {
+		return err
+	}
+	privateKey, err := generateKey(r.config.Algorithm, r.config.KeyBits)
+	if err != nil {
+		return fmt.Errorf("generate key: %w", err)
+	}
+	if err := r.keyManager.AddKey(id, privateKey); err != nil {
+		return fmt.Errorf("add key %s: %w", id, err)
+	}
+	r.pendingID = id
+	r.pendingAt = now.Add(r.config.PrePublish)
+	log.Printf("jwt: published signing key %s, active from %s", id, r.pendingAt.Format(time.RFC3339))
+
+	return nil
+}
+
+// dueLocked reports whether the active key should be replaced, counting the
+// pre-publish window so the new key is ready when Interval is reached.
+func (r *KeyRotator) dueLocked(now time.Time) bool {
+	activeID := r.keyManager.GetKeyID()
+	for _, key := range r.keyManager.Keys() {
+		if key.ID == activeID && key.Status == KeyStatusActive {
+			return !now.Before(key.ActivatedAt.Add(r.config.Interval - r.config.PrePublish))
+		}
+	}
+	// No active key to rotate from
+	return false
+}
+
+// Run calls Tick every checkEvery until ctx is cancelled.
+func (r *KeyRotator) Run(ctx context.Context, checkEvery time.Duration) {
+	ticker := time.NewTicker(checkEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := r.Tick(now); err != nil {
+				log.Printf("ERROR: jwt key rotation: %v", err)
+			}
+		}
+	}
+}
+
+// generateKey generates a private key for a signing algorithm.
+func generateKey(algorithm string, rsaBits int) (crypto.Signer, error) {
+	switch algorithm {
+	case AlgRS256:
+		return rsa.GenerateKey(rand.Reader, rsaBits)
+	case AlgES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+}
+
+// newKeyID generates a random key ID for a rotated key.
+func newKeyID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate key id: %w", err)
+	}
+	return "key-" + hex.EncodeToString(b), nil
+}
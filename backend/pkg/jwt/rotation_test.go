@@ -0,0 +1,107 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyRotator_Schedule(t *testing.T) {
+	km := generateTestKeyPair(t)
+	start := time.Now()
+	clock := start
+	km.now = func() time.Time { return clock }
+	// Re-activate under the fake clock so the schedule starts at start
+	km.keys["test-key-1"].ActivatedAt = start
+
+	cfg := KeyRotatorConfig{Interval: 24 * time.Hour, PrePublish: time.Hour, Grace: 2 * time.Hour, KeyBits: 1024}
+	rotator := NewKeyRotator(km, cfg)
+	tick := func(at time.Duration) {
+		t.Helper()
+		clock = start.Add(at)
+		if err := rotator.Tick(clock); err != nil {
+			t.Fatalf("Tick(%v) failed: %v", at, err)
+		}
+	}
+
+	tick(22 * time.Hour)
+	if len(km.Keys()) != 1 {
+		t.Fatalf("No key should be published before the pre-publish window, got %d keys", len(km.Keys()))
+	}
+
+	// Next key is published as verify-only an hour before the interval ends
+	tick(23 * time.Hour)
+	keys := km.Keys()
+	if len(keys) != 2 || keys[1].Status != KeyStatusVerifyOnly {
+		t.Fatalf("Expected a pre-published verify-only key, got %+v", keys)
+	}
+	if km.GetKeyID() != "test-key-1" {
+		t.Error("Pre-published key should not sign yet")
+	}
+	nextID := keys[1].ID
+	if len(km.JWKS().Keys) != 2 {
+		t.Error("Pre-published key should be in the JWKS")
+	}
+
+	// ...and activated once the pre-publish window has passed
+	tick(24 * time.Hour)
+	if km.GetKeyID() != nextID {
+		t.Fatalf("Active key = %q, want %q", km.GetKeyID(), nextID)
+	}
+	if _, _, err := km.VerificationKey("test-key-1"); err != nil {
+		t.Errorf("Previous key should verify during the grace period: %v", err)
+	}
+
+	// Previous key retires after the grace period
+	tick(26 * time.Hour)
+	if _, _, err := km.VerificationKey("test-key-1"); err == nil {
+		t.Error("Previous key should be retired after the grace period")
+	}
+	if _, _, err := km.VerificationKey(nextID); err != nil {
+		t.Errorf("Active key should verify: %v", err)
+	}
+}
+
+func TestKeyRotator_NoPrePublish(t *testing.T) {
+	km := generateTestKeyPair(t)
+	start := time.Now()
+	km.now = func() time.Time { return start }
+	km.keys["test-key-1"].ActivatedAt = start
+
+	rotator := NewKeyRotator(km, KeyRotatorConfig{Interval: time.Hour, KeyBits: 1024})
+	if err := rotator.Tick(start.Add(time.Hour)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if km.GetKeyID() == "test-key-1" {
+		t.Error("Without a pre-publish window the new key should sign straight away")
+	}
+}
+
+func TestKeyRotator_NoActiveKey(t *testing.T) {
+	km := NewKeyManager()
+	rotator := NewKeyRotator(km, DefaultKeyRotatorConfig())
+
+	if err := rotator.Tick(time.Now().Add(365 * 24 * time.Hour)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(km.Keys()) != 0 {
+		t.Error("Rotator should not create keys when nothing is loaded")
+	}
+}
+
+func TestKeyRotator_Algorithm(t *testing.T) {
+	km := generateTestKeyPair(t)
+	start := time.Now()
+	km.now = func() time.Time { return start }
+	km.keys["test-key-1"].ActivatedAt = start
+
+	rotator := NewKeyRotator(km, KeyRotatorConfig{Interval: time.Hour, Algorithm: AlgEdDSA})
+	if err := rotator.Tick(start.Add(time.Hour)); err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if _, alg, _, _ := km.SigningKey(); alg != AlgEdDSA {
+		t.Errorf("rotated key algorithm = %s, want %s", alg, AlgEdDSA)
+	}
+	if err := NewKeyRotator(km, KeyRotatorConfig{Interval: time.Hour, Algorithm: "HS256"}).Tick(start.Add(3 * time.Hour)); err == nil {
+		t.Error("expected an error for an unsupported algorithm")
+	}
+}
@@ -0,0 +1,230 @@
+// Package oauth implements the protocol parts of an OAuth 2.0 authorization
+// server (RFC 6749): scopes, PKCE verification (RFC 7636), redirect URI
+// rules, and the token endpoint's request and response formats, including
+// token revocation (RFC 7009). Clients, grants and token issuance are left
+// to the caller.
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Grant types.
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+	GrantRefreshToken      = "refresh_token"
+)
+
+// ResponseTypeCode is the only response type supported at the
+// authorization endpoint: the authorization code flow.
+const ResponseTypeCode = "code"
+
+// CodeChallengeMethodS256 is the only PKCE method supported. The plain
+// method offers no protection against a stolen code.
+const CodeChallengeMethodS256 = "S256"
+
+// TokenTypeBearer is the type of every access token issued.
+const TokenTypeBearer = "Bearer"
+
+// Token type hints of a revocation request.
+const (
+	TokenHintAccessToken  = "access_token"
+	TokenHintRefreshToken = "refresh_token"
+)
+
+// Error codes (RFC 6749 sections 4.1.2.1 and 5.2).
+const (
+	ErrorInvalidRequest          = "invalid_request"
+	ErrorInvalidClient           = "invalid_client"
+	ErrorInvalidGrant            = "invalid_grant"
+	ErrorUnauthorizedClient      = "unauthorized_client"
+	ErrorUnsupportedGrantType    = "unsupported_grant_type"
+	ErrorUnsupportedResponseType = "unsupported_response_type"
+	ErrorInvalidScope            = "invalid_scope"
+	ErrorAccessDenied            = "access_denied"
+	ErrorServerError             = "server_error"
+)
+
+// Error is an OAuth error response. It is returned as an error by this
+// package and can be written as is, or added to a redirect with
+// RedirectError.
+type Error struct {
+	Code        string
+	Description string
+}
+
+// NewError creates an Error with the given code.
+func NewError(code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Description: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("oauth: %s: %s", e.Code, e.Description)
+}
+
+// Status returns the HTTP status the token endpoint answers the error with.
+func (e *Error) Status() int {
+	switch e.Code {
+	case ErrorInvalidClient:
+		return http.StatusUnauthorized
+	case ErrorServerError:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// MarshalJSON writes the error in the token endpoint's error format.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description,omitempty"`
+	}{e.Code, e.Description})
+}
+
+// TokenResponse is the token endpoint's successful response.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// ParseScope splits a space-delimited scope parameter into its scopes,
+// dropping duplicates.
+func ParseScope(scope string) []string {
+	var scopes []string
+	for _, s := range strings.Fields(scope) {
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// FormatScope joins scopes into a space-delimited scope parameter.
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// ValidCodeChallenge reports whether challenge is a well-formed S256 code
+// challenge: a base64url-encoded SHA-256 hash without padding.
+func ValidCodeChallenge(challenge string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(b) == sha256.Size
+}
+
+// VerifyCodeVerifier reports whether verifier is well formed (43-128
+// unreserved characters) and matches the S256 challenge.
+func VerifyCodeVerifier(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	for _, r := range verifier {
+		if !isUnreserved(r) {
+			return false
+		}
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// isUnreserved reports whether r may appear in a code verifier.
+func isUnreserved(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+		r == '-' || r == '.' || r == '_' || r == '~'
+}
+
+// ValidRedirectURI reports whether uri may be registered as a client's
+// redirect URI: an absolute https URL without a fragment, or an http URL on
+// the loopback interface for native apps (RFC 8252 section 7.3).
+func ValidRedirectURI(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" || u.Fragment != "" || u.User != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := u.Hostname()
+		if host == "localhost" {
+			return true
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	default:
+		return false
+	}
+}
+
+// RedirectCode returns the redirect sending an authorization code back to
+// the client, echoing its state.
+func RedirectCode(redirectURI, code, state string) string {
+	return withQuery(redirectURI, url.Values{"code": {code}}, state)
+}
+
+// RedirectError returns the redirect reporting an authorization error back
+// to the client, echoing its state.
+func RedirectError(redirectURI string, e *Error, state string) string {
+	params := url.Values{"error": {e.Code}}
+	if e.Description != "" {
+		params.Set("error_description", e.Description)
+	}
+	return withQuery(redirectURI, params, state)
+}
+
+// withQuery adds params and a non-empty state to redirectURI's query,
+// keeping any query it already has.
+func withQuery(redirectURI string, params url.Values, state string) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	q := u.Query()
+	for k, v := range params {
+		q[k] = v
+	}
+	if state != "" {
+		q.Set("state", state)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// ClientCredentials extracts the client's credentials from a token or
+// revocation request: HTTP Basic (client_secret_basic), with the values
+// form-encoded as RFC 6749 section 2.3.1 requires, or else the client_id
+// and client_secret form parameters (client_secret_post, or a public
+// client identifying itself). The form must already be parsed.
+func ClientCredentials(r *http.Request) (clientID, clientSecret string, err error) {
+	if id, secret, ok := r.BasicAuth(); ok {
+		if clientID, err = url.QueryUnescape(id); err != nil {
+			return "", "", NewError(ErrorInvalidClient, "malformed client credentials")
+		}
+		if clientSecret, err = url.QueryUnescape(secret); err != nil {
+			return "", "", NewError(ErrorInvalidClient, "malformed client credentials")
+		}
+		if r.PostForm.Get("client_secret") != "" {
+			return "", "", NewError(ErrorInvalidRequest, "use only one client authentication method")
+		}
+		return clientID, clientSecret, nil
+	}
+	clientID = r.PostForm.Get("client_id")
+	if clientID == "" {
+		return "", "", NewError(ErrorInvalidClient, "client authentication is required")
+	}
+	return clientID, r.PostForm.Get("client_secret"), nil
+}
@@ -0,0 +1,391 @@
+// Package oidc is a minimal OpenID Connect relying party: provider discovery,
+// the authorization code flow with PKCE (RFC 7636), and ID token validation
+// against the provider's published JWKS.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/solobueno/erp/pkg/jwt"
+)
+
+var (
+	// ErrDiscovery is returned when a provider's discovery document can't be
+	// fetched or doesn't describe the expected issuer.
+	ErrDiscovery = errors.New("oidc: provider discovery failed")
+	// ErrExchange is returned when the token endpoint rejects an authorization code.
+	ErrExchange = errors.New("oidc: authorization code exchange failed")
+	// ErrIDTokenInvalid is returned when an ID token fails validation.
+	ErrIDTokenInvalid = errors.New("oidc: id token is invalid")
+)
+
+// maxResponseSize caps how much of a provider response is read.
+const maxResponseSize = 1 << 20
+
+// keyRefreshInterval is the minimum time between JWKS fetches triggered by
+// an unknown key id, so forged kids can't make us hammer the provider.
+var keyRefreshInterval = time.Minute
+
+// clockSkew is the leeway allowed on ID token exp, iat and nbf.
+const clockSkew = time.Minute
+
+// idTokenAlgorithms are the signing algorithms accepted on ID tokens.
+var idTokenAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Provider is an OpenID Connect identity provider, as described by its
+// discovery document. It caches the provider's signing keys and is safe for
+// concurrent use.
+type Provider struct {
+	Issuer                string
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	JWKSURI               string
+
+	client *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+// discoveryDocument is the subset of /.well-known/openid-configuration we use.
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Discover fetches the issuer's discovery document. The issuer and every
+// endpoint must be https URLs, and the document must name exactly the
+// issuer it was fetched for. A nil client uses http.DefaultClient.
+func Discover(ctx context.Context, client *http.Client, issuer string) (*Provider, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if !isHTTPS(issuer) {
+		return nil, fmt.Errorf("%w: issuer must be an https URL", ErrDiscovery)
+	}
+
+	var doc discoveryDocument
+	wellKnown := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, client, wellKnown, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	if doc.Issuer != issuer {
+		return nil, fmt.Errorf("%w: document is for issuer %q", ErrDiscovery, doc.Issuer)
+	}
+	for _, endpoint := range []string{doc.AuthorizationEndpoint, doc.TokenEndpoint, doc.JWKSURI} {
+		if !isHTTPS(endpoint) {
+			return nil, fmt.Errorf("%w: endpoints must be https URLs", ErrDiscovery)
+		}
+	}
+
+	return &Provider{
+		Issuer:                doc.Issuer,
+		AuthorizationEndpoint: doc.AuthorizationEndpoint,
+		TokenEndpoint:         doc.TokenEndpoint,
+		JWKSURI:               doc.JWKSURI,
+		client:                client,
+	}, nil
+}
+
+// AuthRequest holds the parameters of an authorization request.
+type AuthRequest struct {
+	ClientID      string
+	RedirectURI   string
+	State         string
+	Nonce         string
+	CodeChallenge string   // S256 challenge from CodeChallengeS256
+	Scopes        []string // Defaults to openid, email and profile
+}
+
+// AuthCodeURL returns the URL to send the user's browser to for sign-in.
+func (p *Provider) AuthCodeURL(req AuthRequest) string {
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {req.ClientID},
+		"redirect_uri":          {req.RedirectURI},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {req.State},
+		"nonce":                 {req.Nonce},
+		"code_challenge":        {req.CodeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.AuthorizationEndpoint + sep + params.Encode()
+}
+
+// TokenRequest holds the parameters of an authorization code exchange.
+type TokenRequest struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Code         string
+	CodeVerifier string
+}
+
+// TokenResponse is the token endpoint's answer to a code exchange.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+}
+
+// tokenError is the token endpoint's error body (RFC 6749 section 5.2).
+type tokenError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Exchange redeems an authorization code at the token endpoint, proving
+// possession of the PKCE verifier. The client authenticates with HTTP Basic
+// (client_secret_basic).
+func (p *Provider) Exchange(ctx context.Context, req TokenRequest) (*TokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {req.Code},
+		"redirect_uri":  {req.RedirectURI},
+		"code_verifier": {req.CodeVerifier},
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+	// RFC 6749 section 2.3.1: credentials are form-encoded before Basic encoding
+	httpReq.SetBasicAuth(url.QueryEscape(req.ClientID), url.QueryEscape(req.ClientSecret))
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var tokenErr tokenError
+		if json.Unmarshal(body, &tokenErr) == nil && tokenErr.Error != "" {
+			return nil, fmt.Errorf("%w: %s: %s", ErrExchange, tokenErr.Error, tokenErr.Description)
+		}
+		return nil, fmt.Errorf("%w: status %d", ErrExchange, resp.StatusCode)
+	}
+
+	var token TokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: response has no id_token", ErrExchange)
+	}
+	return &token, nil
+}
+
+// IDToken holds the validated claims of an ID token.
+type IDToken struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Expiry        time.Time
+}
+
+// idTokenClaims is the wire form of an ID token's claims.
+type idTokenClaims struct {
+	gojwt.RegisteredClaims
+	Nonce           string    `json:"nonce"`
+	AuthorizedParty string    `json:"azp,omitempty"`
+	Email           string    `json:"email,omitempty"`
+	EmailVerified   boolClaim `json:"email_verified,omitempty"`
+	Name            string    `json:"name,omitempty"`
+}
+
+// boolClaim accepts both JSON booleans and the strings "true" and "false",
+// since some providers send email_verified as a string.
+type boolClaim bool
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *boolClaim) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true", `"true"`:
+		*b = true
+	case "false", `"false"`, "null":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean claim %s", data)
+	}
+	return nil
+}
+
+// VerifyIDToken validates an ID token's signature against the provider's
+// JWKS and checks its issuer, audience, expiry and nonce (OpenID Connect
+// Core section 3.1.3.7).
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, clientID, nonce string) (*IDToken, error) {
+	var claims idTokenClaims
+	_, err := gojwt.ParseWithClaims(rawIDToken, &claims, func(token *gojwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		gojwt.WithValidMethods(idTokenAlgorithms),
+		gojwt.WithIssuer(p.Issuer),
+		gojwt.WithAudience(clientID),
+		gojwt.WithExpirationRequired(),
+		gojwt.WithIssuedAt(),
+		gojwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIDTokenInvalid, err)
+	}
+
+	// A token issued to several audiences must name us as the party it was issued to
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != clientID {
+		return nil, fmt.Errorf("%w: azp does not match the client", ErrIDTokenInvalid)
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrIDTokenInvalid)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrIDTokenInvalid)
+	}
+
+	return &IDToken{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Expiry:        claims.ExpiresAt.Time,
+	}, nil
+}
+
+// key returns the provider's signing key with the given id, fetching the
+// JWKS when the key isn't cached yet (e.g. after the provider rotated).
+// An empty kid matches when the provider publishes a single key.
+func (p *Provider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if !p.keysFetched.IsZero() && time.Since(p.keysFetched) < keyRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set jwt.JWKS
+	if err := getJSON(ctx, p.client, p.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Skip keys we can't use rather than failing the whole set
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	p.keys = keys
+	p.keysFetched = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds a cached key. Callers must hold p.mu.
+func (p *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// NewCodeVerifier returns a random PKCE code verifier.
+func NewCodeVerifier() (string, error) {
+	return randomString()
+}
+
+// NewNonce returns a random nonce binding an ID token to one authorization request.
+func NewNonce() (string, error) {
+	return randomString()
+}
+
+// CodeChallengeS256 derives the S256 PKCE challenge for a code verifier.
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// randomString returns 32 random bytes, base64url-encoded (43 characters).
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// getJSON fetches url and decodes its JSON body into v.
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v)
+}
+
+// isHTTPS reports whether raw is an absolute https URL.
+func isHTTPS(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
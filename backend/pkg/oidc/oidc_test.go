@@ -0,0 +1,288 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/solobueno/erp/pkg/oidc/oidctest"
+)
+
+const (
+	testClientID     = "erp-client"
+	testClientSecret = "s3cret/with+chars"
+	testRedirectURI  = "https://app.example.com/sso/callback"
+)
+
+func newTestProvider(t *testing.T) (*oidctest.Server, *Provider) {
+	t.Helper()
+
+	idp := oidctest.NewServer(testClientID, testClientSecret)
+	t.Cleanup(idp.Close)
+
+	provider, err := Discover(context.Background(), idp.Client(), idp.Issuer())
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	return idp, provider
+}
+
+// signIn runs the authorization request against the stub IdP and returns
+// the code and state it redirected back with.
+func signIn(t *testing.T, idp *oidctest.Server, provider *Provider, state, nonce, verifier string) (string, string) {
+	t.Helper()
+
+	authURL := provider.AuthCodeURL(AuthRequest{
+		ClientID:      testClientID,
+		RedirectURI:   testRedirectURI,
+		State:         state,
+		Nonce:         nonce,
+		CodeChallenge: CodeChallengeS256(verifier),
+	})
+	callback, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+func TestDiscover(t *testing.T) {
+	idp, provider := newTestProvider(t)
+
+	if provider.Issuer != idp.Issuer() || provider.TokenEndpoint != idp.URL+"/token" || provider.JWKSURI != idp.URL+"/jwks" {
+		t.Errorf("unexpected provider: %+v", provider)
+	}
+
+	// The discovery document must name exactly the issuer it was fetched for
+	if _, err := Discover(context.Background(), idp.Client(), idp.Issuer()+"/"); !errors.Is(err, ErrDiscovery) {
+		t.Errorf("issuer mismatch: expected ErrDiscovery, got %v", err)
+	}
+	if _, err := Discover(context.Background(), idp.Client(), "http://idp.example.com"); !errors.Is(err, ErrDiscovery) {
+		t.Errorf("http issuer: expected ErrDiscovery, got %v", err)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	provider := &Provider{AuthorizationEndpoint: "https://idp.example.com/authorize?tenant=abc"}
+
+	authURL, err := url.Parse(provider.AuthCodeURL(AuthRequest{
+		ClientID:      testClientID,
+		RedirectURI:   testRedirectURI,
+		State:         "state-1",
+		Nonce:         "nonce-1",
+		CodeChallenge: CodeChallengeS256("verifier"),
+	}))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() is not a URL: %v", err)
+	}
+
+	q := authURL.Query()
+	want := map[string]string{
+		"tenant":                "abc",
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURI,
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        CodeChallengeS256("verifier"),
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if q.Get(key) != value {
+			t.Errorf("%s = %q, want %q", key, q.Get(key), value)
+		}
+	}
+}
+
+func TestCodeChallengeS256(t *testing.T) {
+	// RFC 7636 appendix B
+	got := CodeChallengeS256("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if got != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Errorf("CodeChallengeS256() = %q", got)
+	}
+
+	v1, _ := NewCodeVerifier()
+	v2, _ := NewCodeVerifier()
+	if len(v1) != 43 || v1 == v2 {
+		t.Errorf("NewCodeVerifier() should return distinct 43-character verifiers, got %q and %q", v1, v2)
+	}
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp, provider := newTestProvider(t)
+	idp.SetIdentity(oidctest.Identity{Subject: "idp-user-1", Email: "ana@example.com", EmailVerified: true, Name: "Ana"})
+
+	verifier, _ := NewCodeVerifier()
+	nonce, _ := NewNonce()
+	code, state := signIn(t, idp, provider, "state-1", nonce, verifier)
+	if state != "state-1" || code == "" {
+		t.Fatalf("unexpected callback: code=%q state=%q", code, state)
+	}
+
+	token, err := provider.Exchange(context.Background(), TokenRequest{
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURI:  testRedirectURI,
+		Code:         code,
+		CodeVerifier: verifier,
+	})
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	idToken, err := provider.VerifyIDToken(context.Background(), token.IDToken, testClientID, nonce)
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if idToken.Subject != "idp-user-1" || idToken.Email != "ana@example.com" || !idToken.EmailVerified || idToken.Name != "Ana" || idToken.Issuer != idp.Issuer() {
+		t.Errorf("unexpected ID token: %+v", idToken)
+	}
+}
+
+func TestExchange_Errors(t *testing.T) {
+	idp, provider := newTestProvider(t)
+	idp.SetIdentity(oidctest.Identity{Subject: "idp-user-1"})
+
+	verifier, _ := NewCodeVerifier()
+	otherVerifier, _ := NewCodeVerifier()
+
+	tests := []struct {
+		name   string
+		modify func(req *TokenRequest)
+	}{
+		{"wrong verifier", func(req *TokenRequest) { req.CodeVerifier = otherVerifier }},
+		{"wrong secret", func(req *TokenRequest) { req.ClientSecret = "wrong" }},
+		{"wrong redirect", func(req *TokenRequest) { req.RedirectURI = "https://evil.example.com/cb" }},
+		{"unknown code", func(req *TokenRequest) { req.Code = "not-a-code" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _ := signIn(t, idp, provider, "state", "nonce", verifier)
+			req := TokenRequest{
+				ClientID:     testClientID,
+				ClientSecret: testClientSecret,
+				RedirectURI:  testRedirectURI,
+				Code:         code,
+				CodeVerifier: verifier,
+			}
+			tt.modify(&req)
+			if _, err := provider.Exchange(context.Background(), req); !errors.Is(err, ErrExchange) {
+				t.Errorf("expected ErrExchange, got %v", err)
+			}
+		})
+	}
+
+	// Codes are single-use
+	code, _ := signIn(t, idp, provider, "state", "nonce", verifier)
+	req := TokenRequest{ClientID: testClientID, ClientSecret: testClientSecret, RedirectURI: testRedirectURI, Code: code, CodeVerifier: verifier}
+	if _, err := provider.Exchange(context.Background(), req); err != nil {
+		t.Fatalf("first Exchange() error = %v", err)
+	}
+	if _, err := provider.Exchange(context.Background(), req); !errors.Is(err, ErrExchange) {
+		t.Errorf("code replay: expected ErrExchange, got %v", err)
+	}
+}
+
+func TestVerifyIDToken_Rejects(t *testing.T) {
+	idp, provider := newTestProvider(t)
+	now := time.Now()
+
+	valid := func() gojwt.MapClaims {
+		return gojwt.MapClaims{
+			"iss":   idp.Issuer(),
+			"sub":   "idp-user-1",
+			"aud":   testClientID,
+			"iat":   now.Unix(),
+			"exp":   now.Add(5 * time.Minute).Unix(),
+			"nonce": "nonce-1",
+		}
+	}
+	if _, err := provider.VerifyIDToken(context.Background(), idp.SignIDToken(valid()), testClientID, "nonce-1"); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c gojwt.MapClaims)
+	}{
+		{"wrong issuer", func(c gojwt.MapClaims) { c["iss"] = "https://other.example.com" }},
+		{"wrong audience", func(c gojwt.MapClaims) { c["aud"] = "other-client" }},
+		{"expired", func(c gojwt.MapClaims) { c["exp"] = now.Add(-time.Hour).Unix() }},
+		{"no expiry", func(c gojwt.MapClaims) { delete(c, "exp") }},
+		{"issued in the future", func(c gojwt.MapClaims) { c["iat"] = now.Add(time.Hour).Unix() }},
+		{"nonce mismatch", func(c gojwt.MapClaims) { c["nonce"] = "nonce-2" }},
+		{"no nonce", func(c gojwt.MapClaims) { delete(c, "nonce") }},
+		{"no subject", func(c gojwt.MapClaims) { delete(c, "sub") }},
+		{"several audiences without azp", func(c gojwt.MapClaims) { c["aud"] = []string{testClientID, "other-client"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.modify(claims)
+			_, err := provider.VerifyIDToken(context.Background(), idp.SignIDToken(claims), testClientID, "nonce-1")
+			if !errors.Is(err, ErrIDTokenInvalid) {
+				t.Errorf("expected ErrIDTokenInvalid, got %v", err)
+			}
+		})
+	}
+
+	t.Run("tampered signature", func(t *testing.T) {
+		token := idp.SignIDToken(valid())
+		_, err := provider.VerifyIDToken(context.Background(), token[:len(token)-4]+"AAAA", testClientID, "nonce-1")
+		if !errors.Is(err, ErrIDTokenInvalid) {
+			t.Errorf("expected ErrIDTokenInvalid, got %v", err)
+		}
+	})
+}
+
+func TestVerifyIDToken_EmailVerifiedString(t *testing.T) {
+	idp, provider := newTestProvider(t)
+
+	idToken, err := provider.VerifyIDToken(context.Background(), idp.SignIDToken(gojwt.MapClaims{
+		"iss":            idp.Issuer(),
+		"sub":            "idp-user-1",
+		"aud":            testClientID,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"nonce":          "nonce-1",
+		"email_verified": "true",
+	}), testClientID, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if !idToken.EmailVerified {
+		t.Error("email_verified \"true\" should be accepted")
+	}
+}
+
+func TestVerifyIDToken_KeyRotation(t *testing.T) {
+	idp, provider := newTestProvider(t)
+	claims := gojwt.MapClaims{
+		"iss":   idp.Issuer(),
+		"sub":   "idp-user-1",
+		"aud":   testClientID,
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": "nonce-1",
+	}
+	if _, err := provider.VerifyIDToken(context.Background(), idp.SignIDToken(claims), testClientID, "nonce-1"); err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+
+	// Right after a fetch, an unknown kid doesn't trigger another one
+	idp.RotateKey()
+	rotated := idp.SignIDToken(claims)
+	if _, err := provider.VerifyIDToken(context.Background(), rotated, testClientID, "nonce-1"); !errors.Is(err, ErrIDTokenInvalid) {
+		t.Errorf("expected ErrIDTokenInvalid within the refresh interval, got %v", err)
+	}
+
+	// Once the interval has passed, the new key is fetched
+	old := keyRefreshInterval
+	keyRefreshInterval = 0
+	t.Cleanup(func() { keyRefreshInterval = old })
+	if _, err := provider.VerifyIDToken(context.Background(), rotated, testClientID, "nonce-1"); err != nil {
+		t.Errorf("VerifyIDToken() after rotation error = %v", err)
+	}
+}
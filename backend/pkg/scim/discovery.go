@@ -0,0 +1,90 @@
+package scim
+
+// Supported marks whether an optional feature is supported.
+type Supported struct {
+	Supported bool `json:"supported"`
+}
+
+// FilterSupport describes filter support and the most results a query
+// returns.
+type FilterSupport struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+// BulkSupport describes bulk operation support.
+type BulkSupport struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+// AuthenticationScheme describes how clients authenticate.
+type AuthenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ServiceProviderConfig describes the features a service provider
+// supports (RFC 7643 section 5).
+type ServiceProviderConfig struct {
+	Schemas               []string               `json:"schemas"`
+	Patch                 Supported              `json:"patch"`
+	Bulk                  BulkSupport            `json:"bulk"`
+	Filter                FilterSupport          `json:"filter"`
+	ChangePassword        Supported              `json:"changePassword"`
+	Sort                  Supported              `json:"sort"`
+	ETag                  Supported              `json:"etag"`
+	AuthenticationSchemes []AuthenticationScheme `json:"authenticationSchemes"`
+	Meta                  *Meta                  `json:"meta,omitempty"`
+}
+
+// NewServiceProviderConfig returns the configuration of a provider
+// supporting what this package implements: PATCH and filtering, with
+// bearer token authentication.
+func NewServiceProviderConfig(maxResults int) *ServiceProviderConfig {
+	return &ServiceProviderConfig{
+		Schemas: []string{ServiceProviderConfigSchema},
+		Patch:   Supported{Supported: true},
+		Filter:  FilterSupport{Supported: true, MaxResults: maxResults},
+		AuthenticationSchemes: []AuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "OAuth Bearer Token",
+			Description: "Authentication with a bearer token in the Authorization header",
+		}},
+		Meta: &Meta{ResourceType: "ServiceProviderConfig"},
+	}
+}
+
+// ResourceType describes a resource endpoint (RFC 7643 section 6).
+type ResourceType struct {
+	Schemas  []string `json:"schemas"`
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Endpoint string   `json:"endpoint"`
+	Schema   string   `json:"schema"`
+	Meta     *Meta    `json:"meta,omitempty"`
+}
+
+// ResourceTypes returns the User and Group resource types.
+func ResourceTypes() []ResourceType {
+	return []ResourceType{
+		{
+			Schemas:  []string{ResourceTypeSchema},
+			ID:       "User",
+			Name:     "User",
+			Endpoint: "/Users",
+			Schema:   UserSchema,
+			Meta:     &Meta{ResourceType: "ResourceType"},
+		},
+		{
+			Schemas:  []string{ResourceTypeSchema},
+			ID:       "Group",
+			Name:     "Group",
+			Endpoint: "/Groups",
+			Schema:   GroupSchema,
+			Meta:     &Meta{ResourceType: "ResourceType"},
+		},
+	}
+}
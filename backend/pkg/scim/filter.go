@@ -0,0 +1,443 @@
+package scim
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Filter is a parsed filter expression (RFC 7644 section 3.4.2.2).
+type Filter interface {
+	// Matches reports whether a resource, in its generic JSON form,
+	// satisfies the filter.
+	Matches(resource map[string]interface{}) bool
+}
+
+// ParseFilter parses a filter expression such as
+// `userName eq "ana@example.com" and active eq true`. It supports the
+// comparison operators eq, ne, co, sw, ew, gt, ge, lt, le and pr, the
+// logical operators and, or and not, grouping with parentheses and value
+// paths like `emails[type eq "work" and value co "@example.com"]`. String
+// comparisons ignore case. Errors are invalidFilter Errors.
+func ParseFilter(s string) (Filter, error) {
+	toks, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{toks: toks}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, BadRequest(ErrorInvalidFilter, "unexpected %q", p.toks[p.pos].text)
+	}
+	return f, nil
+}
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokString
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			toks = append(toks, token{tokLParen, "("})
+			i++
+		case c == ')':
+			toks = append(toks, token{tokRParen, ")"})
+			i++
+		case c == '[':
+			toks = append(toks, token{tokLBracket, "["})
+			i++
+		case c == ']':
+			toks = append(toks, token{tokRBracket, "]"})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, BadRequest(ErrorInvalidFilter, "unterminated string")
+			}
+			var str string
+			if err := json.Unmarshal([]byte(s[i:end+1]), &str); err != nil {
+				return nil, BadRequest(ErrorInvalidFilter, "invalid string %s", s[i:end+1])
+			}
+			toks = append(toks, token{tokString, str})
+			i = end + 1
+		case isWordChar(c):
+			end := i
+			for end < len(s) && isWordChar(s[end]) {
+				end++
+			}
+			toks = append(toks, token{tokWord, s[i:end]})
+			i = end
+		default:
+			return nil, BadRequest(ErrorInvalidFilter, "unexpected character %q", c)
+		}
+	}
+	return toks, nil
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == ':' || c == '.' || c == '$' || c == '-' || c == '+'
+}
+
+type filterParser struct {
+	toks []token
+	pos  int
+}
+
+func (p *filterParser) peekWord(word string) bool {
+	return p.pos < len(p.toks) && p.toks[p.pos].kind == tokWord && strings.EqualFold(p.toks[p.pos].text, word)
+}
+
+func (p *filterParser) expect(kind tokenKind, text string) error {
+	if p.pos >= len(p.toks) || p.toks[p.pos].kind != kind {
+		return BadRequest(ErrorInvalidFilter, "expected %q", text)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekWord("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekWord("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	if p.peekWord("not") {
+		p.pos++
+		if err := p.expect(tokLParen, "("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return notFilter{f}, nil
+	}
+	if p.pos < len(p.toks) && p.toks[p.pos].kind == tokLParen {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+
+	if p.pos >= len(p.toks) || p.toks[p.pos].kind != tokWord {
+		return nil, BadRequest(ErrorInvalidFilter, "expected an attribute")
+	}
+	path, err := parseAttrPath(p.toks[p.pos].text)
+	if err != nil {
+		return nil, err
+	}
+	p.pos++
+
+	if p.pos < len(p.toks) && p.toks[p.pos].kind == tokLBracket {
+		if len(path) != 1 {
+			return nil, BadRequest(ErrorInvalidFilter, "value filters apply to top-level attributes")
+		}
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokRBracket, "]"); err != nil {
+			return nil, err
+		}
+		return valuePathFilter{attr: path[0], filter: inner}, nil
+	}
+
+	if p.pos >= len(p.toks) || p.toks[p.pos].kind != tokWord {
+		return nil, BadRequest(ErrorInvalidFilter, "expected an operator after %s", strings.Join(path, "."))
+	}
+	op := strings.ToLower(p.toks[p.pos].text)
+	p.pos++
+	switch op {
+	case "pr":
+		return presentFilter{path}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, BadRequest(ErrorInvalidFilter, "unknown operator %q", op)
+	}
+
+	if p.pos >= len(p.toks) {
+		return nil, BadRequest(ErrorInvalidFilter, "expected a value after %s", op)
+	}
+	tok := p.toks[p.pos]
+	p.pos++
+	var value interface{}
+	switch {
+	case tok.kind == tokString:
+		value = tok.text
+	case tok.kind == tokWord && strings.EqualFold(tok.text, "true"):
+		value = true
+	case tok.kind == tokWord && strings.EqualFold(tok.text, "false"):
+		value = false
+	case tok.kind == tokWord && strings.EqualFold(tok.text, "null"):
+		value = nil
+	case tok.kind == tokWord:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, BadRequest(ErrorInvalidFilter, "invalid value %q", tok.text)
+		}
+		value = n
+	default:
+		return nil, BadRequest(ErrorInvalidFilter, "expected a value after %s", op)
+	}
+	return compareFilter{path: path, op: op, value: value}, nil
+}
+
+// parseAttrPath splits an attribute path into the attribute and an
+// optional sub-attribute, dropping any schema URN prefix.
+func parseAttrPath(s string) ([]string, error) {
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		s = s[i+1:]
+	}
+	path := strings.Split(s, ".")
+	if len(path) > 2 || slices.Contains(path, "") {
+		return nil, BadRequest(ErrorInvalidPath, "invalid attribute path %q", s)
+	}
+	return path, nil
+}
+
+type andFilter struct{ left, right Filter }
+
+func (f andFilter) Matches(r map[string]interface{}) bool {
+	return f.left.Matches(r) && f.right.Matches(r)
+}
+
+type orFilter struct{ left, right Filter }
+
+func (f orFilter) Matches(r map[string]interface{}) bool {
+	return f.left.Matches(r) || f.right.Matches(r)
+}
+
+type notFilter struct{ inner Filter }
+
+func (f notFilter) Matches(r map[string]interface{}) bool {
+	return !f.inner.Matches(r)
+}
+
+// valuePathFilter matches resources with an entry of a multi-valued
+// attribute that matches the inner filter.
+type valuePathFilter struct {
+	attr   string
+	filter Filter
+}
+
+func (f valuePathFilter) Matches(r map[string]interface{}) bool {
+	v, _ := lookup(r, f.attr)
+	for _, elem := range asSlice(v) {
+		if m, ok := elem.(map[string]interface{}); ok && f.filter.Matches(m) {
+			return true
+		}
+	}
+	return false
+}
+
+type presentFilter struct{ path []string }
+
+func (f presentFilter) Matches(r map[string]interface{}) bool {
+	for _, v := range collect(r, f.path) {
+		if v != nil && v != "" {
+			return true
+		}
+	}
+	return false
+}
+
+type compareFilter struct {
+	path  []string
+	op    string
+	value interface{}
+}
+
+func (f compareFilter) Matches(r map[string]interface{}) bool {
+	values := collect(r, f.path)
+	if f.op == "ne" {
+		return !compareFilter{path: f.path, op: "eq", value: f.value}.Matches(r)
+	}
+	if f.value == nil {
+		return f.op == "eq" && len(values) == 0
+	}
+	for _, v := range values {
+		if compare(f.op, v, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// collect returns the values at path: for a multi-valued attribute, those
+// of every entry (their "value" sub-attribute when none is named).
+func collect(r map[string]interface{}, path []string) []interface{} {
+	v, ok := lookup(r, path[0])
+	if !ok || v == nil {
+		return nil
+	}
+	var out []interface{}
+	for _, elem := range asSlice(v) {
+		m, isMap := elem.(map[string]interface{})
+		switch {
+		case len(path) == 2 && isMap:
+			if sub, ok := lookup(m, path[1]); ok && sub != nil {
+				out = append(out, sub)
+			}
+		case len(path) == 1 && isMap:
+			if sub, ok := lookup(m, "value"); ok && sub != nil {
+				out = append(out, sub)
+			}
+		case len(path) == 1:
+			out = append(out, elem)
+		}
+	}
+	return out
+}
+
+func compare(op string, actual, expected interface{}) bool {
+	switch e := expected.(type) {
+	case string:
+		a, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		a, e = strings.ToLower(a), strings.ToLower(e)
+		switch op {
+		case "eq":
+			return a == e
+		case "co":
+			return strings.Contains(a, e)
+		case "sw":
+			return strings.HasPrefix(a, e)
+		case "ew":
+			return strings.HasSuffix(a, e)
+		case "gt":
+			return a > e
+		case "ge":
+			return a >= e
+		case "lt":
+			return a < e
+		case "le":
+			return a <= e
+		}
+	case bool:
+		a, ok := toBool(actual)
+		return ok && op == "eq" && a == e
+	case float64:
+		a, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return a == e
+		case "gt":
+			return a > e
+		case "ge":
+			return a >= e
+		case "lt":
+			return a < e
+		case "le":
+			return a <= e
+		}
+	}
+	return false
+}
+
+// lookup returns a map entry by case-insensitive key, as attribute names
+// are case-insensitive.
+func lookup(m map[string]interface{}, key string) (interface{}, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// lookupKey returns the key an attribute is stored under, or key itself if
+// it isn't set.
+func lookupKey(m map[string]interface{}, key string) string {
+	if _, ok := m[key]; ok {
+		return key
+	}
+	for k := range m {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+	}
+	return key
+}
+
+func asSlice(v interface{}) []interface{} {
+	if s, ok := v.([]interface{}); ok {
+		return s
+	}
+	if v == nil {
+		return nil
+	}
+	return []interface{}{v}
+}
@@ -0,0 +1,98 @@
+package scim
+
+import (
+	"errors"
+	"testing"
+)
+
+func testUser() map[string]interface{} {
+	return map[string]interface{}{
+		"schemas":    []interface{}{UserSchema},
+		"id":         "5b0c8a1e",
+		"externalId": "HR-1042",
+		"userName":   "Ana.Lopez@example.com",
+		"name":       map[string]interface{}{"givenName": "Ana", "familyName": "López"},
+		"active":     true,
+		"emails": []interface{}{
+			map[string]interface{}{"value": "ana.lopez@example.com", "type": "work", "primary": true},
+			map[string]interface{}{"value": "ana@home.example", "type": "home"},
+		},
+		"meta": map[string]interface{}{"resourceType": "User", "created": "2026-03-01T10:00:00Z"},
+	}
+}
+
+func TestParseFilter_Matches(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{`userName eq "ana.lopez@example.com"`, true},
+		{`USERNAME Eq "ANA.LOPEZ@EXAMPLE.COM"`, true},
+		{`userName eq "luis@example.com"`, false},
+		{`userName ne "luis@example.com"`, true},
+		{`userName co "lopez"`, true},
+		{`userName sw "ana."`, true},
+		{`userName ew "@example.com"`, true},
+		{`externalId eq "HR-1042"`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "ana.lopez@example.com"`, true},
+		{`name.familyName eq "lópez"`, true},
+		{`name.givenName pr`, true},
+		{`displayName pr`, false},
+		{`displayName eq null`, true},
+		{`active eq true`, true},
+		{`active eq false`, false},
+		{`emails.value eq "ana@home.example"`, true},
+		{`emails eq "ana@home.example"`, true},
+		{`emails[type eq "work" and value co "lopez"]`, true},
+		{`emails[type eq "work" and value co "home"]`, false},
+		{`meta.created gt "2026-01-01T00:00:00Z"`, true},
+		{`meta.created lt "2026-01-01T00:00:00Z"`, false},
+		{`userName eq "luis@example.com" or externalId eq "HR-1042"`, true},
+		{`userName eq "luis@example.com" or externalId eq "HR-1042" and active eq false`, false},
+		{`(userName eq "luis@example.com" or externalId eq "HR-1042") and active eq true`, true},
+		{`not (active eq true)`, false},
+		{`not(userName sw "luis")`, true},
+		{`userName eq "say \"hi\""`, false},
+	}
+
+	user := testUser()
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+			if got := f.Matches(user); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName like "ana"`,
+		`userName eq ana`,
+		`userName eq "ana`,
+		`(userName eq "ana"`,
+		`userName eq "ana" and`,
+		`userName eq "ana" extra`,
+		`not userName eq "ana"`,
+		`emails[type eq "work"`,
+		`name.givenName[value eq "x"]`,
+		`userName eq "ana" | active eq true`,
+	}
+
+	for _, filter := range tests {
+		t.Run(filter, func(t *testing.T) {
+			_, err := ParseFilter(filter)
+			var scimErr *Error
+			if !errors.As(err, &scimErr) || scimErr.Status != 400 {
+				t.Fatalf("ParseFilter() error = %v, want a 400 Error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package scim
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ApplyPatch applies PATCH operations (RFC 7644 section 3.5.2) to a
+// resource in its generic JSON form. Operation names are case-insensitive
+// and paths may carry a schema URN prefix, a sub-attribute
+// ("name.givenName") and a value filter ("members[value eq \"...\"]").
+// An add or replace without a path applies each attribute of its value.
+//
+// A remove whose filter matches nothing is a no-op. A remove of a
+// multi-valued attribute with a value removes just the listed entries, as
+// some identity providers send group membership removals that way.
+func ApplyPatch(resource map[string]interface{}, ops []PatchOperation) error {
+	for _, op := range ops {
+		if err := applyOperation(resource, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyOperation(resource map[string]interface{}, op PatchOperation) error {
+	kind := strings.ToLower(op.Op)
+	if kind != "add" && kind != "replace" && kind != "remove" {
+		return BadRequest(ErrorInvalidSyntax, "unknown operation %q", op.Op)
+	}
+
+	hasValue := len(op.Value) > 0 && string(op.Value) != "null"
+	var value interface{}
+	if hasValue {
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return BadRequest(ErrorInvalidSyntax, "invalid value: %v", err)
+		}
+	}
+
+	if op.Path == "" {
+		if kind == "remove" {
+			return BadRequest(ErrorNoTarget, "remove requires a path")
+		}
+		attrs, ok := value.(map[string]interface{})
+		if !ok {
+			return BadRequest(ErrorInvalidValue, "%s without a path needs an object value", kind)
+		}
+		keys := make([]string, 0, len(attrs))
+		for k := range attrs {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			if err := applyPath(resource, kind, k, attrs[k], true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if kind != "remove" && !hasValue {
+		return BadRequest(ErrorInvalidValue, "%s of %q needs a value", kind, op.Path)
+	}
+	return applyPath(resource, kind, op.Path, value, hasValue)
+}
+
+// patchPath is a parsed PATCH path: attr[filter].sub.
+type patchPath struct {
+	attr   string
+	sub    string
+	filter Filter
+}
+
+func parsePatchPath(s string) (*patchPath, error) {
+	head, rest := s, ""
+	var filter Filter
+	if open := strings.Index(s, "["); open >= 0 {
+		closing := strings.LastIndex(s, "]")
+		if closing < open {
+			return nil, BadRequest(ErrorInvalidPath, "invalid path %q", s)
+		}
+		f, err := ParseFilter(s[open+1 : closing])
+		if err != nil {
+			return nil, BadRequest(ErrorInvalidPath, "invalid filter in path %q", s)
+		}
+		head, rest, filter = s[:open], s[closing+1:], f
+		if rest != "" && (!strings.HasPrefix(rest, ".") || strings.Contains(rest[1:], ".")) {
+			return nil, BadRequest(ErrorInvalidPath, "invalid path %q", s)
+		}
+		rest = strings.TrimPrefix(rest, ".")
+	}
+
+	attrPath, err := parseAttrPath(head)
+	if err != nil {
+		return nil, err
+	}
+	p := &patchPath{attr: attrPath[0], filter: filter, sub: rest}
+	if len(attrPath) == 2 {
+		if filter != nil {
+			return nil, BadRequest(ErrorInvalidPath, "invalid path %q", s)
+		}
+		p.sub = attrPath[1]
+	}
+	return p, nil
+}
+
+func applyPath(resource map[string]interface{}, kind, path string, value interface{}, hasValue bool) error {
+	p, err := parsePatchPath(path)
+	if err != nil {
+		return err
+	}
+	key := lookupKey(resource, p.attr)
+	current, exists := resource[key]
+
+	switch {
+	case p.filter != nil:
+		entries, ok := current.([]interface{})
+		if exists && current != nil && !ok {
+			return BadRequest(ErrorInvalidPath, "%s is not multi-valued", p.attr)
+		}
+		kept := make([]interface{}, 0, len(entries))
+		matched := false
+		for _, entry := range entries {
+			m, isMap := entry.(map[string]interface{})
+			if !isMap || !p.filter.Matches(m) {
+				kept = append(kept, entry)
+				continue
+			}
+			matched = true
+			switch {
+			case kind == "remove" && p.sub == "":
+				continue
+			case kind == "remove":
+				delete(m, lookupKey(m, p.sub))
+			case p.sub != "":
+				m[lookupKey(m, p.sub)] = value
+			default:
+				replacement, ok := value.(map[string]interface{})
+				if !ok {
+					return BadRequest(ErrorInvalidValue, "%s entries are objects", p.attr)
+				}
+				if kind == "add" {
+					merge(m, replacement)
+				} else {
+					entry = replacement
+				}
+			}
+			kept = append(kept, entry)
+		}
+		if !matched {
+			if kind == "remove" {
+				return nil
+			}
+			return BadRequest(ErrorNoTarget, "no values match %q", path)
+		}
+		resource[key] = kept
+
+	case p.sub != "":
+		parent, isMap := current.(map[string]interface{})
+		if exists && current != nil && !isMap {
+			return BadRequest(ErrorInvalidPath, "%s has no sub-attributes", p.attr)
+		}
+		if kind == "remove" {
+			if isMap {
+				delete(parent, lookupKey(parent, p.sub))
+			}
+			return nil
+		}
+		if !isMap {
+			parent = map[string]interface{}{}
+			resource[key] = parent
+		}
+		parent[lookupKey(parent, p.sub)] = value
+
+	case kind == "remove":
+		entries, isSlice := current.([]interface{})
+		if !hasValue || !isSlice {
+			delete(resource, key)
+			return nil
+		}
+		remaining := make([]interface{}, 0, len(entries))
+		for _, entry := range entries {
+			if !containsEntry(asSlice(value), entry) {
+				remaining = append(remaining, entry)
+			}
+		}
+		resource[key] = remaining
+
+	case kind == "add":
+		if entries, ok := current.([]interface{}); ok {
+			for _, v := range asSlice(value) {
+				if !containsEntry(entries, v) {
+					entries = append(entries, v)
+				}
+			}
+			resource[key] = entries
+		} else if m, ok := current.(map[string]interface{}); ok && isObject(value) {
+			merge(m, value.(map[string]interface{}))
+		} else {
+			resource[key] = value
+		}
+
+	default: // replace
+		if m, ok := current.(map[string]interface{}); ok && isObject(value) {
+			merge(m, value.(map[string]interface{}))
+		} else {
+			resource[key] = value
+		}
+	}
+	return nil
+}
+
+// merge sets each attribute of src on dst.
+func merge(dst, src map[string]interface{}) {
+	for k, v := range src {
+		dst[lookupKey(dst, k)] = v
+	}
+}
+
+func isObject(v interface{}) bool {
+	_, ok := v.(map[string]interface{})
+	return ok
+}
+
+// containsEntry reports whether a multi-valued attribute has an entry:
+// complex entries are the same if their values are.
+func containsEntry(entries []interface{}, entry interface{}) bool {
+	for _, e := range entries {
+		if sameEntry(e, entry) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameEntry(a, b interface{}) bool {
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if aok && bok {
+		av, _ := lookup(am, "value")
+		bv, _ := lookup(bm, "value")
+		if av != nil || bv != nil {
+			return reflect.DeepEqual(av, bv)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,216 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func op(kind, path, value string) PatchOperation {
+	o := PatchOperation{Op: kind, Path: path}
+	if value != "" {
+		o.Value = json.RawMessage(value)
+	}
+	return o
+}
+
+func testGroup() map[string]interface{} {
+	return map[string]interface{}{
+		"schemas":     []interface{}{GroupSchema},
+		"id":          "manager",
+		"displayName": "manager",
+		"members": []interface{}{
+			map[string]interface{}{"value": "u1", "display": "Ana"},
+			map[string]interface{}{"value": "u2", "display": "Luis"},
+		},
+	}
+}
+
+func memberValues(t *testing.T, group map[string]interface{}) []string {
+	t.Helper()
+	var g Group
+	if err := FromMap(group, &g); err != nil {
+		t.Fatalf("FromMap() error = %v", err)
+	}
+	values := []string{}
+	for _, m := range g.Members {
+		values = append(values, m.Value)
+	}
+	return values
+}
+
+func TestApplyPatch_User(t *testing.T) {
+	tests := []struct {
+		name  string
+		ops   []PatchOperation
+		check func(t *testing.T, u *User)
+	}{
+		{
+			name: "replace active with a string boolean",
+			ops:  []PatchOperation{op("Replace", "active", `"False"`)},
+			check: func(t *testing.T, u *User) {
+				if u.IsActive() {
+					t.Error("user should be inactive")
+				}
+			},
+		},
+		{
+			name: "replace without a path and dotted keys",
+			ops:  []PatchOperation{op("replace", "", `{"active":false,"name.givenName":"Anita","externalId":"HR-2000"}`)},
+			check: func(t *testing.T, u *User) {
+				if u.IsActive() || u.Name.GivenName != "Anita" || u.Name.FamilyName != "López" || u.ExternalID != "HR-2000" {
+					t.Errorf("user = %+v, name = %+v", u, u.Name)
+				}
+			},
+		},
+		{
+			name: "replace a complex value merges it",
+			ops:  []PatchOperation{op("replace", "name", `{"familyName":"Lopez"}`)},
+			check: func(t *testing.T, u *User) {
+				if u.Name.GivenName != "Ana" || u.Name.FamilyName != "Lopez" {
+					t.Errorf("name = %+v", u.Name)
+				}
+			},
+		},
+		{
+			name: "URN-prefixed sub-attribute",
+			ops:  []PatchOperation{op("add", "urn:ietf:params:scim:schemas:core:2.0:User:name.familyName", `"García"`)},
+			check: func(t *testing.T, u *User) {
+				if u.Name.FamilyName != "García" {
+					t.Errorf("name = %+v", u.Name)
+				}
+			},
+		},
+		{
+			name: "replace a sub-attribute of filtered entries",
+			ops:  []PatchOperation{op("replace", `emails[type eq "work"].value`, `"ana@example.com"`)},
+			check: func(t *testing.T, u *User) {
+				if u.Emails[0].Value != "ana@example.com" || u.Emails[1].Value != "ana@home.example" {
+					t.Errorf("emails = %+v", u.Emails)
+				}
+			},
+		},
+		{
+			name: "remove filtered entries",
+			ops:  []PatchOperation{op("remove", `emails[type eq "home"]`, "")},
+			check: func(t *testing.T, u *User) {
+				if len(u.Emails) != 1 || u.Emails[0].Type != "work" {
+					t.Errorf("emails = %+v", u.Emails)
+				}
+			},
+		},
+		{
+			name: "remove an attribute",
+			ops:  []PatchOperation{op("remove", "externalId", "")},
+			check: func(t *testing.T, u *User) {
+				if u.ExternalID != "" {
+					t.Errorf("externalId = %q", u.ExternalID)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := testUser()
+			if err := ApplyPatch(user, tt.ops); err != nil {
+				t.Fatalf("ApplyPatch() error = %v", err)
+			}
+			var u User
+			if err := FromMap(user, &u); err != nil {
+				t.Fatalf("FromMap() error = %v", err)
+			}
+			tt.check(t, &u)
+		})
+	}
+}
+
+func TestApplyPatch_GroupMembers(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []PatchOperation
+		want []string
+	}{
+		{"add appends new members", []PatchOperation{op("add", "members", `[{"value":"u2"},{"value":"u3"}]`)}, []string{"u1", "u2", "u3"}},
+		{"add to an empty group", []PatchOperation{op("remove", "members", ""), op("add", "members", `[{"value":"u3"}]`)}, []string{"u3"}},
+		{"replace sets the members", []PatchOperation{op("replace", "members", `[{"value":"u3"}]`)}, []string{"u3"}},
+		{"remove by filter", []PatchOperation{op("remove", `members[value eq "u1"]`, "")}, []string{"u2"}},
+		{"remove by value", []PatchOperation{op("Remove", "members", `[{"value":"u2"}]`)}, []string{"u1"}},
+		{"remove of a non-member is a no-op", []PatchOperation{op("remove", `members[value eq "u9"]`, "")}, []string{"u1", "u2"}},
+		{"remove all members", []PatchOperation{op("remove", "members", "")}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := testGroup()
+			if err := ApplyPatch(group, tt.ops); err != nil {
+				t.Fatalf("ApplyPatch() error = %v", err)
+			}
+			if got := memberValues(t, group); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("members = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPatch_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       PatchOperation
+		wantType string
+	}{
+		{"unknown op", op("move", "members", `[]`), ErrorInvalidSyntax},
+		{"remove without a path", op("remove", "", ""), ErrorNoTarget},
+		{"add without a value", op("add", "members", ""), ErrorInvalidValue},
+		{"no path and no object", op("replace", "", `"x"`), ErrorInvalidValue},
+		{"invalid value", op("add", "members", `[{`), ErrorInvalidSyntax},
+		{"invalid path", op("replace", "name..givenName", `"x"`), ErrorInvalidPath},
+		{"invalid filter", op("replace", `members[value eq]`, `"x"`), ErrorInvalidPath},
+		{"filter on a single value", op("replace", `displayName[value eq "x"]`, `"x"`), ErrorInvalidPath},
+		{"no filter match", op("replace", `members[value eq "u9"].display`, `"x"`), ErrorNoTarget},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ApplyPatch(testGroup(), []PatchOperation{tt.op})
+			var scimErr *Error
+			if !errors.As(err, &scimErr) || scimErr.ScimType != tt.wantType {
+				t.Fatalf("ApplyPatch() error = %v, want scimType %q", err, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestError_MarshalJSON(t *testing.T) {
+	b, err := json.Marshal(NewError(409, ErrorUniqueness, "userName is taken"))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"schemas":["urn:ietf:params:scim:api:messages:2.0:Error"],"status":"409","scimType":"uniqueness","detail":"userName is taken"}`
+	if string(b) != want {
+		t.Errorf("Marshal() = %s, want %s", b, want)
+	}
+}
+
+func TestNewListResponse(t *testing.T) {
+	resources := []interface{}{"a", "b", "c"}
+
+	tests := []struct {
+		startIndex, count int
+		want              []interface{}
+	}{
+		{1, 100, []interface{}{"a", "b", "c"}},
+		{2, 1, []interface{}{"b"}},
+		{0, 2, []interface{}{"a", "b"}},
+		{5, 10, []interface{}{}},
+		{1, 0, []interface{}{}},
+	}
+
+	for _, tt := range tests {
+		resp := NewListResponse(resources, tt.startIndex, tt.count)
+		if resp.TotalResults != 3 || !reflect.DeepEqual(resp.Resources, tt.want) || resp.ItemsPerPage != len(tt.want) {
+			t.Errorf("NewListResponse(%d, %d) = %+v, want %v", tt.startIndex, tt.count, resp, tt.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// errCBOR is returned for input the CBOR decoder can't or won't decode.
+var errCBOR = errors.New("webauthn: malformed cbor")
+
+// maxCBORDepth caps how deeply arrays and maps may nest.
+const maxCBORDepth = 16
+
+// CBOR major types (RFC 8949 section 3.1).
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+)
+
+// decodeCBOR decodes the first CBOR data item in data and returns it with
+// the bytes that follow it. It handles the subset authenticators produce:
+// integers (as int64), byte and text strings, arrays, maps keyed by
+// integers or text, tags (which are dropped) and the simple values false,
+// true and null. Indefinite lengths and floats are rejected.
+func decodeCBOR(data []byte) (interface{}, []byte, error) {
+	return decodeCBORItem(data, 0)
+}
+
+func decodeCBORItem(data []byte, depth int) (interface{}, []byte, error) {
+	if depth > maxCBORDepth {
+		return nil, nil, fmt.Errorf("%w: nested too deeply", errCBOR)
+	}
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("%w: unexpected end of input", errCBOR)
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+
+	if major == cborSimple {
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22:
+			return nil, data, nil
+		default:
+			return nil, nil, fmt.Errorf("%w: unsupported simple value %d", errCBOR, info)
+		}
+	}
+
+	arg, data, err := cborArgument(info, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case cborUint:
+		if arg > 1<<63-1 {
+			return nil, nil, fmt.Errorf("%w: integer overflows int64", errCBOR)
+		}
+		return int64(arg), data, nil
+	case cborNegInt:
+		if arg > 1<<63-1 {
+			return nil, nil, fmt.Errorf("%w: integer overflows int64", errCBOR)
+		}
+		return -1 - int64(arg), data, nil
+	case cborBytes, cborText:
+		if arg > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("%w: string longer than input", errCBOR)
+		}
+		b := make([]byte, arg)
+		copy(b, data[:arg])
+		if major == cborText {
+			return string(b), data[arg:], nil
+		}
+		return b, data[arg:], nil
+	case cborArray:
+		// Every item takes at least a byte, so a longer count is malformed
+		if arg > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("%w: array longer than input", errCBOR)
+		}
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			var item interface{}
+			if item, data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, data, nil
+	case cborMap:
+		if arg > uint64(len(data))/2 {
+			return nil, nil, fmt.Errorf("%w: map longer than input", errCBOR)
+		}
+		m := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			var key, value interface{}
+			if key, data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, fmt.Errorf("%w: map keys must be integers or text", errCBOR)
+			}
+			if _, dup := m[key]; dup {
+				return nil, nil, fmt.Errorf("%w: duplicate map key %v", errCBOR, key)
+			}
+			if value, data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			m[key] = value
+		}
+		return m, data, nil
+	default: // cborTag
+		return decodeCBORItem(data, depth+1)
+	}
+}
+
+// cborArgument reads the argument of a data item's initial byte: the
+// value itself, or a length or count.
+func cborArgument(info byte, data []byte) (uint64, []byte, error) {
+	var size int
+	switch {
+	case info < 24:
+		return uint64(info), data, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, nil, fmt.Errorf("%w: indefinite or reserved length", errCBOR)
+	}
+	if len(data) < size {
+		return 0, nil, fmt.Errorf("%w: unexpected end of input", errCBOR)
+	}
+	var arg uint64
+	switch size {
+	case 1:
+		arg = uint64(data[0])
+	case 2:
+		arg = uint64(binary.BigEndian.Uint16(data))
+	case 4:
+		arg = uint64(binary.BigEndian.Uint32(data))
+	default:
+		arg = binary.BigEndian.Uint64(data)
+	}
+	return arg, data[size:], nil
+}
+
+// cborMapOf asserts that v is a decoded CBOR map.
+func cborMapOf(v interface{}) (map[interface{}]interface{}, error) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: expected a map", errCBOR)
+	}
+	return m, nil
+}
@@ -0,0 +1,555 @@
+// Package webauthn is a minimal WebAuthn (FIDO2) relying party: it builds
+// the options for registration and authentication ceremonies and verifies
+// the authenticator's responses. Attestation is not checked against any
+// trust store; credentials are trusted on first use. Storing credentials and
+// challenges is left to the caller.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidResponse is returned when an authenticator response can't be
+	// parsed.
+	ErrInvalidResponse = errors.New("webauthn: malformed authenticator response")
+	// ErrVerification is returned when an authenticator response doesn't
+	// match the ceremony or its signature doesn't verify.
+	ErrVerification = errors.New("webauthn: verification failed")
+	// ErrUnsupportedKey is returned for credential keys of an unsupported
+	// type, algorithm or size.
+	ErrUnsupportedKey = errors.New("webauthn: unsupported credential key")
+	// ErrSignCount is returned when a credential's signature counter didn't
+	// increase, a sign that the authenticator may have been cloned. The
+	// signature itself was valid.
+	ErrSignCount = errors.New("webauthn: signature counter did not increase")
+)
+
+// ChallengeSize is the length in bytes of the challenges from NewChallenge.
+const ChallengeSize = 32
+
+// DefaultTimeout is how long the browser is given to complete a ceremony
+// when Config.Timeout is unset.
+const DefaultTimeout = 5 * time.Minute
+
+// maxCredentialIDLength is the longest credential id accepted (WebAuthn
+// Level 3 section 5.8.3).
+const maxCredentialIDLength = 1023
+
+// Credential type, client data types and option values.
+const (
+	credentialTypePublicKey = "public-key"
+	clientDataTypeCreate    = "webauthn.create"
+	clientDataTypeGet       = "webauthn.get"
+
+	// UserVerificationRequired makes the authenticator verify the user with
+	// a PIN or biometric, so a credential is a factor on its own.
+	UserVerificationRequired = "required"
+	// ResidentKeyPreferred asks for a discoverable credential, which lets
+	// the user sign in without typing a username first.
+	ResidentKeyPreferred = "preferred"
+	// AttestationNone asks for no attestation statement.
+	AttestationNone = "none"
+)
+
+// Authenticator data flags (WebAuthn Level 3 section 6.1).
+const (
+	flagUserPresent       = 0x01
+	flagUserVerified      = 0x04
+	flagBackupEligible    = 0x08
+	flagBackedUp          = 0x10
+	flagAttestedData      = 0x40
+	flagExtensionIncluded = 0x80
+)
+
+// Config identifies the relying party.
+type Config struct {
+	// RPID is the relying party id: the registrable domain credentials are
+	// scoped to, e.g. "erp.example.com".
+	RPID string
+	// RPName is the name shown by the browser during registration.
+	RPName string
+	// Origins are the exact origins ceremonies may run on, e.g.
+	// "https://erp.example.com".
+	Origins []string
+	// Timeout is how long the browser is given to complete a ceremony.
+	Timeout time.Duration
+}
+
+// URLEncodedBase64 is binary data carried as unpadded base64url in JSON, as
+// in the JSON forms of the WebAuthn options and responses.
+type URLEncodedBase64 []byte
+
+// MarshalJSON encodes b as an unpadded base64url string.
+func (b URLEncodedBase64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON decodes a base64url string, padded or not.
+func (b *URLEncodedBase64) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// RelyingParty describes the relying party to the authenticator.
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// User describes the account a credential is registered for. ID is the
+// opaque user handle returned with discoverable credentials.
+type User struct {
+	ID          URLEncodedBase64 `json:"id"`
+	Name        string           `json:"name"`
+	DisplayName string           `json:"displayName"`
+}
+
+// CredentialParameter names a credential key algorithm the relying party accepts.
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// CredentialDescriptor identifies an existing credential.
+type CredentialDescriptor struct {
+	Type       string           `json:"type"`
+	ID         URLEncodedBase64 `json:"id"`
+	Transports []string         `json:"transports,omitempty"`
+}
+
+// NewCredentialDescriptor describes the credential with the given id.
+func NewCredentialDescriptor(id []byte, transports []string) CredentialDescriptor {
+	return CredentialDescriptor{Type: credentialTypePublicKey, ID: id, Transports: transports}
+}
+
+// AuthenticatorSelection states what the relying party requires of the
+// authenticator.
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions are the options for navigator.credentials.create(), in
+// the JSON form of PublicKeyCredentialCreationOptions.
+type CreationOptions struct {
+	RP                     RelyingParty           `json:"rp"`
+	User                   User                   `json:"user"`
+	Challenge              URLEncodedBase64       `json:"challenge"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"` // Milliseconds
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are the options for navigator.credentials.get(), in the
+// JSON form of PublicKeyCredentialRequestOptions.
+type RequestOptions struct {
+	Challenge        URLEncodedBase64       `json:"challenge"`
+	Timeout          int64                  `json:"timeout"` // Milliseconds
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// NewChallenge returns a random ceremony challenge.
+func NewChallenge() ([]byte, error) {
+	b := make([]byte, ChallengeSize)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// CreationOptions returns the options registering a credential for user.
+// exclude lists the user's existing credentials, so an authenticator isn't
+// registered twice. User verification is required and a discoverable
+// credential is preferred.
+func (c Config) CreationOptions(user User, challenge []byte, exclude []CredentialDescriptor) *CreationOptions {
+	params := make([]CredentialParameter, 0, len(SupportedAlgorithms))
+	for _, alg := range SupportedAlgorithms {
+		params = append(params, CredentialParameter{Type: credentialTypePublicKey, Alg: alg})
+	}
+	return &CreationOptions{
+		RP:                 RelyingParty{ID: c.RPID, Name: c.RPName},
+		User:               user,
+		Challenge:          challenge,
+		PubKeyCredParams:   params,
+		Timeout:            c.timeout().Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      ResidentKeyPreferred,
+			UserVerification: UserVerificationRequired,
+		},
+		Attestation: AttestationNone,
+	}
+}
+
+// RequestOptions returns the options asserting a credential. With no allow
+// list the browser offers the user's discoverable credentials.
+func (c Config) RequestOptions(challenge []byte, allow []CredentialDescriptor) *RequestOptions {
+	return &RequestOptions{
+		Challenge:        challenge,
+		Timeout:          c.timeout().Milliseconds(),
+		RPID:             c.RPID,
+		AllowCredentials: allow,
+		UserVerification: UserVerificationRequired,
+	}
+}
+
+func (c Config) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+// AttestationResponse is the response part of a registration.
+type AttestationResponse struct {
+	ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+	AttestationObject URLEncodedBase64 `json:"attestationObject"`
+	Transports        []string         `json:"transports,omitempty"`
+}
+
+// RegistrationResponse is the credential returned by
+// navigator.credentials.create(), in its toJSON() form.
+type RegistrationResponse struct {
+	ID       string              `json:"id"`
+	RawID    URLEncodedBase64    `json:"rawId"`
+	Type     string              `json:"type"`
+	Response AttestationResponse `json:"response"`
+}
+
+// Challenge returns the challenge the response claims to answer, so the
+// caller can look up the ceremony. It is verified by VerifyRegistration.
+func (r *RegistrationResponse) Challenge() ([]byte, error) {
+	cd, err := parseClientData(r.Response.ClientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+	return cd.Challenge, nil
+}
+
+// AssertionResponseData is the response part of an assertion.
+type AssertionResponseData struct {
+	ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+	AuthenticatorData URLEncodedBase64 `json:"authenticatorData"`
+	Signature         URLEncodedBase64 `json:"signature"`
+	UserHandle        URLEncodedBase64 `json:"userHandle,omitempty"`
+}
+
+// AssertionResponse is the credential returned by
+// navigator.credentials.get(), in its toJSON() form.
+type AssertionResponse struct {
+	ID       string                `json:"id"`
+	RawID    URLEncodedBase64      `json:"rawId"`
+	Type     string                `json:"type"`
+	Response AssertionResponseData `json:"response"`
+}
+
+// Challenge returns the challenge the response claims to answer, so the
+// caller can look up the ceremony. It is verified by VerifyAssertion.
+func (r *AssertionResponse) Challenge() ([]byte, error) {
+	cd, err := parseClientData(r.Response.ClientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+	return cd.Challenge, nil
+}
+
+// Credential is a newly registered credential, as it should be stored.
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte // CBOR-encoded COSE_Key
+	SignCount      uint32
+	AAGUID         []byte
+	Transports     []string
+	BackupEligible bool
+	BackedUp       bool
+}
+
+// Assertion is the outcome of a verified assertion.
+type Assertion struct {
+	SignCount uint32
+	BackedUp  bool
+}
+
+// clientData is the subset of CollectedClientData we check.
+type clientData struct {
+	Type        string           `json:"type"`
+	Challenge   URLEncodedBase64 `json:"challenge"`
+	Origin      string           `json:"origin"`
+	CrossOrigin bool             `json:"crossOrigin"`
+}
+
+// authenticatorData is parsed authenticator data (WebAuthn Level 3 section 6.1).
+type authenticatorData struct {
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+
+	// Attested credential data, present on registration
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+	key          *publicKey
+}
+
+// VerifyRegistration verifies a registration response against the
+// challenge issued for it and returns the new credential.
+func (c Config) VerifyRegistration(resp *RegistrationResponse, challenge []byte) (*Credential, error) {
+	if resp == nil || resp.Type != credentialTypePublicKey {
+		return nil, fmt.Errorf("%w: not a public key credential", ErrInvalidResponse)
+	}
+	if err := c.verifyClientData(resp.Response.ClientDataJSON, clientDataTypeCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	v, rest, err := decodeCBOR(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: attestation object: %v", ErrInvalidResponse, err)
+	}
+	attestation, err := cborMapOf(v)
+	if err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("%w: attestation object is not a single map", ErrInvalidResponse)
+	}
+	format, _ := attestation["fmt"].(string)
+	rawAuthData, _ := attestation["authData"].([]byte)
+	statement, err := cborMapOf(attestation["attStmt"])
+	if err != nil {
+		return nil, fmt.Errorf("%w: attestation statement is not a map", ErrInvalidResponse)
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+	if authData.flags&flagAttestedData == 0 {
+		return nil, fmt.Errorf("%w: no attested credential data", ErrVerification)
+	}
+	if !bytes.Equal(authData.credentialID, resp.RawID) || resp.ID != base64.RawURLEncoding.EncodeToString(resp.RawID) {
+		return nil, fmt.Errorf("%w: credential id mismatch", ErrVerification)
+	}
+
+	clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+	if err := verifyAttestationStatement(format, statement, authData, rawAuthData, clientDataHash[:]); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:             authData.credentialID,
+		PublicKey:      authData.publicKey,
+		SignCount:      authData.signCount,
+		AAGUID:         authData.aaguid,
+		Transports:     resp.Response.Transports,
+		BackupEligible: authData.flags&flagBackupEligible != 0,
+		BackedUp:       authData.flags&flagBackedUp != 0,
+	}, nil
+}
+
+// VerifyAssertion verifies an assertion response against the challenge
+// issued for it and the stored credential's public key and signature
+// counter. The caller must already have matched resp.RawID (and, for
+// discoverable credentials, the user handle) to the stored credential.
+//
+// A counter that didn't increase returns the assertion together with
+// ErrSignCount, so the caller can decide what to do about a possible clone.
+func (c Config) VerifyAssertion(resp *AssertionResponse, challenge, storedKey []byte, storedCount uint32) (*Assertion, error) {
+	if resp == nil || resp.Type != credentialTypePublicKey {
+		return nil, fmt.Errorf("%w: not a public key credential", ErrInvalidResponse)
+	}
+	if err := c.verifyClientData(resp.Response.ClientDataJSON, clientDataTypeGet, challenge); err != nil {
+		return nil, err
+	}
+
+	authData, err := parseAuthenticatorData(resp.Response.AuthenticatorData)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+
+	key, _, err := parsePublicKey(storedKey)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+	signed := append(slices.Clip(resp.Response.AuthenticatorData), clientDataHash[:]...)
+	if !key.verify(signed, resp.Response.Signature) {
+		return nil, fmt.Errorf("%w: bad signature", ErrVerification)
+	}
+
+	assertion := &Assertion{
+		SignCount: authData.signCount,
+		BackedUp:  authData.flags&flagBackedUp != 0,
+	}
+	// Authenticators that don't keep a counter always report zero
+	if (authData.signCount != 0 || storedCount != 0) && authData.signCount <= storedCount {
+		return assertion, ErrSignCount
+	}
+	return assertion, nil
+}
+
+// verifyClientData checks the client data's type, challenge and origin.
+func (c Config) verifyClientData(raw []byte, wantType string, challenge []byte) error {
+	cd, err := parseClientData(raw)
+	if err != nil {
+		return err
+	}
+	if cd.Type != wantType {
+		return fmt.Errorf("%w: client data type %q, want %q", ErrVerification, cd.Type, wantType)
+	}
+	if len(challenge) == 0 || subtle.ConstantTimeCompare(cd.Challenge, challenge) != 1 {
+		return fmt.Errorf("%w: challenge mismatch", ErrVerification)
+	}
+	if !slices.Contains(c.Origins, cd.Origin) {
+		return fmt.Errorf("%w: origin %q not allowed", ErrVerification, cd.Origin)
+	}
+	if cd.CrossOrigin {
+		return fmt.Errorf("%w: cross-origin ceremony", ErrVerification)
+	}
+	return nil
+}
+
+// verifyAuthenticatorData checks the relying party id hash and that the
+// user was both present and verified.
+func (c Config) verifyAuthenticatorData(authData *authenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(c.RPID))
+	if subtle.ConstantTimeCompare(authData.rpIDHash, rpIDHash[:]) != 1 {
+		return fmt.Errorf("%w: relying party id mismatch", ErrVerification)
+	}
+	if authData.flags&flagUserPresent == 0 {
+		return fmt.Errorf("%w: user not present", ErrVerification)
+	}
+	if authData.flags&flagUserVerified == 0 {
+		return fmt.Errorf("%w: user not verified", ErrVerification)
+	}
+	if authData.flags&flagBackedUp != 0 && authData.flags&flagBackupEligible == 0 {
+		return fmt.Errorf("%w: backed up but not backup eligible", ErrVerification)
+	}
+	return nil
+}
+
+func parseClientData(raw []byte) (*clientData, error) {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return nil, fmt.Errorf("%w: client data: %v", ErrInvalidResponse, err)
+	}
+	return &cd, nil
+}
+
+// parseAuthenticatorData parses authenticator data, including the attested
+// credential data when its flag is set. Extensions are skipped.
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, fmt.Errorf("%w: authenticator data too short", ErrInvalidResponse)
+	}
+	authData := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[37:]
+
+	if authData.flags&flagAttestedData != 0 {
+		if len(rest) < 18 {
+			return nil, fmt.Errorf("%w: attested credential data too short", ErrInvalidResponse)
+		}
+		authData.aaguid = rest[:16]
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength > maxCredentialIDLength || idLength > len(rest) {
+			return nil, fmt.Errorf("%w: bad credential id length", ErrInvalidResponse)
+		}
+		authData.credentialID = rest[:idLength]
+		rest = rest[idLength:]
+
+		key, after, err := parsePublicKey(rest)
+		if err != nil {
+			if errors.Is(err, ErrUnsupportedKey) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: credential public key: %v", ErrInvalidResponse, err)
+		}
+		authData.key = key
+		authData.publicKey = rest[:len(rest)-len(after)]
+		rest = after
+	}
+
+	if authData.flags&flagExtensionIncluded != 0 {
+		var err error
+		if _, rest, err = decodeCBOR(rest); err != nil {
+			return nil, fmt.Errorf("%w: extensions: %v", ErrInvalidResponse, err)
+		}
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: trailing bytes in authenticator data", ErrInvalidResponse)
+	}
+	return authData, nil
+}
+
+// verifyAttestationStatement checks the "none" and "packed" attestation
+// formats. A packed statement's certificate, if any, is not chained to a
+// root: its signature is checked only to catch malformed responses.
+func verifyAttestationStatement(format string, statement map[interface{}]interface{}, authData *authenticatorData, rawAuthData, clientDataHash []byte) error {
+	switch format {
+	case "none":
+		if len(statement) != 0 {
+			return fmt.Errorf("%w: none attestation with a statement", ErrVerification)
+		}
+		return nil
+
+	case "packed":
+		alg, _ := statement["alg"].(int64)
+		sig, _ := statement["sig"].([]byte)
+		signed := append(slices.Clip(rawAuthData), clientDataHash...)
+
+		key := authData.key
+		if x5c, ok := statement["x5c"]; ok {
+			chain, _ := x5c.([]interface{})
+			if len(chain) == 0 {
+				return fmt.Errorf("%w: empty attestation certificate chain", ErrVerification)
+			}
+			der, _ := chain[0].([]byte)
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return fmt.Errorf("%w: attestation certificate: %v", ErrVerification, err)
+			}
+			key = &publicKey{alg: int(alg), key: cert.PublicKey}
+		} else if int(alg) != key.alg {
+			return fmt.Errorf("%w: self attestation algorithm mismatch", ErrVerification)
+		}
+		if !key.verify(signed, sig) {
+			return fmt.Errorf("%w: bad attestation signature", ErrVerification)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("%w: unsupported attestation format %q", ErrVerification, format)
+	}
+}
@@ -0,0 +1,328 @@
+package webauthn
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/solobueno/erp/pkg/webauthn/webauthntest"
+)
+
+const testOrigin = "https://erp.example.com"
+
+var testConfig = Config{
+	RPID:    "erp.example.com",
+	RPName:  "Solo Bueno ERP",
+	Origins: []string{testOrigin},
+}
+
+var testUser = User{ID: []byte("user-handle-0001"), Name: "ana@casa-ana.com", DisplayName: "Ana"}
+
+// register runs a registration ceremony and returns the response the
+// authenticator produced along with the challenge it answers.
+func register(t *testing.T, authn *webauthntest.Authenticator, exclude []CredentialDescriptor) (*RegistrationResponse, []byte) {
+	t.Helper()
+
+	challenge, err := NewChallenge()
+	if err != nil {
+		t.Fatalf("NewChallenge() error = %v", err)
+	}
+	opts, _ := json.Marshal(testConfig.CreationOptions(testUser, challenge, exclude))
+	out, err := authn.Register(opts)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	var resp RegistrationResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		t.Fatalf("unmarshal registration: %v", err)
+	}
+	return &resp, challenge
+}
+
+// assert runs an authentication ceremony and returns the response the
+// authenticator produced along with the challenge it answers.
+func assert(t *testing.T, authn *webauthntest.Authenticator, allow []CredentialDescriptor) (*AssertionResponse, []byte) {
+	t.Helper()
+
+	challenge, err := NewChallenge()
+	if err != nil {
+		t.Fatalf("NewChallenge() error = %v", err)
+	}
+	opts, _ := json.Marshal(testConfig.RequestOptions(challenge, allow))
+	out, err := authn.Login(opts)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	var resp AssertionResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		t.Fatalf("unmarshal assertion: %v", err)
+	}
+	return &resp, challenge
+}
+
+func TestCeremonies(t *testing.T) {
+	for name, alg := range map[string]int{
+		"ES256": webauthntest.AlgES256,
+		"EdDSA": webauthntest.AlgEdDSA,
+		"RS256": webauthntest.AlgRS256,
+	} {
+		t.Run(name, func(t *testing.T) {
+			authn := webauthntest.New(testOrigin)
+			authn.Algorithm = alg
+
+			resp, challenge := register(t, authn, nil)
+			if got, err := resp.Challenge(); err != nil || !reflect.DeepEqual(got, challenge) {
+				t.Fatalf("Challenge() = %x, %v, want %x", got, err, challenge)
+			}
+			cred, err := testConfig.VerifyRegistration(resp, challenge)
+			if err != nil {
+				t.Fatalf("VerifyRegistration() error = %v", err)
+			}
+			if !reflect.DeepEqual(cred.ID, authn.CredentialIDs()[0]) || cred.SignCount != 0 {
+				t.Errorf("credential = %+v", cred)
+			}
+			if string(cred.AAGUID) != string(webauthntest.AAGUID) || !reflect.DeepEqual(cred.Transports, []string{"internal"}) {
+				t.Errorf("AAGUID = %q, transports = %v", cred.AAGUID, cred.Transports)
+			}
+
+			allow := []CredentialDescriptor{NewCredentialDescriptor(cred.ID, cred.Transports)}
+			for want := uint32(1); want <= 2; want++ {
+				assertion, challenge := assert(t, authn, allow)
+				got, err := testConfig.VerifyAssertion(assertion, challenge, cred.PublicKey, cred.SignCount)
+				if err != nil {
+					t.Fatalf("VerifyAssertion() error = %v", err)
+				}
+				if got.SignCount != want {
+					t.Errorf("SignCount = %d, want %d", got.SignCount, want)
+				}
+				cred.SignCount = got.SignCount
+			}
+		})
+	}
+}
+
+func TestCreationOptions(t *testing.T) {
+	opts := testConfig.CreationOptions(testUser, []byte("challenge"), nil)
+	b, _ := json.Marshal(opts)
+	for _, want := range []string{
+		`"rp":{"id":"erp.example.com","name":"Solo Bueno ERP"}`,
+		`"challenge":"Y2hhbGxlbmdl"`,
+		`{"type":"public-key","alg":-7}`,
+		`"timeout":300000`,
+		`"authenticatorSelection":{"residentKey":"preferred","userVerification":"required"}`,
+		`"attestation":"none"`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("creation options %s missing %s", b, want)
+		}
+	}
+	if strings.Contains(string(b), "excludeCredentials") {
+		t.Errorf("creation options %s list excluded credentials", b)
+	}
+}
+
+func TestVerifyRegistration_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		prepare func(a *webauthntest.Authenticator)
+		tamper  func(resp *RegistrationResponse, challenge []byte) []byte
+		wantErr error
+	}{
+		{
+			name: "wrong challenge",
+			tamper: func(_ *RegistrationResponse, _ []byte) []byte {
+				return []byte("some other challenge")
+			},
+			wantErr: ErrVerification,
+		},
+		{
+			name:    "untrusted origin",
+			prepare: func(a *webauthntest.Authenticator) { a.Origin = "https://phish.example.net" },
+			wantErr: ErrVerification,
+		},
+		{
+			name:    "other relying party",
+			config:  Config{RPID: "other.example.com", Origins: []string{testOrigin}},
+			wantErr: ErrVerification,
+		},
+		{
+			name:    "user not verified",
+			prepare: func(a *webauthntest.Authenticator) { a.UserVerified = false },
+			wantErr: ErrVerification,
+		},
+		{
+			name: "credential id mismatch",
+			tamper: func(resp *RegistrationResponse, challenge []byte) []byte {
+				resp.RawID = []byte("another-credential")
+				return challenge
+			},
+			wantErr: ErrVerification,
+		},
+		{
+			name: "not a public key credential",
+			tamper: func(resp *RegistrationResponse, challenge []byte) []byte {
+				resp.Type = "password"
+				return challenge
+			},
+			wantErr: ErrInvalidResponse,
+		},
+		{
+			name: "truncated attestation object",
+			tamper: func(resp *RegistrationResponse, challenge []byte) []byte {
+				resp.Response.AttestationObject = resp.Response.AttestationObject[:40]
+				return challenge
+			},
+			wantErr: ErrInvalidResponse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authn := webauthntest.New(testOrigin)
+			if tt.prepare != nil {
+				tt.prepare(authn)
+			}
+			resp, challenge := register(t, authn, nil)
+			if tt.tamper != nil {
+				challenge = tt.tamper(resp, challenge)
+			}
+			config := testConfig
+			if tt.config.RPID != "" {
+				config = tt.config
+			}
+			if _, err := config.VerifyRegistration(resp, challenge); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyRegistration() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyAssertion_Rejects(t *testing.T) {
+	authn := webauthntest.New(testOrigin)
+	resp, challenge := register(t, authn, nil)
+	cred, err := testConfig.VerifyRegistration(resp, challenge)
+	if err != nil {
+		t.Fatalf("VerifyRegistration() error = %v", err)
+	}
+	other := webauthntest.New(testOrigin)
+	resp, challenge = register(t, other, nil)
+	otherCred, err := testConfig.VerifyRegistration(resp, challenge)
+	if err != nil {
+		t.Fatalf("VerifyRegistration() error = %v", err)
+	}
+
+	t.Run("wrong challenge", func(t *testing.T) {
+		assertion, _ := assert(t, authn, nil)
+		if _, err := testConfig.VerifyAssertion(assertion, []byte("stale"), cred.PublicKey, 0); !errors.Is(err, ErrVerification) {
+			t.Errorf("error = %v, want %v", err, ErrVerification)
+		}
+	})
+
+	t.Run("another credential's key", func(t *testing.T) {
+		assertion, challenge := assert(t, authn, nil)
+		if _, err := testConfig.VerifyAssertion(assertion, challenge, otherCred.PublicKey, 0); !errors.Is(err, ErrVerification) {
+			t.Errorf("error = %v, want %v", err, ErrVerification)
+		}
+	})
+
+	t.Run("tampered authenticator data", func(t *testing.T) {
+		assertion, challenge := assert(t, authn, nil)
+		assertion.Response.AuthenticatorData[36] ^= 0xff // Sign count
+		if _, err := testConfig.VerifyAssertion(assertion, challenge, cred.PublicKey, 0); !errors.Is(err, ErrVerification) {
+			t.Errorf("error = %v, want %v", err, ErrVerification)
+		}
+	})
+
+	t.Run("registration client data", func(t *testing.T) {
+		reg, challenge := register(t, webauthntest.New(testOrigin), nil)
+		assertion, _ := assert(t, authn, nil)
+		assertion.Response.ClientDataJSON = reg.Response.ClientDataJSON
+		if _, err := testConfig.VerifyAssertion(assertion, challenge, cred.PublicKey, 0); !errors.Is(err, ErrVerification) {
+			t.Errorf("error = %v, want %v", err, ErrVerification)
+		}
+	})
+
+	t.Run("user not verified", func(t *testing.T) {
+		unverified := authn.Clone()
+		unverified.UserVerified = false
+		assertion, challenge := assert(t, unverified, nil)
+		if _, err := testConfig.VerifyAssertion(assertion, challenge, cred.PublicKey, 0); !errors.Is(err, ErrVerification) {
+			t.Errorf("error = %v, want %v", err, ErrVerification)
+		}
+	})
+
+	t.Run("cloned authenticator", func(t *testing.T) {
+		clone := authn.Clone()
+		assertion, challenge := assert(t, authn, nil)
+		got, err := testConfig.VerifyAssertion(assertion, challenge, cred.PublicKey, 0)
+		if err != nil {
+			t.Fatalf("VerifyAssertion() error = %v", err)
+		}
+
+		// The clone's counter lags behind the original's
+		assertion, challenge = assert(t, clone, nil)
+		if _, err := testConfig.VerifyAssertion(assertion, challenge, cred.PublicKey, got.SignCount); !errors.Is(err, ErrSignCount) {
+			t.Errorf("error = %v, want %v", err, ErrSignCount)
+		}
+	})
+}
+
+func TestURLEncodedBase64(t *testing.T) {
+	var got struct {
+		Raw    URLEncodedBase64 `json:"raw"`
+		Padded URLEncodedBase64 `json:"padded"`
+	}
+	if err := json.Unmarshal([]byte(`{"raw":"_-8","padded":"_-8="}`), &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if string(got.Raw) != "\xff\xef" || string(got.Padded) != "\xff\xef" {
+		t.Errorf("decoded = %x, %x", got.Raw, got.Padded)
+	}
+	if b, _ := json.Marshal(got.Raw); string(b) != `"_-8"` {
+		t.Errorf("Marshal() = %s", b)
+	}
+	if err := json.Unmarshal([]byte(`{"raw":"+/8"}`), &got); err == nil {
+		t.Error("Unmarshal() accepted standard base64")
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	// {1: 2, 3: -7, "a": h'0102', "b": [true, null]}
+	data := []byte{0xa4, 0x01, 0x02, 0x03, 0x26, 0x61, 'a', 0x42, 0x01, 0x02, 0x61, 'b', 0x82, 0xf5, 0xf6, 0xff}
+	v, rest, err := decodeCBOR(data)
+	if err != nil {
+		t.Fatalf("decodeCBOR() error = %v", err)
+	}
+	want := map[interface{}]interface{}{
+		int64(1): int64(2),
+		int64(3): int64(-7),
+		"a":      []byte{1, 2},
+		"b":      []interface{}{true, nil},
+	}
+	if !reflect.DeepEqual(v, want) || !reflect.DeepEqual(rest, []byte{0xff}) {
+		t.Errorf("decodeCBOR() = %v, rest %x", v, rest)
+	}
+
+	deep := make([]byte, maxCBORDepth+2)
+	for i := range deep {
+		deep[i] = 0x81 // One-item array
+	}
+	for name, data := range map[string][]byte{
+		"empty":              {},
+		"truncated string":   {0x45, 0x01},
+		"indefinite length":  {0x5f, 0x41, 0x01, 0xff},
+		"float":              {0xf9, 0x3c, 0x00},
+		"duplicate key":      {0xa2, 0x01, 0x01, 0x01, 0x02},
+		"array map key":      {0xa1, 0x80, 0x01},
+		"oversized count":    {0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"nested too deeply":  deep,
+		"integer overflow":   {0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"truncated argument": {0x19, 0x01},
+	} {
+		if _, _, err := decodeCBOR(data); !errors.Is(err, errCBOR) {
+			t.Errorf("decodeCBOR(%s) error = %v, want %v", name, err, errCBOR)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+// Package webauthntest provides a software WebAuthn authenticator for tests.
+// It plays both the browser and the authenticator: it takes the relying
+// party's options as JSON and returns the credential JSON a browser would
+// post back, signed with keys it keeps in memory.
+package webauthntest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// COSE algorithm identifiers the authenticator can create keys for.
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// ErrNoCredential is returned by Login when the authenticator holds no
+// credential the options allow.
+var ErrNoCredential = errors.New("webauthntest: no matching credential")
+
+// ErrExcluded is returned by Register when the authenticator already holds
+// one of the excluded credentials.
+var ErrExcluded = errors.New("webauthntest: credential already registered")
+
+// AAGUID is the authenticator model id reported on registration.
+var AAGUID = []byte("webauthntest-sw!")
+
+// credential is a key pair held by the authenticator.
+type credential struct {
+	id         []byte
+	rpID       string
+	userHandle []byte
+	alg        int
+	signer     crypto.Signer
+	signCount  uint32
+}
+
+// Authenticator is a software authenticator bound to a browser origin. It
+// creates discoverable credentials and is safe for concurrent use. The exported fields may be changed between ceremonies
+// to simulate misbehaving clients.
+type Authenticator struct {
+	// Origin is the origin reported in the client data.
+	Origin string
+	// Algorithm is the key type new credentials are created with.
+	Algorithm int
+	// UserVerified reports whether the user was verified (PIN or biometric).
+	UserVerified bool
+	// BackupEligible marks credentials as synced passkeys.
+	BackupEligible bool
+
+	mu          sync.Mutex
+	credentials []*credential
+}
+
+// New returns an authenticator for origin that creates ES256 credentials.
+func New(origin string) *Authenticator {
+	return &Authenticator{Origin: origin, Algorithm: AlgES256, UserVerified: true}
+}
+
+// Clone returns a copy of the authenticator holding the same keys and
+// counters, as an attacker who extracted them would.
+func (a *Authenticator) Clone() *Authenticator {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	clone := &Authenticator{
+		Origin:         a.Origin,
+		Algorithm:      a.Algorithm,
+		UserVerified:   a.UserVerified,
+		BackupEligible: a.BackupEligible,
+	}
+	for _, cred := range a.credentials {
+		c := *cred
+		clone.credentials = append(clone.credentials, &c)
+	}
+	return clone
+}
+
+// CredentialIDs returns the ids of the credentials held, oldest first.
+func (a *Authenticator) CredentialIDs() [][]byte {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ids := make([][]byte, 0, len(a.credentials))
+	for _, cred := range a.credentials {
+		ids = append(ids, cred.id)
+	}
+	return ids
+}
+
+// creationOptions is the subset of PublicKeyCredentialCreationOptions used.
+type creationOptions struct {
+	RP struct {
+		ID string `json:"id"`
+	} `json:"rp"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Challenge          string       `json:"challenge"`
+	PubKeyCredParams   []credParam  `json:"pubKeyCredParams"`
+	ExcludeCredentials []descriptor `json:"excludeCredentials"`
+}
+
+type credParam struct {
+	Alg int `json:"alg"`
+}
+
+// requestOptions is the subset of PublicKeyCredentialRequestOptions used.
+type requestOptions struct {
+	Challenge        string       `json:"challenge"`
+	RPID             string       `json:"rpId"`
+	AllowCredentials []descriptor `json:"allowCredentials"`
+}
+
+type descriptor struct {
+	ID string `json:"id"`
+}
+
+// Register creates a credential for the creation options in optionsJSON
+// and returns the registration response JSON, with a "none" attestation.
+func (a *Authenticator) Register(optionsJSON []byte) ([]byte, error) {
+	var opts creationOptions
+	if err := json.Unmarshal(optionsJSON, &opts); err != nil {
+		return nil, fmt.Errorf("webauthntest: creation options: %w", err)
+	}
+	userHandle, err := decode(opts.User.ID)
+	if err != nil {
+		return nil, fmt.Errorf("webauthntest: user id: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, excluded := range opts.ExcludeCredentials {
+		if a.find(opts.RP.ID, excluded.ID) != nil {
+			return nil, ErrExcluded
+		}
+	}
+	if !slices.Contains(opts.PubKeyCredParams, credParam{Alg: a.Algorithm}) {
+		return nil, fmt.Errorf("webauthntest: relying party doesn't accept algorithm %d", a.Algorithm)
+	}
+
+	signer, err := generateKey(a.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	cred := &credential{
+		id:         randomBytes(16),
+		rpID:       opts.RP.ID,
+		userHandle: userHandle,
+		alg:        a.Algorithm,
+		signer:     signer,
+	}
+
+	authData := a.authenticatorData(cred, flagAttestedData)
+	authData = append(authData, AAGUID...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(cred.id)))
+	authData = append(authData, cred.id...)
+	authData = append(authData, coseKey(cred.alg, signer.Public())...)
+
+	attestationObject := encodeCBOR(cborMap{
+		{"fmt", "none"},
+		{"attStmt", cborMap{}},
+		{"authData", authData},
+	})
+
+	a.credentials = append(a.credentials, cred)
+
+	return json.Marshal(map[string]interface{}{
+		"id":    encode(cred.id),
+		"rawId": encode(cred.id),
+		"type":  "public-key",
+		"response": map[string]interface{}{
+			"clientDataJSON":    encode(a.clientData("webauthn.create", opts.Challenge)),
+			"attestationObject": encode(attestationObject),
+			"transports":        []string{"internal"},
+		},
+		"authenticatorAttachment": "platform",
+	})
+}
+
+// Login asserts a credential for the request options in optionsJSON and
+// returns the assertion response JSON. With an allow list the first
+// allowed credential held is used; without one, the newest credential for
+// the relying party.
+func (a *Authenticator) Login(optionsJSON []byte) ([]byte, error) {
+	var opts requestOptions
+	if err := json.Unmarshal(optionsJSON, &opts); err != nil {
+		return nil, fmt.Errorf("webauthntest: request options: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var cred *credential
+	if len(opts.AllowCredentials) > 0 {
+		for _, allowed := range opts.AllowCredentials {
+			if cred = a.find(opts.RPID, allowed.ID); cred != nil {
+				break
+			}
+		}
+	} else {
+		for i := len(a.credentials) - 1; i >= 0 && cred == nil; i-- {
+			if a.credentials[i].rpID == opts.RPID {
+				cred = a.credentials[i]
+			}
+		}
+	}
+	if cred == nil {
+		return nil, ErrNoCredential
+	}
+
+	cred.signCount++
+	authData := a.authenticatorData(cred, 0)
+	clientData := a.clientData("webauthn.get", opts.Challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	signature, err := sign(cred, append(authData, clientDataHash[:]...))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"id":    encode(cred.id),
+		"rawId": encode(cred.id),
+		"type":  "public-key",
+		"response": map[string]interface{}{
+			"clientDataJSON":    encode(clientData),
+			"authenticatorData": encode(authData),
+			"signature":         encode(signature),
+			"userHandle":        encode(cred.userHandle),
+		},
+		"authenticatorAttachment": "platform",
+	})
+}
+
+// find returns the credential for rpID with the base64url id, or nil.
+func (a *Authenticator) find(rpID, id string) *credential {
+	for _, cred := range a.credentials {
+		if cred.rpID == rpID && encode(cred.id) == strings.TrimRight(id, "=") {
+			return cred
+		}
+	}
+	return nil
+}
+
+// Authenticator data flags.
+const (
+	flagUserPresent    = 0x01
+	flagUserVerified   = 0x04
+	flagBackupEligible = 0x08
+	flagBackedUp       = 0x10
+	flagAttestedData   = 0x40
+)
+
+// authenticatorData returns the rpIdHash, flags and counter of cred.
+func (a *Authenticator) authenticatorData(cred *credential, extraFlags byte) []byte {
+	flags := flagUserPresent | extraFlags
+	if a.UserVerified {
+		flags |= flagUserVerified
+	}
+	if a.BackupEligible {
+		flags |= flagBackupEligible | flagBackedUp
+	}
+	rpIDHash := sha256.Sum256([]byte(cred.rpID))
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, cred.signCount)
+}
+
+func (a *Authenticator) clientData(typ, challenge string) []byte {
+	data, _ := json.Marshal(map[string]interface{}{
+		"type":        typ,
+		"challenge":   challenge,
+		"origin":      a.Origin,
+		"crossOrigin": false,
+	})
+	return data
+}
+
+func generateKey(alg int) (crypto.Signer, error) {
+	switch alg {
+	case AlgES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	case AlgRS256:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		return nil, fmt.Errorf("webauthntest: unsupported algorithm %d", alg)
+	}
+}
+
+func sign(cred *credential, data []byte) ([]byte, error) {
+	if cred.alg == AlgEdDSA {
+		return cred.signer.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(data)
+	return cred.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// coseKey encodes a public key as a COSE_Key.
+func coseKey(alg int, pub crypto.PublicKey) []byte {
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		point, _ := key.Bytes()
+		return encodeCBOR(cborMap{
+			{1, 2},   // kty: EC2
+			{3, alg}, // alg
+			{-1, 1},  // crv: P-256
+			{-2, point[1:33]},
+			{-3, point[33:]},
+		})
+	case ed25519.PublicKey:
+		return encodeCBOR(cborMap{
+			{1, 1},   // kty: OKP
+			{3, alg}, // alg
+			{-1, 6},  // crv: Ed25519
+			{-2, []byte(key)},
+		})
+	case *rsa.PublicKey:
+		return encodeCBOR(cborMap{
+			{1, 3},   // kty: RSA
+			{3, alg}, // alg
+			{-1, key.N.Bytes()},
+			{-2, big.NewInt(int64(key.E)).Bytes()},
+		})
+	default:
+		panic(fmt.Sprintf("webauthntest: unexpected key type %T", pub))
+	}
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}